@@ -0,0 +1,64 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/http"
+	"strings"
+
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+var (
+	gitHTTPPathPattern = lazyregexp.New(`^/[^/]+/[^/]+/(git-upload-pack|git-receive-pack|info/refs|info/lfs/.*)$`)
+	uploadPathPattern  = lazyregexp.New(`/(issues/attachments|releases/attachments|upload-file|avatar)$`)
+)
+
+// maxBodySize returns the maximum size of request body in bytes for the
+// endpoint group that the request path belongs to. It returns 0 when there is
+// no limit.
+func maxBodySize(path string) int64 {
+	limits := conf.HTTP.MaxBodySize
+
+	var size int64
+	switch {
+	case strings.HasPrefix(path, "/api/"):
+		size = limits.API
+	case gitHTTPPathPattern.MatchString(path):
+		// NOTE: Git HTTP has to be checked before other web routes, pushes are
+		// usually much larger than anything else and must not be capped by the
+		// limit of web forms.
+		size = limits.Git
+	case uploadPathPattern.MatchString(path):
+		size = limits.Upload
+	default:
+		size = limits.Form
+	}
+	return size * 1024 * 1024
+}
+
+// MaxBodySize limits the size of request bodies by the configured limit of the
+// endpoint group. Requests that declare a larger body are rejected with 413
+// right away, and reading more than the limit from other requests fails.
+func MaxBodySize() macaron.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		size := maxBodySize(r.URL.Path)
+		if size <= 0 || r.Body == nil {
+			return
+		}
+
+		if r.ContentLength > size {
+			// NOTE: Closing the connection to stop clients from sending the
+			// rest of the body that nobody will read.
+			w.Header().Set("Connection", "close")
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, size)
+	}
+}
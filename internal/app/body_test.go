@@ -0,0 +1,163 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestMaxBodySize(t *testing.T) {
+	before := conf.HTTP.MaxBodySize
+	t.Cleanup(func() {
+		conf.HTTP.MaxBodySize = before
+	})
+	conf.HTTP.MaxBodySize.API = 2
+	conf.HTTP.MaxBodySize.Git = 8
+	conf.HTTP.MaxBodySize.Form = 1
+	conf.HTTP.MaxBodySize.Upload = 4
+
+	readBody := func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	m := macaron.New()
+	m.Use(MaxBodySize())
+	m.Post("/api/v1/repos/:username/:reponame/issues", readBody)
+	m.Post("/:username/:reponame/git-receive-pack", readBody)
+	m.Put("/:username/:reponame/info/lfs/objects/basic/:oid", readBody)
+	m.Post("/:username/:reponame/issues/new", readBody)
+	m.Post("/:username/:reponame/issues/attachments", readBody)
+
+	const mb = 1024 * 1024
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		size          int
+		chunked       bool
+		expStatusCode int
+	}{
+		{
+			name:          "web form within limit",
+			method:        "POST",
+			path:          "/alice/example/issues/new",
+			size:          mb / 2,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "oversize web form",
+			method:        "POST",
+			path:          "/alice/example/issues/new",
+			size:          2 * mb,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "oversize web form without content length",
+			method:        "POST",
+			path:          "/alice/example/issues/new",
+			size:          2 * mb,
+			chunked:       true,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "large git push within limit",
+			method:        "POST",
+			path:          "/alice/example.git/git-receive-pack",
+			size:          6 * mb,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "large git push without content length",
+			method:        "POST",
+			path:          "/alice/example.git/git-receive-pack",
+			size:          6 * mb,
+			chunked:       true,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "oversize git push",
+			method:        "POST",
+			path:          "/alice/example.git/git-receive-pack",
+			size:          9 * mb,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "LFS upload within limit",
+			method:        "PUT",
+			path:          "/alice/example.git/info/lfs/objects/basic/ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f",
+			size:          6 * mb,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "API request within limit",
+			method:        "POST",
+			path:          "/api/v1/repos/alice/example/issues",
+			size:          mb + mb/2,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "oversize API request",
+			method:        "POST",
+			path:          "/api/v1/repos/alice/example/issues",
+			size:          3 * mb,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "upload within limit",
+			method:        "POST",
+			path:          "/alice/example/issues/attachments",
+			size:          3 * mb,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "oversize upload",
+			method:        "POST",
+			path:          "/alice/example/issues/attachments",
+			size:          5 * mb,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body io.Reader = bytes.NewReader(make([]byte, test.size))
+			if test.chunked {
+				// Hide the length of the body from the request
+				body = io.MultiReader(body)
+			}
+			r, err := http.NewRequest(test.method, test.path, body)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+			assert.Equal(t, test.expStatusCode, w.Code)
+		})
+	}
+
+	t.Run("no limit", func(t *testing.T) {
+		conf.HTTP.MaxBodySize.Git = 0
+
+		r, err := http.NewRequest("POST", "/alice/example.git/git-receive-pack", bytes.NewReader(make([]byte, 9*mb)))
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
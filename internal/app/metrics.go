@@ -5,12 +5,16 @@
 package app
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"gopkg.in/macaron.v1"
 
 	"gogs.io/gogs/internal/authutil"
 	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/metrics"
 )
 
 func MetricsFilter() macaron.Handler {
@@ -31,3 +35,71 @@ func MetricsFilter() macaron.Handler {
 		}
 	}
 }
+
+// MetricsTokenFilter responds 404 when application metrics are disabled, and
+// 401 when the request does not have the configured bearer token.
+func MetricsTokenFilter() macaron.Handler {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !conf.Metrics.Enabled || conf.Metrics.Token == "" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(conf.Metrics.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+}
+
+// RequestMetrics counts HTTP requests in metrics.HTTPRequests by the route
+// pattern, method and status code.
+func RequestMetrics() macaron.Handler {
+	return func(c *macaron.Context) {
+		c.Next()
+
+		status := c.Resp.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		metrics.HTTPRequests.WithLabelValues(
+			routePattern(c.Req.URL.Path, c.AllParams(), status),
+			c.Req.Method,
+			strconv.Itoa(status),
+		).Inc()
+	}
+}
+
+// routePattern returns the route pattern of the request path by replacing
+// values of URL parameters with their names, e.g. "/:username/:reponame" for
+// "/alice/example". Requests that are not found without any URL parameter are
+// collapsed into "NotFound" to keep the number of distinct patterns bounded.
+func routePattern(path string, params macaron.Params, status int) string {
+	if len(params) == 0 {
+		if status == http.StatusNotFound {
+			return "NotFound"
+		}
+		return path
+	}
+
+	// Splats can span multiple segments, thus replace them first.
+	if splat := params["*"]; splat != "" && strings.HasSuffix(path, "/"+splat) {
+		path = strings.TrimSuffix(path, splat) + "*"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" || segment == "*" {
+			continue
+		}
+		for name, value := range params {
+			if strings.HasPrefix(name, ":") && value == segment {
+				segments[i] = name
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
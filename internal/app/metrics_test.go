@@ -0,0 +1,127 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/metrics"
+)
+
+func TestMetricsTokenFilter(t *testing.T) {
+	m := macaron.New()
+	m.Use(RequestMetrics())
+	m.Get("/metrics", MetricsTokenFilter(), metrics.Handler())
+	m.Get("/:username/:reponame/issues/:index", func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(t *testing.T, token string) *httptest.ResponseRecorder {
+		r, err := http.NewRequest("GET", "/metrics", nil)
+		require.NoError(t, err)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		conf.SetMockMetrics(t, conf.MetricsOpts{Enabled: false, Token: "secret"})
+		assert.Equal(t, http.StatusNotFound, serve(t, "secret").Code)
+	})
+
+	conf.SetMockMetrics(t, conf.MetricsOpts{Enabled: true, Token: "secret"})
+
+	t.Run("no token", func(t *testing.T) {
+		w := serve(t, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="metrics"`, w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(t, "wrong").Code)
+	})
+
+	t.Run("well-formed metrics", func(t *testing.T) {
+		r, err := http.NewRequest("GET", "/alice/example/issues/1", nil)
+		require.NoError(t, err)
+		m.ServeHTTP(httptest.NewRecorder(), r)
+
+		w := serve(t, "secret")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var parser expfmt.TextParser
+		families, err := parser.TextToMetricFamilies(w.Body)
+		require.NoError(t, err)
+
+		requests, ok := families["gogs_http_requests_total"]
+		require.True(t, ok, "gogs_http_requests_total should be exported")
+		found := false
+		for _, metric := range requests.Metric {
+			labels := make(map[string]string)
+			for _, label := range metric.Label {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["route"] == "/:username/:reponame/issues/:index" && labels["status"] == "200" {
+				found = true
+				assert.GreaterOrEqual(t, metric.Counter.GetValue(), float64(1))
+			}
+		}
+		assert.True(t, found, "request should be counted by its route pattern")
+		assert.Contains(t, families, "go_goroutines")
+	})
+}
+
+func Test_routePattern(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		params macaron.Params
+		status int
+		want   string
+	}{
+		{
+			name:   "static route",
+			path:   "/explore/repos",
+			status: http.StatusOK,
+			want:   "/explore/repos",
+		},
+		{
+			name:   "not found",
+			path:   "/no/such/page",
+			status: http.StatusNotFound,
+			want:   "NotFound",
+		},
+		{
+			name:   "named parameters",
+			path:   "/alice/example/pulls/2",
+			params: macaron.Params{":username": "alice", ":reponame": "example", ":index": "2"},
+			status: http.StatusOK,
+			want:   "/:username/:reponame/pulls/:index",
+		},
+		{
+			name:   "splat",
+			path:   "/alice/example/src/main/docs/README.md",
+			params: macaron.Params{":username": "alice", ":reponame": "example", "*": "main/docs/README.md", "*0": "main/docs/README.md"},
+			status: http.StatusOK,
+			want:   "/:username/:reponame/src/*",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, routePattern(test.path, test.params, test.status))
+		})
+	}
+}
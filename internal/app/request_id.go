@@ -0,0 +1,43 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"gopkg.in/macaron.v1"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/lazyregexp"
+	"gogs.io/gogs/internal/logutil"
+	"gogs.io/gogs/internal/strutil"
+)
+
+// RequestIDHeader is the header that carries the ID of the request.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern is the pattern of request IDs that are accepted from
+// clients, e.g. set by reverse proxies.
+var requestIDPattern = lazyregexp.New(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// RequestID tags the request with an ID, which is taken from the request header
+// when it is valid or generated otherwise. The ID is returned by the response
+// header and attached to the logger in the context of the request, so that all
+// log messages written for the request can be correlated.
+func RequestID() macaron.Handler {
+	return func(c *macaron.Context) {
+		id := c.Req.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			var err error
+			id, err = strutil.RandomChars(20)
+			if err != nil {
+				log.Error("Failed to generate request ID: %v", err)
+				return
+			}
+		}
+
+		c.Resp.Header().Set(RequestIDHeader, id)
+		ctx := c.Req.Context()
+		c.Req.Request = c.Req.WithContext(logutil.NewContext(ctx, logutil.FromContext(ctx).With("request_id", id)))
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/logutil"
+)
+
+func TestRequestID(t *testing.T) {
+	var gotLogger *logutil.Logger
+	m := macaron.New()
+	m.Use(RequestID())
+	m.Get("/", func(c *macaron.Context) {
+		gotLogger = logutil.FromContext(c.Req.Context())
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		wantID string
+	}{
+		{
+			name:   "from header",
+			header: "7c3e1a2b-proxy.1",
+			wantID: "7c3e1a2b-proxy.1",
+		},
+		{
+			name:   "generated",
+			header: "",
+		},
+		{
+			name:   "invalid header",
+			header: "<script>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotLogger = nil
+			r, err := http.NewRequest("GET", "/", nil)
+			require.NoError(t, err)
+			if test.header != "" {
+				r.Header.Set(RequestIDHeader, test.header)
+			}
+
+			w := httptest.NewRecorder()
+			m.ServeHTTP(w, r)
+
+			id := w.Header().Get(RequestIDHeader)
+			if test.wantID != "" {
+				assert.Equal(t, test.wantID, id)
+			} else {
+				assert.Len(t, id, 20)
+				assert.NotEqual(t, test.header, id)
+			}
+			assert.Equal(t, (&logutil.Logger{}).With("request_id", id), gotLogger)
+		})
+	}
+}
@@ -23,6 +23,7 @@ const (
 	PAM         // 4
 	DLDAP       // 5
 	GitHub      // 6
+	OIDC        // 7
 )
 
 // Name returns the human-readable name for given authentication type.
@@ -33,6 +34,7 @@ func Name(typ Type) string {
 		SMTP:   "SMTP",
 		PAM:    "PAM",
 		GitHub: "GitHub",
+		OIDC:   "OpenID Connect",
 	}[typ]
 }
 
@@ -68,12 +70,30 @@ type ExternalAccount struct {
 	FullName string
 	// The email address of the account.
 	Email string
+	// Whether the email address has been verified by the provider, which is
+	// required to link the account with an existing user by email address.
+	EmailVerified bool
 	// The location of the account.
 	Location string
 	// The website of the account.
 	Website string
+	// The URL of the avatar of the account.
+	AvatarURL string
 	// Whether the user should be prompted as a site admin.
 	Admin bool
+	// The fields to be synchronized to the existing user on every login.
+	Sync ExternalAccountSync
+}
+
+// ExternalAccountSync specifies which fields of an ExternalAccount should be
+// synchronized to the existing user on every login. Empty values are never
+// synchronized except for Admin.
+type ExternalAccountSync struct {
+	FullName  bool
+	Email     bool
+	Location  bool
+	AvatarURL bool
+	Admin     bool
 }
 
 // Provider defines an authenticate provider which provides ability to authentication against
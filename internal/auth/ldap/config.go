@@ -9,6 +9,7 @@ package ldap
 import (
 	"crypto/tls"
 	"fmt"
+	"net"
 	"strings"
 
 	ldap "github.com/go-ldap/ldap/v3"
@@ -38,7 +39,7 @@ func SecurityProtocolName(protocol SecurityProtocol) string {
 //
 // ⚠️ WARNING: Change to the field name must preserve the INI key name for backward compatibility.
 type Config struct {
-	Host              string // LDAP host
+	Host              string // LDAP host, or comma-separated hosts in failover order
 	Port              int    // Port number
 	SecurityProtocol  SecurityProtocol
 	SkipVerify        bool
@@ -58,6 +59,14 @@ type Config struct {
 	GroupFilter       string // Group name filter
 	GroupMemberUID    string `ini:"group_member_uid"` // Group Attribute containing array of UserUID
 	UserUID           string `ini:"user_uid"`         // User Attribute listed in group
+	AttributeFullName string // Full name attribute, takes precedence over first name and surname
+	AttributeAvatar   string // Avatar URL attribute
+	AttributeLocation string // Location attribute
+	AdminGroupDN      string `ini:"admin_group_dn"` // DN of the group whose members are admins
+	SyncProfile       bool   // Whether to sync mapped attributes to existing users on every login
+	PoolSize          int    // Maximum number of idle connections kept for each host, 0 to disable pooling
+	Timeout           int    // Timeout in seconds of connecting and each request
+	HostRetryInterval int    // Seconds that a host failed to connect is skipped
 }
 
 func (c *Config) SecurityProtocolName() string {
@@ -108,7 +117,7 @@ func (*Config) sanitizedGroupDN(groupDn string) (string, bool) {
 	return groupDn, true
 }
 
-func (c *Config) findUserDN(l *ldap.Conn, name string) (string, bool) {
+func (c *Config) findUserDN(l ldap.Client, name string) (string, bool) {
 	log.Trace("Search for LDAP user: %s", name)
 	if len(c.BindDN) > 0 && len(c.BindPassword) > 0 {
 		// Replace placeholders with username
@@ -153,18 +162,21 @@ func (c *Config) findUserDN(l *ldap.Conn, name string) (string, bool) {
 	return userDN, true
 }
 
-func dial(ls *Config) (*ldap.Conn, error) {
-	log.Trace("LDAP: Dialing with security protocol '%v' without verifying: %v", ls.SecurityProtocol, ls.SkipVerify)
+// dialHost connects to the host of the LDAP directory, it is a variable to be
+// replaced in tests.
+var dialHost = func(ls *Config, e endpoint) (ldap.Client, error) {
+	log.Trace("LDAP: Dialing %q with security protocol '%v' without verifying: %v", e, ls.SecurityProtocol, ls.SkipVerify)
 
 	tlsCfg := &tls.Config{
-		ServerName:         ls.Host,
+		ServerName:         e.Host,
 		InsecureSkipVerify: ls.SkipVerify,
 	}
+	dialer := &net.Dialer{Timeout: ls.timeout()}
 	if ls.SecurityProtocol == SecurityProtocolLDAPS {
-		return ldap.DialTLS("tcp", fmt.Sprintf("%s:%d", ls.Host, ls.Port), tlsCfg)
+		return ldap.DialURL("ldaps://"+e.String(), ldap.DialWithDialer(dialer), ldap.DialWithTLSConfig(tlsCfg))
 	}
 
-	conn, err := ldap.Dial("tcp", fmt.Sprintf("%s:%d", ls.Host, ls.Port))
+	conn, err := ldap.DialURL("ldap://"+e.String(), ldap.DialWithDialer(dialer))
 	if err != nil {
 		return nil, fmt.Errorf("Dial: %v", err)
 	}
@@ -179,7 +191,7 @@ func dial(ls *Config) (*ldap.Conn, error) {
 	return conn, nil
 }
 
-func bindUser(l *ldap.Conn, userDN, passwd string) error {
+func bindUser(l ldap.Client, userDN, passwd string) error {
 	log.Trace("Binding with userDN: %s", userDN)
 	err := l.Bind(userDN, passwd)
 	if err != nil {
@@ -190,20 +202,71 @@ func bindUser(l *ldap.Conn, userDN, passwd string) error {
 	return err
 }
 
+// searchResult contains attributes of the user entry returned by searchEntry.
+type searchResult struct {
+	Username  string
+	FirstName string
+	Surname   string
+	FullName  string
+	Mail      string
+	Avatar    string
+	Location  string
+	IsAdmin   bool
+	// Whether the admin group membership has been checked successfully.
+	HasAdminGroup bool
+}
+
+// isGroupMember returns true if any of the groups lists the given value in the
+// member attribute.
+func isGroupMember(groups []*ldap.Entry, memberAttr, value string) bool {
+	for _, group := range groups {
+		for _, member := range group.GetAttributeValues(memberAttr) {
+			if member == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // searchEntry searches an LDAP source if an entry (name, passwd) is valid and in the specific filter.
-func (c *Config) searchEntry(name, passwd string, directBind bool) (string, string, string, string, bool, bool) {
+// When the connection breaks during the search, the search is retried with the
+// next host.
+func (c *Config) searchEntry(name, passwd string, directBind bool) (*searchResult, bool) {
 	// See https://tools.ietf.org/search/rfc4513#section-5.1.2
 	if passwd == "" {
 		log.Trace("authentication failed for '%s' with empty password", name)
-		return "", "", "", "", false, false
+		return nil, false
 	}
-	l, err := dial(c)
-	if err != nil {
-		log.Error("LDAP connect failed for '%s': %v", c.Host, err)
-		return "", "", "", "", false, false
+
+	tried := make(map[endpoint]bool)
+	for {
+		l, e, pooled, err := c.connect(tried)
+		if err != nil {
+			log.Error("LDAP connect failed for '%s': %v", c.Host, err)
+			return nil, false
+		}
+
+		result, ok := c.searchEntryWith(l, name, passwd, directBind)
+		if !ok && l.IsClosing() {
+			_ = l.Close()
+			// Idle connections could have been closed by the server, only fresh
+			// connections tell the host is down.
+			if !pooled {
+				log.Warn("LDAP: Connection to %q broke, marking it down for %s", e, c.hostRetryInterval())
+				health.MarkDown(e.String(), c.hostRetryInterval())
+				tried[e] = true
+			}
+			continue
+		}
+		c.release(l, e)
+		return result, ok
 	}
-	defer l.Close()
+}
 
+// searchEntryWith searches an entry (name, passwd) using the connection.
+func (c *Config) searchEntryWith(l ldap.Client, name, passwd string, directBind bool) (*searchResult, bool) {
+	var err error
 	var userDN string
 	if directBind {
 		log.Trace("LDAP will bind directly via UserDN template: %s", c.UserDN)
@@ -211,7 +274,7 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		var ok bool
 		userDN, ok = c.sanitizedUserDN(name)
 		if !ok {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 	} else {
 		log.Trace("LDAP will use BindDN")
@@ -219,7 +282,7 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		var found bool
 		userDN, found = c.findUserDN(l, name)
 		if !found {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 	}
 
@@ -227,26 +290,31 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		// binds user (checking password) before looking-up attributes in user context
 		err = bindUser(l, userDN, passwd)
 		if err != nil {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 	}
 
 	userFilter, ok := c.sanitizedUserQuery(name)
 	if !ok {
-		return "", "", "", "", false, false
+		return nil, false
 	}
 
-	log.Trace("Fetching attributes %q, %q, %q, %q, %q with user filter %q and user DN %q",
-		c.AttributeUsername, c.AttributeName, c.AttributeSurname, c.AttributeMail, c.UserUID, userFilter, userDN)
+	attributes := []string{c.AttributeUsername, c.AttributeName, c.AttributeSurname, c.AttributeMail, c.UserUID}
+	for _, attr := range []string{c.AttributeFullName, c.AttributeAvatar, c.AttributeLocation} {
+		if attr != "" {
+			attributes = append(attributes, attr)
+		}
+	}
+	log.Trace("Fetching attributes %q with user filter %q and user DN %q", attributes, userFilter, userDN)
 
 	search := ldap.NewSearchRequest(
 		userDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, userFilter,
-		[]string{c.AttributeUsername, c.AttributeName, c.AttributeSurname, c.AttributeMail, c.UserUID},
+		attributes,
 		nil)
 	sr, err := l.Search(search)
 	if err != nil {
 		log.Error("LDAP: User search failed: %v", err)
-		return "", "", "", "", false, false
+		return nil, false
 	} else if len(sr.Entries) < 1 {
 		if directBind {
 			log.Trace("LDAP: User filter inhibited user login")
@@ -254,24 +322,39 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 			log.Trace("LDAP: User search failed: 0 entries")
 		}
 
-		return "", "", "", "", false, false
+		return nil, false
 	}
 
-	username := sr.Entries[0].GetAttributeValue(c.AttributeUsername)
-	firstname := sr.Entries[0].GetAttributeValue(c.AttributeName)
-	surname := sr.Entries[0].GetAttributeValue(c.AttributeSurname)
-	mail := sr.Entries[0].GetAttributeValue(c.AttributeMail)
-	uid := sr.Entries[0].GetAttributeValue(c.UserUID)
+	userEntry := sr.Entries[0]
+	result := &searchResult{
+		Username:  userEntry.GetAttributeValue(c.AttributeUsername),
+		FirstName: userEntry.GetAttributeValue(c.AttributeName),
+		Surname:   userEntry.GetAttributeValue(c.AttributeSurname),
+		Mail:      userEntry.GetAttributeValue(c.AttributeMail),
+	}
+	if c.AttributeFullName != "" {
+		result.FullName = userEntry.GetAttributeValue(c.AttributeFullName)
+	}
+	if c.AttributeAvatar != "" {
+		result.Avatar = userEntry.GetAttributeValue(c.AttributeAvatar)
+	}
+	if c.AttributeLocation != "" {
+		result.Location = userEntry.GetAttributeValue(c.AttributeLocation)
+	}
+	uid := userEntry.GetAttributeValue(c.UserUID)
+	if c.UserUID == "dn" {
+		uid = userEntry.DN
+	}
 
 	// Check group membership
 	if c.GroupEnabled {
 		groupFilter, ok := c.sanitizedGroupFilter(c.GroupFilter)
 		if !ok {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 		groupDN, ok := c.sanitizedGroupDN(c.GroupDN)
 		if !ok {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 
 		log.Trace("LDAP: Fetching groups '%v' with filter '%s' and base '%s'", c.GroupMemberUID, groupFilter, groupDN)
@@ -283,38 +366,18 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		srg, err := l.Search(groupSearch)
 		if err != nil {
 			log.Error("LDAP: Group search failed: %v", err)
-			return "", "", "", "", false, false
+			return nil, false
 		} else if len(srg.Entries) < 1 {
 			log.Trace("LDAP: Group search returned no entries")
-			return "", "", "", "", false, false
-		}
-
-		isMember := false
-		if c.UserUID == "dn" {
-			for _, group := range srg.Entries {
-				for _, member := range group.GetAttributeValues(c.GroupMemberUID) {
-					if member == sr.Entries[0].DN {
-						isMember = true
-					}
-				}
-			}
-		} else {
-			for _, group := range srg.Entries {
-				for _, member := range group.GetAttributeValues(c.GroupMemberUID) {
-					if member == uid {
-						isMember = true
-					}
-				}
-			}
+			return nil, false
 		}
 
-		if !isMember {
-			log.Trace("LDAP: Group membership test failed [username: %s, group_member_uid: %s, user_uid: %s", username, c.GroupMemberUID, uid)
-			return "", "", "", "", false, false
+		if !isGroupMember(srg.Entries, c.GroupMemberUID, uid) {
+			log.Trace("LDAP: Group membership test failed [username: %s, group_member_uid: %s, user_uid: %s", result.Username, c.GroupMemberUID, uid)
+			return nil, false
 		}
 	}
 
-	isAdmin := false
 	if len(c.AdminFilter) > 0 {
 		log.Trace("Checking admin with filter '%s' and base '%s'", c.AdminFilter, userDN)
 		search = ldap.NewSearchRequest(
@@ -328,7 +391,31 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		} else if len(sr.Entries) < 1 {
 			log.Trace("LDAP: Admin search returned no entries")
 		} else {
-			isAdmin = true
+			result.IsAdmin = true
+		}
+	}
+
+	// Check admin group membership
+	if c.AdminGroupDN != "" {
+		adminGroupDN, ok := c.sanitizedGroupDN(c.AdminGroupDN)
+		if !ok {
+			return nil, false
+		}
+
+		log.Trace("LDAP: Fetching admin group '%v' with base '%s'", c.GroupMemberUID, adminGroupDN)
+		search = ldap.NewSearchRequest(
+			adminGroupDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false, "(objectClass=*)",
+			[]string{c.GroupMemberUID},
+			nil)
+
+		srg, err := l.Search(search)
+		if err != nil {
+			log.Error("LDAP: Admin group search failed: %v", err)
+		} else {
+			result.HasAdminGroup = true
+			if isGroupMember(srg.Entries, c.GroupMemberUID, uid) {
+				result.IsAdmin = true
+			}
 		}
 	}
 
@@ -336,9 +423,9 @@ func (c *Config) searchEntry(name, passwd string, directBind bool) (string, stri
 		// binds user (checking password) after looking-up attributes in BindDN context
 		err = bindUser(l, userDN, passwd)
 		if err != nil {
-			return "", "", "", "", false, false
+			return nil, false
 		}
 	}
 
-	return username, firstname, surname, mail, isAdmin, true
+	return result, true
 }
@@ -0,0 +1,205 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+	log "unknwon.dev/clog/v2"
+)
+
+const (
+	// defaultTimeout is the timeout of connecting and each request when the
+	// source does not configure one.
+	defaultTimeout = 10 * time.Second
+	// defaultHostRetryInterval is the duration that a failing host is skipped
+	// when the source does not configure one.
+	defaultHostRetryInterval = 30 * time.Second
+)
+
+// endpoint is an address of a host of the LDAP directory.
+type endpoint struct {
+	Host string
+	Port int
+}
+
+func (e endpoint) String() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// endpoints returns hosts of the LDAP directory in failover order. The Host
+// field accepts a comma-separated list of hosts, each of them optionally with
+// its own port.
+func (c *Config) endpoints() []endpoint {
+	var endpoints []endpoint
+	for _, host := range strings.Split(c.Host, ",") {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			continue
+		}
+
+		e := endpoint{Host: host, Port: c.Port}
+		if h, p, err := net.SplitHostPort(host); err == nil {
+			if port, err := strconv.Atoi(p); err == nil {
+				e = endpoint{Host: h, Port: port}
+			}
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints
+}
+
+func (c *Config) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	return defaultTimeout
+}
+
+func (c *Config) hostRetryInterval() time.Duration {
+	if c.HostRetryInterval > 0 {
+		return time.Duration(c.HostRetryInterval) * time.Second
+	}
+	return defaultHostRetryInterval
+}
+
+// poolKey returns the key of pooled connections to the endpoint, connections
+// are only shared by sources with the same security settings.
+func (c *Config) poolKey(e endpoint) string {
+	return fmt.Sprintf("%d|%t|%s", c.SecurityProtocol, c.SkipVerify, e)
+}
+
+// hostHealth keeps track of hosts that are temporarily down.
+type hostHealth struct {
+	lock      sync.Mutex
+	downUntil map[string]time.Time
+}
+
+// MarkDown marks the host down for the duration.
+func (h *hostHealth) MarkDown(addr string, d time.Duration) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.downUntil[addr] = time.Now().Add(d)
+}
+
+// MarkUp marks the host available.
+func (h *hostHealth) MarkUp(addr string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	delete(h.downUntil, addr)
+}
+
+// IsDown returns true if the host has been marked down and the duration has not
+// yet passed.
+func (h *hostHealth) IsDown(addr string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return time.Now().Before(h.downUntil[addr])
+}
+
+// connPool holds idle connections keyed by the pool key.
+type connPool struct {
+	lock sync.Mutex
+	idle map[string][]ldap.Client
+}
+
+// Get returns an idle connection of the key, or nil if there is none.
+func (p *connPool) Get(key string) ldap.Client {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for conns := p.idle[key]; len(conns) > 0; conns = p.idle[key] {
+		conn := conns[len(conns)-1]
+		p.idle[key] = conns[:len(conns)-1]
+		if !conn.IsClosing() {
+			return conn
+		}
+		_ = conn.Close()
+	}
+	return nil
+}
+
+// Put adds the connection to idle connections of the key, or closes it when
+// there are already size idle connections. It returns true if the connection
+// is added.
+func (p *connPool) Put(key string, conn ldap.Client, size int) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.idle[key]) >= size {
+		_ = conn.Close()
+		return false
+	}
+	p.idle[key] = append(p.idle[key], conn)
+	return true
+}
+
+var (
+	health = &hostHealth{downUntil: make(map[string]time.Time)}
+	pool   = &connPool{idle: make(map[string][]ldap.Client)}
+)
+
+// connect returns a connection to the first available host of the LDAP
+// directory, reusing an idle connection when pooling is enabled. Hosts that
+// fail to connect are marked down and skipped until the retry interval passes,
+// but they are still tried as a last resort. Hosts in skip are not tried. It
+// also returns whether the connection is reused from the pool.
+func (c *Config) connect(skip map[endpoint]bool) (_ ldap.Client, _ endpoint, pooled bool, _ error) {
+	var up, down []endpoint
+	for _, e := range c.endpoints() {
+		if skip[e] {
+			continue
+		} else if health.IsDown(e.String()) {
+			down = append(down, e)
+		} else {
+			up = append(up, e)
+		}
+	}
+
+	err := fmt.Errorf("no available host in %q", c.Host)
+	for _, e := range append(up, down...) {
+		if c.PoolSize > 0 {
+			if conn := pool.Get(c.poolKey(e)); conn != nil {
+				return conn, e, true, nil
+			}
+		}
+
+		var conn ldap.Client
+		conn, err = dialHost(c, e)
+		if err != nil {
+			log.Warn("LDAP: Failed to connect to %q, marking it down for %s: %v", e, c.hostRetryInterval(), err)
+			health.MarkDown(e.String(), c.hostRetryInterval())
+			continue
+		}
+		health.MarkUp(e.String())
+		conn.SetTimeout(c.timeout())
+		return conn, e, false, nil
+	}
+	return nil, endpoint{}, false, err
+}
+
+// release returns the connection to the pool when pooling is enabled and the
+// connection is still usable, otherwise it is closed.
+func (c *Config) release(conn ldap.Client, e endpoint) {
+	if c.PoolSize <= 0 || conn.IsClosing() {
+		_ = conn.Close()
+		return
+	}
+
+	// Drop the identity of the last bind, so the next user of the connection
+	// does not inherit it.
+	if err := conn.UnauthenticatedBind(""); err != nil {
+		log.Trace("LDAP: Failed to reset connection to %q: %v", e, err)
+		_ = conn.Close()
+		return
+	}
+	pool.Put(c.poolKey(e), conn, c.PoolSize)
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ldap "github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn is a connection to a fake LDAP directory that has the user "alice"
+// with the password "secret".
+type fakeConn struct {
+	ldap.Client
+	closing bool
+	// Whether to break the connection on the next search.
+	breakOnSearch bool
+	searches      int
+}
+
+func (c *fakeConn) SetTimeout(time.Duration) {}
+
+func (c *fakeConn) IsClosing() bool {
+	return c.closing
+}
+
+func (c *fakeConn) Close() error {
+	c.closing = true
+	return nil
+}
+
+func (c *fakeConn) Bind(username, password string) error {
+	if username != "uid=alice,dc=example,dc=com" || password != "secret" {
+		return ldap.NewError(ldap.LDAPResultInvalidCredentials, errors.New("invalid credentials"))
+	}
+	return nil
+}
+
+func (c *fakeConn) UnauthenticatedBind(string) error {
+	return nil
+}
+
+func (c *fakeConn) Search(*ldap.SearchRequest) (*ldap.SearchResult, error) {
+	c.searches++
+	if c.breakOnSearch {
+		c.closing = true
+		return nil, ldap.NewError(ldap.ErrorNetwork, errors.New("connection closed"))
+	}
+	return &ldap.SearchResult{
+		Entries: []*ldap.Entry{
+			ldap.NewEntry("uid=alice,dc=example,dc=com", map[string][]string{
+				"uid":  {"alice"},
+				"mail": {"alice@example.com"},
+			}),
+		},
+	}, nil
+}
+
+// setMockDialer replaces the dialer with dial, and resets host health and the
+// connection pool for the test.
+func setMockDialer(t *testing.T, dial func(e endpoint) (ldap.Client, error)) {
+	beforeDial, beforeHealth, beforePool := dialHost, health, pool
+	dialHost = func(_ *Config, e endpoint) (ldap.Client, error) {
+		return dial(e)
+	}
+	health = &hostHealth{downUntil: make(map[string]time.Time)}
+	pool = &connPool{idle: make(map[string][]ldap.Client)}
+	t.Cleanup(func() {
+		dialHost, health, pool = beforeDial, beforeHealth, beforePool
+	})
+}
+
+func newTestConfig(host string, poolSize int) *Config {
+	return &Config{
+		Host:              host,
+		Port:              389,
+		UserDN:            "uid=%s,dc=example,dc=com",
+		Filter:            "(uid=%s)",
+		AttributeUsername: "uid",
+		AttributeMail:     "mail",
+		PoolSize:          poolSize,
+	}
+}
+
+func TestConfig_endpoints(t *testing.T) {
+	c := &Config{Host: "ldap1.example.com, ldap2.example.com:1389,,[::1]:636", Port: 389}
+	want := []endpoint{
+		{Host: "ldap1.example.com", Port: 389},
+		{Host: "ldap2.example.com", Port: 1389},
+		{Host: "::1", Port: 636},
+	}
+	assert.Equal(t, want, c.endpoints())
+}
+
+func TestConfig_searchEntry_failover(t *testing.T) {
+	var dialed []string
+	setMockDialer(t, func(e endpoint) (ldap.Client, error) {
+		dialed = append(dialed, e.Host)
+		if e.Host == "ldap1" {
+			return nil, errors.New("connection refused")
+		}
+		return &fakeConn{}, nil
+	})
+
+	c := newTestConfig("ldap1,ldap2", 0)
+	result, ok := c.searchEntry("alice", "secret", true)
+	require.True(t, ok)
+	assert.Equal(t, "alice@example.com", result.Mail)
+	assert.Equal(t, []string{"ldap1", "ldap2"}, dialed)
+	assert.True(t, health.IsDown("ldap1:389"))
+
+	// The host marked down is skipped until the retry interval passes
+	dialed = nil
+	_, ok = c.searchEntry("alice", "secret", true)
+	require.True(t, ok)
+	assert.Equal(t, []string{"ldap2"}, dialed)
+
+	_, ok = c.searchEntry("alice", "wrong", true)
+	assert.False(t, ok)
+
+	t.Run("all hosts down", func(t *testing.T) {
+		setMockDialer(t, func(e endpoint) (ldap.Client, error) {
+			return nil, errors.New("connection refused")
+		})
+		_, ok := c.searchEntry("alice", "secret", true)
+		assert.False(t, ok)
+	})
+}
+
+func TestConfig_searchEntry_brokenConnection(t *testing.T) {
+	conns := map[string]*fakeConn{
+		"ldap1": {breakOnSearch: true},
+		"ldap2": {},
+	}
+	setMockDialer(t, func(e endpoint) (ldap.Client, error) {
+		return conns[e.Host], nil
+	})
+
+	c := newTestConfig("ldap1,ldap2", 0)
+	_, ok := c.searchEntry("alice", "secret", true)
+	require.True(t, ok)
+	assert.Equal(t, 1, conns["ldap1"].searches)
+	assert.Equal(t, 1, conns["ldap2"].searches)
+	assert.True(t, health.IsDown("ldap1:389"))
+}
+
+func TestConfig_searchEntry_pool(t *testing.T) {
+	var numDials int
+	setMockDialer(t, func(e endpoint) (ldap.Client, error) {
+		numDials++
+		return &fakeConn{}, nil
+	})
+
+	c := newTestConfig("ldap1", 2)
+	for i := 0; i < 3; i++ {
+		_, ok := c.searchEntry("alice", "secret", true)
+		require.True(t, ok)
+	}
+	assert.Equal(t, 1, numDials, "connection should be reused")
+
+	// Connections closed while idle are not reused
+	conn := pool.Get(c.poolKey(endpoint{Host: "ldap1", Port: 389}))
+	require.NotNil(t, conn)
+	_ = conn.Close()
+	pool.Put(c.poolKey(endpoint{Host: "ldap1", Port: 389}), conn, c.PoolSize)
+	_, ok := c.searchEntry("alice", "secret", true)
+	require.True(t, ok)
+	assert.Equal(t, 2, numDials)
+
+	t.Run("pooling disabled", func(t *testing.T) {
+		numDials = 0
+		c := newTestConfig("ldap1", 0)
+		for i := 0; i < 2; i++ {
+			_, ok := c.searchEntry("alice", "secret", true)
+			require.True(t, ok)
+		}
+		assert.Equal(t, 2, numDials)
+	})
+}
+
+func TestConnPool_Put(t *testing.T) {
+	p := &connPool{idle: make(map[string][]ldap.Client)}
+	assert.True(t, p.Put("key", &fakeConn{}, 1))
+
+	extra := &fakeConn{}
+	assert.False(t, p.Put("key", extra, 1))
+	assert.True(t, extra.IsClosing(), "connection beyond the pool size should be closed")
+}
@@ -27,14 +27,16 @@ func NewProvider(directBind bool, cfg *Config) auth.Provider {
 // Authenticate queries if login/password is valid against the LDAP directory pool,
 // and returns queried information when succeeded.
 func (p *Provider) Authenticate(login, password string) (*auth.ExternalAccount, error) {
-	username, fn, sn, email, isAdmin, succeed := p.config.searchEntry(login, password, p.directBind)
+	result, succeed := p.config.searchEntry(login, password, p.directBind)
 	if !succeed {
 		return nil, auth.ErrBadCredentials{Args: map[string]any{"login": login}}
 	}
 
+	username := result.Username
 	if username == "" {
 		username = login
 	}
+	email := result.Mail
 	if email == "" {
 		email = fmt.Sprintf("%s@localhost", username)
 	}
@@ -51,13 +53,27 @@ func (p *Provider) Authenticate(login, password string) (*auth.ExternalAccount,
 			return firstname + " " + surname
 		}
 	}
+	fullName := result.FullName
+	if fullName == "" {
+		fullName = composeFullName(result.FirstName, result.Surname, username)
+	}
 
+	sync := p.config.SyncProfile
 	return &auth.ExternalAccount{
-		Login:    login,
-		Name:     username,
-		FullName: composeFullName(fn, sn, username),
-		Email:    email,
-		Admin:    isAdmin,
+		Login:     login,
+		Name:      username,
+		FullName:  fullName,
+		Email:     email,
+		Location:  result.Location,
+		AvatarURL: result.Avatar,
+		Admin:     result.IsAdmin,
+		Sync: auth.ExternalAccountSync{
+			FullName:  sync && (result.FullName != "" || result.FirstName != "" || result.Surname != ""),
+			Email:     sync && result.Mail != "",
+			Location:  sync,
+			AvatarURL: sync,
+			Admin:     result.HasAdminGroup,
+		},
 	}, nil
 }
 
@@ -0,0 +1,245 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/auth"
+)
+
+// Config contains configuration for OpenID Connect authentication.
+//
+// ⚠️ WARNING: Change to the field name must preserve the INI key name for backward compatibility.
+type Config struct {
+	// The URL of the issuer that serves the discovery document at
+	// "/.well-known/openid-configuration", e.g. https://accounts.example.com.
+	Issuer       string
+	ClientID     string `ini:"client_id"`
+	ClientSecret string
+	// The space-separated list of scopes to request in addition to "openid",
+	// e.g. "profile email groups".
+	Scopes string
+	// The claims to be mapped to fields of the user, standard claims are used
+	// when not set.
+	UsernameClaim string
+	EmailClaim    string
+	FullNameClaim string
+	// The claim of the list of groups of the user, default is "groups".
+	GroupsClaim string
+	// The group whose members are promoted as site admins.
+	AdminGroup string
+	SkipVerify bool
+}
+
+// discovery contains the metadata of a provider that is needed for the
+// authorization code flow.
+type discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+func (c *Config) client() *http.Client {
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.SkipVerify},
+		},
+	}
+}
+
+// doJSON sends the request and decodes the JSON response into v.
+func doJSON(client *http.Client, req *http.Request, v any) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func getJSON(ctx context.Context, client *http.Client, url, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(client, req, v)
+}
+
+func (c *Config) discover(ctx context.Context, client *http.Client) (*discovery, error) {
+	issuer := strings.TrimSuffix(c.Issuer, "/")
+	var d discovery
+	err := getJSON(ctx, client, issuer+"/.well-known/openid-configuration", "", &d)
+	if err != nil {
+		return nil, errors.Wrap(err, "get discovery document")
+	}
+
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, errors.Errorf("issuer mismatch: want %q but got %q", issuer, d.Issuer)
+	} else if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("incomplete discovery document")
+	}
+	return &d, nil
+}
+
+func (c *Config) scopes() string {
+	scopes := []string{"openid"}
+	for _, scope := range strings.Fields(c.Scopes) {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return strings.Join(scopes, " ")
+}
+
+// authCodeURL returns the URL of the authorization endpoint to redirect the
+// user to.
+func (c *Config) authCodeURL(d *discovery, redirectURL, state, nonce string) (string, error) {
+	u, err := url.Parse(d.AuthorizationEndpoint)
+	if err != nil {
+		return "", errors.Wrap(err, "parse authorization endpoint")
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", c.ClientID)
+	q.Set("redirect_uri", redirectURL)
+	q.Set("scope", c.scopes())
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	IDToken     string `json:"id_token"`
+}
+
+// exchange exchanges the authorization code for tokens at the token endpoint.
+func (c *Config) exchange(ctx context.Context, client *http.Client, d *discovery, redirectURL, code string) (*tokenResponse, error) {
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {redirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(c.ClientID), url.QueryEscape(c.ClientSecret))
+
+	var token tokenResponse
+	if err = doJSON(client, req, &token); err != nil {
+		return nil, err
+	} else if token.IDToken == "" {
+		return nil, errors.New("no ID token in response")
+	}
+	return &token, nil
+}
+
+func stringClaim(claims map[string]any, name string) string {
+	switch v := claims[name].(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
+
+func boolClaim(claims map[string]any, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+func stringsClaim(claims map[string]any, name string) []string {
+	switch v := claims[name].(type) {
+	case string:
+		return []string{v}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func claimOrDefault(claim, defaultClaim string) string {
+	if claim != "" {
+		return claim
+	}
+	return defaultClaim
+}
+
+// toExternalAccount maps the claims of the user to an external account, where
+// the login is the subject of the user.
+func (c *Config) toExternalAccount(claims map[string]any) (*auth.ExternalAccount, error) {
+	subject := stringClaim(claims, "sub")
+	if subject == "" {
+		return nil, errors.New(`missing claim "sub"`)
+	}
+
+	usernameClaim := claimOrDefault(c.UsernameClaim, "preferred_username")
+	username := stringClaim(claims, usernameClaim)
+	if username == "" {
+		return nil, fmt.Errorf("missing claim %q", usernameClaim)
+	}
+
+	account := &auth.ExternalAccount{
+		Login:         subject,
+		Name:          username,
+		FullName:      stringClaim(claims, claimOrDefault(c.FullNameClaim, "name")),
+		Email:         stringClaim(claims, claimOrDefault(c.EmailClaim, "email")),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		Sync: auth.ExternalAccountSync{
+			FullName: true,
+			Email:    true,
+		},
+	}
+	if c.AdminGroup != "" {
+		for _, group := range stringsClaim(claims, claimOrDefault(c.GroupsClaim, "groups")) {
+			if group == c.AdminGroup {
+				account.Admin = true
+				break
+			}
+		}
+		account.Sync.Admin = true
+	}
+	return account, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/auth"
+)
+
+// Provider contains configuration of an OpenID Connect authentication provider.
+type Provider struct {
+	config *Config
+	// now returns the current time to validate the ID token against.
+	now func() time.Time
+}
+
+// NewProvider creates a new OpenID Connect authentication provider.
+func NewProvider(cfg *Config) auth.Provider {
+	return &Provider{
+		config: cfg,
+		now:    time.Now,
+	}
+}
+
+// Authenticate always returns auth.ErrBadCredentials because users of OpenID
+// Connect providers sign in through the authorization code flow in the
+// browser, see AuthCodeURL and Exchange.
+func (*Provider) Authenticate(login, _ string) (*auth.ExternalAccount, error) {
+	return nil, auth.ErrBadCredentials{Args: map[string]any{"login": login}}
+}
+
+// AuthCodeURL returns the URL of the provider to redirect the user to for
+// authorization. The state and nonce must be unguessable and kept for the
+// callback to the redirect URL.
+func (p *Provider) AuthCodeURL(ctx context.Context, redirectURL, state, nonce string) (string, error) {
+	d, err := p.config.discover(ctx, p.config.client())
+	if err != nil {
+		return "", err
+	}
+	return p.config.authCodeURL(d, redirectURL, state, nonce)
+}
+
+// Exchange exchanges the authorization code for tokens and returns the
+// external account of the user. The ID token must be signed by the provider,
+// issued for the client and contain the nonce of the authorization request.
+func (p *Provider) Exchange(ctx context.Context, redirectURL, code, nonce string) (*auth.ExternalAccount, error) {
+	client := p.config.client()
+	d, err := p.config.discover(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := p.config.exchange(ctx, client, d, redirectURL, code)
+	if err != nil {
+		return nil, errors.Wrap(err, "exchange code")
+	}
+
+	var keys jsonWebKeySet
+	err = getJSON(ctx, client, d.JWKSURI, "", &keys)
+	if err != nil {
+		return nil, errors.Wrap(err, "get JSON web keys")
+	}
+
+	claims, err := verifyIDToken(token.IDToken, &keys, d.Issuer, p.config.ClientID, nonce, p.now())
+	if err != nil {
+		return nil, errors.Wrap(err, "verify ID token")
+	}
+
+	if d.UserinfoEndpoint != "" && token.AccessToken != "" {
+		var userinfo map[string]any
+		err = getJSON(ctx, client, d.UserinfoEndpoint, token.AccessToken, &userinfo)
+		if err != nil {
+			return nil, errors.Wrap(err, "get userinfo")
+		}
+
+		// The userinfo must be of the same user that the ID token was issued for,
+		// see https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse.
+		if stringClaim(userinfo, "sub") != stringClaim(claims, "sub") {
+			return nil, errors.New("subject of userinfo does not match the ID token")
+		}
+		for name, value := range userinfo {
+			claims[name] = value
+		}
+	}
+	return p.config.toExternalAccount(claims)
+}
+
+func (p *Provider) Config() any {
+	return p.config
+}
+
+func (*Provider) HasTLS() bool {
+	return true
+}
+
+func (*Provider) UseTLS() bool {
+	return true
+}
+
+func (p *Provider) SkipTLSVerify() bool {
+	return p.config.SkipVerify
+}
@@ -0,0 +1,325 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/auth"
+)
+
+// stubProvider is an OpenID Connect provider that issues ID tokens with given
+// claims for the authorization code "code".
+type stubProvider struct {
+	*httptest.Server
+	key      *rsa.PrivateKey
+	claims   map[string]any
+	userinfo map[string]any
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &stubProvider{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize",
+			"token_endpoint":         p.URL + "/token",
+			"userinfo_endpoint":      p.URL + "/userinfo",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{
+				{
+					"kty": "RSA",
+					"kid": "key-1",
+					"use": "sig",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				},
+			},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, _ := r.BasicAuth()
+		if clientID != "gogs" || clientSecret != "secret" || r.PostFormValue("code") != "code" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     p.sign(t, p.claims),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(p.userinfo)
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *stubProvider) sign(t *testing.T, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "kid": "key-1", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestProvider(t *testing.T) {
+	stub := newStubProvider(t)
+	now := time.Now()
+	newProvider := func(cfg Config) *Provider {
+		cfg.Issuer = stub.URL
+		cfg.ClientID = "gogs"
+		cfg.ClientSecret = "secret"
+		p := NewProvider(&cfg).(*Provider)
+		p.now = func() time.Time { return now }
+		return p
+	}
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss":   stub.URL,
+			"sub":   "248289761001",
+			"aud":   "gogs",
+			"exp":   now.Add(time.Hour).Unix(),
+			"iat":   now.Unix(),
+			"nonce": "nonce",
+		}
+	}
+	stub.userinfo = map[string]any{
+		"sub":                "248289761001",
+		"preferred_username": "alice",
+		"name":               "Alice",
+		"email":              "alice@example.com",
+		"email_verified":     true,
+		"groups":             []string{"developers", "gogs-admins"},
+	}
+	const redirectURL = "https://gogs.example.com/user/login/oidc/1/callback"
+
+	t.Run("AuthCodeURL", func(t *testing.T) {
+		p := newProvider(Config{Scopes: "openid profile email"})
+		got, err := p.AuthCodeURL(context.Background(), redirectURL, "state", "nonce")
+		require.NoError(t, err)
+
+		u, err := url.Parse(got)
+		require.NoError(t, err)
+		assert.Equal(t, stub.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+		want := url.Values{
+			"response_type": {"code"},
+			"client_id":     {"gogs"},
+			"redirect_uri":  {redirectURL},
+			"scope":         {"openid profile email"},
+			"state":         {"state"},
+			"nonce":         {"nonce"},
+		}
+		assert.Equal(t, want, u.Query())
+	})
+
+	t.Run("Exchange", func(t *testing.T) {
+		stub.claims = validClaims()
+		p := newProvider(Config{AdminGroup: "gogs-admins"})
+		got, err := p.Exchange(context.Background(), redirectURL, "code", "nonce")
+		require.NoError(t, err)
+
+		want := &auth.ExternalAccount{
+			Login:         "248289761001",
+			Name:          "alice",
+			FullName:      "Alice",
+			Email:         "alice@example.com",
+			EmailVerified: true,
+			Admin:         true,
+			Sync: auth.ExternalAccountSync{
+				FullName: true,
+				Email:    true,
+				Admin:    true,
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("Exchange with invalid ID token", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			claims  func(claims map[string]any)
+			nonce   string
+			wantErr string
+		}{
+			{
+				name:    "nonce mismatch",
+				claims:  func(map[string]any) {},
+				nonce:   "replayed",
+				wantErr: "nonce mismatch",
+			},
+			{
+				name:    "expired",
+				claims:  func(claims map[string]any) { claims["exp"] = now.Add(-time.Hour).Unix() },
+				nonce:   "nonce",
+				wantErr: "token expired",
+			},
+			{
+				name:    "other audience",
+				claims:  func(claims map[string]any) { claims["aud"] = "other" },
+				nonce:   "nonce",
+				wantErr: "not issued for the client",
+			},
+			{
+				name:    "other issuer",
+				claims:  func(claims map[string]any) { claims["iss"] = "https://evil.example.com" },
+				nonce:   "nonce",
+				wantErr: "unexpected issuer",
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				stub.claims = validClaims()
+				test.claims(stub.claims)
+				_, err := newProvider(Config{}).Exchange(context.Background(), redirectURL, "code", test.nonce)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+			})
+		}
+	})
+
+	t.Run("Exchange with mismatched userinfo", func(t *testing.T) {
+		stub.claims = validClaims()
+		stub.claims["sub"] = "someone-else"
+		_, err := newProvider(Config{}).Exchange(context.Background(), redirectURL, "code", "nonce")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "subject of userinfo does not match")
+	})
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub := newStubProvider(t)
+	key := jsonWebKey{
+		Kty: "RSA",
+		Kid: "key-1",
+		N:   base64.RawURLEncoding.EncodeToString(stub.key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(stub.key.E)).Bytes()),
+	}
+	keys := &jsonWebKeySet{Keys: []jsonWebKey{key}}
+	now := time.Now()
+	raw := stub.sign(t, map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "1",
+		"aud":   []string{"gogs"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"nonce": "nonce",
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		claims, err := verifyIDToken(raw, keys, "https://issuer.example.com", "gogs", "nonce", now)
+		require.NoError(t, err)
+		assert.Equal(t, "1", stringClaim(claims, "sub"))
+	})
+
+	t.Run("tampered claims", func(t *testing.T) {
+		parts := strings.Split(raw, ".")
+		other := strings.Split(stub.sign(t, map[string]any{"sub": "2"}), ".")
+		tampered := parts[0] + "." + other[1] + "." + parts[2]
+		_, err := verifyIDToken(tampered, keys, "https://issuer.example.com", "gogs", "nonce", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "verify signature")
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+		unsigned := header + "." + strings.Split(raw, ".")[1] + "."
+		_, err := verifyIDToken(unsigned, keys, "https://issuer.example.com", "gogs", "nonce", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported algorithm "none"`)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		other := &jsonWebKeySet{Keys: []jsonWebKey{{Kty: "RSA", Kid: "key-2", N: key.N, E: key.E}}}
+		_, err := verifyIDToken(raw, other, "https://issuer.example.com", "gogs", "nonce", now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no unique signing key")
+	})
+}
+
+func TestConfig_toExternalAccount(t *testing.T) {
+	claims := map[string]any{
+		"sub":            "1",
+		"login":          "bob",
+		"mail":           "bob@example.com",
+		"display_name":   "Bob",
+		"email_verified": "true",
+		"roles":          "gogs-admins",
+	}
+
+	t.Run("custom claims", func(t *testing.T) {
+		cfg := &Config{
+			UsernameClaim: "login",
+			EmailClaim:    "mail",
+			FullNameClaim: "display_name",
+			GroupsClaim:   "roles",
+			AdminGroup:    "gogs-admins",
+		}
+		got, err := cfg.toExternalAccount(claims)
+		require.NoError(t, err)
+
+		want := &auth.ExternalAccount{
+			Login:         "1",
+			Name:          "bob",
+			FullName:      "Bob",
+			Email:         "bob@example.com",
+			EmailVerified: true,
+			Admin:         true,
+			Sync: auth.ExternalAccountSync{
+				FullName: true,
+				Email:    true,
+				Admin:    true,
+			},
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("not in admin group", func(t *testing.T) {
+		cfg := &Config{UsernameClaim: "login", AdminGroup: "other"}
+		got, err := cfg.toExternalAccount(claims)
+		require.NoError(t, err)
+		assert.False(t, got.Admin)
+		assert.True(t, got.Sync.Admin)
+	})
+
+	t.Run("missing username", func(t *testing.T) {
+		_, err := (&Config{}).toExternalAccount(claims)
+		assert.EqualError(t, err, `missing claim "preferred_username"`)
+	})
+}
@@ -15,6 +15,8 @@ import (
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/lfsutil"
+	"gogs.io/gogs/internal/route/lfs"
 )
 
 var (
@@ -32,6 +34,8 @@ to make automatic initialization process more smoothly`,
 			subcmdRewriteAuthorizedKeys,
 			subcmdSyncRepositoryHooks,
 			subcmdReinitMissingRepositories,
+			subcmdReconcileIssueCounts,
+			subcmdMigrateLFSStorage,
 		},
 	}
 
@@ -131,6 +135,32 @@ to make automatic initialization process more smoothly`,
 			stringFlag("config, c", "", "Custom configuration file path"),
 		},
 	}
+
+	subcmdReconcileIssueCounts = cli.Command{
+		Name:  "reconcile-issue-counts",
+		Usage: "Recompute numbers of issues and pull requests of all repositories",
+		Action: adminDashboardOperation(
+			db.ReconcileRepoIssueCounts,
+			"Numbers of issues and pull requests of all repositories have been recomputed successfully",
+		),
+		Flags: []cli.Flag{
+			stringFlag("config, c", "", "Custom configuration file path"),
+		},
+	}
+
+	subcmdMigrateLFSStorage = cli.Command{
+		Name:  "migrate-lfs-storage",
+		Usage: "Copy LFS objects from one storage backend to another",
+		Description: `Copy LFS objects that are stored in the source storage backend to the
+destination and serve them from the destination afterwards. Objects are left in
+the source storage backend and can be removed once the migration is verified.`,
+		Action: runMigrateLFSStorage,
+		Flags: []cli.Flag{
+			stringFlag("from", "local", "Source storage backend"),
+			stringFlag("to", "", "Destination storage backend, default is [lfs] STORAGE"),
+			stringFlag("config, c", "", "Custom configuration file path"),
+		},
+	}
 )
 
 func runCreateUser(c *cli.Context) error {
@@ -170,6 +200,40 @@ func runCreateUser(c *cli.Context) error {
 	return nil
 }
 
+func runMigrateLFSStorage(c *cli.Context) error {
+	err := conf.Init(c.String("config"))
+	if err != nil {
+		return errors.Wrap(err, "init configuration")
+	}
+	conf.InitLogging(true)
+
+	if _, err = db.SetEngine(); err != nil {
+		return errors.Wrap(err, "set engine")
+	}
+
+	to := c.String("to")
+	if to == "" {
+		to = conf.LFS.Storage
+	}
+	storagers := lfs.Storagers()
+	src := storagers[lfsutil.Storage(c.String("from"))]
+	if src == nil {
+		return errors.Errorf("unknown source storage %q", c.String("from"))
+	}
+	dst := storagers[lfsutil.Storage(to)]
+	if dst == nil {
+		return errors.Errorf("unknown destination storage %q", to)
+	}
+
+	migrated, err := db.MigrateLFSObjects(context.Background(), src, dst)
+	if err != nil {
+		return errors.Wrapf(err, "migrate LFS objects (%d migrated)", migrated)
+	}
+
+	fmt.Printf("%d LFS objects have been migrated from %q to %q successfully\n", migrated, src.Storage(), dst.Storage())
+	return nil
+}
+
 func adminDashboardOperation(operation func() error, successMessage string) func(*cli.Context) error {
 	return func(c *cli.Context) error {
 		err := conf.Init(c.String("config"))
@@ -25,6 +25,7 @@ import (
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/email"
 	"gogs.io/gogs/internal/httplib"
+	"gogs.io/gogs/internal/tool"
 )
 
 var (
@@ -70,6 +71,8 @@ func runHookPreReceive(c *cli.Context) error {
 
 	isWiki := strings.Contains(os.Getenv(db.ENV_REPO_CUSTOM_HOOKS_PATH), ".wiki.git/")
 
+	var repo *db.Repository
+	var newCommitIDs []string
 	buf := bytes.NewBuffer(nil)
 	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
@@ -87,9 +90,40 @@ func runHookPreReceive(c *cli.Context) error {
 		oldCommitID := string(fields[0])
 		newCommitID := string(fields[1])
 		branchName := git.RefShortName(string(fields[2]))
+		repoID := com.StrTo(os.Getenv(db.ENV_REPO_ID)).MustInt64()
+		newCommitIDs = append(newCommitIDs, newCommitID)
+
+		// Branch name pattern
+		if strings.HasPrefix(string(fields[2]), git.RefsHeads) {
+			if repo == nil {
+				var err error
+				repo, err = db.GetRepositoryByID(repoID)
+				if err != nil {
+					fail("Internal error", "GetRepositoryByID [repo_id: %d]: %v", repoID, err)
+				}
+			}
+
+			err := repo.CheckPushBranchName(branchName, oldCommitID, newCommitID)
+			if err != nil {
+				if db.IsErrBranchNameNotAllowed(err) {
+					fail(fmt.Sprintf("Branch name '%s' does not match the required pattern '%s'", branchName, err.(db.ErrBranchNameNotAllowed).Pattern()), "")
+				}
+				fail("Internal error", "CheckPushBranchName [repo_id: %d, branch: %s]: %v", repoID, branchName, err)
+			}
+
+			// Commit message pattern
+			repoPath := db.RepoPath(os.Getenv(db.ENV_REPO_OWNER_NAME), os.Getenv(db.ENV_REPO_NAME))
+			err = repo.CheckPushCommitMessages(repoPath, oldCommitID, newCommitID)
+			if err != nil {
+				if db.IsErrCommitMessageNotAllowed(err) {
+					errNotAllowed := err.(db.ErrCommitMessageNotAllowed)
+					fail(fmt.Sprintf("Commit %s does not match the required commit message pattern '%s': %s", errNotAllowed.CommitID(), errNotAllowed.Pattern(), errNotAllowed.Subject()), "")
+				}
+				fail("Internal error", "CheckPushCommitMessages [repo_id: %d, branch: %s]: %v", repoID, branchName, err)
+			}
+		}
 
 		// Branch protection
-		repoID := com.StrTo(os.Getenv(db.ENV_REPO_ID)).MustInt64()
 		protectBranch, err := db.GetProtectBranchOfRepoByName(repoID, branchName)
 		if err != nil {
 			if db.IsErrBranchNotExist(err) {
@@ -101,6 +135,13 @@ func runHookPreReceive(c *cli.Context) error {
 			continue
 		}
 
+		// The first push to an empty repository creates its branches, which cannot
+		// be done through pull requests, e.g. when the default branch is protected
+		// on creation.
+		if repo != nil && repo.IsBare {
+			continue
+		}
+
 		// Whitelist users can bypass require pull request check
 		bypassRequirePullRequest := false
 
@@ -125,13 +166,50 @@ func runHookPreReceive(c *cli.Context) error {
 		}
 
 		// Check force push
+		repoPath := db.RepoPath(os.Getenv(db.ENV_REPO_OWNER_NAME), os.Getenv(db.ENV_REPO_NAME))
 		output, err := git.NewCommand("rev-list", "--max-count=1", oldCommitID, "^"+newCommitID).
-			RunInDir(db.RepoPath(os.Getenv(db.ENV_REPO_OWNER_NAME), os.Getenv(db.ENV_REPO_NAME)))
+			RunInDir(repoPath)
 		if err != nil {
 			fail("Internal error", "Failed to detect force push: %v", err)
 		} else if len(output) > 0 {
 			fail(fmt.Sprintf("Branch '%s' is protected from force push", branchName), "")
 		}
+
+		// Check merge commits
+		if protectBranch.RequireLinearHistory {
+			hasMergeCommits, err := db.HasMergeCommits(repoPath, oldCommitID, newCommitID)
+			if err != nil {
+				fail("Internal error", "Failed to detect merge commits: %v", err)
+			} else if hasMergeCommits {
+				fail(fmt.Sprintf("Branch '%s' requires linear history and merge commits are not allowed", branchName), "")
+			}
+		}
+	}
+
+	// Push limits
+	if len(newCommitIDs) > 0 {
+		repoID := com.StrTo(os.Getenv(db.ENV_REPO_ID)).MustInt64()
+		if repo == nil {
+			var err error
+			repo, err = db.GetRepositoryByID(repoID)
+			if err != nil {
+				fail("Internal error", "GetRepositoryByID [repo_id: %d]: %v", repoID, err)
+			}
+		}
+
+		repoPath := db.RepoPath(os.Getenv(db.ENV_REPO_OWNER_NAME), os.Getenv(db.ENV_REPO_NAME))
+		err := repo.CheckPushObjects(repoPath, newCommitIDs...)
+		if err != nil {
+			switch err := err.(type) {
+			case db.ErrPushFileBlocked:
+				fail(fmt.Sprintf("File '%s' (%s) matches the blocked file pattern '%s'", err.Path(), err.ObjectID(), err.Pattern()), "")
+			case db.ErrPushFileTooLarge:
+				fail(fmt.Sprintf("File '%s' (%s) is %s and exceeds the maximum file size of %s", err.Path(), err.ObjectID(), tool.FileSize(err.Size()), tool.FileSize(err.Limit())), "")
+			case db.ErrPushTooLarge:
+				fail(fmt.Sprintf("Push is %s and exceeds the maximum push size of %s, the largest file is '%s'", tool.FileSize(err.Size()), tool.FileSize(err.Limit()), err.Path()), "")
+			}
+			fail("Internal error", "CheckPushObjects [repo_id: %d]: %v", repoID, err)
+		}
 	}
 
 	customHooksPath := filepath.Join(os.Getenv(db.ENV_REPO_CUSTOM_HOOKS_PATH), "pre-receive")
@@ -198,6 +276,9 @@ func runHookPostReceive(c *cli.Context) error {
 	// Post-receive hook does more than just gather Git information,
 	// so we need to setup additional services for email notifications.
 	email.NewContext()
+	if err := email.LoadTemplateOverrides(conf.Email.TemplatePath); err != nil {
+		log.Warn("Failed to load mail template overrides, using built-in templates: %v", err)
+	}
 
 	isWiki := strings.Contains(os.Getenv(db.ENV_REPO_CUSTOM_HOOKS_PATH), ".wiki.git/")
 
@@ -235,6 +316,7 @@ func runHookPostReceive(c *cli.Context) error {
 		q.Add("branch", git.RefShortName(options.FullRefspec))
 		q.Add("secret", os.Getenv(db.ENV_REPO_OWNER_SALT_MD5))
 		q.Add("pusher", os.Getenv(db.ENV_AUTH_USER_ID))
+		q.Add("old", options.OldCommitID)
 		reqURL := fmt.Sprintf("%s%s/%s/tasks/trigger?%s", conf.Server.LocalRootURL, options.RepoUserName, options.RepoName, q.Encode())
 		log.Trace("Trigger task: %s", reqURL)
 
@@ -216,6 +216,9 @@ func runServ(c *cli.Context) error {
 					Private: repo.IsPrivate,
 				},
 			)
+			if !repo.HasBaseAccess(ctx, user.ID) {
+				mode = db.AccessModeNone
+			}
 			if mode < requestMode {
 				clientMessage := _ACCESS_DENIED_MESSAGE
 				if mode >= db.AccessModeRead {
@@ -12,8 +12,10 @@ import (
 	"net/http"
 	"net/http/fcgi"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
 	"github.com/go-macaron/binding"
 	"github.com/go-macaron/cache"
@@ -35,6 +37,7 @@ import (
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/metrics"
 	"gogs.io/gogs/internal/osutil"
 	"gogs.io/gogs/internal/route"
 	"gogs.io/gogs/internal/route/admin"
@@ -44,6 +47,7 @@ import (
 	"gogs.io/gogs/internal/route/org"
 	"gogs.io/gogs/internal/route/repo"
 	"gogs.io/gogs/internal/route/user"
+	"gogs.io/gogs/internal/ssh"
 	"gogs.io/gogs/internal/template"
 	"gogs.io/gogs/public"
 	"gogs.io/gogs/templates"
@@ -64,10 +68,12 @@ and it takes care of all the other things for you`,
 // newMacaron initializes Macaron instance.
 func newMacaron() *macaron.Macaron {
 	m := macaron.New()
+	m.Use(app.RequestID())
 	if !conf.Server.DisableRouterLog {
 		m.Use(macaron.Logger())
 	}
 	m.Use(macaron.Recovery())
+	m.Use(app.MaxBodySize())
 	if conf.Server.EnableGzip {
 		m.Use(gzip.Gziper())
 	}
@@ -111,6 +117,20 @@ func newMacaron() *macaron.Macaron {
 			SkipLogging: conf.Server.DisableRouterLog,
 		},
 	))
+	if conf.Markdown.CustomEmojiPath != "" {
+		m.Use(macaron.Static(
+			conf.Markdown.CustomEmojiPath,
+			macaron.StaticOptions{
+				ETag:        true,
+				Prefix:      conf.CustomEmojiPathPrefix,
+				SkipLogging: conf.Server.DisableRouterLog,
+			},
+		))
+	}
+
+	if conf.Metrics.Enabled {
+		m.Use(app.RequestMetrics())
+	}
 
 	renderOpt := macaron.RenderOptions{
 		Directory:         filepath.Join(conf.WorkDir(), "templates"),
@@ -168,6 +188,12 @@ func runWeb(c *cli.Context) error {
 		log.Fatal("Failed to initialize application: %v", err)
 	}
 
+	err = template.LoadSnippets(conf.UI.Snippets.Path)
+	if err != nil {
+		log.Fatal("Failed to load custom snippets: %v", err)
+	}
+	go reloadOnSignal()
+
 	m := newMacaron()
 
 	reqSignIn := context.Toggle(&context.ToggleOptions{SignInRequired: true})
@@ -191,6 +217,7 @@ func runWeb(c *cli.Context) error {
 		m.Combo("/install", route.InstallInit).Get(route.Install).
 			Post(bindIgnErr(form.Install{}), route.InstallPost)
 		m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
+		m.Combo("/notifications/unsubscribe").Get(repo.NotificationUnsubscribe).Post(repo.NotificationUnsubscribePost)
 
 		// ***** START: User *****
 		m.Group("/user", func() {
@@ -199,6 +226,8 @@ func runWeb(c *cli.Context) error {
 					Post(bindIgnErr(form.SignIn{}), user.LoginPost)
 				m.Combo("/two_factor").Get(user.LoginTwoFactor).Post(user.LoginTwoFactorPost)
 				m.Combo("/two_factor_recovery_code").Get(user.LoginTwoFactorRecoveryCode).Post(user.LoginTwoFactorRecoveryCodePost)
+				m.Get("/oidc/:id", user.OIDCLogin)
+				m.Get("/oidc/:id/callback", user.OIDCCallback)
 			})
 
 			m.Get("/sign_up", user.SignUp)
@@ -216,6 +245,7 @@ func runWeb(c *cli.Context) error {
 			m.Combo("/email").Get(user.SettingsEmails).
 				Post(bindIgnErr(form.AddEmail{}), user.SettingsEmailPost)
 			m.Post("/email/delete", user.DeleteEmail)
+			m.Post("/email/privacy", bindIgnErr(form.EmailPrivacy{}), user.SettingsEmailPrivacyPost)
 			m.Get("/password", user.SettingsPassword)
 			m.Post("/password", bindIgnErr(form.ChangePassword{}), user.SettingsPasswordPost)
 			m.Combo("/ssh").Get(user.SettingsSSHKeys).
@@ -232,14 +262,25 @@ func runWeb(c *cli.Context) error {
 			m.Group("/repositories", func() {
 				m.Get("", user.SettingsRepos)
 				m.Post("/leave", user.SettingsLeaveRepo)
+				m.Post("/invitations/accept", user.SettingsAcceptRepoInvitation)
+				m.Post("/invitations/decline", user.SettingsDeclineRepoInvitation)
+				m.Post("/auto_watch", user.SettingsAutoWatchPost)
+				m.Post("/auto_assign", bindIgnErr(form.AutoAssign{}), user.SettingsAutoAssignPost)
 			})
 			m.Group("/organizations", func() {
 				m.Get("", user.SettingsOrganizations)
 				m.Post("/leave", user.SettingsLeaveOrganization)
+				m.Post("/invitations/accept", user.SettingsAcceptOrgInvitation)
+				m.Post("/invitations/decline", user.SettingsDeclineOrgInvitation)
 			})
 			m.Combo("/applications").Get(user.SettingsApplications).
 				Post(bindIgnErr(form.NewAccessToken{}), user.SettingsApplicationsPost)
 			m.Post("/applications/delete", user.SettingsDeleteApplication)
+			m.Group("/sessions", func() {
+				m.Get("", user.SettingsSessions)
+				m.Post("/delete", user.SettingsDeleteSession)
+				m.Post("/delete_all", user.SettingsDeleteSessions)
+			})
 			m.Route("/delete", "GET,POST", user.SettingsDelete)
 		}, reqSignIn, func(c *context.Context) {
 			c.Data["PageIsUserSettings"] = true
@@ -252,6 +293,7 @@ func runWeb(c *cli.Context) error {
 			m.Get("/forget_password", user.ForgotPasswd)
 			m.Post("/forget_password", user.ForgotPasswdPost)
 			m.Post("/logout", user.SignOut)
+			m.Get("/export", reqSignIn, user.SettingsExport)
 		})
 		// ***** END: User *****
 
@@ -354,11 +396,13 @@ func runWeb(c *cli.Context) error {
 				m.Post("/slack/new", bindIgnErr(form.NewSlackHook{}), repo.WebhooksSlackNewPost)
 				m.Post("/discord/new", bindIgnErr(form.NewDiscordHook{}), repo.WebhooksDiscordNewPost)
 				m.Post("/dingtalk/new", bindIgnErr(form.NewDingtalkHook{}), repo.WebhooksDingtalkNewPost)
+				m.Post("/msteams/new", bindIgnErr(form.NewMSTeamsHook{}), repo.WebhooksMSTeamsNewPost)
 				m.Get("/:id", repo.WebhooksEdit)
 				m.Post("/gogs/:id", bindIgnErr(form.NewWebhook{}), repo.WebhooksEditPost)
 				m.Post("/slack/:id", bindIgnErr(form.NewSlackHook{}), repo.WebhooksSlackEditPost)
 				m.Post("/discord/:id", bindIgnErr(form.NewDiscordHook{}), repo.WebhooksDiscordEditPost)
 				m.Post("/dingtalk/:id", bindIgnErr(form.NewDingtalkHook{}), repo.WebhooksDingtalkEditPost)
+				m.Post("/msteams/:id", bindIgnErr(form.NewMSTeamsHook{}), repo.WebhooksMSTeamsEditPost)
 			}, repo.InjectOrgRepoContext())
 		}
 
@@ -406,6 +450,7 @@ func runWeb(c *cli.Context) error {
 				})
 
 				m.Route("/invitations/new", "GET,POST", org.Invitation)
+				m.Post("/invitations/delete", org.DeleteInvitation)
 			}, context.OrgAssignment(true, true))
 		}, reqSignIn)
 		// ***** END: Organization *****
@@ -431,10 +476,14 @@ func runWeb(c *cli.Context) error {
 					m.Combo("").Get(repo.SettingsCollaboration).Post(repo.SettingsCollaborationPost)
 					m.Post("/access_mode", repo.ChangeCollaborationAccessMode)
 					m.Post("/delete", repo.DeleteCollaboration)
+					m.Post("/invitations/delete", repo.DeleteCollaboratorInvitation)
 				})
 				m.Group("/branches", func() {
 					m.Get("", repo.SettingsBranches)
 					m.Post("/default_branch", repo.UpdateDefaultBranch)
+					m.Post("/name_pattern", repo.UpdateBranchNamePattern)
+					m.Post("/commit_message_pattern", repo.UpdateCommitMessagePattern)
+					m.Post("/push_limits", repo.UpdatePushLimits)
 					m.Combo("/*").Get(repo.SettingsProtectedBranch).
 						Post(bindIgnErr(form.ProtectBranch{}), repo.SettingsProtectedBranchPost)
 				}, func(c *context.Context) {
@@ -464,6 +513,12 @@ func runWeb(c *cli.Context) error {
 						Post(bindIgnErr(form.AddSSHKey{}), repo.SettingsDeployKeysPost)
 					m.Post("/delete", repo.DeleteDeployKey)
 				})
+
+				m.Group("/notifications", func() {
+					m.Combo("").Get(repo.SettingsNotifications).
+						Post(bindIgnErr(form.NotificationRecipient{}), repo.SettingsNotificationsPost)
+					m.Post("/delete", repo.DeleteNotificationRecipient)
+				})
 			}, func(c *context.Context) {
 				c.Data["PageIsSettings"] = true
 			})
@@ -480,7 +535,7 @@ func runWeb(c *cli.Context) error {
 			// FIXME: should use different URLs but mostly same logic for comments of issue and pull reuqest.
 			// So they can apply their own enable/disable logic on routers.
 			m.Group("/issues", func() {
-				m.Combo("/new", repo.MustEnableIssues).Get(context.RepoRef(), repo.NewIssue).
+				m.Combo("/new", repo.MustEnableIssues, repo.MustAllowCreateIssues).Get(context.RepoRef(), repo.NewIssue).
 					Post(bindIgnErr(form.NewIssue{}), repo.NewIssuePost)
 
 				m.Group("/:index", func() {
@@ -532,7 +587,7 @@ func runWeb(c *cli.Context) error {
 				m.Post("/delete", repo.DeleteRelease)
 				m.Get("/edit/*", repo.EditRelease)
 				m.Post("/edit/*", bindIgnErr(form.EditRelease{}), repo.EditReleasePost)
-			}, repo.MustBeNotBare, reqRepoWriter, func(c *context.Context) {
+			}, repo.MustBeNotBare, repo.MustEnableReleases, reqRepoWriter, func(c *context.Context) {
 				c.Data["PageIsViewFiles"] = true
 			})
 
@@ -542,6 +597,7 @@ func runWeb(c *cli.Context) error {
 			// which should be /org1/test-repo/compare/master...develop
 			m.Combo("/compare/*", repo.MustAllowPulls).Get(repo.CompareAndPullRequest).
 				Post(bindIgnErr(form.NewIssue{}), repo.CompareAndPullRequestPost)
+			m.Combo("/cla", repo.MustAllowPulls, repo.MustRequireCLA).Get(repo.CLA).Post(repo.CLAPost)
 
 			m.Group("", func() {
 				m.Combo("/_edit/*").Get(repo.EditFile).
@@ -575,7 +631,8 @@ func runWeb(c *cli.Context) error {
 
 		m.Group("/:username/:reponame", func() {
 			m.Group("", func() {
-				m.Get("/releases", repo.MustBeNotBare, repo.Releases)
+				m.Get("/releases", repo.MustBeNotBare, repo.MustEnableReleases, repo.Releases)
+				m.Get("/releases/checksums/*", repo.MustBeNotBare, repo.MustEnableReleases, repo.ReleaseChecksums)
 				m.Get("/pulls", repo.RetrieveLabels, repo.Pulls)
 				m.Get("/pulls/:index", repo.ViewPull)
 			}, context.RepoRef())
@@ -604,6 +661,13 @@ func runWeb(c *cli.Context) error {
 				m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 				m.Get("/files", context.RepoRef(), repo.ViewPullFiles)
 				m.Post("/merge", reqRepoWriter, repo.MergePullRequest)
+				m.Post("/update_branch", reqRepoWriter, repo.UpdatePullBranch)
+				m.Post("/auto_merge", reqRepoWriter, repo.EnableAutoMergePull)
+				m.Post("/auto_merge/cancel", reqRepoWriter, repo.CancelAutoMergePull)
+				m.Post("/approve", reqRepoWriter, repo.ApprovePull)
+				m.Post("/unapprove", reqRepoWriter, repo.UnapprovePull)
+				m.Post("/linked_issues", reqRepoWriter, repo.LinkPullIssue)
+				m.Post("/linked_issues/delete", reqRepoWriter, repo.UnlinkPullIssue)
 			}, repo.MustAllowPulls)
 
 			m.Group("", func() {
@@ -675,12 +739,17 @@ func runWeb(c *cli.Context) error {
 
 	m.Group("/-", func() {
 		m.Get("/metrics", app.MetricsFilter(), promhttp.Handler()) // "/-/metrics"
-
-		m.Group("/api", func() {
-			m.Post("/sanitize_ipynb", app.SanitizeIpynb()) // "/-/api/sanitize_ipynb"
-		})
 	})
 
+	// *********************************
+	// ----- Application metrics -----
+	// *********************************
+
+	if conf.Metrics.Enabled {
+		metrics.Registry.MustRegister(db.NewStatsCollector())
+		m.Get("/metrics", app.MetricsTokenFilter(), metrics.Handler())
+	}
+
 	// **********************
 	// ----- robots.txt -----
 	// **********************
@@ -778,3 +847,25 @@ func runWeb(c *cli.Context) error {
 
 	return nil
 }
+
+// reloadOnSignal reloads custom snippets and host keys of the builtin SSH
+// server whenever the process receives SIGHUP.
+func reloadOnSignal() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	for range sigs {
+		if err := template.LoadSnippets(conf.UI.Snippets.Path); err != nil {
+			log.Error("Failed to reload custom snippets: %v", err)
+		} else {
+			log.Info("Custom snippets reloaded")
+		}
+
+		if conf.SSH.StartBuiltinServer {
+			if err := ssh.ReloadHostKeys(); err != nil {
+				log.Error("Failed to reload SSH host keys: %v", err)
+			} else {
+				log.Info("SSH host keys reloaded")
+			}
+		}
+	}
+}
@@ -165,11 +165,25 @@ func Init(customConf string) error {
 	// *******************************
 
 	Repository.Root = filepath.Join(HomeDir(), "gogs-repositories")
+	Repository.InitTemplatesPath = filepath.Join(CustomDir(), "conf")
 	if err = File.Section("repository").MapTo(&Repository); err != nil {
 		return errors.Wrap(err, "mapping [repository] section")
 	}
 	Repository.Root = ensureAbs(Repository.Root)
+	Repository.InitTemplatesPath = ensureAbs(Repository.InitTemplatesPath)
 	Repository.Upload.TempPath = ensureAbs(Repository.Upload.TempPath)
+	Repository.Archive.CachePath = ensureAbs(Repository.Archive.CachePath)
+	Repository.Deletion.ExportPath = ensureAbs(Repository.Deletion.ExportPath)
+	switch Repository.Deletion.CrossReferences {
+	case RepoDeletionCrossReferencesKeep, RepoDeletionCrossReferencesTombstone:
+	default:
+		return errors.Errorf("unsupported mode of cross-references of deleted repositories %q", Repository.Deletion.CrossReferences)
+	}
+	for _, baseURL := range []*string{&Repository.CloneURL.HTTPBaseURL, &Repository.CloneURL.AnonymousHTTPBaseURL} {
+		if *baseURL != "" && !strings.HasSuffix(*baseURL, "/") {
+			*baseURL += "/"
+		}
+	}
 
 	// *****************************
 	// ----- Database settings -----
@@ -215,6 +229,9 @@ func Init(customConf string) error {
 		}
 		Email.FromEmail = parsed.Address
 	}
+	if Email.TemplatePath != "" {
+		Email.TemplatePath = ensureAbs(Email.TemplatePath)
+	}
 
 	// ***********************************
 	// ----- Authentication settings -----
@@ -232,6 +249,23 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [user] section")
 	}
 
+	switch User.DeletionPolicy {
+	case UserDeletionPolicyGhost, UserDeletionPolicyDelete:
+	default:
+		return errors.Errorf("unsupported user deletion policy %q", User.DeletionPolicy)
+	}
+	if User.NoReplyDomain == "" {
+		User.NoReplyDomain = "noreply." + Server.Domain
+	}
+
+	// *********************************
+	// ----- Organization settings -----
+	// *********************************
+
+	if err = File.Section("organization").MapTo(&Organization); err != nil {
+		return errors.Wrap(err, "mapping [organization] section")
+	}
+
 	// ****************************
 	// ----- Session settings -----
 	// ****************************
@@ -326,9 +360,15 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [mirror] section")
 	}
 
+	if Mirror.MinInterval <= 0 {
+		Mirror.MinInterval = 1
+	}
 	if Mirror.DefaultInterval <= 0 {
 		Mirror.DefaultInterval = 8
 	}
+	if Mirror.DefaultInterval < Mirror.MinInterval {
+		Mirror.DefaultInterval = Mirror.MinInterval
+	}
 
 	// *************************
 	// ----- I18n settings -----
@@ -348,6 +388,9 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [lfs] section")
 	}
 	LFS.ObjectsPath = ensureAbs(LFS.ObjectsPath)
+	if err = LFS.validate(); err != nil {
+		return errors.Wrap(err, "validate [lfs] section")
+	}
 
 	handleDeprecated()
 
@@ -375,10 +418,25 @@ func Init(customConf string) error {
 		return errors.Wrap(err, "mapping [ui] section")
 	} else if err = File.Section("prometheus").MapTo(&Prometheus); err != nil {
 		return errors.Wrap(err, "mapping [prometheus] section")
+	} else if err = File.Section("metrics").MapTo(&Metrics); err != nil {
+		return errors.Wrap(err, "mapping [metrics] section")
 	} else if err = File.Section("other").MapTo(&Other); err != nil {
 		return errors.Wrap(err, "mapping [other] section")
 	}
 
+	if Metrics.Enabled && Metrics.Token == "" {
+		return errors.New("[metrics] TOKEN is required when ENABLED is true")
+	}
+
+	if UI.Snippets.Path == "" {
+		UI.Snippets.Path = filepath.Join(CustomDir(), "snippets")
+	}
+	UI.Snippets.Path = ensureAbs(UI.Snippets.Path)
+
+	if Markdown.CustomEmojiPath != "" {
+		Markdown.CustomEmojiPath = ensureAbs(Markdown.CustomEmojiPath)
+	}
+
 	HasRobotsTxt = osutil.IsFile(filepath.Join(CustomDir(), "robots.txt"))
 	return nil
 }
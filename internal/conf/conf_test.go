@@ -40,6 +40,7 @@ func TestInit(t *testing.T) {
 		{"email", &Email},
 		{"auth", &Auth},
 		{"user", &User},
+		{"organization", &Organization},
 		{"session", &Session},
 		{"attachment", &Attachment},
 		{"time", &Time},
@@ -12,10 +12,15 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/ini.v1"
 	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/logutil"
 )
 
 type loggerConf struct {
 	Buffer int64
+	// Format is the format of log messages, either "text" or "json". It is only
+	// applicable to the console and file loggers.
+	Format string
 	Config any
 }
 
@@ -67,12 +72,17 @@ func initLogConf(cfg *ini.File, hookMode bool) (_ *logConf, hasConsole bool, _ e
 
 		level := levelMappings[strings.ToLower(sec.Key("LEVEL").MustString("trace"))]
 		buffer := sec.Key("BUFFER_LEN").MustInt64(100)
+		format := "text"
+		if strings.EqualFold(sec.Key("FORMAT").String(), "json") {
+			format = "json"
+		}
 		var c *loggerConf
 		switch modes[i] {
 		case log.DefaultConsoleName:
 			hasConsole = true
 			c = &loggerConf{
 				Buffer: buffer,
+				Format: format,
 				Config: log.ConsoleConfig{
 					Level: level,
 				},
@@ -82,6 +92,7 @@ func initLogConf(cfg *ini.File, hookMode bool) (_ *logConf, hasConsole bool, _ e
 			logPath := filepath.Join(lc.RootPath, "gogs.log")
 			c = &loggerConf{
 				Buffer: buffer,
+				Format: format,
 				Config: log.FileConfig{
 					Level:    level,
 					Filename: logPath,
@@ -155,10 +166,18 @@ func InitLogging(hookMode bool) {
 		switch mode {
 		case log.DefaultConsoleName:
 			level = c.Config.(log.ConsoleConfig).Level
-			err = log.NewConsole(c.Buffer, c.Config)
+			if c.Format == "json" {
+				err = log.New(mode, logutil.JSONConsoleIniter(), c.Buffer, c.Config)
+			} else {
+				err = log.NewConsole(c.Buffer, c.Config)
+			}
 		case log.DefaultFileName:
 			level = c.Config.(log.FileConfig).Level
-			err = log.NewFile(c.Buffer, c.Config)
+			if c.Format == "json" {
+				err = log.New(mode, logutil.JSONFileIniter(), c.Buffer, c.Config)
+			} else {
+				err = log.NewFile(c.Buffer, c.Config)
+			}
 		case log.DefaultSlackName:
 			level = c.Config.(log.SlackConfig).Level
 			err = log.NewSlack(c.Buffer, c.Config)
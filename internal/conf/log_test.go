@@ -50,6 +50,33 @@ MODE = file
 		assert.NotNil(t, got)
 	})
 
+	t.Run("json format", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[log]
+MODE = console, file, slack
+FORMAT = json
+
+[log.console]
+
+[log.file]
+FORMAT = text
+
+[log.slack]
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, _, err := initLogConf(f, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "json", got.Configs[0].Format)
+		assert.Equal(t, "text", got.Configs[1].Format)
+		assert.Empty(t, got.Configs[2].Format)
+	})
+
 	f, err := ini.Load([]byte(`
 [log]
 ROOT_PATH = log
@@ -97,11 +124,13 @@ USERNAME = yoyo
 		Configs: []*loggerConf{
 			{
 				Buffer: 10,
+				Format: "text",
 				Config: log.ConsoleConfig{
 					Level: log.LevelTrace,
 				},
 			}, {
 				Buffer: 50,
+				Format: "text",
 				Config: log.FileConfig{
 					Level:    log.LevelInfo,
 					Filename: filepath.Join(WorkDir(), "log", "gogs.log"),
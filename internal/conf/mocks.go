@@ -25,6 +25,22 @@ func SetMockAuth(t *testing.T, otps AuthOpts) {
 	})
 }
 
+func SetMockSecurity(t *testing.T, opts SecurityOpts) {
+	before := Security
+	Security = opts
+	t.Cleanup(func() {
+		Security = before
+	})
+}
+
+func SetMockMirror(t *testing.T, opts MirrorOpts) {
+	before := Mirror
+	Mirror = opts
+	t.Cleanup(func() {
+		Mirror = before
+	})
+}
+
 var mockServer sync.Mutex
 
 func SetMockServer(t *testing.T, opts ServerOpts) {
@@ -61,6 +77,30 @@ func SetMockRepository(t *testing.T, opts RepositoryOpts) {
 	})
 }
 
+var mockUser sync.Mutex
+
+func SetMockUser(t *testing.T, opts UserOpts) {
+	mockUser.Lock()
+	before := User
+	User = opts
+	t.Cleanup(func() {
+		User = before
+		mockUser.Unlock()
+	})
+}
+
+var mockOrganization sync.Mutex
+
+func SetMockOrganization(t *testing.T, opts OrganizationOpts) {
+	mockOrganization.Lock()
+	before := Organization
+	Organization = opts
+	t.Cleanup(func() {
+		Organization = before
+		mockOrganization.Unlock()
+	})
+}
+
 func SetMockUI(t *testing.T, opts UIOpts) {
 	before := UI
 	UI = opts
@@ -80,3 +120,31 @@ func SetMockPicture(t *testing.T, opts PictureOpts) {
 		mockPicture.Unlock()
 	})
 }
+
+var mockMarkdown sync.Mutex
+
+func SetMockMarkdown(t *testing.T, opts MarkdownOpts) {
+	mockMarkdown.Lock()
+	before := Markdown
+	Markdown = opts
+	t.Cleanup(func() {
+		Markdown = before
+		mockMarkdown.Unlock()
+	})
+}
+
+func SetMockI18n(t *testing.T, langs []string) {
+	before := I18n
+	I18n = &i18nConf{Langs: langs}
+	t.Cleanup(func() {
+		I18n = before
+	})
+}
+
+func SetMockMetrics(t *testing.T, opts MetricsOpts) {
+	before := Metrics
+	Metrics = opts
+	t.Cleanup(func() {
+		Metrics = before
+	})
+}
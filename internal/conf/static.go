@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gogs/go-libravatar"
+	"github.com/pkg/errors"
 )
 
 // ℹ️ README: This file contains static values that should only be set at initialization time.
@@ -34,19 +35,6 @@ var (
 var CustomConf string
 
 var (
-	// Security settings
-	Security struct {
-		InstallLock             bool
-		SecretKey               string
-		LoginRememberDays       int
-		CookieRememberName      string
-		CookieUsername          string
-		CookieSecure            bool
-		EnableLoginStatusCookie bool
-		LoginStatusCookieName   string
-		LocalNetworkAllowlist   []string `delim:","`
-	}
-
 	// Email settings
 	Email struct {
 		Enabled       bool
@@ -67,15 +55,12 @@ var (
 		UsePlainText    bool
 		AddPlainTextAlt bool
 
+		TemplatePath string
+
 		// Derived from other static values
 		FromEmail string `ini:"-"` // Parsed email address of From without person's name.
 	}
 
-	// User settings
-	User struct {
-		EnableEmailNotification bool
-	}
-
 	// Session settings
 	Session struct {
 		Provider       string
@@ -97,6 +82,14 @@ var (
 	// HTTP settings
 	HTTP struct {
 		AccessControlAllowOrigin string
+
+		// Limits of request body size in MB for groups of endpoints, 0 means no limit.
+		MaxBodySize struct {
+			API    int64 `ini:"API"`
+			Git    int64
+			Form   int64
+			Upload int64
+		} `ini:"http.max_body_size"`
 	}
 
 	// Attachment settings
@@ -110,6 +103,9 @@ var (
 
 	// Release settings
 	Release struct {
+		SignTags   bool
+		SigningKey string
+
 		Attachment struct {
 			Enabled      bool
 			AllowedTypes []string `delim:"|"`
@@ -126,24 +122,15 @@ var (
 		FormatLayout string `ini:"-"` // Actual layout of the Format.
 	}
 
-	// Mirror settings
-	Mirror struct {
-		DefaultInterval int
-	}
-
 	// Webhook settings
 	Webhook struct {
 		Types          []string
 		DeliverTimeout int
 		SkipTLSVerify  bool `ini:"SKIP_TLS_VERIFY"`
 		PagingNum      int
-	}
 
-	// Markdown settings
-	Markdown struct {
-		EnableHardLineBreak bool
-		CustomURLSchemes    []string `ini:"CUSTOM_URL_SCHEMES"`
-		FileExtensions      []string
+		DeliveryHistoryMaxCount int
+		DeliveryHistoryMaxAge   time.Duration
 	}
 
 	// Smartypants settings
@@ -185,6 +172,48 @@ var (
 			Schedule   string
 			OlderThan  time.Duration
 		} `ini:"cron.repo_archive_cleanup"`
+		ScanOrgMirrors struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.scan_org_mirrors"`
+		DeleteExpiredRepoInvitations struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.delete_expired_repo_invitations"`
+		DeleteExpiredOrgInvitations struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.delete_expired_org_invitations"`
+		OrphanedFileCleanup struct {
+			Enabled     bool
+			RunAtStart  bool
+			Schedule    string
+			GracePeriod time.Duration
+			DryRun      bool
+		} `ini:"cron.orphaned_file_cleanup"`
+		PruneWebhookDeliveries struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.prune_webhook_deliveries"`
+		StaleIssues struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.stale_issues"`
+		UpdateRepoSizes struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.update_repo_sizes"`
+		CleanupPullRequestRefs struct {
+			Enabled    bool
+			RunAtStart bool
+			Schedule   string
+		} `ini:"cron.cleanup_pull_request_refs"`
 	}
 
 	// Git settings
@@ -196,20 +225,34 @@ var (
 		MaxDiffFiles         int      `ini:"MAX_GIT_DIFF_FILES"`
 		MaxDiffLines         int      `ini:"MAX_GIT_DIFF_LINES"`
 		MaxDiffLineChars     int      `ini:"MAX_GIT_DIFF_LINE_CHARACTERS"`
+		CollapseDiffLines    int      `ini:"COLLAPSE_GIT_DIFF_LINES"`
+		CollapseDiffBytes    int64    `ini:"COLLAPSE_GIT_DIFF_BYTES"`
+		MaxDiffRenderedLines int      `ini:"MAX_GIT_DIFF_RENDERED_LINES"`
 		GCArgs               []string `ini:"GC_ARGS" delim:" "`
-		Timeout              struct {
+
+		MaxConcurrentOperations        int           `ini:"MAX_CONCURRENT_OPERATIONS"`
+		MaxConcurrentOperationsPerRepo int           `ini:"MAX_CONCURRENT_OPERATIONS_PER_REPO"`
+		MaxConcurrentOperationsPerIP   int           `ini:"MAX_CONCURRENT_OPERATIONS_PER_IP"`
+		OperationQueueTimeout          time.Duration `ini:"OPERATION_QUEUE_TIMEOUT"`
+		MaxOperationBandwidth          int64         `ini:"MAX_OPERATION_BANDWIDTH"`
+
+		Timeout struct {
 			Migrate int
 			Mirror  int
 			Clone   int
 			Pull    int
 			Diff    int
+			Blame   int
 			GC      int `ini:"GC"`
 		} `ini:"git.timeout"`
 	}
 
 	// API settings
 	API struct {
-		MaxResponseItems int
+		MaxResponseItems          int
+		RequireRepoDeletionToken  bool
+		RepoDeletionTokenLifetime time.Duration
+		SecretsTokenLifetime      time.Duration
 	}
 
 	// Prometheus settings
@@ -258,6 +301,40 @@ type AuthOpts struct {
 // Authentication settings
 var Auth AuthOpts
 
+type SecurityOpts struct {
+	InstallLock             bool
+	SecretKey               string
+	LoginRememberDays       int
+	CookieRememberName      string
+	CookieUsername          string
+	CookieSecure            bool
+	EnableLoginStatusCookie bool
+	LoginStatusCookieName   string
+	LocalNetworkAllowlist   []string `delim:","`
+
+	// Sign in lockout settings
+	LoginLockout struct {
+		Enabled            bool
+		MaxAccountAttempts int
+		MaxIPAttempts      int `ini:"MAX_IP_ATTEMPTS"`
+		Window             time.Duration
+		MaxWindow          time.Duration
+		TrustProxyHeaders  bool
+	} `ini:"security.login_lockout"`
+}
+
+// Security settings
+var Security SecurityOpts
+
+type MirrorOpts struct {
+	DefaultInterval int
+	MinInterval     int
+	SyncCooldown    time.Duration
+}
+
+// Mirror settings
+var Mirror MirrorOpts
+
 type ServerOpts struct {
 	ExternalURL          string `ini:"EXTERNAL_URL"`
 	Domain               string
@@ -279,6 +356,8 @@ type ServerOpts struct {
 
 	LandingURL string `ini:"LANDING_URL"`
 
+	GoGetImportPrefix string `ini:"GO_GET_IMPORT_PREFIX"`
+
 	// Derived from other static values
 	URL            *url.URL    `ini:"-"` // Parsed URL object of ExternalURL.
 	Subpath        string      `ini:"-"` // Subpath found the ExternalURL. Should be empty when not found.
@@ -317,12 +396,36 @@ type RepositoryOpts struct {
 	ANSICharset              string `ini:"ANSI_CHARSET"`
 	ForcePrivate             bool
 	MaxCreationLimit         int
+	MaxCollaborators         int
 	PreferredLicenses        []string
+	InitTemplatesPath        string
+	DefaultGitignores        []string
+	DefaultLicense           string
+	DefaultReadme            string
 	DisableHTTPGit           bool `ini:"DISABLE_HTTP_GIT"`
 	EnableLocalPathMigration bool
 	EnableRawFileRenderMode  bool
 	CommitsFetchConcurrency  int
 	DefaultBranch            string
+	EnableCommentEditHistory bool
+
+	ExcludeForksFromCreationLimit bool
+
+	RequireCollaboratorInvitation  bool
+	CollaboratorInvitationLifetime time.Duration
+
+	// Comment rate limit settings
+	CommentRateLimit struct {
+		MaxComments int
+		Interval    time.Duration
+	} `ini:"repository.comment_rate_limit"`
+
+	// Repository language statistics settings
+	Languages struct {
+		Enabled      bool
+		MaxFileSize  int64
+		IgnoredPaths []string
+	} `ini:"repository.languages"`
 
 	// Repository editor settings
 	Editor struct {
@@ -338,11 +441,103 @@ type RepositoryOpts struct {
 		FileMaxSize  int64
 		MaxFiles     int
 	} `ini:"repository.upload"`
+
+	// Repository archive settings
+	Archive struct {
+		CachePath    string
+		MaxCacheSize int64
+		MaxSize      int64
+	} `ini:"repository.archive"`
+
+	// Repository topic settings
+	Topics struct {
+		Allowed []string
+		Strict  bool
+	} `ini:"repository.topics"`
+
+	// Repository lifecycle hook settings
+	Lifecycle struct {
+		Enabled bool
+		URL     string `ini:"URL"`
+		Secret  string
+		Command string
+		Timeout time.Duration
+	} `ini:"repository.lifecycle"`
+
+	// Issue classifier settings
+	IssueClassifier struct {
+		Enabled       bool
+		URL           string `ini:"URL"`
+		AllowedLabels []string
+		Timeout       time.Duration
+	} `ini:"repository.issue_classifier"`
+
+	// Pull request ref settings
+	PullRequestRefs struct {
+		Cleanup     bool
+		KeepHeadRef bool
+	} `ini:"repository.pull_request_refs"`
+
+	// Default branch protection settings
+	DefaultBranchProtection struct {
+		Enabled              bool
+		RequirePullRequest   bool
+		RequireLinearHistory bool
+		RequireUpToDate      bool
+	} `ini:"repository.default_branch_protection"`
+
+	// Push limit settings
+	PushLimits struct {
+		MaxPushSize         int64
+		MaxBlobSize         int64
+		BlockedFilePatterns []string
+	} `ini:"repository.push_limits"`
+
+	// Repository deletion settings
+	Deletion struct {
+		ExportIssues    bool
+		ExportPath      string
+		CrossReferences string
+	} `ini:"repository.deletion"`
+
+	// Repository clone URL settings
+	CloneURL struct {
+		GitHost              string
+		HTTPBaseURL          string `ini:"HTTP_BASE_URL"`
+		SSHBaseURL           string `ini:"SSH_BASE_URL"`
+		AnonymousHTTPBaseURL string `ini:"ANONYMOUS_HTTP_BASE_URL"`
+	} `ini:"repository.clone_url"`
 }
 
 // Repository settings
 var Repository RepositoryOpts
 
+type UserOpts struct {
+	EnableEmailNotification bool
+	DeletionPolicy          string
+	ExportInterval          time.Duration
+
+	AutoWatchOnCreate  bool
+	AutoWatchOnPush    bool
+	AutoWatchOnComment bool
+
+	NoReplyDomain string
+}
+
+// User settings
+var User UserOpts
+
+type OrganizationOpts struct {
+	MaxTeams           int
+	MaxTeamMembers     int
+	MembershipCacheTTL time.Duration `ini:"MEMBERSHIP_CACHE_TTL"`
+
+	MemberInvitationLifetime time.Duration
+}
+
+// Organization settings
+var Organization OrganizationOpts
+
 type DatabaseOpts struct {
 	Type         string
 	Host         string
@@ -360,8 +555,51 @@ type DatabaseOpts struct {
 var Database DatabaseOpts
 
 type LFSOpts struct {
+	// Whether LFS is enabled for new repositories, which can be overridden by
+	// each repository.
+	Enabled     bool
 	Storage     string
 	ObjectsPath string
+
+	// Settings of the "s3" storage backend.
+	S3 struct {
+		Endpoint        string
+		Region          string
+		Bucket          string
+		AccessKeyID     string `ini:"ACCESS_KEY_ID"`
+		SecretAccessKey string
+	} `ini:"lfs.s3"`
+}
+
+// validate returns an error if the storage backend is unsupported, or settings
+// of the "s3" storage backend are incomplete when it is used.
+func (opts *LFSOpts) validate() error {
+	switch opts.Storage {
+	case "local":
+		return nil
+	case "s3":
+	default:
+		return errors.Errorf("unsupported storage %q", opts.Storage)
+	}
+
+	u, err := url.Parse(opts.S3.Endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.Errorf("[lfs.s3] ENDPOINT %q is not a valid HTTP(S) URL", opts.S3.Endpoint)
+	}
+	for _, v := range []struct {
+		key   string
+		value string
+	}{
+		{"REGION", opts.S3.Region},
+		{"BUCKET", opts.S3.Bucket},
+		{"ACCESS_KEY_ID", opts.S3.AccessKeyID},
+		{"SECRET_ACCESS_KEY", opts.S3.SecretAccessKey},
+	} {
+		if v.value == "" {
+			return errors.Errorf("[lfs.s3] %s is required when STORAGE is \"s3\"", v.key)
+		}
+	}
+	return nil
 }
 
 // LFS settings
@@ -371,6 +609,8 @@ type UIUserOpts struct {
 	RepoPagingNum     int
 	NewsFeedPagingNum int
 	CommitsPagingNum  int
+	// Whether to show the README of the profile repository on user profiles.
+	EnableProfileReadme bool
 }
 
 type UIOpts struct {
@@ -379,6 +619,7 @@ type UIOpts struct {
 	FeedMaxCommitNum   int
 	ThemeColorMetaTag  string
 	MaxDisplayFileSize int64
+	MaxRenderFileSize  int64
 
 	Admin struct {
 		UserPagingNum   int
@@ -387,11 +628,23 @@ type UIOpts struct {
 		OrgPagingNum    int
 	} `ini:"ui.admin"`
 	User UIUserOpts `ini:"ui.user"`
+
+	Snippets struct {
+		Path string
+	} `ini:"ui.snippets"`
 }
 
 // UI settings
 var UI UIOpts
 
+type MetricsOpts struct {
+	Enabled bool
+	Token   string
+}
+
+// Application metrics settings
+var Metrics MetricsOpts
+
 type PictureOpts struct {
 	AvatarUploadPath           string
 	RepositoryAvatarUploadPath string
@@ -406,6 +659,21 @@ type PictureOpts struct {
 // Picture settings
 var Picture PictureOpts
 
+type MarkdownOpts struct {
+	EnableHardLineBreak bool
+	CustomURLSchemes    []string `ini:"CUSTOM_URL_SCHEMES"`
+	FileExtensions      []string
+
+	DisableEmoji           bool
+	DisableMentions        bool
+	DisableIssueReferences bool
+	DisableAutolink        bool
+	CustomEmojiPath        string
+}
+
+// Markdown settings
+var Markdown MarkdownOpts
+
 type i18nConf struct {
 	Langs     []string          `delim:","`
 	Names     []string          `delim:","`
@@ -447,9 +715,33 @@ var (
 	UseMSSQL      bool
 )
 
+// The policies of handling content of a deleted user.
+const (
+	// UserDeletionPolicyGhost keeps all content and attributes it to the ghost
+	// user.
+	UserDeletionPolicyGhost = "ghost"
+	// UserDeletionPolicyDelete deletes comments, and keeps issues and pull
+	// requests by attributing them to the ghost user.
+	UserDeletionPolicyDelete = "delete"
+)
+
+// The modes of handling references to a deleted repository from issues and
+// pull requests of other repositories.
+const (
+	// RepoDeletionCrossReferencesKeep leaves references as they are, which become
+	// broken links.
+	RepoDeletionCrossReferencesKeep = "keep"
+	// RepoDeletionCrossReferencesTombstone rewrites references to plain text that
+	// marks the repository as deleted.
+	RepoDeletionCrossReferencesTombstone = "tombstone"
+)
+
 // UsersAvatarPathPrefix is the path prefix to user avatars.
 const UsersAvatarPathPrefix = "avatars"
 
+// CustomEmojiPathPrefix is the path prefix to custom emoji.
+const CustomEmojiPathPrefix = "custom-emoji"
+
 // UserDefaultAvatarURLPath returns the URL path of the default user avatar.
 func UserDefaultAvatarURLPath() string {
 	return Server.Subpath + "/img/avatar_default.png"
@@ -33,3 +33,55 @@ func Test_i18n_DateLang(t *testing.T) {
 		})
 	}
 }
+
+func TestLFSOpts_validate(t *testing.T) {
+	newS3 := func(endpoint, bucket string) LFSOpts {
+		opts := LFSOpts{Storage: "s3"}
+		opts.S3.Endpoint = endpoint
+		opts.S3.Region = "us-east-1"
+		opts.S3.Bucket = bucket
+		opts.S3.AccessKeyID = "id"
+		opts.S3.SecretAccessKey = "secret"
+		return opts
+	}
+
+	tests := []struct {
+		name    string
+		opts    LFSOpts
+		wantErr string
+	}{
+		{
+			name: "local",
+			opts: LFSOpts{Storage: "local"},
+		},
+		{
+			name:    "unsupported storage",
+			opts:    LFSOpts{Storage: "ftp"},
+			wantErr: `unsupported storage "ftp"`,
+		},
+		{
+			name: "s3",
+			opts: newS3("https://s3.us-east-1.amazonaws.com", "lfs"),
+		},
+		{
+			name:    "s3 without endpoint",
+			opts:    newS3("", "lfs"),
+			wantErr: `[lfs.s3] ENDPOINT "" is not a valid HTTP(S) URL`,
+		},
+		{
+			name:    "s3 without bucket",
+			opts:    newS3("https://s3.us-east-1.amazonaws.com", ""),
+			wantErr: `[lfs.s3] BUCKET is required when STORAGE is "s3"`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.opts.validate()
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// JSONSuccess responses JSON with status http.StatusOK. When the "fields" query
+// parameter is present, e.g. "?fields=name,full_name", only the requested
+// top-level fields of the object (or of each object in a list) are returned.
+func (c *APIContext) JSONSuccess(data any) {
+	fields := c.Query("fields")
+	if fields == "" {
+		c.Context.JSONSuccess(data)
+		return
+	}
+
+	shaped, err := selectFields(data, strings.Split(fields, ","))
+	if err != nil {
+		c.Error(err, "select fields")
+		return
+	}
+	c.JSON(http.StatusOK, shaped)
+}
+
+// jsonFieldNames returns the set of JSON field names of the struct type, with
+// pointers, slices and arrays dereferenced to their element types. It returns
+// nil if the type is not a struct.
+func jsonFieldNames(typ reflect.Type) map[string]struct{} {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	names := make(map[string]struct{}, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			if field.Anonymous {
+				for embedded := range jsonFieldNames(field.Type) {
+					names[embedded] = struct{}{}
+				}
+				continue
+			} else if !field.IsExported() {
+				continue
+			}
+			name = field.Name
+		}
+		names[name] = struct{}{}
+	}
+	return names
+}
+
+// selectFields returns the serialized form of the data with only given
+// top-level fields. Fields that are not JSON fields of the underlying struct
+// type are ignored, and the data is returned as-is when the data is not a
+// struct (or a list of structs) or none of given fields is known.
+func selectFields(data any, fields []string) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	known := jsonFieldNames(reflect.TypeOf(data))
+	if len(known) == 0 {
+		return data, nil
+	}
+
+	selected := make(map[string]struct{}, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if _, ok := known[field]; ok {
+			selected[field] = struct{}{}
+		}
+	}
+	if len(selected) == 0 {
+		return data, nil
+	}
+
+	p, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	pick := func(obj map[string]json.RawMessage) map[string]json.RawMessage {
+		for name := range obj {
+			if _, ok := selected[name]; !ok {
+				delete(obj, name)
+			}
+		}
+		return obj
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(data)).Kind() {
+	case reflect.Slice, reflect.Array:
+		var objs []map[string]json.RawMessage
+		if err = json.Unmarshal(p, &objs); err != nil {
+			return nil, err
+		}
+		for i := range objs {
+			objs[i] = pick(objs[i])
+		}
+		return objs, nil
+	default:
+		var obj map[string]json.RawMessage
+		if err = json.Unmarshal(p, &obj); err != nil {
+			return nil, err
+		}
+		// A nil pointer is serialized as "null".
+		if obj == nil {
+			return nil, nil
+		}
+		return pick(obj), nil
+	}
+}
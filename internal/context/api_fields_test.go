@@ -0,0 +1,92 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/gogs/go-gogs-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestAPIContext_JSONSuccess(t *testing.T) {
+	repo := &api.Repository{
+		ID:          1,
+		Name:        "example",
+		FullName:    "alice/example",
+		Description: "An example",
+		Owner:       &api.User{ID: 1, UserName: "alice"},
+	}
+
+	m := macaron.New()
+	m.Use(macaron.Renderer())
+	m.Use(func(ctx *macaron.Context) {
+		ctx.Map(&APIContext{Context: &Context{Context: ctx}})
+	})
+	m.Get("/repo", func(c *APIContext) {
+		c.JSONSuccess(repo)
+	})
+	m.Get("/repos", func(c *APIContext) {
+		c.JSONSuccess([]*api.Repository{repo, repo})
+	})
+	m.Get("/map", func(c *APIContext) {
+		c.JSONSuccess(map[string]any{"ok": true})
+	})
+
+	get := func(t *testing.T, url string, v any) {
+		t.Helper()
+		r, err := http.NewRequest("GET", url, nil)
+		require.NoError(t, err)
+		rr := httptest.NewRecorder()
+		m.ServeHTTP(rr, r)
+		require.Equal(t, http.StatusOK, rr.Code)
+		err = json.Unmarshal(rr.Body.Bytes(), v)
+		require.NoError(t, err)
+	}
+
+	t.Run("full object", func(t *testing.T) {
+		var got map[string]any
+		get(t, "/repo", &got)
+		assert.Equal(t, "example", got["name"])
+		assert.Equal(t, "An example", got["description"])
+		assert.Contains(t, got, "owner")
+	})
+
+	t.Run("selected fields", func(t *testing.T) {
+		var got map[string]any
+		get(t, "/repo?fields=name,full_name", &got)
+		assert.Equal(t, map[string]any{"name": "example", "full_name": "alice/example"}, got)
+	})
+
+	t.Run("unknown fields are ignored", func(t *testing.T) {
+		var got map[string]any
+		get(t, "/repo?fields=name,%20secret", &got)
+		assert.Equal(t, map[string]any{"name": "example"}, got)
+
+		// Nothing is selected when no field is known
+		get(t, "/repo?fields=secret", &got)
+		assert.Equal(t, "An example", got["description"])
+	})
+
+	t.Run("list", func(t *testing.T) {
+		var got []map[string]any
+		get(t, "/repos?fields=id,full_name", &got)
+		assert.Equal(t, []map[string]any{
+			{"id": float64(1), "full_name": "alice/example"},
+			{"id": float64(1), "full_name": "alice/example"},
+		}, got)
+	})
+
+	t.Run("not a struct", func(t *testing.T) {
+		var got map[string]any
+		get(t, "/map?fields=name", &got)
+		assert.Equal(t, map[string]any{"ok": true}, got)
+	})
+}
@@ -6,6 +6,8 @@ package context
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
@@ -20,6 +22,7 @@ import (
 	"gogs.io/gogs/internal/auth"
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/tool"
 )
 
@@ -139,9 +142,7 @@ func authenticatedUserID(c *macaron.Context, sess session.Store) (_ int64, isTok
 				}
 				return 0, false
 			}
-			if err = db.AccessTokens.Touch(c.Req.Context(), t.ID); err != nil {
-				log.Error("Failed to touch access token: %v", err)
-			}
+			touchAccessToken(t, c.RemoteAddr())
 			return t.UserID, true
 		}
 	}
@@ -158,11 +159,51 @@ func authenticatedUserID(c *macaron.Context, sess session.Store) (_ int64, isTok
 			}
 			return 0, false
 		}
+		if !authenticateSession(c, sess, id) {
+			return 0, false
+		}
 		return id, false
 	}
 	return 0, false
 }
 
+// sessionRecordedKey is the session key to indicate the session has been
+// recorded in db.UserSessions.
+const sessionRecordedKey = "sessionRecorded"
+
+// authenticateSession checks the signed-in session of the user is not revoked,
+// and records the session when it is seen for the first time. A revoked session
+// is flushed so that the user is signed out.
+func authenticateSession(c *macaron.Context, sess session.Store, userID int64) bool {
+	s, err := db.UserSessions.GetBySID(c.Req.Context(), sess.ID())
+	if err == nil {
+		if s.UserID != userID {
+			_ = sess.Flush()
+			return false
+		}
+		touchUserSession(s, c.RemoteAddr())
+		return true
+	} else if !db.IsErrUserSessionNotExist(err) {
+		log.Error("Failed to get user session: %v", err)
+		return false
+	}
+
+	// The record of a session that has been recorded before can only be gone when
+	// the session is revoked.
+	if sess.Get(sessionRecordedKey) != nil {
+		_ = sess.Flush()
+		return false
+	}
+
+	_, err = db.UserSessions.Create(c.Req.Context(), userID, sess.ID(), c.Req.UserAgent(), c.RemoteAddr())
+	if err != nil {
+		log.Error("Failed to create user session: %v", err)
+		return false
+	}
+	_ = sess.Set(sessionRecordedKey, true)
+	return true
+}
+
 // authenticatedUser returns the user object of the authenticated user, along with two bool values
 // which indicate whether the user uses HTTP Basic Authentication or token authentication respectively.
 func authenticatedUser(ctx *macaron.Context, sess session.Store) (_ *db.User, isBasicAuth, isTokenAuth bool) {
@@ -210,9 +251,9 @@ func authenticatedUser(ctx *macaron.Context, sess session.Store) (_ *db.User, is
 			if len(auths) == 2 && auths[0] == "Basic" {
 				uname, passwd, _ := tool.BasicAuthDecode(auths[1])
 
-				u, err := db.Users.Authenticate(ctx.Req.Context(), uname, passwd, -1)
+				u, err := db.AuthenticateWithLockout(ctx.Req.Context(), uname, passwd, -1, LoginIP(ctx))
 				if err != nil {
-					if !auth.IsErrBadCredentials(err) {
+					if !auth.IsErrBadCredentials(err) && !db.IsErrLoginLocked(err) {
 						log.Error("Failed to authenticate user: %v", err)
 					}
 					return nil, false, false
@@ -232,18 +273,56 @@ func authenticatedUser(ctx *macaron.Context, sess session.Store) (_ *db.User, is
 	return u, false, isTokenAuth
 }
 
+// touchAccessToken records the use of the access token from the remote address
+// in the background, so that the request is not blocked by the write.
+//
+// NOTE: There is no need to fail the auth flow if we can't touch the token.
+func touchAccessToken(t *db.AccessToken, remoteAddr string) {
+	store := db.AccessTokens
+	go func() {
+		if err := store.Touch(context.Background(), t, remoteAddr); err != nil {
+			log.Error("Failed to touch access token [id: %d]: %v", t.ID, err)
+		}
+	}()
+}
+
+// touchUserSession records the activity of the session from the remote address
+// in the background, so that the request is not blocked by the write.
+//
+// NOTE: There is no need to fail the auth flow if we can't touch the session.
+func touchUserSession(s *db.UserSession, remoteAddr string) {
+	store := db.UserSessions
+	go func() {
+		if err := store.Touch(context.Background(), s, remoteAddr); err != nil {
+			log.Error("Failed to touch user session [id: %d]: %v", s.ID, err)
+		}
+	}()
+}
+
+// LoginIP returns the IP address of the client for sign in lockouts. Headers set
+// by reverse proxies are only trusted when configured, because clients could
+// otherwise forge them to avoid the lockout.
+func LoginIP(c *macaron.Context) string {
+	if conf.Security.LoginLockout.TrustProxyHeaders {
+		return c.RemoteAddr()
+	}
+
+	host, _, err := net.SplitHostPort(c.Req.RemoteAddr)
+	if err != nil {
+		return c.Req.RemoteAddr
+	}
+	return host
+}
+
 // AuthenticateByToken attempts to authenticate a user by the given access
-// token. It returns db.ErrAccessTokenNotExist when the access token does not
-// exist.
-func AuthenticateByToken(ctx context.Context, token string) (*db.User, error) {
+// token used from the remote address. It returns db.ErrAccessTokenNotExist when
+// the access token does not exist.
+func AuthenticateByToken(ctx context.Context, token, remoteAddr string) (*db.User, error) {
 	t, err := db.AccessTokens.GetBySHA1(ctx, token)
 	if err != nil {
 		return nil, errors.Wrap(err, "get access token by SHA1")
 	}
-	if err = db.AccessTokens.Touch(ctx, t.ID); err != nil {
-		// NOTE: There is no need to fail the auth flow if we can't touch the token.
-		log.Error("Failed to touch access token [id: %d]: %v", t.ID, err)
-	}
+	touchAccessToken(t, remoteAddr)
 
 	user, err := db.Users.GetByID(ctx, t.UserID)
 	if err != nil {
@@ -251,3 +330,42 @@ func AuthenticateByToken(ctx context.Context, token string) (*db.User, error) {
 	}
 	return user, nil
 }
+
+type ErrTwoFactorRequired struct {
+	args errutil.Args
+}
+
+// IsErrTwoFactorRequired returns true if the underlying error has the type
+// ErrTwoFactorRequired.
+func IsErrTwoFactorRequired(err error) bool {
+	_, ok := errors.Cause(err).(ErrTwoFactorRequired)
+	return ok
+}
+
+func (err ErrTwoFactorRequired) Error() string {
+	return fmt.Sprintf("user with two-factor authentication enabled cannot authenticate with password: %v", err.args)
+}
+
+// AuthenticateByBasic attempts to authenticate a user by the credentials of
+// HTTP Basic Authentication sent by Git clients. Access tokens are tried first,
+// either as the username or the password, so that using them is never counted as
+// a failed sign in attempt, and then the username and password with the sign in
+// lockout. It returns ErrTwoFactorRequired when the user has two-factor
+// authentication enabled but uses the password.
+func AuthenticateByBasic(c *macaron.Context, username, password string) (*db.User, error) {
+	user, err := AuthenticateByToken(c.Req.Context(), username, c.RemoteAddr())
+	if db.IsErrAccessTokenNotExist(err) {
+		user, err = AuthenticateByToken(c.Req.Context(), password, c.RemoteAddr())
+	}
+	if !db.IsErrAccessTokenNotExist(err) {
+		return user, err
+	}
+
+	user, err = db.AuthenticateWithLockout(c.Req.Context(), username, password, -1, LoginIP(c))
+	if err != nil {
+		return nil, err
+	} else if db.TwoFactors.IsEnabled(c.Req.Context(), user.ID) {
+		return nil, ErrTwoFactorRequired{args: errutil.Args{"userID": user.ID}}
+	}
+	return user, nil
+}
@@ -23,6 +23,8 @@ import (
 	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/form"
 	"gogs.io/gogs/internal/lazyregexp"
+	"gogs.io/gogs/internal/logutil"
+	"gogs.io/gogs/internal/strutil"
 	"gogs.io/gogs/internal/template"
 )
 
@@ -169,6 +171,17 @@ func (c *Context) RenderWithErr(msg, tpl string, f any) {
 	c.HTML(http.StatusOK, tpl)
 }
 
+// Logger returns the logger of the request, which writes log messages with
+// contextual fields of the request, e.g. the request ID.
+func (c *Context) Logger() *logutil.Logger {
+	return logutil.FromContext(c.Req.Context())
+}
+
+// AddLogField attaches the field to the logger of the request.
+func (c *Context) AddLogField(key string, value any) {
+	c.Req.Request = c.Req.WithContext(logutil.NewContext(c.Req.Context(), c.Logger().With(key, value)))
+}
+
 // NotFound renders the 404 page.
 func (c *Context) NotFound() {
 	c.Title("status.page_not_found")
@@ -177,7 +190,7 @@ func (c *Context) NotFound() {
 
 // Error renders the 500 page.
 func (c *Context) Error(err error, msg string) {
-	log.ErrorDepth(4, "%s: %v", msg, err)
+	c.Logger().ErrorDepth(4, "%s: %v", msg, err)
 
 	c.Title("status.internal_server_error")
 
@@ -262,6 +275,17 @@ func Contexter() macaron.Handler {
 		// Get user from session or header when possible
 		c.User, c.IsBasicAuth, c.IsTokenAuth = authenticatedUser(c.Context, c.Session)
 
+		// Attach the actor of operations to be recorded by audit logs.
+		actor := db.AuditActor{IP: c.RemoteAddr()}
+		if c.User != nil {
+			actor.ID = c.User.ID
+			actor.Name = c.User.Name
+		}
+		c.Req.Request = c.Req.WithContext(db.WithAuditActor(c.Req.Context(), actor))
+		if c.User != nil {
+			c.AddLogField("user", c.User.Name)
+		}
+
 		if c.User != nil {
 			c.IsLogged = true
 			c.Data["IsLogged"] = c.IsLogged
@@ -291,6 +315,13 @@ func Contexter() macaron.Handler {
 		log.Trace("Session ID: %s", sess.ID())
 		log.Trace("CSRF Token: %v", c.Data["CSRFToken"])
 
+		// The nonce is used by custom snippets for the Content Security Policy.
+		nonce, err := strutil.RandomChars(16)
+		if err != nil {
+			log.Error("Failed to generate nonce: %v", err)
+		}
+		c.Data["CSPNonce"] = nonce
+
 		c.Data["ShowRegistrationButton"] = !conf.Auth.DisableRegistration
 		c.Data["ShowFooterBranding"] = conf.Other.ShowFooterBranding
 
@@ -7,16 +7,23 @@ import (
 
 	"github.com/unknwon/com"
 	"gopkg.in/macaron.v1"
+	log "unknwon.dev/clog/v2"
 
+	"gogs.io/gogs/internal/auth"
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/repoutil"
+	"gogs.io/gogs/internal/tool"
 )
 
 // ServeGoGet does quick responses for appropriate go-get meta with status OK
 // regardless of whether the user has access to the repository, or the repository
 // does exist at all. This is particular a workaround for "go get" command which
 // does not respect .netrc file.
+//
+// When a vanity import path prefix is configured, the meta is only served for
+// repositories that the user has read access to, and the import path is
+// composed of the prefix instead of the host and subpath of the external URL.
 func ServeGoGet() macaron.Handler {
 	return func(c *macaron.Context) {
 		if c.Query("go-get") != "1" {
@@ -27,14 +34,34 @@ func ServeGoGet() macaron.Handler {
 		repoName := c.Params(":reponame")
 		branchName := "master"
 
+		var repo *db.Repository
 		owner, err := db.Users.GetByUsername(c.Req.Context(), ownerName)
 		if err == nil {
-			repo, err := db.Repos.GetByName(c.Req.Context(), owner.ID, repoName)
+			repo, err = db.Repos.GetByName(c.Req.Context(), owner.ID, repoName)
 			if err == nil && repo.DefaultBranch != "" {
 				branchName = repo.DefaultBranch
 			}
 		}
 
+		importPrefix := path.Join(conf.Server.URL.Host, conf.Server.Subpath)
+		if conf.Server.GoGetImportPrefix != "" {
+			if repo == nil ||
+				!db.Perms.Authorize(
+					c.Req.Context(),
+					goGetUserID(c),
+					repo.ID,
+					db.AccessModeRead,
+					db.AccessModeOptions{
+						OwnerID: repo.OwnerID,
+						Private: repo.IsPrivate,
+					},
+				) {
+				c.PlainText(http.StatusNotFound, []byte(http.StatusText(http.StatusNotFound)))
+				return
+			}
+			importPrefix = conf.Server.GoGetImportPrefix
+		}
+
 		prefix := conf.Server.ExternalURL + path.Join(ownerName, repoName, "src", branchName)
 		insecureFlag := ""
 		if !strings.HasPrefix(conf.Server.ExternalURL, "https://") {
@@ -52,7 +79,7 @@ func ServeGoGet() macaron.Handler {
 </html>
 `,
 			map[string]string{
-				"GoGetImport":    path.Join(conf.Server.URL.Host, conf.Server.Subpath, ownerName, repoName),
+				"GoGetImport":    path.Join(importPrefix, ownerName, repoName),
 				"CloneLink":      repoutil.HTTPSCloneURL(ownerName, repoName),
 				"GoDocDirectory": prefix + "{/dir}",
 				"GoDocFile":      prefix + "{/dir}/{file}#L{line}",
@@ -61,3 +88,26 @@ func ServeGoGet() macaron.Handler {
 		)))
 	}
 }
+
+// goGetUserID returns the ID of the user authenticated by the basic auth of the
+// request, which is how "go get" sends credentials from the .netrc file, in the
+// same way as Git over HTTP. It returns 0 for anonymous requests.
+func goGetUserID(c *macaron.Context) int64 {
+	auths := strings.Fields(c.Req.Header.Get("Authorization"))
+	if len(auths) != 2 || auths[0] != "Basic" {
+		return 0
+	}
+
+	username, password, err := tool.BasicAuthDecode(auths[1])
+	if err != nil {
+		return 0
+	}
+	u, err := AuthenticateByBasic(c, username, password)
+	if err != nil {
+		if !auth.IsErrBadCredentials(err) && !db.IsErrLoginLocked(err) && !IsErrTwoFactorRequired(err) {
+			log.Error("Failed to authenticate user: %v", err)
+		}
+		return 0
+	}
+	return u.ID
+}
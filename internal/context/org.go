@@ -73,7 +73,7 @@ func HandleOrgAssignment(c *Context, args ...bool) {
 			c.Org.IsMember = true
 			c.Org.IsTeamMember = true
 			c.Org.IsTeamAdmin = true
-		} else if org.IsOrgMember(c.User.ID) {
+		} else if db.Orgs.IsOrgMember(c.Req.Context(), org.ID, c.User.ID) {
 			c.Org.IsMember = true
 		}
 	} else {
@@ -161,6 +161,7 @@ func RepoAssignment(pages ...bool) macaron.Handler {
 		}
 
 		c.Repo.Repository = repo
+		c.AddLogField("repo", owner.Name+"/"+repo.Name)
 		c.Data["RepoName"] = c.Repo.Repository.Name
 		c.Data["IsBareRepo"] = c.Repo.Repository.IsBare
 		c.Repo.RepoLink = repo.Link()
@@ -177,6 +178,12 @@ func RepoAssignment(pages ...bool) macaron.Handler {
 					Private: repo.IsPrivate,
 				},
 			)
+
+			// Forks of private repositories are only accessible to users who have
+			// access to the base repository.
+			if c.Repo.AccessMode > db.AccessModeNone && !repo.HasBaseAccess(c.Req.Context(), c.UserID()) {
+				c.Repo.AccessMode = db.AccessModeNone
+			}
 		}
 
 		// If the authenticated user has no direct access, see if the repository is a fork
@@ -234,6 +241,7 @@ func RepoAssignment(pages ...bool) macaron.Handler {
 			}
 			c.Data["MirrorEnablePrune"] = c.Repo.Mirror.EnablePrune
 			c.Data["MirrorInterval"] = c.Repo.Mirror.Interval
+			c.Data["MirrorMinInterval"] = conf.Mirror.MinInterval
 			c.Data["Mirror"] = c.Repo.Mirror
 		}
 
@@ -258,11 +266,17 @@ func RepoAssignment(pages ...bool) macaron.Handler {
 		c.Data["IsRepositoryOwner"] = c.Repo.IsOwner()
 		c.Data["IsRepositoryAdmin"] = c.Repo.IsAdmin()
 		c.Data["IsRepositoryWriter"] = c.Repo.IsWriter()
+		c.Data["CanCreateIssues"] = repo.CanCreateIssues(c.Repo.AccessMode)
 
 		c.Data["DisableSSH"] = conf.SSH.Disabled
 		c.Data["DisableHTTP"] = conf.Repository.DisableHTTPGit
-		c.Data["CloneLink"] = repo.CloneLink()
-		c.Data["WikiCloneLink"] = repo.WikiCloneLink()
+		cloneLink, wikiCloneLink := repo.CloneLink(), repo.WikiCloneLink()
+		if !c.IsLogged {
+			cloneLink.HTTPS = cloneLink.AnonymousHTTPS
+			wikiCloneLink.HTTPS = wikiCloneLink.AnonymousHTTPS
+		}
+		c.Data["CloneLink"] = cloneLink
+		c.Data["WikiCloneLink"] = wikiCloneLink
 
 		if c.IsLogged {
 			c.Data["IsWatchingRepo"] = db.IsWatching(c.User.ID, repo.ID)
@@ -66,6 +66,94 @@ func NewContext() {
 			go db.DeleteOldRepositoryArchives()
 		}
 	}
+	if conf.Cron.ScanOrgMirrors.Enabled {
+		entry, err = c.AddFunc("Scan organization mirrors", conf.Cron.ScanOrgMirrors.Schedule, db.ScanOrgMirrors)
+		if err != nil {
+			log.Fatal("Cron.(scan organization mirrors): %v", err)
+		}
+		if conf.Cron.ScanOrgMirrors.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.ScanOrgMirrors()
+		}
+	}
+	if conf.Cron.DeleteExpiredRepoInvitations.Enabled {
+		entry, err = c.AddFunc("Delete expired repository invitations", conf.Cron.DeleteExpiredRepoInvitations.Schedule, db.DeleteExpiredRepoInvitations)
+		if err != nil {
+			log.Fatal("Cron.(delete expired repository invitations): %v", err)
+		}
+		if conf.Cron.DeleteExpiredRepoInvitations.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.DeleteExpiredRepoInvitations()
+		}
+	}
+	if conf.Cron.DeleteExpiredOrgInvitations.Enabled {
+		entry, err = c.AddFunc("Delete expired organization invitations", conf.Cron.DeleteExpiredOrgInvitations.Schedule, db.DeleteExpiredOrgInvitations)
+		if err != nil {
+			log.Fatal("Cron.(delete expired organization invitations): %v", err)
+		}
+		if conf.Cron.DeleteExpiredOrgInvitations.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.DeleteExpiredOrgInvitations()
+		}
+	}
+	if conf.Cron.OrphanedFileCleanup.Enabled {
+		entry, err = c.AddFunc("Orphaned file cleanup", conf.Cron.OrphanedFileCleanup.Schedule, db.DeleteOrphanedFiles)
+		if err != nil {
+			log.Fatal("Cron.(orphaned file cleanup): %v", err)
+		}
+		if conf.Cron.OrphanedFileCleanup.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.DeleteOrphanedFiles()
+		}
+	}
+	if conf.Cron.PruneWebhookDeliveries.Enabled {
+		entry, err = c.AddFunc("Prune webhook deliveries", conf.Cron.PruneWebhookDeliveries.Schedule, db.PruneHookTasks)
+		if err != nil {
+			log.Fatal("Cron.(prune webhook deliveries): %v", err)
+		}
+		if conf.Cron.PruneWebhookDeliveries.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.PruneHookTasks()
+		}
+	}
+	if conf.Cron.StaleIssues.Enabled {
+		entry, err = c.AddFunc("Process stale issues", conf.Cron.StaleIssues.Schedule, db.ProcessStaleIssues)
+		if err != nil {
+			log.Fatal("Cron.(process stale issues): %v", err)
+		}
+		if conf.Cron.StaleIssues.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.ProcessStaleIssues()
+		}
+	}
+	if conf.Cron.UpdateRepoSizes.Enabled {
+		entry, err = c.AddFunc("Update repository sizes", conf.Cron.UpdateRepoSizes.Schedule, db.UpdateRepoSizes)
+		if err != nil {
+			log.Fatal("Cron.(update repository sizes): %v", err)
+		}
+		if conf.Cron.UpdateRepoSizes.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.UpdateRepoSizes()
+		}
+	}
+	if conf.Cron.CleanupPullRequestRefs.Enabled {
+		entry, err = c.AddFunc("Clean up pull request refs", conf.Cron.CleanupPullRequestRefs.Schedule, db.CleanupPullRequestRefs)
+		if err != nil {
+			log.Fatal("Cron.(clean up pull request refs): %v", err)
+		}
+		if conf.Cron.CleanupPullRequestRefs.RunAtStart {
+			entry.Prev = time.Now()
+			entry.ExecTimes++
+			go db.CleanupPullRequestRefs()
+		}
+	}
 	c.Start()
 }
 
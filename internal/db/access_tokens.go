@@ -33,8 +33,11 @@ type AccessTokensStore interface {
 	GetBySHA1(ctx context.Context, sha1 string) (*AccessToken, error)
 	// List returns all access tokens belongs to given user.
 	List(ctx context.Context, userID int64) ([]*AccessToken, error)
-	// Touch updates the updated time of the given access token to the current time.
-	Touch(ctx context.Context, id int64) error
+	// Touch records the use of the given access token from the IP address by
+	// updating its last used time to the current time. To avoid a write on every
+	// request, it is a no-op when the access token was last used from the same IP
+	// address within the last AccessTokenTouchInterval.
+	Touch(ctx context.Context, t *AccessToken, ip string) error
 }
 
 var AccessTokens AccessTokensStore
@@ -51,6 +54,9 @@ type AccessToken struct {
 	CreatedUnix       int64
 	Updated           time.Time `gorm:"-" json:"-"`
 	UpdatedUnix       int64
+	LastUsed          time.Time `gorm:"-" json:"-"`
+	LastUsedUnix      int64
+	LastUsedIP        string
 	HasRecentActivity bool `gorm:"-" json:"-"`
 	HasUsed           bool `gorm:"-" json:"-"`
 }
@@ -71,6 +77,9 @@ func (t *AccessToken) AfterFind(tx *gorm.DB) error {
 		t.HasUsed = t.Updated.After(t.Created)
 		t.HasRecentActivity = t.Updated.Add(7 * 24 * time.Hour).After(tx.NowFunc())
 	}
+	if t.LastUsedUnix > 0 {
+		t.LastUsed = time.Unix(t.LastUsedUnix, 0).Local()
+	}
 	return nil
 }
 
@@ -113,6 +122,7 @@ func (db *accessTokens) Create(ctx context.Context, userID int64, name string) (
 	if err = db.WithContext(ctx).Create(accessToken).Error; err != nil {
 		return nil, err
 	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionAccessTokenCreate, accessToken.ID, accessToken.Name, "")
 
 	// Set back the raw access token value, for the sake of the caller.
 	accessToken.Sha1 = token
@@ -167,10 +177,31 @@ func (db *accessTokens) List(ctx context.Context, userID int64) ([]*AccessToken,
 	return tokens, db.WithContext(ctx).Where("uid = ?", userID).Order("id ASC").Find(&tokens).Error
 }
 
-func (db *accessTokens) Touch(ctx context.Context, id int64) error {
-	return db.WithContext(ctx).
+// AccessTokenTouchInterval is the minimal interval between two writes of the
+// last used time of an access token.
+const AccessTokenTouchInterval = time.Minute
+
+func (db *accessTokens) Touch(ctx context.Context, t *AccessToken, ip string) error {
+	now := db.NowFunc()
+	if t.LastUsedIP == ip && now.Unix()-t.LastUsedUnix < int64(AccessTokenTouchInterval.Seconds()) {
+		return nil
+	}
+
+	err := db.WithContext(ctx).
 		Model(new(AccessToken)).
-		Where("id = ?", id).
-		UpdateColumn("updated_unix", db.NowFunc().Unix()).
+		Where("id = ?", t.ID).
+		UpdateColumns(map[string]any{
+			"updated_unix":   now.Unix(),
+			"last_used_unix": now.Unix(),
+			"last_used_ip":   ip,
+		}).
 		Error
+	if err != nil {
+		return err
+	}
+
+	t.UpdatedUnix = now.Unix()
+	t.LastUsedUnix = now.Unix()
+	t.LastUsedIP = ip
+	return nil
 }
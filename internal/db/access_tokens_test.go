@@ -98,7 +98,7 @@ func TestAccessTokens(t *testing.T) {
 	}
 	t.Parallel()
 
-	tables := []any{new(AccessToken)}
+	tables := []any{new(AccessToken), new(AuditLog)}
 	db := &accessTokens{
 		DB: dbtest.NewDB(t, "accessTokens", tables...),
 	}
@@ -235,14 +235,46 @@ func accessTokensTouch(t *testing.T, db *accessTokens) {
 	token, err := db.Create(ctx, 1, "Test")
 	require.NoError(t, err)
 
-	// Updated field is zero now
+	// Updated and last used fields are zero now
 	assert.True(t, token.Updated.IsZero())
+	assert.True(t, token.LastUsed.IsZero())
 
-	err = db.Touch(ctx, token.ID)
+	err = db.Touch(ctx, token, "127.0.0.1")
 	require.NoError(t, err)
 
-	// Get back from DB should have Updated set
-	token, err = db.GetBySHA1(ctx, token.Sha1)
+	// Get back from DB should have Updated and last used fields set
+	got, err := db.GetBySHA1(ctx, token.Sha1)
+	require.NoError(t, err)
+	assert.Equal(t, db.NowFunc().Format(time.RFC3339), got.Updated.UTC().Format(time.RFC3339))
+	assert.Equal(t, db.NowFunc().Format(time.RFC3339), got.LastUsed.UTC().Format(time.RFC3339))
+	assert.Equal(t, "127.0.0.1", got.LastUsedIP)
+
+	// Rapid uses from the same IP address should not write to the database
+	err = db.Model(new(AccessToken)).Where("id = ?", token.ID).UpdateColumn("last_used_unix", 1).Error
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		err = db.Touch(ctx, got, "127.0.0.1")
+		require.NoError(t, err)
+	}
+	got, err = db.GetBySHA1(ctx, token.Sha1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.LastUsedUnix)
+
+	// Use from another IP address should be recorded right away
+	err = db.Touch(ctx, got, "10.0.0.1")
+	require.NoError(t, err)
+	got, err = db.GetBySHA1(ctx, token.Sha1)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", got.LastUsedIP)
+	assert.Equal(t, db.NowFunc().Unix(), got.LastUsedUnix)
+
+	// Use after the interval should be recorded again
+	got.LastUsedUnix -= int64(AccessTokenTouchInterval.Seconds())
+	err = db.Model(new(AccessToken)).Where("id = ?", token.ID).UpdateColumn("last_used_unix", got.LastUsedUnix).Error
+	require.NoError(t, err)
+	err = db.Touch(ctx, got, "10.0.0.1")
+	require.NoError(t, err)
+	got, err = db.GetBySHA1(ctx, token.Sha1)
 	require.NoError(t, err)
-	assert.Equal(t, db.NowFunc().Format(time.RFC3339), token.Updated.UTC().Format(time.RFC3339))
+	assert.Equal(t, db.NowFunc().Unix(), got.LastUsedUnix)
 }
@@ -22,6 +22,7 @@ import (
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/lazyregexp"
+	"gogs.io/gogs/internal/markup"
 	"gogs.io/gogs/internal/repoutil"
 	"gogs.io/gogs/internal/strutil"
 	"gogs.io/gogs/internal/testutil"
@@ -39,6 +40,9 @@ type ActionsStore interface {
 	// ListByOrganization returns actions of the organization viewable by the actor.
 	// Results are paginated if `afterID` is given.
 	ListByOrganization(ctx context.Context, orgID, actorID, afterID int64) ([]*Action, error)
+	// ListActivities returns a page of distinct actions matching given options,
+	// sorted from the newest.
+	ListActivities(ctx context.Context, opts ListActivitiesOptions) ([]*Action, error)
 	// ListByUser returns actions of the user viewable by the actor. Results are
 	// paginated if `afterID` is given. The `isProfile` indicates whether repository
 	// permissions should be considered.
@@ -129,6 +133,95 @@ func (db *actions) ListByOrganization(ctx context.Context, orgID, actorID, after
 	return actions, db.listByOrganization(ctx, orgID, actorID, afterID).Find(&actions).Error
 }
 
+type ListActivitiesOptions struct {
+	// The ID of the repository, zero means any repository.
+	RepoID int64
+	// The ID of the organization that owns the repositories, zero means any
+	// owner. Only actions of public repositories and repositories that the viewer
+	// has access to via teams are included when it is given.
+	OrgID    int64
+	ViewerID int64
+	// The ID of the doer, zero means any doer.
+	ActUserID int64
+	// Whether to only include actions of public repositories.
+	OnlyPublic bool
+	// The types of actions, empty means any type.
+	OpTypes []ActionType
+	// The time range of creation, zero means unbounded.
+	Since time.Time
+	Until time.Time
+
+	Page     int
+	PageSize int
+}
+
+func (db *actions) ListActivities(ctx context.Context, opts ListActivitiesOptions) ([]*Action, error) {
+	// Every action has a copy for each of the watchers, the copy for the doer is
+	// the only one to be listed to avoid duplicates.
+	query := db.WithContext(ctx).Where("user_id = act_user_id")
+	if opts.RepoID > 0 {
+		query = query.Where("repo_id = ?", opts.RepoID)
+	}
+	if opts.OrgID > 0 {
+		/*
+			Equivalent SQL for PostgreSQL:
+
+			repo_id IN (
+				SELECT id FROM "repository"
+				WHERE
+					owner_id = @orgID
+				AND (
+						(is_private = FALSE AND is_unlisted = FALSE)
+					OR  id IN (
+							SELECT team_repo.repo_id FROM "team_repo"
+							JOIN team_user ON team_repo.team_id = team_user.team_id
+							WHERE team_user.org_id = @orgID AND team_user.uid = @viewerID
+						)
+					)
+			)
+		*/
+		query = query.Where("repo_id IN (?)", db.
+			Select("id").
+			Table("repository").
+			Where("owner_id = ?", opts.OrgID).
+			Where(db.
+				Where("is_private = ? AND is_unlisted = ?", false, false).
+				Or("id IN (?)", db.
+					Select("team_repo.repo_id").
+					Table("team_repo").
+					Joins("JOIN team_user ON team_repo.team_id = team_user.team_id").
+					Where("team_user.org_id = ? AND team_user.uid = ?", opts.OrgID, opts.ViewerID),
+				),
+			),
+		)
+	}
+	if opts.ActUserID > 0 {
+		query = query.Where("act_user_id = ?", opts.ActUserID)
+	}
+	if opts.OnlyPublic {
+		query = query.Where("is_private = ?", false)
+	}
+	if len(opts.OpTypes) > 0 {
+		query = query.Where("op_type IN (?)", opts.OpTypes)
+	}
+	if !opts.Since.IsZero() {
+		query = query.Where("created_unix >= ?", opts.Since.Unix())
+	}
+	if !opts.Until.IsZero() {
+		query = query.Where("created_unix < ?", opts.Until.Unix())
+	}
+
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	actions := make([]*Action, 0, opts.PageSize)
+	return actions, query.
+		Order("id DESC").
+		Limit(opts.PageSize).Offset((opts.Page - 1) * opts.PageSize).
+		Find(&actions).
+		Error
+}
+
 func (db *actions) listByUser(ctx context.Context, userID, actorID, afterID int64, isProfile bool) *gorm.DB {
 	/*
 		Equivalent SQL for PostgreSQL:
@@ -344,38 +437,51 @@ func assembleKeywordsPattern(words []string) string {
 }
 
 // updateCommitReferencesToIssues checks if issues are manipulated by commit message.
-func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*PushCommit) error {
+func updateCommitReferencesToIssues(ctx context.Context, db *gorm.DB, doer *User, repo *Repository, commits []*PushCommit) error {
 	trimRightNonDigits := func(c rune) bool {
 		return !unicode.IsDigit(c)
 	}
 
-	// Commits are appended in the reverse order.
-	for i := len(commits) - 1; i >= 0; i-- {
-		c := commits[i]
-
-		refMarked := make(map[int64]bool)
-		for _, ref := range issueReferencePattern.FindAllString(c.Message, -1) {
-			ref = strings.TrimSpace(ref)
-			ref = strings.TrimRightFunc(ref, trimRightNonDigits)
+	// getIssueByRef returns the issue that the reference points to, or nil when
+	// it does not exist or the doer does not have the desired access mode to its
+	// repository.
+	getIssueByRef := func(ref string, desired AccessMode) (*Issue, error) {
+		ref = strings.TrimRightFunc(ref, trimRightNonDigits)
+		if ref == "" {
+			return nil, nil
+		}
 
-			if ref == "" {
-				continue
+		target, index, err := resolveIssueReference(ctx, db, doer, repo.MustOwner().Name, repo, ref, desired)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				return nil, nil
 			}
+			return nil, err
+		}
 
-			// Add repo name if missing
-			if ref[0] == '#' {
-				ref = fmt.Sprintf("%s%s", repo.FullName(), ref)
-			} else if !strings.Contains(ref, "/") {
-				// FIXME: We don't support User#ID syntax yet
-				continue
+		issue, err := GetIssueByIndex(target.ID, index)
+		if err != nil {
+			if IsErrIssueNotExist(err) {
+				return nil, nil
 			}
+			return nil, err
+		}
+		return issue, issue.Repo.GetOwner()
+	}
 
-			issue, err := GetIssueByRef(ref)
+	// Commits are appended in the reverse order.
+	for i := len(commits) - 1; i >= 0; i-- {
+		c := commits[i]
+
+		refMarked := make(map[int64]bool)
+		refs := issueReferencePattern.FindAllString(c.Message, -1)
+		refs = append(refs, markup.FindAllIssueKeys(c.Message, repo.IssueKeyPrefix)...)
+		for _, ref := range refs {
+			issue, err := getIssueByRef(strings.TrimSpace(ref), AccessModeRead)
 			if err != nil {
-				if IsErrIssueNotExist(err) {
-					continue
-				}
 				return err
+			} else if issue == nil {
+				continue
 			}
 
 			if refMarked[issue.ID] {
@@ -397,27 +503,12 @@ func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*Pus
 		refMarked = make(map[int64]bool)
 		// FIXME: Can merge this and the next for loop to a common function.
 		for _, ref := range issueCloseKeywordsPattern.FindAllString(c.Message, -1) {
-			ref = ref[strings.IndexByte(ref, byte(' '))+1:]
-			ref = strings.TrimRightFunc(ref, trimRightNonDigits)
-
-			if ref == "" {
-				continue
-			}
-
-			// Add repo name if missing
-			if ref[0] == '#' {
-				ref = fmt.Sprintf("%s%s", repo.FullName(), ref)
-			} else if !strings.Contains(ref, "/") {
-				// FIXME: We don't support User#ID syntax yet
-				continue
-			}
-
-			issue, err := GetIssueByRef(ref)
+			// Closing issues in other repositories requires write access to them.
+			issue, err := getIssueByRef(ref[strings.IndexByte(ref, byte(' '))+1:], AccessModeWrite)
 			if err != nil {
-				if IsErrIssueNotExist(err) {
-					continue
-				}
 				return err
+			} else if issue == nil {
+				continue
 			}
 
 			if refMarked[issue.ID] {
@@ -425,39 +516,22 @@ func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*Pus
 			}
 			refMarked[issue.ID] = true
 
-			if issue.RepoID != repo.ID || issue.IsClosed {
+			if issue.IsClosed {
 				continue
 			}
 
-			if err = issue.ChangeStatus(doer, repo, true); err != nil {
+			if err = issue.ChangeStatus(doer, issue.Repo, true); err != nil {
 				return err
 			}
 		}
 
 		// It is conflict to have close and reopen at same time, so refsMarkd doesn't need to reinit here.
 		for _, ref := range issueReopenKeywordsPattern.FindAllString(c.Message, -1) {
-			ref = ref[strings.IndexByte(ref, byte(' '))+1:]
-			ref = strings.TrimRightFunc(ref, trimRightNonDigits)
-
-			if ref == "" {
-				continue
-			}
-
-			// Add repo name if missing
-			if ref[0] == '#' {
-				ref = fmt.Sprintf("%s%s", repo.FullName(), ref)
-			} else if !strings.Contains(ref, "/") {
-				// We don't support User#ID syntax yet
-				// return ErrNotImplemented
-				continue
-			}
-
-			issue, err := GetIssueByRef(ref)
+			issue, err := getIssueByRef(ref[strings.IndexByte(ref, byte(' '))+1:], AccessModeWrite)
 			if err != nil {
-				if IsErrIssueNotExist(err) {
-					continue
-				}
 				return err
+			} else if issue == nil {
+				continue
 			}
 
 			if refMarked[issue.ID] {
@@ -465,11 +539,11 @@ func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*Pus
 			}
 			refMarked[issue.ID] = true
 
-			if issue.RepoID != repo.ID || !issue.IsClosed {
+			if !issue.IsClosed {
 				continue
 			}
 
-			if err = issue.ChangeStatus(doer, repo, false); err != nil {
+			if err = issue.ChangeStatus(doer, issue.Repo, false); err != nil {
 				return err
 			}
 		}
@@ -477,6 +551,19 @@ func updateCommitReferencesToIssues(doer *User, repo *Repository, commits []*Pus
 	return nil
 }
 
+// hasPushed returns true if the user has ever pushed to the repository.
+func (db *actions) hasPushed(ctx context.Context, userID, repoID int64) bool {
+	var count int64
+	err := db.WithContext(ctx).
+		Model(new(Action)).
+		Where("act_user_id = ? AND repo_id = ? AND op_type IN (?)",
+			userID, repoID,
+			[]ActionType{ActionCommitRepo, ActionCreateBranch, ActionDeleteBranch, ActionPushTag, ActionDeleteTag},
+		).
+		Count(&count).Error
+	return err == nil && count > 0
+}
+
 type CommitRepoOptions struct {
 	Owner       *User
 	Repo        *Repository
@@ -498,6 +585,15 @@ func (db *actions) CommitRepo(ctx context.Context, opts CommitRepoOptions) error
 		return errors.Wrapf(err, "get pusher [name: %s]", opts.PusherName)
 	}
 
+	// Only the first push of the user to the repository counts, so users who
+	// unwatched the repository afterwards are not subscribed again.
+	if pusher.IsAutoWatchOnPush() && !db.hasPushed(ctx, pusher.ID, opts.Repo.ID) {
+		err = NewReposStore(db.DB).AutoWatch(ctx, pusher.ID, opts.Repo.ID)
+		if err != nil {
+			return errors.Wrap(err, "auto-watch repository")
+		}
+	}
+
 	isNewRef := opts.OldCommitID == git.EmptyID
 	isDelRef := opts.NewCommitID == git.EmptyID
 
@@ -547,7 +643,7 @@ func (db *actions) CommitRepo(ctx context.Context, opts CommitRepoOptions) error
 
 	// Only update issues via commits when internal issue tracker is enabled
 	if opts.Repo.EnableIssues && !opts.Repo.EnableExternalTracker {
-		if err = updateCommitReferencesToIssues(pusher, opts.Repo, opts.Commits.Commits); err != nil {
+		if err = updateCommitReferencesToIssues(ctx, db.DB, pusher, opts.Repo, opts.Commits.Commits); err != nil {
 			log.Error("update commit references to issues: %v", err)
 		}
 	}
@@ -735,13 +831,13 @@ const (
 // Action is a user operation to a repository. It implements template.Actioner
 // interface to be able to use it in template rendering.
 type Action struct {
-	ID           int64 `gorm:"primaryKey"`
-	UserID       int64 `gorm:"index"` // Receiver user ID
-	OpType       ActionType
-	ActUserID    int64  // Doer user ID
-	ActUserName  string // Doer user name
-	ActAvatar    string `xorm:"-" gorm:"-" json:"-"`
-	RepoID       int64  `xorm:"INDEX" gorm:"index"`
+	ID           int64      `gorm:"primaryKey"`
+	UserID       int64      `gorm:"index"` // Receiver user ID
+	OpType       ActionType `xorm:"INDEX" gorm:"index"`
+	ActUserID    int64      `xorm:"INDEX" gorm:"index"` // Doer user ID
+	ActUserName  string     // Doer user name
+	ActAvatar    string     `xorm:"-" gorm:"-" json:"-"`
+	RepoID       int64      `xorm:"INDEX" gorm:"index"`
 	RepoUserName string
 	RepoName     string
 	RefName      string
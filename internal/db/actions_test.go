@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gogs/git-module"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
@@ -99,7 +100,7 @@ func TestActions(t *testing.T) {
 	}
 	t.Parallel()
 
-	tables := []any{new(Action), new(User), new(Repository), new(EmailAddress), new(Watch)}
+	tables := []any{new(Action), new(User), new(Repository), new(EmailAddress), new(Watch), new(TeamRepo), new(TeamUser)}
 	db := &actions{
 		DB: dbtest.NewDB(t, "actions", tables...),
 	}
@@ -109,6 +110,7 @@ func TestActions(t *testing.T) {
 		test func(t *testing.T, db *actions)
 	}{
 		{"CommitRepo", actionsCommitRepo},
+		{"ListActivities", actionsListActivities},
 		{"ListByOrganization", actionsListByOrganization},
 		{"ListByUser", actionsListByUser},
 		{"MergePullRequest", actionsMergePullRequest},
@@ -325,6 +327,158 @@ func actionsCommitRepo(t *testing.T, db *actions) {
 		want[0].Created = time.Unix(want[0].CreatedUnix, 0)
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("auto-watch on first push", func(t *testing.T) {
+		t.Cleanup(func() {
+			err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).WithContext(ctx).Delete(new(Action)).Error
+			require.NoError(t, err)
+		})
+
+		bob, err := NewUsersStore(db.DB).Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+		require.NoError(t, err)
+		cindy, err := NewUsersStore(db.DB).Create(ctx, "cindy", "cindy@example.com", CreateUserOptions{})
+		require.NoError(t, err)
+		disabled := AutoWatchDisabled
+		err = NewUsersStore(db.DB).Update(ctx, cindy.ID, UpdateUserOptions{AutoWatchOnPush: &disabled})
+		require.NoError(t, err)
+
+		push := func(t *testing.T, pusherName string) {
+			t.Helper()
+			err := db.CommitRepo(ctx,
+				CommitRepoOptions{
+					PusherName:  pusherName,
+					Owner:       alice,
+					Repo:        repo,
+					RefFullName: "refs/heads/main",
+					OldCommitID: "ca82a6dff817ec66f44342007202690a93763949",
+					NewCommitID: git.EmptyID,
+				},
+			)
+			require.NoError(t, err)
+		}
+		isWatching := func(t *testing.T, userID int64) bool {
+			t.Helper()
+			watches, err := NewReposStore(db.DB).ListWatches(ctx, repo.ID)
+			require.NoError(t, err)
+			for _, w := range watches {
+				if w.UserID == userID {
+					return true
+				}
+			}
+			return false
+		}
+
+		conf.SetMockUser(t, conf.UserOpts{AutoWatchOnPush: true})
+
+		push(t, bob.Name)
+		assert.True(t, isWatching(t, bob.ID))
+
+		// Pushes after the first one should not subscribe again.
+		push(t, cindy.Name)
+		assert.False(t, isWatching(t, cindy.ID))
+		enabled := AutoWatchEnabled
+		err = NewUsersStore(db.DB).Update(ctx, cindy.ID, UpdateUserOptions{AutoWatchOnPush: &enabled})
+		require.NoError(t, err)
+		push(t, cindy.Name)
+		assert.False(t, isWatching(t, cindy.ID))
+	})
+}
+
+func actionsListActivities(t *testing.T, db *actions) {
+	ctx := context.Background()
+
+	// Organization 1 has a public repository 1 and private repositories 2 and 3,
+	// where the user 3 has access to the repository 2 via a team.
+	for _, repo := range []*Repository{
+		{ID: 1, OwnerID: 1, LowerName: "public", Name: "public"},
+		{ID: 2, OwnerID: 1, LowerName: "private", Name: "private", IsPrivate: true},
+		{ID: 3, OwnerID: 1, LowerName: "secret", Name: "secret", IsPrivate: true},
+		{ID: 4, OwnerID: 2, LowerName: "other", Name: "other"},
+	} {
+		err := db.DB.Create(repo).Error
+		require.NoError(t, err)
+	}
+	err := db.DB.Create(&TeamRepo{OrgID: 1, TeamID: 1, RepoID: 2}).Error
+	require.NoError(t, err)
+	err = db.DB.Create(&TeamUser{OrgID: 1, TeamID: 1, UID: 3}).Error
+	require.NoError(t, err)
+
+	now := db.NowFunc()
+	for _, action := range []*Action{
+		{UserID: 3, ActUserID: 3, OpType: ActionCommitRepo, RepoID: 1, CreatedUnix: now.Add(-3 * time.Hour).Unix()},
+		// A copy of the action for a watcher
+		{UserID: 4, ActUserID: 3, OpType: ActionCommitRepo, RepoID: 1, CreatedUnix: now.Add(-3 * time.Hour).Unix()},
+		{UserID: 3, ActUserID: 3, OpType: ActionCreateIssue, RepoID: 1, CreatedUnix: now.Add(-2 * time.Hour).Unix()},
+		{UserID: 3, ActUserID: 3, OpType: ActionCommitRepo, RepoID: 2, IsPrivate: true, CreatedUnix: now.Add(-time.Hour).Unix()},
+		{UserID: 4, ActUserID: 4, OpType: ActionCommentIssue, RepoID: 3, IsPrivate: true, CreatedUnix: now.Unix()},
+		{UserID: 3, ActUserID: 3, OpType: ActionCommitRepo, RepoID: 4, CreatedUnix: now.Unix()},
+	} {
+		err = db.DB.Create(action).Error
+		require.NoError(t, err)
+	}
+
+	ids := func(actions []*Action) []int64 {
+		ids := make([]int64, len(actions))
+		for i, action := range actions {
+			ids[i] = action.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name    string
+		opts    ListActivitiesOptions
+		wantIDs []int64
+	}{
+		{
+			name:    "repository",
+			opts:    ListActivitiesOptions{RepoID: 1},
+			wantIDs: []int64{3, 1},
+		},
+		{
+			name:    "filter by type",
+			opts:    ListActivitiesOptions{RepoID: 1, OpTypes: []ActionType{ActionCreateIssue, ActionCommentIssue}},
+			wantIDs: []int64{3},
+		},
+		{
+			name: "filter by date",
+			opts: ListActivitiesOptions{
+				Since: now.Add(-150 * time.Minute),
+				Until: now.Add(-30 * time.Minute),
+			},
+			wantIDs: []int64{4, 3},
+		},
+		{
+			name:    "organization viewed by a team member",
+			opts:    ListActivitiesOptions{OrgID: 1, ViewerID: 3},
+			wantIDs: []int64{4, 3, 1},
+		},
+		{
+			name:    "organization viewed by others",
+			opts:    ListActivitiesOptions{OrgID: 1, ViewerID: 4},
+			wantIDs: []int64{3, 1},
+		},
+		{
+			name:    "public actions of the user",
+			opts:    ListActivitiesOptions{ActUserID: 3, OnlyPublic: true},
+			wantIDs: []int64{6, 3, 1},
+		},
+		{
+			name:    "paginate",
+			opts:    ListActivitiesOptions{ActUserID: 3, Page: 2, PageSize: 2},
+			wantIDs: []int64{3, 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if test.opts.PageSize == 0 {
+				test.opts.PageSize = 10
+			}
+			got, err := db.ListActivities(ctx, test.opts)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantIDs, ids(got))
+		})
+	}
 }
 
 func actionsListByOrganization(t *testing.T, db *actions) {
@@ -896,3 +1050,113 @@ func actionsTransferRepo(t *testing.T, db *actions) {
 	want[0].Created = time.Unix(want[0].CreatedUnix, 0)
 	assert.Equal(t, want, got)
 }
+
+func TestActions_refWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	conf.SetMockServer(t, conf.ServerOpts{})
+	conf.SetMockSSH(t, conf.SSHOpts{})
+
+	setTestEngine(t, new(User), new(Repository), new(Webhook), new(HookTask))
+	db := &actions{
+		DB: dbtest.NewDB(t, "actionsRefWebhooks", new(Action), new(User), new(Repository), new(EmailAddress), new(Watch)),
+	}
+
+	alice, err := NewUsersStore(db.DB).Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	repo, err := NewReposStore(db.DB).Create(ctx, alice.ID, CreateRepoOptions{Name: "example"})
+	require.NoError(t, err)
+	_, err = x.Insert(alice, repo)
+	require.NoError(t, err)
+
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents:   HookEvents{Create: true, Delete: true},
+		},
+		IsActive: true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	// A new branch without new commits, e.g. branched off from an existing one
+	err = db.CommitRepo(ctx,
+		CommitRepoOptions{
+			PusherName:  alice.Name,
+			Owner:       alice,
+			Repo:        repo,
+			RefFullName: "refs/heads/feature",
+			OldCommitID: git.EmptyID,
+			NewCommitID: "085bb3bcb608e1e8451d4b2432f8ecbe6306e7e7",
+			Commits:     CommitsToPushCommits(nil),
+		},
+	)
+	require.NoError(t, err)
+	err = db.CommitRepo(ctx,
+		CommitRepoOptions{
+			PusherName:  alice.Name,
+			Owner:       alice,
+			Repo:        repo,
+			RefFullName: "refs/heads/feature",
+			OldCommitID: "085bb3bcb608e1e8451d4b2432f8ecbe6306e7e7",
+			NewCommitID: git.EmptyID,
+			Commits:     CommitsToPushCommits(nil),
+		},
+	)
+	require.NoError(t, err)
+	err = db.PushTag(ctx,
+		PushTagOptions{
+			Owner:       alice,
+			Repo:        repo,
+			PusherName:  alice.Name,
+			RefFullName: "refs/tags/v1.0.0",
+			NewCommitID: "085bb3bcb608e1e8451d4b2432f8ecbe6306e7e7",
+		},
+	)
+	require.NoError(t, err)
+
+	var tasks []*HookTask
+	err = x.Asc("id").Find(&tasks)
+	require.NoError(t, err)
+
+	type refEvent struct {
+		EventType HookEventType
+		Ref       string
+		RefType   string
+		Sender    string
+	}
+	got := make([]refEvent, len(tasks))
+	for i := range tasks {
+		var payload struct {
+			Ref     string `json:"ref"`
+			RefType string `json:"ref_type"`
+			Sender  struct {
+				UserName string `json:"username"`
+			} `json:"sender"`
+		}
+		err = jsoniter.Unmarshal([]byte(tasks[i].PayloadContent), &payload)
+		require.NoError(t, err)
+		got[i] = refEvent{
+			EventType: tasks[i].EventType,
+			Ref:       payload.Ref,
+			RefType:   payload.RefType,
+			Sender:    payload.Sender.UserName,
+		}
+	}
+
+	// The push event is not chosen by the webhook
+	want := []refEvent{
+		{EventType: HOOK_EVENT_CREATE, Ref: "feature", RefType: "branch", Sender: "alice"},
+		{EventType: HOOK_EVENT_DELETE, Ref: "feature", RefType: "branch", Sender: "alice"},
+		{EventType: HOOK_EVENT_CREATE, Ref: "v1.0.0", RefType: "tag", Sender: "alice"},
+	}
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,243 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// ApprovalsStore is the persistent interface for approvals of pull requests.
+type ApprovalsStore interface {
+	// Create approves the pull request with given issue ID by the reviewer at
+	// the commit. It updates the commit when the reviewer has already approved
+	// the pull request.
+	Create(ctx context.Context, repoID, issueID, reviewerID int64, commitID string) error
+	// Delete withdraws the approval of the pull request with given issue ID by
+	// the reviewer. It does nothing when the reviewer has not approved the pull
+	// request.
+	Delete(ctx context.Context, issueID, reviewerID int64) error
+	// ListByIssueID returns all approvals of the pull request with given issue
+	// ID, sorted from the oldest.
+	ListByIssueID(ctx context.Context, issueID int64) ([]*Approval, error)
+}
+
+var Approvals ApprovalsStore
+
+var _ ApprovalsStore = (*approvals)(nil)
+
+type approvals struct {
+	*gorm.DB
+}
+
+// NewApprovalsStore returns a persistent interface for approvals of pull
+// requests with given database connection.
+func NewApprovalsStore(db *gorm.DB) ApprovalsStore {
+	return &approvals{DB: db}
+}
+
+// Approval is an approving review of a pull request by a user.
+type Approval struct {
+	ID         int64  `gorm:"primaryKey"`
+	RepoID     int64  `gorm:"index;not null"`
+	IssueID    int64  `gorm:"uniqueIndex:approval_issue_reviewer_unique;not null"`
+	ReviewerID int64  `gorm:"uniqueIndex:approval_issue_reviewer_unique;index;not null"`
+	CommitID   string `gorm:"type:VARCHAR(40);not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (a *Approval) BeforeCreate(tx *gorm.DB) error {
+	if a.CreatedUnix == 0 {
+		a.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (a *Approval) AfterFind(_ *gorm.DB) error {
+	a.Created = time.Unix(a.CreatedUnix, 0).Local()
+	return nil
+}
+
+func (db *approvals) Create(ctx context.Context, repoID, issueID, reviewerID int64, commitID string) error {
+	return db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "issue_id"}, {Name: "reviewer_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"commit_id", "created_unix"}),
+	}).Create(
+		&Approval{
+			RepoID:     repoID,
+			IssueID:    issueID,
+			ReviewerID: reviewerID,
+			CommitID:   commitID,
+		},
+	).Error
+}
+
+func (db *approvals) Delete(ctx context.Context, issueID, reviewerID int64) error {
+	return db.WithContext(ctx).
+		Where("issue_id = ? AND reviewer_id = ?", issueID, reviewerID).
+		Delete(&Approval{}).
+		Error
+}
+
+func (db *approvals) ListByIssueID(ctx context.Context, issueID int64) ([]*Approval, error) {
+	var approvals []*Approval
+	return approvals, db.WithContext(ctx).
+		Where("issue_id = ?", issueID).
+		Order("id ASC").
+		Find(&approvals).
+		Error
+}
+
+type ErrApprovalNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrApprovalNotAllowed(err error) bool {
+	_, ok := errors.Cause(err).(ErrApprovalNotAllowed)
+	return ok
+}
+
+func (err ErrApprovalNotAllowed) Error() string {
+	return fmt.Sprintf("user cannot approve the pull request: %v", err.args)
+}
+
+// Approve approves the pull request by the doer at the latest commit of its
+// head. It returns ErrApprovalNotAllowed when the doer is the author of the
+// pull request. This method assumes the Issue field is loaded.
+func (pr *PullRequest) Approve(ctx context.Context, doer *User) error {
+	if doer.ID == pr.Issue.PosterID {
+		return ErrApprovalNotAllowed{args: errutil.Args{"pullRequestID": pr.ID, "userID": doer.ID}}
+	}
+	commitID, err := pr.headCommitID()
+	if err != nil {
+		return errors.Wrap(err, "get head commit ID")
+	}
+	err = Approvals.Create(ctx, pr.BaseRepoID, pr.IssueID, doer.ID, commitID)
+	if err != nil {
+		return err
+	}
+
+	// The approval may be the last requirement for auto-merge.
+	pr.updateAutoMerge()
+	return nil
+}
+
+// Unapprove withdraws the approval of the pull request by the doer, and
+// cancels auto-merge of the pull request if enabled.
+func (pr *PullRequest) Unapprove(ctx context.Context, doer *User) error {
+	err := Approvals.Delete(ctx, pr.IssueID, doer.ID)
+	if err != nil {
+		return err
+	}
+
+	if pr.IsAutoMergeEnabled() {
+		if err = pr.CancelAutoMerge(); err != nil {
+			return errors.Wrap(err, "cancel auto-merge")
+		}
+	}
+	return nil
+}
+
+// Approvers returns users who have approved the pull request, sorted by the
+// time they approved. Users who no longer exist are skipped.
+func (pr *PullRequest) Approvers(ctx context.Context) ([]*User, error) {
+	approvals, err := Approvals.ListByIssueID(ctx, pr.IssueID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list approvals")
+	}
+
+	users := make([]*User, 0, len(approvals))
+	for _, a := range approvals {
+		u, err := Users.GetByID(ctx, a.ReviewerID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "get user by ID %d", a.ReviewerID)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// currentApprovals returns approvals of the pull request at its latest commit,
+// approvals at older commits no longer count after new commits are pushed.
+func (pr *PullRequest) currentApprovals(ctx context.Context) ([]*Approval, error) {
+	commitID, err := pr.headCommitID()
+	if err != nil {
+		return nil, errors.Wrap(err, "get head commit ID")
+	}
+	approvals, err := Approvals.ListByIssueID(ctx, pr.IssueID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list approvals")
+	}
+
+	current := approvals[:0]
+	for _, a := range approvals {
+		if a.CommitID == commitID {
+			current = append(current, a)
+		}
+	}
+	return current, nil
+}
+
+// MissingApprovals returns the number of approvals at the latest commit that
+// the pull request still needs by the base branch before merging.
+func (pr *PullRequest) MissingApprovals(ctx context.Context) (int, error) {
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		if IsErrBranchNotExist(err) {
+			return 0, nil
+		}
+		return 0, errors.Wrap(err, "get protect branch")
+	} else if !protectBranch.Protected || protectBranch.RequiredApprovals <= 0 {
+		return 0, nil
+	}
+
+	approvals, err := pr.currentApprovals(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if missing := protectBranch.RequiredApprovals - len(approvals); missing > 0 {
+		return missing, nil
+	}
+	return 0, nil
+}
+
+type ErrApprovalsRequired struct {
+	args errutil.Args
+}
+
+func IsErrApprovalsRequired(err error) bool {
+	_, ok := err.(ErrApprovalsRequired)
+	return ok
+}
+
+func (err ErrApprovalsRequired) Error() string {
+	return fmt.Sprintf("pull request does not have enough approvals: %v", err.args)
+}
+
+// checkRequiredApprovals returns ErrApprovalsRequired when the pull request
+// does not have enough approvals at its latest commit by the base branch.
+func (pr *PullRequest) checkRequiredApprovals(ctx context.Context) error {
+	missing, err := pr.MissingApprovals(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get missing approvals")
+	} else if missing > 0 {
+		return ErrApprovalsRequired{args: errutil.Args{"pullRequestID": pr.ID, "missing": missing}}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -27,6 +29,7 @@ type Attachment struct {
 	CommentID int64
 	ReleaseID int64 `xorm:"INDEX"`
 	Name      string
+	SHA256    string `xorm:"sha256 VARCHAR(64)"` // Hex-encoded SHA-256 checksum of the content
 
 	Created     time.Time `xorm:"-" json:"-"`
 	CreatedUnix int64
@@ -71,11 +74,15 @@ func NewAttachment(name string, buf []byte, file multipart.File) (_ *Attachment,
 	}
 	defer fw.Close()
 
-	if _, err = fw.Write(buf); err != nil {
+	// Hash the content while writing to avoid reading the file again.
+	hash := sha256.New()
+	w := io.MultiWriter(fw, hash)
+	if _, err = w.Write(buf); err != nil {
 		return nil, fmt.Errorf("Write: %v", err)
-	} else if _, err = io.Copy(fw, file); err != nil {
+	} else if _, err = io.Copy(w, file); err != nil {
 		return nil, fmt.Errorf("Copy: %v", err)
 	}
+	attach.SHA256 = hex.EncodeToString(hash.Sum(nil))
 
 	if _, err := x.Insert(attach); err != nil {
 		return nil, err
@@ -124,6 +131,18 @@ func getAttachmentsByUUIDs(e Engine, uuids []string) ([]*Attachment, error) {
 	return attachments, e.In("uuid", uuids).Find(&attachments)
 }
 
+// GetAttachmentByID returns attachment by given ID.
+func GetAttachmentByID(id int64) (*Attachment, error) {
+	attach := new(Attachment)
+	has, err := x.ID(id).Get(attach)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAttachmentNotExist{args: map[string]any{"attachmentID": id}}
+	}
+	return attach, nil
+}
+
 // GetAttachmentByUUID returns attachment by given UUID.
 func GetAttachmentByUUID(uuid string) (*Attachment, error) {
 	return getAttachmentByUUID(x, uuid)
@@ -0,0 +1,61 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestAttachmentChecksums(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Attachment))
+
+	before := conf.Attachment.Path
+	conf.Attachment.Path = t.TempDir()
+	t.Cleanup(func() {
+		conf.Attachment.Path = before
+	})
+
+	newAttachment := func(t *testing.T, name, head, rest string) *Attachment {
+		path := filepath.Join(t.TempDir(), name)
+		err := os.WriteFile(path, []byte(rest), 0o644)
+		require.NoError(t, err)
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		attach, err := NewAttachment(name, []byte(head), f)
+		require.NoError(t, err)
+		return attach
+	}
+
+	// The checksum covers both the sniffed head and the rest of the content
+	hello := newAttachment(t, "hello.txt", "hello ", "world\n")
+	assert.Equal(t, "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447", hello.SHA256)
+
+	got, err := GetAttachmentByUUID(hello.UUID)
+	require.NoError(t, err)
+	assert.Equal(t, hello.SHA256, got.SHA256)
+
+	// Attachments whose checksums could not be backfilled are omitted
+	empty := newAttachment(t, "empty.txt", "", "")
+	assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", empty.SHA256)
+	missing := &Attachment{Name: "missing.txt"}
+
+	r := &Release{Attachments: []*Attachment{hello, missing, empty}}
+	want := "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447  hello.txt\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  empty.txt\n"
+	assert.Equal(t, want, string(r.SHA256Sums()))
+}
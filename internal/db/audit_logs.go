@@ -0,0 +1,203 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+)
+
+// AuditLogsStore is the persistent interface for audit logs of sensitive
+// operations. Audit logs are immutable once created.
+type AuditLogsStore interface {
+	// Create creates a new audit log with given options.
+	Create(ctx context.Context, opts CreateAuditLogOptions) (*AuditLog, error)
+	// List returns a page of audit logs matching given options, sorted from the
+	// newest, and the total number of matching audit logs.
+	List(ctx context.Context, opts ListAuditLogsOptions) ([]*AuditLog, int64, error)
+}
+
+var AuditLogs AuditLogsStore
+
+var _ AuditLogsStore = (*auditLogs)(nil)
+
+type auditLogs struct {
+	*gorm.DB
+}
+
+// NewAuditLogsStore returns a persistent interface for audit logs with given
+// database connection.
+func NewAuditLogsStore(db *gorm.DB) AuditLogsStore {
+	return &auditLogs{DB: db}
+}
+
+// AuditAction is the type of sensitive operation recorded by an audit log.
+type AuditAction string
+
+const (
+	AuditActionUserCreate         AuditAction = "user.create"
+	AuditActionUserDelete         AuditAction = "user.delete"
+	AuditActionUserGrantAdmin     AuditAction = "user.grant_admin"
+	AuditActionUserRevokeAdmin    AuditAction = "user.revoke_admin"
+	AuditActionRepoDelete         AuditAction = "repo.delete"
+	AuditActionRepoTransfer       AuditAction = "repo.transfer"
+	AuditActionCollaboratorAdd    AuditAction = "repo.collaborator_add"
+	AuditActionCollaboratorEdit   AuditAction = "repo.collaborator_edit"
+	AuditActionCollaboratorRemove AuditAction = "repo.collaborator_remove"
+	AuditActionLoginSourceCreate  AuditAction = "login_source.create"
+	AuditActionLoginSourceUpdate  AuditAction = "login_source.update"
+	AuditActionLoginSourceDelete  AuditAction = "login_source.delete"
+	AuditActionAccessTokenCreate  AuditAction = "access_token.create"
+)
+
+// AuditLog is a record of a sensitive operation.
+type AuditLog struct {
+	ID int64 `gorm:"primaryKey"`
+	// The ID and name of the user who performed the operation. The ID is zero
+	// for operations that are not performed by a signed-in user, e.g. from the
+	// command line.
+	ActorID   int64       `gorm:"index;not null"`
+	ActorName string      `gorm:"not null"`
+	Action    AuditAction `gorm:"index;not null"`
+	// The ID and name of the object of the operation, i.e. a user, a repository,
+	// a login source or an access token, depending on the action.
+	TargetID   int64  `gorm:"not null"`
+	TargetName string `gorm:"not null"`
+	// Additional information of the operation, e.g. the new access mode of a
+	// collaborator.
+	Content string `gorm:"type:TEXT"`
+	IP      string `gorm:"not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64     `gorm:"index"`
+}
+
+// BeforeCreate implements the GORM create hook.
+func (l *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if l.CreatedUnix == 0 {
+		l.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (l *AuditLog) AfterFind(_ *gorm.DB) error {
+	l.Created = time.Unix(l.CreatedUnix, 0).Local()
+	return nil
+}
+
+type CreateAuditLogOptions struct {
+	ActorID    int64
+	ActorName  string
+	Action     AuditAction
+	TargetID   int64
+	TargetName string
+	Content    string
+	IP         string
+}
+
+func (db *auditLogs) Create(ctx context.Context, opts CreateAuditLogOptions) (*AuditLog, error) {
+	l := &AuditLog{
+		ActorID:    opts.ActorID,
+		ActorName:  opts.ActorName,
+		Action:     opts.Action,
+		TargetID:   opts.TargetID,
+		TargetName: opts.TargetName,
+		Content:    opts.Content,
+		IP:         opts.IP,
+	}
+	return l, db.WithContext(ctx).Create(l).Error
+}
+
+type ListAuditLogsOptions struct {
+	// The ID of the actor, zero means any actor.
+	ActorID int64
+	// The action, empty means any action.
+	Action AuditAction
+	// The time range of creation, zero means unbounded.
+	Since time.Time
+	Until time.Time
+
+	Page     int
+	PageSize int
+}
+
+func (db *auditLogs) List(ctx context.Context, opts ListAuditLogsOptions) ([]*AuditLog, int64, error) {
+	query := db.WithContext(ctx).Model(new(AuditLog))
+	if opts.ActorID > 0 {
+		query = query.Where("actor_id = ?", opts.ActorID)
+	}
+	if opts.Action != "" {
+		query = query.Where("action = ?", opts.Action)
+	}
+	if !opts.Since.IsZero() {
+		query = query.Where("created_unix >= ?", opts.Since.Unix())
+	}
+	if !opts.Until.IsZero() {
+		query = query.Where("created_unix < ?", opts.Until.Unix())
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	if err != nil {
+		return nil, 0, errors.Wrap(err, "count")
+	}
+
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	logs := make([]*AuditLog, 0, opts.PageSize)
+	return logs, count, query.
+		Order("id DESC").
+		Limit(opts.PageSize).Offset((opts.Page - 1) * opts.PageSize).
+		Find(&logs).
+		Error
+}
+
+type auditActorKey struct{}
+
+// AuditActor is the user who performs operations in a context, along with the
+// IP address the operations come from.
+type AuditActor struct {
+	ID   int64
+	Name string
+	IP   string
+}
+
+// WithAuditActor returns a copy of the context with the actor to be recorded by
+// audit logs of operations performed with the returned context.
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// recordAudit creates an audit log with given store for the actor in the
+// context. Failures are logged rather than returned, so that auditing never
+// breaks the audited operation.
+func recordAudit(ctx context.Context, store AuditLogsStore, action AuditAction, targetID int64, targetName, content string) {
+	actor, _ := ctx.Value(auditActorKey{}).(AuditActor)
+	_, err := store.Create(ctx, CreateAuditLogOptions{
+		ActorID:    actor.ID,
+		ActorName:  actor.Name,
+		Action:     action,
+		TargetID:   targetID,
+		TargetName: targetName,
+		Content:    content,
+		IP:         actor.IP,
+	})
+	if err != nil {
+		log.Error("Failed to record audit log [action: %s, target_id: %d]: %v", action, targetID, err)
+	}
+}
+
+// RecordAudit creates an audit log for the actor in the context for sensitive
+// operations that are not performed through a store. Failures are logged rather
+// than returned.
+func RecordAudit(ctx context.Context, action AuditAction, targetID int64, targetName, content string) {
+	recordAudit(ctx, AuditLogs, action, targetID, targetName, content)
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestAuditLogs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(AuditLog)}
+	db := &auditLogs{
+		DB: dbtest.NewDB(t, "auditLogs", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *auditLogs)
+	}{
+		{"Create", auditLogsCreate},
+		{"List", auditLogsList},
+		{"recordAudit", auditLogsRecordAudit},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func auditLogsCreate(t *testing.T, db *auditLogs) {
+	ctx := context.Background()
+
+	l, err := db.Create(ctx,
+		CreateAuditLogOptions{
+			ActorID:    1,
+			ActorName:  "alice",
+			Action:     AuditActionUserDelete,
+			TargetID:   2,
+			TargetName: "bob",
+			IP:         "127.0.0.1",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, db.NowFunc().Unix(), l.CreatedUnix)
+
+	logs, count, err := db.List(ctx, ListAuditLogsOptions{PageSize: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	require.Len(t, logs, 1)
+	assert.Equal(t, AuditActionUserDelete, logs[0].Action)
+	assert.Equal(t, "bob", logs[0].TargetName)
+	assert.Equal(t, l.CreatedUnix, logs[0].Created.Unix())
+}
+
+func auditLogsList(t *testing.T, db *auditLogs) {
+	ctx := context.Background()
+
+	now := db.NowFunc()
+	for _, l := range []*AuditLog{
+		{ActorID: 1, Action: AuditActionUserCreate, CreatedUnix: now.Add(-3 * time.Hour).Unix()},
+		{ActorID: 1, Action: AuditActionUserDelete, CreatedUnix: now.Add(-2 * time.Hour).Unix()},
+		{ActorID: 2, Action: AuditActionUserDelete, CreatedUnix: now.Add(-time.Hour).Unix()},
+		{ActorID: 1, Action: AuditActionRepoDelete, CreatedUnix: now.Unix()},
+	} {
+		err := db.DB.Create(l).Error
+		require.NoError(t, err)
+	}
+
+	actions := func(logs []*AuditLog) []AuditAction {
+		actions := make([]AuditAction, len(logs))
+		for i, l := range logs {
+			actions[i] = l.Action
+		}
+		return actions
+	}
+
+	tests := []struct {
+		name        string
+		opts        ListAuditLogsOptions
+		wantCount   int64
+		wantActions []AuditAction
+	}{
+		{
+			name:        "all",
+			opts:        ListAuditLogsOptions{PageSize: 10},
+			wantCount:   4,
+			wantActions: []AuditAction{AuditActionRepoDelete, AuditActionUserDelete, AuditActionUserDelete, AuditActionUserCreate},
+		},
+		{
+			name:        "filter by actor",
+			opts:        ListAuditLogsOptions{ActorID: 2, PageSize: 10},
+			wantCount:   1,
+			wantActions: []AuditAction{AuditActionUserDelete},
+		},
+		{
+			name:        "filter by action",
+			opts:        ListAuditLogsOptions{Action: AuditActionUserDelete, PageSize: 10},
+			wantCount:   2,
+			wantActions: []AuditAction{AuditActionUserDelete, AuditActionUserDelete},
+		},
+		{
+			name: "filter by date",
+			opts: ListAuditLogsOptions{
+				Since:    now.Add(-150 * time.Minute),
+				Until:    now.Add(-30 * time.Minute),
+				PageSize: 10,
+			},
+			wantCount:   2,
+			wantActions: []AuditAction{AuditActionUserDelete, AuditActionUserDelete},
+		},
+		{
+			name:        "paginate",
+			opts:        ListAuditLogsOptions{ActorID: 1, Page: 2, PageSize: 2},
+			wantCount:   3,
+			wantActions: []AuditAction{AuditActionUserCreate},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logs, count, err := db.List(ctx, test.opts)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantCount, count)
+			assert.Equal(t, test.wantActions, actions(logs))
+		})
+	}
+}
+
+func auditLogsRecordAudit(t *testing.T, db *auditLogs) {
+	ctx := context.Background()
+
+	// Operations without an actor are recorded as well
+	recordAudit(ctx, db, AuditActionUserCreate, 1, "alice", "")
+
+	ctx = WithAuditActor(ctx, AuditActor{ID: 1, Name: "alice", IP: "127.0.0.1"})
+	recordAudit(ctx, db, AuditActionAccessTokenCreate, 2, "token", "")
+
+	logs, _, err := db.List(ctx, ListAuditLogsOptions{PageSize: 10})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	assert.Equal(t, int64(1), logs[0].ActorID)
+	assert.Equal(t, "alice", logs[0].ActorName)
+	assert.Equal(t, "127.0.0.1", logs[0].IP)
+	assert.Equal(t, int64(0), logs[1].ActorID)
+	assert.Empty(t, logs[1].IP)
+}
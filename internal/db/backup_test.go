@@ -31,7 +31,7 @@ func TestDumpAndImport(t *testing.T) {
 	}
 	t.Parallel()
 
-	const wantTables = 8
+	const wantTables = 27
 	if len(Tables) != wantTables {
 		t.Fatalf("New table has added (want %d got %d), please add new tests for the table and update this check", wantTables, len(Tables))
 	}
@@ -132,6 +132,91 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			CreatedUnix:  1588568886,
 		},
 
+		&Approval{
+			ID:          1,
+			RepoID:      1,
+			IssueID:     1,
+			ReviewerID:  2,
+			CommitID:    "4eaa8c1fb2ca4a2b2d4b0e1fdd4fa2dd8e3e5d5b",
+			CreatedUnix: 1588568886,
+		},
+
+		&AuditLog{
+			ID:          1,
+			ActorID:     1,
+			ActorName:   "alice",
+			Action:      AuditActionUserDelete,
+			TargetID:    2,
+			TargetName:  "bob",
+			IP:          "127.0.0.1",
+			CreatedUnix: 1588568886,
+		},
+
+		&CLASignature{
+			ID:          1,
+			RepoID:      1,
+			UserID:      2,
+			CreatedUnix: 1588568886,
+		},
+
+		&CommentHistory{
+			CommentID:   1,
+			EditorID:    1,
+			Content:     "The original content",
+			CreatedUnix: 1588568886,
+		},
+		&CommentHistory{
+			CommentID:   1,
+			EditorID:    2,
+			Content:     "The edited content",
+			CreatedUnix: 1588572486, // 1 hour later
+		},
+
+		&CommitStatus{
+			ID:          1,
+			RepoID:      1,
+			CommitID:    "d8e8fca2dc0f896fd7cb4cb0031ba249d8e8fca2",
+			Context:     "ci/build",
+			CreatorID:   1,
+			State:       CommitStatusSuccess,
+			TargetURL:   "https://ci.example.com/builds/1",
+			Description: "The build succeeded",
+			CreatedUnix: 1588568886,
+			UpdatedUnix: 1588572486,
+		},
+
+		&Deployment{
+			ID:          1,
+			RepoID:      1,
+			CreatorID:   1,
+			Ref:         "main",
+			SHA:         "d8e8fca2dc0f896fd7cb4cb0031ba249d8e8fca2",
+			Task:        "deploy",
+			Environment: "production",
+			Payload:     `{"region":"us-east-1"}`,
+			State:       DeploymentStateSuccess,
+			CreatedUnix: 1588568886,
+			UpdatedUnix: 1588572486,
+		},
+
+		&DeploymentStatus{
+			ID:           1,
+			RepoID:       1,
+			DeploymentID: 1,
+			CreatorID:    1,
+			State:        DeploymentStatePending,
+			CreatedUnix:  1588568886,
+		},
+		&DeploymentStatus{
+			ID:           2,
+			RepoID:       1,
+			DeploymentID: 1,
+			CreatorID:    1,
+			State:        DeploymentStateSuccess,
+			TargetURL:    "https://example.com/deployments/1",
+			CreatedUnix:  1588572486,
+		},
+
 		&EmailAddress{
 			ID:          1,
 			UserID:      1,
@@ -156,6 +241,26 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			FollowID: 1,
 		},
 
+		&IgnoredRepo{
+			ID:     1,
+			UserID: 2,
+			RepoID: 1,
+		},
+
+		&IssueWorkflowState{
+			ID:     1,
+			RepoID: 1,
+			Name:   "In progress",
+			Color:  "#fbca04",
+		},
+		&IssueWorkflowState{
+			ID:       2,
+			RepoID:   1,
+			Name:     "Won't fix",
+			Color:    "#ffffff",
+			IsClosed: true,
+		},
+
 		&LFSObject{
 			RepoID:    1,
 			OID:       "ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f",
@@ -171,6 +276,14 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			CreatedAt: time.Unix(1588568886, 0).UTC(),
 		},
 
+		&LinkedIssue{
+			ID:            1,
+			RepoID:        1,
+			PullRequestID: 1,
+			IssueID:       2,
+			CreatedUnix:   1588568886,
+		},
+
 		&LoginSource{
 			Type:      auth.PAM,
 			Name:      "My PAM",
@@ -197,6 +310,106 @@ func setupDBToDump(t *testing.T, db *gorm.DB) {
 			Description: "This is a notice",
 			CreatedUnix: 1588568886,
 		},
+
+		&OrgInvitation{
+			ID:          1,
+			OrgID:       2,
+			InviterID:   1,
+			InviteeID:   3,
+			CreatedUnix: 1588568886,
+			ExpiresUnix: 1589173686,
+		},
+
+		&OrgMirror{
+			ID:                    1,
+			OrgID:                 2,
+			UpstreamURL:           "https://github.com/gogs",
+			AuthUsername:          "alice",
+			EncryptedAuthPassword: "GJU9KW4ruxjzVxQ6dMhSiVxb9IbbKTGbtkBaKSTxyxE7",
+			NamePrefix:            "gogs-",
+			CreatedByID:           1,
+			CreatedUnix:           1588568886,
+			LastScannedUnix:       1588572486,
+		},
+
+		&RepoInvitation{
+			ID:          1,
+			RepoID:      1,
+			InviterID:   1,
+			InviteeID:   2,
+			Mode:        AccessModeWrite,
+			CreatedUnix: 1588568886,
+			ExpiresUnix: 1589173686,
+		},
+
+		&RepoLanguage{
+			ID:        1,
+			RepoID:    1,
+			Language:  "Go",
+			Bytes:     4096,
+			CommitSHA: "5df3713c6eebb8b81413a4ac29a7572fbf8bc6a1",
+		},
+		&RepoLanguage{
+			ID:        2,
+			RepoID:    1,
+			Language:  "JavaScript",
+			Bytes:     1024,
+			CommitSHA: "5df3713c6eebb8b81413a4ac29a7572fbf8bc6a1",
+		},
+
+		&RepoNotificationRecipient{
+			ID:          1,
+			RepoID:      1,
+			Email:       "dev@lists.example.com",
+			Events:      "issue,pull_request",
+			CreatedUnix: 1588568886,
+		},
+
+		&RepoSecret{
+			ID:             1,
+			RepoID:         1,
+			Name:           "DEPLOY_TOKEN",
+			EncryptedValue: "bW9jay1lbmNyeXB0ZWQtdmFsdWU=",
+			CreatedUnix:    1588568886,
+			UpdatedUnix:    1588568886,
+		},
+		&RepoSecret{
+			ID:             2,
+			OrgID:          2,
+			Name:           "NPM_TOKEN",
+			EncryptedValue: "bW9jay1lbmNyeXB0ZWQtdmFsdWU=",
+			CreatedUnix:    1588568886,
+			UpdatedUnix:    1588568886,
+		},
+
+		&RepoTopic{
+			ID:     1,
+			RepoID: 1,
+			Name:   "go",
+		},
+		&RepoTopic{
+			ID:     2,
+			RepoID: 1,
+			Name:   "web",
+		},
+
+		&ReviewRequest{
+			ID:          1,
+			RepoID:      1,
+			IssueID:     1,
+			ReviewerID:  2,
+			CreatedUnix: 1588568886,
+		},
+
+		&UserSession{
+			ID:            1,
+			UserID:        1,
+			SessionIDHash: cryptoutil.SHA256("c6c1fd8e5d9d8b0c"),
+			UserAgent:     "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0",
+			IP:            "127.0.0.1",
+			CreatedUnix:   1588568886,
+			LastSeenUnix:  1588572486,
+		},
 	}
 	for _, val := range vals {
 		err := db.Create(val).Error
@@ -0,0 +1,146 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/tool"
+)
+
+// CLASignaturesStore is the persistent interface for signatures of contributor
+// license agreements (CLA) of repositories.
+type CLASignaturesStore interface {
+	// Sign records that the user has signed the CLA of the repository at the
+	// current time and returns the signature. Signing again is a no-op and
+	// returns the existing signature.
+	Sign(ctx context.Context, repoID, userID int64) (*CLASignature, error)
+	// Get returns the signature of the CLA of the repository by the user. It
+	// returns ErrCLASignatureNotExist when not found.
+	Get(ctx context.Context, repoID, userID int64) (*CLASignature, error)
+}
+
+var CLASignatures CLASignaturesStore
+
+var _ CLASignaturesStore = (*claSignatures)(nil)
+
+type claSignatures struct {
+	*gorm.DB
+}
+
+// NewCLASignaturesStore returns a persistent interface for signatures of
+// contributor license agreements with given database connection.
+func NewCLASignaturesStore(db *gorm.DB) CLASignaturesStore {
+	return &claSignatures{DB: db}
+}
+
+// CLASignature is a signature of the contributor license agreement of a
+// repository by a user.
+type CLASignature struct {
+	ID     int64 `gorm:"primaryKey"`
+	RepoID int64 `gorm:"uniqueIndex:cla_signature_repo_user_unique;not null"`
+	UserID int64 `gorm:"uniqueIndex:cla_signature_repo_user_unique;index;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (s *CLASignature) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedUnix == 0 {
+		s.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (s *CLASignature) AfterFind(_ *gorm.DB) error {
+	s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	return nil
+}
+
+func (db *claSignatures) Sign(ctx context.Context, repoID, userID int64) (*CLASignature, error) {
+	s := &CLASignature{
+		RepoID: repoID,
+		UserID: userID,
+	}
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(s).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "create")
+	}
+	return db.Get(ctx, repoID, userID)
+}
+
+var _ errutil.NotFound = (*ErrCLASignatureNotExist)(nil)
+
+type ErrCLASignatureNotExist struct {
+	args errutil.Args
+}
+
+// IsErrCLASignatureNotExist returns true if the underlying error has the type
+// ErrCLASignatureNotExist.
+func IsErrCLASignatureNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrCLASignatureNotExist)
+	return ok
+}
+
+func (err ErrCLASignatureNotExist) Error() string {
+	return fmt.Sprintf("CLA signature does not exist: %v", err.args)
+}
+
+func (ErrCLASignatureNotExist) NotFound() bool {
+	return true
+}
+
+func (db *claSignatures) Get(ctx context.Context, repoID, userID int64) (*CLASignature, error) {
+	s := new(CLASignature)
+	err := db.WithContext(ctx).Where("repo_id = ? AND user_id = ?", repoID, userID).First(s).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrCLASignatureNotExist{args: errutil.Args{"repoID": repoID, "userID": userID}}
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+// CLAAllowlistUserIDList returns the list of IDs of users who are exempted from
+// signing the CLA of the repository.
+func (repo *Repository) CLAAllowlistUserIDList() []int64 {
+	return tool.StringsToInt64s(strings.Split(repo.CLAAllowlistUserIDs, ","))
+}
+
+// IsCLASignatureRequired returns true if the user has to sign the CLA of the
+// repository before their pull requests can be merged.
+func (repo *Repository) IsCLASignatureRequired(ctx context.Context, userID int64) (bool, error) {
+	if !repo.RequireCLA || userID == repo.OwnerID {
+		return false, nil
+	}
+
+	for _, id := range repo.CLAAllowlistUserIDList() {
+		if id == userID {
+			return false, nil
+		}
+	}
+	if repo.CLAExemptOrgMembers && IsOrganizationMember(repo.OwnerID, userID) {
+		return false, nil
+	}
+
+	_, err := CLASignatures.Get(ctx, repo.ID, userID)
+	if err == nil {
+		return false, nil
+	} else if !IsErrCLASignatureNotExist(err) {
+		return false, errors.Wrap(err, "get CLA signature")
+	}
+	return true, nil
+}
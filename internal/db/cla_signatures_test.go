@@ -0,0 +1,148 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestCLASignatures(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(CLASignature)}
+	db := &claSignatures{
+		DB: dbtest.NewDB(t, "claSignatures", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *claSignatures)
+	}{
+		{"Sign", claSignaturesSign},
+		{"Get", claSignaturesGet},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func claSignaturesSign(t *testing.T, db *claSignatures) {
+	ctx := context.Background()
+
+	s, err := db.Sign(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), s.RepoID)
+	assert.Equal(t, int64(2), s.UserID)
+	assert.Equal(t, db.NowFunc().Unix(), s.CreatedUnix)
+
+	// Signing again should return the existing signature
+	got, err := db.Sign(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, got.ID)
+
+	var count int64
+	err = db.Model(new(CLASignature)).Count(&count).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func claSignaturesGet(t *testing.T, db *claSignatures) {
+	ctx := context.Background()
+
+	_, err := db.Get(ctx, 1, 2)
+	wantErr := ErrCLASignatureNotExist{args: errutil.Args{"repoID": int64(1), "userID": int64(2)}}
+	assert.Equal(t, wantErr, err)
+
+	s, err := db.Sign(ctx, 1, 2)
+	require.NoError(t, err)
+
+	got, err := db.Get(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, got.ID)
+
+	// The signature is only for the repository
+	_, err = db.Get(ctx, 2, 2)
+	assert.True(t, IsErrCLASignatureNotExist(err))
+}
+
+func TestRepository_IsCLASignatureRequired(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(OrgUser))
+	before := CLASignatures
+	CLASignatures = NewCLASignaturesStore(dbtest.NewDB(t, "isCLASignatureRequired", new(CLASignature)))
+	t.Cleanup(func() {
+		CLASignatures = before
+	})
+
+	ctx := context.Background()
+	const (
+		orgID    = 1
+		memberID = 2
+		authorID = 3
+		allowed  = 4
+	)
+	_, err := x.Insert(&OrgUser{OrgID: orgID, Uid: memberID})
+	require.NoError(t, err)
+
+	repo := &Repository{
+		ID:                  1,
+		OwnerID:             orgID,
+		RequireCLA:          true,
+		CLADocumentURL:      "https://example.com/cla",
+		CLAExemptOrgMembers: true,
+		CLAAllowlistUserIDs: "4",
+	}
+
+	// Pull requests of the author are blocked until the CLA is signed
+	required, err := repo.IsCLASignatureRequired(ctx, authorID)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	_, err = CLASignatures.Sign(ctx, repo.ID, authorID)
+	require.NoError(t, err)
+	required, err = repo.IsCLASignatureRequired(ctx, authorID)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	// Organization members and allowlisted users are exempted
+	for _, userID := range []int64{memberID, allowed} {
+		required, err = repo.IsCLASignatureRequired(ctx, userID)
+		require.NoError(t, err)
+		assert.False(t, required, "user %d", userID)
+	}
+
+	// Organization members are required to sign when not exempted
+	repo.CLAExemptOrgMembers = false
+	required, err = repo.IsCLASignatureRequired(ctx, memberID)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	// Nothing is required when the CLA is not enabled
+	repo.RequireCLA = false
+	required, err = repo.IsCLASignatureRequired(ctx, memberID)
+	require.NoError(t, err)
+	assert.False(t, required)
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/gogs/git-module"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/pathutil"
+)
+
+// CodeOwnersCandidates are the paths of the file in the base branch that
+// assigns owners to paths of the repository, e.g.
+//
+//	*.go     @alice
+//	/docs/   @acme/writers bob@example.com
+var CodeOwnersCandidates = []string{
+	"CODEOWNERS",
+	".gogs/CODEOWNERS",
+	".github/CODEOWNERS",
+	"docs/CODEOWNERS",
+}
+
+// codeOwnersRule is a line of CODEOWNERS file that assigns owners to paths
+// matching the pattern.
+type codeOwnersRule struct {
+	pattern string
+	owners  []string
+}
+
+// parseCodeOwners parses rules of the CODEOWNERS file. Blank lines, comments
+// and rules with invalid patterns are skipped.
+func parseCodeOwners(data []byte) []*codeOwnersRule {
+	var rules []*codeOwnersRule
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if _, err := pathutil.MatchGlob(strings.Trim(fields[0], "/"), ""); err != nil {
+			continue
+		}
+		rules = append(rules, &codeOwnersRule{
+			pattern: fields[0],
+			owners:  fields[1:],
+		})
+	}
+	return rules
+}
+
+// matchCodeOwnersPattern returns true if the file matches the pattern of
+// CODEOWNERS file, which follows the rules of ".gitignore". Patterns with a
+// leading or middle slash are relative to the root of the repository, and other
+// patterns match at any depth. Patterns that match a directory match all files
+// under it.
+func matchCodeOwnersPattern(pattern, file string) bool {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	glob := strings.Trim(pattern, "/")
+	if !anchored && !strings.HasPrefix(glob, "**") {
+		glob = "**/" + glob
+	}
+
+	if !strings.HasSuffix(pattern, "/") {
+		if matched, _ := pathutil.MatchGlob(glob, file); matched {
+			return true
+		}
+	}
+	matched, _ := pathutil.MatchGlob(glob+"/**", file)
+	return matched
+}
+
+// codeOwnersOf returns owners of the file by the last rule that matches it.
+func codeOwnersOf(rules []*codeOwnersRule, file string) []string {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if matchCodeOwnersPattern(rules[i].pattern, file) {
+			return rules[i].owners
+		}
+	}
+	return nil
+}
+
+type ErrCodeOwnerReviewRequired struct {
+	args errutil.Args
+}
+
+func IsErrCodeOwnerReviewRequired(err error) bool {
+	_, ok := err.(ErrCodeOwnerReviewRequired)
+	return ok
+}
+
+func (err ErrCodeOwnerReviewRequired) Error() string {
+	return fmt.Sprintf("pull request requires approvals from code owners: %v", err.args)
+}
+
+// codeOwnerRules returns rules of the CODEOWNERS file in the base branch of the
+// pull request, or nil if there is none.
+func (pr *PullRequest) codeOwnerRules(baseGitRepo *git.Repository) ([]*codeOwnersRule, error) {
+	commit, err := baseGitRepo.BranchCommit(pr.BaseBranch)
+	if err != nil {
+		return nil, errors.Wrap(err, "get base branch commit")
+	}
+
+	for _, candidate := range CodeOwnersCandidates {
+		blob, err := commit.Blob(candidate)
+		if err != nil {
+			continue
+		}
+		p, err := blob.Bytes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %q", candidate)
+		}
+		return parseCodeOwners(p), nil
+	}
+	return nil, nil
+}
+
+// codeOwnerApproved returns true if the owner of CODEOWNERS file, i.e. a user
+// by "@username" or email, or a team of the owner organization of the base
+// repository by "@org/team", is one of or contains any of the approvers. Owners
+// that do not exist are never approved.
+func (pr *PullRequest) codeOwnerApproved(ctx context.Context, owner string, approverIDs map[int64]bool) (approved, exists bool, err error) {
+	name := strings.TrimPrefix(owner, "@")
+	if orgName, teamName, ok := strings.Cut(name, "/"); ok {
+		org := pr.BaseRepo.MustOwner()
+		if !org.IsOrganization() || !strings.EqualFold(orgName, org.Name) {
+			return false, false, nil
+		}
+		team, err := GetTeamOfOrgByName(org.ID, teamName)
+		if err != nil {
+			if IsErrTeamNotExist(err) {
+				return false, false, nil
+			}
+			return false, false, errors.Wrapf(err, "get team %q", owner)
+		}
+		for approverID := range approverIDs {
+			if IsTeamMember(org.ID, team.ID, approverID) {
+				return true, true, nil
+			}
+		}
+		return false, true, nil
+	}
+
+	var u *User
+	if strings.HasPrefix(owner, "@") {
+		u, err = Users.GetByUsername(ctx, name)
+	} else {
+		u, err = Users.GetByEmail(ctx, name)
+	}
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return false, false, nil
+		}
+		return false, false, errors.Wrapf(err, "get user %q", owner)
+	}
+	return approverIDs[u.ID], true, nil
+}
+
+// MissingCodeOwners returns owners of files changed by the pull request whose
+// approvals are still required, each item lists owners of the same files
+// separated by spaces, e.g. "@alice @acme/writers". Every changed file needs an
+// approval from any of its owners by the CODEOWNERS file in the base branch, and
+// owning teams are satisfied by any of their members. Only approvals of the
+// current head of the pull request count, thus owners need to approve again
+// after new commits are pushed.
+func (pr *PullRequest) MissingCodeOwners(ctx context.Context) ([]string, error) {
+	if err := pr.LoadAttributes(); err != nil {
+		return nil, errors.Wrap(err, "load attributes")
+	}
+
+	baseRepoPath := pr.BaseRepo.RepoPath()
+	baseGitRepo, err := git.Open(baseRepoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "open repository")
+	}
+	rules, err := pr.codeOwnerRules(baseGitRepo)
+	if err != nil {
+		return nil, errors.Wrap(err, "get code owner rules")
+	} else if len(rules) == 0 {
+		return nil, nil
+	}
+
+	stdout, err := git.NewCommand("diff", "--name-only", "-z", fmt.Sprintf("%s...refs/pull/%d/head", git.RefsHeads+pr.BaseBranch, pr.Index)).RunInDir(baseRepoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "list changed files")
+	}
+
+	approvals, err := pr.currentApprovals(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "get current approvals")
+	}
+	approverIDs := make(map[int64]bool, len(approvals))
+	for _, a := range approvals {
+		approverIDs[a.ReviewerID] = true
+	}
+
+	type ownerStatus struct {
+		approved bool
+		exists   bool
+	}
+	statuses := make(map[string]ownerStatus)
+	missing := make(map[string]bool)
+	for _, file := range bytes.Split(stdout, []byte{0}) {
+		if len(file) == 0 {
+			continue
+		}
+
+		var hasOwner, approved bool
+		var owners []string
+		for _, owner := range codeOwnersOf(rules, string(file)) {
+			status, ok := statuses[owner]
+			if !ok {
+				status.approved, status.exists, err = pr.codeOwnerApproved(ctx, owner, approverIDs)
+				if err != nil {
+					return nil, err
+				}
+				statuses[owner] = status
+			}
+			if !status.exists {
+				continue
+			}
+
+			hasOwner = true
+			approved = approved || status.approved
+			owners = append(owners, owner)
+		}
+		if hasOwner && !approved {
+			missing[strings.Join(owners, " ")] = true
+		}
+	}
+
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// checkCodeOwnerReviews returns ErrCodeOwnerReviewRequired when the base branch
+// requires approvals from code owners but any of them is missing.
+func (pr *PullRequest) checkCodeOwnerReviews(ctx context.Context) error {
+	if !IsBranchOfRepoRequireCodeOwnerReviews(pr.BaseRepoID, pr.BaseBranch) {
+		return nil
+	}
+
+	missing, err := pr.MissingCodeOwners(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get missing code owners")
+	} else if len(missing) > 0 {
+		return ErrCodeOwnerReviewRequired{args: errutil.Args{"pullRequestID": pr.ID, "owners": missing}}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestParseCodeOwners(t *testing.T) {
+	rules := parseCodeOwners([]byte(`
+# Default owners
+*           @alice
+
+/docs/      @acme/writers bob@example.com  # Documentation
+src/*.go    @carol
+[invalid    @dave
+`))
+	require.Len(t, rules, 3)
+	assert.Equal(t, &codeOwnersRule{pattern: "*", owners: []string{"@alice"}}, rules[0])
+	assert.Equal(t, &codeOwnersRule{pattern: "/docs/", owners: []string{"@acme/writers", "bob@example.com"}}, rules[1])
+	assert.Equal(t, &codeOwnersRule{pattern: "src/*.go", owners: []string{"@carol"}}, rules[2])
+
+	assert.Equal(t, []string{"@alice"}, codeOwnersOf(rules, "README.md"))
+	assert.Equal(t, []string{"@acme/writers", "bob@example.com"}, codeOwnersOf(rules, "docs/install/README.md"))
+	assert.Equal(t, []string{"@carol"}, codeOwnersOf(rules, "src/main.go"))
+	assert.Equal(t, []string{"@alice"}, codeOwnersOf(rules, "src/cmd/main.go"))
+	assert.Nil(t, codeOwnersOf(rules[1:], "README.md"))
+}
+
+func TestMatchCodeOwnersPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		file    string
+		want    bool
+	}{
+		{pattern: "*", file: "README.md", want: true},
+		{pattern: "*", file: "docs/README.md", want: true},
+		{pattern: "*.md", file: "docs/README.md", want: true},
+		{pattern: "*.md", file: "main.go", want: false},
+		{pattern: "/README.md", file: "README.md", want: true},
+		{pattern: "/README.md", file: "docs/README.md", want: false},
+		{pattern: "docs/", file: "docs/README.md", want: true},
+		{pattern: "docs/", file: "src/docs/README.md", want: true},
+		{pattern: "/docs/", file: "src/docs/README.md", want: false},
+		{pattern: "docs", file: "docs/install/README.md", want: true},
+		{pattern: "docs", file: "docs", want: true},
+		{pattern: "docs/*.md", file: "docs/README.md", want: true},
+		{pattern: "docs/*.md", file: "docs/install/README.md", want: false},
+		{pattern: "**/logs", file: "build/logs/output.txt", want: true},
+		{pattern: "/src/**/*.go", file: "src/cmd/main.go", want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.file, func(t *testing.T) {
+			assert.Equal(t, test.want, matchCodeOwnersPattern(test.pattern, test.file))
+		})
+	}
+}
+
+func TestPullRequest_MissingCodeOwners(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser), new(PullRequest),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone), new(IssueWorkflowState),
+		new(Team), new(TeamUser), new(ProtectBranch), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "missingCodeOwners", new(User), new(EmailAddress), new(Approval))
+	SetMockUsersStore(t, NewUsersStore(gdb))
+	before := Approvals
+	Approvals = NewApprovalsStore(gdb)
+	t.Cleanup(func() {
+		Approvals = before
+	})
+
+	newUser := func(t *testing.T, name string) *User {
+		u, err := Users.Create(ctx, name, name+"@example.com", CreateUserOptions{Activated: true})
+		require.NoError(t, err)
+		_, err = x.Insert(u)
+		require.NoError(t, err)
+		return u
+	}
+	alice := newUser(t, "alice")
+	bob := newUser(t, "bob")
+	carol := newUser(t, "carol")
+	dave := newUser(t, "dave")
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization}
+	_, err := x.Insert(org)
+	require.NoError(t, err)
+	team := &Team{OrgID: org.ID, LowerName: "core", Name: "core", Authorize: AccessModeWrite}
+	_, err = x.Insert(team)
+	require.NoError(t, err)
+	_, err = x.Insert(&TeamUser{OrgID: org.ID, TeamID: team.ID, UID: carol.ID})
+	require.NoError(t, err)
+
+	repo := &Repository{OwnerID: org.ID, Owner: org, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	repoPath := repo.RepoPath()
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(workPath, name)), os.ModePerm)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("CODEOWNERS", "*.md @bob\n/src/ @acme/core @ghost\n/LICENSE @ghost\n")
+	run("push", "origin", "main")
+
+	run("checkout", "-b", "feature")
+	commit("README.md", "Hello")
+	commit("LICENSE", "MIT")
+	run("push", "origin", "feature")
+
+	_, err = x.Insert(&ProtectBranch{
+		RepoID:                  repo.ID,
+		Name:                    "main",
+		Protected:               true,
+		RequireCodeOwnerReviews: true,
+	})
+	require.NoError(t, err)
+
+	issue := &Issue{RepoID: repo.ID, Repo: repo, PosterID: alice.ID, Poster: alice, Title: "Add README", IsPull: true}
+	pr := &PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadUserName: org.Name,
+		HeadBranch:   "feature",
+		BaseBranch:   "main",
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+	}
+	err = NewPullRequest(repo, issue, nil, nil, pr, nil)
+	require.NoError(t, err)
+	pr.Issue = issue
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	baseGitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	merge := func(t *testing.T) error {
+		t.Helper()
+		return pr.Merge(dave, baseGitRepo, MERGE_STYLE_REGULAR, "")
+	}
+	missingCodeOwners := func(t *testing.T) []string {
+		t.Helper()
+		missing, err := pr.MissingCodeOwners(ctx)
+		require.NoError(t, err)
+		return missing
+	}
+
+	// The license has no owner that exists
+	assert.Equal(t, []string{"@bob"}, missingCodeOwners(t))
+	err = merge(t)
+	assert.True(t, IsErrCodeOwnerReviewRequired(err), "want ErrCodeOwnerReviewRequired but got %v", err)
+
+	// Authors cannot approve their own pull requests
+	err = pr.Approve(ctx, alice)
+	assert.True(t, IsErrApprovalNotAllowed(err))
+
+	err = pr.Approve(ctx, bob)
+	require.NoError(t, err)
+	assert.Empty(t, missingCodeOwners(t))
+
+	// New commits that change files of other owners require their approvals, and
+	// approvals of the old head no longer count
+	run("checkout", "feature")
+	commit("src/main.go", "package main")
+	run("push", "origin", "feature")
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"@acme/core", "@bob"}, missingCodeOwners(t))
+	err = merge(t)
+	assert.True(t, IsErrCodeOwnerReviewRequired(err), "want ErrCodeOwnerReviewRequired but got %v", err)
+
+	err = pr.Approve(ctx, bob)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@acme/core"}, missingCodeOwners(t))
+
+	// Withdrawn approvals are no longer counted
+	err = pr.Unapprove(ctx, bob)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"@acme/core", "@bob"}, missingCodeOwners(t))
+	err = pr.Approve(ctx, bob)
+	require.NoError(t, err)
+
+	// Any member of the owning team can approve
+	err = pr.Approve(ctx, carol)
+	require.NoError(t, err)
+	assert.Empty(t, missingCodeOwners(t))
+
+	// Pushing after approvals requires owners to approve again
+	run("checkout", "feature")
+	commit("README.md", "Hello, world")
+	run("push", "origin", "feature")
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"@acme/core", "@bob"}, missingCodeOwners(t))
+	err = pr.checkCodeOwnerReviews(ctx)
+	assert.True(t, IsErrCodeOwnerReviewRequired(err), "want ErrCodeOwnerReviewRequired but got %v", err)
+
+	err = pr.Approve(ctx, bob)
+	require.NoError(t, err)
+	err = pr.Approve(ctx, carol)
+	require.NoError(t, err)
+	assert.Empty(t, missingCodeOwners(t))
+
+	// NOTE: Merging for real is not tested because it requires a lot more setup,
+	// only check the pull request is no longer blocked by code owners.
+	err = pr.checkCodeOwnerReviews(ctx)
+	assert.NoError(t, err)
+}
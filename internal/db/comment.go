@@ -16,8 +16,10 @@ import (
 
 	api "github.com/gogs/go-gogs-client"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/markup"
+	"gogs.io/gogs/internal/sync"
 )
 
 // CommentType defines whether a comment is just a simple comment, an action (like close) or a reference.
@@ -37,6 +39,9 @@ const (
 	COMMENT_TYPE_COMMENT_REF
 	// Reference from a pull request
 	COMMENT_TYPE_PULL_REF
+
+	// Change of the custom workflow state, the name of new state is the content
+	COMMENT_TYPE_WORKFLOW_STATE
 )
 
 type CommentTag int
@@ -50,29 +55,36 @@ const (
 
 // Comment represents a comment in commit and issue page.
 type Comment struct {
-	ID              int64
+	ID              int64 `gorm:"primaryKey"`
 	Type            CommentType
 	PosterID        int64
-	Poster          *User  `xorm:"-" json:"-"`
-	IssueID         int64  `xorm:"INDEX"`
-	Issue           *Issue `xorm:"-" json:"-"`
+	Poster          *User  `xorm:"-" json:"-" gorm:"-"`
+	IssueID         int64  `xorm:"INDEX" gorm:"index"`
+	Issue           *Issue `xorm:"-" json:"-" gorm:"-"`
 	CommitID        int64
 	Line            int64
-	Content         string `xorm:"TEXT"`
-	RenderedContent string `xorm:"-" json:"-"`
+	Content         string `xorm:"TEXT" gorm:"type:TEXT"`
+	RenderedContent string `xorm:"-" json:"-" gorm:"-"`
 
-	Created     time.Time `xorm:"-" json:"-"`
+	Created     time.Time `xorm:"-" json:"-" gorm:"-"`
 	CreatedUnix int64
-	Updated     time.Time `xorm:"-" json:"-"`
+	Updated     time.Time `xorm:"-" json:"-" gorm:"-"`
 	UpdatedUnix int64
+	// The number of times the content has been edited.
+	NumEdits int
 
 	// Reference issue in commit message
-	CommitSHA string `xorm:"VARCHAR(40)"`
+	CommitSHA string `xorm:"VARCHAR(40)" gorm:"type:VARCHAR(40)"`
 
-	Attachments []*Attachment `xorm:"-" json:"-"`
+	Attachments []*Attachment `xorm:"-" json:"-" gorm:"-"`
 
 	// For view issue page.
-	ShowTag CommentTag `xorm:"-" json:"-"`
+	ShowTag CommentTag `xorm:"-" json:"-" gorm:"-"`
+}
+
+// IsEdited returns true if the content of the comment has been edited.
+func (c *Comment) IsEdited() bool {
+	return c.NumEdits > 0
 }
 
 func (c *Comment) BeforeInsert() {
@@ -180,7 +192,7 @@ func (cmt *Comment) mailParticipants(e Engine, opType ActionType, issue *Issue)
 	case ActionReopenIssue:
 		issue.Content = fmt.Sprintf("Reopened #%d", issue.Index)
 	}
-	if err = mailIssueCommentToParticipants(issue, cmt.Poster, mentions); err != nil {
+	if err = mailIssueCommentToParticipants(issue, cmt.Poster, mentions, true); err != nil {
 		log.Error("mailIssueCommentToParticipants: %v", err)
 	}
 
@@ -335,8 +347,54 @@ func CreateComment(opts *CreateCommentOptions) (comment *Comment, err error) {
 	return comment, sess.Commit()
 }
 
-// CreateIssueComment creates a plain issue comment.
+type ErrCommentRateLimitExceeded struct {
+	args map[string]any
+}
+
+func IsErrCommentRateLimitExceeded(err error) bool {
+	_, ok := err.(ErrCommentRateLimitExceeded)
+	return ok
+}
+
+func (err ErrCommentRateLimitExceeded) Error() string {
+	return fmt.Sprintf("too many comments created in a short time: %v", err.args)
+}
+
+// commentRateLimiter tracks plain comments created by each user.
+var commentRateLimiter = sync.NewKeyedRateLimiter()
+
+// checkCommentRateLimit returns ErrCommentRateLimitExceeded if the doer has
+// created too many plain comments within the configured interval. Users who
+// have write access to the repository are exempt.
+func checkCommentRateLimit(doer *User, repo *Repository) error {
+	limit := conf.Repository.CommentRateLimit
+	if limit.MaxComments <= 0 {
+		return nil
+	}
+
+	if Perms.Authorize(context.TODO(), doer.ID, repo.ID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: repo.OwnerID,
+			Private: repo.IsPrivate,
+		},
+	) {
+		return nil
+	}
+
+	if !commentRateLimiter.Allow(com.ToStr(doer.ID), limit.MaxComments, limit.Interval) {
+		return ErrCommentRateLimitExceeded{args: map[string]any{"userID": doer.ID}}
+	}
+	return nil
+}
+
+// CreateIssueComment creates a plain issue comment. It returns
+// ErrCommentRateLimitExceeded if the doer has created too many comments in a
+// short time.
 func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content string, attachments []string) (*Comment, error) {
+	if err := checkCommentRateLimit(doer, repo); err != nil {
+		return nil, err
+	}
+
 	comment, err := CreateComment(&CreateCommentOptions{
 		Type:        COMMENT_TYPE_COMMENT,
 		Doer:        doer,
@@ -349,6 +407,10 @@ func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content stri
 		return nil, fmt.Errorf("CreateComment: %v", err)
 	}
 
+	if err = issue.UnmarkStale(); err != nil {
+		log.Error("UnmarkStale [issue_id: %d]: %v", issue.ID, err)
+	}
+
 	comment.Issue = issue
 	if err = PrepareWebhooks(repo, HOOK_EVENT_ISSUE_COMMENT, &api.IssueCommentPayload{
 		Action:     api.HOOK_ISSUE_COMMENT_CREATED,
@@ -478,9 +540,36 @@ func GetCommentsByRepoIDSince(repoID, since int64) ([]*Comment, error) {
 	return getCommentsByRepoIDSince(x, repoID, since)
 }
 
-// UpdateComment updates information of comment.
+// UpdateComment updates content of the comment and fires the edited event of
+// webhooks. It does nothing when the content is not changed.
 func UpdateComment(doer *User, c *Comment, oldContent string) (err error) {
-	if _, err = x.Id(c.ID).AllCols().Update(c); err != nil {
+	if oldContent == c.Content {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if conf.Repository.EnableCommentEditHistory {
+		_, err = sess.Insert(&CommentHistory{
+			CommentID:   c.ID,
+			EditorID:    doer.ID,
+			Content:     oldContent,
+			CreatedUnix: time.Now().Unix(),
+		})
+		if err != nil {
+			return fmt.Errorf("create comment history: %v", err)
+		}
+	}
+	c.NumEdits++
+
+	if _, err = sess.ID(c.ID).AllCols().Update(c); err != nil {
+		return err
+	}
+	if err = sess.Commit(); err != nil {
 		return err
 	}
 
@@ -539,6 +628,11 @@ func DeleteCommentByID(doer *User, id int64) error {
 		log.Error("Failed to delete attachments by comment[%d]: %v", comment.ID, err)
 	}
 
+	err = CommentHistories.DeleteByCommentID(context.TODO(), comment.ID)
+	if err != nil {
+		log.Error("Failed to delete histories by comment[%d]: %v", comment.ID, err)
+	}
+
 	if err = comment.Issue.LoadAttributes(); err != nil {
 		log.Error("Issue.LoadAttributes [issue_id: %d]: %v", comment.IssueID, err)
 	} else if err = PrepareWebhooks(comment.Issue.Repo, HOOK_EVENT_ISSUE_COMMENT, &api.IssueCommentPayload{
@@ -0,0 +1,130 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// CommentHistoriesStore is the persistent interface for edit histories of
+// comments.
+type CommentHistoriesStore interface {
+	// Create saves the given content as a prior version of the comment, which is
+	// replaced by the editor.
+	Create(ctx context.Context, commentID, editorID int64, content string) error
+	// List returns all prior versions of the comment, sorted from the oldest to
+	// the newest.
+	List(ctx context.Context, commentID int64) ([]*CommentHistory, error)
+	// DeleteByID deletes the prior version with given ID of the comment, and
+	// decreases the number of edits of the comment accordingly. It returns
+	// ErrCommentHistoryNotExist when not found.
+	DeleteByID(ctx context.Context, commentID, id int64) error
+	// DeleteByCommentID deletes all prior versions of the comment.
+	DeleteByCommentID(ctx context.Context, commentID int64) error
+}
+
+var CommentHistories CommentHistoriesStore
+
+var _ CommentHistoriesStore = (*commentHistories)(nil)
+
+type commentHistories struct {
+	*gorm.DB
+}
+
+// NewCommentHistoriesStore returns a persistent interface for edit histories of
+// comments with given database connection.
+func NewCommentHistoriesStore(db *gorm.DB) CommentHistoriesStore {
+	return &commentHistories{DB: db}
+}
+
+// CommentHistory is a prior version of the content of a comment.
+type CommentHistory struct {
+	ID        int64 `gorm:"primaryKey"`
+	CommentID int64 `gorm:"index;not null"`
+	// The user whose edit replaced this version.
+	EditorID int64  `gorm:"not null"`
+	Content  string `gorm:"type:TEXT"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (h *CommentHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.CreatedUnix == 0 {
+		h.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (h *CommentHistory) AfterFind(_ *gorm.DB) error {
+	h.Created = time.Unix(h.CreatedUnix, 0).Local()
+	return nil
+}
+
+func (db *commentHistories) Create(ctx context.Context, commentID, editorID int64, content string) error {
+	return db.WithContext(ctx).Create(
+		&CommentHistory{
+			CommentID: commentID,
+			EditorID:  editorID,
+			Content:   content,
+		},
+	).Error
+}
+
+func (db *commentHistories) List(ctx context.Context, commentID int64) ([]*CommentHistory, error) {
+	var histories []*CommentHistory
+	return histories, db.WithContext(ctx).
+		Where("comment_id = ?", commentID).
+		Order("id ASC").
+		Find(&histories).
+		Error
+}
+
+var _ errutil.NotFound = (*ErrCommentHistoryNotExist)(nil)
+
+type ErrCommentHistoryNotExist struct {
+	args errutil.Args
+}
+
+func IsErrCommentHistoryNotExist(err error) bool {
+	_, ok := err.(ErrCommentHistoryNotExist)
+	return ok
+}
+
+func (err ErrCommentHistoryNotExist) Error() string {
+	return fmt.Sprintf("comment history does not exist: %v", err.args)
+}
+
+func (ErrCommentHistoryNotExist) NotFound() bool {
+	return true
+}
+
+func (db *commentHistories) DeleteByID(ctx context.Context, commentID, id int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("comment_id = ? AND id = ?", commentID, id).Delete(new(CommentHistory))
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return ErrCommentHistoryNotExist{args: errutil.Args{"commentID": commentID, "id": id}}
+		}
+
+		return tx.Model(new(Comment)).
+			Where("id = ? AND num_edits > 0", commentID).
+			UpdateColumn("num_edits", gorm.Expr("num_edits - 1")).
+			Error
+	})
+}
+
+func (db *commentHistories) DeleteByCommentID(ctx context.Context, commentID int64) error {
+	return db.WithContext(ctx).Where("comment_id = ?", commentID).Delete(new(CommentHistory)).Error
+}
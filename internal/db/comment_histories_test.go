@@ -0,0 +1,125 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestCommentHistories(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(CommentHistory), new(Comment)}
+	db := &commentHistories{
+		DB: dbtest.NewDB(t, "commentHistories", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *commentHistories)
+	}{
+		{"Create", commentHistoriesCreate},
+		{"DeleteByID", commentHistoriesDeleteByID},
+		{"DeleteByCommentID", commentHistoriesDeleteByCommentID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func commentHistoriesCreate(t *testing.T, db *commentHistories) {
+	ctx := context.Background()
+
+	// Editing a comment twice should yield two versions in order
+	err := db.Create(ctx, 1, 1, "first")
+	require.NoError(t, err)
+	err = db.Create(ctx, 1, 2, "second")
+	require.NoError(t, err)
+	err = db.Create(ctx, 2, 1, "other")
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "first", got[0].Content)
+	assert.Equal(t, int64(1), got[0].EditorID)
+	assert.Equal(t, "second", got[1].Content)
+	assert.Equal(t, int64(2), got[1].EditorID)
+}
+
+func commentHistoriesDeleteByID(t *testing.T, db *commentHistories) {
+	ctx := context.Background()
+
+	err := db.DeleteByID(ctx, 1, 1)
+	wantErr := ErrCommentHistoryNotExist{args: errutil.Args{"commentID": int64(1), "id": int64(1)}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DB.Create(&Comment{ID: 1, NumEdits: 2}).Error
+	require.NoError(t, err)
+	err = db.Create(ctx, 1, 1, "first")
+	require.NoError(t, err)
+	err = db.Create(ctx, 1, 1, "second")
+	require.NoError(t, err)
+	histories, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, histories, 2)
+
+	// Should not delete a version of another comment
+	err = db.DeleteByID(ctx, 2, histories[0].ID)
+	wantErr = ErrCommentHistoryNotExist{args: errutil.Args{"commentID": int64(2), "id": histories[0].ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 1, histories[0].ID)
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "second", got[0].Content)
+
+	// The number of edits of the comment should be decreased
+	comment := new(Comment)
+	err = db.First(comment, 1).Error
+	require.NoError(t, err)
+	assert.Equal(t, 1, comment.NumEdits)
+}
+
+func commentHistoriesDeleteByCommentID(t *testing.T, db *commentHistories) {
+	ctx := context.Background()
+
+	err := db.Create(ctx, 1, 1, "first")
+	require.NoError(t, err)
+	err = db.Create(ctx, 2, 1, "other")
+	require.NoError(t, err)
+
+	err = db.DeleteByCommentID(ctx, 1)
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = db.List(ctx, 2)
+	require.NoError(t, err)
+	assert.Len(t, got, 1)
+}
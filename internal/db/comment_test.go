@@ -0,0 +1,228 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestCheckCommentRateLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t, "checkCommentRateLimit", new(Access))
+	SetMockPermsStore(t, NewPermsStore(db))
+
+	opts := conf.Repository
+	opts.CommentRateLimit.MaxComments = 3
+	opts.CommentRateLimit.Interval = time.Hour
+	conf.SetMockRepository(t, opts)
+
+	repo := &Repository{ID: 1, OwnerID: 1}
+	owner := &User{ID: 1}
+	maintainer := &User{ID: 2}
+	commenter := &User{ID: 3}
+	err := db.Create(&Access{UserID: maintainer.ID, RepoID: repo.ID, Mode: AccessModeWrite}).Error
+	require.NoError(t, err)
+
+	t.Run("rapid commenter is throttled", func(t *testing.T) {
+		t.Cleanup(func() { commentRateLimiter.Reset("3") })
+
+		for i := 0; i < 3; i++ {
+			err := checkCommentRateLimit(commenter, repo)
+			require.NoError(t, err)
+		}
+
+		err := checkCommentRateLimit(commenter, repo)
+		wantErr := ErrCommentRateLimitExceeded{args: map[string]any{"userID": commenter.ID}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("owner and maintainer are exempt", func(t *testing.T) {
+		for _, doer := range []*User{owner, maintainer} {
+			for i := 0; i < 5; i++ {
+				err := checkCommentRateLimit(doer, repo)
+				require.NoError(t, err)
+			}
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Cleanup(func() { commentRateLimiter.Reset("3") })
+
+		conf.Repository.CommentRateLimit.MaxComments = 0
+		for i := 0; i < 5; i++ {
+			err := checkCommentRateLimit(commenter, repo)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestComment_webhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf.SetMockServer(t, conf.ServerOpts{})
+	conf.SetMockSSH(t, conf.SSHOpts{})
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Issue), new(Label), new(IssueLabel),
+		new(Attachment), new(Comment), new(Milestone), new(Webhook), new(HookTask),
+	)
+	db := dbtest.NewDB(t, "commentWebhooks", new(User), new(EmailAddress), new(CommentHistory))
+	SetMockUsersStore(t, NewUsersStore(db))
+	before := CommentHistories
+	CommentHistories = NewCommentHistoriesStore(db)
+	t.Cleanup(func() {
+		CommentHistories = before
+	})
+
+	alice := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	err := db.Create(alice).Error
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: alice.ID, LowerName: "example", Name: "example"}
+	issue := &Issue{ID: 1, RepoID: repo.ID, Index: 1, PosterID: alice.ID, Title: "example"}
+	comment := &Comment{ID: 1, Type: COMMENT_TYPE_COMMENT, PosterID: alice.ID, IssueID: issue.ID, Content: "Hello"}
+	_, err = x.Insert(alice, repo, issue, comment)
+	require.NoError(t, err)
+
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents:   HookEvents{IssueComment: true},
+		},
+		IsActive: true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	type commentPayload struct {
+		Action  string `json:"action"`
+		Comment struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+		} `json:"comment"`
+		Changes *struct {
+			Body struct {
+				From string `json:"from"`
+			} `json:"body"`
+		} `json:"changes"`
+	}
+	listPayloads := func(t *testing.T) []*commentPayload {
+		var tasks []*HookTask
+		err := x.Asc("id").Find(&tasks)
+		require.NoError(t, err)
+
+		payloads := make([]*commentPayload, len(tasks))
+		for i := range tasks {
+			assert.Equal(t, HOOK_EVENT_ISSUE_COMMENT, tasks[i].EventType)
+			payloads[i] = new(commentPayload)
+			err = jsoniter.Unmarshal([]byte(tasks[i].PayloadContent), payloads[i])
+			require.NoError(t, err)
+		}
+		return payloads
+	}
+
+	// A no-op edit does not fire
+	loadComment := func(t *testing.T) *Comment {
+		c, err := GetCommentByID(comment.ID)
+		require.NoError(t, err)
+		c.Issue, err = GetIssueByID(c.IssueID)
+		require.NoError(t, err)
+		return c
+	}
+	c := loadComment(t)
+	err = UpdateComment(alice, c, c.Content)
+	require.NoError(t, err)
+	assert.Empty(t, listPayloads(t))
+
+	c = loadComment(t)
+	oldContent := c.Content
+	c.Content = "Hello, world"
+	err = UpdateComment(alice, c, oldContent)
+	require.NoError(t, err)
+
+	payloads := listPayloads(t)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "edited", payloads[0].Action)
+	assert.Equal(t, comment.ID, payloads[0].Comment.ID)
+	assert.Equal(t, "Hello, world", payloads[0].Comment.Body)
+	require.NotNil(t, payloads[0].Changes)
+	assert.Equal(t, "Hello", payloads[0].Changes.Body.From)
+
+	err = DeleteCommentByID(alice, comment.ID)
+	require.NoError(t, err)
+
+	payloads = listPayloads(t)
+	require.Len(t, payloads, 2)
+	assert.Equal(t, "deleted", payloads[1].Action)
+	assert.Equal(t, comment.ID, payloads[1].Comment.ID)
+	assert.Nil(t, payloads[1].Changes)
+}
+
+func TestUpdateComment_history(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf.SetMockServer(t, conf.ServerOpts{})
+	conf.SetMockSSH(t, conf.SSHOpts{})
+	repoOpts := conf.Repository
+	repoOpts.EnableCommentEditHistory = true
+	conf.SetMockRepository(t, repoOpts)
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Issue), new(Label), new(IssueLabel),
+		new(Attachment), new(Comment), new(CommentHistory), new(Milestone), new(Webhook), new(HookTask),
+	)
+	db := dbtest.NewDB(t, "updateCommentHistory", new(User), new(EmailAddress))
+	SetMockUsersStore(t, NewUsersStore(db))
+
+	alice := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	err := db.Create(alice).Error
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: alice.ID, LowerName: "example", Name: "example"}
+	issue := &Issue{ID: 1, RepoID: repo.ID, Index: 1, PosterID: alice.ID, Title: "example"}
+	comment := &Comment{ID: 1, Type: COMMENT_TYPE_COMMENT, PosterID: alice.ID, IssueID: issue.ID, Content: "Hello"}
+	_, err = x.Insert(alice, repo, issue, comment)
+	require.NoError(t, err)
+
+	c, err := GetCommentByID(comment.ID)
+	require.NoError(t, err)
+	c.Issue, err = GetIssueByID(c.IssueID)
+	require.NoError(t, err)
+	c.Content = "Hello, world"
+	err = UpdateComment(alice, c, "Hello")
+	require.NoError(t, err)
+
+	// The prior version is saved along with the edit
+	var histories []*CommentHistory
+	err = x.Where("comment_id = ?", comment.ID).Find(&histories)
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	assert.Equal(t, "Hello", histories[0].Content)
+	assert.Equal(t, alice.ID, histories[0].EditorID)
+	assert.NotZero(t, histories[0].CreatedUnix)
+
+	got, err := GetCommentByID(comment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, world", got.Content)
+	assert.Equal(t, 1, got.NumEdits)
+}
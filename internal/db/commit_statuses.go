@@ -0,0 +1,278 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// CommitStatusesStore is the persistent interface for statuses of commits
+// reported by external systems, e.g. CI.
+type CommitStatusesStore interface {
+	// Create creates a new status of the commit in the repository with given
+	// options. It replaces the existing status of the same context of the commit.
+	Create(ctx context.Context, repoID, creatorID int64, commitID string, opts CreateCommitStatusOptions) (*CommitStatus, error)
+	// ListByCommit returns statuses of the commit in the repository, one for
+	// each context, sorted by context in alphabetical order.
+	ListByCommit(ctx context.Context, repoID int64, commitID string) ([]*CommitStatus, error)
+}
+
+var CommitStatuses CommitStatusesStore
+
+var _ CommitStatusesStore = (*commitStatuses)(nil)
+
+type commitStatuses struct {
+	*gorm.DB
+}
+
+// NewCommitStatusesStore returns a persistent interface for statuses of commits
+// with given database connection.
+func NewCommitStatusesStore(db *gorm.DB) CommitStatusesStore {
+	return &commitStatuses{DB: db}
+}
+
+// CommitStatusState is the state of a commit status.
+type CommitStatusState string
+
+const (
+	CommitStatusPending CommitStatusState = "pending"
+	CommitStatusSuccess CommitStatusState = "success"
+	CommitStatusFailure CommitStatusState = "failure"
+	CommitStatusError   CommitStatusState = "error"
+)
+
+// IsValid returns true if the state is one of the known states.
+func (s CommitStatusState) IsValid() bool {
+	switch s {
+	case CommitStatusPending, CommitStatusSuccess, CommitStatusFailure, CommitStatusError:
+		return true
+	}
+	return false
+}
+
+// CommitStatus is the latest status of a context, e.g. "ci/build", of a commit.
+type CommitStatus struct {
+	ID          int64             `gorm:"primaryKey"`
+	RepoID      int64             `gorm:"uniqueIndex:commit_status_repo_commit_context_unique;not null"`
+	CommitID    string            `gorm:"type:VARCHAR(40);uniqueIndex:commit_status_repo_commit_context_unique;not null"`
+	Context     string            `gorm:"type:VARCHAR(255);uniqueIndex:commit_status_repo_commit_context_unique;not null"`
+	CreatorID   int64             `gorm:"not null"`
+	State       CommitStatusState `gorm:"not null"`
+	TargetURL   string            `gorm:"type:TEXT"`
+	Description string            `gorm:"type:TEXT"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Updated     time.Time `gorm:"-" json:"-"`
+	UpdatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (s *CommitStatus) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedUnix == 0 {
+		s.CreatedUnix = tx.NowFunc().Unix()
+		s.UpdatedUnix = s.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (s *CommitStatus) AfterFind(_ *gorm.DB) error {
+	s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	s.Updated = time.Unix(s.UpdatedUnix, 0).Local()
+	return nil
+}
+
+type CreateCommitStatusOptions struct {
+	// Context is the name of the check, default to "default" when empty.
+	Context     string
+	State       CommitStatusState
+	TargetURL   string
+	Description string
+}
+
+func (db *commitStatuses) Create(ctx context.Context, repoID, creatorID int64, commitID string, opts CreateCommitStatusOptions) (*CommitStatus, error) {
+	if opts.Context == "" {
+		opts.Context = "default"
+	}
+
+	err := db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "repo_id"}, {Name: "commit_id"}, {Name: "context"}},
+		DoUpdates: clause.AssignmentColumns([]string{"creator_id", "state", "target_url", "description", "updated_unix"}),
+	}).Create(
+		&CommitStatus{
+			RepoID:      repoID,
+			CommitID:    commitID,
+			Context:     opts.Context,
+			CreatorID:   creatorID,
+			State:       opts.State,
+			TargetURL:   opts.TargetURL,
+			Description: opts.Description,
+		},
+	).Error
+	if err != nil {
+		return nil, err
+	}
+
+	status := new(CommitStatus)
+	return status, db.WithContext(ctx).
+		Where("repo_id = ? AND commit_id = ? AND context = ?", repoID, commitID, opts.Context).
+		First(status).
+		Error
+}
+
+func (db *commitStatuses) ListByCommit(ctx context.Context, repoID int64, commitID string) ([]*CommitStatus, error) {
+	var statuses []*CommitStatus
+	return statuses, db.WithContext(ctx).
+		Where("repo_id = ? AND commit_id = ?", repoID, commitID).
+		Order("context ASC").
+		Find(&statuses).
+		Error
+}
+
+// APICommitStatus is the API format of a commit status.
+type APICommitStatus struct {
+	ID          int64             `json:"id"`
+	Context     string            `json:"context"`
+	State       CommitStatusState `json:"state"`
+	TargetURL   string            `json:"target_url"`
+	Description string            `json:"description"`
+	Creator     *api.User         `json:"creator"`
+	Created     time.Time         `json:"created_at"`
+	Updated     time.Time         `json:"updated_at"`
+}
+
+// APIFormat returns the API format of the commit status created by the creator.
+func (s *CommitStatus) APIFormat(creator *User) *APICommitStatus {
+	return &APICommitStatus{
+		ID:          s.ID,
+		Context:     s.Context,
+		State:       s.State,
+		TargetURL:   s.TargetURL,
+		Description: s.Description,
+		Creator:     creator.APIFormat(),
+		Created:     s.Created,
+		Updated:     s.Updated,
+	}
+}
+
+// headCommitID returns the ID of the latest commit of the head of the pull
+// request that has been pushed to the base repository.
+func (pr *PullRequest) headCommitID() (string, error) {
+	if err := pr.LoadAttributes(); err != nil {
+		return "", errors.Wrap(err, "load attributes")
+	}
+
+	baseGitRepo, err := git.Open(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return "", errors.Wrap(err, "open repository")
+	}
+	return baseGitRepo.RevParse(fmt.Sprintf("refs/pull/%d/head", pr.Index))
+}
+
+// RequiredStatusCheck is the state of a status check required by the base
+// branch of a pull request for its head commit.
+type RequiredStatusCheck struct {
+	Context string
+	// State is CommitStatusPending when the context has not been reported.
+	State CommitStatusState
+}
+
+// RequiredStatusChecks returns status checks required by the base branch of the
+// pull request with their states for the head commit, sorted by context in
+// alphabetical order.
+func (pr *PullRequest) RequiredStatusChecks(ctx context.Context) ([]*RequiredStatusCheck, error) {
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		if IsErrBranchNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "get protect branch")
+	}
+	contexts := protectBranch.StatusCheckContexts()
+	if !protectBranch.Protected || len(contexts) == 0 {
+		return nil, nil
+	}
+
+	commitID, err := pr.headCommitID()
+	if err != nil {
+		return nil, errors.Wrap(err, "get head commit ID")
+	}
+	statuses, err := CommitStatuses.ListByCommit(ctx, pr.BaseRepoID, commitID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list commit statuses")
+	}
+	states := make(map[string]CommitStatusState, len(statuses))
+	for _, s := range statuses {
+		states[s.Context] = s.State
+	}
+
+	checks := make([]*RequiredStatusCheck, 0, len(contexts))
+	for _, name := range contexts {
+		state, ok := states[name]
+		if !ok {
+			state = CommitStatusPending
+		}
+		checks = append(checks, &RequiredStatusCheck{Context: name, State: state})
+	}
+	return checks, nil
+}
+
+// MissingStatusChecks returns status checks required by the base branch of the
+// pull request that have not succeeded for the head commit.
+func (pr *PullRequest) MissingStatusChecks(ctx context.Context) ([]*RequiredStatusCheck, error) {
+	checks, err := pr.RequiredStatusChecks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := checks[:0]
+	for _, check := range checks {
+		if check.State != CommitStatusSuccess {
+			missing = append(missing, check)
+		}
+	}
+	return missing, nil
+}
+
+type ErrStatusChecksRequired struct {
+	args errutil.Args
+}
+
+func IsErrStatusChecksRequired(err error) bool {
+	_, ok := err.(ErrStatusChecksRequired)
+	return ok
+}
+
+func (err ErrStatusChecksRequired) Error() string {
+	return fmt.Sprintf("required status checks have not succeeded: %v", err.args)
+}
+
+// checkStatusChecks returns ErrStatusChecksRequired when any of status checks
+// required by the base branch has not succeeded for the head commit of the
+// pull request.
+func (pr *PullRequest) checkStatusChecks(ctx context.Context) error {
+	missing, err := pr.MissingStatusChecks(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get missing status checks")
+	} else if len(missing) > 0 {
+		contexts := make([]string, len(missing))
+		for i := range missing {
+			contexts[i] = missing[i].Context
+		}
+		return ErrStatusChecksRequired{args: errutil.Args{"pullRequestID": pr.ID, "contexts": contexts}}
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestCommitStatuses(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &commitStatuses{
+		DB: dbtest.NewDB(t, "commitStatuses", new(CommitStatus)),
+	}
+
+	const commitID = "d8e8fca2dc0f896fd7cb4cb0031ba249d8e8fca2"
+	status, err := db.Create(ctx, 1, 1, commitID, CreateCommitStatusOptions{State: CommitStatusPending})
+	require.NoError(t, err)
+	assert.Equal(t, "default", status.Context)
+
+	_, err = db.Create(ctx, 1, 1, commitID, CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusPending})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 2, 1, commitID, CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusFailure})
+	require.NoError(t, err)
+
+	// A new status of the same context replaces the old one
+	status, err = db.Create(ctx, 1, 2, commitID,
+		CreateCommitStatusOptions{
+			Context:     "ci/test",
+			State:       CommitStatusSuccess,
+			TargetURL:   "https://ci.example.com/builds/1",
+			Description: "All tests passed",
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), status.CreatorID)
+	assert.Equal(t, CommitStatusSuccess, status.State)
+
+	statuses, err := db.ListByCommit(ctx, 1, commitID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "ci/test", statuses[0].Context)
+	assert.Equal(t, CommitStatusSuccess, statuses[0].State)
+	assert.Equal(t, "https://ci.example.com/builds/1", statuses[0].TargetURL)
+	assert.Equal(t, "default", statuses[1].Context)
+}
+
+func TestPullRequest_requiredApprovalsAndStatusChecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser), new(PullRequest),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone), new(IssueWorkflowState),
+		new(ProtectBranch), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "requiredApprovalsAndStatusChecks", new(User), new(EmailAddress), new(Approval), new(CommitStatus))
+	SetMockUsersStore(t, NewUsersStore(gdb))
+	beforeApprovals, beforeCommitStatuses := Approvals, CommitStatuses
+	Approvals = NewApprovalsStore(gdb)
+	CommitStatuses = NewCommitStatusesStore(gdb)
+	t.Cleanup(func() {
+		Approvals, CommitStatuses = beforeApprovals, beforeCommitStatuses
+	})
+
+	newUser := func(t *testing.T, name string) *User {
+		u, err := Users.Create(ctx, name, name+"@example.com", CreateUserOptions{Activated: true})
+		require.NoError(t, err)
+		_, err = x.Insert(u)
+		require.NoError(t, err)
+		return u
+	}
+	alice := newUser(t, "alice")
+	bob := newUser(t, "bob")
+	carol := newUser(t, "carol")
+
+	repo := &Repository{OwnerID: alice.ID, Owner: alice, LowerName: "example", Name: "example"}
+	_, err := x.Insert(repo)
+	require.NoError(t, err)
+	repoPath := repo.RepoPath()
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("README.md", "Hello")
+	run("push", "origin", "main")
+	run("checkout", "-b", "feature")
+	commit("main.go", "package main")
+	run("push", "origin", "feature")
+
+	_, err = x.Insert(&ProtectBranch{
+		RepoID:               repo.ID,
+		Name:                 "main",
+		Protected:            true,
+		RequiredApprovals:    2,
+		RequiredStatusChecks: "ci/build,ci/test",
+	})
+	require.NoError(t, err)
+
+	issue := &Issue{RepoID: repo.ID, Repo: repo, PosterID: alice.ID, Poster: alice, Title: "Add main", IsPull: true}
+	pr := &PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadUserName: alice.Name,
+		HeadBranch:   "feature",
+		BaseBranch:   "main",
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+	}
+	err = NewPullRequest(repo, issue, nil, nil, pr, nil)
+	require.NoError(t, err)
+	pr.Issue = issue
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	baseGitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	headCommitID := func(t *testing.T) string {
+		t.Helper()
+		commitID, err := pr.headCommitID()
+		require.NoError(t, err)
+		return commitID
+	}
+	missingApprovals := func(t *testing.T) int {
+		t.Helper()
+		missing, err := pr.MissingApprovals(ctx)
+		require.NoError(t, err)
+		return missing
+	}
+	missingStatusChecks := func(t *testing.T) []string {
+		t.Helper()
+		missing, err := pr.MissingStatusChecks(ctx)
+		require.NoError(t, err)
+		contexts := make([]string, len(missing))
+		for i := range missing {
+			contexts[i] = missing[i].Context
+		}
+		return contexts
+	}
+
+	assert.Equal(t, 2, missingApprovals(t))
+	err = pr.Merge(alice, baseGitRepo, MERGE_STYLE_REGULAR, "")
+	assert.True(t, IsErrApprovalsRequired(err), "want ErrApprovalsRequired but got %v", err)
+
+	err = pr.Approve(ctx, bob)
+	require.NoError(t, err)
+	err = pr.Approve(ctx, carol)
+	require.NoError(t, err)
+	assert.Zero(t, missingApprovals(t))
+
+	assert.Equal(t, []string{"ci/build", "ci/test"}, missingStatusChecks(t))
+	err = pr.Merge(alice, baseGitRepo, MERGE_STYLE_REGULAR, "")
+	assert.True(t, IsErrStatusChecksRequired(err), "want ErrStatusChecksRequired but got %v", err)
+
+	_, err = CommitStatuses.Create(ctx, repo.ID, bob.ID, headCommitID(t), CreateCommitStatusOptions{Context: "ci/build", State: CommitStatusSuccess})
+	require.NoError(t, err)
+	_, err = CommitStatuses.Create(ctx, repo.ID, bob.ID, headCommitID(t), CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusFailure})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ci/test"}, missingStatusChecks(t))
+
+	_, err = CommitStatuses.Create(ctx, repo.ID, bob.ID, headCommitID(t), CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusSuccess})
+	require.NoError(t, err)
+	assert.Empty(t, missingStatusChecks(t))
+
+	// New commits need new approvals and status checks
+	run("checkout", "feature")
+	commit("main.go", "package main\n\nfunc main() {}")
+	run("push", "origin", "feature")
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+	assert.Equal(t, 2, missingApprovals(t))
+	assert.Equal(t, []string{"ci/build", "ci/test"}, missingStatusChecks(t))
+}
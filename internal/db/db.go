@@ -18,6 +18,7 @@ import (
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbutil"
+	"gogs.io/gogs/internal/markup"
 )
 
 func newLogWriter() (logger.Writer, error) {
@@ -41,11 +42,17 @@ func newLogWriter() (logger.Writer, error) {
 //
 // NOTE: Lines are sorted in alphabetical order, each letter in its own line.
 var Tables = []any{
-	new(Access), new(AccessToken), new(Action),
+	new(Access), new(AccessToken), new(Action), new(Approval), new(AuditLog),
+	new(CLASignature), new(CommentHistory), new(CommitStatus),
+	new(Deployment), new(DeploymentStatus),
 	new(EmailAddress),
 	new(Follow),
-	new(LFSObject), new(LoginSource),
+	new(IgnoredRepo), new(IssueWorkflowState),
+	new(LFSObject), new(LinkedIssue), new(LoginSource),
 	new(Notice),
+	new(OrgInvitation), new(OrgMirror),
+	new(RepoInvitation), new(RepoLanguage), new(RepoNotificationRecipient), new(RepoSecret), new(RepoTopic), new(ReviewRequest),
+	new(UserSession),
 }
 
 // Init initializes the database with given logger.
@@ -77,6 +84,12 @@ func Init(w logger.Writer) (*gorm.DB, error) {
 		return nil, errors.Wrap(err, "open database")
 	}
 
+	if conf.Metrics.Enabled {
+		if err = registerQueryMetrics(db); err != nil {
+			return nil, errors.Wrap(err, "register query metrics")
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, errors.Wrap(err, "get underlying *sql.DB")
@@ -123,14 +136,34 @@ func Init(w logger.Writer) (*gorm.DB, error) {
 	// Initialize stores, sorted in alphabetical order.
 	AccessTokens = &accessTokens{DB: db}
 	Actions = NewActionsStore(db)
+	Approvals = NewApprovalsStore(db)
+	AuditLogs = NewAuditLogsStore(db)
+	CLASignatures = NewCLASignaturesStore(db)
+	CommentHistories = NewCommentHistoriesStore(db)
+	CommitStatuses = NewCommitStatusesStore(db)
+	Deployments = NewDeploymentsStore(db)
+	IssueWorkflowStates = NewIssueWorkflowStatesStore(db)
 	LoginSources = &loginSources{DB: db, files: sourceFiles}
 	LFS = &lfs{DB: db}
+	LinkedIssues = NewLinkedIssuesStore(db)
 	Notices = NewNoticesStore(db)
+	OrgInvitations = NewOrgInvitationsStore(db)
+	OrgMirrors = NewOrgMirrorsStore(db)
 	Orgs = NewOrgsStore(db)
 	Perms = NewPermsStore(db)
+	ProtectBranches = NewProtectBranchesStore(db)
+	RepoInvitations = NewRepoInvitationsStore(db)
+	RepoLanguages = NewRepoLanguagesStore(db)
+	RepoNotificationRecipients = NewRepoNotificationRecipientsStore(db)
+	RepoSecrets = NewRepoSecretsStore(db)
 	Repos = NewReposStore(db)
+	ReviewRequests = NewReviewRequestsStore(db)
+	Topics = NewTopicsStore(db)
 	TwoFactors = &twoFactors{DB: db}
+	UserSessions = NewUserSessionsStore(db)
 	Users = NewUsersStore(db)
 
+	markup.ResolveRepoLink = newRepoLinkResolver(db)
+
 	return db, nil
 }
@@ -0,0 +1,492 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	api "github.com/gogs/go-gogs-client"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/testutil"
+)
+
+// DeploymentsStore is the persistent interface for deployments of repositories
+// and their statuses.
+type DeploymentsStore interface {
+	// Create creates a new deployment of the repository with given options, and
+	// its initial status in DeploymentStatePending.
+	Create(ctx context.Context, repoID, creatorID int64, opts CreateDeploymentOptions) (*Deployment, error)
+	// GetByID returns the deployment with given ID of the repository. It returns
+	// ErrDeploymentNotExist when not found.
+	GetByID(ctx context.Context, repoID, id int64) (*Deployment, error)
+	// List returns deployments of the repository matching given options, sorted
+	// from the newest.
+	List(ctx context.Context, repoID int64, opts ListDeploymentsOptions) ([]*Deployment, error)
+	// ListEnvironments returns names of environments that the repository has been
+	// deployed to, sorted in alphabetical order.
+	ListEnvironments(ctx context.Context, repoID int64) ([]string, error)
+	// CreateStatus creates a new status of the deployment with given ID of the
+	// repository, and updates the state of the deployment accordingly. It returns
+	// ErrDeploymentNotExist when the deployment is not found, and
+	// ErrDeploymentStateTransitionInvalid when the deployment can't transition to
+	// the state.
+	CreateStatus(ctx context.Context, repoID, deploymentID, creatorID int64, opts CreateDeploymentStatusOptions) (*DeploymentStatus, error)
+	// ListStatuses returns all statuses of the deployment with given ID of the
+	// repository, sorted from the newest.
+	ListStatuses(ctx context.Context, repoID, deploymentID int64) ([]*DeploymentStatus, error)
+}
+
+var Deployments DeploymentsStore
+
+var _ DeploymentsStore = (*deployments)(nil)
+
+type deployments struct {
+	*gorm.DB
+}
+
+// NewDeploymentsStore returns a persistent interface for deployments of
+// repositories with given database connection.
+func NewDeploymentsStore(db *gorm.DB) DeploymentsStore {
+	return &deployments{DB: db}
+}
+
+// DeploymentState is the state of a deployment.
+type DeploymentState string
+
+const (
+	DeploymentStatePending    DeploymentState = "pending"
+	DeploymentStateInProgress DeploymentState = "in_progress"
+	DeploymentStateSuccess    DeploymentState = "success"
+	DeploymentStateFailure    DeploymentState = "failure"
+	DeploymentStateError      DeploymentState = "error"
+)
+
+// IsValid returns true if the state is one of the known states.
+func (s DeploymentState) IsValid() bool {
+	switch s {
+	case DeploymentStatePending, DeploymentStateInProgress,
+		DeploymentStateSuccess, DeploymentStateFailure, DeploymentStateError:
+		return true
+	}
+	return false
+}
+
+// IsFinal returns true if the deployment has finished in the state.
+func (s DeploymentState) IsFinal() bool {
+	return s == DeploymentStateSuccess || s == DeploymentStateFailure || s == DeploymentStateError
+}
+
+// CanTransitionTo returns true if a deployment in the state can transition to
+// the next state. Deployments only move forward from pending to in progress and
+// then to one of the final states, which are never left.
+func (s DeploymentState) CanTransitionTo(next DeploymentState) bool {
+	if !next.IsValid() || s.IsFinal() {
+		return false
+	}
+	switch s {
+	case DeploymentStatePending:
+		return next != DeploymentStatePending
+	case DeploymentStateInProgress:
+		return next.IsFinal()
+	}
+	return false
+}
+
+// Deployment is a request to deploy a ref of a repository to an environment.
+type Deployment struct {
+	ID          int64  `gorm:"primaryKey"`
+	RepoID      int64  `gorm:"index;not null"`
+	CreatorID   int64  `gorm:"not null"`
+	Ref         string `gorm:"not null"`
+	SHA         string `gorm:"not null"`
+	Task        string `gorm:"not null"`
+	Environment string `gorm:"index;not null"`
+	// Payload is the JSON object of extra information for the deployment that is
+	// passed to external systems as it is.
+	Payload     string          `gorm:"type:TEXT"`
+	Description string          `gorm:"type:TEXT"`
+	State       DeploymentState `gorm:"not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Updated     time.Time `gorm:"-" json:"-"`
+	UpdatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (d *Deployment) BeforeCreate(tx *gorm.DB) error {
+	if d.CreatedUnix == 0 {
+		d.CreatedUnix = tx.NowFunc().Unix()
+		d.UpdatedUnix = d.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (d *Deployment) AfterFind(_ *gorm.DB) error {
+	d.Created = time.Unix(d.CreatedUnix, 0).Local()
+	d.Updated = time.Unix(d.UpdatedUnix, 0).Local()
+	return nil
+}
+
+// DeploymentStatus is a change of the state of a deployment.
+type DeploymentStatus struct {
+	ID           int64           `gorm:"primaryKey"`
+	RepoID       int64           `gorm:"index;not null"`
+	DeploymentID int64           `gorm:"index;not null"`
+	CreatorID    int64           `gorm:"not null"`
+	State        DeploymentState `gorm:"not null"`
+	TargetURL    string          `gorm:"type:TEXT"`
+	Description  string          `gorm:"type:TEXT"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (s *DeploymentStatus) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedUnix == 0 {
+		s.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (s *DeploymentStatus) AfterFind(_ *gorm.DB) error {
+	s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	return nil
+}
+
+type CreateDeploymentOptions struct {
+	Ref string
+	// SHA is the commit ID that the ref points to at the time of creation.
+	SHA string
+	// Task is the name of the task to run, default to "deploy" when empty.
+	Task string
+	// Environment is the name of the environment to deploy to, default to
+	// "production" when empty.
+	Environment string
+	Payload     string
+	Description string
+}
+
+func (db *deployments) Create(ctx context.Context, repoID, creatorID int64, opts CreateDeploymentOptions) (*Deployment, error) {
+	if opts.Task == "" {
+		opts.Task = "deploy"
+	}
+	if opts.Environment == "" {
+		opts.Environment = "production"
+	}
+
+	d := &Deployment{
+		RepoID:      repoID,
+		CreatorID:   creatorID,
+		Ref:         opts.Ref,
+		SHA:         opts.SHA,
+		Task:        opts.Task,
+		Environment: opts.Environment,
+		Payload:     opts.Payload,
+		Description: opts.Description,
+		State:       DeploymentStatePending,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(d).Error
+		if err != nil {
+			return errors.Wrap(err, "create deployment")
+		}
+
+		return tx.Create(
+			&DeploymentStatus{
+				RepoID:       repoID,
+				DeploymentID: d.ID,
+				CreatorID:    creatorID,
+				State:        DeploymentStatePending,
+			},
+		).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.GetByID(ctx, repoID, d.ID)
+}
+
+var _ errutil.NotFound = (*ErrDeploymentNotExist)(nil)
+
+type ErrDeploymentNotExist struct {
+	args errutil.Args
+}
+
+// IsErrDeploymentNotExist returns true if the underlying error has the type
+// ErrDeploymentNotExist.
+func IsErrDeploymentNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrDeploymentNotExist)
+	return ok
+}
+
+func (err ErrDeploymentNotExist) Error() string {
+	return fmt.Sprintf("deployment does not exist: %v", err.args)
+}
+
+func (ErrDeploymentNotExist) NotFound() bool {
+	return true
+}
+
+func (db *deployments) GetByID(ctx context.Context, repoID, id int64) (*Deployment, error) {
+	d := new(Deployment)
+	err := db.WithContext(ctx).Where("id = ? AND repo_id = ?", id, repoID).First(d).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrDeploymentNotExist{args: errutil.Args{"repoID": repoID, "id": id}}
+		}
+		return nil, err
+	}
+	return d, nil
+}
+
+type ListDeploymentsOptions struct {
+	// The ref, empty means any ref.
+	Ref string
+	// The task, empty means any task.
+	Task string
+	// The environment, empty means any environment.
+	Environment string
+}
+
+func (db *deployments) List(ctx context.Context, repoID int64, opts ListDeploymentsOptions) ([]*Deployment, error) {
+	query := db.WithContext(ctx).Where("repo_id = ?", repoID)
+	if opts.Ref != "" {
+		query = query.Where("ref = ?", opts.Ref)
+	}
+	if opts.Task != "" {
+		query = query.Where("task = ?", opts.Task)
+	}
+	if opts.Environment != "" {
+		query = query.Where("environment = ?", opts.Environment)
+	}
+
+	var ds []*Deployment
+	return ds, query.Order("id DESC").Find(&ds).Error
+}
+
+func (db *deployments) ListEnvironments(ctx context.Context, repoID int64) ([]string, error) {
+	var environments []string
+	return environments, db.WithContext(ctx).
+		Model(new(Deployment)).
+		Distinct("environment").
+		Where("repo_id = ?", repoID).
+		Order("environment ASC").
+		Pluck("environment", &environments).
+		Error
+}
+
+type ErrDeploymentStateTransitionInvalid struct {
+	args errutil.Args
+}
+
+// IsErrDeploymentStateTransitionInvalid returns true if the underlying error
+// has the type ErrDeploymentStateTransitionInvalid.
+func IsErrDeploymentStateTransitionInvalid(err error) bool {
+	_, ok := errors.Cause(err).(ErrDeploymentStateTransitionInvalid)
+	return ok
+}
+
+func (err ErrDeploymentStateTransitionInvalid) Error() string {
+	return fmt.Sprintf("deployment state transition is invalid: %v", err.args)
+}
+
+type CreateDeploymentStatusOptions struct {
+	State       DeploymentState
+	TargetURL   string
+	Description string
+}
+
+func (db *deployments) CreateStatus(ctx context.Context, repoID, deploymentID, creatorID int64, opts CreateDeploymentStatusOptions) (*DeploymentStatus, error) {
+	status := &DeploymentStatus{
+		RepoID:       repoID,
+		DeploymentID: deploymentID,
+		CreatorID:    creatorID,
+		State:        opts.State,
+		TargetURL:    opts.TargetURL,
+		Description:  opts.Description,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		d := new(Deployment)
+		err := tx.Where("id = ? AND repo_id = ?", deploymentID, repoID).First(d).Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return ErrDeploymentNotExist{args: errutil.Args{"repoID": repoID, "id": deploymentID}}
+			}
+			return err
+		}
+		if !d.State.CanTransitionTo(opts.State) {
+			return ErrDeploymentStateTransitionInvalid{args: errutil.Args{"from": d.State, "to": opts.State}}
+		}
+
+		err = tx.Create(status).Error
+		if err != nil {
+			return errors.Wrap(err, "create status")
+		}
+
+		// Only transit from the state that has been checked in case of concurrent
+		// updates.
+		result := tx.Model(d).
+			Where("state = ?", d.State).
+			Updates(map[string]any{
+				"state":        opts.State,
+				"updated_unix": tx.NowFunc().Unix(),
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update deployment")
+		} else if result.RowsAffected == 0 {
+			return ErrDeploymentStateTransitionInvalid{args: errutil.Args{"from": d.State, "to": opts.State}}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	status.Created = time.Unix(status.CreatedUnix, 0).Local()
+	return status, nil
+}
+
+func (db *deployments) ListStatuses(ctx context.Context, repoID, deploymentID int64) ([]*DeploymentStatus, error) {
+	var statuses []*DeploymentStatus
+	return statuses, db.WithContext(ctx).
+		Where("repo_id = ? AND deployment_id = ?", repoID, deploymentID).
+		Order("id DESC").
+		Find(&statuses).
+		Error
+}
+
+// APIDeployment is the API format of a deployment.
+type APIDeployment struct {
+	ID          int64           `json:"id"`
+	Ref         string          `json:"ref"`
+	SHA         string          `json:"sha"`
+	Task        string          `json:"task"`
+	Environment string          `json:"environment"`
+	Payload     json.RawMessage `json:"payload"`
+	Description string          `json:"description"`
+	State       DeploymentState `json:"state"`
+	Creator     *api.User       `json:"creator"`
+	Created     time.Time       `json:"created_at"`
+	Updated     time.Time       `json:"updated_at"`
+}
+
+// APIFormat returns the API format of the deployment created by the creator.
+func (d *Deployment) APIFormat(creator *User) *APIDeployment {
+	payload := json.RawMessage(d.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+	return &APIDeployment{
+		ID:          d.ID,
+		Ref:         d.Ref,
+		SHA:         d.SHA,
+		Task:        d.Task,
+		Environment: d.Environment,
+		Payload:     payload,
+		Description: d.Description,
+		State:       d.State,
+		Creator:     creator.APIFormat(),
+		Created:     d.Created,
+		Updated:     d.Updated,
+	}
+}
+
+// APIDeploymentStatus is the API format of a deployment status.
+type APIDeploymentStatus struct {
+	ID          int64           `json:"id"`
+	State       DeploymentState `json:"state"`
+	TargetURL   string          `json:"target_url"`
+	Description string          `json:"description"`
+	Creator     *api.User       `json:"creator"`
+	Created     time.Time       `json:"created_at"`
+}
+
+// APIFormat returns the API format of the deployment status created by the
+// creator.
+func (s *DeploymentStatus) APIFormat(creator *User) *APIDeploymentStatus {
+	return &APIDeploymentStatus{
+		ID:          s.ID,
+		State:       s.State,
+		TargetURL:   s.TargetURL,
+		Description: s.Description,
+		Creator:     creator.APIFormat(),
+		Created:     s.Created,
+	}
+}
+
+// DeploymentPayload represents a payload information of deployment event.
+type DeploymentPayload struct {
+	Action     string          `json:"action"`
+	Deployment *APIDeployment  `json:"deployment"`
+	Repository *api.Repository `json:"repository"`
+	Sender     *api.User       `json:"sender"`
+}
+
+func (p *DeploymentPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// DeploymentStatusPayload represents a payload information of deployment status
+// event.
+type DeploymentStatusPayload struct {
+	Action           string               `json:"action"`
+	DeploymentStatus *APIDeploymentStatus `json:"deployment_status"`
+	Deployment       *APIDeployment       `json:"deployment"`
+	Repository       *api.Repository      `json:"repository"`
+	Sender           *api.User            `json:"sender"`
+}
+
+func (p *DeploymentStatusPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// PrepareDeploymentWebhooks adds hook tasks of deployment event for the
+// deployment created by the doer.
+func PrepareDeploymentWebhooks(doer *User, repo *Repository, d *Deployment) error {
+	if x == nil && testutil.InTest {
+		return nil
+	}
+
+	return prepareWebhooks(x, repo, HOOK_EVENT_DEPLOYMENT, &DeploymentPayload{
+		Action:     "created",
+		Deployment: d.APIFormat(doer),
+		Repository: repo.APIFormatLegacy(nil),
+		Sender:     doer.APIFormat(),
+	})
+}
+
+// PrepareDeploymentStatusWebhooks adds hook tasks of deployment status event for
+// the status of the deployment created by the doer. The deployment is expected
+// to be in the state of the status.
+func PrepareDeploymentStatusWebhooks(doer *User, repo *Repository, d *Deployment, status *DeploymentStatus) error {
+	if x == nil && testutil.InTest {
+		return nil
+	}
+
+	creator, err := getUserByID(x, d.CreatorID)
+	if err != nil {
+		if !IsErrUserNotExist(err) {
+			return errors.Wrap(err, "get deployment creator")
+		}
+		creator = NewGhostUser()
+	}
+	return prepareWebhooks(x, repo, HOOK_EVENT_DEPLOYMENT_STATUS, &DeploymentStatusPayload{
+		Action:           "created",
+		DeploymentStatus: status.APIFormat(doer),
+		Deployment:       d.APIFormat(creator),
+		Repository:       repo.APIFormatLegacy(nil),
+		Sender:           doer.APIFormat(),
+	})
+}
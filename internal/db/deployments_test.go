@@ -0,0 +1,237 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestDeploymentState_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from DeploymentState
+		to   DeploymentState
+		want bool
+	}{
+		{from: DeploymentStatePending, to: DeploymentStateInProgress, want: true},
+		{from: DeploymentStatePending, to: DeploymentStateSuccess, want: true},
+		{from: DeploymentStatePending, to: DeploymentStatePending, want: false},
+		{from: DeploymentStateInProgress, to: DeploymentStateSuccess, want: true},
+		{from: DeploymentStateInProgress, to: DeploymentStateFailure, want: true},
+		{from: DeploymentStateInProgress, to: DeploymentStateError, want: true},
+		{from: DeploymentStateInProgress, to: DeploymentStatePending, want: false},
+		{from: DeploymentStateInProgress, to: DeploymentStateInProgress, want: false},
+		{from: DeploymentStateSuccess, to: DeploymentStateFailure, want: false},
+		{from: DeploymentStateFailure, to: DeploymentStateInProgress, want: false},
+		{from: DeploymentStatePending, to: "unknown", want: false},
+	}
+	for _, test := range tests {
+		t.Run(string(test.from)+"->"+string(test.to), func(t *testing.T) {
+			assert.Equal(t, test.want, test.from.CanTransitionTo(test.to))
+		})
+	}
+}
+
+func TestDeployments(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(Deployment), new(DeploymentStatus)}
+	db := &deployments{
+		DB: dbtest.NewDB(t, "deployments", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *deployments)
+	}{
+		{"Create", deploymentsCreate},
+		{"List", deploymentsList},
+		{"ListEnvironments", deploymentsListEnvironments},
+		{"CreateStatus", deploymentsCreateStatus},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func deploymentsCreate(t *testing.T, db *deployments) {
+	ctx := context.Background()
+
+	d, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "main", SHA: "1234567"})
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", d.Task)
+	assert.Equal(t, "production", d.Environment)
+	assert.Equal(t, DeploymentStatePending, d.State)
+	assert.Equal(t, db.NowFunc().Unix(), d.CreatedUnix)
+
+	// The initial status is created along with the deployment
+	statuses, err := db.ListStatuses(ctx, 1, d.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, DeploymentStatePending, statuses[0].State)
+
+	// Deployments of other repositories are not accessible
+	_, err = db.GetByID(ctx, 2, d.ID)
+	wantErr := ErrDeploymentNotExist{args: errutil.Args{"repoID": int64(2), "id": d.ID}}
+	assert.Equal(t, wantErr, err)
+}
+
+func deploymentsList(t *testing.T, db *deployments) {
+	ctx := context.Background()
+
+	d1, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "main", Environment: "staging"})
+	require.NoError(t, err)
+	d2, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "v1.0.0"})
+	require.NoError(t, err)
+	d3, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "main"})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 2, 2, CreateDeploymentOptions{Ref: "main"})
+	require.NoError(t, err)
+
+	ds, err := db.List(ctx, 1, ListDeploymentsOptions{})
+	require.NoError(t, err)
+	require.Len(t, ds, 3)
+	assert.Equal(t, []int64{d3.ID, d2.ID, d1.ID}, []int64{ds[0].ID, ds[1].ID, ds[2].ID})
+
+	ds, err = db.List(ctx, 1, ListDeploymentsOptions{Ref: "main", Environment: "production"})
+	require.NoError(t, err)
+	require.Len(t, ds, 1)
+	assert.Equal(t, d3.ID, ds[0].ID)
+}
+
+func deploymentsListEnvironments(t *testing.T, db *deployments) {
+	ctx := context.Background()
+
+	for _, env := range []string{"staging", "production", "staging"} {
+		_, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "main", Environment: env})
+		require.NoError(t, err)
+	}
+	_, err := db.Create(ctx, 2, 2, CreateDeploymentOptions{Ref: "main", Environment: "qa"})
+	require.NoError(t, err)
+
+	got, err := db.ListEnvironments(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"production", "staging"}, got)
+}
+
+func deploymentsCreateStatus(t *testing.T, db *deployments) {
+	ctx := context.Background()
+
+	d, err := db.Create(ctx, 1, 2, CreateDeploymentOptions{Ref: "main"})
+	require.NoError(t, err)
+
+	// Statuses of deployments in other repositories can't be created
+	_, err = db.CreateStatus(ctx, 2, d.ID, 2, CreateDeploymentStatusOptions{State: DeploymentStateInProgress})
+	assert.True(t, IsErrDeploymentNotExist(err))
+
+	for _, state := range []DeploymentState{DeploymentStateInProgress, DeploymentStateSuccess} {
+		status, err := db.CreateStatus(ctx, 1, d.ID, 3, CreateDeploymentStatusOptions{State: state, TargetURL: "https://example.com/logs"})
+		require.NoError(t, err)
+		assert.Equal(t, state, status.State)
+
+		got, err := db.GetByID(ctx, 1, d.ID)
+		require.NoError(t, err)
+		assert.Equal(t, state, got.State)
+	}
+
+	// A finished deployment can't transition again
+	_, err = db.CreateStatus(ctx, 1, d.ID, 3, CreateDeploymentStatusOptions{State: DeploymentStateFailure})
+	wantErr := ErrDeploymentStateTransitionInvalid{args: errutil.Args{"from": DeploymentStateSuccess, "to": DeploymentStateFailure}}
+	assert.Equal(t, wantErr, err)
+
+	statuses, err := db.ListStatuses(ctx, 1, d.ID)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	assert.Equal(t, DeploymentStateSuccess, statuses[0].State)
+	assert.Equal(t, int64(3), statuses[0].CreatorID)
+}
+
+func TestPrepareDeploymentWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Webhook), new(HookTask))
+	db := &deployments{
+		DB: dbtest.NewDB(t, "prepareDeploymentWebhooks", new(Deployment), new(DeploymentStatus)),
+	}
+	ctx := context.Background()
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	deployer := &User{ID: 2, LowerName: "ci", Name: "ci", Email: "ci@example.com"}
+	_, err := x.Insert(owner, deployer)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents:   HookEvents{Deployment: true, DeploymentStatus: true},
+		},
+		IsActive: true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	d, err := db.Create(ctx, repo.ID, owner.ID, CreateDeploymentOptions{Ref: "main", SHA: "1234567", Payload: `{"region":"eu"}`})
+	require.NoError(t, err)
+	err = PrepareDeploymentWebhooks(owner, repo, d)
+	require.NoError(t, err)
+
+	status, err := db.CreateStatus(ctx, repo.ID, d.ID, deployer.ID, CreateDeploymentStatusOptions{State: DeploymentStateInProgress})
+	require.NoError(t, err)
+	d.State = status.State
+	err = PrepareDeploymentStatusWebhooks(deployer, repo, d, status)
+	require.NoError(t, err)
+
+	var tasks []*HookTask
+	err = x.Asc("id").Find(&tasks)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+
+	assert.Equal(t, HOOK_EVENT_DEPLOYMENT, tasks[0].EventType)
+	var deploymentPayload DeploymentPayload
+	err = jsoniter.Unmarshal([]byte(tasks[0].PayloadContent), &deploymentPayload)
+	require.NoError(t, err)
+	assert.Equal(t, "created", deploymentPayload.Action)
+	assert.Equal(t, d.ID, deploymentPayload.Deployment.ID)
+	assert.Equal(t, "production", deploymentPayload.Deployment.Environment)
+	assert.JSONEq(t, `{"region":"eu"}`, string(deploymentPayload.Deployment.Payload))
+	assert.Equal(t, DeploymentStatePending, deploymentPayload.Deployment.State)
+	assert.Equal(t, "alice", deploymentPayload.Sender.UserName)
+
+	assert.Equal(t, HOOK_EVENT_DEPLOYMENT_STATUS, tasks[1].EventType)
+	var statusPayload DeploymentStatusPayload
+	err = jsoniter.Unmarshal([]byte(tasks[1].PayloadContent), &statusPayload)
+	require.NoError(t, err)
+	assert.Equal(t, DeploymentStateInProgress, statusPayload.DeploymentStatus.State)
+	assert.Equal(t, DeploymentStateInProgress, statusPayload.Deployment.State)
+	assert.Equal(t, "alice", statusPayload.Deployment.Creator.UserName)
+	assert.Equal(t, "ci", statusPayload.Sender.UserName)
+}
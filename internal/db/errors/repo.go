@@ -6,6 +6,7 @@ package errors
 
 import (
 	"fmt"
+	"time"
 )
 
 type InvalidRepoReference struct {
@@ -34,6 +35,34 @@ func (err MirrorNotExist) Error() string {
 	return fmt.Sprintf("mirror does not exist [repo_id: %d]", err.RepoID)
 }
 
+type MirrorIntervalTooShort struct {
+	Interval    int
+	MinInterval int
+}
+
+func IsMirrorIntervalTooShort(err error) bool {
+	_, ok := err.(MirrorIntervalTooShort)
+	return ok
+}
+
+func (err MirrorIntervalTooShort) Error() string {
+	return fmt.Sprintf("mirror interval is too short [interval: %d, min_interval: %d]", err.Interval, err.MinInterval)
+}
+
+type MirrorSyncCooldown struct {
+	RepoID    int64
+	Remaining time.Duration
+}
+
+func IsMirrorSyncCooldown(err error) bool {
+	_, ok := err.(MirrorSyncCooldown)
+	return ok
+}
+
+func (err MirrorSyncCooldown) Error() string {
+	return fmt.Sprintf("mirror was synced recently [repo_id: %d, remaining: %s]", err.RepoID, err.Remaining)
+}
+
 type BranchAlreadyExists struct {
 	Name string
 }
@@ -7,6 +7,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -43,9 +44,12 @@ type Issue struct {
 	AssigneeID      int64 `gorm:"index"`
 	Assignee        *User `xorm:"-" json:"-" gorm:"-"`
 	IsClosed        bool
-	IsRead          bool         `xorm:"-" json:"-" gorm:"-"`
-	IsPull          bool         // Indicates whether is a pull request or not.
-	PullRequest     *PullRequest `xorm:"-" json:"-" gorm:"-"`
+	WorkflowStateID int64               `gorm:"index"`
+	WorkflowState   *IssueWorkflowState `xorm:"-" json:"-" gorm:"-"`
+	StaleLabelID    int64               // The label applied when the issue was marked as stale, 0 if not stale.
+	IsRead          bool                `xorm:"-" json:"-" gorm:"-"`
+	IsPull          bool                // Indicates whether is a pull request or not.
+	PullRequest     *PullRequest        `xorm:"-" json:"-" gorm:"-"`
 	NumComments     int
 
 	Deadline     time.Time `xorm:"-" json:"-" gorm:"-"`
@@ -223,6 +227,15 @@ func (issue *Issue) APIFormat() *api.Issue {
 	return apiIssue
 }
 
+// Key returns the key of the issue for display, e.g. PROJ-123 when the
+// repository has a prefix of issue keys, or #123 otherwise.
+func (issue *Issue) Key() string {
+	if issue.Repo != nil && issue.Repo.IssueKeyPrefix != "" {
+		return fmt.Sprintf("%s-%d", issue.Repo.IssueKeyPrefix, issue.Index)
+	}
+	return "#" + strconv.FormatInt(issue.Index, 10)
+}
+
 // HashTag returns unique hash tag for issue.
 func (issue *Issue) HashTag() string {
 	return "issue-" + com.ToStr(issue.ID)
@@ -242,30 +255,61 @@ func (issue *Issue) HasLabel(labelID int64) bool {
 	return issue.hasLabel(x, labelID)
 }
 
-func (issue *Issue) sendLabelUpdatedWebhook(doer *User) {
-	var err error
+// newLabelsPayload returns the issues or pull request payload of a label
+// change with the given action, added and removed labels.
+func (issue *Issue) newLabelsPayload(doer *User, action api.HookIssueAction, added, removed []*Label) (HookEventType, api.Payloader) {
+	addedLabels := make([]*api.Label, len(added))
+	for i := range added {
+		addedLabels[i] = added[i].APIFormat()
+	}
+	removedLabels := make([]*api.Label, len(removed))
+	for i := range removed {
+		removedLabels[i] = removed[i].APIFormat()
+	}
+
 	if issue.IsPull {
-		err = issue.PullRequest.LoadIssue()
-		if err != nil {
-			log.Error("LoadIssue: %v", err)
-			return
+		return HOOK_EVENT_PULL_REQUEST, &PullRequestLabelsPayload{
+			PullRequestPayload: &api.PullRequestPayload{
+				Action:      action,
+				Index:       issue.Index,
+				PullRequest: issue.PullRequest.APIFormat(),
+				Repository:  issue.Repo.APIFormatLegacy(nil),
+				Sender:      doer.APIFormat(),
+			},
+			AddedLabels:   addedLabels,
+			RemovedLabels: removedLabels,
 		}
-		err = PrepareWebhooks(issue.Repo, HOOK_EVENT_PULL_REQUEST, &api.PullRequestPayload{
-			Action:      api.HOOK_ISSUE_LABEL_UPDATED,
-			Index:       issue.Index,
-			PullRequest: issue.PullRequest.APIFormat(),
-			Repository:  issue.Repo.APIFormatLegacy(nil),
-			Sender:      doer.APIFormat(),
-		})
-	} else {
-		err = PrepareWebhooks(issue.Repo, HOOK_EVENT_ISSUES, &api.IssuesPayload{
-			Action:     api.HOOK_ISSUE_LABEL_UPDATED,
+	}
+	return HOOK_EVENT_ISSUES, &IssuesLabelsPayload{
+		IssuesPayload: &api.IssuesPayload{
+			Action:     action,
 			Index:      issue.Index,
 			Issue:      issue.APIFormat(),
 			Repository: issue.Repo.APIFormatLegacy(nil),
 			Sender:     doer.APIFormat(),
-		})
+		},
+		AddedLabels:   addedLabels,
+		RemovedLabels: removedLabels,
 	}
+}
+
+// sendLabelsWebhook fires a single webhook for a batch of label changes. It
+// does nothing when no label has been added or removed.
+func (issue *Issue) sendLabelsWebhook(doer *User, action api.HookIssueAction, added, removed []*Label) {
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	if issue.IsPull {
+		err := issue.PullRequest.LoadIssue()
+		if err != nil {
+			log.Error("LoadIssue: %v", err)
+			return
+		}
+	}
+
+	event, p := issue.newLabelsPayload(doer, action, added, removed)
+	err := PrepareWebhooks(issue.Repo, event, p)
 	if err != nil {
 		log.Error("PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
 	}
@@ -277,11 +321,15 @@ func (issue *Issue) addLabel(e *xorm.Session, label *Label) error {
 
 // AddLabel adds a new label to the issue.
 func (issue *Issue) AddLabel(doer *User, label *Label) error {
+	if issue.HasLabel(label.ID) {
+		return nil
+	}
+
 	if err := NewIssueLabel(issue, label); err != nil {
 		return err
 	}
 
-	issue.sendLabelUpdatedWebhook(doer)
+	issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_UPDATED, []*Label{label}, nil)
 	return nil
 }
 
@@ -291,11 +339,16 @@ func (issue *Issue) addLabels(e *xorm.Session, labels []*Label) error {
 
 // AddLabels adds a list of new labels to the issue.
 func (issue *Issue) AddLabels(doer *User, labels []*Label) error {
+	if err := issue.getLabels(x); err != nil {
+		return err
+	}
+	added, _ := diffLabels(issue.Labels, labels)
+
 	if err := NewIssueLabels(issue, labels); err != nil {
 		return err
 	}
 
-	issue.sendLabelUpdatedWebhook(doer)
+	issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_UPDATED, added, nil)
 	return nil
 }
 
@@ -317,11 +370,15 @@ func (issue *Issue) removeLabel(e *xorm.Session, label *Label) error {
 
 // RemoveLabel removes a label from issue by given ID.
 func (issue *Issue) RemoveLabel(doer *User, label *Label) error {
+	if !issue.HasLabel(label.ID) {
+		return nil
+	}
+
 	if err := DeleteIssueLabel(issue, label); err != nil {
 		return err
 	}
 
-	issue.sendLabelUpdatedWebhook(doer)
+	issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_UPDATED, nil, []*Label{label})
 	return nil
 }
 
@@ -343,6 +400,12 @@ func (issue *Issue) clearLabels(e *xorm.Session) (err error) {
 }
 
 func (issue *Issue) ClearLabels(doer *User) (err error) {
+	if err = issue.getLabels(x); err != nil {
+		return fmt.Errorf("getLabels: %v", err)
+	}
+	removed := make([]*Label, len(issue.Labels))
+	copy(removed, issue.Labels)
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -357,37 +420,18 @@ func (issue *Issue) ClearLabels(doer *User) (err error) {
 		return fmt.Errorf("Commit: %v", err)
 	}
 
-	if issue.IsPull {
-		err = issue.PullRequest.LoadIssue()
-		if err != nil {
-			log.Error("LoadIssue: %v", err)
-			return err
-		}
-		err = PrepareWebhooks(issue.Repo, HOOK_EVENT_PULL_REQUEST, &api.PullRequestPayload{
-			Action:      api.HOOK_ISSUE_LABEL_CLEARED,
-			Index:       issue.Index,
-			PullRequest: issue.PullRequest.APIFormat(),
-			Repository:  issue.Repo.APIFormatLegacy(nil),
-			Sender:      doer.APIFormat(),
-		})
-	} else {
-		err = PrepareWebhooks(issue.Repo, HOOK_EVENT_ISSUES, &api.IssuesPayload{
-			Action:     api.HOOK_ISSUE_LABEL_CLEARED,
-			Index:      issue.Index,
-			Issue:      issue.APIFormat(),
-			Repository: issue.Repo.APIFormatLegacy(nil),
-			Sender:     doer.APIFormat(),
-		})
-	}
-	if err != nil {
-		log.Error("PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
-	}
-
+	issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_CLEARED, nil, removed)
 	return nil
 }
 
-// ReplaceLabels removes all current labels and add new labels to the issue.
-func (issue *Issue) ReplaceLabels(labels []*Label) (err error) {
+// ReplaceLabels removes all current labels and add new labels to the issue. It
+// fires a single webhook with all labels added and removed.
+func (issue *Issue) ReplaceLabels(doer *User, labels []*Label) (err error) {
+	if err = issue.getLabels(x); err != nil {
+		return fmt.Errorf("getLabels: %v", err)
+	}
+	added, removed := diffLabels(issue.Labels, labels)
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -400,7 +444,12 @@ func (issue *Issue) ReplaceLabels(labels []*Label) (err error) {
 		return fmt.Errorf("addLabels: %v", err)
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return fmt.Errorf("Commit: %v", err)
+	}
+
+	issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_UPDATED, added, removed)
+	return nil
 }
 
 func (issue *Issue) GetAssignee() (err error) {
@@ -442,6 +491,8 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 		return err
 	} else if err = updateIssueUsersByStatus(e, issue.ID, isClosed); err != nil {
 		return err
+	} else if err = issue.unsetMismatchedWorkflowState(e); err != nil {
+		return fmt.Errorf("unsetMismatchedWorkflowState: %v", err)
 	}
 
 	// Update issue count of labels
@@ -459,6 +510,13 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 		}
 	}
 
+	// Reopening is an activity that makes the issue no longer stale
+	if !issue.IsClosed {
+		if err = issue.unmarkStale(e); err != nil {
+			return fmt.Errorf("unmarkStale: %v", err)
+		}
+	}
+
 	// Update issue count of milestone
 	if err = changeMilestoneIssueStats(e, issue); err != nil {
 		return err
@@ -499,8 +557,10 @@ func (issue *Issue) ChangeStatus(doer *User, repo *Repository, isClosed bool) (e
 		}
 		if isClosed {
 			apiPullRequest.Action = api.HOOK_ISSUE_CLOSED
+			issue.PullRequest.cleanupRefs()
 		} else {
 			apiPullRequest.Action = api.HOOK_ISSUE_REOPENED
+			issue.PullRequest.restoreHeadRef()
 		}
 		err = PrepareWebhooks(repo, HOOK_EVENT_PULL_REQUEST, apiPullRequest)
 	} else {
@@ -663,11 +723,38 @@ type NewIssueOptions struct {
 	LableIDs    []int64
 	Attachments []string // In UUID format.
 	IsPull      bool
+	// The explicit index of the issue, which is only used for importing issues
+	// from elsewhere. The next index of the repository is used when it is zero.
+	Index int64
 }
 
 func newIssue(e *xorm.Session, opts NewIssueOptions) (err error) {
 	opts.Issue.Title = strings.TrimSpace(opts.Issue.Title)
-	opts.Issue.Index = opts.Repo.NextIssueIndex()
+
+	// The highest index of the repository after the issue is created.
+	var maxIndex int64
+	if opts.Index > 0 {
+		exist, err := e.Exist(&Issue{RepoID: opts.Issue.RepoID, Index: opts.Index})
+		if err != nil {
+			return fmt.Errorf("check existence of index: %v", err)
+		} else if exist {
+			return ErrIssueIndexAlreadyExist{args: map[string]any{"repoID": opts.Issue.RepoID, "index": opts.Index}}
+		}
+
+		// Use the latest counters because the repository could be stale when
+		// importing issues in batch.
+		repo, err := getRepositoryByID(e, opts.Issue.RepoID)
+		if err != nil {
+			return fmt.Errorf("get repository by ID: %v", err)
+		}
+		maxIndex = repo.NextIssueIndex() - 1
+		if opts.Index > maxIndex {
+			maxIndex = opts.Index
+		}
+		opts.Issue.Index = opts.Index
+	} else {
+		opts.Issue.Index = opts.Repo.NextIssueIndex()
+	}
 
 	if opts.Issue.MilestoneID > 0 {
 		milestone, err := getMilestoneByRepoID(e, opts.Issue.RepoID, opts.Issue.MilestoneID)
@@ -715,6 +802,15 @@ func newIssue(e *xorm.Session, opts NewIssueOptions) (err error) {
 		return err
 	}
 
+	// Keep the counter continuing from the highest index regardless of the order
+	// of explicit indexes.
+	if opts.Index > 0 {
+		_, err = e.Exec("UPDATE `repository` SET issue_index_offset = ? - num_issues - num_pulls WHERE id = ?", maxIndex, opts.Issue.RepoID)
+		if err != nil {
+			return fmt.Errorf("update issue index offset: %v", err)
+		}
+	}
+
 	if len(opts.LableIDs) > 0 {
 		// During the session, SQLite3 driver cannot handle retrieve objects after update something.
 		// So we have to get all needed labels first.
@@ -764,6 +860,13 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string)
 		return err
 	}
 
+	if issue.AssigneeID == 0 {
+		issue.AssigneeID, err = pickAutoAssignee(sess, repo)
+		if err != nil {
+			return fmt.Errorf("pick auto assignee: %v", err)
+		}
+	}
+
 	if err = newIssue(sess, NewIssueOptions{
 		Repo:        repo,
 		Issue:       issue,
@@ -803,9 +906,80 @@ func NewIssue(repo *Repository, issue *Issue, labelIDs []int64, uuids []string)
 		log.Error("PrepareWebhooks: %v", err)
 	}
 
+	notifyIssueClassifier(issue.ID)
 	return nil
 }
 
+// NewImportedIssue creates a new issue with the explicit index for repository,
+// which is only meant to be used for importing issues from elsewhere to
+// preserve their original numbers. It returns ErrIssueIndexAlreadyExist when the
+// index is already taken by another issue or pull request. No notifications are
+// sent for imported issues.
+func NewImportedIssue(repo *Repository, issue *Issue, index int64, labelIDs []int64) (err error) {
+	if index <= 0 {
+		return fmt.Errorf("invalid index: %d", index)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = newIssue(sess, NewIssueOptions{
+		Repo:     repo,
+		Issue:    issue,
+		LableIDs: labelIDs,
+		Index:    index,
+	}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// SetNextIssueIndex seeds the issue index counter of the repository so that
+// the next issue or pull request is created with given index. It returns
+// ErrIssueIndexAlreadyExist when the index is not higher than the highest index
+// of existing issues and pull requests.
+func SetNextIssueIndex(repoID, index int64) error {
+	if index <= 0 {
+		return fmt.Errorf("invalid index: %d", index)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	last := new(Issue)
+	has, err := sess.Where("repo_id = ?", repoID).Desc("index").Get(last)
+	if err != nil {
+		return fmt.Errorf("get the last issue: %v", err)
+	} else if has && last.Index >= index {
+		return ErrIssueIndexAlreadyExist{args: map[string]any{"repoID": repoID, "index": last.Index}}
+	}
+
+	_, err = sess.Exec("UPDATE `repository` SET issue_index_offset = ? - num_issues - num_pulls WHERE id = ?", index-1, repoID)
+	if err != nil {
+		return fmt.Errorf("update issue index offset: %v", err)
+	}
+	return sess.Commit()
+}
+
+type ErrIssueIndexAlreadyExist struct {
+	args map[string]any
+}
+
+func IsErrIssueIndexAlreadyExist(err error) bool {
+	_, ok := err.(ErrIssueIndexAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueIndexAlreadyExist) Error() string {
+	return fmt.Sprintf("issue index already exists: %v", err.args)
+}
+
 var _ errutil.NotFound = (*ErrIssueNotExist)(nil)
 
 type ErrIssueNotExist struct {
@@ -825,31 +999,6 @@ func (ErrIssueNotExist) NotFound() bool {
 	return true
 }
 
-// GetIssueByRef returns an Issue specified by a GFM reference, e.g. owner/repo#123.
-func GetIssueByRef(ref string) (*Issue, error) {
-	n := strings.IndexByte(ref, byte('#'))
-	if n == -1 {
-		return nil, ErrIssueNotExist{args: map[string]any{"ref": ref}}
-	}
-
-	index := com.StrTo(ref[n+1:]).MustInt64()
-	if index == 0 {
-		return nil, ErrIssueNotExist{args: map[string]any{"ref": ref}}
-	}
-
-	repo, err := GetRepositoryByRef(ref[:n])
-	if err != nil {
-		return nil, err
-	}
-
-	issue, err := GetIssueByIndex(repo.ID, index)
-	if err != nil {
-		return nil, err
-	}
-
-	return issue, issue.LoadAttributes()
-}
-
 // GetRawIssueByIndex returns raw issue without loading attributes by index in a repository.
 func GetRawIssueByIndex(repoID, index int64) (*Issue, error) {
 	issue := &Issue{
@@ -904,13 +1053,15 @@ type IssuesOptions struct {
 	RepoID      int64
 	PosterID    int64
 	MilestoneID int64
-	RepoIDs     []int64
-	Page        int
-	IsClosed    bool
-	IsMention   bool
-	IsPull      bool
-	Labels      string
-	SortType    string
+	// WorkflowStateID filters issues in the custom workflow state.
+	WorkflowStateID int64
+	RepoIDs         []int64
+	Page            int
+	IsClosed        bool
+	IsMention       bool
+	IsPull          bool
+	Labels          string
+	SortType        string
 }
 
 // buildIssuesQuery returns nil if it foresees there won't be any value returned.
@@ -943,6 +1094,10 @@ func buildIssuesQuery(opts *IssuesOptions) *xorm.Session {
 		sess.And("issue.milestone_id=?", opts.MilestoneID)
 	}
 
+	if opts.WorkflowStateID > 0 {
+		sess.And("issue.workflow_state_id=?", opts.WorkflowStateID)
+	}
+
 	sess.And("issue.is_pull=?", opts.IsPull)
 
 	switch opts.SortType {
@@ -1234,8 +1389,11 @@ type IssueStatsOptions struct {
 	Labels      string
 	MilestoneID int64
 	AssigneeID  int64
-	FilterMode  FilterMode
-	IsPull      bool
+	// WorkflowStateID counts issues in the custom workflow state, which are
+	// counted as open or closed by the status that the state maps to.
+	WorkflowStateID int64
+	FilterMode      FilterMode
+	IsPull          bool
 }
 
 // GetIssueStats returns issue statistic information by given conditions.
@@ -1260,6 +1418,10 @@ func GetIssueStats(opts *IssueStatsOptions) *IssueStats {
 			sess.And("assignee_id = ?", opts.AssigneeID)
 		}
 
+		if opts.WorkflowStateID > 0 {
+			sess.And("issue.workflow_state_id = ?", opts.WorkflowStateID)
+		}
+
 		return sess
 	}
 
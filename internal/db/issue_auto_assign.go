@@ -0,0 +1,188 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gogs.io/gogs/internal/tool"
+)
+
+// AutoAssignStrategy is the strategy to pick the assignee of new issues
+// automatically among members of a team.
+type AutoAssignStrategy string
+
+const (
+	AutoAssignDisabled    AutoAssignStrategy = ""
+	AutoAssignRoundRobin  AutoAssignStrategy = "round_robin"  // Members take turns in the order of user ID
+	AutoAssignLeastLoaded AutoAssignStrategy = "least_loaded" // The member with fewest open issues assigned
+)
+
+// IsValid returns true if the strategy is a known strategy or disabled.
+func (s AutoAssignStrategy) IsValid() bool {
+	switch s {
+	case AutoAssignDisabled, AutoAssignRoundRobin, AutoAssignLeastLoaded:
+		return true
+	}
+	return false
+}
+
+// IsAutoAssignEnabled returns true if new issues of the repository are assigned
+// automatically.
+func (repo *Repository) IsAutoAssignEnabled() bool {
+	return repo.AutoAssignStrategy != AutoAssignDisabled && repo.AutoAssignTeamID > 0
+}
+
+// AutoAssignUnavailableUserIDList returns the list of IDs of users who are
+// marked unavailable for automatic assignment of new issues of the repository.
+func (repo *Repository) AutoAssignUnavailableUserIDList() []int64 {
+	if repo.AutoAssignUnavailableUserIDs == "" {
+		return nil
+	}
+	return tool.StringsToInt64s(strings.Split(repo.AutoAssignUnavailableUserIDs, ","))
+}
+
+// autoAssignCandidates returns IDs of active members of the team for automatic
+// assignment sorted in ascending order, excluding members who have opted out or
+// are marked unavailable for the repository.
+func autoAssignCandidates(e Engine, repo *Repository, teamID int64) ([]int64, error) {
+	memberIDs := make([]int64, 0, 5)
+	err := e.Table("team_user").Cols("team_user.uid").
+		Join("INNER", "`user`", "`user`.id = team_user.uid").
+		Where("team_user.team_id = ? AND team_user.org_id = ?", teamID, repo.OwnerID).
+		And("`user`.is_active = ? AND `user`.auto_assign_opt_out = ?", true, false).
+		Asc("team_user.uid").
+		Find(&memberIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get team members: %v", err)
+	}
+
+	unavailable := make(map[int64]bool)
+	for _, id := range repo.AutoAssignUnavailableUserIDList() {
+		unavailable[id] = true
+	}
+
+	candidates := memberIDs[:0]
+	for _, id := range memberIDs {
+		if !unavailable[id] {
+			candidates = append(candidates, id)
+		}
+	}
+	return candidates, nil
+}
+
+// pickAutoAssignee returns the ID of the user to be assigned to a new issue of
+// the repository by its strategy, or 0 if there is no available candidate. The
+// round-robin cursor of the repository is advanced when needed.
+func pickAutoAssignee(e Engine, repo *Repository) (int64, error) {
+	if !repo.IsAutoAssignEnabled() {
+		return 0, nil
+	}
+
+	candidates, err := autoAssignCandidates(e, repo, repo.AutoAssignTeamID)
+	if err != nil {
+		return 0, err
+	} else if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	switch repo.AutoAssignStrategy {
+	case AutoAssignRoundRobin:
+		// Use the latest cursor because the repository could be stale when issues
+		// are created concurrently.
+		var cursor int64
+		if _, err = e.Table("repository").Cols("auto_assign_cursor").Where("id = ?", repo.ID).Get(&cursor); err != nil {
+			return 0, fmt.Errorf("get round-robin cursor: %v", err)
+		}
+
+		assigneeID := candidates[0]
+		for _, id := range candidates {
+			if id > cursor {
+				assigneeID = id
+				break
+			}
+		}
+
+		if _, err = e.Exec("UPDATE `repository` SET auto_assign_cursor = ? WHERE id = ?", assigneeID, repo.ID); err != nil {
+			return 0, fmt.Errorf("update round-robin cursor: %v", err)
+		}
+		repo.AutoAssignCursor = assigneeID
+		return assigneeID, nil
+
+	case AutoAssignLeastLoaded:
+		type assigneeCount struct {
+			AssigneeID int64
+			Count      int64
+		}
+		counts := make([]*assigneeCount, 0, len(candidates))
+		err = e.Table("issue").Select("assignee_id, COUNT(*) AS count").
+			Where("repo_id = ? AND is_pull = ? AND is_closed = ?", repo.ID, false, false).
+			In("assignee_id", candidates).
+			GroupBy("assignee_id").
+			Find(&counts)
+		if err != nil {
+			return 0, fmt.Errorf("count open issues by assignee: %v", err)
+		}
+
+		numIssues := make(map[int64]int64, len(counts))
+		for _, c := range counts {
+			numIssues[c.AssigneeID] = c.Count
+		}
+
+		// Ties are broken by the lowest user ID.
+		assigneeID := candidates[0]
+		for _, id := range candidates[1:] {
+			if numIssues[id] < numIssues[assigneeID] {
+				assigneeID = id
+			}
+		}
+		return assigneeID, nil
+	}
+	return 0, nil
+}
+
+// SetDefaultPullAssignee sets the default assignee of new pull requests of the
+// repository by the username, without saving the repository. An empty username
+// removes the default assignee. It returns ErrUserNotExist when the user does
+// not exist or cannot be assigned to issues of the repository.
+func (repo *Repository) SetDefaultPullAssignee(ctx context.Context, username string) error {
+	if username == "" {
+		repo.DefaultPullAssigneeID = 0
+		return nil
+	}
+
+	u, err := Users.GetByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+	if _, err = repo.GetAssigneeByID(u.ID); err != nil {
+		return err
+	}
+	repo.DefaultPullAssigneeID = u.ID
+	return nil
+}
+
+// pickDefaultPullAssignee returns the ID of the default assignee of new pull
+// requests of the repository. It returns 0 when there is no default assignee,
+// the pull request is opened by the default assignee, or the default assignee
+// is no longer active.
+func pickDefaultPullAssignee(e Engine, repo *Repository, posterID int64) (int64, error) {
+	if repo.DefaultPullAssigneeID <= 0 || repo.DefaultPullAssigneeID == posterID {
+		return 0, nil
+	}
+
+	assignee, err := getUserByID(e, repo.DefaultPullAssigneeID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("get user by ID: %v", err)
+	} else if !assignee.IsActive {
+		return 0, nil
+	}
+	return assignee.ID, nil
+}
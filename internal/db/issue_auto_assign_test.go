@@ -0,0 +1,176 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickAutoAssignee(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(TeamUser), new(Repository), new(Issue))
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization}
+	alice := &User{LowerName: "alice", Name: "alice", IsActive: true}
+	bob := &User{LowerName: "bob", Name: "bob", IsActive: true}
+	carol := &User{LowerName: "carol", Name: "carol", IsActive: true, AutoAssignOptOut: true}
+	dave := &User{LowerName: "dave", Name: "dave", IsActive: true}
+	eve := &User{LowerName: "eve", Name: "eve", IsActive: false}
+	_, err := x.Insert(org, alice, bob, carol, dave, eve)
+	require.NoError(t, err)
+
+	const teamID = 1
+	for _, u := range []*User{alice, bob, carol, dave, eve} {
+		_, err = x.Insert(&TeamUser{OrgID: org.ID, TeamID: teamID, UID: u.ID})
+		require.NoError(t, err)
+	}
+
+	newRepo := func(t *testing.T, name string, strategy AutoAssignStrategy) *Repository {
+		repo := &Repository{
+			OwnerID:                      org.ID,
+			LowerName:                    name,
+			Name:                         name,
+			AutoAssignTeamID:             teamID,
+			AutoAssignStrategy:           strategy,
+			AutoAssignUnavailableUserIDs: fmt.Sprintf("%d", dave.ID),
+		}
+		_, err := x.Insert(repo)
+		require.NoError(t, err)
+		return repo
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := newRepo(t, "disabled", AutoAssignDisabled)
+
+		got, err := pickAutoAssignee(x, repo)
+		require.NoError(t, err)
+		assert.Zero(t, got)
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		repo := newRepo(t, "round-robin", AutoAssignRoundRobin)
+
+		// Members who have opted out, are unavailable or inactive are skipped
+		var got []int64
+		for i := 0; i < 4; i++ {
+			id, err := pickAutoAssignee(x, repo)
+			require.NoError(t, err)
+			got = append(got, id)
+		}
+		assert.Equal(t, []int64{alice.ID, bob.ID, alice.ID, bob.ID}, got)
+
+		// The cursor is persisted so that the rotation continues with a fresh
+		// copy of the repository, e.g. after restarts.
+		stale, err := getRepositoryByID(x, repo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, stale.AutoAssignCursor)
+		stale.AutoAssignCursor = 0
+
+		id, err := pickAutoAssignee(x, stale)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, id)
+
+		// The next member takes the turn when the current one becomes unavailable
+		stale.AutoAssignUnavailableUserIDs = fmt.Sprintf("%d,%d", dave.ID, bob.ID)
+		id, err = pickAutoAssignee(x, stale)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, id)
+	})
+
+	t.Run("least loaded", func(t *testing.T) {
+		repo := newRepo(t, "least-loaded", AutoAssignLeastLoaded)
+		other := newRepo(t, "other", AutoAssignLeastLoaded)
+
+		for _, issue := range []*Issue{
+			{RepoID: repo.ID, Index: 1, AssigneeID: alice.ID},
+			{RepoID: repo.ID, Index: 2, AssigneeID: alice.ID},
+			{RepoID: repo.ID, Index: 3, AssigneeID: bob.ID},
+			{RepoID: repo.ID, Index: 4, AssigneeID: bob.ID, IsClosed: true},
+			{RepoID: repo.ID, Index: 5, AssigneeID: bob.ID, IsClosed: true},
+			{RepoID: repo.ID, Index: 6, AssigneeID: bob.ID, IsPull: true},
+			{RepoID: other.ID, Index: 1, AssigneeID: bob.ID},
+		} {
+			_, err := x.Insert(issue)
+			require.NoError(t, err)
+		}
+
+		got, err := pickAutoAssignee(x, repo)
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, got)
+
+		// Ties are broken by the lowest user ID
+		_, err = x.Insert(&Issue{RepoID: repo.ID, Index: 7, AssigneeID: bob.ID})
+		require.NoError(t, err)
+		got, err = pickAutoAssignee(x, repo)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, got)
+	})
+}
+
+func TestPickDefaultPullAssignee(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User))
+
+	alice := &User{LowerName: "alice", Name: "alice", IsActive: true}
+	bob := &User{LowerName: "bob", Name: "bob", IsActive: true}
+	eve := &User{LowerName: "eve", Name: "eve", IsActive: false}
+	_, err := x.Insert(alice, bob, eve)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		assigneeID int64
+		posterID   int64
+		want       int64
+	}{
+		{
+			name:     "no default assignee",
+			posterID: bob.ID,
+			want:     0,
+		},
+		{
+			name:       "unassigned pull request",
+			assigneeID: alice.ID,
+			posterID:   bob.ID,
+			want:       alice.ID,
+		},
+		{
+			name:       "opened by the default assignee",
+			assigneeID: alice.ID,
+			posterID:   alice.ID,
+			want:       0,
+		},
+		{
+			name:       "inactive default assignee",
+			assigneeID: eve.ID,
+			posterID:   bob.ID,
+			want:       0,
+		},
+		{
+			name:       "default assignee no longer exists",
+			assigneeID: 404,
+			posterID:   bob.ID,
+			want:       0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &Repository{DefaultPullAssigneeID: test.assigneeID}
+			got, err := pickDefaultPullAssignee(x, repo, test.posterID)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	api "github.com/gogs/go-gogs-client"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// IssueClassifierPayload is the JSON payload delivered to the instance-wide
+// issue classifier.
+type IssueClassifierPayload struct {
+	Repository string `json:"repository"`
+	Index      int64  `json:"index"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	Poster     string `json:"poster"`
+	// Labels are names of labels of the repository that the classifier is
+	// allowed to apply.
+	Labels []string `json:"labels"`
+}
+
+// IssueClassifierResponse is the JSON response of the issue classifier.
+type IssueClassifierResponse struct {
+	// Labels are names of labels to apply to the issue.
+	Labels []string `json:"labels"`
+}
+
+// isLabelAllowedForClassifier returns true if the label is allowed to be applied
+// by the issue classifier. Any label is allowed when the list of allowed labels
+// is empty.
+func isLabelAllowedForClassifier(name string) bool {
+	allowed := conf.Repository.IssueClassifier.AllowedLabels
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(strings.TrimSpace(a), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyIssue sends the payload to the configured issue classifier and
+// returns names of labels in the response.
+func classifyIssue(ctx context.Context, p *IssueClassifierPayload) ([]string, error) {
+	data, err := jsoniter.Marshal(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal payload")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, conf.Repository.IssueClassifier.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "new request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gogs-Event", "issue_classify")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "send request")
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode/100 != 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil, errors.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	var result IssueClassifierResponse
+	err = jsoniter.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode response")
+	}
+	return result.Labels, nil
+}
+
+// applyClassifiedLabels sends the issue to the issue classifier and applies
+// returned labels that are allowed and exist in the repository. It returns
+// labels that are newly added to the issue.
+func (issue *Issue) applyClassifiedLabels(ctx context.Context) ([]*Label, error) {
+	labels, err := GetLabelsByRepoID(issue.RepoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get labels of repository")
+	}
+	candidates := make(map[string]string, len(labels))
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if isLabelAllowedForClassifier(label.Name) {
+			candidates[strings.ToLower(label.Name)] = label.Name
+			names = append(names, label.Name)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	p := &IssueClassifierPayload{
+		Index:  issue.Index,
+		Title:  issue.Title,
+		Body:   issue.Content,
+		Labels: names,
+	}
+	if issue.Repo != nil {
+		p.Repository = issue.Repo.FullName()
+	}
+	if issue.Poster != nil {
+		p.Poster = issue.Poster.Name
+	}
+	classified, err := classifyIssue(ctx, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "classify issue")
+	}
+
+	var apply []string
+	for _, name := range classified {
+		if name, ok := candidates[strings.ToLower(strings.TrimSpace(name))]; ok {
+			apply = append(apply, name)
+		}
+	}
+	if len(apply) == 0 {
+		return nil, nil
+	}
+
+	added, _, err := issue.applyAutoLabels(apply)
+	if err != nil {
+		return nil, errors.Wrap(err, "apply labels")
+	}
+	return added, nil
+}
+
+// notifyIssueClassifier sends the new issue with given ID to the issue
+// classifier asynchronously when it is enabled, so it never blocks creating the
+// issue. Failures are logged.
+func notifyIssueClassifier(issueID int64) {
+	opts := conf.Repository.IssueClassifier
+	if !opts.Enabled || opts.URL == "" {
+		return
+	}
+
+	go func() {
+		timeout := opts.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		// NOTE: The issue is loaded again to not share it with the caller.
+		issue, err := GetIssueByID(issueID)
+		if err != nil {
+			log.Error("Failed to get issue for classifier [id: %d]: %v", issueID, err)
+			return
+		}
+
+		added, err := issue.applyClassifiedLabels(ctx)
+		if err != nil {
+			log.Error("Failed to apply classified labels [repo_id: %d, index: %d]: %v", issue.RepoID, issue.Index, err)
+			return
+		}
+		log.Trace("Issue classifier [repo_id: %d, index: %d]: %d labels added", issue.RepoID, issue.Index, len(added))
+		issue.sendLabelsWebhook(issue.Poster, api.HOOK_ISSUE_LABEL_UPDATED, added, nil)
+	}()
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func setIssueClassifierConf(t *testing.T, url string, allowedLabels ...string) {
+	before := conf.Repository.IssueClassifier
+	t.Cleanup(func() {
+		conf.Repository.IssueClassifier = before
+	})
+
+	conf.Repository.IssueClassifier.Enabled = true
+	conf.Repository.IssueClassifier.URL = url
+	conf.Repository.IssueClassifier.AllowedLabels = allowedLabels
+}
+
+func TestIssue_applyClassifiedLabels(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Label), new(IssueLabel))
+
+	bug := &Label{RepoID: 1, Name: "bug", Color: "#ff0000"}
+	wontfix := &Label{RepoID: 1, Name: "wontfix", Color: "#ffffff"}
+	documentation := &Label{RepoID: 1, Name: "documentation", Color: "#0000ff"}
+	for _, label := range []*Label{bug, wontfix, documentation} {
+		_, err := x.Insert(label)
+		require.NoError(t, err)
+	}
+
+	var gotPayload *IssueClassifierPayload
+	respLabels := []string{"Bug", "unknown", "wontfix"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPayload = new(IssueClassifierPayload)
+		err := jsoniter.NewDecoder(r.Body).Decode(gotPayload)
+		require.NoError(t, err)
+
+		if respLabels == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = jsoniter.NewEncoder(w).Encode(IssueClassifierResponse{Labels: respLabels})
+	}))
+	defer server.Close()
+
+	labelNames := func(t *testing.T, issueID int64) []string {
+		labels, err := GetLabelsByIssueID(issueID)
+		require.NoError(t, err)
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, label.Name)
+		}
+		return names
+	}
+
+	t.Run("whitelisted labels are applied", func(t *testing.T) {
+		setIssueClassifierConf(t, server.URL, "bug", "unknown", "documentation")
+		issue := &Issue{
+			ID:      1,
+			RepoID:  1,
+			Index:   1,
+			Title:   "Crash on start",
+			Content: "It crashes.",
+			Repo:    &Repository{ID: 1, Name: "repo1", Owner: &User{Name: "alice"}},
+			Poster:  &User{Name: "bob"},
+		}
+
+		added, err := issue.applyClassifiedLabels(context.Background())
+		require.NoError(t, err)
+		require.Len(t, added, 1)
+		assert.Equal(t, "bug", added[0].Name)
+		assert.Equal(t, []string{"bug"}, labelNames(t, issue.ID))
+
+		want := &IssueClassifierPayload{
+			Repository: "alice/repo1",
+			Index:      1,
+			Title:      "Crash on start",
+			Body:       "It crashes.",
+			Poster:     "bob",
+			Labels:     []string{"bug", "documentation"},
+		}
+		assert.Equal(t, want, gotPayload)
+	})
+
+	t.Run("any existing label is allowed without whitelist", func(t *testing.T) {
+		setIssueClassifierConf(t, server.URL)
+		issue := &Issue{ID: 2, RepoID: 1, Index: 2}
+
+		added, err := issue.applyClassifiedLabels(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, added, 2)
+		assert.ElementsMatch(t, []string{"bug", "wontfix"}, labelNames(t, issue.ID))
+	})
+
+	t.Run("no candidate labels", func(t *testing.T) {
+		setIssueClassifierConf(t, server.URL, "question")
+		gotPayload = nil
+		issue := &Issue{ID: 3, RepoID: 1, Index: 3}
+
+		added, err := issue.applyClassifiedLabels(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, added)
+		assert.Nil(t, gotPayload, "classifier should not be called")
+	})
+
+	t.Run("classifier failure", func(t *testing.T) {
+		setIssueClassifierConf(t, server.URL, "bug")
+		respLabels = nil
+		issue := &Issue{ID: 4, RepoID: 1, Index: 4}
+
+		_, err := issue.applyClassifiedLabels(context.Background())
+		assert.Error(t, err)
+		assert.Empty(t, labelNames(t, issue.ID))
+	})
+}
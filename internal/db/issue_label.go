@@ -73,6 +73,33 @@ func (label *Label) APIFormat() *api.Label {
 	}
 }
 
+// diffLabels returns labels that only exist in the new list as added and labels
+// that only exist in the old list as removed. Duplicates are reported once.
+func diffLabels(oldLabels, newLabels []*Label) (added, removed []*Label) {
+	oldIDs := make(map[int64]bool, len(oldLabels))
+	for _, l := range oldLabels {
+		oldIDs[l.ID] = true
+	}
+	newIDs := make(map[int64]bool, len(newLabels))
+	for _, l := range newLabels {
+		if newIDs[l.ID] {
+			continue
+		}
+		newIDs[l.ID] = true
+
+		if !oldIDs[l.ID] {
+			added = append(added, l)
+		}
+	}
+	for _, l := range oldLabels {
+		if !newIDs[l.ID] {
+			removed = append(removed, l)
+			newIDs[l.ID] = true // Prevent reporting duplicates
+		}
+	}
+	return added, removed
+}
+
 // CalOpenIssues calculates the open issues of label.
 func (label *Label) CalOpenIssues() {
 	label.NumOpenIssues = label.NumIssues - label.NumClosedIssues
@@ -262,6 +289,9 @@ type IssueLabel struct {
 	ID      int64
 	IssueID int64 `xorm:"UNIQUE(s)"`
 	LabelID int64 `xorm:"UNIQUE(s)"`
+	// Whether the label is applied automatically by changed paths of the pull
+	// request, which is removed automatically when no longer matches.
+	IsAuto bool
 }
 
 func hasIssueLabel(e Engine, issueID, labelID int64) bool {
@@ -275,9 +305,14 @@ func HasIssueLabel(issueID, labelID int64) bool {
 }
 
 func newIssueLabel(e *xorm.Session, issue *Issue, label *Label) (err error) {
+	return addIssueLabel(e, issue, label, false)
+}
+
+func addIssueLabel(e *xorm.Session, issue *Issue, label *Label, isAuto bool) (err error) {
 	if _, err = e.Insert(&IssueLabel{
 		IssueID: issue.ID,
 		LabelID: label.ID,
+		IsAuto:  isAuto,
 	}); err != nil {
 		return err
 	}
@@ -0,0 +1,104 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	api "github.com/gogs/go-gogs-client"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_diffLabels(t *testing.T) {
+	bug := &Label{ID: 1, Name: "bug"}
+	feature := &Label{ID: 2, Name: "feature"}
+	docs := &Label{ID: 3, Name: "docs"}
+
+	tests := []struct {
+		name        string
+		oldLabels   []*Label
+		newLabels   []*Label
+		wantAdded   []*Label
+		wantRemoved []*Label
+	}{
+		{
+			name:      "nothing changed",
+			oldLabels: []*Label{bug},
+			newLabels: []*Label{bug},
+		},
+		{
+			name:      "only added",
+			newLabels: []*Label{bug, feature},
+			wantAdded: []*Label{bug, feature},
+		},
+		{
+			name:        "only removed",
+			oldLabels:   []*Label{bug, feature},
+			wantRemoved: []*Label{bug, feature},
+		},
+		{
+			name:        "replaced",
+			oldLabels:   []*Label{bug, feature},
+			newLabels:   []*Label{feature, docs},
+			wantAdded:   []*Label{docs},
+			wantRemoved: []*Label{bug},
+		},
+		{
+			name:      "duplicates",
+			oldLabels: []*Label{bug},
+			newLabels: []*Label{docs, docs, bug},
+			wantAdded: []*Label{docs},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			added, removed := diffLabels(test.oldLabels, test.newLabels)
+			assert.Equal(t, test.wantAdded, added)
+			assert.Equal(t, test.wantRemoved, removed)
+		})
+	}
+}
+
+func TestIssuesLabelsPayload_JSONPayload(t *testing.T) {
+	p := &IssuesLabelsPayload{
+		IssuesPayload: &api.IssuesPayload{
+			Action: api.HOOK_ISSUE_LABEL_UPDATED,
+			Index:  1,
+		},
+		AddedLabels:   []*api.Label{(&Label{ID: 3, Name: "docs", Color: "#000000"}).APIFormat()},
+		RemovedLabels: []*api.Label{(&Label{ID: 1, Name: "bug", Color: "#ffffff"}).APIFormat()},
+	}
+	data, err := p.JSONPayload()
+	require.NoError(t, err)
+
+	var got struct {
+		Action        string       `json:"action"`
+		Number        int64        `json:"number"`
+		AddedLabels   []*api.Label `json:"added_labels"`
+		RemovedLabels []*api.Label `json:"removed_labels"`
+	}
+	err = jsoniter.Unmarshal(data, &got)
+	require.NoError(t, err)
+
+	assert.Equal(t, "label_updated", got.Action)
+	assert.Equal(t, int64(1), got.Number)
+	assert.Equal(t, []*api.Label{{ID: 3, Name: "docs", Color: "000000"}}, got.AddedLabels)
+	assert.Equal(t, []*api.Label{{ID: 1, Name: "bug", Color: "ffffff"}}, got.RemovedLabels)
+}
+
+func Test_unwrapLabelsPayload(t *testing.T) {
+	issues := &api.IssuesPayload{Action: api.HOOK_ISSUE_LABEL_UPDATED}
+	pull := &api.PullRequestPayload{Action: api.HOOK_ISSUE_LABEL_UPDATED}
+
+	assert.True(t, isLabelsPayload(&IssuesLabelsPayload{IssuesPayload: issues}))
+	assert.True(t, isLabelsPayload(&PullRequestLabelsPayload{PullRequestPayload: pull}))
+	assert.False(t, isLabelsPayload(issues))
+
+	assert.Equal(t, issues, unwrapLabelsPayload(&IssuesLabelsPayload{IssuesPayload: issues}))
+	assert.Equal(t, pull, unwrapLabelsPayload(&PullRequestLabelsPayload{PullRequestPayload: pull}))
+	assert.Equal(t, issues, unwrapLabelsPayload(issues))
+}
@@ -7,6 +7,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/unknwon/com"
@@ -15,6 +18,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/email"
 	"gogs.io/gogs/internal/markup"
+	"gogs.io/gogs/internal/repoutil"
 	"gogs.io/gogs/internal/userutil"
 )
 
@@ -95,11 +99,47 @@ func NewMailerIssue(issue *Issue) email.Issue {
 	return mailerIssue{issue}
 }
 
+// notificationListRecipients returns email addresses of notification recipients
+// of the repository that subscribe to the new issue or comment, excluding given
+// email addresses which are notified otherwise.
+func notificationListRecipients(ctx context.Context, issue *Issue, isComment bool, excludes []string) ([]string, error) {
+	recipients, err := RepoNotificationRecipients.ListByEvent(ctx, issue.RepoID, issueNotificationEvent(issue, isComment))
+	if err != nil {
+		return nil, err
+	}
+
+	tos := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		excluded := false
+		for _, email := range excludes {
+			if strings.EqualFold(email, r.Email) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			tos = append(tos, r.Email)
+		}
+	}
+	return tos, nil
+}
+
+// notificationUnsubscribeLink returns the link for the notification recipient
+// to unsubscribe from notifications of the repository.
+func notificationUnsubscribeLink(repoID int64, email string) string {
+	return conf.Server.ExternalURL + "notifications/unsubscribe?" + url.Values{
+		"repo_id": {strconv.FormatInt(repoID, 10)},
+		"email":   {email},
+		"token":   {repoutil.NewUnsubscribeToken(repoID, email)},
+	}.Encode()
+}
+
 // mailIssueCommentToParticipants can be used for both new issue creation and comment.
-// This functions sends two list of emails:
+// This functions sends three lists of emails:
 // 1. Repository watchers, users who participated in comments and the assignee.
-// 2. Users who are not in 1. but get mentioned in current issue/comment.
-func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string) error {
+// 2. Notification recipients of the repository who are not in 1.
+// 3. Users who are not in 1. but get mentioned in current issue/comment.
+func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string, isComment bool) error {
 	ctx := context.TODO()
 
 	if !conf.User.EnableEmailNotification {
@@ -115,6 +155,16 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string)
 		return fmt.Errorf("GetParticipantsByIssueID [issue_id: %d]: %v", issue.ID, err)
 	}
 
+	// Users who commented on the issue are only notified when they watch issues
+	// they comment on automatically and are not ignoring the repository.
+	commenters := participants[:0]
+	for _, p := range participants {
+		if p.IsAutoWatchOnComment() && !Repos.IsIgnoring(ctx, p.ID, issue.RepoID) {
+			commenters = append(commenters, p)
+		}
+	}
+	participants = commenters
+
 	// In case the issue poster is not watching the repository,
 	// even if we have duplicated in watchers, can be safely filtered out.
 	if issue.PosterID != doer.ID {
@@ -157,6 +207,14 @@ func mailIssueCommentToParticipants(issue *Issue, doer *User, mentions []string)
 	}
 	email.SendIssueCommentMail(NewMailerIssue(issue), NewMailerRepo(issue.Repo), NewMailerUser(doer), tos)
 
+	listTos, err := notificationListRecipients(ctx, issue, isComment, append(tos, doer.Email))
+	if err != nil {
+		return errors.Wrap(err, "list notification recipients")
+	}
+	for _, to := range listTos {
+		email.SendIssueListMail(NewMailerIssue(issue), NewMailerRepo(issue.Repo), NewMailerUser(doer), to, notificationUnsubscribeLink(issue.RepoID, to))
+	}
+
 	// Mail mentioned people and exclude watchers.
 	names = append(names, doer.Name)
 	toUsernames := make([]string, 0, len(mentions)) // list of user names.
@@ -184,7 +242,7 @@ func (issue *Issue) MailParticipants() (err error) {
 		return fmt.Errorf("UpdateIssueMentions [%d]: %v", issue.ID, err)
 	}
 
-	if err = mailIssueCommentToParticipants(issue, issue.Poster, mentions); err != nil {
+	if err = mailIssueCommentToParticipants(issue, issue.Poster, mentions, false); err != nil {
 		log.Error("mailIssueCommentToParticipants: %v", err)
 	}
 
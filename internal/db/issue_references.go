@@ -0,0 +1,91 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/markup"
+	"gogs.io/gogs/internal/repoutil"
+)
+
+// resolveRepoByName returns the repository with given owner and name when the
+// doer has the desired access mode to it, or ErrRepoNotExist otherwise. The doer
+// is treated as an anonymous user when it is nil.
+func resolveRepoByName(ctx context.Context, db *gorm.DB, doer *User, owner, name string, desired AccessMode) (*Repository, error) {
+	u, err := NewUsersStore(db).GetByUsername(ctx, owner)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return nil, ErrRepoNotExist{args: errutil.Args{"ownerName": owner, "name": name}}
+		}
+		return nil, errors.Wrap(err, "get owner by name")
+	}
+
+	repo, err := NewReposStore(db).GetByName(ctx, u.ID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var doerID int64
+	if doer != nil {
+		doerID = doer.ID
+	}
+	if !NewPermsStore(db).Authorize(ctx, doerID, repo.ID, desired,
+		AccessModeOptions{
+			OwnerID: repo.OwnerID,
+			Private: repo.IsPrivate,
+		},
+	) {
+		return nil, ErrRepoNotExist{args: errutil.Args{"ownerName": owner, "name": name}}
+	}
+	return repo, nil
+}
+
+// resolveIssueReference resolves the repository and the index of the issue that
+// the reference in forms of "#123", "repo#123" and "owner/repo#123" points to,
+// relative to the given owner and repository. Keys of issues of the given
+// repository, e.g. "PROJ-123", are also resolved when it has a prefix of issue
+// keys. It returns ErrRepoNotExist when
+// the repository does not exist or the doer does not have the desired access
+// mode to it.
+func resolveIssueReference(ctx context.Context, db *gorm.DB, doer *User, ownerName string, repo *Repository, ref string, desired AccessMode) (*Repository, int64, error) {
+	if index, ok := markup.ParseIssueKey(ref, repo.IssueKeyPrefix); ok {
+		return repo, index, nil
+	}
+
+	owner, name, index, ok := markup.ParseIssueReference(ref, ownerName, repo.Name)
+	if !ok {
+		return nil, 0, ErrRepoNotExist{args: errutil.Args{"ref": ref}}
+	}
+
+	// The pusher is always allowed to reference issues in the same repository.
+	if strings.EqualFold(owner, ownerName) && strings.EqualFold(name, repo.Name) {
+		return repo, index, nil
+	}
+
+	target, err := resolveRepoByName(ctx, db, doer, owner, name, desired)
+	if err != nil {
+		return nil, 0, err
+	}
+	return target, index, nil
+}
+
+// newRepoLinkResolver returns a markup.ResolveRepoLink that only resolves
+// repositories readable by anonymous users, because the rendered content may be
+// seen by anyone who can read the repository it belongs to.
+func newRepoLinkResolver(db *gorm.DB) func(owner, name string) (string, bool) {
+	return func(owner, name string) (string, bool) {
+		repo, err := resolveRepoByName(context.Background(), db, nil, owner, name, AccessModeRead)
+		if err != nil {
+			return "", false
+		}
+		return repoutil.HTMLURL(owner, repo.Name), true
+	}
+}
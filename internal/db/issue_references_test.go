@@ -0,0 +1,97 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestResolveIssueReference(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	db := dbtest.NewDB(t, "resolveIssueReference", new(User), new(EmailAddress), new(Repository), new(Access), new(Watch))
+
+	alice, err := NewUsersStore(db).Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := NewUsersStore(db).Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	repos := NewReposStore(db)
+	aliceRepo, err := repos.Create(ctx, alice.ID, CreateRepoOptions{Name: "example"})
+	require.NoError(t, err)
+	aliceDocs, err := repos.Create(ctx, alice.ID, CreateRepoOptions{Name: "docs"})
+	require.NoError(t, err)
+	alicePrivate, err := repos.Create(ctx, alice.ID, CreateRepoOptions{Name: "private", Private: true})
+	require.NoError(t, err)
+	bobRepo, err := repos.Create(ctx, bob.ID, CreateRepoOptions{Name: "tools"})
+	require.NoError(t, err)
+	bobPrivate, err := repos.Create(ctx, bob.ID, CreateRepoOptions{Name: "secret", Private: true})
+	require.NoError(t, err)
+
+	err = NewPermsStore(db).SetRepoPerms(ctx, bobPrivate.ID, map[int64]AccessMode{alice.ID: AccessModeRead})
+	require.NoError(t, err)
+	aliceRepo.IssueKeyPrefix = "PROJ"
+
+	tests := []struct {
+		name      string
+		ref       string
+		desired   AccessMode
+		wantRepo  int64
+		wantIndex int64
+		wantErr   bool
+	}{
+		{name: "same repository", ref: "#1", desired: AccessModeWrite, wantRepo: aliceRepo.ID, wantIndex: 1},
+		{name: "same owner", ref: "docs#2", desired: AccessModeWrite, wantRepo: aliceDocs.ID, wantIndex: 2},
+		{name: "same owner with owner", ref: "alice/docs#3", desired: AccessModeWrite, wantRepo: aliceDocs.ID, wantIndex: 3},
+		{name: "same owner private", ref: "private#4", desired: AccessModeWrite, wantRepo: alicePrivate.ID, wantIndex: 4},
+		{name: "cross owner", ref: "bob/tools#5", desired: AccessModeRead, wantRepo: bobRepo.ID, wantIndex: 5},
+		{name: "cross owner private with access", ref: "bob/secret#6", desired: AccessModeRead, wantRepo: bobPrivate.ID, wantIndex: 6},
+		{name: "issue key", ref: "PROJ-123", desired: AccessModeWrite, wantRepo: aliceRepo.ID, wantIndex: 123},
+
+		{name: "cross owner without write access", ref: "bob/tools#5", desired: AccessModeWrite, wantErr: true},
+		{name: "cross owner private without write access", ref: "bob/secret#6", desired: AccessModeWrite, wantErr: true},
+		{name: "repository not exist", ref: "tools#7", desired: AccessModeRead, wantErr: true},
+		{name: "owner not exist", ref: "cindy/tools#8", desired: AccessModeRead, wantErr: true},
+		{name: "invalid index", ref: "docs#0", desired: AccessModeRead, wantErr: true},
+		{name: "issue key of another prefix", ref: "OTHER-1", desired: AccessModeRead, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo, index, err := resolveIssueReference(ctx, db, alice, alice.Name, aliceRepo, test.ref, test.desired)
+			if test.wantErr {
+				assert.True(t, IsErrRepoNotExist(err), "want ErrRepoNotExist but got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.wantRepo, repo.ID)
+			assert.Equal(t, test.wantIndex, index)
+		})
+	}
+
+	t.Run("repository link", func(t *testing.T) {
+		resolve := newRepoLinkResolver(db)
+
+		link, ok := resolve("bob", "tools")
+		assert.True(t, ok)
+		assert.Equal(t, conf.Server.ExternalURL+"bob/tools", link)
+
+		// Private repositories are not visible to everyone
+		_, ok = resolve("bob", "secret")
+		assert.False(t, ok)
+		_, ok = resolve("bob", "404")
+		assert.False(t, ok)
+	})
+}
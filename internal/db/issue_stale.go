@@ -0,0 +1,332 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	log "unknwon.dev/clog/v2"
+	"xorm.io/xorm"
+)
+
+// StaleIssuesConfigCandidates are the paths of the file in the default branch of
+// the repository that defines the stale policy of issues, e.g.
+//
+//	daysUntilStale: 60
+//	daysUntilClose: 7
+//	staleLabel: stale
+//	exemptLabels:
+//	  - pinned
+//	  - security
+var StaleIssuesConfigCandidates = []string{
+	".gogs/stale.yml",
+	".github/stale.yml",
+}
+
+// StaleIssuesPolicy is the policy of marking inactive issues as stale and
+// closing them.
+type StaleIssuesPolicy struct {
+	// The number of days of inactivity before an issue is marked as stale.
+	DaysUntilStale int `yaml:"daysUntilStale"`
+	// The number of days of inactivity after an issue is marked as stale before
+	// it is closed, 0 to never close.
+	DaysUntilClose int `yaml:"daysUntilClose"`
+	// The label to apply when an issue is marked as stale, it is created if not
+	// exists in the repository.
+	StaleLabel string `yaml:"staleLabel"`
+	// Issues with any of these labels are never marked as stale.
+	ExemptLabels []string `yaml:"exemptLabels"`
+	// The comment to post when an issue is marked as stale, empty to not post.
+	MarkComment string `yaml:"markComment"`
+	// The comment to post when a stale issue is closed, empty to not post.
+	CloseComment string `yaml:"closeComment"`
+}
+
+// parseStaleIssuesPolicy parses the stale policy, absent fields are filled with
+// default values.
+func parseStaleIssuesPolicy(data []byte) (*StaleIssuesPolicy, error) {
+	policy := &StaleIssuesPolicy{
+		DaysUntilStale: 60,
+		DaysUntilClose: 7,
+		StaleLabel:     "stale",
+		MarkComment: "This issue has been automatically marked as stale because it has not had recent activity. " +
+			"It will be closed if no further activity occurs.",
+	}
+	err := yaml.Unmarshal(data, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.DaysUntilStale <= 0 {
+		return nil, errors.Errorf("daysUntilStale must be positive but got %d", policy.DaysUntilStale)
+	} else if policy.DaysUntilClose < 0 {
+		return nil, errors.Errorf("daysUntilClose must not be negative but got %d", policy.DaysUntilClose)
+	} else if policy.StaleLabel == "" {
+		return nil, errors.New("staleLabel must not be empty")
+	}
+	return policy, nil
+}
+
+// getStaleIssuesPolicy returns the stale policy in the default branch of the
+// repository, or nil if the repository does not have one.
+func getStaleIssuesPolicy(repo *Repository) (*StaleIssuesPolicy, error) {
+	gitRepo, err := git.Open(repo.RepoPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "open repository")
+	}
+	commit, err := gitRepo.BranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return nil, errors.Wrap(err, "get default branch commit")
+	}
+
+	for _, candidate := range StaleIssuesConfigCandidates {
+		blob, err := commit.Blob(candidate)
+		if err != nil {
+			continue
+		}
+		p, err := blob.Bytes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "read %q", candidate)
+		}
+		policy, err := parseStaleIssuesPolicy(p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse %q", candidate)
+		}
+		return policy, nil
+	}
+	return nil, nil
+}
+
+// hasAnyLabel returns true if the issue has any of the labels.
+func (issue *Issue) hasAnyLabel(e Engine, labelIDs []int64) bool {
+	for _, labelID := range labelIDs {
+		if hasIssueLabel(e, issue.ID, labelID) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyStaleIssuesPolicy marks open issues of the repository that have been
+// inactive for the configured days as stale, and closes stale issues that have
+// been inactive for the further configured days, on behalf of the doer. Issues
+// with any of the exempt labels are skipped.
+func applyStaleIssuesPolicy(doer *User, repo *Repository, policy *StaleIssuesPolicy, now time.Time) (marked, closed int, err error) {
+	staleLabel, err := getLabelOfRepoByName(x, repo.ID, policy.StaleLabel)
+	if err != nil {
+		if !IsErrLabelNotExist(err) {
+			return 0, 0, errors.Wrap(err, "get stale label")
+		}
+
+		staleLabel = &Label{
+			RepoID: repo.ID,
+			Name:   policy.StaleLabel,
+			Color:  "#ededed",
+		}
+		if _, err = x.Insert(staleLabel); err != nil {
+			return 0, 0, errors.Wrap(err, "create stale label")
+		}
+	}
+
+	var exemptLabelIDs []int64
+	for _, name := range policy.ExemptLabels {
+		label, err := getLabelOfRepoByName(x, repo.ID, name)
+		if err != nil {
+			if IsErrLabelNotExist(err) {
+				continue
+			}
+			return 0, 0, errors.Wrapf(err, "get exempt label %q", name)
+		}
+		exemptLabelIDs = append(exemptLabelIDs, label.ID)
+	}
+
+	var issues []*Issue
+	err = x.Where("repo_id = ? AND is_pull = ? AND is_closed = ? AND stale_label_id = 0", repo.ID, false, false).
+		And("updated_unix < ?", now.AddDate(0, 0, -policy.DaysUntilStale).Unix()).
+		Find(&issues)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "list inactive issues")
+	}
+	for _, issue := range issues {
+		if issue.hasAnyLabel(x, exemptLabelIDs) {
+			continue
+		}
+
+		issue.Repo = repo
+		if err = issue.markStale(doer, staleLabel, policy.MarkComment); err != nil {
+			return marked, closed, errors.Wrapf(err, "mark issue %d as stale", issue.ID)
+		}
+		marked++
+	}
+
+	if policy.DaysUntilClose == 0 {
+		return marked, closed, nil
+	}
+
+	issues = issues[:0]
+	err = x.Where("repo_id = ? AND is_pull = ? AND is_closed = ? AND stale_label_id > 0", repo.ID, false, false).
+		And("updated_unix < ?", now.AddDate(0, 0, -policy.DaysUntilClose).Unix()).
+		Find(&issues)
+	if err != nil {
+		return marked, closed, errors.Wrap(err, "list stale issues")
+	}
+	for _, issue := range issues {
+		if issue.hasAnyLabel(x, exemptLabelIDs) {
+			continue
+		}
+
+		issue.Repo = repo
+		if issue.Poster, err = getUserByID(x, issue.PosterID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return marked, closed, errors.Wrapf(err, "get poster of issue %d", issue.ID)
+			}
+			issue.Poster = NewGhostUser()
+		}
+
+		if policy.CloseComment != "" {
+			_, err = CreateComment(&CreateCommentOptions{
+				Type:    COMMENT_TYPE_COMMENT,
+				Doer:    doer,
+				Repo:    repo,
+				Issue:   issue,
+				Content: policy.CloseComment,
+			})
+			if err != nil {
+				return marked, closed, errors.Wrapf(err, "create close comment of issue %d", issue.ID)
+			}
+		}
+		if err = issue.ChangeStatus(doer, repo, true); err != nil {
+			return marked, closed, errors.Wrapf(err, "close issue %d", issue.ID)
+		}
+		closed++
+	}
+	return marked, closed, nil
+}
+
+// markStale applies the stale label to the issue and posts the comment if it is
+// not empty.
+func (issue *Issue) markStale(doer *User, staleLabel *Label, comment string) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if comment != "" {
+		_, err = createComment(sess, &CreateCommentOptions{
+			Type:    COMMENT_TYPE_COMMENT,
+			Doer:    doer,
+			Repo:    issue.Repo,
+			Issue:   issue,
+			Content: comment,
+		})
+		if err != nil {
+			return errors.Wrap(err, "create comment")
+		}
+	}
+
+	if !hasIssueLabel(sess, issue.ID, staleLabel.ID) {
+		if err = issue.getLabels(sess); err != nil {
+			return errors.Wrap(err, "get labels")
+		} else if err = newIssueLabel(sess, issue, staleLabel); err != nil {
+			return errors.Wrap(err, "add stale label")
+		}
+	}
+
+	issue.StaleLabelID = staleLabel.ID
+	if err = updateIssueCols(sess, issue, "stale_label_id"); err != nil {
+		return errors.Wrap(err, "update issue")
+	}
+	return sess.Commit()
+}
+
+// unmarkStale removes the stale label from the issue and makes it no longer
+// stale. It is a no-op if the issue is not stale.
+func (issue *Issue) unmarkStale(e *xorm.Session) (err error) {
+	if issue.StaleLabelID == 0 {
+		return nil
+	}
+
+	if err = issue.getLabels(e); err != nil {
+		return errors.Wrap(err, "get labels")
+	}
+	for _, label := range issue.Labels {
+		if label.ID == issue.StaleLabelID {
+			if err = deleteIssueLabel(e, issue, label); err != nil {
+				return errors.Wrap(err, "delete stale label")
+			}
+			break
+		}
+	}
+
+	issue.StaleLabelID = 0
+	return updateIssueCols(e, issue, "stale_label_id")
+}
+
+// UnmarkStale removes the stale label from the issue because of new activity.
+func (issue *Issue) UnmarkStale() (err error) {
+	if issue.StaleLabelID == 0 {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = issue.unmarkStale(sess); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// ProcessStaleIssues applies the stale policy of each repository that has open
+// issues and a policy in its default branch.
+func ProcessStaleIssues() {
+	if taskStatusTable.IsRunning(_PROCESS_STALE_ISSUES) {
+		return
+	}
+	taskStatusTable.Start(_PROCESS_STALE_ISSUES)
+	defer taskStatusTable.Stop(_PROCESS_STALE_ISSUES)
+
+	log.Trace("Doing: ProcessStaleIssues")
+
+	// NOTE: Collect repositories first because the policy is applied with writes
+	// that can't be done while iterating on some databases.
+	var repos []*Repository
+	err := x.Where("num_issues > num_closed_issues AND is_bare = ?", false).Find(&repos)
+	if err != nil {
+		log.Error("Failed to list repositories with open issues: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, repo := range repos {
+		if err = repo.GetOwner(); err != nil {
+			log.Error("Failed to get owner of repository [%d]: %v", repo.ID, err)
+			continue
+		}
+
+		policy, err := getStaleIssuesPolicy(repo)
+		if err != nil {
+			log.Error("Failed to get stale policy of repository %q: %v", repo.FullName(), err)
+			continue
+		} else if policy == nil {
+			continue
+		}
+
+		marked, closed, err := applyStaleIssuesPolicy(repo.Owner, repo, policy, now)
+		if err != nil {
+			log.Error("Failed to apply stale policy of repository %q: %v", repo.FullName(), err)
+		}
+		if marked > 0 || closed > 0 {
+			log.Trace("ProcessStaleIssues[%s]: %d marked, %d closed", repo.FullName(), marked, closed)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStaleIssuesPolicy(t *testing.T) {
+	policy, err := parseStaleIssuesPolicy([]byte(`
+daysUntilStale: 30
+exemptLabels:
+  - pinned
+closeComment: Closed due to inactivity.
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 30, policy.DaysUntilStale)
+	assert.Equal(t, 7, policy.DaysUntilClose)
+	assert.Equal(t, "stale", policy.StaleLabel)
+	assert.Equal(t, []string{"pinned"}, policy.ExemptLabels)
+	assert.NotEmpty(t, policy.MarkComment)
+	assert.Equal(t, "Closed due to inactivity.", policy.CloseComment)
+
+	_, err = parseStaleIssuesPolicy([]byte(`daysUntilStale: 0`))
+	assert.Error(t, err)
+
+	_, err = parseStaleIssuesPolicy([]byte(`staleLabel: ""`))
+	assert.Error(t, err)
+}
+
+func TestApplyStaleIssuesPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone),
+		new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	pinned := &Label{RepoID: repo.ID, Name: "pinned", Color: "#0000ff"}
+	_, err = x.Insert(pinned)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		repo, err := GetRepositoryByID(repo.ID)
+		require.NoError(t, err)
+
+		issue := &Issue{RepoID: repo.ID, PosterID: owner.ID, Title: "example"}
+		sess := x.NewSession()
+		require.NoError(t, sess.Begin())
+		err = newIssue(sess, NewIssueOptions{Repo: repo, Issue: issue})
+		require.NoError(t, err)
+		require.NoError(t, sess.Commit())
+		sess.Close()
+	}
+	getIssue := func(t *testing.T, index int64) *Issue {
+		issue, err := GetRawIssueByIndex(repo.ID, index)
+		require.NoError(t, err)
+		issue.Repo = repo
+		issue.Poster = owner
+		return issue
+	}
+	// Pretend all open issues have been inactive for the given days
+	setInactiveDays := func(t *testing.T, days int) {
+		_, err := x.Exec("UPDATE `issue` SET updated_unix = ? WHERE is_closed = ?", time.Now().AddDate(0, 0, -days).Unix(), false)
+		require.NoError(t, err)
+	}
+	getLabelNames := func(t *testing.T, index int64) []string {
+		labels, err := GetLabelsByIssueID(getIssue(t, index).ID)
+		require.NoError(t, err)
+		names := make([]string, len(labels))
+		for i := range labels {
+			names[i] = labels[i].Name
+		}
+		return names
+	}
+
+	// Issue #3 is exempt from the policy
+	err = NewIssueLabel(getIssue(t, 3), pinned)
+	require.NoError(t, err)
+
+	policy := &StaleIssuesPolicy{
+		DaysUntilStale: 30,
+		DaysUntilClose: 7,
+		StaleLabel:     "stale",
+		ExemptLabels:   []string{"pinned"},
+		MarkComment:    "Marked as stale.",
+		CloseComment:   "Closed as stale.",
+	}
+	// Nothing happens before the issues are inactive for long enough
+	setInactiveDays(t, 29)
+	marked, closed, err := applyStaleIssuesPolicy(owner, repo, policy, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+	assert.Equal(t, 0, closed)
+
+	// Inactive issues get marked as stale with a comment
+	setInactiveDays(t, 31)
+	marked, closed, err = applyStaleIssuesPolicy(owner, repo, policy, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, marked)
+	assert.Equal(t, 0, closed)
+	assert.NotZero(t, getIssue(t, 1).StaleLabelID)
+	assert.Equal(t, []string{"stale"}, getLabelNames(t, 1))
+	assert.Zero(t, getIssue(t, 3).StaleLabelID)
+	assert.Equal(t, []string{"pinned"}, getLabelNames(t, 3))
+
+	var comments []*Comment
+	err = x.Where("issue_id = ?", getIssue(t, 1).ID).Find(&comments)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, "Marked as stale.", comments[0].Content)
+
+	// Commenting makes the issue no longer stale
+	_, err = CreateIssueComment(owner, repo, getIssue(t, 2), "Still relevant", nil)
+	require.NoError(t, err)
+	assert.Zero(t, getIssue(t, 2).StaleLabelID)
+	assert.Empty(t, getLabelNames(t, 2))
+
+	// Stale issues get closed after further inactivity
+	setInactiveDays(t, 8)
+	marked, closed, err = applyStaleIssuesPolicy(owner, repo, policy, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 0, marked)
+	assert.Equal(t, 1, closed)
+	issue := getIssue(t, 1)
+	assert.True(t, issue.IsClosed)
+	assert.Equal(t, []string{"stale"}, getLabelNames(t, 1))
+	assert.False(t, getIssue(t, 2).IsClosed)
+	assert.False(t, getIssue(t, 3).IsClosed)
+
+	comments = nil
+	err = x.Where("issue_id = ?", issue.ID).Asc("id").Find(&comments)
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	assert.Equal(t, "Closed as stale.", comments[1].Content)
+	assert.Equal(t, COMMENT_TYPE_CLOSE, comments[2].Type)
+
+	// Reopening makes the issue no longer stale
+	err = issue.ChangeStatus(owner, repo, false)
+	require.NoError(t, err)
+	assert.Zero(t, getIssue(t, 1).StaleLabelID)
+	assert.Empty(t, getLabelNames(t, 1))
+
+	label, err := GetLabelOfRepoByName(repo.ID, "stale")
+	require.NoError(t, err)
+	assert.Equal(t, 0, label.NumIssues)
+	assert.Equal(t, 0, label.NumClosedIssues)
+}
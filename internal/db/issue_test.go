@@ -0,0 +1,144 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewImportedIssue(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment),
+	)
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	reloadRepo := func(t *testing.T) *Repository {
+		repo, err := GetRepositoryByID(repo.ID)
+		require.NoError(t, err)
+		return repo
+	}
+	newImportedIssue := func(index int64) error {
+		return NewImportedIssue(reloadRepo(t), &Issue{RepoID: repo.ID, PosterID: owner.ID, Title: "imported"}, index, nil)
+	}
+
+	// Imported in arbitrary order with gaps
+	for _, index := range []int64{5, 2, 7} {
+		err = newImportedIssue(index)
+		require.NoError(t, err)
+
+		issue, err := GetRawIssueByIndex(repo.ID, index)
+		require.NoError(t, err)
+		assert.Equal(t, index, issue.Index)
+	}
+
+	// The counter continues from the highest imported index
+	assert.Equal(t, int64(8), reloadRepo(t).NextIssueIndex())
+
+	// Collisions are rejected
+	err = newImportedIssue(5)
+	wantErr := ErrIssueIndexAlreadyExist{args: map[string]any{"repoID": repo.ID, "index": int64(5)}}
+	assert.Equal(t, wantErr, err)
+
+	// Filling a gap does not move the counter
+	err = newImportedIssue(3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), reloadRepo(t).NextIssueIndex())
+
+	// The counter can be seeded higher but not to an existing index
+	err = SetNextIssueIndex(repo.ID, 7)
+	wantErr = ErrIssueIndexAlreadyExist{args: map[string]any{"repoID": repo.ID, "index": int64(7)}}
+	assert.Equal(t, wantErr, err)
+
+	err = SetNextIssueIndex(repo.ID, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), reloadRepo(t).NextIssueIndex())
+}
+
+func TestIssue_changeStatus_repoCounters(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone),
+		new(PullRequest),
+	)
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	reloadRepo := func(t *testing.T) *Repository {
+		repo, err := GetRepositoryByID(repo.ID)
+		require.NoError(t, err)
+		return repo
+	}
+	for _, isPull := range []bool{false, false, true} {
+		issue := &Issue{RepoID: repo.ID, PosterID: owner.ID, Title: "example", IsPull: isPull}
+		sess := x.NewSession()
+		require.NoError(t, sess.Begin())
+		err = newIssue(sess, NewIssueOptions{Repo: reloadRepo(t), Issue: issue, IsPull: isPull})
+		require.NoError(t, err)
+		require.NoError(t, sess.Commit())
+		sess.Close()
+
+		if isPull {
+			_, err = x.Insert(&PullRequest{IssueID: issue.ID, Index: issue.Index, BaseRepoID: repo.ID})
+			require.NoError(t, err)
+		}
+	}
+	changeStatus := func(t *testing.T, index int64, isClosed bool) {
+		issue, err := GetRawIssueByIndex(repo.ID, index)
+		require.NoError(t, err)
+
+		sess := x.NewSession()
+		defer sess.Close()
+		require.NoError(t, sess.Begin())
+		err = issue.changeStatus(sess, owner, reloadRepo(t), isClosed)
+		require.NoError(t, err)
+		require.NoError(t, sess.Commit())
+	}
+	assertCounters := func(t *testing.T, numIssues, numClosedIssues, numPulls, numClosedPulls int) {
+		t.Helper()
+		got := reloadRepo(t)
+		assert.Equal(t, numIssues, got.NumIssues)
+		assert.Equal(t, numClosedIssues, got.NumClosedIssues)
+		assert.Equal(t, numIssues-numClosedIssues, got.NumOpenIssues)
+		assert.Equal(t, numPulls, got.NumPulls)
+		assert.Equal(t, numClosedPulls, got.NumClosedPulls)
+		assert.Equal(t, numPulls-numClosedPulls, got.NumOpenPulls)
+	}
+	assertCounters(t, 2, 0, 1, 0)
+
+	changeStatus(t, 1, true)
+	changeStatus(t, 3, true)
+	assertCounters(t, 2, 1, 1, 1)
+
+	// Changing to the same status is a no-op
+	changeStatus(t, 1, true)
+	assertCounters(t, 2, 1, 1, 1)
+
+	changeStatus(t, 1, false)
+	changeStatus(t, 3, false)
+	assertCounters(t, 2, 0, 1, 0)
+}
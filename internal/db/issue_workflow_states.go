@@ -0,0 +1,227 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// IssueWorkflowStatesStore is the persistent interface for custom workflow
+// states of issues, e.g. "in progress" or "blocked". Each state maps to either
+// open or closed, which remains the canonical status of issues.
+type IssueWorkflowStatesStore interface {
+	// Create creates a new workflow state of the repository with given options.
+	// It returns ErrIssueWorkflowStateAlreadyExist when a state with the same
+	// name already exists in the repository.
+	Create(ctx context.Context, repoID int64, opts CreateIssueWorkflowStateOptions) (*IssueWorkflowState, error)
+	// GetByID returns the workflow state with given ID of the repository. It
+	// returns ErrIssueWorkflowStateNotExist when not found.
+	GetByID(ctx context.Context, repoID, id int64) (*IssueWorkflowState, error)
+	// List returns all workflow states of the repository, sorted by their
+	// creation.
+	List(ctx context.Context, repoID int64) ([]*IssueWorkflowState, error)
+	// DeleteByID deletes the workflow state with given ID of the repository, and
+	// unsets it from all issues. Issues are left open or closed as they are. It
+	// returns ErrIssueWorkflowStateNotExist when not found.
+	DeleteByID(ctx context.Context, repoID, id int64) error
+}
+
+var IssueWorkflowStates IssueWorkflowStatesStore
+
+var _ IssueWorkflowStatesStore = (*issueWorkflowStates)(nil)
+
+type issueWorkflowStates struct {
+	*gorm.DB
+}
+
+// NewIssueWorkflowStatesStore returns a persistent interface for custom
+// workflow states of issues with given database connection.
+func NewIssueWorkflowStatesStore(db *gorm.DB) IssueWorkflowStatesStore {
+	return &issueWorkflowStates{DB: db}
+}
+
+// IssueWorkflowState is a custom workflow state of issues in a repository.
+type IssueWorkflowState struct {
+	ID     int64  `gorm:"primaryKey"`
+	RepoID int64  `gorm:"uniqueIndex:issue_workflow_state_repo_name_unique;not null"`
+	Name   string `gorm:"uniqueIndex:issue_workflow_state_repo_name_unique;not null"`
+	Color  string `gorm:"type:VARCHAR(7);not null"`
+	// IsClosed indicates whether issues in the state are closed, otherwise open.
+	IsClosed bool `gorm:"not null"`
+}
+
+// ForegroundColor calculates the text color for the state based on its
+// background color.
+func (s *IssueWorkflowState) ForegroundColor() template.CSS {
+	return (&Label{Color: s.Color}).ForegroundColor()
+}
+
+type CreateIssueWorkflowStateOptions struct {
+	Name     string
+	Color    string
+	IsClosed bool
+}
+
+type ErrIssueWorkflowStateAlreadyExist struct {
+	args errutil.Args
+}
+
+// IsErrIssueWorkflowStateAlreadyExist returns true if the underlying error has
+// the type ErrIssueWorkflowStateAlreadyExist.
+func IsErrIssueWorkflowStateAlreadyExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrIssueWorkflowStateAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueWorkflowStateAlreadyExist) Error() string {
+	return fmt.Sprintf("issue workflow state already exists: %v", err.args)
+}
+
+func (db *issueWorkflowStates) Create(ctx context.Context, repoID int64, opts CreateIssueWorkflowStateOptions) (*IssueWorkflowState, error) {
+	err := db.WithContext(ctx).Where("repo_id = ? AND name = ?", repoID, opts.Name).First(new(IssueWorkflowState)).Error
+	if err == nil {
+		return nil, ErrIssueWorkflowStateAlreadyExist{args: errutil.Args{"repoID": repoID, "name": opts.Name}}
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	state := &IssueWorkflowState{
+		RepoID:   repoID,
+		Name:     opts.Name,
+		Color:    opts.Color,
+		IsClosed: opts.IsClosed,
+	}
+	return state, db.WithContext(ctx).Create(state).Error
+}
+
+var _ errutil.NotFound = (*ErrIssueWorkflowStateNotExist)(nil)
+
+type ErrIssueWorkflowStateNotExist struct {
+	args errutil.Args
+}
+
+// IsErrIssueWorkflowStateNotExist returns true if the underlying error has the
+// type ErrIssueWorkflowStateNotExist.
+func IsErrIssueWorkflowStateNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrIssueWorkflowStateNotExist)
+	return ok
+}
+
+func (err ErrIssueWorkflowStateNotExist) Error() string {
+	return fmt.Sprintf("issue workflow state does not exist: %v", err.args)
+}
+
+func (ErrIssueWorkflowStateNotExist) NotFound() bool {
+	return true
+}
+
+func (db *issueWorkflowStates) GetByID(ctx context.Context, repoID, id int64) (*IssueWorkflowState, error) {
+	state := new(IssueWorkflowState)
+	err := db.WithContext(ctx).Where("id = ? AND repo_id = ?", id, repoID).First(state).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrIssueWorkflowStateNotExist{args: errutil.Args{"repoID": repoID, "id": id}}
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+func (db *issueWorkflowStates) List(ctx context.Context, repoID int64) ([]*IssueWorkflowState, error) {
+	var states []*IssueWorkflowState
+	return states, db.WithContext(ctx).
+		Where("repo_id = ?", repoID).
+		Order("id ASC").
+		Find(&states).
+		Error
+}
+
+func (db *issueWorkflowStates) DeleteByID(ctx context.Context, repoID, id int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND repo_id = ?", id, repoID).Delete(new(IssueWorkflowState))
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return ErrIssueWorkflowStateNotExist{args: errutil.Args{"repoID": repoID, "id": id}}
+		}
+
+		return tx.Model(new(Issue)).
+			Where("repo_id = ? AND workflow_state_id = ?", repoID, id).
+			UpdateColumn("workflow_state_id", 0).
+			Error
+	})
+}
+
+// ChangeWorkflowState changes the workflow state of the issue and logs the
+// change to the timeline. The issue is closed or reopened when the status that
+// the state maps to differs from the current status. The workflow state is unset
+// when the state is nil.
+func (issue *Issue) ChangeWorkflowState(doer *User, repo *Repository, state *IssueWorkflowState) error {
+	var stateID int64
+	var stateName string
+	if state != nil {
+		stateID = state.ID
+		stateName = state.Name
+	}
+	if issue.WorkflowStateID == stateID {
+		return nil
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	issue.WorkflowStateID = stateID
+	if err := updateIssueCols(sess, issue, "workflow_state_id"); err != nil {
+		return errors.Wrap(err, "update issue")
+	}
+
+	_, err := createComment(sess, &CreateCommentOptions{
+		Type:    COMMENT_TYPE_WORKFLOW_STATE,
+		Doer:    doer,
+		Repo:    repo,
+		Issue:   issue,
+		Content: stateName,
+	})
+	if err != nil {
+		return errors.Wrap(err, "create comment")
+	}
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	if state == nil || state.IsClosed == issue.IsClosed {
+		return nil
+	}
+	return issue.ChangeStatus(doer, repo, state.IsClosed)
+}
+
+// unsetMismatchedWorkflowState unsets the workflow state of the issue when it
+// does not map to the current status of the issue, e.g. an issue "in progress" is
+// closed.
+func (issue *Issue) unsetMismatchedWorkflowState(e Engine) error {
+	if issue.WorkflowStateID == 0 {
+		return nil
+	}
+
+	matched, err := e.Where("id = ? AND is_closed = ?", issue.WorkflowStateID, issue.IsClosed).Exist(new(IssueWorkflowState))
+	if err != nil {
+		return err
+	} else if matched {
+		return nil
+	}
+
+	issue.WorkflowStateID = 0
+	return updateIssueCols(e, issue, "workflow_state_id")
+}
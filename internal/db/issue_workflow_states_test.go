@@ -0,0 +1,211 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestIssueWorkflowStates(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(IssueWorkflowState), new(Issue)}
+	db := &issueWorkflowStates{
+		DB: dbtest.NewDB(t, "issueWorkflowStates", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *issueWorkflowStates)
+	}{
+		{"Create", issueWorkflowStatesCreate},
+		{"List", issueWorkflowStatesList},
+		{"DeleteByID", issueWorkflowStatesDeleteByID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func issueWorkflowStatesCreate(t *testing.T, db *issueWorkflowStates) {
+	ctx := context.Background()
+
+	state, err := db.Create(ctx, 1, CreateIssueWorkflowStateOptions{Name: "In progress", Color: "#fbca04"})
+	require.NoError(t, err)
+
+	got, err := db.GetByID(ctx, 1, state.ID)
+	require.NoError(t, err)
+	assert.Equal(t, state, got)
+
+	// The same name is allowed in other repositories but not the same one
+	_, err = db.Create(ctx, 2, CreateIssueWorkflowStateOptions{Name: "In progress", Color: "#fbca04"})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 1, CreateIssueWorkflowStateOptions{Name: "In progress", Color: "#000000"})
+	wantErr := ErrIssueWorkflowStateAlreadyExist{args: errutil.Args{"repoID": int64(1), "name": "In progress"}}
+	assert.Equal(t, wantErr, err)
+
+	// States of other repositories are not accessible
+	_, err = db.GetByID(ctx, 3, state.ID)
+	assert.True(t, IsErrIssueWorkflowStateNotExist(err))
+}
+
+func issueWorkflowStatesList(t *testing.T, db *issueWorkflowStates) {
+	ctx := context.Background()
+
+	s1, err := db.Create(ctx, 1, CreateIssueWorkflowStateOptions{Name: "In progress", Color: "#fbca04"})
+	require.NoError(t, err)
+	s2, err := db.Create(ctx, 1, CreateIssueWorkflowStateOptions{Name: "Won't fix", Color: "#ffffff", IsClosed: true})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 2, CreateIssueWorkflowStateOptions{Name: "Blocked", Color: "#e11d21"})
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*IssueWorkflowState{s1, s2}, got)
+}
+
+func issueWorkflowStatesDeleteByID(t *testing.T, db *issueWorkflowStates) {
+	ctx := context.Background()
+
+	state, err := db.Create(ctx, 1, CreateIssueWorkflowStateOptions{Name: "In progress", Color: "#fbca04"})
+	require.NoError(t, err)
+	issue := &Issue{RepoID: 1, Index: 1, WorkflowStateID: state.ID}
+	err = db.DB.Create(issue).Error
+	require.NoError(t, err)
+
+	// Deleting a state of another repository is rejected
+	err = db.DeleteByID(ctx, 2, state.ID)
+	wantErr := ErrIssueWorkflowStateNotExist{args: errutil.Args{"repoID": int64(2), "id": state.ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 1, state.ID)
+	require.NoError(t, err)
+
+	_, err = db.GetByID(ctx, 1, state.ID)
+	assert.True(t, IsErrIssueWorkflowStateNotExist(err))
+
+	// The state is unset from issues
+	got := new(Issue)
+	err = db.First(got, issue.ID).Error
+	require.NoError(t, err)
+	assert.Zero(t, got.WorkflowStateID)
+}
+
+func TestIssue_ChangeWorkflowState(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone),
+		new(IssueWorkflowState), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	inProgress := &IssueWorkflowState{RepoID: repo.ID, Name: "In progress", Color: "#fbca04"}
+	wontFix := &IssueWorkflowState{RepoID: repo.ID, Name: "Won't fix", Color: "#ffffff", IsClosed: true}
+	_, err = x.Insert(inProgress, wontFix)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		repo, err := GetRepositoryByID(repo.ID)
+		require.NoError(t, err)
+
+		issue := &Issue{RepoID: repo.ID, PosterID: owner.ID, Title: "example"}
+		sess := x.NewSession()
+		require.NoError(t, sess.Begin())
+		err = newIssue(sess, NewIssueOptions{Repo: repo, Issue: issue})
+		require.NoError(t, err)
+		require.NoError(t, sess.Commit())
+		sess.Close()
+	}
+	getIssue := func(t *testing.T, index int64) *Issue {
+		issue, err := GetRawIssueByIndex(repo.ID, index)
+		require.NoError(t, err)
+		issue.Poster = owner
+		return issue
+	}
+	changeWorkflowState := func(t *testing.T, index int64, state *IssueWorkflowState) {
+		err := getIssue(t, index).ChangeWorkflowState(owner, repo, state)
+		require.NoError(t, err)
+	}
+	listIndexes := func(t *testing.T, stateID int64, isClosed bool) []int64 {
+		var issues []*Issue
+		err := buildIssuesQuery(&IssuesOptions{RepoID: repo.ID, WorkflowStateID: stateID, IsClosed: isClosed, SortType: "oldest"}).Find(&issues)
+		require.NoError(t, err)
+		indexes := make([]int64, len(issues))
+		for i := range issues {
+			indexes[i] = issues[i].Index
+		}
+		return indexes
+	}
+	assertStats := func(t *testing.T, stateID int64, openCount, closedCount int64) {
+		t.Helper()
+		stats := GetIssueStats(&IssueStatsOptions{RepoID: repo.ID, WorkflowStateID: stateID, FilterMode: FILTER_MODE_YOUR_REPOS})
+		assert.Equal(t, openCount, stats.OpenCount)
+		assert.Equal(t, closedCount, stats.ClosedCount)
+	}
+
+	changeWorkflowState(t, 1, inProgress)
+	changeWorkflowState(t, 2, inProgress)
+	assert.Equal(t, []int64{1, 2}, listIndexes(t, inProgress.ID, false))
+	assert.Empty(t, listIndexes(t, wontFix.ID, false))
+	assertStats(t, inProgress.ID, 2, 0)
+	assertStats(t, 0, 3, 0)
+
+	// Moving to a state that maps to closed closes the issue
+	changeWorkflowState(t, 2, wontFix)
+	assert.True(t, getIssue(t, 2).IsClosed)
+	assert.Equal(t, []int64{2}, listIndexes(t, wontFix.ID, true))
+	assertStats(t, wontFix.ID, 0, 1)
+	assertStats(t, 0, 2, 1)
+
+	// Reopening the issue unsets the state that no longer matches
+	err = getIssue(t, 2).ChangeStatus(owner, repo, false)
+	require.NoError(t, err)
+	assert.Zero(t, getIssue(t, 2).WorkflowStateID)
+	assertStats(t, wontFix.ID, 0, 0)
+
+	// Unsetting the state keeps the status
+	changeWorkflowState(t, 1, nil)
+	issue := getIssue(t, 1)
+	assert.Zero(t, issue.WorkflowStateID)
+	assert.False(t, issue.IsClosed)
+
+	// Every change is logged to the timeline
+	var comments []*Comment
+	err = x.Where("issue_id = ?", issue.ID).Asc("id").Find(&comments)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, COMMENT_TYPE_WORKFLOW_STATE, comments[0].Type)
+	assert.Equal(t, "In progress", comments[0].Content)
+	assert.Equal(t, COMMENT_TYPE_WORKFLOW_STATE, comments[1].Type)
+	assert.Empty(t, comments[1].Content)
+}
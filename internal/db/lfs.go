@@ -7,8 +7,10 @@ package db
 import (
 	"context"
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/pkg/errors"
 	"gorm.io/gorm"
 
 	"gogs.io/gogs/internal/errutil"
@@ -25,6 +27,15 @@ type LFSStore interface {
 	// GetObjectsByOIDs returns LFS objects found within "oids". The returned list
 	// could have less elements if some oids were not found.
 	GetObjectsByOIDs(ctx context.Context, repoID int64, oids ...lfsutil.OID) ([]*LFSObject, error)
+	// ListObjectsByStorage returns LFS objects of all repositories that are
+	// stored in the given storage backend.
+	ListObjectsByStorage(ctx context.Context, storage lfsutil.Storage) ([]*LFSObject, error)
+	// TotalSizeByRepoID returns the total size of LFS objects stored for the
+	// repository.
+	TotalSizeByRepoID(ctx context.Context, repoID int64) (int64, error)
+	// UpdateObjectStorage updates the storage backend of LFS objects with given
+	// OID of all repositories from "from" to "to".
+	UpdateObjectStorage(ctx context.Context, oid lfsutil.OID, from, to lfsutil.Storage) error
 }
 
 var LFS LFSStore
@@ -95,3 +106,74 @@ func (db *lfs) GetObjectsByOIDs(ctx context.Context, repoID int64, oids ...lfsut
 	}
 	return objects, nil
 }
+
+func (db *lfs) TotalSizeByRepoID(ctx context.Context, repoID int64) (int64, error) {
+	var size int64
+	err := db.WithContext(ctx).Model(&LFSObject{}).
+		Select("COALESCE(SUM(size), 0)").
+		Where("repo_id = ?", repoID).
+		Scan(&size).Error
+	return size, err
+}
+
+func (db *lfs) ListObjectsByStorage(ctx context.Context, storage lfsutil.Storage) ([]*LFSObject, error) {
+	var objects []*LFSObject
+	return objects, db.WithContext(ctx).
+		Where("storage = ?", storage).
+		Order("oid ASC, repo_id ASC").
+		Find(&objects).
+		Error
+}
+
+func (db *lfs) UpdateObjectStorage(ctx context.Context, oid lfsutil.OID, from, to lfsutil.Storage) error {
+	return db.WithContext(ctx).
+		Model(&LFSObject{}).
+		Where("oid = ? AND storage = ?", oid, from).
+		Update("storage", to).
+		Error
+}
+
+// MigrateLFSObjects copies LFS objects that are stored in the "from" storage
+// backend to the "to" storage backend, and updates records of objects to be
+// served from the new backend. Objects are left in the old backend and it is up
+// to the caller to clean them up. It returns the number of migrated objects.
+func MigrateLFSObjects(ctx context.Context, from, to lfsutil.Storager) (int, error) {
+	if from.Storage() == to.Storage() {
+		return 0, errors.Errorf("source and destination are the same storage %q", from.Storage())
+	}
+
+	objects, err := LFS.ListObjectsByStorage(ctx, from.Storage())
+	if err != nil {
+		return 0, errors.Wrap(err, "list objects")
+	}
+
+	var migrated int
+	for i, object := range objects {
+		// NOTE: Objects with the same OID share the same content across
+		// repositories, thus only need to be copied once.
+		if i > 0 && objects[i-1].OID == object.OID {
+			continue
+		}
+
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(from.Download(object.OID, pw))
+		}()
+		written, err := to.Upload(object.OID, pr)
+		// Close the reader in case the upload returns before reading all the
+		// content, so that the download is not blocked forever.
+		_ = pr.Close()
+		if err != nil {
+			return migrated, errors.Wrapf(err, "copy object %q", object.OID)
+		} else if written != object.Size {
+			return migrated, errors.Errorf("copy object %q: size mismatch, want %d but got %d", object.OID, object.Size, written)
+		}
+
+		err = LFS.UpdateObjectStorage(ctx, object.OID, from.Storage(), to.Storage())
+		if err != nil {
+			return migrated, errors.Wrapf(err, "update storage of object %q", object.OID)
+		}
+		migrated++
+	}
+	return migrated, nil
+}
@@ -5,7 +5,10 @@
 package db
 
 import (
+	"bytes"
 	"context"
+	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -35,6 +38,10 @@ func TestLFS(t *testing.T) {
 		{"CreateObject", lfsCreateObject},
 		{"GetObjectByOID", lfsGetObjectByOID},
 		{"GetObjectsByOIDs", lfsGetObjectsByOIDs},
+		{"TotalSizeByRepoID", lfsTotalSizeByRepoID},
+		{"ListObjectsByStorage", lfsListObjectsByStorage},
+		{"UpdateObjectStorage", lfsUpdateObjectStorage},
+		{"MigrateLFSObjects", lfsMigrateLFSObjects},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Cleanup(func() {
@@ -110,3 +117,150 @@ func lfsGetObjectsByOIDs(t *testing.T, db *lfs) {
 	assert.Equal(t, repoID, objects[1].RepoID)
 	assert.Equal(t, oid2, objects[1].OID)
 }
+
+func lfsTotalSizeByRepoID(t *testing.T, db *lfs) {
+	ctx := context.Background()
+
+	// Repositories without LFS objects have zero size
+	size, err := db.TotalSizeByRepoID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+
+	oid1 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f")
+	oid2 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64g")
+	err = db.CreateObject(ctx, 1, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 1, oid2, 30, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 2, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+
+	size, err = db.TotalSizeByRepoID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), size)
+}
+
+func lfsListObjectsByStorage(t *testing.T, db *lfs) {
+	ctx := context.Background()
+
+	oid1 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f")
+	oid2 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64g")
+	err := db.CreateObject(ctx, 2, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 1, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 1, oid2, 30, lfsutil.StorageS3)
+	require.NoError(t, err)
+
+	objects, err := db.ListObjectsByStorage(ctx, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, int64(1), objects[0].RepoID)
+	assert.Equal(t, oid1, objects[0].OID)
+	assert.Equal(t, int64(2), objects[1].RepoID)
+	assert.Equal(t, oid1, objects[1].OID)
+
+	objects, err = db.ListObjectsByStorage(ctx, lfsutil.StorageS3)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, oid2, objects[0].OID)
+}
+
+func lfsUpdateObjectStorage(t *testing.T, db *lfs) {
+	ctx := context.Background()
+
+	oid := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f")
+	err := db.CreateObject(ctx, 1, oid, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 2, oid, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+
+	// Objects in other storage backends are not affected
+	err = db.UpdateObjectStorage(ctx, oid, lfsutil.StorageS3, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	objects, err := db.ListObjectsByStorage(ctx, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	err = db.UpdateObjectStorage(ctx, oid, lfsutil.StorageLocal, lfsutil.StorageS3)
+	require.NoError(t, err)
+	for _, repoID := range []int64{1, 2} {
+		object, err := db.GetObjectByOID(ctx, repoID, oid)
+		require.NoError(t, err)
+		assert.Equal(t, lfsutil.StorageS3, object.Storage)
+	}
+}
+
+// memoryStorage is an in-memory storage backend for testing.
+type memoryStorage struct {
+	storage lfsutil.Storage
+	objects map[lfsutil.OID]string
+}
+
+func (s *memoryStorage) Storage() lfsutil.Storage {
+	return s.storage
+}
+
+func (s *memoryStorage) Upload(oid lfsutil.OID, rc io.ReadCloser) (int64, error) {
+	defer rc.Close()
+
+	var buf bytes.Buffer
+	written, err := io.Copy(&buf, rc)
+	if err != nil {
+		return 0, err
+	}
+	s.objects[oid] = buf.String()
+	return written, nil
+}
+
+func (s *memoryStorage) Download(oid lfsutil.OID, w io.Writer) error {
+	content, ok := s.objects[oid]
+	if !ok {
+		return lfsutil.ErrObjectNotExist
+	}
+	_, err := io.Copy(w, strings.NewReader(content))
+	return err
+}
+
+func lfsMigrateLFSObjects(t *testing.T, db *lfs) {
+	ctx := context.Background()
+	SetMockLFSStore(t, db)
+
+	oid1 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f")
+	oid2 := lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64g")
+	err := db.CreateObject(ctx, 1, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 2, oid1, 12, lfsutil.StorageLocal)
+	require.NoError(t, err)
+	err = db.CreateObject(ctx, 1, oid2, 5, lfsutil.StorageLocal)
+	require.NoError(t, err)
+
+	from := &memoryStorage{
+		storage: lfsutil.StorageLocal,
+		objects: map[lfsutil.OID]string{
+			oid1: "Hello world!",
+			oid2: "Hello",
+		},
+	}
+	to := &memoryStorage{
+		storage: lfsutil.StorageS3,
+		objects: map[lfsutil.OID]string{},
+	}
+
+	_, err = MigrateLFSObjects(ctx, from, from)
+	assert.Error(t, err)
+
+	migrated, err := MigrateLFSObjects(ctx, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 2, migrated)
+	assert.Equal(t, from.objects, to.objects)
+
+	objects, err := db.ListObjectsByStorage(ctx, lfsutil.StorageS3)
+	require.NoError(t, err)
+	assert.Len(t, objects, 3)
+
+	// Nothing is left to migrate
+	migrated, err = MigrateLFSObjects(ctx, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// LinkedIssuesStore is the persistent interface for issues that are explicitly
+// linked to pull requests to be resolved by them, in addition to those referred
+// by closing keywords.
+type LinkedIssuesStore interface {
+	// Create links the issue to the pull request. It does nothing when the issue
+	// is already linked to the pull request.
+	Create(ctx context.Context, repoID, pullRequestID, issueID int64) error
+	// Delete unlinks the issue from the pull request. It does nothing when the
+	// issue is not linked to the pull request.
+	Delete(ctx context.Context, pullRequestID, issueID int64) error
+	// ListByPullRequestID returns all issues linked to the pull request, sorted
+	// from the oldest.
+	ListByPullRequestID(ctx context.Context, pullRequestID int64) ([]*LinkedIssue, error)
+}
+
+var LinkedIssues LinkedIssuesStore
+
+var _ LinkedIssuesStore = (*linkedIssues)(nil)
+
+type linkedIssues struct {
+	*gorm.DB
+}
+
+// NewLinkedIssuesStore returns a persistent interface for issues linked to pull
+// requests with given database connection.
+func NewLinkedIssuesStore(db *gorm.DB) LinkedIssuesStore {
+	return &linkedIssues{DB: db}
+}
+
+// LinkedIssue is an issue that is resolved by a pull request, and is closed when
+// the pull request is merged.
+type LinkedIssue struct {
+	ID            int64 `gorm:"primaryKey"`
+	RepoID        int64 `gorm:"index;not null"`
+	PullRequestID int64 `gorm:"uniqueIndex:linked_issue_pull_request_issue_unique;not null"`
+	IssueID       int64 `gorm:"uniqueIndex:linked_issue_pull_request_issue_unique;index;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (l *LinkedIssue) BeforeCreate(tx *gorm.DB) error {
+	if l.CreatedUnix == 0 {
+		l.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (l *LinkedIssue) AfterFind(_ *gorm.DB) error {
+	l.Created = time.Unix(l.CreatedUnix, 0).Local()
+	return nil
+}
+
+func (db *linkedIssues) Create(ctx context.Context, repoID, pullRequestID, issueID int64) error {
+	return db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(
+		&LinkedIssue{
+			RepoID:        repoID,
+			PullRequestID: pullRequestID,
+			IssueID:       issueID,
+		},
+	).Error
+}
+
+func (db *linkedIssues) Delete(ctx context.Context, pullRequestID, issueID int64) error {
+	return db.WithContext(ctx).
+		Where("pull_request_id = ? AND issue_id = ?", pullRequestID, issueID).
+		Delete(&LinkedIssue{}).
+		Error
+}
+
+func (db *linkedIssues) ListByPullRequestID(ctx context.Context, pullRequestID int64) ([]*LinkedIssue, error) {
+	var links []*LinkedIssue
+	return links, db.WithContext(ctx).
+		Where("pull_request_id = ?", pullRequestID).
+		Order("id ASC").
+		Find(&links).
+		Error
+}
+
+type ErrLinkedIssueNotAllowed struct {
+	args errutil.Args
+}
+
+// IsErrLinkedIssueNotAllowed returns true if the underlying error has the type
+// ErrLinkedIssueNotAllowed.
+func IsErrLinkedIssueNotAllowed(err error) bool {
+	_, ok := errors.Cause(err).(ErrLinkedIssueNotAllowed)
+	return ok
+}
+
+func (err ErrLinkedIssueNotAllowed) Error() string {
+	return fmt.Sprintf("issue cannot be linked to the pull request: %v", err.args)
+}
+
+// LinkIssue links the issue to the pull request to be closed when the pull
+// request is merged. Only issues of the base repository can be linked, it
+// returns ErrLinkedIssueNotAllowed for pull requests or issues of other
+// repositories.
+func (pr *PullRequest) LinkIssue(ctx context.Context, issue *Issue) error {
+	if issue.IsPull || issue.RepoID != pr.BaseRepoID {
+		return ErrLinkedIssueNotAllowed{args: errutil.Args{"pullRequestID": pr.ID, "issueID": issue.ID}}
+	}
+	return LinkedIssues.Create(ctx, pr.BaseRepoID, pr.ID, issue.ID)
+}
+
+// UnlinkIssue unlinks the issue from the pull request.
+func (pr *PullRequest) UnlinkIssue(ctx context.Context, issue *Issue) error {
+	return LinkedIssues.Delete(ctx, pr.ID, issue.ID)
+}
+
+// LinkedIssues returns issues linked to the pull request, sorted by the time
+// they were linked. Issues that no longer exist are skipped.
+func (pr *PullRequest) LinkedIssues(ctx context.Context) ([]*Issue, error) {
+	links, err := LinkedIssues.ListByPullRequestID(ctx, pr.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list linked issues")
+	}
+
+	issues := make([]*Issue, 0, len(links))
+	for _, link := range links {
+		issue, err := GetIssueByID(link.IssueID)
+		if err != nil {
+			if IsErrIssueNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "get issue by ID %d", link.IssueID)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// closeLinkedIssues closes open issues linked to the pull request by the doer,
+// with a reference to the pull request in their timelines. This method assumes
+// the pull request has been merged and the Issue and BaseRepo fields are
+// loaded.
+func (pr *PullRequest) closeLinkedIssues(doer *User) {
+	issues, err := pr.LinkedIssues(context.TODO())
+	if err != nil {
+		log.Error("Failed to get linked issues of pull request [%d]: %v", pr.ID, err)
+		return
+	}
+
+	content := fmt.Sprintf(`<a href="%s/pulls/%d">#%d %s</a>`, pr.BaseRepo.Link(), pr.Index, pr.Index, template.HTMLEscapeString(pr.Issue.Title))
+	for _, issue := range issues {
+		if issue.IsClosed {
+			continue
+		}
+
+		_, err = CreateComment(&CreateCommentOptions{
+			Type:    COMMENT_TYPE_PULL_REF,
+			Doer:    doer,
+			Repo:    pr.BaseRepo,
+			Issue:   issue,
+			Content: content,
+		})
+		if err != nil {
+			log.Error("Failed to create pull request reference comment [issue_id: %d]: %v", issue.ID, err)
+			continue
+		}
+		if err = issue.ChangeStatus(doer, pr.BaseRepo, true); err != nil {
+			log.Error("Failed to close linked issue [%d] of pull request [%d]: %v", issue.ID, pr.ID, err)
+		}
+	}
+}
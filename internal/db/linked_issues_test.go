@@ -0,0 +1,159 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestLinkedIssues(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &linkedIssues{
+		DB: dbtest.NewDB(t, "linkedIssues", new(LinkedIssue)),
+	}
+
+	err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+	err = db.Create(ctx, 1, 1, 3)
+	require.NoError(t, err)
+	err = db.Create(ctx, 1, 4, 2)
+	require.NoError(t, err)
+
+	// Linking the same issue again is a no-op
+	err = db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+
+	listIssueIDs := func(t *testing.T, pullRequestID int64) []int64 {
+		links, err := db.ListByPullRequestID(ctx, pullRequestID)
+		require.NoError(t, err)
+		ids := make([]int64, len(links))
+		for i := range links {
+			ids[i] = links[i].IssueID
+		}
+		return ids
+	}
+	assert.Equal(t, []int64{2, 3}, listIssueIDs(t, 1))
+	assert.Equal(t, []int64{2}, listIssueIDs(t, 4))
+
+	err = db.Delete(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, listIssueIDs(t, 1))
+	assert.Equal(t, []int64{2}, listIssueIDs(t, 4))
+}
+
+func TestPullRequest_closeLinkedIssues(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone),
+		new(IssueWorkflowState), new(PullRequest), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "closeLinkedIssues", new(User), new(EmailAddress), new(LinkedIssue))
+	before := LinkedIssues
+	LinkedIssues = NewLinkedIssuesStore(gdb)
+	t.Cleanup(func() {
+		LinkedIssues = before
+	})
+	SetMockUsersStore(t, NewUsersStore(gdb))
+
+	owner, err := Users.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	_, err = x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	other := &Repository{ID: 2, OwnerID: owner.ID, Owner: owner, LowerName: "other", Name: "other"}
+	_, err = x.Insert(other)
+	require.NoError(t, err)
+
+	newTestIssue := func(t *testing.T, repoID int64, isPull bool) *Issue {
+		repo, err := GetRepositoryByID(repoID)
+		require.NoError(t, err)
+
+		issue := &Issue{RepoID: repo.ID, PosterID: owner.ID, Title: "example", IsPull: isPull}
+		sess := x.NewSession()
+		defer sess.Close()
+		require.NoError(t, sess.Begin())
+		err = newIssue(sess, NewIssueOptions{Repo: repo, Issue: issue, IsPull: isPull})
+		require.NoError(t, err)
+		require.NoError(t, sess.Commit())
+		return issue
+	}
+
+	pullIssue := newTestIssue(t, repo.ID, true)
+	pr := &PullRequest{
+		IssueID:    pullIssue.ID,
+		Index:      pullIssue.Index,
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: "feature",
+		BaseBranch: "master",
+	}
+	_, err = x.Insert(pr)
+	require.NoError(t, err)
+	pr.Issue = pullIssue
+	pr.BaseRepo = repo
+
+	issue1 := newTestIssue(t, repo.ID, false)
+	issue2 := newTestIssue(t, repo.ID, false)
+	closed := newTestIssue(t, repo.ID, false)
+	unlinked := newTestIssue(t, repo.ID, false)
+	for _, issue := range []*Issue{issue1, issue2, closed} {
+		err = pr.LinkIssue(ctx, issue)
+		require.NoError(t, err)
+	}
+	closed.Poster = owner
+	err = closed.ChangeStatus(owner, repo, true)
+	require.NoError(t, err)
+
+	// Only issues of the base repository can be linked
+	err = pr.LinkIssue(ctx, pullIssue)
+	assert.True(t, IsErrLinkedIssueNotAllowed(err))
+	err = pr.LinkIssue(ctx, newTestIssue(t, other.ID, false))
+	assert.True(t, IsErrLinkedIssueNotAllowed(err))
+
+	linked, err := pr.LinkedIssues(ctx)
+	require.NoError(t, err)
+	require.Len(t, linked, 3)
+
+	pr.closeLinkedIssues(owner)
+
+	countPullRefs := func(t *testing.T, issueID int64) int64 {
+		count, err := x.Where("issue_id = ? AND type = ?", issueID, COMMENT_TYPE_PULL_REF).Count(new(Comment))
+		require.NoError(t, err)
+		return count
+	}
+	for _, issue := range []*Issue{issue1, issue2} {
+		got, err := GetIssueByID(issue.ID)
+		require.NoError(t, err)
+		assert.True(t, got.IsClosed, "issue #%d", issue.Index)
+		assert.Equal(t, int64(1), countPullRefs(t, issue.ID), "issue #%d", issue.Index)
+	}
+
+	// Issues already closed have no reference
+	assert.Zero(t, countPullRefs(t, closed.ID))
+
+	got, err := GetIssueByID(unlinked.ID)
+	require.NoError(t, err)
+	assert.False(t, got.IsClosed)
+	assert.Zero(t, countPullRefs(t, unlinked.ID))
+}
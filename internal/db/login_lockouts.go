@@ -0,0 +1,116 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gogs.io/gogs/internal/auth"
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/sync"
+)
+
+var (
+	// accountLoginFailures tracks failed sign in attempts by the login name, which
+	// is either a username or an email, regardless of whether the account exists.
+	accountLoginFailures = sync.NewKeyedLockout()
+	// ipLoginFailures tracks failed sign in attempts by the remote IP address.
+	ipLoginFailures = sync.NewKeyedLockout()
+)
+
+type ErrLoginLocked struct {
+	args errutil.Args
+}
+
+// IsErrLoginLocked returns true if the underlying error has the type
+// ErrLoginLocked.
+func IsErrLoginLocked(err error) bool {
+	_, ok := err.(ErrLoginLocked)
+	return ok
+}
+
+func (err ErrLoginLocked) Error() string {
+	return fmt.Sprintf("too many failed sign in attempts: %v", err.args)
+}
+
+func loginLockoutKey(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
+
+// checkLoginLockout returns ErrLoginLocked if sign in attempts of the login
+// name or from the remote IP address are locked because of too many failures.
+func checkLoginLockout(login, remoteIP string) error {
+	if !conf.Security.LoginLockout.Enabled {
+		return nil
+	}
+
+	if accountLoginFailures.LockedFor(loginLockoutKey(login)) > 0 ||
+		ipLoginFailures.LockedFor(remoteIP) > 0 {
+		return ErrLoginLocked{args: errutil.Args{"login": login, "remoteIP": remoteIP}}
+	}
+	return nil
+}
+
+// recordLoginFailure records a failed sign in attempt of the login name from the
+// remote IP address, which may lock further attempts of either.
+func recordLoginFailure(login, remoteIP string) {
+	opts := conf.Security.LoginLockout
+	if !opts.Enabled {
+		return
+	}
+
+	if opts.MaxAccountAttempts > 0 {
+		accountLoginFailures.Fail(loginLockoutKey(login), opts.MaxAccountAttempts, opts.Window, opts.MaxWindow)
+	}
+	if opts.MaxIPAttempts > 0 {
+		ipLoginFailures.Fail(remoteIP, opts.MaxIPAttempts, opts.Window, opts.MaxWindow)
+	}
+}
+
+// resetLoginFailures resets failed sign in attempts of the login name after a
+// successful sign in. Failures from the remote IP address are kept, so that
+// signing in to an account does not allow guessing passwords of others.
+func resetLoginFailures(login string) {
+	accountLoginFailures.Reset(loginLockoutKey(login))
+}
+
+// AuthenticateWithLockout validates username and password like
+// Users.Authenticate, and must be used by every path that signs in with a
+// password. The lockout is checked before the password and applies to any login
+// name, so that it does not reveal whether the account exists. It returns
+// ErrLoginLocked when attempts of the login name or from the remote IP address
+// are locked, and otherwise records failed attempts.
+func AuthenticateWithLockout(ctx context.Context, login, password string, loginSourceID int64, remoteIP string) (*User, error) {
+	if err := checkLoginLockout(login, remoteIP); err != nil {
+		return nil, err
+	}
+
+	u, err := Users.Authenticate(ctx, login, password, loginSourceID)
+	if err != nil {
+		if auth.IsErrBadCredentials(err) || IsErrLoginSourceMismatch(err) {
+			recordLoginFailure(login, remoteIP)
+		}
+		return nil, err
+	}
+	resetLoginFailures(login)
+	return u, nil
+}
+
+// ClearLoginLockout clears failed sign in attempts of the user by either the
+// username or the email.
+func ClearLoginLockout(u *User) {
+	accountLoginFailures.Reset(loginLockoutKey(u.Name))
+	accountLoginFailures.Reset(loginLockoutKey(u.Email))
+}
+
+// ClearAllLoginLockouts clears failed sign in attempts of all accounts and IP
+// addresses.
+func ClearAllLoginLockouts() {
+	accountLoginFailures.ResetAll()
+	ipLoginFailures.ResetAll()
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/auth"
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestLoginLockout(t *testing.T) {
+	opts := conf.Security
+	opts.LoginLockout.Enabled = true
+	opts.LoginLockout.MaxAccountAttempts = 3
+	opts.LoginLockout.MaxIPAttempts = 5
+	opts.LoginLockout.Window = time.Minute
+	opts.LoginLockout.MaxWindow = time.Hour
+	conf.SetMockSecurity(t, opts)
+
+	t.Cleanup(ClearAllLoginLockouts)
+	failTimes := func(login, remoteIP string, n int) {
+		for i := 0; i < n; i++ {
+			recordLoginFailure(login, remoteIP)
+		}
+	}
+
+	t.Run("exceeding the account threshold locks", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		failTimes("alice", "127.0.0.1", 2)
+		assert.Nil(t, checkLoginLockout("alice", "127.0.0.1"))
+
+		failTimes("alice", "127.0.0.1", 1)
+		wantErr := ErrLoginLocked{args: errutil.Args{"login": "Alice", "remoteIP": "127.0.0.2"}}
+		assert.Equal(t, wantErr, checkLoginLockout("Alice", "127.0.0.2"))
+
+		// Other accounts are not affected
+		assert.Nil(t, checkLoginLockout("bob", "127.0.0.1"))
+	})
+
+	t.Run("exceeding the IP threshold locks", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		for _, login := range []string{"alice", "bob", "cindy", "dan", "eve"} {
+			recordLoginFailure(login, "127.0.0.1")
+		}
+		assert.True(t, IsErrLoginLocked(checkLoginLockout("frank", "127.0.0.1")))
+		assert.Nil(t, checkLoginLockout("frank", "127.0.0.2"))
+	})
+
+	t.Run("successful login resets the counter", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		failTimes("alice", "127.0.0.1", 2)
+		resetLoginFailures("alice")
+		failTimes("alice", "127.0.0.1", 2)
+		assert.Nil(t, checkLoginLockout("alice", "127.0.0.1"))
+	})
+
+	t.Run("admin clears the lockout", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		failTimes("alice", "127.0.0.1", 3)
+		failTimes("alice@example.com", "127.0.0.1", 3)
+		ClearLoginLockout(&User{Name: "alice", Email: "alice@example.com"})
+		assert.Nil(t, checkLoginLockout("alice", "127.0.0.2"))
+		assert.Nil(t, checkLoginLockout("alice@example.com", "127.0.0.2"))
+	})
+
+	t.Run("lockout window grows exponentially", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		var windows []time.Duration
+		for i := 0; i < 9; i++ {
+			windows = append(windows, accountLoginFailures.Fail("alice", 3, time.Minute, time.Hour))
+		}
+		want := []time.Duration{0, 0, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 32 * time.Minute, time.Hour}
+		assert.Equal(t, want, windows)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Cleanup(ClearAllLoginLockouts)
+
+		opts := conf.Security
+		opts.LoginLockout.Enabled = false
+		conf.SetMockSecurity(t, opts)
+
+		failTimes("alice", "127.0.0.1", 10)
+		assert.Nil(t, checkLoginLockout("alice", "127.0.0.1"))
+	})
+}
+
+func TestAuthenticateWithLockout(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	opts := conf.Security
+	opts.LoginLockout.Enabled = true
+	opts.LoginLockout.MaxAccountAttempts = 2
+	opts.LoginLockout.MaxIPAttempts = 5
+	opts.LoginLockout.Window = time.Minute
+	opts.LoginLockout.MaxWindow = time.Hour
+	conf.SetMockSecurity(t, opts)
+	t.Cleanup(ClearAllLoginLockouts)
+
+	ctx := context.Background()
+	db := dbtest.NewDB(t, "authenticateWithLockout", new(User), new(EmailAddress))
+	SetMockUsersStore(t, NewUsersStore(db))
+	_, err := Users.Create(ctx, "alice", "alice@example.com", CreateUserOptions{Password: "pa$$word", Activated: true})
+	require.NoError(t, err)
+
+	u, err := AuthenticateWithLockout(ctx, "alice", "pa$$word", -1, "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", u.Name)
+
+	for i := 0; i < 2; i++ {
+		_, err = AuthenticateWithLockout(ctx, "alice", "bad", -1, "127.0.0.1")
+		assert.True(t, auth.IsErrBadCredentials(err), "want ErrBadCredentials but got %v", err)
+	}
+
+	// The correct password is rejected once locked
+	_, err = AuthenticateWithLockout(ctx, "alice", "pa$$word", -1, "127.0.0.2")
+	assert.True(t, IsErrLoginLocked(err), "want ErrLoginLocked but got %v", err)
+
+	// Non-existent accounts are counted the same way
+	for i := 0; i < 2; i++ {
+		_, err = AuthenticateWithLockout(ctx, "bob", "bad", -1, "127.0.0.3")
+		assert.True(t, auth.IsErrBadCredentials(err), "want ErrBadCredentials but got %v", err)
+	}
+	_, err = AuthenticateWithLockout(ctx, "bob", "bad", -1, "127.0.0.3")
+	assert.True(t, IsErrLoginLocked(err), "want ErrLoginLocked but got %v", err)
+}
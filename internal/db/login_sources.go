@@ -17,6 +17,7 @@ import (
 	"gogs.io/gogs/internal/auth"
 	"gogs.io/gogs/internal/auth/github"
 	"gogs.io/gogs/internal/auth/ldap"
+	"gogs.io/gogs/internal/auth/oidc"
 	"gogs.io/gogs/internal/auth/pam"
 	"gogs.io/gogs/internal/auth/smtp"
 	"gogs.io/gogs/internal/errutil"
@@ -134,6 +135,14 @@ func (s *LoginSource) AfterFind(_ *gorm.DB) error {
 		}
 		s.Provider = github.NewProvider(&cfg)
 
+	case auth.OIDC:
+		var cfg oidc.Config
+		err := jsoniter.UnmarshalFromString(s.Config, &cfg)
+		if err != nil {
+			return err
+		}
+		s.Provider = oidc.NewProvider(&cfg)
+
 	default:
 		return fmt.Errorf("unrecognized login source type: %v", s.Type)
 	}
@@ -164,6 +173,10 @@ func (s *LoginSource) IsGitHub() bool {
 	return s.Type == auth.GitHub
 }
 
+func (s *LoginSource) IsOIDC() bool {
+	return s.Type == auth.OIDC
+}
+
 func (s *LoginSource) LDAP() *ldap.Config {
 	return s.Provider.Config().(*ldap.Config)
 }
@@ -180,6 +193,10 @@ func (s *LoginSource) GitHub() *github.Config {
 	return s.Provider.Config().(*github.Config)
 }
 
+func (s *LoginSource) OIDC() *oidc.Config {
+	return s.Provider.Config().(*oidc.Config)
+}
+
 var _ LoginSourcesStore = (*loginSources)(nil)
 
 type loginSources struct {
@@ -226,7 +243,13 @@ func (db *loginSources) Create(ctx context.Context, opts CreateLoginSourceOption
 	if err != nil {
 		return nil, err
 	}
-	return source, db.WithContext(ctx).Create(source).Error
+
+	err = db.WithContext(ctx).Create(source).Error
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionLoginSourceCreate, source.ID, source.Name, "")
+	return source, nil
 }
 
 func (db *loginSources) Count(ctx context.Context) int64 {
@@ -257,7 +280,12 @@ func (db *loginSources) DeleteByID(ctx context.Context, id int64) error {
 		return ErrLoginSourceInUse{args: errutil.Args{"id": id}}
 	}
 
-	return db.WithContext(ctx).Where("id = ?", id).Delete(new(LoginSource)).Error
+	err = db.WithContext(ctx).Where("id = ?", id).Delete(new(LoginSource)).Error
+	if err != nil {
+		return err
+	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionLoginSourceDelete, id, "", "")
+	return nil
 }
 
 func (db *loginSources) GetByID(ctx context.Context, id int64) (*LoginSource, error) {
@@ -316,16 +344,20 @@ func (db *loginSources) ResetNonDefault(ctx context.Context, dflt *LoginSource)
 
 func (db *loginSources) Save(ctx context.Context, source *LoginSource) error {
 	if source.File == nil {
-		return db.WithContext(ctx).Save(source).Error
-	}
-
-	source.File.SetGeneral("name", source.Name)
-	source.File.SetGeneral("is_activated", strconv.FormatBool(source.IsActived))
-	source.File.SetGeneral("is_default", strconv.FormatBool(source.IsDefault))
-	if err := source.File.SetConfig(source.Provider.Config()); err != nil {
-		return errors.Wrap(err, "set config")
-	} else if err = source.File.Save(); err != nil {
-		return errors.Wrap(err, "save file")
+		err := db.WithContext(ctx).Save(source).Error
+		if err != nil {
+			return err
+		}
+	} else {
+		source.File.SetGeneral("name", source.Name)
+		source.File.SetGeneral("is_activated", strconv.FormatBool(source.IsActived))
+		source.File.SetGeneral("is_default", strconv.FormatBool(source.IsDefault))
+		if err := source.File.SetConfig(source.Provider.Config()); err != nil {
+			return errors.Wrap(err, "set config")
+		} else if err = source.File.Save(); err != nil {
+			return errors.Wrap(err, "save file")
+		}
 	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionLoginSourceUpdate, source.ID, source.Name, "")
 	return nil
 }
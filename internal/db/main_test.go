@@ -8,12 +8,16 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	_ "modernc.org/sqlite"
 	log "unknwon.dev/clog/v2"
+	"xorm.io/core"
+	"xorm.io/xorm"
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/testutil"
@@ -64,3 +68,21 @@ func clearTables(t *testing.T, db *gorm.DB, tables ...any) error {
 	}
 	return nil
 }
+
+// setTestEngine replaces the legacy engine with a new SQLite database that has
+// given tables for the duration of the test. Tests using it must not be run in
+// parallel.
+func setTestEngine(t *testing.T, tables ...any) {
+	engine, err := xorm.NewEngine("sqlite3", "file:"+filepath.Join(t.TempDir(), "gogs.db")+"?cache=shared&mode=rwc")
+	require.NoError(t, err)
+	engine.SetMapper(core.GonicMapper{})
+	err = engine.Sync2(tables...)
+	require.NoError(t, err)
+
+	before := x
+	x = engine
+	t.Cleanup(func() {
+		x = before
+		_ = engine.Close()
+	})
+}
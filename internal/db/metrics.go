@@ -0,0 +1,164 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/metrics"
+)
+
+// registerQueryMetrics registers callbacks of the database to observe durations
+// of queries in metrics.DBQueryDuration.
+//
+// NOTE: Queries of legacy models through the XORM engine are not observed
+// because the version of XORM in use has no hooks for queries.
+func registerQueryMetrics(db *gorm.DB) error {
+	const startKey = "metrics:start"
+	before := func(tx *gorm.DB) {
+		tx.InstanceSet(startKey, time.Now())
+	}
+	after := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			start, ok := tx.InstanceGet(startKey)
+			if !ok {
+				return
+			}
+			metrics.DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+
+	c := db.Callback()
+	errs := []error{
+		c.Create().Before("gorm:create").Register("metrics:before_create", before),
+		c.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		c.Query().Before("gorm:query").Register("metrics:before_query", before),
+		c.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		c.Update().Before("gorm:update").Register("metrics:before_update", before),
+		c.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		c.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		c.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		c.Row().Before("gorm:row").Register("metrics:before_row", before),
+		c.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		c.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		c.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statsCacheTTL is the duration that counts of the statistics collector are
+// reused, so frequent scrapes do not hit the database every time.
+const statsCacheTTL = 30 * time.Second
+
+var (
+	activeUsersDesc = prometheus.NewDesc(
+		"gogs_users_active",
+		"Number of active users.",
+		nil, nil,
+	)
+	repositoriesDesc = prometheus.NewDesc(
+		"gogs_repositories",
+		"Number of repositories.",
+		nil, nil,
+	)
+	openIssuesDesc = prometheus.NewDesc(
+		"gogs_issues_open",
+		"Number of open issues and pull requests by type.",
+		[]string{"type"}, nil,
+	)
+	webhookQueueDesc = prometheus.NewDesc(
+		"gogs_webhook_queue_depth",
+		"Number of webhook deliveries that are not yet delivered.",
+		nil, nil,
+	)
+)
+
+type statsCounts struct {
+	activeUsers  int64
+	repositories int64
+	openIssues   int64
+	openPulls    int64
+	pendingHooks int64
+	collectedAt  time.Time
+}
+
+// statsCollector is a Prometheus collector of counts of objects in the
+// database.
+type statsCollector struct {
+	lock   sync.Mutex
+	counts statsCounts
+}
+
+// NewStatsCollector returns a new Prometheus collector of counts of active
+// users, repositories, open issues and pull requests, and pending webhook
+// deliveries.
+func NewStatsCollector() prometheus.Collector {
+	return &statsCollector{}
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeUsersDesc
+	ch <- repositoriesDesc
+	ch <- openIssuesDesc
+	ch <- webhookQueueDesc
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if time.Since(c.counts.collectedAt) > statsCacheTTL {
+		counts, err := countStats()
+		if err != nil {
+			log.Error("Failed to count statistics for metrics: %v", err)
+			return
+		}
+		c.counts = *counts
+	}
+
+	ch <- prometheus.MustNewConstMetric(activeUsersDesc, prometheus.GaugeValue, float64(c.counts.activeUsers))
+	ch <- prometheus.MustNewConstMetric(repositoriesDesc, prometheus.GaugeValue, float64(c.counts.repositories))
+	ch <- prometheus.MustNewConstMetric(openIssuesDesc, prometheus.GaugeValue, float64(c.counts.openIssues), "issue")
+	ch <- prometheus.MustNewConstMetric(openIssuesDesc, prometheus.GaugeValue, float64(c.counts.openPulls), "pull")
+	ch <- prometheus.MustNewConstMetric(webhookQueueDesc, prometheus.GaugeValue, float64(c.counts.pendingHooks))
+}
+
+func countStats() (*statsCounts, error) {
+	var counts statsCounts
+	var err error
+	counts.activeUsers, err = x.Where("type = ? AND is_active = ?", UserTypeIndividual, true).Count(new(User))
+	if err != nil {
+		return nil, errors.Wrap(err, "count active users")
+	}
+	counts.repositories, err = x.Count(new(Repository))
+	if err != nil {
+		return nil, errors.Wrap(err, "count repositories")
+	}
+	counts.openIssues, err = x.Where("is_pull = ? AND is_closed = ?", false, false).Count(new(Issue))
+	if err != nil {
+		return nil, errors.Wrap(err, "count open issues")
+	}
+	counts.openPulls, err = x.Where("is_pull = ? AND is_closed = ?", true, false).Count(new(Issue))
+	if err != nil {
+		return nil, errors.Wrap(err, "count open pull requests")
+	}
+	counts.pendingHooks, err = x.Where("is_delivered = ?", false).Count(new(HookTask))
+	if err != nil {
+		return nil, errors.Wrap(err, "count pending webhook deliveries")
+	}
+	counts.collectedAt = time.Now()
+	return &counts, nil
+}
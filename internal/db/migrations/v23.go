@@ -0,0 +1,77 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// backfillAttachmentSHA256 adds the "sha256" column to the "attachment" table
+// and computes checksums of existing attachments from their files. Checksums of
+// attachments whose files can't be read are left empty.
+func backfillAttachmentSHA256(db *gorm.DB) error {
+	type attachment struct {
+		ID     int64
+		UUID   string `gorm:"column:uuid"`
+		SHA256 string `gorm:"column:sha256;type:VARCHAR(64)"`
+	}
+
+	if !db.Migrator().HasTable(&attachment{}) {
+		return errMigrationSkipped
+	}
+	if !db.Migrator().HasColumn(&attachment{}, "SHA256") {
+		err := db.Migrator().AddColumn(&attachment{}, "SHA256")
+		if err != nil {
+			return errors.Wrap(err, "add column")
+		}
+	}
+
+	checksum := func(uuid string) (string, error) {
+		if len(uuid) < 2 {
+			return "", errors.Errorf("malformed UUID %q", uuid)
+		}
+
+		f, err := os.Open(filepath.Join(conf.Attachment.Path, uuid[0:1], uuid[1:2], uuid))
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = f.Close() }()
+
+		hash := sha256.New()
+		if _, err = io.Copy(hash, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	var attachments []*attachment
+	return db.Where("sha256 IS NULL OR sha256 = ''").
+		FindInBatches(&attachments, 100, func(tx *gorm.DB, _ int) error {
+			for _, a := range attachments {
+				sum, err := checksum(a.UUID)
+				if err != nil {
+					log.Warn("Failed to compute checksum of attachment [%d]: %v", a.ID, err)
+					continue
+				}
+
+				err = tx.Model(&attachment{}).Where("id = ?", a.ID).Update("sha256", sum).Error
+				if err != nil {
+					return errors.Wrap(err, "update")
+				}
+			}
+			return nil
+		}).
+		Error
+}
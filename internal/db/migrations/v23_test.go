@@ -0,0 +1,77 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+type attachmentPreV23 struct {
+	ID          int64
+	UUID        string `gorm:"column:uuid;unique"`
+	IssueID     int64  `gorm:"index"`
+	CommentID   int64
+	ReleaseID   int64 `gorm:"index"`
+	Name        string
+	CreatedUnix int64
+}
+
+func (*attachmentPreV23) TableName() string {
+	return "attachment"
+}
+
+type attachmentV23 struct {
+	ID     int64
+	UUID   string `gorm:"column:uuid"`
+	SHA256 string `gorm:"column:sha256"`
+}
+
+func (*attachmentV23) TableName() string {
+	return "attachment"
+}
+
+func TestBackfillAttachmentSHA256(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	before := conf.Attachment.Path
+	conf.Attachment.Path = t.TempDir()
+	t.Cleanup(func() {
+		conf.Attachment.Path = before
+	})
+
+	db := dbtest.NewDB(t, "backfillAttachmentSHA256", new(attachmentPreV23))
+	err := db.Create([]*attachmentPreV23{
+		{ID: 1, UUID: "ab12", Name: "hello.txt"},
+		{ID: 2, UUID: "cd34", Name: "missing.txt"},
+	}).Error
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(conf.Attachment.Path, "a", "b"), os.ModePerm)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(conf.Attachment.Path, "a", "b", "ab12"), []byte("hello world\n"), 0o644)
+	require.NoError(t, err)
+
+	err = backfillAttachmentSHA256(db)
+	require.NoError(t, err)
+
+	var got []*attachmentV23
+	err = db.Order("id ASC").Find(&got).Error
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "a948904f2f0f479b8f8197694b30184b0d2ed1c1cd2a1ec0fb85d299a192a447", got[0].SHA256)
+
+	// Checksums of missing files are left empty
+	assert.Empty(t, got[1].SHA256)
+}
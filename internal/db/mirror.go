@@ -67,9 +67,45 @@ func (m *Mirror) AfterSet(colName string, _ xorm.Cell) {
 	}
 }
 
+// effectiveInterval returns the interval of the mirror in hours, which is at
+// least the minimum interval enforced by the server.
+func (m *Mirror) effectiveInterval() int {
+	if m.Interval < conf.Mirror.MinInterval {
+		return conf.Mirror.MinInterval
+	}
+	return m.Interval
+}
+
 // ScheduleNextSync calculates and sets next sync time based on repository mirror setting.
 func (m *Mirror) ScheduleNextSync() {
-	m.NextSync = time.Now().Add(time.Duration(m.Interval) * time.Hour)
+	m.NextSync = time.Now().Add(time.Duration(m.effectiveInterval()) * time.Hour)
+}
+
+// SetInterval sets the interval of the mirror in hours and reschedules the next
+// sync. It returns errors.MirrorIntervalTooShort if the interval is shorter than
+// the minimum interval enforced by the server.
+func (m *Mirror) SetInterval(interval int) error {
+	if interval < conf.Mirror.MinInterval {
+		return errors.MirrorIntervalTooShort{Interval: interval, MinInterval: conf.Mirror.MinInterval}
+	}
+	m.Interval = interval
+	m.ScheduleNextSync()
+	return nil
+}
+
+// SyncNow adds the mirror to the sync queue. It returns
+// errors.MirrorSyncCooldown if the mirror was synced within the cooldown to
+// prevent hammering the upstream.
+func (m *Mirror) SyncNow() error {
+	if m.LastSyncUnix > 0 {
+		remaining := time.Until(m.LastSync.Add(conf.Mirror.SyncCooldown))
+		if remaining > 0 {
+			return errors.MirrorSyncCooldown{RepoID: m.RepoID, Remaining: remaining.Round(time.Second)}
+		}
+	}
+
+	go MirrorQueue.Add(m.RepoID)
+	return nil
 }
 
 func (m *Mirror) readAddress() {
@@ -6,8 +6,13 @@ package db
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/db/errors"
 )
 
 func Test_parseRemoteUpdateOutput(t *testing.T) {
@@ -35,3 +40,79 @@ From https://try.gogs.io/unknwon/upsteam
 		})
 	}
 }
+
+func TestMirror_ScheduleNextSync(t *testing.T) {
+	conf.SetMockMirror(t, conf.MirrorOpts{DefaultInterval: 8, MinInterval: 2})
+
+	// A custom interval is honored
+	m := &Mirror{Interval: 4}
+	m.ScheduleNextSync()
+	assert.WithinDuration(t, time.Now().Add(4*time.Hour), m.NextSync, time.Minute)
+
+	// The minimum interval is enforced on existing mirrors
+	m = &Mirror{Interval: 1}
+	m.ScheduleNextSync()
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), m.NextSync, time.Minute)
+}
+
+func TestMirror_SetInterval(t *testing.T) {
+	conf.SetMockMirror(t, conf.MirrorOpts{DefaultInterval: 8, MinInterval: 2})
+
+	m := &Mirror{Interval: 8}
+	err := m.SetInterval(1)
+	assert.Equal(t, errors.MirrorIntervalTooShort{Interval: 1, MinInterval: 2}, err)
+	assert.Equal(t, 8, m.Interval)
+
+	err = m.SetInterval(3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, m.Interval)
+	assert.WithinDuration(t, time.Now().Add(3*time.Hour), m.NextSync, time.Minute)
+}
+
+func TestMirror_SyncNow(t *testing.T) {
+	conf.SetMockMirror(t, conf.MirrorOpts{DefaultInterval: 8, MinInterval: 1, SyncCooldown: 5 * time.Minute})
+
+	// Mirrors synced within the cooldown are rejected
+	lastSync := time.Now().Add(-time.Minute)
+	m := &Mirror{RepoID: 101, LastSync: lastSync, LastSyncUnix: lastSync.Unix()}
+	err := m.SyncNow()
+	assert.True(t, errors.IsMirrorSyncCooldown(err))
+	assert.InDelta(t, 4*time.Minute, err.(errors.MirrorSyncCooldown).Remaining, float64(time.Second))
+
+	lastSync = time.Now().Add(-10 * time.Minute)
+	m = &Mirror{RepoID: 101, LastSync: lastSync, LastSyncUnix: lastSync.Unix()}
+	assert.NoError(t, m.SyncNow())
+
+	// Mirrors never synced are not rejected
+	m = &Mirror{RepoID: 102}
+	assert.NoError(t, m.SyncNow())
+}
+
+func TestMirrorUpdate(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Repository), new(Mirror))
+
+	for _, repo := range []*Repository{
+		{ID: 1, OwnerID: 1, LowerName: "due", Name: "due", IsMirror: true},
+		{ID: 2, OwnerID: 1, LowerName: "not-due", Name: "not-due", IsMirror: true},
+	} {
+		_, err := x.Insert(repo)
+		require.NoError(t, err)
+	}
+	_, err := x.Insert(
+		&Mirror{RepoID: 1, Interval: 1, NextSync: time.Now().Add(-time.Minute)},
+		&Mirror{RepoID: 2, Interval: 1, NextSync: time.Now().Add(time.Hour)},
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		MirrorQueue.Remove(1)
+		MirrorQueue.Remove(2)
+	})
+
+	MirrorUpdate()
+	assert.True(t, MirrorQueue.Exist(1))
+	assert.False(t, MirrorQueue.Exist(2))
+}
@@ -81,14 +81,14 @@ func (org *User) GetMembers(limit int) error {
 	return nil
 }
 
-// AddMember adds new member to organization.
-func (org *User) AddMember(uid int64) error {
-	return AddOrgUser(org.ID, uid)
+// AddMember adds new member to organization by the doer.
+func (org *User) AddMember(doer *User, uid int64) error {
+	return AddOrgUser(doer, org.ID, uid)
 }
 
-// RemoveMember removes member from organization.
-func (org *User) RemoveMember(uid int64) error {
-	return RemoveOrgUser(org.ID, uid)
+// RemoveMember removes member from organization by the doer.
+func (org *User) RemoveMember(doer *User, uid int64) error {
+	return RemoveOrgUser(doer, org.ID, uid)
 }
 
 func (org *User) removeOrgRepo(e Engine, repoID int64) error {
@@ -123,6 +123,9 @@ func CreateOrganization(org, owner *User) (err error) {
 	}
 	org.UseCustomAvatar = true
 	org.MaxRepoCreation = -1
+	org.MaxCollaborators = -1
+	org.MaxTeams = -1
+	org.MaxTeamMembers = -1
 	org.NumTeams = 1
 	org.NumMembers = 1
 
@@ -171,7 +174,13 @@ func CreateOrganization(org, owner *User) (err error) {
 		return fmt.Errorf("create directory: %v", err)
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	membershipCache.invalidateOrg(org.ID, owner.ID)
+	membershipCache.invalidateTeam(t.ID, owner.ID)
+	return nil
 }
 
 // GetOrgByName returns organization by given name.
@@ -231,10 +240,16 @@ func DeleteOrganization(org *User) error {
 		&Team{OrgID: org.ID},
 		&OrgUser{OrgID: org.ID},
 		&TeamUser{OrgID: org.ID},
+		&RepoSecret{OrgID: org.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	membershipCache.reset()
+	return nil
 }
 
 // ________                ____ ___
@@ -262,8 +277,10 @@ func IsOrganizationOwner(orgID, userID int64) bool {
 
 // IsOrganizationMember returns true if given user is member of organization.
 func IsOrganizationMember(orgId, uid int64) bool {
-	has, _ := x.Where("uid=?", uid).And("org_id=?", orgId).Get(new(OrgUser))
-	return has
+	return membershipCache.load(orgMembershipKey(orgId, uid), func() bool {
+		has, _ := x.Where("uid=?", uid).And("org_id=?", orgId).Get(new(OrgUser))
+		return has
+	})
 }
 
 // IsPublicMembership returns true if given user public his/her membership.
@@ -336,8 +353,8 @@ func ChangeOrgUserStatus(orgID, uid int64, public bool) error {
 	return err
 }
 
-// AddOrgUser adds new user to given organization.
-func AddOrgUser(orgID, uid int64) error {
+// AddOrgUser adds new user to given organization by the doer.
+func AddOrgUser(doer *User, orgID, uid int64) error {
 	if IsOrganizationMember(orgID, uid) {
 		return nil
 	}
@@ -348,22 +365,47 @@ func AddOrgUser(orgID, uid int64) error {
 		return err
 	}
 
-	ou := &OrgUser{
-		Uid:   uid,
-		OrgID: orgID,
+	added, err := addOrgUser(sess, orgID, uid)
+	if err != nil {
+		return err
 	}
 
-	if _, err := sess.Insert(ou); err != nil {
-		return err
-	} else if _, err = sess.Exec("UPDATE `user` SET num_members = num_members + 1 WHERE id = ?", orgID); err != nil {
+	if err := sess.Commit(); err != nil {
 		return err
 	}
 
-	return sess.Commit()
+	if added {
+		membershipCache.invalidateOrg(orgID, uid)
+		prepareMembershipWebhooks(doer, orgID, uid, HOOK_MEMBERSHIP_ADDED)
+	}
+	return nil
+}
+
+// addOrgUser adds the user to the organization, it returns false if the user is
+// already a member. The caller is responsible for invalidating the membership
+// cache and preparing webhooks after the transaction is committed.
+func addOrgUser(e Engine, orgID, uid int64) (added bool, err error) {
+	has, err := e.Where("uid = ?", uid).And("org_id = ?", orgID).Get(new(OrgUser))
+	if err != nil {
+		return false, err
+	} else if has {
+		return false, nil
+	}
+
+	ou := &OrgUser{
+		Uid:   uid,
+		OrgID: orgID,
+	}
+	if _, err = e.Insert(ou); err != nil {
+		return false, err
+	} else if _, err = e.Exec("UPDATE `user` SET num_members = num_members + 1 WHERE id = ?", orgID); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
-// RemoveOrgUser removes user from given organization.
-func RemoveOrgUser(orgID, userID int64) error {
+// RemoveOrgUser removes user from given organization by the doer.
+func RemoveOrgUser(doer *User, orgID, userID int64) error {
 	ou := new(OrgUser)
 
 	has, err := x.Where("uid=?", userID).And("org_id=?", orgID).Get(ou)
@@ -437,7 +479,17 @@ func RemoveOrgUser(orgID, userID int64) error {
 		}
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	membershipCache.invalidateOrg(orgID, userID)
+	for _, t := range teams {
+		membershipCache.invalidateTeam(t.ID, userID)
+		prepareTeamWebhooks(doer, t, userID, HOOK_MEMBERSHIP_REMOVED)
+	}
+	prepareMembershipWebhooks(doer, orgID, userID, HOOK_MEMBERSHIP_REMOVED)
+	return nil
 }
 
 func removeOrgRepo(e Engine, orgID, repoID int64) error {
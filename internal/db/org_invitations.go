@@ -0,0 +1,291 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+// OrgInvitationsStore is the persistent interface for invitations of
+// organization members.
+type OrgInvitationsStore interface {
+	// Create creates a new invitation for the invitee to join the organization,
+	// which expires after conf.Organization.MemberInvitationLifetime. It returns
+	// ErrOrgInvitationAlreadyExist when an unexpired invitation already exists.
+	Create(ctx context.Context, orgID, inviterID, inviteeID int64) (*OrgInvitation, error)
+	// GetByID returns the unexpired invitation with given ID for the invitee. It
+	// returns ErrOrgInvitationNotExist when not found.
+	//
+	// 🚨 SECURITY: The "inviteeID" is required to prevent attacker gets
+	// arbitrary invitation that belongs to another user.
+	GetByID(ctx context.Context, inviteeID, id int64) (*OrgInvitation, error)
+	// ListByOrgID returns all unexpired invitations of the organization, sorted
+	// from the oldest.
+	ListByOrgID(ctx context.Context, orgID int64) ([]*OrgInvitation, error)
+	// ListByInviteeID returns all unexpired invitations for the invitee, sorted
+	// from the oldest.
+	ListByInviteeID(ctx context.Context, inviteeID int64) ([]*OrgInvitation, error)
+	// Accept accepts the unexpired invitation with given ID for the invitee,
+	// which adds the invitee as a member of the organization and deletes the
+	// invitation. It returns ErrOrgInvitationNotExist when not found.
+	Accept(ctx context.Context, inviteeID, id int64) error
+	// Decline deletes the unexpired invitation with given ID for the invitee
+	// without adding the membership. It returns ErrOrgInvitationNotExist when not
+	// found.
+	Decline(ctx context.Context, inviteeID, id int64) error
+	// DeleteByID deletes the invitation with given ID of the organization. It
+	// returns ErrOrgInvitationNotExist when not found.
+	DeleteByID(ctx context.Context, orgID, id int64) error
+	// DeleteExpired deletes all expired invitations and returns the number of
+	// deleted invitations.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+var OrgInvitations OrgInvitationsStore
+
+var _ OrgInvitationsStore = (*orgInvitations)(nil)
+
+type orgInvitations struct {
+	*gorm.DB
+}
+
+// NewOrgInvitationsStore returns a persistent interface for invitations of
+// organization members with given database connection.
+func NewOrgInvitationsStore(db *gorm.DB) OrgInvitationsStore {
+	return &orgInvitations{DB: db}
+}
+
+// OrgInvitation is an invitation for a user to join an organization.
+type OrgInvitation struct {
+	ID        int64 `gorm:"primaryKey"`
+	OrgID     int64 `gorm:"uniqueIndex:org_invitation_org_invitee_unique;not null"`
+	InviterID int64 `gorm:"not null"`
+	InviteeID int64 `gorm:"uniqueIndex:org_invitation_org_invitee_unique;index;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Expires     time.Time `gorm:"-" json:"-"`
+	ExpiresUnix int64     `gorm:"index"`
+}
+
+// BeforeCreate implements the GORM create hook.
+func (inv *OrgInvitation) BeforeCreate(tx *gorm.DB) error {
+	if inv.CreatedUnix == 0 {
+		inv.CreatedUnix = tx.NowFunc().Unix()
+	}
+	if inv.ExpiresUnix == 0 {
+		inv.ExpiresUnix = inv.CreatedUnix + int64(conf.Organization.MemberInvitationLifetime.Seconds())
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (inv *OrgInvitation) AfterFind(_ *gorm.DB) error {
+	inv.Created = time.Unix(inv.CreatedUnix, 0).Local()
+	inv.Expires = time.Unix(inv.ExpiresUnix, 0).Local()
+	return nil
+}
+
+type ErrOrgInvitationAlreadyExist struct {
+	args errutil.Args
+}
+
+// IsErrOrgInvitationAlreadyExist returns true if the underlying error has the
+// type ErrOrgInvitationAlreadyExist.
+func IsErrOrgInvitationAlreadyExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrOrgInvitationAlreadyExist)
+	return ok
+}
+
+func (err ErrOrgInvitationAlreadyExist) Error() string {
+	return fmt.Sprintf("organization invitation already exists: %v", err.args)
+}
+
+func (db *orgInvitations) Create(ctx context.Context, orgID, inviterID, inviteeID int64) (*OrgInvitation, error) {
+	inv := &OrgInvitation{
+		OrgID:     orgID,
+		InviterID: inviterID,
+		InviteeID: inviteeID,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// An expired invitation is replaced by the new one.
+		err := tx.Where("org_id = ? AND invitee_id = ? AND expires_unix <= ?", orgID, inviteeID, tx.NowFunc().Unix()).
+			Delete(new(OrgInvitation)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete expired invitation")
+		}
+
+		err = tx.Where("org_id = ? AND invitee_id = ?", orgID, inviteeID).First(new(OrgInvitation)).Error
+		if err == nil {
+			return ErrOrgInvitationAlreadyExist{args: errutil.Args{"orgID": orgID, "inviteeID": inviteeID}}
+		} else if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		return tx.Create(inv).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.GetByID(ctx, inviteeID, inv.ID)
+}
+
+var _ errutil.NotFound = (*ErrOrgInvitationNotExist)(nil)
+
+type ErrOrgInvitationNotExist struct {
+	args errutil.Args
+}
+
+// IsErrOrgInvitationNotExist returns true if the underlying error has the type
+// ErrOrgInvitationNotExist.
+func IsErrOrgInvitationNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrOrgInvitationNotExist)
+	return ok
+}
+
+func (err ErrOrgInvitationNotExist) Error() string {
+	return fmt.Sprintf("organization invitation does not exist: %v", err.args)
+}
+
+func (ErrOrgInvitationNotExist) NotFound() bool {
+	return true
+}
+
+func (db *orgInvitations) GetByID(ctx context.Context, inviteeID, id int64) (*OrgInvitation, error) {
+	inv := new(OrgInvitation)
+	err := db.WithContext(ctx).
+		Where("id = ? AND invitee_id = ? AND expires_unix > ?", id, inviteeID, db.NowFunc().Unix()).
+		First(inv).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrOrgInvitationNotExist{args: errutil.Args{"inviteeID": inviteeID, "id": id}}
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (db *orgInvitations) ListByOrgID(ctx context.Context, orgID int64) ([]*OrgInvitation, error) {
+	var invs []*OrgInvitation
+	return invs, db.WithContext(ctx).
+		Where("org_id = ? AND expires_unix > ?", orgID, db.NowFunc().Unix()).
+		Order("id ASC").
+		Find(&invs).
+		Error
+}
+
+func (db *orgInvitations) ListByInviteeID(ctx context.Context, inviteeID int64) ([]*OrgInvitation, error) {
+	var invs []*OrgInvitation
+	return invs, db.WithContext(ctx).
+		Where("invitee_id = ? AND expires_unix > ?", inviteeID, db.NowFunc().Unix()).
+		Order("id ASC").
+		Find(&invs).
+		Error
+}
+
+func (db *orgInvitations) Accept(ctx context.Context, inviteeID, id int64) error {
+	inv, err := db.GetByID(ctx, inviteeID, id)
+	if err != nil {
+		return err
+	}
+
+	invitee, err := NewUsersStore(db.DB).GetByID(ctx, inviteeID)
+	if err != nil {
+		return errors.Wrap(err, "get invitee")
+	}
+
+	added := false
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Deleting the invitation before adding the membership claims it, so that the
+		// same invitation can't be accepted twice by concurrent requests.
+		result := tx.Where("id = ? AND expires_unix > ?", inv.ID, tx.NowFunc().Unix()).Delete(new(OrgInvitation))
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "delete invitation")
+		} else if result.RowsAffected == 0 {
+			return ErrOrgInvitationNotExist{args: errutil.Args{"inviteeID": inviteeID, "id": id}}
+		}
+
+		err := tx.Where("uid = ? AND org_id = ?", inviteeID, inv.OrgID).First(new(OrgUser)).Error
+		if err == nil {
+			return nil // Already a member
+		} else if err != gorm.ErrRecordNotFound {
+			return errors.Wrap(err, "get organization user")
+		}
+
+		err = tx.Create(&OrgUser{Uid: inviteeID, OrgID: inv.OrgID}).Error
+		if err != nil {
+			return errors.Wrap(err, "create organization user")
+		}
+		err = tx.Model(new(User)).
+			Where("id = ?", inv.OrgID).
+			UpdateColumn("num_members", gorm.Expr("num_members + 1")).
+			Error
+		if err != nil {
+			return errors.Wrap(err, "increase number of members")
+		}
+		added = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if added {
+		membershipCache.invalidateOrg(inv.OrgID, inviteeID)
+		prepareMembershipWebhooks(invitee, inv.OrgID, inviteeID, HOOK_MEMBERSHIP_ADDED)
+	}
+	return nil
+}
+
+func (db *orgInvitations) Decline(ctx context.Context, inviteeID, id int64) error {
+	inv, err := db.GetByID(ctx, inviteeID, id)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Delete(inv).Error
+}
+
+func (db *orgInvitations) DeleteByID(ctx context.Context, orgID, id int64) error {
+	result := db.WithContext(ctx).Where("id = ? AND org_id = ?", id, orgID).Delete(new(OrgInvitation))
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrOrgInvitationNotExist{args: errutil.Args{"orgID": orgID, "id": id}}
+	}
+	return nil
+}
+
+func (db *orgInvitations) DeleteExpired(ctx context.Context) (int64, error) {
+	result := db.WithContext(ctx).Where("expires_unix <= ?", db.NowFunc().Unix()).Delete(new(OrgInvitation))
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpiredOrgInvitations deletes all expired invitations of organization
+// members.
+func DeleteExpiredOrgInvitations() {
+	if taskStatusTable.IsRunning(_DELETE_EXPIRED_ORG_INVITATIONS) {
+		return
+	}
+	taskStatusTable.Start(_DELETE_EXPIRED_ORG_INVITATIONS)
+	defer taskStatusTable.Stop(_DELETE_EXPIRED_ORG_INVITATIONS)
+
+	log.Trace("Doing: DeleteExpiredOrgInvitations")
+
+	count, err := OrgInvitations.DeleteExpired(context.Background())
+	if err != nil {
+		log.Error("Failed to delete expired organization invitations: %v", err)
+		return
+	}
+	log.Trace("Deleted %d expired organization invitations", count)
+}
@@ -0,0 +1,235 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestOrgInvitations(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MemberInvitationLifetime: time.Hour})
+	tables := []any{new(OrgInvitation)}
+	db := &orgInvitations{
+		DB: dbtest.NewDB(t, "orgInvitations", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *orgInvitations)
+	}{
+		{"Create", orgInvitationsCreate},
+		{"List", orgInvitationsList},
+		{"Decline", orgInvitationsDecline},
+		{"DeleteByID", orgInvitationsDeleteByID},
+		{"DeleteExpired", orgInvitationsDeleteExpired},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+// createExpiredOrgInvitation creates an invitation that has already expired.
+func createExpiredOrgInvitation(t *testing.T, db *orgInvitations, orgID, inviteeID int64) *OrgInvitation {
+	now := db.NowFunc().Unix()
+	inv := &OrgInvitation{
+		OrgID:       orgID,
+		InviterID:   1,
+		InviteeID:   inviteeID,
+		CreatedUnix: now - 3600,
+		ExpiresUnix: now - 1,
+	}
+	err := db.DB.Create(inv).Error
+	require.NoError(t, err)
+	return inv
+}
+
+func orgInvitationsCreate(t *testing.T, db *orgInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, db.NowFunc().Unix(), inv.CreatedUnix)
+	assert.Equal(t, inv.CreatedUnix+3600, inv.ExpiresUnix)
+
+	// Inviting the same user again should fail while the invitation is pending
+	_, err = db.Create(ctx, 1, 1, 2)
+	wantErr := ErrOrgInvitationAlreadyExist{args: errutil.Args{"orgID": int64(1), "inviteeID": int64(2)}}
+	assert.Equal(t, wantErr, err)
+
+	// An expired invitation should be replaced
+	createExpiredOrgInvitation(t, db, 2, 2)
+	got, err := db.Create(ctx, 2, 1, 2)
+	require.NoError(t, err)
+	assert.Greater(t, got.ExpiresUnix, db.NowFunc().Unix())
+}
+
+func orgInvitationsList(t *testing.T, db *orgInvitations) {
+	ctx := context.Background()
+
+	inv1, err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+	inv2, err := db.Create(ctx, 1, 1, 3)
+	require.NoError(t, err)
+	inv3, err := db.Create(ctx, 2, 1, 2)
+	require.NoError(t, err)
+	createExpiredOrgInvitation(t, db, 3, 2)
+
+	invs, err := db.ListByOrgID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, invs, 2)
+	assert.Equal(t, inv1.ID, invs[0].ID)
+	assert.Equal(t, inv2.ID, invs[1].ID)
+
+	invs, err = db.ListByInviteeID(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, invs, 2)
+	assert.Equal(t, inv1.ID, invs[0].ID)
+	assert.Equal(t, inv3.ID, invs[1].ID)
+}
+
+func orgInvitationsDecline(t *testing.T, db *orgInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+
+	// Only the invitee can decline
+	err = db.Decline(ctx, 3, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+
+	err = db.Decline(ctx, 2, inv.ID)
+	require.NoError(t, err)
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+
+	// Expired invitations can't be declined
+	expired := createExpiredOrgInvitation(t, db, 2, 2)
+	err = db.Decline(ctx, 2, expired.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+}
+
+func orgInvitationsDeleteByID(t *testing.T, db *orgInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+
+	// Invitations of other organizations can't be deleted
+	err = db.DeleteByID(ctx, 2, inv.ID)
+	wantErr := ErrOrgInvitationNotExist{args: errutil.Args{"orgID": int64(2), "id": inv.ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 1, inv.ID)
+	require.NoError(t, err)
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+}
+
+func orgInvitationsDeleteExpired(t *testing.T, db *orgInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2)
+	require.NoError(t, err)
+	createExpiredOrgInvitation(t, db, 2, 2)
+	createExpiredOrgInvitation(t, db, 3, 2)
+
+	count, err := db.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	var total int64
+	err = db.Model(new(OrgInvitation)).Count(&total).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	require.NoError(t, err)
+}
+
+func TestOrgInvitations_Accept(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MemberInvitationLifetime: time.Hour})
+	db := &orgInvitations{
+		DB: dbtest.NewDB(t, "orgInvitationsAccept", new(OrgInvitation), new(User), new(EmailAddress), new(OrgUser)),
+	}
+	ctx := context.Background()
+
+	org := &User{ID: 1, LowerName: "acme", Name: "acme", Type: UserTypeOrganization}
+	invitee := &User{ID: 2, LowerName: "bob", Name: "bob"}
+	err := db.DB.Create([]*User{org, invitee}).Error
+	require.NoError(t, err)
+
+	isMember := func(t *testing.T, orgID, userID int64) bool {
+		t.Helper()
+		var count int64
+		err := db.Model(new(OrgUser)).Where("org_id = ? AND uid = ?", orgID, userID).Count(&count).Error
+		require.NoError(t, err)
+		return count > 0
+	}
+	numMembers := func(t *testing.T) int {
+		t.Helper()
+		got, err := NewUsersStore(db.DB).GetByID(ctx, org.ID)
+		require.NoError(t, err)
+		return got.NumMembers
+	}
+
+	inv, err := db.Create(ctx, org.ID, 3, invitee.ID)
+	require.NoError(t, err)
+
+	// Only the invitee can accept
+	err = db.Accept(ctx, 3, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+	assert.False(t, isMember(t, org.ID, invitee.ID))
+
+	// Accepting adds the membership
+	err = db.Accept(ctx, invitee.ID, inv.ID)
+	require.NoError(t, err)
+	assert.True(t, isMember(t, org.ID, invitee.ID))
+	assert.Equal(t, 1, numMembers(t))
+
+	// An accepted invitation is removed
+	err = db.Accept(ctx, invitee.ID, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+
+	// Accepting as an existing member only removes the invitation
+	inv, err = db.Create(ctx, org.ID, 3, invitee.ID)
+	require.NoError(t, err)
+	err = db.Accept(ctx, invitee.ID, inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, numMembers(t))
+	_, err = db.GetByID(ctx, invitee.ID, inv.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+
+	// Expired invitations are not accepted
+	expired := createExpiredOrgInvitation(t, db, 4, invitee.ID)
+	err = db.Accept(ctx, invitee.ID, expired.ID)
+	assert.True(t, IsErrOrgInvitationNotExist(err))
+	assert.False(t, isMember(t, 4, invitee.ID))
+}
@@ -0,0 +1,524 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptoutil"
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/netutil"
+)
+
+// OrgMirrorsStore is the persistent interface for mirrors of upstream
+// organizations.
+type OrgMirrorsStore interface {
+	// Create creates a new mirror of the upstream organization for the
+	// organization, the password of the upstream is encrypted with the key. It
+	// returns ErrOrgMirrorAlreadyExist when the upstream organization is already
+	// mirrored by the organization.
+	Create(ctx context.Context, orgID, doerID int64, key string, opts CreateOrgMirrorOptions) (*OrgMirror, error)
+	// GetByID returns the mirror of upstream organization with given ID of the
+	// organization. It returns ErrOrgMirrorNotExist when not found.
+	GetByID(ctx context.Context, orgID, id int64) (*OrgMirror, error)
+	// List returns all mirrors of upstream organizations of the organization.
+	List(ctx context.Context, orgID int64) ([]*OrgMirror, error)
+	// ListAll returns all mirrors of upstream organizations.
+	ListAll(ctx context.Context) ([]*OrgMirror, error)
+	// Touch updates the last scanned time of the mirror to the current time.
+	Touch(ctx context.Context, id int64) error
+	// DeleteByID deletes the mirror of upstream organization with given ID of the
+	// organization. Repositories that have been mirrored are not affected.
+	DeleteByID(ctx context.Context, orgID, id int64) error
+}
+
+var OrgMirrors OrgMirrorsStore
+
+var _ OrgMirrorsStore = (*orgMirrors)(nil)
+
+type orgMirrors struct {
+	*gorm.DB
+}
+
+// NewOrgMirrorsStore returns a persistent interface for mirrors of upstream
+// organizations with given database connection.
+func NewOrgMirrorsStore(db *gorm.DB) OrgMirrorsStore {
+	return &orgMirrors{DB: db}
+}
+
+// OrgMirror is a mirror of an upstream organization, every repository of the
+// upstream organization is mirrored as a pull mirror of the organization.
+type OrgMirror struct {
+	ID    int64 `gorm:"primaryKey"`
+	OrgID int64 `gorm:"uniqueIndex:org_mirror_org_upstream_unique;not null"`
+	// The web URL of the upstream organization, e.g. https://github.com/gogs.
+	UpstreamURL  string `gorm:"type:VARCHAR(255);uniqueIndex:org_mirror_org_upstream_unique;not null"`
+	AuthUsername string
+	// The base64-encoded password of the upstream encrypted by AES-GCM, empty
+	// when no password is set.
+	EncryptedAuthPassword string
+	// The prefix prepended to names of upstream repositories as local names.
+	NamePrefix  string
+	CreatedByID int64
+
+	Created         time.Time `gorm:"-" json:"-"`
+	CreatedUnix     int64
+	LastScanned     time.Time `gorm:"-" json:"-"`
+	LastScannedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (m *OrgMirror) BeforeCreate(tx *gorm.DB) error {
+	if m.CreatedUnix == 0 {
+		m.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (m *OrgMirror) AfterFind(_ *gorm.DB) error {
+	m.Created = time.Unix(m.CreatedUnix, 0).Local()
+	if m.LastScannedUnix > 0 {
+		m.LastScanned = time.Unix(m.LastScannedUnix, 0).Local()
+	}
+	return nil
+}
+
+type CreateOrgMirrorOptions struct {
+	UpstreamURL  string
+	AuthUsername string
+	AuthPassword string
+	NamePrefix   string
+}
+
+type ErrOrgMirrorAlreadyExist struct {
+	args errutil.Args
+}
+
+// IsErrOrgMirrorAlreadyExist returns true if the underlying error has the type
+// ErrOrgMirrorAlreadyExist.
+func IsErrOrgMirrorAlreadyExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrOrgMirrorAlreadyExist)
+	return ok
+}
+
+func (err ErrOrgMirrorAlreadyExist) Error() string {
+	return fmt.Sprintf("mirror of upstream organization already exists: %v", err.args)
+}
+
+func (db *orgMirrors) Create(ctx context.Context, orgID, doerID int64, key string, opts CreateOrgMirrorOptions) (*OrgMirror, error) {
+	upstreamURL := strings.TrimRight(opts.UpstreamURL, "/")
+	if _, err := upstreamReposAPIURL(upstreamURL); err != nil {
+		return nil, ErrInvalidCloneAddr{IsURLError: true}
+	}
+
+	err := db.WithContext(ctx).Where("org_id = ? AND upstream_url = ?", orgID, upstreamURL).First(new(OrgMirror)).Error
+	if err == nil {
+		return nil, ErrOrgMirrorAlreadyExist{args: errutil.Args{"orgID": orgID, "upstreamURL": upstreamURL}}
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	var encryptedPassword string
+	if opts.AuthPassword != "" {
+		encrypted, err := cryptoutil.AESGCMEncrypt(cryptoutil.MD5Bytes(key), []byte(opts.AuthPassword))
+		if err != nil {
+			return nil, errors.Wrap(err, "encrypt password")
+		}
+		encryptedPassword = base64.StdEncoding.EncodeToString(encrypted)
+	}
+
+	m := &OrgMirror{
+		OrgID:                 orgID,
+		UpstreamURL:           upstreamURL,
+		AuthUsername:          opts.AuthUsername,
+		EncryptedAuthPassword: encryptedPassword,
+		NamePrefix:            opts.NamePrefix,
+		CreatedByID:           doerID,
+	}
+	if err = db.WithContext(ctx).Create(m).Error; err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _ errutil.NotFound = (*ErrOrgMirrorNotExist)(nil)
+
+type ErrOrgMirrorNotExist struct {
+	args errutil.Args
+}
+
+// IsErrOrgMirrorNotExist returns true if the underlying error has the type
+// ErrOrgMirrorNotExist.
+func IsErrOrgMirrorNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrOrgMirrorNotExist)
+	return ok
+}
+
+func (err ErrOrgMirrorNotExist) Error() string {
+	return fmt.Sprintf("mirror of upstream organization does not exist: %v", err.args)
+}
+
+func (ErrOrgMirrorNotExist) NotFound() bool {
+	return true
+}
+
+func (db *orgMirrors) GetByID(ctx context.Context, orgID, id int64) (*OrgMirror, error) {
+	m := new(OrgMirror)
+	err := db.WithContext(ctx).Where("org_id = ? AND id = ?", orgID, id).First(m).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrOrgMirrorNotExist{args: errutil.Args{"orgID": orgID, "id": id}}
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+func (db *orgMirrors) List(ctx context.Context, orgID int64) ([]*OrgMirror, error) {
+	var mirrors []*OrgMirror
+	return mirrors, db.WithContext(ctx).Where("org_id = ?", orgID).Order("id ASC").Find(&mirrors).Error
+}
+
+func (db *orgMirrors) ListAll(ctx context.Context) ([]*OrgMirror, error) {
+	var mirrors []*OrgMirror
+	return mirrors, db.WithContext(ctx).Order("id ASC").Find(&mirrors).Error
+}
+
+func (db *orgMirrors) Touch(ctx context.Context, id int64) error {
+	return db.WithContext(ctx).
+		Model(new(OrgMirror)).
+		Where("id = ?", id).
+		UpdateColumn("last_scanned_unix", db.NowFunc().Unix()).
+		Error
+}
+
+func (db *orgMirrors) DeleteByID(ctx context.Context, orgID, id int64) error {
+	result := db.WithContext(ctx).Where("org_id = ? AND id = ?", orgID, id).Delete(new(OrgMirror))
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrOrgMirrorNotExist{args: errutil.Args{"orgID": orgID, "id": id}}
+	}
+	return nil
+}
+
+// authPassword returns the password of the upstream decrypted with the key.
+func (m *OrgMirror) authPassword(key string) (string, error) {
+	if m.EncryptedAuthPassword == "" {
+		return "", nil
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(m.EncryptedAuthPassword)
+	if err != nil {
+		return "", errors.Wrap(err, "decode password")
+	}
+	decrypted, err := cryptoutil.AESGCMDecrypt(cryptoutil.MD5Bytes(key), encrypted)
+	if err != nil {
+		return "", errors.Wrap(err, "decrypt password")
+	}
+	return string(decrypted), nil
+}
+
+// UpstreamRepo is a repository of an upstream organization.
+type UpstreamRepo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CloneURL    string `json:"clone_url"`
+	Private     bool   `json:"private"`
+}
+
+// upstreamReposAPIURL returns the API endpoint for listing repositories of the
+// upstream organization, with the page number to be appended. The upstream is
+// either GitHub or a Gogs-compatible instance.
+func upstreamReposAPIURL(upstreamURL string) (string, error) {
+	u, err := url.Parse(upstreamURL)
+	if err != nil {
+		return "", errors.Wrap(err, "parse upstream URL")
+	} else if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.Errorf("unsupported scheme %q of upstream URL", u.Scheme)
+	}
+
+	subpath, org := path.Split(strings.Trim(u.Path, "/"))
+	if org == "" {
+		return "", errors.New("no organization in upstream URL")
+	}
+
+	if strings.EqualFold(u.Host, "github.com") {
+		return fmt.Sprintf("https://api.github.com/orgs/%s/repos?per_page=100&page=", url.PathEscape(org)), nil
+	}
+	u.Path = "/" + path.Join(subpath, "api/v1/orgs", org, "repos")
+	u.RawQuery = "limit=50&page="
+	return u.String(), nil
+}
+
+const (
+	// orgMirrorMaxPages is the maximum number of pages to list repositories of an
+	// upstream organization, to stop upstreams that never run out of pages.
+	orgMirrorMaxPages = 100
+	// orgMirrorMaxRepos is the maximum number of repositories of an upstream
+	// organization to be mirrored.
+	orgMirrorMaxRepos = 5000
+)
+
+// listUpstreamRepos returns all repositories of the upstream organization of
+// the mirror by calling its API. It returns an error when the upstream has more
+// than orgMirrorMaxPages pages or orgMirrorMaxRepos repositories.
+func listUpstreamRepos(ctx context.Context, m *OrgMirror) ([]*UpstreamRepo, error) {
+	u, err := url.Parse(m.UpstreamURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse upstream URL")
+	} else if netutil.IsBlockedLocalHostname(u.Hostname(), conf.Security.LocalNetworkAllowlist) {
+		return nil, errors.Errorf("upstream URL resolved to a blocked local address")
+	}
+
+	apiURL, err := upstreamReposAPIURL(m.UpstreamURL)
+	if err != nil {
+		return nil, err
+	}
+	password, err := m.authPassword(conf.Security.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: time.Minute}
+	seen := make(map[string]bool)
+	var repos []*UpstreamRepo
+	for page := 1; page <= orgMirrorMaxPages; page++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%d", apiURL, page), nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "new request")
+		}
+		req.Header.Set("Accept", "application/json")
+		if m.AuthUsername != "" || password != "" {
+			req.SetBasicAuth(m.AuthUsername, password)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "do request")
+		}
+
+		var pageRepos []*UpstreamRepo
+		if resp.StatusCode != http.StatusOK {
+			err = errors.Errorf("unexpected status code %d", resp.StatusCode)
+		} else {
+			err = jsoniter.NewDecoder(resp.Body).Decode(&pageRepos)
+		}
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "list repositories of page %d", page)
+		}
+
+		// Some APIs (e.g. Gogs) do not paginate and return all repositories
+		// regardless of the page number, stop when nothing new is returned.
+		found := false
+		for _, r := range pageRepos {
+			if seen[r.Name] {
+				continue
+			}
+			seen[r.Name] = true
+			found = true
+			repos = append(repos, r)
+		}
+		if !found {
+			return repos, nil
+		} else if len(repos) > orgMirrorMaxRepos {
+			return nil, errors.Errorf("upstream has more than %d repositories", orgMirrorMaxRepos)
+		}
+	}
+	return nil, errors.Errorf("upstream has more than %d pages of repositories", orgMirrorMaxPages)
+}
+
+const (
+	// OrgMirrorRepoCreated means the mirror of the upstream repository is created.
+	OrgMirrorRepoCreated = "created"
+	// OrgMirrorRepoExists means the upstream repository is already mirrored.
+	OrgMirrorRepoExists = "exists"
+	// OrgMirrorRepoConflict means a repository that is not a mirror already
+	// exists with the local name, and the upstream repository is skipped.
+	OrgMirrorRepoConflict = "conflict"
+	// OrgMirrorRepoFailed means failed to create the mirror of the upstream
+	// repository.
+	OrgMirrorRepoFailed = "failed"
+)
+
+// OrgMirrorRepoStatus is the status of an upstream repository after syncing a
+// mirror of upstream organization.
+type OrgMirrorRepoStatus struct {
+	UpstreamName string
+	Name         string
+	Status       string
+	Error        string
+}
+
+type orgMirrorSyncer struct {
+	// list returns all repositories of the upstream organization.
+	list func(ctx context.Context, m *OrgMirror) ([]*UpstreamRepo, error)
+	// get returns the local repository of the organization with given name.
+	get func(ctx context.Context, orgID int64, name string) (*Repository, error)
+	// create creates a pull mirror of the upstream repository with given name.
+	create func(ctx context.Context, m *OrgMirror, upstream *UpstreamRepo, name string) error
+}
+
+// sync creates pull mirrors for repositories of the upstream organization that
+// have not been mirrored yet.
+func (s *orgMirrorSyncer) sync(ctx context.Context, m *OrgMirror) ([]*OrgMirrorRepoStatus, error) {
+	upstreams, err := s.list(ctx, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "list upstream repositories")
+	}
+
+	statuses := make([]*OrgMirrorRepoStatus, 0, len(upstreams))
+	for _, upstream := range upstreams {
+		status := &OrgMirrorRepoStatus{
+			UpstreamName: upstream.Name,
+			Name:         m.NamePrefix + upstream.Name,
+		}
+		statuses = append(statuses, status)
+
+		repo, err := s.get(ctx, m.OrgID, status.Name)
+		if err == nil {
+			if repo.IsMirror {
+				status.Status = OrgMirrorRepoExists
+			} else {
+				status.Status = OrgMirrorRepoConflict
+			}
+			continue
+		} else if !IsErrRepoNotExist(err) {
+			status.Status = OrgMirrorRepoFailed
+			status.Error = err.Error()
+			continue
+		}
+
+		err = s.create(ctx, m, upstream, status.Name)
+		if err != nil {
+			status.Status = OrgMirrorRepoFailed
+			status.Error = err.Error()
+			continue
+		}
+		status.Status = OrgMirrorRepoCreated
+	}
+	return statuses, nil
+}
+
+// createUpstreamMirror creates a pull mirror of the upstream repository in the
+// organization of the mirror of upstream organization.
+func createUpstreamMirror(ctx context.Context, m *OrgMirror, upstream *UpstreamRepo, name string) error {
+	org, err := Users.GetByID(ctx, m.OrgID)
+	if err != nil {
+		return errors.Wrap(err, "get organization")
+	}
+	doer, err := Users.GetByID(ctx, m.CreatedByID)
+	if err != nil {
+		return errors.Wrap(err, "get creator")
+	}
+
+	remoteAddr, err := url.Parse(upstream.CloneURL)
+	if err != nil {
+		return errors.Wrap(err, "parse clone URL")
+	} else if remoteAddr.Scheme != "http" && remoteAddr.Scheme != "https" {
+		return errors.Errorf("unsupported scheme %q of clone URL", remoteAddr.Scheme)
+	} else if netutil.IsBlockedLocalHostname(remoteAddr.Hostname(), conf.Security.LocalNetworkAllowlist) {
+		return errors.New("clone URL resolved to a blocked local address")
+	}
+	password, err := m.authPassword(conf.Security.SecretKey)
+	if err != nil {
+		return err
+	}
+	if m.AuthUsername != "" || password != "" {
+		remoteAddr.User = url.UserPassword(m.AuthUsername, password)
+	}
+
+	repo, err := MigrateRepository(doer, org, MigrateRepoOptions{
+		Name:        name,
+		Description: upstream.Description,
+		IsPrivate:   upstream.Private || !org.IsPublicRepoAllowed(),
+		IsMirror:    true,
+		RemoteAddr:  remoteAddr.String(),
+	})
+	if err != nil {
+		if repo != nil {
+			if errDelete := DeleteRepository(org.ID, repo.ID); errDelete != nil {
+				log.Error("Failed to delete repository [%d]: %v", repo.ID, errDelete)
+			}
+		}
+		return errors.New(HandleMirrorCredentials(err.Error(), true))
+	}
+	return nil
+}
+
+var defaultOrgMirrorSyncer = &orgMirrorSyncer{
+	list: listUpstreamRepos,
+	get: func(ctx context.Context, orgID int64, name string) (*Repository, error) {
+		return Repos.GetByName(ctx, orgID, name)
+	},
+	create: createUpstreamMirror,
+}
+
+// SyncOrgMirror creates pull mirrors for repositories of the upstream
+// organization that have not been mirrored yet, and returns the status of each
+// upstream repository. Created mirrors are then kept in sync by the mirror
+// syncer.
+func SyncOrgMirror(ctx context.Context, m *OrgMirror) ([]*OrgMirrorRepoStatus, error) {
+	statuses, err := defaultOrgMirrorSyncer.sync(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = OrgMirrors.Touch(ctx, m.ID); err != nil {
+		log.Error("Failed to touch mirror of upstream organization [%d]: %v", m.ID, err)
+	}
+	return statuses, nil
+}
+
+// ScanOrgMirrors syncs all mirrors of upstream organizations to mirror new
+// upstream repositories.
+func ScanOrgMirrors() {
+	if taskStatusTable.IsRunning(_SCAN_ORG_MIRRORS) {
+		return
+	}
+	taskStatusTable.Start(_SCAN_ORG_MIRRORS)
+	defer taskStatusTable.Stop(_SCAN_ORG_MIRRORS)
+
+	log.Trace("Doing: ScanOrgMirrors")
+
+	ctx := context.Background()
+	mirrors, err := OrgMirrors.ListAll(ctx)
+	if err != nil {
+		log.Error("Failed to list mirrors of upstream organizations: %v", err)
+		return
+	}
+
+	for _, m := range mirrors {
+		statuses, err := SyncOrgMirror(ctx, m)
+		if err != nil {
+			log.Error("Failed to sync mirror of upstream organization %q [%d]: %v", m.UpstreamURL, m.ID, err)
+			continue
+		}
+
+		for _, status := range statuses {
+			switch status.Status {
+			case OrgMirrorRepoCreated:
+				log.Trace("Mirror of upstream repository %q created: %s", status.UpstreamName, status.Name)
+			case OrgMirrorRepoFailed:
+				log.Error("Failed to mirror upstream repository %q of %q: %s", status.UpstreamName, m.UpstreamURL, status.Error)
+			}
+		}
+	}
+}
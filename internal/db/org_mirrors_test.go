@@ -0,0 +1,309 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptoutil"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestOrgMirrors(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(OrgMirror)}
+	db := &orgMirrors{
+		DB: dbtest.NewDB(t, "orgMirrors", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *orgMirrors)
+	}{
+		{"Create", orgMirrorsCreate},
+		{"GetByID", orgMirrorsGetByID},
+		{"List", orgMirrorsList},
+		{"Touch", orgMirrorsTouch},
+		{"DeleteByID", orgMirrorsDeleteByID},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func orgMirrorsCreate(t *testing.T, db *orgMirrors) {
+	ctx := context.Background()
+
+	m, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{
+		UpstreamURL:  "https://github.com/gogs/",
+		AuthUsername: "alice",
+		AuthPassword: "123456",
+		NamePrefix:   "gogs-",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://github.com/gogs", m.UpstreamURL)
+	assert.Equal(t, int64(1), m.CreatedByID)
+	assert.Equal(t, db.NowFunc().Unix(), m.CreatedUnix)
+
+	// The password should be stored encrypted
+	m, err = db.GetByID(ctx, 2, m.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, m.EncryptedAuthPassword)
+	assert.NotContains(t, m.EncryptedAuthPassword, "123456")
+	password, err := m.authPassword("key")
+	require.NoError(t, err)
+	assert.Equal(t, "123456", password)
+
+	// Mirroring the same upstream organization again should fail
+	_, err = db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	wantErr := ErrOrgMirrorAlreadyExist{args: errutil.Args{"orgID": int64(2), "upstreamURL": "https://github.com/gogs"}}
+	assert.Equal(t, wantErr, err)
+
+	// But it is fine for another organization
+	_, err = db.Create(ctx, 3, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+
+	// The upstream URL must point to an organization
+	_, err = db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com"})
+	assert.Equal(t, ErrInvalidCloneAddr{IsURLError: true}, err)
+}
+
+func orgMirrorsGetByID(t *testing.T, db *orgMirrors) {
+	ctx := context.Background()
+
+	m, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+
+	got, err := db.GetByID(ctx, 2, m.ID)
+	require.NoError(t, err)
+	assert.Equal(t, m.UpstreamURL, got.UpstreamURL)
+	assert.True(t, got.LastScanned.IsZero())
+
+	// Getting the mirror of another organization should fail
+	_, err = db.GetByID(ctx, 3, m.ID)
+	wantErr := ErrOrgMirrorNotExist{args: errutil.Args{"orgID": int64(3), "id": m.ID}}
+	assert.Equal(t, wantErr, err)
+}
+
+func orgMirrorsList(t *testing.T, db *orgMirrors) {
+	ctx := context.Background()
+
+	m1, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+	m2, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://example.com/gogs"})
+	require.NoError(t, err)
+	m3, err := db.Create(ctx, 3, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+
+	mirrors, err := db.List(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, mirrors, 2)
+	assert.Equal(t, m1.ID, mirrors[0].ID)
+	assert.Equal(t, m2.ID, mirrors[1].ID)
+
+	mirrors, err = db.ListAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, mirrors, 3)
+	assert.Equal(t, m3.ID, mirrors[2].ID)
+}
+
+func orgMirrorsTouch(t *testing.T, db *orgMirrors) {
+	ctx := context.Background()
+
+	m, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+
+	err = db.Touch(ctx, m.ID)
+	require.NoError(t, err)
+
+	got, err := db.GetByID(ctx, 2, m.ID)
+	require.NoError(t, err)
+	assert.Equal(t, db.NowFunc().Unix(), got.LastScannedUnix)
+}
+
+func orgMirrorsDeleteByID(t *testing.T, db *orgMirrors) {
+	ctx := context.Background()
+
+	m, err := db.Create(ctx, 2, 1, "key", CreateOrgMirrorOptions{UpstreamURL: "https://github.com/gogs"})
+	require.NoError(t, err)
+
+	// Deleting the mirror of another organization should fail
+	err = db.DeleteByID(ctx, 3, m.ID)
+	wantErr := ErrOrgMirrorNotExist{args: errutil.Args{"orgID": int64(3), "id": m.ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 2, m.ID)
+	require.NoError(t, err)
+
+	_, err = db.GetByID(ctx, 2, m.ID)
+	assert.True(t, IsErrOrgMirrorNotExist(err))
+}
+
+func TestUpstreamReposAPIURL(t *testing.T) {
+	tests := []struct {
+		upstreamURL string
+		want        string
+		wantErr     bool
+	}{
+		{upstreamURL: "https://github.com/gogs", want: "https://api.github.com/orgs/gogs/repos?per_page=100&page="},
+		{upstreamURL: "https://GitHub.com/gogs/", want: "https://api.github.com/orgs/gogs/repos?per_page=100&page="},
+		{upstreamURL: "https://try.gogs.io/gogs", want: "https://try.gogs.io/api/v1/orgs/gogs/repos?limit=50&page="},
+		{upstreamURL: "http://example.com/git/gogs", want: "http://example.com/git/api/v1/orgs/gogs/repos?limit=50&page="},
+		{upstreamURL: "https://example.com", wantErr: true},
+		{upstreamURL: "ssh://example.com/gogs", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.upstreamURL, func(t *testing.T) {
+			got, err := upstreamReposAPIURL(test.upstreamURL)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestOrgMirrorSyncer(t *testing.T) {
+	ctx := context.Background()
+	m := &OrgMirror{ID: 1, OrgID: 2, UpstreamURL: "https://github.com/gogs", NamePrefix: "gogs-"}
+
+	// The "gogs-docs" is already mirrored, and "gogs-website" is a regular
+	// repository created by someone else.
+	localRepos := map[string]*Repository{
+		"gogs-docs":    {Name: "gogs-docs", IsMirror: true},
+		"gogs-website": {Name: "gogs-website"},
+	}
+	var created []string
+	syncer := &orgMirrorSyncer{
+		list: func(context.Context, *OrgMirror) ([]*UpstreamRepo, error) {
+			return []*UpstreamRepo{
+				{Name: "gogs", CloneURL: "https://github.com/gogs/gogs.git"},
+				{Name: "docs", CloneURL: "https://github.com/gogs/docs.git"},
+				{Name: "website", CloneURL: "https://github.com/gogs/website.git"},
+				{Name: "broken", CloneURL: "https://github.com/gogs/broken.git"},
+			}, nil
+		},
+		get: func(_ context.Context, orgID int64, name string) (*Repository, error) {
+			assert.Equal(t, m.OrgID, orgID)
+			repo, ok := localRepos[name]
+			if !ok {
+				return nil, ErrRepoNotExist{args: errutil.Args{"ownerID": orgID, "name": name}}
+			}
+			return repo, nil
+		},
+		create: func(_ context.Context, _ *OrgMirror, upstream *UpstreamRepo, name string) error {
+			if upstream.Name == "broken" {
+				return errors.New("clone: repository not found")
+			}
+			created = append(created, name)
+			localRepos[name] = &Repository{Name: name, IsMirror: true}
+			return nil
+		},
+	}
+
+	statuses, err := syncer.sync(ctx, m)
+	require.NoError(t, err)
+	want := []*OrgMirrorRepoStatus{
+		{UpstreamName: "gogs", Name: "gogs-gogs", Status: OrgMirrorRepoCreated},
+		{UpstreamName: "docs", Name: "gogs-docs", Status: OrgMirrorRepoExists},
+		{UpstreamName: "website", Name: "gogs-website", Status: OrgMirrorRepoConflict},
+		{UpstreamName: "broken", Name: "gogs-broken", Status: OrgMirrorRepoFailed, Error: "clone: repository not found"},
+	}
+	assert.Equal(t, want, statuses)
+	assert.Equal(t, []string{"gogs-gogs"}, created)
+
+	// Syncing again should be idempotent
+	created = nil
+	statuses, err = syncer.sync(ctx, m)
+	require.NoError(t, err)
+	assert.Equal(t, OrgMirrorRepoExists, statuses[0].Status)
+	assert.Empty(t, created)
+
+	t.Run("failed to list upstream repositories", func(t *testing.T) {
+		syncer := &orgMirrorSyncer{
+			list: func(context.Context, *OrgMirror) ([]*UpstreamRepo, error) {
+				return nil, errors.New("unexpected status code 401")
+			},
+		}
+		_, err := syncer.sync(ctx, m)
+		assert.Error(t, err)
+	})
+}
+
+func TestListUpstreamRepos(t *testing.T) {
+	var requests int
+	var pageSize int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		username, password, _ := r.BasicAuth()
+		assert.Equal(t, "alice", username)
+		assert.Equal(t, "123456", password)
+
+		// A hostile upstream that never runs out of new repositories
+		page := r.URL.Query().Get("page")
+		repos := make([]*UpstreamRepo, pageSize)
+		for i := range repos {
+			repos[i] = &UpstreamRepo{Name: fmt.Sprintf("repo-%s-%d", page, i)}
+		}
+		_ = json.NewEncoder(w).Encode(repos)
+	}))
+	t.Cleanup(server.Close)
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	securityOpts := conf.Security
+	securityOpts.SecretKey = "key"
+	securityOpts.LocalNetworkAllowlist = []string{u.Hostname()}
+	conf.SetMockSecurity(t, securityOpts)
+
+	encrypted, err := cryptoutil.AESGCMEncrypt(cryptoutil.MD5Bytes("key"), []byte("123456"))
+	require.NoError(t, err)
+	m := &OrgMirror{
+		UpstreamURL:           server.URL + "/gogs",
+		AuthUsername:          "alice",
+		EncryptedAuthPassword: base64.StdEncoding.EncodeToString(encrypted),
+	}
+
+	t.Run("too many pages", func(t *testing.T) {
+		requests, pageSize = 0, 1
+		_, err := listUpstreamRepos(context.Background(), m)
+		assert.EqualError(t, err, fmt.Sprintf("upstream has more than %d pages of repositories", orgMirrorMaxPages))
+		assert.Equal(t, orgMirrorMaxPages, requests)
+	})
+
+	t.Run("too many repositories", func(t *testing.T) {
+		requests, pageSize = 0, orgMirrorMaxRepos/2
+		_, err := listUpstreamRepos(context.Background(), m)
+		assert.EqualError(t, err, fmt.Sprintf("upstream has more than %d repositories", orgMirrorMaxRepos))
+		assert.Equal(t, 3, requests)
+	})
+}
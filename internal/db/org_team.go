@@ -5,7 +5,6 @@
 package db
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
@@ -25,8 +24,8 @@ type Team struct {
 	Name        string
 	Description string
 	Authorize   AccessMode
-	Repos       []*Repository `xorm:"-" json:"-"`
-	Members     []*User       `xorm:"-" json:"-"`
+	Repos       []*Repository `xorm:"-" json:"-" gorm:"-"`
+	Members     []*User       `xorm:"-" json:"-" gorm:"-"`
 	NumRepos    int
 	NumMembers  int
 }
@@ -88,15 +87,15 @@ func (t *Team) GetMembers() (err error) {
 	return t.getMembers(x)
 }
 
-// AddMember adds new membership of the team to the organization,
+// AddMember adds new membership of the team to the organization by the doer,
 // the user will have membership to the organization automatically when needed.
-func (t *Team) AddMember(uid int64) error {
-	return AddTeamMember(t.OrgID, t.ID, uid)
+func (t *Team) AddMember(doer *User, uid int64) error {
+	return AddTeamMember(doer, t.OrgID, t.ID, uid)
 }
 
-// RemoveMember removes member from team of organization.
-func (t *Team) RemoveMember(uid int64) error {
-	return RemoveTeamMember(t.OrgID, t.ID, uid)
+// RemoveMember removes member from team of organization by the doer.
+func (t *Team) RemoveMember(doer *User, uid int64) error {
+	return RemoveTeamMember(doer, t.OrgID, t.ID, uid)
 }
 
 func (t *Team) hasRepository(e Engine, repoID int64) bool {
@@ -268,7 +267,8 @@ func NewTeam(t *Team) error {
 		return err
 	}
 
-	has, err := x.Id(t.OrgID).Get(new(User))
+	org := new(User)
+	has, err := x.Id(t.OrgID).Get(org)
 	if err != nil {
 		return err
 	} else if !has {
@@ -290,17 +290,67 @@ func NewTeam(t *Team) error {
 		return err
 	}
 
-	if _, err = sess.Insert(t); err != nil {
+	// Update organization number of teams.
+	ok, err := incrNumWithinLimit(sess, "user", "num_teams", t.OrgID, org.maxNumTeams())
+	if err != nil {
 		return err
+	} else if !ok {
+		return ErrReachLimitOfTeams{Limit: org.maxNumTeams()}
 	}
 
-	// Update organization number of teams.
-	if _, err = sess.Exec("UPDATE `user` SET num_teams=num_teams+1 WHERE id = ?", t.OrgID); err != nil {
+	if _, err = sess.Insert(t); err != nil {
 		return err
 	}
 	return sess.Commit()
 }
 
+// incrNumWithinLimit increments the counter column of the row with given ID in
+// the table. The limit is checked in the same statement so that concurrent
+// increments cannot exceed it, and it returns false when the limit has been
+// reached. A negative limit means no limit.
+func incrNumWithinLimit(e Engine, table, column string, id int64, limit int) (bool, error) {
+	if limit <= -1 {
+		_, err := e.Exec(fmt.Sprintf("UPDATE `%s` SET %s=%s+1 WHERE id = ?", table, column, column), id)
+		return err == nil, err
+	}
+
+	result, err := e.Exec(fmt.Sprintf("UPDATE `%s` SET %s=%s+1 WHERE id = ? AND %s < ?", table, column, column, column), id, limit)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+type ErrReachLimitOfTeams struct {
+	Limit int
+}
+
+func IsErrReachLimitOfTeams(err error) bool {
+	_, ok := err.(ErrReachLimitOfTeams)
+	return ok
+}
+
+func (err ErrReachLimitOfTeams) Error() string {
+	return fmt.Sprintf("organization has reached maximum limit of teams [limit: %d]", err.Limit)
+}
+
+type ErrReachLimitOfTeamMembers struct {
+	Limit int
+}
+
+func IsErrReachLimitOfTeamMembers(err error) bool {
+	_, ok := err.(ErrReachLimitOfTeamMembers)
+	return ok
+}
+
+func (err ErrReachLimitOfTeamMembers) Error() string {
+	return fmt.Sprintf("team has reached maximum limit of members [limit: %d]", err.Limit)
+}
+
 var _ errutil.NotFound = (*ErrTeamNotExist)(nil)
 
 type ErrTeamNotExist struct {
@@ -410,15 +460,35 @@ func UpdateTeam(t *Team, authChanged bool) (err error) {
 	return sess.Commit()
 }
 
-// DeleteTeam deletes given team.
+type ErrCannotDeleteOwnerTeam struct {
+	args errutil.Args
+}
+
+// IsErrCannotDeleteOwnerTeam returns true if the underlying error has the type
+// ErrCannotDeleteOwnerTeam.
+func IsErrCannotDeleteOwnerTeam(err error) bool {
+	_, ok := err.(ErrCannotDeleteOwnerTeam)
+	return ok
+}
+
+func (err ErrCannotDeleteOwnerTeam) Error() string {
+	return fmt.Sprintf("the owner team cannot be deleted: %v", err.args)
+}
+
+// DeleteTeam deletes given team. It returns ErrCannotDeleteOwnerTeam if the
+// team is the owner team of the organization.
 // It's caller's responsibility to assign organization ID.
 func DeleteTeam(t *Team) error {
+	if t.IsOwnerTeam() {
+		return ErrCannotDeleteOwnerTeam{args: errutil.Args{"teamID": t.ID, "orgID": t.OrgID}}
+	}
+
 	if err := t.GetRepositories(); err != nil {
 		return err
 	}
 
 	// Get organization.
-	org, err := Users.GetByID(context.TODO(), t.OrgID)
+	org, err := getUserByID(x, t.OrgID)
 	if err != nil {
 		return err
 	}
@@ -450,7 +520,12 @@ func DeleteTeam(t *Team) error {
 		return err
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	membershipCache.reset()
+	return nil
 }
 
 // ___________                    ____ ___
@@ -475,7 +550,9 @@ func isTeamMember(e Engine, orgID, teamID, uid int64) bool {
 
 // IsTeamMember returns true if given user is a member of team.
 func IsTeamMember(orgID, teamID, uid int64) bool {
-	return isTeamMember(x, orgID, teamID, uid)
+	return membershipCache.load(teamMembershipKey(teamID, uid), func() bool {
+		return isTeamMember(x, orgID, teamID, uid)
+	})
 }
 
 func getTeamMembers(e Engine, teamID int64) (_ []*User, err error) {
@@ -521,23 +598,28 @@ func GetUserTeams(orgID, userID int64) ([]*Team, error) {
 	return getUserTeams(x, orgID, userID)
 }
 
-// AddTeamMember adds new membership of given team to given organization,
-// the user will have membership to given organization automatically when needed.
-func AddTeamMember(orgID, teamID, userID int64) error {
+// AddTeamMember adds new membership of given team to given organization by the
+// doer, the user will have membership to given organization automatically when
+// needed.
+func AddTeamMember(doer *User, orgID, teamID, userID int64) error {
 	if IsTeamMember(orgID, teamID, userID) {
 		return nil
 	}
 
-	if err := AddOrgUser(orgID, userID); err != nil {
+	// Get team and its repositories.
+	t, err := GetTeamByID(teamID)
+	if err != nil {
 		return err
 	}
 
-	// Get team and its repositories.
-	t, err := GetTeamByID(teamID)
+	org, err := getUserByID(x, orgID)
 	if err != nil {
 		return err
 	}
-	t.NumMembers++
+	limit := org.maxNumTeamMembers()
+	if limit > -1 && t.NumMembers >= limit {
+		return ErrReachLimitOfTeamMembers{Limit: limit}
+	}
 
 	if err = t.GetRepositories(); err != nil {
 		return err
@@ -549,6 +631,20 @@ func AddTeamMember(orgID, teamID, userID int64) error {
 		return err
 	}
 
+	// The limit is checked again within the transaction to be safe with concurrent
+	// operations, which also rolls back joining the organization.
+	ok, err := incrNumWithinLimit(sess, "team", "num_members", t.ID, limit)
+	if err != nil {
+		return err
+	} else if !ok {
+		return ErrReachLimitOfTeamMembers{Limit: limit}
+	}
+
+	joinedOrg, err := addOrgUser(sess, orgID, userID)
+	if err != nil {
+		return err
+	}
+
 	tu := &TeamUser{
 		UID:    userID,
 		OrgID:  orgID,
@@ -556,8 +652,6 @@ func AddTeamMember(orgID, teamID, userID int64) error {
 	}
 	if _, err = sess.Insert(tu); err != nil {
 		return err
-	} else if _, err = sess.ID(t.ID).Update(t); err != nil {
-		return err
 	}
 
 	// Give access to team repositories.
@@ -580,7 +674,17 @@ func AddTeamMember(orgID, teamID, userID int64) error {
 		return err
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	if joinedOrg {
+		membershipCache.invalidateOrg(orgID, userID)
+		prepareMembershipWebhooks(doer, orgID, userID, HOOK_MEMBERSHIP_ADDED)
+	}
+	membershipCache.invalidateTeam(teamID, userID)
+	prepareTeamWebhooks(doer, t, userID, HOOK_MEMBERSHIP_ADDED)
+	return nil
 }
 
 func removeTeamMember(e Engine, orgID, teamID, uid int64) error {
@@ -645,8 +749,18 @@ func removeTeamMember(e Engine, orgID, teamID, uid int64) error {
 	return nil
 }
 
-// RemoveTeamMember removes member from given team of given organization.
-func RemoveTeamMember(orgID, teamID, uid int64) error {
+// RemoveTeamMember removes member from given team of given organization by the
+// doer.
+func RemoveTeamMember(doer *User, orgID, teamID, uid int64) error {
+	if !isTeamMember(x, orgID, teamID, uid) {
+		return nil
+	}
+
+	t, err := GetTeamByID(teamID)
+	if err != nil {
+		return err
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
@@ -655,7 +769,13 @@ func RemoveTeamMember(orgID, teamID, uid int64) error {
 	if err := removeTeamMember(sess, orgID, teamID, uid); err != nil {
 		return err
 	}
-	return sess.Commit()
+	if err := sess.Commit(); err != nil {
+		return err
+	}
+
+	membershipCache.invalidateTeam(teamID, uid)
+	prepareTeamWebhooks(doer, t, uid, HOOK_MEMBERSHIP_REMOVED)
+	return nil
 }
 
 // ___________                  __________
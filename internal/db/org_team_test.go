@@ -0,0 +1,198 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestNewTeam_Limit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team))
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MaxTeams: 2, MaxTeamMembers: -1})
+
+	org := &User{
+		LowerName:        "acme",
+		Name:             "acme",
+		Type:             UserTypeOrganization,
+		NumTeams:         1, // The owners team
+		MaxRepoCreation:  -1,
+		MaxCollaborators: -1,
+		MaxTeams:         -1,
+		MaxTeamMembers:   -1,
+	}
+	_, err := x.Insert(org)
+	require.NoError(t, err)
+
+	err = NewTeam(&Team{OrgID: org.ID, Name: "dev"})
+	require.NoError(t, err)
+
+	// The limit has been reached
+	err = NewTeam(&Team{OrgID: org.ID, Name: "ops"})
+	assert.Equal(t, ErrReachLimitOfTeams{Limit: 2}, err)
+
+	count, err := x.Count(&Team{OrgID: org.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// The limit of the organization overrides the global default
+	_, err = x.ID(org.ID).Cols("max_teams").Update(&User{MaxTeams: 3})
+	require.NoError(t, err)
+	err = NewTeam(&Team{OrgID: org.ID, Name: "ops"})
+	require.NoError(t, err)
+
+	got, err := getUserByID(x, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.NumTeams)
+}
+
+func TestAddTeamMember_Limit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team), new(TeamUser), new(TeamRepo), new(OrgUser))
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MaxTeams: -1, MaxTeamMembers: 1})
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization, MaxTeamMembers: -1}
+	alice := &User{LowerName: "alice", Name: "alice", MaxTeamMembers: -1}
+	bob := &User{LowerName: "bob", Name: "bob", MaxTeamMembers: -1}
+	_, err := x.Insert(org, alice, bob)
+	require.NoError(t, err)
+
+	team := &Team{OrgID: org.ID, LowerName: "dev", Name: "dev"}
+	_, err = x.Insert(team)
+	require.NoError(t, err)
+
+	err = AddTeamMember(alice, org.ID, team.ID, alice.ID)
+	require.NoError(t, err)
+
+	// The limit has been reached
+	err = AddTeamMember(alice, org.ID, team.ID, bob.ID)
+	assert.Equal(t, ErrReachLimitOfTeamMembers{Limit: 1}, err)
+	assert.False(t, IsTeamMember(org.ID, team.ID, bob.ID))
+	assert.False(t, IsOrganizationMember(org.ID, bob.ID))
+
+	got, err := GetTeamByID(team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.NumMembers)
+}
+
+func TestTeams(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team), new(TeamUser), new(TeamRepo), new(OrgUser), new(Repository), new(Access), new(Collaboration), new(Watch))
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MaxTeams: -1, MaxTeamMembers: -1})
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization, NumTeams: 1, MaxTeams: -1, MaxTeamMembers: -1}
+	alice := &User{LowerName: "alice", Name: "alice", MaxTeamMembers: -1}
+	_, err := x.Insert(org, alice)
+	require.NoError(t, err)
+	owners := &Team{OrgID: org.ID, LowerName: "owners", Name: OWNER_TEAM, Authorize: AccessModeOwner}
+	_, err = x.Insert(owners)
+	require.NoError(t, err)
+
+	team := &Team{OrgID: org.ID, Name: "Dev", Authorize: AccessModeRead}
+	err = NewTeam(team)
+	require.NoError(t, err)
+	assert.Equal(t, "dev", team.LowerName)
+
+	err = NewTeam(&Team{OrgID: org.ID, Name: "dev"})
+	assert.True(t, IsErrTeamAlreadyExist(err))
+
+	t.Run("update", func(t *testing.T) {
+		team.Name = "Developers"
+		team.Authorize = AccessModeWrite
+		err := UpdateTeam(team, true)
+		require.NoError(t, err)
+
+		got, err := GetTeamByID(team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "developers", got.LowerName)
+		assert.Equal(t, AccessModeWrite, got.Authorize)
+
+		err = UpdateTeam(&Team{ID: team.ID, OrgID: org.ID, Name: OWNER_TEAM}, false)
+		assert.True(t, IsErrTeamAlreadyExist(err))
+	})
+
+	t.Run("add and remove member", func(t *testing.T) {
+		err := AddTeamMember(alice, org.ID, team.ID, alice.ID)
+		require.NoError(t, err)
+		assert.True(t, IsTeamMember(org.ID, team.ID, alice.ID))
+		assert.True(t, IsOrganizationMember(org.ID, alice.ID))
+
+		got, err := GetTeamByID(team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.NumMembers)
+
+		err = RemoveTeamMember(alice, org.ID, team.ID, alice.ID)
+		require.NoError(t, err)
+		assert.False(t, IsTeamMember(org.ID, team.ID, alice.ID))
+
+		got, err = GetTeamByID(team.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.NumMembers)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := DeleteTeam(owners)
+		wantErr := ErrCannotDeleteOwnerTeam{args: errutil.Args{"teamID": owners.ID, "orgID": org.ID}}
+		assert.Equal(t, wantErr, err)
+
+		err = DeleteTeam(team)
+		require.NoError(t, err)
+		_, err = GetTeamByID(team.ID)
+		assert.True(t, IsErrTeamNotExist(err))
+
+		got, err := getUserByID(x, org.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.NumTeams)
+	})
+}
+
+func TestTeamMembershipCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team), new(TeamUser), new(TeamRepo), new(OrgUser))
+	setMockMembershipCache(t, time.Minute)
+	conf.Organization.MaxTeams = -1
+	conf.Organization.MaxTeamMembers = -1
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization, MaxTeamMembers: -1}
+	alice := &User{LowerName: "alice", Name: "alice", MaxTeamMembers: -1}
+	_, err := x.Insert(org, alice)
+	require.NoError(t, err)
+	team := &Team{OrgID: org.ID, LowerName: "dev", Name: "dev"}
+	_, err = x.Insert(team)
+	require.NoError(t, err)
+
+	// Cache the results before the membership changes
+	assert.False(t, IsTeamMember(org.ID, team.ID, alice.ID))
+	assert.False(t, IsOrganizationMember(org.ID, alice.ID))
+
+	err = AddTeamMember(alice, org.ID, team.ID, alice.ID)
+	require.NoError(t, err)
+	assert.True(t, IsTeamMember(org.ID, team.ID, alice.ID))
+	assert.True(t, IsOrganizationMember(org.ID, alice.ID))
+
+	err = RemoveTeamMember(alice, org.ID, team.ID, alice.ID)
+	require.NoError(t, err)
+	assert.False(t, IsTeamMember(org.ID, team.ID, alice.ID))
+	assert.True(t, IsOrganizationMember(org.ID, alice.ID))
+}
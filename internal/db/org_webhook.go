@@ -0,0 +1,138 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+	log "unknwon.dev/clog/v2"
+
+	api "github.com/gogs/go-gogs-client"
+
+	"gogs.io/gogs/internal/testutil"
+)
+
+// HookMembershipAction is the action of membership and team events.
+type HookMembershipAction string
+
+const (
+	HOOK_MEMBERSHIP_ADDED   HookMembershipAction = "added"
+	HOOK_MEMBERSHIP_REMOVED HookMembershipAction = "removed"
+)
+
+// MembershipPayload represents a payload information of membership event, which
+// is fired when a user is added to or removed from an organization.
+type MembershipPayload struct {
+	Action       HookMembershipAction `json:"action"`
+	Member       *api.User            `json:"member"`
+	Organization *api.Organization    `json:"organization"`
+	Sender       *api.User            `json:"sender"`
+}
+
+func (p *MembershipPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// TeamPayload represents a payload information of team event, which is fired
+// when a user is added to or removed from a team of an organization.
+type TeamPayload struct {
+	Action       HookMembershipAction `json:"action"`
+	Member       *api.User            `json:"member"`
+	Team         *api.Team            `json:"team"`
+	Organization *api.Organization    `json:"organization"`
+	Sender       *api.User            `json:"sender"`
+}
+
+func (p *TeamPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// apiFormatOrganization converts the organization to its API format.
+func (org *User) apiFormatOrganization() *api.Organization {
+	return &api.Organization{
+		ID:          org.ID,
+		AvatarUrl:   org.AvatarURL(),
+		UserName:    org.Name,
+		FullName:    org.FullName,
+		Description: org.Description,
+		Website:     org.Website,
+		Location:    org.Location,
+	}
+}
+
+// prepareOrgWebhooks adds hook tasks of the event to active webhooks of the
+// organization.
+func prepareOrgWebhooks(e Engine, orgID int64, event HookEventType, p api.Payloader) error {
+	webhooks, err := getActiveWebhooksByOrgID(e, orgID)
+	if err != nil {
+		return fmt.Errorf("getActiveWebhooksByOrgID [%d]: %v", orgID, err)
+	}
+	return prepareHookTasks(e, 0, event, p, webhooks, false)
+}
+
+// prepareMembershipWebhooks adds hook tasks of membership event for the member
+// added to or removed from the organization by the doer.
+func prepareMembershipWebhooks(doer *User, orgID, memberID int64, action HookMembershipAction) {
+	if x == nil && testutil.InTest {
+		return
+	}
+
+	err := func() error {
+		org, err := getUserByID(x, orgID)
+		if err != nil {
+			return fmt.Errorf("get organization: %v", err)
+		}
+		member, err := getUserByID(x, memberID)
+		if err != nil {
+			return fmt.Errorf("get member: %v", err)
+		}
+
+		return prepareOrgWebhooks(x, orgID, HOOK_EVENT_MEMBERSHIP, &MembershipPayload{
+			Action:       action,
+			Member:       member.APIFormat(),
+			Organization: org.apiFormatOrganization(),
+			Sender:       doer.APIFormat(),
+		})
+	}()
+	if err != nil {
+		log.Error("Failed to prepare membership webhooks [org_id: %d, member_id: %d]: %v", orgID, memberID, err)
+	}
+}
+
+// prepareTeamWebhooks adds hook tasks of team event for the member added to or
+// removed from the team by the doer.
+func prepareTeamWebhooks(doer *User, t *Team, memberID int64, action HookMembershipAction) {
+	if x == nil && testutil.InTest {
+		return
+	}
+
+	err := func() error {
+		org, err := getUserByID(x, t.OrgID)
+		if err != nil {
+			return fmt.Errorf("get organization: %v", err)
+		}
+		member, err := getUserByID(x, memberID)
+		if err != nil {
+			return fmt.Errorf("get member: %v", err)
+		}
+
+		return prepareOrgWebhooks(x, t.OrgID, HOOK_EVENT_TEAM, &TeamPayload{
+			Action: action,
+			Member: member.APIFormat(),
+			Team: &api.Team{
+				ID:          t.ID,
+				Name:        t.Name,
+				Description: t.Description,
+				Permission:  t.Authorize.String(),
+			},
+			Organization: org.apiFormatOrganization(),
+			Sender:       doer.APIFormat(),
+		})
+	}()
+	if err != nil {
+		log.Error("Failed to prepare team webhooks [team_id: %d, member_id: %d]: %v", t.ID, memberID, err)
+	}
+}
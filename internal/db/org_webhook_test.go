@@ -0,0 +1,132 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestTeamMember_webhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team), new(TeamUser), new(TeamRepo), new(OrgUser), new(Webhook), new(HookTask))
+	conf.SetMockOrganization(t, conf.OrganizationOpts{MaxTeams: -1, MaxTeamMembers: -1})
+
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization, MaxTeamMembers: -1}
+	alice := &User{LowerName: "alice", Name: "alice", MaxTeamMembers: -1}
+	bob := &User{LowerName: "bob", Name: "bob", MaxTeamMembers: -1}
+	_, err := x.Insert(org, alice, bob)
+	require.NoError(t, err)
+	team := &Team{OrgID: org.ID, LowerName: "dev", Name: "dev", Authorize: AccessModeWrite}
+	_, err = x.Insert(team)
+	require.NoError(t, err)
+
+	hook := &Webhook{
+		OrgID:        org.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents:   HookEvents{Membership: true, Team: true},
+		},
+		IsActive: true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	type payload struct {
+		Action string `json:"action"`
+		Member struct {
+			UserName string `json:"username"`
+		} `json:"member"`
+		Team *struct {
+			Name string `json:"name"`
+		} `json:"team"`
+		Organization struct {
+			UserName string `json:"username"`
+		} `json:"organization"`
+		Sender struct {
+			UserName string `json:"username"`
+		} `json:"sender"`
+	}
+	type event struct {
+		typ     HookEventType
+		payload *payload
+	}
+	var numTasks int
+	listEvents := func(t *testing.T) []*event {
+		var tasks []*HookTask
+		err := x.Asc("id").Find(&tasks)
+		require.NoError(t, err)
+		tasks = tasks[numTasks:]
+		numTasks += len(tasks)
+
+		events := make([]*event, len(tasks))
+		for i := range tasks {
+			assert.Equal(t, int64(0), tasks[i].RepoID)
+			events[i] = &event{typ: tasks[i].EventType, payload: new(payload)}
+			err = jsoniter.Unmarshal([]byte(tasks[i].PayloadContent), events[i].payload)
+			require.NoError(t, err)
+		}
+		return events
+	}
+
+	t.Run("add member", func(t *testing.T) {
+		err := AddTeamMember(alice, org.ID, team.ID, bob.ID)
+		require.NoError(t, err)
+
+		events := listEvents(t)
+		require.Len(t, events, 2)
+
+		// Bob joins the organization before the team
+		assert.Equal(t, HOOK_EVENT_MEMBERSHIP, events[0].typ)
+		assert.Equal(t, "added", events[0].payload.Action)
+		assert.Equal(t, "bob", events[0].payload.Member.UserName)
+		assert.Equal(t, "acme", events[0].payload.Organization.UserName)
+		assert.Equal(t, "alice", events[0].payload.Sender.UserName)
+		assert.Nil(t, events[0].payload.Team)
+
+		assert.Equal(t, HOOK_EVENT_TEAM, events[1].typ)
+		assert.Equal(t, "added", events[1].payload.Action)
+		assert.Equal(t, "bob", events[1].payload.Member.UserName)
+		require.NotNil(t, events[1].payload.Team)
+		assert.Equal(t, "dev", events[1].payload.Team.Name)
+		assert.Equal(t, "acme", events[1].payload.Organization.UserName)
+		assert.Equal(t, "alice", events[1].payload.Sender.UserName)
+	})
+
+	t.Run("add existing member", func(t *testing.T) {
+		err := AddTeamMember(alice, org.ID, team.ID, bob.ID)
+		require.NoError(t, err)
+		assert.Empty(t, listEvents(t))
+	})
+
+	t.Run("remove member", func(t *testing.T) {
+		err := RemoveTeamMember(bob, org.ID, team.ID, bob.ID)
+		require.NoError(t, err)
+
+		events := listEvents(t)
+		require.Len(t, events, 1)
+		assert.Equal(t, HOOK_EVENT_TEAM, events[0].typ)
+		assert.Equal(t, "removed", events[0].payload.Action)
+		assert.Equal(t, "bob", events[0].payload.Member.UserName)
+		assert.Equal(t, "bob", events[0].payload.Sender.UserName)
+
+		// Removing a user who is not a member is a no-op
+		err = RemoveTeamMember(bob, org.ID, team.ID, bob.ID)
+		require.NoError(t, err)
+		assert.Empty(t, listEvents(t))
+	})
+}
@@ -6,11 +6,15 @@ package db
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbutil"
+	"gogs.io/gogs/internal/sync"
 )
 
 // OrgsStore is the persistent interface for organizations.
@@ -26,6 +30,10 @@ type OrgsStore interface {
 
 	// CountByUser returns the number of organizations the user is a member of.
 	CountByUser(ctx context.Context, userID int64) (int64, error)
+	// IsOrgMember returns true if the user is a member of the organization.
+	IsOrgMember(ctx context.Context, orgID, userID int64) bool
+	// IsTeamMember returns true if the user is a member of the team.
+	IsTeamMember(ctx context.Context, teamID, userID int64) bool
 }
 
 var Orgs OrgsStore
@@ -85,6 +93,79 @@ func (db *orgs) CountByUser(ctx context.Context, userID int64) (int64, error) {
 	return count, db.WithContext(ctx).Model(&OrgUser{}).Where("uid = ?", userID).Count(&count).Error
 }
 
+func (db *orgs) IsOrgMember(ctx context.Context, orgID, userID int64) bool {
+	return membershipCache.load(orgMembershipKey(orgID, userID), func() bool {
+		return db.exists(ctx, "SELECT EXISTS (SELECT 1 FROM org_user WHERE org_id = ? AND uid = ?)", orgID, userID)
+	})
+}
+
+func (db *orgs) IsTeamMember(ctx context.Context, teamID, userID int64) bool {
+	return membershipCache.load(teamMembershipKey(teamID, userID), func() bool {
+		return db.exists(ctx, "SELECT EXISTS (SELECT 1 FROM team_user WHERE team_id = ? AND uid = ?)", teamID, userID)
+	})
+}
+
+// exists runs the query that selects a single boolean. It returns false when
+// the query fails.
+func (db *orgs) exists(ctx context.Context, query string, args ...any) bool {
+	var exists bool
+	err := db.WithContext(ctx).Raw(query, args...).Scan(&exists).Error
+	if err != nil {
+		log.Error("Failed to check membership: %v", err)
+		return false
+	}
+	return exists
+}
+
+// membershipCache caches results of organization and team membership checks
+// when conf.Organization.MembershipCacheTTL is positive. Entries must be
+// invalidated whenever memberships change.
+var membershipCache = &membershipCacheStore{cache: sync.NewTTLCache()}
+
+type membershipCacheStore struct {
+	cache *sync.TTLCache
+}
+
+func orgMembershipKey(orgID, userID int64) string {
+	return fmt.Sprintf("org:%d:%d", orgID, userID)
+}
+
+func teamMembershipKey(teamID, userID int64) string {
+	return fmt.Sprintf("team:%d:%d", teamID, userID)
+}
+
+// load returns the cached result of the key, or calls check to get and cache
+// the result when there is no cached result or caching is disabled.
+func (s *membershipCacheStore) load(key string, check func() bool) bool {
+	ttl := conf.Organization.MembershipCacheTTL
+	if ttl <= 0 {
+		return check()
+	}
+
+	if v, ok := s.cache.Get(key); ok {
+		return v.(bool)
+	}
+	result := check()
+	s.cache.Set(key, result, ttl)
+	return result
+}
+
+// invalidateOrg removes the cached result of the organization membership.
+func (s *membershipCacheStore) invalidateOrg(orgID, userID int64) {
+	s.cache.Delete(orgMembershipKey(orgID, userID))
+}
+
+// invalidateTeam removes the cached result of the team membership.
+func (s *membershipCacheStore) invalidateTeam(teamID, userID int64) {
+	s.cache.Delete(teamMembershipKey(teamID, userID))
+}
+
+// reset removes all cached results, e.g. when a team or an organization is
+// deleted along with all its memberships.
+func (s *membershipCacheStore) reset() {
+	s.cache.Reset()
+}
+
 type Organization = User
 
 func (o *Organization) TableName() string {
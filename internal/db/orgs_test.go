@@ -7,10 +7,12 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbtest"
 	"gogs.io/gogs/internal/dbutil"
 )
@@ -21,7 +23,7 @@ func TestOrgs(t *testing.T) {
 	}
 	t.Parallel()
 
-	tables := []any{new(User), new(EmailAddress), new(OrgUser)}
+	tables := []any{new(User), new(EmailAddress), new(OrgUser), new(TeamUser)}
 	db := &orgs{
 		DB: dbtest.NewDB(t, "orgs", tables...),
 	}
@@ -33,6 +35,8 @@ func TestOrgs(t *testing.T) {
 		{"List", orgsList},
 		{"SearchByName", orgsSearchByName},
 		{"CountByUser", orgsCountByUser},
+		{"IsOrgMember", orgsIsOrgMember},
+		{"IsTeamMember", orgsIsTeamMember},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Cleanup(func() {
@@ -183,3 +187,69 @@ func orgsCountByUser(t *testing.T, db *orgs) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(0), got)
 }
+
+func setMockMembershipCache(t *testing.T, ttl time.Duration) {
+	opts := conf.Organization
+	opts.MembershipCacheTTL = ttl
+	conf.SetMockOrganization(t, opts)
+	t.Cleanup(membershipCache.reset)
+}
+
+func orgsIsOrgMember(t *testing.T, db *orgs) {
+	ctx := context.Background()
+
+	// TODO: Use Orgs.Join to replace SQL hack when the method is available.
+	err := db.Exec(`INSERT INTO org_user (uid, org_id) VALUES (?, ?)`, 1, 1).Error
+	require.NoError(t, err)
+
+	t.Run("without cache", func(t *testing.T) {
+		setMockMembershipCache(t, 0)
+
+		assert.True(t, db.IsOrgMember(ctx, 1, 1))
+		assert.False(t, db.IsOrgMember(ctx, 1, 2))
+		assert.False(t, db.IsOrgMember(ctx, 2, 1))
+	})
+
+	t.Run("with cache", func(t *testing.T) {
+		setMockMembershipCache(t, time.Minute)
+
+		assert.False(t, db.IsOrgMember(ctx, 1, 2))
+
+		err := db.Exec(`INSERT INTO org_user (uid, org_id) VALUES (?, ?)`, 2, 1).Error
+		require.NoError(t, err)
+
+		// The cached result is used until it is invalidated
+		assert.False(t, db.IsOrgMember(ctx, 1, 2))
+		membershipCache.invalidateOrg(1, 2)
+		assert.True(t, db.IsOrgMember(ctx, 1, 2))
+	})
+}
+
+func orgsIsTeamMember(t *testing.T, db *orgs) {
+	ctx := context.Background()
+
+	err := db.Exec(`INSERT INTO team_user (org_id, team_id, uid) VALUES (?, ?, ?)`, 1, 1, 1).Error
+	require.NoError(t, err)
+
+	t.Run("without cache", func(t *testing.T) {
+		setMockMembershipCache(t, 0)
+
+		assert.True(t, db.IsTeamMember(ctx, 1, 1))
+		assert.False(t, db.IsTeamMember(ctx, 1, 2))
+		assert.False(t, db.IsTeamMember(ctx, 2, 1))
+	})
+
+	t.Run("with cache", func(t *testing.T) {
+		setMockMembershipCache(t, time.Minute)
+
+		assert.True(t, db.IsTeamMember(ctx, 1, 1))
+
+		err := db.Exec(`DELETE FROM team_user WHERE team_id = ? AND uid = ?`, 1, 1).Error
+		require.NoError(t, err)
+
+		// The cached result is used until it is invalidated
+		assert.True(t, db.IsTeamMember(ctx, 1, 1))
+		membershipCache.invalidateTeam(1, 1)
+		assert.False(t, db.IsTeamMember(ctx, 1, 1))
+	})
+}
@@ -0,0 +1,239 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// orphanedFilesBatchSize is the number of files to be checked against the
+// database at a time.
+const orphanedFilesBatchSize = 100
+
+// orphanedFilesSource is a storage directory where every file belongs to a
+// database row.
+type orphanedFilesSource struct {
+	name string
+	root string
+	// keyOf returns the key of the row that the file with given path belongs to,
+	// where the path is relative to the root. It returns false if the file is
+	// not managed by the source, which is never seen as orphaned.
+	keyOf func(relPath string) (string, bool)
+	// existing returns the subset of given keys that have rows in the
+	// database.
+	existing func(keys []string) (map[string]bool, error)
+}
+
+// existingIDs returns a function that returns the subset of given IDs that
+// have rows in the table.
+func existingIDs(table string) func(keys []string) (map[string]bool, error) {
+	return func(keys []string) (map[string]bool, error) {
+		ids := make([]int64, 0, len(keys))
+		for _, key := range keys {
+			id, err := strconv.ParseInt(key, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse ID %q", key)
+			}
+			ids = append(ids, id)
+		}
+
+		var found []int64
+		err := x.Table(table).In("id", ids).Cols("id").Find(&found)
+		if err != nil {
+			return nil, err
+		}
+
+		existing := make(map[string]bool, len(found))
+		for _, id := range found {
+			existing[strconv.FormatInt(id, 10)] = true
+		}
+		return existing, nil
+	}
+}
+
+// idKeyOf returns the key of files that are named after row IDs and stored
+// directly under the root.
+func idKeyOf(relPath string) (string, bool) {
+	if filepath.Dir(relPath) != "." {
+		return "", false
+	}
+	id, err := strconv.ParseInt(relPath, 10, 64)
+	if err != nil || id <= 0 {
+		return "", false
+	}
+	return relPath, true
+}
+
+// attachmentKeyOf returns the UUID of an attachment file, which is stored as
+// "<uuid[0]>/<uuid[1]>/<uuid>" under the root.
+func attachmentKeyOf(relPath string) (string, bool) {
+	uuid := filepath.Base(relPath)
+	if len(uuid) < 2 || relPath != filepath.Join(uuid[0:1], uuid[1:2], uuid) {
+		return "", false
+	}
+	return uuid, true
+}
+
+func existingAttachments(keys []string) (map[string]bool, error) {
+	var uuids []string
+	err := x.Table("attachment").In("uuid", keys).Cols("uuid").Find(&uuids)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(uuids))
+	for _, uuid := range uuids {
+		existing[uuid] = true
+	}
+	return existing, nil
+}
+
+func orphanedFilesSources() []*orphanedFilesSource {
+	return []*orphanedFilesSource{
+		{
+			name:     "user avatars",
+			root:     conf.Picture.AvatarUploadPath,
+			keyOf:    idKeyOf,
+			existing: existingIDs("user"),
+		},
+		{
+			name:     "repository avatars",
+			root:     conf.Picture.RepositoryAvatarUploadPath,
+			keyOf:    idKeyOf,
+			existing: existingIDs("repository"),
+		},
+		{
+			name:     "attachments",
+			root:     conf.Attachment.Path,
+			keyOf:    attachmentKeyOf,
+			existing: existingAttachments,
+		},
+	}
+}
+
+// findOrphanedFiles returns paths of files in the source that were last
+// modified before given time and are not referenced by any database row. It
+// returns an error without any path if any of the checks against the database
+// fails, so that files are never treated as orphaned by mistake.
+func findOrphanedFiles(src *orphanedFilesSource, olderThan time.Time) ([]string, error) {
+	var (
+		orphans []string
+		batch   = make(map[string][]string, orphanedFilesBatchSize)
+	)
+	checkBatch := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		keys := make([]string, 0, len(batch))
+		for key := range batch {
+			keys = append(keys, key)
+		}
+		existing, err := src.existing(keys)
+		if err != nil {
+			return errors.Wrap(err, "check existence")
+		}
+
+		for key, paths := range batch {
+			if !existing[key] {
+				orphans = append(orphans, paths...)
+			}
+		}
+		batch = make(map[string][]string, orphanedFilesBatchSize)
+		return nil
+	}
+
+	err := filepath.WalkDir(src.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == src.root {
+				return filepath.SkipDir
+			}
+			return err
+		} else if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(src.root, path)
+		if err != nil {
+			return err
+		}
+		key, ok := src.keyOf(relPath)
+		if !ok {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		} else if fi.ModTime().After(olderThan) {
+			return nil
+		}
+
+		batch[key] = append(batch[key], path)
+		if len(batch) >= orphanedFilesBatchSize {
+			return checkBatch()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(); err != nil {
+		return nil, err
+	}
+	return orphans, nil
+}
+
+// DeleteOrphanedFiles deletes avatars and attachments that are no longer
+// referenced by any database row after the grace period. Files are only logged
+// without being deleted in dry-run mode.
+func DeleteOrphanedFiles() {
+	if taskStatusTable.IsRunning(_DELETE_ORPHANED_FILES) {
+		return
+	}
+	taskStatusTable.Start(_DELETE_ORPHANED_FILES)
+	defer taskStatusTable.Stop(_DELETE_ORPHANED_FILES)
+
+	log.Trace("Doing: DeleteOrphanedFiles")
+
+	dryRun := conf.Cron.OrphanedFileCleanup.DryRun
+	olderThan := time.Now().Add(-conf.Cron.OrphanedFileCleanup.GracePeriod)
+	for _, src := range orphanedFilesSources() {
+		paths, err := findOrphanedFiles(src, olderThan)
+		if err != nil {
+			log.Error("Failed to find orphaned %s: %v", src.name, err)
+			continue
+		}
+
+		for _, path := range paths {
+			if dryRun {
+				log.Info("Orphaned file of %s would be deleted: %s", src.name, path)
+				continue
+			}
+
+			if err = os.Remove(path); err != nil {
+				desc := fmt.Sprintf("Failed to delete orphaned file '%s': %v", path, err)
+				log.Warn(desc)
+				if err = Notices.Create(context.TODO(), NoticeTypeRepository, desc); err != nil {
+					log.Error("CreateRepositoryNotice: %v", err)
+				}
+				continue
+			}
+			log.Trace("Orphaned file of %s deleted: %s", src.name, path)
+		}
+	}
+}
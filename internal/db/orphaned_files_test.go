@@ -0,0 +1,120 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindOrphanedFiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Attachment))
+
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	writeFile := func(t *testing.T, path string, modTime time.Time) string {
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte("content"), 0o644)
+		require.NoError(t, err)
+		err = os.Chtimes(path, modTime, modTime)
+		require.NoError(t, err)
+		return path
+	}
+
+	t.Run("avatars", func(t *testing.T) {
+		root := t.TempDir()
+		src := &orphanedFilesSource{
+			root:     root,
+			keyOf:    idKeyOf,
+			existing: existingIDs("user"),
+		}
+
+		// Spans multiple batches with every third user deleted
+		var wantOrphans []string
+		for id := int64(1); id <= orphanedFilesBatchSize*2+10; id++ {
+			path := writeFile(t, filepath.Join(root, strconv.FormatInt(id, 10)), old)
+			if id%3 == 0 {
+				wantOrphans = append(wantOrphans, path)
+				continue
+			}
+			_, err := x.Insert(&User{ID: id, LowerName: "user" + strconv.FormatInt(id, 10), Name: "user" + strconv.FormatInt(id, 10), Email: strconv.FormatInt(id, 10) + "@example.com"})
+			require.NoError(t, err)
+		}
+
+		// Orphaned but still within the grace period
+		writeFile(t, filepath.Join(root, "9999"), now)
+		// Not managed by the source
+		writeFile(t, filepath.Join(root, "README"), old)
+		writeFile(t, filepath.Join(root, "nested", "9998"), old)
+
+		got, err := findOrphanedFiles(src, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		sort.Strings(got)
+		sort.Strings(wantOrphans)
+		assert.Equal(t, wantOrphans, got)
+	})
+
+	t.Run("attachments", func(t *testing.T) {
+		root := t.TempDir()
+		src := &orphanedFilesSource{
+			root:     root,
+			keyOf:    attachmentKeyOf,
+			existing: existingAttachments,
+		}
+
+		const referenced = "a1b2c3d4-0000-0000-0000-000000000001"
+		_, err := x.Insert(&Attachment{UUID: referenced, Name: "referenced.txt"})
+		require.NoError(t, err)
+		writeFile(t, filepath.Join(root, "a", "1", referenced), old)
+
+		orphan := writeFile(t, filepath.Join(root, "b", "2", "b2c3d4e5-0000-0000-0000-000000000002"), old)
+		// Stored in a wrong directory
+		writeFile(t, filepath.Join(root, "c", "c3d4e5f6-0000-0000-0000-000000000003"), old)
+
+		got, err := findOrphanedFiles(src, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []string{orphan}, got)
+	})
+
+	t.Run("nonexistent root", func(t *testing.T) {
+		src := &orphanedFilesSource{
+			root:     filepath.Join(t.TempDir(), "404"),
+			keyOf:    idKeyOf,
+			existing: existingIDs("user"),
+		}
+		got, err := findOrphanedFiles(src, now)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, filepath.Join(root, "1"), old)
+
+		src := &orphanedFilesSource{
+			root:  root,
+			keyOf: idKeyOf,
+			existing: func([]string) (map[string]bool, error) {
+				return nil, errors.New("connection reset")
+			},
+		}
+		got, err := findOrphanedFiles(src, now)
+		assert.Error(t, err)
+		assert.Empty(t, got)
+	})
+}
@@ -0,0 +1,269 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/tool"
+)
+
+// ProtectBranchesStore is the persistent interface for protected branches.
+type ProtectBranchesStore interface {
+	// List returns all protected branches of the given repository, sorted by
+	// branch name.
+	List(ctx context.Context, repoID int64) ([]*ProtectBranch, error)
+	// GetByName returns the protection options of the named branch in the given
+	// repository. It returns ErrProtectBranchNotExist when not found.
+	GetByName(ctx context.Context, repoID int64, name string) (*ProtectBranch, error)
+	// ValidateWhitelist checks every given user and team exists and has write
+	// access to the repository. It returns ErrInvalidProtectBranchWhitelist when
+	// any of them does not.
+	ValidateWhitelist(ctx context.Context, repo *Repository, userIDs, teamIDs []int64) error
+	// Save validates the options of the protected branch and saves them, creating
+	// the record if its ID is 0. It returns ErrInvalidProtectBranchWhitelist when
+	// the whitelist is not valid, or ErrInvalidProtectBranchRequiredApprovals when
+	// the number of required approvals is negative. Whitelist entries for the
+	// branch are regenerated from the whitelisted users and members of whitelisted
+	// teams, and required status checks are deduplicated and sorted.
+	Save(ctx context.Context, repo *Repository, protectBranch *ProtectBranch) error
+	// Delete deletes the protection options and whitelist of the named branch in
+	// the given repository. It returns ErrProtectBranchNotExist when not found.
+	Delete(ctx context.Context, repoID int64, name string) error
+}
+
+var ProtectBranches ProtectBranchesStore
+
+var _ ProtectBranchesStore = (*protectBranches)(nil)
+
+type protectBranches struct {
+	*gorm.DB
+}
+
+// NewProtectBranchesStore returns a persistent interface for protected branches
+// with given database connection.
+func NewProtectBranchesStore(db *gorm.DB) ProtectBranchesStore {
+	return &protectBranches{DB: db}
+}
+
+func (db *protectBranches) List(ctx context.Context, repoID int64) ([]*ProtectBranch, error) {
+	var protectBranches []*ProtectBranch
+	return protectBranches, db.WithContext(ctx).
+		Where("repo_id = ? AND protected = ?", repoID, true).
+		Order("name ASC").
+		Find(&protectBranches).
+		Error
+}
+
+var _ errutil.NotFound = (*ErrProtectBranchNotExist)(nil)
+
+type ErrProtectBranchNotExist struct {
+	args errutil.Args
+}
+
+func IsErrProtectBranchNotExist(err error) bool {
+	_, ok := err.(ErrProtectBranchNotExist)
+	return ok
+}
+
+func (err ErrProtectBranchNotExist) Error() string {
+	return fmt.Sprintf("protect branch does not exist: %v", err.args)
+}
+
+func (ErrProtectBranchNotExist) NotFound() bool {
+	return true
+}
+
+func (db *protectBranches) GetByName(ctx context.Context, repoID int64, name string) (*ProtectBranch, error) {
+	protectBranch := new(ProtectBranch)
+	err := db.WithContext(ctx).Where("repo_id = ? AND name = ?", repoID, name).First(protectBranch).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrProtectBranchNotExist{args: errutil.Args{"repoID": repoID, "name": name}}
+		}
+		return nil, err
+	}
+	return protectBranch, nil
+}
+
+type ErrInvalidProtectBranchWhitelist struct {
+	args errutil.Args
+}
+
+func IsErrInvalidProtectBranchWhitelist(err error) bool {
+	_, ok := err.(ErrInvalidProtectBranchWhitelist)
+	return ok
+}
+
+func (err ErrInvalidProtectBranchWhitelist) Error() string {
+	return fmt.Sprintf("invalid protect branch whitelist: %v", err.args)
+}
+
+func (db *protectBranches) ValidateWhitelist(ctx context.Context, repo *Repository, userIDs, teamIDs []int64) error {
+	perms := NewPermsStore(db.DB)
+	for _, userID := range userIDs {
+		if !perms.Authorize(ctx, userID, repo.ID, AccessModeWrite,
+			AccessModeOptions{
+				OwnerID: repo.OwnerID,
+				Private: repo.IsPrivate,
+			},
+		) {
+			return ErrInvalidProtectBranchWhitelist{args: errutil.Args{"userID": userID}}
+		}
+	}
+
+	if len(teamIDs) == 0 {
+		return nil
+	}
+
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT team.id FROM team
+		JOIN team_repo ON team_repo.team_id = team.id
+		WHERE
+			team.org_id = @ownerID
+		AND team_repo.repo_id = @repoID
+		AND team.authorize >= @accessModeWrite
+	*/
+	var validTeamIDs []int64
+	err := db.WithContext(ctx).
+		Table("team").
+		Joins("JOIN team_repo ON team_repo.team_id = team.id").
+		Where("team.org_id = ? AND team_repo.repo_id = ? AND team.authorize >= ?", repo.OwnerID, repo.ID, AccessModeWrite).
+		Pluck("team.id", &validTeamIDs).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "list teams with write access")
+	}
+
+	valid := make(map[int64]bool, len(validTeamIDs))
+	for _, teamID := range validTeamIDs {
+		valid[teamID] = true
+	}
+	for _, teamID := range teamIDs {
+		if !valid[teamID] {
+			return ErrInvalidProtectBranchWhitelist{args: errutil.Args{"teamID": teamID}}
+		}
+	}
+	return nil
+}
+
+type ErrInvalidProtectBranchRequiredApprovals struct {
+	args errutil.Args
+}
+
+func IsErrInvalidProtectBranchRequiredApprovals(err error) bool {
+	_, ok := err.(ErrInvalidProtectBranchRequiredApprovals)
+	return ok
+}
+
+func (err ErrInvalidProtectBranchRequiredApprovals) Error() string {
+	return fmt.Sprintf("invalid number of required approvals: %v", err.args)
+}
+
+// parseWhitelistIDs parses the comma-separated list of IDs, ignoring empty and
+// malformed elements.
+func parseWhitelistIDs(s string) []int64 {
+	ids := tool.StringsToInt64s(strings.Split(s, ","))
+	valid := ids[:0]
+	for _, id := range ids {
+		if id > 0 {
+			valid = append(valid, id)
+		}
+	}
+	return valid
+}
+
+func (db *protectBranches) Save(ctx context.Context, repo *Repository, protectBranch *ProtectBranch) error {
+	if protectBranch.RequiredApprovals < 0 {
+		return ErrInvalidProtectBranchRequiredApprovals{args: errutil.Args{"requiredApprovals": protectBranch.RequiredApprovals}}
+	}
+
+	userIDs := parseWhitelistIDs(protectBranch.WhitelistUserIDs)
+	teamIDs := parseWhitelistIDs(protectBranch.WhitelistTeamIDs)
+	err := db.ValidateWhitelist(ctx, repo, userIDs, teamIDs)
+	if err != nil {
+		return err
+	}
+	protectBranch.RepoID = repo.ID
+	protectBranch.WhitelistUserIDs = strings.Join(tool.Int64sToStrings(userIDs), ",")
+	protectBranch.WhitelistTeamIDs = strings.Join(tool.Int64sToStrings(teamIDs), ",")
+	protectBranch.RequiredStatusChecks = strings.Join(parseStatusCheckContexts(protectBranch.RequiredStatusChecks), ",")
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if protectBranch.ID == 0 {
+			err := tx.Create(protectBranch).Error
+			if err != nil {
+				return errors.Wrap(err, "create")
+			}
+		} else {
+			err := tx.Select("*").Updates(protectBranch).Error
+			if err != nil {
+				return errors.Wrap(err, "update")
+			}
+		}
+
+		// Merge users and members of teams
+		mergedUserIDs := make(map[int64]bool, len(userIDs))
+		for _, userID := range userIDs {
+			mergedUserIDs[userID] = true
+		}
+		if len(teamIDs) > 0 {
+			var memberIDs []int64
+			err := tx.Table("team_user").Where("team_id IN (?)", teamIDs).Pluck("uid", &memberIDs).Error
+			if err != nil {
+				return errors.Wrap(err, "list team members")
+			}
+			for _, memberID := range memberIDs {
+				mergedUserIDs[memberID] = true
+			}
+		}
+
+		err := tx.Where("protect_branch_id = ?", protectBranch.ID).Delete(new(ProtectBranchWhitelist)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete old whitelists")
+		}
+		if len(mergedUserIDs) == 0 {
+			return nil
+		}
+
+		whitelists := make([]*ProtectBranchWhitelist, 0, len(mergedUserIDs))
+		for userID := range mergedUserIDs {
+			whitelists = append(whitelists, &ProtectBranchWhitelist{
+				ProtectBranchID: protectBranch.ID,
+				RepoID:          repo.ID,
+				Name:            protectBranch.Name,
+				UserID:          userID,
+			})
+		}
+		err = tx.Create(&whitelists).Error
+		if err != nil {
+			return errors.Wrap(err, "create whitelists")
+		}
+		return nil
+	})
+}
+
+func (db *protectBranches) Delete(ctx context.Context, repoID int64, name string) error {
+	protectBranch, err := db.GetByName(ctx, repoID, name)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("protect_branch_id = ?", protectBranch.ID).Delete(new(ProtectBranchWhitelist)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete whitelists")
+		}
+		return tx.Delete(protectBranch).Error
+	})
+}
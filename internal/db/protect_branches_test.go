@@ -0,0 +1,202 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestProtectBranches(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(ProtectBranch), new(ProtectBranchWhitelist), new(Access)}
+	db := &protectBranches{
+		DB: dbtest.NewDB(t, "protectBranches", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *protectBranches)
+	}{
+		{"Save", protectBranchesSave},
+		{"GetByName", protectBranchesGetByName},
+		{"List", protectBranchesList},
+		{"ValidateWhitelist", protectBranchesValidateWhitelist},
+		{"Delete", protectBranchesDelete},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func protectBranchesSave(t *testing.T, db *protectBranches) {
+	ctx := context.Background()
+
+	repo := &Repository{ID: 1, OwnerID: 1, IsPrivate: true}
+	err := NewPermsStore(db.DB).SetRepoPerms(ctx, repo.ID, map[int64]AccessMode{2: AccessModeWrite})
+	require.NoError(t, err)
+
+	protectBranch := &ProtectBranch{
+		Name:             "main",
+		Protected:        true,
+		EnableWhitelist:  true,
+		WhitelistUserIDs: "1,,2",
+	}
+	err = db.Save(ctx, repo, protectBranch)
+	require.NoError(t, err)
+	assert.NotZero(t, protectBranch.ID)
+	assert.Equal(t, "1,2", protectBranch.WhitelistUserIDs)
+
+	var whitelists []*ProtectBranchWhitelist
+	err = db.Where("protect_branch_id = ?", protectBranch.ID).Order("user_id ASC").Find(&whitelists).Error
+	require.NoError(t, err)
+	require.Len(t, whitelists, 2)
+	assert.Equal(t, int64(1), whitelists[0].UserID)
+	assert.Equal(t, int64(2), whitelists[1].UserID)
+
+	// Updating should regenerate the whitelist
+	protectBranch.WhitelistUserIDs = "2"
+	err = db.Save(ctx, repo, protectBranch)
+	require.NoError(t, err)
+
+	whitelists = nil
+	err = db.Where("protect_branch_id = ?", protectBranch.ID).Find(&whitelists).Error
+	require.NoError(t, err)
+	require.Len(t, whitelists, 1)
+	assert.Equal(t, int64(2), whitelists[0].UserID)
+
+	// Unknown users should be rejected without changing anything
+	protectBranch.WhitelistUserIDs = "2,404"
+	err = db.Save(ctx, repo, protectBranch)
+	wantErr := ErrInvalidProtectBranchWhitelist{args: errutil.Args{"userID": int64(404)}}
+	assert.Equal(t, wantErr, err)
+
+	got, err := db.GetByName(ctx, repo.ID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.WhitelistUserIDs)
+
+	// Required status checks should be deduplicated and sorted
+	protectBranch.WhitelistUserIDs = "2"
+	protectBranch.RequiredApprovals = 2
+	protectBranch.RequiredStatusChecks = " ci/test,ci/build,, ci/test"
+	err = db.Save(ctx, repo, protectBranch)
+	require.NoError(t, err)
+
+	got, err = db.GetByName(ctx, repo.ID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.RequiredApprovals)
+	assert.Equal(t, "ci/build,ci/test", got.RequiredStatusChecks)
+	assert.Equal(t, []string{"ci/build", "ci/test"}, got.StatusCheckContexts())
+
+	protectBranch.RequiredApprovals = -1
+	err = db.Save(ctx, repo, protectBranch)
+	assert.True(t, IsErrInvalidProtectBranchRequiredApprovals(err), "want ErrInvalidProtectBranchRequiredApprovals but got %v", err)
+}
+
+func protectBranchesGetByName(t *testing.T, db *protectBranches) {
+	ctx := context.Background()
+
+	repo := &Repository{ID: 1, OwnerID: 1}
+	_, err := db.GetByName(ctx, repo.ID, "main")
+	wantErr := ErrProtectBranchNotExist{args: errutil.Args{"repoID": int64(1), "name": "main"}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.Save(ctx, repo, &ProtectBranch{Name: "main", Protected: true, RequirePullRequest: true})
+	require.NoError(t, err)
+
+	got, err := db.GetByName(ctx, repo.ID, "main")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.RepoID)
+	assert.True(t, got.Protected)
+	assert.True(t, got.RequirePullRequest)
+}
+
+func protectBranchesList(t *testing.T, db *protectBranches) {
+	ctx := context.Background()
+
+	repo := &Repository{ID: 1, OwnerID: 1}
+	for _, pb := range []*ProtectBranch{
+		{Name: "release", Protected: true},
+		{Name: "main", Protected: true},
+		{Name: "dev", Protected: false},
+	} {
+		err := db.Save(ctx, repo, pb)
+		require.NoError(t, err)
+	}
+	err := db.Save(ctx, &Repository{ID: 2, OwnerID: 1}, &ProtectBranch{Name: "main", Protected: true})
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, repo.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "main", got[0].Name)
+	assert.Equal(t, "release", got[1].Name)
+}
+
+func protectBranchesValidateWhitelist(t *testing.T, db *protectBranches) {
+	ctx := context.Background()
+
+	repo := &Repository{ID: 1, OwnerID: 1, IsPrivate: true}
+	err := NewPermsStore(db.DB).SetRepoPerms(ctx, repo.ID,
+		map[int64]AccessMode{
+			2: AccessModeWrite,
+			3: AccessModeRead,
+		},
+	)
+	require.NoError(t, err)
+
+	err = db.ValidateWhitelist(ctx, repo, []int64{1, 2}, nil)
+	require.NoError(t, err)
+
+	err = db.ValidateWhitelist(ctx, repo, []int64{2, 3}, nil)
+	wantErr := ErrInvalidProtectBranchWhitelist{args: errutil.Args{"userID": int64(3)}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.ValidateWhitelist(ctx, repo, []int64{404}, nil)
+	wantErr = ErrInvalidProtectBranchWhitelist{args: errutil.Args{"userID": int64(404)}}
+	assert.Equal(t, wantErr, err)
+}
+
+func protectBranchesDelete(t *testing.T, db *protectBranches) {
+	ctx := context.Background()
+
+	repo := &Repository{ID: 1, OwnerID: 1}
+	err := db.Delete(ctx, repo.ID, "main")
+	wantErr := ErrProtectBranchNotExist{args: errutil.Args{"repoID": int64(1), "name": "main"}}
+	assert.Equal(t, wantErr, err)
+
+	protectBranch := &ProtectBranch{Name: "main", Protected: true, WhitelistUserIDs: "1"}
+	err = db.Save(ctx, repo, protectBranch)
+	require.NoError(t, err)
+
+	err = db.Delete(ctx, repo.ID, "main")
+	require.NoError(t, err)
+
+	_, err = db.GetByName(ctx, repo.ID, "main")
+	assert.Equal(t, wantErr, err)
+
+	var count int64
+	err = db.Model(new(ProtectBranchWhitelist)).Where("protect_branch_id = ?", protectBranch.ID).Count(&count).Error
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
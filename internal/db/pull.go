@@ -20,9 +20,11 @@ import (
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/gitutil"
 	"gogs.io/gogs/internal/osutil"
 	"gogs.io/gogs/internal/process"
 	"gogs.io/gogs/internal/sync"
+	"gogs.io/gogs/internal/testutil"
 )
 
 var PullRequestQueue = sync.NewUniqueQueue(1000)
@@ -67,6 +69,14 @@ type PullRequest struct {
 	Merger         *User     `xorm:"-" json:"-" gorm:"-"`
 	Merged         time.Time `xorm:"-" json:"-" gorm:"-"`
 	MergedUnix     int64
+
+	// The merge style and the user to automatically merge the pull request with
+	// once all requirements are satisfied, the style is empty when auto-merge is
+	// not enabled. The commit is the head when auto-merge was enabled, and
+	// auto-merge is canceled once the head has changed.
+	AutoMergeStyle    MergeStyle `xorm:"VARCHAR(32)" gorm:"type:VARCHAR(32)"`
+	AutoMergeUserID   int64
+	AutoMergeCommitID string `xorm:"VARCHAR(40)" gorm:"type:VARCHAR(40)"`
 }
 
 func (pr *PullRequest) BeforeUpdate() {
@@ -192,12 +202,105 @@ const (
 	MERGE_STYLE_REBASE  MergeStyle = "rebase_before_merging"
 )
 
-// Merge merges pull request to base repository.
+type ErrMergeCommitNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrMergeCommitNotAllowed(err error) bool {
+	_, ok := err.(ErrMergeCommitNotAllowed)
+	return ok
+}
+
+func (err ErrMergeCommitNotAllowed) Error() string {
+	return fmt.Sprintf("branch requires linear history and merge commits are not allowed: %v", err.args)
+}
+
+type ErrPullRequestOutOfDate struct {
+	args errutil.Args
+}
+
+func IsErrPullRequestOutOfDate(err error) bool {
+	_, ok := err.(ErrPullRequestOutOfDate)
+	return ok
+}
+
+func (err ErrPullRequestOutOfDate) Error() string {
+	return fmt.Sprintf("head branch is behind the base branch that requires it to be up to date: %v", err.args)
+}
+
+// IsHeadUpToDate returns true if the head of the pull request contains the
+// latest commit of the base branch.
+func (pr *PullRequest) IsHeadUpToDate() (bool, error) {
+	baseGitRepo, err := git.Open(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return false, fmt.Errorf("open repository: %v", err)
+	}
+
+	baseCommitID, err := baseGitRepo.BranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return false, fmt.Errorf("get base branch %q commit ID: %v", pr.BaseBranch, err)
+	}
+
+	mergeBase, err := baseGitRepo.MergeBase(baseCommitID, fmt.Sprintf("refs/pull/%d/head", pr.Index))
+	if err != nil {
+		if gitutil.IsErrNoMergeBase(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("get merge base: %v", err)
+	}
+	return mergeBase == baseCommitID, nil
+}
+
+// Merge merges pull request to base repository. It returns
+// ErrMergeCommitNotAllowed when the base branch requires linear history but a
+// merge commit is requested, or ErrPullRequestOutOfDate when the base branch
+// requires head branches to be up to date but the head is behind, or
+// ErrCodeOwnerReviewRequired when the base branch requires approvals from code
+// owners but any of them is missing, or ErrApprovalsRequired when the base
+// branch requires more approvals, or ErrStatusChecksRequired when any of status
+// checks required by the base branch has not succeeded.
 // FIXME: add repoWorkingPull make sure two merges does not happen at same time.
 func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle MergeStyle, commitDescription string) (err error) {
 	ctx := context.TODO()
 
+	// Check if merge style is allowed, reset to default style if not. Rebasing is
+	// always allowed for branches that require linear history.
+	requireLinearHistory := IsBranchOfRepoRequireLinearHistory(pr.BaseRepoID, pr.BaseBranch)
+	if mergeStyle == MERGE_STYLE_REBASE && !pr.BaseRepo.PullsAllowRebase && !requireLinearHistory {
+		mergeStyle = MERGE_STYLE_REGULAR
+	}
+	if mergeStyle == MERGE_STYLE_REGULAR && requireLinearHistory {
+		return ErrMergeCommitNotAllowed{args: errutil.Args{"repoID": pr.BaseRepoID, "branch": pr.BaseBranch}}
+	}
+
+	if IsBranchOfRepoRequireUpToDate(pr.BaseRepoID, pr.BaseBranch) {
+		upToDate, err := pr.IsHeadUpToDate()
+		if err != nil {
+			return fmt.Errorf("check if head is up to date: %v", err)
+		} else if !upToDate {
+			return ErrPullRequestOutOfDate{args: errutil.Args{"pullRequestID": pr.ID, "branch": pr.BaseBranch}}
+		}
+	}
+
+	if err = pr.checkCodeOwnerReviews(ctx); err != nil {
+		return err
+	}
+	if err = pr.checkRequiredApprovals(ctx); err != nil {
+		return err
+	}
+	if err = pr.checkStatusChecks(ctx); err != nil {
+		return err
+	}
+	if err = pr.checkSignOffs(); err != nil {
+		return err
+	}
+
 	defer func() {
+		// NOTE: Background tasks would outlive the test engine and race with the
+		// next test, so they are skipped in tests.
+		if testutil.InTest {
+			return
+		}
 		go HookQueue.Add(pr.BaseRepo.ID)
 		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false)
 	}()
@@ -253,11 +356,6 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 
 	remoteHeadBranch := "head_repo/" + pr.HeadBranch
 
-	// Check if merge style is allowed, reset to default style if not
-	if mergeStyle == MERGE_STYLE_REBASE && !pr.BaseRepo.PullsAllowRebase {
-		mergeStyle = MERGE_STYLE_REGULAR
-	}
-
 	switch mergeStyle {
 	case MERGE_STYLE_REGULAR: // Create merge commit
 
@@ -271,7 +369,7 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 		// Create a merge commit for the base branch.
 		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
 			fmt.Sprintf("PullRequest.Merge (git merge): %s", tmpBasePath),
-			"git", "commit", fmt.Sprintf("--author='%s <%s>'", doer.DisplayName(), doer.Email),
+			"git", "commit", fmt.Sprintf("--author='%s <%s>'", doer.DisplayName(), doer.CommitEmail()),
 			"-m", fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.HeadBranch, pr.HeadUserName, pr.HeadRepo.Name, pr.BaseBranch),
 			"-m", commitDescription); err != nil {
 			return fmt.Errorf("git commit [%s]: %v - %s", tmpBasePath, err, stderr)
@@ -279,11 +377,22 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 
 	case MERGE_STYLE_REBASE: // Rebase before merging
 
-		// Rebase head branch based on base branch, this creates a non-branch commit state.
-		if _, stderr, err = process.ExecDir(-1, tmpBasePath,
-			fmt.Sprintf("PullRequest.Merge (git rebase): %s", tmpBasePath),
-			"git", "rebase", "--quiet", pr.BaseBranch, remoteHeadBranch); err != nil {
-			return fmt.Errorf("git rebase [%s on %s]: %s", remoteHeadBranch, pr.BaseBranch, stderr)
+		// Rebase head branch based on base branch, this creates a non-branch commit
+		// state. Fall back to a plain rebase when fixup commits cannot be folded.
+		autosquashed := false
+		if pr.BaseRepo.PullsAutosquash {
+			if err = gitutil.RebaseAutosquash(tmpBasePath, pr.BaseBranch, remoteHeadBranch); err != nil {
+				log.Warn("PullRequest.Merge: failed to autosquash pull request %d, falling back to plain rebase: %v", pr.ID, err)
+			} else {
+				autosquashed = true
+			}
+		}
+		if !autosquashed {
+			if _, stderr, err = process.ExecDir(-1, tmpBasePath,
+				fmt.Sprintf("PullRequest.Merge (git rebase): %s", tmpBasePath),
+				"git", "rebase", "--quiet", pr.BaseBranch, remoteHeadBranch); err != nil {
+				return fmt.Errorf("git rebase [%s on %s]: %s", remoteHeadBranch, pr.BaseBranch, stderr)
+			}
 		}
 
 		// Name non-branch commit state to a new temporary branch in order to save changes.
@@ -334,6 +443,9 @@ func (pr *PullRequest) Merge(doer *User, baseGitRepo *git.Repository, mergeStyle
 	if err = sess.Commit(); err != nil {
 		return fmt.Errorf("Commit: %v", err)
 	}
+	pr.cleanupRefs()
+	pr.closeLinkedIssues(doer)
+	defer pr.deleteHeadBranchAfterMerge(doer)
 
 	if err = Actions.MergePullRequest(ctx, doer, pr.Issue.Repo.Owner, pr.Issue.Repo, pr.Issue); err != nil {
 		log.Error("Failed to create action for merge pull request, pull_request_id: %d, error: %v", pr.ID, err)
@@ -452,6 +564,13 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 		return err
 	}
 
+	if pull.AssigneeID == 0 {
+		pull.AssigneeID, err = pickDefaultPullAssignee(sess, repo, pull.PosterID)
+		if err != nil {
+			return fmt.Errorf("pick default pull request assignee: %v", err)
+		}
+	}
+
 	if err = newIssue(sess, NewIssueOptions{
 		Repo:        repo,
 		Issue:       pull,
@@ -500,8 +619,17 @@ func NewPullRequest(repo *Repository, pull *Issue, labelIDs []int64, uuids []str
 	if err = pull.MailParticipants(); err != nil {
 		log.Error("MailParticipants: %v", err)
 	}
+	if err = pr.requestDefaultReviewers(context.TODO(), repo, pull.PosterID); err != nil {
+		log.Error("Failed to request default reviewers for pull request %d: %v", pr.ID, err)
+	}
+	if err = pr.requestRotationReviewers(context.TODO(), repo, pull.PosterID); err != nil {
+		log.Error("Failed to request rotation reviewers for pull request %d: %v", pr.ID, err)
+	}
 
 	pr.Issue = pull
+	if err = pr.mailRequestedReviewers(context.TODO(), pull.Poster); err != nil {
+		log.Error("Failed to mail requested reviewers of pull request %d: %v", pr.ID, err)
+	}
 	pull.PullRequest = pr
 	if err = PrepareWebhooks(repo, HOOK_EVENT_PULL_REQUEST, &api.PullRequestPayload{
 		Action:      api.HOOK_ISSUE_OPENED,
@@ -702,6 +830,85 @@ func (pr *PullRequest) PushToBaseRepo() (err error) {
 	return nil
 }
 
+type ErrPullRequestUpdateConflict struct {
+	args errutil.Args
+}
+
+func IsErrPullRequestUpdateConflict(err error) bool {
+	_, ok := err.(ErrPullRequestUpdateConflict)
+	return ok
+}
+
+func (err ErrPullRequestUpdateConflict) Error() string {
+	return fmt.Sprintf("base branch cannot be merged into head branch without conflicts: %v", err.args)
+}
+
+type ErrPullRequestHeadProtected struct {
+	args errutil.Args
+}
+
+func IsErrPullRequestHeadProtected(err error) bool {
+	_, ok := err.(ErrPullRequestHeadProtected)
+	return ok
+}
+
+func (err ErrPullRequestHeadProtected) Error() string {
+	return fmt.Sprintf("head branch is protected: %v", err.args)
+}
+
+// IsHeadBranchProtected returns true if the head branch of the pull request is
+// a protected branch.
+func (pr *PullRequest) IsHeadBranchProtected() bool {
+	protectBranch, err := GetProtectBranchOfRepoByName(pr.HeadRepoID, pr.HeadBranch)
+	return err == nil && protectBranch.Protected
+}
+
+// UpdateBranch brings the head branch of the pull request up to date by
+// merging the base branch into it. It returns ErrPullRequestHeadProtected when
+// the head branch is protected, and ErrPullRequestUpdateConflict when the merge
+// has conflicts. Callers are responsible for adding test tasks for the pushed
+// head branch.
+func (pr *PullRequest) UpdateBranch(doer *User) error {
+	if pr.IsHeadBranchProtected() {
+		return ErrPullRequestHeadProtected{args: errutil.Args{"pullRequestID": pr.ID, "branch": pr.HeadBranch}}
+	}
+
+	sig := &git.Signature{
+		Name:  doer.DisplayName(),
+		Email: doer.CommitEmail(),
+		When:  time.Now(),
+	}
+	_, err := gitutil.UpdateBranch(pr.HeadRepo.RepoPath(), gitutil.UpdateBranchOptions{
+		Branch:         pr.HeadBranch,
+		UpstreamPath:   pr.BaseRepo.RepoPath(),
+		UpstreamBranch: pr.BaseBranch,
+		Author:         sig,
+		Committer:      sig,
+		Message:        fmt.Sprintf("Merge branch '%s' of %s/%s into %s", pr.BaseBranch, pr.BaseRepo.MustOwner().Name, pr.BaseRepo.Name, pr.HeadBranch),
+		PushEnvs: ComposeHookEnvs(ComposeHookEnvsOptions{
+			AuthUser:  doer,
+			OwnerName: pr.HeadRepo.MustOwner().Name,
+			OwnerSalt: pr.HeadRepo.MustOwner().Salt,
+			RepoID:    pr.HeadRepo.ID,
+			RepoName:  pr.HeadRepo.Name,
+			RepoPath:  pr.HeadRepo.RepoPath(),
+		}),
+	})
+	if err != nil {
+		if err == gitutil.ErrMergeConflict {
+			return ErrPullRequestUpdateConflict{args: errutil.Args{"pullRequestID": pr.ID}}
+		}
+		return fmt.Errorf("update branch: %v", err)
+	}
+
+	// Push right away so the head is known to be up to date before the patch is
+	// tested in the background.
+	if err = pr.PushToBaseRepo(); err != nil {
+		return fmt.Errorf("push to base repository: %v", err)
+	}
+	return nil
+}
+
 // AddToTaskQueue adds itself to pull request test task queue.
 func (pr *PullRequest) AddToTaskQueue() {
 	go PullRequestQueue.AddFunc(pr.ID, func() {
@@ -753,7 +960,7 @@ func (prs PullRequestList) LoadAttributes() error {
 	return prs.loadAttributes(x)
 }
 
-func addHeadRepoTasks(prs []*PullRequest) {
+func addHeadRepoTasks(doer *User, prs []*PullRequest) {
 	for _, pr := range prs {
 		if pr.HeadRepo == nil {
 			log.Trace("addHeadRepoTasks[%d]: missing head repository", pr.ID)
@@ -767,6 +974,8 @@ func addHeadRepoTasks(prs []*PullRequest) {
 		} else if err := pr.PushToBaseRepo(); err != nil {
 			log.Error("PushToBaseRepo: %v", err)
 			continue
+		} else if err := pr.ApplyPathLabels(doer); err != nil {
+			log.Error("ApplyPathLabels: %v", err)
 		}
 
 		pr.AddToTaskQueue()
@@ -809,7 +1018,7 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 		}
 	}
 
-	addHeadRepoTasks(prs)
+	addHeadRepoTasks(doer, prs)
 
 	log.Trace("AddTestPullRequestTask [base_repo_id: %d, base_branch: %s]: finding pull requests", repoID, branch)
 	prs, err = GetUnmergedPullRequestsByBaseInfo(repoID, branch)
@@ -834,7 +1043,9 @@ func (pr *PullRequest) checkAndUpdateStatus() {
 	if !PullRequestQueue.Exist(pr.ID) {
 		if err := pr.UpdateCols("status"); err != nil {
 			log.Error("Update[%d]: %v", pr.ID, err)
+			return
 		}
+		pr.updateAutoMerge()
 	}
 }
 
@@ -0,0 +1,212 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// IsAutoMergeEnabled returns true if the pull request is merged automatically
+// once all requirements are satisfied.
+func (pr *PullRequest) IsAutoMergeEnabled() bool {
+	return pr.AutoMergeStyle != ""
+}
+
+func (pr *PullRequest) setAutoMerge(style MergeStyle, userID int64, commitID string) error {
+	pr.AutoMergeStyle = style
+	pr.AutoMergeUserID = userID
+	pr.AutoMergeCommitID = commitID
+	return pr.UpdateCols("auto_merge_style", "auto_merge_user_id", "auto_merge_commit_id")
+}
+
+// headBranchCommitID returns the latest commit of the head branch in the head
+// repository, which is the commit to be merged.
+func (pr *PullRequest) headBranchCommitID() (string, error) {
+	headGitRepo, err := git.Open(pr.HeadRepo.RepoPath())
+	if err != nil {
+		return "", errors.Wrap(err, "open repository")
+	}
+	return headGitRepo.RevParse(git.RefsHeads + pr.HeadBranch)
+}
+
+// EnableAutoMerge enables auto-merge of the pull request with the merge style
+// by the doer for its current head, and merges the pull request right away
+// when all requirements are already satisfied. Auto-merge is canceled once new
+// commits are pushed to the head branch. The merge style falls back to creating a merge commit
+// when rebasing is not allowed, it returns ErrMergeCommitNotAllowed when the
+// base branch requires linear history but a merge commit is requested. This
+// method assumes the doer has write access to the base repository.
+func (pr *PullRequest) EnableAutoMerge(doer *User, style MergeStyle) error {
+	if err := pr.LoadAttributes(); err != nil {
+		return errors.Wrap(err, "load attributes")
+	}
+
+	requireLinearHistory := IsBranchOfRepoRequireLinearHistory(pr.BaseRepoID, pr.BaseBranch)
+	if style != MERGE_STYLE_REBASE || (!pr.BaseRepo.PullsAllowRebase && !requireLinearHistory) {
+		style = MERGE_STYLE_REGULAR
+	}
+	if style == MERGE_STYLE_REGULAR && requireLinearHistory {
+		return ErrMergeCommitNotAllowed{args: errutil.Args{"repoID": pr.BaseRepoID, "branch": pr.BaseBranch}}
+	}
+
+	commitID, err := pr.headCommitID()
+	if err != nil {
+		return errors.Wrap(err, "get head commit ID")
+	}
+	if err = pr.setAutoMerge(style, doer.ID, commitID); err != nil {
+		return errors.Wrap(err, "enable auto-merge")
+	}
+	_, err = pr.tryAutoMerge()
+	return err
+}
+
+// CancelAutoMerge cancels auto-merge of the pull request.
+func (pr *PullRequest) CancelAutoMerge() error {
+	return pr.setAutoMerge("", 0, "")
+}
+
+// autoMerging contains IDs of pull requests that are being merged
+// automatically, to prevent merging the same pull request concurrently from
+// different triggers.
+var autoMerging sync.Map
+
+// tryAutoMerge merges the pull request by the user who enabled auto-merge when
+// it is mergeable and all requirements are satisfied, and returns true if the
+// pull request has been merged. Auto-merge is canceled when the pull request
+// can no longer be merged with it, e.g. the user has lost write access or new
+// commits have been pushed since it was enabled.
+func (pr *PullRequest) tryAutoMerge() (merged bool, err error) {
+	if !pr.IsAutoMergeEnabled() || pr.HasMerged || !pr.CanAutoMerge() {
+		return false, nil
+	}
+
+	if _, loaded := autoMerging.LoadOrStore(pr.ID, struct{}{}); loaded {
+		return false, nil
+	}
+	defer autoMerging.Delete(pr.ID)
+
+	if err = pr.LoadAttributes(); err != nil {
+		return false, errors.Wrap(err, "load attributes")
+	} else if err = pr.LoadIssue(); err != nil {
+		return false, errors.Wrap(err, "load issue")
+	}
+	if pr.Issue.IsClosed || pr.HeadRepo == nil {
+		return false, nil
+	}
+
+	// New commits have not been seen by the user who enabled auto-merge.
+	headCommitID, err := pr.headBranchCommitID()
+	if err != nil {
+		return false, errors.Wrap(err, "get head branch commit ID")
+	} else if headCommitID != pr.AutoMergeCommitID {
+		log.Trace("Auto-merge of pull request [%d] is canceled: head has changed", pr.ID)
+		return false, pr.CancelAutoMerge()
+	}
+	if err = pr.BaseRepo.GetOwner(); err != nil {
+		return false, errors.Wrap(err, "get owner")
+	}
+	pr.Issue.Repo = pr.BaseRepo
+
+	ctx := context.TODO()
+	doer, err := Users.GetByID(ctx, pr.AutoMergeUserID)
+	if err != nil {
+		if IsErrUserNotExist(err) {
+			return false, pr.CancelAutoMerge()
+		}
+		return false, errors.Wrap(err, "get user")
+	}
+	if !doer.IsAdmin && !Perms.Authorize(ctx, doer.ID, pr.BaseRepoID, AccessModeWrite,
+		AccessModeOptions{
+			OwnerID: pr.BaseRepo.OwnerID,
+			Private: pr.BaseRepo.IsPrivate,
+		},
+	) {
+		log.Trace("Auto-merge of pull request [%d] is canceled: user [%d] has no write access", pr.ID, doer.ID)
+		return false, pr.CancelAutoMerge()
+	}
+
+	required, err := pr.BaseRepo.IsCLASignatureRequired(ctx, pr.Issue.PosterID)
+	if err != nil {
+		return false, errors.Wrap(err, "check CLA signature")
+	} else if required {
+		return false, nil
+	}
+
+	baseGitRepo, err := git.Open(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return false, errors.Wrap(err, "open repository")
+	}
+	err = pr.Merge(doer, baseGitRepo, pr.AutoMergeStyle, "")
+	if err != nil {
+		switch {
+		case IsErrPullRequestOutOfDate(err),
+			IsErrCodeOwnerReviewRequired(err),
+			IsErrApprovalsRequired(err),
+			IsErrStatusChecksRequired(err),
+			IsErrSignOffRequired(err):
+			return false, nil
+		case IsErrMergeCommitNotAllowed(err):
+			log.Trace("Auto-merge of pull request [%d] is canceled: merge commit is not allowed", pr.ID)
+			return false, pr.CancelAutoMerge()
+		}
+		return false, errors.Wrap(err, "merge")
+	}
+
+	log.Trace("Pull request [%d] has been merged automatically by user [%d]", pr.ID, doer.ID)
+	return true, pr.CancelAutoMerge()
+}
+
+// updateAutoMerge cancels auto-merge of the pull request when it has conflicts,
+// otherwise tries to merge it. Errors are logged.
+func (pr *PullRequest) updateAutoMerge() {
+	if !pr.IsAutoMergeEnabled() {
+		return
+	}
+
+	if pr.Status == PULL_REQUEST_STATUS_CONFLICT {
+		log.Trace("Auto-merge of pull request [%d] is canceled: conflicts", pr.ID)
+		if err := pr.CancelAutoMerge(); err != nil {
+			log.Error("Failed to cancel auto-merge of pull request [%d]: %v", pr.ID, err)
+		}
+		return
+	}
+
+	if _, err := pr.tryAutoMerge(); err != nil {
+		log.Error("Failed to auto-merge pull request [%d]: %v", pr.ID, err)
+	}
+}
+
+// UpdateAutoMergeByCommit updates auto-merge of open pull requests of the base
+// repository whose head is the commit, e.g. when a status of the commit has
+// been reported. Errors are logged.
+func UpdateAutoMergeByCommit(baseRepoID int64, commitID string) {
+	prs := make([]*PullRequest, 0, 2)
+	err := x.Where("pull_request.base_repo_id = ? AND pull_request.has_merged = ? AND pull_request.auto_merge_style != ? AND issue.is_closed = ?",
+		baseRepoID, false, "", false).
+		Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Find(&prs)
+	if err != nil {
+		log.Error("Failed to find pull requests with auto-merge [base_repo_id: %d]: %v", baseRepoID, err)
+		return
+	}
+
+	for _, pr := range prs {
+		headCommitID, err := pr.headCommitID()
+		if err != nil {
+			log.Error("Failed to get head commit ID of pull request [%d]: %v", pr.ID, err)
+			continue
+		} else if headCommitID != commitID {
+			continue
+		}
+		pr.updateAutoMerge()
+	}
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+// setupAutoMergeTest creates a repository owned by alice that requires
+// approvals from code owners, and a pull request by bob that changes a file
+// owned by carol. It returns the pull request and users alice and carol.
+func setupAutoMergeTest(t *testing.T, dbName string) (pr *PullRequest, alice, carol *User) {
+	t.Helper()
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser), new(PullRequest),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone), new(IssueWorkflowState),
+		new(ProtectBranch), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, dbName, new(User), new(EmailAddress), new(Approval), new(CommitStatus), new(LinkedIssue), new(Access), new(Action), new(Watch), new(Repository))
+	SetMockUsersStore(t, NewUsersStore(gdb))
+	SetMockPermsStore(t, NewPermsStore(gdb))
+	beforeApprovals, beforeCommitStatuses, beforeLinkedIssues, beforeActions := Approvals, CommitStatuses, LinkedIssues, Actions
+	Approvals = NewApprovalsStore(gdb)
+	CommitStatuses = NewCommitStatusesStore(gdb)
+	LinkedIssues = NewLinkedIssuesStore(gdb)
+	Actions = NewActionsStore(gdb)
+	t.Cleanup(func() {
+		Approvals, CommitStatuses, LinkedIssues, Actions = beforeApprovals, beforeCommitStatuses, beforeLinkedIssues, beforeActions
+	})
+
+	newUser := func(t *testing.T, name string) *User {
+		u, err := Users.Create(ctx, name, name+"@example.com", CreateUserOptions{Activated: true})
+		require.NoError(t, err)
+		_, err = x.Insert(u)
+		require.NoError(t, err)
+		return u
+	}
+	alice = newUser(t, "alice")
+	bob := newUser(t, "bob")
+	carol = newUser(t, "carol")
+
+	repo := &Repository{OwnerID: alice.ID, Owner: alice, LowerName: "example", Name: "example", DefaultBranch: "main"}
+	_, err := x.Insert(repo)
+	require.NoError(t, err)
+	repoPath := repo.RepoPath()
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("CODEOWNERS", "*.md @carol\n")
+	run("push", "origin", "main")
+
+	run("checkout", "-b", "feature")
+	commit("README.md", "Hello")
+	run("push", "origin", "feature")
+
+	_, err = x.Insert(&ProtectBranch{
+		RepoID:                  repo.ID,
+		Name:                    "main",
+		Protected:               true,
+		RequireCodeOwnerReviews: true,
+	})
+	require.NoError(t, err)
+
+	issue := &Issue{RepoID: repo.ID, Repo: repo, PosterID: bob.ID, Poster: bob, Title: "Add README", IsPull: true}
+	pr = &PullRequest{
+		HeadRepoID:   repo.ID,
+		BaseRepoID:   repo.ID,
+		HeadUserName: alice.Name,
+		HeadBranch:   "feature",
+		BaseBranch:   "main",
+		HeadRepo:     repo,
+		BaseRepo:     repo,
+	}
+	err = NewPullRequest(repo, issue, nil, nil, pr, nil)
+	require.NoError(t, err)
+	pr.Issue = issue
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	// The patch is not saved, pretend the test merge is still in progress.
+	pr.Status = PULL_REQUEST_STATUS_CHECKING
+	err = pr.UpdateCols("status")
+	require.NoError(t, err)
+	return pr, alice, carol
+}
+
+// pushToHeadBranch pushes a new commit that writes the file to the head branch
+// of the pull request.
+func pushToHeadBranch(t *testing.T, pr *PullRequest, name, content string) {
+	t.Helper()
+
+	workPath := t.TempDir()
+	_, err := git.NewCommand("clone", "-b", pr.HeadBranch, pr.HeadRepo.RepoPath(), workPath).RunInDir(t.TempDir())
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+	require.NoError(t, err)
+	for _, args := range [][]string{
+		{"add", name},
+		{"commit", "-m", "Update " + name},
+		{"push", "origin", pr.HeadBranch},
+	} {
+		_, err = git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+}
+
+func TestPullRequest_AutoMerge(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	pr, alice, carol := setupAutoMergeTest(t, "autoMerge")
+
+	// Nothing happens while the pull request is being checked
+	err := pr.EnableAutoMerge(alice, MERGE_STYLE_REGULAR)
+	require.NoError(t, err)
+	assert.True(t, pr.IsAutoMergeEnabled())
+	assert.False(t, pr.HasMerged)
+
+	// The test merge succeeds but the approval of the code owner is missing
+	pr.checkAndUpdateStatus()
+	assert.Equal(t, PULL_REQUEST_STATUS_MERGEABLE, pr.Status)
+	assert.False(t, pr.HasMerged)
+	assert.True(t, pr.IsAutoMergeEnabled())
+
+	// The approval is the last requirement to be satisfied
+	err = pr.Approve(ctx, carol)
+	require.NoError(t, err)
+
+	got, err := GetPullRequestByID(pr.ID)
+	require.NoError(t, err)
+	assert.True(t, got.HasMerged)
+	assert.Equal(t, alice.ID, got.MergerID)
+	assert.False(t, got.IsAutoMergeEnabled())
+}
+
+func TestPullRequest_AutoMergeStatusChecks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	pr, alice, carol := setupAutoMergeTest(t, "autoMergeStatusChecks")
+	_, err := x.Where("repo_id = ?", pr.BaseRepoID).Cols("required_status_checks").Update(&ProtectBranch{RequiredStatusChecks: "ci/test"})
+	require.NoError(t, err)
+	pr.Status = PULL_REQUEST_STATUS_MERGEABLE
+	err = pr.UpdateCols("status")
+	require.NoError(t, err)
+
+	err = pr.Approve(ctx, carol)
+	require.NoError(t, err)
+	err = pr.EnableAutoMerge(alice, MERGE_STYLE_REGULAR)
+	require.NoError(t, err)
+	assert.False(t, pr.HasMerged)
+
+	headCommitID, err := pr.headCommitID()
+	require.NoError(t, err)
+	_, err = CommitStatuses.Create(ctx, pr.BaseRepoID, alice.ID, headCommitID, CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusPending})
+	require.NoError(t, err)
+	UpdateAutoMergeByCommit(pr.BaseRepoID, headCommitID)
+
+	got, err := GetPullRequestByID(pr.ID)
+	require.NoError(t, err)
+	assert.False(t, got.HasMerged)
+	assert.True(t, got.IsAutoMergeEnabled())
+
+	// The status check is the last requirement to be satisfied
+	_, err = CommitStatuses.Create(ctx, pr.BaseRepoID, alice.ID, headCommitID, CreateCommitStatusOptions{Context: "ci/test", State: CommitStatusSuccess})
+	require.NoError(t, err)
+	UpdateAutoMergeByCommit(pr.BaseRepoID, headCommitID)
+
+	got, err = GetPullRequestByID(pr.ID)
+	require.NoError(t, err)
+	assert.True(t, got.HasMerged)
+	assert.Equal(t, alice.ID, got.MergerID)
+	assert.False(t, got.IsAutoMergeEnabled())
+}
+
+func TestPullRequest_AutoMergeCanceled(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	pr, alice, carol := setupAutoMergeTest(t, "autoMergeCanceled")
+	pr.Status = PULL_REQUEST_STATUS_MERGEABLE
+	err := pr.UpdateCols("status")
+	require.NoError(t, err)
+
+	enableAutoMerge := func(t *testing.T) {
+		t.Helper()
+		err := pr.EnableAutoMerge(alice, MERGE_STYLE_REGULAR)
+		require.NoError(t, err)
+		require.True(t, pr.IsAutoMergeEnabled())
+	}
+	isAutoMergeEnabled := func(t *testing.T) bool {
+		t.Helper()
+		got, err := GetPullRequestByID(pr.ID)
+		require.NoError(t, err)
+		return got.IsAutoMergeEnabled()
+	}
+
+	t.Run("withdrawn approval", func(t *testing.T) {
+		enableAutoMerge(t)
+		err := pr.Unapprove(ctx, carol)
+		require.NoError(t, err)
+		assert.False(t, isAutoMergeEnabled(t))
+	})
+
+	t.Run("new commits", func(t *testing.T) {
+		enableAutoMerge(t)
+		pushToHeadBranch(t, pr, "README.md", "Hello, world")
+		err := pr.PushToBaseRepo()
+		require.NoError(t, err)
+		pr.checkAndUpdateStatus()
+		assert.False(t, isAutoMergeEnabled(t))
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		enableAutoMerge(t)
+		pr.Status = PULL_REQUEST_STATUS_CONFLICT
+		pr.checkAndUpdateStatus()
+		assert.False(t, isAutoMergeEnabled(t))
+	})
+
+	got, err := GetPullRequestByID(pr.ID)
+	require.NoError(t, err)
+	assert.False(t, got.HasMerged)
+}
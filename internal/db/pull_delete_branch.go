@@ -0,0 +1,114 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/pathutil"
+)
+
+type ErrBranchPatternInvalid struct {
+	args errutil.Args
+}
+
+func IsErrBranchPatternInvalid(err error) bool {
+	_, ok := err.(ErrBranchPatternInvalid)
+	return ok
+}
+
+func (err ErrBranchPatternInvalid) Error() string {
+	return fmt.Sprintf("branch pattern is not valid: %v", err.args)
+}
+
+// ValidateBranchPatterns returns ErrBranchPatternInvalid if any of the comma
+// separated list of branch globs is malformed.
+func ValidateBranchPatterns(patterns string) error {
+	for _, pattern := range ParseFilePatterns(patterns) {
+		if _, err := pathutil.MatchGlob(pattern, ""); err != nil {
+			return ErrBranchPatternInvalid{args: errutil.Args{"pattern": pattern}}
+		}
+	}
+	return nil
+}
+
+// IsBranchExemptFromDeletionAfterMerge returns true if the branch matches any
+// of globs that exempt branches from being deleted after pull requests are
+// merged.
+func (repo *Repository) IsBranchExemptFromDeletionAfterMerge(branch string) bool {
+	for _, pattern := range ParseFilePatterns(repo.PullsDeleteBranchExemptions) {
+		if matched, _ := pathutil.MatchGlob(pattern, branch); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteHeadBranchAfterMerge deletes the head branch of the merged pull request
+// when the base repository enables so. The branch is kept when it is in another
+// repository, is the default or a protected branch, is exempted, has new
+// commits after the merge, or is the head of other open pull requests. Errors
+// are logged.
+func (pr *PullRequest) deleteHeadBranchAfterMerge(doer *User) {
+	repo := pr.BaseRepo
+	if !repo.PullsDeleteBranchAfterMerge ||
+		pr.HeadRepoID != pr.BaseRepoID ||
+		pr.HeadBranch == repo.DefaultBranch ||
+		repo.IsBranchExemptFromDeletionAfterMerge(pr.HeadBranch) {
+		return
+	}
+
+	protectBranch, err := GetProtectBranchOfRepoByName(repo.ID, pr.HeadBranch)
+	if err == nil && protectBranch.Protected {
+		return
+	} else if err != nil && !IsErrBranchNotExist(err) {
+		log.Error("Failed to get protect branch %q of repository [%d]: %v", pr.HeadBranch, repo.ID, err)
+		return
+	}
+
+	prs, err := GetUnmergedPullRequestsByHeadInfo(repo.ID, pr.HeadBranch)
+	if err != nil {
+		log.Error("Failed to get unmerged pull requests by head branch %q: %v", pr.HeadBranch, err)
+		return
+	} else if len(prs) > 0 {
+		return
+	}
+
+	gitRepo, err := git.Open(repo.RepoPath())
+	if err != nil {
+		log.Error("Failed to open repository %q: %v", repo.RepoPath(), err)
+		return
+	}
+	commitID, err := gitRepo.BranchCommitID(pr.HeadBranch)
+	if err != nil {
+		log.Error("Failed to get commit ID of branch %q: %v", pr.HeadBranch, err)
+		return
+	} else if commitID != pr.MergedCommitID {
+		return
+	}
+
+	err = gitRepo.DeleteBranch(pr.HeadBranch, git.DeleteBranchOptions{Force: true})
+	if err != nil {
+		log.Error("Failed to delete branch %q: %v", pr.HeadBranch, err)
+		return
+	}
+	log.Trace("Head branch %q of pull request [%d] has been deleted after merge", pr.HeadBranch, pr.ID)
+
+	err = PrepareWebhooks(repo, HOOK_EVENT_DELETE, &api.DeletePayload{
+		Ref:        pr.HeadBranch,
+		RefType:    "branch",
+		PusherType: api.PUSHER_TYPE_USER,
+		Repo:       repo.APIFormatLegacy(nil),
+		Sender:     doer.APIFormat(),
+	})
+	if err != nil {
+		log.Error("Failed to prepare webhooks for %q: %v", HOOK_EVENT_DELETE, err)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestRepository_IsBranchExemptFromDeletionAfterMerge(t *testing.T) {
+	repo := &Repository{PullsDeleteBranchExemptions: "release/*, develop,hotfix/**"}
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{branch: "release/1.0", want: true},
+		{branch: "release/1.0/rc", want: false},
+		{branch: "develop", want: true},
+		{branch: "develop-next", want: false},
+		{branch: "hotfix/a/b", want: true},
+		{branch: "feature", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.branch, func(t *testing.T) {
+			assert.Equal(t, test.want, repo.IsBranchExemptFromDeletionAfterMerge(test.branch))
+		})
+	}
+}
+
+func TestValidateBranchPatterns(t *testing.T) {
+	assert.NoError(t, ValidateBranchPatterns("release/*,develop"))
+	assert.True(t, IsErrBranchPatternInvalid(ValidateBranchPatterns("release/[")))
+}
+
+func TestPullRequest_deleteHeadBranchAfterMerge(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Issue), new(IssueUser), new(PullRequest),
+		new(Label), new(IssueLabel), new(Attachment), new(Comment), new(Milestone), new(IssueWorkflowState),
+		new(ProtectBranch), new(Watch), new(Action), new(Webhook), new(HookTask),
+	)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "deleteHeadBranchAfterMerge", new(User), new(EmailAddress), new(LinkedIssue), new(Action), new(Watch), new(Repository))
+	SetMockUsersStore(t, NewUsersStore(gdb))
+	beforeLinkedIssues, beforeActions := LinkedIssues, Actions
+	LinkedIssues = NewLinkedIssuesStore(gdb)
+	Actions = NewActionsStore(gdb)
+	t.Cleanup(func() {
+		LinkedIssues, Actions = beforeLinkedIssues, beforeActions
+	})
+
+	alice, err := Users.Create(ctx, "alice", "alice@example.com", CreateUserOptions{Activated: true})
+	require.NoError(t, err)
+	_, err = x.Insert(alice)
+	require.NoError(t, err)
+
+	repo := &Repository{
+		OwnerID:                     alice.ID,
+		Owner:                       alice,
+		LowerName:                   "example",
+		Name:                        "example",
+		DefaultBranch:               "main",
+		PullsDeleteBranchAfterMerge: true,
+		PullsDeleteBranchExemptions: "release/*,develop",
+	}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	repoPath := repo.RepoPath()
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("README.md", "Hello")
+	run("push", "origin", "main")
+	for _, branch := range []string{"release/1.0", "fix"} {
+		run("checkout", "-b", branch, "main")
+		commit(filepath.Base(branch)+".txt", branch)
+		run("push", "origin", branch)
+	}
+
+	baseGitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	merge := func(t *testing.T, branch string) {
+		t.Helper()
+
+		// Reload for the next index of issues
+		repo, err := GetRepositoryByID(repo.ID)
+		require.NoError(t, err)
+		repo.Owner = alice
+
+		issue := &Issue{RepoID: repo.ID, Repo: repo, PosterID: alice.ID, Poster: alice, Title: "Merge " + branch, IsPull: true}
+		pr := &PullRequest{
+			HeadRepoID:   repo.ID,
+			BaseRepoID:   repo.ID,
+			HeadUserName: alice.Name,
+			HeadBranch:   branch,
+			BaseBranch:   "main",
+			HeadRepo:     repo,
+			BaseRepo:     repo,
+		}
+		err = NewPullRequest(repo, issue, nil, nil, pr, nil)
+		require.NoError(t, err)
+		pr.Issue = issue
+		err = pr.PushToBaseRepo()
+		require.NoError(t, err)
+
+		err = pr.Merge(alice, baseGitRepo, MERGE_STYLE_REGULAR, "")
+		require.NoError(t, err)
+	}
+
+	merge(t, "release/1.0")
+	assert.True(t, baseGitRepo.HasBranch("release/1.0"), "exempted branch should be kept")
+
+	merge(t, "fix")
+	assert.False(t, baseGitRepo.HasBranch("fix"), "branch should be deleted")
+}
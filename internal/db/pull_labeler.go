@@ -0,0 +1,208 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	log "unknwon.dev/clog/v2"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+
+	"gogs.io/gogs/internal/pathutil"
+)
+
+// PathLabelerConfigCandidates are the paths of the file in the default branch of
+// the base repository that maps labels to path globs, e.g.
+//
+//	documentation:
+//	  - docs/**
+//	  - "*.md"
+var PathLabelerConfigCandidates = []string{
+	".gogs/labeler.yml",
+	".github/labeler.yml",
+}
+
+// parsePathLabelerConfig parses the config that maps label names to path globs.
+func parsePathLabelerConfig(data []byte) (map[string][]string, error) {
+	config := make(map[string][]string)
+	err := yaml.Unmarshal(data, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	for label, globs := range config {
+		for _, glob := range globs {
+			if _, err = pathutil.MatchGlob(glob, ""); err != nil {
+				return nil, errors.Errorf("label %q: invalid glob %q", label, glob)
+			}
+		}
+	}
+	return config, nil
+}
+
+// matchPathLabels returns sorted names of labels that have any glob matching any
+// of the changed files.
+func matchPathLabels(config map[string][]string, files []string) []string {
+	var names []string
+	for label, globs := range config {
+	loop:
+		for _, glob := range globs {
+			for _, file := range files {
+				if matched, _ := pathutil.MatchGlob(glob, file); matched {
+					names = append(names, label)
+					break loop
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPathLabels applies labels to the pull request by its changed files
+// according to the path labeler config in the default branch of the base
+// repository. Labels applied by a previous run that no longer match are
+// removed, while labels applied manually are never removed.
+func (pr *PullRequest) ApplyPathLabels(doer *User) error {
+	if pr.MergeBase == "" {
+		return nil
+	}
+	if err := pr.LoadIssue(); err != nil {
+		return errors.Wrap(err, "load issue")
+	} else if err = pr.LoadAttributes(); err != nil {
+		return errors.Wrap(err, "load attributes")
+	}
+
+	baseRepoPath := pr.BaseRepo.RepoPath()
+	baseGitRepo, err := git.Open(baseRepoPath)
+	if err != nil {
+		return errors.Wrap(err, "open repository")
+	}
+	commit, err := baseGitRepo.BranchCommit(pr.BaseRepo.DefaultBranch)
+	if err != nil {
+		return errors.Wrap(err, "get default branch commit")
+	}
+
+	var config map[string][]string
+	for _, candidate := range PathLabelerConfigCandidates {
+		blob, err := commit.Blob(candidate)
+		if err != nil {
+			continue
+		}
+		p, err := blob.Bytes()
+		if err != nil {
+			return errors.Wrapf(err, "read %q", candidate)
+		}
+		config, err = parsePathLabelerConfig(p)
+		if err != nil {
+			return errors.Wrapf(err, "parse %q", candidate)
+		}
+		break
+	}
+	if len(config) == 0 {
+		return nil
+	}
+
+	stdout, err := git.NewCommand("diff", "--name-only", "-z", pr.MergeBase, fmt.Sprintf("refs/pull/%d/head", pr.Index)).RunInDir(baseRepoPath)
+	if err != nil {
+		return errors.Wrap(err, "list changed files")
+	}
+	var files []string
+	for _, file := range bytes.Split(stdout, []byte{0}) {
+		if len(file) > 0 {
+			files = append(files, string(file))
+		}
+	}
+
+	added, removed, err := pr.Issue.applyAutoLabels(matchPathLabels(config, files))
+	if err != nil {
+		return errors.Wrap(err, "apply labels")
+	}
+	log.Trace("ApplyPathLabels[%d]: %d added, %d removed", pr.ID, len(added), len(removed))
+
+	if pr.Issue.Repo == nil {
+		pr.Issue.Repo = pr.BaseRepo
+	}
+	pr.Issue.PullRequest = pr
+	pr.Issue.sendLabelsWebhook(doer, api.HOOK_ISSUE_LABEL_UPDATED, added, removed)
+	return nil
+}
+
+// applyAutoLabels makes the given labels applied to the issue by names, and
+// removes automatically applied labels that are not given. Names of labels that
+// do not exist in the repository are ignored.
+func (issue *Issue) applyAutoLabels(names []string) (added, removed []*Label, err error) {
+	issueLabels, err := getIssueLabels(x, issue.ID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "get issue labels")
+	}
+	isAuto := make(map[int64]bool, len(issueLabels))
+	for _, issueLabel := range issueLabels {
+		isAuto[issueLabel.LabelID] = issueLabel.IsAuto
+	}
+
+	wanted := make(map[int64]bool, len(names))
+	for _, name := range names {
+		label, err := getLabelOfRepoByName(x, issue.RepoID, name)
+		if err != nil {
+			if IsErrLabelNotExist(err) {
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "get label %q", name)
+		}
+		wanted[label.ID] = true
+
+		if _, ok := isAuto[label.ID]; !ok {
+			added = append(added, label)
+		}
+	}
+	for labelID, auto := range isAuto {
+		if !auto || wanted[labelID] {
+			continue
+		}
+
+		label, err := getLabelOfRepoByID(x, issue.RepoID, labelID)
+		if err != nil {
+			if IsErrLabelNotExist(err) {
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "get label by ID %d", labelID)
+		}
+		removed = append(removed, label)
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].ID < removed[j].ID
+	})
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, nil, err
+	}
+
+	if err = issue.getLabels(sess); err != nil {
+		return nil, nil, errors.Wrap(err, "get labels")
+	}
+	for _, label := range added {
+		if err = addIssueLabel(sess, issue, label, true); err != nil {
+			return nil, nil, errors.Wrapf(err, "add label %d", label.ID)
+		}
+	}
+	for _, label := range removed {
+		if err = deleteIssueLabel(sess, issue, label); err != nil {
+			return nil, nil, errors.Wrapf(err, "delete label %d", label.ID)
+		}
+	}
+	return added, removed, sess.Commit()
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePathLabelerConfig(t *testing.T) {
+	config, err := parsePathLabelerConfig([]byte(`
+documentation:
+  - docs/**
+  - "*.md"
+backend:
+  - internal/**
+`))
+	require.NoError(t, err)
+	assert.Equal(t,
+		map[string][]string{
+			"documentation": {"docs/**", "*.md"},
+			"backend":       {"internal/**"},
+		},
+		config,
+	)
+
+	_, err = parsePathLabelerConfig([]byte(`documentation: docs/**`))
+	assert.Error(t, err)
+
+	_, err = parsePathLabelerConfig([]byte(`documentation: ["[docs"]`))
+	assert.Error(t, err)
+}
+
+func TestMatchPathLabels(t *testing.T) {
+	config := map[string][]string{
+		"documentation": {"docs/**", "*.md"},
+		"backend":       {"internal/**"},
+		"frontend":      {"public/**", "templates/**"},
+	}
+	got := matchPathLabels(config, []string{"README.md", "internal/db/pull.go"})
+	assert.Equal(t, []string{"backend", "documentation"}, got)
+
+	got = matchPathLabels(config, []string{"conf/app.ini"})
+	assert.Empty(t, got)
+}
+
+func TestIssue_applyAutoLabels(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Label), new(IssueLabel))
+
+	documentation := &Label{RepoID: 1, Name: "documentation", Color: "#0000ff"}
+	backend := &Label{RepoID: 1, Name: "backend", Color: "#00ff00"}
+	bug := &Label{RepoID: 1, Name: "bug", Color: "#ff0000"}
+	for _, label := range []*Label{documentation, backend, bug} {
+		_, err := x.Insert(label)
+		require.NoError(t, err)
+	}
+
+	issue := &Issue{ID: 1, RepoID: 1, IsPull: true}
+	err := NewIssueLabel(issue, bug)
+	require.NoError(t, err)
+
+	labelNames := func(t *testing.T) []string {
+		labels, err := GetLabelsByIssueID(issue.ID)
+		require.NoError(t, err)
+		names := make([]string, 0, len(labels))
+		for _, label := range labels {
+			names = append(names, label.Name)
+		}
+		return names
+	}
+
+	// The pull request touches a matched path
+	added, removed, err := issue.applyAutoLabels([]string{"bug", "documentation", "missing"})
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	assert.Equal(t, "documentation", added[0].Name)
+	assert.Empty(t, removed)
+	assert.ElementsMatch(t, []string{"bug", "documentation"}, labelNames(t))
+
+	// The pull request no longer touches the path, and the manually applied
+	// label is kept.
+	added, removed, err = issue.applyAutoLabels([]string{"backend"})
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	assert.Equal(t, "backend", added[0].Name)
+	require.Len(t, removed, 1)
+	assert.Equal(t, "documentation", removed[0].Name)
+	assert.ElementsMatch(t, []string{"backend", "bug"}, labelNames(t))
+
+	added, removed, err = issue.applyAutoLabels(nil)
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	require.Len(t, removed, 1)
+	assert.Equal(t, "backend", removed[0].Name)
+	assert.Equal(t, []string{"bug"}, labelNames(t))
+}
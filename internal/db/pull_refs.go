@@ -0,0 +1,112 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitutil"
+)
+
+// cleanupPullRequestRefs deletes refs of pull requests with given indexes in the
+// repository of given path, the head refs are kept when configured. Callers
+// must make sure none of the pull requests is open.
+func cleanupPullRequestRefs(repoPath string, indexes map[int64]bool) error {
+	refs, err := gitutil.ListPullRequestRefs(repoPath)
+	if err != nil {
+		return errors.Wrap(err, "list pull request refs")
+	}
+
+	var deletes []string
+	for index, names := range refs {
+		if !indexes[index] {
+			continue
+		}
+		for _, name := range names {
+			if conf.Repository.PullRequestRefs.KeepHeadRef && name == fmt.Sprintf("%s%d/head", gitutil.RefsPull, index) {
+				continue
+			}
+			deletes = append(deletes, name)
+		}
+	}
+	return gitutil.DeleteRefs(repoPath, deletes...)
+}
+
+// cleanupRefs deletes refs of the pull request in the base repository if it has
+// been merged or closed. It requires the Issue and BaseRepo fields to be
+// loaded.
+func (pr *PullRequest) cleanupRefs() {
+	if !conf.Repository.PullRequestRefs.Cleanup ||
+		!(pr.HasMerged || pr.Issue.IsClosed) {
+		return
+	}
+
+	err := cleanupPullRequestRefs(pr.BaseRepo.RepoPath(), map[int64]bool{pr.Index: true})
+	if err != nil {
+		log.Error("Failed to clean up refs of pull request [%d]: %v", pr.ID, err)
+	}
+}
+
+// restoreHeadRef pushes the head branch to the head ref of the pull request in
+// the base repository, which might have been cleaned up when it was closed.
+func (pr *PullRequest) restoreHeadRef() {
+	if err := pr.LoadAttributes(); err != nil {
+		log.Error("Failed to load attributes of pull request [%d]: %v", pr.ID, err)
+		return
+	} else if pr.HeadRepo == nil {
+		return
+	}
+
+	if err := pr.PushToBaseRepo(); err != nil {
+		log.Error("Failed to restore head ref of pull request [%d]: %v", pr.ID, err)
+	}
+}
+
+// CleanupPullRequestRefs deletes refs of all merged and closed pull requests.
+func CleanupPullRequestRefs() {
+	if !conf.Repository.PullRequestRefs.Cleanup {
+		return
+	}
+
+	if taskStatusTable.IsRunning(_CLEANUP_PULL_REQUEST_REFS) {
+		return
+	}
+	taskStatusTable.Start(_CLEANUP_PULL_REQUEST_REFS)
+	defer taskStatusTable.Stop(_CLEANUP_PULL_REQUEST_REFS)
+
+	log.Trace("Doing: CleanupPullRequestRefs")
+
+	var prs []*PullRequest
+	err := x.Where("pull_request.has_merged = ? OR issue.is_closed = ?", true, true).
+		Join("INNER", "issue", "issue.id = pull_request.issue_id").
+		Find(&prs)
+	if err != nil {
+		log.Error("Failed to list merged and closed pull requests: %v", err)
+		return
+	}
+
+	indexes := make(map[int64]map[int64]bool)
+	for _, pr := range prs {
+		if indexes[pr.BaseRepoID] == nil {
+			indexes[pr.BaseRepoID] = make(map[int64]bool)
+		}
+		indexes[pr.BaseRepoID][pr.Index] = true
+	}
+
+	for repoID := range indexes {
+		repo, err := GetRepositoryByID(repoID)
+		if err != nil {
+			log.Error("Failed to get repository [%d]: %v", repoID, err)
+			continue
+		}
+		if err = cleanupPullRequestRefs(repo.RepoPath(), indexes[repoID]); err != nil {
+			log.Error("Failed to clean up pull request refs of repository [%d]: %v", repoID, err)
+		}
+	}
+}
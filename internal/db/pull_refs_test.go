@@ -0,0 +1,149 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitutil"
+)
+
+func TestCleanupPullRequestRefs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(User), new(Repository), new(Issue), new(PullRequest))
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	repoOpts.PullRequestRefs.Cleanup = true
+	repoOpts.PullRequestRefs.KeepHeadRef = false
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{Name: "alice", LowerName: "alice"}
+	_, err := x.Insert(alice)
+	require.NoError(t, err)
+	repo := &Repository{Name: "example", LowerName: "example", OwnerID: alice.ID, Owner: alice}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	// Pull request #1 is merged, #2 is open and #3 is closed
+	pulls := []struct {
+		index     int64
+		hasMerged bool
+		isClosed  bool
+	}{
+		{index: 1, hasMerged: true, isClosed: true},
+		{index: 2},
+		{index: 3, isClosed: true},
+	}
+	repoPath := repo.RepoPath()
+	err = git.Init(repoPath)
+	require.NoError(t, err)
+	_, err = git.NewCommand("commit", "--allow-empty", "-m", "Initial commit").RunInDir(repoPath)
+	require.NoError(t, err)
+
+	setupRefs := func(t *testing.T) {
+		for _, p := range pulls {
+			for _, name := range []string{"head", "merge"} {
+				_, err := git.NewCommand("update-ref", fmt.Sprintf("%s%d/%s", gitutil.RefsPull, p.index, name), "HEAD").RunInDir(repoPath)
+				require.NoError(t, err)
+			}
+		}
+	}
+	for _, p := range pulls {
+		issue := &Issue{RepoID: repo.ID, Index: p.index, IsPull: true, IsClosed: p.isClosed}
+		_, err = x.Insert(issue)
+		require.NoError(t, err)
+		_, err = x.Insert(&PullRequest{IssueID: issue.ID, Index: p.index, BaseRepoID: repo.ID, HasMerged: p.hasMerged})
+		require.NoError(t, err)
+	}
+
+	t.Run("merged pull request", func(t *testing.T) {
+		setupRefs(t)
+
+		pr, err := GetPullRequestByIssueID(1)
+		require.NoError(t, err)
+		pr.Issue = &Issue{IsClosed: true}
+		pr.BaseRepo = repo
+		pr.cleanupRefs()
+
+		refs, err := gitutil.ListPullRequestRefs(repoPath)
+		require.NoError(t, err)
+		assert.Nil(t, refs[1])
+		assert.Len(t, refs[2], 2)
+		assert.Len(t, refs[3], 2)
+	})
+
+	t.Run("open pull request", func(t *testing.T) {
+		setupRefs(t)
+
+		pr, err := GetPullRequestByIssueID(2)
+		require.NoError(t, err)
+		pr.Issue = &Issue{IsClosed: false}
+		pr.BaseRepo = repo
+		pr.cleanupRefs()
+
+		refs, err := gitutil.ListPullRequestRefs(repoPath)
+		require.NoError(t, err)
+		assert.Len(t, refs[2], 2)
+	})
+
+	t.Run("sweep", func(t *testing.T) {
+		setupRefs(t)
+
+		CleanupPullRequestRefs()
+
+		refs, err := gitutil.ListPullRequestRefs(repoPath)
+		require.NoError(t, err)
+		assert.Equal(t, map[int64][]string{2: {"refs/pull/2/head", "refs/pull/2/merge"}}, refs)
+	})
+
+	t.Run("keep head ref", func(t *testing.T) {
+		setupRefs(t)
+		// NOTE: The mock is restored when the parent test finishes.
+		conf.Repository.PullRequestRefs.KeepHeadRef = true
+
+		CleanupPullRequestRefs()
+
+		refs, err := gitutil.ListPullRequestRefs(repoPath)
+		require.NoError(t, err)
+		want := map[int64][]string{
+			1: {"refs/pull/1/head"},
+			2: {"refs/pull/2/head", "refs/pull/2/merge"},
+			3: {"refs/pull/3/head"},
+		}
+		assert.Equal(t, want, refs)
+	})
+
+	t.Run("restore head ref", func(t *testing.T) {
+		_, err := git.NewCommand("branch", "-f", "feature").RunInDir(repoPath)
+		require.NoError(t, err)
+		err = gitutil.DeleteRefs(repoPath, "refs/pull/3/head")
+		require.NoError(t, err)
+
+		pr, err := GetPullRequestByIssueID(3)
+		require.NoError(t, err)
+		pr.HeadRepo = repo
+		pr.BaseRepo = repo
+		pr.HeadBranch = "feature"
+		pr.restoreHeadRef()
+
+		refs, err := gitutil.ListPullRequestRefs(repoPath)
+		require.NoError(t, err)
+		assert.Contains(t, refs[3], "refs/pull/3/head")
+	})
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// SignOff is a "Signed-off-by" trailer of a commit message, which certifies the
+// Developer Certificate of Origin (DCO).
+type SignOff struct {
+	Name  string
+	Email string
+}
+
+// parseSignOffs returns "Signed-off-by" trailers in the last paragraph of the
+// commit message. The subject line is never treated as a trailer.
+func parseSignOffs(message string) []*SignOff {
+	message = strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n"))
+	i := strings.LastIndex(message, "\n\n")
+	if i == -1 {
+		return nil
+	}
+
+	var signOffs []*SignOff
+	for _, line := range strings.Split(message[i+2:], "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "Signed-off-by") {
+			continue
+		}
+
+		addr, err := mail.ParseAddress(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		signOffs = append(signOffs, &SignOff{
+			Name:  addr.Name,
+			Email: addr.Address,
+		})
+	}
+	return signOffs
+}
+
+// SignOffAllowlistEmailList returns the list of email addresses of commit
+// authors who are exempted from signing off their commits.
+func (repo *Repository) SignOffAllowlistEmailList() []string {
+	var emails []string
+	for _, email := range strings.Split(repo.SignOffAllowlistEmails, ",") {
+		email = strings.TrimSpace(email)
+		if email != "" {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
+
+// isSignOffExempted returns true if commits authored by the email address are
+// not required to be signed off.
+func (repo *Repository) isSignOffExempted(email string) bool {
+	for _, e := range repo.SignOffAllowlistEmailList() {
+		if strings.EqualFold(e, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingSignOff is a commit of a pull request that is not signed off by its
+// author.
+type MissingSignOff struct {
+	CommitID    string
+	Summary     string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// MissingSignOffs returns commits of the pull request that do not have a
+// "Signed-off-by" trailer matching the email of their authors. Merge commits
+// and commits authored by exempted email addresses are skipped. Commits are
+// listed between the base branch and the head of the pull request, thus they
+// are checked again whenever new commits are pushed.
+func (pr *PullRequest) MissingSignOffs() ([]*MissingSignOff, error) {
+	if err := pr.LoadAttributes(); err != nil {
+		return nil, errors.Wrap(err, "load attributes")
+	} else if !pr.BaseRepo.RequireSignOff {
+		return nil, nil
+	}
+
+	const fieldSep, commitSep = "\x1f", "\x1e"
+	stdout, err := git.NewCommand(
+		"log", "--reverse", "--format=%H%x1f%P%x1f%an%x1f%ae%x1f%B%x1e",
+		fmt.Sprintf("%s..refs/pull/%d/head", git.RefsHeads+pr.BaseBranch, pr.Index),
+	).RunInDir(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "list commits")
+	}
+
+	var missing []*MissingSignOff
+	for _, entry := range strings.Split(string(stdout), commitSep) {
+		fields := strings.SplitN(strings.TrimLeft(entry, "\n"), fieldSep, 5)
+		if len(fields) != 5 {
+			continue
+		}
+		commitID, parents, authorName, authorEmail, message := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+		if len(strings.Fields(parents)) > 1 || pr.BaseRepo.isSignOffExempted(authorEmail) {
+			continue
+		}
+
+		signedOff := false
+		for _, s := range parseSignOffs(message) {
+			if strings.EqualFold(s.Email, authorEmail) {
+				signedOff = true
+				break
+			}
+		}
+		if signedOff {
+			continue
+		}
+
+		summary, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+		missing = append(missing, &MissingSignOff{
+			CommitID:    commitID,
+			Summary:     summary,
+			AuthorName:  authorName,
+			AuthorEmail: authorEmail,
+		})
+	}
+	return missing, nil
+}
+
+type ErrSignOffRequired struct {
+	args errutil.Args
+}
+
+// IsErrSignOffRequired returns true if the underlying error has the type
+// ErrSignOffRequired.
+func IsErrSignOffRequired(err error) bool {
+	_, ok := err.(ErrSignOffRequired)
+	return ok
+}
+
+func (err ErrSignOffRequired) Error() string {
+	return fmt.Sprintf("commits of pull request are not signed off: %v", err.args)
+}
+
+// checkSignOffs returns ErrSignOffRequired when the base repository requires
+// commits to be signed off but any of them is not.
+func (pr *PullRequest) checkSignOffs() error {
+	missing, err := pr.MissingSignOffs()
+	if err != nil {
+		return errors.Wrap(err, "get missing sign-offs")
+	} else if len(missing) > 0 {
+		commitIDs := make([]string, 0, len(missing))
+		for _, m := range missing {
+			commitIDs = append(commitIDs, m.CommitID)
+		}
+		return ErrSignOffRequired{args: errutil.Args{"pullRequestID": pr.ID, "commitIDs": commitIDs}}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestParseSignOffs(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []*SignOff
+	}{
+		{
+			name:    "no trailers",
+			message: "Fix typo\n\nSome details.",
+		},
+		{
+			name:    "subject only",
+			message: "Signed-off-by: Alice <alice@example.com>",
+		},
+		{
+			name:    "single sign-off",
+			message: "Fix typo\n\nSome details.\n\nSigned-off-by: Alice <alice@example.com>\n",
+			want:    []*SignOff{{Name: "Alice", Email: "alice@example.com"}},
+		},
+		{
+			name:    "multiple trailers",
+			message: "Fix typo\r\n\r\nCo-authored-by: Bob <bob@example.com>\r\nsigned-off-by: Bob <bob@example.com>\r\nSigned-off-by: Alice <alice@example.com>",
+			want: []*SignOff{
+				{Name: "Bob", Email: "bob@example.com"},
+				{Name: "Alice", Email: "alice@example.com"},
+			},
+		},
+		{
+			name:    "not in last paragraph",
+			message: "Fix typo\n\nSigned-off-by: Alice <alice@example.com>\n\nSome details.",
+		},
+		{
+			name:    "invalid address",
+			message: "Fix typo\n\nSigned-off-by: Alice",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, parseSignOffs(test.message))
+		})
+	}
+}
+
+func TestPullRequest_MissingSignOffs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(ProtectBranch))
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	owner := &User{ID: 1, Name: "alice"}
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, Name: "example", RequireSignOff: true}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name string, args ...string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(name), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run(append([]string{"commit", "-m", "Add " + name}, args...)...)
+	}
+	push := func() {
+		run("push", "--force", "origin", "main", "feature:refs/pull/1/head")
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("README.md")
+	run("checkout", "-b", "feature")
+	commit("a.txt", "--signoff")
+	commit("b.txt")
+	run("commit", "--allow-empty", "--author", "bot <bot@example.com>", "-m", "Bump dependencies")
+	run("checkout", "main")
+	commit("c.txt", "--signoff")
+	run("checkout", "feature")
+	run("merge", "--no-ff", "-m", "Merge branch 'main' into feature", "main")
+	push()
+
+	pr := &PullRequest{
+		ID:         1,
+		Index:      1,
+		HeadRepoID: repo.ID,
+		BaseRepoID: repo.ID,
+		HeadBranch: "feature",
+		BaseBranch: "main",
+		HeadRepo:   repo,
+		BaseRepo:   repo,
+	}
+	missingSummaries := func(t *testing.T) []string {
+		t.Helper()
+		missing, err := pr.MissingSignOffs()
+		require.NoError(t, err)
+		summaries := make([]string, 0, len(missing))
+		for _, m := range missing {
+			summaries = append(summaries, m.Summary)
+		}
+		return summaries
+	}
+
+	// Merge commits are exempted
+	assert.Equal(t, []string{"Add b.txt", "Bump dependencies"}, missingSummaries(t))
+
+	baseGitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	err = pr.Merge(owner, baseGitRepo, MERGE_STYLE_REGULAR, "")
+	assert.True(t, IsErrSignOffRequired(err), "want ErrSignOffRequired but got %v", err)
+
+	// Bots can be exempted by email
+	repo.SignOffAllowlistEmails = "ci@example.com, BOT@example.com"
+	assert.Equal(t, []string{"Add b.txt"}, missingSummaries(t))
+
+	// Sign-off must match the author
+	run("commit", "--allow-empty", "--author", "bob <bob@example.com>", "--signoff", "-m", "Update docs")
+	push()
+	assert.Equal(t, []string{"Add b.txt", "Update docs"}, missingSummaries(t))
+
+	// New commits are checked again after signing off all commits
+	run("commit", "--amend", "--allow-empty", "-m", "Update docs\n\nSigned-off-by: bob <bob@example.com>")
+	run("rebase", "--signoff", "main")
+	push()
+	assert.Empty(t, missingSummaries(t))
+	assert.NoError(t, pr.checkSignOffs())
+
+	repo.RequireSignOff = false
+	repo.SignOffAllowlistEmails = ""
+	run("reset", "--hard", "main")
+	commit("d.txt")
+	push()
+	assert.Empty(t, missingSummaries(t))
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/unknwon/com"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/process"
+)
+
+// MergeCheckState is the state of the test merge of a pull request.
+type MergeCheckState string
+
+const (
+	MergeCheckStateComputing MergeCheckState = "computing"
+	MergeCheckStateMergeable MergeCheckState = "mergeable"
+	MergeCheckStateConflict  MergeCheckState = "conflict"
+	MergeCheckStateMerged    MergeCheckState = "merged"
+)
+
+// MergeCheck is the result of the test merge of the head commit of a pull
+// request into the base commit.
+type MergeCheck struct {
+	State        MergeCheckState
+	BaseCommitID string
+	HeadCommitID string
+	MergeBase    string
+}
+
+// Mergeable returns true if the head commit can be merged into the base commit
+// without conflicts.
+func (c *MergeCheck) Mergeable() bool {
+	return c.State == MergeCheckStateMergeable
+}
+
+// mergeChecks caches results of test merges by pull request ID. A result is
+// only valid for the base and head commits it was computed for.
+var mergeChecks = struct {
+	sync.Mutex
+	results map[int64]*MergeCheck
+	running map[int64]bool
+}{
+	results: make(map[int64]*MergeCheck),
+	running: make(map[int64]bool),
+}
+
+// CheckMergeability returns the result of the test merge of the pull request.
+// The result is cached and recomputed in the background when the base branch or
+// the head branch has advanced, in which case the state is
+// MergeCheckStateComputing until the new result is available.
+//
+// This method assumes following fields have been assigned with valid values:
+// Required - BaseRepo, HeadRepo
+func (pr *PullRequest) CheckMergeability() (*MergeCheck, error) {
+	if pr.HasMerged {
+		return &MergeCheck{
+			State:        MergeCheckStateMerged,
+			HeadCommitID: pr.MergedCommitID,
+			MergeBase:    pr.MergeBase,
+		}, nil
+	}
+
+	baseRepoPath := pr.BaseRepo.RepoPath()
+	baseGitRepo, err := git.Open(baseRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("open base repository: %v", err)
+	}
+	baseCommitID, err := baseGitRepo.BranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("get base branch %q commit ID: %v", pr.BaseBranch, err)
+	}
+
+	headRepoPath := RepoPath(pr.HeadUserName, pr.HeadRepo.Name)
+	headGitRepo, err := git.Open(headRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("open head repository: %v", err)
+	}
+	headCommitID, err := headGitRepo.BranchCommitID(pr.HeadBranch)
+	if err != nil {
+		return nil, fmt.Errorf("get head branch %q commit ID: %v", pr.HeadBranch, err)
+	}
+
+	mergeChecks.Lock()
+	defer mergeChecks.Unlock()
+
+	result := mergeChecks.results[pr.ID]
+	if result != nil && result.BaseCommitID == baseCommitID && result.HeadCommitID == headCommitID {
+		return result, nil
+	}
+
+	if !mergeChecks.running[pr.ID] {
+		mergeChecks.running[pr.ID] = true
+		go func() {
+			result, err := testMerge(baseRepoPath, baseCommitID, headRepoPath, pr.HeadBranch, headCommitID)
+
+			mergeChecks.Lock()
+			defer mergeChecks.Unlock()
+			delete(mergeChecks.running, pr.ID)
+			if err != nil {
+				log.Error("Failed to test merge of pull request [id: %d]: %v", pr.ID, err)
+				return
+			}
+			mergeChecks.results[pr.ID] = result
+		}()
+	}
+
+	return &MergeCheck{
+		State:        MergeCheckStateComputing,
+		BaseCommitID: baseCommitID,
+		HeadCommitID: headCommitID,
+	}, nil
+}
+
+// testMerge merges the head commit into the base commit in a temporary worktree
+// of the base repository without committing to find out whether they conflict.
+func testMerge(baseRepoPath, baseCommitID, headRepoPath, headBranch, headCommitID string) (_ *MergeCheck, err error) {
+	tmpPath := filepath.Join(conf.Server.AppDataPath, "tmp", "merge-checks", com.ToStr(time.Now().UnixNano()))
+	if err = os.MkdirAll(filepath.Dir(tmpPath), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	var stderr string
+	if _, stderr, err = process.ExecDir(-1, baseRepoPath,
+		fmt.Sprintf("testMerge (git worktree add): %s", tmpPath),
+		"git", "worktree", "add", "--detach", tmpPath, baseCommitID); err != nil {
+		return nil, fmt.Errorf("git worktree add: %s", stderr)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpPath)
+		if _, stderr, err := process.ExecDir(-1, baseRepoPath,
+			fmt.Sprintf("testMerge (git worktree prune): %s", tmpPath),
+			"git", "worktree", "prune"); err != nil {
+			log.Error("Failed to prune worktrees [path: %s]: %s", baseRepoPath, stderr)
+		}
+	}()
+
+	// Fetch the head branch so that the head commit is available in the base
+	// repository.
+	if _, stderr, err = process.ExecDir(-1, tmpPath,
+		fmt.Sprintf("testMerge (git fetch): %s", tmpPath),
+		"git", "fetch", "--quiet", "--no-tags", headRepoPath, git.RefsHeads+headBranch); err != nil {
+		return nil, fmt.Errorf("git fetch [%s]: %s", headRepoPath, stderr)
+	}
+
+	result := &MergeCheck{
+		BaseCommitID: baseCommitID,
+		HeadCommitID: headCommitID,
+	}
+
+	// The merge base does not exist when the histories are unrelated, which then
+	// fails the test merge as well.
+	stdout, _, err := process.ExecDir(-1, tmpPath,
+		fmt.Sprintf("testMerge (git merge-base): %s", tmpPath),
+		"git", "merge-base", baseCommitID, headCommitID)
+	if err == nil {
+		result.MergeBase = strings.TrimSpace(stdout)
+	}
+
+	if _, stderr, err = process.ExecDir(-1, tmpPath,
+		fmt.Sprintf("testMerge (git merge --no-commit): %s", tmpPath),
+		"git", "-c", "user.name=Gogs", "-c", "user.email=gogs@localhost",
+		"merge", "--no-ff", "--no-commit", headCommitID); err != nil {
+		log.Trace("testMerge [%s]: has conflict\n%s", tmpPath, stderr)
+		result.State = MergeCheckStateConflict
+	} else {
+		result.State = MergeCheckStateMergeable
+	}
+	return result, nil
+}
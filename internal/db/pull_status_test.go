@@ -0,0 +1,134 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestPullRequest_CheckMergeability(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	conf.SetMockServer(t, conf.ServerOpts{AppDataPath: t.TempDir()})
+
+	alice := &User{ID: 1, Name: "alice"}
+	repo := &Repository{ID: 1, Name: "example", OwnerID: alice.ID, Owner: alice}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("README.md", "Hello")
+	run("push", "origin", "main")
+
+	run("checkout", "-b", "clean")
+	commit("feature.md", "Feature")
+	run("push", "origin", "clean")
+
+	run("checkout", "-b", "conflict", "main")
+	commit("README.md", "Hello from conflict")
+	run("push", "origin", "conflict")
+
+	run("checkout", "main")
+	commit("README.md", "Hello from main")
+	run("push", "origin", "main")
+
+	newPullRequest := func(id int64, headBranch string) *PullRequest {
+		return &PullRequest{
+			ID:           id,
+			BaseRepo:     repo,
+			HeadRepo:     repo,
+			HeadUserName: alice.Name,
+			BaseBranch:   "main",
+			HeadBranch:   headBranch,
+		}
+	}
+	waitForMergeCheck := func(t *testing.T, pr *PullRequest) *MergeCheck {
+		var got *MergeCheck
+		require.Eventually(t, func() bool {
+			var err error
+			got, err = pr.CheckMergeability()
+			return assert.NoError(t, err) && got.State != MergeCheckStateComputing
+		}, 10*time.Second, 10*time.Millisecond)
+		return got
+	}
+
+	t.Run("clean", func(t *testing.T) {
+		pr := newPullRequest(1, "clean")
+		got, err := pr.CheckMergeability()
+		require.NoError(t, err)
+		assert.Equal(t, MergeCheckStateComputing, got.State)
+		assert.False(t, got.Mergeable())
+
+		got = waitForMergeCheck(t, pr)
+		assert.Equal(t, MergeCheckStateMergeable, got.State)
+		assert.True(t, got.Mergeable())
+		assert.NotEmpty(t, got.MergeBase)
+
+		// The result should be cached as long as the base and head do not advance
+		again, err := pr.CheckMergeability()
+		require.NoError(t, err)
+		assert.Same(t, got, again)
+
+		run("checkout", "main")
+		commit("main.md", "Main")
+		run("push", "origin", "main")
+
+		again, err = pr.CheckMergeability()
+		require.NoError(t, err)
+		assert.Equal(t, MergeCheckStateComputing, again.State)
+		again = waitForMergeCheck(t, pr)
+		assert.Equal(t, MergeCheckStateMergeable, again.State)
+		assert.NotEqual(t, got.BaseCommitID, again.BaseCommitID)
+		assert.Equal(t, got.MergeBase, again.MergeBase)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		pr := newPullRequest(2, "conflict")
+		got := waitForMergeCheck(t, pr)
+		assert.Equal(t, MergeCheckStateConflict, got.State)
+		assert.False(t, got.Mergeable())
+	})
+
+	t.Run("merged", func(t *testing.T) {
+		pr := newPullRequest(3, "clean")
+		pr.HasMerged = true
+		got, err := pr.CheckMergeability()
+		require.NoError(t, err)
+		assert.Equal(t, MergeCheckStateMerged, got.State)
+	})
+}
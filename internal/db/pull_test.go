@@ -0,0 +1,131 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestPullRequest_UpdateBranch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(ProtectBranch))
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{ID: 1, Name: "alice", Email: "alice@example.com"}
+	repo := &Repository{ID: 1, Name: "example", OwnerID: alice.ID, Owner: alice}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit("README.md", "Hello")
+	run("push", "origin", "main")
+
+	run("checkout", "-b", "feature")
+	commit("feature.md", "Feature")
+	run("push", "origin", "feature")
+
+	run("checkout", "main")
+	commit("main.md", "Main")
+	run("push", "origin", "main")
+
+	_, err = x.Insert(&ProtectBranch{
+		RepoID:          repo.ID,
+		Name:            "main",
+		Protected:       true,
+		RequireUpToDate: true,
+	})
+	require.NoError(t, err)
+
+	pr := &PullRequest{
+		ID:           1,
+		Index:        1,
+		BaseRepoID:   repo.ID,
+		BaseRepo:     repo,
+		HeadRepoID:   repo.ID,
+		HeadRepo:     repo,
+		HeadUserName: alice.Name,
+		BaseBranch:   "main",
+		HeadBranch:   "feature",
+	}
+	err = pr.PushToBaseRepo()
+	require.NoError(t, err)
+
+	upToDate, err := pr.IsHeadUpToDate()
+	require.NoError(t, err)
+	assert.False(t, upToDate)
+
+	baseGitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	err = pr.Merge(alice, baseGitRepo, MERGE_STYLE_REGULAR, "")
+	assert.True(t, IsErrPullRequestOutOfDate(err), "want ErrPullRequestOutOfDate but got %v", err)
+
+	err = pr.UpdateBranch(alice)
+	require.NoError(t, err)
+
+	upToDate, err = pr.IsHeadUpToDate()
+	require.NoError(t, err)
+	assert.True(t, upToDate)
+
+	t.Run("conflict", func(t *testing.T) {
+		run("checkout", "-b", "conflict", "main")
+		commit("README.md", "Hello from conflict")
+		run("push", "origin", "conflict")
+
+		run("checkout", "main")
+		commit("README.md", "Hello from main")
+		run("push", "origin", "main")
+
+		pr := *pr
+		pr.ID = 2
+		pr.Index = 2
+		pr.HeadBranch = "conflict"
+		err = pr.UpdateBranch(alice)
+		assert.True(t, IsErrPullRequestUpdateConflict(err), "want ErrPullRequestUpdateConflict but got %v", err)
+	})
+
+	t.Run("protected head branch", func(t *testing.T) {
+		_, err := x.Insert(&ProtectBranch{
+			RepoID:    repo.ID,
+			Name:      "feature",
+			Protected: true,
+		})
+		require.NoError(t, err)
+
+		err = pr.UpdateBranch(alice)
+		assert.True(t, IsErrPullRequestHeadProtected(err), "want ErrPullRequestHeadProtected but got %v", err)
+	})
+}
@@ -5,6 +5,7 @@
 package db
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"strings"
@@ -15,7 +16,9 @@ import (
 
 	"github.com/gogs/git-module"
 	api "github.com/gogs/go-gogs-client"
+	jsoniter "github.com/json-iterator/go"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/process"
 )
@@ -107,6 +110,72 @@ func (r *Release) APIFormat() *api.Release {
 	}
 }
 
+// APIReleaseAsset is the API format of an uploaded file of a release.
+type APIReleaseAsset struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	DownloadURL string    `json:"browser_download_url"`
+	Created     time.Time `json:"created_at"`
+}
+
+// APIRelease extends api.Release with uploaded assets of the release.
+type APIRelease struct {
+	*api.Release
+	Assets []*APIReleaseAsset `json:"assets"`
+}
+
+// apiFormatWithAssets returns the API format of the release with uploaded
+// assets. This method assumes the publisher and attachments are loaded.
+func (r *Release) apiFormatWithAssets() *APIRelease {
+	assets := make([]*APIReleaseAsset, len(r.Attachments))
+	for i, a := range r.Attachments {
+		assets[i] = &APIReleaseAsset{
+			ID:          a.ID,
+			Name:        a.Name,
+			DownloadURL: conf.Server.ExternalURL + "attachments/" + a.UUID,
+			Created:     a.Created,
+		}
+	}
+	return &APIRelease{
+		Release: r.APIFormat(),
+		Assets:  assets,
+	}
+}
+
+// Actions of release events in addition to api.HOOK_RELEASE_PUBLISHED.
+const (
+	HOOK_RELEASE_UPDATED api.HookReleaseAction = "updated"
+	HOOK_RELEASE_DELETED api.HookReleaseAction = "deleted"
+)
+
+// ReleasePayload represents a payload information of release event, which
+// extends api.ReleasePayload with uploaded assets of the release.
+type ReleasePayload struct {
+	Action     api.HookReleaseAction `json:"action"`
+	Release    *APIRelease           `json:"release"`
+	Repository *api.Repository       `json:"repository"`
+	Sender     *api.User             `json:"sender"`
+}
+
+func (p *ReleasePayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// SHA256Sums returns the content of the "SHA256SUMS" file of attachments in
+// the format of the "sha256sum" command. Attachments without checksums, i.e.
+// their files were missing when checksums were backfilled, are omitted. This
+// method assumes attachments are loaded.
+func (r *Release) SHA256Sums() []byte {
+	var buf bytes.Buffer
+	for _, a := range r.Attachments {
+		if a.SHA256 == "" {
+			continue
+		}
+		_, _ = fmt.Fprintf(&buf, "%s  %s\n", a.SHA256, a.Name)
+	}
+	return buf.Bytes()
+}
+
 // IsReleaseExist returns true if release with given tag name already exists.
 func IsReleaseExist(repoID int64, tagName string) (bool, error) {
 	if tagName == "" {
@@ -116,6 +185,41 @@ func IsReleaseExist(repoID int64, tagName string) (bool, error) {
 	return x.Get(&Release{RepoID: repoID, LowerTagName: strings.ToLower(tagName)})
 }
 
+// signedTagOptions returns options to create an annotated tag signed with the
+// server GPG key for the release when signing is enabled, with the publisher as
+// the tagger.
+func signedTagOptions(r *Release) (git.CreateTagOptions, error) {
+	if !conf.Release.SignTags {
+		return git.CreateTagOptions{}, nil
+	}
+
+	publisher, err := getUserByID(x, r.PublisherID)
+	if err != nil {
+		return git.CreateTagOptions{}, fmt.Errorf("get publisher: %v", err)
+	}
+
+	args := []string{"--sign"}
+	if conf.Release.SigningKey != "" {
+		args = append(args, "--local-user="+conf.Release.SigningKey)
+	}
+	message := r.Title
+	if message == "" {
+		message = r.TagName
+	}
+	return git.CreateTagOptions{
+		Annotated: true,
+		Message:   message,
+		Author: &git.Signature{
+			Name:  publisher.DisplayName(),
+			Email: publisher.CommitEmail(),
+			When:  time.Now(),
+		},
+		CommandOptions: git.CommandOptions{
+			Args: args,
+		},
+	}, nil
+}
+
 func createTag(gitRepo *git.Repository, r *Release) error {
 	// Only actual create when publish.
 	if !r.IsDraft {
@@ -127,12 +231,23 @@ func createTag(gitRepo *git.Repository, r *Release) error {
 
 			// Trim '--' prefix to prevent command line argument vulnerability.
 			r.TagName = strings.TrimPrefix(r.TagName, "--")
-			if err = gitRepo.CreateTag(r.TagName, commit.ID.String()); err != nil {
+			opts, err := signedTagOptions(r)
+			if err != nil {
+				return fmt.Errorf("get signed tag options: %v", err)
+			}
+			if err = gitRepo.CreateTag(r.TagName, commit.ID.String(), opts); err != nil {
 				if strings.Contains(err.Error(), "is not a valid tag name") {
 					return ErrInvalidTagName{r.TagName}
 				}
 				return err
 			}
+
+			// The target could have advanced since a draft was saved.
+			r.Sha1 = commit.ID.String()
+			r.NumCommits, err = commit.CommitsCount()
+			if err != nil {
+				return fmt.Errorf("count commits: %v", err)
+			}
 		} else {
 			commit, err := gitRepo.TagCommit(r.TagName)
 			if err != nil {
@@ -149,12 +264,14 @@ func createTag(gitRepo *git.Repository, r *Release) error {
 	return nil
 }
 
-func (r *Release) preparePublishWebhooks() {
-	if err := PrepareWebhooks(r.Repo, HOOK_EVENT_RELEASE, &api.ReleasePayload{
-		Action:     api.HOOK_RELEASE_PUBLISHED,
-		Release:    r.APIFormat(),
+// prepareWebhooks adds hook tasks of release event with the action done by the
+// doer. This method assumes attributes of the release are loaded.
+func (r *Release) prepareWebhooks(doer *User, action api.HookReleaseAction) {
+	if err := PrepareWebhooks(r.Repo, HOOK_EVENT_RELEASE, &ReleasePayload{
+		Action:     action,
+		Release:    r.apiFormatWithAssets(),
 		Repository: r.Repo.APIFormatLegacy(nil),
-		Sender:     r.Publisher.APIFormat(),
+		Sender:     doer.APIFormat(),
 	}); err != nil {
 		log.Error("PrepareWebhooks: %v", err)
 	}
@@ -202,7 +319,7 @@ func NewRelease(gitRepo *git.Repository, r *Release, uuids []string) error {
 	if err != nil {
 		return fmt.Errorf("GetReleaseByID: %v", err)
 	}
-	r.preparePublishWebhooks()
+	r.prepareWebhooks(r.Publisher, api.HOOK_RELEASE_PUBLISHED)
 	return nil
 }
 
@@ -267,6 +384,31 @@ func GetPublishedReleasesByRepoID(repoID int64, matches ...string) ([]*Release,
 	return releases, sess.Find(&releases, new(Release))
 }
 
+// GetLatestReleaseByRepoID returns the most recently created release of
+// repository that is neither a draft nor a prerelease.
+func GetLatestReleaseByRepoID(repoID int64) (*Release, error) {
+	r := new(Release)
+	has, err := x.Where("repo_id = ?", repoID).
+		And("is_draft = ?", false).
+		And("is_prerelease = ?", false).
+		Desc("created_unix").Desc("id").
+		Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrReleaseNotExist{args: map[string]any{"repoID": repoID, "latest": true}}
+	}
+
+	return r, r.LoadAttributes()
+}
+
+// AddReleaseAttachment links the attachment to the release.
+func AddReleaseAttachment(releaseID int64, a *Attachment) error {
+	a.ReleaseID = releaseID
+	_, err := x.ID(a.ID).Cols("release_id").Update(a)
+	return err
+}
+
 // GetReleasesByRepoID returns a list of all releases (including drafts) of given repository.
 func GetReleasesByRepoID(repoID int64) ([]*Release, error) {
 	releases := make([]*Release, 0)
@@ -305,14 +447,18 @@ func SortReleases(rels []*Release) {
 	sort.Sort(sorter)
 }
 
-// UpdateRelease updates information of a release.
+// UpdateRelease updates information of a release. The release event is fired
+// with the action "published" when publishing a draft, or "updated" when the
+// release has already been published. Editing drafts does not fire events.
 func UpdateRelease(doer *User, gitRepo *git.Repository, r *Release, isPublish bool, uuids []string) (err error) {
+	r.PublisherID = doer.ID
 	if err = createTag(gitRepo, r); err != nil {
+		if IsErrInvalidTagName(err) {
+			return err
+		}
 		return fmt.Errorf("createTag: %v", err)
 	}
 
-	r.PublisherID = doer.ID
-
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -337,16 +483,25 @@ func UpdateRelease(doer *User, gitRepo *git.Repository, r *Release, isPublish bo
 		return fmt.Errorf("Commit: %v", err)
 	}
 
-	if !isPublish {
+	if r.IsDraft {
 		return nil
 	}
-	r.Publisher = doer
-	r.preparePublishWebhooks()
+	r, err = GetReleaseByID(r.ID)
+	if err != nil {
+		return fmt.Errorf("GetReleaseByID: %v", err)
+	}
+	action := HOOK_RELEASE_UPDATED
+	if isPublish {
+		action = api.HOOK_RELEASE_PUBLISHED
+	}
+	r.prepareWebhooks(doer, action)
 	return nil
 }
 
-// DeleteReleaseOfRepoByID deletes a release and corresponding Git tag by given ID.
-func DeleteReleaseOfRepoByID(repoID, id int64) error {
+// DeleteReleaseOfRepoByID deletes a release and corresponding Git tag by given
+// ID, the release event is fired with the action "deleted" by the doer unless
+// the release is a draft.
+func DeleteReleaseOfRepoByID(doer *User, repoID, id int64) error {
 	rel, err := GetReleaseByID(id)
 	if err != nil {
 		return fmt.Errorf("GetReleaseByID: %v", err)
@@ -362,16 +517,22 @@ func DeleteReleaseOfRepoByID(repoID, id int64) error {
 		return fmt.Errorf("GetRepositoryByID: %v", err)
 	}
 
-	_, stderr, err := process.ExecDir(-1, repo.RepoPath(),
-		fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID),
-		"git", "tag", "-d", rel.TagName)
-	if err != nil && !strings.Contains(stderr, "not found") {
-		return fmt.Errorf("git tag -d: %v - %s", err, stderr)
+	// Tags are only created for published releases.
+	if !rel.IsDraft {
+		_, stderr, err := process.ExecDir(-1, repo.RepoPath(),
+			fmt.Sprintf("DeleteReleaseByID (git tag -d): %d", rel.ID),
+			"git", "tag", "-d", rel.TagName)
+		if err != nil && !strings.Contains(stderr, "not found") {
+			return fmt.Errorf("git tag -d: %v - %s", err, stderr)
+		}
 	}
 
 	if _, err = x.Id(rel.ID).Delete(new(Release)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
 
+	if !rel.IsDraft {
+		rel.prepareWebhooks(doer, HOOK_RELEASE_DELETED)
+	}
 	return nil
 }
@@ -0,0 +1,221 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestRelease_draftPublishing(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(User), new(Repository), new(Release), new(Attachment), new(Webhook), new(HookTask))
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{Name: "alice", LowerName: "alice", Email: "alice@example.com"}
+	_, err := x.Insert(alice)
+	require.NoError(t, err)
+	repo := &Repository{Name: "example", LowerName: "example", OwnerID: alice.ID, Owner: alice}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	repoPath := repo.RepoPath()
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	err = os.WriteFile(filepath.Join(workPath, "README.md"), []byte("Hello"), 0o644)
+	require.NoError(t, err)
+	run("add", "README.md")
+	run("commit", "-m", "Initial commit")
+	run("push", "origin", "main")
+
+	gitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent: &HookEvent{
+			ChooseEvents: true,
+			HookEvents:   HookEvents{Release: true},
+		},
+		IsActive: true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	type releasePayload struct {
+		Action  string `json:"action"`
+		Release struct {
+			TagName string `json:"tag_name"`
+			Draft   bool   `json:"draft"`
+			Assets  []any  `json:"assets"`
+		} `json:"release"`
+	}
+	// listPayloads returns payloads of release events fired since the last call.
+	var numTasks int
+	listPayloads := func(t *testing.T) []*releasePayload {
+		var tasks []*HookTask
+		err := x.Asc("id").Find(&tasks)
+		require.NoError(t, err)
+		tasks = tasks[numTasks:]
+		numTasks += len(tasks)
+
+		payloads := make([]*releasePayload, len(tasks))
+		for i := range tasks {
+			assert.Equal(t, HOOK_EVENT_RELEASE, tasks[i].EventType)
+			payloads[i] = new(releasePayload)
+			err = jsoniter.Unmarshal([]byte(tasks[i].PayloadContent), payloads[i])
+			require.NoError(t, err)
+		}
+		return payloads
+	}
+
+	draft := &Release{
+		RepoID:      repo.ID,
+		PublisherID: alice.ID,
+		TagName:     "v1.0.0",
+		Target:      "main",
+		Title:       "v1.0.0",
+		IsDraft:     true,
+	}
+	err = NewRelease(gitRepo, draft, nil)
+	require.NoError(t, err)
+	assert.False(t, gitRepo.HasTag("v1.0.0"), "draft should not create the tag")
+	assert.Empty(t, listPayloads(t), "creating a draft should not fire")
+
+	_, err = GetLatestReleaseByRepoID(repo.ID)
+	assert.True(t, IsErrReleaseNotExist(err), "drafts should not be the latest release")
+
+	draft, err = GetReleaseByID(draft.ID)
+	require.NoError(t, err)
+	draft.Note = "Work in progress"
+	err = UpdateRelease(alice, gitRepo, draft, false, nil)
+	require.NoError(t, err)
+	assert.Empty(t, listPayloads(t), "editing a draft should not fire")
+
+	draft, err = GetReleaseByID(draft.ID)
+	require.NoError(t, err)
+	draft.IsDraft = false
+	err = UpdateRelease(alice, gitRepo, draft, true, nil)
+	require.NoError(t, err)
+	assert.True(t, gitRepo.HasTag("v1.0.0"), "publishing should create the tag")
+
+	payloads := listPayloads(t)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "published", payloads[0].Action)
+	assert.Equal(t, "v1.0.0", payloads[0].Release.TagName)
+	assert.False(t, payloads[0].Release.Draft)
+	assert.NotNil(t, payloads[0].Release.Assets)
+
+	mainID, err := gitRepo.BranchCommitID("main")
+	require.NoError(t, err)
+	got, err := GetReleaseByID(draft.ID)
+	require.NoError(t, err)
+	assert.False(t, got.IsDraft)
+	assert.Equal(t, mainID, got.Sha1)
+	assert.Equal(t, int64(1), got.NumCommits)
+
+	got.Note = "Release notes"
+	err = UpdateRelease(alice, gitRepo, got, false, nil)
+	require.NoError(t, err)
+	payloads = listPayloads(t)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "updated", payloads[0].Action)
+
+	t.Run("delete draft keeps existing tag", func(t *testing.T) {
+		draft := &Release{
+			RepoID:      repo.ID,
+			PublisherID: alice.ID,
+			TagName:     "v0.9.0",
+			Target:      "main",
+			IsDraft:     true,
+		}
+		err := gitRepo.CreateTag("v0.9.0", mainID)
+		require.NoError(t, err)
+		err = NewRelease(gitRepo, draft, nil)
+		require.NoError(t, err)
+
+		err = DeleteReleaseOfRepoByID(alice, repo.ID, draft.ID)
+		require.NoError(t, err)
+		assert.True(t, gitRepo.HasTag("v0.9.0"))
+	})
+
+	t.Run("delete published release", func(t *testing.T) {
+		err := DeleteReleaseOfRepoByID(alice, repo.ID, draft.ID)
+		require.NoError(t, err)
+		assert.False(t, gitRepo.HasTag("v1.0.0"))
+
+		payloads := listPayloads(t)
+		require.Len(t, payloads, 1, "deleting drafts should not fire")
+		assert.Equal(t, "deleted", payloads[0].Action)
+		assert.Equal(t, "v1.0.0", payloads[0].Release.TagName)
+	})
+}
+
+func TestGetLatestReleaseByRepoID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Release), new(Attachment))
+
+	alice := &User{Name: "alice", LowerName: "alice"}
+	_, err := x.Insert(alice)
+	require.NoError(t, err)
+	repo := &Repository{Name: "example", LowerName: "example", OwnerID: alice.ID}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	_, err = GetLatestReleaseByRepoID(repo.ID)
+	assert.True(t, IsErrReleaseNotExist(err), "want ErrReleaseNotExist but got %v", err)
+
+	releases := []*Release{
+		{TagName: "v1.0.0", CreatedUnix: 100},
+		{TagName: "v1.1.0", CreatedUnix: 200},
+		{TagName: "v2.0.0-rc.1", CreatedUnix: 300, IsPrerelease: true},
+		{TagName: "v2.0.0", CreatedUnix: 400, IsDraft: true},
+		{TagName: "v1.1.1", CreatedUnix: 200},
+	}
+	for _, r := range releases {
+		r.RepoID = repo.ID
+		r.PublisherID = alice.ID
+		r.LowerTagName = r.TagName
+		_, err = x.Insert(r)
+		require.NoError(t, err)
+	}
+
+	// Ties of created time are broken by the most recently inserted.
+	got, err := GetLatestReleaseByRepoID(repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.1", got.TagName)
+}
@@ -33,7 +33,6 @@ import (
 	embedConf "gogs.io/gogs/conf"
 	"gogs.io/gogs/internal/avatar"
 	"gogs.io/gogs/internal/conf"
-	dberrors "gogs.io/gogs/internal/db/errors"
 	"gogs.io/gogs/internal/dbutil"
 	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/markup"
@@ -66,7 +65,7 @@ func LoadRepoConfig() {
 			log.Fatal("Failed to get %q files: %v", t, err)
 		}
 
-		customPath := filepath.Join(conf.CustomDir(), "conf", t)
+		customPath := filepath.Join(conf.Repository.InitTemplatesPath, t)
 		if com.IsDir(customPath) {
 			customFiles, err := com.StatDir(customPath)
 			if err != nil {
@@ -159,10 +158,17 @@ type Repository struct {
 	Name            string `xorm:"INDEX NOT NULL" gorm:"index;not null"`
 	Description     string `xorm:"VARCHAR(512)" gorm:"type:VARCHAR(512)"`
 	Website         string
+	Topics          []string `xorm:"-" gorm:"-" json:"-"`
 	DefaultBranch   string
 	Size            int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
 	UseCustomAvatar bool
 
+	// Storage statistics, computed at the default branch commit SizeCommitID
+	ObjectCount     int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	LFSSize         int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	SizeCommitID    string
+	SizeUpdatedUnix int64
+
 	// Counters
 	NumWatches          int
 	NumStars            int
@@ -177,6 +183,9 @@ type Repository struct {
 	NumClosedMilestones int `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
 	NumOpenMilestones   int `xorm:"-" gorm:"-" json:"-"`
 	NumTags             int `xorm:"-" gorm:"-" json:"-"`
+	// The offset of the issue index counter, which is only non-zero for
+	// repositories that have issues imported from elsewhere.
+	IssueIndexOffset int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
 
 	IsPrivate bool
 	// TODO: When migrate to GORM, make sure to do a loose migration with `HasColumn` and `AddColumn`,
@@ -194,6 +203,7 @@ type Repository struct {
 	ExternalWikiURL       string
 	EnableIssues          bool `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
 	AllowPublicIssues     bool
+	IssuesReadOnly        bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 	EnableExternalTracker bool
 	ExternalTrackerURL    string
 	ExternalTrackerFormat string
@@ -202,6 +212,66 @@ type Repository struct {
 	EnablePulls           bool              `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
 	PullsIgnoreWhitespace bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 	PullsAllowRebase      bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	PullsAutosquash       bool              `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	EnableReleases        bool              `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
+	EnableLFS             bool              `xorm:"NOT NULL DEFAULT true" gorm:"not null;default:TRUE"`
+
+	// Whether to delete head branches of pull requests after they are merged,
+	// except branches that match any of the comma separated globs, e.g.
+	// "release/*,develop".
+	PullsDeleteBranchAfterMerge bool   `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	PullsDeleteBranchExemptions string `xorm:"TEXT" gorm:"type:TEXT"`
+
+	// The prefix of keys of issues and pull requests, e.g. PROJ for PROJ-123,
+	// empty means using numbers only, e.g. #123.
+	IssueKeyPrefix string `xorm:"VARCHAR(10)" gorm:"type:VARCHAR(10)"`
+
+	// The regular expression that names of new branches must match, empty means
+	// use the default of the organization.
+	BranchNamePattern string `xorm:"VARCHAR(255)" gorm:"type:VARCHAR(255)"`
+
+	// The regular expression that subjects of pushed commit messages must match,
+	// empty means use the default of the organization. Merge commits are not
+	// checked when exempted.
+	CommitMessagePattern      string `xorm:"VARCHAR(255)" gorm:"type:VARCHAR(255)"`
+	CommitMessageExemptMerges bool   `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+
+	// Limits of objects introduced by pushes, zero values mean use the default of
+	// the organization. Sizes are in MB, and file patterns are comma separated.
+	PushMaxSize             int64  `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	PushMaxBlobSize         int64  `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	PushBlockedFilePatterns string `xorm:"VARCHAR(1024)" gorm:"type:VARCHAR(1024)"`
+
+	// Contributor license agreement (CLA) settings
+	RequireCLA          bool   `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	CLADocumentURL      string `xorm:"VARCHAR(512)" gorm:"type:VARCHAR(512)"`
+	CLAExemptOrgMembers bool   `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	CLAAllowlistUserIDs string `xorm:"TEXT" gorm:"column:cla_allowlist_user_i_ds;type:TEXT"`
+
+	// Developer Certificate of Origin (DCO) settings, commits authored by
+	// allowlisted email addresses (e.g. bots) are not required to be signed off.
+	RequireSignOff         bool   `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	SignOffAllowlistEmails string `xorm:"TEXT" gorm:"type:TEXT"`
+
+	// Automatic assignment of new issues to members of a team
+	AutoAssignTeamID             int64
+	AutoAssignStrategy           AutoAssignStrategy `xorm:"VARCHAR(20) NOT NULL DEFAULT ''" gorm:"type:VARCHAR(20);not null;default:''"`
+	AutoAssignUnavailableUserIDs string             `xorm:"TEXT" gorm:"column:auto_assign_unavailable_user_i_ds;type:TEXT"`
+	// The ID of the user who was assigned last by the round-robin strategy
+	AutoAssignCursor int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+
+	// Users and teams who are requested to review every new pull request
+	DefaultReviewerUserIDs string `xorm:"TEXT" gorm:"column:default_reviewer_user_i_ds;type:TEXT"`
+	DefaultReviewerTeamIDs string `xorm:"TEXT" gorm:"column:default_reviewer_team_i_ds;type:TEXT"`
+	// The user who is assigned new pull requests opened without an assignee
+	DefaultPullAssigneeID int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+
+	// Requesting reviews of new pull requests from members of a team in turns
+	ReviewRotationTeamID   int64
+	ReviewRotationStrategy AutoAssignStrategy `xorm:"VARCHAR(20) NOT NULL DEFAULT ''" gorm:"type:VARCHAR(20);not null;default:''"`
+	ReviewRotationCount    int                `xorm:"NOT NULL DEFAULT 1" gorm:"not null;default:1"`
+	// The ID of the user who was requested last by the round-robin strategy
+	ReviewRotationCursor int64 `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
 
 	IsFork   bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
 	ForkID   int64
@@ -282,6 +352,26 @@ func (repo *Repository) CanGuestViewIssues() bool {
 	return repo.EnableIssues && !repo.EnableExternalTracker && repo.AllowPublicIssues
 }
 
+// CanReadIssues returns true if a user with given access mode to the repository
+// can read its issues. Issues are readable by anyone when public access to
+// issues is allowed, regardless of the visibility of the code.
+func (repo *Repository) CanReadIssues(mode AccessMode) bool {
+	if !repo.EnableIssues || repo.EnableExternalTracker {
+		return false
+	}
+	return mode >= AccessModeRead || repo.AllowPublicIssues
+}
+
+// CanCreateIssues returns true if a signed-in user with given access mode to
+// the repository can create issues and comment on them. Only users with write
+// access can do so when issues are read-only.
+func (repo *Repository) CanCreateIssues(mode AccessMode) bool {
+	if !repo.CanReadIssues(mode) {
+		return false
+	}
+	return !repo.IssuesReadOnly || mode >= AccessModeWrite
+}
+
 // MustOwner always returns a valid *User object to avoid conceptually impossible error handling.
 // It creates a fake object that contains error details when error occurs.
 func (repo *Repository) MustOwner() *User {
@@ -433,14 +523,23 @@ func (repo *Repository) mustOwner(e Engine) *User {
 	return repo.Owner
 }
 
+// UpdateSize recomputes and saves the size and number of objects of the
+// repository, and the total size of its LFS objects.
 func (repo *Repository) UpdateSize() error {
 	countObject, err := git.CountObjects(repo.RepoPath())
 	if err != nil {
 		return fmt.Errorf("count repository objects: %v", err)
 	}
+	lfsSize, err := LFS.TotalSizeByRepoID(context.TODO(), repo.ID)
+	if err != nil {
+		return fmt.Errorf("get total size of LFS objects: %v", err)
+	}
 
-	repo.Size = countObject.Size + countObject.SizePack
-	if _, err = x.Id(repo.ID).Cols("size").Update(repo); err != nil {
+	repo.Size, repo.ObjectCount = repoStorageStats(countObject)
+	repo.LFSSize = lfsSize
+	repo.SizeCommitID = repo.defaultBranchCommitID()
+	repo.SizeUpdatedUnix = time.Now().Unix()
+	if _, err = x.Id(repo.ID).Cols("size", "object_count", "lfs_size", "size_commit_id", "size_updated_unix").Update(repo); err != nil {
 		return fmt.Errorf("update size: %v", err)
 	}
 	return nil
@@ -454,10 +553,16 @@ func (repo *Repository) ComposeMetas() map[string]string {
 
 	repo.ExternalMetas = map[string]string{
 		"repoLink": repo.Link(),
+		// The owner is also used to resolve references to issues of repositories
+		// with the same owner, e.g. docs#123.
+		"user": repo.MustOwner().Name,
+	}
+
+	if repo.IssueKeyPrefix != "" {
+		repo.ExternalMetas["issueKeyPrefix"] = repo.IssueKeyPrefix
 	}
 
 	if repo.EnableExternalTracker {
-		repo.ExternalMetas["user"] = repo.MustOwner().Name
 		repo.ExternalMetas["repo"] = repo.Name
 		repo.ExternalMetas["format"] = repo.ExternalTrackerFormat
 
@@ -472,6 +577,20 @@ func (repo *Repository) ComposeMetas() map[string]string {
 	return repo.ExternalMetas
 }
 
+// ExternalIssueURL returns the URL of the issue with given index in the external
+// issue tracker, or the URL of the external issue tracker itself when the URL
+// format is not set.
+func (repo *Repository) ExternalIssueURL(index string) string {
+	if repo.ExternalTrackerFormat == "" {
+		return repo.ExternalTrackerURL
+	}
+	return com.Expand(repo.ExternalTrackerFormat, map[string]string{
+		"user":  repo.MustOwner().Name,
+		"repo":  repo.Name,
+		"index": index,
+	})
+}
+
 // DeleteWiki removes the actual and local copy of repository wiki.
 func (repo *Repository) DeleteWiki() {
 	wikiPaths := []string{repo.WikiPath(), repo.LocalWikiPath()}
@@ -631,7 +750,7 @@ func (repo *Repository) CanEnableEditor() bool {
 // FIXME: should have a mutex to prevent producing same index for two issues that are created
 // closely enough.
 func (repo *Repository) NextIssueIndex() int64 {
-	return int64(repo.NumIssues+repo.NumPulls) + 1
+	return repo.IssueIndexOffset + int64(repo.NumIssues+repo.NumPulls) + 1
 }
 
 func (repo *Repository) LocalCopyPath() string {
@@ -730,20 +849,8 @@ func IsRepositoryExist(u *User, repoName string) (bool, error) {
 
 // Deprecated: Use repoutil.NewCloneLink instead.
 func (repo *Repository) cloneLink(isWiki bool) *repoutil.CloneLink {
-	repoName := repo.Name
-	if isWiki {
-		repoName += ".wiki"
-	}
-
 	repo.Owner = repo.MustOwner()
-	cl := new(repoutil.CloneLink)
-	if conf.SSH.Port != 22 {
-		cl.SSH = fmt.Sprintf("ssh://%s@%s:%d/%s/%s.git", conf.App.RunUser, conf.SSH.Domain, conf.SSH.Port, repo.Owner.Name, repoName)
-	} else {
-		cl.SSH = fmt.Sprintf("%s@%s:%s/%s.git", conf.App.RunUser, conf.SSH.Domain, repo.Owner.Name, repoName)
-	}
-	cl.HTTPS = repoutil.HTTPSCloneURL(repo.Owner.Name, repoName)
-	return cl
+	return repoutil.NewCloneLink(repo.Owner.Name, repo.Name, isWiki)
 }
 
 // CloneLink returns clone URLs of repository.
@@ -971,13 +1078,82 @@ func getRepoInitFile(tp, name string) ([]byte, error) {
 	relPath := path.Join(tp, strings.TrimLeft(path.Clean("/"+name), "/"))
 
 	// Use custom file when available.
-	customPath := filepath.Join(conf.CustomDir(), "conf", relPath)
+	customPath := filepath.Join(conf.Repository.InitTemplatesPath, relPath)
 	if osutil.IsFile(customPath) {
 		return os.ReadFile(customPath)
 	}
 	return embedConf.Files.ReadFile(relPath)
 }
 
+var _ errutil.NotFound = (*ErrRepoInitTemplateNotExist)(nil)
+
+type ErrRepoInitTemplateNotExist struct {
+	args errutil.Args
+}
+
+// IsErrRepoInitTemplateNotExist returns true if the underlying error has the
+// type ErrRepoInitTemplateNotExist.
+func IsErrRepoInitTemplateNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoInitTemplateNotExist)
+	return ok
+}
+
+// Value returns the name of the template that does not exist.
+func (err ErrRepoInitTemplateNotExist) Value() string {
+	val, _ := err.args["name"].(string)
+	return val
+}
+
+func (err ErrRepoInitTemplateNotExist) Error() string {
+	return fmt.Sprintf("repository initialization template does not exist: %v", err.args)
+}
+
+func (ErrRepoInitTemplateNotExist) NotFound() bool {
+	return true
+}
+
+type ErrRepoVisibilityNotAllowed struct {
+	args errutil.Args
+}
+
+// IsErrRepoVisibilityNotAllowed returns true if the underlying error has the
+// type ErrRepoVisibilityNotAllowed.
+func IsErrRepoVisibilityNotAllowed(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoVisibilityNotAllowed)
+	return ok
+}
+
+func (err ErrRepoVisibilityNotAllowed) Error() string {
+	return fmt.Sprintf("public repository is not allowed for the owner: %v", err.args)
+}
+
+// checkRepoVisibility returns ErrRepoVisibilityNotAllowed when the owner is not
+// allowed to own a repository with given visibility.
+func checkRepoVisibility(owner *User, isPrivate bool) error {
+	if isPrivate || owner.IsPublicRepoAllowed() {
+		return nil
+	}
+	return ErrRepoVisibilityNotAllowed{args: errutil.Args{"ownerID": owner.ID}}
+}
+
+// ValidateRepoInitTemplates returns ErrRepoInitTemplateNotExist when any of the
+// given comma-separated .gitignore templates, license or README template does
+// not exist. Empty names are skipped.
+func ValidateRepoInitTemplates(gitignores, license, readme string) error {
+	for _, name := range strings.Split(gitignores, ",") {
+		if name != "" && !com.IsSliceContainsStr(Gitignores, name) {
+			return ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "gitignore", "name": name}}
+		}
+	}
+	if license != "" && !com.IsSliceContainsStr(Licenses, license) {
+		return ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "license", "name": license}}
+	}
+	if readme != "" && !com.IsSliceContainsStr(Readmes, readme) {
+		return ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "readme", "name": readme}}
+	}
+	return nil
+}
+
 func prepareRepoCommit(repo *Repository, tmpDir, repoPath string, opts CreateRepoOptionsLegacy) error {
 	// Clone to temporary path and do the init commit.
 	err := git.Clone(repoPath, tmpDir, git.CloneOptions{})
@@ -986,21 +1162,24 @@ func prepareRepoCommit(repo *Repository, tmpDir, repoPath string, opts CreateRep
 	}
 
 	// README
-	data, err := getRepoInitFile("readme", opts.Readme)
-	if err != nil {
-		return fmt.Errorf("getRepoInitFile[%s]: %v", opts.Readme, err)
-	}
+	var data []byte
+	if len(opts.Readme) > 0 {
+		data, err = getRepoInitFile("readme", opts.Readme)
+		if err != nil {
+			return fmt.Errorf("getRepoInitFile[%s]: %v", opts.Readme, err)
+		}
 
-	cloneLink := repo.CloneLink()
-	match := map[string]string{
-		"Name":           repo.Name,
-		"Description":    repo.Description,
-		"CloneURL.SSH":   cloneLink.SSH,
-		"CloneURL.HTTPS": cloneLink.HTTPS,
-	}
-	if err = os.WriteFile(filepath.Join(tmpDir, "README.md"),
-		[]byte(com.Expand(string(data), match)), 0644); err != nil {
-		return fmt.Errorf("write README.md: %v", err)
+		cloneLink := repo.CloneLink()
+		match := map[string]string{
+			"Name":           repo.Name,
+			"Description":    repo.Description,
+			"CloneURL.SSH":   cloneLink.SSH,
+			"CloneURL.HTTPS": cloneLink.HTTPS,
+		}
+		if err = os.WriteFile(filepath.Join(tmpDir, "README.md"),
+			[]byte(com.Expand(string(data), match)), 0644); err != nil {
+			return fmt.Errorf("write README.md: %v", err)
+		}
 	}
 
 	// .gitignore
@@ -1058,7 +1237,7 @@ func initRepository(e Engine, repoPath string, doer *User, repo *Repository, opt
 		repoPath,
 		git.SymbolicRefOptions{
 			Name: "HEAD",
-			Ref:  git.RefsHeads + conf.Repository.DefaultBranch,
+			Ref:  git.RefsHeads + repo.DefaultBranch,
 		},
 	)
 	if err != nil {
@@ -1083,7 +1262,7 @@ func initRepository(e Engine, repoPath string, doer *User, repo *Repository, opt
 			tmpDir,
 			&git.Signature{
 				Name:  doer.DisplayName(),
-				Email: doer.Email,
+				Email: doer.CommitEmail(),
 				When:  time.Now(),
 			},
 		)
@@ -1102,7 +1281,6 @@ func initRepository(e Engine, repoPath string, doer *User, repo *Repository, opt
 		repo.IsBare = true
 	}
 
-	repo.DefaultBranch = conf.Repository.DefaultBranch
 	if err = updateRepository(e, repo, false); err != nil {
 		return fmt.Errorf("updateRepository: %v", err)
 	}
@@ -1162,8 +1340,11 @@ func createRepository(e *xorm.Session, doer, owner *User, repo *Repository) (err
 		}
 	}
 
-	if err = watchRepo(e, owner.ID, repo.ID, true); err != nil {
-		return fmt.Errorf("watchRepo: %v", err)
+	// Repositories created on behalf of others are always watched by the owner.
+	if owner.ID != doer.ID || doer.IsAutoWatchOnCreate() {
+		if err = watchRepo(e, owner.ID, repo.ID, true); err != nil {
+			return fmt.Errorf("watchRepo: %v", err)
+		}
 	}
 
 	// FIXME: This is identical to Actions.NewRepo but we are not yet able to wrap
@@ -1208,21 +1389,39 @@ func (err ErrReachLimitOfRepo) Error() string {
 
 // CreateRepository creates a repository for given user or organization.
 func CreateRepository(doer, owner *User, opts CreateRepoOptionsLegacy) (_ *Repository, err error) {
-	if !owner.canCreateRepo() {
+	ok, err := owner.canCreateRepo(x, doer, false)
+	if err != nil {
+		return nil, err
+	} else if !ok {
 		return nil, ErrReachLimitOfRepo{Limit: owner.maxNumRepos()}
 	}
+	if err = checkRepoVisibility(owner, opts.IsPrivate); err != nil {
+		return nil, err
+	}
+
+	if opts.AutoInit && !opts.IsMirror {
+		if opts.Readme == "" {
+			_, _, opts.Readme = owner.RepoInitDefaults()
+		}
+		if err = ValidateRepoInitTemplates(opts.Gitignores, opts.License, opts.Readme); err != nil {
+			return nil, err
+		}
+	}
 
 	repo := &Repository{
-		OwnerID:      owner.ID,
-		Owner:        owner,
-		Name:         opts.Name,
-		LowerName:    strings.ToLower(opts.Name),
-		Description:  opts.Description,
-		IsPrivate:    opts.IsPrivate,
-		IsUnlisted:   opts.IsUnlisted,
-		EnableWiki:   true,
-		EnableIssues: true,
-		EnablePulls:  true,
+		OwnerID:        owner.ID,
+		Owner:          owner,
+		Name:           opts.Name,
+		LowerName:      strings.ToLower(opts.Name),
+		Description:    opts.Description,
+		IsPrivate:      opts.IsPrivate,
+		IsUnlisted:     opts.IsUnlisted,
+		EnableWiki:     true,
+		EnableIssues:   true,
+		EnablePulls:    true,
+		EnableReleases: true,
+		EnableLFS:      conf.LFS.Enabled,
+		DefaultBranch:  conf.Repository.DefaultBranch,
 	}
 
 	sess := x.NewSession()
@@ -1249,6 +1448,13 @@ func CreateRepository(doer, owner *User, opts CreateRepoOptionsLegacy) (_ *Repos
 		if err != nil {
 			return nil, fmt.Errorf("CreateRepository 'git update-server-info': %s", stderr)
 		}
+
+		protectBranch := newDefaultProtectBranch(owner, repo.ID, repo.DefaultBranch)
+		if protectBranch != nil {
+			if _, err = sess.Insert(protectBranch); err != nil {
+				return nil, errors.Wrap(err, "protect default branch")
+			}
+		}
 	}
 	if err = sess.Commit(); err != nil {
 		return nil, err
@@ -1260,6 +1466,7 @@ func CreateRepository(doer, owner *User, opts CreateRepoOptionsLegacy) (_ *Repos
 		return nil, errors.Wrap(err, "update user")
 	}
 
+	notifyRepoLifecycle(newRepoLifecyclePayload(RepoLifecycleCreated, doer, owner.Name, repo))
 	return repo, nil
 }
 
@@ -1364,6 +1571,9 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 	} else if has {
 		return ErrRepoAlreadyExist{args: errutil.Args{"ownerName": newOwnerName, "name": repo.Name}}
 	}
+	if err = checkRepoVisibility(newOwner, repo.IsPrivate); err != nil {
+		return err
+	}
 
 	sess := x.NewSession()
 	defer sess.Close()
@@ -1372,6 +1582,7 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 	}
 
 	owner := repo.Owner
+	old := *repo
 
 	// Note: we have to set value here to make sure recalculate accesses is based on
 	// new owner.
@@ -1492,7 +1703,18 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		}
 	}
 
-	return sess.Commit()
+	if err = sess.Commit(); err != nil {
+		return err
+	}
+
+	p := newRepoLifecyclePayload(RepoLifecycleTransferred, doer, newOwner.Name, repo)
+	p.PreviousOwner = owner.Name
+	notifyRepoLifecycle(p)
+
+	if err = PrepareRepositoryEditedWebhooks(doer, &old, repo); err != nil {
+		log.Error("PrepareRepositoryEditedWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+	return nil
 }
 
 func deleteRepoLocalCopy(repoID int64) {
@@ -1535,6 +1757,14 @@ func ChangeRepositoryName(u *User, oldRepoName, newRepoName string) (err error)
 	}
 
 	deleteRepoLocalCopy(repo.ID)
+	// Cached archives are prefixed with the old repository name
+	RemoveAllWithNotice("Delete repository archives", archiveCacheDir(repo.ID))
+
+	p := newRepoLifecyclePayload(RepoLifecycleRenamed, nil, u.Name, repo)
+	p.Name = newRepoName
+	p.FullName = u.Name + "/" + newRepoName
+	p.PreviousName = oldRepoName
+	notifyRepoLifecycle(p)
 	return nil
 }
 
@@ -1602,7 +1832,12 @@ func updateRepository(e Engine, repo *Repository, visibilityChanged bool) (err e
 			return fmt.Errorf("getRepositoriesByForkID: %v", err)
 		}
 		for i := range forkRepos {
-			forkRepos[i].IsPrivate = repo.IsPrivate
+			if err = forkRepos[i].getOwner(e); err != nil {
+				return fmt.Errorf("getOwner[%d]: %v", forkRepos[i].ID, err)
+			}
+			// Forks stay private when their owners are not allowed to own public
+			// repositories.
+			forkRepos[i].IsPrivate = repo.IsPrivate || !forkRepos[i].Owner.IsPublicRepoAllowed()
 			forkRepos[i].IsUnlisted = repo.IsUnlisted
 			if err = updateRepository(e, forkRepos[i], true); err != nil {
 				return fmt.Errorf("updateRepository[%d]: %v", forkRepos[i].ID, err)
@@ -1618,7 +1853,29 @@ func updateRepository(e Engine, repo *Repository, visibilityChanged bool) (err e
 	return nil
 }
 
+// UpdateRepository updates the repository. It returns
+// ErrRepoVisibilityNotAllowed when the repository is changed from private to
+// public but the owner is not allowed to own public repositories, or the
+// repository is a fork of a private repository.
 func UpdateRepository(repo *Repository, visibilityChanged bool) (err error) {
+	var wasPrivate bool
+	if visibilityChanged && !repo.IsPrivate {
+		if _, err = x.Table("repository").Cols("is_private").Where("id = ?", repo.ID).Get(&wasPrivate); err != nil {
+			return fmt.Errorf("get visibility: %v", err)
+		}
+	}
+	if wasPrivate {
+		if err = repo.GetOwner(); err != nil {
+			return fmt.Errorf("get owner: %v", err)
+		}
+		if err = checkRepoVisibility(repo.Owner, repo.IsPrivate); err != nil {
+			return err
+		}
+		if err = checkForkVisibility(x, repo); err != nil {
+			return err
+		}
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -1652,6 +1909,15 @@ func DeleteRepository(ownerID, repoID int64) error {
 			return err
 		}
 	}
+	repo.Owner = org
+
+	if conf.Repository.Deletion.ExportIssues {
+		archivePath, err := exportDeletedRepositoryIssues(x, org.Name, repo)
+		if err != nil {
+			return fmt.Errorf("export issues: %v", err)
+		}
+		log.Trace("Issues of repository %q have been exported to %q", repo.FullName(), archivePath)
+	}
 
 	sess := x.NewSession()
 	defer sess.Close()
@@ -1686,6 +1952,20 @@ func DeleteRepository(ownerID, repoID int64) error {
 		&Webhook{RepoID: repoID},
 		&HookTask{RepoID: repoID},
 		&LFSObject{RepoID: repoID},
+		&CLASignature{RepoID: repoID},
+		&RepoInvitation{RepoID: repoID},
+		&IgnoredRepo{RepoID: repoID},
+		&RepoTopic{RepoID: repoID},
+		&RepoSecret{RepoID: repoID},
+		&RepoNotificationRecipient{RepoID: repoID},
+		&ReviewRequest{RepoID: repoID},
+		&Approval{RepoID: repoID},
+		&CommitStatus{RepoID: repoID},
+		&LinkedIssue{RepoID: repoID},
+		&Deployment{RepoID: repoID},
+		&DeploymentStatus{RepoID: repoID},
+		&IssueWorkflowState{RepoID: repoID},
+		&RepoLanguage{RepoID: repoID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -1718,6 +1998,12 @@ func DeleteRepository(ownerID, repoID int64) error {
 		return err
 	}
 
+	if conf.Repository.Deletion.CrossReferences == conf.RepoDeletionCrossReferencesTombstone {
+		if err = tombstoneCrossReferences(sess, repo); err != nil {
+			return fmt.Errorf("tombstone cross-references: %v", err)
+		}
+	}
+
 	if repo.IsFork {
 		if _, err = sess.Exec("UPDATE `repository` SET num_forks=num_forks-1 WHERE id=?", repo.ForkID); err != nil {
 			return fmt.Errorf("decrease fork count: %v", err)
@@ -1735,6 +2021,7 @@ func DeleteRepository(ownerID, repoID int64) error {
 	// Remove repository files.
 	repoPath := repo.RepoPath()
 	RemoveAllWithNotice("Delete repository files", repoPath)
+	RemoveAllWithNotice("Delete repository archives", archiveCacheDir(repo.ID))
 
 	repo.DeleteWiki()
 
@@ -1749,26 +2036,10 @@ func DeleteRepository(ownerID, repoID int64) error {
 		}
 	}
 
+	notifyRepoLifecycle(newRepoLifecyclePayload(RepoLifecycleDeleted, nil, org.Name, repo))
 	return nil
 }
 
-// GetRepositoryByRef returns a Repository specified by a GFM reference.
-// See https://help.github.com/articles/writing-on-github#references for more information on the syntax.
-func GetRepositoryByRef(ref string) (*Repository, error) {
-	n := strings.IndexByte(ref, byte('/'))
-	if n < 2 {
-		return nil, dberrors.InvalidRepoReference{Ref: ref}
-	}
-
-	userName, repoName := ref[:n], ref[n+1:]
-	user, err := Users.GetByUsername(context.TODO(), userName)
-	if err != nil {
-		return nil, err
-	}
-
-	return GetRepositoryByName(user.ID, repoName)
-}
-
 // GetRepositoryByName returns the repository by given name under user if exists.
 // Deprecated: Use Repos.GetByName instead.
 func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
@@ -1858,7 +2129,8 @@ func GetUserAndCollaborativeRepositories(userID int64) ([]*Repository, error) {
 type SearchRepoOptions struct {
 	Keyword  string
 	OwnerID  int64
-	UserID   int64 // When set results will contain all public/private repositories user has access to
+	Topic    string // When set results will only contain repositories with the topic
+	UserID   int64  // When set results will contain all public/private repositories user has access to
 	OrderBy  string
 	Private  bool // Include private repositories in results
 	Page     int
@@ -1891,6 +2163,10 @@ func SearchRepositoryByName(opts *SearchRepoOptions) (repos []*Repository, count
 	if opts.OwnerID > 0 {
 		sess.And("repo.owner_id = ?", opts.OwnerID)
 	}
+	if len(opts.Topic) > 0 {
+		sess.Join("INNER", "repo_topic", "repo_topic.repo_id = repo.id").
+			And("repo_topic.name = ?", strings.ToLower(opts.Topic))
+	}
 
 	// We need all fields (repo.*) in final list but only ID (repo.id) is good enough for counting.
 	count, err = sess.Clone().Distinct("repo.id").Count(new(Repository))
@@ -2061,6 +2337,15 @@ const (
 	_GIT_FSCK           = "git_fsck"
 	_CHECK_REPO_STATS   = "check_repos_stats"
 	_CLEAN_OLD_ARCHIVES = "clean_old_archives"
+	_SCAN_ORG_MIRRORS   = "scan_org_mirrors"
+
+	_DELETE_EXPIRED_REPO_INVITATIONS = "delete_expired_repo_invitations"
+	_DELETE_EXPIRED_ORG_INVITATIONS  = "delete_expired_org_invitations"
+	_DELETE_ORPHANED_FILES           = "delete_orphaned_files"
+	_PRUNE_HOOK_TASKS                = "prune_hook_tasks"
+	_PROCESS_STALE_ISSUES            = "process_stale_issues"
+	_UPDATE_REPO_SIZES               = "update_repo_sizes"
+	_CLEANUP_PULL_REQUEST_REFS       = "cleanup_pull_request_refs"
 )
 
 // GitFsck calls 'git fsck' to check repository health.
@@ -2181,26 +2466,13 @@ func CheckRepoStats() {
 		repoStatsCheck(checkers[i])
 	}
 
-	// ***** START: Repository.NumClosedIssues *****
-	desc := "repository count 'num_closed_issues'"
-	results, err := x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_closed_issues!=(SELECT COUNT(*) FROM `issue` WHERE repo_id=repo.id AND is_closed=? AND is_pull=?)", true, false)
-	if err != nil {
-		log.Error("Select %s: %v", desc, err)
-	} else {
-		for _, result := range results {
-			id := com.StrTo(result["id"]).MustInt64()
-			log.Trace("Updating %s: %d", desc, id)
-			_, err = x.Exec("UPDATE `repository` SET num_closed_issues=(SELECT COUNT(*) FROM `issue` WHERE repo_id=? AND is_closed=? AND is_pull=?) WHERE id=?", id, true, false, id)
-			if err != nil {
-				log.Error("Update %s[%d]: %v", desc, id, err)
-			}
-		}
+	if err := ReconcileRepoIssueCounts(); err != nil {
+		log.Error("Reconcile repository issue counts: %v", err)
 	}
-	// ***** END: Repository.NumClosedIssues *****
 
 	// FIXME: use checker when stop supporting old fork repo format.
 	// ***** START: Repository.NumForks *****
-	results, err = x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id)")
+	results, err := x.Query("SELECT repo.id FROM `repository` repo WHERE repo.num_forks!=(SELECT COUNT(*) FROM `repository` WHERE fork_id=repo.id)")
 	if err != nil {
 		log.Error("Select repository count 'num_forks': %v", err)
 	} else {
@@ -2230,6 +2502,29 @@ func CheckRepoStats() {
 	// ***** END: Repository.NumForks *****
 }
 
+// ReconcileRepoIssueCounts recomputes numbers of issues and pull requests of
+// all repositories whose denormalized counters have drifted.
+func ReconcileRepoIssueCounts() error {
+	results, err := x.Query("SELECT repo.id FROM `repository` repo WHERE "+
+		"repo.num_issues!=(SELECT COUNT(*) FROM `issue` WHERE repo_id=repo.id AND is_pull=?) OR "+
+		"repo.num_closed_issues!=(SELECT COUNT(*) FROM `issue` WHERE repo_id=repo.id AND is_pull=? AND is_closed=?) OR "+
+		"repo.num_pulls!=(SELECT COUNT(*) FROM `issue` WHERE repo_id=repo.id AND is_pull=?) OR "+
+		"repo.num_closed_pulls!=(SELECT COUNT(*) FROM `issue` WHERE repo_id=repo.id AND is_pull=? AND is_closed=?)",
+		false, false, true, true, true, true)
+	if err != nil {
+		return errors.Wrap(err, "select drifted repositories")
+	}
+
+	for _, result := range results {
+		id := com.StrTo(result["id"]).MustInt64()
+		log.Trace("Updating repository issue counts: %d", id)
+		if _, err = Repos.ReconcileIssueCounts(context.TODO(), id); err != nil {
+			log.Error("Reconcile issue counts[%d]: %v", id, err)
+		}
+	}
+	return nil
+}
+
 type RepositoryList []*Repository
 
 func (repos RepositoryList) loadAttributes(e Engine) error {
@@ -2281,6 +2576,23 @@ func (repos RepositoryList) loadAttributes(e Engine) error {
 		}
 	}
 
+	// Load topics
+	repoIDs := make([]int64, 0, len(repos))
+	for i := range repos {
+		repoIDs = append(repoIDs, repos[i].ID)
+	}
+	repoTopics := make([]*RepoTopic, 0, len(repoIDs))
+	if err := e.In("repo_id", repoIDs).Asc("name").Find(&repoTopics); err != nil {
+		return fmt.Errorf("find topics: %v", err)
+	}
+	topicSet := make(map[int64][]string, len(repos))
+	for _, t := range repoTopics {
+		topicSet[t.RepoID] = append(topicSet[t.RepoID], t.Name)
+	}
+	for i := range repos {
+		repos[i].Topics = topicSet[repos[i].ID]
+	}
+
 	return nil
 }
 
@@ -2520,25 +2832,44 @@ func HasForkedRepo(ownerID, repoID int64) (*Repository, bool, error) {
 	return repo, true, repo.LoadAttributes()
 }
 
+// newForkRepository returns a new fork of the base repository to be owned by the
+// owner. The fork is private when the base repository is private or the owner is
+// not allowed to own public repositories.
+func newForkRepository(owner *User, baseRepo *Repository, name, desc string) *Repository {
+	return &Repository{
+		OwnerID:        owner.ID,
+		Owner:          owner,
+		Name:           name,
+		LowerName:      strings.ToLower(name),
+		Description:    desc,
+		DefaultBranch:  baseRepo.DefaultBranch,
+		IsPrivate:      baseRepo.IsPrivate || !owner.IsPublicRepoAllowed(),
+		IsUnlisted:     baseRepo.IsUnlisted,
+		IsFork:         true,
+		ForkID:         baseRepo.ID,
+		EnableReleases: true,
+		EnableLFS:      conf.LFS.Enabled,
+	}
+}
+
 // ForkRepository creates a fork of target repository under another user domain.
+// Forks of private repositories are always private. It returns
+// ErrPrivateForkNotAllowed when the owner is not allowed to fork the private
+// repository.
 func ForkRepository(doer, owner *User, baseRepo *Repository, name, desc string) (_ *Repository, err error) {
-	if !owner.canCreateRepo() {
+	ok, err := owner.canCreateRepo(x, doer, true)
+	if err != nil {
+		return nil, err
+	} else if !ok {
 		return nil, ErrReachLimitOfRepo{Limit: owner.maxNumRepos()}
 	}
 
-	repo := &Repository{
-		OwnerID:       owner.ID,
-		Owner:         owner,
-		Name:          name,
-		LowerName:     strings.ToLower(name),
-		Description:   desc,
-		DefaultBranch: baseRepo.DefaultBranch,
-		IsPrivate:     baseRepo.IsPrivate,
-		IsUnlisted:    baseRepo.IsUnlisted,
-		IsFork:        true,
-		ForkID:        baseRepo.ID,
+	if err = checkPrivateForkAllowed(x, baseRepo, owner); err != nil {
+		return nil, err
 	}
 
+	repo := newForkRepository(owner, baseRepo, name, desc)
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
@@ -2592,6 +2923,8 @@ func ForkRepository(doer, owner *User, baseRepo *Repository, name, desc string)
 	}); err != nil {
 		log.Error("PrepareWebhooks [repo_id: %d]: %v", baseRepo.ID, err)
 	}
+
+	notifyRepoLifecycle(newRepoLifecyclePayload(RepoLifecycleCreated, doer, owner.Name, repo))
 	return repo, nil
 }
 
@@ -0,0 +1,186 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogs/git-module"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptoutil"
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/osutil"
+	"gogs.io/gogs/internal/pathutil"
+)
+
+type ErrArchiveTooLarge struct {
+	args errutil.Args
+}
+
+func IsErrArchiveTooLarge(err error) bool {
+	_, ok := err.(ErrArchiveTooLarge)
+	return ok
+}
+
+func (err ErrArchiveTooLarge) Error() string {
+	return fmt.Sprintf("archive is too large: %v", err.args)
+}
+
+var errArchiveSizeExceeded = errors.New("archive size exceeded")
+
+// archiveLimitWriter writes to the underlying writer and fails once more than
+// limit bytes have been written in total. There is no limit when the limit is
+// not positive.
+type archiveLimitWriter struct {
+	w     io.Writer
+	limit int64
+	n     int64
+}
+
+func (w *archiveLimitWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	if w.limit > 0 && w.n > w.limit {
+		return 0, errArchiveSizeExceeded
+	}
+	return w.w.Write(p)
+}
+
+// archiveCacheLock guards the eviction of cached archives.
+var archiveCacheLock sync.Mutex
+
+// archiveCacheDir returns the directory to cache archives of the repository.
+func archiveCacheDir(repoID int64) string {
+	return filepath.Join(conf.Repository.Archive.CachePath, strconv.FormatInt(repoID, 10))
+}
+
+// ArchiveCommit returns the path of the archive of the commit in given format.
+// Only the subtree at the tree path is archived when it is not empty. Archives
+// are cached on disk by the commit ID, format and tree path, and the least
+// recently used archives are evicted when the total size of the cache exceeds
+// the limit. It returns ErrArchiveTooLarge if the archive exceeds the size
+// limit.
+func (repo *Repository) ArchiveCommit(commit *git.Commit, format git.ArchiveFormat, treePath string) (string, error) {
+	treePath = pathutil.Clean(treePath)
+	name := commit.ID.String()
+	treeish := commit.ID.String()
+	if treePath != "" {
+		name += "-" + cryptoutil.SHA1(treePath)[:16]
+		treeish += ":" + treePath
+	}
+	archivePath := filepath.Join(archiveCacheDir(repo.ID), name+"."+string(format))
+
+	if osutil.IsFile(archivePath) {
+		// Mark as recently used for the eviction
+		now := time.Now()
+		if err := os.Chtimes(archivePath, now, now); err != nil {
+			log.Warn("Failed to touch cached archive %q: %v", archivePath, err)
+		}
+		return archivePath, nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(archivePath), os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("create archive directory: %v", err)
+	}
+
+	// Generate into a temporary file to not serve incomplete archives
+	tmp, err := os.CreateTemp(filepath.Dir(archivePath), "tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("create temporary file: %v", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	maxSize := conf.Repository.Archive.MaxSize << 20
+	w := &archiveLimitWriter{w: tmp, limit: maxSize}
+	stderr := new(bytes.Buffer)
+	err = git.NewCommand("archive", "--prefix="+repo.Name+"/", "--format="+string(format), treeish).
+		RunInDirPipeline(w, stderr, repo.RepoPath())
+	_ = tmp.Close()
+	if maxSize > 0 && w.n > maxSize {
+		return "", ErrArchiveTooLarge{args: errutil.Args{"repoID": repo.ID, "commitID": commit.ID.String(), "maxSize": maxSize}}
+	} else if err != nil {
+		return "", fmt.Errorf("run git archive: %v - %s", err, stderr)
+	}
+
+	if err = os.Rename(tmp.Name(), archivePath); err != nil {
+		return "", fmt.Errorf("rename temporary file: %v", err)
+	}
+
+	if err = evictArchiveCache(conf.Repository.Archive.MaxCacheSize << 20); err != nil {
+		log.Error("Failed to evict archive cache: %v", err)
+	}
+	return archivePath, nil
+}
+
+// evictArchiveCache removes the least recently used archives until the total
+// size of cached archives is no more than the maximum size. Nothing is removed
+// when the maximum size is not positive.
+func evictArchiveCache(maxSize int64) error {
+	if maxSize <= 0 {
+		return nil
+	}
+
+	archiveCacheLock.Lock()
+	defer archiveCacheLock.Unlock()
+
+	type archive struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var archives []archive
+	var totalSize int64
+	err := filepath.WalkDir(conf.Repository.Archive.CachePath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		} else if d.IsDir() || strings.HasPrefix(d.Name(), "tmp-") {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		archives = append(archives, archive{path: path, size: fi.Size(), modTime: fi.ModTime()})
+		totalSize += fi.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk cache directory: %v", err)
+	}
+
+	sort.Slice(archives, func(i, j int) bool {
+		return archives[i].modTime.Before(archives[j].modTime)
+	})
+	for _, a := range archives {
+		if totalSize <= maxSize {
+			break
+		}
+		if err = os.Remove(a.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove archive: %v", err)
+		}
+		totalSize -= a.size
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestRepository_ArchiveCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	repoOpts.Archive.CachePath = t.TempDir()
+	repoOpts.Archive.MaxCacheSize = 0
+	repoOpts.Archive.MaxSize = 0
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{ID: 1, Name: "alice", Email: "alice@example.com"}
+	repo := &Repository{ID: 1, Name: "example", OwnerID: alice.ID, Owner: alice}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	tmpDir := filepath.Join(t.TempDir(), "example")
+	err = prepareRepoCommit(repo, tmpDir, repoPath, CreateRepoOptionsLegacy{Name: "example", Readme: "Default"})
+	require.NoError(t, err)
+	err = os.MkdirAll(filepath.Join(tmpDir, "docs"), os.ModePerm)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(tmpDir, "docs", "guide.md"), []byte("# Guide"), 0o644)
+	require.NoError(t, err)
+	err = initRepoCommit(tmpDir, &git.Signature{Name: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+
+	gitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	branch, err := gitRepo.SymbolicRef()
+	require.NoError(t, err)
+	err = gitRepo.CreateTag("v1.0.0", git.RefShortName(branch))
+	require.NoError(t, err)
+	commit, err := gitRepo.TagCommit("v1.0.0")
+	require.NoError(t, err)
+
+	zipFiles := func(t *testing.T, path string) []string {
+		r, err := zip.OpenReader(path)
+		require.NoError(t, err)
+		defer func() { _ = r.Close() }()
+
+		var names []string
+		for _, f := range r.File {
+			if !f.FileInfo().IsDir() {
+				names = append(names, f.Name)
+			}
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	t.Run("tag archive", func(t *testing.T) {
+		got, err := repo.ArchiveCommit(commit, git.ArchiveZip, "")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example/README.md", "example/docs/guide.md"}, zipFiles(t, got))
+
+		// The cached archive should be reused for the same commit
+		past := time.Now().Add(-time.Hour)
+		err = os.Chtimes(got, past, past)
+		require.NoError(t, err)
+		wantInfo, err := os.Stat(got)
+		require.NoError(t, err)
+
+		again, err := repo.ArchiveCommit(commit, git.ArchiveZip, "")
+		require.NoError(t, err)
+		assert.Equal(t, got, again)
+		gotInfo, err := os.Stat(again)
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(wantInfo, gotInfo))
+		assert.True(t, gotInfo.ModTime().After(past), "cache hit should be marked as recently used")
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		got, err := repo.ArchiveCommit(commit, git.ArchiveZip, "/docs/")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example/guide.md"}, zipFiles(t, got))
+	})
+
+	t.Run("evict", func(t *testing.T) {
+		dir := archiveCacheDir(repo.ID)
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+
+		err = evictArchiveCache(1)
+		require.NoError(t, err)
+		entries, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
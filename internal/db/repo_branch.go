@@ -5,15 +5,15 @@
 package db
 
 import (
-	"context"
+	"bytes"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/gogs/git-module"
-	"github.com/unknwon/com"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/errutil"
-	"gogs.io/gogs/internal/tool"
 )
 
 type Branch struct {
@@ -87,11 +87,11 @@ func (br *Branch) GetCommit() (*git.Commit, error) {
 }
 
 type ProtectBranchWhitelist struct {
-	ID              int64
+	ID              int64 `gorm:"primaryKey"`
 	ProtectBranchID int64
-	RepoID          int64  `xorm:"UNIQUE(protect_branch_whitelist)"`
-	Name            string `xorm:"UNIQUE(protect_branch_whitelist)"`
-	UserID          int64  `xorm:"UNIQUE(protect_branch_whitelist)"`
+	RepoID          int64  `xorm:"UNIQUE(protect_branch_whitelist)" gorm:"uniqueIndex:protect_branch_whitelist_unique"`
+	Name            string `xorm:"UNIQUE(protect_branch_whitelist)" gorm:"uniqueIndex:protect_branch_whitelist_unique"`
+	UserID          int64  `xorm:"UNIQUE(protect_branch_whitelist)" gorm:"uniqueIndex:protect_branch_whitelist_unique"`
 }
 
 // IsUserInProtectBranchWhitelist returns true if given user is in the whitelist of a branch in a repository.
@@ -102,14 +102,46 @@ func IsUserInProtectBranchWhitelist(repoID, userID int64, branch string) bool {
 
 // ProtectBranch contains options of a protected branch.
 type ProtectBranch struct {
-	ID                 int64
-	RepoID             int64  `xorm:"UNIQUE(protect_branch)"`
-	Name               string `xorm:"UNIQUE(protect_branch)"`
-	Protected          bool
-	RequirePullRequest bool
-	EnableWhitelist    bool
-	WhitelistUserIDs   string `xorm:"TEXT"`
-	WhitelistTeamIDs   string `xorm:"TEXT"`
+	ID                      int64  `gorm:"primaryKey"`
+	RepoID                  int64  `xorm:"UNIQUE(protect_branch)" gorm:"uniqueIndex:protect_branch_repo_name_unique"`
+	Name                    string `xorm:"UNIQUE(protect_branch)" gorm:"uniqueIndex:protect_branch_repo_name_unique"`
+	Protected               bool
+	RequirePullRequest      bool
+	RequireLinearHistory    bool
+	RequireUpToDate         bool
+	RequireCodeOwnerReviews bool
+	// The number of approvals at the latest commit that pull requests need
+	// before merging.
+	RequiredApprovals int
+	// The comma-separated list of contexts of commit statuses that must succeed
+	// for the latest commit of pull requests before merging.
+	RequiredStatusChecks string `xorm:"TEXT" gorm:"type:TEXT"`
+	EnableWhitelist      bool
+	WhitelistUserIDs     string `xorm:"TEXT" gorm:"column:whitelist_user_i_ds;type:TEXT"`
+	WhitelistTeamIDs     string `xorm:"TEXT" gorm:"column:whitelist_team_i_ds;type:TEXT"`
+}
+
+// StatusCheckContexts returns contexts of required status checks, sorted in
+// alphabetical order.
+func (protectBranch *ProtectBranch) StatusCheckContexts() []string {
+	return parseStatusCheckContexts(protectBranch.RequiredStatusChecks)
+}
+
+// parseStatusCheckContexts parses the comma-separated list of contexts of
+// status checks, and returns unique non-empty contexts in alphabetical order.
+func parseStatusCheckContexts(s string) []string {
+	seen := make(map[string]bool)
+	contexts := make([]string, 0, strings.Count(s, ",")+1)
+	for _, context := range strings.Split(s, ",") {
+		context = strings.TrimSpace(context)
+		if context == "" || seen[context] {
+			continue
+		}
+		seen[context] = true
+		contexts = append(contexts, context)
+	}
+	sort.Strings(contexts)
+	return contexts
 }
 
 // GetProtectBranchOfRepoByName returns *ProtectBranch by branch name in given repository.
@@ -136,144 +168,103 @@ func IsBranchOfRepoRequirePullRequest(repoID int64, name string) bool {
 	return protectBranch.Protected && protectBranch.RequirePullRequest
 }
 
-// UpdateProtectBranch saves branch protection options.
-// If ID is 0, it creates a new record. Otherwise, updates existing record.
-func UpdateProtectBranch(protectBranch *ProtectBranch) (err error) {
-	sess := x.NewSession()
-	defer sess.Close()
-	if err = sess.Begin(); err != nil {
-		return err
-	}
-
-	if protectBranch.ID == 0 {
-		if _, err = sess.Insert(protectBranch); err != nil {
-			return fmt.Errorf("Insert: %v", err)
-		}
-	}
-
-	if _, err = sess.ID(protectBranch.ID).AllCols().Update(protectBranch); err != nil {
-		return fmt.Errorf("Update: %v", err)
+// IsBranchOfRepoRequireLinearHistory returns true if branch requires linear
+// history in given repository.
+func IsBranchOfRepoRequireLinearHistory(repoID int64, name string) bool {
+	protectBranch, err := GetProtectBranchOfRepoByName(repoID, name)
+	if err != nil {
+		return false
 	}
-
-	return sess.Commit()
+	return protectBranch.Protected && protectBranch.RequireLinearHistory
 }
 
-// UpdateOrgProtectBranch saves branch protection options of organizational repository.
-// If ID is 0, it creates a new record. Otherwise, updates existing record.
-// This function also performs check if whitelist user and team's IDs have been changed
-// to avoid unnecessary whitelist delete and regenerate.
-func UpdateOrgProtectBranch(repo *Repository, protectBranch *ProtectBranch, whitelistUserIDs, whitelistTeamIDs string) (err error) {
-	if err = repo.GetOwner(); err != nil {
-		return fmt.Errorf("GetOwner: %v", err)
-	} else if !repo.Owner.IsOrganization() {
-		return fmt.Errorf("expect repository owner to be an organization")
+// IsBranchOfRepoRequireCodeOwnerReviews returns true if branch requires
+// approvals from code owners of changed files before merging pull requests in
+// given repository.
+func IsBranchOfRepoRequireCodeOwnerReviews(repoID int64, name string) bool {
+	protectBranch, err := GetProtectBranchOfRepoByName(repoID, name)
+	if err != nil {
+		return false
 	}
+	return protectBranch.Protected && protectBranch.RequireCodeOwnerReviews
+}
 
-	hasUsersChanged := false
-	validUserIDs := tool.StringsToInt64s(strings.Split(protectBranch.WhitelistUserIDs, ","))
-	if protectBranch.WhitelistUserIDs != whitelistUserIDs {
-		hasUsersChanged = true
-		userIDs := tool.StringsToInt64s(strings.Split(whitelistUserIDs, ","))
-		validUserIDs = make([]int64, 0, len(userIDs))
-		for _, userID := range userIDs {
-			if !Perms.Authorize(context.TODO(), userID, repo.ID, AccessModeWrite,
-				AccessModeOptions{
-					OwnerID: repo.OwnerID,
-					Private: repo.IsPrivate,
-				},
-			) {
-				continue // Drop invalid user ID
-			}
-
-			validUserIDs = append(validUserIDs, userID)
-		}
-
-		protectBranch.WhitelistUserIDs = strings.Join(tool.Int64sToStrings(validUserIDs), ",")
+// IsBranchOfRepoRequireUpToDate returns true if branch requires head branches
+// of pull requests to be up to date before merging in given repository.
+func IsBranchOfRepoRequireUpToDate(repoID int64, name string) bool {
+	protectBranch, err := GetProtectBranchOfRepoByName(repoID, name)
+	if err != nil {
+		return false
 	}
+	return protectBranch.Protected && protectBranch.RequireUpToDate
+}
 
-	hasTeamsChanged := false
-	validTeamIDs := tool.StringsToInt64s(strings.Split(protectBranch.WhitelistTeamIDs, ","))
-	if protectBranch.WhitelistTeamIDs != whitelistTeamIDs {
-		hasTeamsChanged = true
-		teamIDs := tool.StringsToInt64s(strings.Split(whitelistTeamIDs, ","))
-		teams, err := GetTeamsHaveAccessToRepo(repo.OwnerID, repo.ID, AccessModeWrite)
-		if err != nil {
-			return fmt.Errorf("GetTeamsHaveAccessToRepo [org_id: %d, repo_id: %d]: %v", repo.OwnerID, repo.ID, err)
-		}
-		validTeamIDs = make([]int64, 0, len(teams))
-		for i := range teams {
-			if teams[i].HasWriteAccess() && com.IsSliceContainsInt64(teamIDs, teams[i].ID) {
-				validTeamIDs = append(validTeamIDs, teams[i].ID)
-			}
-		}
-
-		protectBranch.WhitelistTeamIDs = strings.Join(tool.Int64sToStrings(validTeamIDs), ",")
+// HasMergeCommits returns true if any of the commits that are reachable from
+// the new commit but not from the old commit has more than one parent. When
+// the old commit is empty, commits that are reachable from any existing ref
+// are excluded instead.
+func HasMergeCommits(repoPath, oldCommitID, newCommitID string) (bool, error) {
+	args := []string{"rev-list", "--min-parents=2", "--max-count=1", newCommitID}
+	if oldCommitID == git.EmptyID {
+		args = append(args, "--not", "--all")
+	} else {
+		args = append(args, "^"+oldCommitID)
 	}
-
-	// Make sure protectBranch.ID is not 0 for whitelists
-	if protectBranch.ID == 0 {
-		if _, err = x.Insert(protectBranch); err != nil {
-			return fmt.Errorf("Insert: %v", err)
-		}
+	output, err := git.NewCommand(args...).RunInDir(repoPath)
+	if err != nil {
+		return false, err
 	}
+	return len(bytes.TrimSpace(output)) > 0, nil
+}
 
-	// Merge users and members of teams
-	var whitelists []*ProtectBranchWhitelist
-	if hasUsersChanged || hasTeamsChanged {
-		mergedUserIDs := make(map[int64]bool)
-		for _, userID := range validUserIDs {
-			// Empty whitelist users can cause an ID with 0
-			if userID != 0 {
-				mergedUserIDs[userID] = true
-			}
-		}
+// GetProtectBranchesByRepoID returns a list of *ProtectBranch in given repository.
+func GetProtectBranchesByRepoID(repoID int64) ([]*ProtectBranch, error) {
+	protectBranches := make([]*ProtectBranch, 0, 2)
+	return protectBranches, x.Where("repo_id = ? and protected = ?", repoID, true).Asc("name").Find(&protectBranches)
+}
 
-		for _, teamID := range validTeamIDs {
-			members, err := GetTeamMembers(teamID)
-			if err != nil {
-				return fmt.Errorf("GetTeamMembers [team_id: %d]: %v", teamID, err)
-			}
+// DefaultBranchProtection is the preference of an organization about whether to
+// protect the default branch of new repositories.
+type DefaultBranchProtection int
 
-			for i := range members {
-				mergedUserIDs[members[i].ID] = true
-			}
-		}
+const (
+	DefaultBranchProtectionDefault  DefaultBranchProtection = iota // Use the global default
+	DefaultBranchProtectionEnabled                                 // Always protect
+	DefaultBranchProtectionDisabled                                // Never protect
+)
 
-		whitelists = make([]*ProtectBranchWhitelist, 0, len(mergedUserIDs))
-		for userID := range mergedUserIDs {
-			whitelists = append(whitelists, &ProtectBranchWhitelist{
-				ProtectBranchID: protectBranch.ID,
-				RepoID:          repo.ID,
-				Name:            protectBranch.Name,
-				UserID:          userID,
-			})
-		}
+// IsEnabled returns true if the preference is enabled, or the global default
+// is enabled when the preference is not set.
+func (p DefaultBranchProtection) IsEnabled(globalDefault bool) bool {
+	switch p {
+	case DefaultBranchProtectionEnabled:
+		return true
+	case DefaultBranchProtectionDisabled:
+		return false
+	default:
+		return globalDefault
 	}
+}
 
-	sess := x.NewSession()
-	defer sess.Close()
-	if err = sess.Begin(); err != nil {
-		return err
+// newDefaultProtectBranch returns the protection rule of the default branch of
+// a new repository owned by the owner, or nil if the default branch should not
+// be protected.
+func newDefaultProtectBranch(owner *User, repoID int64, branch string) *ProtectBranch {
+	opts := conf.Repository.DefaultBranchProtection
+	enabled := opts.Enabled
+	if owner.IsOrganization() {
+		enabled = owner.DefaultBranchProtection.IsEnabled(enabled)
 	}
-
-	if _, err = sess.ID(protectBranch.ID).AllCols().Update(protectBranch); err != nil {
-		return fmt.Errorf("Update: %v", err)
+	if !enabled {
+		return nil
 	}
 
-	// Refresh whitelists
-	if hasUsersChanged || hasTeamsChanged {
-		if _, err = sess.Delete(&ProtectBranchWhitelist{ProtectBranchID: protectBranch.ID}); err != nil {
-			return fmt.Errorf("delete old protect branch whitelists: %v", err)
-		} else if _, err = sess.Insert(whitelists); err != nil {
-			return fmt.Errorf("insert new protect branch whitelists: %v", err)
-		}
+	return &ProtectBranch{
+		RepoID:               repoID,
+		Name:                 branch,
+		Protected:            true,
+		RequirePullRequest:   opts.RequirePullRequest,
+		RequireLinearHistory: opts.RequireLinearHistory,
+		RequireUpToDate:      opts.RequireUpToDate,
 	}
-
-	return sess.Commit()
-}
-
-// GetProtectBranchesByRepoID returns a list of *ProtectBranch in given repository.
-func GetProtectBranchesByRepoID(repoID int64) ([]*ProtectBranch, error) {
-	protectBranches := make([]*ProtectBranch, 0, 2)
-	return protectBranches, x.Where("repo_id = ? and protected = ?", repoID, true).Asc("name").Find(&protectBranches)
 }
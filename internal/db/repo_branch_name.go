@@ -0,0 +1,108 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gogs/git-module"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+type ErrBranchNamePatternInvalid struct {
+	args errutil.Args
+}
+
+func IsErrBranchNamePatternInvalid(err error) bool {
+	_, ok := err.(ErrBranchNamePatternInvalid)
+	return ok
+}
+
+func (err ErrBranchNamePatternInvalid) Error() string {
+	return fmt.Sprintf("branch name pattern is not a valid regular expression: %v", err.args)
+}
+
+// compileBranchNamePattern compiles the pattern to match the whole branch name.
+func compileBranchNamePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, ErrBranchNamePatternInvalid{args: errutil.Args{"pattern": pattern, "error": err.Error()}}
+	}
+	return re, nil
+}
+
+// ValidateBranchNamePattern returns ErrBranchNamePatternInvalid if the pattern
+// is not a valid regular expression. An empty pattern is always valid.
+func ValidateBranchNamePattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	_, err := compileBranchNamePattern(pattern)
+	return err
+}
+
+// EffectiveBranchNamePattern returns the pattern that names of new branches of
+// the repository must match, which falls back to the default of the
+// organization that owns the repository. An empty pattern means no restriction.
+func (repo *Repository) EffectiveBranchNamePattern() (string, error) {
+	if repo.BranchNamePattern != "" {
+		return repo.BranchNamePattern, nil
+	}
+
+	if err := repo.GetOwner(); err != nil {
+		return "", fmt.Errorf("get owner: %v", err)
+	}
+	if repo.Owner.IsOrganization() {
+		return repo.Owner.BranchNamePattern, nil
+	}
+	return "", nil
+}
+
+type ErrBranchNameNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrBranchNameNotAllowed(err error) bool {
+	_, ok := err.(ErrBranchNameNotAllowed)
+	return ok
+}
+
+func (err ErrBranchNameNotAllowed) Error() string {
+	return fmt.Sprintf("branch name does not match the required pattern: %v", err.args)
+}
+
+// Pattern returns the pattern that the branch name does not match.
+func (err ErrBranchNameNotAllowed) Pattern() string {
+	return err.args["pattern"].(string)
+}
+
+// CheckPushBranchName checks whether pushing from the old commit to the new
+// commit of the branch is allowed by the branch name pattern. Only creation of
+// new branches other than the default branch is restricted, updates and
+// deletions of existing branches are always allowed. It returns
+// ErrBranchNameNotAllowed when the branch name does not match the pattern.
+func (repo *Repository) CheckPushBranchName(branch, oldCommitID, newCommitID string) error {
+	if oldCommitID != git.EmptyID || newCommitID == git.EmptyID || branch == repo.DefaultBranch {
+		return nil
+	}
+
+	pattern, err := repo.EffectiveBranchNamePattern()
+	if err != nil {
+		return err
+	} else if pattern == "" {
+		return nil
+	}
+
+	re, err := compileBranchNamePattern(pattern)
+	if err != nil {
+		return err
+	}
+	if !re.MatchString(branch) {
+		return ErrBranchNameNotAllowed{args: errutil.Args{"branch": branch, "pattern": pattern}}
+	}
+	return nil
+}
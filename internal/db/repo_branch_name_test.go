@@ -0,0 +1,105 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestValidateBranchNamePattern(t *testing.T) {
+	assert.NoError(t, ValidateBranchNamePattern(""))
+	assert.NoError(t, ValidateBranchNamePattern("(feature|bugfix)/.+"))
+	assert.True(t, IsErrBranchNamePatternInvalid(ValidateBranchNamePattern("feature/(")))
+}
+
+func TestRepository_CheckPushBranchName(t *testing.T) {
+	const (
+		oldCommitID = "8d4ecd5d9e2fe5a4b0a1b5d1d6f4a1bd1b7c8d2e"
+		newCommitID = "0a7c6b0f0d1e6e4b5e3a2d1c8b7a6f5e4d3c2b1a"
+	)
+
+	org := &User{ID: 1, Name: "acme", Type: UserTypeOrganization, BranchNamePattern: "(feature|bugfix)/.+"}
+	alice := &User{ID: 2, Name: "alice"}
+
+	tests := []struct {
+		name        string
+		repo        *Repository
+		branch      string
+		oldCommitID string
+		newCommitID string
+		wantErr     error
+	}{
+		{
+			name:        "conforming new branch",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "feature/login",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+		},
+		{
+			name:        "non-conforming new branch",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "login",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+			wantErr:     ErrBranchNameNotAllowed{args: errutil.Args{"branch": "login", "pattern": "(feature|bugfix)/.+"}},
+		},
+		{
+			name:        "pattern matches the whole name",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "hotfix/feature/login",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+			wantErr:     ErrBranchNameNotAllowed{args: errutil.Args{"branch": "hotfix/feature/login", "pattern": "(feature|bugfix)/.+"}},
+		},
+		{
+			name:        "default branch",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "main",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+		},
+		{
+			name:        "update existing branch",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "login",
+			oldCommitID: oldCommitID,
+			newCommitID: newCommitID,
+		},
+		{
+			name:        "delete existing branch",
+			repo:        &Repository{Owner: org, DefaultBranch: "main"},
+			branch:      "login",
+			oldCommitID: oldCommitID,
+			newCommitID: git.EmptyID,
+		},
+		{
+			name:        "repository pattern overrides organization default",
+			repo:        &Repository{Owner: org, DefaultBranch: "main", BranchNamePattern: "release-.+"},
+			branch:      "feature/login",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+			wantErr:     ErrBranchNameNotAllowed{args: errutil.Args{"branch": "feature/login", "pattern": "release-.+"}},
+		},
+		{
+			name:        "no pattern",
+			repo:        &Repository{Owner: alice, DefaultBranch: "main"},
+			branch:      "login",
+			oldCommitID: git.EmptyID,
+			newCommitID: newCommitID,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.repo.CheckPushBranchName(test.branch, test.oldCommitID, test.newCommitID)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
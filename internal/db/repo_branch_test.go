@@ -0,0 +1,216 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestHasMergeCommits(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		output, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(name string) string {
+		err := os.WriteFile(filepath.Join(repoPath, name), []byte(name), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Add "+name)
+		return run("rev-parse", "HEAD")
+	}
+
+	run("init", "-b", "main")
+	base := commit("README.md")
+
+	run("checkout", "-b", "feature")
+	feature := commit("feature.md")
+
+	run("checkout", "main")
+	main := commit("main.md")
+	run("merge", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	merge := run("rev-parse", "HEAD")
+
+	// Create a merge commit that is not reachable from any ref
+	run("checkout", "--detach", base)
+	commit("detached.md")
+	run("merge", "--no-ff", "-m", "Merge branch 'feature'", "feature")
+	detachedMerge := run("rev-parse", "HEAD")
+	run("checkout", "main")
+
+	tests := []struct {
+		name        string
+		oldCommitID string
+		newCommitID string
+		want        bool
+	}{
+		{
+			name:        "fast-forward",
+			oldCommitID: base,
+			newCommitID: feature,
+			want:        false,
+		},
+		{
+			name:        "merge commit",
+			oldCommitID: main,
+			newCommitID: merge,
+			want:        true,
+		},
+		{
+			name:        "merge commit already exists",
+			oldCommitID: merge,
+			newCommitID: merge,
+			want:        false,
+		},
+		{
+			name:        "new branch with existing merge commit",
+			oldCommitID: git.EmptyID,
+			newCommitID: merge,
+			want:        false,
+		},
+		{
+			name:        "new branch with new merge commit",
+			oldCommitID: git.EmptyID,
+			newCommitID: detachedMerge,
+			want:        true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := HasMergeCommits(repoPath, test.oldCommitID, test.newCommitID)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func Test_newDefaultProtectBranch(t *testing.T) {
+	alice := &User{ID: 1, Name: "alice"}
+	newOrg := func(p DefaultBranchProtection) *User {
+		return &User{ID: 2, Name: "acme", Type: UserTypeOrganization, DefaultBranchProtection: p}
+	}
+
+	tests := []struct {
+		name          string
+		globalEnabled bool
+		owner         *User
+		want          bool
+	}{
+		{
+			name:          "user with global default enabled",
+			globalEnabled: true,
+			owner:         alice,
+			want:          true,
+		},
+		{
+			name:  "user with global default disabled",
+			owner: alice,
+			want:  false,
+		},
+		{
+			name:          "organization uses global default",
+			globalEnabled: true,
+			owner:         newOrg(DefaultBranchProtectionDefault),
+			want:          true,
+		},
+		{
+			name:  "organization enables",
+			owner: newOrg(DefaultBranchProtectionEnabled),
+			want:  true,
+		},
+		{
+			name:          "organization disables",
+			globalEnabled: true,
+			owner:         newOrg(DefaultBranchProtectionDisabled),
+			want:          false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := conf.RepositoryOpts{}
+			opts.DefaultBranchProtection.Enabled = test.globalEnabled
+			opts.DefaultBranchProtection.RequirePullRequest = true
+			conf.SetMockRepository(t, opts)
+
+			got := newDefaultProtectBranch(test.owner, 1, "main")
+			if !test.want {
+				assert.Nil(t, got)
+				return
+			}
+
+			want := &ProtectBranch{
+				RepoID:             1,
+				Name:               "main",
+				Protected:          true,
+				RequirePullRequest: true,
+			}
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCreateRepository_defaultBranchProtection(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Access), new(Collaboration), new(Watch), new(Action), new(ProtectBranch))
+	SetMockUsersStore(t, NewUsersStore(dbtest.NewDB(t, "createRepositoryDefaultBranchProtection", new(User))))
+
+	opts := conf.Repository
+	opts.Root = t.TempDir()
+	opts.MaxCreationLimit = -1
+	opts.DefaultBranch = "main"
+	opts.DefaultBranchProtection.Enabled = true
+	opts.DefaultBranchProtection.RequirePullRequest = true
+	conf.SetMockRepository(t, opts)
+
+	alice := &User{Name: "alice", LowerName: "alice", MaxRepoCreation: -1}
+	_, err := x.Insert(alice)
+	require.NoError(t, err)
+
+	repo, err := CreateRepository(alice, alice, CreateRepoOptionsLegacy{Name: "example"})
+	require.NoError(t, err)
+
+	got, err := GetProtectBranchOfRepoByName(repo.ID, "main")
+	require.NoError(t, err)
+	assert.True(t, got.Protected)
+	assert.True(t, got.RequirePullRequest)
+	assert.False(t, got.RequireLinearHistory)
+
+	// Owners can override the default protection afterwards
+	got.RequirePullRequest = false
+	_, err = x.ID(got.ID).AllCols().Update(got)
+	require.NoError(t, err)
+	assert.False(t, IsBranchOfRepoRequirePullRequest(repo.ID, "main"))
+
+	// No protection when disabled
+	// NOTE: The mock is restored when the test finishes.
+	conf.Repository.DefaultBranchProtection.Enabled = false
+	repo, err = CreateRepository(alice, alice, CreateRepoOptionsLegacy{Name: "example2"})
+	require.NoError(t, err)
+	_, err = GetProtectBranchOfRepoByName(repo.ID, "main")
+	assert.True(t, IsErrBranchNotExist(err))
+}
@@ -66,12 +66,31 @@ func (repo *Repository) AddCollaborator(u *User) error {
 	}
 	collaboration.Mode = AccessModeWrite
 
+	if err = repo.GetOwner(); err != nil {
+		return fmt.Errorf("get owner: %v", err)
+	}
+
 	sess := x.NewSession()
 	defer sess.Close()
 	if err = sess.Begin(); err != nil {
 		return err
 	}
 
+	limit := repo.Owner.maxNumCollaborators()
+	if limit > -1 {
+		// Lock the repository row before counting so that concurrent additions are
+		// counted one after another and cannot exceed the limit.
+		if _, err = sess.Exec("UPDATE `repository` SET updated_unix=updated_unix WHERE id = ?", repo.ID); err != nil {
+			return fmt.Errorf("lock repository: %v", err)
+		}
+		count, err := sess.Count(&Collaboration{RepoID: repo.ID})
+		if err != nil {
+			return fmt.Errorf("count collaborators: %v", err)
+		} else if count >= int64(limit) {
+			return ErrReachLimitOfCollaborators{Limit: limit}
+		}
+	}
+
 	if _, err = sess.Insert(collaboration); err != nil {
 		return err
 	} else if err = repo.recalculateAccesses(sess); err != nil {
@@ -81,6 +100,19 @@ func (repo *Repository) AddCollaborator(u *User) error {
 	return sess.Commit()
 }
 
+type ErrReachLimitOfCollaborators struct {
+	Limit int
+}
+
+func IsErrReachLimitOfCollaborators(err error) bool {
+	_, ok := err.(ErrReachLimitOfCollaborators)
+	return ok
+}
+
+func (err ErrReachLimitOfCollaborators) Error() string {
+	return fmt.Sprintf("repository has reached maximum limit of collaborators [limit: %d]", err.Limit)
+}
+
 func (repo *Repository) getCollaborations(e Engine) ([]*Collaboration, error) {
 	collaborations := make([]*Collaboration, 0)
 	return collaborations, e.Find(&collaborations, &Collaboration{RepoID: repo.ID})
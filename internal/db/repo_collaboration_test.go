@@ -0,0 +1,53 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestRepository_AddCollaborator_Limit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Collaboration), new(Access))
+	conf.SetMockRepository(t, conf.RepositoryOpts{MaxCollaborators: 1})
+
+	alice := &User{LowerName: "alice", Name: "alice", MaxCollaborators: -1}
+	bob := &User{LowerName: "bob", Name: "bob", MaxCollaborators: -1}
+	cindy := &User{LowerName: "cindy", Name: "cindy", MaxCollaborators: -1}
+	_, err := x.Insert(alice, bob, cindy)
+	require.NoError(t, err)
+
+	repo := &Repository{OwnerID: alice.ID, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	err = repo.AddCollaborator(bob)
+	require.NoError(t, err)
+
+	// Adding an existing collaborator is a no-op
+	err = repo.AddCollaborator(bob)
+	require.NoError(t, err)
+
+	// The limit has been reached
+	err = repo.AddCollaborator(cindy)
+	assert.Equal(t, ErrReachLimitOfCollaborators{Limit: 1}, err)
+	assert.False(t, IsCollaborator(repo.ID, cindy.ID))
+
+	// The limit of the owner overrides the global default
+	_, err = x.ID(alice.ID).Cols("max_collaborators").Update(&User{MaxCollaborators: 2})
+	require.NoError(t, err)
+	repo.Owner = nil
+	err = repo.AddCollaborator(cindy)
+	require.NoError(t, err)
+	assert.True(t, IsCollaborator(repo.ID, cindy.ID))
+}
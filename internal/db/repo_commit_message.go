@@ -0,0 +1,182 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// ConventionalCommitsPattern is the commit message pattern that matches subjects
+// following the Conventional Commits specification, e.g. "feat(api): add users".
+const ConventionalCommitsPattern = `(build|chore|ci|docs|feat|fix|perf|refactor|revert|style|test)(\([\w./-]+\))?!?: .+`
+
+type ErrCommitMessagePatternInvalid struct {
+	args errutil.Args
+}
+
+func IsErrCommitMessagePatternInvalid(err error) bool {
+	_, ok := err.(ErrCommitMessagePatternInvalid)
+	return ok
+}
+
+func (err ErrCommitMessagePatternInvalid) Error() string {
+	return fmt.Sprintf("commit message pattern is not a valid regular expression: %v", err.args)
+}
+
+// compileCommitMessagePattern compiles the pattern to match the whole subject
+// of a commit message.
+func compileCommitMessagePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, ErrCommitMessagePatternInvalid{args: errutil.Args{"pattern": pattern, "error": err.Error()}}
+	}
+	return re, nil
+}
+
+// ValidateCommitMessagePattern returns ErrCommitMessagePatternInvalid if the
+// pattern is not a valid regular expression. An empty pattern is always valid.
+func ValidateCommitMessagePattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	_, err := compileCommitMessagePattern(pattern)
+	return err
+}
+
+// EffectiveCommitMessagePattern returns the pattern that subjects of pushed
+// commit messages of the repository must match, and whether merge commits are
+// exempted. Both fall back to the default of the organization that owns the
+// repository. An empty pattern means no restriction.
+func (repo *Repository) EffectiveCommitMessagePattern() (pattern string, exemptMerges bool, err error) {
+	if repo.CommitMessagePattern != "" {
+		return repo.CommitMessagePattern, repo.CommitMessageExemptMerges, nil
+	}
+
+	if err := repo.GetOwner(); err != nil {
+		return "", false, fmt.Errorf("get owner: %v", err)
+	}
+	if repo.Owner.IsOrganization() {
+		return repo.Owner.CommitMessagePattern, repo.Owner.CommitMessageExemptMerges, nil
+	}
+	return "", false, nil
+}
+
+type ErrCommitMessageNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrCommitMessageNotAllowed(err error) bool {
+	_, ok := err.(ErrCommitMessageNotAllowed)
+	return ok
+}
+
+func (err ErrCommitMessageNotAllowed) Error() string {
+	return fmt.Sprintf("commit message does not match the required pattern: %v", err.args)
+}
+
+// CommitID returns the ID of the commit whose message does not match the
+// pattern.
+func (err ErrCommitMessageNotAllowed) CommitID() string {
+	return err.args["commitID"].(string)
+}
+
+// Subject returns the subject of the commit message that does not match the
+// pattern.
+func (err ErrCommitMessageNotAllowed) Subject() string {
+	return err.args["subject"].(string)
+}
+
+// Pattern returns the pattern that the commit message does not match.
+func (err ErrCommitMessageNotAllowed) Pattern() string {
+	return err.args["pattern"].(string)
+}
+
+type pushedCommit struct {
+	ID      string
+	IsMerge bool
+	Subject string
+}
+
+// listPushedCommits returns commits that are pushed from the old commit to the
+// new commit in the repository of given path, oldest first. All commits that
+// are not reachable from any existing ref are returned for new branches.
+func listPushedCommits(repoPath, oldCommitID, newCommitID string) ([]*pushedCommit, error) {
+	args := []string{"log", "--reverse", "--format=%H%x00%P%x00%s", newCommitID}
+	if oldCommitID == git.EmptyID {
+		args = append(args, "--not", "--all")
+	} else {
+		args = append(args, "^"+oldCommitID)
+	}
+
+	stderr := new(bytes.Buffer)
+	stdout := new(bytes.Buffer)
+	err := git.NewCommand(args...).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return nil, errors.Errorf("%v - %s", err, stderr)
+	}
+
+	var commits []*pushedCommit
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		// Expect "<commit ID>\x00<parent IDs>\x00<subject>"
+		fields := strings.SplitN(line, "\x00", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		commits = append(commits, &pushedCommit{
+			ID:      fields[0],
+			IsMerge: len(strings.Fields(fields[1])) > 1,
+			Subject: fields[2],
+		})
+	}
+	return commits, nil
+}
+
+// CheckPushCommitMessages checks whether subjects of messages of commits pushed
+// from the old commit to the new commit in the repository of given path match
+// the commit message pattern. It returns ErrCommitMessageNotAllowed for the
+// first commit whose subject does not match the pattern.
+func (repo *Repository) CheckPushCommitMessages(repoPath, oldCommitID, newCommitID string) error {
+	if newCommitID == git.EmptyID {
+		return nil
+	}
+
+	pattern, exemptMerges, err := repo.EffectiveCommitMessagePattern()
+	if err != nil {
+		return err
+	} else if pattern == "" {
+		return nil
+	}
+
+	re, err := compileCommitMessagePattern(pattern)
+	if err != nil {
+		return err
+	}
+
+	commits, err := listPushedCommits(repoPath, oldCommitID, newCommitID)
+	if err != nil {
+		return errors.Wrap(err, "list pushed commits")
+	}
+	for _, c := range commits {
+		if c.IsMerge && exemptMerges {
+			continue
+		}
+		if !re.MatchString(c.Subject) {
+			return ErrCommitMessageNotAllowed{args: errutil.Args{"commitID": c.ID, "subject": c.Subject, "pattern": pattern}}
+		}
+	}
+	return nil
+}
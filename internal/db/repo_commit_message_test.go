@@ -0,0 +1,159 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestValidateCommitMessagePattern(t *testing.T) {
+	assert.NoError(t, ValidateCommitMessagePattern(""))
+	assert.NoError(t, ValidateCommitMessagePattern(ConventionalCommitsPattern))
+	assert.True(t, IsErrCommitMessagePatternInvalid(ValidateCommitMessagePattern("feat(")))
+}
+
+func TestConventionalCommitsPattern(t *testing.T) {
+	re, err := compileCommitMessagePattern(ConventionalCommitsPattern)
+	require.NoError(t, err)
+
+	for _, subject := range []string{
+		"feat: add users",
+		"fix(api): handle empty body",
+		"refactor(db/repo)!: drop legacy columns",
+	} {
+		assert.True(t, re.MatchString(subject), subject)
+	}
+	for _, subject := range []string{
+		"Add users",
+		"feat add users",
+		"feature: add users",
+		"fix(): handle empty body",
+		"wip: fix: handle empty body",
+	} {
+		assert.False(t, re.MatchString(subject), subject)
+	}
+}
+
+func TestRepository_CheckPushCommitMessages(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	run("init", "-b", "main")
+	run("commit", "--allow-empty", "-m", "Initial commit")
+	base := run("rev-parse", "HEAD")
+
+	// Conforming commits on the main branch
+	run("commit", "--allow-empty", "-m", "feat: add users")
+	run("commit", "--allow-empty", "-m", "fix(api): handle empty body\n\nThe body is optional.")
+	conforming := run("rev-parse", "HEAD")
+
+	// A non-conforming commit followed by a conforming one
+	run("checkout", "-b", "nonconforming", base)
+	run("commit", "--allow-empty", "-m", "Add users")
+	nonconformingID := run("rev-parse", "HEAD")
+	run("commit", "--allow-empty", "-m", "Update users")
+	run("commit", "--allow-empty", "-m", "feat: update users")
+	nonconforming := run("rev-parse", "HEAD")
+
+	// A merge commit with the default merge message
+	run("checkout", "-b", "merge", conforming)
+	run("commit", "--allow-empty", "-m", "docs: describe users")
+	run("merge", "--no-ff", "--no-edit", "nonconforming")
+	mergeID := run("rev-parse", "HEAD")
+
+	// Commits of a new branch that are not reachable from any ref
+	run("checkout", "-b", "deleted", conforming)
+	run("commit", "--allow-empty", "-m", "Remove users")
+	newBranchID := run("rev-parse", "HEAD")
+	run("checkout", "main")
+	run("branch", "-D", "deleted")
+
+	org := &User{ID: 1, Name: "acme", Type: UserTypeOrganization, CommitMessagePattern: ConventionalCommitsPattern}
+	tests := []struct {
+		name        string
+		repo        *Repository
+		oldCommitID string
+		newCommitID string
+		wantErr     error
+	}{
+		{
+			name:        "conforming commits",
+			repo:        &Repository{Owner: org},
+			oldCommitID: base,
+			newCommitID: conforming,
+		},
+		{
+			name:        "non-conforming commit",
+			repo:        &Repository{Owner: org},
+			oldCommitID: base,
+			newCommitID: nonconforming,
+			wantErr:     ErrCommitMessageNotAllowed{args: errutil.Args{"commitID": nonconformingID, "subject": "Add users", "pattern": ConventionalCommitsPattern}},
+		},
+		{
+			name:        "merge commit",
+			repo:        &Repository{Owner: org},
+			oldCommitID: nonconforming,
+			newCommitID: mergeID,
+			wantErr:     ErrCommitMessageNotAllowed{args: errutil.Args{"commitID": mergeID, "subject": "Merge branch 'nonconforming' into merge", "pattern": ConventionalCommitsPattern}},
+		},
+		{
+			name:        "exempt merge commit",
+			repo:        &Repository{Owner: &User{ID: 1, Name: "acme", Type: UserTypeOrganization, CommitMessagePattern: ConventionalCommitsPattern, CommitMessageExemptMerges: true}},
+			oldCommitID: nonconforming,
+			newCommitID: mergeID,
+		},
+		{
+			name:        "repository pattern overrides the organization",
+			repo:        &Repository{Owner: org, CommitMessagePattern: "[A-Z].+"},
+			oldCommitID: base,
+			newCommitID: nonconforming,
+			wantErr:     ErrCommitMessageNotAllowed{args: errutil.Args{"commitID": nonconforming, "subject": "feat: update users", "pattern": "[A-Z].+"}},
+		},
+		{
+			name:        "new branch",
+			repo:        &Repository{Owner: org},
+			oldCommitID: git.EmptyID,
+			newCommitID: newBranchID,
+			wantErr:     ErrCommitMessageNotAllowed{args: errutil.Args{"commitID": newBranchID, "subject": "Remove users", "pattern": ConventionalCommitsPattern}},
+		},
+		{
+			name:        "delete branch",
+			repo:        &Repository{Owner: org},
+			oldCommitID: nonconforming,
+			newCommitID: git.EmptyID,
+		},
+		{
+			name:        "no pattern",
+			repo:        &Repository{Owner: &User{ID: 2, Name: "alice"}},
+			oldCommitID: base,
+			newCommitID: nonconforming,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.repo.CheckPushCommitMessages(repoPath, test.oldCommitID, test.newCommitID)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
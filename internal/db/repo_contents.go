@@ -0,0 +1,221 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/gitutil"
+	"gogs.io/gogs/internal/pathutil"
+)
+
+type ErrRepoFilePathInvalid struct {
+	args errutil.Args
+}
+
+func IsErrRepoFilePathInvalid(err error) bool {
+	_, ok := err.(ErrRepoFilePathInvalid)
+	return ok
+}
+
+func (err ErrRepoFilePathInvalid) Error() string {
+	return fmt.Sprintf("repository file path is not valid: %v", err.args)
+}
+
+var _ errutil.NotFound = (*ErrRepoFileNotExist)(nil)
+
+type ErrRepoFileNotExist struct {
+	args errutil.Args
+}
+
+func IsErrRepoFileNotExist(err error) bool {
+	_, ok := err.(ErrRepoFileNotExist)
+	return ok
+}
+
+func (err ErrRepoFileNotExist) Error() string {
+	return fmt.Sprintf("repository file does not exist: %v", err.args)
+}
+
+func (ErrRepoFileNotExist) NotFound() bool {
+	return true
+}
+
+type ErrRepoFileSHAMismatch struct {
+	args errutil.Args
+}
+
+func IsErrRepoFileSHAMismatch(err error) bool {
+	_, ok := err.(ErrRepoFileSHAMismatch)
+	return ok
+}
+
+func (err ErrRepoFileSHAMismatch) Error() string {
+	return fmt.Sprintf("repository file does not match the SHA: %v", err.args)
+}
+
+type ErrBranchWriteNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrBranchWriteNotAllowed(err error) bool {
+	_, ok := err.(ErrBranchWriteNotAllowed)
+	return ok
+}
+
+func (err ErrBranchWriteNotAllowed) Error() string {
+	return fmt.Sprintf("branch is protected from direct writes: %v", err.args)
+}
+
+// CommitRepoFileOptions contains options for committing a single file change to
+// a branch.
+type CommitRepoFileOptions struct {
+	Branch   string
+	TreePath string
+	Content  []byte
+	// Whether the file must not exist yet.
+	IsNewFile bool
+	// Whether to delete the file, which must exist.
+	IsDelete bool
+	// The expected blob SHA of the existing file, the change is rejected with
+	// ErrRepoFileSHAMismatch if the file has been changed. Optional.
+	SHA string
+
+	// The author and committer default to the doer, and their time defaults to now.
+	Author    *git.Signature
+	Committer *git.Signature
+	Message   string
+}
+
+// CommitRepoFile creates, updates or deletes a single file by committing
+// directly on top of the branch, without a local copy of the repository. The
+// commit is pushed on behalf of the doer through server-side hooks like any
+// other push, which also fire the push events. It returns the ID of the new
+// commit.
+func (repo *Repository) CommitRepoFile(doer *User, opts CommitRepoFileOptions) (string, error) {
+	_, newCommitID, err := repo.commitRepoFile(doer, opts)
+	return newCommitID, err
+}
+
+// checkBranchWritable returns ErrBranchWriteNotAllowed if the doer is not
+// allowed to directly write to the branch by its protection options, following
+// the same rules as pushes.
+func (repo *Repository) checkBranchWritable(doer *User, branch string) error {
+	protectBranch, err := GetProtectBranchOfRepoByName(repo.ID, branch)
+	if err != nil {
+		if IsErrBranchNotExist(err) {
+			return nil
+		}
+		return errors.Wrap(err, "get protect branch")
+	} else if !protectBranch.Protected {
+		return nil
+	}
+
+	if protectBranch.EnableWhitelist {
+		if !IsUserInProtectBranchWhitelist(repo.ID, doer.ID, branch) {
+			return ErrBranchWriteNotAllowed{args: errutil.Args{"branch": branch, "reason": "not in the push whitelist"}}
+		}
+		return nil
+	}
+	if protectBranch.RequirePullRequest {
+		return ErrBranchWriteNotAllowed{args: errutil.Args{"branch": branch, "reason": "pull request required"}}
+	}
+	return nil
+}
+
+func (repo *Repository) commitRepoFile(doer *User, opts CommitRepoFileOptions) (oldCommitID, newCommitID string, err error) {
+	// 🚨 SECURITY: Prevent writing files into the ".git" directory
+	treePath := pathutil.Clean(opts.TreePath)
+	if treePath == "" || isRepositoryGitPath(treePath) {
+		return "", "", ErrRepoFilePathInvalid{args: errutil.Args{"path": opts.TreePath}}
+	}
+
+	err = repo.checkBranchWritable(doer, opts.Branch)
+	if err != nil {
+		return "", "", err
+	}
+
+	repoPath := repo.RepoPath()
+	gitRepo, err := git.Open(repoPath)
+	if err != nil {
+		return "", "", errors.Wrap(err, "open repository")
+	}
+	oldCommitID, err = gitRepo.BranchCommitID(opts.Branch)
+	if err != nil {
+		return "", "", ErrBranchNotExist{args: errutil.Args{"name": opts.Branch}}
+	}
+	commit, err := gitRepo.CatFileCommit(oldCommitID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "get commit")
+	}
+
+	var blobID string
+	entry, err := commit.TreeEntry(treePath)
+	if err == nil {
+		if entry.IsTree() {
+			return "", "", ErrRepoFilePathInvalid{args: errutil.Args{"path": opts.TreePath, "reason": "is a directory"}}
+		}
+		blobID = entry.ID().String()
+	} else if !gitutil.IsErrRevisionNotExist(err) {
+		return "", "", errors.Wrap(err, "get tree entry")
+	}
+
+	switch {
+	case opts.IsNewFile && blobID != "":
+		return "", "", ErrRepoFileAlreadyExist{FileName: treePath}
+	case opts.IsDelete && blobID == "":
+		return "", "", ErrRepoFileNotExist{args: errutil.Args{"path": treePath}}
+	case opts.SHA != "" && opts.SHA != blobID:
+		return "", "", ErrRepoFileSHAMismatch{args: errutil.Args{"path": treePath, "sha": opts.SHA}}
+	}
+
+	now := time.Now()
+	withDefault := func(sig *git.Signature) *git.Signature {
+		if sig == nil {
+			return &git.Signature{
+				Name:  doer.DisplayName(),
+				Email: doer.CommitEmail(),
+				When:  now,
+			}
+		} else if sig.When.IsZero() {
+			return &git.Signature{
+				Name:  sig.Name,
+				Email: sig.Email,
+				When:  now,
+			}
+		}
+		return sig
+	}
+	author := withDefault(opts.Author)
+	committer := withDefault(opts.Committer)
+
+	newCommitID, err = gitutil.CommitFile(repoPath, gitutil.CommitFileOptions{
+		Ref:       git.RefsHeads + opts.Branch,
+		ParentID:  oldCommitID,
+		TreePath:  treePath,
+		Content:   opts.Content,
+		Delete:    opts.IsDelete,
+		Author:    author,
+		Committer: committer,
+		Message:   opts.Message,
+		PushEnvs: ComposeHookEnvs(ComposeHookEnvsOptions{
+			AuthUser:  doer,
+			OwnerName: repo.MustOwner().Name,
+			OwnerSalt: repo.MustOwner().Salt,
+			RepoID:    repo.ID,
+			RepoName:  repo.Name,
+			RepoPath:  repoPath,
+		}),
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err, "commit file")
+	}
+	return oldCommitID, newCommitID, nil
+}
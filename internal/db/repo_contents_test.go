@@ -0,0 +1,220 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitutil"
+)
+
+func TestRepository_commitRepoFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(ProtectBranch), new(ProtectBranchWhitelist))
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{ID: 1, Name: "alice", Email: "alice@example.com"}
+	repo := &Repository{ID: 1, Name: "example", OwnerID: alice.ID, Owner: alice}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	err = os.WriteFile(filepath.Join(workPath, "README.md"), []byte("Hello"), 0o644)
+	require.NoError(t, err)
+	run("add", "README.md")
+	run("commit", "-m", "Initial commit")
+	run("push", "origin", "main")
+
+	gitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	blobID := func(t *testing.T, commitID, treePath string) string {
+		commit, err := gitRepo.CatFileCommit(commitID)
+		require.NoError(t, err)
+		entry, err := commit.TreeEntry(treePath)
+		require.NoError(t, err)
+		return entry.ID().String()
+	}
+	readFile := func(t *testing.T, commitID, treePath string) string {
+		commit, err := gitRepo.CatFileCommit(commitID)
+		require.NoError(t, err)
+		blob, err := commit.Blob(treePath)
+		require.NoError(t, err)
+		p, err := blob.Bytes()
+		require.NoError(t, err)
+		return string(p)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		oldCommitID, newCommitID, err := repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:    "main",
+			TreePath:  "docs/guide.md",
+			Content:   []byte("Guide"),
+			IsNewFile: true,
+			Author:    &git.Signature{Name: "bob", Email: "bob@example.com"},
+			Message:   "Add guide",
+		})
+		require.NoError(t, err)
+
+		branchCommitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+		assert.Equal(t, newCommitID, branchCommitID)
+		assert.Equal(t, "Guide", readFile(t, newCommitID, "docs/guide.md"))
+		assert.Equal(t, "Hello", readFile(t, newCommitID, "README.md"))
+
+		commit, err := gitRepo.CatFileCommit(newCommitID)
+		require.NoError(t, err)
+		parentID, err := commit.ParentID(0)
+		require.NoError(t, err)
+		assert.Equal(t, oldCommitID, parentID.String())
+		assert.Equal(t, "bob", commit.Author.Name)
+		assert.Equal(t, "alice", commit.Committer.Name)
+		assert.Equal(t, "Add guide", commit.Message)
+
+		_, _, err = repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:    "main",
+			TreePath:  "docs/guide.md",
+			Content:   []byte("Guide"),
+			IsNewFile: true,
+			Message:   "Add guide again",
+		})
+		assert.True(t, IsErrRepoFileAlreadyExist(err), "%v", err)
+	})
+
+	t.Run("update with the correct SHA", func(t *testing.T) {
+		commitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+
+		_, newCommitID, err := repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "README.md",
+			Content:  []byte("Hello world"),
+			SHA:      blobID(t, commitID, "README.md"),
+			Message:  "Update README",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Hello world", readFile(t, newCommitID, "README.md"))
+	})
+
+	t.Run("conflict on a stale SHA", func(t *testing.T) {
+		commitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+
+		_, _, err = repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "README.md",
+			Content:  []byte("Hello again"),
+			SHA:      "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391",
+			Message:  "Update README",
+		})
+		assert.True(t, IsErrRepoFileSHAMismatch(err), "%v", err)
+
+		branchCommitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+		assert.Equal(t, commitID, branchCommitID)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		commitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+
+		_, newCommitID, err := repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "docs/guide.md",
+			IsDelete: true,
+			SHA:      blobID(t, commitID, "docs/guide.md"),
+			Message:  "Remove guide",
+		})
+		require.NoError(t, err)
+
+		commit, err := gitRepo.CatFileCommit(newCommitID)
+		require.NoError(t, err)
+		_, err = commit.TreeEntry("docs/guide.md")
+		assert.Error(t, err)
+
+		_, _, err = repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "docs/guide.md",
+			IsDelete: true,
+			Message:  "Remove guide again",
+		})
+		assert.True(t, IsErrRepoFileNotExist(err), "%v", err)
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		_, _, err := repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: ".git/config",
+			Content:  []byte("[core]"),
+			Message:  "Update config",
+		})
+		assert.True(t, IsErrRepoFilePathInvalid(err), "%v", err)
+	})
+
+	t.Run("rejected by hooks", func(t *testing.T) {
+		hook := "#!/bin/sh\nif [ \"$GOGS_AUTH_USER_NAME\" != bob ]; then\n  echo \"only bob can push\" >&2\n  exit 1\nfi\n"
+		hookPath := filepath.Join(repoPath, "hooks", "pre-receive")
+		err := os.WriteFile(hookPath, []byte(hook), 0o755)
+		require.NoError(t, err)
+		defer func() { _ = os.Remove(hookPath) }()
+
+		oldCommitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+		_, _, err = repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "README.md",
+			Content:  []byte("Hello from alice"),
+			Message:  "Update README",
+		})
+		assert.Equal(t, gitutil.ErrPushRejected, errors.Cause(err))
+
+		branchCommitID, err := gitRepo.BranchCommitID("main")
+		require.NoError(t, err)
+		assert.Equal(t, oldCommitID, branchCommitID)
+	})
+
+	t.Run("protected branch", func(t *testing.T) {
+		_, err := x.Insert(&ProtectBranch{
+			RepoID:             repo.ID,
+			Name:               "main",
+			Protected:          true,
+			RequirePullRequest: true,
+		})
+		require.NoError(t, err)
+
+		_, _, err = repo.commitRepoFile(alice, CommitRepoFileOptions{
+			Branch:   "main",
+			TreePath: "README.md",
+			Content:  []byte("Hello"),
+			Message:  "Update README",
+		})
+		assert.True(t, IsErrBranchWriteNotAllowed(err), "%v", err)
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"archive/zip"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+type exportedRepoIssue struct {
+	ID        int64     `json:"id"`
+	Index     int64     `json:"index"`
+	Poster    string    `json:"poster"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Labels    []string  `json:"labels"`
+	Milestone string    `json:"milestone,omitempty"`
+	Assignee  string    `json:"assignee,omitempty"`
+	IsPull    bool      `json:"is_pull"`
+	IsClosed  bool      `json:"is_closed"`
+	Created   time.Time `json:"created_at"`
+	Updated   time.Time `json:"updated_at"`
+
+	// Attributes of pull requests.
+	HeadBranch     string `json:"head_branch,omitempty"`
+	BaseBranch     string `json:"base_branch,omitempty"`
+	HasMerged      bool   `json:"has_merged,omitempty"`
+	MergedCommitID string `json:"merged_commit_id,omitempty"`
+}
+
+type exportedRepoComment struct {
+	ID         int64       `json:"id"`
+	IssueIndex int64       `json:"issue_index"`
+	Type       CommentType `json:"type"`
+	Poster     string      `json:"poster"`
+	Content    string      `json:"content"`
+	CommitSHA  string      `json:"commit_sha,omitempty"`
+	Created    time.Time   `json:"created_at"`
+	Updated    time.Time   `json:"updated_at"`
+}
+
+// exportRepositoryIssues writes a zip archive of issues and pull requests of
+// the repository with their comments to the writer.
+func exportRepositoryIssues(e Engine, repo *Repository, w io.Writer) error {
+	issues := make([]*Issue, 0, 25)
+	if err := e.Where("repo_id = ?", repo.ID).Asc("`index`").Find(&issues); err != nil {
+		return errors.Wrap(err, "list issues")
+	}
+
+	unix := func(t int64) time.Time { return time.Unix(t, 0).UTC() }
+	exportedIssues := make([]*exportedRepoIssue, 0, len(issues))
+	exportedComments := make([]*exportedRepoComment, 0, len(issues))
+	for _, issue := range issues {
+		issue.Repo = repo
+		if err := issue.loadAttributes(e); err != nil {
+			return errors.Wrapf(err, "load attributes of issue %d", issue.ID)
+		}
+
+		v := &exportedRepoIssue{
+			ID:       issue.ID,
+			Index:    issue.Index,
+			Poster:   issue.Poster.Name,
+			Title:    issue.Title,
+			Content:  issue.Content,
+			Labels:   make([]string, 0, len(issue.Labels)),
+			IsPull:   issue.IsPull,
+			IsClosed: issue.IsClosed,
+			Created:  unix(issue.CreatedUnix),
+			Updated:  unix(issue.UpdatedUnix),
+		}
+		for _, l := range issue.Labels {
+			v.Labels = append(v.Labels, l.Name)
+		}
+		if issue.Milestone != nil {
+			v.Milestone = issue.Milestone.Name
+		}
+		if issue.Assignee != nil {
+			v.Assignee = issue.Assignee.Name
+		}
+		if pr := issue.PullRequest; pr != nil {
+			v.HeadBranch = pr.HeadBranch
+			v.BaseBranch = pr.BaseBranch
+			v.HasMerged = pr.HasMerged
+			v.MergedCommitID = pr.MergedCommitID
+		}
+		exportedIssues = append(exportedIssues, v)
+
+		for _, c := range issue.Comments {
+			exportedComments = append(exportedComments, &exportedRepoComment{
+				ID:         c.ID,
+				IssueIndex: issue.Index,
+				Type:       c.Type,
+				Poster:     c.Poster.Name,
+				Content:    c.Content,
+				CommitSHA:  c.CommitSHA,
+				Created:    unix(c.CreatedUnix),
+				Updated:    unix(c.UpdatedUnix),
+			})
+		}
+	}
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"repository.json", &exportedRepository{
+			ID:            repo.ID,
+			Name:          repo.Name,
+			Description:   repo.Description,
+			Website:       repo.Website,
+			DefaultBranch: repo.DefaultBranch,
+			IsPrivate:     repo.IsPrivate,
+			IsFork:        repo.IsFork,
+			Created:       unix(repo.CreatedUnix),
+			Updated:       unix(repo.UpdatedUnix),
+		}},
+		{"issues.json", exportedIssues},
+		{"comments.json", exportedComments},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		data, err := jsoniter.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "marshal %q", f.name)
+		}
+
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrapf(err, "create %q", f.name)
+		}
+		_, err = fw.Write(data)
+		if err != nil {
+			return errors.Wrapf(err, "write %q", f.name)
+		}
+	}
+	return zw.Close()
+}
+
+// exportDeletedRepositoryIssues exports issues and pull requests of the
+// repository that is about to be deleted to an archive in the configured
+// directory, and returns the path of the archive.
+func exportDeletedRepositoryIssues(e Engine, ownerName string, repo *Repository) (string, error) {
+	dir := filepath.Join(conf.Repository.Deletion.ExportPath, strings.ToLower(ownerName))
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return "", errors.Wrap(err, "create directory")
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%s-%d-%d.zip", repo.LowerName, repo.ID, time.Now().Unix()))
+	f, err := os.Create(name)
+	if err != nil {
+		return "", errors.Wrap(err, "create file")
+	}
+
+	err = exportRepositoryIssues(e, repo, f)
+	if err == nil {
+		err = f.Close()
+	} else {
+		_ = f.Close()
+	}
+	if err != nil {
+		_ = os.Remove(name)
+		return "", err
+	}
+	return name, nil
+}
+
+// commitRefCommentPattern matches the content of commit reference comments,
+// see updateCommitReferencesToIssues.
+var commitRefCommentPattern = regexp.MustCompile(`(?s)^<a href="[^"]*/commit/([0-9a-f]+)">(.*)</a>$`)
+
+// tombstoneCrossReferences rewrites commit reference comments that link to the
+// repository in issues of other repositories to plain text that marks the
+// repository as deleted. It must be called after comments of the repository
+// itself have been deleted.
+func tombstoneCrossReferences(e Engine, repo *Repository) error {
+	prefix := `<a href="` + repo.Link() + `/commit/`
+	comments := make([]*Comment, 0, 10)
+	err := e.Where("type = ? AND content LIKE ?", COMMENT_TYPE_COMMIT_REF, prefix+"%").Find(&comments)
+	if err != nil {
+		return errors.Wrap(err, "list commit reference comments")
+	}
+
+	for _, c := range comments {
+		// LIKE patterns may have matched more than the prefix, e.g. underscores.
+		if !strings.HasPrefix(c.Content, prefix) {
+			continue
+		}
+		m := commitRefCommentPattern.FindStringSubmatch(c.Content)
+		if m == nil {
+			continue
+		}
+
+		sha := m[1]
+		if len(sha) > 10 {
+			sha = sha[:10]
+		}
+		content := fmt.Sprintf(`<del title="deleted repository">%s@%s</del> %s`, template.HTMLEscapeString(repo.FullName()), sha, m[2])
+		if _, err = e.Exec("UPDATE `comment` SET content = ? WHERE id = ?", content, c.ID); err != nil {
+			return errors.Wrapf(err, "update comment %d", c.ID)
+		}
+	}
+	return nil
+}
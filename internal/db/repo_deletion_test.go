@@ -0,0 +1,184 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"archive/zip"
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+// setupDeleteRepositoryTest creates a repository with an issue and a pull
+// request, and an issue of another repository that is referenced by a commit of
+// the repository. It returns the owner, the repository and the reference
+// comment.
+func setupDeleteRepositoryTest(t *testing.T, deletionOpts func(opts *conf.RepositoryOpts)) (owner *User, repo *Repository, refComment *Comment) {
+	t.Helper()
+
+	setTestEngine(t,
+		new(User), new(Repository), new(Access), new(Action), new(Watch), new(Star), new(Mirror),
+		new(Issue), new(IssueUser), new(Milestone), new(Release), new(Collaboration), new(PullRequest),
+		new(ProtectBranch), new(ProtectBranchWhitelist), new(Webhook), new(HookTask), new(LFSObject),
+		new(CLASignature), new(RepoInvitation), new(IgnoredRepo), new(RepoTopic), new(RepoSecret),
+		new(RepoNotificationRecipient), new(ReviewRequest), new(Approval), new(LinkedIssue),
+		new(CommitStatus), new(Deployment), new(DeploymentStatus), new(IssueWorkflowState), new(RepoLanguage),
+		new(Comment), new(Attachment), new(Label), new(IssueLabel),
+	)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	repoOpts.Deletion.ExportPath = t.TempDir()
+	deletionOpts(&repoOpts)
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "deleteRepository", new(User), new(EmailAddress))
+	SetMockUsersStore(t, NewUsersStore(gdb))
+
+	alice, err := Users.Create(ctx, "alice", "alice@example.com", CreateUserOptions{Activated: true})
+	require.NoError(t, err)
+	_, err = x.Insert(alice)
+	require.NoError(t, err)
+
+	repo = &Repository{OwnerID: alice.ID, Owner: alice, LowerName: "example", Name: "example", DefaultBranch: "main"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	other := &Repository{OwnerID: alice.ID, Owner: alice, LowerName: "other", Name: "other"}
+	_, err = x.Insert(other)
+	require.NoError(t, err)
+
+	label := &Label{RepoID: repo.ID, Name: "bug", Color: "#ee0701"}
+	_, err = x.Insert(label)
+	require.NoError(t, err)
+	issue := &Issue{RepoID: repo.ID, Index: 1, PosterID: alice.ID, Title: "Crash on start", Content: "It crashes"}
+	_, err = x.Insert(issue)
+	require.NoError(t, err)
+	_, err = x.Insert(&IssueLabel{IssueID: issue.ID, LabelID: label.ID})
+	require.NoError(t, err)
+	_, err = x.Insert(&Comment{Type: COMMENT_TYPE_COMMENT, PosterID: alice.ID, IssueID: issue.ID, Content: "Confirmed"})
+	require.NoError(t, err)
+
+	pull := &Issue{RepoID: repo.ID, Index: 2, PosterID: alice.ID, Title: "Fix crash", IsPull: true, IsClosed: true}
+	_, err = x.Insert(pull)
+	require.NoError(t, err)
+	_, err = x.Insert(&PullRequest{
+		IssueID:        pull.ID,
+		Index:          pull.Index,
+		HeadRepoID:     repo.ID,
+		BaseRepoID:     repo.ID,
+		HeadBranch:     "fix",
+		BaseBranch:     "main",
+		HasMerged:      true,
+		MergedCommitID: "2c1d2a6ad6c5a9c3c6b0c6ae4b8b2a1f23c8e7a1",
+	})
+	require.NoError(t, err)
+
+	otherIssue := &Issue{RepoID: other.ID, Index: 1, PosterID: alice.ID, Title: "Track crash"}
+	_, err = x.Insert(otherIssue)
+	require.NoError(t, err)
+	refComment = &Comment{
+		Type:      COMMENT_TYPE_COMMIT_REF,
+		PosterID:  alice.ID,
+		IssueID:   otherIssue.ID,
+		CommitSHA: "2c1d2a6ad6c5a9c3c6b0c6ae4b8b2a1f23c8e7a1",
+		Content:   `<a href="` + repo.Link() + `/commit/2c1d2a6ad6c5a9c3c6b0c6ae4b8b2a1f23c8e7a1">Fix crash</a>`,
+	}
+	_, err = x.Insert(refComment)
+	require.NoError(t, err)
+	return alice, repo, refComment
+}
+
+func getCommentContent(t *testing.T, id int64) string {
+	t.Helper()
+	c := new(Comment)
+	has, err := x.ID(id).Get(c)
+	require.NoError(t, err)
+	require.True(t, has)
+	return c.Content
+}
+
+func TestDeleteRepository_keepCrossReferences(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	owner, repo, refComment := setupDeleteRepositoryTest(t, func(opts *conf.RepositoryOpts) {
+		opts.Deletion.ExportIssues = false
+		opts.Deletion.CrossReferences = conf.RepoDeletionCrossReferencesKeep
+	})
+	err := DeleteRepository(owner.ID, repo.ID)
+	require.NoError(t, err)
+
+	archives, err := filepath.Glob(filepath.Join(conf.Repository.Deletion.ExportPath, "*", "*.zip"))
+	require.NoError(t, err)
+	assert.Empty(t, archives)
+	assert.Equal(t, refComment.Content, getCommentContent(t, refComment.ID))
+}
+
+func TestDeleteRepository_exportIssues(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	owner, repo, refComment := setupDeleteRepositoryTest(t, func(opts *conf.RepositoryOpts) {
+		opts.Deletion.ExportIssues = true
+		opts.Deletion.CrossReferences = conf.RepoDeletionCrossReferencesTombstone
+	})
+	err := DeleteRepository(owner.ID, repo.ID)
+	require.NoError(t, err)
+
+	count, err := x.Where("repo_id = ?", repo.ID).Count(new(Issue))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, `<del title="deleted repository">alice/example@2c1d2a6ad6</del> Fix crash`, getCommentContent(t, refComment.ID))
+
+	archives, err := filepath.Glob(filepath.Join(conf.Repository.Deletion.ExportPath, "alice", "example-*.zip"))
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+
+	zr, err := zip.OpenReader(archives[0])
+	require.NoError(t, err)
+	defer func() { _ = zr.Close() }()
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		_ = r.Close()
+		files[f.Name] = data
+	}
+	require.Len(t, files, 3)
+
+	var issues []*exportedRepoIssue
+	err = jsoniter.Unmarshal(files["issues.json"], &issues)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "Crash on start", issues[0].Title)
+	assert.Equal(t, "alice", issues[0].Poster)
+	assert.Equal(t, []string{"bug"}, issues[0].Labels)
+	assert.True(t, issues[1].IsPull)
+	assert.True(t, issues[1].HasMerged)
+	assert.Equal(t, "fix", issues[1].HeadBranch)
+
+	var comments []*exportedRepoComment
+	err = jsoniter.Unmarshal(files["comments.json"], &comments)
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	assert.Equal(t, int64(1), comments[0].IssueIndex)
+	assert.Equal(t, "Confirmed", comments[0].Content)
+}
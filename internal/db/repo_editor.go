@@ -189,7 +189,7 @@ func (repo *Repository) UpdateRepoFile(doer *User, opts UpdateRepoFileOptions) (
 		localPath,
 		&git.Signature{
 			Name:  doer.DisplayName(),
-			Email: doer.Email,
+			Email: doer.CommitEmail(),
 			When:  time.Now(),
 		},
 		opts.Message,
@@ -311,7 +311,7 @@ func (repo *Repository) DeleteRepoFile(doer *User, opts DeleteRepoFileOptions) (
 		localPath,
 		&git.Signature{
 			Name:  doer.DisplayName(),
-			Email: doer.Email,
+			Email: doer.CommitEmail(),
 			When:  time.Now(),
 		},
 		opts.Message,
@@ -562,7 +562,7 @@ func (repo *Repository) UploadRepoFiles(doer *User, opts UploadRepoFileOptions)
 		localPath,
 		&git.Signature{
 			Name:  doer.DisplayName(),
-			Email: doer.Email,
+			Email: doer.CommitEmail(),
 			When:  time.Now(),
 		},
 		opts.Message,
@@ -5,9 +5,14 @@
 package db
 
 import (
+	"path/filepath"
 	"testing"
 
+	"github.com/gogs/git-module"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
 )
 
 func TestIsRepositoryGitPath(t *testing.T) {
@@ -57,3 +62,74 @@ func TestIsRepositoryGitPath(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_UpdateRepoFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+	conf.SetMockUser(t, conf.UserOpts{NoReplyDomain: "noreply.example.com"})
+
+	alice := &User{ID: 1, Name: "alice", Email: "alice@example.com"}
+	repo := &Repository{ID: 1, Name: "example", OwnerID: alice.ID, Owner: alice}
+	repoPath := repo.RepoPath()
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	tmpDir := filepath.Join(t.TempDir(), "example")
+	err = prepareRepoCommit(repo, tmpDir, repoPath, CreateRepoOptionsLegacy{Name: "example", Readme: "Default"})
+	require.NoError(t, err)
+	err = initRepoCommit(tmpDir, &git.Signature{Name: "alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+
+	gitRepo, err := git.Open(repoPath)
+	require.NoError(t, err)
+	branch, err := gitRepo.SymbolicRef()
+	require.NoError(t, err)
+	branch = git.RefShortName(branch)
+
+	tests := []struct {
+		name             string
+		keepEmailPrivate bool
+		wantEmail        string
+	}{
+		{
+			name:      "public email",
+			wantEmail: "alice@example.com",
+		},
+		{
+			name:             "private email",
+			keepEmailPrivate: true,
+			wantEmail:        "1+alice@noreply.example.com",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			alice.KeepEmailPrivate = test.keepEmailPrivate
+			err := repo.UpdateRepoFile(alice,
+				UpdateRepoFileOptions{
+					OldBranch:   branch,
+					NewBranch:   branch,
+					OldTreeName: "README.md",
+					NewTreeName: "README.md",
+					Message:     "Update README.md",
+					Content:     test.name,
+				},
+			)
+			require.NoError(t, err)
+
+			commit, err := gitRepo.BranchCommit(branch)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantEmail, commit.Author.Email)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+type ErrPrivateForkNotAllowed struct {
+	args errutil.Args
+}
+
+// IsErrPrivateForkNotAllowed returns true if the underlying error has the type
+// ErrPrivateForkNotAllowed.
+func IsErrPrivateForkNotAllowed(err error) bool {
+	_, ok := err.(ErrPrivateForkNotAllowed)
+	return ok
+}
+
+func (err ErrPrivateForkNotAllowed) Error() string {
+	return fmt.Sprintf("fork of private repository is not allowed for the owner: %v", err.args)
+}
+
+// checkPrivateForkAllowed returns ErrPrivateForkNotAllowed when the base
+// repository is private and owned by an organization that restricts private
+// forks, but the owner of the fork is not a member of the organization.
+func checkPrivateForkAllowed(e Engine, baseRepo *Repository, owner *User) error {
+	if !baseRepo.IsPrivate {
+		return nil
+	}
+
+	if err := baseRepo.getOwner(e); err != nil {
+		return fmt.Errorf("get owner of base repository: %v", err)
+	}
+	org := baseRepo.Owner
+	if !org.IsOrganization() || !org.RestrictPrivateForks {
+		return nil
+	}
+
+	if !owner.IsOrganization() && IsOrganizationMember(org.ID, owner.ID) {
+		return nil
+	}
+	return ErrPrivateForkNotAllowed{args: errutil.Args{"repoID": baseRepo.ID, "ownerID": owner.ID}}
+}
+
+// checkForkVisibility returns ErrRepoVisibilityNotAllowed when the repository is
+// a public fork of a private repository, i.e. a fork can never be more public
+// than its base repository.
+func checkForkVisibility(e Engine, repo *Repository) error {
+	if repo.IsPrivate || !repo.IsFork {
+		return nil
+	}
+
+	baseRepo := repo.BaseRepo
+	if baseRepo == nil {
+		var err error
+		baseRepo, err = getRepositoryByID(e, repo.ForkID)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				return nil
+			}
+			return fmt.Errorf("get base repository: %v", err)
+		}
+	}
+	if !baseRepo.IsPrivate {
+		return nil
+	}
+	return ErrRepoVisibilityNotAllowed{args: errutil.Args{"repoID": repo.ID, "baseRepoID": baseRepo.ID}}
+}
+
+// HasBaseAccess returns false if the repository is a fork of a private
+// repository that the user has no access to. Forks of private repositories are
+// only accessible to users who have access to their base repositories,
+// regardless of access to the forks.
+func (repo *Repository) HasBaseAccess(ctx context.Context, userID int64) bool {
+	if !repo.IsFork {
+		return true
+	}
+
+	baseRepo := repo.BaseRepo
+	if baseRepo == nil {
+		var err error
+		baseRepo, err = Repos.GetByID(ctx, repo.ForkID)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				return true
+			}
+			log.Error("Failed to get base repository [repo_id: %d, fork_id: %d]: %v", repo.ID, repo.ForkID, err)
+			return false
+		}
+	}
+	if !baseRepo.IsPrivate {
+		return true
+	}
+
+	return Perms.Authorize(ctx, userID, baseRepo.ID, AccessModeRead,
+		AccessModeOptions{
+			OwnerID: baseRepo.OwnerID,
+			Private: baseRepo.IsPrivate,
+		},
+	)
+}
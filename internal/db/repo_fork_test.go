@@ -0,0 +1,135 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestNewForkRepository(t *testing.T) {
+	conf.SetMockRepository(t, conf.RepositoryOpts{})
+	alice := &User{ID: 1, Name: "alice"}
+
+	t.Run("fork of private repository is private", func(t *testing.T) {
+		fork := newForkRepository(alice, &Repository{ID: 1, IsPrivate: true}, "example", "")
+		assert.True(t, fork.IsPrivate)
+		assert.True(t, fork.IsFork)
+		assert.Equal(t, int64(1), fork.ForkID)
+		assert.True(t, fork.EnableReleases)
+	})
+
+	t.Run("fork of public repository is public", func(t *testing.T) {
+		fork := newForkRepository(alice, &Repository{ID: 1}, "example", "")
+		assert.False(t, fork.IsPrivate)
+	})
+
+	t.Run("fork to owner that only allows private repositories", func(t *testing.T) {
+		org := &User{ID: 2, Name: "acme", Type: UserTypeOrganization, ForcePrivateRepos: true}
+		fork := newForkRepository(org, &Repository{ID: 1}, "example", "")
+		assert.True(t, fork.IsPrivate)
+	})
+}
+
+func TestForkVisibility(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Repository))
+	conf.SetMockRepository(t, conf.RepositoryOpts{})
+	alice := &User{ID: 1, Name: "alice"}
+	base := &Repository{ID: 1, Name: "example", IsPrivate: true}
+
+	// A fork cannot be made more public than its base repository
+	fork := &Repository{ID: 2, OwnerID: alice.ID, Owner: alice, Name: "example", IsFork: true, ForkID: base.ID, BaseRepo: base, IsPrivate: true}
+	_, err := x.Insert(fork)
+	require.NoError(t, err)
+	fork.IsPrivate = false
+	err = UpdateRepository(fork, true)
+	wantErr := ErrRepoVisibilityNotAllowed{args: errutil.Args{"repoID": fork.ID, "baseRepoID": base.ID}}
+	assert.Equal(t, wantErr, err)
+
+	assert.NoError(t, checkForkVisibility(x, &Repository{ID: 2, IsPrivate: true, IsFork: true, BaseRepo: base}))
+	assert.NoError(t, checkForkVisibility(x, &Repository{ID: 2, IsFork: true, BaseRepo: &Repository{ID: 1}}))
+}
+
+func TestPrivateForkRestriction(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Repository), new(OrgUser))
+	conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: -1})
+
+	org := &User{ID: 1, Name: "acme", Type: UserTypeOrganization, RestrictPrivateForks: true}
+	member := &User{ID: 2, Name: "alice", MaxRepoCreation: -1}
+	outsider := &User{ID: 3, Name: "bob", MaxRepoCreation: -1}
+	otherOrg := &User{ID: 4, Name: "other", Type: UserTypeOrganization, MaxRepoCreation: -1}
+	_, err := x.Insert(&OrgUser{OrgID: org.ID, Uid: member.ID})
+	require.NoError(t, err)
+
+	privateRepo := &Repository{ID: 1, OwnerID: org.ID, Owner: org, Name: "private", IsPrivate: true}
+	publicRepo := &Repository{ID: 2, OwnerID: org.ID, Owner: org, Name: "public"}
+
+	t.Run("cross-organization fork is blocked", func(t *testing.T) {
+		_, err := ForkRepository(member, otherOrg, privateRepo, "private", "")
+		wantErr := ErrPrivateForkNotAllowed{args: errutil.Args{"repoID": privateRepo.ID, "ownerID": otherOrg.ID}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("fork by outsider is blocked", func(t *testing.T) {
+		_, err := ForkRepository(outsider, outsider, privateRepo, "private", "")
+		assert.True(t, IsErrPrivateForkNotAllowed(err))
+	})
+
+	t.Run("fork by member is allowed", func(t *testing.T) {
+		assert.NoError(t, checkPrivateForkAllowed(x, privateRepo, member))
+	})
+
+	t.Run("public repository is not restricted", func(t *testing.T) {
+		assert.NoError(t, checkPrivateForkAllowed(x, publicRepo, otherOrg))
+	})
+
+	t.Run("restriction disabled", func(t *testing.T) {
+		org := &User{ID: 1, Name: "acme", Type: UserTypeOrganization}
+		repo := &Repository{ID: 1, OwnerID: org.ID, Owner: org, Name: "private", IsPrivate: true}
+		assert.NoError(t, checkPrivateForkAllowed(x, repo, otherOrg))
+	})
+}
+
+func TestRepository_HasBaseAccess(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	db := dbtest.NewDB(t, "repositoryHasBaseAccess", new(Access))
+	SetMockPermsStore(t, NewPermsStore(db))
+
+	base := &Repository{ID: 1, OwnerID: 1, IsPrivate: true}
+	collaborator := int64(2)
+	forker := int64(3)
+	err := db.Create(&Access{UserID: collaborator, RepoID: base.ID, Mode: AccessModeRead}).Error
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	fork := &Repository{ID: 2, OwnerID: forker, IsPrivate: true, IsFork: true, ForkID: base.ID, BaseRepo: base}
+	assert.True(t, fork.HasBaseAccess(ctx, base.OwnerID))
+	assert.True(t, fork.HasBaseAccess(ctx, collaborator))
+	// The forker has lost access to the base repository
+	assert.False(t, fork.HasBaseAccess(ctx, forker))
+
+	// Forks of public repositories and non-forks are not restricted
+	publicFork := &Repository{ID: 3, OwnerID: forker, IsFork: true, ForkID: 4, BaseRepo: &Repository{ID: 4, OwnerID: 1}}
+	assert.True(t, publicFork.HasBaseAccess(ctx, forker))
+	assert.True(t, (&Repository{ID: 5, OwnerID: forker, IsPrivate: true}).HasBaseAccess(ctx, forker))
+}
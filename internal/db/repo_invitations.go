@@ -0,0 +1,266 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+// RepoInvitationsStore is the persistent interface for invitations of
+// repository collaborators.
+type RepoInvitationsStore interface {
+	// Create creates a new invitation for the invitee to collaborate on the
+	// repository with given access mode, which expires after
+	// conf.Repository.CollaboratorInvitationLifetime. It returns
+	// ErrRepoInvitationAlreadyExist when an unexpired invitation already exists.
+	Create(ctx context.Context, repoID, inviterID, inviteeID int64, mode AccessMode) (*RepoInvitation, error)
+	// GetByID returns the unexpired invitation with given ID for the invitee. It
+	// returns ErrRepoInvitationNotExist when not found.
+	//
+	// 🚨 SECURITY: The "inviteeID" is required to prevent attacker gets
+	// arbitrary invitation that belongs to another user.
+	GetByID(ctx context.Context, inviteeID, id int64) (*RepoInvitation, error)
+	// ListByRepoID returns all unexpired invitations of the repository, sorted
+	// from the oldest.
+	ListByRepoID(ctx context.Context, repoID int64) ([]*RepoInvitation, error)
+	// ListByInviteeID returns all unexpired invitations for the invitee, sorted
+	// from the oldest.
+	ListByInviteeID(ctx context.Context, inviteeID int64) ([]*RepoInvitation, error)
+	// Accept accepts the unexpired invitation with given ID for the invitee,
+	// which grants the invitee the access mode of the invitation to the
+	// repository and deletes the invitation. It returns
+	// ErrRepoInvitationNotExist when not found.
+	Accept(ctx context.Context, inviteeID, id int64) error
+	// Decline deletes the unexpired invitation with given ID for the invitee
+	// without granting any access. It returns ErrRepoInvitationNotExist when not
+	// found.
+	Decline(ctx context.Context, inviteeID, id int64) error
+	// DeleteByID deletes the invitation with given ID of the repository. It
+	// returns ErrRepoInvitationNotExist when not found.
+	DeleteByID(ctx context.Context, repoID, id int64) error
+	// DeleteExpired deletes all expired invitations and returns the number of
+	// deleted invitations.
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+var RepoInvitations RepoInvitationsStore
+
+var _ RepoInvitationsStore = (*repoInvitations)(nil)
+
+type repoInvitations struct {
+	*gorm.DB
+}
+
+// NewRepoInvitationsStore returns a persistent interface for invitations of
+// repository collaborators with given database connection.
+func NewRepoInvitationsStore(db *gorm.DB) RepoInvitationsStore {
+	return &repoInvitations{DB: db}
+}
+
+// RepoInvitation is an invitation for a user to collaborate on a repository.
+type RepoInvitation struct {
+	ID        int64      `gorm:"primaryKey"`
+	RepoID    int64      `gorm:"uniqueIndex:repo_invitation_repo_invitee_unique;not null"`
+	InviterID int64      `gorm:"not null"`
+	InviteeID int64      `gorm:"uniqueIndex:repo_invitation_repo_invitee_unique;index;not null"`
+	Mode      AccessMode `gorm:"not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Expires     time.Time `gorm:"-" json:"-"`
+	ExpiresUnix int64     `gorm:"index"`
+}
+
+// BeforeCreate implements the GORM create hook.
+func (inv *RepoInvitation) BeforeCreate(tx *gorm.DB) error {
+	if inv.CreatedUnix == 0 {
+		inv.CreatedUnix = tx.NowFunc().Unix()
+	}
+	if inv.ExpiresUnix == 0 {
+		inv.ExpiresUnix = inv.CreatedUnix + int64(conf.Repository.CollaboratorInvitationLifetime.Seconds())
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (inv *RepoInvitation) AfterFind(_ *gorm.DB) error {
+	inv.Created = time.Unix(inv.CreatedUnix, 0).Local()
+	inv.Expires = time.Unix(inv.ExpiresUnix, 0).Local()
+	return nil
+}
+
+type ErrRepoInvitationAlreadyExist struct {
+	args errutil.Args
+}
+
+// IsErrRepoInvitationAlreadyExist returns true if the underlying error has the
+// type ErrRepoInvitationAlreadyExist.
+func IsErrRepoInvitationAlreadyExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoInvitationAlreadyExist)
+	return ok
+}
+
+func (err ErrRepoInvitationAlreadyExist) Error() string {
+	return fmt.Sprintf("repository invitation already exists: %v", err.args)
+}
+
+func (db *repoInvitations) Create(ctx context.Context, repoID, inviterID, inviteeID int64, mode AccessMode) (*RepoInvitation, error) {
+	inv := &RepoInvitation{
+		RepoID:    repoID,
+		InviterID: inviterID,
+		InviteeID: inviteeID,
+		Mode:      mode,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// An expired invitation is replaced by the new one.
+		err := tx.Where("repo_id = ? AND invitee_id = ? AND expires_unix <= ?", repoID, inviteeID, tx.NowFunc().Unix()).
+			Delete(new(RepoInvitation)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete expired invitation")
+		}
+
+		err = tx.Where("repo_id = ? AND invitee_id = ?", repoID, inviteeID).First(new(RepoInvitation)).Error
+		if err == nil {
+			return ErrRepoInvitationAlreadyExist{args: errutil.Args{"repoID": repoID, "inviteeID": inviteeID}}
+		} else if err != gorm.ErrRecordNotFound {
+			return err
+		}
+		return tx.Create(inv).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return db.GetByID(ctx, inviteeID, inv.ID)
+}
+
+var _ errutil.NotFound = (*ErrRepoInvitationNotExist)(nil)
+
+type ErrRepoInvitationNotExist struct {
+	args errutil.Args
+}
+
+// IsErrRepoInvitationNotExist returns true if the underlying error has the type
+// ErrRepoInvitationNotExist.
+func IsErrRepoInvitationNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoInvitationNotExist)
+	return ok
+}
+
+func (err ErrRepoInvitationNotExist) Error() string {
+	return fmt.Sprintf("repository invitation does not exist: %v", err.args)
+}
+
+func (ErrRepoInvitationNotExist) NotFound() bool {
+	return true
+}
+
+func (db *repoInvitations) GetByID(ctx context.Context, inviteeID, id int64) (*RepoInvitation, error) {
+	inv := new(RepoInvitation)
+	err := db.WithContext(ctx).
+		Where("id = ? AND invitee_id = ? AND expires_unix > ?", id, inviteeID, db.NowFunc().Unix()).
+		First(inv).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, ErrRepoInvitationNotExist{args: errutil.Args{"inviteeID": inviteeID, "id": id}}
+		}
+		return nil, err
+	}
+	return inv, nil
+}
+
+func (db *repoInvitations) ListByRepoID(ctx context.Context, repoID int64) ([]*RepoInvitation, error) {
+	var invs []*RepoInvitation
+	return invs, db.WithContext(ctx).
+		Where("repo_id = ? AND expires_unix > ?", repoID, db.NowFunc().Unix()).
+		Order("id ASC").
+		Find(&invs).
+		Error
+}
+
+func (db *repoInvitations) ListByInviteeID(ctx context.Context, inviteeID int64) ([]*RepoInvitation, error) {
+	var invs []*RepoInvitation
+	return invs, db.WithContext(ctx).
+		Where("invitee_id = ? AND expires_unix > ?", inviteeID, db.NowFunc().Unix()).
+		Order("id ASC").
+		Find(&invs).
+		Error
+}
+
+func (db *repoInvitations) Accept(ctx context.Context, inviteeID, id int64) error {
+	inv, err := db.GetByID(ctx, inviteeID, id)
+	if err != nil {
+		return err
+	}
+
+	repo, err := GetRepositoryByID(inv.RepoID)
+	if err != nil {
+		return errors.Wrap(err, "get repository by ID")
+	}
+	invitee, err := getUserByID(x, inviteeID)
+	if err != nil {
+		return errors.Wrap(err, "get user by ID")
+	}
+
+	if err = repo.AddCollaborator(invitee); err != nil {
+		return errors.Wrap(err, "add collaborator")
+	} else if err = repo.ChangeCollaborationAccessMode(inviteeID, inv.Mode); err != nil {
+		return errors.Wrap(err, "change collaboration access mode")
+	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionCollaboratorAdd, repo.ID, repo.FullName(),
+		fmt.Sprintf("collaborator: %s, permission: %s", invitee.Name, inv.Mode))
+	return db.WithContext(ctx).Delete(inv).Error
+}
+
+func (db *repoInvitations) Decline(ctx context.Context, inviteeID, id int64) error {
+	inv, err := db.GetByID(ctx, inviteeID, id)
+	if err != nil {
+		return err
+	}
+	return db.WithContext(ctx).Delete(inv).Error
+}
+
+func (db *repoInvitations) DeleteByID(ctx context.Context, repoID, id int64) error {
+	result := db.WithContext(ctx).Where("id = ? AND repo_id = ?", id, repoID).Delete(new(RepoInvitation))
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrRepoInvitationNotExist{args: errutil.Args{"repoID": repoID, "id": id}}
+	}
+	return nil
+}
+
+func (db *repoInvitations) DeleteExpired(ctx context.Context) (int64, error) {
+	result := db.WithContext(ctx).Where("expires_unix <= ?", db.NowFunc().Unix()).Delete(new(RepoInvitation))
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpiredRepoInvitations deletes all expired invitations of repository
+// collaborators.
+func DeleteExpiredRepoInvitations() {
+	if taskStatusTable.IsRunning(_DELETE_EXPIRED_REPO_INVITATIONS) {
+		return
+	}
+	taskStatusTable.Start(_DELETE_EXPIRED_REPO_INVITATIONS)
+	defer taskStatusTable.Stop(_DELETE_EXPIRED_REPO_INVITATIONS)
+
+	log.Trace("Doing: DeleteExpiredRepoInvitations")
+
+	count, err := RepoInvitations.DeleteExpired(context.Background())
+	if err != nil {
+		log.Error("Failed to delete expired repository invitations: %v", err)
+		return
+	}
+	log.Trace("Deleted %d expired repository invitations", count)
+}
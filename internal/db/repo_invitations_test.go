@@ -0,0 +1,251 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestRepoInvitations(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	conf.SetMockRepository(t, conf.RepositoryOpts{CollaboratorInvitationLifetime: time.Hour})
+	tables := []any{new(RepoInvitation)}
+	db := &repoInvitations{
+		DB: dbtest.NewDB(t, "repoInvitations", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *repoInvitations)
+	}{
+		{"Create", repoInvitationsCreate},
+		{"GetByID", repoInvitationsGetByID},
+		{"List", repoInvitationsList},
+		{"Decline", repoInvitationsDecline},
+		{"DeleteByID", repoInvitationsDeleteByID},
+		{"DeleteExpired", repoInvitationsDeleteExpired},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+// createExpiredRepoInvitation creates an invitation that has already expired.
+func createExpiredRepoInvitation(t *testing.T, db *repoInvitations, repoID, inviteeID int64) *RepoInvitation {
+	now := db.NowFunc().Unix()
+	inv := &RepoInvitation{
+		RepoID:      repoID,
+		InviterID:   1,
+		InviteeID:   inviteeID,
+		Mode:        AccessModeWrite,
+		CreatedUnix: now - 3600,
+		ExpiresUnix: now - 1,
+	}
+	err := db.DB.Create(inv).Error
+	require.NoError(t, err)
+	return inv
+}
+
+func repoInvitationsCreate(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2, AccessModeRead)
+	require.NoError(t, err)
+	assert.Equal(t, AccessModeRead, inv.Mode)
+	assert.Equal(t, db.NowFunc().Unix(), inv.CreatedUnix)
+	assert.Greater(t, inv.ExpiresUnix, inv.CreatedUnix)
+
+	// Inviting the same user again should fail while the invitation is pending
+	_, err = db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	wantErr := ErrRepoInvitationAlreadyExist{args: errutil.Args{"repoID": int64(1), "inviteeID": int64(2)}}
+	assert.Equal(t, wantErr, err)
+
+	// An expired invitation should be replaced
+	createExpiredRepoInvitation(t, db, 2, 2)
+	got, err := db.Create(ctx, 2, 1, 2, AccessModeAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, AccessModeAdmin, got.Mode)
+	assert.Greater(t, got.ExpiresUnix, db.NowFunc().Unix())
+}
+
+func repoInvitationsGetByID(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	require.NoError(t, err)
+
+	got, err := db.GetByID(ctx, 2, inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, inv.RepoID, got.RepoID)
+	assert.Equal(t, inv.ExpiresUnix, got.Expires.Unix())
+
+	// Invitations of other users are not visible
+	_, err = db.GetByID(ctx, 3, inv.ID)
+	wantErr := ErrRepoInvitationNotExist{args: errutil.Args{"inviteeID": int64(3), "id": inv.ID}}
+	assert.Equal(t, wantErr, err)
+
+	// Expired invitations are not visible
+	expired := createExpiredRepoInvitation(t, db, 2, 2)
+	_, err = db.GetByID(ctx, 2, expired.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+}
+
+func repoInvitationsList(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv1, err := db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	require.NoError(t, err)
+	inv2, err := db.Create(ctx, 1, 1, 3, AccessModeWrite)
+	require.NoError(t, err)
+	inv3, err := db.Create(ctx, 2, 1, 2, AccessModeRead)
+	require.NoError(t, err)
+	createExpiredRepoInvitation(t, db, 3, 2)
+
+	invs, err := db.ListByRepoID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, invs, 2)
+	assert.Equal(t, inv1.ID, invs[0].ID)
+	assert.Equal(t, inv2.ID, invs[1].ID)
+
+	invs, err = db.ListByInviteeID(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, invs, 2)
+	assert.Equal(t, inv1.ID, invs[0].ID)
+	assert.Equal(t, inv3.ID, invs[1].ID)
+}
+
+func repoInvitationsDecline(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	require.NoError(t, err)
+
+	// Only the invitee can decline
+	err = db.Decline(ctx, 3, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+
+	err = db.Decline(ctx, 2, inv.ID)
+	require.NoError(t, err)
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+
+	// A declined invitation can't be declined again
+	err = db.Decline(ctx, 2, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+
+	// Expired invitations can't be declined
+	expired := createExpiredRepoInvitation(t, db, 2, 2)
+	err = db.Decline(ctx, 2, expired.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+}
+
+func repoInvitationsDeleteByID(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	require.NoError(t, err)
+
+	// Invitations of other repositories can't be deleted
+	err = db.DeleteByID(ctx, 2, inv.ID)
+	wantErr := ErrRepoInvitationNotExist{args: errutil.Args{"repoID": int64(2), "id": inv.ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 1, inv.ID)
+	require.NoError(t, err)
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+}
+
+func repoInvitationsDeleteExpired(t *testing.T, db *repoInvitations) {
+	ctx := context.Background()
+
+	inv, err := db.Create(ctx, 1, 1, 2, AccessModeWrite)
+	require.NoError(t, err)
+	createExpiredRepoInvitation(t, db, 2, 2)
+	createExpiredRepoInvitation(t, db, 3, 2)
+
+	count, err := db.DeleteExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	var total int64
+	err = db.Model(new(RepoInvitation)).Count(&total).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	_, err = db.GetByID(ctx, 2, inv.ID)
+	require.NoError(t, err)
+}
+
+func TestRepoInvitations_Accept(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	conf.SetMockRepository(t, conf.RepositoryOpts{
+		MaxCollaborators:               -1,
+		CollaboratorInvitationLifetime: time.Hour,
+	})
+	setTestEngine(t, new(User), new(Repository), new(Collaboration), new(Access))
+	db := &repoInvitations{
+		DB: dbtest.NewDB(t, "repoInvitationsAccept", new(RepoInvitation), new(AuditLog)),
+	}
+	ctx := context.Background()
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", MaxCollaborators: -1}
+	invitee := &User{ID: 2, LowerName: "bob", Name: "bob"}
+	_, err := x.Insert(owner, invitee)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, LowerName: "example", Name: "example"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	inv, err := db.Create(ctx, repo.ID, owner.ID, invitee.ID, AccessModeAdmin)
+	require.NoError(t, err)
+
+	// Only the invitee can accept
+	err = db.Accept(ctx, owner.ID, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+	assert.False(t, IsCollaborator(repo.ID, invitee.ID))
+
+	// Accepting grants the access mode of the invitation
+	err = db.Accept(ctx, invitee.ID, inv.ID)
+	require.NoError(t, err)
+	collaboration := &Collaboration{RepoID: repo.ID, UserID: invitee.ID}
+	has, err := x.Get(collaboration)
+	require.NoError(t, err)
+	require.True(t, has)
+	assert.Equal(t, AccessModeAdmin, collaboration.Mode)
+
+	// An accepted invitation is removed
+	err = db.Accept(ctx, invitee.ID, inv.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+
+	// Expired invitations are not accepted
+	expired := createExpiredRepoInvitation(t, db, 2, invitee.ID)
+	err = db.Accept(ctx, invitee.ID, expired.ID)
+	assert.True(t, IsErrRepoInvitationNotExist(err))
+}
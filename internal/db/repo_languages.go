@@ -0,0 +1,219 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	"github.com/unknwon/com"
+	"gorm.io/gorm"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/langutil"
+	"gogs.io/gogs/internal/repoutil"
+	"gogs.io/gogs/internal/sync"
+)
+
+// RepoLanguagesStore is the persistent interface for language statistics of
+// repositories.
+type RepoLanguagesStore interface {
+	// GetCommitSHA returns the commit that language statistics of the repository
+	// are computed at, or an empty string if they have never been computed.
+	GetCommitSHA(ctx context.Context, repoID int64) (string, error)
+	// List returns language statistics of the repository, sorted by bytes in
+	// descending order.
+	List(ctx context.Context, repoID int64) ([]*RepoLanguage, error)
+	// Update replaces language statistics of the repository with the number of
+	// bytes of each language computed at the given commit. The commit is
+	// recorded even when no language is detected.
+	Update(ctx context.Context, repoID int64, commitSHA string, stats map[string]int64) error
+}
+
+var RepoLanguages RepoLanguagesStore
+
+var _ RepoLanguagesStore = (*repoLanguages)(nil)
+
+type repoLanguages struct {
+	*gorm.DB
+}
+
+// NewRepoLanguagesStore returns a persistent interface for language statistics
+// of repositories with given database connection.
+func NewRepoLanguagesStore(db *gorm.DB) RepoLanguagesStore {
+	return &repoLanguages{DB: db}
+}
+
+// RepoLanguage is the number of bytes of a language in the default branch of a
+// repository. A row with an empty language only records the commit when no
+// language is detected, so the repository is not scanned again.
+type RepoLanguage struct {
+	ID       int64  `gorm:"primaryKey"`
+	RepoID   int64  `gorm:"uniqueIndex:repo_language_repo_language_unique;not null"`
+	Language string `gorm:"uniqueIndex:repo_language_repo_language_unique;not null"`
+	Bytes    int64  `gorm:"not null"`
+	// CommitSHA is the commit that the statistics are computed at.
+	CommitSHA string `gorm:"type:VARCHAR(40);not null"`
+}
+
+func (db *repoLanguages) GetCommitSHA(ctx context.Context, repoID int64) (string, error) {
+	var languages []*RepoLanguage
+	err := db.WithContext(ctx).
+		Select("commit_sha").
+		Where("repo_id = ?", repoID).
+		Limit(1).
+		Find(&languages).
+		Error
+	if err != nil || len(languages) == 0 {
+		return "", err
+	}
+	return languages[0].CommitSHA, nil
+}
+
+func (db *repoLanguages) List(ctx context.Context, repoID int64) ([]*RepoLanguage, error) {
+	var languages []*RepoLanguage
+	return languages, db.WithContext(ctx).
+		Where("repo_id = ? AND language != ?", repoID, "").
+		Order("bytes DESC, language ASC").
+		Find(&languages).
+		Error
+}
+
+func (db *repoLanguages) Update(ctx context.Context, repoID int64, commitSHA string, stats map[string]int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("repo_id = ?", repoID).Delete(new(RepoLanguage)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete existing")
+		}
+
+		languages := make([]*RepoLanguage, 0, len(stats))
+		for language, bytes := range stats {
+			languages = append(languages, &RepoLanguage{
+				RepoID:    repoID,
+				Language:  language,
+				Bytes:     bytes,
+				CommitSHA: commitSHA,
+			})
+		}
+		if len(languages) == 0 {
+			languages = append(languages, &RepoLanguage{
+				RepoID:    repoID,
+				CommitSHA: commitSHA,
+			})
+		}
+		return tx.Create(&languages).Error
+	})
+}
+
+// computeLanguageStats scans blobs of the tree of the commit in the repository
+// and returns the number of bytes of each language. Ignored paths and files
+// larger than the configured size are skipped.
+func computeLanguageStats(repoPath, commitSHA string) (langutil.Stats, error) {
+	opts := conf.Repository.Languages
+	stdout, err := git.NewCommand("ls-tree", "-r", "-l", "-z", commitSHA).RunInDir(repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "list tree")
+	}
+
+	stats := make(langutil.Stats)
+	for _, line := range bytes.Split(stdout, []byte{0}) {
+		// Format: <mode> SP <type> SP <object> SP <size> TAB <path>
+		tab := bytes.IndexByte(line, '\t')
+		if tab < 0 {
+			continue
+		}
+		fields := bytes.Fields(line[:tab])
+		if len(fields) != 4 || string(fields[1]) != "blob" {
+			continue
+		}
+		filepath := string(line[tab+1:])
+		size, err := strconv.ParseInt(string(fields[3]), 10, 64)
+		if err != nil || (opts.MaxFileSize > 0 && size > opts.MaxFileSize) {
+			continue
+		} else if langutil.IsIgnored(filepath, opts.IgnoredPaths) {
+			continue
+		}
+
+		var content []byte
+		if langutil.NeedsContent(filepath) {
+			content, err = git.NewCommand("cat-file", "blob", string(fields[2])).RunInDir(repoPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "read blob %q", filepath)
+			}
+		}
+		stats.Add(filepath, size, content)
+	}
+	return stats, nil
+}
+
+// RepoLanguagesQueue is the queue of repository IDs whose language statistics
+// need to be recomputed.
+var RepoLanguagesQueue = sync.NewUniqueQueue(1000)
+
+// AddRepoLanguagesTask adds the repository to the queue to recompute its
+// language statistics.
+func AddRepoLanguagesTask(repoID int64) {
+	if !conf.Repository.Languages.Enabled {
+		return
+	}
+	go RepoLanguagesQueue.Add(repoID)
+}
+
+// updateRepoLanguages recomputes language statistics of the repository from
+// its default branch, unless they have been computed at the same commit.
+func updateRepoLanguages(ctx context.Context, repoID int64) error {
+	repo, err := Repos.GetByID(ctx, repoID)
+	if err != nil {
+		return errors.Wrap(err, "get repository")
+	}
+	owner, err := Users.GetByID(ctx, repo.OwnerID)
+	if err != nil {
+		return errors.Wrap(err, "get owner")
+	}
+
+	repoPath := repoutil.RepositoryPath(owner.Name, repo.Name)
+	gitRepo, err := git.Open(repoPath)
+	if err != nil {
+		return errors.Wrap(err, "open repository")
+	}
+	commit, err := gitRepo.BranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return errors.Wrap(err, "get default branch commit")
+	}
+
+	commitSHA, err := RepoLanguages.GetCommitSHA(ctx, repo.ID)
+	if err != nil {
+		return errors.Wrap(err, "get commit SHA")
+	} else if commitSHA == commit.ID.String() {
+		return nil
+	}
+
+	stats, err := computeLanguageStats(repoPath, commit.ID.String())
+	if err != nil {
+		return errors.Wrap(err, "compute language stats")
+	}
+	return RepoLanguages.Update(ctx, repo.ID, commit.ID.String(), stats)
+}
+
+// UpdateRepoLanguages listens on the queue and recomputes language statistics
+// of repositories.
+func UpdateRepoLanguages() {
+	for repoID := range RepoLanguagesQueue.Queue() {
+		log.Trace("UpdateRepoLanguages[%v]: processing task", repoID)
+		RepoLanguagesQueue.Remove(repoID)
+
+		if err := updateRepoLanguages(context.Background(), com.StrTo(repoID).MustInt64()); err != nil {
+			log.Error("Failed to update languages of repository %s: %v", repoID, err)
+		}
+	}
+}
+
+func InitUpdateRepoLanguages() {
+	go UpdateRepoLanguages()
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/langutil"
+)
+
+func TestRepoLanguages(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(RepoLanguage)}
+	db := &repoLanguages{
+		DB: dbtest.NewDB(t, "repoLanguages", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *repoLanguages)
+	}{
+		{"GetCommitSHA", repoLanguagesGetCommitSHA},
+		{"List", repoLanguagesList},
+		{"Update", repoLanguagesUpdate},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func repoLanguagesGetCommitSHA(t *testing.T, db *repoLanguages) {
+	ctx := context.Background()
+
+	// Never computed
+	got, err := db.GetCommitSHA(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	err = db.Update(ctx, 1, "sha1", map[string]int64{"Go": 100})
+	require.NoError(t, err)
+	got, err = db.GetCommitSHA(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "sha1", got)
+
+	// No language is detected
+	err = db.Update(ctx, 2, "sha2", nil)
+	require.NoError(t, err)
+	got, err = db.GetCommitSHA(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "sha2", got)
+}
+
+func repoLanguagesList(t *testing.T, db *repoLanguages) {
+	ctx := context.Background()
+
+	err := db.Update(ctx, 1, "sha1", map[string]int64{"Go": 100, "Shell": 300, "CSS": 100})
+	require.NoError(t, err)
+	err = db.Update(ctx, 2, "sha2", map[string]int64{"Rust": 500})
+	require.NoError(t, err)
+
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	// Sorted by bytes, then by name for ties
+	var names []string
+	for _, l := range got {
+		names = append(names, l.Language)
+		assert.Equal(t, "sha1", l.CommitSHA)
+	}
+	assert.Equal(t, []string{"Shell", "CSS", "Go"}, names)
+}
+
+func repoLanguagesUpdate(t *testing.T, db *repoLanguages) {
+	ctx := context.Background()
+
+	err := db.Update(ctx, 1, "sha1", map[string]int64{"Go": 100, "Shell": 300})
+	require.NoError(t, err)
+
+	// Updating should replace all existing statistics
+	err = db.Update(ctx, 1, "sha2", map[string]int64{"Go": 200})
+	require.NoError(t, err)
+	got, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Go", got[0].Language)
+	assert.Equal(t, int64(200), got[0].Bytes)
+	assert.Equal(t, "sha2", got[0].CommitSHA)
+
+	err = db.Update(ctx, 1, "sha3", nil)
+	require.NoError(t, err)
+	got, err = db.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	commitSHA, err := db.GetCommitSHA(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "sha3", commitSHA)
+
+	// Languages detected later replace the empty result
+	err = db.Update(ctx, 1, "sha4", map[string]int64{"Go": 300})
+	require.NoError(t, err)
+	got, err = db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "Go", got[0].Language)
+}
+
+func TestComputeLanguageStats(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Languages.MaxFileSize = 1024
+	repoOpts.Languages.IgnoredPaths = []string{"vendor/", "*.min.js"}
+	conf.SetMockRepository(t, repoOpts)
+
+	workPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+		return string(stdout)
+	}
+	write := func(name, content string) {
+		err := os.MkdirAll(filepath.Join(workPath, filepath.Dir(name)), 0o755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+	}
+
+	run("init", "-b", "main")
+	write("main.go", "package main\n")
+	write("internal/util.go", "package internal\n")
+	write("bin/run", "#!/bin/sh\necho\n")
+	write("include/util.h", "int add(int a, int b);\n")
+	write("public/app.js", "alert(1)\n")
+	write("public/jquery.min.js", "!function(){}\n")
+	write("vendor/lib/lib.go", "package lib\n")
+	write("big.py", string(make([]byte, 2048)))
+	write("README.md", "Hello\n")
+	run("add", ".")
+	run("commit", "-m", "Initial commit")
+	sha := run("rev-parse", "HEAD")
+
+	got, err := computeLanguageStats(workPath, sha[:40])
+	require.NoError(t, err)
+	want := langutil.Stats{
+		"Go":         int64(len("package main\n") + len("package internal\n")),
+		"Shell":      int64(len("#!/bin/sh\necho\n")),
+		"C":          int64(len("int add(int a, int b);\n")),
+		"JavaScript": int64(len("alert(1)\n")),
+	}
+	assert.Equal(t, want, got)
+}
@@ -0,0 +1,139 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// RepoLifecycleEvent is the event of a repository lifecycle hook.
+type RepoLifecycleEvent string
+
+const (
+	RepoLifecycleCreated     RepoLifecycleEvent = "created"
+	RepoLifecycleDeleted     RepoLifecycleEvent = "deleted"
+	RepoLifecycleTransferred RepoLifecycleEvent = "transferred"
+	RepoLifecycleRenamed     RepoLifecycleEvent = "renamed"
+)
+
+// RepoLifecyclePayload is the JSON payload delivered to the instance-wide
+// repository lifecycle hook.
+type RepoLifecyclePayload struct {
+	Event    RepoLifecycleEvent `json:"event"`
+	ID       int64              `json:"id"`
+	Owner    string             `json:"owner"`
+	Name     string             `json:"name"`
+	FullName string             `json:"full_name"`
+	Private  bool               `json:"private"`
+	// PreviousOwner is only set for the "transferred" event.
+	PreviousOwner string `json:"previous_owner,omitempty"`
+	// PreviousName is only set for the "renamed" event.
+	PreviousName string `json:"previous_name,omitempty"`
+	Sender       string `json:"sender,omitempty"`
+}
+
+func newRepoLifecyclePayload(event RepoLifecycleEvent, doer *User, ownerName string, repo *Repository) *RepoLifecyclePayload {
+	p := &RepoLifecyclePayload{
+		Event:    event,
+		ID:       repo.ID,
+		Owner:    ownerName,
+		Name:     repo.Name,
+		FullName: ownerName + "/" + repo.Name,
+		Private:  repo.IsPrivate,
+	}
+	if doer != nil {
+		p.Sender = doer.Name
+	}
+	return p
+}
+
+// deliverRepoLifecycleHook delivers the payload to the configured HTTP callback
+// and/or runs the configured command with the payload as its standard input.
+func deliverRepoLifecycleHook(ctx context.Context, p *RepoLifecyclePayload) error {
+	data, err := jsoniter.Marshal(p)
+	if err != nil {
+		return errors.Wrap(err, "marshal payload")
+	}
+
+	opts := conf.Repository.Lifecycle
+	if opts.URL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.URL, bytes.NewReader(data))
+		if err != nil {
+			return errors.Wrap(err, "new request")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gogs-Event", "repository_"+string(p.Event))
+		if opts.Secret != "" {
+			sig := hmac.New(sha256.New, []byte(opts.Secret))
+			_, _ = sig.Write(data)
+			req.Header.Set("X-Gogs-Signature", hex.EncodeToString(sig.Sum(nil)))
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return errors.Wrap(err, "send request")
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode/100 != 2 {
+			return errors.Errorf("unexpected response status %d", resp.StatusCode)
+		}
+	}
+
+	if opts.Command != "" {
+		cmd := exec.CommandContext(ctx, opts.Command)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Env = append(os.Environ(),
+			"GOGS_REPO_EVENT="+string(p.Event),
+			"GOGS_REPO_ID="+fmt.Sprint(p.ID),
+			"GOGS_REPO_OWNER="+p.Owner,
+			"GOGS_REPO_NAME="+p.Name,
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return errors.Wrapf(err, "run command: %s", output)
+		}
+	}
+	return nil
+}
+
+// notifyRepoLifecycle delivers the repository lifecycle hook asynchronously
+// when it is enabled, so it never blocks the operation. Failures are logged.
+func notifyRepoLifecycle(p *RepoLifecyclePayload) {
+	if !conf.Repository.Lifecycle.Enabled {
+		return
+	}
+
+	go func() {
+		timeout := conf.Repository.Lifecycle.Timeout
+		if timeout <= 0 {
+			timeout = 10 * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		err := deliverRepoLifecycleHook(ctx, p)
+		if err != nil {
+			log.Error("Failed to deliver repository lifecycle hook [event: %s, repo: %s]: %v", p.Event, p.FullName, err)
+		}
+	}()
+}
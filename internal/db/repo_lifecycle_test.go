@@ -0,0 +1,140 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func setRepoLifecycleConf(t *testing.T, url, secret, command string) {
+	before := conf.Repository.Lifecycle
+	t.Cleanup(func() {
+		conf.Repository.Lifecycle = before
+	})
+
+	conf.Repository.Lifecycle.Enabled = true
+	conf.Repository.Lifecycle.URL = url
+	conf.Repository.Lifecycle.Secret = secret
+	conf.Repository.Lifecycle.Command = command
+	conf.Repository.Lifecycle.Timeout = 5 * time.Second
+}
+
+func TestNotifyRepoLifecycle(t *testing.T) {
+	type received struct {
+		event   string
+		payload *RepoLifecyclePayload
+	}
+	receivedCh := make(chan received, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		p := new(RepoLifecyclePayload)
+		err = jsoniter.Unmarshal(data, p)
+		require.NoError(t, err)
+
+		sig := hmac.New(sha256.New, []byte("secret"))
+		_, _ = sig.Write(data)
+		assert.Equal(t, hex.EncodeToString(sig.Sum(nil)), r.Header.Get("X-Gogs-Signature"))
+
+		receivedCh <- received{
+			event:   r.Header.Get("X-Gogs-Event"),
+			payload: p,
+		}
+	}))
+	defer server.Close()
+	setRepoLifecycleConf(t, server.URL, "secret", "")
+
+	p := newRepoLifecyclePayload(RepoLifecycleTransferred, &User{Name: "alice"}, "org1", &Repository{ID: 1, Name: "repo1"})
+	p.PreviousOwner = "alice"
+	notifyRepoLifecycle(p)
+
+	select {
+	case got := <-receivedCh:
+		assert.Equal(t, "repository_transferred", got.event)
+		assert.Equal(t,
+			&RepoLifecyclePayload{
+				Event:         RepoLifecycleTransferred,
+				ID:            1,
+				Owner:         "org1",
+				Name:          "repo1",
+				FullName:      "org1/repo1",
+				PreviousOwner: "alice",
+				Sender:        "alice",
+			},
+			got.payload,
+		)
+	case <-time.After(5 * time.Second):
+		t.Fatal("lifecycle hook not received")
+	}
+}
+
+func TestDeliverRepoLifecycleHook(t *testing.T) {
+	t.Run("unexpected response status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		setRepoLifecycleConf(t, server.URL, "", "")
+
+		err := deliverRepoLifecycleHook(context.Background(), newRepoLifecyclePayload(RepoLifecycleDeleted, nil, "alice", &Repository{ID: 1, Name: "repo1"}))
+		assert.EqualError(t, err, "unexpected response status 500")
+	})
+
+	t.Run("command", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("Skipping testing on Windows")
+		}
+
+		dir := t.TempDir()
+		output := filepath.Join(dir, "payload.json")
+		command := filepath.Join(dir, "hook.sh")
+		err := os.WriteFile(command, []byte("#!/bin/sh\necho \"$GOGS_REPO_EVENT\" > "+output+".event\ncat > "+output+"\n"), 0755)
+		require.NoError(t, err)
+		setRepoLifecycleConf(t, "", "", command)
+
+		err = deliverRepoLifecycleHook(context.Background(), newRepoLifecyclePayload(RepoLifecycleCreated, &User{Name: "alice"}, "alice", &Repository{ID: 1, Name: "repo1", IsPrivate: true}))
+		require.NoError(t, err)
+
+		event, err := os.ReadFile(output + ".event")
+		require.NoError(t, err)
+		assert.Equal(t, "created\n", string(event))
+
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		got := new(RepoLifecyclePayload)
+		err = jsoniter.Unmarshal(data, got)
+		require.NoError(t, err)
+		assert.Equal(t,
+			&RepoLifecyclePayload{
+				Event:    RepoLifecycleCreated,
+				ID:       1,
+				Owner:    "alice",
+				Name:     "repo1",
+				FullName: "alice/repo1",
+				Private:  true,
+				Sender:   "alice",
+			},
+			got,
+		)
+	})
+}
@@ -0,0 +1,252 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/errutil"
+)
+
+// NotificationEvent is the type of events that email notification recipients
+// of repositories subscribe to.
+type NotificationEvent string
+
+const (
+	NotificationEventIssue              NotificationEvent = "issue"
+	NotificationEventIssueComment       NotificationEvent = "issue_comment"
+	NotificationEventPullRequest        NotificationEvent = "pull_request"
+	NotificationEventPullRequestComment NotificationEvent = "pull_request_comment"
+)
+
+// NotificationEvents is the list of all notification event types.
+var NotificationEvents = []NotificationEvent{
+	NotificationEventIssue,
+	NotificationEventIssueComment,
+	NotificationEventPullRequest,
+	NotificationEventPullRequestComment,
+}
+
+// IsValid returns true if the notification event type is known.
+func (e NotificationEvent) IsValid() bool {
+	for _, event := range NotificationEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// issueNotificationEvent returns the notification event type of a new issue or
+// a new comment on the issue.
+func issueNotificationEvent(issue *Issue, isComment bool) NotificationEvent {
+	switch {
+	case issue.IsPull && isComment:
+		return NotificationEventPullRequestComment
+	case issue.IsPull:
+		return NotificationEventPullRequest
+	case isComment:
+		return NotificationEventIssueComment
+	default:
+		return NotificationEventIssue
+	}
+}
+
+// RepoNotificationRecipientsStore is the persistent interface for extra email
+// addresses (e.g. mailing lists) that receive notifications of issues and pull
+// requests of repositories without having accounts.
+type RepoNotificationRecipientsStore interface {
+	// Set adds the email address as a recipient of the repository subscribing to
+	// given events, or updates the events when the address already exists. It
+	// returns ErrNotificationRecipientInvalid when the email address or any of
+	// the events is not valid.
+	Set(ctx context.Context, repoID int64, email string, events []NotificationEvent) (*RepoNotificationRecipient, error)
+	// List returns all recipients of the repository sorted by email address.
+	List(ctx context.Context, repoID int64) ([]*RepoNotificationRecipient, error)
+	// ListByEvent returns recipients of the repository subscribing to the event.
+	ListByEvent(ctx context.Context, repoID int64, event NotificationEvent) ([]*RepoNotificationRecipient, error)
+	// Delete deletes the recipient with given email address from the
+	// repository. It returns ErrNotificationRecipientNotExist when not found.
+	Delete(ctx context.Context, repoID int64, email string) error
+}
+
+var RepoNotificationRecipients RepoNotificationRecipientsStore
+
+var _ RepoNotificationRecipientsStore = (*repoNotificationRecipients)(nil)
+
+type repoNotificationRecipients struct {
+	*gorm.DB
+}
+
+// NewRepoNotificationRecipientsStore returns a persistent interface for email
+// notification recipients of repositories with given database connection.
+func NewRepoNotificationRecipientsStore(db *gorm.DB) RepoNotificationRecipientsStore {
+	return &repoNotificationRecipients{DB: db}
+}
+
+// RepoNotificationRecipient is an email address that receives notifications of
+// a repository.
+type RepoNotificationRecipient struct {
+	ID     int64  `gorm:"primaryKey"`
+	RepoID int64  `gorm:"uniqueIndex:repo_notification_recipient_repo_email_unique;not null"`
+	Email  string `gorm:"type:VARCHAR(255);uniqueIndex:repo_notification_recipient_repo_email_unique;not null"`
+	// Events is the comma-separated list of subscribed event types.
+	Events string `gorm:"type:VARCHAR(255);not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (r *RepoNotificationRecipient) BeforeCreate(tx *gorm.DB) error {
+	if r.CreatedUnix == 0 {
+		r.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (r *RepoNotificationRecipient) AfterFind(_ *gorm.DB) error {
+	r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	return nil
+}
+
+// EventList returns the list of subscribed event types.
+func (r *RepoNotificationRecipient) EventList() []NotificationEvent {
+	var events []NotificationEvent
+	for _, event := range strings.Split(r.Events, ",") {
+		if event != "" {
+			events = append(events, NotificationEvent(event))
+		}
+	}
+	return events
+}
+
+// HasEvent returns true if the recipient subscribes to the event.
+func (r *RepoNotificationRecipient) HasEvent(event NotificationEvent) bool {
+	for _, e := range r.EventList() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type ErrNotificationRecipientInvalid struct {
+	args errutil.Args
+}
+
+// IsErrNotificationRecipientInvalid returns true if the underlying error has
+// the type ErrNotificationRecipientInvalid.
+func IsErrNotificationRecipientInvalid(err error) bool {
+	_, ok := errors.Cause(err).(ErrNotificationRecipientInvalid)
+	return ok
+}
+
+func (err ErrNotificationRecipientInvalid) Error() string {
+	return fmt.Sprintf("notification recipient is invalid: %v", err.args)
+}
+
+func (db *repoNotificationRecipients) Set(ctx context.Context, repoID int64, email string, events []NotificationEvent) (*RepoNotificationRecipient, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return nil, ErrNotificationRecipientInvalid{args: errutil.Args{"email": email}}
+	}
+	if len(events) == 0 {
+		return nil, ErrNotificationRecipientInvalid{args: errutil.Args{"email": email, "events": events}}
+	}
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		if !event.IsValid() {
+			return nil, ErrNotificationRecipientInvalid{args: errutil.Args{"email": email, "event": event}}
+		}
+		names = append(names, string(event))
+	}
+
+	r := &RepoNotificationRecipient{
+		RepoID: repoID,
+		Email:  strings.ToLower(email),
+		Events: strings.Join(names, ","),
+	}
+	err = db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "repo_id"}, {Name: "email"}},
+			DoUpdates: clause.AssignmentColumns([]string{"events"}),
+		}).
+		Create(r).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "upsert")
+	}
+
+	// NOTE: The ID is not reliably returned by the upsert for existing records.
+	r = new(RepoNotificationRecipient)
+	return r, db.WithContext(ctx).Where("repo_id = ? AND email = ?", repoID, strings.ToLower(email)).First(r).Error
+}
+
+func (db *repoNotificationRecipients) List(ctx context.Context, repoID int64) ([]*RepoNotificationRecipient, error) {
+	var recipients []*RepoNotificationRecipient
+	return recipients, db.WithContext(ctx).
+		Where("repo_id = ?", repoID).
+		Order("email ASC").
+		Find(&recipients).
+		Error
+}
+
+func (db *repoNotificationRecipients) ListByEvent(ctx context.Context, repoID int64, event NotificationEvent) ([]*RepoNotificationRecipient, error) {
+	recipients, err := db.List(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := recipients[:0]
+	for _, r := range recipients {
+		if r.HasEvent(event) {
+			subscribed = append(subscribed, r)
+		}
+	}
+	return subscribed, nil
+}
+
+var _ errutil.NotFound = (*ErrNotificationRecipientNotExist)(nil)
+
+type ErrNotificationRecipientNotExist struct {
+	args errutil.Args
+}
+
+// IsErrNotificationRecipientNotExist returns true if the underlying error has
+// the type ErrNotificationRecipientNotExist.
+func IsErrNotificationRecipientNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrNotificationRecipientNotExist)
+	return ok
+}
+
+func (err ErrNotificationRecipientNotExist) Error() string {
+	return fmt.Sprintf("notification recipient does not exist: %v", err.args)
+}
+
+func (ErrNotificationRecipientNotExist) NotFound() bool {
+	return true
+}
+
+func (db *repoNotificationRecipients) Delete(ctx context.Context, repoID int64, email string) error {
+	result := db.WithContext(ctx).
+		Where("repo_id = ? AND email = ?", repoID, strings.ToLower(strings.TrimSpace(email))).
+		Delete(&RepoNotificationRecipient{})
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrNotificationRecipientNotExist{args: errutil.Args{"repoID": repoID, "email": email}}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestRepoNotificationRecipients(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	ctx := context.Background()
+	db := &repoNotificationRecipients{
+		DB: dbtest.NewDB(t, "repoNotificationRecipients", new(RepoNotificationRecipient)),
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := db.Set(ctx, 1, "not an email", []NotificationEvent{NotificationEventIssue})
+		assert.True(t, IsErrNotificationRecipientInvalid(err))
+		_, err = db.Set(ctx, 1, "Dev <dev@example.com>", []NotificationEvent{NotificationEventIssue})
+		assert.True(t, IsErrNotificationRecipientInvalid(err))
+		_, err = db.Set(ctx, 1, "dev@example.com", nil)
+		assert.True(t, IsErrNotificationRecipientInvalid(err))
+		_, err = db.Set(ctx, 1, "dev@example.com", []NotificationEvent{"push"})
+		assert.True(t, IsErrNotificationRecipientInvalid(err))
+	})
+
+	r, err := db.Set(ctx, 1, "Dev@Example.com", []NotificationEvent{NotificationEventIssue})
+	require.NoError(t, err)
+	assert.Equal(t, "dev@example.com", r.Email)
+	_, err = db.Set(ctx, 1, "qa@example.com", []NotificationEvent{NotificationEventPullRequest, NotificationEventPullRequestComment})
+	require.NoError(t, err)
+	_, err = db.Set(ctx, 2, "dev@example.com", []NotificationEvent{NotificationEventIssue})
+	require.NoError(t, err)
+
+	// Setting again updates events of the existing recipient
+	updated, err := db.Set(ctx, 1, "dev@example.com", []NotificationEvent{NotificationEventIssue, NotificationEventIssueComment})
+	require.NoError(t, err)
+	assert.Equal(t, r.ID, updated.ID)
+	assert.Equal(t, []NotificationEvent{NotificationEventIssue, NotificationEventIssueComment}, updated.EventList())
+
+	listEmails := func(t *testing.T, repoID int64, event NotificationEvent) []string {
+		recipients, err := db.ListByEvent(ctx, repoID, event)
+		require.NoError(t, err)
+		emails := make([]string, 0, len(recipients))
+		for _, r := range recipients {
+			emails = append(emails, r.Email)
+		}
+		return emails
+	}
+	assert.Equal(t, []string{"dev@example.com"}, listEmails(t, 1, NotificationEventIssueComment))
+	assert.Equal(t, []string{"qa@example.com"}, listEmails(t, 1, NotificationEventPullRequest))
+
+	recipients, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, recipients, 2)
+
+	err = db.Delete(ctx, 1, "DEV@example.com")
+	require.NoError(t, err)
+	assert.Empty(t, listEmails(t, 1, NotificationEventIssue))
+	assert.Equal(t, []string{"dev@example.com"}, listEmails(t, 2, NotificationEventIssue))
+
+	err = db.Delete(ctx, 1, "dev@example.com")
+	assert.True(t, IsErrNotificationRecipientNotExist(err))
+}
+
+func TestNotificationListRecipients(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	before := RepoNotificationRecipients
+	RepoNotificationRecipients = NewRepoNotificationRecipientsStore(dbtest.NewDB(t, "notificationListRecipients", new(RepoNotificationRecipient)))
+	t.Cleanup(func() {
+		RepoNotificationRecipients = before
+	})
+
+	_, err := RepoNotificationRecipients.Set(ctx, 1, "dev@lists.example.com", []NotificationEvent{NotificationEventIssue, NotificationEventPullRequestComment})
+	require.NoError(t, err)
+	_, err = RepoNotificationRecipients.Set(ctx, 1, "alice@example.com", []NotificationEvent{NotificationEventIssue})
+	require.NoError(t, err)
+
+	issue := &Issue{RepoID: 1, Title: "Crash on start"}
+	tests := []struct {
+		name      string
+		issue     *Issue
+		isComment bool
+		excludes  []string
+		want      []string
+	}{
+		{
+			name:  "new issue",
+			issue: issue,
+			want:  []string{"alice@example.com", "dev@lists.example.com"},
+		},
+		{
+			name:     "new issue excludes notified users",
+			issue:    issue,
+			excludes: []string{"Alice@example.com"},
+			want:     []string{"dev@lists.example.com"},
+		},
+		{
+			name:      "comment on issue",
+			issue:     issue,
+			isComment: true,
+			want:      []string{},
+		},
+		{
+			name:      "comment on pull request",
+			issue:     &Issue{RepoID: 1, IsPull: true},
+			isComment: true,
+			want:      []string{"dev@lists.example.com"},
+		},
+		{
+			name:  "another repository",
+			issue: &Issue{RepoID: 2},
+			want:  []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := notificationListRecipients(ctx, test.issue, test.isComment, test.excludes)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
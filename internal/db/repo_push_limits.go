@@ -0,0 +1,334 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+// PushLimits contains limits of objects introduced by pushes to a repository.
+type PushLimits struct {
+	// The maximum total size of objects introduced by a push in bytes, 0 means no
+	// limit.
+	MaxPushSize int64
+	// The maximum size of each file introduced by a push in bytes, 0 means no
+	// limit.
+	MaxBlobSize int64
+	// The file patterns that are not allowed to be pushed.
+	BlockedFilePatterns []string
+}
+
+// IsZero returns true if there is no limit.
+func (l *PushLimits) IsZero() bool {
+	return l.MaxPushSize <= 0 && l.MaxBlobSize <= 0 && len(l.BlockedFilePatterns) == 0
+}
+
+// ParseFilePatterns returns non-empty patterns of the comma separated list.
+func ParseFilePatterns(patterns string) []string {
+	var parsed []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern != "" {
+			parsed = append(parsed, pattern)
+		}
+	}
+	return parsed
+}
+
+type ErrFilePatternInvalid struct {
+	args errutil.Args
+}
+
+func IsErrFilePatternInvalid(err error) bool {
+	_, ok := err.(ErrFilePatternInvalid)
+	return ok
+}
+
+func (err ErrFilePatternInvalid) Error() string {
+	return fmt.Sprintf("file pattern is not valid: %v", err.args)
+}
+
+// ValidateFilePatterns returns ErrFilePatternInvalid if any of the comma
+// separated list of patterns is malformed.
+func ValidateFilePatterns(patterns string) error {
+	for _, pattern := range ParseFilePatterns(patterns) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return ErrFilePatternInvalid{args: errutil.Args{"pattern": pattern}}
+		}
+	}
+	return nil
+}
+
+// matchFilePattern returns true if the file of given path matches the pattern.
+// Patterns that contain a slash match the full path, others match the file
+// name.
+func matchFilePattern(pattern, filePath string) bool {
+	name := filePath
+	if !strings.Contains(pattern, "/") {
+		name = path.Base(filePath)
+	}
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
+
+// EffectivePushLimits returns limits of objects introduced by pushes to the
+// repository. Each limit falls back to the default of the organization that
+// owns the repository, and then to the server defaults.
+func (repo *Repository) EffectivePushLimits() (*PushLimits, error) {
+	if err := repo.GetOwner(); err != nil {
+		return nil, fmt.Errorf("get owner: %v", err)
+	}
+
+	maxPushSize := repo.PushMaxSize
+	maxBlobSize := repo.PushMaxBlobSize
+	blockedFilePatterns := ParseFilePatterns(repo.PushBlockedFilePatterns)
+	if repo.Owner.IsOrganization() {
+		if maxPushSize == 0 {
+			maxPushSize = repo.Owner.PushMaxSize
+		}
+		if maxBlobSize == 0 {
+			maxBlobSize = repo.Owner.PushMaxBlobSize
+		}
+		if len(blockedFilePatterns) == 0 {
+			blockedFilePatterns = ParseFilePatterns(repo.Owner.PushBlockedFilePatterns)
+		}
+	}
+	if maxPushSize == 0 {
+		maxPushSize = conf.Repository.PushLimits.MaxPushSize
+	}
+	if maxBlobSize == 0 {
+		maxBlobSize = conf.Repository.PushLimits.MaxBlobSize
+	}
+	if len(blockedFilePatterns) == 0 {
+		blockedFilePatterns = conf.Repository.PushLimits.BlockedFilePatterns
+	}
+
+	return &PushLimits{
+		MaxPushSize:         maxPushSize * 1024 * 1024,
+		MaxBlobSize:         maxBlobSize * 1024 * 1024,
+		BlockedFilePatterns: blockedFilePatterns,
+	}, nil
+}
+
+type ErrPushTooLarge struct {
+	args errutil.Args
+}
+
+func IsErrPushTooLarge(err error) bool {
+	_, ok := err.(ErrPushTooLarge)
+	return ok
+}
+
+func (err ErrPushTooLarge) Error() string {
+	return fmt.Sprintf("push exceeds the maximum size: %v", err.args)
+}
+
+// Size returns the total size of objects introduced by the push in bytes.
+func (err ErrPushTooLarge) Size() int64 {
+	return err.args["size"].(int64)
+}
+
+// Limit returns the maximum total size of objects in bytes.
+func (err ErrPushTooLarge) Limit() int64 {
+	return err.args["limit"].(int64)
+}
+
+// Path returns the path of the largest file introduced by the push.
+func (err ErrPushTooLarge) Path() string {
+	return err.args["path"].(string)
+}
+
+type ErrPushFileTooLarge struct {
+	args errutil.Args
+}
+
+func IsErrPushFileTooLarge(err error) bool {
+	_, ok := err.(ErrPushFileTooLarge)
+	return ok
+}
+
+func (err ErrPushFileTooLarge) Error() string {
+	return fmt.Sprintf("pushed file exceeds the maximum size: %v", err.args)
+}
+
+// ObjectID returns the ID of the blob that exceeds the maximum size.
+func (err ErrPushFileTooLarge) ObjectID() string {
+	return err.args["objectID"].(string)
+}
+
+// Path returns the path of the file that exceeds the maximum size.
+func (err ErrPushFileTooLarge) Path() string {
+	return err.args["path"].(string)
+}
+
+// Size returns the size of the file in bytes.
+func (err ErrPushFileTooLarge) Size() int64 {
+	return err.args["size"].(int64)
+}
+
+// Limit returns the maximum size of each file in bytes.
+func (err ErrPushFileTooLarge) Limit() int64 {
+	return err.args["limit"].(int64)
+}
+
+type ErrPushFileBlocked struct {
+	args errutil.Args
+}
+
+func IsErrPushFileBlocked(err error) bool {
+	_, ok := err.(ErrPushFileBlocked)
+	return ok
+}
+
+func (err ErrPushFileBlocked) Error() string {
+	return fmt.Sprintf("pushed file matches a blocked pattern: %v", err.args)
+}
+
+// ObjectID returns the ID of the blob whose path is blocked.
+func (err ErrPushFileBlocked) ObjectID() string {
+	return err.args["objectID"].(string)
+}
+
+// Path returns the path of the file that matches the blocked pattern.
+func (err ErrPushFileBlocked) Path() string {
+	return err.args["path"].(string)
+}
+
+// Pattern returns the blocked pattern that the file matches.
+func (err ErrPushFileBlocked) Pattern() string {
+	return err.args["pattern"].(string)
+}
+
+type pushedObject struct {
+	ID   string
+	Type string
+	Size int64
+	// The path of the object, empty for commits and tags.
+	Path string
+}
+
+// listPushedObjects returns objects that are reachable from any of the new
+// commits but not from any existing ref in the repository of given path. It
+// must be called before refs are updated, i.e. in the pre-receive hook.
+func listPushedObjects(repoPath string, newCommitIDs []string) ([]*pushedObject, error) {
+	args := append([]string{"rev-list", "--objects"}, newCommitIDs...)
+	args = append(args, "--not", "--all")
+
+	stderr := new(bytes.Buffer)
+	revs := new(bytes.Buffer)
+	err := git.NewCommand(args...).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdout: revs,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return nil, errors.Errorf("list objects: %v - %s", err, stderr)
+	}
+
+	stderr.Reset()
+	stdout := new(bytes.Buffer)
+	err = git.NewCommand("cat-file", "--batch-check=%(objectname) %(objecttype) %(objectsize) %(rest)").
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdin:  revs,
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return nil, errors.Errorf("check objects: %v - %s", err, stderr)
+	}
+
+	var objects []*pushedObject
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		// Expect "<object ID> <type> <size> <path>", the path is empty for
+		// commits and tags.
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) < 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse size of object %q", fields[0])
+		}
+
+		object := &pushedObject{
+			ID:   fields[0],
+			Type: fields[1],
+			Size: size,
+		}
+		if len(fields) == 4 {
+			object.Path = fields[3]
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// CheckPushObjects checks whether objects introduced by pushing the new commits
+// to the repository of given path are within push limits of the repository. It
+// returns ErrPushFileBlocked or ErrPushFileTooLarge for the first offending
+// file, or ErrPushTooLarge if the total size of objects exceeds the limit.
+func (repo *Repository) CheckPushObjects(repoPath string, newCommitIDs ...string) error {
+	commitIDs := make([]string, 0, len(newCommitIDs))
+	for _, id := range newCommitIDs {
+		if id != git.EmptyID {
+			commitIDs = append(commitIDs, id)
+		}
+	}
+	if len(commitIDs) == 0 {
+		return nil
+	}
+
+	limits, err := repo.EffectivePushLimits()
+	if err != nil {
+		return err
+	} else if limits.IsZero() {
+		return nil
+	}
+
+	objects, err := listPushedObjects(repoPath, commitIDs)
+	if err != nil {
+		return errors.Wrap(err, "list pushed objects")
+	}
+
+	var totalSize int64
+	var largest *pushedObject
+	for _, object := range objects {
+		totalSize += object.Size
+		if object.Type != "blob" {
+			continue
+		}
+		if largest == nil || object.Size > largest.Size {
+			largest = object
+		}
+
+		for _, pattern := range limits.BlockedFilePatterns {
+			if matchFilePattern(pattern, object.Path) {
+				return ErrPushFileBlocked{args: errutil.Args{"objectID": object.ID, "path": object.Path, "pattern": pattern}}
+			}
+		}
+		if limits.MaxBlobSize > 0 && object.Size > limits.MaxBlobSize {
+			return ErrPushFileTooLarge{args: errutil.Args{"objectID": object.ID, "path": object.Path, "size": object.Size, "limit": limits.MaxBlobSize}}
+		}
+	}
+
+	if limits.MaxPushSize > 0 && totalSize > limits.MaxPushSize {
+		var largestPath string
+		if largest != nil {
+			largestPath = largest.Path
+		}
+		return ErrPushTooLarge{args: errutil.Args{"size": totalSize, "limit": limits.MaxPushSize, "path": largestPath}}
+	}
+	return nil
+}
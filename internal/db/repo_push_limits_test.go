@@ -0,0 +1,199 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestValidateFilePatterns(t *testing.T) {
+	assert.NoError(t, ValidateFilePatterns(""))
+	assert.NoError(t, ValidateFilePatterns("*.iso, images/*.vmdk,"))
+	assert.True(t, IsErrFilePatternInvalid(ValidateFilePatterns("*.iso, [a-")))
+}
+
+func TestMatchFilePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		wantOK  bool
+	}{
+		{pattern: "*.iso", path: "ubuntu.iso", wantOK: true},
+		{pattern: "*.iso", path: "images/ubuntu.iso", wantOK: true},
+		{pattern: "*.iso", path: "ubuntu.iso.txt", wantOK: false},
+		{pattern: "images/*.vmdk", path: "images/disk.vmdk", wantOK: true},
+		{pattern: "images/*.vmdk", path: "backup/images/disk.vmdk", wantOK: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.path, func(t *testing.T) {
+			assert.Equal(t, test.wantOK, matchFilePattern(test.pattern, test.path))
+		})
+	}
+}
+
+func TestRepository_EffectivePushLimits(t *testing.T) {
+	opts := conf.Repository
+	opts.PushLimits.MaxPushSize = 100
+	opts.PushLimits.MaxBlobSize = 10
+	opts.PushLimits.BlockedFilePatterns = []string{"*.iso"}
+	conf.SetMockRepository(t, opts)
+
+	org := &User{ID: 1, Name: "acme", Type: UserTypeOrganization, PushMaxBlobSize: 5, PushBlockedFilePatterns: "*.vmdk"}
+	tests := []struct {
+		name       string
+		repo       *Repository
+		wantLimits *PushLimits
+	}{
+		{
+			name: "server defaults",
+			repo: &Repository{Owner: &User{ID: 2, Name: "alice"}},
+			wantLimits: &PushLimits{
+				MaxPushSize:         100 << 20,
+				MaxBlobSize:         10 << 20,
+				BlockedFilePatterns: []string{"*.iso"},
+			},
+		},
+		{
+			name: "organization defaults",
+			repo: &Repository{Owner: org},
+			wantLimits: &PushLimits{
+				MaxPushSize:         100 << 20,
+				MaxBlobSize:         5 << 20,
+				BlockedFilePatterns: []string{"*.vmdk"},
+			},
+		},
+		{
+			name: "repository overrides",
+			repo: &Repository{Owner: org, PushMaxSize: 50, PushMaxBlobSize: 1, PushBlockedFilePatterns: "*.zip, *.tar"},
+			wantLimits: &PushLimits{
+				MaxPushSize:         50 << 20,
+				MaxBlobSize:         1 << 20,
+				BlockedFilePatterns: []string{"*.zip", "*.tar"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limits, err := test.repo.EffectivePushLimits()
+			require.NoError(t, err)
+			assert.Equal(t, test.wantLimits, limits)
+		})
+	}
+}
+
+func TestRepository_CheckPushObjects(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	writeFile := func(name string, size int) {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(repoPath, name)), 0755)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(repoPath, name), []byte(strings.Repeat("a", size)), 0644)
+		require.NoError(t, err)
+	}
+	// commit creates a commit on top of the base with given files, and leaves it
+	// unreachable from any ref as if it is being pushed.
+	commit := func(base string, files map[string]int) string {
+		run("checkout", "-b", "pushed", base)
+		for name, size := range files {
+			writeFile(name, size)
+		}
+		run("add", "-A")
+		run("commit", "-m", "Add files")
+		id := run("rev-parse", "HEAD")
+		run("checkout", "main")
+		run("branch", "-D", "pushed")
+		return id
+	}
+
+	run("init", "-b", "main")
+	writeFile("README.md", 10)
+	run("add", "-A")
+	run("commit", "-m", "Initial commit")
+	base := run("rev-parse", "HEAD")
+
+	normal := commit(base, map[string]int{"docs/guide.md": 100})
+	oversize := commit(base, map[string]int{"docs/guide.md": 100, "images/disk.img": 2 << 20})
+	oversizeBlobID := run("rev-parse", oversize+":images/disk.img")
+	blocked := commit(base, map[string]int{"images/ubuntu.iso": 200})
+	blockedBlobID := run("rev-parse", blocked+":images/ubuntu.iso")
+	large := commit(base, map[string]int{"data/a.bin": 600 << 10, "data/b.bin": 700 << 10})
+
+	owner := &User{ID: 1, Name: "alice"}
+	limited := &Repository{Owner: owner, PushMaxSize: 1, PushMaxBlobSize: 1, PushBlockedFilePatterns: "*.iso"}
+	tests := []struct {
+		name         string
+		repo         *Repository
+		newCommitIDs []string
+		wantErr      error
+	}{
+		{
+			name:         "normal push",
+			repo:         limited,
+			newCommitIDs: []string{normal},
+		},
+		{
+			name:         "oversize file",
+			repo:         limited,
+			newCommitIDs: []string{oversize},
+			wantErr:      ErrPushFileTooLarge{args: errutil.Args{"objectID": oversizeBlobID, "path": "images/disk.img", "size": int64(2 << 20), "limit": int64(1 << 20)}},
+		},
+		{
+			name:         "blocked file",
+			repo:         limited,
+			newCommitIDs: []string{normal, blocked},
+			wantErr:      ErrPushFileBlocked{args: errutil.Args{"objectID": blockedBlobID, "path": "images/ubuntu.iso", "pattern": "*.iso"}},
+		},
+		{
+			name:         "delete branch",
+			repo:         limited,
+			newCommitIDs: []string{git.EmptyID},
+		},
+		{
+			name:         "no limits",
+			repo:         &Repository{Owner: owner},
+			newCommitIDs: []string{oversize, blocked},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.repo.CheckPushObjects(repoPath, test.newCommitIDs...)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+
+	t.Run("push too large", func(t *testing.T) {
+		// Both files are within the limit of each file but not in total
+		err := limited.CheckPushObjects(repoPath, large)
+		require.True(t, IsErrPushTooLarge(err), "want ErrPushTooLarge but got %v", err)
+
+		errTooLarge := err.(ErrPushTooLarge)
+		assert.Greater(t, errTooLarge.Size(), int64(1300<<10))
+		assert.Equal(t, int64(1<<20), errTooLarge.Limit())
+		assert.Equal(t, "data/b.bin", errTooLarge.Path())
+	})
+}
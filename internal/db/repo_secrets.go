@@ -0,0 +1,213 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/cryptoutil"
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+// RepoSecretsStore is the persistent interface for secrets of repositories and
+// organizations to be used by CI integrations. Secrets of an organization are
+// inherited by all of its repositories.
+//
+// Each secret belongs to either an organization or a repository, which is
+// identified by a non-zero "orgID" or "repoID" respectively.
+type RepoSecretsStore interface {
+	// Set creates or updates the secret with given name. The "key" is used to
+	// encrypt and later decrypt given "value", which should be configured in
+	// site-level and change of the "key" will break all existing secrets. It
+	// returns ErrRepoSecretNameInvalid when the name is not valid.
+	Set(ctx context.Context, orgID, repoID int64, key, name, value string) error
+	// List returns all secrets of the organization or the repository sorted by
+	// name, values of secrets are never loaded.
+	List(ctx context.Context, orgID, repoID int64) ([]*RepoSecret, error)
+	// Delete deletes the secret with given name. It returns
+	// ErrRepoSecretNotExist when not found.
+	Delete(ctx context.Context, orgID, repoID int64, name string) error
+	// ListValues returns decrypted values of all secrets available to the
+	// repository indexed by name, including secrets inherited from the
+	// organization with given ID. Secrets of the repository take precedence over
+	// ones of the organization with the same name.
+	//
+	// 🚨 SECURITY: Values must only be returned to an authorized CI context.
+	ListValues(ctx context.Context, key string, orgID, repoID int64) (map[string]string, error)
+}
+
+var RepoSecrets RepoSecretsStore
+
+var _ RepoSecretsStore = (*repoSecrets)(nil)
+
+type repoSecrets struct {
+	*gorm.DB
+}
+
+// NewRepoSecretsStore returns a persistent interface for secrets of
+// repositories and organizations with given database connection.
+func NewRepoSecretsStore(db *gorm.DB) RepoSecretsStore {
+	return &repoSecrets{DB: db}
+}
+
+// RepoSecret is an encrypted secret of an organization or a repository.
+type RepoSecret struct {
+	ID     int64  `gorm:"primaryKey"`
+	OrgID  int64  `gorm:"uniqueIndex:repo_secret_scope_name_unique;not null"`
+	RepoID int64  `gorm:"uniqueIndex:repo_secret_scope_name_unique;not null"`
+	Name   string `gorm:"uniqueIndex:repo_secret_scope_name_unique;not null"`
+	// EncryptedValue is the base64-encoded value encrypted by AES in GCM mode.
+	EncryptedValue string `gorm:"type:TEXT;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+	Updated     time.Time `gorm:"-" json:"-"`
+	UpdatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (s *RepoSecret) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedUnix == 0 {
+		s.CreatedUnix = tx.NowFunc().Unix()
+		s.UpdatedUnix = s.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (s *RepoSecret) AfterFind(_ *gorm.DB) error {
+	s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	s.Updated = time.Unix(s.UpdatedUnix, 0).Local()
+	return nil
+}
+
+var secretNamePattern = lazyregexp.New(`^[A-Z_][A-Z0-9_]*$`)
+
+// MaxSecretNameLength is the maximum length of a secret name.
+const MaxSecretNameLength = 100
+
+type ErrRepoSecretNameInvalid struct {
+	args errutil.Args
+}
+
+// IsErrRepoSecretNameInvalid returns true if the underlying error has the type
+// ErrRepoSecretNameInvalid.
+func IsErrRepoSecretNameInvalid(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoSecretNameInvalid)
+	return ok
+}
+
+func (err ErrRepoSecretNameInvalid) Error() string {
+	return fmt.Sprintf("secret name must only contain uppercase letters, digits and underscores, and must not start with a digit: %v", err.args)
+}
+
+// ValidateSecretName returns ErrRepoSecretNameInvalid if the name is not a
+// valid secret name.
+func ValidateSecretName(name string) error {
+	if len(name) > MaxSecretNameLength || !secretNamePattern.MatchString(name) {
+		return ErrRepoSecretNameInvalid{args: errutil.Args{"name": name}}
+	}
+	return nil
+}
+
+func (db *repoSecrets) Set(ctx context.Context, orgID, repoID int64, key, name, value string) error {
+	if err := ValidateSecretName(name); err != nil {
+		return err
+	}
+
+	encrypted, err := cryptoutil.AESGCMEncrypt(cryptoutil.MD5Bytes(key), []byte(value))
+	if err != nil {
+		return errors.Wrap(err, "encrypt value")
+	}
+
+	s := &RepoSecret{
+		OrgID:          orgID,
+		RepoID:         repoID,
+		Name:           name,
+		EncryptedValue: base64.StdEncoding.EncodeToString(encrypted),
+	}
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "org_id"}, {Name: "repo_id"}, {Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]any{"encrypted_value": s.EncryptedValue, "updated_unix": db.NowFunc().Unix()}),
+		}).
+		Create(s).Error
+}
+
+func (db *repoSecrets) List(ctx context.Context, orgID, repoID int64) ([]*RepoSecret, error) {
+	var secrets []*RepoSecret
+	return secrets, db.WithContext(ctx).
+		Select("id", "org_id", "repo_id", "name", "created_unix", "updated_unix").
+		Where("org_id = ? AND repo_id = ?", orgID, repoID).
+		Order("name ASC").
+		Find(&secrets).Error
+}
+
+var _ errutil.NotFound = (*ErrRepoSecretNotExist)(nil)
+
+type ErrRepoSecretNotExist struct {
+	args errutil.Args
+}
+
+// IsErrRepoSecretNotExist returns true if the underlying error has the type
+// ErrRepoSecretNotExist.
+func IsErrRepoSecretNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrRepoSecretNotExist)
+	return ok
+}
+
+func (err ErrRepoSecretNotExist) Error() string {
+	return fmt.Sprintf("secret does not exist: %v", err.args)
+}
+
+func (ErrRepoSecretNotExist) NotFound() bool {
+	return true
+}
+
+func (db *repoSecrets) Delete(ctx context.Context, orgID, repoID int64, name string) error {
+	result := db.WithContext(ctx).
+		Where("org_id = ? AND repo_id = ? AND name = ?", orgID, repoID, name).
+		Delete(new(RepoSecret))
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrRepoSecretNotExist{args: errutil.Args{"orgID": orgID, "repoID": repoID, "name": name}}
+	}
+	return nil
+}
+
+func (db *repoSecrets) ListValues(ctx context.Context, key string, orgID, repoID int64) (map[string]string, error) {
+	var secrets []*RepoSecret
+	err := db.WithContext(ctx).
+		Where("(org_id = ? AND repo_id = 0) OR (org_id = 0 AND repo_id = ?)", orgID, repoID).
+		// Load secrets of the organization first to be overridden.
+		Order("repo_id ASC").
+		Find(&secrets).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list")
+	}
+
+	values := make(map[string]string, len(secrets))
+	for _, s := range secrets {
+		encrypted, err := base64.StdEncoding.DecodeString(s.EncryptedValue)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decode secret %q", s.Name)
+		}
+		decrypted, err := cryptoutil.AESGCMDecrypt(cryptoutil.MD5Bytes(key), encrypted)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decrypt secret %q", s.Name)
+		}
+		values[s.Name] = string(decrypted)
+	}
+	return values, nil
+}
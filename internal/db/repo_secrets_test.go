@@ -0,0 +1,166 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestValidateSecretName(t *testing.T) {
+	for _, name := range []string{"TOKEN", "NPM_TOKEN", "_TOKEN", "TOKEN_2"} {
+		assert.NoError(t, ValidateSecretName(name), name)
+	}
+	for _, name := range []string{"", "token", "NPM-TOKEN", "2TOKEN", "NPM TOKEN", string(make([]byte, MaxSecretNameLength+1))} {
+		assert.True(t, IsErrRepoSecretNameInvalid(ValidateSecretName(name)), name)
+	}
+}
+
+func TestRepoSecrets(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(RepoSecret)}
+	db := &repoSecrets{
+		DB: dbtest.NewDB(t, "repoSecrets", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *repoSecrets)
+	}{
+		{"Set", repoSecretsSet},
+		{"List", repoSecretsList},
+		{"Delete", repoSecretsDelete},
+		{"ListValues", repoSecretsListValues},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+const testSecretKey = "secret-key"
+
+func repoSecretsSet(t *testing.T, db *repoSecrets) {
+	ctx := context.Background()
+
+	err := db.Set(ctx, 0, 1, testSecretKey, "deploy-token", "s3cr3t")
+	wantErr := ErrRepoSecretNameInvalid{args: errutil.Args{"name": "deploy-token"}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.Set(ctx, 0, 1, testSecretKey, "DEPLOY_TOKEN", "s3cr3t")
+	require.NoError(t, err)
+
+	// Values are encrypted at rest
+	secret := new(RepoSecret)
+	err = db.Where("repo_id = ? AND name = ?", 1, "DEPLOY_TOKEN").First(secret).Error
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret.EncryptedValue)
+	assert.NotContains(t, secret.EncryptedValue, "s3cr3t")
+
+	// Setting again updates the value
+	err = db.Set(ctx, 0, 1, testSecretKey, "DEPLOY_TOKEN", "n3w")
+	require.NoError(t, err)
+
+	values, err := db.ListValues(ctx, testSecretKey, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DEPLOY_TOKEN": "n3w"}, values)
+}
+
+func repoSecretsList(t *testing.T, db *repoSecrets) {
+	ctx := context.Background()
+
+	err := db.Set(ctx, 0, 1, testSecretKey, "NPM_TOKEN", "npm")
+	require.NoError(t, err)
+	err = db.Set(ctx, 0, 1, testSecretKey, "DEPLOY_TOKEN", "deploy")
+	require.NoError(t, err)
+	err = db.Set(ctx, 0, 2, testSecretKey, "OTHER_TOKEN", "other")
+	require.NoError(t, err)
+	err = db.Set(ctx, 1, 0, testSecretKey, "ORG_TOKEN", "org")
+	require.NoError(t, err)
+
+	secrets, err := db.List(ctx, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, "DEPLOY_TOKEN", secrets[0].Name)
+	assert.Equal(t, "NPM_TOKEN", secrets[1].Name)
+
+	// Values should never be returned on list
+	for _, s := range secrets {
+		assert.Empty(t, s.EncryptedValue)
+		assert.NotZero(t, s.CreatedUnix)
+	}
+
+	secrets, err = db.List(ctx, 1, 0)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "ORG_TOKEN", secrets[0].Name)
+}
+
+func repoSecretsDelete(t *testing.T, db *repoSecrets) {
+	ctx := context.Background()
+
+	err := db.Set(ctx, 0, 1, testSecretKey, "DEPLOY_TOKEN", "deploy")
+	require.NoError(t, err)
+
+	// Secrets of another repository should not be deleted
+	err = db.Delete(ctx, 0, 2, "DEPLOY_TOKEN")
+	wantErr := ErrRepoSecretNotExist{args: errutil.Args{"orgID": int64(0), "repoID": int64(2), "name": "DEPLOY_TOKEN"}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.Delete(ctx, 0, 1, "DEPLOY_TOKEN")
+	require.NoError(t, err)
+
+	secrets, err := db.List(ctx, 0, 1)
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+}
+
+func repoSecretsListValues(t *testing.T, db *repoSecrets) {
+	ctx := context.Background()
+
+	err := db.Set(ctx, 1, 0, testSecretKey, "NPM_TOKEN", "org-npm")
+	require.NoError(t, err)
+	err = db.Set(ctx, 1, 0, testSecretKey, "DEPLOY_TOKEN", "org-deploy")
+	require.NoError(t, err)
+	err = db.Set(ctx, 0, 1, testSecretKey, "DEPLOY_TOKEN", "repo-deploy")
+	require.NoError(t, err)
+	err = db.Set(ctx, 2, 0, testSecretKey, "OTHER_TOKEN", "other")
+	require.NoError(t, err)
+
+	// Values round-trip and secrets of the repository take precedence
+	values, err := db.ListValues(ctx, testSecretKey, 1, 1)
+	require.NoError(t, err)
+	want := map[string]string{
+		"NPM_TOKEN":    "org-npm",
+		"DEPLOY_TOKEN": "repo-deploy",
+	}
+	assert.Equal(t, want, values)
+
+	// Secrets of organizations are not inherited by repositories of users
+	values, err = db.ListValues(ctx, testSecretKey, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DEPLOY_TOKEN": "repo-deploy"}, values)
+
+	// A different key should not be able to decrypt values
+	_, err = db.ListValues(ctx, "another-key", 1, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"github.com/gogs/git-module"
+	"github.com/unknwon/com"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/sync"
+)
+
+// repoStorageStats returns the size in bytes and the number of objects of a
+// repository from its disk usage report, counting both loose and packed
+// objects.
+func repoStorageStats(countObject *git.CountObject) (size, objectCount int64) {
+	return countObject.Size + countObject.SizePack, countObject.Count + countObject.InPack
+}
+
+// defaultBranchCommitID returns the commit ID of the default branch, or an
+// empty string if it does not exist, e.g. the repository is empty.
+func (repo *Repository) defaultBranchCommitID() string {
+	gitRepo, err := git.Open(repo.RepoPath())
+	if err != nil {
+		return ""
+	}
+	commitID, err := gitRepo.BranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		return ""
+	}
+	return commitID
+}
+
+// RepoSizeQueue is the queue of repository IDs whose storage statistics need to
+// be recomputed.
+var RepoSizeQueue = sync.NewUniqueQueue(1000)
+
+// AddRepoSizeTask adds the repository to the queue to recompute its storage
+// statistics.
+func AddRepoSizeTask(repoID int64) {
+	go RepoSizeQueue.Add(repoID)
+}
+
+// updateRepoSize recomputes storage statistics of the repository, unless they
+// have been computed at the same commit of the default branch. Changes to other
+// branches are picked up by the periodic refresh of UpdateRepoSizes.
+func updateRepoSize(repoID int64) error {
+	repo, err := GetRepositoryByID(repoID)
+	if err != nil {
+		return err
+	}
+
+	commitID := repo.defaultBranchCommitID()
+	if commitID != "" && commitID == repo.SizeCommitID {
+		return nil
+	}
+	return repo.UpdateSize()
+}
+
+// SyncRepoSizes listens on the queue and recomputes storage statistics of
+// repositories.
+func SyncRepoSizes() {
+	for repoID := range RepoSizeQueue.Queue() {
+		log.Trace("SyncRepoSizes[%v]: processing task", repoID)
+		RepoSizeQueue.Remove(repoID)
+
+		if err := updateRepoSize(com.StrTo(repoID).MustInt64()); err != nil {
+			log.Error("Failed to update size of repository %s: %v", repoID, err)
+		}
+	}
+}
+
+func InitSyncRepoSizes() {
+	go SyncRepoSizes()
+}
+
+// UpdateRepoSizes recomputes storage statistics of all repositories regardless
+// of the cached commit.
+func UpdateRepoSizes() {
+	if taskStatusTable.IsRunning(_UPDATE_REPO_SIZES) {
+		return
+	}
+	taskStatusTable.Start(_UPDATE_REPO_SIZES)
+	defer taskStatusTable.Stop(_UPDATE_REPO_SIZES)
+
+	log.Trace("Doing: UpdateRepoSizes")
+
+	// NOTE: Collect repositories first because sizes are saved with writes that
+	// can't be done while iterating on some databases.
+	var repos []*Repository
+	if err := x.Where("id > 0").Find(&repos); err != nil {
+		log.Error("Failed to list repositories: %v", err)
+		return
+	}
+	for _, repo := range repos {
+		if err := repo.UpdateSize(); err != nil {
+			log.Error("Failed to update size of repository [%d]: %v", repo.ID, err)
+		}
+	}
+}
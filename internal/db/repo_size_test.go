@@ -0,0 +1,105 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/lfsutil"
+)
+
+func Test_repoStorageStats(t *testing.T) {
+	size, objectCount := repoStorageStats(&git.CountObject{
+		Count:    3,
+		Size:     12 * 1024,
+		InPack:   40,
+		Packs:    1,
+		SizePack: 100 * 1024,
+	})
+	assert.Equal(t, int64(112*1024), size)
+	assert.Equal(t, int64(43), objectCount)
+}
+
+func TestUpdateRepoSize(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	setTestEngine(t, new(User), new(Repository))
+	lfsStore := &lfs{DB: dbtest.NewDB(t, "updateRepoSize", new(LFSObject))}
+	SetMockLFSStore(t, lfsStore)
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	alice := &User{Name: "alice", LowerName: "alice"}
+	_, err := x.Insert(alice)
+	require.NoError(t, err)
+	repo := &Repository{Name: "example", LowerName: "example", OwnerID: alice.ID, DefaultBranch: "main"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+
+	repoPath := repo.RepoPath()
+	workPath := t.TempDir()
+	run := func(args ...string) {
+		_, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+	}
+	err = git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	commit := func(content string) {
+		err := os.WriteFile(filepath.Join(workPath, "README.md"), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", "README.md")
+		run("commit", "-m", "Update README")
+		run("push", "origin", "main")
+	}
+	commit("Hello")
+
+	err = lfsStore.CreateObject(context.Background(), repo.ID, lfsutil.OID("ef797c8118f02dfb649607dd5d3f8c7623048c9c063d532cc95c5ed7a898a64f"), 1024, lfsutil.StorageLocal)
+	require.NoError(t, err)
+
+	err = updateRepoSize(repo.ID)
+	require.NoError(t, err)
+	got, err := GetRepositoryByID(repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), got.ObjectCount, "one commit, tree and blob")
+	assert.Greater(t, got.Size, int64(0))
+	assert.Equal(t, int64(1024), got.LFSSize)
+	assert.Equal(t, got.defaultBranchCommitID(), got.SizeCommitID)
+	assert.NotZero(t, got.SizeUpdatedUnix)
+
+	// Statistics are reused when the default branch has not changed
+	_, err = x.Id(repo.ID).Cols("object_count").Update(&Repository{ObjectCount: 1})
+	require.NoError(t, err)
+	err = updateRepoSize(repo.ID)
+	require.NoError(t, err)
+	got, err = GetRepositoryByID(repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.ObjectCount)
+
+	commit("Hello world")
+	err = updateRepoSize(repo.ID)
+	require.NoError(t, err)
+	got, err = GetRepositoryByID(repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), got.ObjectCount)
+}
@@ -1,10 +1,16 @@
 package db
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/gogs/git-module"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
 	"gogs.io/gogs/internal/markup"
 )
 
@@ -52,3 +58,317 @@ func TestRepository_ComposeMetas(t *testing.T) {
 		assert.Equal(t, "https://someurl.com/{user}/{repo}/{issue}", metas["format"])
 	})
 }
+
+func TestRepository_ExternalIssueURL(t *testing.T) {
+	repo := &Repository{
+		Name:               "testrepo",
+		Owner:              &User{Name: "testuser"},
+		ExternalTrackerURL: "https://jira.example.com",
+	}
+	assert.Equal(t, "https://jira.example.com", repo.ExternalIssueURL("123"))
+
+	repo.ExternalTrackerFormat = "https://jira.example.com/browse/PROJ-{index}?from={user}/{repo}"
+	assert.Equal(t, "https://jira.example.com/browse/PROJ-123?from=testuser/testrepo", repo.ExternalIssueURL("123"))
+}
+
+func TestRepository_renderIssueReferences(t *testing.T) {
+	repo := &Repository{
+		Name:                  "testrepo",
+		Owner:                 &User{Name: "testuser"},
+		ExternalTrackerURL:    "https://jira.example.com",
+		ExternalTrackerFormat: "https://jira.example.com/browse/PROJ-{index}",
+	}
+	render := func() string {
+		repo.ExternalMetas = nil
+		return string(markup.RenderIssueIndexPattern([]byte("Fix #123"), repo.Link(), repo.ComposeMetas()))
+	}
+
+	assert.Equal(t, `Fix <a href="`+repo.Link()+`/issues/123">#123</a>`, render())
+
+	repo.EnableExternalTracker = true
+	assert.Equal(t, `Fix <a href="https://jira.example.com/browse/PROJ-123">#123</a>`, render())
+}
+
+func TestRepoInitTemplates(t *testing.T) {
+	templatesPath := t.TempDir()
+	err := os.MkdirAll(filepath.Join(templatesPath, "license"), os.ModePerm)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(templatesPath, "license", "Custom License"), []byte("All rights reserved by Example Inc.\n"), 0644)
+	require.NoError(t, err)
+
+	before := conf.Repository
+	conf.SetMockRepository(t,
+		conf.RepositoryOpts{
+			InitTemplatesPath: templatesPath,
+			DefaultBranch:     before.DefaultBranch,
+		},
+	)
+
+	gitignores, licenses, readmes, labelTemplates := Gitignores, Licenses, Readmes, LabelTemplates
+	t.Cleanup(func() {
+		Gitignores, Licenses, Readmes, LabelTemplates = gitignores, licenses, readmes, labelTemplates
+	})
+	LoadRepoConfig()
+	assert.Contains(t, Licenses, "Custom License")
+	assert.Contains(t, Licenses, "MIT License")
+
+	t.Run("validate", func(t *testing.T) {
+		err := ValidateRepoInitTemplates("Go,Node", "Custom License", "Default")
+		assert.NoError(t, err)
+
+		err = ValidateRepoInitTemplates("Go,404", "", "")
+		wantErr := ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "gitignore", "name": "404"}}
+		assert.Equal(t, wantErr, err)
+
+		err = ValidateRepoInitTemplates("", "404", "")
+		wantErr = ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "license", "name": "404"}}
+		assert.Equal(t, wantErr, err)
+
+		err = ValidateRepoInitTemplates("", "", "404")
+		wantErr = ErrRepoInitTemplateNotExist{args: errutil.Args{"type": "readme", "name": "404"}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("initial commit with custom license", func(t *testing.T) {
+		t.Setenv("GIT_COMMITTER_NAME", "alice")
+		t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+		repoPath := filepath.Join(t.TempDir(), "example.git")
+		err := git.Init(repoPath, git.InitOptions{Bare: true})
+		require.NoError(t, err)
+
+		repo := &Repository{Name: "example", Owner: &User{Name: "alice"}}
+		tmpDir := filepath.Join(t.TempDir(), "example")
+		err = prepareRepoCommit(repo, tmpDir, repoPath,
+			CreateRepoOptionsLegacy{
+				Name:    "example",
+				License: "Custom License",
+				Readme:  "Default",
+			},
+		)
+		require.NoError(t, err)
+		err = initRepoCommit(tmpDir, &git.Signature{Name: "alice", Email: "alice@example.com"})
+		require.NoError(t, err)
+
+		gitRepo, err := git.Open(repoPath)
+		require.NoError(t, err)
+		blob, err := gitRepo.CatFileBlob("HEAD:LICENSE")
+		require.NoError(t, err)
+		got, err := blob.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, "All rights reserved by Example Inc.\n", string(got))
+
+		_, err = gitRepo.CatFileBlob("HEAD:README.md")
+		assert.NoError(t, err)
+	})
+}
+
+func TestRepoVisibilityRestriction(t *testing.T) {
+	user := &User{ID: 1, Name: "alice", MaxRepoCreation: -1}
+	org := &User{ID: 2, Name: "acme", Type: UserTypeOrganization, MaxRepoCreation: -1, ForcePrivateRepos: true}
+
+	t.Run("public repository allowed", func(t *testing.T) {
+		assert.True(t, user.IsPublicRepoAllowed())
+		assert.False(t, org.IsPublicRepoAllowed())
+
+		// The restriction only takes effect for organizations
+		assert.True(t, (&User{ForcePrivateRepos: true}).IsPublicRepoAllowed())
+	})
+
+	t.Run("public repository forced private by server", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: -1, ForcePrivate: true})
+		assert.False(t, user.IsPublicRepoAllowed())
+
+		_, err := CreateRepository(user, user, CreateRepoOptionsLegacy{Name: "example"})
+		assert.True(t, IsErrRepoVisibilityNotAllowed(err))
+	})
+
+	t.Run("create public repository", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: -1})
+
+		_, err := CreateRepository(user, org, CreateRepoOptionsLegacy{Name: "example"})
+		wantErr := ErrRepoVisibilityNotAllowed{args: errutil.Args{"ownerID": org.ID}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("make repository public", func(t *testing.T) {
+		setTestEngine(t, new(Repository))
+		repo := &Repository{ID: 1, OwnerID: org.ID, Owner: org, Name: "example", IsPrivate: true}
+		_, err := x.Insert(repo)
+		require.NoError(t, err)
+
+		repo.IsPrivate = false
+		err = UpdateRepository(repo, true)
+		wantErr := ErrRepoVisibilityNotAllowed{args: errutil.Args{"ownerID": org.ID}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("change other visibility of public repository", func(t *testing.T) {
+		setTestEngine(t, new(Repository), new(Collaboration), new(Access), new(Team), new(TeamRepo), new(Action))
+		repo := &Repository{ID: 1, OwnerID: org.ID, Owner: org, Name: "example"}
+		_, err := x.Insert(repo)
+		require.NoError(t, err)
+
+		repo.IsUnlisted = true
+		err = UpdateRepository(repo, true)
+		assert.NoError(t, err)
+	})
+}
+
+func TestRepoCreationLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(Repository))
+
+	alice := &User{ID: 1, Name: "alice", MaxRepoCreation: 1, NumRepos: 1}
+	admin := &User{ID: 2, Name: "admin", MaxRepoCreation: -1, IsAdmin: true}
+	org := &User{ID: 3, Name: "acme", Type: UserTypeOrganization, MaxRepoCreation: -1, NumRepos: 2}
+
+	t.Run("user at the limit", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: -1})
+
+		_, err := CreateRepository(alice, alice, CreateRepoOptionsLegacy{Name: "example"})
+		assert.Equal(t, ErrReachLimitOfRepo{Limit: 1}, err)
+
+		_, err = ForkRepository(alice, alice, &Repository{ID: 1}, "example", "")
+		assert.Equal(t, ErrReachLimitOfRepo{Limit: 1}, err)
+	})
+
+	t.Run("organization at the limit", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: 2})
+
+		_, err := CreateRepository(alice, org, CreateRepoOptionsLegacy{Name: "example"})
+		assert.Equal(t, ErrReachLimitOfRepo{Limit: 2}, err)
+	})
+
+	t.Run("admin is exempt", func(t *testing.T) {
+		// Use the visibility restriction to stop right after the limit check
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: 2, ForcePrivate: true})
+
+		ok, err := org.canCreateRepo(x, admin, false)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = CreateRepository(admin, org, CreateRepoOptionsLegacy{Name: "example"})
+		assert.True(t, IsErrRepoVisibilityNotAllowed(err))
+	})
+
+	t.Run("forks excluded from the limit", func(t *testing.T) {
+		conf.SetMockRepository(t, conf.RepositoryOpts{MaxCreationLimit: -1, ExcludeForksFromCreationLimit: true})
+
+		_, err := x.Insert(&Repository{OwnerID: alice.ID, LowerName: "fork", Name: "fork", IsFork: true})
+		require.NoError(t, err)
+
+		// The only repository of alice is a fork
+		ok, err := alice.canCreateRepo(x, alice, false)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		_, err = x.Insert(&Repository{OwnerID: alice.ID, LowerName: "source", Name: "source"})
+		require.NoError(t, err)
+		ok, err = alice.canCreateRepo(x, alice, false)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		// Forks are not limited
+		ok, err = alice.canCreateRepo(x, alice, true)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+}
+
+func TestRepository_IssuesAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		repo       *Repository
+		mode       AccessMode
+		wantRead   bool
+		wantCreate bool
+	}{
+		{
+			name:       "public code and issues for guest",
+			repo:       &Repository{EnableIssues: true},
+			mode:       AccessModeRead,
+			wantRead:   true,
+			wantCreate: true,
+		},
+		{
+			name:       "public code and read-only issues for guest",
+			repo:       &Repository{EnableIssues: true, IssuesReadOnly: true},
+			mode:       AccessModeRead,
+			wantRead:   true,
+			wantCreate: false,
+		},
+		{
+			name:       "public code and read-only issues for writer",
+			repo:       &Repository{EnableIssues: true, IssuesReadOnly: true},
+			mode:       AccessModeWrite,
+			wantRead:   true,
+			wantCreate: true,
+		},
+		{
+			name:       "private code and private issues for guest",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true},
+			mode:       AccessModeNone,
+			wantRead:   false,
+			wantCreate: false,
+		},
+		{
+			name:       "private code and private issues for collaborator",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true},
+			mode:       AccessModeRead,
+			wantRead:   true,
+			wantCreate: true,
+		},
+		{
+			name:       "private code and public issues for guest",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true, AllowPublicIssues: true},
+			mode:       AccessModeNone,
+			wantRead:   true,
+			wantCreate: true,
+		},
+		{
+			name:       "private code and public read-only issues for guest",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true, AllowPublicIssues: true, IssuesReadOnly: true},
+			mode:       AccessModeNone,
+			wantRead:   true,
+			wantCreate: false,
+		},
+		{
+			name:       "private code and public read-only issues for collaborator",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true, AllowPublicIssues: true, IssuesReadOnly: true},
+			mode:       AccessModeRead,
+			wantRead:   true,
+			wantCreate: false,
+		},
+		{
+			name:       "private code and public read-only issues for writer",
+			repo:       &Repository{IsPrivate: true, EnableIssues: true, AllowPublicIssues: true, IssuesReadOnly: true},
+			mode:       AccessModeWrite,
+			wantRead:   true,
+			wantCreate: true,
+		},
+		{
+			name:       "issues disabled",
+			repo:       &Repository{EnableIssues: false, AllowPublicIssues: true},
+			mode:       AccessModeOwner,
+			wantRead:   false,
+			wantCreate: false,
+		},
+		{
+			name:       "external issue tracker",
+			repo:       &Repository{EnableIssues: true, EnableExternalTracker: true},
+			mode:       AccessModeOwner,
+			wantRead:   false,
+			wantCreate: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantRead, test.repo.CanReadIssues(test.mode))
+			assert.Equal(t, test.wantCreate, test.repo.CanCreateIssues(test.mode))
+		})
+	}
+}
@@ -49,11 +49,28 @@ type ReposStore interface {
 
 	// ListWatches returns all watches of the given repository.
 	ListWatches(ctx context.Context, repoID int64) ([]*Watch, error)
-	// Watch marks the user to watch the repository.
+	// Watch marks the user to watch the repository, and clears the mark of the
+	// user ignoring the repository if any.
 	Watch(ctx context.Context, userID, repoID int64) error
+	// Unwatch marks the user to not watch the repository, and to ignore the
+	// repository so that it is never watched automatically for the user.
+	Unwatch(ctx context.Context, userID, repoID int64) error
+	// AutoWatch marks the user to watch the repository unless the user is ignoring
+	// the repository.
+	AutoWatch(ctx context.Context, userID, repoID int64) error
+	// IsIgnoring returns true if the user is ignoring the repository.
+	IsIgnoring(ctx context.Context, userID, repoID int64) bool
 
 	// HasForkedBy returns true if the given repository has forked by the given user.
 	HasForkedBy(ctx context.Context, repoID, userID int64) bool
+
+	// CountIssuesByRepo returns numbers of open and closed issues and pull
+	// requests of the repository that match the options, in a single query.
+	CountIssuesByRepo(ctx context.Context, repoID int64, opts CountIssuesByRepoOptions) (*RepoIssueCounts, error)
+	// ReconcileIssueCounts recomputes the denormalized numbers of issues and pull
+	// requests of the repository. It returns true if any of them had drifted and
+	// has been corrected.
+	ReconcileIssueCounts(ctx context.Context, repoID int64) (bool, error)
 }
 
 var Repos ReposStore
@@ -76,6 +93,8 @@ func (r *Repository) BeforeUpdate(tx *gorm.DB) error {
 func (r *Repository) AfterFind(_ *gorm.DB) error {
 	r.Created = time.Unix(r.CreatedUnix, 0).Local()
 	r.Updated = time.Unix(r.UpdatedUnix, 0).Local()
+	r.NumOpenIssues = r.NumIssues - r.NumClosedIssues
+	r.NumOpenPulls = r.NumPulls - r.NumClosedPulls
 	return nil
 }
 
@@ -145,16 +164,18 @@ func (err ErrRepoAlreadyExist) Error() string {
 }
 
 type CreateRepoOptions struct {
-	Name          string
-	Description   string
-	DefaultBranch string
-	Private       bool
-	Mirror        bool
-	EnableWiki    bool
-	EnableIssues  bool
-	EnablePulls   bool
-	Fork          bool
-	ForkID        int64
+	Name           string
+	Description    string
+	DefaultBranch  string
+	Private        bool
+	Mirror         bool
+	EnableWiki     bool
+	EnableIssues   bool
+	EnablePulls    bool
+	EnableReleases bool
+	EnableLFS      bool
+	Fork           bool
+	ForkID         int64
 }
 
 func (db *repos) Create(ctx context.Context, ownerID int64, opts CreateRepoOptions) (*Repository, error) {
@@ -176,18 +197,20 @@ func (db *repos) Create(ctx context.Context, ownerID int64, opts CreateRepoOptio
 	}
 
 	repo := &Repository{
-		OwnerID:       ownerID,
-		LowerName:     strings.ToLower(opts.Name),
-		Name:          opts.Name,
-		Description:   opts.Description,
-		DefaultBranch: opts.DefaultBranch,
-		IsPrivate:     opts.Private,
-		IsMirror:      opts.Mirror,
-		EnableWiki:    opts.EnableWiki,
-		EnableIssues:  opts.EnableIssues,
-		EnablePulls:   opts.EnablePulls,
-		IsFork:        opts.Fork,
-		ForkID:        opts.ForkID,
+		OwnerID:        ownerID,
+		LowerName:      strings.ToLower(opts.Name),
+		Name:           opts.Name,
+		Description:    opts.Description,
+		DefaultBranch:  opts.DefaultBranch,
+		IsPrivate:      opts.Private,
+		IsMirror:       opts.Mirror,
+		EnableWiki:     opts.EnableWiki,
+		EnableIssues:   opts.EnableIssues,
+		EnablePulls:    opts.EnablePulls,
+		EnableReleases: opts.EnableReleases,
+		EnableLFS:      opts.EnableLFS,
+		IsFork:         opts.Fork,
+		ForkID:         opts.ForkID,
 	}
 	return repo, db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		err = tx.Create(repo).Error
@@ -195,7 +218,17 @@ func (db *repos) Create(ctx context.Context, ownerID int64, opts CreateRepoOptio
 			return errors.Wrap(err, "create")
 		}
 
-		err = NewReposStore(tx).Watch(ctx, ownerID, repo.ID)
+		// Unknown owners are treated as having the default preference.
+		owner := new(User)
+		err = tx.Where("id = ?", ownerID).First(owner).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return errors.Wrap(err, "get owner")
+		}
+		if !owner.IsAutoWatchOnCreate() {
+			return nil
+		}
+
+		err = db.watch(tx, ownerID, repo.ID)
 		if err != nil {
 			return errors.Wrap(err, "watch")
 		}
@@ -404,23 +437,183 @@ func (db *repos) recountWatches(tx *gorm.DB, repoID int64) error {
 
 func (db *repos) Watch(ctx context.Context, userID, repoID int64) error {
 	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		w := &Watch{
+		err := tx.Where("user_id = ? AND repo_id = ?", userID, repoID).Delete(new(IgnoredRepo)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete ignored repository")
+		}
+
+		return db.watch(tx, userID, repoID)
+	})
+}
+
+func (db *repos) watch(tx *gorm.DB, userID, repoID int64) error {
+	w := &Watch{
+		UserID: userID,
+		RepoID: repoID,
+	}
+	result := tx.FirstOrCreate(w, w)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, "upsert")
+	} else if result.RowsAffected <= 0 {
+		return nil // Relation already exists
+	}
+
+	return db.recountWatches(tx, repoID)
+}
+
+// IgnoredRepo is the relation that a user has explicitly unwatched a repository,
+// which prevents the repository from being watched automatically for the user.
+type IgnoredRepo struct {
+	ID     int64 `gorm:"primaryKey"`
+	UserID int64 `gorm:"uniqueIndex:ignored_repo_user_repo_unique;not null"`
+	RepoID int64 `gorm:"uniqueIndex:ignored_repo_user_repo_unique;index;not null"`
+}
+
+func (db *repos) Unwatch(ctx context.Context, userID, repoID int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ignored := &IgnoredRepo{
 			UserID: userID,
 			RepoID: repoID,
 		}
-		result := tx.FirstOrCreate(w, w)
+		err := tx.FirstOrCreate(ignored, ignored).Error
+		if err != nil {
+			return errors.Wrap(err, "upsert ignored repository")
+		}
+
+		result := tx.Where("user_id = ? AND repo_id = ?", userID, repoID).Delete(new(Watch))
 		if result.Error != nil {
-			return errors.Wrap(result.Error, "upsert")
+			return errors.Wrap(result.Error, "delete watch")
 		} else if result.RowsAffected <= 0 {
-			return nil // Relation already exists
+			return nil // Relation does not exist
 		}
 
 		return db.recountWatches(tx, repoID)
 	})
 }
 
+func (db *repos) AutoWatch(ctx context.Context, userID, repoID int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if NewReposStore(tx).IsIgnoring(ctx, userID, repoID) {
+			return nil
+		}
+		return db.watch(tx, userID, repoID)
+	})
+}
+
+func (db *repos) IsIgnoring(ctx context.Context, userID, repoID int64) bool {
+	var count int64
+	db.WithContext(ctx).Model(new(IgnoredRepo)).Where("user_id = ? AND repo_id = ?", userID, repoID).Count(&count)
+	return count > 0
+}
+
 func (db *repos) HasForkedBy(ctx context.Context, repoID, userID int64) bool {
 	var count int64
 	db.WithContext(ctx).Model(new(Repository)).Where("owner_id = ? AND fork_id = ?", userID, repoID).Count(&count)
 	return count > 0
 }
+
+// CountIssuesByRepoOptions contains optional conditions for counting issues and
+// pull requests of a repository, zero values mean no condition.
+type CountIssuesByRepoOptions struct {
+	PosterID    int64
+	AssigneeID  int64
+	MilestoneID int64
+}
+
+// RepoIssueCounts contains numbers of issues and pull requests of a repository,
+// where the numbers of issues and pull requests include the closed ones.
+type RepoIssueCounts struct {
+	NumIssues       int64
+	NumClosedIssues int64
+	NumPulls        int64
+	NumClosedPulls  int64
+}
+
+// NumOpenIssues returns the number of open issues.
+func (c *RepoIssueCounts) NumOpenIssues() int64 {
+	return c.NumIssues - c.NumClosedIssues
+}
+
+// NumOpenPulls returns the number of open pull requests.
+func (c *RepoIssueCounts) NumOpenPulls() int64 {
+	return c.NumPulls - c.NumClosedPulls
+}
+
+func (db *repos) CountIssuesByRepo(ctx context.Context, repoID int64, opts CountIssuesByRepoOptions) (*RepoIssueCounts, error) {
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT is_pull, is_closed, COUNT(*) AS count FROM issue
+		WHERE repo_id = @repoID
+		[AND poster_id = @posterID]
+		[AND assignee_id = @assigneeID]
+		[AND milestone_id = @milestoneID]
+		GROUP BY is_pull, is_closed
+	*/
+	tx := db.WithContext(ctx).Model(new(Issue)).
+		Select("is_pull, is_closed, COUNT(*) AS count").
+		Where("repo_id = ?", repoID)
+	if opts.PosterID > 0 {
+		tx = tx.Where("poster_id = ?", opts.PosterID)
+	}
+	if opts.AssigneeID > 0 {
+		tx = tx.Where("assignee_id = ?", opts.AssigneeID)
+	}
+	if opts.MilestoneID > 0 {
+		tx = tx.Where("milestone_id = ?", opts.MilestoneID)
+	}
+
+	var rows []struct {
+		IsPull   bool
+		IsClosed bool
+		Count    int64
+	}
+	err := tx.Group("is_pull, is_closed").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := new(RepoIssueCounts)
+	for _, row := range rows {
+		if row.IsPull {
+			counts.NumPulls += row.Count
+			if row.IsClosed {
+				counts.NumClosedPulls += row.Count
+			}
+		} else {
+			counts.NumIssues += row.Count
+			if row.IsClosed {
+				counts.NumClosedIssues += row.Count
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (db *repos) ReconcileIssueCounts(ctx context.Context, repoID int64) (bool, error) {
+	var drifted bool
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		counts, err := NewReposStore(tx).CountIssuesByRepo(ctx, repoID, CountIssuesByRepoOptions{})
+		if err != nil {
+			return errors.Wrap(err, "count issues")
+		}
+
+		// Use UpdateColumns to not touch the updated time of the repository.
+		result := tx.Model(new(Repository)).
+			Where("id = ?", repoID).
+			Where("NOT (num_issues = ? AND num_closed_issues = ? AND num_pulls = ? AND num_closed_pulls = ?)",
+				counts.NumIssues, counts.NumClosedIssues, counts.NumPulls, counts.NumClosedPulls).
+			UpdateColumns(map[string]any{
+				"num_issues":        counts.NumIssues,
+				"num_closed_issues": counts.NumClosedIssues,
+				"num_pulls":         counts.NumPulls,
+				"num_closed_pulls":  counts.NumClosedPulls,
+			})
+		if result.Error != nil {
+			return errors.Wrap(result.Error, "update counts")
+		}
+		drifted = result.RowsAffected > 0
+		return nil
+	})
+	return drifted, err
+}
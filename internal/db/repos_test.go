@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/dbtest"
 	"gogs.io/gogs/internal/errutil"
 )
@@ -85,7 +86,7 @@ func TestRepos(t *testing.T) {
 	}
 	t.Parallel()
 
-	tables := []any{new(Repository), new(Access), new(Watch), new(User), new(EmailAddress), new(Star)}
+	tables := []any{new(Repository), new(Access), new(Watch), new(IgnoredRepo), new(User), new(EmailAddress), new(Star), new(Issue)}
 	db := &repos{
 		DB: dbtest.NewDB(t, "repos", tables...),
 	}
@@ -103,7 +104,11 @@ func TestRepos(t *testing.T) {
 		{"Touch", reposTouch},
 		{"ListByRepo", reposListWatches},
 		{"Watch", reposWatch},
+		{"Unwatch", reposUnwatch},
+		{"AutoWatch", reposAutoWatch},
 		{"HasForkedBy", reposHasForkedBy},
+		{"CountIssuesByRepo", reposCountIssuesByRepo},
+		{"ReconcileIssueCounts", reposReconcileIssueCounts},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Cleanup(func() {
@@ -120,6 +125,7 @@ func TestRepos(t *testing.T) {
 
 func reposCreate(t *testing.T, db *repos) {
 	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
 
 	t.Run("name not allowed", func(t *testing.T) {
 		_, err := db.Create(ctx,
@@ -160,6 +166,18 @@ func reposCreate(t *testing.T, db *repos) {
 	require.NoError(t, err)
 	assert.Equal(t, db.NowFunc().Format(time.RFC3339), repo.Created.UTC().Format(time.RFC3339))
 	assert.Equal(t, 1, repo.NumWatches) // The owner is watching the repo by default.
+
+	t.Run("owner disabled auto-watch", func(t *testing.T) {
+		owner := &User{LowerName: "alice", Name: "alice", AutoWatchOnCreate: AutoWatchDisabled}
+		err := db.DB.Create(owner).Error
+		require.NoError(t, err)
+
+		repo, err := db.Create(ctx, owner.ID, CreateRepoOptions{Name: "repo3"})
+		require.NoError(t, err)
+		repo, err = db.GetByID(ctx, repo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, repo.NumWatches)
+	})
 }
 
 func reposGetByCollaboratorID(t *testing.T, db *repos) {
@@ -304,6 +322,7 @@ func reposTouch(t *testing.T, db *repos) {
 
 func reposListWatches(t *testing.T, db *repos) {
 	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
 
 	err := db.Watch(ctx, 1, 1)
 	require.NoError(t, err)
@@ -327,6 +346,7 @@ func reposListWatches(t *testing.T, db *repos) {
 
 func reposWatch(t *testing.T, db *repos) {
 	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
 
 	reposStore := NewReposStore(db.DB)
 	repo1, err := reposStore.Create(ctx, 1, CreateRepoOptions{Name: "repo1"})
@@ -344,6 +364,59 @@ func reposWatch(t *testing.T, db *repos) {
 	assert.Equal(t, 2, repo1.NumWatches) // The owner is watching the repo by default.
 }
 
+func reposUnwatch(t *testing.T, db *repos) {
+	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
+
+	repo1, err := db.Create(ctx, 1, CreateRepoOptions{Name: "repo1"})
+	require.NoError(t, err)
+
+	err = db.Unwatch(ctx, 1, repo1.ID)
+	require.NoError(t, err)
+	assert.True(t, db.IsIgnoring(ctx, 1, repo1.ID))
+
+	// It is OK to unwatch multiple times and just be noop.
+	err = db.Unwatch(ctx, 1, repo1.ID)
+	require.NoError(t, err)
+
+	repo1, err = db.GetByID(ctx, repo1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, repo1.NumWatches)
+
+	// Watching explicitly again stops ignoring the repository.
+	err = db.Watch(ctx, 1, repo1.ID)
+	require.NoError(t, err)
+	assert.False(t, db.IsIgnoring(ctx, 1, repo1.ID))
+}
+
+func reposAutoWatch(t *testing.T, db *repos) {
+	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
+
+	repo1, err := db.Create(ctx, 1, CreateRepoOptions{Name: "repo1"})
+	require.NoError(t, err)
+
+	err = db.AutoWatch(ctx, 2, repo1.ID)
+	require.NoError(t, err)
+	repo1, err = db.GetByID(ctx, repo1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo1.NumWatches)
+
+	// The ignoring user should not be re-subscribed.
+	err = db.Unwatch(ctx, 2, repo1.ID)
+	require.NoError(t, err)
+	err = db.AutoWatch(ctx, 2, repo1.ID)
+	require.NoError(t, err)
+	repo1, err = db.GetByID(ctx, repo1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo1.NumWatches)
+
+	watches, err := db.ListWatches(ctx, repo1.ID)
+	require.NoError(t, err)
+	require.Len(t, watches, 1)
+	assert.Equal(t, int64(1), watches[0].UserID)
+}
+
 func reposHasForkedBy(t *testing.T, db *repos) {
 	ctx := context.Background()
 
@@ -363,3 +436,82 @@ func reposHasForkedBy(t *testing.T, db *repos) {
 	has = db.HasForkedBy(ctx, 1, 2)
 	assert.True(t, has)
 }
+
+func reposCountIssuesByRepo(t *testing.T, db *repos) {
+	ctx := context.Background()
+
+	for _, issue := range []*Issue{
+		{RepoID: 1, Index: 1, PosterID: 1},
+		{RepoID: 1, Index: 2, PosterID: 2, IsClosed: true},
+		{RepoID: 1, Index: 3, PosterID: 1, IsPull: true},
+		{RepoID: 1, Index: 4, PosterID: 2, IsPull: true, IsClosed: true, MilestoneID: 1},
+		{RepoID: 1, Index: 5, PosterID: 2, IsPull: true, IsClosed: true},
+		{RepoID: 2, Index: 1, PosterID: 1},
+	} {
+		err := db.DB.Create(issue).Error
+		require.NoError(t, err)
+	}
+
+	got, err := db.CountIssuesByRepo(ctx, 1, CountIssuesByRepoOptions{})
+	require.NoError(t, err)
+	want := &RepoIssueCounts{
+		NumIssues:       2,
+		NumClosedIssues: 1,
+		NumPulls:        3,
+		NumClosedPulls:  2,
+	}
+	assert.Equal(t, want, got)
+	assert.Equal(t, int64(1), got.NumOpenIssues())
+	assert.Equal(t, int64(1), got.NumOpenPulls())
+
+	got, err = db.CountIssuesByRepo(ctx, 1, CountIssuesByRepoOptions{PosterID: 2})
+	require.NoError(t, err)
+	want = &RepoIssueCounts{
+		NumIssues:       1,
+		NumClosedIssues: 1,
+		NumPulls:        2,
+		NumClosedPulls:  2,
+	}
+	assert.Equal(t, want, got)
+
+	got, err = db.CountIssuesByRepo(ctx, 1, CountIssuesByRepoOptions{MilestoneID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, &RepoIssueCounts{NumPulls: 1, NumClosedPulls: 1}, got)
+
+	got, err = db.CountIssuesByRepo(ctx, 3, CountIssuesByRepoOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, &RepoIssueCounts{}, got)
+}
+
+func reposReconcileIssueCounts(t *testing.T, db *repos) {
+	ctx := context.Background()
+
+	repo, err := db.Create(ctx, 1, CreateRepoOptions{Name: "repo1"})
+	require.NoError(t, err)
+	for _, issue := range []*Issue{
+		{RepoID: repo.ID, Index: 1},
+		{RepoID: repo.ID, Index: 2, IsClosed: true},
+		{RepoID: repo.ID, Index: 3, IsPull: true},
+	} {
+		err = db.DB.Create(issue).Error
+		require.NoError(t, err)
+	}
+
+	drifted, err := db.ReconcileIssueCounts(ctx, repo.ID)
+	require.NoError(t, err)
+	assert.True(t, drifted)
+
+	repo, err = db.GetByID(ctx, repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.NumIssues)
+	assert.Equal(t, 1, repo.NumClosedIssues)
+	assert.Equal(t, 1, repo.NumOpenIssues)
+	assert.Equal(t, 1, repo.NumPulls)
+	assert.Equal(t, 0, repo.NumClosedPulls)
+	assert.Equal(t, 1, repo.NumOpenPulls)
+
+	// Counters are not touched when nothing has drifted
+	drifted, err = db.ReconcileIssueCounts(ctx, repo.ID)
+	require.NoError(t, err)
+	assert.False(t, drifted)
+}
@@ -0,0 +1,325 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/email"
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/tool"
+)
+
+// ReviewRequestsStore is the persistent interface for review requests of pull
+// requests.
+type ReviewRequestsStore interface {
+	// Create requests reviews of the pull request with given issue ID from the
+	// reviewers, and returns IDs of reviewers who are newly requested. Reviewers
+	// who have already been requested are skipped, so that requests from different
+	// sources never duplicate.
+	Create(ctx context.Context, repoID, issueID int64, reviewerIDs []int64) ([]int64, error)
+	// ListByIssueID returns all review requests of the pull request with given
+	// issue ID, sorted from the oldest.
+	ListByIssueID(ctx context.Context, issueID int64) ([]*ReviewRequest, error)
+	// CountByReviewer returns the number of review requests of each reviewer
+	// among pull requests with given issue IDs. Reviewers without any request are
+	// omitted.
+	CountByReviewer(ctx context.Context, issueIDs []int64) (map[int64]int64, error)
+}
+
+var ReviewRequests ReviewRequestsStore
+
+var _ ReviewRequestsStore = (*reviewRequests)(nil)
+
+type reviewRequests struct {
+	*gorm.DB
+}
+
+// NewReviewRequestsStore returns a persistent interface for review requests of
+// pull requests with given database connection.
+func NewReviewRequestsStore(db *gorm.DB) ReviewRequestsStore {
+	return &reviewRequests{DB: db}
+}
+
+// ReviewRequest is a request for a user to review a pull request.
+type ReviewRequest struct {
+	ID         int64 `gorm:"primaryKey"`
+	RepoID     int64 `gorm:"index;not null"`
+	IssueID    int64 `gorm:"uniqueIndex:review_request_issue_reviewer_unique;not null"`
+	ReviewerID int64 `gorm:"uniqueIndex:review_request_issue_reviewer_unique;index;not null"`
+
+	Created     time.Time `gorm:"-" json:"-"`
+	CreatedUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (r *ReviewRequest) BeforeCreate(tx *gorm.DB) error {
+	if r.CreatedUnix == 0 {
+		r.CreatedUnix = tx.NowFunc().Unix()
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (r *ReviewRequest) AfterFind(_ *gorm.DB) error {
+	r.Created = time.Unix(r.CreatedUnix, 0).Local()
+	return nil
+}
+
+func (db *reviewRequests) Create(ctx context.Context, repoID, issueID int64, reviewerIDs []int64) ([]int64, error) {
+	var requested []int64
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, reviewerID := range reviewerIDs {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(
+				&ReviewRequest{
+					RepoID:     repoID,
+					IssueID:    issueID,
+					ReviewerID: reviewerID,
+				},
+			)
+			if result.Error != nil {
+				return errors.Wrapf(result.Error, "create review request for reviewer %d", reviewerID)
+			} else if result.RowsAffected > 0 {
+				requested = append(requested, reviewerID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return requested, nil
+}
+
+func (db *reviewRequests) ListByIssueID(ctx context.Context, issueID int64) ([]*ReviewRequest, error) {
+	var requests []*ReviewRequest
+	return requests, db.WithContext(ctx).
+		Where("issue_id = ?", issueID).
+		Order("id ASC").
+		Find(&requests).
+		Error
+}
+
+func (db *reviewRequests) CountByReviewer(ctx context.Context, issueIDs []int64) (map[int64]int64, error) {
+	if len(issueIDs) == 0 {
+		return map[int64]int64{}, nil
+	}
+
+	var counts []struct {
+		ReviewerID int64
+		Count      int64
+	}
+	err := db.WithContext(ctx).Model(&ReviewRequest{}).
+		Select("reviewer_id, COUNT(*) AS count").
+		Where("issue_id IN (?)", issueIDs).
+		Group("reviewer_id").
+		Scan(&counts).
+		Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		result[c.ReviewerID] = c.Count
+	}
+	return result, nil
+}
+
+// RequestedReviewers returns users who are requested to review the pull
+// request, sorted by the time they were requested. Users who no longer exist are
+// skipped.
+func (pr *PullRequest) RequestedReviewers(ctx context.Context) ([]*User, error) {
+	requests, err := ReviewRequests.ListByIssueID(ctx, pr.IssueID)
+	if err != nil {
+		return nil, errors.Wrap(err, "list review requests")
+	}
+
+	users := make([]*User, 0, len(requests))
+	for _, r := range requests {
+		u, err := Users.GetByID(ctx, r.ReviewerID)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "get user by ID %d", r.ReviewerID)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// mailRequestedReviewers sends emails to active reviewers who are requested to
+// review the pull request, except the doer. This method assumes the Issue field
+// and its Repo field are loaded.
+func (pr *PullRequest) mailRequestedReviewers(ctx context.Context, doer *User) error {
+	if !conf.User.EnableEmailNotification {
+		return nil
+	}
+
+	reviewers, err := pr.RequestedReviewers(ctx)
+	if err != nil {
+		return errors.Wrap(err, "get requested reviewers")
+	}
+
+	tos := make([]string, 0, len(reviewers))
+	for _, u := range reviewers {
+		if u.ID == doer.ID || !u.IsActive {
+			continue
+		}
+		tos = append(tos, u.Email)
+	}
+	email.SendReviewRequestMail(NewMailerIssue(pr.Issue), NewMailerRepo(pr.Issue.Repo), NewMailerUser(doer), tos)
+	return nil
+}
+
+// DefaultReviewerUserIDList returns the list of IDs of users who are requested
+// to review new pull requests of the repository.
+func (repo *Repository) DefaultReviewerUserIDList() []int64 {
+	if repo.DefaultReviewerUserIDs == "" {
+		return nil
+	}
+	return tool.StringsToInt64s(strings.Split(repo.DefaultReviewerUserIDs, ","))
+}
+
+// DefaultReviewerTeamIDList returns the list of IDs of teams whose members are
+// requested to review new pull requests of the repository.
+func (repo *Repository) DefaultReviewerTeamIDList() []int64 {
+	if repo.DefaultReviewerTeamIDs == "" {
+		return nil
+	}
+	return tool.StringsToInt64s(strings.Split(repo.DefaultReviewerTeamIDs, ","))
+}
+
+// DefaultReviewers returns users and teams who are requested to review new pull
+// requests of the repository. Users and teams that no longer exist are skipped.
+func (repo *Repository) DefaultReviewers(ctx context.Context) ([]*User, []*Team, error) {
+	users := make([]*User, 0, len(repo.DefaultReviewerUserIDList()))
+	for _, id := range repo.DefaultReviewerUserIDList() {
+		u, err := Users.GetByID(ctx, id)
+		if err != nil {
+			if IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "get user by ID %d", id)
+		}
+		users = append(users, u)
+	}
+
+	teams := make([]*Team, 0, len(repo.DefaultReviewerTeamIDList()))
+	for _, id := range repo.DefaultReviewerTeamIDList() {
+		t, err := GetTeamByID(id)
+		if err != nil {
+			if IsErrTeamNotExist(err) {
+				continue
+			}
+			return nil, nil, errors.Wrapf(err, "get team by ID %d", id)
+		}
+		teams = append(teams, t)
+	}
+	return users, teams, nil
+}
+
+// SetDefaultReviewers sets users and teams of the organization who are requested
+// to review new pull requests of the repository by their names, without saving
+// the repository. It returns ErrUserNotExist or ErrTeamNotExist when any of them
+// does not exist or has no read access to the repository.
+func (repo *Repository) SetDefaultReviewers(ctx context.Context, usernames, teamNames []string) error {
+	userIDs := make([]string, 0, len(usernames))
+	for _, name := range usernames {
+		u, err := Users.GetByUsername(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !Perms.Authorize(ctx, u.ID, repo.ID, AccessModeRead,
+			AccessModeOptions{
+				OwnerID: repo.OwnerID,
+				Private: repo.IsPrivate,
+			},
+		) {
+			return ErrUserNotExist{args: errutil.Args{"name": name}}
+		}
+		userIDs = append(userIDs, strconv.FormatInt(u.ID, 10))
+	}
+
+	teamIDs := make([]string, 0, len(teamNames))
+	for _, name := range teamNames {
+		t, err := GetTeamOfOrgByName(repo.OwnerID, name)
+		if err != nil {
+			return err
+		}
+		if !t.IsOwnerTeam() && !t.HasRepository(repo.ID) {
+			return ErrTeamNotExist{args: map[string]any{"orgID": repo.OwnerID, "name": name}}
+		}
+		teamIDs = append(teamIDs, strconv.FormatInt(t.ID, 10))
+	}
+
+	repo.DefaultReviewerUserIDs = strings.Join(userIDs, ",")
+	repo.DefaultReviewerTeamIDs = strings.Join(teamIDs, ",")
+	return nil
+}
+
+// defaultReviewerIDs returns IDs of active users who are requested to review new
+// pull requests of the repository by default sorted in ascending order, with
+// teams of the organization expanded to their members and the author excluded.
+func defaultReviewerIDs(e Engine, repo *Repository, authorID int64) ([]int64, error) {
+	userIDs := repo.DefaultReviewerUserIDList()
+	if teamIDs := repo.DefaultReviewerTeamIDList(); len(teamIDs) > 0 {
+		memberIDs := make([]int64, 0, 5)
+		err := e.Table("team_user").Cols("uid").
+			Where("org_id = ?", repo.OwnerID).
+			In("team_id", teamIDs).
+			Find(&memberIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get team members: %v", err)
+		}
+		userIDs = append(userIDs, memberIDs...)
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	// Only keep users who still exist and are active in case they have changed
+	// since the settings were saved.
+	reviewerIDs := make([]int64, 0, len(userIDs))
+	err := e.Table(new(User)).Cols("id").
+		In("id", userIDs).
+		And("id != ? AND is_active = ?", authorID, true).
+		Find(&reviewerIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get active users: %v", err)
+	}
+	sort.Slice(reviewerIDs, func(i, j int) bool {
+		return reviewerIDs[i] < reviewerIDs[j]
+	})
+	return reviewerIDs, nil
+}
+
+// requestDefaultReviewers requests reviews of the pull request from default
+// reviewers of its base repository, except the author.
+func (pr *PullRequest) requestDefaultReviewers(ctx context.Context, repo *Repository, authorID int64) error {
+	reviewerIDs, err := defaultReviewerIDs(x, repo, authorID)
+	if err != nil {
+		return errors.Wrap(err, "get default reviewers")
+	} else if len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	_, err = ReviewRequests.Create(ctx, repo.ID, pr.IssueID, reviewerIDs)
+	if err != nil {
+		return errors.Wrap(err, "create review requests")
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestReviewRequests(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(ReviewRequest)}
+	db := &reviewRequests{
+		DB: dbtest.NewDB(t, "reviewRequests", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *reviewRequests)
+	}{
+		{"Create", reviewRequestsCreate},
+		{"ListByIssueID", reviewRequestsListByIssueID},
+		{"CountByReviewer", reviewRequestsCountByReviewer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func reviewRequestsCreate(t *testing.T, db *reviewRequests) {
+	ctx := context.Background()
+
+	requested, err := db.Create(ctx, 1, 1, []int64{2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, requested)
+
+	// Reviewers who have been requested are skipped
+	requested, err = db.Create(ctx, 1, 1, []int64{3, 4})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4}, requested)
+
+	requested, err = db.Create(ctx, 1, 1, []int64{2})
+	require.NoError(t, err)
+	assert.Empty(t, requested)
+}
+
+func reviewRequestsListByIssueID(t *testing.T, db *reviewRequests) {
+	ctx := context.Background()
+
+	_, err := db.Create(ctx, 1, 1, []int64{3, 2})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 1, 2, []int64{4})
+	require.NoError(t, err)
+
+	requests, err := db.ListByIssueID(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, requests, 2)
+	assert.Equal(t, int64(3), requests[0].ReviewerID)
+	assert.Equal(t, int64(2), requests[1].ReviewerID)
+	assert.Equal(t, db.NowFunc().Unix(), requests[0].CreatedUnix)
+}
+
+func reviewRequestsCountByReviewer(t *testing.T, db *reviewRequests) {
+	ctx := context.Background()
+
+	_, err := db.Create(ctx, 1, 1, []int64{2, 3})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 1, 2, []int64{3})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 1, 3, []int64{4})
+	require.NoError(t, err)
+
+	counts, err := db.CountByReviewer(ctx, []int64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[int64]int64{2: 1, 3: 2}, counts)
+
+	counts, err = db.CountByReviewer(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, counts)
+}
+
+func TestPullRequest_requestDefaultReviewers(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(TeamUser))
+	before := ReviewRequests
+	ReviewRequests = NewReviewRequestsStore(dbtest.NewDB(t, "requestDefaultReviewers", new(ReviewRequest)))
+	t.Cleanup(func() {
+		ReviewRequests = before
+	})
+
+	ctx := context.Background()
+	const (
+		orgID      = 1
+		authorID   = 2
+		reviewerID = 3
+		memberID   = 4
+		inactiveID = 5
+		outsiderID = 6
+	)
+	for _, u := range []*User{
+		{ID: orgID, LowerName: "acme", Name: "acme", Type: UserTypeOrganization, IsActive: true},
+		{ID: authorID, LowerName: "alice", Name: "alice", IsActive: true},
+		{ID: reviewerID, LowerName: "bob", Name: "bob", IsActive: true},
+		{ID: memberID, LowerName: "cindy", Name: "cindy", IsActive: true},
+		{ID: inactiveID, LowerName: "dan", Name: "dan", IsActive: false},
+		{ID: outsiderID, LowerName: "eve", Name: "eve", IsActive: true},
+	} {
+		_, err := x.Insert(u)
+		require.NoError(t, err)
+	}
+	for _, tu := range []*TeamUser{
+		{OrgID: orgID, TeamID: 1, UID: authorID},
+		{OrgID: orgID, TeamID: 1, UID: memberID},
+		{OrgID: orgID, TeamID: 1, UID: inactiveID},
+		// Teams are only expanded within the organization
+		{OrgID: 2, TeamID: 2, UID: outsiderID},
+	} {
+		_, err := x.Insert(tu)
+		require.NoError(t, err)
+	}
+
+	repo := &Repository{
+		ID:                     1,
+		OwnerID:                orgID,
+		DefaultReviewerUserIDs: "3,4",
+		DefaultReviewerTeamIDs: "1,2",
+	}
+
+	// The author is never requested, and members of both the team and the list
+	// of users are only requested once.
+	pr := &PullRequest{IssueID: 1}
+	err := pr.requestDefaultReviewers(ctx, repo, authorID)
+	require.NoError(t, err)
+
+	requests, err := ReviewRequests.ListByIssueID(ctx, pr.IssueID)
+	require.NoError(t, err)
+	var got []int64
+	for _, r := range requests {
+		got = append(got, r.ReviewerID)
+	}
+	assert.Equal(t, []int64{reviewerID, memberID}, got)
+
+	// Reviewers requested by other sources are not requested again
+	pr = &PullRequest{IssueID: 2}
+	_, err = ReviewRequests.Create(ctx, repo.ID, pr.IssueID, []int64{memberID})
+	require.NoError(t, err)
+	err = pr.requestDefaultReviewers(ctx, repo, reviewerID)
+	require.NoError(t, err)
+
+	requests, err = ReviewRequests.ListByIssueID(ctx, pr.IssueID)
+	require.NoError(t, err)
+	got = got[:0]
+	for _, r := range requests {
+		got = append(got, r.ReviewerID)
+	}
+	assert.Equal(t, []int64{memberID, authorID}, got)
+}
+
+func TestPullRequest_RequestedReviewers(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "requestedReviewers", new(User), new(EmailAddress), new(ReviewRequest))
+	usersStore := NewUsersStore(gdb)
+	reviewRequestsStore := NewReviewRequestsStore(gdb)
+
+	alice, err := usersStore.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := usersStore.Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	// Reviewers who no longer exist are skipped
+	_, err = reviewRequestsStore.Create(ctx, 1, 1, []int64{bob.ID, 404, alice.ID})
+	require.NoError(t, err)
+
+	SetMockUsersStore(t, usersStore)
+	before := ReviewRequests
+	ReviewRequests = reviewRequestsStore
+	t.Cleanup(func() {
+		ReviewRequests = before
+	})
+
+	pr := &PullRequest{IssueID: 1}
+	reviewers, err := pr.RequestedReviewers(ctx)
+	require.NoError(t, err)
+	require.Len(t, reviewers, 2)
+	assert.Equal(t, "bob", reviewers[0].Name)
+	assert.Equal(t, "alice", reviewers[1].Name)
+}
+
+func TestRepository_SetDefaultReviewers(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Team), new(TeamRepo))
+	db := dbtest.NewDB(t, "setDefaultReviewers", new(User), new(EmailAddress), new(Access))
+	SetMockUsersStore(t, NewUsersStore(db))
+	SetMockPermsStore(t, NewPermsStore(db))
+
+	ctx := context.Background()
+	org := &User{ID: 1, LowerName: "acme", Name: "acme", Type: UserTypeOrganization}
+	reader := &User{ID: 2, LowerName: "bob", Name: "bob", Email: "bob@example.com"}
+	outsider := &User{ID: 3, LowerName: "eve", Name: "eve", Email: "eve@example.com"}
+	err := db.Create([]*User{org, reader, outsider}).Error
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: org.ID, IsPrivate: true}
+	err = db.Create(&Access{UserID: reader.ID, RepoID: repo.ID, Mode: AccessModeRead}).Error
+	require.NoError(t, err)
+
+	dev := &Team{ID: 1, OrgID: org.ID, LowerName: "dev", Name: "dev", Authorize: AccessModeRead}
+	ops := &Team{ID: 2, OrgID: org.ID, LowerName: "ops", Name: "ops", Authorize: AccessModeRead}
+	_, err = x.Insert(dev, ops, &TeamRepo{OrgID: org.ID, TeamID: dev.ID, RepoID: repo.ID})
+	require.NoError(t, err)
+
+	err = repo.SetDefaultReviewers(ctx, []string{"bob"}, []string{"dev"})
+	require.NoError(t, err)
+	assert.Equal(t, "2", repo.DefaultReviewerUserIDs)
+	assert.Equal(t, "1", repo.DefaultReviewerTeamIDs)
+
+	// Users and teams without access to the repository are rejected
+	err = repo.SetDefaultReviewers(ctx, []string{"bob", "eve"}, nil)
+	assert.True(t, IsErrUserNotExist(err), "want ErrUserNotExist but got %v", err)
+	err = repo.SetDefaultReviewers(ctx, nil, []string{"ops"})
+	assert.True(t, IsErrTeamNotExist(err), "want ErrTeamNotExist but got %v", err)
+}
@@ -0,0 +1,117 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// IsReviewRotationEnabled returns true if reviews of new pull requests of the
+// repository are requested from members of a team in turns.
+func (repo *Repository) IsReviewRotationEnabled() bool {
+	return repo.ReviewRotationStrategy != AutoAssignDisabled && repo.ReviewRotationTeamID > 0 && repo.ReviewRotationCount > 0
+}
+
+// pickRotationReviewers returns IDs of users to be requested to review a new
+// pull request of the repository by its review rotation strategy, excluding the
+// author. Members who have opted out of automatic assignment or are marked
+// unavailable for the repository are never picked. The round-robin cursor of the
+// repository is advanced when needed.
+func pickRotationReviewers(ctx context.Context, e Engine, repo *Repository, authorID int64) ([]int64, error) {
+	if !repo.IsReviewRotationEnabled() {
+		return nil, nil
+	}
+
+	members, err := autoAssignCandidates(e, repo, repo.ReviewRotationTeamID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]int64, 0, len(members))
+	for _, id := range members {
+		if id != authorID {
+			candidates = append(candidates, id)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	count := repo.ReviewRotationCount
+	if count > len(candidates) {
+		count = len(candidates)
+	}
+
+	switch repo.ReviewRotationStrategy {
+	case AutoAssignRoundRobin:
+		// Use the latest cursor because the repository could be stale when pull
+		// requests are created concurrently.
+		var cursor int64
+		if _, err = e.Table("repository").Cols("review_rotation_cursor").Where("id = ?", repo.ID).Get(&cursor); err != nil {
+			return nil, fmt.Errorf("get round-robin cursor: %v", err)
+		}
+
+		start := 0
+		for i, id := range candidates {
+			if id > cursor {
+				start = i
+				break
+			}
+		}
+
+		reviewerIDs := make([]int64, 0, count)
+		for i := 0; i < count; i++ {
+			reviewerIDs = append(reviewerIDs, candidates[(start+i)%len(candidates)])
+		}
+
+		cursor = reviewerIDs[len(reviewerIDs)-1]
+		if _, err = e.Exec("UPDATE `repository` SET review_rotation_cursor = ? WHERE id = ?", cursor, repo.ID); err != nil {
+			return nil, fmt.Errorf("update round-robin cursor: %v", err)
+		}
+		repo.ReviewRotationCursor = cursor
+		return reviewerIDs, nil
+
+	case AutoAssignLeastLoaded:
+		issueIDs := make([]int64, 0, 10)
+		err = e.Table("issue").Cols("id").
+			Where("repo_id = ? AND is_pull = ? AND is_closed = ?", repo.ID, true, false).
+			Find(&issueIDs)
+		if err != nil {
+			return nil, fmt.Errorf("get open pull requests: %v", err)
+		}
+
+		numRequests, err := ReviewRequests.CountByReviewer(ctx, issueIDs)
+		if err != nil {
+			return nil, errors.Wrap(err, "count review requests by reviewer")
+		}
+
+		// Ties are broken by the lowest user ID.
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return numRequests[candidates[i]] < numRequests[candidates[j]]
+		})
+		return candidates[:count], nil
+	}
+	return nil, nil
+}
+
+// requestRotationReviewers requests reviews of the pull request from members
+// picked by the review rotation of its base repository.
+func (pr *PullRequest) requestRotationReviewers(ctx context.Context, repo *Repository, authorID int64) error {
+	reviewerIDs, err := pickRotationReviewers(ctx, x, repo, authorID)
+	if err != nil {
+		return errors.Wrap(err, "pick reviewers")
+	} else if len(reviewerIDs) == 0 {
+		return nil
+	}
+
+	_, err = ReviewRequests.Create(ctx, repo.ID, pr.IssueID, reviewerIDs)
+	if err != nil {
+		return errors.Wrap(err, "create review requests")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestPickRotationReviewers(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(TeamUser), new(Repository), new(Issue))
+	before := ReviewRequests
+	ReviewRequests = NewReviewRequestsStore(dbtest.NewDB(t, "pickRotationReviewers", new(ReviewRequest)))
+	t.Cleanup(func() {
+		ReviewRequests = before
+	})
+
+	ctx := context.Background()
+	org := &User{LowerName: "acme", Name: "acme", Type: UserTypeOrganization}
+	alice := &User{LowerName: "alice", Name: "alice", IsActive: true}
+	bob := &User{LowerName: "bob", Name: "bob", IsActive: true}
+	carol := &User{LowerName: "carol", Name: "carol", IsActive: true}
+	dave := &User{LowerName: "dave", Name: "dave", IsActive: true}
+	eve := &User{LowerName: "eve", Name: "eve", IsActive: true}
+	_, err := x.Insert(org, alice, bob, carol, dave, eve)
+	require.NoError(t, err)
+
+	const teamID = 1
+	for _, u := range []*User{alice, bob, carol, dave, eve} {
+		_, err = x.Insert(&TeamUser{OrgID: org.ID, TeamID: teamID, UID: u.ID})
+		require.NoError(t, err)
+	}
+
+	newRepo := func(t *testing.T, name string, strategy AutoAssignStrategy) *Repository {
+		repo := &Repository{
+			OwnerID:                      org.ID,
+			LowerName:                    name,
+			Name:                         name,
+			ReviewRotationTeamID:         teamID,
+			ReviewRotationStrategy:       strategy,
+			ReviewRotationCount:          2,
+			AutoAssignUnavailableUserIDs: fmt.Sprintf("%d", eve.ID),
+		}
+		_, err := x.Insert(repo)
+		require.NoError(t, err)
+		return repo
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		repo := newRepo(t, "disabled", AutoAssignDisabled)
+
+		got, err := pickRotationReviewers(ctx, x, repo, alice.ID)
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		repo := newRepo(t, "round-robin", AutoAssignRoundRobin)
+
+		// Members take turns across pull requests, and the unavailable member is
+		// skipped.
+		var got [][]int64
+		for i := 0; i < 3; i++ {
+			ids, err := pickRotationReviewers(ctx, x, repo, org.ID)
+			require.NoError(t, err)
+			got = append(got, ids)
+		}
+		assert.Equal(t,
+			[][]int64{
+				{alice.ID, bob.ID},
+				{carol.ID, dave.ID},
+				{alice.ID, bob.ID},
+			},
+			got,
+		)
+
+		// The cursor is persisted so that the rotation continues with a fresh
+		// copy of the repository.
+		fresh, err := getRepositoryByID(x, repo.ID)
+		require.NoError(t, err)
+		assert.Equal(t, bob.ID, fresh.ReviewRotationCursor)
+
+		// The author is never picked, and the rotation goes on with the next
+		// member.
+		ids, err := pickRotationReviewers(ctx, x, fresh, carol.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{dave.ID, alice.ID}, ids)
+	})
+
+	t.Run("least loaded", func(t *testing.T) {
+		repo := newRepo(t, "least-loaded", AutoAssignLeastLoaded)
+
+		open := &Issue{RepoID: repo.ID, Index: 1, IsPull: true}
+		closed := &Issue{RepoID: repo.ID, Index: 2, IsPull: true, IsClosed: true}
+		_, err := x.Insert(open, closed)
+		require.NoError(t, err)
+
+		_, err = ReviewRequests.Create(ctx, repo.ID, open.ID, []int64{alice.ID, carol.ID})
+		require.NoError(t, err)
+		// Requests of closed pull requests are not outstanding.
+		_, err = ReviewRequests.Create(ctx, repo.ID, closed.ID, []int64{bob.ID, dave.ID})
+		require.NoError(t, err)
+
+		ids, err := pickRotationReviewers(ctx, x, repo, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{bob.ID, dave.ID}, ids)
+	})
+}
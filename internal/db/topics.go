@@ -0,0 +1,261 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/errutil"
+)
+
+// TopicsStore is the persistent interface for topics of repositories.
+type TopicsStore interface {
+	// Set replaces topics of the repository with given topics, which are
+	// normalized and validated by ValidateTopics.
+	Set(ctx context.Context, repoID int64, topics []string) error
+	// ListByRepo returns all topics of the repository, sorted by name.
+	ListByRepo(ctx context.Context, repoID int64) ([]string, error)
+	// Count returns topics of public repositories with the number of
+	// repositories of each topic, sorted by given order. At most limit topics
+	// are returned when limit is positive.
+	Count(ctx context.Context, orderBy TopicOrder, limit int) ([]*TopicCount, error)
+	// Search returns at most limit topics that start with given keyword. Only
+	// allowed topics are returned when the list of allowed topics is configured,
+	// otherwise topics in use are returned.
+	Search(ctx context.Context, keyword string, limit int) ([]string, error)
+}
+
+var Topics TopicsStore
+
+var _ TopicsStore = (*topics)(nil)
+
+type topics struct {
+	*gorm.DB
+}
+
+// NewTopicsStore returns a persistent interface for topics of repositories with
+// given database connection.
+func NewTopicsStore(db *gorm.DB) TopicsStore {
+	return &topics{DB: db}
+}
+
+// RepoTopic is a topic of a repository.
+type RepoTopic struct {
+	ID     int64  `gorm:"primaryKey"`
+	RepoID int64  `gorm:"uniqueIndex:repo_topic_repo_name_unique;not null"`
+	Name   string `gorm:"uniqueIndex:repo_topic_repo_name_unique;index;not null"`
+}
+
+// TopicCount is a topic with the number of repositories of the topic.
+type TopicCount struct {
+	Name     string
+	NumRepos int64
+}
+
+// TopicOrder is the order of topics with counts.
+type TopicOrder string
+
+const (
+	TopicOrderPopular TopicOrder = "popular" // Sorted by number of repositories
+	TopicOrderName    TopicOrder = "name"    // Sorted by name
+)
+
+// MaxRepoTopics is the maximum number of topics that a repository can have.
+const MaxRepoTopics = 25
+
+var topicPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,34}$`)
+
+type ErrTopicInvalid struct {
+	args errutil.Args
+}
+
+func IsErrTopicInvalid(err error) bool {
+	_, ok := err.(ErrTopicInvalid)
+	return ok
+}
+
+func (err ErrTopicInvalid) Error() string {
+	return fmt.Sprintf("topic is invalid: %v", err.args)
+}
+
+// Topic returns the invalid topic.
+func (err ErrTopicInvalid) Topic() string {
+	topic, _ := err.args["topic"].(string)
+	return topic
+}
+
+type ErrTopicNotAllowed struct {
+	args errutil.Args
+}
+
+func IsErrTopicNotAllowed(err error) bool {
+	_, ok := err.(ErrTopicNotAllowed)
+	return ok
+}
+
+func (err ErrTopicNotAllowed) Error() string {
+	return fmt.Sprintf("topic is not allowed: %v", err.args)
+}
+
+// Topic returns the disallowed topic.
+func (err ErrTopicNotAllowed) Topic() string {
+	topic, _ := err.args["topic"].(string)
+	return topic
+}
+
+type ErrTooManyTopics struct {
+	args errutil.Args
+}
+
+func IsErrTooManyTopics(err error) bool {
+	_, ok := err.(ErrTooManyTopics)
+	return ok
+}
+
+func (err ErrTooManyTopics) Error() string {
+	return fmt.Sprintf("too many topics: %v", err.args)
+}
+
+// isTopicAllowed returns true if the topic is in the list of allowed topics.
+func isTopicAllowed(topic string) bool {
+	for _, allowed := range conf.Repository.Topics.Allowed {
+		if strings.EqualFold(allowed, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTopics returns the normalized, deduplicated and sorted list of given
+// topics. It returns ErrTopicInvalid if any of the topics is not a valid topic,
+// ErrTooManyTopics if there are more than MaxRepoTopics topics, or
+// ErrTopicNotAllowed if any of the topics is not in the list of allowed topics
+// in strict mode.
+func ValidateTopics(topics []string) ([]string, error) {
+	seen := make(map[string]bool, len(topics))
+	normalized := make([]string, 0, len(topics))
+	for _, topic := range topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic == "" || seen[topic] {
+			continue
+		}
+		seen[topic] = true
+
+		if !topicPattern.MatchString(topic) {
+			return nil, ErrTopicInvalid{args: errutil.Args{"topic": topic}}
+		}
+		if conf.Repository.Topics.Strict && !isTopicAllowed(topic) {
+			return nil, ErrTopicNotAllowed{args: errutil.Args{"topic": topic}}
+		}
+		normalized = append(normalized, topic)
+	}
+
+	if len(normalized) > MaxRepoTopics {
+		return nil, ErrTooManyTopics{args: errutil.Args{"count": len(normalized), "max": MaxRepoTopics}}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func (db *topics) Set(ctx context.Context, repoID int64, topics []string) error {
+	topics, err := ValidateTopics(topics)
+	if err != nil {
+		return err
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("repo_id = ?", repoID).Delete(new(RepoTopic)).Error
+		if err != nil {
+			return err
+		}
+		if len(topics) == 0 {
+			return nil
+		}
+
+		repoTopics := make([]*RepoTopic, 0, len(topics))
+		for _, topic := range topics {
+			repoTopics = append(repoTopics, &RepoTopic{RepoID: repoID, Name: topic})
+		}
+		return tx.Create(repoTopics).Error
+	})
+}
+
+func (db *topics) ListByRepo(ctx context.Context, repoID int64) ([]string, error) {
+	var topics []string
+	return topics, db.WithContext(ctx).
+		Model(new(RepoTopic)).
+		Where("repo_id = ?", repoID).
+		Order("name ASC").
+		Pluck("name", &topics).
+		Error
+}
+
+func (db *topics) Count(ctx context.Context, orderBy TopicOrder, limit int) ([]*TopicCount, error) {
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT repo_topic.name, COUNT(*) AS num_repos FROM repo_topic
+		JOIN repository ON repository.id = repo_topic.repo_id
+		WHERE repository.is_private = FALSE AND repository.is_unlisted = FALSE
+		GROUP BY repo_topic.name
+		ORDER BY num_repos DESC, repo_topic.name ASC
+		LIMIT @limit
+	*/
+	order := "num_repos DESC, repo_topic.name ASC"
+	if orderBy == TopicOrderName {
+		order = "repo_topic.name ASC"
+	}
+
+	q := db.WithContext(ctx).
+		Model(new(RepoTopic)).
+		Select("repo_topic.name, COUNT(*) AS num_repos").
+		Joins("JOIN repository ON repository.id = repo_topic.repo_id").
+		Where("repository.is_private = ? AND repository.is_unlisted = ?", false, false).
+		Group("repo_topic.name").
+		Order(order)
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	var counts []*TopicCount
+	return counts, q.Scan(&counts).Error
+}
+
+func (db *topics) Search(ctx context.Context, keyword string, limit int) ([]string, error) {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if len(conf.Repository.Topics.Allowed) > 0 {
+		var topics []string
+		for _, topic := range conf.Repository.Topics.Allowed {
+			topic = strings.ToLower(topic)
+			if strings.HasPrefix(topic, keyword) {
+				topics = append(topics, topic)
+			}
+		}
+		sort.Strings(topics)
+		if limit > 0 && len(topics) > limit {
+			topics = topics[:limit]
+		}
+		return topics, nil
+	}
+
+	q := db.WithContext(ctx).
+		Model(new(RepoTopic)).
+		Distinct("name").
+		Where("name LIKE ?", keyword+"%").
+		Order("name ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+
+	var topics []string
+	return topics, q.Pluck("name", &topics).Error
+}
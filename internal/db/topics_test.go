@@ -0,0 +1,228 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestValidateTopics(t *testing.T) {
+	setTopics := func(t *testing.T, allowed []string, strict bool) {
+		opts := conf.Repository
+		opts.Topics.Allowed = allowed
+		opts.Topics.Strict = strict
+		conf.SetMockRepository(t, opts)
+	}
+
+	t.Run("normalized", func(t *testing.T) {
+		setTopics(t, nil, false)
+
+		got, err := ValidateTopics([]string{" Web ", "go", "", "web"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "web"}, got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		setTopics(t, nil, false)
+
+		_, err := ValidateTopics([]string{"go", "-web"})
+		wantErr := ErrTopicInvalid{args: errutil.Args{"topic": "-web"}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("too many", func(t *testing.T) {
+		setTopics(t, nil, false)
+
+		topics := make([]string, 0, MaxRepoTopics+1)
+		for i := 0; i <= MaxRepoTopics; i++ {
+			topics = append(topics, string(rune('a'+i)))
+		}
+		_, err := ValidateTopics(topics)
+		wantErr := ErrTooManyTopics{args: errutil.Args{"count": MaxRepoTopics + 1, "max": MaxRepoTopics}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("disallowed topic in strict mode", func(t *testing.T) {
+		setTopics(t, []string{"go", "Web"}, true)
+
+		got, err := ValidateTopics([]string{"go", "web"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "web"}, got)
+
+		_, err = ValidateTopics([]string{"go", "rust"})
+		wantErr := ErrTopicNotAllowed{args: errutil.Args{"topic": "rust"}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("disallowed topic in non-strict mode", func(t *testing.T) {
+		setTopics(t, []string{"go", "web"}, false)
+
+		got, err := ValidateTopics([]string{"go", "rust"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "rust"}, got)
+	})
+}
+
+func TestTopics(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	tables := []any{new(RepoTopic), new(Repository)}
+	db := &topics{
+		DB: dbtest.NewDB(t, "topics", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *topics)
+	}{
+		{"Set", topicsSet},
+		{"Count", topicsCount},
+		{"Search", topicsSearch},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+func topicsSet(t *testing.T, db *topics) {
+	ctx := context.Background()
+
+	opts := conf.Repository
+	opts.Topics.Allowed = []string{"go", "web"}
+	opts.Topics.Strict = true
+	conf.SetMockRepository(t, opts)
+
+	err := db.Set(ctx, 1, []string{"web", "go"})
+	require.NoError(t, err)
+	err = db.Set(ctx, 2, []string{"go"})
+	require.NoError(t, err)
+
+	got, err := db.ListByRepo(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "web"}, got)
+
+	// A disallowed topic should reject the whole set in strict mode
+	err = db.Set(ctx, 1, []string{"go", "rust"})
+	wantErr := ErrTopicNotAllowed{args: errutil.Args{"topic": "rust"}}
+	assert.Equal(t, wantErr, err)
+
+	got, err = db.ListByRepo(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "web"}, got)
+
+	// The same topic should be allowed otherwise
+	conf.Repository.Topics.Strict = false
+	err = db.Set(ctx, 1, []string{"go", "rust"})
+	require.NoError(t, err)
+
+	got, err = db.ListByRepo(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go", "rust"}, got)
+
+	// Topics of other repositories should not be affected
+	got, err = db.ListByRepo(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, got)
+
+	err = db.Set(ctx, 1, nil)
+	require.NoError(t, err)
+	got, err = db.ListByRepo(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func topicsCount(t *testing.T, db *topics) {
+	ctx := context.Background()
+
+	for _, repo := range []*Repository{
+		{ID: 1, OwnerID: 1, Name: "public1", LowerName: "public1"},
+		{ID: 2, OwnerID: 1, Name: "public2", LowerName: "public2"},
+		{ID: 3, OwnerID: 1, Name: "private", LowerName: "private", IsPrivate: true},
+		{ID: 4, OwnerID: 1, Name: "unlisted", LowerName: "unlisted", IsUnlisted: true},
+	} {
+		err := db.Create(repo).Error
+		require.NoError(t, err)
+	}
+
+	err := db.Set(ctx, 1, []string{"go", "web"})
+	require.NoError(t, err)
+	err = db.Set(ctx, 2, []string{"web"})
+	require.NoError(t, err)
+	err = db.Set(ctx, 3, []string{"secret", "web"})
+	require.NoError(t, err)
+	err = db.Set(ctx, 4, []string{"secret"})
+	require.NoError(t, err)
+
+	got, err := db.Count(ctx, TopicOrderPopular, 0)
+	require.NoError(t, err)
+	want := []*TopicCount{
+		{Name: "web", NumRepos: 2},
+		{Name: "go", NumRepos: 1},
+	}
+	assert.Equal(t, want, got)
+
+	got, err = db.Count(ctx, TopicOrderName, 0)
+	require.NoError(t, err)
+	want = []*TopicCount{
+		{Name: "go", NumRepos: 1},
+		{Name: "web", NumRepos: 2},
+	}
+	assert.Equal(t, want, got)
+
+	got, err = db.Count(ctx, TopicOrderPopular, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []*TopicCount{{Name: "web", NumRepos: 2}}, got)
+}
+
+func topicsSearch(t *testing.T, db *topics) {
+	ctx := context.Background()
+
+	err := db.Set(ctx, 1, []string{"go", "gogs", "web"})
+	require.NoError(t, err)
+	err = db.Set(ctx, 2, []string{"gogs"})
+	require.NoError(t, err)
+
+	t.Run("topics in use", func(t *testing.T) {
+		opts := conf.Repository
+		opts.Topics.Allowed = nil
+		conf.SetMockRepository(t, opts)
+
+		got, err := db.Search(ctx, "Go", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "gogs"}, got)
+
+		got, err = db.Search(ctx, "go", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go"}, got)
+	})
+
+	t.Run("allowed topics", func(t *testing.T) {
+		opts := conf.Repository
+		opts.Topics.Allowed = []string{"golang", "web", "go"}
+		conf.SetMockRepository(t, opts)
+
+		got, err := db.Search(ctx, "go", 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "golang"}, got)
+	})
+}
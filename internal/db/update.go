@@ -83,10 +83,6 @@ func PushUpdate(opts PushUpdateOptions) (err error) {
 		return fmt.Errorf("GetRepositoryByName: %v", err)
 	}
 
-	if err = repo.UpdateSize(); err != nil {
-		return fmt.Errorf("UpdateSize: %v", err)
-	}
-
 	// Push tags
 	if strings.HasPrefix(opts.FullRefspec, git.RefsTags) {
 		err := Actions.PushTag(ctx,
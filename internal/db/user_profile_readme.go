@@ -0,0 +1,173 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"sync"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitutil"
+	"gogs.io/gogs/internal/markup"
+	"gogs.io/gogs/internal/tool"
+)
+
+// profileReadmeRepoNames returns names of repositories in the order of priority
+// whose README is shown on the profile page of the user.
+func profileReadmeRepoNames(u *User) []string {
+	return []string{u.Name, ".profile"}
+}
+
+// ProfileReadme is the rendered README of the profile repository of a user.
+type ProfileReadme struct {
+	Repo     *Repository
+	FileName string
+	// Content is the rendered HTML of the README.
+	Content string
+}
+
+type profileReadmeCacheEntry struct {
+	commitID string
+	link     string
+	fileName string
+	content  string
+}
+
+// profileReadmeCache caches rendered profile READMEs keyed by the repository
+// ID, an entry is only valid for the commit it was rendered from.
+var profileReadmeCache = struct {
+	sync.RWMutex
+	entries map[int64]profileReadmeCacheEntry
+}{
+	entries: make(map[int64]profileReadmeCacheEntry),
+}
+
+// GetProfileReadme returns the rendered README in the default branch of the
+// profile repository of the user, i.e. the repository "<username>/<username>"
+// or "<username>/.profile". It returns nil if no such repository exists, the
+// viewer (0 for anonymous) has no read access to it, or it has no README.
+func GetProfileReadme(ctx context.Context, u *User, viewerID int64) (*ProfileReadme, error) {
+	var repo *Repository
+	for _, name := range profileReadmeRepoNames(u) {
+		r, err := Repos.GetByName(ctx, u.ID, name)
+		if err != nil {
+			if IsErrRepoNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "get repository by name")
+		}
+
+		repo = r
+		break
+	}
+	if repo == nil || repo.IsBare {
+		return nil, nil
+	}
+
+	if repo.IsPrivate && !Perms.Authorize(ctx, viewerID, repo.ID, AccessModeRead,
+		AccessModeOptions{
+			OwnerID: repo.OwnerID,
+			Private: repo.IsPrivate,
+		},
+	) {
+		return nil, nil
+	}
+	repo.Owner = u
+
+	gitRepo, err := git.Open(RepoPath(u.Name, repo.Name))
+	if err != nil {
+		return nil, errors.Wrap(err, "open repository")
+	}
+	commit, err := gitRepo.BranchCommit(repo.DefaultBranch)
+	if err != nil {
+		if gitutil.IsErrRevisionNotExist(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "get branch commit")
+	}
+
+	commitID := commit.ID.String()
+	profileReadmeCache.RLock()
+	entry, ok := profileReadmeCache.entries[repo.ID]
+	profileReadmeCache.RUnlock()
+	if ok && entry.commitID == commitID && entry.link == repo.Link() {
+		if entry.fileName == "" {
+			return nil, nil
+		}
+		return &ProfileReadme{
+			Repo:     repo,
+			FileName: entry.fileName,
+			Content:  entry.content,
+		}, nil
+	}
+
+	entry = profileReadmeCacheEntry{
+		commitID: commitID,
+		link:     repo.Link(),
+	}
+	entry.fileName, entry.content, err = renderProfileReadme(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	profileReadmeCache.Lock()
+	profileReadmeCache.entries[repo.ID] = entry
+	profileReadmeCache.Unlock()
+
+	if entry.fileName == "" {
+		return nil, nil
+	}
+	return &ProfileReadme{
+		Repo:     repo,
+		FileName: entry.fileName,
+		Content:  entry.content,
+	}, nil
+}
+
+// renderProfileReadme returns the file name and rendered HTML of the README in
+// the root directory of the commit. It returns empty values when there is no
+// README that can be displayed.
+func renderProfileReadme(repo *Repository, commit *git.Commit) (fileName, content string, _ error) {
+	entries, err := commit.Entries()
+	if err != nil {
+		return "", "", errors.Wrap(err, "list entries")
+	}
+
+	var readme *git.Blob
+	for _, entry := range entries {
+		if entry.IsTree() || !markup.IsReadmeFile(entry.Name()) {
+			continue
+		}
+
+		readme = entry.Blob()
+		break
+	}
+	if readme == nil || readme.Size() >= conf.UI.MaxDisplayFileSize {
+		return "", "", nil
+	}
+
+	p, err := readme.Bytes()
+	if err != nil {
+		return "", "", errors.Wrap(err, "read file")
+	} else if !tool.IsTextFile(p) {
+		return "", "", nil
+	}
+
+	urlPrefix := repo.Link() + "/src/" + repo.DefaultBranch
+	switch markup.Detect(readme.Name()) {
+	case markup.TypeMarkdown:
+		p = markup.Markdown(p, urlPrefix, repo.ComposeMetas())
+	case markup.TypeOrgMode:
+		p = markup.OrgMode(p, urlPrefix, repo.ComposeMetas())
+	default:
+		p = bytes.ReplaceAll([]byte(template.HTMLEscapeString(string(p))), []byte("\n"), []byte(`<br>`))
+	}
+	return readme.Name(), string(p), nil
+}
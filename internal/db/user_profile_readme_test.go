@@ -0,0 +1,115 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+)
+
+func TestGetProfileReadme(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	uiOpts := conf.UI
+	uiOpts.MaxDisplayFileSize = 8 * 1024
+	conf.SetMockUI(t, uiOpts)
+	markdownOpts := conf.Markdown
+	markdownOpts.FileExtensions = []string{".md"}
+	conf.SetMockMarkdown(t, markdownOpts)
+
+	ctx := context.Background()
+	gdb := dbtest.NewDB(t, "getProfileReadme", new(User), new(EmailAddress), new(Repository), new(Access), new(Watch))
+	SetMockReposStore(t, NewReposStore(gdb))
+	SetMockPermsStore(t, NewPermsStore(gdb))
+
+	users := NewUsersStore(gdb)
+	alice, err := users.Create(ctx, "alice", "alice@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	bob, err := users.Create(ctx, "bob", "bob@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+	cindy, err := users.Create(ctx, "cindy", "cindy@example.com", CreateUserOptions{})
+	require.NoError(t, err)
+
+	// pushReadme creates the repository of the user with given content of the
+	// README in the default branch.
+	pushReadme := func(t *testing.T, owner *User, name, content string, private bool) *Repository {
+		t.Helper()
+
+		repo, err := Repos.Create(ctx, owner.ID, CreateRepoOptions{Name: name, DefaultBranch: "main", Private: private})
+		require.NoError(t, err)
+		repoPath := RepoPath(owner.Name, name)
+		err = git.Init(repoPath, git.InitOptions{Bare: true})
+		require.NoError(t, err)
+
+		workPath := t.TempDir()
+		run := func(args ...string) {
+			_, err := git.NewCommand(args...).RunInDir(workPath)
+			require.NoError(t, err)
+		}
+		run("init", "-b", "main")
+		err = os.WriteFile(filepath.Join(workPath, "README.md"), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", "README.md")
+		run("commit", "-m", "Update README")
+		run("push", repoPath, "main")
+		return repo
+	}
+
+	_ = pushReadme(t, alice, "alice", "# Hi, I'm Alice", false)
+	_ = pushReadme(t, alice, ".profile", "# Not me", false)
+	bobProfile := pushReadme(t, bob, ".profile", "# Bob's *secret* plans", true)
+	err = NewPermsStore(gdb).SetRepoPerms(ctx, bobProfile.ID, map[int64]AccessMode{alice.ID: AccessModeRead})
+	require.NoError(t, err)
+
+	t.Run("repository named after the user takes priority", func(t *testing.T) {
+		readme, err := GetProfileReadme(ctx, alice, 0)
+		require.NoError(t, err)
+		require.NotNil(t, readme)
+		assert.Equal(t, "alice", readme.Repo.Name)
+		assert.Equal(t, "README.md", readme.FileName)
+		assert.Equal(t, "<h1>Hi, I&#39;m Alice</h1>\n", readme.Content)
+	})
+
+	t.Run("private repository", func(t *testing.T) {
+		for _, viewerID := range []int64{bob.ID, alice.ID} {
+			readme, err := GetProfileReadme(ctx, bob, viewerID)
+			require.NoError(t, err)
+			require.NotNil(t, readme)
+			assert.Equal(t, ".profile", readme.Repo.Name)
+			assert.Contains(t, readme.Content, "<em>secret</em>")
+		}
+
+		// Anonymous viewers and users without access to the repository
+		for _, viewerID := range []int64{0, cindy.ID} {
+			readme, err := GetProfileReadme(ctx, bob, viewerID)
+			require.NoError(t, err)
+			assert.Nil(t, readme)
+		}
+	})
+
+	t.Run("no profile repository", func(t *testing.T) {
+		readme, err := GetProfileReadme(ctx, cindy, 0)
+		require.NoError(t, err)
+		assert.Nil(t, readme)
+	})
+}
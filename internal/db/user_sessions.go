@@ -0,0 +1,235 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/cryptoutil"
+	"gogs.io/gogs/internal/errutil"
+)
+
+// UserSessionsStore is the persistent interface for metadata of signed-in
+// sessions of users.
+type UserSessionsStore interface {
+	// Create records a new signed-in session with given session ID of the user.
+	// Records of expired sessions of the user are removed along the way.
+	Create(ctx context.Context, userID int64, sid, userAgent, ip string) (*UserSession, error)
+	// GetBySID returns the record of the session with given session ID. It
+	// returns ErrUserSessionNotExist when not found.
+	GetBySID(ctx context.Context, sid string) (*UserSession, error)
+	// List returns all unexpired sessions of the user, sorted from the most
+	// recently seen.
+	List(ctx context.Context, userID int64) ([]*UserSession, error)
+	// Touch records the activity of the given session from the IP address by
+	// updating its last seen time to the current time. To avoid a write on every
+	// request, it is a no-op when the session was last seen from the same IP
+	// address within the last UserSessionTouchInterval.
+	Touch(ctx context.Context, s *UserSession, ip string) error
+	// DeleteByID deletes the record of the session with given ID, which revokes
+	// the session on its next request. It returns ErrUserSessionNotExist when not
+	// found.
+	//
+	// 🚨 SECURITY: The "userID" is required to prevent attacker revokes arbitrary
+	// session that belongs to another user.
+	DeleteByID(ctx context.Context, userID, id int64) error
+	// Revoke deletes the record of the session with given ID like DeleteByID, and
+	// regenerates rands of the user to invalidate remember-me cookies, otherwise
+	// the revoked device would be signed in again right away. Remember-me cookies
+	// on other devices are invalidated as well since they can't be told apart.
+	//
+	// 🚨 SECURITY: The "userID" is required to prevent attacker revokes arbitrary
+	// session that belongs to another user.
+	Revoke(ctx context.Context, userID, id int64) error
+	// RevokeAll deletes records of all sessions of the user, and regenerates
+	// rands of the user to invalidate remember-me cookies on all devices.
+	RevokeAll(ctx context.Context, userID int64) error
+}
+
+var UserSessions UserSessionsStore
+
+var _ UserSessionsStore = (*userSessions)(nil)
+
+type userSessions struct {
+	*gorm.DB
+}
+
+// NewUserSessionsStore returns a persistent interface for metadata of signed-in
+// sessions of users with given database connection.
+func NewUserSessionsStore(db *gorm.DB) UserSessionsStore {
+	return &userSessions{DB: db}
+}
+
+// UserSession is the metadata of a signed-in session of a user. The session
+// data lives in the session provider, and a session without its record is
+// considered as revoked.
+type UserSession struct {
+	ID     int64 `gorm:"primaryKey"`
+	UserID int64 `gorm:"index;not null"`
+	// The SHA256 hash of the session ID, so that the session ID can't be derived
+	// from the database.
+	SessionIDHash string `gorm:"type:VARCHAR(64);unique;not null"`
+	UserAgent     string `gorm:"type:VARCHAR(512)"`
+	IP            string
+
+	Created      time.Time `gorm:"-" json:"-"`
+	CreatedUnix  int64
+	LastSeen     time.Time `gorm:"-" json:"-"`
+	LastSeenUnix int64
+}
+
+// BeforeCreate implements the GORM create hook.
+func (s *UserSession) BeforeCreate(tx *gorm.DB) error {
+	if s.CreatedUnix == 0 {
+		s.CreatedUnix = tx.NowFunc().Unix()
+	}
+	if s.LastSeenUnix == 0 {
+		s.LastSeenUnix = s.CreatedUnix
+	}
+	return nil
+}
+
+// AfterFind implements the GORM query hook.
+func (s *UserSession) AfterFind(_ *gorm.DB) error {
+	s.Created = time.Unix(s.CreatedUnix, 0).Local()
+	s.LastSeen = time.Unix(s.LastSeenUnix, 0).Local()
+	return nil
+}
+
+// expiredBefore returns the Unix timestamp before which a session that has not
+// been seen is expired in the session provider.
+func (db *userSessions) expiredBefore() int64 {
+	return db.NowFunc().Unix() - conf.Session.MaxLifeTime
+}
+
+func (db *userSessions) Create(ctx context.Context, userID int64, sid, userAgent, ip string) (*UserSession, error) {
+	if len(userAgent) > 512 {
+		userAgent = userAgent[:512]
+	}
+	s := &UserSession{
+		UserID:        userID,
+		SessionIDHash: cryptoutil.SHA256(sid),
+		UserAgent:     userAgent,
+		IP:            ip,
+	}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND last_seen_unix < ?", userID, db.expiredBefore()).Delete(new(UserSession)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete expired sessions")
+		}
+		return tx.Create(s).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+var _ errutil.NotFound = (*ErrUserSessionNotExist)(nil)
+
+type ErrUserSessionNotExist struct {
+	args errutil.Args
+}
+
+// IsErrUserSessionNotExist returns true if the underlying error has the type
+// ErrUserSessionNotExist.
+func IsErrUserSessionNotExist(err error) bool {
+	_, ok := errors.Cause(err).(ErrUserSessionNotExist)
+	return ok
+}
+
+func (err ErrUserSessionNotExist) Error() string {
+	return fmt.Sprintf("user session does not exist: %v", err.args)
+}
+
+func (ErrUserSessionNotExist) NotFound() bool {
+	return true
+}
+
+func (db *userSessions) GetBySID(ctx context.Context, sid string) (*UserSession, error) {
+	s := new(UserSession)
+	err := db.WithContext(ctx).Where("session_id_hash = ?", cryptoutil.SHA256(sid)).First(s).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// NOTE: Do not leak the session ID in the error message.
+			return nil, ErrUserSessionNotExist{args: errutil.Args{"sid": "<redacted>"}}
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (db *userSessions) List(ctx context.Context, userID int64) ([]*UserSession, error) {
+	var sessions []*UserSession
+	return sessions, db.WithContext(ctx).
+		Where("user_id = ? AND last_seen_unix >= ?", userID, db.expiredBefore()).
+		Order("last_seen_unix DESC, id DESC").
+		Find(&sessions).
+		Error
+}
+
+// UserSessionTouchInterval is the minimal interval between two writes of the
+// last seen time of a session.
+const UserSessionTouchInterval = time.Minute
+
+func (db *userSessions) Touch(ctx context.Context, s *UserSession, ip string) error {
+	now := db.NowFunc()
+	if s.IP == ip && now.Unix()-s.LastSeenUnix < int64(UserSessionTouchInterval.Seconds()) {
+		return nil
+	}
+
+	err := db.WithContext(ctx).
+		Model(new(UserSession)).
+		Where("id = ?", s.ID).
+		UpdateColumns(map[string]any{
+			"last_seen_unix": now.Unix(),
+			"ip":             ip,
+		}).
+		Error
+	if err != nil {
+		return err
+	}
+
+	s.LastSeenUnix = now.Unix()
+	s.LastSeen = time.Unix(s.LastSeenUnix, 0).Local()
+	s.IP = ip
+	return nil
+}
+
+func (db *userSessions) DeleteByID(ctx context.Context, userID, id int64) error {
+	result := db.WithContext(ctx).Where("user_id = ? AND id = ?", userID, id).Delete(new(UserSession))
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return ErrUserSessionNotExist{args: errutil.Args{"userID": userID, "id": id}}
+	}
+	return nil
+}
+
+func (db *userSessions) Revoke(ctx context.Context, userID, id int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := NewUserSessionsStore(tx).DeleteByID(ctx, userID, id)
+		if err != nil {
+			return err
+		}
+		return NewUsersStore(tx).Update(ctx, userID, UpdateUserOptions{GenerateNewRands: true})
+	})
+}
+
+func (db *userSessions) RevokeAll(ctx context.Context, userID int64) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(new(UserSession)).Error
+		if err != nil {
+			return errors.Wrap(err, "delete sessions")
+		}
+		return NewUsersStore(tx).Update(ctx, userID, UpdateUserOptions{GenerateNewRands: true})
+	})
+}
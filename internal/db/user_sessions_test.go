@@ -0,0 +1,241 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/dbtest"
+	"gogs.io/gogs/internal/errutil"
+)
+
+func TestUserSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	t.Parallel()
+
+	tables := []any{new(UserSession), new(User), new(EmailAddress)}
+	db := &userSessions{
+		DB: dbtest.NewDB(t, "userSessions", tables...),
+	}
+
+	for _, tc := range []struct {
+		name string
+		test func(t *testing.T, db *userSessions)
+	}{
+		{"Create", userSessionsCreate},
+		{"GetBySID", userSessionsGetBySID},
+		{"List", userSessionsList},
+		{"Touch", userSessionsTouch},
+		{"DeleteByID", userSessionsDeleteByID},
+		{"Revoke", userSessionsRevoke},
+		{"RevokeAll", userSessionsRevokeAll},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(func() {
+				err := clearTables(t, db.DB, tables...)
+				require.NoError(t, err)
+			})
+			tc.test(t, db)
+		})
+		if t.Failed() {
+			break
+		}
+	}
+}
+
+// createExpiredUserSession creates a record of the session with given ID of the
+// user that is no longer alive in the session provider.
+func createExpiredUserSession(t *testing.T, db *userSessions, userID int64, sid string) *UserSession {
+	s, err := db.Create(context.Background(), userID, sid, "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+
+	s.LastSeenUnix = db.NowFunc().Unix() - conf.Session.MaxLifeTime - 1
+	err = db.Model(s).UpdateColumn("last_seen_unix", s.LastSeenUnix).Error
+	require.NoError(t, err)
+	return s
+}
+
+func userSessionsCreate(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	_ = createExpiredUserSession(t, db, 1, "sid0")
+
+	s, err := db.Create(ctx, 1, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), s.UserID)
+	assert.Equal(t, "Firefox", s.UserAgent)
+	assert.Equal(t, "127.0.0.1", s.IP)
+	assert.Equal(t, db.NowFunc().Unix(), s.CreatedUnix)
+	assert.Equal(t, db.NowFunc().Unix(), s.LastSeenUnix)
+
+	// The session ID should not be stored as-is
+	assert.NotEqual(t, "sid1", s.SessionIDHash)
+
+	// Records of expired sessions should be removed
+	_, err = db.GetBySID(ctx, "sid0")
+	assert.True(t, IsErrUserSessionNotExist(err))
+}
+
+func userSessionsGetBySID(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	s, err := db.Create(ctx, 1, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+
+	got, err := db.GetBySID(ctx, "sid1")
+	require.NoError(t, err)
+	assert.Equal(t, s.ID, got.ID)
+	assert.Equal(t, s.CreatedUnix, got.Created.Unix())
+
+	_, err = db.GetBySID(ctx, "404")
+	wantErr := ErrUserSessionNotExist{args: errutil.Args{"sid": "<redacted>"}}
+	assert.Equal(t, wantErr, err)
+}
+
+func userSessionsList(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	_ = createExpiredUserSession(t, db, 1, "sid0")
+	s1, err := db.Create(ctx, 1, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+	s2, err := db.Create(ctx, 1, "sid2", "Safari", "10.0.0.1")
+	require.NoError(t, err)
+	_, err = db.Create(ctx, 2, "sid3", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+
+	// Make the second session the most recently seen one
+	err = db.Model(s2).UpdateColumn("last_seen_unix", s2.LastSeenUnix+1).Error
+	require.NoError(t, err)
+
+	sessions, err := db.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, s2.ID, sessions[0].ID)
+	assert.Equal(t, s1.ID, sessions[1].ID)
+}
+
+func userSessionsTouch(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	s, err := db.Create(ctx, 1, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+
+	// Rapid requests from the same IP address should not write to the database
+	err = db.Model(new(UserSession)).Where("id = ?", s.ID).UpdateColumn("last_seen_unix", 1).Error
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		err = db.Touch(ctx, s, "127.0.0.1")
+		require.NoError(t, err)
+	}
+	got, err := db.GetBySID(ctx, "sid1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.LastSeenUnix)
+
+	// Request from another IP address should be recorded right away
+	err = db.Touch(ctx, got, "10.0.0.1")
+	require.NoError(t, err)
+	got, err = db.GetBySID(ctx, "sid1")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", got.IP)
+	assert.Equal(t, db.NowFunc().Unix(), got.LastSeenUnix)
+
+	// Request after the interval should be recorded again
+	got.LastSeenUnix -= int64(UserSessionTouchInterval.Seconds())
+	err = db.Model(new(UserSession)).Where("id = ?", got.ID).UpdateColumn("last_seen_unix", got.LastSeenUnix).Error
+	require.NoError(t, err)
+	err = db.Touch(ctx, got, "10.0.0.1")
+	require.NoError(t, err)
+	got, err = db.GetBySID(ctx, "sid1")
+	require.NoError(t, err)
+	assert.Equal(t, db.NowFunc().Unix(), got.LastSeenUnix)
+}
+
+func userSessionsDeleteByID(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	s, err := db.Create(ctx, 1, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+
+	// Revoking a session of another user should fail
+	err = db.DeleteByID(ctx, 2, s.ID)
+	wantErr := ErrUserSessionNotExist{args: errutil.Args{"userID": int64(2), "id": s.ID}}
+	assert.Equal(t, wantErr, err)
+
+	err = db.DeleteByID(ctx, 1, s.ID)
+	require.NoError(t, err)
+
+	// The revoked session should not be found anymore
+	_, err = db.GetBySID(ctx, "sid1")
+	assert.True(t, IsErrUserSessionNotExist(err))
+}
+
+// rememberCookieSecret returns the secret used to sign remember-me cookies of
+// the user, which should change for revoked sessions.
+func rememberCookieSecret(t *testing.T, db *userSessions, userID int64) string {
+	u, err := NewUsersStore(db.DB).GetByID(context.Background(), userID)
+	require.NoError(t, err)
+	return u.Rands + u.Password
+}
+
+func userSessionsRevoke(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	alice, err := NewUsersStore(db.DB).Create(ctx, "alice", "alice@example.com", CreateUserOptions{Password: "pass"})
+	require.NoError(t, err)
+	s, err := db.Create(ctx, alice.ID, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+	secret := rememberCookieSecret(t, db, alice.ID)
+
+	// Revoking a session of another user should fail and leave the remember-me
+	// cookie of the user intact.
+	err = db.Revoke(ctx, alice.ID+1, s.ID)
+	wantErr := ErrUserSessionNotExist{args: errutil.Args{"userID": alice.ID + 1, "id": s.ID}}
+	assert.Equal(t, wantErr, err)
+	assert.Equal(t, secret, rememberCookieSecret(t, db, alice.ID))
+
+	err = db.Revoke(ctx, alice.ID, s.ID)
+	require.NoError(t, err)
+
+	_, err = db.GetBySID(ctx, "sid1")
+	assert.True(t, IsErrUserSessionNotExist(err))
+
+	// The remember-me cookie of the revoked device should not be able to sign in
+	// the user again.
+	assert.NotEqual(t, secret, rememberCookieSecret(t, db, alice.ID))
+}
+
+func userSessionsRevokeAll(t *testing.T, db *userSessions) {
+	ctx := context.Background()
+
+	alice, err := NewUsersStore(db.DB).Create(ctx, "alice", "alice@example.com", CreateUserOptions{Password: "pass"})
+	require.NoError(t, err)
+	_, err = db.Create(ctx, alice.ID, "sid1", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+	_, err = db.Create(ctx, alice.ID, "sid2", "Safari", "10.0.0.1")
+	require.NoError(t, err)
+	_, err = db.Create(ctx, alice.ID+1, "sid3", "Firefox", "127.0.0.1")
+	require.NoError(t, err)
+	secret := rememberCookieSecret(t, db, alice.ID)
+
+	err = db.RevokeAll(ctx, alice.ID)
+	require.NoError(t, err)
+
+	sessions, err := db.List(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	assert.NotEqual(t, secret, rememberCookieSecret(t, db, alice.ID))
+
+	// Sessions of other users should be intact
+	sessions, err = db.List(ctx, alice.ID+1)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1)
+}
@@ -5,9 +5,11 @@
 package db
 
 import (
+	"archive/zip"
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 	"github.com/go-macaron/binding"
 	api "github.com/gogs/go-gogs-client"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 	log "unknwon.dev/clog/v2"
@@ -46,6 +49,13 @@ type UsersStore interface {
 	// When the "loginSourceID" is positive, it tries to authenticate via given
 	// login source and creates a new user when not yet exists in the database.
 	Authenticate(ctx context.Context, username, password string, loginSourceID int64) (*User, error)
+	// AuthenticateExternal returns the user of the external account that has
+	// been authenticated by given login source, e.g. via OpenID Connect. The user
+	// is looked up by the login of the external account, then by the verified
+	// email address to link an existing local user, and is created when neither
+	// exists. It returns ErrEmailAlreadyUsed if the email address belongs to a
+	// user of another login source.
+	AuthenticateExternal(ctx context.Context, loginSourceID int64, extAccount *auth.ExternalAccount) (*User, error)
 	// Create creates a new user and persists to database. It returns
 	// ErrNameNotAllowed if the given name or pattern of the name is not allowed as
 	// a username, or ErrUserAlreadyExist when a user with same name already exists,
@@ -54,6 +64,7 @@ type UsersStore interface {
 
 	// GetByEmail returns the user (not organization) with given email. It ignores
 	// records with unverified emails and returns ErrUserNotExist when not found.
+	// The noreply email address of a user is resolved to the user as well.
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	// GetByID returns the user with given ID. It returns ErrUserNotExist when not
 	// found.
@@ -92,14 +103,20 @@ type UsersStore interface {
 	// DeleteCustomAvatar deletes the current user custom avatar and falls back to
 	// use look up avatar by email.
 	DeleteCustomAvatar(ctx context.Context, userID int64) error
-	// DeleteByID deletes the given user and all their resources. It returns
-	// ErrUserOwnRepos when the user still has repository ownership, or returns
-	// ErrUserHasOrgs when the user still has organization membership. It is more
-	// performant to skip rewriting the "authorized_keys" file for individual
-	// deletion in a batch operation.
+	// DeleteByID deletes the given user and all their resources including
+	// organization memberships. It returns ErrUserOwnRepos when the user still has
+	// repository ownership, or returns ErrLastOrgOwner when the user is the last
+	// owner of any organization. Issues, pull requests and comments of the user are
+	// handled by conf.User.DeletionPolicy.
+	// It is more performant to skip rewriting the "authorized_keys" file for
+	// individual deletion in a batch operation.
 	DeleteByID(ctx context.Context, userID int64, skipRewriteAuthorizedKeys bool) error
 	// DeleteInactivated deletes all inactivated users.
 	DeleteInactivated() error
+	// Export writes a zip archive of data of the given user to the writer, which
+	// consists of the profile, email addresses, metadata of owned repositories,
+	// authored issues and comments, and SSH keys in JSON files.
+	Export(ctx context.Context, userID int64, w io.Writer) error
 
 	// AddEmail adds a new email address to given user. It returns
 	// ErrEmailAlreadyUsed if the email has been verified by another user.
@@ -236,7 +253,7 @@ func (db *users) Authenticate(ctx context.Context, login, password string, login
 	}
 
 	if !createNewUser {
-		return user, nil
+		return db.syncExternalAccount(ctx, user, extAccount)
 	}
 
 	// Validate username make sure it satisfies requirement.
@@ -244,7 +261,7 @@ func (db *users) Authenticate(ctx context.Context, login, password string, login
 		return nil, fmt.Errorf("invalid pattern for attribute 'username' [%s]: must be valid alpha or numeric or dash(-_) or dot characters", extAccount.Name)
 	}
 
-	return db.Create(ctx, extAccount.Name, extAccount.Email,
+	user, err = db.Create(ctx, extAccount.Name, extAccount.Email,
 		CreateUserOptions{
 			FullName:    extAccount.FullName,
 			LoginSource: authSourceID,
@@ -255,6 +272,115 @@ func (db *users) Authenticate(ctx context.Context, login, password string, login
 			Admin:       extAccount.Admin,
 		},
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply fields that are not covered by creation, e.g. the avatar URL.
+	return db.syncExternalAccount(ctx, user, extAccount)
+}
+
+func (db *users) AuthenticateExternal(ctx context.Context, loginSourceID int64, extAccount *auth.ExternalAccount) (*User, error) {
+	user := new(User)
+	err := db.WithContext(ctx).
+		Where("login_source = ? AND login_name = ?", loginSourceID, extAccount.Login).
+		First(user).Error
+	if err == nil {
+		return db.syncExternalAccount(ctx, user, extAccount)
+	} else if err != gorm.ErrRecordNotFound {
+		return nil, errors.Wrap(err, "get user by login name")
+	}
+
+	// Only link an existing user when the provider vouches for the ownership of
+	// the email address, otherwise anyone could take over the account by
+	// claiming the same email address.
+	email := strings.ToLower(strings.TrimSpace(extAccount.Email))
+	if email != "" && extAccount.EmailVerified {
+		user, err = db.GetByEmail(ctx, email)
+		if err == nil {
+			if !user.IsLocal() {
+				return nil, ErrEmailAlreadyUsed{args: errutil.Args{"email": email}}
+			}
+
+			err = db.Update(ctx, user.ID, UpdateUserOptions{
+				LoginSource: &loginSourceID,
+				LoginName:   &extAccount.Login,
+			})
+			if err != nil {
+				return nil, errors.Wrap(err, "link user")
+			}
+			user.LoginSource = loginSourceID
+			user.LoginName = extAccount.Login
+			return db.syncExternalAccount(ctx, user, extAccount)
+		} else if !IsErrUserNotExist(err) {
+			return nil, errors.Wrap(err, "get user by email")
+		}
+	}
+
+	// Validate username make sure it satisfies requirement.
+	if binding.AlphaDashDotPattern.MatchString(extAccount.Name) {
+		return nil, fmt.Errorf("invalid pattern for attribute 'username' [%s]: must be valid alpha or numeric or dash(-_) or dot characters", extAccount.Name)
+	}
+
+	user, err = db.Create(ctx, extAccount.Name, email,
+		CreateUserOptions{
+			FullName:    extAccount.FullName,
+			LoginSource: loginSourceID,
+			LoginName:   extAccount.Login,
+			Location:    extAccount.Location,
+			Website:     extAccount.Website,
+			Activated:   true,
+			Admin:       extAccount.Admin,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return db.syncExternalAccount(ctx, user, extAccount)
+}
+
+// syncExternalAccount updates the profile of the user with fields of the
+// external account that are marked to be synchronized, and returns the updated
+// user. It only writes to the database when any of the fields has changed.
+func (db *users) syncExternalAccount(ctx context.Context, user *User, extAccount *auth.ExternalAccount) (*User, error) {
+	var opts UpdateUserOptions
+	changed := false
+	if extAccount.Sync.FullName && extAccount.FullName != "" && extAccount.FullName != user.FullName {
+		opts.FullName = &extAccount.FullName
+		changed = true
+	}
+	if email := strings.ToLower(strings.TrimSpace(extAccount.Email)); extAccount.Sync.Email && email != "" && email != user.Email {
+		_, err := db.GetByEmail(ctx, email)
+		if err == nil {
+			log.Warn("Skipped syncing email of user %q from login source: %q is already used", user.Name, email)
+		} else if !IsErrUserNotExist(err) {
+			return nil, errors.Wrap(err, "check email")
+		} else {
+			opts.Email = &email
+			changed = true
+		}
+	}
+	if extAccount.Sync.Location && extAccount.Location != "" && extAccount.Location != user.Location {
+		opts.Location = &extAccount.Location
+		changed = true
+	}
+	if extAccount.Sync.AvatarURL && extAccount.AvatarURL != "" && extAccount.AvatarURL != user.Avatar {
+		opts.Avatar = &extAccount.AvatarURL
+		changed = true
+	}
+	if extAccount.Sync.Admin && extAccount.Admin != user.IsAdmin {
+		opts.IsAdmin = &extAccount.Admin
+		changed = true
+	}
+	if !changed {
+		return user, nil
+	}
+
+	err := db.Update(ctx, user.ID, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "update user")
+	}
+	return db.GetByID(ctx, user.ID)
 }
 
 func (db *users) ChangeUsername(ctx context.Context, userID int64, newUsername string) error {
@@ -420,20 +546,23 @@ func (db *users) Create(ctx context.Context, username, email string, opts Create
 	}
 
 	user := &User{
-		LowerName:       strings.ToLower(username),
-		Name:            username,
-		FullName:        opts.FullName,
-		Email:           email,
-		Password:        opts.Password,
-		LoginSource:     opts.LoginSource,
-		LoginName:       opts.LoginName,
-		Location:        opts.Location,
-		Website:         opts.Website,
-		MaxRepoCreation: -1,
-		IsActive:        opts.Activated,
-		IsAdmin:         opts.Admin,
-		Avatar:          cryptoutil.MD5(email), // Gravatar URL uses the MD5 hash of the email, see https://en.gravatar.com/site/implement/hash/
-		AvatarEmail:     email,
+		LowerName:        strings.ToLower(username),
+		Name:             username,
+		FullName:         opts.FullName,
+		Email:            email,
+		Password:         opts.Password,
+		LoginSource:      opts.LoginSource,
+		LoginName:        opts.LoginName,
+		Location:         opts.Location,
+		Website:          opts.Website,
+		MaxRepoCreation:  -1,
+		MaxCollaborators: -1,
+		MaxTeams:         -1,
+		MaxTeamMembers:   -1,
+		IsActive:         opts.Activated,
+		IsAdmin:          opts.Admin,
+		Avatar:           cryptoutil.MD5(email), // Gravatar URL uses the MD5 hash of the email, see https://en.gravatar.com/site/implement/hash/
+		AvatarEmail:      email,
 	}
 
 	user.Rands, err = userutil.RandomSalt()
@@ -446,7 +575,12 @@ func (db *users) Create(ctx context.Context, username, email string, opts Create
 	}
 	user.Password = userutil.EncodePassword(user.Password, user.Salt)
 
-	return user, db.WithContext(ctx).Create(user).Error
+	err = db.WithContext(ctx).Create(user).Error
+	if err != nil {
+		return nil, err
+	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionUserCreate, user.ID, user.Name, "")
+	return user, nil
 }
 
 func (db *users) DeleteCustomAvatar(ctx context.Context, userID int64) error {
@@ -476,21 +610,6 @@ func (err ErrUserOwnRepos) Error() string {
 	return fmt.Sprintf("user still has repository ownership: %v", err.args)
 }
 
-type ErrUserHasOrgs struct {
-	args errutil.Args
-}
-
-// IsErrUserHasOrgs returns true if the underlying error has the type
-// ErrUserHasOrgs.
-func IsErrUserHasOrgs(err error) bool {
-	_, ok := errors.Cause(err).(ErrUserHasOrgs)
-	return ok
-}
-
-func (err ErrUserHasOrgs) Error() string {
-	return fmt.Sprintf("user still has organization membership: %v", err.args)
-}
-
 func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthorizedKeys bool) error {
 	user, err := db.GetByID(ctx, userID)
 	if err != nil {
@@ -500,8 +619,8 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 		return errors.Wrap(err, "get user")
 	}
 
-	// Double check the user is not a direct owner of any repository and not a
-	// member of any organization.
+	// Double check the user is not a direct owner of any repository and not the
+	// last owner of any organization.
 	var count int64
 	err = db.WithContext(ctx).Model(&Repository{}).Where("owner_id = ?", userID).Count(&count).Error
 	if err != nil {
@@ -510,14 +629,40 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 		return ErrUserOwnRepos{args: errutil.Args{"userID": userID}}
 	}
 
-	err = db.WithContext(ctx).Model(&OrgUser{}).Where("uid = ?", userID).Count(&count).Error
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		SELECT COUNT(*) FROM team
+		WHERE name = @ownerTeam AND num_members <= 1 AND id IN (
+			SELECT team_id FROM team_user WHERE uid = @userID
+		)
+	*/
+	err = db.WithContext(ctx).Table("team").
+		Where("name = ? AND num_members <= 1 AND id IN (?)", OWNER_TEAM, db.
+			Select("team_id").
+			Table("team_user").
+			Where("uid = ?", userID),
+		).
+		Count(&count).
+		Error
 	if err != nil {
-		return errors.Wrap(err, "count organization membership")
+		return errors.Wrap(err, "count owned organizations")
 	} else if count > 0 {
-		return ErrUserHasOrgs{args: errutil.Args{"userID": userID}}
+		return ErrLastOrgOwner{UID: userID}
+	}
+
+	var orgIDs, teamIDs []int64
+	err = db.WithContext(ctx).Model(&OrgUser{}).Where("uid = ?", userID).Pluck("org_id", &orgIDs).Error
+	if err != nil {
+		return errors.Wrap(err, "list organizations")
+	}
+	err = db.WithContext(ctx).Model(&TeamUser{}).Where("uid = ?", userID).Pluck("team_id", &teamIDs).Error
+	if err != nil {
+		return errors.Wrap(err, "list teams")
 	}
 
 	needsRewriteAuthorizedKeys := false
+	var attachmentUUIDs []string
 	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		/*
 			Equivalent SQL for PostgreSQL:
@@ -603,6 +748,48 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 			return errors.Wrap(err, `decrease "user.num_following"`)
 		}
 
+		/*
+			Equivalent SQL for PostgreSQL:
+
+			UPDATE user
+			SET num_members = num_members - 1
+			WHERE id IN (
+				SELECT org_id FROM org_user WHERE uid = @userID
+			)
+		*/
+		err = tx.Table("user").
+			Where("id IN (?)", tx.
+				Select("org_id").
+				Table("org_user").
+				Where("uid = ?", userID),
+			).
+			UpdateColumn("num_members", gorm.Expr("num_members - 1")).
+			Error
+		if err != nil {
+			return errors.Wrap(err, `decrease "user.num_members"`)
+		}
+
+		/*
+			Equivalent SQL for PostgreSQL:
+
+			UPDATE team
+			SET num_members = num_members - 1
+			WHERE id IN (
+				SELECT team_id FROM team_user WHERE uid = @userID
+			)
+		*/
+		err = tx.Table("team").
+			Where("id IN (?)", tx.
+				Select("team_id").
+				Table("team_user").
+				Where("uid = ?", userID),
+			).
+			UpdateColumn("num_members", gorm.Expr("num_members - 1")).
+			Error
+		if err != nil {
+			return errors.Wrap(err, `decrease "team.num_members"`)
+		}
+
 		if !skipRewriteAuthorizedKeys {
 			// We need to rewrite "authorized_keys" file if the user owns any public keys.
 			needsRewriteAuthorizedKeys = tx.Where("owner_id = ?", userID).First(&PublicKey{}).Error != gorm.ErrRecordNotFound
@@ -613,6 +800,30 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 			return errors.Wrap(err, "clear assignees")
 		}
 
+		if conf.User.DeletionPolicy == conf.UserDeletionPolicyDelete {
+			attachmentUUIDs, err = deleteCommentsByPoster(tx, userID)
+			if err != nil {
+				return errors.Wrap(err, "delete comments")
+			}
+		}
+
+		// Issues, pull requests and the rest of comments are kept for the integrity
+		// of conversations in repositories, and attributed to the ghost user.
+		ghostID := NewGhostUser().ID
+		for _, t := range []struct {
+			table  any
+			column string
+		}{
+			{&Issue{}, "poster_id"},
+			{&Comment{}, "poster_id"},
+			{&CommentHistory{}, "editor_id"},
+		} {
+			err = tx.Model(t.table).Where(t.column+" = ?", userID).UpdateColumn(t.column, ghostID).Error
+			if err != nil {
+				return errors.Wrapf(err, "reassign table %T to the ghost user", t.table)
+			}
+		}
+
 		for _, t := range []struct {
 			table any
 			where string
@@ -624,10 +835,20 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 
 			{&AccessToken{}, "uid = @userID"},
 			{&Collaboration{}, "user_id = @userID"},
+			{&OrgUser{}, "uid = @userID"},
+			{&TeamUser{}, "uid = @userID"},
 			{&Access{}, "user_id = @userID"},
 			{&Action{}, "user_id = @userID"},
 			{&IssueUser{}, "uid = @userID"},
 			{&EmailAddress{}, "uid = @userID"},
+			{&UserSession{}, "user_id = @userID"},
+			{&OrgMirror{}, "org_id = @userID"},
+			{&CLASignature{}, "user_id = @userID"},
+			{&RepoInvitation{}, "invitee_id = @userID OR inviter_id = @userID"},
+			{&OrgInvitation{}, "org_id = @userID OR invitee_id = @userID OR inviter_id = @userID"},
+			{&IgnoredRepo{}, "user_id = @userID"},
+			{&ReviewRequest{}, "reviewer_id = @userID"},
+			{&Approval{}, "reviewer_id = @userID"},
 			{&User{}, "id = @userID"},
 		} {
 			err = tx.Where(t.where, sql.Named("userID", userID)).Delete(t.table).Error
@@ -640,9 +861,20 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 	if err != nil {
 		return err
 	}
+	recordAudit(ctx, NewAuditLogsStore(db.DB), AuditActionUserDelete, user.ID, user.Name, "")
+
+	for _, orgID := range orgIDs {
+		membershipCache.invalidateOrg(orgID, userID)
+	}
+	for _, teamID := range teamIDs {
+		membershipCache.invalidateTeam(teamID, userID)
+	}
 
 	_ = os.RemoveAll(repoutil.UserPath(user.Name))
 	_ = os.Remove(userutil.CustomAvatarPath(userID))
+	for _, uuid := range attachmentUUIDs {
+		_ = os.Remove(AttachmentLocalPath(uuid))
+	}
 
 	if needsRewriteAuthorizedKeys {
 		err = NewPublicKeysStore(db.DB).RewriteAuthorizedKeys()
@@ -653,6 +885,63 @@ func (db *users) DeleteByID(ctx context.Context, userID int64, skipRewriteAuthor
 	return nil
 }
 
+// deleteCommentsByPoster deletes all comments posted by the given user along
+// with their histories and attachments, and returns UUIDs of the deleted
+// attachments for removing files after the transaction is committed. Other types
+// of comments, e.g. closing and reopening an issue, are kept because they are
+// events of the issue.
+func deleteCommentsByPoster(tx *gorm.DB, userID int64) (attachmentUUIDs []string, err error) {
+	comments := tx.Select("id").
+		Table("comment").
+		Where("poster_id = ? AND type = ?", userID, COMMENT_TYPE_COMMENT)
+
+	/*
+		Equivalent SQL for PostgreSQL:
+
+		UPDATE issue
+		SET num_comments = num_comments - (
+			SELECT COUNT(*) FROM comment
+			WHERE comment.issue_id = issue.id AND poster_id = @userID AND type = @type
+		)
+		WHERE id IN (
+			SELECT issue_id FROM comment WHERE poster_id = @userID AND type = @type
+		)
+	*/
+	err = tx.Table("issue").
+		Where("id IN (?)", tx.
+			Select("issue_id").
+			Table("comment").
+			Where("poster_id = ? AND type = ?", userID, COMMENT_TYPE_COMMENT),
+		).
+		UpdateColumn("num_comments", gorm.Expr("num_comments - (?)", tx.
+			Select("COUNT(*)").
+			Table("comment").
+			Where("comment.issue_id = issue.id AND poster_id = ? AND type = ?", userID, COMMENT_TYPE_COMMENT),
+		)).
+		Error
+	if err != nil {
+		return nil, errors.Wrap(err, `decrease "issue.num_comments"`)
+	}
+
+	err = tx.Model(&Attachment{}).Where("comment_id IN (?)", comments).Pluck("uuid", &attachmentUUIDs).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "list attachments")
+	}
+
+	for _, table := range []any{&Attachment{}, &CommentHistory{}} {
+		err = tx.Where("comment_id IN (?)", comments).Delete(table).Error
+		if err != nil {
+			return nil, errors.Wrapf(err, "clean up table %T", table)
+		}
+	}
+
+	err = tx.Where("poster_id = ? AND type = ?", userID, COMMENT_TYPE_COMMENT).Delete(&Comment{}).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "delete comments")
+	}
+	return attachmentUUIDs, nil
+}
+
 // NOTE: We do not take context.Context here because this operation in practice
 // could much longer than the general request timeout (e.g. one minute).
 func (db *users) DeleteInactivated() error {
@@ -666,7 +955,7 @@ func (db *users) DeleteInactivated() error {
 		err = db.DeleteByID(context.Background(), userID, true)
 		if err != nil {
 			// Skip users that may had set to inactivated by admins.
-			if IsErrUserOwnRepos(err) || IsErrUserHasOrgs(err) {
+			if IsErrUserOwnRepos(err) || IsErrLastOrgOwner(err) {
 				continue
 			}
 			return errors.Wrapf(err, "delete user with ID %d", userID)
@@ -679,6 +968,201 @@ func (db *users) DeleteInactivated() error {
 	return nil
 }
 
+type exportedUser struct {
+	ID          int64     `json:"id"`
+	Username    string    `json:"username"`
+	FullName    string    `json:"full_name"`
+	Email       string    `json:"email"`
+	Location    string    `json:"location"`
+	Website     string    `json:"website"`
+	Description string    `json:"description"`
+	Created     time.Time `json:"created_at"`
+	Updated     time.Time `json:"updated_at"`
+}
+
+type exportedEmailAddress struct {
+	Email       string `json:"email"`
+	IsActivated bool   `json:"is_activated"`
+}
+
+type exportedRepository struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Description   string    `json:"description"`
+	Website       string    `json:"website"`
+	DefaultBranch string    `json:"default_branch"`
+	IsPrivate     bool      `json:"is_private"`
+	IsFork        bool      `json:"is_fork"`
+	Created       time.Time `json:"created_at"`
+	Updated       time.Time `json:"updated_at"`
+}
+
+type exportedIssue struct {
+	ID       int64     `json:"id"`
+	RepoID   int64     `json:"repo_id"`
+	Index    int64     `json:"index"`
+	Title    string    `json:"title"`
+	Content  string    `json:"content"`
+	IsPull   bool      `json:"is_pull"`
+	IsClosed bool      `json:"is_closed"`
+	Created  time.Time `json:"created_at"`
+	Updated  time.Time `json:"updated_at"`
+}
+
+type exportedComment struct {
+	ID      int64     `json:"id"`
+	IssueID int64     `json:"issue_id"`
+	Content string    `json:"content"`
+	Created time.Time `json:"created_at"`
+	Updated time.Time `json:"updated_at"`
+}
+
+type exportedPublicKey struct {
+	Name        string    `json:"name"`
+	Fingerprint string    `json:"fingerprint"`
+	Content     string    `json:"content"`
+	Created     time.Time `json:"created_at"`
+}
+
+func (db *users) Export(ctx context.Context, userID int64, w io.Writer) error {
+	user, err := db.GetByID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, "get user")
+	}
+
+	var emails []*EmailAddress
+	err = db.WithContext(ctx).Where("uid = ?", userID).Order("id ASC").Find(&emails).Error
+	if err != nil {
+		return errors.Wrap(err, "list email addresses")
+	}
+	var repos []*Repository
+	err = db.WithContext(ctx).Where("owner_id = ?", userID).Order("id ASC").Find(&repos).Error
+	if err != nil {
+		return errors.Wrap(err, "list repositories")
+	}
+	var issues []*Issue
+	err = db.WithContext(ctx).Where("poster_id = ?", userID).Order("id ASC").Find(&issues).Error
+	if err != nil {
+		return errors.Wrap(err, "list issues")
+	}
+	var comments []*Comment
+	err = db.WithContext(ctx).
+		Where("poster_id = ? AND type = ?", userID, COMMENT_TYPE_COMMENT).
+		Order("id ASC").
+		Find(&comments).
+		Error
+	if err != nil {
+		return errors.Wrap(err, "list comments")
+	}
+	var keys []*PublicKey
+	err = db.WithContext(ctx).Where("owner_id = ?", userID).Order("id ASC").Find(&keys).Error
+	if err != nil {
+		return errors.Wrap(err, "list public keys")
+	}
+
+	unix := func(t int64) time.Time { return time.Unix(t, 0).UTC() }
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"profile.json", &exportedUser{
+			ID:          user.ID,
+			Username:    user.Name,
+			FullName:    user.FullName,
+			Email:       user.Email,
+			Location:    user.Location,
+			Website:     user.Website,
+			Description: user.Description,
+			Created:     unix(user.CreatedUnix),
+			Updated:     unix(user.UpdatedUnix),
+		}},
+		{"emails.json", func() []*exportedEmailAddress {
+			vs := make([]*exportedEmailAddress, 0, len(emails))
+			for _, e := range emails {
+				vs = append(vs, &exportedEmailAddress{Email: e.Email, IsActivated: e.IsActivated})
+			}
+			return vs
+		}()},
+		{"repositories.json", func() []*exportedRepository {
+			vs := make([]*exportedRepository, 0, len(repos))
+			for _, r := range repos {
+				vs = append(vs, &exportedRepository{
+					ID:            r.ID,
+					Name:          r.Name,
+					Description:   r.Description,
+					Website:       r.Website,
+					DefaultBranch: r.DefaultBranch,
+					IsPrivate:     r.IsPrivate,
+					IsFork:        r.IsFork,
+					Created:       unix(r.CreatedUnix),
+					Updated:       unix(r.UpdatedUnix),
+				})
+			}
+			return vs
+		}()},
+		{"issues.json", func() []*exportedIssue {
+			vs := make([]*exportedIssue, 0, len(issues))
+			for _, i := range issues {
+				vs = append(vs, &exportedIssue{
+					ID:       i.ID,
+					RepoID:   i.RepoID,
+					Index:    i.Index,
+					Title:    i.Title,
+					Content:  i.Content,
+					IsPull:   i.IsPull,
+					IsClosed: i.IsClosed,
+					Created:  unix(i.CreatedUnix),
+					Updated:  unix(i.UpdatedUnix),
+				})
+			}
+			return vs
+		}()},
+		{"comments.json", func() []*exportedComment {
+			vs := make([]*exportedComment, 0, len(comments))
+			for _, c := range comments {
+				vs = append(vs, &exportedComment{
+					ID:      c.ID,
+					IssueID: c.IssueID,
+					Content: c.Content,
+					Created: unix(c.CreatedUnix),
+					Updated: unix(c.UpdatedUnix),
+				})
+			}
+			return vs
+		}()},
+		{"ssh_keys.json", func() []*exportedPublicKey {
+			vs := make([]*exportedPublicKey, 0, len(keys))
+			for _, k := range keys {
+				vs = append(vs, &exportedPublicKey{
+					Name:        k.Name,
+					Fingerprint: k.Fingerprint,
+					Content:     k.Content,
+					Created:     unix(k.CreatedUnix),
+				})
+			}
+			return vs
+		}()},
+	}
+
+	zw := zip.NewWriter(w)
+	for _, f := range files {
+		data, err := jsoniter.MarshalIndent(f.v, "", "  ")
+		if err != nil {
+			return errors.Wrapf(err, "marshal %q", f.name)
+		}
+
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return errors.Wrapf(err, "create %q", f.name)
+		}
+		_, err = fw.Write(data)
+		if err != nil {
+			return errors.Wrapf(err, "write %q", f.name)
+		}
+	}
+	return zw.Close()
+}
+
 func (*users) recountFollows(tx *gorm.DB, userID, followID int64) error {
 	/*
 		Equivalent SQL for PostgreSQL:
@@ -788,6 +1272,21 @@ func (db *users) GetByEmail(ctx context.Context, email string) (*User, error) {
 	}
 	email = strings.ToLower(email)
 
+	if userID, name, ok := userutil.ParseNoReplyEmail(email); ok {
+		user := new(User)
+		err := db.WithContext(ctx).
+			Where("id = ? AND lower_name = ? AND type = ?", userID, name, UserTypeIndividual).
+			First(user).
+			Error
+		if err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil, ErrUserNotExist{args: errutil.Args{"email": email}}
+			}
+			return nil, err
+		}
+		return user, nil
+	}
+
 	/*
 		Equivalent SQL for PostgreSQL:
 
@@ -967,8 +1466,36 @@ type UpdateUserOptions struct {
 	Description *string
 
 	MaxRepoCreation    *int
+	MaxCollaborators   *int
+	MaxTeams           *int
+	MaxTeamMembers     *int
 	LastRepoVisibility *bool
 
+	DefaultRepoGitignores *string
+	DefaultRepoLicense    *string
+	DefaultRepoReadme     *string
+	ForcePrivateRepos     *bool
+	RestrictPrivateForks  *bool
+	BranchNamePattern     *string
+
+	CommitMessagePattern      *string
+	CommitMessageExemptMerges *bool
+
+	PushMaxSize             *int64
+	PushMaxBlobSize         *int64
+	PushBlockedFilePatterns *string
+
+	RequireMemberInvitation *bool
+
+	DefaultBranchProtection *DefaultBranchProtection
+
+	AutoWatchOnCreate  *AutoWatchPreference
+	AutoWatchOnPush    *AutoWatchPreference
+	AutoWatchOnComment *AutoWatchPreference
+
+	KeepEmailPrivate *bool
+	AutoAssignOptOut *bool
+
 	IsActivated      *bool
 	IsAdmin          *bool
 	AllowGitHook     *bool
@@ -980,6 +1507,16 @@ type UpdateUserOptions struct {
 }
 
 func (db *users) Update(ctx context.Context, userID int64, opts UpdateUserOptions) error {
+	// Changes of the admin privilege need to be audited.
+	var user *User
+	if opts.IsAdmin != nil {
+		var err error
+		user, err = db.GetByID(ctx, userID)
+		if err != nil {
+			return errors.Wrap(err, "get user")
+		}
+	}
+
 	updates := map[string]any{
 		"updated_unix": db.NowFunc().Unix(),
 	}
@@ -1036,10 +1573,85 @@ func (db *users) Update(ctx context.Context, userID int64, opts UpdateUserOption
 		}
 		updates["max_repo_creation"] = *opts.MaxRepoCreation
 	}
+	if opts.MaxCollaborators != nil {
+		if *opts.MaxCollaborators < -1 {
+			*opts.MaxCollaborators = -1
+		}
+		updates["max_collaborators"] = *opts.MaxCollaborators
+	}
+	if opts.MaxTeams != nil {
+		if *opts.MaxTeams < -1 {
+			*opts.MaxTeams = -1
+		}
+		updates["max_teams"] = *opts.MaxTeams
+	}
+	if opts.MaxTeamMembers != nil {
+		if *opts.MaxTeamMembers < -1 {
+			*opts.MaxTeamMembers = -1
+		}
+		updates["max_team_members"] = *opts.MaxTeamMembers
+	}
 	if opts.LastRepoVisibility != nil {
 		updates["last_repo_visibility"] = *opts.LastRepoVisibility
 	}
 
+	if opts.DefaultRepoGitignores != nil {
+		updates["default_repo_gitignores"] = *opts.DefaultRepoGitignores
+	}
+	if opts.DefaultRepoLicense != nil {
+		updates["default_repo_license"] = *opts.DefaultRepoLicense
+	}
+	if opts.DefaultRepoReadme != nil {
+		updates["default_repo_readme"] = *opts.DefaultRepoReadme
+	}
+	if opts.ForcePrivateRepos != nil {
+		updates["force_private_repos"] = *opts.ForcePrivateRepos
+	}
+	if opts.RestrictPrivateForks != nil {
+		updates["restrict_private_forks"] = *opts.RestrictPrivateForks
+	}
+	if opts.BranchNamePattern != nil {
+		updates["branch_name_pattern"] = *opts.BranchNamePattern
+	}
+	if opts.CommitMessagePattern != nil {
+		updates["commit_message_pattern"] = *opts.CommitMessagePattern
+	}
+	if opts.CommitMessageExemptMerges != nil {
+		updates["commit_message_exempt_merges"] = *opts.CommitMessageExemptMerges
+	}
+	if opts.PushMaxSize != nil {
+		updates["push_max_size"] = *opts.PushMaxSize
+	}
+	if opts.PushMaxBlobSize != nil {
+		updates["push_max_blob_size"] = *opts.PushMaxBlobSize
+	}
+	if opts.PushBlockedFilePatterns != nil {
+		updates["push_blocked_file_patterns"] = *opts.PushBlockedFilePatterns
+	}
+	if opts.RequireMemberInvitation != nil {
+		updates["require_member_invitation"] = *opts.RequireMemberInvitation
+	}
+	if opts.DefaultBranchProtection != nil {
+		updates["default_branch_protection"] = *opts.DefaultBranchProtection
+	}
+
+	if opts.AutoWatchOnCreate != nil {
+		updates["auto_watch_on_create"] = *opts.AutoWatchOnCreate
+	}
+	if opts.AutoWatchOnPush != nil {
+		updates["auto_watch_on_push"] = *opts.AutoWatchOnPush
+	}
+	if opts.AutoWatchOnComment != nil {
+		updates["auto_watch_on_comment"] = *opts.AutoWatchOnComment
+	}
+
+	if opts.KeepEmailPrivate != nil {
+		updates["keep_email_private"] = *opts.KeepEmailPrivate
+	}
+	if opts.AutoAssignOptOut != nil {
+		updates["auto_assign_opt_out"] = *opts.AutoAssignOptOut
+	}
+
 	if opts.IsActivated != nil {
 		updates["is_active"] = *opts.IsActivated
 	}
@@ -1063,7 +1675,19 @@ func (db *users) Update(ctx context.Context, userID int64, opts UpdateUserOption
 		updates["avatar_email"] = strutil.Truncate(*opts.AvatarEmail, 255)
 	}
 
-	return db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+	err := db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+	if err != nil {
+		return err
+	}
+
+	if user != nil && user.IsAdmin != *opts.IsAdmin {
+		action := AuditActionUserRevokeAdmin
+		if *opts.IsAdmin {
+			action = AuditActionUserGrantAdmin
+		}
+		recordAudit(ctx, NewAuditLogsStore(db.DB), action, user.ID, user.Name, "")
+	}
+	return nil
 }
 
 func (db *users) UseCustomAvatar(ctx context.Context, userID int64, avatar []byte) error {
@@ -1293,6 +1917,13 @@ type User struct {
 	LastRepoVisibility bool
 	// Maximum repository creation limit, -1 means use global default
 	MaxRepoCreation int `xorm:"NOT NULL DEFAULT -1" gorm:"not null;default:-1"`
+	// Maximum number of collaborators of each owned repository, -1 means use
+	// global default
+	MaxCollaborators int `xorm:"NOT NULL DEFAULT -1" gorm:"not null;default:-1"`
+	// Maximum number of teams of the organization and members of each team, -1
+	// means use global default
+	MaxTeams       int `xorm:"NOT NULL DEFAULT -1" gorm:"not null;default:-1"`
+	MaxTeamMembers int `xorm:"NOT NULL DEFAULT -1" gorm:"not null;default:-1"`
 
 	// Permissions
 	IsActive         bool // Activate primary email
@@ -1318,6 +1949,72 @@ type User struct {
 	NumMembers  int
 	Teams       []*Team `xorm:"-" gorm:"-" json:"-"`
 	Members     []*User `xorm:"-" gorm:"-" json:"-"`
+
+	// The .gitignore, license and README templates pre-selected when creating a
+	// repository owned by the organization, empty means use global default
+	DefaultRepoGitignores string
+	DefaultRepoLicense    string
+	DefaultRepoReadme     string
+	// Whether repositories owned by the organization are forced to be private
+	ForcePrivateRepos bool
+	// Whether private repositories owned by the organization can only be forked
+	// by its members to their own accounts
+	RestrictPrivateForks bool
+	// The regular expression that names of new branches of repositories owned by
+	// the organization must match by default, empty means no restriction
+	BranchNamePattern string `xorm:"VARCHAR(255)" gorm:"type:VARCHAR(255)"`
+	// The regular expression that subjects of pushed commit messages of
+	// repositories owned by the organization must match by default, empty means
+	// no restriction. Merge commits are not checked when exempted.
+	CommitMessagePattern      string `xorm:"VARCHAR(255)" gorm:"type:VARCHAR(255)"`
+	CommitMessageExemptMerges bool
+	// Limits of objects introduced by pushes to repositories owned by the
+	// organization by default, zero values mean use the server defaults. Sizes
+	// are in MB, and file patterns are comma separated.
+	PushMaxSize             int64  `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	PushMaxBlobSize         int64  `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	PushBlockedFilePatterns string `xorm:"VARCHAR(1024)" gorm:"type:VARCHAR(1024)"`
+	// Whether adding a member to the organization sends an invitation that the
+	// user needs to accept, instead of adding the membership immediately
+	RequireMemberInvitation bool
+	// Whether to protect the default branch of new repositories owned by the
+	// organization
+	DefaultBranchProtection DefaultBranchProtection `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+
+	// Whether to watch automatically the repositories created by the user, pushed
+	// to by the user, and the issues commented on by the user
+	AutoWatchOnCreate  AutoWatchPreference `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	AutoWatchOnPush    AutoWatchPreference `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+	AutoWatchOnComment AutoWatchPreference `xorm:"NOT NULL DEFAULT 0" gorm:"not null;default:0"`
+
+	// Whether to hide the email address of the user from others and to use the
+	// noreply email address as the author of commits made via the web UI and API
+	KeepEmailPrivate bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+	// Whether to be excluded from automatic assignment of new issues
+	AutoAssignOptOut bool `xorm:"NOT NULL DEFAULT false" gorm:"not null;default:FALSE"`
+}
+
+// AutoWatchPreference is the preference of a user about whether to watch
+// automatically.
+type AutoWatchPreference int
+
+const (
+	AutoWatchDefault  AutoWatchPreference = iota // Use the global default
+	AutoWatchEnabled                             // Always watch automatically
+	AutoWatchDisabled                            // Never watch automatically
+)
+
+// IsEnabled returns true if the preference is enabled, or the global default
+// is enabled when the preference is not set.
+func (p AutoWatchPreference) IsEnabled(globalDefault bool) bool {
+	switch p {
+	case AutoWatchEnabled:
+		return true
+	case AutoWatchDisabled:
+		return false
+	default:
+		return globalDefault
+	}
 }
 
 // BeforeCreate implements the GORM create hook.
@@ -1368,9 +2065,121 @@ func (u *User) maxNumRepos() int {
 	return u.MaxRepoCreation
 }
 
-// canCreateRepo returns true if the user can create a repository.
-func (u *User) canCreateRepo() bool {
-	return u.maxNumRepos() <= -1 || u.NumRepos < u.maxNumRepos()
+// maxNumCollaborators returns the maximum number of collaborators that each
+// repository owned by the user can have.
+func (u *User) maxNumCollaborators() int {
+	if u.MaxCollaborators <= -1 {
+		return conf.Repository.MaxCollaborators
+	}
+	return u.MaxCollaborators
+}
+
+// maxNumTeams returns the maximum number of teams that the organization can
+// have, including the owners team.
+func (u *User) maxNumTeams() int {
+	if u.MaxTeams <= -1 {
+		return conf.Organization.MaxTeams
+	}
+	return u.MaxTeams
+}
+
+// maxNumTeamMembers returns the maximum number of members that each team of the
+// organization can have.
+func (u *User) maxNumTeamMembers() int {
+	if u.MaxTeamMembers <= -1 {
+		return conf.Organization.MaxTeamMembers
+	}
+	return u.MaxTeamMembers
+}
+
+// IsAutoWatchOnCreate returns true if the user automatically watches the
+// repositories they create.
+func (u *User) IsAutoWatchOnCreate() bool {
+	return u.AutoWatchOnCreate.IsEnabled(conf.User.AutoWatchOnCreate)
+}
+
+// IsAutoWatchOnPush returns true if the user automatically watches the
+// repositories they push to.
+func (u *User) IsAutoWatchOnPush() bool {
+	return u.AutoWatchOnPush.IsEnabled(conf.User.AutoWatchOnPush)
+}
+
+// IsAutoWatchOnComment returns true if the user automatically watches the
+// issues they comment on.
+func (u *User) IsAutoWatchOnComment() bool {
+	return u.AutoWatchOnComment.IsEnabled(conf.User.AutoWatchOnComment)
+}
+
+// NoReplyEmail returns the noreply email address of the user.
+func (u *User) NoReplyEmail() string {
+	return userutil.NoReplyEmail(u.ID, u.Name)
+}
+
+// CommitEmail returns the email address to be used as the author of commits
+// made by the user via the web UI and API, which is the noreply email address
+// if the user keeps their email address private.
+func (u *User) CommitEmail() string {
+	if u.KeepEmailPrivate {
+		return u.NoReplyEmail()
+	}
+	return u.Email
+}
+
+// RepoInitDefaults returns the .gitignore, license and README templates
+// pre-selected when creating a repository owned by the user. Organizations may
+// override the global defaults.
+func (u *User) RepoInitDefaults() (gitignores, license, readme string) {
+	gitignores = strings.Join(conf.Repository.DefaultGitignores, ",")
+	license = conf.Repository.DefaultLicense
+	readme = conf.Repository.DefaultReadme
+	if !u.IsOrganization() {
+		return gitignores, license, readme
+	}
+
+	if u.DefaultRepoGitignores != "" {
+		gitignores = u.DefaultRepoGitignores
+	}
+	if u.DefaultRepoLicense != "" {
+		license = u.DefaultRepoLicense
+	}
+	if u.DefaultRepoReadme != "" {
+		readme = u.DefaultRepoReadme
+	}
+	return gitignores, license, readme
+}
+
+// IsPublicRepoAllowed returns true if the user is allowed to own public
+// repositories, which may be restricted either server-wide or by the
+// organization.
+func (u *User) IsPublicRepoAllowed() bool {
+	if conf.Repository.ForcePrivate {
+		return false
+	}
+	return !(u.IsOrganization() && u.ForcePrivateRepos)
+}
+
+// canCreateRepo returns true if the user can create a repository by the doer,
+// which is a fork when isFork is true. Site admins are exempt from the limit.
+// Forks are not counted toward nor limited by the limit when
+// conf.Repository.ExcludeForksFromCreationLimit is enabled.
+func (u *User) canCreateRepo(e Engine, doer *User, isFork bool) (bool, error) {
+	if u.maxNumRepos() <= -1 || (doer != nil && doer.IsAdmin) {
+		return true, nil
+	}
+
+	numRepos := int64(u.NumRepos)
+	if conf.Repository.ExcludeForksFromCreationLimit {
+		if isFork {
+			return true, nil
+		}
+
+		var err error
+		numRepos, err = e.Where("owner_id = ? AND is_fork = ?", u.ID, false).Count(new(Repository))
+		if err != nil {
+			return false, fmt.Errorf("count non-fork repositories: %v", err)
+		}
+	}
+	return numRepos < int64(u.maxNumRepos()), nil
 }
 
 // CanCreateOrganization returns true if user can create organizations.
@@ -1434,6 +2243,9 @@ func (u *User) AvatarURLPath() string {
 			return defaultURLPath
 		}
 		return fmt.Sprintf("%s/%s/%d", conf.Server.Subpath, conf.UsersAvatarPathPrefix, u.ID)
+	case strings.HasPrefix(u.Avatar, "http://") || strings.HasPrefix(u.Avatar, "https://"):
+		// Set by the login source, e.g. LDAP
+		return u.Avatar
 	case conf.Picture.DisableGravatar:
 		if !hasCustomAvatar {
 			if err := userutil.GenerateRandomAvatar(u.ID, u.Name, u.Email); err != nil {
@@ -1529,6 +2341,7 @@ var (
 		"debug":    {},
 		"raw":      {},
 		"install":  {},
+		"metrics":  {},
 		"api":      {},
 		"avatar":   {},
 		"user":     {},
@@ -5,8 +5,11 @@
 package db
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -87,7 +90,8 @@ func TestUsers(t *testing.T) {
 	tables := []any{
 		new(User), new(EmailAddress), new(Repository), new(Follow), new(PullRequest), new(PublicKey), new(OrgUser),
 		new(Watch), new(Star), new(Issue), new(AccessToken), new(Collaboration), new(Action), new(IssueUser),
-		new(Access),
+		new(Access), new(Comment), new(CommentHistory), new(Attachment), new(UserSession), new(OrgMirror), new(CLASignature), new(RepoInvitation), new(OrgInvitation), new(IgnoredRepo), new(ReviewRequest), new(Approval),
+		new(AuditLog), new(Team), new(TeamUser),
 	}
 	db := &users{
 		DB: dbtest.NewDB(t, "users", tables...),
@@ -98,12 +102,15 @@ func TestUsers(t *testing.T) {
 		test func(t *testing.T, db *users)
 	}{
 		{"Authenticate", usersAuthenticate},
+		{"AuthenticateExternal", usersAuthenticateExternal},
 		{"ChangeUsername", usersChangeUsername},
 		{"Count", usersCount},
 		{"Create", usersCreate},
 		{"DeleteCustomAvatar", usersDeleteCustomAvatar},
 		{"DeleteByID", usersDeleteByID},
+		{"DeleteByIDWithDeletionPolicy", usersDeleteByIDWithDeletionPolicy},
 		{"DeleteInactivated", usersDeleteInactivated},
+		{"Export", usersExport},
 		{"GetByEmail", usersGetByEmail},
 		{"GetByID", usersGetByID},
 		{"GetByUsername", usersGetByUsername},
@@ -234,6 +241,183 @@ func usersAuthenticate(t *testing.T, db *users) {
 		require.NoError(t, err)
 		assert.Equal(t, "cindy@example.com", user.Email)
 	})
+
+	t.Run("sync existing user via login source", func(t *testing.T) {
+		extAccount := &auth.ExternalAccount{
+			Name:      "dan",
+			FullName:  "Dan Doe",
+			Email:     "dan@example.com",
+			Location:  "Earth",
+			AvatarURL: "https://example.com/dan.png",
+			Admin:     true,
+			Sync: auth.ExternalAccountSync{
+				FullName:  true,
+				Email:     true,
+				Location:  true,
+				AvatarURL: true,
+				Admin:     true,
+			},
+		}
+		mockLoginSources := NewMockLoginSourcesStore()
+		mockLoginSources.GetByIDFunc.SetDefaultHook(func(ctx context.Context, id int64) (*LoginSource, error) {
+			mockProvider := NewMockProvider()
+			mockProvider.AuthenticateFunc.SetDefaultReturn(extAccount, nil)
+			s := &LoginSource{
+				IsActived: true,
+				Provider:  mockProvider,
+			}
+			return s, nil
+		})
+		setMockLoginSourcesStore(t, mockLoginSources)
+
+		dan, err := db.Create(ctx, "dan", "dan@old.example.com",
+			CreateUserOptions{
+				FullName:    "Dan",
+				LoginSource: 1,
+			},
+		)
+		require.NoError(t, err)
+		assert.False(t, dan.IsAdmin)
+
+		user, err := db.Authenticate(ctx, dan.Name, password, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Dan Doe", user.FullName)
+		assert.Equal(t, "dan@example.com", user.Email)
+		assert.Equal(t, "Earth", user.Location)
+		assert.Equal(t, "https://example.com/dan.png", user.AvatarURLPath())
+		assert.True(t, user.IsAdmin)
+
+		// Leaving the admin group revokes the admin while other fields are kept
+		extAccount.Admin = false
+		extAccount.FullName = ""
+		user, err = db.Authenticate(ctx, dan.Name, password, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Dan Doe", user.FullName)
+		assert.False(t, user.IsAdmin)
+	})
+}
+
+func usersAuthenticateExternal(t *testing.T, db *users) {
+	ctx := context.Background()
+
+	const loginSourceID = 7
+	alice, err := db.Create(ctx, "alice", "alice@example.com",
+		CreateUserOptions{
+			Activated: true,
+		},
+	)
+	require.NoError(t, err)
+	_, err = db.Create(ctx, "bob", "bob@example.com",
+		CreateUserOptions{
+			LoginSource: 3,
+			LoginName:   "bob",
+			Activated:   true,
+		},
+	)
+	require.NoError(t, err)
+
+	t.Run("not linked with unverified email", func(t *testing.T) {
+		_, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login: "alice-subject",
+				Name:  "alice2",
+				Email: alice.Email,
+			},
+		)
+		wantErr := ErrEmailAlreadyUsed{args: errutil.Args{"email": alice.Email}}
+		assert.Equal(t, wantErr, err)
+
+		got, err := db.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.True(t, got.IsLocal())
+	})
+
+	t.Run("link by verified email", func(t *testing.T) {
+		user, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login:         "alice-subject",
+				Name:          "alice-sso",
+				FullName:      "Alice",
+				Email:         "Alice@Example.com",
+				EmailVerified: true,
+				Sync: auth.ExternalAccountSync{
+					FullName: true,
+				},
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, user.ID)
+		assert.Equal(t, "alice", user.Name)
+		assert.Equal(t, "Alice", user.FullName)
+
+		got, err := db.GetByID(ctx, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(loginSourceID), got.LoginSource)
+		assert.Equal(t, "alice-subject", got.LoginName)
+	})
+
+	t.Run("link by subject", func(t *testing.T) {
+		user, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login: "alice-subject",
+				Name:  "whoever",
+				Email: "alice@new.example.com",
+				Admin: true,
+				Sync: auth.ExternalAccountSync{
+					Email: true,
+					Admin: true,
+				},
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, alice.ID, user.ID)
+		assert.Equal(t, "alice@new.example.com", user.Email)
+		assert.True(t, user.IsAdmin)
+	})
+
+	t.Run("email used by another login source", func(t *testing.T) {
+		_, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login:         "bob-subject",
+				Name:          "bob-sso",
+				Email:         "bob@example.com",
+				EmailVerified: true,
+			},
+		)
+		wantErr := ErrEmailAlreadyUsed{args: errutil.Args{"email": "bob@example.com"}}
+		assert.Equal(t, wantErr, err)
+	})
+
+	t.Run("invalid username", func(t *testing.T) {
+		_, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login: "cindy-subject",
+				Name:  "cindy@example.com",
+			},
+		)
+		assert.Error(t, err)
+	})
+
+	t.Run("create new user", func(t *testing.T) {
+		user, err := db.AuthenticateExternal(ctx, loginSourceID,
+			&auth.ExternalAccount{
+				Login:         "cindy-subject",
+				Name:          "cindy",
+				FullName:      "Cindy",
+				Email:         "cindy@example.com",
+				EmailVerified: true,
+				Admin:         true,
+			},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "cindy", user.Name)
+		assert.Equal(t, "Cindy", user.FullName)
+		assert.Equal(t, "cindy@example.com", user.Email)
+		assert.Equal(t, int64(loginSourceID), user.LoginSource)
+		assert.Equal(t, "cindy-subject", user.LoginName)
+		assert.True(t, user.IsActive)
+		assert.True(t, user.IsAdmin)
+	})
 }
 
 func usersChangeUsername(t *testing.T, db *users) {
@@ -481,6 +665,7 @@ func usersDeleteCustomAvatar(t *testing.T, db *users) {
 
 func usersDeleteByID(t *testing.T, db *users) {
 	ctx := context.Background()
+	conf.SetMockUser(t, conf.UserOpts{AutoWatchOnCreate: true})
 	reposStore := NewReposStore(db.DB)
 
 	t.Run("user still has repository ownership", func(t *testing.T) {
@@ -495,25 +680,36 @@ func usersDeleteByID(t *testing.T, db *users) {
 		assert.Equal(t, wantErr, err)
 	})
 
-	t.Run("user still has organization membership", func(t *testing.T) {
-		bob, err := db.Create(ctx, "bob", "bob@exmaple.com", CreateUserOptions{})
-		require.NoError(t, err)
-
-		// TODO: Use Orgs.Create to replace SQL hack when the method is available.
-		org1, err := db.Create(ctx, "org1", "org1@example.com", CreateUserOptions{})
+	// TODO: Use Orgs.Create and Orgs.Join to replace SQL hack when the methods are
+	// available.
+	newOrg := func(t *testing.T, name string, owners ...*User) (*User, *Team) {
+		org, err := db.Create(ctx, name, name+"@example.com", CreateUserOptions{})
 		require.NoError(t, err)
 		err = db.Exec(
-			dbutil.Quote("UPDATE %s SET type = ? WHERE id IN (?)", "user"),
-			UserTypeOrganization, org1.ID,
+			dbutil.Quote("UPDATE %s SET type = ?, num_members = ? WHERE id IN (?)", "user"),
+			UserTypeOrganization, len(owners), org.ID,
 		).Error
 		require.NoError(t, err)
 
-		// TODO: Use Orgs.Join to replace SQL hack when the method is available.
-		err = db.Exec(`INSERT INTO org_user (uid, org_id) VALUES (?, ?)`, bob.ID, org1.ID).Error
+		team := &Team{OrgID: org.ID, LowerName: "owners", Name: OWNER_TEAM, Authorize: AccessModeOwner, NumMembers: len(owners)}
+		err = db.DB.Create(team).Error
+		require.NoError(t, err)
+		for _, owner := range owners {
+			err = db.DB.Create(&OrgUser{Uid: owner.ID, OrgID: org.ID, IsOwner: true, NumTeams: 1}).Error
+			require.NoError(t, err)
+			err = db.DB.Create(&TeamUser{OrgID: org.ID, TeamID: team.ID, UID: owner.ID}).Error
+			require.NoError(t, err)
+		}
+		return org, team
+	}
+
+	t.Run("user is the last owner of an organization", func(t *testing.T) {
+		bob, err := db.Create(ctx, "bob", "bob@exmaple.com", CreateUserOptions{})
 		require.NoError(t, err)
+		_, _ = newOrg(t, "org1", bob)
 
 		err = db.DeleteByID(ctx, bob.ID, false)
-		wantErr := ErrUserHasOrgs{errutil.Args{"userID": bob.ID}}
+		wantErr := ErrLastOrgOwner{UID: bob.ID}
 		assert.Equal(t, wantErr, err)
 	})
 
@@ -527,6 +723,9 @@ func usersDeleteByID(t *testing.T, db *users) {
 	testUser, err := db.Create(ctx, "testUser", "testUser@exmaple.com", CreateUserOptions{})
 	require.NoError(t, err)
 
+	// Mock organization membership
+	org2, ownerTeam := newOrg(t, "org2", cindy, testUser)
+
 	// Mock watches, stars and follows
 	err = reposStore.Watch(ctx, testUser.ID, repo2.ID)
 	require.NoError(t, err)
@@ -572,6 +771,14 @@ func usersDeleteByID(t *testing.T, db *users) {
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
+		&UserSession{UserID: testUser.ID},
+		&OrgMirror{OrgID: testUser.ID},
+		&CLASignature{UserID: testUser.ID},
+		&RepoInvitation{InviteeID: testUser.ID},
+		&OrgInvitation{InviteeID: testUser.ID},
+		&IgnoredRepo{UserID: testUser.ID},
+		&ReviewRequest{ReviewerID: testUser.ID},
+		&Approval{ReviewerID: testUser.ID},
 	} {
 		err = db.DB.Create(table).Error
 		require.NoError(t, err, "table for %T", table)
@@ -623,10 +830,20 @@ func usersDeleteByID(t *testing.T, db *users) {
 		&PublicKey{OwnerID: testUser.ID},
 		&AccessToken{UserID: testUser.ID},
 		&Collaboration{UserID: testUser.ID},
+		&OrgUser{Uid: testUser.ID},
+		&TeamUser{UID: testUser.ID},
 		&Access{UserID: testUser.ID},
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
+		&UserSession{UserID: testUser.ID},
+		&OrgMirror{OrgID: testUser.ID},
+		&CLASignature{UserID: testUser.ID},
+		&RepoInvitation{InviteeID: testUser.ID},
+		&OrgInvitation{InviteeID: testUser.ID},
+		&IgnoredRepo{UserID: testUser.ID},
+		&ReviewRequest{ReviewerID: testUser.ID},
+		&Approval{ReviewerID: testUser.ID},
 	}
 	for _, table := range relatedTables {
 		var count int64
@@ -639,7 +856,8 @@ func usersDeleteByID(t *testing.T, db *users) {
 	assert.True(t, osutil.IsExist(tempCustomAvatarPath))
 
 	// Pull the trigger
-	err = db.DeleteByID(ctx, testUser.ID, false)
+	adminCtx := WithAuditActor(ctx, AuditActor{ID: cindy.ID, Name: cindy.Name, IP: "127.0.0.1"})
+	err = db.DeleteByID(adminCtx, testUser.ID, false)
 	require.NoError(t, err)
 
 	// Verify after-the-fact data
@@ -655,6 +873,13 @@ func usersDeleteByID(t *testing.T, db *users) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, frank.NumFollowing)
 
+	org2, err = db.GetByID(ctx, org2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, org2.NumMembers)
+	err = db.DB.First(ownerTeam, ownerTeam.ID).Error
+	require.NoError(t, err)
+	assert.Equal(t, 1, ownerTeam.NumMembers)
+
 	authorizedKeys, err = os.ReadFile(authorizedKeysPath())
 	require.NoError(t, err)
 	assert.Empty(t, authorizedKeys)
@@ -675,6 +900,14 @@ func usersDeleteByID(t *testing.T, db *users) {
 		&Action{UserID: testUser.ID},
 		&IssueUser{UserID: testUser.ID},
 		&EmailAddress{UserID: testUser.ID},
+		&UserSession{UserID: testUser.ID},
+		&OrgMirror{OrgID: testUser.ID},
+		&CLASignature{UserID: testUser.ID},
+		&RepoInvitation{InviteeID: testUser.ID},
+		&OrgInvitation{InviteeID: testUser.ID},
+		&IgnoredRepo{UserID: testUser.ID},
+		&ReviewRequest{ReviewerID: testUser.ID},
+		&Approval{ReviewerID: testUser.ID},
 	} {
 		var count int64
 		err = db.DB.Model(table).Where(table).Count(&count).Error
@@ -688,6 +921,114 @@ func usersDeleteByID(t *testing.T, db *users) {
 	_, err = db.GetByID(ctx, testUser.ID)
 	wantErr := ErrUserNotExist{errutil.Args{"userID": testUser.ID}}
 	assert.Equal(t, wantErr, err)
+
+	// The deletion is audited with the actor in the context
+	logs, _, err := NewAuditLogsStore(db.DB).List(ctx,
+		ListAuditLogsOptions{
+			Action:   AuditActionUserDelete,
+			PageSize: 10,
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, cindy.ID, logs[0].ActorID)
+	assert.Equal(t, cindy.Name, logs[0].ActorName)
+	assert.Equal(t, testUser.ID, logs[0].TargetID)
+	assert.Equal(t, testUser.Name, logs[0].TargetName)
+	assert.Equal(t, "127.0.0.1", logs[0].IP)
+}
+
+func usersDeleteByIDWithDeletionPolicy(t *testing.T, db *users) {
+	ctx := context.Background()
+
+	alice, err := db.Create(ctx, "alice", "alice@exmaple.com", CreateUserOptions{})
+	require.NoError(t, err)
+	repo1, err := NewReposStore(db.DB).Create(ctx, alice.ID, CreateRepoOptions{Name: "repo1"})
+	require.NoError(t, err)
+
+	ghostID := NewGhostUser().ID
+	tests := []struct {
+		policy          string
+		wantNumComments int
+		wantDeleted     bool
+	}{
+		{policy: conf.UserDeletionPolicyGhost, wantNumComments: 2},
+		{policy: conf.UserDeletionPolicyDelete, wantNumComments: 1, wantDeleted: true},
+	}
+	for i, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			conf.SetMockUser(t, conf.UserOpts{DeletionPolicy: test.policy})
+
+			bob, err := db.Create(ctx, "bob-"+test.policy, "bob-"+test.policy+"@exmaple.com", CreateUserOptions{})
+			require.NoError(t, err)
+
+			// TODO: Use Issues.Create and Comments.Create to replace SQL hack when the
+			//  methods are available.
+			issue := &Issue{
+				RepoID:      repo1.ID,
+				Index:       int64(i + 1),
+				PosterID:    bob.ID,
+				Title:       "test-issue",
+				NumComments: 2,
+			}
+			err = db.DB.Create(issue).Error
+			require.NoError(t, err)
+
+			bobComment := &Comment{Type: COMMENT_TYPE_COMMENT, PosterID: bob.ID, IssueID: issue.ID, Content: "bob"}
+			bobClose := &Comment{Type: COMMENT_TYPE_CLOSE, PosterID: bob.ID, IssueID: issue.ID}
+			aliceComment := &Comment{Type: COMMENT_TYPE_COMMENT, PosterID: alice.ID, IssueID: issue.ID, Content: "alice"}
+			for _, c := range []*Comment{bobComment, bobClose, aliceComment} {
+				err = db.DB.Create(c).Error
+				require.NoError(t, err)
+			}
+
+			history := &CommentHistory{CommentID: bobComment.ID, EditorID: bob.ID, Content: "bob-old"}
+			err = db.DB.Create(history).Error
+			require.NoError(t, err)
+			attachment := &Attachment{UUID: "test-uuid-" + test.policy, IssueID: issue.ID, CommentID: bobComment.ID, Name: "test.txt"}
+			err = db.DB.Create(attachment).Error
+			require.NoError(t, err)
+
+			err = db.DeleteByID(ctx, bob.ID, true)
+			require.NoError(t, err)
+
+			err = db.DB.First(issue, issue.ID).Error
+			require.NoError(t, err)
+			assert.Equal(t, ghostID, issue.PosterID)
+			assert.Equal(t, test.wantNumComments, issue.NumComments)
+
+			err = db.DB.First(bobClose, bobClose.ID).Error
+			require.NoError(t, err)
+			assert.Equal(t, ghostID, bobClose.PosterID)
+
+			err = db.DB.First(aliceComment, aliceComment.ID).Error
+			require.NoError(t, err)
+			assert.Equal(t, alice.ID, aliceComment.PosterID)
+
+			if test.wantDeleted {
+				for _, table := range []any{
+					&Comment{ID: bobComment.ID},
+					&CommentHistory{ID: history.ID},
+					&Attachment{ID: attachment.ID},
+				} {
+					err = db.DB.Where(table).First(table).Error
+					assert.Equal(t, gorm.ErrRecordNotFound, err, "table for %T", table)
+				}
+				return
+			}
+
+			err = db.DB.First(bobComment, bobComment.ID).Error
+			require.NoError(t, err)
+			assert.Equal(t, ghostID, bobComment.PosterID)
+
+			err = db.DB.First(history, history.ID).Error
+			require.NoError(t, err)
+			assert.Equal(t, ghostID, history.EditorID)
+
+			err = db.DB.First(attachment, attachment.ID).Error
+			require.NoError(t, err)
+		})
+	}
 }
 
 func usersDeleteInactivated(t *testing.T, db *users) {
@@ -700,7 +1041,7 @@ func usersDeleteInactivated(t *testing.T, db *users) {
 	_, err = reposStore.Create(ctx, alice.ID, CreateRepoOptions{Name: "repo1"})
 	require.NoError(t, err)
 
-	// User with organization membership should be skipped
+	// User as the last owner of an organization should be skipped
 	bob, err := db.Create(ctx, "bob", "bob@exmaple.com", CreateUserOptions{})
 	require.NoError(t, err)
 	// TODO: Use Orgs.Create to replace SQL hack when the method is available.
@@ -712,7 +1053,12 @@ func usersDeleteInactivated(t *testing.T, db *users) {
 	).Error
 	require.NoError(t, err)
 	// TODO: Use Orgs.Join to replace SQL hack when the method is available.
-	err = db.Exec(`INSERT INTO org_user (uid, org_id) VALUES (?, ?)`, bob.ID, org1.ID).Error
+	ownerTeam := &Team{OrgID: org1.ID, LowerName: "owners", Name: OWNER_TEAM, NumMembers: 1}
+	err = db.DB.Create(ownerTeam).Error
+	require.NoError(t, err)
+	err = db.Exec(`INSERT INTO org_user (uid, org_id, is_owner) VALUES (?, ?, ?)`, bob.ID, org1.ID, true).Error
+	require.NoError(t, err)
+	err = db.Exec(`INSERT INTO team_user (org_id, team_id, uid) VALUES (?, ?, ?)`, org1.ID, ownerTeam.ID, bob.ID).Error
 	require.NoError(t, err)
 
 	// User activated state should be skipped
@@ -738,6 +1084,56 @@ func usersDeleteInactivated(t *testing.T, db *users) {
 	require.Len(t, users, 3)
 }
 
+func usersExport(t *testing.T, db *users) {
+	ctx := context.Background()
+
+	alice, err := db.Create(ctx, "alice", "alice@exmaple.com", CreateUserOptions{FullName: "Alice"})
+	require.NoError(t, err)
+	repo1, err := NewReposStore(db.DB).Create(ctx, alice.ID, CreateRepoOptions{Name: "repo1"})
+	require.NoError(t, err)
+
+	// TODO: Use Issues.Create, Comments.Create and PublicKeys.Add to replace SQL
+	//  hack when the methods are available.
+	issue := &Issue{RepoID: repo1.ID, Index: 1, PosterID: alice.ID, Title: "test-issue"}
+	err = db.DB.Create(issue).Error
+	require.NoError(t, err)
+	err = db.DB.Create(&Comment{Type: COMMENT_TYPE_COMMENT, PosterID: alice.ID, IssueID: issue.ID, Content: "test-comment"}).Error
+	require.NoError(t, err)
+	err = db.DB.Create(&PublicKey{OwnerID: alice.ID, Name: "test-key", Fingerprint: "test-fingerprint", Content: "test-key-content"}).Error
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = db.Export(ctx, alice.ID, &buf)
+	require.NoError(t, err)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+
+		r, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		_ = r.Close()
+		files[f.Name] = string(data)
+	}
+
+	wantNames := []string{"comments.json", "emails.json", "issues.json", "profile.json", "repositories.json", "ssh_keys.json"}
+	assert.ElementsMatch(t, wantNames, names)
+
+	assert.Contains(t, files["profile.json"], `"username": "alice"`)
+	assert.Contains(t, files["profile.json"], `"full_name": "Alice"`)
+	assert.NotContains(t, files["profile.json"], alice.Password)
+	assert.Contains(t, files["repositories.json"], `"name": "repo1"`)
+	assert.Contains(t, files["issues.json"], `"title": "test-issue"`)
+	assert.Contains(t, files["comments.json"], `"content": "test-comment"`)
+	assert.Contains(t, files["ssh_keys.json"], `"content": "test-key-content"`)
+}
+
 func usersGetByEmail(t *testing.T, db *users) {
 	ctx := context.Background()
 
@@ -799,6 +1195,23 @@ func usersGetByEmail(t *testing.T, db *users) {
 		require.NoError(t, err)
 		assert.Equal(t, bob.Name, user.Name)
 	})
+
+	t.Run("by noreply email", func(t *testing.T) {
+		conf.SetMockUser(t, conf.UserOpts{NoReplyDomain: "noreply.example.com"})
+
+		cindy, err := db.Create(ctx, "cindy", "cindy@example.com", CreateUserOptions{})
+		require.NoError(t, err)
+
+		user, err := db.GetByEmail(ctx, cindy.NoReplyEmail())
+		require.NoError(t, err)
+		assert.Equal(t, cindy.Name, user.Name)
+
+		// Both the user ID and username must match
+		email := userutil.NoReplyEmail(cindy.ID, "alice")
+		_, err = db.GetByEmail(ctx, email)
+		wantErr := ErrUserNotExist{args: errutil.Args{"email": email}}
+		assert.Equal(t, wantErr, err)
+	})
 }
 
 func usersGetByID(t *testing.T, db *users) {
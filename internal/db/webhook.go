@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -74,6 +75,14 @@ type HookEvents struct {
 	PullRequest  bool `json:"pull_request"`
 	IssueComment bool `json:"issue_comment"`
 	Release      bool `json:"release"`
+	Label        bool `json:"label"`
+	// RepositoryEdited is for changes of repository settings.
+	RepositoryEdited bool `json:"repository_edited"`
+	Deployment       bool `json:"deployment"`
+	DeploymentStatus bool `json:"deployment_status"`
+	// Membership and Team are only available to organization webhooks.
+	Membership bool `json:"membership"`
+	Team       bool `json:"team"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -108,6 +117,9 @@ type Webhook struct {
 	HookTaskType HookTaskType
 	Meta         string     `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus   HookStatus // Last delivery status
+	// The expression that payloads must match to be delivered, empty means
+	// all payloads are delivered. See webhook_filter.go for the syntax.
+	Filter string `xorm:"TEXT"`
 
 	Created     time.Time `xorm:"-" json:"-"`
 	CreatedUnix int64
@@ -147,6 +159,17 @@ func (w *Webhook) SlackMeta() *SlackMeta {
 	return s
 }
 
+func (w *Webhook) MSTeamsMeta() *MSTeamsMeta {
+	m := &MSTeamsMeta{}
+	if w.Meta == "" {
+		return m
+	}
+	if err := jsoniter.Unmarshal([]byte(w.Meta), m); err != nil {
+		log.Error("Failed to get Microsoft Teams meta [webhook_id: %d]: %v", w.ID, err)
+	}
+	return m
+}
+
 // History returns history of webhook by given conditions.
 func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
@@ -207,13 +230,49 @@ func (w *Webhook) HasReleaseEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Release)
 }
 
+// HasLabelEvent returns true if hook enabled label event.
+func (w *Webhook) HasLabelEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Label)
+}
+
+// HasRepositoryEditedEvent returns true if hook enabled repository edited event.
+func (w *Webhook) HasRepositoryEditedEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.RepositoryEdited)
+}
+
+// HasDeploymentEvent returns true if hook enabled deployment event.
+func (w *Webhook) HasDeploymentEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Deployment)
+}
+
+// HasDeploymentStatusEvent returns true if hook enabled deployment status event.
+func (w *Webhook) HasDeploymentStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.DeploymentStatus)
+}
+
+// HasMembershipEvent returns true if hook enabled membership event.
+func (w *Webhook) HasMembershipEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Membership)
+}
+
+// HasTeamEvent returns true if hook enabled team event.
+func (w *Webhook) HasTeamEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Team)
+}
+
 type eventChecker struct {
 	checker func() bool
 	typ     HookEventType
 }
 
 func (w *Webhook) EventsArray() []string {
-	events := make([]string, 0, 8)
+	events := make([]string, 0, 14)
 	eventCheckers := []eventChecker{
 		{w.HasCreateEvent, HOOK_EVENT_CREATE},
 		{w.HasDeleteEvent, HOOK_EVENT_DELETE},
@@ -223,6 +282,12 @@ func (w *Webhook) EventsArray() []string {
 		{w.HasPullRequestEvent, HOOK_EVENT_PULL_REQUEST},
 		{w.HasIssueCommentEvent, HOOK_EVENT_ISSUE_COMMENT},
 		{w.HasReleaseEvent, HOOK_EVENT_RELEASE},
+		{w.HasLabelEvent, HOOK_EVENT_LABEL},
+		{w.HasRepositoryEditedEvent, HOOK_EVENT_REPOSITORY_EDITED},
+		{w.HasDeploymentEvent, HOOK_EVENT_DEPLOYMENT},
+		{w.HasDeploymentStatusEvent, HOOK_EVENT_DEPLOYMENT_STATUS},
+		{w.HasMembershipEvent, HOOK_EVENT_MEMBERSHIP},
+		{w.HasTeamEvent, HOOK_EVENT_TEAM},
 	}
 	for _, c := range eventCheckers {
 		if c.checker() {
@@ -372,6 +437,7 @@ const (
 	SLACK
 	DISCORD
 	DINGTALK
+	MSTEAMS
 )
 
 var hookTaskTypes = map[string]HookTaskType{
@@ -379,6 +445,7 @@ var hookTaskTypes = map[string]HookTaskType{
 	"slack":    SLACK,
 	"discord":  DISCORD,
 	"dingtalk": DINGTALK,
+	"msteams":  MSTEAMS,
 }
 
 // ToHookTaskType returns HookTaskType by given name.
@@ -396,6 +463,8 @@ func (t HookTaskType) Name() string {
 		return "discord"
 	case DINGTALK:
 		return "dingtalk"
+	case MSTEAMS:
+		return "msteams"
 	}
 	return ""
 }
@@ -417,8 +486,147 @@ const (
 	HOOK_EVENT_PULL_REQUEST  HookEventType = "pull_request"
 	HOOK_EVENT_ISSUE_COMMENT HookEventType = "issue_comment"
 	HOOK_EVENT_RELEASE       HookEventType = "release"
+
+	// HOOK_EVENT_LABEL is only used for subscribing to label changes, the
+	// payloads are delivered as HOOK_EVENT_ISSUES or HOOK_EVENT_PULL_REQUEST.
+	HOOK_EVENT_LABEL HookEventType = "label"
+
+	HOOK_EVENT_REPOSITORY_EDITED HookEventType = "repository_edited"
+	HOOK_EVENT_DEPLOYMENT        HookEventType = "deployment"
+	HOOK_EVENT_DEPLOYMENT_STATUS HookEventType = "deployment_status"
+	HOOK_EVENT_MEMBERSHIP        HookEventType = "membership"
+	HOOK_EVENT_TEAM              HookEventType = "team"
 )
 
+// RepositoryChange represents the old and new values of a changed repository
+// setting.
+type RepositoryChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// RepositoryEditedPayload represents a payload information of repository
+// edited event, where the keys of changes are names of changed settings.
+type RepositoryEditedPayload struct {
+	Changes    map[string]*RepositoryChange `json:"changes"`
+	Repository *api.Repository              `json:"repository"`
+	Sender     *api.User                    `json:"sender"`
+}
+
+func (p *RepositoryEditedPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// changeLines returns the human-readable form of changes sorted by names of
+// the settings, e.g. "default_branch: master -> main".
+func (p *RepositoryEditedPayload) changeLines() []string {
+	names := make([]string, 0, len(p.Changes))
+	for name := range p.Changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = fmt.Sprintf("%s: %v -> %v", name, p.Changes[name].From, p.Changes[name].To)
+	}
+	return lines
+}
+
+// repositoryChanges returns changes of key settings from the old to the new
+// state of the repository.
+func repositoryChanges(e Engine, old, repo *Repository) map[string]*RepositoryChange {
+	changes := make(map[string]*RepositoryChange)
+	if old.OwnerID != repo.OwnerID {
+		changes["owner"] = &RepositoryChange{From: old.mustOwner(e).Name, To: repo.mustOwner(e).Name}
+	}
+	if old.Name != repo.Name {
+		changes["name"] = &RepositoryChange{From: old.Name, To: repo.Name}
+	}
+	if old.Description != repo.Description {
+		changes["description"] = &RepositoryChange{From: old.Description, To: repo.Description}
+	}
+	if old.Website != repo.Website {
+		changes["website"] = &RepositoryChange{From: old.Website, To: repo.Website}
+	}
+	if old.IsPrivate != repo.IsPrivate {
+		changes["private"] = &RepositoryChange{From: old.IsPrivate, To: repo.IsPrivate}
+	}
+	if old.IsUnlisted != repo.IsUnlisted {
+		changes["unlisted"] = &RepositoryChange{From: old.IsUnlisted, To: repo.IsUnlisted}
+	}
+	if old.DefaultBranch != repo.DefaultBranch {
+		changes["default_branch"] = &RepositoryChange{From: old.DefaultBranch, To: repo.DefaultBranch}
+	}
+	return changes
+}
+
+// PrepareRepositoryEditedWebhooks adds hook tasks of repository edited event
+// for changes of key settings from the old to the new state of the repository.
+// It does nothing if none of the settings has changed.
+func PrepareRepositoryEditedWebhooks(doer *User, old, repo *Repository) error {
+	if x == nil && testutil.InTest {
+		return nil
+	}
+
+	changes := repositoryChanges(x, old, repo)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	return prepareWebhooks(x, repo, HOOK_EVENT_REPOSITORY_EDITED, &RepositoryEditedPayload{
+		Changes:    changes,
+		Repository: repo.APIFormatLegacy(nil),
+		Sender:     doer.APIFormat(),
+	})
+}
+
+// IssuesLabelsPayload represents a payload information of issues event with
+// label changes.
+type IssuesLabelsPayload struct {
+	*api.IssuesPayload
+	AddedLabels   []*api.Label `json:"added_labels"`
+	RemovedLabels []*api.Label `json:"removed_labels"`
+}
+
+func (p *IssuesLabelsPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// PullRequestLabelsPayload represents a payload information of pull request
+// event with label changes.
+type PullRequestLabelsPayload struct {
+	*api.PullRequestPayload
+	AddedLabels   []*api.Label `json:"added_labels"`
+	RemovedLabels []*api.Label `json:"removed_labels"`
+}
+
+func (p *PullRequestLabelsPayload) JSONPayload() ([]byte, error) {
+	return jsoniter.MarshalIndent(p, "", "  ")
+}
+
+// isLabelsPayload returns true if the payload is of a label change.
+func isLabelsPayload(p api.Payloader) bool {
+	switch p.(type) {
+	case *IssuesLabelsPayload, *PullRequestLabelsPayload:
+		return true
+	}
+	return false
+}
+
+// unwrapLabelsPayload returns the underlying issues or pull request payload of
+// a label change, so that they can be handled by the same builders of
+// non-Gogs type hooks. Other payloads are returned as-is.
+func unwrapLabelsPayload(p api.Payloader) api.Payloader {
+	switch v := p.(type) {
+	case *IssuesLabelsPayload:
+		return v.IssuesPayload
+	case *PullRequestLabelsPayload:
+		return v.PullRequestPayload
+	}
+	return p
+}
+
 // HookRequest represents hook task request information.
 type HookRequest struct {
 	Headers map[string]string `json:"headers"`
@@ -561,67 +769,105 @@ func UpdateHookTask(t *HookTask) error {
 	return err
 }
 
-// prepareHookTasks adds list of webhooks to task queue.
-func prepareHookTasks(e Engine, repo *Repository, event HookEventType, p api.Payloader, webhooks []*Webhook) (err error) {
+// hasEvent returns true if the webhook should be delivered for the given event
+// and payload.
+func (w *Webhook) hasEvent(event HookEventType, p api.Payloader) bool {
+	switch event {
+	case HOOK_EVENT_CREATE:
+		return w.HasCreateEvent()
+	case HOOK_EVENT_DELETE:
+		return w.HasDeleteEvent()
+	case HOOK_EVENT_FORK:
+		return w.HasForkEvent()
+	case HOOK_EVENT_PUSH:
+		return w.HasPushEvent()
+	case HOOK_EVENT_ISSUES:
+		return w.HasIssuesEvent() || (isLabelsPayload(p) && w.HasLabelEvent())
+	case HOOK_EVENT_PULL_REQUEST:
+		return w.HasPullRequestEvent() || (isLabelsPayload(p) && w.HasLabelEvent())
+	case HOOK_EVENT_ISSUE_COMMENT:
+		return w.HasIssueCommentEvent()
+	case HOOK_EVENT_RELEASE:
+		return w.HasReleaseEvent()
+	case HOOK_EVENT_REPOSITORY_EDITED:
+		return w.HasRepositoryEditedEvent()
+	case HOOK_EVENT_DEPLOYMENT:
+		return w.HasDeploymentEvent()
+	case HOOK_EVENT_DEPLOYMENT_STATUS:
+		return w.HasDeploymentStatusEvent()
+	case HOOK_EVENT_MEMBERSHIP:
+		return w.HasMembershipEvent()
+	case HOOK_EVENT_TEAM:
+		return w.HasTeamEvent()
+	}
+	return true
+}
+
+// mergeWebhooks returns repository-level webhooks with inherited
+// organization-level webhooks appended. An organization-level webhook is skipped
+// when a repository-level webhook is already configured with the same URL and
+// secret, so the same endpoint does not receive the event twice.
+func mergeWebhooks(repoHooks, orgHooks []*Webhook) []*Webhook {
+	if len(orgHooks) == 0 {
+		return repoHooks
+	}
+
+	type endpoint struct {
+		url    string
+		secret string
+	}
+	seen := make(map[endpoint]bool, len(repoHooks))
+	for _, w := range repoHooks {
+		seen[endpoint{url: w.URL, secret: w.Secret}] = true
+	}
+
+	webhooks := make([]*Webhook, len(repoHooks), len(repoHooks)+len(orgHooks))
+	copy(webhooks, repoHooks)
+	for _, w := range orgHooks {
+		if seen[endpoint{url: w.URL, secret: w.Secret}] {
+			continue
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks
+}
+
+// prepareHookTasks adds list of webhooks to task queue. The repository ID is
+// zero for events that do not belong to any repository, e.g. organization
+// membership changes. Filters of webhooks are not applied to test deliveries.
+func prepareHookTasks(e Engine, repoID int64, event HookEventType, p api.Payloader, webhooks []*Webhook, isTest bool) (err error) {
 	if len(webhooks) == 0 {
 		return nil
 	}
 
 	var payloader api.Payloader
 	for _, w := range webhooks {
-		switch event {
-		case HOOK_EVENT_CREATE:
-			if !w.HasCreateEvent() {
-				continue
-			}
-		case HOOK_EVENT_DELETE:
-			if !w.HasDeleteEvent() {
-				continue
-			}
-		case HOOK_EVENT_FORK:
-			if !w.HasForkEvent() {
-				continue
-			}
-		case HOOK_EVENT_PUSH:
-			if !w.HasPushEvent() {
-				continue
-			}
-		case HOOK_EVENT_ISSUES:
-			if !w.HasIssuesEvent() {
-				continue
-			}
-		case HOOK_EVENT_PULL_REQUEST:
-			if !w.HasPullRequestEvent() {
-				continue
-			}
-		case HOOK_EVENT_ISSUE_COMMENT:
-			if !w.HasIssueCommentEvent() {
-				continue
-			}
-		case HOOK_EVENT_RELEASE:
-			if !w.HasReleaseEvent() {
+		if !w.hasEvent(event, p) {
+			continue
+		}
+
+		if !isTest {
+			// Filters are validated when saved, deliver the payload anyway in case the
+			// syntax has changed since then.
+			matched, err := w.matchFilter(p)
+			if err != nil {
+				log.Error("Failed to match filter of webhook [%d]: %v", w.ID, err)
+			} else if !matched {
 				continue
 			}
 		}
 
 		// Use separate objects so modifications won't be made on payload on non-Gogs type hooks.
-		switch w.HookTaskType {
-		case SLACK:
-			payloader, err = GetSlackPayload(p, event, w.Meta)
-			if err != nil {
-				return fmt.Errorf("GetSlackPayload: %v", err)
-			}
-		case DISCORD:
-			payloader, err = GetDiscordPayload(p, event, w.Meta)
-			if err != nil {
-				return fmt.Errorf("GetDiscordPayload: %v", err)
-			}
-		case DINGTALK:
-			payloader, err = GetDingtalkPayload(p, event)
+		builder, err := newPayloadBuilder(w)
+		if err != nil {
+			return fmt.Errorf("newPayloadBuilder: %v", err)
+		}
+		if builder != nil {
+			payloader, err = buildPayload(builder, unwrapLabelsPayload(p), event)
 			if err != nil {
-				return fmt.Errorf("GetDingtalkPayload: %v", err)
+				return fmt.Errorf("buildPayload: %v", err)
 			}
-		default:
+		} else {
 			payloader = p
 		}
 
@@ -637,7 +883,7 @@ func prepareHookTasks(e Engine, repo *Repository, event HookEventType, p api.Pay
 		}
 
 		if err = createHookTask(e, &HookTask{
-			RepoID:      repo.ID,
+			RepoID:      repoID,
 			HookID:      w.ID,
 			Type:        w.HookTaskType,
 			URL:         w.URL,
@@ -654,7 +900,7 @@ func prepareHookTasks(e Engine, repo *Repository, event HookEventType, p api.Pay
 	// It's safe to fail when the whole function is called during hook execution
 	// because resource released after exit. Also, there is no process started to
 	// consume this input during hook execution.
-	go HookQueue.Add(repo.ID)
+	go HookQueue.Add(repoID)
 	return nil
 }
 
@@ -664,16 +910,15 @@ func prepareWebhooks(e Engine, repo *Repository, event HookEventType, p api.Payl
 		return fmt.Errorf("getActiveWebhooksByRepoID [%d]: %v", repo.ID, err)
 	}
 
-	// check if repo belongs to org and append additional webhooks
+	// Check if repo belongs to org and append inherited webhooks
 	if repo.mustOwner(e).IsOrganization() {
-		// get hooks for org
 		orgws, err := getActiveWebhooksByOrgID(e, repo.OwnerID)
 		if err != nil {
 			return fmt.Errorf("getActiveWebhooksByOrgID [%d]: %v", repo.OwnerID, err)
 		}
-		webhooks = append(webhooks, orgws...)
+		webhooks = mergeWebhooks(webhooks, orgws)
 	}
-	return prepareHookTasks(e, repo, event, p, webhooks)
+	return prepareHookTasks(e, repo.ID, event, p, webhooks, false)
 }
 
 // PrepareWebhooks adds all active webhooks to task queue.
@@ -692,7 +937,7 @@ func TestWebhook(repo *Repository, event HookEventType, p api.Payloader, webhook
 	if err != nil {
 		return fmt.Errorf("GetWebhookOfRepoByID [repo_id: %d, id: %d]: %v", repo.ID, webhookID, err)
 	}
-	return prepareHookTasks(x, repo, event, p, []*Webhook{webhook})
+	return prepareHookTasks(x, repo.ID, event, p, []*Webhook{webhook}, true)
 }
 
 func (t *HookTask) deliver() {
@@ -783,6 +1028,115 @@ func (t *HookTask) deliver() {
 	t.ResponseInfo.Body = string(p)
 }
 
+// hookTaskPruneBatchSize is the number of hook tasks to be deleted at a time,
+// so that the table is not locked for long.
+const hookTaskPruneBatchSize = 100
+
+// deleteHookTasksInBatches deletes delivered hook tasks that match the
+// condition in batches, and returns the number of deleted tasks.
+func deleteHookTasksInBatches(cond string, args ...any) (int64, error) {
+	var deleted int64
+	for {
+		var ids []int64
+		err := x.Table("hook_task").
+			Where("is_delivered = ?", true).
+			And(cond, args...).
+			Cols("id").
+			Limit(hookTaskPruneBatchSize).
+			Find(&ids)
+		if err != nil {
+			return deleted, fmt.Errorf("find hook tasks: %v", err)
+		} else if len(ids) == 0 {
+			return deleted, nil
+		}
+
+		n, err := x.In("id", ids).Delete(new(HookTask))
+		if err != nil {
+			return deleted, fmt.Errorf("delete hook tasks: %v", err)
+		}
+		deleted += n
+
+		if len(ids) < hookTaskPruneBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// pruneHookTasks deletes delivered hook tasks that are not among the most
+// recent maxCount deliveries of their webhooks, or were delivered before the
+// given time. Limits with zero values are ignored. Undelivered hook tasks are
+// never deleted. It returns the number of deleted tasks.
+func pruneHookTasks(maxCount int, deliveredBefore time.Time) (int64, error) {
+	var deleted int64
+	if !deliveredBefore.IsZero() {
+		n, err := deleteHookTasksInBatches("delivered < ?", deliveredBefore.UnixNano())
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("prune by age: %v", err)
+		}
+	}
+
+	if maxCount <= 0 {
+		return deleted, nil
+	}
+
+	var hookIDs []int64
+	err := x.Table("hook_task").
+		Where("is_delivered = ?", true).
+		GroupBy("hook_id").
+		Having(fmt.Sprintf("COUNT(*) > %d", maxCount)).
+		Cols("hook_id").
+		Find(&hookIDs)
+	if err != nil {
+		return deleted, fmt.Errorf("find webhooks exceeding limit: %v", err)
+	}
+
+	for _, hookID := range hookIDs {
+		// Find the oldest one of the most recent deliveries to keep.
+		var oldestIDs []int64
+		err = x.Table("hook_task").
+			Where("hook_id = ? AND is_delivered = ?", hookID, true).
+			Desc("id").
+			Cols("id").
+			Limit(1, maxCount-1).
+			Find(&oldestIDs)
+		if err != nil {
+			return deleted, fmt.Errorf("find oldest retained hook task of webhook %d: %v", hookID, err)
+		} else if len(oldestIDs) == 0 {
+			continue
+		}
+
+		n, err := deleteHookTasksInBatches("hook_id = ? AND id < ?", hookID, oldestIDs[0])
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("prune by count of webhook %d: %v", hookID, err)
+		}
+	}
+	return deleted, nil
+}
+
+// PruneHookTasks deletes delivery histories of webhooks that are beyond the
+// retention policy.
+func PruneHookTasks() {
+	if taskStatusTable.IsRunning(_PRUNE_HOOK_TASKS) {
+		return
+	}
+	taskStatusTable.Start(_PRUNE_HOOK_TASKS)
+	defer taskStatusTable.Stop(_PRUNE_HOOK_TASKS)
+
+	log.Trace("Doing: PruneHookTasks")
+
+	var deliveredBefore time.Time
+	if conf.Webhook.DeliveryHistoryMaxAge > 0 {
+		deliveredBefore = time.Now().Add(-conf.Webhook.DeliveryHistoryMaxAge)
+	}
+	deleted, err := pruneHookTasks(conf.Webhook.DeliveryHistoryMaxCount, deliveredBefore)
+	if err != nil {
+		log.Error("Failed to prune hook tasks: %v", err)
+	}
+	log.Trace("Pruned %d hook tasks", deleted)
+}
+
 // DeliverHooks checks and delivers undelivered hooks.
 // TODO: shoot more hooks at same time.
 func DeliverHooks() {
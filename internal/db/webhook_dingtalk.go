@@ -9,10 +9,11 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
-	"github.com/pkg/errors"
 
 	"github.com/gogs/git-module"
 	api "github.com/gogs/go-gogs-client"
+
+	"gogs.io/gogs/internal/conf"
 )
 
 const (
@@ -59,28 +60,116 @@ func NewDingtalkActionCard(singleTitle, singleURL string) DingtalkActionCard {
 }
 
 // TODO: add content
-func GetDingtalkPayload(p api.Payloader, event HookEventType) (payload *DingtalkPayload, err error) {
-	switch event {
-	case HOOK_EVENT_CREATE:
-		payload = getDingtalkCreatePayload(p.(*api.CreatePayload))
-	case HOOK_EVENT_DELETE:
-		payload = getDingtalkDeletePayload(p.(*api.DeletePayload))
-	case HOOK_EVENT_FORK:
-		payload = getDingtalkForkPayload(p.(*api.ForkPayload))
-	case HOOK_EVENT_PUSH:
-		payload = getDingtalkPushPayload(p.(*api.PushPayload))
-	case HOOK_EVENT_ISSUES:
-		payload = getDingtalkIssuesPayload(p.(*api.IssuesPayload))
-	case HOOK_EVENT_ISSUE_COMMENT:
-		payload = getDingtalkIssueCommentPayload(p.(*api.IssueCommentPayload))
-	case HOOK_EVENT_PULL_REQUEST:
-		payload = getDingtalkPullRequestPayload(p.(*api.PullRequestPayload))
-	case HOOK_EVENT_RELEASE:
-		payload = getDingtalkReleasePayload(p.(*api.ReleasePayload))
-	default:
-		return nil, errors.Errorf("unexpected event %q", event)
+type dingtalkPayloadBuilder struct{}
+
+func (dingtalkPayloadBuilder) Create(p *api.CreatePayload) api.Payloader {
+	return getDingtalkCreatePayload(p)
+}
+
+func (dingtalkPayloadBuilder) Delete(p *api.DeletePayload) api.Payloader {
+	return getDingtalkDeletePayload(p)
+}
+
+func (dingtalkPayloadBuilder) Fork(p *api.ForkPayload) api.Payloader {
+	return getDingtalkForkPayload(p)
+}
+
+func (dingtalkPayloadBuilder) Push(p *api.PushPayload) api.Payloader {
+	return getDingtalkPushPayload(p)
+}
+
+func (dingtalkPayloadBuilder) Issues(p *api.IssuesPayload) api.Payloader {
+	return getDingtalkIssuesPayload(p)
+}
+
+func (dingtalkPayloadBuilder) IssueComment(p *api.IssueCommentPayload) api.Payloader {
+	return getDingtalkIssueCommentPayload(p)
+}
+
+func (dingtalkPayloadBuilder) PullRequest(p *api.PullRequestPayload) api.Payloader {
+	return getDingtalkPullRequestPayload(p)
+}
+
+func (dingtalkPayloadBuilder) Release(p *ReleasePayload) api.Payloader {
+	return getDingtalkReleasePayload(p)
+}
+
+func (dingtalkPayloadBuilder) RepositoryEdited(p *RepositoryEditedPayload) api.Payloader {
+	actionCard := NewDingtalkActionCard("View Repo", p.Repository.HTMLURL)
+	actionCard.Text += "# Repo Settings Edited"
+	actionCard.Text += "\n- Repo: " + MarkdownLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	actionCard.Text += "\n- Sender: " + p.Sender.UserName
+	for _, line := range p.changeLines() {
+		actionCard.Text += "\n- " + line
+	}
+
+	return &DingtalkPayload{
+		MsgType:    "actionCard",
+		ActionCard: actionCard,
+	}
+}
+
+func (dingtalkPayloadBuilder) Deployment(p *DeploymentPayload) api.Payloader {
+	commitURL := p.Repository.HTMLURL + "/commit/" + p.Deployment.SHA
+	actionCard := NewDingtalkActionCard("View Commit", commitURL)
+	actionCard.Text += "# Deployment Created"
+	actionCard.Text += "\n- Repo: " + MarkdownLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	actionCard.Text += "\n- Ref: " + MarkdownLinkFormatter(commitURL, p.Deployment.Ref)
+	actionCard.Text += "\n- Environment: **" + p.Deployment.Environment + "**"
+	actionCard.Text += "\n- Sender: " + p.Sender.UserName
+
+	return &DingtalkPayload{
+		MsgType:    "actionCard",
+		ActionCard: actionCard,
+	}
+}
+
+func (dingtalkPayloadBuilder) DeploymentStatus(p *DeploymentStatusPayload) api.Payloader {
+	commitURL := p.Repository.HTMLURL + "/commit/" + p.Deployment.SHA
+	targetURL := p.DeploymentStatus.TargetURL
+	if targetURL == "" {
+		targetURL = commitURL
+	}
+	actionCard := NewDingtalkActionCard("View Deployment", targetURL)
+	actionCard.Text += "# Deployment Status Changed"
+	actionCard.Text += "\n- Repo: " + MarkdownLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	actionCard.Text += "\n- Ref: " + MarkdownLinkFormatter(commitURL, p.Deployment.Ref)
+	actionCard.Text += "\n- Environment: **" + p.Deployment.Environment + "**"
+	actionCard.Text += "\n- State: **" + string(p.DeploymentStatus.State) + "**"
+
+	return &DingtalkPayload{
+		MsgType:    "actionCard",
+		ActionCard: actionCard,
+	}
+}
+
+func (dingtalkPayloadBuilder) Membership(p *MembershipPayload) api.Payloader {
+	orgURL := conf.Server.ExternalURL + p.Organization.UserName
+	actionCard := NewDingtalkActionCard("View Organization", orgURL)
+	actionCard.Text += "# Member " + strings.Title(string(p.Action))
+	actionCard.Text += "\n- Organization: " + MarkdownLinkFormatter(orgURL, p.Organization.UserName)
+	actionCard.Text += "\n- Member: **" + p.Member.UserName + "**"
+	actionCard.Text += "\n- Sender: " + p.Sender.UserName
+
+	return &DingtalkPayload{
+		MsgType:    "actionCard",
+		ActionCard: actionCard,
+	}
+}
+
+func (dingtalkPayloadBuilder) Team(p *TeamPayload) api.Payloader {
+	teamURL := conf.Server.ExternalURL + "org/" + p.Organization.UserName + "/teams/" + strings.ToLower(p.Team.Name)
+	actionCard := NewDingtalkActionCard("View Team", teamURL)
+	actionCard.Text += "# Team Member " + strings.Title(string(p.Action))
+	actionCard.Text += "\n- Organization: " + MarkdownLinkFormatter(conf.Server.ExternalURL+p.Organization.UserName, p.Organization.UserName)
+	actionCard.Text += "\n- Team: " + MarkdownLinkFormatter(teamURL, p.Team.Name)
+	actionCard.Text += "\n- Member: **" + p.Member.UserName + "**"
+	actionCard.Text += "\n- Sender: " + p.Sender.UserName
+
+	return &DingtalkPayload{
+		MsgType:    "actionCard",
+		ActionCard: actionCard,
 	}
-	return payload, nil
 }
 
 func getDingtalkCreatePayload(p *api.CreatePayload) *DingtalkPayload {
@@ -243,7 +332,7 @@ func getDingtalkPullRequestPayload(p *api.PullRequestPayload) *DingtalkPayload {
 	}
 }
 
-func getDingtalkReleasePayload(p *api.ReleasePayload) *DingtalkPayload {
+func getDingtalkReleasePayload(p *ReleasePayload) *DingtalkPayload {
 	releaseURL := p.Repository.HTMLURL + "/src/" + p.Release.TagName
 
 	author := p.Release.Author.FullName
@@ -252,7 +341,14 @@ func getDingtalkReleasePayload(p *api.ReleasePayload) *DingtalkPayload {
 	}
 
 	actionCard := NewDingtalkActionCard("View Release", releaseURL)
-	actionCard.Text += "# New Release Published"
+	switch p.Action {
+	case HOOK_RELEASE_UPDATED:
+		actionCard.Text += "# Release Updated"
+	case HOOK_RELEASE_DELETED:
+		actionCard.Text += "# Release Deleted"
+	default:
+		actionCard.Text += "# New Release Published"
+	}
 	actionCard.Text += "\n- Repo: " + MarkdownLinkFormatter(p.Repository.HTMLURL, p.Repository.Name)
 	actionCard.Text += "\n- Tag: " + MarkdownLinkFormatter(releaseURL, p.Release.TagName)
 	actionCard.Text += "\n- Author: " + author
@@ -10,7 +10,6 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
-	"github.com/pkg/errors"
 
 	"github.com/gogs/git-module"
 	api "github.com/gogs/go-gogs-client"
@@ -355,10 +354,13 @@ func getDiscordPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) *
 	}
 }
 
-func getDiscordReleasePayload(p *api.ReleasePayload) *DiscordPayload {
+func getDiscordReleasePayload(p *ReleasePayload) *DiscordPayload {
 	repoLink := DiscordLinkFormatter(p.Repository.HTMLURL, p.Repository.Name)
 	refLink := DiscordLinkFormatter(p.Repository.HTMLURL+"/src/"+p.Release.TagName, p.Release.TagName)
 	content := fmt.Sprintf("Published new release %s of %s", refLink, repoLink)
+	if p.Action != api.HOOK_RELEASE_PUBLISHED {
+		content = fmt.Sprintf("Release %s of %s %s", refLink, repoLink, p.Action)
+	}
 	return &DiscordPayload{
 		Embeds: []*DiscordEmbedObject{{
 			Description: content,
@@ -371,38 +373,121 @@ func getDiscordReleasePayload(p *api.ReleasePayload) *DiscordPayload {
 	}
 }
 
-func GetDiscordPayload(p api.Payloader, event HookEventType, meta string) (payload *DiscordPayload, err error) {
-	slack := &SlackMeta{}
-	if err := jsoniter.Unmarshal([]byte(meta), &slack); err != nil {
-		return nil, fmt.Errorf("jsoniter.Unmarshal: %v", err)
-	}
-
-	switch event {
-	case HOOK_EVENT_CREATE:
-		payload = getDiscordCreatePayload(p.(*api.CreatePayload))
-	case HOOK_EVENT_DELETE:
-		payload = getDiscordDeletePayload(p.(*api.DeletePayload))
-	case HOOK_EVENT_FORK:
-		payload = getDiscordForkPayload(p.(*api.ForkPayload))
-	case HOOK_EVENT_PUSH:
-		payload = getDiscordPushPayload(p.(*api.PushPayload), slack)
-	case HOOK_EVENT_ISSUES:
-		payload = getDiscordIssuesPayload(p.(*api.IssuesPayload), slack)
-	case HOOK_EVENT_ISSUE_COMMENT:
-		payload = getDiscordIssueCommentPayload(p.(*api.IssueCommentPayload), slack)
-	case HOOK_EVENT_PULL_REQUEST:
-		payload = getDiscordPullRequestPayload(p.(*api.PullRequestPayload), slack)
-	case HOOK_EVENT_RELEASE:
-		payload = getDiscordReleasePayload(p.(*api.ReleasePayload))
-	default:
-		return nil, errors.Errorf("unexpected event %q", event)
-	}
+type discordPayloadBuilder struct {
+	meta *SlackMeta
+}
 
-	payload.Username = slack.Username
-	payload.AvatarURL = slack.IconURL
+// decorate applies hook-specific attributes to the payload.
+func (b *discordPayloadBuilder) decorate(payload *DiscordPayload) api.Payloader {
+	payload.Username = b.meta.Username
+	payload.AvatarURL = b.meta.IconURL
 	if len(payload.Embeds) > 0 {
-		color, _ := strconv.ParseInt(strings.TrimLeft(slack.Color, "#"), 16, 32)
+		color, _ := strconv.ParseInt(strings.TrimLeft(b.meta.Color, "#"), 16, 32)
 		payload.Embeds[0].Color = int(color)
 	}
-	return payload, nil
+	return payload
+}
+
+func (b *discordPayloadBuilder) Create(p *api.CreatePayload) api.Payloader {
+	return b.decorate(getDiscordCreatePayload(p))
+}
+
+func (b *discordPayloadBuilder) Delete(p *api.DeletePayload) api.Payloader {
+	return b.decorate(getDiscordDeletePayload(p))
+}
+
+func (b *discordPayloadBuilder) Fork(p *api.ForkPayload) api.Payloader {
+	return b.decorate(getDiscordForkPayload(p))
+}
+
+func (b *discordPayloadBuilder) Push(p *api.PushPayload) api.Payloader {
+	return b.decorate(getDiscordPushPayload(p, b.meta))
+}
+
+func (b *discordPayloadBuilder) Issues(p *api.IssuesPayload) api.Payloader {
+	return b.decorate(getDiscordIssuesPayload(p, b.meta))
+}
+
+func (b *discordPayloadBuilder) IssueComment(p *api.IssueCommentPayload) api.Payloader {
+	return b.decorate(getDiscordIssueCommentPayload(p, b.meta))
+}
+
+func (b *discordPayloadBuilder) PullRequest(p *api.PullRequestPayload) api.Payloader {
+	return b.decorate(getDiscordPullRequestPayload(p, b.meta))
+}
+
+func (b *discordPayloadBuilder) Release(p *ReleasePayload) api.Payloader {
+	return b.decorate(getDiscordReleasePayload(p))
+}
+
+func (b *discordPayloadBuilder) RepositoryEdited(p *RepositoryEditedPayload) api.Payloader {
+	return b.decorate(&DiscordPayload{
+		Embeds: []*DiscordEmbedObject{{
+			Title:       fmt.Sprintf("[%s] Repository settings edited", p.Repository.FullName),
+			Description: strings.Join(p.changeLines(), "\n"),
+			URL:         p.Repository.HTMLURL,
+			Author: &DiscordEmbedAuthorObject{
+				Name:    p.Sender.UserName,
+				IconURL: p.Sender.AvatarUrl,
+			},
+		}},
+	})
+}
+
+func (b *discordPayloadBuilder) Deployment(p *DeploymentPayload) api.Payloader {
+	return b.decorate(&DiscordPayload{
+		Embeds: []*DiscordEmbedObject{{
+			Title:       fmt.Sprintf("[%s] Deployment created: %s to %s", p.Repository.FullName, p.Deployment.Ref, p.Deployment.Environment),
+			Description: p.Deployment.Description,
+			URL:         p.Repository.HTMLURL + "/commit/" + p.Deployment.SHA,
+			Author: &DiscordEmbedAuthorObject{
+				Name:    p.Sender.UserName,
+				IconURL: p.Sender.AvatarUrl,
+			},
+		}},
+	})
+}
+
+func (b *discordPayloadBuilder) DeploymentStatus(p *DeploymentStatusPayload) api.Payloader {
+	url := p.DeploymentStatus.TargetURL
+	if url == "" {
+		url = p.Repository.HTMLURL + "/commit/" + p.Deployment.SHA
+	}
+	return b.decorate(&DiscordPayload{
+		Embeds: []*DiscordEmbedObject{{
+			Title:       fmt.Sprintf("[%s] Deployment of %s to %s: %s", p.Repository.FullName, p.Deployment.Ref, p.Deployment.Environment, p.DeploymentStatus.State),
+			Description: p.DeploymentStatus.Description,
+			URL:         url,
+			Author: &DiscordEmbedAuthorObject{
+				Name:    p.Sender.UserName,
+				IconURL: p.Sender.AvatarUrl,
+			},
+		}},
+	})
+}
+
+func (b *discordPayloadBuilder) Membership(p *MembershipPayload) api.Payloader {
+	return b.decorate(&DiscordPayload{
+		Embeds: []*DiscordEmbedObject{{
+			Title: fmt.Sprintf("[%s] Member %s: %s", p.Organization.UserName, p.Action, p.Member.UserName),
+			URL:   conf.Server.ExternalURL + p.Organization.UserName,
+			Author: &DiscordEmbedAuthorObject{
+				Name:    p.Sender.UserName,
+				IconURL: p.Sender.AvatarUrl,
+			},
+		}},
+	})
+}
+
+func (b *discordPayloadBuilder) Team(p *TeamPayload) api.Payloader {
+	return b.decorate(&DiscordPayload{
+		Embeds: []*DiscordEmbedObject{{
+			Title: fmt.Sprintf("[%s] Team %s member %s: %s", p.Organization.UserName, p.Team.Name, p.Action, p.Member.UserName),
+			URL:   conf.Server.ExternalURL + "org/" + p.Organization.UserName + "/teams/" + strings.ToLower(p.Team.Name),
+			Author: &DiscordEmbedAuthorObject{
+				Name:    p.Sender.UserName,
+				IconURL: p.Sender.AvatarUrl,
+			},
+		}},
+	})
 }
@@ -0,0 +1,476 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/errutil"
+	"gogs.io/gogs/internal/pathutil"
+)
+
+// Webhook filters are small expressions evaluated against payloads to decide
+// whether webhooks should be delivered, e.g.
+//
+//	path("src/**") && !label("wontfix")
+//
+// An expression consists of predicates that take a single quoted string,
+// combined with "!", "&&", "||" and parentheses. Supported predicates are:
+//
+//   - path(glob): the push touches a file matching the glob
+//   - branch(glob): the pushed, created or deleted branch, or the base branch
+//     of the pull request matches the glob
+//   - label(name): the issue or pull request has the label
+//   - action(name): the action of the event is the name, e.g. "opened"
+//   - sender(name): the event is triggered by the user with the name
+const (
+	webhookFilterMaxLength = 1024
+	webhookFilterMaxDepth  = 32
+)
+
+type ErrWebhookFilterInvalid struct {
+	args errutil.Args
+}
+
+func IsErrWebhookFilterInvalid(err error) bool {
+	_, ok := err.(ErrWebhookFilterInvalid)
+	return ok
+}
+
+func (err ErrWebhookFilterInvalid) Error() string {
+	return fmt.Sprintf("webhook filter is not valid: %v", err.args)
+}
+
+// Reason returns the reason why the filter is not valid.
+func (err ErrWebhookFilterInvalid) Reason() string {
+	reason, _ := err.args["reason"].(string)
+	return reason
+}
+
+// webhookFilterPayload contains attributes of a payload that can be checked by
+// webhook filters.
+type webhookFilterPayload struct {
+	// The push to list changed paths of, paths are listed on demand because it
+	// needs to run Git.
+	push        *api.PushPayload
+	paths       []string
+	pathsLoaded bool
+
+	branch string
+	labels []string
+	action string
+	sender string
+}
+
+func newWebhookFilterPayload(p api.Payloader) *webhookFilterPayload {
+	fp := new(webhookFilterPayload)
+	setSender := func(u *api.User) {
+		if u != nil {
+			fp.sender = u.UserName
+		}
+	}
+	setLabels := func(labels []*api.Label) {
+		for _, l := range labels {
+			fp.labels = append(fp.labels, l.Name)
+		}
+	}
+
+	switch v := unwrapLabelsPayload(p).(type) {
+	case *api.PushPayload:
+		if strings.HasPrefix(v.Ref, git.RefsHeads) {
+			fp.branch = strings.TrimPrefix(v.Ref, git.RefsHeads)
+		}
+		fp.push = v
+		setSender(v.Sender)
+	case *api.CreatePayload:
+		if v.RefType == "branch" {
+			fp.branch = v.Ref
+		}
+		setSender(v.Sender)
+	case *api.DeletePayload:
+		if v.RefType == "branch" {
+			fp.branch = v.Ref
+		}
+		setSender(v.Sender)
+	case *api.IssuesPayload:
+		fp.action = string(v.Action)
+		if v.Issue != nil {
+			setLabels(v.Issue.Labels)
+		}
+		setSender(v.Sender)
+	case *api.PullRequestPayload:
+		fp.action = string(v.Action)
+		if v.PullRequest != nil {
+			fp.branch = v.PullRequest.BaseBranch
+			setLabels(v.PullRequest.Labels)
+		}
+		setSender(v.Sender)
+	case *api.IssueCommentPayload:
+		fp.action = string(v.Action)
+		if v.Issue != nil {
+			setLabels(v.Issue.Labels)
+		}
+		setSender(v.Sender)
+	case *api.ReleasePayload:
+		fp.action = string(v.Action)
+		setSender(v.Sender)
+	case *api.ForkPayload:
+		setSender(v.Sender)
+	}
+	return fp
+}
+
+// changedPaths returns paths of files changed by the push, or nil for other
+// payloads.
+func (p *webhookFilterPayload) changedPaths() []string {
+	if p.push == nil || p.pathsLoaded {
+		return p.paths
+	}
+
+	paths, err := pushChangedPaths(p.push)
+	if err != nil {
+		log.Error("Failed to list changed paths of push to %q: %v", p.push.Ref, err)
+		paths = payloadCommitsChangedPaths(p.push.Commits)
+	}
+	p.paths = paths
+	p.pathsLoaded = true
+	return p.paths
+}
+
+// pushChangedPaths lists paths of files changed by the push from the
+// repository. Commits of the payload are not used because they are truncated
+// for large pushes. For a new branch, files changed by commits that are not in
+// any other branch are listed.
+func pushChangedPaths(p *api.PushPayload) ([]string, error) {
+	if p.Repo == nil || p.Repo.Owner == nil || p.After == "" {
+		// The push cannot be located in any repository, e.g. the payload is made up.
+		return payloadCommitsChangedPaths(p.Commits), nil
+	}
+
+	var args []string
+	if p.Before == "" || p.Before == git.EmptyID {
+		branch := strings.TrimPrefix(p.Ref, git.RefsHeads)
+		args = []string{"log", "--format=", "--name-only", "-z", p.After, "--not", "--exclude=" + branch, "--branches"}
+	} else {
+		args = []string{"diff", "--name-only", "-z", p.Before, p.After}
+	}
+	stdout, err := git.NewCommand(args...).RunInDir(RepoPath(p.Repo.Owner.UserName, p.Repo.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range bytes.Split(stdout, []byte{0}) {
+		path = bytes.Trim(path, "\n")
+		if len(path) == 0 || seen[string(path)] {
+			continue
+		}
+		seen[string(path)] = true
+		paths = append(paths, string(path))
+	}
+	return paths, nil
+}
+
+// payloadCommitsChangedPaths returns paths of files changed by the commits.
+func payloadCommitsChangedPaths(commits []*api.PayloadCommit) []string {
+	var paths []string
+	for _, c := range commits {
+		paths = append(paths, c.Added...)
+		paths = append(paths, c.Removed...)
+		paths = append(paths, c.Modified...)
+	}
+	return paths
+}
+
+type webhookFilterPredicate struct {
+	// Whether the argument is a glob that needs to be validated.
+	glob bool
+	eval func(p *webhookFilterPayload, arg string) bool
+}
+
+var webhookFilterPredicates = map[string]webhookFilterPredicate{
+	"path": {
+		glob: true,
+		eval: func(p *webhookFilterPayload, arg string) bool {
+			for _, path := range p.changedPaths() {
+				if matched, _ := pathutil.MatchGlob(arg, path); matched {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	"branch": {
+		glob: true,
+		eval: func(p *webhookFilterPayload, arg string) bool {
+			if p.branch == "" {
+				return false
+			}
+			matched, _ := pathutil.MatchGlob(arg, p.branch)
+			return matched
+		},
+	},
+	"label": {
+		eval: func(p *webhookFilterPayload, arg string) bool {
+			for _, label := range p.labels {
+				if strings.EqualFold(label, arg) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	"action": {
+		eval: func(p *webhookFilterPayload, arg string) bool {
+			return p.action == arg
+		},
+	},
+	"sender": {
+		eval: func(p *webhookFilterPayload, arg string) bool {
+			return strings.EqualFold(p.sender, arg)
+		},
+	},
+}
+
+type webhookFilterExpr interface {
+	eval(p *webhookFilterPayload) bool
+}
+
+type webhookFilterNot struct {
+	x webhookFilterExpr
+}
+
+func (e *webhookFilterNot) eval(p *webhookFilterPayload) bool {
+	return !e.x.eval(p)
+}
+
+type webhookFilterAnd struct {
+	x, y webhookFilterExpr
+}
+
+func (e *webhookFilterAnd) eval(p *webhookFilterPayload) bool {
+	return e.x.eval(p) && e.y.eval(p)
+}
+
+type webhookFilterOr struct {
+	x, y webhookFilterExpr
+}
+
+func (e *webhookFilterOr) eval(p *webhookFilterPayload) bool {
+	return e.x.eval(p) || e.y.eval(p)
+}
+
+type webhookFilterCall struct {
+	predicate webhookFilterPredicate
+	arg       string
+}
+
+func (e *webhookFilterCall) eval(p *webhookFilterPayload) bool {
+	return e.predicate.eval(p, e.arg)
+}
+
+// webhookFilterParser is a recursive descent parser of webhook filters with the
+// grammar:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | primary
+//	primary = "(" or ")" | ident "(" string ")"
+type webhookFilterParser struct {
+	s     string
+	pos   int
+	depth int
+}
+
+func (p *webhookFilterParser) errorf(format string, args ...any) error {
+	return ErrWebhookFilterInvalid{args: errutil.Args{
+		"reason": fmt.Sprintf("%s at position %d", fmt.Sprintf(format, args...), p.pos+1),
+	}}
+}
+
+func (p *webhookFilterParser) skipSpaces() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// consume skips spaces and consumes the token if the input continues with it.
+func (p *webhookFilterParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.s[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *webhookFilterParser) parseOr() (webhookFilterExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("||") {
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = &webhookFilterOr{x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *webhookFilterParser) parseAnd() (webhookFilterExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.consume("&&") {
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = &webhookFilterAnd{x: x, y: y}
+	}
+	return x, nil
+}
+
+func (p *webhookFilterParser) parseUnary() (webhookFilterExpr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > webhookFilterMaxDepth {
+		return nil, p.errorf("expression is nested too deeply")
+	}
+
+	if p.consume("!") {
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &webhookFilterNot{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *webhookFilterParser) parsePrimary() (webhookFilterExpr, error) {
+	if p.consume("(") {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf(`expect ")"`)
+		}
+		return x, nil
+	}
+
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] >= 'a' && p.s[p.pos] <= 'z') {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	if name == "" {
+		return nil, p.errorf("expect a predicate")
+	}
+	predicate, ok := webhookFilterPredicates[name]
+	if !ok {
+		p.pos = start
+		return nil, p.errorf("unknown predicate %q", name)
+	}
+
+	if !p.consume("(") {
+		return nil, p.errorf(`expect "("`)
+	}
+	arg, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+	if predicate.glob {
+		if _, err = pathutil.MatchGlob(arg, ""); err != nil {
+			return nil, p.errorf("malformed glob %q", arg)
+		}
+	}
+	if !p.consume(")") {
+		return nil, p.errorf(`expect ")"`)
+	}
+	return &webhookFilterCall{predicate: predicate, arg: arg}, nil
+}
+
+// parseString parses a double-quoted string with Go escape sequences.
+func (p *webhookFilterParser) parseString() (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.s) || p.s[p.pos] != '"' {
+		return "", p.errorf("expect a quoted string")
+	}
+
+	start := p.pos
+	for p.pos++; p.pos < len(p.s); p.pos++ {
+		switch p.s[p.pos] {
+		case '\\':
+			p.pos++
+		case '"':
+			p.pos++
+			s, err := strconv.Unquote(p.s[start:p.pos])
+			if err != nil {
+				p.pos = start
+				return "", p.errorf("malformed string")
+			}
+			return s, nil
+		}
+	}
+	p.pos = start
+	return "", p.errorf("unterminated string")
+}
+
+// parseWebhookFilter parses the webhook filter. It returns nil for an empty
+// filter, which matches all payloads.
+func parseWebhookFilter(filter string) (webhookFilterExpr, error) {
+	if strings.TrimSpace(filter) == "" {
+		return nil, nil
+	} else if len(filter) > webhookFilterMaxLength {
+		return nil, ErrWebhookFilterInvalid{args: errutil.Args{
+			"reason": fmt.Sprintf("expression is longer than %d characters", webhookFilterMaxLength),
+		}}
+	}
+
+	p := &webhookFilterParser{s: filter}
+	x, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return x, nil
+}
+
+// ValidateWebhookFilter returns ErrWebhookFilterInvalid if the webhook filter
+// is malformed.
+func ValidateWebhookFilter(filter string) error {
+	_, err := parseWebhookFilter(filter)
+	return err
+}
+
+// matchFilter returns true if the payload matches the filter of the webhook, or
+// the webhook has no filter.
+func (w *Webhook) matchFilter(p api.Payloader) (bool, error) {
+	x, err := parseWebhookFilter(w.Filter)
+	if err != nil {
+		return false, err
+	} else if x == nil {
+		return true, nil
+	}
+	return x.eval(newWebhookFilterPayload(p)), nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func TestValidateWebhookFilter(t *testing.T) {
+	tests := []struct {
+		filter     string
+		wantReason string
+	}{
+		{filter: ""},
+		{filter: "  "},
+		{filter: `path("src/**")`},
+		{filter: ` !( branch("release/*") || label("bug \"critical\"") ) && sender("alice") `},
+		{filter: `path(src)`, wantReason: "expect a quoted string at position 6"},
+		{filter: `path("src/**"`, wantReason: `expect ")" at position 14`},
+		{filter: `path("src/**") &&`, wantReason: "expect a predicate at position 18"},
+		{filter: `path("src/**") label("bug")`, wantReason: `unexpected "label(\"bug\")" at position 16`},
+		{filter: `files("src/**")`, wantReason: `unknown predicate "files" at position 1`},
+		{filter: `branch("release/[")`, wantReason: `malformed glob "release/[" at position 19`},
+		{filter: `label("bug)`, wantReason: "unterminated string at position 7"},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			err := ValidateWebhookFilter(test.filter)
+			if test.wantReason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.True(t, IsErrWebhookFilterInvalid(err), "want ErrWebhookFilterInvalid but got %v", err)
+			assert.Equal(t, test.wantReason, err.(ErrWebhookFilterInvalid).Reason())
+		})
+	}
+}
+
+func TestWebhook_matchFilter(t *testing.T) {
+	push := &api.PushPayload{
+		Ref: "refs/heads/main",
+		Commits: []*api.PayloadCommit{
+			{Modified: []string{"README.md"}},
+			{Added: []string{"src/main.go"}},
+		},
+		Sender: &api.User{UserName: "alice"},
+	}
+	issue := &IssuesLabelsPayload{
+		IssuesPayload: &api.IssuesPayload{
+			Action: api.HOOK_ISSUE_LABEL_UPDATED,
+			Issue:  &api.Issue{Labels: []*api.Label{{Name: "Bug"}}},
+			Sender: &api.User{UserName: "bob"},
+		},
+	}
+
+	tests := []struct {
+		filter    string
+		payload   api.Payloader
+		wantMatch bool
+	}{
+		{filter: "", payload: push, wantMatch: true},
+		{filter: `path("src/**")`, payload: push, wantMatch: true},
+		{filter: `path("docs/**")`, payload: push, wantMatch: false},
+		{filter: `path("*.md") && branch("main")`, payload: push, wantMatch: true},
+		{filter: `!sender("alice")`, payload: push, wantMatch: false},
+		{filter: `branch("release/*") || sender("ALICE")`, payload: push, wantMatch: true},
+		{filter: `label("bug")`, payload: push, wantMatch: false},
+		{filter: `label("bug")`, payload: issue, wantMatch: true},
+		{filter: `label("bug") && action("label_updated")`, payload: issue, wantMatch: true},
+		{filter: `label("bug") && !(action("label_updated") || path("**"))`, payload: issue, wantMatch: false},
+	}
+	for _, test := range tests {
+		t.Run(test.filter, func(t *testing.T) {
+			w := &Webhook{Filter: test.filter}
+			matched, err := w.matchFilter(test.payload)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantMatch, matched)
+		})
+	}
+}
+
+func TestPrepareWebhooks_filter(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Webhook), new(HookTask))
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example", DefaultBranch: "main"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent:    &HookEvent{PushOnly: true},
+		IsActive:     true,
+		Filter:       `path("src/**")`,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	countTasks := func(t *testing.T) int64 {
+		t.Helper()
+		count, err := x.Count(new(HookTask))
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("no matching files changed", func(t *testing.T) {
+		err := PrepareWebhooks(repo, HOOK_EVENT_PUSH, &api.PushPayload{
+			Ref:     "refs/heads/main",
+			Commits: []*api.PayloadCommit{{Modified: []string{"README.md", "docs/src/index.md"}}},
+		})
+		require.NoError(t, err)
+		assert.Zero(t, countTasks(t))
+	})
+
+	t.Run("matching files changed", func(t *testing.T) {
+		err := PrepareWebhooks(repo, HOOK_EVENT_PUSH, &api.PushPayload{
+			Ref:     "refs/heads/main",
+			Commits: []*api.PayloadCommit{{Modified: []string{"README.md"}}, {Removed: []string{"src/old.go"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), countTasks(t))
+	})
+
+	t.Run("test delivery", func(t *testing.T) {
+		err := TestWebhook(repo, HOOK_EVENT_PUSH, &api.PushPayload{
+			Ref:     "refs/heads/main",
+			Commits: []*api.PayloadCommit{{Modified: []string{"README.md"}}},
+		}, hook.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), countTasks(t))
+	})
+}
+
+func TestPushChangedPaths(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+
+	repoPath := RepoPath("alice", "example")
+	err := git.Init(repoPath, git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	workPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+		return string(stdout)
+	}
+	commit := func(name string) string {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(workPath, name)), os.ModePerm)
+		require.NoError(t, err)
+		err = os.WriteFile(filepath.Join(workPath, name), []byte(name), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Add "+name)
+		return run("rev-parse", "HEAD")[:40]
+	}
+
+	run("init", "-b", "main")
+	run("remote", "add", "origin", repoPath)
+	before := commit("README.md")
+	run("push", "origin", "main")
+	commit("src/main.go")
+	after := commit("docs/index.md")
+	run("push", "origin", "main")
+
+	run("checkout", "-b", "feature")
+	featureAfter := commit("feature.go")
+	run("push", "origin", "feature")
+
+	repo := &api.Repository{Owner: &api.User{UserName: "alice"}, Name: "example"}
+	tests := []struct {
+		name      string
+		payload   *api.PushPayload
+		wantPaths []string
+	}{
+		{
+			name: "commits truncated",
+			payload: &api.PushPayload{
+				Ref:     "refs/heads/main",
+				Before:  before,
+				After:   after,
+				Commits: []*api.PayloadCommit{{Added: []string{"docs/index.md"}}},
+				Repo:    repo,
+			},
+			wantPaths: []string{"docs/index.md", "src/main.go"},
+		},
+		{
+			name: "new branch",
+			payload: &api.PushPayload{
+				Ref:    "refs/heads/feature",
+				Before: git.EmptyID,
+				After:  featureAfter,
+				Repo:   repo,
+			},
+			wantPaths: []string{"feature.go"},
+		},
+		{
+			name: "no repository",
+			payload: &api.PushPayload{
+				Ref:     "refs/heads/main",
+				Commits: []*api.PayloadCommit{{Modified: []string{"README.md"}}},
+			},
+			wantPaths: []string{"README.md"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paths, err := pushChangedPaths(test.payload)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, test.wantPaths, paths)
+		})
+	}
+
+	// Files changed by commits that are dropped from the payload still match
+	w := &Webhook{Filter: `path("src/**")`}
+	matched, err := w.matchFilter(tests[0].payload)
+	require.NoError(t, err)
+	assert.True(t, matched)
+}
@@ -0,0 +1,316 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/gogs/git-module"
+	api "github.com/gogs/go-gogs-client"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// MSTeamsMeta contains hook-specific attributes of Microsoft Teams webhooks.
+type MSTeamsMeta struct {
+	ThemeColor string `json:"theme_color"`
+}
+
+type MSTeamsFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type MSTeamsSection struct {
+	ActivityTitle    string         `json:"activityTitle"`
+	ActivitySubtitle string         `json:"activitySubtitle,omitempty"`
+	ActivityImage    string         `json:"activityImage,omitempty"`
+	Text             string         `json:"text,omitempty"`
+	Facts            []*MSTeamsFact `json:"facts,omitempty"`
+	Markdown         bool           `json:"markdown"`
+}
+
+type MSTeamsActionTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+type MSTeamsAction struct {
+	Type    string                 `json:"@type"`
+	Name    string                 `json:"name"`
+	Targets []*MSTeamsActionTarget `json:"targets"`
+}
+
+// MSTeamsPayload is a legacy actionable message card accepted by incoming
+// webhooks of Microsoft Teams.
+//
+// See https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference.
+type MSTeamsPayload struct {
+	Type            string            `json:"@type"`
+	Context         string            `json:"@context"`
+	ThemeColor      string            `json:"themeColor,omitempty"`
+	Summary         string            `json:"summary"`
+	Title           string            `json:"title"`
+	Sections        []*MSTeamsSection `json:"sections"`
+	PotentialAction []*MSTeamsAction  `json:"potentialAction,omitempty"`
+}
+
+func (p *MSTeamsPayload) JSONPayload() ([]byte, error) {
+	data, err := jsoniter.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+type msteamsPayloadBuilder struct {
+	meta *MSTeamsMeta
+}
+
+// newCard returns a message card with a single section about the sender that
+// links to the given URL.
+func (b *msteamsPayloadBuilder) newCard(title, text string, sender *api.User, linkName, link string, facts ...*MSTeamsFact) *MSTeamsPayload {
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: strings.TrimLeft(b.meta.ThemeColor, "#"),
+		Summary:    title,
+		Title:      title,
+		Sections: []*MSTeamsSection{{
+			ActivityTitle: sender.UserName,
+			ActivityImage: sender.AvatarUrl,
+			Text:          text,
+			Facts:         facts,
+			Markdown:      true,
+		}},
+		PotentialAction: []*MSTeamsAction{{
+			Type: "OpenUri",
+			Name: linkName,
+			Targets: []*MSTeamsActionTarget{{
+				OS:  "default",
+				URI: link,
+			}},
+		}},
+	}
+}
+
+func (b *msteamsPayloadBuilder) Create(p *api.CreatePayload) api.Payloader {
+	refName := git.RefShortName(p.Ref)
+	return b.newCard(
+		fmt.Sprintf("[%s] New %s created: %s", p.Repo.FullName, p.RefType, refName),
+		"",
+		p.Sender,
+		"View "+p.RefType, p.Repo.HTMLURL+"/src/"+refName,
+	)
+}
+
+func (b *msteamsPayloadBuilder) Delete(p *api.DeletePayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] %s deleted: %s", p.Repo.FullName, strings.Title(p.RefType), git.RefShortName(p.Ref)),
+		"",
+		p.Sender,
+		"View repository", p.Repo.HTMLURL,
+	)
+}
+
+func (b *msteamsPayloadBuilder) Fork(p *api.ForkPayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Forked to %s", p.Repo.FullName, p.Forkee.FullName),
+		"",
+		p.Sender,
+		"View fork", p.Forkee.HTMLURL,
+	)
+}
+
+func (b *msteamsPayloadBuilder) Push(p *api.PushPayload) api.Payloader {
+	branchName := git.RefShortName(p.Ref)
+
+	commitDesc := "1 new commit"
+	if len(p.Commits) != 1 {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+	}
+
+	lines := make([]string, len(p.Commits))
+	for i, commit := range p.Commits {
+		lines[i] = fmt.Sprintf("%s %s - %s",
+			MarkdownLinkFormatter(commit.URL, commit.ID[:7]),
+			strings.Split(commit.Message, "\n")[0],
+			commit.Author.Name,
+		)
+	}
+
+	link := p.CompareURL
+	if link == "" {
+		link = p.Repo.HTMLURL + "/src/" + branchName
+	}
+	return b.newCard(
+		fmt.Sprintf("[%s:%s] %s", p.Repo.FullName, branchName, commitDesc),
+		// Teams only breaks lines of Markdown text on blank lines.
+		strings.Join(lines, "\n\n"),
+		p.Sender,
+		"View changes", link,
+		&MSTeamsFact{Name: "Repository", Value: p.Repo.FullName},
+		&MSTeamsFact{Name: "Branch", Value: branchName},
+		&MSTeamsFact{Name: "Pusher", Value: p.Pusher.UserName},
+	)
+}
+
+func (b *msteamsPayloadBuilder) Issues(p *api.IssuesPayload) api.Payloader {
+	var facts []*MSTeamsFact
+	switch p.Action {
+	case api.HOOK_ISSUE_ASSIGNED:
+		facts = append(facts, &MSTeamsFact{Name: "Assignee", Value: p.Issue.Assignee.UserName})
+	case api.HOOK_ISSUE_MILESTONED:
+		facts = append(facts, &MSTeamsFact{Name: "Milestone", Value: p.Issue.Milestone.Title})
+	case api.HOOK_ISSUE_LABEL_UPDATED:
+		facts = append(facts, &MSTeamsFact{Name: "Labels", Value: msteamsLabels(p.Issue.Labels)})
+	}
+
+	var text string
+	if p.Action == api.HOOK_ISSUE_OPENED || p.Action == api.HOOK_ISSUE_EDITED {
+		text = p.Issue.Body
+	}
+	return b.newCard(
+		fmt.Sprintf("[%s] Issue %s: #%d %s", p.Repository.FullName, msteamsAction(p.Action), p.Index, p.Issue.Title),
+		text,
+		p.Sender,
+		"View issue", fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index),
+		facts...,
+	)
+}
+
+func (b *msteamsPayloadBuilder) IssueComment(p *api.IssueCommentPayload) api.Payloader {
+	link := fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index)
+	if p.Action != api.HOOK_ISSUE_COMMENT_DELETED {
+		link += "#" + CommentHashTag(p.Comment.ID)
+	}
+	return b.newCard(
+		fmt.Sprintf("[%s] Comment %s: #%d %s", p.Repository.FullName, p.Action, p.Issue.Index, p.Issue.Title),
+		p.Comment.Body,
+		p.Sender,
+		"View comment", link,
+	)
+}
+
+func (b *msteamsPayloadBuilder) PullRequest(p *api.PullRequestPayload) api.Payloader {
+	var facts []*MSTeamsFact
+	switch p.Action {
+	case api.HOOK_ISSUE_OPENED:
+		facts = append(facts, &MSTeamsFact{Name: "Branches", Value: p.PullRequest.HeadBranch + " → " + p.PullRequest.BaseBranch})
+	case api.HOOK_ISSUE_ASSIGNED:
+		facts = append(facts, &MSTeamsFact{Name: "Assignee", Value: p.PullRequest.Assignee.UserName})
+	case api.HOOK_ISSUE_MILESTONED:
+		facts = append(facts, &MSTeamsFact{Name: "Milestone", Value: p.PullRequest.Milestone.Title})
+	case api.HOOK_ISSUE_LABEL_UPDATED:
+		facts = append(facts, &MSTeamsFact{Name: "Labels", Value: msteamsLabels(p.PullRequest.Labels)})
+	}
+
+	action := msteamsAction(p.Action)
+	if p.Action == api.HOOK_ISSUE_CLOSED && p.PullRequest.HasMerged {
+		action = "merged"
+	}
+
+	var text string
+	if p.Action == api.HOOK_ISSUE_OPENED || p.Action == api.HOOK_ISSUE_EDITED {
+		text = p.PullRequest.Body
+	}
+	return b.newCard(
+		fmt.Sprintf("[%s] Pull request %s: #%d %s", p.Repository.FullName, action, p.Index, p.PullRequest.Title),
+		text,
+		p.Sender,
+		"View pull request", fmt.Sprintf("%s/pulls/%d", p.Repository.HTMLURL, p.Index),
+		facts...,
+	)
+}
+
+func (b *msteamsPayloadBuilder) Release(p *ReleasePayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Release %s: %s", p.Repository.FullName, p.Action, p.Release.TagName),
+		p.Release.Body,
+		p.Sender,
+		"View release", p.Repository.HTMLURL+"/src/"+p.Release.TagName,
+		&MSTeamsFact{Name: "Title", Value: p.Release.Name},
+		&MSTeamsFact{Name: "Tag", Value: p.Release.TagName},
+	)
+}
+
+func (b *msteamsPayloadBuilder) RepositoryEdited(p *RepositoryEditedPayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Repository settings edited", p.Repository.FullName),
+		strings.Join(p.changeLines(), "\n\n"),
+		p.Sender,
+		"View repository", p.Repository.HTMLURL,
+	)
+}
+
+func (b *msteamsPayloadBuilder) Deployment(p *DeploymentPayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Deployment created: %s to %s", p.Repository.FullName, p.Deployment.Ref, p.Deployment.Environment),
+		p.Deployment.Description,
+		p.Sender,
+		"View commit", p.Repository.HTMLURL+"/commit/"+p.Deployment.SHA,
+		&MSTeamsFact{Name: "Task", Value: p.Deployment.Task},
+		&MSTeamsFact{Name: "Environment", Value: p.Deployment.Environment},
+	)
+}
+
+func (b *msteamsPayloadBuilder) DeploymentStatus(p *DeploymentStatusPayload) api.Payloader {
+	targetURL := p.DeploymentStatus.TargetURL
+	if targetURL == "" {
+		targetURL = p.Repository.HTMLURL + "/commit/" + p.Deployment.SHA
+	}
+	return b.newCard(
+		fmt.Sprintf("[%s] Deployment %s: %s to %s", p.Repository.FullName, msteamsDeploymentState(p.DeploymentStatus.State), p.Deployment.Ref, p.Deployment.Environment),
+		p.DeploymentStatus.Description,
+		p.Sender,
+		"View deployment", targetURL,
+		&MSTeamsFact{Name: "Task", Value: p.Deployment.Task},
+		&MSTeamsFact{Name: "Environment", Value: p.Deployment.Environment},
+	)
+}
+
+func (b *msteamsPayloadBuilder) Membership(p *MembershipPayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Member %s: %s", p.Organization.UserName, p.Action, p.Member.UserName),
+		"",
+		p.Sender,
+		"View members", conf.Server.ExternalURL+"org/"+p.Organization.UserName+"/members",
+	)
+}
+
+func (b *msteamsPayloadBuilder) Team(p *TeamPayload) api.Payloader {
+	return b.newCard(
+		fmt.Sprintf("[%s] Team %s member %s: %s", p.Organization.UserName, p.Team.Name, p.Action, p.Member.UserName),
+		"",
+		p.Sender,
+		"View team", conf.Server.ExternalURL+"org/"+p.Organization.UserName+"/teams/"+strings.ToLower(p.Team.Name),
+		&MSTeamsFact{Name: "Permission", Value: p.Team.Permission},
+	)
+}
+
+// msteamsDeploymentState returns the human-readable form of the deployment
+// state.
+func msteamsDeploymentState(state DeploymentState) string {
+	return strings.ReplaceAll(string(state), "_", " ")
+}
+
+// msteamsAction returns the human-readable form of the issue action.
+func msteamsAction(action api.HookIssueAction) string {
+	return strings.ReplaceAll(string(action), "_", " ")
+}
+
+func msteamsLabels(labels []*api.Label) string {
+	if len(labels) == 0 {
+		return "<empty>"
+	}
+	names := make([]string, len(labels))
+	for i := range labels {
+		names[i] = labels[i].Name
+	}
+	return strings.Join(names, ", ")
+}
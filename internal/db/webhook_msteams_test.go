@@ -0,0 +1,82 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	api "github.com/gogs/go-gogs-client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMSTeamsPayloadBuilder_Push(t *testing.T) {
+	w := &Webhook{
+		HookTaskType: MSTEAMS,
+		Meta:         `{"theme_color":"#0076d7"}`,
+	}
+	builder, err := newPayloadBuilder(w)
+	require.NoError(t, err)
+
+	sender := &api.User{UserName: "alice", AvatarUrl: "https://example.com/avatar.png"}
+	p := &api.PushPayload{
+		Ref:        "refs/heads/main",
+		CompareURL: "https://example.com/alice/example/compare/1234567...89abcde",
+		Commits: []*api.PayloadCommit{
+			{
+				ID:      "1234567890abcdef",
+				Message: "Fix typo\n\nLong description",
+				URL:     "https://example.com/alice/example/commit/1234567890abcdef",
+				Author:  &api.PayloadUser{Name: "Alice"},
+			},
+			{
+				ID:      "89abcdef01234567",
+				Message: "Add feature",
+				URL:     "https://example.com/alice/example/commit/89abcdef01234567",
+				Author:  &api.PayloadUser{Name: "Bob"},
+			},
+		},
+		Repo:   &api.Repository{FullName: "alice/example", HTMLURL: "https://example.com/alice/example"},
+		Pusher: sender,
+		Sender: sender,
+	}
+	payloader, err := buildPayload(builder, p, HOOK_EVENT_PUSH)
+	require.NoError(t, err)
+
+	want := &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: "0076d7",
+		Summary:    "[alice/example:main] 2 new commits",
+		Title:      "[alice/example:main] 2 new commits",
+		Sections: []*MSTeamsSection{{
+			ActivityTitle: "alice",
+			ActivityImage: "https://example.com/avatar.png",
+			Text: "[1234567](https://example.com/alice/example/commit/1234567890abcdef) Fix typo - Alice\n\n" +
+				"[89abcde](https://example.com/alice/example/commit/89abcdef01234567) Add feature - Bob",
+			Facts: []*MSTeamsFact{
+				{Name: "Repository", Value: "alice/example"},
+				{Name: "Branch", Value: "main"},
+				{Name: "Pusher", Value: "alice"},
+			},
+			Markdown: true,
+		}},
+		PotentialAction: []*MSTeamsAction{{
+			Type: "OpenUri",
+			Name: "View changes",
+			Targets: []*MSTeamsActionTarget{{
+				OS:  "default",
+				URI: "https://example.com/alice/example/compare/1234567...89abcde",
+			}},
+		}},
+	}
+	assert.Equal(t, want, payloader)
+
+	got, err := payloader.JSONPayload()
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `"@type": "MessageCard"`)
+	assert.Contains(t, string(got), `"@context": "https://schema.org/extensions"`)
+	assert.Contains(t, string(got), `"themeColor": "0076d7"`)
+}
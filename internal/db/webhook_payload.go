@@ -0,0 +1,95 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+
+	api "github.com/gogs/go-gogs-client"
+)
+
+// PayloadBuilder composes the message of a chat integration for each type of
+// event. Adding a new chat integration is a matter of implementing this
+// interface and returning it from newPayloadBuilder for its hook task type.
+type PayloadBuilder interface {
+	Create(p *api.CreatePayload) api.Payloader
+	Delete(p *api.DeletePayload) api.Payloader
+	Fork(p *api.ForkPayload) api.Payloader
+	Push(p *api.PushPayload) api.Payloader
+	Issues(p *api.IssuesPayload) api.Payloader
+	IssueComment(p *api.IssueCommentPayload) api.Payloader
+	PullRequest(p *api.PullRequestPayload) api.Payloader
+	Release(p *ReleasePayload) api.Payloader
+	RepositoryEdited(p *RepositoryEditedPayload) api.Payloader
+	Deployment(p *DeploymentPayload) api.Payloader
+	DeploymentStatus(p *DeploymentStatusPayload) api.Payloader
+	Membership(p *MembershipPayload) api.Payloader
+	Team(p *TeamPayload) api.Payloader
+}
+
+// newPayloadBuilder returns the payload builder for the hook task type of the
+// webhook with its hook-specific attributes. It returns nil when payloads
+// should be delivered as they are, e.g. the Gogs type.
+func newPayloadBuilder(w *Webhook) (PayloadBuilder, error) {
+	switch w.HookTaskType {
+	case SLACK:
+		meta := &SlackMeta{}
+		if err := jsoniter.Unmarshal([]byte(w.Meta), meta); err != nil {
+			return nil, errors.Wrap(err, "unmarshal Slack meta")
+		}
+		return &slackPayloadBuilder{meta: meta}, nil
+	case DISCORD:
+		meta := &SlackMeta{}
+		if err := jsoniter.Unmarshal([]byte(w.Meta), meta); err != nil {
+			return nil, errors.Wrap(err, "unmarshal Discord meta")
+		}
+		return &discordPayloadBuilder{meta: meta}, nil
+	case DINGTALK:
+		return dingtalkPayloadBuilder{}, nil
+	case MSTEAMS:
+		meta := &MSTeamsMeta{}
+		if w.Meta != "" {
+			if err := jsoniter.Unmarshal([]byte(w.Meta), meta); err != nil {
+				return nil, errors.Wrap(err, "unmarshal Microsoft Teams meta")
+			}
+		}
+		return &msteamsPayloadBuilder{meta: meta}, nil
+	}
+	return nil, nil
+}
+
+// buildPayload composes the payload of the event using the builder.
+func buildPayload(b PayloadBuilder, p api.Payloader, event HookEventType) (api.Payloader, error) {
+	switch event {
+	case HOOK_EVENT_CREATE:
+		return b.Create(p.(*api.CreatePayload)), nil
+	case HOOK_EVENT_DELETE:
+		return b.Delete(p.(*api.DeletePayload)), nil
+	case HOOK_EVENT_FORK:
+		return b.Fork(p.(*api.ForkPayload)), nil
+	case HOOK_EVENT_PUSH:
+		return b.Push(p.(*api.PushPayload)), nil
+	case HOOK_EVENT_ISSUES:
+		return b.Issues(p.(*api.IssuesPayload)), nil
+	case HOOK_EVENT_ISSUE_COMMENT:
+		return b.IssueComment(p.(*api.IssueCommentPayload)), nil
+	case HOOK_EVENT_PULL_REQUEST:
+		return b.PullRequest(p.(*api.PullRequestPayload)), nil
+	case HOOK_EVENT_RELEASE:
+		return b.Release(p.(*ReleasePayload)), nil
+	case HOOK_EVENT_REPOSITORY_EDITED:
+		return b.RepositoryEdited(p.(*RepositoryEditedPayload)), nil
+	case HOOK_EVENT_DEPLOYMENT:
+		return b.Deployment(p.(*DeploymentPayload)), nil
+	case HOOK_EVENT_DEPLOYMENT_STATUS:
+		return b.DeploymentStatus(p.(*DeploymentStatusPayload)), nil
+	case HOOK_EVENT_MEMBERSHIP:
+		return b.Membership(p.(*MembershipPayload)), nil
+	case HOOK_EVENT_TEAM:
+		return b.Team(p.(*TeamPayload)), nil
+	}
+	return nil, errors.Errorf("unexpected event %q", event)
+}
@@ -9,7 +9,6 @@ import (
 	"strings"
 
 	jsoniter "github.com/json-iterator/go"
-	"github.com/pkg/errors"
 
 	"github.com/gogs/git-module"
 	api "github.com/gogs/go-gogs-client"
@@ -279,47 +278,113 @@ func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) *Sl
 	}
 }
 
-func getSlackReleasePayload(p *api.ReleasePayload) *SlackPayload {
+func getSlackReleasePayload(p *ReleasePayload) *SlackPayload {
 	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.Name)
 	refLink := SlackLinkFormatter(p.Repository.HTMLURL+"/src/"+p.Release.TagName, p.Release.TagName)
 	text := fmt.Sprintf("[%s] new release %s published by %s", repoLink, refLink, p.Sender.UserName)
+	if p.Action != api.HOOK_RELEASE_PUBLISHED {
+		text = fmt.Sprintf("[%s] release %s %s by %s", repoLink, refLink, p.Action, p.Sender.UserName)
+	}
 	return &SlackPayload{
 		Text: text,
 	}
 }
 
-func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (payload *SlackPayload, err error) {
-	slack := &SlackMeta{}
-	if err := jsoniter.Unmarshal([]byte(meta), &slack); err != nil {
-		return nil, fmt.Errorf("Unmarshal: %v", err)
-	}
+type slackPayloadBuilder struct {
+	meta *SlackMeta
+}
 
-	switch event {
-	case HOOK_EVENT_CREATE:
-		payload = getSlackCreatePayload(p.(*api.CreatePayload))
-	case HOOK_EVENT_DELETE:
-		payload = getSlackDeletePayload(p.(*api.DeletePayload))
-	case HOOK_EVENT_FORK:
-		payload = getSlackForkPayload(p.(*api.ForkPayload))
-	case HOOK_EVENT_PUSH:
-		payload = getSlackPushPayload(p.(*api.PushPayload), slack)
-	case HOOK_EVENT_ISSUES:
-		payload = getSlackIssuesPayload(p.(*api.IssuesPayload), slack)
-	case HOOK_EVENT_ISSUE_COMMENT:
-		payload = getSlackIssueCommentPayload(p.(*api.IssueCommentPayload), slack)
-	case HOOK_EVENT_PULL_REQUEST:
-		payload = getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack)
-	case HOOK_EVENT_RELEASE:
-		payload = getSlackReleasePayload(p.(*api.ReleasePayload))
-	default:
-		return nil, errors.Errorf("unexpected event %q", event)
+// decorate applies hook-specific attributes to the payload.
+func (b *slackPayloadBuilder) decorate(payload *SlackPayload) api.Payloader {
+	payload.Channel = b.meta.Channel
+	payload.Username = b.meta.Username
+	payload.IconURL = b.meta.IconURL
+	if len(payload.Attachments) > 0 {
+		payload.Attachments[0].Color = b.meta.Color
 	}
+	return payload
+}
 
-	payload.Channel = slack.Channel
-	payload.Username = slack.Username
-	payload.IconURL = slack.IconURL
-	if len(payload.Attachments) > 0 {
-		payload.Attachments[0].Color = slack.Color
+func (b *slackPayloadBuilder) Create(p *api.CreatePayload) api.Payloader {
+	return b.decorate(getSlackCreatePayload(p))
+}
+
+func (b *slackPayloadBuilder) Delete(p *api.DeletePayload) api.Payloader {
+	return b.decorate(getSlackDeletePayload(p))
+}
+
+func (b *slackPayloadBuilder) Fork(p *api.ForkPayload) api.Payloader {
+	return b.decorate(getSlackForkPayload(p))
+}
+
+func (b *slackPayloadBuilder) Push(p *api.PushPayload) api.Payloader {
+	return b.decorate(getSlackPushPayload(p, b.meta))
+}
+
+func (b *slackPayloadBuilder) Issues(p *api.IssuesPayload) api.Payloader {
+	return b.decorate(getSlackIssuesPayload(p, b.meta))
+}
+
+func (b *slackPayloadBuilder) IssueComment(p *api.IssueCommentPayload) api.Payloader {
+	return b.decorate(getSlackIssueCommentPayload(p, b.meta))
+}
+
+func (b *slackPayloadBuilder) PullRequest(p *api.PullRequestPayload) api.Payloader {
+	return b.decorate(getSlackPullRequestPayload(p, b.meta))
+}
+
+func (b *slackPayloadBuilder) Release(p *ReleasePayload) api.Payloader {
+	return b.decorate(getSlackReleasePayload(p))
+}
+
+func (b *slackPayloadBuilder) RepositoryEdited(p *RepositoryEditedPayload) api.Payloader {
+	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	senderLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Sender.UserName, p.Sender.UserName)
+	return b.decorate(&SlackPayload{
+		Text: fmt.Sprintf("[%s] Repository settings edited by %s", repoLink, senderLink),
+		Attachments: []*SlackAttachment{{
+			Color: b.meta.Color,
+			Text:  SlackTextFormatter(strings.Join(p.changeLines(), "\n")),
+		}},
+	})
+}
+
+func (b *slackPayloadBuilder) Deployment(p *DeploymentPayload) api.Payloader {
+	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	refLink := SlackLinkFormatter(p.Repository.HTMLURL+"/commit/"+p.Deployment.SHA, p.Deployment.Ref)
+	senderLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Sender.UserName, p.Sender.UserName)
+	return b.decorate(&SlackPayload{
+		Text: fmt.Sprintf("[%s] Deployment of %s to %s created by %s", repoLink, refLink, SlackTextFormatter(p.Deployment.Environment), senderLink),
+	})
+}
+
+func (b *slackPayloadBuilder) DeploymentStatus(p *DeploymentStatusPayload) api.Payloader {
+	repoLink := SlackLinkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
+	refLink := SlackLinkFormatter(p.Repository.HTMLURL+"/commit/"+p.Deployment.SHA, p.Deployment.Ref)
+	text := fmt.Sprintf("[%s] Deployment of %s to %s: %s", repoLink, refLink, SlackTextFormatter(p.Deployment.Environment), p.DeploymentStatus.State)
+	if p.DeploymentStatus.TargetURL != "" {
+		text += " " + SlackLinkFormatter(p.DeploymentStatus.TargetURL, "details")
 	}
-	return payload, nil
+	return b.decorate(&SlackPayload{
+		Text: text,
+	})
+}
+
+func (b *slackPayloadBuilder) Membership(p *MembershipPayload) api.Payloader {
+	orgLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Organization.UserName, p.Organization.UserName)
+	memberLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Member.UserName, p.Member.UserName)
+	senderLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Sender.UserName, p.Sender.UserName)
+	return b.decorate(&SlackPayload{
+		Text: fmt.Sprintf("[%s] Member %s %s by %s", orgLink, memberLink, p.Action, senderLink),
+	})
+}
+
+func (b *slackPayloadBuilder) Team(p *TeamPayload) api.Payloader {
+	orgLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Organization.UserName, p.Organization.UserName)
+	teamLink := SlackLinkFormatter(conf.Server.ExternalURL+"org/"+p.Organization.UserName+"/teams/"+strings.ToLower(p.Team.Name), p.Team.Name)
+	memberLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Member.UserName, p.Member.UserName)
+	senderLink := SlackLinkFormatter(conf.Server.ExternalURL+p.Sender.UserName, p.Sender.UserName)
+	return b.decorate(&SlackPayload{
+		Text: fmt.Sprintf("[%s] Member %s of team %s %s by %s", orgLink, memberLink, teamLink, p.Action, senderLink),
+	})
 }
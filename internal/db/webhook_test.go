@@ -0,0 +1,190 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/gogs/go-gogs-client"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_hasEvent(t *testing.T) {
+	pushOnly := &Webhook{HookEvent: &HookEvent{PushOnly: true}}
+	assert.True(t, pushOnly.hasEvent(HOOK_EVENT_PUSH, &api.PushPayload{}))
+	assert.False(t, pushOnly.hasEvent(HOOK_EVENT_ISSUES, &api.IssuesPayload{}))
+
+	labelOnly := &Webhook{HookEvent: &HookEvent{ChooseEvents: true, HookEvents: HookEvents{Label: true}}}
+	assert.False(t, labelOnly.hasEvent(HOOK_EVENT_ISSUES, &api.IssuesPayload{}))
+	assert.True(t, labelOnly.hasEvent(HOOK_EVENT_ISSUES, &IssuesLabelsPayload{IssuesPayload: &api.IssuesPayload{}}))
+	assert.True(t, labelOnly.hasEvent(HOOK_EVENT_PULL_REQUEST, &PullRequestLabelsPayload{PullRequestPayload: &api.PullRequestPayload{}}))
+}
+
+func Test_mergeWebhooks(t *testing.T) {
+	repoHook := &Webhook{ID: 1, RepoID: 1, URL: "https://example.com/a", Secret: "s1", HookEvent: &HookEvent{PushOnly: true}}
+	orgHook := &Webhook{ID: 2, OrgID: 2, URL: "https://example.com/b", HookEvent: &HookEvent{PushOnly: true}}
+	orgHookDup := &Webhook{ID: 3, OrgID: 2, URL: "https://example.com/a", Secret: "s1", HookEvent: &HookEvent{PushOnly: true}}
+	orgHookOtherSecret := &Webhook{ID: 4, OrgID: 2, URL: "https://example.com/a", Secret: "s2", HookEvent: &HookEvent{PushOnly: true}}
+
+	t.Run("org hook fires for a push in its repository", func(t *testing.T) {
+		got := mergeWebhooks(nil, []*Webhook{orgHook})
+		assert.Equal(t, []*Webhook{orgHook}, got)
+		assert.True(t, got[0].hasEvent(HOOK_EVENT_PUSH, &api.PushPayload{}))
+	})
+
+	t.Run("repo and org hooks are merged", func(t *testing.T) {
+		got := mergeWebhooks([]*Webhook{repoHook}, []*Webhook{orgHook})
+		assert.Equal(t, []*Webhook{repoHook, orgHook}, got)
+	})
+
+	t.Run("same endpoint is delivered once", func(t *testing.T) {
+		got := mergeWebhooks([]*Webhook{repoHook}, []*Webhook{orgHookDup, orgHookOtherSecret})
+		assert.Equal(t, []*Webhook{repoHook, orgHookOtherSecret}, got)
+	})
+}
+
+func TestPrepareRepositoryEditedWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(User), new(Repository), new(Webhook), new(HookTask))
+
+	owner := &User{ID: 1, LowerName: "alice", Name: "alice", Email: "alice@example.com"}
+	_, err := x.Insert(owner)
+	require.NoError(t, err)
+	repo := &Repository{ID: 1, OwnerID: owner.ID, Owner: owner, LowerName: "example", Name: "example", DefaultBranch: "master"}
+	_, err = x.Insert(repo)
+	require.NoError(t, err)
+	hook := &Webhook{
+		RepoID:       repo.ID,
+		URL:          "https://example.com/hook",
+		HookTaskType: GOGS,
+		HookEvent:    &HookEvent{SendEverything: true},
+		IsActive:     true,
+	}
+	err = hook.UpdateEvent()
+	require.NoError(t, err)
+	err = CreateWebhook(hook)
+	require.NoError(t, err)
+
+	t.Run("no-op save does not fire", func(t *testing.T) {
+		old := *repo
+		err := PrepareRepositoryEditedWebhooks(owner, &old, repo)
+		require.NoError(t, err)
+
+		count, err := x.Count(new(HookTask))
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("default branch changed", func(t *testing.T) {
+		old := *repo
+		repo.DefaultBranch = "main"
+		err := PrepareRepositoryEditedWebhooks(owner, &old, repo)
+		require.NoError(t, err)
+
+		var tasks []*HookTask
+		err = x.Find(&tasks)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, HOOK_EVENT_REPOSITORY_EDITED, tasks[0].EventType)
+
+		var payload RepositoryEditedPayload
+		err = jsoniter.Unmarshal([]byte(tasks[0].PayloadContent), &payload)
+		require.NoError(t, err)
+		want := map[string]*RepositoryChange{
+			"default_branch": {From: "master", To: "main"},
+		}
+		assert.Equal(t, want, payload.Changes)
+		assert.Equal(t, "alice", payload.Sender.UserName)
+	})
+}
+
+func TestPruneHookTasks(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	setTestEngine(t, new(HookTask))
+
+	now := time.Now()
+	insert := func(t *testing.T, hookID int64, delivered bool, deliveredAt time.Time) *HookTask {
+		task := &HookTask{RepoID: 1, HookID: hookID, IsDelivered: delivered}
+		if delivered {
+			task.Delivered = deliveredAt.UnixNano()
+		}
+		_, err := x.Insert(task)
+		require.NoError(t, err)
+		return task
+	}
+	remainingIDs := func(t *testing.T) []int64 {
+		var ids []int64
+		err := x.Table("hook_task").Asc("id").Cols("id").Find(&ids)
+		require.NoError(t, err)
+		return ids
+	}
+
+	t.Run("by age", func(t *testing.T) {
+		t.Cleanup(func() {
+			_, err := x.Where("id > 0").Delete(new(HookTask))
+			require.NoError(t, err)
+		})
+
+		_ = insert(t, 1, true, now.Add(-48*time.Hour))
+		oldPending := insert(t, 1, false, time.Time{})
+		recent := insert(t, 1, true, now.Add(-time.Hour))
+
+		deleted, err := pruneHookTasks(0, now.Add(-24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+		assert.Equal(t, []int64{oldPending.ID, recent.ID}, remainingIDs(t))
+	})
+
+	t.Run("by count", func(t *testing.T) {
+		t.Cleanup(func() {
+			_, err := x.Where("id > 0").Delete(new(HookTask))
+			require.NoError(t, err)
+		})
+
+		// Spans multiple batches of the first webhook
+		var want []int64
+		for i := 0; i < hookTaskPruneBatchSize*2+10; i++ {
+			task := insert(t, 1, true, now)
+			if i >= hookTaskPruneBatchSize*2+10-3 {
+				want = append(want, task.ID)
+			}
+		}
+		pending := insert(t, 1, false, time.Time{})
+		want = append(want, pending.ID)
+		for i := 0; i < 3; i++ {
+			task := insert(t, 2, true, now)
+			want = append(want, task.ID)
+		}
+
+		deleted, err := pruneHookTasks(3, time.Time{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(hookTaskPruneBatchSize*2+10-3), deleted)
+		assert.Equal(t, want, remainingIDs(t))
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		t.Cleanup(func() {
+			_, err := x.Where("id > 0").Delete(new(HookTask))
+			require.NoError(t, err)
+		})
+
+		old := insert(t, 1, true, now.Add(-48*time.Hour))
+		recent := insert(t, 1, true, now)
+
+		deleted, err := pruneHookTasks(0, time.Time{})
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		assert.Equal(t, []int64{old.ID, recent.ID}, remainingIDs(t))
+	})
+}
@@ -24,6 +24,14 @@ import (
 
 var wikiWorkingPool = sync.NewExclusivePool()
 
+const (
+	// WikiHomePage is the name of the page shown as the entry of the wiki.
+	WikiHomePage = "Home"
+	// WikiSidebarPage is the name of the page rendered as the sidebar of all
+	// other pages when it exists.
+	WikiSidebarPage = "_Sidebar"
+)
+
 // ToWikiPageURL formats a string to corresponding wiki URL name.
 func ToWikiPageURL(name string) string {
 	return url.QueryEscape(name)
@@ -73,6 +81,25 @@ func (repo *Repository) InitWiki() error {
 	return nil
 }
 
+// GetWikiPage returns the raw content of the wiki page with given name in the
+// latest revision of the wiki. It returns an error satisfying
+// gitutil.IsErrRevisionNotExist when the page does not exist.
+func (repo *Repository) GetWikiPage(name string) ([]byte, error) {
+	wikiRepo, err := git.Open(repo.WikiPath())
+	if err != nil {
+		return nil, fmt.Errorf("open repository: %v", err)
+	}
+	commit, err := wikiRepo.BranchCommit("master")
+	if err != nil {
+		return nil, err
+	}
+	blob, err := commit.Blob(ToWikiPageName(name) + ".md")
+	if err != nil {
+		return nil, err
+	}
+	return blob.Bytes()
+}
+
 func (repo *Repository) LocalWikiPath() string {
 	return filepath.Join(conf.Server.AppDataPath, "tmp", "local-wiki", com.ToStr(repo.ID))
 }
@@ -111,6 +138,11 @@ func (repo *Repository) updateWikiPage(doer *User, oldTitle, title, content, mes
 			return ErrWikiAlreadyExist{filename}
 		}
 	} else {
+		// Renaming must not overwrite another existing page.
+		oldTitle = ToWikiPageName(oldTitle)
+		if oldTitle != title && com.IsExist(filename) {
+			return ErrWikiAlreadyExist{filename}
+		}
 		os.Remove(path.Join(localPath, oldTitle+".md"))
 	}
 
@@ -136,7 +168,7 @@ func (repo *Repository) updateWikiPage(doer *User, oldTitle, title, content, mes
 		localPath,
 		&git.Signature{
 			Name:  doer.DisplayName(),
-			Email: doer.Email,
+			Email: doer.CommitEmail(),
 			When:  time.Now(),
 		},
 		message,
@@ -183,7 +215,7 @@ func (repo *Repository) DeleteWikiPage(doer *User, title string) (err error) {
 		localPath,
 		&git.Signature{
 			Name:  doer.DisplayName(),
-			Email: doer.Email,
+			Email: doer.CommitEmail(),
 			When:  time.Now(),
 		},
 		message,
@@ -0,0 +1,125 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/gitutil"
+	"gogs.io/gogs/internal/markup"
+)
+
+func TestToWikiPageName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "Home", want: "Home"},
+		{url: "Getting+Started", want: "Getting Started"},
+		{url: "../../authorized_keys", want: "authorized_keys"},
+		{url: "docs/install", want: "docs install"},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			assert.Equal(t, test.want, ToWikiPageName(test.url))
+		})
+	}
+}
+
+func TestRepository_WikiPages(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	repoOpts := conf.Repository
+	repoOpts.Root = t.TempDir()
+	conf.SetMockRepository(t, repoOpts)
+	serverOpts := conf.Server
+	serverOpts.AppDataPath = t.TempDir()
+	conf.SetMockServer(t, serverOpts)
+
+	alice := &User{ID: 1, Name: "alice", LowerName: "alice", Email: "alice@example.com"}
+	repo := &Repository{ID: 1, Name: "example", LowerName: "example", OwnerID: alice.ID, Owner: alice}
+
+	// NOTE: Initialize the wiki without delegate hooks, which require the gogs
+	// binary to accept pushes.
+	err := git.Init(repo.WikiPath(), git.InitOptions{Bare: true})
+	require.NoError(t, err)
+
+	wikiLog := func(t *testing.T) []*git.Commit {
+		wikiRepo, err := git.Open(repo.WikiPath())
+		require.NoError(t, err)
+		commits, err := wikiRepo.Log(git.RefsHeads + "master")
+		require.NoError(t, err)
+		return commits
+	}
+
+	t.Run("create and render", func(t *testing.T) {
+		err := repo.AddWikiPage(alice, WikiHomePage, "# Welcome\n\nSee [[Install]].", "")
+		require.NoError(t, err)
+
+		commits := wikiLog(t)
+		require.Len(t, commits, 1)
+		assert.Equal(t, "Update page 'Home'", commits[0].Summary())
+		assert.Equal(t, "alice", commits[0].Author.Name)
+
+		p, err := repo.GetWikiPage(WikiHomePage)
+		require.NoError(t, err)
+		assert.Equal(t, "# Welcome\n\nSee [[Install]].", string(p))
+
+		html := string(markup.Markdown(p, "/alice/example", nil))
+		assert.Contains(t, html, "<h1")
+		assert.Contains(t, html, "Welcome</h1>")
+
+		err = repo.AddWikiPage(alice, WikiHomePage, "Again", "")
+		assert.True(t, IsErrWikiAlreadyExist(err))
+	})
+
+	t.Run("edit and rename", func(t *testing.T) {
+		err := repo.AddWikiPage(alice, "Install", "Run the binary.", "Add install guide")
+		require.NoError(t, err)
+		err = repo.EditWikiPage(alice, "Install", "Install", "Run the binary with `web`.", "")
+		require.NoError(t, err)
+
+		commits := wikiLog(t)
+		require.Len(t, commits, 3)
+		assert.Equal(t, "Update page 'Install'", commits[0].Summary())
+		assert.Equal(t, "Add install guide", commits[1].Summary())
+
+		p, err := repo.GetWikiPage("Install")
+		require.NoError(t, err)
+		assert.Equal(t, "Run the binary with `web`.", string(p))
+
+		// Renaming to an existing page is rejected
+		err = repo.EditWikiPage(alice, "Install", WikiHomePage, "Overwritten", "")
+		assert.True(t, IsErrWikiAlreadyExist(err))
+
+		err = repo.EditWikiPage(alice, "Install", "Installation", "Run the binary with `web`.", "")
+		require.NoError(t, err)
+		assert.Len(t, wikiLog(t), 4)
+
+		_, err = repo.GetWikiPage("Install")
+		assert.True(t, gitutil.IsErrRevisionNotExist(err))
+		_, err = repo.GetWikiPage("Installation")
+		require.NoError(t, err)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		err := repo.DeleteWikiPage(alice, "Installation")
+		require.NoError(t, err)
+
+		commits := wikiLog(t)
+		require.Len(t, commits, 5)
+		assert.Equal(t, "Delete page 'Installation'", commits[0].Summary())
+
+		_, err = repo.GetWikiPage("Installation")
+		assert.True(t, gitutil.IsErrRevisionNotExist(err))
+	})
+}
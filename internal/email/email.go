@@ -5,6 +5,7 @@
 package email
 
 import (
+	"bytes"
 	"fmt"
 	"html/template"
 	"path/filepath"
@@ -26,10 +27,13 @@ const (
 	MAIL_AUTH_RESET_PASSWORD  = "auth/reset_passwd"
 	MAIL_AUTH_REGISTER_NOTIFY = "auth/register_notify"
 
-	MAIL_ISSUE_COMMENT = "issue/comment"
-	MAIL_ISSUE_MENTION = "issue/mention"
+	MAIL_ISSUE_COMMENT        = "issue/comment"
+	MAIL_ISSUE_MENTION        = "issue/mention"
+	MAIL_ISSUE_REVIEW_REQUEST = "issue/review_request"
 
-	MAIL_NOTIFY_COLLABORATOR = "notify/collaborator"
+	MAIL_NOTIFY_COLLABORATOR            = "notify/collaborator"
+	MAIL_NOTIFY_COLLABORATOR_INVITATION = "notify/collaborator_invitation"
+	MAIL_NOTIFY_ORG_MEMBER_INVITATION   = "notify/org_member_invitation"
 )
 
 var (
@@ -37,27 +41,43 @@ var (
 	tplRenderOnce sync.Once
 )
 
-// render renders a mail template with given data.
-func render(tpl string, data map[string]any) (string, error) {
+// funcMap returns the template functions available to mail templates.
+func funcMap() template.FuncMap {
+	return map[string]any{
+		"AppName": func() string {
+			return conf.App.BrandName
+		},
+		"AppURL": func() string {
+			return conf.Server.ExternalURL
+		},
+		"Year": func() int {
+			return time.Now().Year()
+		},
+		"Str2HTML": func(raw string) template.HTML {
+			return template.HTML(markup.Sanitize(raw))
+		},
+	}
+}
+
+// render renders a mail template with given data. The override for the given
+// language or the generic override is preferred over the built-in template when
+// present.
+func render(lang, tpl string, data map[string]any) (string, error) {
+	if t := lookupOverride(lang, tpl); t != nil {
+		var buf bytes.Buffer
+		err := t.Execute(&buf, data)
+		if err == nil {
+			return buf.String(), nil
+		}
+		log.Warn("Failed to render mail template override %q, falling back to built-in: %v", t.Name(), err)
+	}
+
 	tplRenderOnce.Do(func() {
 		opt := &macaron.RenderOptions{
 			Directory:         filepath.Join(conf.WorkDir(), "templates", "mail"),
 			AppendDirectories: []string{filepath.Join(conf.CustomDir(), "templates", "mail")},
 			Extensions:        []string{".tmpl", ".html"},
-			Funcs: []template.FuncMap{map[string]any{
-				"AppName": func() string {
-					return conf.App.BrandName
-				},
-				"AppURL": func() string {
-					return conf.Server.ExternalURL
-				},
-				"Year": func() int {
-					return time.Now().Year()
-				},
-				"Str2HTML": func(raw string) template.HTML {
-					return template.HTML(markup.Sanitize(raw))
-				},
-			}},
+			Funcs:             []template.FuncMap{funcMap()},
 		}
 		if !conf.Server.LoadAssetsFromDisk {
 			opt.TemplateFileSystem = templates.NewTemplateFileSystem("mail", opt.AppendDirectories[0])
@@ -74,6 +94,26 @@ func render(tpl string, data map[string]any) (string, error) {
 	return tplRender.HTMLString(tpl, data)
 }
 
+// contextLang returns the language of the current request, or the default
+// language of the site when there is none.
+func contextLang(c *macaron.Context) string {
+	if c != nil {
+		if lang, _ := c.Data["Lang"].(string); lang != "" {
+			return lang
+		}
+	}
+	return defaultLang()
+}
+
+// defaultLang returns the default language of the site, which is used by mails
+// not sent in response to a request of the recipient, e.g. issue notifications.
+func defaultLang() string {
+	if conf.I18n == nil || len(conf.I18n.Langs) == 0 {
+		return ""
+	}
+	return conf.I18n.Langs[0]
+}
+
 func SendTestMail(email string) error {
 	return gomail.Send(&Sender{}, NewMessage([]string{email}, "Gogs Test Email", "Hello 👋, greeting from Gogs!").Message)
 }
@@ -101,14 +141,14 @@ type Issue interface {
 	HTMLURL() string
 }
 
-func SendUserMail(_ *macaron.Context, u User, tpl, code, subject, info string) {
+func SendUserMail(c *macaron.Context, u User, tpl, code, subject, info string) {
 	data := map[string]any{
 		"Username":          u.DisplayName(),
 		"ActiveCodeLives":   conf.Auth.ActivateCodeLives / 60,
 		"ResetPwdCodeLives": conf.Auth.ResetPasswordCodeLives / 60,
 		"Code":              code,
 	}
-	body, err := render(tpl, data)
+	body, err := render(contextLang(c), tpl, data)
 	if err != nil {
 		log.Error("render: %v", err)
 		return
@@ -136,7 +176,7 @@ func SendActivateEmailMail(c *macaron.Context, u User, email string) {
 		"Code":            u.GenerateEmailActivateCode(email),
 		"Email":           email,
 	}
-	body, err := render(MAIL_AUTH_ACTIVATE_EMAIL, data)
+	body, err := render(contextLang(c), MAIL_AUTH_ACTIVATE_EMAIL, data)
 	if err != nil {
 		log.Error("HTMLString: %v", err)
 		return
@@ -153,7 +193,7 @@ func SendRegisterNotifyMail(c *macaron.Context, u User) {
 	data := map[string]any{
 		"Username": u.DisplayName(),
 	}
-	body, err := render(MAIL_AUTH_REGISTER_NOTIFY, data)
+	body, err := render(contextLang(c), MAIL_AUTH_REGISTER_NOTIFY, data)
 	if err != nil {
 		log.Error("HTMLString: %v", err)
 		return
@@ -174,7 +214,7 @@ func SendCollaboratorMail(u, doer User, repo Repository) {
 		"RepoName": repo.FullName(),
 		"Link":     repo.HTMLURL(),
 	}
-	body, err := render(MAIL_NOTIFY_COLLABORATOR, data)
+	body, err := render(defaultLang(), MAIL_NOTIFY_COLLABORATOR, data)
 	if err != nil {
 		log.Error("HTMLString: %v", err)
 		return
@@ -186,6 +226,52 @@ func SendCollaboratorMail(u, doer User, repo Repository) {
 	Send(msg)
 }
 
+// SendCollaboratorInvitationMail sends mail notification to the invitee of a
+// repository collaborator invitation.
+func SendCollaboratorInvitationMail(u, doer User, repo Repository) {
+	subject := fmt.Sprintf("%s invited you to collaborate on %s", doer.DisplayName(), repo.FullName())
+
+	data := map[string]any{
+		"Subject":  subject,
+		"Inviter":  doer.DisplayName(),
+		"RepoName": repo.FullName(),
+		"Link":     conf.Server.ExternalURL + "user/settings/repositories",
+	}
+	body, err := render(defaultLang(), MAIL_NOTIFY_COLLABORATOR_INVITATION, data)
+	if err != nil {
+		log.Error("HTMLString: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{u.Email()}, subject, body)
+	msg.Info = fmt.Sprintf("UID: %d, invite collaborator", u.ID())
+
+	Send(msg)
+}
+
+// SendOrgMemberInvitationMail sends mail notification to the invitee of an
+// organization member invitation.
+func SendOrgMemberInvitationMail(u, doer, org User) {
+	subject := fmt.Sprintf("%s invited you to join %s", doer.DisplayName(), org.DisplayName())
+
+	data := map[string]any{
+		"Subject": subject,
+		"Inviter": doer.DisplayName(),
+		"OrgName": org.DisplayName(),
+		"Link":    conf.Server.ExternalURL + "user/settings/organizations",
+	}
+	body, err := render(defaultLang(), MAIL_NOTIFY_ORG_MEMBER_INVITATION, data)
+	if err != nil {
+		log.Error("HTMLString: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{u.Email()}, subject, body)
+	msg.Info = fmt.Sprintf("UID: %d, invite organization member", u.ID())
+
+	Send(msg)
+}
+
 func composeTplData(subject, body, link string) map[string]any {
 	data := make(map[string]any, 10)
 	data["Subject"] = subject
@@ -194,18 +280,22 @@ func composeTplData(subject, body, link string) map[string]any {
 	return data
 }
 
-func composeIssueMessage(issue Issue, repo Repository, doer User, tplName string, tos []string, info string) *Message {
+func composeIssueMessage(issue Issue, repo Repository, doer User, tplName string, tos []string, info, unsubscribeLink string) *Message {
 	subject := issue.MailSubject()
 	body := string(markup.Markdown([]byte(issue.Content()), repo.HTMLURL(), repo.ComposeMetas()))
 	data := composeTplData(subject, body, issue.HTMLURL())
 	data["Doer"] = doer
-	content, err := render(tplName, data)
+	data["UnsubscribeLink"] = unsubscribeLink
+	content, err := render(defaultLang(), tplName, data)
 	if err != nil {
 		log.Error("HTMLString (%s): %v", tplName, err)
 	}
 	from := gomail.NewMessage().FormatAddress(conf.Email.FromEmail, doer.DisplayName())
 	msg := NewMessageFrom(tos, from, subject, content)
 	msg.Info = fmt.Sprintf("Subject: %s, %s", subject, info)
+	if unsubscribeLink != "" {
+		msg.SetHeader("List-Unsubscribe", "<"+unsubscribeLink+">")
+	}
 	return msg
 }
 
@@ -215,7 +305,7 @@ func SendIssueCommentMail(issue Issue, repo Repository, doer User, tos []string)
 		return
 	}
 
-	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_COMMENT, tos, "issue comment"))
+	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_COMMENT, tos, "issue comment", ""))
 }
 
 // SendIssueMentionMail composes and sends issue mention emails to target receivers.
@@ -223,5 +313,21 @@ func SendIssueMentionMail(issue Issue, repo Repository, doer User, tos []string)
 	if len(tos) == 0 {
 		return
 	}
-	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_MENTION, tos, "issue mention"))
+	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_MENTION, tos, "issue mention", ""))
+}
+
+// SendReviewRequestMail composes and sends emails to reviewers who are requested
+// to review the pull request.
+func SendReviewRequestMail(issue Issue, repo Repository, doer User, tos []string) {
+	if len(tos) == 0 {
+		return
+	}
+	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_REVIEW_REQUEST, tos, "review request", ""))
+}
+
+// SendIssueListMail composes and sends an issue email to a notification
+// recipient of the repository, which is not associated with any user and has to
+// be able to unsubscribe via the given link.
+func SendIssueListMail(issue Issue, repo Repository, doer User, to, unsubscribeLink string) {
+	Send(composeIssueMessage(issue, repo, doer, MAIL_ISSUE_COMMENT, []string{to}, "issue list", unsubscribeLink))
 }
@@ -0,0 +1,120 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package email
+
+import (
+	"html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+)
+
+// builtinTemplates is the list of mail templates that can be overridden.
+var builtinTemplates = map[string]bool{
+	MAIL_AUTH_ACTIVATE:                  true,
+	MAIL_AUTH_ACTIVATE_EMAIL:            true,
+	MAIL_AUTH_RESET_PASSWORD:            true,
+	MAIL_AUTH_REGISTER_NOTIFY:           true,
+	MAIL_ISSUE_COMMENT:                  true,
+	MAIL_ISSUE_MENTION:                  true,
+	MAIL_ISSUE_REVIEW_REQUEST:           true,
+	MAIL_NOTIFY_COLLABORATOR:            true,
+	MAIL_NOTIFY_COLLABORATOR_INVITATION: true,
+	MAIL_NOTIFY_ORG_MEMBER_INVITATION:   true,
+}
+
+var (
+	overridesMu sync.RWMutex
+	// overrides is keyed by the template name, optionally prefixed by a language
+	// and a slash, e.g. "auth/activate" and "zh-CN/auth/activate".
+	overrides map[string]*template.Template
+)
+
+// LoadTemplateOverrides loads mail templates from the given directory that take
+// precedence over the built-in ones. A template named "auth/activate" is
+// overridden by "<dir>/auth/activate.tmpl", and for a specific language by
+// "<dir>/<lang>/auth/activate.tmpl". Files that do not correspond to a built-in
+// template or fail to parse are skipped with a warning. An empty directory
+// clears all overrides.
+func LoadTemplateOverrides(dir string) error {
+	if dir == "" {
+		overridesMu.Lock()
+		overrides = nil
+		overridesMu.Unlock()
+		return nil
+	}
+
+	loaded := make(map[string]*template.Template)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if ext != ".tmpl" && ext != ".html" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(rel), ext)
+
+		name := key
+		if !builtinTemplates[name] {
+			i := strings.Index(key, "/")
+			if i < 0 || !builtinTemplates[key[i+1:]] {
+				log.Warn("Mail template override %q does not match any built-in template, skipped", path)
+				return nil
+			}
+			name = key[i+1:]
+		}
+
+		p, err := os.ReadFile(path)
+		if err != nil {
+			return errors.Wrapf(err, "read %q", path)
+		}
+		t, err := template.New(key).Funcs(funcMap()).Parse(string(p))
+		if err != nil {
+			log.Warn("Failed to parse mail template override %q, using built-in: %v", path, err)
+			return nil
+		}
+
+		loaded[key] = t
+		log.Info("Mail template %q is overridden by %q", name, path)
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "walk directory")
+	}
+
+	overridesMu.Lock()
+	overrides = loaded
+	overridesMu.Unlock()
+	return nil
+}
+
+// lookupOverride returns the override of the template for the given language,
+// or the generic override when there is none for the language. It returns nil
+// if the template is not overridden.
+func lookupOverride(lang, tpl string) *template.Template {
+	overridesMu.RLock()
+	defer overridesMu.RUnlock()
+
+	if lang != "" {
+		if t, ok := overrides[lang+"/"+tpl]; ok {
+			return t
+		}
+	}
+	return overrides[tpl]
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package email
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestRender_Override(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "auth/activate.tmpl", `Hi {{.Username}}, welcome to {{AppName}}`)
+	writeTemplate(t, dir, "zh-CN/auth/activate.tmpl", `你好 {{.Username}}`)
+	writeTemplate(t, dir, "auth/unknown.tmpl", `Unused`)
+
+	err := LoadTemplateOverrides(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = LoadTemplateOverrides("")
+	})
+
+	data := map[string]any{"Username": "alice"}
+	tests := []struct {
+		name string
+		lang string
+		want string
+	}{
+		{
+			name: "generic override",
+			want: "Hi alice, welcome to ",
+		},
+		{
+			name: "localized override",
+			lang: "zh-CN",
+			want: "你好 alice",
+		},
+		{
+			name: "no override for language",
+			lang: "fr-FR",
+			want: "Hi alice, welcome to ",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := render(test.lang, MAIL_AUTH_ACTIVATE, data)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	assert.Nil(t, lookupOverride("", "auth/unknown"))
+
+	t.Run("default language without request", func(t *testing.T) {
+		conf.SetMockI18n(t, []string{"zh-CN", "en-US"})
+
+		got, err := render(contextLang(nil), MAIL_AUTH_ACTIVATE, data)
+		require.NoError(t, err)
+		assert.Equal(t, "你好 alice", got)
+	})
+}
+
+func TestRender_MalformedOverride(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "auth/activate.tmpl", `Hi {{.Username}`)
+
+	err := LoadTemplateOverrides(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = LoadTemplateOverrides("")
+	})
+
+	assert.Nil(t, lookupOverride("", MAIL_AUTH_ACTIVATE))
+
+	got, err := render("", MAIL_AUTH_ACTIVATE, map[string]any{"Username": "alice"})
+	require.NoError(t, err)
+	assert.Contains(t, got, "alice")
+	assert.NotContains(t, got, "Hi alice")
+}
+
+func TestLoadTemplateOverrides(t *testing.T) {
+	err := LoadTemplateOverrides(filepath.Join(t.TempDir(), "404"))
+	assert.Error(t, err)
+}
@@ -11,7 +11,7 @@ import (
 
 type Authentication struct {
 	ID                int64
-	Type              int    `binding:"Range(2,6)"`
+	Type              int    `binding:"Range(2,7)"`
 	Name              string `binding:"Required;MaxSize(30)"`
 	Host              string
 	Port              int
@@ -31,6 +31,14 @@ type Authentication struct {
 	GroupFilter       string
 	GroupMemberUID    string
 	UserUID           string
+	AttributeFullName string
+	AttributeAvatar   string
+	AttributeLocation string
+	AdminGroupDN      string
+	SyncProfile       bool
+	PoolSize          int `binding:"Range(0,100)" locale:"admin.auths.pool_size"`
+	Timeout           int `binding:"Range(0,600)" locale:"admin.auths.timeout"`
+	HostRetryInterval int `binding:"Range(0,86400)" locale:"admin.auths.host_retry_interval"`
 	IsActive          bool
 	IsDefault         bool
 	SMTPAuth          string
@@ -42,6 +50,15 @@ type Authentication struct {
 	SkipVerify        bool
 	PAMServiceName    string
 	GitHubAPIEndpoint string `form:"github_api_endpoint" binding:"Url"`
+	OIDCIssuer        string `form:"oidc_issuer" binding:"Url"`
+	OIDCClientID      string `form:"oidc_client_id"`
+	OIDCClientSecret  string `form:"oidc_client_secret"`
+	OIDCScopes        string `form:"oidc_scopes"`
+	OIDCUsernameClaim string `form:"oidc_username_claim"`
+	OIDCEmailClaim    string `form:"oidc_email_claim"`
+	OIDCFullNameClaim string `form:"oidc_full_name_claim"`
+	OIDCGroupsClaim   string `form:"oidc_groups_claim"`
+	OIDCAdminGroup    string `form:"oidc_admin_group"`
 }
 
 func (f *Authentication) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
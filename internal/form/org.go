@@ -18,12 +18,32 @@ func (f *CreateOrg) Validate(ctx *macaron.Context, errs binding.Errors) binding.
 }
 
 type UpdateOrgSetting struct {
-	Name            string `binding:"Required;AlphaDashDot;MaxSize(35)" locale:"org.org_name_holder"`
-	FullName        string `binding:"MaxSize(100)"`
-	Description     string `binding:"MaxSize(255)"`
-	Website         string `binding:"Url;MaxSize(100)"`
-	Location        string `binding:"MaxSize(50)"`
-	MaxRepoCreation int
+	Name             string `binding:"Required;AlphaDashDot;MaxSize(35)" locale:"org.org_name_holder"`
+	FullName         string `binding:"MaxSize(100)"`
+	Description      string `binding:"MaxSize(255)"`
+	Website          string `binding:"Url;MaxSize(100)"`
+	Location         string `binding:"MaxSize(50)"`
+	MaxRepoCreation  int
+	MaxCollaborators int
+	MaxTeams         int
+	MaxTeamMembers   int
+
+	DefaultRepoGitignores string
+	DefaultRepoLicense    string
+	DefaultRepoReadme     string
+	ForcePrivateRepos     bool
+	RestrictPrivateForks  bool
+	BranchNamePattern     string `binding:"MaxSize(255)"`
+
+	CommitMessagePattern      string `binding:"MaxSize(255)"`
+	CommitMessageExemptMerges bool
+
+	PushMaxSize             int64  `binding:"Range(0,1048576)"`
+	PushMaxBlobSize         int64  `binding:"Range(0,1048576)"`
+	PushBlockedFilePatterns string `binding:"MaxSize(1024)"`
+
+	RequireMemberInvitation bool
+	DefaultBranchProtection int
 }
 
 func (f *UpdateOrgSetting) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
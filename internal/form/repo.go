@@ -97,6 +97,7 @@ type RepoSetting struct {
 	RepoName      string `binding:"Required;AlphaDashDot;MaxSize(100)"`
 	Description   string `binding:"MaxSize(512)"`
 	Website       string `binding:"Url;MaxSize(100)"`
+	Topics        string `binding:"MaxSize(1024)"`
 	Branch        string
 	Interval      int
 	MirrorAddress string
@@ -105,19 +106,41 @@ type RepoSetting struct {
 	EnablePrune   bool
 
 	// Advanced settings
-	EnableWiki            bool
-	AllowPublicWiki       bool
-	EnableExternalWiki    bool
-	ExternalWikiURL       string
-	EnableIssues          bool
-	AllowPublicIssues     bool
-	EnableExternalTracker bool
-	ExternalTrackerURL    string
-	TrackerURLFormat      string
-	TrackerIssueStyle     string
-	EnablePulls           bool
-	PullsIgnoreWhitespace bool
-	PullsAllowRebase      bool
+	EnableWiki                  bool
+	AllowPublicWiki             bool
+	EnableExternalWiki          bool
+	ExternalWikiURL             string
+	EnableIssues                bool
+	AllowPublicIssues           bool
+	IssuesReadOnly              bool
+	IssueKeyPrefix              string `binding:"MaxSize(10)"`
+	EnableExternalTracker       bool
+	ExternalTrackerURL          string
+	TrackerURLFormat            string
+	TrackerIssueStyle           string
+	AutoAssignStrategy          string
+	AutoAssignTeamID            int64
+	AutoAssignUnavailableUsers  string
+	EnablePulls                 bool
+	PullsIgnoreWhitespace       bool
+	PullsAllowRebase            bool
+	PullsAutosquash             bool
+	PullsDeleteBranchAfterMerge bool
+	PullsDeleteBranchExemptions string
+	EnableReleases              bool
+	EnableLFS                   bool
+	RequireCLA                  bool
+	CLADocumentURL              string
+	CLAExemptOrgMembers         bool
+	CLAAllowlistUsers           string
+	RequireSignOff              bool
+	SignOffAllowlistEmails      string
+	DefaultReviewers            string
+	DefaultReviewerTeams        string
+	DefaultPullAssignee         string
+	ReviewRotationStrategy      string
+	ReviewRotationTeamID        int64
+	ReviewRotationCount         int
 }
 
 func (f *RepoSetting) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
@@ -132,11 +155,16 @@ func (f *RepoSetting) Validate(ctx *macaron.Context, errs binding.Errors) bindin
 //         \/             \/     \/     \/     \/
 
 type ProtectBranch struct {
-	Protected          bool
-	RequirePullRequest bool
-	EnableWhitelist    bool
-	WhitelistUsers     string
-	WhitelistTeams     string
+	Protected               bool
+	RequirePullRequest      bool
+	RequireLinearHistory    bool
+	RequireUpToDate         bool
+	RequireCodeOwnerReviews bool
+	RequiredApprovals       int
+	RequiredStatusChecks    string
+	EnableWhitelist         bool
+	WhitelistUsers          string
+	WhitelistTeams          string
 }
 
 func (f *ProtectBranch) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
@@ -151,16 +179,23 @@ func (f *ProtectBranch) Validate(ctx *macaron.Context, errs binding.Errors) bind
 //        \/       \/    \/     \/     \/            \/
 
 type Webhook struct {
-	Events       string
-	Create       bool
-	Delete       bool
-	Fork         bool
-	Push         bool
-	Issues       bool
-	IssueComment bool
-	PullRequest  bool
-	Release      bool
-	Active       bool
+	Events           string
+	Create           bool
+	Delete           bool
+	Fork             bool
+	Push             bool
+	Issues           bool
+	IssueComment     bool
+	PullRequest      bool
+	Release          bool
+	Label            bool
+	RepositoryEdited bool
+	Deployment       bool
+	DeploymentStatus bool
+	Membership       bool
+	Team             bool
+	Active           bool
+	Filter           string
 }
 
 func (f Webhook) PushOnly() bool {
@@ -220,6 +255,16 @@ func (f *NewDingtalkHook) Validate(ctx *macaron.Context, errs binding.Errors) bi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+type NewMSTeamsHook struct {
+	PayloadURL string `binding:"Required;Url"`
+	ThemeColor string
+	Webhook
+}
+
+func (f *NewMSTeamsHook) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -234,6 +279,8 @@ type NewIssue struct {
 	AssigneeID  int64
 	Content     string
 	Files       []string
+	// The file name of the issue form that the issue is submitted with.
+	Template string
 }
 
 func (f *NewIssue) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
@@ -431,3 +478,15 @@ func (f *DeleteRepoFile) Validate(ctx *macaron.Context, errs binding.Errors) bin
 func (f *DeleteRepoFile) IsNewBrnach() bool {
 	return f.CommitChoice == "commit-to-new-branch"
 }
+
+type NotificationRecipient struct {
+	Email              string `binding:"Required;Email;MaxSize(254)"`
+	Issue              bool
+	IssueComment       bool
+	PullRequest        bool
+	PullRequestComment bool
+}
+
+func (f *NotificationRecipient) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -128,6 +128,22 @@ func (f *AddEmail) Validate(ctx *macaron.Context, errs binding.Errors) binding.E
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+type EmailPrivacy struct {
+	KeepEmailPrivate bool
+}
+
+func (f *EmailPrivacy) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+type AutoAssign struct {
+	AutoAssignOptOut bool
+}
+
+func (f *AutoAssign) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 type ChangePassword struct {
 	OldPassword string `binding:"Required;MinSize(1);MaxSize(255)"`
 	Password    string `binding:"Required;MaxSize(255)"`
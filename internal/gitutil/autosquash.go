@@ -0,0 +1,41 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bytes"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+)
+
+// RebaseAutosquash rebases the branch onto the upstream in the work tree of
+// given path, and folds "fixup!" and "squash!" commits into the commits they
+// target along the way, which is the non-interactive equivalent of "git rebase
+// -i --autosquash". Messages of "squash!" commits are appended to messages of
+// their targets. The rebase is aborted when it cannot be applied cleanly, and
+// leaves the work tree as it was.
+func RebaseAutosquash(workPath, upstream, branch string) error {
+	run := func(args ...string) error {
+		stderr := new(bytes.Buffer)
+		err := git.NewCommand(args...).
+			// Accept the todo list and messages of squashed commits as they are.
+			AddEnvs("GIT_SEQUENCE_EDITOR=true", "GIT_EDITOR=true").
+			RunInDirWithOptions(workPath, git.RunInDirOptions{
+				Stderr: stderr,
+			})
+		if err != nil {
+			return errors.Errorf("%v - %s", err, stderr)
+		}
+		return nil
+	}
+
+	err := run("rebase", "--quiet", "--interactive", "--autosquash", upstream, branch)
+	if err != nil {
+		_ = run("rebase", "--abort")
+		return errors.Wrap(err, "rebase")
+	}
+	return nil
+}
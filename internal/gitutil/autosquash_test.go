@@ -0,0 +1,76 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebaseAutosquash(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	workPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(workPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	commit := func(name, content, message string) {
+		err := os.WriteFile(filepath.Join(workPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", message)
+	}
+
+	run("init", "-b", "main")
+	commit("README.md", "readme", "Initial commit")
+	run("checkout", "-b", "feature")
+	commit("a.txt", "a", "Add a")
+	commit("b.txt", "b", "Add b")
+	commit("a.txt", "a fixed", "fixup! Add a")
+	commit("b.txt", "b improved", "squash! Add b\n\nImprove b")
+	run("checkout", "main")
+	commit("main.txt", "main", "Update main")
+
+	err := RebaseAutosquash(workPath, "main", "feature")
+	require.NoError(t, err)
+
+	subjects := run("log", "--format=%s", "main..HEAD")
+	assert.Equal(t, "Add b\nAdd a", subjects)
+	assert.Contains(t, run("log", "-1", "--format=%B", "HEAD"), "Improve b")
+	assert.Equal(t, "a fixed", run("show", "HEAD:a.txt"))
+	assert.Equal(t, "b improved", run("show", "HEAD:b.txt"))
+	assert.Equal(t, "main", run("show", "HEAD:main.txt"), "should be rebased onto the upstream")
+
+	t.Run("conflict", func(t *testing.T) {
+		run("checkout", "main")
+		commit("c.txt", "c from main", "Add c on main")
+		run("checkout", "-b", "conflict", "main~1")
+		commit("c.txt", "c", "Add c")
+		commit("c.txt", "c fixed", "fixup! Add c")
+
+		head := run("rev-parse", "HEAD")
+		err := RebaseAutosquash(workPath, "main", "conflict")
+		assert.Error(t, err)
+
+		// The failed rebase is aborted
+		assert.Equal(t, head, run("rev-parse", "HEAD"))
+		assert.Empty(t, run("status", "--porcelain"))
+	})
+}
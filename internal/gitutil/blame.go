@@ -0,0 +1,251 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bufio"
+	"bytes"
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+)
+
+// BlameCommit contains information of a commit that lines are attributed to.
+type BlameCommit struct {
+	ID          string
+	AuthorName  string
+	AuthorEmail string
+	AuthorTime  time.Time
+	Summary     string
+}
+
+// BlameLine is a line of a blamed file with the commit it is attributed to.
+type BlameLine struct {
+	// The 1-based line number in the blamed file.
+	Number int
+	// The 1-based line number in the file of the commit the line is attributed
+	// to.
+	OriginalNumber int
+	Commit         *BlameCommit
+	Content        string
+}
+
+// ParseBlamePorcelain parses the output of "git blame --porcelain" into lines
+// with the commits they are attributed to.
+func ParseBlamePorcelain(data []byte) ([]*BlameLine, error) {
+	commits := make(map[string]*BlameCommit)
+	lines := make([]*BlameLine, 0, bytes.Count(data, []byte("\n\t")))
+
+	var current *BlameLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if current == nil {
+			// The header line: <sha> <original line> <final line> [<lines in group>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 || len(fields[0]) < 40 {
+				return nil, errors.Errorf("malformed header line %q", line)
+			}
+			original, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse original line number of %q", line)
+			}
+			final, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse final line number of %q", line)
+			}
+
+			commit, ok := commits[fields[0]]
+			if !ok {
+				commit = &BlameCommit{ID: fields[0]}
+				commits[fields[0]] = commit
+			}
+			current = &BlameLine{
+				Number:         final,
+				OriginalNumber: original,
+				Commit:         commit,
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "\t") {
+			current.Content = line[1:]
+			lines = append(lines, current)
+			current = nil
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Commit.AuthorName = value
+		case "author-mail":
+			current.Commit.AuthorEmail = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "parse author time %q", value)
+			}
+			current.Commit.AuthorTime = time.Unix(sec, 0)
+		case "summary":
+			current.Commit.Summary = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "scan")
+	} else if current != nil {
+		return nil, errors.Errorf("missing content of line %d", current.Number)
+	}
+	return lines, nil
+}
+
+// BlameRange returns lines between start and end (both 1-based and inclusive).
+// The end is clamped to the last line, and a non-positive end means the last
+// line. It returns nil when start is beyond the last line.
+func BlameRange(lines []*BlameLine, start, end int) []*BlameLine {
+	if start < 1 {
+		start = 1
+	}
+	if end <= 0 || end > len(lines) {
+		end = len(lines)
+	}
+	if start > end {
+		return nil
+	}
+	return lines[start-1 : end]
+}
+
+// blameCacheTTL is the time to live of cached blame results.
+const blameCacheTTL = 10 * time.Minute
+
+// blameCacheMaxEntries is the maximum number of files whose blame results are
+// cached, the least recently used ones are evicted when exceeded.
+const blameCacheMaxEntries = 256
+
+// blameCacheStore caches blame results of files keyed by the commit and the path
+// with the least recently used ones evicted.
+type blameCacheStore struct {
+	lock       sync.Mutex
+	maxEntries int
+	// order holds *blameCacheEntry with the most recently used at the front.
+	order   *list.List
+	entries map[blameCacheKey]*list.Element
+}
+
+type blameCacheKey struct {
+	commitID string
+	path     string
+}
+
+type blameCacheEntry struct {
+	key       blameCacheKey
+	lines     []*BlameLine
+	expiresAt time.Time
+}
+
+// newBlameCacheStore returns a new blameCacheStore that holds at most given
+// number of entries.
+func newBlameCacheStore(maxEntries int) *blameCacheStore {
+	return &blameCacheStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[blameCacheKey]*list.Element),
+	}
+}
+
+// Get returns the cached blame result of the file at the commit.
+func (c *blameCacheStore) Get(commitID, path string) ([]*BlameLine, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.entries[blameCacheKey{commitID: commitID, path: path}]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*blameCacheEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.lines, true
+}
+
+// Set caches the blame result of the file at the commit, and evicts the least
+// recently used entries when the cache is full.
+func (c *blameCacheStore) Set(commitID, path string, lines []*BlameLine) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := blameCacheKey{commitID: commitID, path: path}
+	expiresAt := time.Now().Add(blameCacheTTL)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*blameCacheEntry)
+		entry.lines = lines
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&blameCacheEntry{
+		key:       key,
+		lines:     lines,
+		expiresAt: expiresAt,
+	})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// InvalidateCommit removes all cached blame results at the commit.
+func (c *blameCacheStore) InvalidateCommit(commitID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key, elem := range c.entries {
+		if key.commitID == commitID {
+			c.remove(elem)
+		}
+	}
+}
+
+// remove removes the entry of the element. The caller must hold the lock.
+func (c *blameCacheStore) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*blameCacheEntry).key)
+}
+
+var blameCache = newBlameCacheStore(blameCacheMaxEntries)
+
+// InvalidateBlame removes all cached blame results at the commit.
+func InvalidateBlame(commitID string) {
+	blameCache.InvalidateCommit(commitID)
+}
+
+// BlameFile returns blame results of the file at the commit of the repository
+// in given path. Results are cached by the commit ID and the path.
+func BlameFile(repoPath, commitID, path string, timeout time.Duration) ([]*BlameLine, error) {
+	if lines, ok := blameCache.Get(commitID, path); ok {
+		return lines, nil
+	}
+
+	stdout, err := git.NewCommand("blame", "--porcelain", commitID, "--", path).RunInDirWithTimeout(timeout, repoPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "run git blame")
+	}
+
+	lines, err := ParseBlamePorcelain(stdout)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse output")
+	}
+	blameCache.Set(commitID, path, lines)
+	return lines, nil
+}
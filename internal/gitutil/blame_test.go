@@ -0,0 +1,158 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	blameCommit1 = "a1f0b5e2d35ca7a1e6c9b34d5f2e8a0c1b2d3e4f"
+	blameCommit2 = "b2e1c6f3e46db8b2f7dac45e6a3f9b1d2c3e4f5a"
+)
+
+var blamePorcelain = "" +
+	blameCommit1 + " 1 1 2\n" +
+	"author Alice\n" +
+	"author-mail <alice@example.com>\n" +
+	"author-time 1700000000\n" +
+	"author-tz +0000\n" +
+	"committer Alice\n" +
+	"committer-mail <alice@example.com>\n" +
+	"committer-time 1700000000\n" +
+	"committer-tz +0000\n" +
+	"summary Add README\n" +
+	"filename README.md\n" +
+	"\t# Hello\n" +
+	blameCommit1 + " 2 2\n" +
+	"\t\n" +
+	blameCommit2 + " 1 3 1\n" +
+	"author Bob\n" +
+	"author-mail <bob@example.com>\n" +
+	"author-time 1600000000\n" +
+	"author-tz +0000\n" +
+	"committer Bob\n" +
+	"committer-mail <bob@example.com>\n" +
+	"committer-time 1600000000\n" +
+	"committer-tz +0000\n" +
+	"summary Initial commit\n" +
+	"boundary\n" +
+	"filename README.md\n" +
+	"\tWorld\n"
+
+func TestParseBlamePorcelain(t *testing.T) {
+	lines, err := ParseBlamePorcelain([]byte(blamePorcelain))
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+
+	alice := &BlameCommit{
+		ID:          blameCommit1,
+		AuthorName:  "Alice",
+		AuthorEmail: "alice@example.com",
+		AuthorTime:  time.Unix(1700000000, 0),
+		Summary:     "Add README",
+	}
+	bob := &BlameCommit{
+		ID:          blameCommit2,
+		AuthorName:  "Bob",
+		AuthorEmail: "bob@example.com",
+		AuthorTime:  time.Unix(1600000000, 0),
+		Summary:     "Initial commit",
+	}
+	assert.Equal(t, &BlameLine{Number: 1, OriginalNumber: 1, Commit: alice, Content: "# Hello"}, lines[0])
+	assert.Equal(t, &BlameLine{Number: 2, OriginalNumber: 2, Commit: alice, Content: ""}, lines[1])
+	assert.Equal(t, &BlameLine{Number: 3, OriginalNumber: 1, Commit: bob, Content: "World"}, lines[2])
+
+	// Lines of the same commit share the commit information.
+	assert.Same(t, lines[0].Commit, lines[1].Commit)
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := ParseBlamePorcelain([]byte("not a header\n"))
+		assert.Error(t, err)
+
+		_, err = ParseBlamePorcelain([]byte(blameCommit1 + " 1 1 1\nauthor Alice\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestBlameRange(t *testing.T) {
+	lines := make([]*BlameLine, 5)
+	for i := range lines {
+		lines[i] = &BlameLine{Number: i + 1}
+	}
+	numbers := func(lines []*BlameLine) []int {
+		var got []int
+		for _, l := range lines {
+			got = append(got, l.Number)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name  string
+		start int
+		end   int
+		want  []int
+	}{
+		{name: "all", want: []int{1, 2, 3, 4, 5}},
+		{name: "middle", start: 2, end: 4, want: []int{2, 3, 4}},
+		{name: "single", start: 3, end: 3, want: []int{3}},
+		{name: "open end", start: 4, want: []int{4, 5}},
+		{name: "end beyond last", start: 4, end: 100, want: []int{4, 5}},
+		{name: "start beyond last", start: 6},
+		{name: "start after end", start: 4, end: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, numbers(BlameRange(lines, test.start, test.end)))
+		})
+	}
+}
+
+func TestBlameCacheStore(t *testing.T) {
+	c := newBlameCacheStore(10)
+	lines := []*BlameLine{{Number: 1}}
+	c.Set("1", "README.md", lines)
+	c.Set("1", "LICENSE", lines)
+	c.Set("2", "README.md", lines)
+
+	got, ok := c.Get("1", "README.md")
+	assert.True(t, ok)
+	assert.Equal(t, lines, got)
+
+	_, ok = c.Get("1", "404.md")
+	assert.False(t, ok)
+
+	c.InvalidateCommit("1")
+	_, ok = c.Get("1", "README.md")
+	assert.False(t, ok)
+	_, ok = c.Get("1", "LICENSE")
+	assert.False(t, ok)
+	_, ok = c.Get("2", "README.md")
+	assert.True(t, ok)
+}
+
+func TestBlameCacheStore_Evict(t *testing.T) {
+	c := newBlameCacheStore(2)
+	lines := []*BlameLine{{Number: 1}}
+	c.Set("1", "README.md", lines)
+	c.Set("1", "LICENSE", lines)
+
+	// Access makes the entry the most recently used
+	_, ok := c.Get("1", "README.md")
+	assert.True(t, ok)
+
+	c.Set("2", "README.md", lines)
+	_, ok = c.Get("1", "LICENSE")
+	assert.False(t, ok)
+	_, ok = c.Get("1", "README.md")
+	assert.True(t, ok)
+	_, ok = c.Get("2", "README.md")
+	assert.True(t, ok)
+}
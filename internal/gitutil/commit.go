@@ -0,0 +1,149 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+)
+
+// ErrRefChanged is returned when the reference to be updated no longer points to
+// the expected commit.
+var ErrRefChanged = errors.New("reference has been changed")
+
+// ErrPushRejected is returned when the push is rejected by server-side hooks.
+var ErrPushRejected = errors.New("push rejected by server-side hooks")
+
+// CommitFileOptions contains options for committing a single file change.
+type CommitFileOptions struct {
+	// The full name of the reference to be updated, e.g. "refs/heads/master".
+	Ref string
+	// The commit that the reference is expected to point to, which becomes the
+	// parent of the new commit.
+	ParentID string
+	// The path of the file to be changed.
+	TreePath string
+	// The new content of the file, ignored when the file is to be deleted.
+	Content []byte
+	// Whether to delete the file.
+	Delete bool
+
+	Author    *git.Signature
+	Committer *git.Signature
+	Message   string
+	// Environment variables of the push, e.g. to run server-side hooks on behalf
+	// of the user.
+	PushEnvs []string
+}
+
+// CommitFile creates a commit that adds, updates or deletes a single file on
+// top of the parent commit of the repository in given path, without a working
+// tree. The new commit is then pushed to the reference of the same repository,
+// so server-side hooks are run as for any other push. The push only succeeds
+// if the reference still points to the parent commit, otherwise ErrRefChanged
+// is returned. It returns ErrPushRejected, wrapped with the output of the push,
+// when the hooks reject the push, and the ID of the new commit otherwise.
+func CommitFile(repoPath string, opts CommitFileOptions) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "gogs-commit-file-")
+	if err != nil {
+		return "", errors.Wrap(err, "create temporary directory")
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	indexEnv := "GIT_INDEX_FILE=" + filepath.Join(tmpDir, "index")
+	run := func(stdin []byte, envs []string, args ...string) (string, error) {
+		cmd := git.NewCommand(args...).AddEnvs(append(envs, indexEnv)...)
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		err := cmd.RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdin:  bytes.NewReader(stdin),
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+		if err != nil {
+			return "", errors.Errorf("%v - %s", err, stderr)
+		}
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	_, err = run(nil, nil, "read-tree", opts.ParentID)
+	if err != nil {
+		return "", errors.Wrap(err, "read tree")
+	}
+
+	if opts.Delete {
+		// A zero mode removes the path, which unlike "--force-remove" does not
+		// require a working tree.
+		_, err = run([]byte("0 "+git.EmptyID+"\t"+opts.TreePath+"\n"), nil, "update-index", "--index-info")
+		if err != nil {
+			return "", errors.Wrap(err, "remove from index")
+		}
+	} else {
+		// Keep the mode of the existing file, e.g. executable
+		mode := "100644"
+		stage, err := run(nil, nil, "ls-files", "--stage", "--", opts.TreePath)
+		if err != nil {
+			return "", errors.Wrap(err, "list index")
+		} else if fields := strings.Fields(stage); len(fields) > 0 && strings.HasPrefix(fields[0], "100") {
+			mode = fields[0]
+		}
+
+		blobID, err := run(opts.Content, nil, "hash-object", "-w", "--stdin")
+		if err != nil {
+			return "", errors.Wrap(err, "write blob")
+		}
+		_, err = run(nil, nil, "update-index", "--add", "--cacheinfo", fmt.Sprintf("%s,%s,%s", mode, blobID, opts.TreePath))
+		if err != nil {
+			return "", errors.Wrap(err, "add to index")
+		}
+	}
+
+	treeID, err := run(nil, nil, "write-tree")
+	if err != nil {
+		return "", errors.Wrap(err, "write tree")
+	}
+
+	envs := []string{
+		"GIT_AUTHOR_NAME=" + opts.Author.Name,
+		"GIT_AUTHOR_EMAIL=" + opts.Author.Email,
+		"GIT_AUTHOR_DATE=" + gitDate(opts.Author.When),
+		"GIT_COMMITTER_NAME=" + opts.Committer.Name,
+		"GIT_COMMITTER_EMAIL=" + opts.Committer.Email,
+		"GIT_COMMITTER_DATE=" + gitDate(opts.Committer.When),
+	}
+	commitID, err := run([]byte(opts.Message), envs, "commit-tree", treeID, "-p", opts.ParentID)
+	if err != nil {
+		return "", errors.Wrap(err, "commit tree")
+	}
+
+	stderr := new(bytes.Buffer)
+	err = git.NewCommand("push", "--quiet", "--force-with-lease="+opts.Ref+":"+opts.ParentID, ".", commitID+":"+opts.Ref).
+		AddEnvs(opts.PushEnvs...).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdout: new(bytes.Buffer),
+			Stderr: stderr,
+		})
+	if err != nil {
+		if current, _ := run(nil, nil, "rev-parse", "--verify", "--quiet", opts.Ref); current != opts.ParentID {
+			return "", ErrRefChanged
+		} else if strings.Contains(stderr.String(), "[remote rejected]") {
+			return "", errors.Wrap(ErrPushRejected, strings.TrimSpace(stderr.String()))
+		}
+		return "", errors.Errorf("push: %v - %s", err, stderr)
+	}
+	return commitID, nil
+}
+
+// gitDate formats the time in the Git internal date format.
+func gitDate(t time.Time) string {
+	return fmt.Sprintf("%d %s", t.Unix(), t.Format("-0700"))
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return string(stdout)
+	}
+	run("init", "-b", "main")
+	err := os.WriteFile(filepath.Join(repoPath, "run.sh"), []byte("echo"), 0o755)
+	require.NoError(t, err)
+	run("add", "run.sh")
+	run("commit", "-m", "Initial commit")
+	parentID, err := git.NewCommand("rev-parse", "HEAD").RunInDir(repoPath)
+	require.NoError(t, err)
+	// Detach so the branch can be pushed to by the commit.
+	run("checkout", "--detach")
+
+	sig := &git.Signature{Name: "bob", Email: "bob@example.com", When: time.Now()}
+	opts := CommitFileOptions{
+		Ref:       git.RefsHeads + "main",
+		ParentID:  string(parentID[:len(parentID)-1]),
+		TreePath:  "run.sh",
+		Content:   []byte("echo hello"),
+		Author:    sig,
+		Committer: sig,
+		Message:   "Update run.sh",
+	}
+	commitID, err := CommitFile(repoPath, opts)
+	require.NoError(t, err)
+	assert.Equal(t, commitID+"\n", run("rev-parse", "main"))
+	assert.Equal(t, "echo hello", run("show", "main:run.sh"))
+	assert.Contains(t, run("ls-tree", "main", "run.sh"), "100755 blob", "file mode should be kept")
+
+	t.Run("reference changed", func(t *testing.T) {
+		opts := opts
+		opts.Content = []byte("echo stale")
+		_, err := CommitFile(repoPath, opts)
+		assert.Equal(t, ErrRefChanged, err)
+		assert.Equal(t, commitID+"\n", run("rev-parse", "main"))
+	})
+
+	t.Run("rejected by hooks", func(t *testing.T) {
+		hook := "#!/bin/sh\nif [ \"$GOGS_AUTH_USER_NAME\" != bob ]; then\n  echo \"only bob can push\" >&2\n  exit 1\nfi\n"
+		err := os.WriteFile(filepath.Join(repoPath, ".git", "hooks", "pre-receive"), []byte(hook), 0o755)
+		require.NoError(t, err)
+
+		opts := opts
+		opts.ParentID = commitID
+		opts.Content = []byte("echo world")
+		_, err = CommitFile(repoPath, opts)
+		assert.Equal(t, ErrPushRejected, errors.Cause(err))
+		assert.Contains(t, err.Error(), "only bob can push")
+		assert.Equal(t, commitID+"\n", run("rev-parse", "main"))
+
+		opts.PushEnvs = []string{"GOGS_AUTH_USER_NAME=bob"}
+		newCommitID, err := CommitFile(repoPath, opts)
+		require.NoError(t, err)
+		assert.Equal(t, newCommitID+"\n", run("rev-parse", "main"))
+	})
+}
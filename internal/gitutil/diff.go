@@ -113,6 +113,17 @@ func diffsToHTML(diffs []diffmatchpatch.Diff, lineType git.DiffLineType) templat
 type DiffFile struct {
 	*git.DiffFile
 	Sections []*DiffSection
+
+	// NumLines is the number of lines in all sections of the file.
+	NumLines int
+	// NumBytes is the number of bytes of lines in all sections of the file.
+	NumBytes int64
+	// IsCollapsed indicates whether the diff of the file is too large to be
+	// rendered by default.
+	IsCollapsed bool
+	// IsNotRendered indicates whether the diff of the file is not rendered
+	// because the total size of rendered files has exceeded the limit.
+	IsNotRendered bool
 }
 
 // HighlightClass returns the detected highlight class for the file.
@@ -152,7 +163,9 @@ func NewDiff(oldDiff *git.Diff) *Diff {
 				buf.WriteString(newDiff.Files[i].Sections[j].Lines[k].Content)
 				buf.WriteString("\n")
 			}
+			newDiff.Files[i].NumLines += len(newDiff.Files[i].Sections[j].Lines)
 		}
+		newDiff.Files[i].NumBytes = int64(buf.Len() - newDiff.Files[i].NumLines)
 
 		charsetLabel, err := tool.DetectEncoding(buf.Bytes())
 		if charsetLabel != "UTF-8" && err == nil {
@@ -173,6 +186,58 @@ func NewDiff(oldDiff *git.Diff) *Diff {
 	return newDiff
 }
 
+// DiffRenderOptions contains options for rendering the diff of files inline.
+type DiffRenderOptions struct {
+	// CollapseLines is the number of lines that the diff of a file exceeds to be
+	// collapsed. 0 means no limit.
+	CollapseLines int
+	// CollapseBytes is the number of bytes that the diff of a file exceeds to be
+	// collapsed. 0 means no limit.
+	CollapseBytes int64
+	// MaxTotalLines is the total number of lines of files to be rendered, files
+	// beyond which are listed but not rendered. 0 means no limit.
+	MaxTotalLines int
+	// ExpandAll indicates whether to render all files regardless of limits.
+	ExpandAll bool
+	// ExpandFiles is the list of names of files to be rendered regardless of
+	// limits.
+	ExpandFiles []string
+}
+
+// ApplyRenderOptions marks files that are too large to be rendered by default
+// as collapsed, and files beyond the total limit as not rendered.
+func (diff *Diff) ApplyRenderOptions(opts DiffRenderOptions) {
+	if opts.ExpandAll {
+		return
+	}
+
+	expandFiles := make(map[string]bool, len(opts.ExpandFiles))
+	for _, name := range opts.ExpandFiles {
+		expandFiles[name] = true
+	}
+
+	totalLines := 0
+	exceeded := false
+	for _, f := range diff.Files {
+		if f.IsIncomplete() || f.IsBinary() || expandFiles[f.Name] {
+			continue
+		}
+
+		if (opts.CollapseLines > 0 && f.NumLines > opts.CollapseLines) ||
+			(opts.CollapseBytes > 0 && f.NumBytes > opts.CollapseBytes) {
+			f.IsCollapsed = true
+			continue
+		}
+
+		if exceeded || (opts.MaxTotalLines > 0 && totalLines+f.NumLines > opts.MaxTotalLines) {
+			f.IsNotRendered = true
+			exceeded = true
+			continue
+		}
+		totalLines += f.NumLines
+	}
+}
+
 // ParseDiff parses the diff from given io.Reader.
 func ParseDiff(r io.Reader, maxFiles, maxFileLines, maxLineChars int) (*Diff, error) {
 	done := make(chan git.SteamParseDiffResult)
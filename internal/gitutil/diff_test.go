@@ -6,6 +6,7 @@ package gitutil
 
 import (
 	"html/template"
+	"strings"
 	"testing"
 
 	dmp "github.com/sergi/go-diff/diffmatchpatch"
@@ -47,3 +48,88 @@ func Test_diffsToHTML(t *testing.T) {
 		})
 	}
 }
+
+func TestDiff_ApplyRenderOptions(t *testing.T) {
+	newFile := func(name string, numLines int, content string) *git.DiffFile {
+		lines := make([]*git.DiffLine, numLines)
+		for i := range lines {
+			lines[i] = &git.DiffLine{Type: git.DiffLineAdd, Content: content}
+		}
+		return &git.DiffFile{
+			Name:     name,
+			Sections: []*git.DiffSection{{Lines: lines}},
+		}
+	}
+	newDiff := func() *Diff {
+		return NewDiff(&git.Diff{
+			Files: []*git.DiffFile{
+				newFile("small.go", 10, "+small"),
+				newFile("long.go", 600, "+long"),
+				newFile("wide.go", 10, "+"+strings.Repeat("x", 1000)),
+				newFile("medium.go", 100, "+medium"),
+				newFile("last.go", 10, "+last"),
+			},
+		})
+	}
+	opts := DiffRenderOptions{
+		CollapseLines: 500,
+		CollapseBytes: 5000,
+		MaxTotalLines: 100,
+	}
+
+	type fileState struct {
+		collapsed   bool
+		notRendered bool
+	}
+	states := func(diff *Diff) map[string]fileState {
+		got := make(map[string]fileState, len(diff.Files))
+		for _, f := range diff.Files {
+			got[f.Name] = fileState{collapsed: f.IsCollapsed, notRendered: f.IsNotRendered}
+		}
+		return got
+	}
+
+	t.Run("sizes", func(t *testing.T) {
+		diff := newDiff()
+		assert.Equal(t, 600, diff.Files[1].NumLines)
+		assert.Equal(t, int64(600*5), diff.Files[1].NumBytes)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		diff := newDiff()
+		diff.ApplyRenderOptions(opts)
+		want := map[string]fileState{
+			"small.go":  {},
+			"long.go":   {collapsed: true},
+			"wide.go":   {collapsed: true},
+			"medium.go": {notRendered: true},
+			"last.go":   {notRendered: true},
+		}
+		assert.Equal(t, want, states(diff))
+	})
+
+	t.Run("expand file", func(t *testing.T) {
+		diff := newDiff()
+		opts := opts
+		opts.ExpandFiles = []string{"long.go", "last.go"}
+		diff.ApplyRenderOptions(opts)
+		want := map[string]fileState{
+			"small.go":  {},
+			"long.go":   {},
+			"wide.go":   {collapsed: true},
+			"medium.go": {notRendered: true},
+			"last.go":   {},
+		}
+		assert.Equal(t, want, states(diff))
+	})
+
+	t.Run("expand all", func(t *testing.T) {
+		diff := newDiff()
+		opts := opts
+		opts.ExpandAll = true
+		diff.ApplyRenderOptions(opts)
+		for _, state := range states(diff) {
+			assert.Equal(t, fileState{}, state)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"io"
+	stdsync "sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/sync"
+)
+
+// ErrOperationLimitReached is returned when a Git operation can't be started
+// because of the limits of concurrent operations.
+var ErrOperationLimitReached = errors.New("too many concurrent Git operations")
+
+// OperationLimitOptions contains the limits of concurrent Git operations. A
+// limit that is not positive means unlimited.
+type OperationLimitOptions struct {
+	// The max number of concurrent operations in total.
+	Global int
+	// The max number of concurrent operations on a single repository.
+	PerRepo int
+	// The max number of concurrent operations from a single IP address.
+	PerIP int
+	// The time duration an operation waits for each of the limits before being
+	// rejected.
+	QueueTimeout time.Duration
+}
+
+// OperationLimiter limits the number of concurrent Git operations in total,
+// per repository and per client IP address.
+type OperationLimiter struct {
+	global  *sync.KeyedSemaphore
+	perRepo *sync.KeyedSemaphore
+	perIP   *sync.KeyedSemaphore
+	timeout time.Duration
+}
+
+// NewOperationLimiter returns a new OperationLimiter with given limits.
+func NewOperationLimiter(opts OperationLimitOptions) *OperationLimiter {
+	newSemaphore := func(limit int) *sync.KeyedSemaphore {
+		if limit <= 0 {
+			return nil
+		}
+		return sync.NewKeyedSemaphore(limit)
+	}
+	return &OperationLimiter{
+		global:  newSemaphore(opts.Global),
+		perRepo: newSemaphore(opts.PerRepo),
+		perIP:   newSemaphore(opts.PerIP),
+		timeout: opts.QueueTimeout,
+	}
+}
+
+// Acquire acquires a slot for a Git operation on the repository with given path
+// from the IP address, it waits in queue when any of the limits is reached. The
+// returned function must be called to release the slot once the operation is
+// done. It returns ErrOperationLimitReached when no slot is available after the
+// queue timeout.
+//
+// The per IP address limit is acquired first, so that a client that exceeds its
+// own limit waits without holding the slots shared with others.
+func (l *OperationLimiter) Acquire(repoPath, ip string) (release func(), err error) {
+	type slot struct {
+		semaphore *sync.KeyedSemaphore
+		key       string
+	}
+	slots := []slot{
+		{l.perIP, ip},
+		{l.perRepo, repoPath},
+		{l.global, ""},
+	}
+
+	acquired := make([]slot, 0, len(slots))
+	release = func() {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			acquired[i].semaphore.Release(acquired[i].key)
+		}
+	}
+	for _, s := range slots {
+		if s.semaphore == nil {
+			continue
+		}
+		if !s.semaphore.Acquire(s.key, l.timeout) {
+			release()
+			return nil, ErrOperationLimitReached
+		}
+		acquired = append(acquired, s)
+	}
+	return release, nil
+}
+
+var (
+	operationLimiterOnce stdsync.Once
+	operationLimiter     *OperationLimiter
+)
+
+// DefaultOperationLimiter returns the OperationLimiter with limits of the
+// "[git]" section in the configuration.
+func DefaultOperationLimiter() *OperationLimiter {
+	operationLimiterOnce.Do(func() {
+		operationLimiter = NewOperationLimiter(OperationLimitOptions{
+			Global:       conf.Git.MaxConcurrentOperations,
+			PerRepo:      conf.Git.MaxConcurrentOperationsPerRepo,
+			PerIP:        conf.Git.MaxConcurrentOperationsPerIP,
+			QueueTimeout: conf.Git.OperationQueueTimeout,
+		})
+	})
+	return operationLimiter
+}
+
+// throttledWriter is a writer that writes at most given bytes per second.
+type throttledWriter struct {
+	w          io.Writer
+	rate       int64
+	start      time.Time
+	written    int64
+	sleep      func(time.Duration)
+	sinceStart func() time.Duration
+}
+
+// NewThrottledWriter returns a writer that writes to w at most given bytes per
+// second. It returns w as-is when the rate is not positive.
+func NewThrottledWriter(w io.Writer, bytesPerSecond int64) io.Writer {
+	if bytesPerSecond <= 0 {
+		return w
+	}
+	tw := &throttledWriter{
+		w:     w,
+		rate:  bytesPerSecond,
+		start: time.Now(),
+		sleep: time.Sleep,
+	}
+	tw.sinceStart = func() time.Duration { return time.Since(tw.start) }
+	return tw
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		// Write in chunks of at most the rate, so that a single large write is
+		// spread over multiple seconds.
+		chunk := p
+		if int64(len(chunk)) > w.rate {
+			chunk = chunk[:w.rate]
+		}
+
+		n, err := w.w.Write(chunk)
+		total += n
+		w.written += int64(n)
+		if err != nil {
+			return total, err
+		}
+		p = p[n:]
+
+		// Wait until the time that the written bytes are allowed by the rate.
+		expected := time.Duration(float64(w.written) / float64(w.rate) * float64(time.Second))
+		if elapsed := w.sinceStart(); expected > elapsed {
+			w.sleep(expected - elapsed)
+		}
+	}
+	return total, nil
+}
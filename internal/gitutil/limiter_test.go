@@ -0,0 +1,132 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationLimiter(t *testing.T) {
+	t.Run("reject over the global limit", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{Global: 2})
+
+		release1, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+		require.NoError(t, err)
+		release2, err := l.Acquire("/repos/alice/b.git", "10.0.0.2")
+		require.NoError(t, err)
+
+		_, err = l.Acquire("/repos/alice/c.git", "10.0.0.3")
+		assert.Equal(t, ErrOperationLimitReached, err)
+
+		// A slot is available again after release
+		release1()
+		release3, err := l.Acquire("/repos/alice/c.git", "10.0.0.3")
+		require.NoError(t, err)
+		release2()
+		release3()
+	})
+
+	t.Run("reject over the per repository limit", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{Global: 10, PerRepo: 1})
+
+		release, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.Acquire("/repos/alice/a.git", "10.0.0.2")
+		assert.Equal(t, ErrOperationLimitReached, err)
+
+		// Other repositories are not affected
+		releaseOther, err := l.Acquire("/repos/alice/b.git", "10.0.0.2")
+		require.NoError(t, err)
+		releaseOther()
+	})
+
+	t.Run("reject over the per IP address limit", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{Global: 2, PerIP: 1})
+
+		release, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.Acquire("/repos/alice/b.git", "10.0.0.1")
+		assert.Equal(t, ErrOperationLimitReached, err)
+
+		// The rejected operation must not hold the global slot, so that other
+		// clients are not starved.
+		releaseOther, err := l.Acquire("/repos/alice/b.git", "10.0.0.2")
+		require.NoError(t, err)
+		releaseOther()
+	})
+
+	t.Run("queue until a slot is released", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{Global: 1, QueueTimeout: time.Minute})
+
+		release, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+		require.NoError(t, err)
+
+		acquired := make(chan struct{})
+		go func() {
+			release, err := l.Acquire("/repos/alice/b.git", "10.0.0.2")
+			if assert.NoError(t, err) {
+				release()
+			}
+			close(acquired)
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("the operation should be queued")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release()
+		select {
+		case <-acquired:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the queued operation should be started after release")
+		}
+	})
+
+	t.Run("reject after the queue timeout", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{Global: 1, QueueTimeout: 10 * time.Millisecond})
+
+		release, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+		require.NoError(t, err)
+		defer release()
+
+		_, err = l.Acquire("/repos/alice/b.git", "10.0.0.2")
+		assert.Equal(t, ErrOperationLimitReached, err)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		l := NewOperationLimiter(OperationLimitOptions{})
+		for i := 0; i < 100; i++ {
+			_, err := l.Acquire("/repos/alice/a.git", "10.0.0.1")
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestThrottledWriter(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Equal(t, &buf, NewThrottledWriter(&buf, 0))
+
+	var slept time.Duration
+	w := NewThrottledWriter(&buf, 100).(*throttledWriter)
+	w.sleep = func(d time.Duration) { slept += d }
+	w.sinceStart = func() time.Duration { return slept }
+
+	n, err := w.Write(bytes.Repeat([]byte("a"), 250))
+	require.NoError(t, err)
+	assert.Equal(t, 250, n)
+	assert.Equal(t, 250, buf.Len())
+	assert.Equal(t, 2500*time.Millisecond, slept)
+}
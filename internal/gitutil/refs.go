@@ -0,0 +1,70 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+)
+
+// RefsPull is the prefix of refs of pull requests, e.g. "refs/pull/1/head".
+const RefsPull = "refs/pull/"
+
+// ListPullRequestRefs returns full names of refs of pull requests in the
+// repository of given path, grouped by indexes of pull requests.
+func ListPullRequestRefs(repoPath string) (map[int64][]string, error) {
+	stderr := new(bytes.Buffer)
+	stdout := new(bytes.Buffer)
+	err := git.NewCommand("for-each-ref", "--format=%(refname)", RefsPull).
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return nil, errors.Errorf("%v - %s", err, stderr)
+	}
+
+	refs := make(map[int64][]string)
+	for _, name := range strings.Split(stdout.String(), "\n") {
+		// Expect "refs/pull/<index>/<name>"
+		fields := strings.SplitN(strings.TrimPrefix(name, RefsPull), "/", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		index, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		refs[index] = append(refs[index], name)
+	}
+	return refs, nil
+}
+
+// DeleteRefs deletes refs of given full names in the repository of given path
+// in a single transaction.
+func DeleteRefs(repoPath string, refs ...string) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	stdin := new(bytes.Buffer)
+	for _, ref := range refs {
+		stdin.WriteString("delete " + ref + "\n")
+	}
+	stderr := new(bytes.Buffer)
+	err := git.NewCommand("update-ref", "--stdin").
+		RunInDirWithOptions(repoPath, git.RunInDirOptions{
+			Stdin:  stdin,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return errors.Errorf("%v - %s", err, stderr)
+	}
+	return nil
+}
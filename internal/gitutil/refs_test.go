@@ -0,0 +1,53 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPullRequestRefs_DeleteRefs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	run("init", "-b", "main")
+	run("commit", "--allow-empty", "-m", "Initial commit")
+	for _, ref := range []string{"refs/pull/1/head", "refs/pull/1/merge", "refs/pull/12/head", "refs/pull/abc/head"} {
+		run("update-ref", ref, "HEAD")
+	}
+
+	refs, err := ListPullRequestRefs(repoPath)
+	require.NoError(t, err)
+	want := map[int64][]string{
+		1:  {"refs/pull/1/head", "refs/pull/1/merge"},
+		12: {"refs/pull/12/head"},
+	}
+	assert.Equal(t, want, refs)
+
+	err = DeleteRefs(repoPath, "refs/pull/1/head", "refs/pull/1/merge")
+	require.NoError(t, err)
+	refs, err = ListPullRequestRefs(repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[int64][]string{12: {"refs/pull/12/head"}}, refs)
+
+	assert.NoError(t, DeleteRefs(repoPath), "deleting no refs should be a no-op")
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+)
+
+// ErrMergeConflict is returned when changes of the upstream branch cannot be
+// merged without conflicts.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// UpdateBranchOptions contains options for updating a branch with changes of
+// an upstream branch.
+type UpdateBranchOptions struct {
+	// The name of the branch to be updated.
+	Branch string
+	// The path of the repository that contains the upstream branch, which can be
+	// the same repository.
+	UpstreamPath string
+	// The name of the upstream branch.
+	UpstreamBranch string
+
+	Author    *git.Signature
+	Committer *git.Signature
+	Message   string
+	// Environment variables of the push, e.g. to run server-side hooks on behalf
+	// of the user.
+	PushEnvs []string
+}
+
+// UpdateBranch creates a merge commit that merges the upstream branch into the
+// branch of the repository in given path, then pushes the branch back to the
+// repository. It returns ErrMergeConflict when the merge has conflicts, and the
+// ID of the merge commit otherwise.
+func UpdateBranch(repoPath string, opts UpdateBranchOptions) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "gogs-update-branch-")
+	if err != nil {
+		return "", errors.Wrap(err, "create temporary directory")
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	workPath := filepath.Join(tmpDir, "work")
+	run := func(dir string, envs []string, args ...string) (string, error) {
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		err := git.NewCommand(args...).AddEnvs(envs...).RunInDirWithOptions(dir, git.RunInDirOptions{
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+		if err != nil {
+			return "", errors.Errorf("%v - %s", err, stderr)
+		}
+		return strings.TrimSpace(stdout.String()), nil
+	}
+
+	_, err = run(tmpDir, nil, "clone", "--quiet", "--no-tags", "-b", opts.Branch, repoPath, workPath)
+	if err != nil {
+		return "", errors.Wrap(err, "clone")
+	}
+
+	upstreamRef := "refs/remotes/upstream/" + opts.UpstreamBranch
+	_, err = run(workPath, nil, "fetch", "--quiet", "--no-tags", opts.UpstreamPath, "+"+git.RefsHeads+opts.UpstreamBranch+":"+upstreamRef)
+	if err != nil {
+		return "", errors.Wrap(err, "fetch upstream")
+	}
+
+	envs := []string{
+		"GIT_AUTHOR_NAME=" + opts.Author.Name,
+		"GIT_AUTHOR_EMAIL=" + opts.Author.Email,
+		"GIT_AUTHOR_DATE=" + gitDate(opts.Author.When),
+		"GIT_COMMITTER_NAME=" + opts.Committer.Name,
+		"GIT_COMMITTER_EMAIL=" + opts.Committer.Email,
+		"GIT_COMMITTER_DATE=" + gitDate(opts.Committer.When),
+	}
+	_, err = run(workPath, envs, "merge", "--no-ff", "--no-edit", "-m", opts.Message, upstreamRef)
+	if err != nil {
+		// Unmerged paths are only left behind by conflicts.
+		if unmerged, _ := run(workPath, nil, "ls-files", "--unmerged"); unmerged != "" {
+			return "", ErrMergeConflict
+		}
+		return "", errors.Wrap(err, "merge")
+	}
+
+	commitID, err := run(workPath, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "get merge commit")
+	}
+
+	_, err = run(workPath, opts.PushEnvs, "push", "--quiet", "origin", "HEAD:"+git.RefsHeads+opts.Branch)
+	if err != nil {
+		return "", errors.Wrap(err, "push")
+	}
+	return commitID, nil
+}
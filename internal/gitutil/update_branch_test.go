@@ -0,0 +1,78 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogs/git-module"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateBranch(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	t.Setenv("GIT_AUTHOR_NAME", "alice")
+	t.Setenv("GIT_AUTHOR_EMAIL", "alice@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "alice")
+	t.Setenv("GIT_COMMITTER_EMAIL", "alice@example.com")
+
+	repoPath := t.TempDir()
+	run := func(args ...string) string {
+		stdout, err := git.NewCommand(args...).RunInDir(repoPath)
+		require.NoError(t, err)
+		return strings.TrimSpace(string(stdout))
+	}
+	commit := func(name, content string) {
+		err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o644)
+		require.NoError(t, err)
+		run("add", name)
+		run("commit", "-m", "Update "+name)
+	}
+	run("init", "-b", "main")
+	commit("README.md", "readme")
+	run("checkout", "-b", "feature")
+	commit("feature.txt", "feature")
+	run("checkout", "main")
+	commit("main.txt", "main")
+	// Detach so the branches can be pushed to by the update.
+	run("checkout", "--detach")
+
+	sig := &git.Signature{Name: "bob", Email: "bob@example.com", When: time.Now()}
+	opts := UpdateBranchOptions{
+		Branch:         "feature",
+		UpstreamPath:   repoPath,
+		UpstreamBranch: "main",
+		Author:         sig,
+		Committer:      sig,
+		Message:        "Merge branch 'main' into feature",
+	}
+	commitID, err := UpdateBranch(repoPath, opts)
+	require.NoError(t, err)
+	assert.Equal(t, commitID, run("rev-parse", "feature"))
+	assert.Equal(t, run("rev-parse", "main"), run("merge-base", "main", "feature"))
+	assert.Equal(t, "main", run("show", "feature:main.txt"))
+	assert.Equal(t, "bob", run("log", "-1", "--format=%an", "feature"))
+
+	t.Run("merge conflict", func(t *testing.T) {
+		run("checkout", "feature")
+		commit("README.md", "feature readme")
+		run("checkout", "main")
+		commit("README.md", "main readme")
+		run("checkout", "--detach")
+
+		before := run("rev-parse", "feature")
+		_, err := UpdateBranch(repoPath, opts)
+		assert.Equal(t, ErrMergeConflict, err)
+		assert.Equal(t, before, run("rev-parse", "feature"))
+	})
+}
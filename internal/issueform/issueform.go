@@ -0,0 +1,256 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package issueform implements YAML-based issue forms, which define structured
+// fields that are rendered as a form and serialized into the issue body.
+package issueform
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType is the type of a form field.
+type FieldType string
+
+const (
+	FieldTypeMarkdown   FieldType = "markdown"
+	FieldTypeInput      FieldType = "input"
+	FieldTypeTextarea   FieldType = "textarea"
+	FieldTypeDropdown   FieldType = "dropdown"
+	FieldTypeCheckboxes FieldType = "checkboxes"
+)
+
+// Form is an issue form.
+type Form struct {
+	// FileName is the name of the file that the form is defined in.
+	FileName    string `yaml:"-"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Title is the default title of new issues.
+	Title string   `yaml:"title"`
+	Body  []*Field `yaml:"body"`
+}
+
+// Field is a field of an issue form.
+type Field struct {
+	// Name is the name of the form value of the field.
+	Name        string           `yaml:"-"`
+	Type        FieldType        `yaml:"type"`
+	ID          string           `yaml:"id"`
+	Attributes  FieldAttributes  `yaml:"attributes"`
+	Validations FieldValidations `yaml:"validations"`
+}
+
+// FieldAttributes contains attributes of a form field, not every attribute
+// applies to every type of fields.
+type FieldAttributes struct {
+	Label       string `yaml:"label"`
+	Description string `yaml:"description"`
+	Placeholder string `yaml:"placeholder"`
+	// Value is the content of markdown fields, or the default value of textarea
+	// fields.
+	Value string `yaml:"value"`
+	// Render is the language to render the value of textarea fields as a code
+	// block.
+	Render string `yaml:"render"`
+	// Multiple indicates whether multiple options of dropdown fields can be
+	// selected.
+	Multiple bool      `yaml:"multiple"`
+	Options  []*Option `yaml:"options"`
+}
+
+// FieldValidations contains validations of a form field.
+type FieldValidations struct {
+	Required bool `yaml:"required"`
+}
+
+// Option is an option of dropdown or checkboxes fields.
+type Option struct {
+	// Name is the name of the form value of checkboxes options.
+	Name     string `yaml:"-"`
+	Label    string `yaml:"label"`
+	Required bool   `yaml:"required"`
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler to accept both plain strings as
+// used by dropdown fields and mappings as used by checkboxes fields.
+func (o *Option) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		o.Label = value.Value
+		return nil
+	}
+
+	type option Option
+	return value.Decode((*option)(o))
+}
+
+// Parse parses and validates the form definition.
+func Parse(fileName string, data []byte) (*Form, error) {
+	var form Form
+	err := yaml.Unmarshal(data, &form)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshal")
+	}
+	form.FileName = fileName
+
+	if form.Name == "" {
+		return nil, errors.New("name is required")
+	} else if len(form.Body) == 0 {
+		return nil, errors.New("body is required")
+	}
+
+	ids := make(map[string]bool)
+	hasInput := false
+	for i, field := range form.Body {
+		pos := fmt.Sprintf("body[%d]", i)
+		if field == nil {
+			return nil, errors.Errorf("%s: field is empty", pos)
+		}
+		field.Name = fmt.Sprintf("form_field_%d", i)
+
+		if field.ID != "" {
+			if ids[field.ID] {
+				return nil, errors.Errorf("%s: duplicated id %q", pos, field.ID)
+			}
+			ids[field.ID] = true
+		}
+
+		switch field.Type {
+		case FieldTypeMarkdown:
+			if field.Attributes.Value == "" {
+				return nil, errors.Errorf("%s: value is required", pos)
+			}
+			continue
+		case FieldTypeInput, FieldTypeTextarea:
+		case FieldTypeDropdown, FieldTypeCheckboxes:
+			if len(field.Attributes.Options) == 0 {
+				return nil, errors.Errorf("%s: options are required", pos)
+			}
+			for j, opt := range field.Attributes.Options {
+				if opt == nil || opt.Label == "" {
+					return nil, errors.Errorf("%s: options[%d]: label is required", pos, j)
+				}
+				if field.Type == FieldTypeCheckboxes {
+					opt.Name = fmt.Sprintf("%s_%d", field.Name, j)
+				}
+			}
+		default:
+			return nil, errors.Errorf("%s: unknown type %q", pos, field.Type)
+		}
+
+		if field.Attributes.Label == "" {
+			return nil, errors.Errorf("%s: label is required", pos)
+		}
+		hasInput = true
+	}
+	if !hasInput {
+		return nil, errors.New("body must contain at least one non-markdown field")
+	}
+	return &form, nil
+}
+
+// RequiredFieldError is returned when a required field is not filled in.
+type RequiredFieldError struct {
+	Label string
+}
+
+func (err RequiredFieldError) Error() string {
+	return fmt.Sprintf("field %q is required", err.Label)
+}
+
+// Validate returns RequiredFieldError for the first required field that is not
+// filled in by the submitted form values.
+func (f *Form) Validate(values url.Values) error {
+	for _, field := range f.Body {
+		switch field.Type {
+		case FieldTypeInput, FieldTypeTextarea, FieldTypeDropdown:
+			if field.Validations.Required && len(fieldValues(field, values)) == 0 {
+				return RequiredFieldError{Label: field.Attributes.Label}
+			}
+		case FieldTypeCheckboxes:
+			for _, opt := range field.Attributes.Options {
+				if opt.Required && values.Get(opt.Name) == "" {
+					return RequiredFieldError{Label: opt.Label}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// fieldValues returns non-empty submitted values of the field. Values of dropdown
+// fields that are not one of the options are ignored.
+func fieldValues(field *Field, values url.Values) []string {
+	var vals []string
+	for _, v := range values[field.Name] {
+		v = strings.TrimSpace(strings.ReplaceAll(v, "\r\n", "\n"))
+		if v == "" {
+			continue
+		}
+
+		if field.Type == FieldTypeDropdown {
+			valid := false
+			for _, opt := range field.Attributes.Options {
+				if opt.Label == v {
+					valid = true
+					break
+				}
+			}
+			if !valid {
+				continue
+			}
+		}
+		vals = append(vals, v)
+		if field.Type != FieldTypeDropdown || !field.Attributes.Multiple {
+			break
+		}
+	}
+	return vals
+}
+
+// Markdown serializes the submitted form values into markdown as the issue body,
+// with a heading for each field.
+func (f *Form) Markdown(values url.Values) string {
+	const noResponse = "_No response_"
+
+	var b strings.Builder
+	for _, field := range f.Body {
+		if field.Type == FieldTypeMarkdown {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("### " + field.Attributes.Label + "\n\n")
+
+		if field.Type == FieldTypeCheckboxes {
+			for _, opt := range field.Attributes.Options {
+				mark := " "
+				if values.Get(opt.Name) != "" {
+					mark = "x"
+				}
+				b.WriteString("- [" + mark + "] " + opt.Label + "\n")
+			}
+			continue
+		}
+
+		vals := fieldValues(field, values)
+		switch {
+		case len(vals) == 0:
+			b.WriteString(noResponse)
+		case field.Type == FieldTypeTextarea && field.Attributes.Render != "":
+			b.WriteString("```" + field.Attributes.Render + "\n" + vals[0] + "\n```")
+		default:
+			b.WriteString(strings.Join(vals, ", "))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,191 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issueform
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bugReport = `
+name: Bug report
+description: Report something that does not work
+title: "[Bug]: "
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to report a bug!
+  - type: input
+    id: version
+    attributes:
+      label: Version
+      placeholder: e.g. 0.13.0
+    validations:
+      required: true
+  - type: textarea
+    id: logs
+    attributes:
+      label: Logs
+      render: shell
+  - type: dropdown
+    id: databases
+    attributes:
+      label: Databases
+      multiple: true
+      options:
+        - SQLite
+        - PostgreSQL
+        - MySQL
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Checklist
+      options:
+        - label: I have searched existing issues
+          required: true
+        - label: I would like to work on a fix
+`
+
+func TestParse(t *testing.T) {
+	form, err := Parse("bug.yml", []byte(bugReport))
+	require.NoError(t, err)
+
+	assert.Equal(t, "bug.yml", form.FileName)
+	assert.Equal(t, "Bug report", form.Name)
+	assert.Equal(t, "Report something that does not work", form.Description)
+	assert.Equal(t, "[Bug]: ", form.Title)
+	require.Len(t, form.Body, 5)
+
+	assert.Equal(t, FieldTypeMarkdown, form.Body[0].Type)
+	assert.Equal(t, "Thanks for taking the time to report a bug!", form.Body[0].Attributes.Value)
+
+	version := form.Body[1]
+	assert.Equal(t, "form_field_1", version.Name)
+	assert.Equal(t, "version", version.ID)
+	assert.Equal(t, "e.g. 0.13.0", version.Attributes.Placeholder)
+	assert.True(t, version.Validations.Required)
+
+	databases := form.Body[3]
+	assert.True(t, databases.Attributes.Multiple)
+	assert.Equal(t, []*Option{{Label: "SQLite"}, {Label: "PostgreSQL"}, {Label: "MySQL"}}, databases.Attributes.Options)
+
+	terms := form.Body[4]
+	assert.Equal(t,
+		[]*Option{
+			{Name: "form_field_4_0", Label: "I have searched existing issues", Required: true},
+			{Name: "form_field_4_1", Label: "I would like to work on a fix"},
+		},
+		terms.Attributes.Options,
+	)
+
+	t.Run("invalid", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			data    string
+			wantErr string
+		}{
+			{
+				name:    "malformed YAML",
+				data:    "name: [",
+				wantErr: "unmarshal",
+			},
+			{
+				name:    "no name",
+				data:    "body:\n  - type: input\n    attributes:\n      label: Version",
+				wantErr: "name is required",
+			},
+			{
+				name:    "no body",
+				data:    "name: Bug",
+				wantErr: "body is required",
+			},
+			{
+				name:    "unknown type",
+				data:    "name: Bug\nbody:\n  - type: slider\n    attributes:\n      label: Severity",
+				wantErr: `body[0]: unknown type "slider"`,
+			},
+			{
+				name:    "no label",
+				data:    "name: Bug\nbody:\n  - type: input",
+				wantErr: "body[0]: label is required",
+			},
+			{
+				name:    "no options",
+				data:    "name: Bug\nbody:\n  - type: dropdown\n    attributes:\n      label: Database",
+				wantErr: "body[0]: options are required",
+			},
+			{
+				name:    "duplicated id",
+				data:    "name: Bug\nbody:\n  - type: input\n    id: a\n    attributes:\n      label: A\n  - type: input\n    id: a\n    attributes:\n      label: B",
+				wantErr: `body[1]: duplicated id "a"`,
+			},
+			{
+				name:    "markdown only",
+				data:    "name: Bug\nbody:\n  - type: markdown\n    attributes:\n      value: Hello",
+				wantErr: "at least one non-markdown field",
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				_, err := Parse("bug.yml", []byte(test.data))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.wantErr)
+			})
+		}
+	})
+}
+
+func TestForm_Validate(t *testing.T) {
+	form, err := Parse("bug.yml", []byte(bugReport))
+	require.NoError(t, err)
+
+	err = form.Validate(url.Values{
+		"form_field_1":   {"  "},
+		"form_field_4_0": {"on"},
+	})
+	assert.Equal(t, RequiredFieldError{Label: "Version"}, err)
+
+	err = form.Validate(url.Values{
+		"form_field_1": {"0.13.0"},
+	})
+	assert.Equal(t, RequiredFieldError{Label: "I have searched existing issues"}, err)
+
+	err = form.Validate(url.Values{
+		"form_field_1":   {"0.13.0"},
+		"form_field_4_0": {"on"},
+	})
+	assert.NoError(t, err)
+}
+
+func TestForm_Markdown(t *testing.T) {
+	form, err := Parse("bug.yml", []byte(bugReport))
+	require.NoError(t, err)
+
+	t.Run("filled in", func(t *testing.T) {
+		got := form.Markdown(url.Values{
+			"form_field_1":   {"0.13.0"},
+			"form_field_2":   {"panic: oops\r\nexit status 2"},
+			"form_field_3":   {"SQLite", "Oracle", "MySQL"},
+			"form_field_4_0": {"on"},
+		})
+		want := "### Version\n\n0.13.0\n" +
+			"\n### Logs\n\n```shell\npanic: oops\nexit status 2\n```\n" +
+			"\n### Databases\n\nSQLite, MySQL\n" +
+			"\n### Checklist\n\n- [x] I have searched existing issues\n- [ ] I would like to work on a fix\n"
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		got := form.Markdown(url.Values{})
+		want := "### Version\n\n_No response_\n" +
+			"\n### Logs\n\n_No response_\n" +
+			"\n### Databases\n\n_No response_\n" +
+			"\n### Checklist\n\n- [ ] I have searched existing issues\n- [ ] I would like to work on a fix\n"
+		assert.Equal(t, want, got)
+	})
+}
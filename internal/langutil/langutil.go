@@ -0,0 +1,265 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package langutil detects programming languages of files for the language
+// statistics of repositories. The detection is based on file names and
+// extensions, with heuristics on the content for ambiguous extensions and
+// scripts without an extension.
+package langutil
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// colors is the display colors of languages known to the classifier.
+var colors = map[string]string{
+	"C":            "#555555",
+	"C#":           "#178600",
+	"C++":          "#f34b7d",
+	"Clojure":      "#db5855",
+	"CMake":        "#da3434",
+	"CoffeeScript": "#244776",
+	"CSS":          "#563d7c",
+	"Dart":         "#00b4ab",
+	"Dockerfile":   "#384d54",
+	"Elixir":       "#6e4a7e",
+	"Erlang":       "#b83998",
+	"Go":           "#00add8",
+	"Groovy":       "#4298b8",
+	"Haskell":      "#5e5086",
+	"HTML":         "#e34c26",
+	"Java":         "#b07219",
+	"JavaScript":   "#f1e05a",
+	"Kotlin":       "#a97bff",
+	"Less":         "#1d365d",
+	"Lua":          "#000080",
+	"Makefile":     "#427819",
+	"MATLAB":       "#e16737",
+	"Objective-C":  "#438eff",
+	"Perl":         "#0298c3",
+	"PHP":          "#4f5d95",
+	"PowerShell":   "#012456",
+	"Python":       "#3572a5",
+	"R":            "#198ce7",
+	"Ruby":         "#701516",
+	"Rust":         "#dea584",
+	"Scala":        "#c22d40",
+	"SCSS":         "#c6538c",
+	"Shell":        "#89e051",
+	"Swift":        "#f05138",
+	"TSX":          "#3178c6",
+	"TypeScript":   "#3178c6",
+	"Vue":          "#41b883",
+}
+
+var (
+	// Lowercased file names that are representing languages.
+	fileNames = map[string]string{
+		"cmakelists.txt": "CMake",
+		"dockerfile":     "Dockerfile",
+		"gnumakefile":    "Makefile",
+		"makefile":       "Makefile",
+		"rakefile":       "Ruby",
+		"gemfile":        "Ruby",
+	}
+
+	// Lowercased extensions that are representing languages without ambiguity.
+	extensions = map[string]string{
+		".bash":   "Shell",
+		".c":      "C",
+		".cc":     "C++",
+		".clj":    "Clojure",
+		".coffee": "CoffeeScript",
+		".cpp":    "C++",
+		".cs":     "C#",
+		".css":    "CSS",
+		".cxx":    "C++",
+		".dart":   "Dart",
+		".erl":    "Erlang",
+		".ex":     "Elixir",
+		".exs":    "Elixir",
+		".go":     "Go",
+		".groovy": "Groovy",
+		".hpp":    "C++",
+		".hs":     "Haskell",
+		".htm":    "HTML",
+		".html":   "HTML",
+		".java":   "Java",
+		".js":     "JavaScript",
+		".jsx":    "JavaScript",
+		".kt":     "Kotlin",
+		".kts":    "Kotlin",
+		".less":   "Less",
+		".lua":    "Lua",
+		".mjs":    "JavaScript",
+		".mk":     "Makefile",
+		".mm":     "Objective-C",
+		".php":    "PHP",
+		".pl":     "Perl",
+		".pm":     "Perl",
+		".ps1":    "PowerShell",
+		".py":     "Python",
+		".r":      "R",
+		".rb":     "Ruby",
+		".rs":     "Rust",
+		".scala":  "Scala",
+		".scss":   "SCSS",
+		".sh":     "Shell",
+		".swift":  "Swift",
+		".ts":     "TypeScript",
+		".tsx":    "TSX",
+		".vue":    "Vue",
+		".zsh":    "Shell",
+	}
+
+	// Interpreters of shebang lines that are representing languages.
+	interpreters = map[string]string{
+		"bash":    "Shell",
+		"node":    "JavaScript",
+		"perl":    "Perl",
+		"php":     "PHP",
+		"python":  "Python",
+		"python2": "Python",
+		"python3": "Python",
+		"ruby":    "Ruby",
+		"sh":      "Shell",
+		"zsh":     "Shell",
+	}
+)
+
+// NeedsContent returns true if the language of the file can't be detected by
+// its name alone, i.e. the extension is ambiguous or there is no extension.
+func NeedsContent(filename string) bool {
+	base := strings.ToLower(path.Base(filename))
+	if _, ok := fileNames[base]; ok {
+		return false
+	}
+
+	switch ext := path.Ext(base); ext {
+	case "":
+		return true
+	case ".h", ".m":
+		return true
+	}
+	return false
+}
+
+var (
+	cppHeaderPattern  = regexp.MustCompile(`(?m)^\s*(class\s+\w+|namespace\s+\w+|template\s*<|#include\s*<(iostream|string|vector|memory|map)>)`)
+	objcHeaderPattern = regexp.MustCompile(`(?m)^\s*(@interface|@protocol|@property|@end\b|#import\s)`)
+	matlabPattern     = regexp.MustCompile(`(?m)^\s*(function\s+.*=|%|end\s*$)`)
+)
+
+// Detect returns the language of the file by its name and content, or an empty
+// string if the language is unknown. The content is only used when NeedsContent
+// returns true, and may be nil otherwise.
+func Detect(filename string, content []byte) string {
+	base := strings.ToLower(path.Base(filename))
+	if name, ok := fileNames[base]; ok {
+		return name
+	}
+
+	ext := path.Ext(base)
+	if name, ok := extensions[ext]; ok {
+		return name
+	}
+
+	switch ext {
+	case "":
+		return shebangLanguage(content)
+	case ".h":
+		switch {
+		case objcHeaderPattern.Match(content):
+			return "Objective-C"
+		case cppHeaderPattern.Match(content):
+			return "C++"
+		}
+		return "C"
+	case ".m":
+		switch {
+		case objcHeaderPattern.Match(content):
+			return "Objective-C"
+		case matlabPattern.Match(content):
+			return "MATLAB"
+		}
+		return "Objective-C"
+	}
+	return ""
+}
+
+// shebangLanguage returns the language of the interpreter in the shebang line of
+// the content, e.g. "#!/usr/bin/env python3".
+func shebangLanguage(content []byte) string {
+	if !bytes.HasPrefix(content, []byte("#!")) {
+		return ""
+	}
+	line := content[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		if len(fields) < 2 {
+			return ""
+		}
+		interpreter = fields[1]
+	}
+	return interpreters[interpreter]
+}
+
+// Color returns the color of the language for displaying, or gray if the
+// language is unknown.
+func Color(name string) string {
+	if color, ok := colors[name]; ok {
+		return color
+	}
+	return "#cccccc"
+}
+
+// IsIgnored returns true if the file path matches any of the patterns. Patterns
+// that end with a slash match directories at any depth, e.g. "vendor/", and
+// others are matched against the file name, e.g. "*.min.js".
+func IsIgnored(filepath string, patterns []string) bool {
+	dirs := strings.Split(path.Dir(filepath), "/")
+	base := path.Base(filepath)
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		if dir := strings.TrimSuffix(pattern, "/"); dir != pattern {
+			for _, d := range dirs {
+				if matched, _ := path.Match(dir, d); matched {
+					return true
+				}
+			}
+			continue
+		}
+
+		if matched, _ := path.Match(pattern, base); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats is the number of bytes of each language.
+type Stats map[string]int64
+
+// Add adds the size of the file to its language. Files of unknown languages are
+// skipped. The content is only needed when NeedsContent returns true.
+func (s Stats) Add(filename string, size int64, content []byte) {
+	if name := Detect(filename, content); name != "" {
+		s[name] += size
+	}
+}
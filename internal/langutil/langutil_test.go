@@ -0,0 +1,99 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package langutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		want     string
+	}{
+		{filename: "main.go", want: "Go"},
+		{filename: "web/src/App.TSX", want: "TSX"},
+		{filename: "Makefile", want: "Makefile"},
+		{filename: "docker/Dockerfile", want: "Dockerfile"},
+		{filename: "CMakeLists.txt", want: "CMake"},
+		{filename: "README.md", want: ""},
+		{filename: "notes.txt", want: ""},
+		{filename: ".gitignore", want: ""},
+
+		{filename: "bin/deploy", content: "#!/usr/bin/env python3\nprint('hi')\n", want: "Python"},
+		{filename: "bin/run", content: "#!/bin/bash\necho hi\n", want: "Shell"},
+		{filename: "LICENSE", content: "MIT License\n", want: ""},
+
+		{filename: "util.h", content: "int add(int a, int b);\n", want: "C"},
+		{filename: "vector.h", content: "#include <vector>\nnamespace util {}\n", want: "C++"},
+		{filename: "View.h", content: "#import <UIKit/UIKit.h>\n@interface View : UIView\n@end\n", want: "Objective-C"},
+		{filename: "View.m", content: "#import \"View.h\"\n@implementation View\n@end\n", want: "Objective-C"},
+		{filename: "solve.m", content: "% Solve the system\nfunction x = solve(A, b)\n  x = A \\ b;\nend\n", want: "MATLAB"},
+	}
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			assert.Equal(t, test.want, Detect(test.filename, []byte(test.content)))
+		})
+	}
+}
+
+func TestNeedsContent(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     bool
+	}{
+		{filename: "main.go", want: false},
+		{filename: "Makefile", want: false},
+		{filename: "include/util.h", want: true},
+		{filename: "View.m", want: true},
+		{filename: "bin/deploy", want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			assert.Equal(t, test.want, NeedsContent(test.filename))
+		})
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	patterns := []string{"vendor/", "node_modules/", "*.min.js", " *.pb.go "}
+	tests := []struct {
+		filepath string
+		want     bool
+	}{
+		{filepath: "vendor/github.com/pkg/errors/errors.go", want: true},
+		{filepath: "web/node_modules/react/index.js", want: true},
+		{filepath: "public/js/jquery.min.js", want: true},
+		{filepath: "api/service.pb.go", want: true},
+		{filepath: "main.go", want: false},
+		{filepath: "internal/vendored/main.go", want: false},
+		{filepath: "public/js/index.js", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.filepath, func(t *testing.T) {
+			assert.Equal(t, test.want, IsIgnored(test.filepath, patterns))
+		})
+	}
+}
+
+func TestStats_Add(t *testing.T) {
+	stats := make(Stats)
+	stats.Add("main.go", 100, nil)
+	stats.Add("internal/db/db.go", 250, nil)
+	stats.Add("public/js/index.js", 80, nil)
+	stats.Add("scripts/build", 20, []byte("#!/bin/sh\nmake\n"))
+	stats.Add("README.md", 1000, nil)
+	stats.Add("logo.png", 5000, nil)
+
+	want := Stats{
+		"Go":         350,
+		"JavaScript": 80,
+		"Shell":      20,
+	}
+	assert.Equal(t, want, stats)
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package lfsutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const StorageS3 Storage = "s3"
+
+var _ Storager = (*S3Storage)(nil)
+
+// S3Storage is a LFS storage backend on S3-compatible object storage. Objects
+// are addressed in path-style, i.e. "<endpoint>/<bucket>/<key>", which is
+// supported by most of S3-compatible services.
+type S3Storage struct {
+	// The endpoint of the service, e.g. "https://s3.us-east-1.amazonaws.com".
+	Endpoint string
+	// The region of the bucket, e.g. "us-east-1".
+	Region string
+	// The name of the bucket to store LFS objects.
+	Bucket string
+	// The credentials to sign requests.
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// The HTTP client to send requests, http.DefaultClient is used when nil.
+	Client *http.Client
+}
+
+func (*S3Storage) Storage() Storage {
+	return StorageS3
+}
+
+func (s *S3Storage) objectURL(oid OID) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s", strings.TrimSuffix(s.Endpoint, "/"), s.Bucket, string(oid[0]), string(oid[1]), string(oid))
+}
+
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func (s *S3Storage) Upload(oid OID, rc io.ReadCloser) (int64, error) {
+	defer rc.Close()
+
+	if !ValidOID(oid) {
+		return 0, ErrInvalidOID
+	}
+
+	// NOTE: S3 requires the size and hash of the content to be known before
+	// uploading, the content is buffered on local disk to get them.
+	f, err := os.CreateTemp("", "lfs-"+string(oid))
+	if err != nil {
+		return 0, errors.Wrap(err, "create temporary file")
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(f, hash), rc)
+	if err != nil {
+		return 0, errors.Wrap(err, "copy file")
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return 0, errors.Wrap(err, "seek file")
+	}
+
+	req, err := http.NewRequest(http.MethodPut, s.objectURL(oid), io.NopCloser(f))
+	if err != nil {
+		return 0, errors.Wrap(err, "new request")
+	}
+	req.ContentLength = written
+	resp, err := s.do(req, hex.EncodeToString(hash.Sum(nil)))
+	if err != nil {
+		return 0, errors.Wrap(err, "put object")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return 0, errors.Errorf("put object: unexpected status %d: %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+	return written, nil
+}
+
+func (s *S3Storage) Download(oid OID, w io.Writer) error {
+	if !ValidOID(oid) {
+		return ErrObjectNotExist
+	}
+
+	req, err := http.NewRequest(http.MethodGet, s.objectURL(oid), nil)
+	if err != nil {
+		return errors.Wrap(err, "new request")
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return errors.Wrap(err, "get object")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrObjectNotExist
+	} else if resp.StatusCode/100 != 2 {
+		return errors.Errorf("get object: unexpected status %d: %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "copy file")
+	}
+	return nil
+}
+
+// readErrorBody returns the leading part of the error response for logging.
+func readErrorBody(r io.Reader) string {
+	p, _ := io.ReadAll(io.LimitReader(r, 512))
+	return strings.TrimSpace(string(p))
+}
+
+// emptyPayloadHash is the hex-encoded SHA256 hash of an empty payload.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// sign signs the request with AWS Signature Version 4 at the given time, see
+// https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+// for details. All headers of the request are signed.
+func (s *S3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.Query().Encode(),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hexSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
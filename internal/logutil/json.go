@@ -0,0 +1,143 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package logutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+)
+
+// nowFunc returns the current time, it is only mocked by tests.
+var nowFunc = time.Now
+
+// formatJSON returns the message as a JSON object with the time, level,
+// message and contextual fields of it, e.g.
+//
+//	{"time":"2026-10-15T08:00:00Z","level":"info","message":"Hello","request_id":"..."}
+func formatJSON(m log.Messager) []byte {
+	body := strings.TrimPrefix(m.String(), fmt.Sprintf("[%5s] ", m.Level()))
+	msg, fs := parseFields(body)
+
+	var buf bytes.Buffer
+	write := func(key string, value any) {
+		if buf.Len() == 0 {
+			buf.WriteByte('{')
+		} else {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(key)
+		buf.Write(k)
+		buf.WriteByte(':')
+		v, err := json.Marshal(value)
+		if err != nil {
+			v, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buf.Write(v)
+	}
+	write("time", nowFunc().Format(time.RFC3339Nano))
+	write("level", strings.ToLower(m.Level().String()))
+	write("message", msg)
+	for _, f := range fs {
+		switch f.Key {
+		case "time", "level", "message":
+			// NOTE: Fields must not shadow the essentials of the message.
+			continue
+		}
+		write(f.Key, f.Value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// jsonMessage is a message that has been formatted as JSON.
+type jsonMessage struct {
+	level log.Level
+	body  string
+}
+
+func (m *jsonMessage) Level() log.Level { return m.level }
+func (m *jsonMessage) String() string   { return m.body }
+
+var _ log.Logger = (*jsonWriterLogger)(nil)
+
+// jsonWriterLogger is a logger that writes messages as JSON objects to the
+// writer, one object per line.
+type jsonWriterLogger struct {
+	name  string
+	level log.Level
+	w     io.Writer
+}
+
+func (l *jsonWriterLogger) Name() string     { return l.name }
+func (l *jsonWriterLogger) Level() log.Level { return l.level }
+
+func (l *jsonWriterLogger) Write(m log.Messager) error {
+	_, err := l.w.Write(append(formatJSON(m), '\n'))
+	return err
+}
+
+// JSONConsoleIniter returns the initer for the console logger that writes
+// messages as JSON objects to the standard output, one object per line. It
+// accepts the same config object as the console logger.
+func JSONConsoleIniter() log.Initer {
+	return func(name string, vs ...any) (log.Logger, error) {
+		var cfg log.ConsoleConfig
+		for i := range vs {
+			if v, ok := vs[i].(log.ConsoleConfig); ok {
+				cfg = v
+			}
+		}
+		return &jsonWriterLogger{
+			name:  name,
+			level: cfg.Level,
+			w:     os.Stdout,
+		}, nil
+	}
+}
+
+var _ log.Logger = (*jsonFileLogger)(nil)
+
+// jsonFileLogger is a file logger that writes messages as JSON objects, one
+// object per line.
+type jsonFileLogger struct {
+	log.Logger
+}
+
+func (l *jsonFileLogger) Write(m log.Messager) error {
+	// NOTE: The file logger prefixes every line with the date and time and
+	// resets the flags whenever rotating files, which must be turned off right
+	// before writing to keep each line a valid JSON object.
+	if f, ok := l.Logger.(interface{ SetFlags(int) }); ok {
+		f.SetFlags(0)
+	}
+	return l.Logger.Write(&jsonMessage{
+		level: m.Level(),
+		body:  string(formatJSON(m)),
+	})
+}
+
+// JSONFileIniter returns the initer for the file logger that writes messages
+// as JSON objects, one object per line. It accepts the same config object as
+// the file logger and rotates files in the same way.
+func JSONFileIniter() log.Initer {
+	return func(name string, vs ...any) (log.Logger, error) {
+		l, err := log.FileIniter()(name, vs...)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := l.(interface{ SetFlags(int) }); !ok {
+			return nil, errors.New("file logger does not support changing flags")
+		}
+		return &jsonFileLogger{Logger: l}, nil
+	}
+}
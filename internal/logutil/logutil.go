@@ -0,0 +1,172 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package logutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "unknwon.dev/clog/v2"
+)
+
+// Field is a contextual key-value pair of log messages, e.g. the ID of the
+// request that the message is written for.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// fieldsSeparator separates the message from its fields in the body of log
+// messages.
+const fieldsSeparator = "\t"
+
+// fields is the suffix of log messages that carries contextual fields in the
+// format of "key=value", values are quoted when necessary. It is formatted
+// lazily by the logging service only when any logger is going to write the
+// message, thus logging with fields costs nothing more when the message is
+// filtered by level.
+type fields []Field
+
+func (fs fields) String() string {
+	if len(fs) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(fieldsSeparator)
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+
+		v := fmt.Sprint(f.Value)
+		if v == "" || strings.ContainsAny(v, " =\"\t\r\n") || strconv.Quote(v) != `"`+v+`"` {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
+
+// parseFields splits the body of log messages into the message and its
+// contextual fields. The whole body is returned as the message when it does not
+// end with well-formed fields.
+func parseFields(body string) (string, []Field) {
+	i := strings.LastIndex(body, fieldsSeparator)
+	if i < 0 {
+		return body, nil
+	}
+
+	var fs []Field
+	s := body[i+len(fieldsSeparator):]
+	for len(s) > 0 {
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 || strings.ContainsAny(s[:eq], " \"") {
+			return body, nil
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return body, nil
+			}
+			value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+		} else {
+			end := strings.IndexByte(s, ' ')
+			if end < 0 {
+				end = len(s)
+			}
+			value = s[:end]
+			s = s[end:]
+		}
+		fs = append(fs, Field{Key: key, Value: value})
+
+		if len(s) > 0 {
+			if s[0] != ' ' {
+				return body, nil
+			}
+			s = s[1:]
+		}
+	}
+	if len(fs) == 0 {
+		return body, nil
+	}
+	return body[:i], fs
+}
+
+// Logger writes log messages with contextual fields. The zero value writes log
+// messages without any field.
+type Logger struct {
+	fields fields
+}
+
+// With returns a copy of the logger with the field appended.
+func (l *Logger) With(key string, value any) *Logger {
+	var fs fields
+	if l != nil {
+		fs = make(fields, len(l.fields), len(l.fields)+1)
+		copy(fs, l.fields)
+	}
+	return &Logger{fields: append(fs, Field{Key: key, Value: value})}
+}
+
+// args returns the formatting arguments of the message followed by the fields
+// of the logger.
+func (l *Logger) args(v []any) []any {
+	if l == nil || len(l.fields) == 0 {
+		return append(v[:len(v):len(v)], "")
+	}
+	return append(v[:len(v):len(v)], l.fields)
+}
+
+// Trace writes formatted log in Trace level.
+func (l *Logger) Trace(format string, v ...any) {
+	log.Trace(format+"%v", l.args(v)...)
+}
+
+// Info writes formatted log in Info level.
+func (l *Logger) Info(format string, v ...any) {
+	log.Info(format+"%v", l.args(v)...)
+}
+
+// Warn writes formatted log in Warn level.
+func (l *Logger) Warn(format string, v ...any) {
+	log.Warn(format+"%v", l.args(v)...)
+}
+
+// Error writes formatted log in Error level.
+func (l *Logger) Error(format string, v ...any) {
+	l.ErrorDepth(4, format, v...)
+}
+
+// ErrorDepth writes formatted log with given skip depth in Error level.
+func (l *Logger) ErrorDepth(skip int, format string, v ...any) {
+	log.ErrorDepth(skip+1, format+"%v", l.args(v)...)
+}
+
+type loggerKey struct{}
+
+// NewContext returns a copy of the context with the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext returns the logger in the context, or a logger without any field
+// if there is none.
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok {
+		return &Logger{}
+	}
+	return l
+}
@@ -0,0 +1,191 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package logutil
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	log "unknwon.dev/clog/v2"
+)
+
+func TestFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		fields   fields
+		wantBody string
+	}{
+		{
+			name:     "no fields",
+			fields:   nil,
+			wantBody: "Hello world",
+		},
+		{
+			name:     "plain values",
+			fields:   fields{{Key: "request_id", Value: "abc"}, {Key: "user", Value: "alice"}},
+			wantBody: "Hello world\trequest_id=abc user=alice",
+		},
+		{
+			name:     "quoted values",
+			fields:   fields{{Key: "repo", Value: "alice/my repo"}, {Key: "empty", Value: ""}, {Key: "quote", Value: `say "hi"=`}},
+			wantBody: "Hello world\trepo=\"alice/my repo\" empty=\"\" quote=\"say \\\"hi\\\"=\"",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body := "Hello world" + test.fields.String()
+			assert.Equal(t, test.wantBody, body)
+
+			msg, fs := parseFields(body)
+			assert.Equal(t, "Hello world", msg)
+			assert.Equal(t, []Field(test.fields), fs)
+		})
+	}
+
+	t.Run("non-string values", func(t *testing.T) {
+		assert.Equal(t, "\tid=1 ok=true", fields{{Key: "id", Value: 1}, {Key: "ok", Value: true}}.String())
+	})
+
+	t.Run("malformed fields", func(t *testing.T) {
+		for _, body := range []string{
+			"Hello\tworld",
+			"Hello\tkey=\"unterminated",
+			"Hello\tkey=\"value\"trailing",
+			"Hello\t=value",
+			"Hello\tmy key=value",
+		} {
+			msg, fs := parseFields(body)
+			assert.Equal(t, body, msg)
+			assert.Nil(t, fs)
+		}
+	})
+}
+
+func TestLogger_With(t *testing.T) {
+	var l *Logger
+	l1 := l.With("request_id", "abc")
+	l2 := l1.With("user", "alice")
+	l3 := l1.With("repo", "alice/example")
+
+	assert.Equal(t, fields{{Key: "request_id", Value: "abc"}}, l1.fields)
+	assert.Equal(t, fields{{Key: "request_id", Value: "abc"}, {Key: "user", Value: "alice"}}, l2.fields)
+	assert.Equal(t, fields{{Key: "request_id", Value: "abc"}, {Key: "repo", Value: "alice/example"}}, l3.fields)
+
+	ctx := context.Background()
+	assert.Equal(t, &Logger{}, FromContext(ctx))
+	assert.Equal(t, l2, FromContext(NewContext(ctx, l2)))
+}
+
+type chanWriter chan []byte
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestJSON(t *testing.T) {
+	before := nowFunc
+	t.Cleanup(func() {
+		nowFunc = before
+	})
+	now := time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC)
+	nowFunc = func() time.Time { return now }
+
+	const name = "TestJSON"
+	w := make(chanWriter, 1)
+	err := log.New(name, func(name string, _ ...any) (log.Logger, error) {
+		return &jsonWriterLogger{name: name, level: log.LevelInfo, w: w}, nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		log.Remove(name)
+	})
+
+	readEntry := func(t *testing.T) map[string]any {
+		t.Helper()
+
+		var line []byte
+		select {
+		case line = <-w:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for log entry")
+		}
+		require.True(t, len(line) > 0 && line[len(line)-1] == '\n', "want a line but got %q", line)
+
+		var entry map[string]any
+		err := json.Unmarshal(line, &entry)
+		require.NoError(t, err, "want valid JSON but got %q", line)
+		return entry
+	}
+
+	t.Run("with fields", func(t *testing.T) {
+		l := (&Logger{}).
+			With("request_id", "abc").
+			With("user", "alice").
+			With("repo", "alice/my repo")
+		l.Trace("Filtered by level")
+		l.Info("Hello %s", "world")
+
+		want := map[string]any{
+			"time":       "2026-10-15T08:00:00Z",
+			"level":      "info",
+			"message":    "Hello world",
+			"request_id": "abc",
+			"user":       "alice",
+			"repo":       "alice/my repo",
+		}
+		assert.Equal(t, want, readEntry(t))
+	})
+
+	t.Run("without fields", func(t *testing.T) {
+		log.Warn("Disk usage is %d%%", 90)
+
+		want := map[string]any{
+			"time":    "2026-10-15T08:00:00Z",
+			"level":   "warn",
+			"message": "Disk usage is 90%",
+		}
+		assert.Equal(t, want, readEntry(t))
+	})
+
+	t.Run("fields do not shadow essentials", func(t *testing.T) {
+		(&Logger{}).With("level", "trace").With("message", "Bye").Warn("Hello")
+
+		want := map[string]any{
+			"time":    "2026-10-15T08:00:00Z",
+			"level":   "warn",
+			"message": "Hello",
+		}
+		assert.Equal(t, want, readEntry(t))
+	})
+}
+
+func TestJSONFileIniter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "gogs.log")
+	l, err := JSONFileIniter()("file", log.FileConfig{
+		Level:    log.LevelTrace,
+		Filename: filename,
+	})
+	require.NoError(t, err)
+
+	err = l.Write(&jsonMessage{level: log.LevelInfo, body: "[ INFO] Hello\tuser=alice"})
+	require.NoError(t, err)
+
+	p, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	// Lines are not prefixed by the date and time as other files
+	var entry map[string]any
+	err = json.Unmarshal(p, &entry)
+	require.NoError(t, err, "want valid JSON but got %q", p)
+	assert.Equal(t, "Hello", entry["message"])
+	assert.Equal(t, "alice", entry["user"])
+}
@@ -0,0 +1,83 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"bytes"
+	"encoding/csv"
+	"html"
+	"io"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+type csvRenderer struct{}
+
+func (*csvRenderer) Extensions() []string {
+	return []string{".csv", ".tsv"}
+}
+
+// Render renders the CSV (or TSV when the content has no comma in the first
+// line) content to an HTML table with the first row as the table header.
+func (*csvRenderer) Render(input []byte, _ string) ([]byte, error) {
+	r := csv.NewReader(bytes.NewReader(input))
+	firstLine, _, _ := bytes.Cut(input, []byte("\n"))
+	if !bytes.ContainsRune(firstLine, ',') && bytes.ContainsRune(firstLine, '\t') {
+		r.Comma = '\t'
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	var buf bytes.Buffer
+	buf.WriteString(`<table class="ui celled compact table">`)
+	rows := 0
+	for ; ; rows++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "read record")
+		}
+
+		cellTag := "td"
+		if rows == 0 {
+			cellTag = "th"
+			buf.WriteString("<thead>")
+		} else if rows == 1 {
+			buf.WriteString("<tbody>")
+		}
+
+		buf.WriteString("<tr>")
+		for _, field := range record {
+			buf.WriteString("<" + cellTag + ">")
+			buf.WriteString(html.EscapeString(field))
+			buf.WriteString("</" + cellTag + ">")
+		}
+		buf.WriteString("</tr>")
+
+		if rows == 0 {
+			buf.WriteString("</thead>")
+		}
+	}
+	if rows > 1 {
+		buf.WriteString("</tbody>")
+	}
+	buf.WriteString("</table>")
+
+	// All fields have been escaped, the sanitizer is only the last line of
+	// defense.
+	return csvPolicy.SanitizeBytes(buf.Bytes()), nil
+}
+
+var csvPolicy = &lazyPolicy{
+	build: func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AllowAttrs("class").Matching(lazyregexp.New(`^ui celled compact table$`).Regexp()).OnElements("table")
+		return p
+	},
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/lazyregexp"
+	"gogs.io/gogs/public"
+)
+
+// EmojiPattern matches shortcode emoji, e.g. :smile:
+var EmojiPattern = lazyregexp.New(`:[0-9a-zA-Z_+-]+:`)
+
+var emojiNamePattern = lazyregexp.New(`^[0-9a-zA-Z_+-]+$`)
+
+// customEmojiExts is the list of file extensions that are loaded as custom emoji.
+var customEmojiExts = []string{".png", ".gif", ".jpg", ".jpeg", ".webp"}
+
+var builtinEmoji struct {
+	once  sync.Once
+	names map[string]bool
+}
+
+// builtinEmojiNames returns the set of names of builtin emoji shipped with the
+// public assets.
+func builtinEmojiNames() map[string]bool {
+	builtinEmoji.once.Do(func() {
+		builtinEmoji.names = make(map[string]bool)
+		entries, err := public.Files.ReadDir("img/emoji")
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if path.Ext(name) == ".png" {
+				builtinEmoji.names[strings.TrimSuffix(name, ".png")] = true
+			}
+		}
+	})
+	return builtinEmoji.names
+}
+
+// customEmoji maps names of custom emoji to their file names.
+var customEmoji map[string]string
+
+// LoadCustomEmoji loads custom emoji from image files in the given directory,
+// where each file is available as an emoji by its name without extension. It
+// unloads all custom emoji when the directory is empty.
+func LoadCustomEmoji(dir string) error {
+	if dir == "" {
+		customEmoji = nil
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Wrap(err, "read directory")
+	}
+
+	emoji := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		ext := strings.ToLower(path.Ext(entry.Name()))
+		name := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		if !isCustomEmojiExt(ext) || !emojiNamePattern.MatchString(name) {
+			continue
+		}
+		emoji[name] = entry.Name()
+	}
+	customEmoji = emoji
+	return nil
+}
+
+func isCustomEmojiExt(ext string) bool {
+	for _, e := range customEmojiExts {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderEmoji renders shortcode emoji to corresponding images. Shortcodes that
+// are neither custom nor builtin emoji are kept as they are.
+func RenderEmoji(rawBytes []byte) []byte {
+	return EmojiPattern.ReplaceAllFunc(rawBytes, func(m []byte) []byte {
+		name := string(m[1 : len(m)-1])
+
+		var src string
+		if file, ok := customEmoji[name]; ok {
+			src = conf.Server.Subpath + "/" + conf.CustomEmojiPathPrefix + "/" + file
+		} else if builtinEmojiNames()[name] {
+			src = conf.Server.Subpath + "/img/emoji/" + name + ".png"
+		} else {
+			return m
+		}
+		return []byte(fmt.Sprintf(`<img class="emoji" src="%s" alt=":%s:" title=":%s:">`, src, name, name))
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gogs.io/gogs/internal/conf"
+	. "gogs.io/gogs/internal/markup"
+)
+
+func TestRenderEmoji(t *testing.T) {
+	conf.SetMockServer(t, conf.ServerOpts{Subpath: "/gogs"})
+
+	dir := t.TempDir()
+	for _, name := range []string{"parrot.gif", "smile.png", "readme.txt", "bad name.png"} {
+		err := os.WriteFile(filepath.Join(dir, name), nil, 0o644)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name        string
+		customEmoji string
+		input       string
+		want        string
+	}{
+		{
+			name:  "builtin emoji",
+			input: "Hello :smile: and :+1:",
+			want:  `Hello <img class="emoji" src="/gogs/img/emoji/smile.png" alt=":smile:" title=":smile:"> and <img class="emoji" src="/gogs/img/emoji/+1.png" alt=":+1:" title=":+1:">`,
+		},
+		{
+			name:  "unknown emoji",
+			input: "Time is 10:30:00 :not_an_emoji: :parrot:",
+			want:  "Time is 10:30:00 :not_an_emoji: :parrot:",
+		},
+		{
+			name:        "custom emoji",
+			customEmoji: dir,
+			input:       ":parrot::smile: :readme: :bad name:",
+			want:        `<img class="emoji" src="/gogs/custom-emoji/parrot.gif" alt=":parrot:" title=":parrot:"><img class="emoji" src="/gogs/custom-emoji/smile.png" alt=":smile:" title=":smile:"> :readme: :bad name:`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := LoadCustomEmoji(test.customEmoji)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				_ = LoadCustomEmoji("")
+			})
+
+			assert.Equal(t, test.want, string(RenderEmoji([]byte(test.input))))
+		})
+	}
+}
+
+func TestLoadCustomEmoji(t *testing.T) {
+	err := LoadCustomEmoji(filepath.Join(t.TempDir(), "404"))
+	assert.Error(t, err)
+}
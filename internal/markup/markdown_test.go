@@ -74,3 +74,29 @@ func Test_Markdown(t *testing.T) {
 		})
 	}
 }
+
+func TestRawMarkdown_Autolink(t *testing.T) {
+	input := []byte("See https://gogs.io")
+
+	tests := []struct {
+		name string
+		opts conf.MarkdownOpts
+		want string
+	}{
+		{
+			name: "enabled",
+			want: "<p>See <a href=\"https://gogs.io\">https://gogs.io</a></p>\n",
+		},
+		{
+			name: "disabled",
+			opts: conf.MarkdownOpts{DisableAutolink: true},
+			want: "<p>See https://gogs.io</p>\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.SetMockMarkdown(t, test.opts)
+			assert.Equal(t, test.want, string(RawMarkdown(input, "")))
+		})
+	}
+}
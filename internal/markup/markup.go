@@ -8,6 +8,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/unknwon/com"
@@ -23,11 +25,6 @@ func IsReadmeFile(name string) bool {
 	return strings.HasPrefix(strings.ToLower(name), "readme")
 }
 
-// IsIPythonNotebook reports whether name looks like a IPython notebook based on its extension.
-func IsIPythonNotebook(name string) bool {
-	return strings.HasSuffix(name, ".ipynb")
-}
-
 const (
 	IssueNameStyleNumeric      = "numeric"
 	IssueNameStyleAlphanumeric = "alphanumeric"
@@ -49,8 +46,8 @@ var (
 	// IssueAlphanumericPattern matches string that references to an alphanumeric issue, e.g. ABC-1234
 	IssueAlphanumericPattern = lazyregexp.New(`( |^|\(|\[)[A-Z]{1,10}-[1-9][0-9]*\b`)
 	// CrossReferenceIssueNumericPattern matches string that references a numeric issue in a difference repository
-	// e.g. gogs/gogs#12345
-	CrossReferenceIssueNumericPattern = lazyregexp.New(`( |^)[0-9a-zA-Z-_\.]+/[0-9a-zA-Z-_\.]+#[0-9]+\b`)
+	// e.g. gogs/gogs#12345, or docs#12345 for a repository of the same owner
+	CrossReferenceIssueNumericPattern = lazyregexp.New(`( |^|\()([0-9a-zA-Z-_\.]+/)?[0-9a-zA-Z-_\.]+#[0-9]+\b`)
 
 	// Sha1CurrentPattern matches string that represents a commit SHA, e.g. d8a994ef243349f321568f9e36d5c3f444b99cae
 	// FIXME: this pattern matches pure numbers as well, right now we do a hack to check in RenderSha1CurrentPattern by converting string to a number.
@@ -114,29 +111,123 @@ func RenderIssueIndexPattern(rawBytes []byte, urlPrefix string, metas map[string
 		}
 		rawBytes = bytes.Replace(rawBytes, m, []byte(link), 1)
 	}
-	return rawBytes
+
+	// Issue keys are only rendered for the internal issue tracker.
+	if metas == nil || metas["format"] != "" || metas["issueKeyPrefix"] == "" {
+		return rawBytes
+	}
+	prefix := metas["issueKeyPrefix"]
+	return issueKeyPattern(prefix).ReplaceAllFunc(rawBytes, func(m []byte) []byte {
+		var lead string
+		if m[0] == ' ' || m[0] == '(' || m[0] == '[' {
+			lead, m = string(m[:1]), m[1:]
+		}
+		index, _ := ParseIssueKey(string(m), prefix)
+		return []byte(fmt.Sprintf(`%s<a href="%s/issues/%d">%s</a>`, lead, urlPrefix, index, m))
+	})
+}
+
+// IssueKeyPrefixPattern matches valid prefixes of issue keys, e.g. PROJ
+var IssueKeyPrefixPattern = lazyregexp.New(`^[A-Z][A-Z0-9]{0,9}$`)
+
+// issueKeyPattern returns the pattern that matches string that references to an
+// issue by its key with given prefix, e.g. PROJ-123 for the prefix "PROJ".
+func issueKeyPattern(prefix string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[ (\[])(` + regexp.QuoteMeta(prefix) + `-[1-9][0-9]*)\b`)
+}
+
+// FindAllIssueKeys returns issue keys with given prefix that are referenced in
+// given content, e.g. PROJ-123 for the prefix "PROJ".
+func FindAllIssueKeys(content, prefix string) []string {
+	if prefix == "" {
+		return nil
+	}
+
+	matches := issueKeyPattern(prefix).FindAllStringSubmatch(content, -1)
+	keys := make([]string, len(matches))
+	for i := range matches {
+		keys[i] = matches[i][1]
+	}
+	return keys
+}
+
+// ParseIssueKey parses the issue key in form of "PROJ-123" with given prefix,
+// and returns the index of the issue.
+func ParseIssueKey(key, prefix string) (index int64, ok bool) {
+	if prefix == "" || !strings.HasPrefix(key, prefix+"-") {
+		return 0, false
+	}
+
+	index, err := strconv.ParseInt(key[len(prefix)+1:], 10, 64)
+	if err != nil || index <= 0 {
+		return 0, false
+	}
+	return index, true
+}
+
+// ParseIssueReference parses the issue reference in forms of "#123", "repo#123" and
+// "owner/repo#123". The owner and repository name default to given ones when
+// omitted from the reference.
+func ParseIssueReference(ref, defaultOwner, defaultRepo string) (owner, repo string, index int64, ok bool) {
+	n := strings.LastIndexByte(ref, '#')
+	if n == -1 {
+		return "", "", 0, false
+	}
+
+	index = com.StrTo(ref[n+1:]).MustInt64()
+	if index <= 0 {
+		return "", "", 0, false
+	}
+
+	owner, repo = defaultOwner, defaultRepo
+	if n > 0 {
+		repo = ref[:n]
+		if i := strings.IndexByte(repo, '/'); i > -1 {
+			owner, repo = repo[:i], repo[i+1:]
+		}
+	}
+	if owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return "", "", 0, false
+	}
+	return owner, repo, index, true
 }
 
-// Note: this section is for purpose of increase performance and
-// reduce memory allocation at runtime since they are constant literals.
-var pound = []byte("#")
+// ResolveRepoLink returns the link of the repository with given owner and name
+// when it exists and is visible to everyone. Cross-repository issue references
+// that it fails to resolve are rendered as plain text. When it is not set, only
+// references with both owner and repository name are rendered, without being
+// verified.
+var ResolveRepoLink func(owner, name string) (link string, ok bool)
 
 // RenderCrossReferenceIssueIndexPattern renders issue indexes from other repositories to corresponding links.
-func RenderCrossReferenceIssueIndexPattern(rawBytes []byte, _ string, _ map[string]string) []byte {
-	ms := CrossReferenceIssueNumericPattern.FindAll(rawBytes, -1)
-	for _, m := range ms {
+func RenderCrossReferenceIssueIndexPattern(rawBytes []byte, _ string, metas map[string]string) []byte {
+	return []byte(CrossReferenceIssueNumericPattern.ReplaceAllStringFunc(string(rawBytes), func(m string) string {
+		var prefix string
 		if m[0] == ' ' || m[0] == '(' {
-			m = m[1:] // ignore leading space or opening parentheses
+			prefix, m = m[:1], m[1:] // ignore leading space or opening parentheses
 		}
 
-		delimIdx := bytes.Index(m, pound)
-		repo := string(m[:delimIdx])
-		index := string(m[delimIdx+1:])
+		// References to a repository of the same owner are only supported with a
+		// resolver to verify them.
+		hasOwner := strings.Contains(m, "/")
+		if !hasOwner && (ResolveRepoLink == nil || metas["user"] == "") {
+			return prefix + m
+		}
 
-		link := fmt.Sprintf(`<a href="%s%s/issues/%s">%s</a>`, conf.Server.ExternalURL, repo, index, m)
-		rawBytes = bytes.Replace(rawBytes, m, []byte(link), 1)
-	}
-	return rawBytes
+		owner, repo, index, ok := ParseIssueReference(m, metas["user"], "")
+		if !ok {
+			return prefix + m
+		}
+
+		link := conf.Server.ExternalURL + owner + "/" + repo
+		if ResolveRepoLink != nil {
+			link, ok = ResolveRepoLink(owner, repo)
+			if !ok {
+				return prefix + m
+			}
+		}
+		return fmt.Sprintf(`%s<a href="%s/issues/%d">%s</a>`, prefix, link, index, m)
+	}))
 }
 
 // RenderSha1CurrentPattern renders SHA1 strings to corresponding links that assumes in the same repository.
@@ -150,17 +241,31 @@ func RenderSha1CurrentPattern(rawBytes []byte, urlPrefix string) []byte {
 	}))
 }
 
-// RenderSpecialLink renders mentions, indexes and SHA1 strings to corresponding links.
-func RenderSpecialLink(rawBytes []byte, urlPrefix string, metas map[string]string) []byte {
+// RenderMentions renders mentions to corresponding links of user profiles.
+func RenderMentions(rawBytes []byte) []byte {
 	ms := MentionPattern.FindAll(rawBytes, -1)
 	for _, m := range ms {
 		m = m[bytes.Index(m, []byte("@")):]
 		rawBytes = bytes.ReplaceAll(rawBytes, m, []byte(fmt.Sprintf(`<a href="%s/%s">%s</a>`, conf.Server.Subpath, m[1:], m)))
 	}
+	return rawBytes
+}
 
-	rawBytes = RenderIssueIndexPattern(rawBytes, urlPrefix, metas)
-	rawBytes = RenderCrossReferenceIssueIndexPattern(rawBytes, urlPrefix, metas)
+// RenderSpecialLink renders mentions, indexes, SHA1 strings and emoji to
+// corresponding links and images. Each pass except SHA1 strings can be disabled
+// in the [markdown] section of the configuration.
+func RenderSpecialLink(rawBytes []byte, urlPrefix string, metas map[string]string) []byte {
+	if !conf.Markdown.DisableMentions {
+		rawBytes = RenderMentions(rawBytes)
+	}
+	if !conf.Markdown.DisableIssueReferences {
+		rawBytes = RenderIssueIndexPattern(rawBytes, urlPrefix, metas)
+		rawBytes = RenderCrossReferenceIssueIndexPattern(rawBytes, urlPrefix, metas)
+	}
 	rawBytes = RenderSha1CurrentPattern(rawBytes, metas["repoLink"])
+	if !conf.Markdown.DisableEmoji {
+		rawBytes = RenderEmoji(rawBytes)
+	}
 	return rawBytes
 }
 
@@ -314,10 +419,9 @@ outerLoop:
 type Type string
 
 const (
-	TypeUnrecognized    Type = "unrecognized"
-	TypeMarkdown        Type = "markdown"
-	TypeOrgMode         Type = "orgmode"
-	TypeIPythonNotebook Type = "ipynb"
+	TypeUnrecognized Type = "unrecognized"
+	TypeMarkdown     Type = "markdown"
+	TypeOrgMode      Type = "orgmode"
 )
 
 // Detect returns best guess of a markup type based on file name.
@@ -327,8 +431,6 @@ func Detect(filename string) Type {
 		return TypeMarkdown
 	case IsOrgModeFile(filename):
 		return TypeOrgMode
-	case IsIPythonNotebook(filename):
-		return TypeIPythonNotebook
 	default:
 		return TypeUnrecognized
 	}
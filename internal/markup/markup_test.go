@@ -9,6 +9,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"gogs.io/gogs/internal/conf"
 	. "gogs.io/gogs/internal/markup"
 )
 
@@ -216,6 +217,166 @@ func Test_RenderIssueIndexPattern(t *testing.T) {
 	})
 }
 
+func TestParseIssueReference(t *testing.T) {
+	tests := []struct {
+		ref       string
+		wantOwner string
+		wantRepo  string
+		wantIndex int64
+		wantOK    bool
+	}{
+		{ref: "#1", wantOwner: "alice", wantRepo: "example", wantIndex: 1, wantOK: true},
+		{ref: "docs#12", wantOwner: "alice", wantRepo: "docs", wantIndex: 12, wantOK: true},
+		{ref: "bob/tools#123", wantOwner: "bob", wantRepo: "tools", wantIndex: 123, wantOK: true},
+
+		{ref: "123"},
+		{ref: "#abc"},
+		{ref: "#0"},
+		{ref: "/tools#1"},
+		{ref: "bob/#1"},
+		{ref: "bob/tools/extra#1"},
+	}
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			owner, repo, index, ok := ParseIssueReference(test.ref, "alice", "example")
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.wantOwner, owner)
+			assert.Equal(t, test.wantRepo, repo)
+			assert.Equal(t, test.wantIndex, index)
+		})
+	}
+}
+
+func TestRenderIssueIndexPattern_issueKey(t *testing.T) {
+	urlPrefix := "/alice/example"
+	metas := map[string]string{"issueKeyPrefix": "PROJ"}
+	tests := []struct {
+		input  string
+		expVal string
+	}{
+		{input: "PROJ-1", expVal: `<a href="/alice/example/issues/1">PROJ-1</a>`},
+		{input: "fix PROJ-12 and (PROJ-3)", expVal: `fix <a href="/alice/example/issues/12">PROJ-12</a> and (<a href="/alice/example/issues/3">PROJ-3</a>)`},
+		{input: "[PROJ-4] #5", expVal: `[<a href="/alice/example/issues/4">PROJ-4</a>] <a href="/alice/example/issues/5">#5</a>`},
+
+		{input: "PROJ-0", expVal: "PROJ-0"},
+		{input: "XPROJ-1", expVal: "XPROJ-1"},
+		{input: "PROJ-1a", expVal: "PROJ-1a"},
+		{input: "OTHER-1", expVal: "OTHER-1"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expVal, string(RenderIssueIndexPattern([]byte(test.input), urlPrefix, metas)))
+		})
+	}
+
+	t.Run("no prefix", func(t *testing.T) {
+		assert.Equal(t, "PROJ-1", string(RenderIssueIndexPattern([]byte("PROJ-1"), urlPrefix, map[string]string{})))
+	})
+}
+
+func TestFindAllIssueKeys(t *testing.T) {
+	assert.Equal(t, []string{"PROJ-1", "PROJ-23"}, FindAllIssueKeys("Fix PROJ-1 and PROJ-23, not XPROJ-4", "PROJ"))
+	assert.Empty(t, FindAllIssueKeys("Fix PROJ-1", ""))
+}
+
+func TestParseIssueKey(t *testing.T) {
+	tests := []struct {
+		key       string
+		prefix    string
+		wantIndex int64
+		wantOK    bool
+	}{
+		{key: "PROJ-123", prefix: "PROJ", wantIndex: 123, wantOK: true},
+
+		{key: "PROJ-123", prefix: ""},
+		{key: "PROJ-0", prefix: "PROJ"},
+		{key: "PROJ-abc", prefix: "PROJ"},
+		{key: "OTHER-1", prefix: "PROJ"},
+		{key: "#1", prefix: "PROJ"},
+	}
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			index, ok := ParseIssueKey(test.key, test.prefix)
+			assert.Equal(t, test.wantOK, ok)
+			assert.Equal(t, test.wantIndex, index)
+		})
+	}
+}
+
+func TestRenderCrossReferenceIssueIndexPattern(t *testing.T) {
+	conf.SetMockServer(t, conf.ServerOpts{
+		ExternalURL: "http://localhost:3000/",
+	})
+
+	t.Run("without resolver", func(t *testing.T) {
+		metas := map[string]string{"user": "alice"}
+		tests := []struct {
+			input  string
+			expVal string
+		}{
+			{input: "test bob/tools#5 issue", expVal: `test <a href="http://localhost:3000/bob/tools/issues/5">bob/tools#5</a> issue`},
+			{input: "(bob/tools#5)", expVal: `(<a href="http://localhost:3000/bob/tools/issues/5">bob/tools#5</a>)`},
+			// References to the same owner are not rendered without verification
+			{input: "test docs#5 issue", expVal: "test docs#5 issue"},
+		}
+		for _, test := range tests {
+			t.Run(test.input, func(t *testing.T) {
+				assert.Equal(t, test.expVal, string(RenderCrossReferenceIssueIndexPattern([]byte(test.input), "", metas)))
+			})
+		}
+	})
+
+	t.Run("with resolver", func(t *testing.T) {
+		before := ResolveRepoLink
+		ResolveRepoLink = func(owner, name string) (string, bool) {
+			if (owner == "alice" && name == "docs") || (owner == "bob" && name == "tools") {
+				return "http://localhost:3000/" + owner + "/" + name, true
+			}
+			return "", false
+		}
+		t.Cleanup(func() {
+			ResolveRepoLink = before
+		})
+
+		tests := []struct {
+			name   string
+			metas  map[string]string
+			input  string
+			expVal string
+		}{
+			{
+				name:   "same owner",
+				metas:  map[string]string{"user": "alice"},
+				input:  "test docs#5 issue",
+				expVal: `test <a href="http://localhost:3000/alice/docs/issues/5">docs#5</a> issue`,
+			},
+			{
+				name:   "cross owner",
+				metas:  map[string]string{"user": "alice"},
+				input:  "test bob/tools#5 and docs#6",
+				expVal: `test <a href="http://localhost:3000/bob/tools/issues/5">bob/tools#5</a> and <a href="http://localhost:3000/alice/docs/issues/6">docs#6</a>`,
+			},
+			{
+				name:   "unresolvable",
+				metas:  map[string]string{"user": "alice"},
+				input:  "test bob/secret#5 and tools#6",
+				expVal: "test bob/secret#5 and tools#6",
+			},
+			{
+				name:   "no owner",
+				metas:  nil,
+				input:  "test docs#5 issue",
+				expVal: "test docs#5 issue",
+			},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				assert.Equal(t, test.expVal, string(RenderCrossReferenceIssueIndexPattern([]byte(test.input), "", test.metas)))
+			})
+		}
+	})
+}
+
 func TestRenderSha1CurrentPattern(t *testing.T) {
 	metas := map[string]string{
 		"repoLink": "/someuser/somerepo",
@@ -253,3 +414,46 @@ func TestRenderSha1CurrentPattern(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderSpecialLink(t *testing.T) {
+	conf.SetMockServer(t, conf.ServerOpts{Subpath: "/gogs", SubpathDepth: 1})
+	metas := map[string]string{"repoLink": "/gogs/alice/repo"}
+	input := "@bob fixed #1 in d8a994ef243349f321568f9e36d5c3f444b99cae :smile:"
+
+	mention := `<a href="/gogs/bob">@bob</a>`
+	issue := `<a href="/gogs/alice/repo/issues/1">#1</a>`
+	sha1 := `<a href="/gogs/alice/repo/commit/d8a994ef243349f321568f9e36d5c3f444b99cae"><code>d8a994ef24</code></a>`
+	emoji := `<img class="emoji" src="/gogs/img/emoji/smile.png" alt=":smile:" title=":smile:">`
+
+	tests := []struct {
+		name string
+		opts conf.MarkdownOpts
+		want string
+	}{
+		{
+			name: "all enabled",
+			want: mention + " fixed " + issue + " in " + sha1 + " " + emoji,
+		},
+		{
+			name: "mentions disabled",
+			opts: conf.MarkdownOpts{DisableMentions: true},
+			want: "@bob fixed " + issue + " in " + sha1 + " " + emoji,
+		},
+		{
+			name: "issue references disabled",
+			opts: conf.MarkdownOpts{DisableIssueReferences: true},
+			want: mention + " fixed #1 in " + sha1 + " " + emoji,
+		},
+		{
+			name: "emoji disabled",
+			opts: conf.MarkdownOpts{DisableEmoji: true},
+			want: mention + " fixed " + issue + " in " + sha1 + " :smile:",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.SetMockMarkdown(t, test.opts)
+			assert.Equal(t, test.want, string(RenderSpecialLink([]byte(input), "/gogs/alice/repo", metas)))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+// notebookText is a multiline string in a Jupyter notebook, which is either a
+// string or a list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := jsoniter.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+
+	var s string
+	if err := jsoniter.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+type notebookOutput struct {
+	OutputType string                  `json:"output_type"`
+	Name       string                  `json:"name"`
+	Text       notebookText            `json:"text"`
+	Data       map[string]notebookText `json:"data"`
+	EName      string                  `json:"ename"`
+	EValue     string                  `json:"evalue"`
+	Traceback  []string                `json:"traceback"`
+}
+
+type notebookCell struct {
+	CellType       string           `json:"cell_type"`
+	Source         notebookText     `json:"source"`
+	ExecutionCount *int             `json:"execution_count"`
+	Outputs        []notebookOutput `json:"outputs"`
+}
+
+// notebook is the subset of the Jupyter notebook format (nbformat 4) that is
+// needed for rendering.
+//
+// See https://nbformat.readthedocs.io/en/latest/format_description.html.
+type notebook struct {
+	NBFormat int            `json:"nbformat"`
+	Cells    []notebookCell `json:"cells"`
+	Metadata struct {
+		KernelSpec struct {
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// language returns the programming language of code cells in the notebook.
+func (nb *notebook) language() string {
+	if nb.Metadata.LanguageInfo.Name != "" {
+		return nb.Metadata.LanguageInfo.Name
+	}
+	if nb.Metadata.KernelSpec.Language != "" {
+		return nb.Metadata.KernelSpec.Language
+	}
+	return "python"
+}
+
+// notebookMarkdownPolicy sanitizes rendered markdown cells, which are the only
+// HTML from the notebook. Everything else is escaped when rendering.
+var notebookMarkdownPolicy = &lazyPolicy{
+	build: func() *bluemonday.Policy {
+		p := bluemonday.UGCPolicy()
+		p.AllowAttrs("class").Matching(lazyregexp.New(`^language-[\w+-]+$`).Regexp()).OnElements("code")
+		return p
+	},
+}
+
+// notebookImagePattern matches base64-encoded image data of outputs.
+var notebookImagePattern = lazyregexp.New(`^[A-Za-z0-9+/]+={0,2}$`)
+
+// ansiEscapePattern matches ANSI escape sequences used for coloring tracebacks.
+var ansiEscapePattern = lazyregexp.New(`\x1b\[[0-9;]*[A-Za-z]`)
+
+type notebookRenderer struct{}
+
+func (*notebookRenderer) Extensions() []string {
+	return []string{".ipynb"}
+}
+
+func (*notebookRenderer) Render(input []byte, urlPrefix string) ([]byte, error) {
+	var nb notebook
+	if err := jsoniter.Unmarshal(input, &nb); err != nil {
+		return nil, errors.Wrap(err, "parse notebook")
+	} else if nb.NBFormat != 4 {
+		return nil, errors.Errorf("unsupported notebook format version %d", nb.NBFormat)
+	}
+
+	language := html.EscapeString(nb.language())
+
+	var buf bytes.Buffer
+	buf.WriteString(`<div class="nb-notebook">`)
+	for _, cell := range nb.Cells {
+		switch cell.CellType {
+		case "markdown":
+			buf.WriteString(`<div class="nb-cell nb-markdown-cell">`)
+			buf.Write(notebookMarkdownPolicy.SanitizeBytes(RawMarkdown([]byte(cell.Source), urlPrefix)))
+			buf.WriteString(`</div>`)
+
+		case "code":
+			prompt := ""
+			if cell.ExecutionCount != nil {
+				prompt = fmt.Sprintf("%d", *cell.ExecutionCount)
+			}
+
+			buf.WriteString(`<div class="nb-cell nb-code-cell">`)
+			fmt.Fprintf(&buf, `<div class="nb-input" data-prompt-number="%s"><pre><code class="language-%s">%s</code></pre></div>`,
+				prompt, language, html.EscapeString(string(cell.Source)))
+			for _, output := range cell.Outputs {
+				fmt.Fprintf(&buf, `<div class="nb-output" data-prompt-number="%s">`, prompt)
+				renderNotebookOutput(&buf, output)
+				buf.WriteString(`</div>`)
+			}
+			buf.WriteString(`</div>`)
+
+		default:
+			buf.WriteString(`<div class="nb-cell nb-raw-cell"><pre>`)
+			buf.WriteString(html.EscapeString(string(cell.Source)))
+			buf.WriteString(`</pre></div>`)
+		}
+	}
+	buf.WriteString(`</div>`)
+	return buf.Bytes(), nil
+}
+
+// renderNotebookOutput renders the text or image output of a code cell. Other
+// types of output (e.g. HTML and JavaScript) are not rendered for security
+// reasons.
+func renderNotebookOutput(buf *bytes.Buffer, output notebookOutput) {
+	switch output.OutputType {
+	case "stream":
+		class := "nb-stdout"
+		if output.Name == "stderr" {
+			class = "nb-stderr"
+		}
+		fmt.Fprintf(buf, `<pre class="%s">%s</pre>`, class, html.EscapeString(string(output.Text)))
+
+	case "execute_result", "display_data":
+		for _, mime := range []string{"image/png", "image/jpeg"} {
+			// Image data is written as-is, thus it must be valid base64.
+			data := strings.Join(strings.Fields(string(output.Data[mime])), "")
+			if notebookImagePattern.MatchString(data) {
+				fmt.Fprintf(buf, `<img src="data:%s;base64,%s">`, mime, data)
+				return
+			}
+		}
+		if text, ok := output.Data["text/plain"]; ok {
+			fmt.Fprintf(buf, `<pre class="nb-text-output">%s</pre>`, html.EscapeString(string(text)))
+		}
+
+	case "error":
+		traceback := ansiEscapePattern.ReplaceAllString(strings.Join(output.Traceback, "\n"), "")
+		if traceback == "" {
+			traceback = output.EName + ": " + output.EValue
+		}
+		fmt.Fprintf(buf, `<pre class="nb-stderr">%s</pre>`, html.EscapeString(traceback))
+	}
+}
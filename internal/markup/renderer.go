@@ -0,0 +1,75 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// FileRenderer renders content of files in rich formats (e.g. Jupyter
+// notebooks) to HTML for viewing.
+type FileRenderer interface {
+	// Extensions returns the file extensions handled by the renderer, including
+	// the leading dot, e.g. ".ipynb".
+	Extensions() []string
+	// Render renders the content of the file to HTML. The urlPrefix is the link
+	// to the directory that contains the file for resolving relative links.
+	//
+	// 🚨 SECURITY: The returned HTML is embedded into the page as-is, thus it must
+	// be sanitized by the renderer.
+	Render(input []byte, urlPrefix string) ([]byte, error)
+}
+
+var fileRenderers struct {
+	sync.RWMutex
+	byExtension map[string]FileRenderer
+}
+
+// RegisterFileRenderer registers the renderer for all of its file extensions,
+// replacing any renderer previously registered for the same extension.
+func RegisterFileRenderer(r FileRenderer) {
+	fileRenderers.Lock()
+	defer fileRenderers.Unlock()
+
+	if fileRenderers.byExtension == nil {
+		fileRenderers.byExtension = make(map[string]FileRenderer)
+	}
+	for _, ext := range r.Extensions() {
+		fileRenderers.byExtension[strings.ToLower(ext)] = r
+	}
+}
+
+// FileRendererFor returns the renderer registered for the extension of given
+// file name, or nil if there is none.
+func FileRendererFor(filename string) FileRenderer {
+	fileRenderers.RLock()
+	defer fileRenderers.RUnlock()
+	return fileRenderers.byExtension[strings.ToLower(filepath.Ext(filename))]
+}
+
+// lazyPolicy is a sanitizer policy that is built on first use.
+type lazyPolicy struct {
+	build  func() *bluemonday.Policy
+	policy *bluemonday.Policy
+	once   sync.Once
+}
+
+// SanitizeBytes applies the policy whitelist to the HTML fragment.
+func (p *lazyPolicy) SanitizeBytes(b []byte) []byte {
+	p.once.Do(func() {
+		p.policy = p.build()
+	})
+	return p.policy.SanitizeBytes(b)
+}
+
+func init() {
+	RegisterFileRenderer(&notebookRenderer{})
+	RegisterFileRenderer(&csvRenderer{})
+	RegisterFileRenderer(&svgRenderer{})
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "gogs.io/gogs/internal/markup"
+)
+
+func TestFileRendererFor(t *testing.T) {
+	for _, name := range []string{"example.ipynb", "Example.IPYNB", "data.csv", "data.tsv", "logo.svg"} {
+		assert.NotNil(t, FileRendererFor(name), name)
+	}
+	for _, name := range []string{"main.go", "README.md", "ipynb"} {
+		assert.Nil(t, FileRendererFor(name), name)
+	}
+}
+
+func TestNotebookRenderer(t *testing.T) {
+	input := `{
+  "nbformat": 4,
+  "nbformat_minor": 5,
+  "metadata": {"language_info": {"name": "python"}},
+  "cells": [
+    {"cell_type": "markdown", "metadata": {}, "source": ["# Analysis\n", "Some **bold** text <script>alert(1)</script>"]},
+    {
+      "cell_type": "code",
+      "execution_count": 1,
+      "metadata": {},
+      "source": "print(1 < 2)",
+      "outputs": [
+        {"output_type": "stream", "name": "stdout", "text": ["True\n"]},
+        {"output_type": "execute_result", "execution_count": 1, "metadata": {}, "data": {"text/plain": ["<b>42</b>"], "text/html": ["<script>alert(1)</script>"]}},
+        {"output_type": "display_data", "metadata": {}, "data": {"image/png": "iVBORw0K\nGgo=\n"}},
+        {"output_type": "display_data", "metadata": {}, "data": {"image/png": "\"><script>alert(1)</script>", "text/plain": ["<Figure>"]}}
+      ]
+    }
+  ]
+}`
+	got, err := FileRendererFor("example.ipynb").Render([]byte(input), "/alice/example/src/main")
+	require.NoError(t, err)
+
+	html := string(got)
+	assert.Contains(t, html, `<div class="nb-cell nb-markdown-cell">`)
+	assert.Contains(t, html, `<h1>Analysis</h1>`)
+	assert.Contains(t, html, `<strong>bold</strong>`)
+	assert.Contains(t, html, `<div class="nb-input" data-prompt-number="1"><pre><code class="language-python">print(1 &lt; 2)</code></pre></div>`)
+	assert.Contains(t, html, `<pre class="nb-stdout">True`)
+	assert.Contains(t, html, `<pre class="nb-text-output">&lt;b&gt;42&lt;/b&gt;</pre>`)
+	assert.Contains(t, html, `<img src="data:image/png;base64,iVBORw0KGgo=">`)
+	assert.Contains(t, html, `<pre class="nb-text-output">&lt;Figure&gt;</pre>`)
+	assert.NotContains(t, html, "<script>")
+
+	t.Run("unsupported format", func(t *testing.T) {
+		_, err := FileRendererFor("example.ipynb").Render([]byte(`{"nbformat": 3, "worksheets": []}`), "")
+		assert.Error(t, err)
+	})
+}
+
+func TestCSVRenderer(t *testing.T) {
+	got, err := FileRendererFor("data.csv").Render([]byte("name,age\nalice,30\n<b>bob</b>,\"2,5\"\n"), "")
+	require.NoError(t, err)
+	want := `<table class="ui celled compact table">` +
+		`<thead><tr><th>name</th><th>age</th></tr></thead>` +
+		`<tbody><tr><td>alice</td><td>30</td></tr><tr><td>&lt;b&gt;bob&lt;/b&gt;</td><td>2,5</td></tr></tbody>` +
+		`</table>`
+	assert.Equal(t, want, string(got))
+
+	got, err = FileRendererFor("data.tsv").Render([]byte("name\tage\nalice\t30\n"), "")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `<th>name</th><th>age</th>`)
+}
+
+func TestSVGRenderer(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10" onload="alert(1)">
+	<script>alert(1)</script>
+	<g id="repo-readme"><use href="#repo-readme"/></g>
+	<a href="javascript:alert(1)"><rect width="10" height="10" fill="url(https://example.com/track)"/></a>
+	<circle cx="5" cy="5" r="4" fill="#ff0000" onclick="alert(1)"/>
+	<foreignObject><div>html</div></foreignObject>
+</svg>`
+	got, err := FileRendererFor("logo.svg").Render([]byte(input), "")
+	require.NoError(t, err)
+
+	html := string(got)
+	assert.Contains(t, html, `<svg xmlns="http://www.w3.org/2000/svg" viewbox="0 0 10 10">`)
+	assert.Contains(t, html, `<rect width="10" height="10"/>`)
+	assert.Contains(t, html, `<circle cx="5" cy="5" r="4" fill="#ff0000"/>`)
+	assert.NotContains(t, html, "alert")
+	assert.NotContains(t, html, "example.com")
+	assert.NotContains(t, html, "foreignobject")
+	assert.NotContains(t, html, "repo-readme")
+}
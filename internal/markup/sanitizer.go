@@ -5,6 +5,7 @@
 package markup
 
 import (
+	"net/url"
 	"sync"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -32,18 +33,30 @@ func NewSanitizer() {
 		// We only want to allow HighlightJS specific classes for code blocks
 		sanitizer.policy.AllowAttrs("class").Matching(lazyregexp.New(`^language-\w+$`).Regexp()).OnElements("code")
 
+		// Emoji
+		sanitizer.policy.AllowAttrs("class").Matching(lazyregexp.New(`^emoji$`).Regexp()).OnElements("img")
+		sanitizer.policy.AllowAttrs("alt", "title").Matching(lazyregexp.New(`^:[0-9a-zA-Z_+-]+:$`).Regexp()).OnElements("img")
+
 		// Checkboxes
 		sanitizer.policy.AllowAttrs("type").Matching(lazyregexp.New(`^checkbox$`).Regexp()).OnElements("input")
 		sanitizer.policy.AllowAttrs("checked", "disabled").OnElements("input")
 
-		// Data URLs
-		sanitizer.policy.AllowURLSchemes("data")
+		// Data URLs, only for PNG and JPEG images that cannot run scripts
+		sanitizer.policy.AllowURLSchemeWithCustomPolicy("data", isDataImageURL)
 
 		// Custom URL-Schemes
 		sanitizer.policy.AllowURLSchemes(conf.Markdown.CustomURLSchemes...)
 	})
 }
 
+var dataImageURLPattern = lazyregexp.New(`^image/(png|jpeg);base64,[A-Za-z0-9+/]+={0,2}$`)
+
+// isDataImageURL returns true if the URL is a data URL of a base64-encoded PNG
+// or JPEG image.
+func isDataImageURL(u *url.URL) bool {
+	return u.RawQuery == "" && u.Fragment == "" && dataImageURLPattern.MatchString(u.Opaque)
+}
+
 // Sanitize takes a string that contains a HTML fragment or document and applies policy whitelist.
 func Sanitize(s string) string {
 	return sanitizer.policy.Sanitize(s)
@@ -26,6 +26,16 @@ func Test_Sanitizer(t *testing.T) {
 		{input: `<code class="language-random ui tab active menu attached animating sidebar following bar center"></code>`, expVal: `<code></code>`},
 		{input: `<code class="language-go"></code>`, expVal: `<code class="language-go"></code>`},
 
+		// Emoji
+		{input: `<img class="emoji" src="/img/emoji/smile.png" alt=":smile:" title=":smile:">`, expVal: `<img class="emoji" src="/img/emoji/smile.png" alt=":smile:" title=":smile:">`},
+		{input: `<img class="emoji large" src="/img/emoji/smile.png">`, expVal: `<img src="/img/emoji/smile.png">`},
+		{input: `<img src="/avatar.png" alt="An avatar">`, expVal: `<img src="/avatar.png" alt="An avatar">`},
+
+		// Data URLs
+		{input: `<img src="data:image/png;base64,iVBORw0KGgo=">`, expVal: `<img src="data:image/png;base64,iVBORw0KGgo=">`},
+		{input: `<img src="data:image/svg+xml;base64,PHN2Zz4=">`, expVal: ``},
+		{input: `<a href="data:text/html;base64,PHNjcmlwdD4=">Click</a>`, expVal: `Click`},
+
 		// Input checkbox
 		{input: `<input type="hidden">`, expVal: ``},
 		{input: `<input type="checkbox">`, expVal: `<input type="checkbox">`},
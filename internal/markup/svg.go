@@ -0,0 +1,59 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"gogs.io/gogs/internal/lazyregexp"
+)
+
+// svgPolicy only allows static SVG elements and presentation attributes,
+// everything that could run scripts (e.g. <script>, event handlers,
+// <foreignObject>) or load external resources (e.g. <image>, stylesheets) is
+// removed. IDs are removed as well so that they cannot clobber elements of the
+// page, thus references to fragments (e.g. gradients) are not supported.
+var svgPolicy = &lazyPolicy{
+	build: func() *bluemonday.Policy {
+		p := bluemonday.NewPolicy()
+		p.AllowElements(
+			"svg", "g", "title", "desc",
+			"path", "rect", "circle", "ellipse", "line", "polyline", "polygon",
+			"text", "tspan",
+		)
+		p.AllowAttrs(
+			"class", "x", "y", "x1", "y1", "x2", "y2", "cx", "cy", "r", "rx", "ry",
+			"width", "height", "viewbox", "preserveaspectratio", "version", "xmlns",
+			"d", "points", "transform", "pathlength",
+			"fill-opacity", "fill-rule", "stroke-width", "stroke-opacity",
+			"stroke-linecap", "stroke-linejoin", "stroke-dasharray", "stroke-dashoffset", "stroke-miterlimit",
+			"opacity", "color", "display", "visibility", "clip-rule",
+			"font-family", "font-size", "font-weight", "font-style", "text-anchor", "dominant-baseline",
+			"dx", "dy", "rotate", "letter-spacing",
+		).Globally()
+
+		paint := lazyregexp.New(`^(#[0-9a-fA-F]{3,8}|[a-zA-Z]+|rgba?\([\d\s.,%]+\))$`).Regexp()
+		p.AllowAttrs("fill", "stroke").Matching(paint).Globally()
+		return p
+	},
+}
+
+type svgRenderer struct{}
+
+func (*svgRenderer) Extensions() []string {
+	return []string{".svg"}
+}
+
+// Render renders the SVG content to HTML for embedding inline, with everything
+// other than static graphics removed.
+func (*svgRenderer) Render(input []byte, _ string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`<div class="view-raw ui center">`)
+	buf.Write(svgPolicy.SanitizeBytes(input))
+	buf.WriteString(`</div>`)
+	return buf.Bytes(), nil
+}
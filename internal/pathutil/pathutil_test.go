@@ -82,3 +82,36 @@ func TestClean(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "docs/**", name: "docs/README.md", want: true},
+		{pattern: "docs/**", name: "docs/dev/local.md", want: true},
+		{pattern: "docs/**", name: "README.md", want: false},
+		{pattern: "*.md", name: "README.md", want: true},
+		{pattern: "*.md", name: "docs/README.md", want: false},
+		{pattern: "**/*.md", name: "README.md", want: true},
+		{pattern: "**/*.md", name: "docs/dev/README.md", want: true},
+		{pattern: "internal/**/*_test.go", name: "internal/db/repo_test.go", want: true},
+		{pattern: "internal/**/*_test.go", name: "internal/db/repo.go", want: false},
+		{pattern: "conf/app.?ni", name: "conf/app.ini", want: true},
+		{pattern: "[abc].go", name: "b.go", want: true},
+		{pattern: "[!abc].go", name: "b.go", want: false},
+		{pattern: "a+b.txt", name: "a+b.txt", want: true},
+		{pattern: "a+b.txt", name: "aab.txt", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern+" "+test.name, func(t *testing.T) {
+			got, err := MatchGlob(test.pattern, test.name)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	_, err := MatchGlob("[abc", "a")
+	assert.Error(t, err)
+}
@@ -5,10 +5,18 @@
 package repoutil
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net"
+	"net/url"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"gogs.io/gogs/internal/conf"
 )
@@ -17,6 +25,9 @@ import (
 type CloneLink struct {
 	SSH   string
 	HTTPS string
+	// AnonymousHTTPS is the HTTPS clone URL for users who are not signed in, which
+	// is the same as HTTPS unless configured otherwise.
+	AnonymousHTTPS string
 }
 
 // NewCloneLink returns clone URLs using given owner and repository name.
@@ -25,19 +36,64 @@ func NewCloneLink(owner, repo string, isWiki bool) *CloneLink {
 		repo += ".wiki"
 	}
 
-	cl := new(CloneLink)
+	return &CloneLink{
+		SSH:            SSHCloneURL(owner, repo),
+		HTTPS:          HTTPSCloneURL(owner, repo),
+		AnonymousHTTPS: AnonymousHTTPSCloneURL(owner, repo),
+	}
+}
+
+// SSHCloneURL returns SSH clone URL using given owner and repository name.
+func SSHCloneURL(owner, repo string) string {
+	opts := conf.Repository.CloneURL
+	if opts.SSHBaseURL != "" {
+		return fmt.Sprintf("%s%s/%s.git", opts.SSHBaseURL, owner, repo)
+	}
+
+	domain := conf.SSH.Domain
+	if opts.GitHost != "" {
+		domain = opts.GitHost
+		// The port of the public Git host, if any, is only meant for HTTP.
+		if host, _, err := net.SplitHostPort(opts.GitHost); err == nil {
+			domain = host
+		}
+	}
 	if conf.SSH.Port != 22 {
-		cl.SSH = fmt.Sprintf("ssh://%s@%s:%d/%s/%s.git", conf.App.RunUser, conf.SSH.Domain, conf.SSH.Port, owner, repo)
-	} else {
-		cl.SSH = fmt.Sprintf("%s@%s:%s/%s.git", conf.App.RunUser, conf.SSH.Domain, owner, repo)
+		return fmt.Sprintf("ssh://%s@%s:%d/%s/%s.git", conf.App.RunUser, domain, conf.SSH.Port, owner, repo)
+	}
+	return fmt.Sprintf("%s@%s:%s/%s.git", conf.App.RunUser, domain, owner, repo)
+}
+
+// httpCloneBaseURL returns the base URL of HTTPS clone URLs, which is the
+// external URL unless overridden.
+func httpCloneBaseURL() string {
+	opts := conf.Repository.CloneURL
+	if opts.HTTPBaseURL != "" {
+		return opts.HTTPBaseURL
+	}
+
+	if opts.GitHost != "" {
+		u, err := url.Parse(conf.Server.ExternalURL)
+		if err == nil {
+			u.Host = opts.GitHost
+			return u.String()
+		}
 	}
-	cl.HTTPS = HTTPSCloneURL(owner, repo)
-	return cl
+	return conf.Server.ExternalURL
 }
 
 // HTTPSCloneURL returns HTTPS clone URL using given owner and repository name.
 func HTTPSCloneURL(owner, repo string) string {
-	return fmt.Sprintf("%s%s/%s.git", conf.Server.ExternalURL, owner, repo)
+	return fmt.Sprintf("%s%s/%s.git", httpCloneBaseURL(), owner, repo)
+}
+
+// AnonymousHTTPSCloneURL returns HTTPS clone URL for users who are not signed
+// in using given owner and repository name.
+func AnonymousHTTPSCloneURL(owner, repo string) string {
+	if baseURL := conf.Repository.CloneURL.AnonymousHTTPBaseURL; baseURL != "" {
+		return fmt.Sprintf("%s%s/%s.git", baseURL, owner, repo)
+	}
+	return HTTPSCloneURL(owner, repo)
 }
 
 // HTMLURL returns HTML URL using given owner and repository name.
@@ -73,3 +129,102 @@ func RepositoryLocalPath(repoID int64) string {
 func RepositoryLocalWikiPath(repoID int64) string {
 	return filepath.Join(conf.Server.AppDataPath, "tmp", "local-wiki", strconv.FormatInt(repoID, 10))
 }
+
+var (
+	ErrDeletionTokenInvalid = errors.New("invalid deletion token")
+	ErrDeletionTokenExpired = errors.New("deletion token has expired")
+)
+
+func deletionTokenSignature(repoID, userID int64, expires string) string {
+	mac := hmac.New(sha256.New, []byte(conf.Security.SecretKey))
+	_, _ = fmt.Fprintf(mac, "%d:%d:%s", repoID, userID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewDeletionToken returns a token that authorizes the user to delete the
+// repository with the given ID until the given time.
+func NewDeletionToken(repoID, userID int64, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expires + "." + deletionTokenSignature(repoID, userID, expires)
+}
+
+// VerifyDeletionToken verifies the token is generated by NewDeletionToken for
+// the same repository and user, and has not expired by the given time.
+func VerifyDeletionToken(token string, repoID, userID int64, now time.Time) error {
+	valid, expired := verifySignedToken(token, now, func(expires string) string {
+		return deletionTokenSignature(repoID, userID, expires)
+	})
+	if !valid {
+		return ErrDeletionTokenInvalid
+	} else if expired {
+		return ErrDeletionTokenExpired
+	}
+	return nil
+}
+
+// verifySignedToken verifies the token in the form of "<expires>.<signature>"
+// has the signature computed by the sign function, and reports whether it has
+// expired by the given time.
+func verifySignedToken(token string, now time.Time, sign func(expires string) string) (valid, expired bool) {
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false, false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return false, false
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(sign(expires))) {
+		return false, false
+	}
+	return true, now.Unix() >= expiresAt
+}
+
+var (
+	ErrSecretsTokenInvalid = errors.New("invalid secrets token")
+	ErrSecretsTokenExpired = errors.New("secrets token has expired")
+)
+
+func secretsTokenSignature(repoID int64, expires string) string {
+	mac := hmac.New(sha256.New, []byte(conf.Security.SecretKey))
+	_, _ = fmt.Fprintf(mac, "secrets:%d:%s", repoID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecretsToken returns a token that authorizes a CI context to retrieve
+// values of secrets available to the repository with the given ID until the
+// given time.
+func NewSecretsToken(repoID int64, expiresAt time.Time) string {
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	return expires + "." + secretsTokenSignature(repoID, expires)
+}
+
+// VerifySecretsToken verifies the token is generated by NewSecretsToken for the
+// same repository, and has not expired by the given time.
+func VerifySecretsToken(token string, repoID int64, now time.Time) error {
+	valid, expired := verifySignedToken(token, now, func(expires string) string {
+		return secretsTokenSignature(repoID, expires)
+	})
+	if !valid {
+		return ErrSecretsTokenInvalid
+	} else if expired {
+		return ErrSecretsTokenExpired
+	}
+	return nil
+}
+
+// NewUnsubscribeToken returns a token that authorizes the email address to
+// unsubscribe from notifications of the repository with the given ID. The token
+// never expires, so links in sent emails remain valid.
+func NewUnsubscribeToken(repoID int64, email string) string {
+	mac := hmac.New(sha256.New, []byte(conf.Security.SecretKey))
+	_, _ = fmt.Fprintf(mac, "unsubscribe:%d:%s", repoID, strings.ToLower(email))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyUnsubscribeToken returns true if the token is generated by
+// NewUnsubscribeToken for the same repository and email address.
+func VerifyUnsubscribeToken(token string, repoID int64, email string) bool {
+	return hmac.Equal([]byte(token), []byte(NewUnsubscribeToken(repoID, email)))
+}
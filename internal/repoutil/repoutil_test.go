@@ -6,7 +6,10 @@ package repoutil
 
 import (
 	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -35,8 +38,9 @@ func TestNewCloneLink(t *testing.T) {
 
 		got := NewCloneLink("alice", "example", false)
 		want := &CloneLink{
-			SSH:   "git@example.com:alice/example.git",
-			HTTPS: "https://example.com/alice/example.git",
+			SSH:            "git@example.com:alice/example.git",
+			HTTPS:          "https://example.com/alice/example.git",
+			AnonymousHTTPS: "https://example.com/alice/example.git",
 		}
 		assert.Equal(t, want, got)
 	})
@@ -51,8 +55,9 @@ func TestNewCloneLink(t *testing.T) {
 
 		got := NewCloneLink("alice", "example", false)
 		want := &CloneLink{
-			SSH:   "ssh://git@example.com:2222/alice/example.git",
-			HTTPS: "https://example.com/alice/example.git",
+			SSH:            "ssh://git@example.com:2222/alice/example.git",
+			HTTPS:          "https://example.com/alice/example.git",
+			AnonymousHTTPS: "https://example.com/alice/example.git",
 		}
 		assert.Equal(t, want, got)
 	})
@@ -67,11 +72,82 @@ func TestNewCloneLink(t *testing.T) {
 
 		got := NewCloneLink("alice", "example", true)
 		want := &CloneLink{
-			SSH:   "git@example.com:alice/example.wiki.git",
-			HTTPS: "https://example.com/alice/example.wiki.git",
+			SSH:            "git@example.com:alice/example.wiki.git",
+			HTTPS:          "https://example.com/alice/example.wiki.git",
+			AnonymousHTTPS: "https://example.com/alice/example.wiki.git",
 		}
 		assert.Equal(t, want, got)
 	})
+
+	t.Run("public Git host", func(t *testing.T) {
+		conf.SetMockSSH(t,
+			conf.SSHOpts{
+				Domain: "example.com",
+				Port:   22,
+			},
+		)
+		conf.SetMockRepository(t, newCloneURLRepositoryOpts("git.example.com:8443", "", "", ""))
+
+		got := NewCloneLink("alice", "example", false)
+		want := &CloneLink{
+			SSH:            "git@git.example.com:alice/example.git",
+			HTTPS:          "https://git.example.com:8443/alice/example.git",
+			AnonymousHTTPS: "https://git.example.com:8443/alice/example.git",
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("overridden base URLs", func(t *testing.T) {
+		conf.SetMockSSH(t,
+			conf.SSHOpts{
+				Domain: "example.com",
+				Port:   22,
+			},
+		)
+		conf.SetMockRepository(t,
+			newCloneURLRepositoryOpts(
+				"git.example.com",
+				"https://proxy.example.com/git/",
+				"ssh://git@ssh.example.com:2222/",
+				"https://cdn.example.com/",
+			),
+		)
+
+		got := NewCloneLink("alice", "example", false)
+		want := &CloneLink{
+			SSH:            "ssh://git@ssh.example.com:2222/alice/example.git",
+			HTTPS:          "https://proxy.example.com/git/alice/example.git",
+			AnonymousHTTPS: "https://cdn.example.com/alice/example.git",
+		}
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("anonymous base URL only", func(t *testing.T) {
+		conf.SetMockSSH(t,
+			conf.SSHOpts{
+				Domain: "example.com",
+				Port:   2222,
+			},
+		)
+		conf.SetMockRepository(t, newCloneURLRepositoryOpts("", "", "", "https://cdn.example.com/"))
+
+		got := NewCloneLink("alice", "example", true)
+		want := &CloneLink{
+			SSH:            "ssh://git@example.com:2222/alice/example.wiki.git",
+			HTTPS:          "https://example.com/alice/example.wiki.git",
+			AnonymousHTTPS: "https://cdn.example.com/alice/example.wiki.git",
+		}
+		assert.Equal(t, want, got)
+	})
+}
+
+func newCloneURLRepositoryOpts(gitHost, httpBaseURL, sshBaseURL, anonymousHTTPBaseURL string) conf.RepositoryOpts {
+	var opts conf.RepositoryOpts
+	opts.CloneURL.GitHost = gitHost
+	opts.CloneURL.HTTPBaseURL = httpBaseURL
+	opts.CloneURL.SSHBaseURL = sshBaseURL
+	opts.CloneURL.AnonymousHTTPBaseURL = anonymousHTTPBaseURL
+	return opts
 }
 
 func TestHTMLURL(t *testing.T) {
@@ -161,3 +237,62 @@ func TestRepositoryLocalWikiPath(t *testing.T) {
 	want := "data/tmp/local-wiki/1"
 	assert.Equal(t, want, got)
 }
+
+func TestVerifyDeletionToken(t *testing.T) {
+	now := time.Now()
+	token := NewDeletionToken(1, 2, now.Add(5*time.Minute))
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, VerifyDeletionToken(token, 1, 2, now))
+	})
+
+	t.Run("required", func(t *testing.T) {
+		assert.Equal(t, ErrDeletionTokenInvalid, VerifyDeletionToken("", 1, 2, now))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		assert.Equal(t, ErrDeletionTokenExpired, VerifyDeletionToken(token, 1, 2, now.Add(5*time.Minute)))
+	})
+
+	t.Run("another repository or user", func(t *testing.T) {
+		assert.Equal(t, ErrDeletionTokenInvalid, VerifyDeletionToken(token, 2, 2, now))
+		assert.Equal(t, ErrDeletionTokenInvalid, VerifyDeletionToken(token, 1, 3, now))
+	})
+
+	t.Run("tampered expiry", func(t *testing.T) {
+		_, signature, _ := strings.Cut(token, ".")
+		tampered := strconv.FormatInt(now.Add(time.Hour).Unix(), 10) + "." + signature
+		assert.Equal(t, ErrDeletionTokenInvalid, VerifyDeletionToken(tampered, 1, 2, now.Add(10*time.Minute)))
+	})
+}
+
+func TestVerifySecretsToken(t *testing.T) {
+	now := time.Now()
+	token := NewSecretsToken(1, now.Add(time.Hour))
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, VerifySecretsToken(token, 1, now))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		assert.Equal(t, ErrSecretsTokenExpired, VerifySecretsToken(token, 1, now.Add(time.Hour)))
+	})
+
+	t.Run("another repository", func(t *testing.T) {
+		assert.Equal(t, ErrSecretsTokenInvalid, VerifySecretsToken(token, 2, now))
+	})
+
+	t.Run("deletion token", func(t *testing.T) {
+		deletionToken := NewDeletionToken(1, 2, now.Add(time.Hour))
+		assert.Equal(t, ErrSecretsTokenInvalid, VerifySecretsToken(deletionToken, 1, now))
+	})
+}
+
+func TestVerifyUnsubscribeToken(t *testing.T) {
+	token := NewUnsubscribeToken(1, "list@example.com")
+	assert.True(t, VerifyUnsubscribeToken(token, 1, "list@example.com"))
+	assert.True(t, VerifyUnsubscribeToken(token, 1, "List@Example.com"))
+	assert.False(t, VerifyUnsubscribeToken(token, 2, "list@example.com"))
+	assert.False(t, VerifyUnsubscribeToken(token, 1, "other@example.com"))
+	assert.False(t, VerifyUnsubscribeToken("", 1, "list@example.com"))
+}
@@ -137,6 +137,8 @@ const (
 	SyncSSHAuthorizedKey
 	SyncRepositoryHooks
 	ReinitMissingRepository
+	ReconcileIssueCounts
+	ClearLoginLockouts
 )
 
 func Operation(c *context.Context) {
@@ -164,6 +166,12 @@ func Operation(c *context.Context) {
 	case ReinitMissingRepository:
 		success = c.Tr("admin.dashboard.reinit_missing_repos_success")
 		err = db.ReinitMissingRepositories()
+	case ReconcileIssueCounts:
+		success = c.Tr("admin.dashboard.reconcile_issue_counts_success")
+		err = db.ReconcileRepoIssueCounts()
+	case ClearLoginLockouts:
+		success = c.Tr("admin.dashboard.clear_login_lockouts_success")
+		db.ClearAllLoginLockouts()
 	}
 
 	if err != nil {
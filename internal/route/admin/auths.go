@@ -15,6 +15,7 @@ import (
 	"gogs.io/gogs/internal/auth"
 	"gogs.io/gogs/internal/auth/github"
 	"gogs.io/gogs/internal/auth/ldap"
+	"gogs.io/gogs/internal/auth/oidc"
 	"gogs.io/gogs/internal/auth/pam"
 	"gogs.io/gogs/internal/auth/smtp"
 	"gogs.io/gogs/internal/conf"
@@ -57,6 +58,7 @@ var (
 		{auth.Name(auth.SMTP), auth.SMTP},
 		{auth.Name(auth.PAM), auth.PAM},
 		{auth.Name(auth.GitHub), auth.GitHub},
+		{auth.Name(auth.OIDC), auth.OIDC},
 	}
 	securityProtocols = []dropdownItem{
 		{ldap.SecurityProtocolName(ldap.SecurityProtocolUnencrypted), ldap.SecurityProtocolUnencrypted},
@@ -104,6 +106,14 @@ func parseLDAPConfig(f form.Authentication) *ldap.Config {
 		GroupMemberUID:    f.GroupMemberUID,
 		UserUID:           f.UserUID,
 		AdminFilter:       f.AdminFilter,
+		AttributeFullName: f.AttributeFullName,
+		AttributeAvatar:   f.AttributeAvatar,
+		AttributeLocation: f.AttributeLocation,
+		AdminGroupDN:      f.AdminGroupDN,
+		SyncProfile:       f.SyncProfile,
+		PoolSize:          f.PoolSize,
+		Timeout:           f.Timeout,
+		HostRetryInterval: f.HostRetryInterval,
 	}
 }
 
@@ -118,6 +128,21 @@ func parseSMTPConfig(f form.Authentication) *smtp.Config {
 	}
 }
 
+func parseOIDCConfig(f form.Authentication) *oidc.Config {
+	return &oidc.Config{
+		Issuer:        strings.TrimSuffix(f.OIDCIssuer, "/"),
+		ClientID:      f.OIDCClientID,
+		ClientSecret:  f.OIDCClientSecret,
+		Scopes:        f.OIDCScopes,
+		UsernameClaim: f.OIDCUsernameClaim,
+		EmailClaim:    f.OIDCEmailClaim,
+		FullNameClaim: f.OIDCFullNameClaim,
+		GroupsClaim:   f.OIDCGroupsClaim,
+		AdminGroup:    f.OIDCAdminGroup,
+		SkipVerify:    f.SkipVerify,
+	}
+}
+
 func NewAuthSourcePost(c *context.Context, f form.Authentication) {
 	c.Title("admin.auths.new")
 	c.PageIs("Admin")
@@ -148,6 +173,9 @@ func NewAuthSourcePost(c *context.Context, f form.Authentication) {
 			SkipVerify:  f.SkipVerify,
 		}
 		hasTLS = true
+	case auth.OIDC:
+		config = parseOIDCConfig(f)
+		hasTLS = true
 	default:
 		c.Status(http.StatusBadRequest)
 		return
@@ -248,6 +276,8 @@ func EditAuthSourcePost(c *context.Context, f form.Authentication) {
 			APIEndpoint: strings.TrimSuffix(f.GitHubAPIEndpoint, "/") + "/",
 			SkipVerify:  f.SkipVerify,
 		})
+	case auth.OIDC:
+		provider = oidc.NewProvider(parseOIDCConfig(f))
 	default:
 		c.Status(http.StatusBadRequest)
 		return
@@ -34,7 +34,8 @@ func Repos(c *context.Context) {
 	)
 
 	keyword := c.Query("q")
-	if keyword == "" {
+	sortType := c.Query("sort")
+	if keyword == "" && sortType != "size" {
 		repos, err = db.Repositories(page, conf.UI.Admin.RepoPagingNum)
 		if err != nil {
 			c.Error(err, "list repositories")
@@ -42,9 +43,13 @@ func Repos(c *context.Context) {
 		}
 		count = db.CountRepositories(true)
 	} else {
+		orderBy := "id ASC"
+		if sortType == "size" {
+			orderBy = "size DESC"
+		}
 		repos, count, err = db.SearchRepositoryByName(&db.SearchRepoOptions{
 			Keyword:  keyword,
-			OrderBy:  "id ASC",
+			OrderBy:  orderBy,
 			Private:  true,
 			Page:     page,
 			PageSize: conf.UI.Admin.RepoPagingNum,
@@ -55,6 +60,7 @@ func Repos(c *context.Context) {
 		}
 	}
 	c.Data["Keyword"] = keyword
+	c.Data["SortType"] = sortType
 	c.Data["Total"] = count
 	c.Data["Page"] = paginater.New(int(count), conf.UI.Admin.RepoPagingNum, page, 5)
 
@@ -79,6 +85,7 @@ func DeleteRepo(c *context.Context) {
 		return
 	}
 	log.Trace("Repository deleted: %s/%s", repo.MustOwner().Name, repo.Name)
+	db.RecordAudit(c.Req.Context(), db.AuditActionRepoDelete, repo.ID, repo.MustOwner().Name+"/"+repo.Name, "")
 
 	c.Flash.Success(c.Tr("repo.settings.deletion_success"))
 	c.JSONSuccess(map[string]any{
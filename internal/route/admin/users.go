@@ -233,8 +233,8 @@ func DeleteUser(c *context.Context) {
 			c.JSONSuccess(map[string]any{
 				"redirect": conf.Server.Subpath + "/admin/users/" + c.Params(":userid"),
 			})
-		case db.IsErrUserHasOrgs(err):
-			c.Flash.Error(c.Tr("admin.users.still_has_org"))
+		case db.IsErrLastOrgOwner(err):
+			c.Flash.Error(c.Tr("admin.users.last_org_owner"))
 			c.JSONSuccess(map[string]any{
 				"redirect": conf.Server.Subpath + "/admin/users/" + c.Params(":userid"),
 			})
@@ -0,0 +1,100 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// AuditLog is the API message of an audit log of a sensitive operation.
+type AuditLog struct {
+	ID         int64     `json:"id"`
+	ActorID    int64     `json:"actor_id"`
+	ActorName  string    `json:"actor_name"`
+	Action     string    `json:"action"`
+	TargetID   int64     `json:"target_id"`
+	TargetName string    `json:"target_name"`
+	Content    string    `json:"content"`
+	IP         string    `json:"ip"`
+	Created    time.Time `json:"created_at"`
+}
+
+// parseTimeQuery parses the query value with given name in RFC 3339 format, it
+// returns zero time when the value is empty.
+func parseTimeQuery(c *context.APIContext, name string) (time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %q: %v", name, err)
+	}
+	return t, nil
+}
+
+func ListAuditLogs(c *context.APIContext) {
+	opts := db.ListAuditLogsOptions{
+		Action:   db.AuditAction(c.Query("action")),
+		Page:     c.QueryInt("page"),
+		PageSize: convert.ToCorrectPageSize(c.QueryInt("limit")),
+	}
+
+	if actor := c.Query("actor"); actor != "" {
+		u, err := db.Users.GetByUsername(c.Req.Context(), actor)
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("actor does not exist: [name: %s]", actor))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return
+		}
+		opts.ActorID = u.ID
+	}
+
+	var err error
+	opts.Since, err = parseTimeQuery(c, "since")
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		return
+	}
+	opts.Until, err = parseTimeQuery(c, "until")
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	logs, count, err := db.AuditLogs.List(c.Req.Context(), opts)
+	if err != nil {
+		c.Error(err, "list audit logs")
+		return
+	}
+
+	apiLogs := make([]*AuditLog, len(logs))
+	for i, l := range logs {
+		apiLogs[i] = &AuditLog{
+			ID:         l.ID,
+			ActorID:    l.ActorID,
+			ActorName:  l.ActorName,
+			Action:     string(l.Action),
+			TargetID:   l.TargetID,
+			TargetName: l.TargetName,
+			Content:    l.Content,
+			IP:         l.IP,
+			Created:    l.Created,
+		}
+	}
+
+	c.SetLinkHeader(int(count), opts.PageSize)
+	c.JSONSuccess(&apiLogs)
+}
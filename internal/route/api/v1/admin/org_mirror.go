@@ -0,0 +1,164 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/netutil"
+)
+
+// OrgMirror is the API message of a mirror of an upstream organization. The
+// credentials are never returned.
+type OrgMirror struct {
+	ID          int64     `json:"id"`
+	UpstreamURL string    `json:"upstream_url"`
+	Username    string    `json:"username"`
+	NamePrefix  string    `json:"name_prefix"`
+	Created     time.Time `json:"created_at"`
+	LastScanned time.Time `json:"last_scanned_at"`
+}
+
+// OrgMirrorRepoStatus is the API message of the mirroring status of an
+// upstream repository.
+type OrgMirrorRepoStatus struct {
+	UpstreamName string `json:"upstream_name"`
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
+
+// OrgMirrorSyncResult is the API message of the result of syncing a mirror of
+// an upstream organization.
+type OrgMirrorSyncResult struct {
+	Mirror *OrgMirror             `json:"mirror"`
+	Repos  []*OrgMirrorRepoStatus `json:"repos"`
+}
+
+// CreateOrgMirrorRequest is the API message for mirroring an upstream
+// organization.
+type CreateOrgMirrorRequest struct {
+	UpstreamURL string `json:"upstream_url" binding:"Required;Url"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	NamePrefix  string `json:"name_prefix" binding:"MaxSize(50)"`
+}
+
+func toOrgMirror(m *db.OrgMirror) *OrgMirror {
+	return &OrgMirror{
+		ID:          m.ID,
+		UpstreamURL: m.UpstreamURL,
+		Username:    m.AuthUsername,
+		NamePrefix:  m.NamePrefix,
+		Created:     m.Created,
+		LastScanned: m.LastScanned,
+	}
+}
+
+func toOrgMirrorRepoStatuses(statuses []*db.OrgMirrorRepoStatus) []*OrgMirrorRepoStatus {
+	apiStatuses := make([]*OrgMirrorRepoStatus, len(statuses))
+	for i, s := range statuses {
+		apiStatuses[i] = &OrgMirrorRepoStatus{
+			UpstreamName: s.UpstreamName,
+			Name:         s.Name,
+			Status:       s.Status,
+			Error:        s.Error,
+		}
+	}
+	return apiStatuses
+}
+
+// syncOrgMirror syncs the mirror and renders the result with given status.
+func syncOrgMirror(c *context.APIContext, m *db.OrgMirror, status int) {
+	statuses, err := db.SyncOrgMirror(c.Req.Context(), m)
+	if err != nil {
+		c.ErrorStatus(http.StatusBadGateway, errors.Wrap(err, "sync mirror of upstream organization"))
+		return
+	}
+
+	m, err = db.OrgMirrors.GetByID(c.Req.Context(), m.OrgID, m.ID)
+	if err != nil {
+		c.Error(err, "get mirror of upstream organization by ID")
+		return
+	}
+	c.JSON(status, &OrgMirrorSyncResult{
+		Mirror: toOrgMirror(m),
+		Repos:  toOrgMirrorRepoStatuses(statuses),
+	})
+}
+
+// GET /admin/orgs/:orgname/mirrors
+func ListOrgMirrors(c *context.APIContext) {
+	mirrors, err := db.OrgMirrors.List(c.Req.Context(), c.Org.Organization.ID)
+	if err != nil {
+		c.Error(err, "list mirrors of upstream organizations")
+		return
+	}
+
+	apiMirrors := make([]*OrgMirror, len(mirrors))
+	for i := range mirrors {
+		apiMirrors[i] = toOrgMirror(mirrors[i])
+	}
+	c.JSONSuccess(&apiMirrors)
+}
+
+// POST /admin/orgs/:orgname/mirrors
+func CreateOrgMirror(c *context.APIContext, r CreateOrgMirrorRequest) {
+	u, err := url.Parse(r.UpstreamURL)
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		return
+	} else if netutil.IsBlockedLocalHostname(u.Hostname(), conf.Security.LocalNetworkAllowlist) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Upstream URL resolved to a local network address that is implicitly blocked."))
+		return
+	}
+
+	m, err := db.OrgMirrors.Create(c.Req.Context(), c.Org.Organization.ID, c.User.ID, conf.Security.SecretKey, db.CreateOrgMirrorOptions{
+		UpstreamURL:  r.UpstreamURL,
+		AuthUsername: r.Username,
+		AuthPassword: r.Password,
+		NamePrefix:   r.NamePrefix,
+	})
+	if err != nil {
+		if db.IsErrOrgMirrorAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("The upstream organization is already mirrored."))
+		} else if db.IsErrInvalidCloneAddr(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Upstream URL must be an HTTP(S) URL of an organization."))
+		} else {
+			c.Error(err, "create mirror of upstream organization")
+		}
+		return
+	}
+
+	syncOrgMirror(c, m, http.StatusCreated)
+}
+
+// POST /admin/orgs/:orgname/mirrors/:id/sync
+func SyncOrgMirror(c *context.APIContext) {
+	m, err := db.OrgMirrors.GetByID(c.Req.Context(), c.Org.Organization.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get mirror of upstream organization by ID")
+		return
+	}
+
+	syncOrgMirror(c, m, http.StatusOK)
+}
+
+// DELETE /admin/orgs/:orgname/mirrors/:id
+func DeleteOrgMirror(c *context.APIContext) {
+	err := db.OrgMirrors.DeleteByID(c.Req.Context(), c.Org.Organization.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete mirror of upstream organization by ID")
+		return
+	}
+	c.NoContent()
+}
@@ -23,7 +23,7 @@ func CreateTeam(c *context.APIContext, form api.CreateTeamOption) {
 		Authorize:   db.ParseAccessMode(form.Permission),
 	}
 	if err := db.NewTeam(team); err != nil {
-		if db.IsErrTeamAlreadyExist(err) {
+		if db.IsErrTeamAlreadyExist(err) || db.IsErrReachLimitOfTeams(err) {
 			c.ErrorStatus(http.StatusUnprocessableEntity, err)
 		} else {
 			c.Error(err, "new team")
@@ -39,8 +39,12 @@ func AddTeamMember(c *context.APIContext) {
 	if c.Written() {
 		return
 	}
-	if err := c.Org.Team.AddMember(u.ID); err != nil {
-		c.Error(err, "add member")
+	if err := c.Org.Team.AddMember(c.User, u.ID); err != nil {
+		if db.IsErrReachLimitOfTeamMembers(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "add member")
+		}
 		return
 	}
 
@@ -53,7 +57,7 @@ func RemoveTeamMember(c *context.APIContext) {
 		return
 	}
 
-	if err := c.Org.Team.RemoveMember(u.ID); err != nil {
+	if err := c.Org.Team.RemoveMember(c.User, u.ID); err != nil {
 		c.Error(err, "remove member")
 		return
 	}
@@ -5,9 +5,14 @@
 package admin
 
 import (
+	"net/http"
+
 	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
 
 	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
 	"gogs.io/gogs/internal/route/api/v1/repo"
 	"gogs.io/gogs/internal/route/api/v1/user"
 )
@@ -20,3 +25,81 @@ func CreateRepo(c *context.APIContext, form api.CreateRepoOption) {
 
 	repo.CreateUserRepo(c, owner, form)
 }
+
+// ImportIssueRequest is the API message for importing an issue with its
+// original number.
+type ImportIssueRequest struct {
+	Index  int64   `json:"index" binding:"Required"`
+	Title  string  `json:"title" binding:"Required"`
+	Body   string  `json:"body"`
+	Poster string  `json:"poster"`
+	Labels []int64 `json:"labels"`
+	Closed bool    `json:"closed"`
+}
+
+// ImportIssue creates an issue with the explicit index, which is only allowed
+// for site administrators to prevent forging issue numbers.
+func ImportIssue(c *context.APIContext, form ImportIssueRequest) {
+	poster := c.User
+	if form.Poster != "" {
+		var err error
+		poster, err = db.Users.GetByUsername(c.Req.Context(), form.Poster)
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("poster does not exist: [name: %s]", form.Poster))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return
+		}
+	}
+
+	issue := &db.Issue{
+		RepoID:   c.Repo.Repository.ID,
+		Title:    form.Title,
+		PosterID: poster.ID,
+		Poster:   poster,
+		Content:  form.Body,
+	}
+	if err := db.NewImportedIssue(c.Repo.Repository, issue, form.Index, form.Labels); err != nil {
+		if db.IsErrIssueIndexAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "new imported issue")
+		}
+		return
+	}
+
+	if form.Closed {
+		if err := issue.ChangeStatus(poster, c.Repo.Repository, true); err != nil {
+			c.Error(err, "change status to closed")
+			return
+		}
+	}
+
+	// Refetch from database to assign some automatic values
+	issue, err := db.GetIssueByID(issue.ID)
+	if err != nil {
+		c.Error(err, "get issue by ID")
+		return
+	}
+	c.JSON(http.StatusCreated, convert.ToIssue(issue))
+}
+
+// SetNextIssueIndexRequest is the API message for seeding the issue index
+// counter of a repository.
+type SetNextIssueIndexRequest struct {
+	Index int64 `json:"index" binding:"Required"`
+}
+
+func SetNextIssueIndex(c *context.APIContext, form SetNextIssueIndexRequest) {
+	if err := db.SetNextIssueIndex(c.Repo.Repository.ID, form.Index); err != nil {
+		if db.IsErrIssueIndexAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "set next issue index")
+		}
+		return
+	}
+	c.NoContent()
+}
@@ -131,7 +131,7 @@ func DeleteUser(c *context.APIContext) {
 
 	if err := db.Users.DeleteByID(c.Req.Context(), u.ID, false); err != nil {
 		if db.IsErrUserOwnRepos(err) ||
-			db.IsErrUserHasOrgs(err) {
+			db.IsErrLastOrgOwner(err) {
 			c.ErrorStatus(http.StatusUnprocessableEntity, err)
 		} else {
 			c.Error(err, "delete user")
@@ -143,6 +143,19 @@ func DeleteUser(c *context.APIContext) {
 	c.NoContent()
 }
 
+// DELETE /admin/users/:username/login_lockout
+func ClearLoginLockout(c *context.APIContext) {
+	u := user.GetUserByParams(c)
+	if c.Written() {
+		return
+	}
+
+	db.ClearLoginLockout(u)
+	log.Trace("Sign in lockout cleared by admin(%s): %s", c.User.Name, u.Name)
+
+	c.NoContent()
+}
+
 func CreatePublicKey(c *context.APIContext, form api.CreateKeyOption) {
 	u := user.GetUserByParams(c)
 	if c.Written() {
@@ -25,7 +25,9 @@ import (
 
 // repoAssignment extracts information from URL parameters to retrieve the repository,
 // and makes sure the context user has at least the read access to the repository.
-func repoAssignment() macaron.Handler {
+// When allowPublicIssues is set, users without access are also allowed if public
+// access to issues of the repository is allowed.
+func repoAssignment(allowPublicIssues ...bool) macaron.Handler {
 	return func(c *context.APIContext) {
 		username := c.Params(":username")
 		reponame := c.Params(":reponame")
@@ -63,14 +65,22 @@ func repoAssignment() macaron.Handler {
 					Private: repo.IsPrivate,
 				},
 			)
+
+			// Forks of private repositories are only accessible to users who have
+			// access to the base repository.
+			if c.Repo.AccessMode > db.AccessModeNone && !repo.HasBaseAccess(c.Req.Context(), c.UserID()) {
+				c.Repo.AccessMode = db.AccessModeNone
+			}
 		}
 
-		if !c.Repo.HasAccess() {
+		if !c.Repo.HasAccess() &&
+			!(len(allowPublicIssues) > 0 && allowPublicIssues[0] && repo.CanGuestViewIssues()) {
 			c.NotFound()
 			return
 		}
 
 		c.Repo.Repository = repo
+		c.AddLogField("repo", owner.Name+"/"+repo.Name)
 	}
 }
 
@@ -104,6 +114,14 @@ func orgAssignment(args ...bool) macaron.Handler {
 				c.NotFoundOrError(err, "get team by ID")
 				return
 			}
+
+			if c.Org.Organization == nil {
+				c.Org.Organization, err = db.Users.GetByID(c.Req.Context(), c.Org.Team.OrgID)
+				if err != nil {
+					c.NotFoundOrError(err, "get organization by ID")
+					return
+				}
+			}
 		}
 	}
 }
@@ -158,6 +176,21 @@ func reqRepoAdmin() macaron.Handler {
 	}
 }
 
+// reqOrgOwner makes sure the context user is an owner of the organization.
+func reqOrgOwner() macaron.Handler {
+	return func(c *context.APIContext) {
+		if !c.Org.Organization.IsOrganization() {
+			c.NotFound()
+			return
+		}
+
+		if !c.IsLogged || (!c.User.IsAdmin && !c.Org.Organization.IsOwnedBy(c.User.ID)) {
+			c.Status(http.StatusForbidden)
+			return
+		}
+	}
+}
+
 func mustEnableIssues(c *context.APIContext) {
 	if !c.Repo.Repository.EnableIssues || c.Repo.Repository.EnableExternalTracker {
 		c.NotFound()
@@ -165,6 +198,27 @@ func mustEnableIssues(c *context.APIContext) {
 	}
 }
 
+func mustAllowCreateIssues(c *context.APIContext) {
+	if !c.Repo.Repository.CanCreateIssues(c.Repo.AccessMode) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+}
+
+func mustEnablePulls(c *context.APIContext) {
+	if !c.Repo.Repository.AllowsPulls() {
+		c.NotFound()
+		return
+	}
+}
+
+func mustEnableReleases(c *context.APIContext) {
+	if !c.Repo.Repository.EnableReleases {
+		c.NotFound()
+		return
+	}
+}
+
 // RegisterRoutes registers all route in API v1 to the web application.
 // FIXME: custom form error response
 func RegisterRoutes(m *macaron.Macaron) {
@@ -177,6 +231,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		// Miscellaneous
 		m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 		m.Post("/markdown/raw", misc.MarkdownRaw)
+		m.Get("/ssh/host_keys", misc.ListSSHHostKeys)
 
 		// Users
 		m.Group("/users", func() {
@@ -184,6 +239,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 			m.Group("/:username", func() {
 				m.Get("", user.GetInfo)
+				m.Get("/activities", repo.ListUserActivities)
 
 				m.Group("/tokens", func() {
 					m.Combo("").
@@ -230,7 +286,23 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Delete(user.DeletePublicKey)
 			})
 
+			m.Group("/sessions", func() {
+				m.Combo("").
+					Get(user.ListSessions).
+					Delete(user.DeleteSessions)
+				m.Delete("/:id", user.DeleteSession)
+			})
+
 			m.Get("/issues", repo.ListUserIssues)
+
+			m.Group("/repository_invitations", func() {
+				m.Get("", repo.ListMyRepoInvitations)
+				m.Patch("/:id", bind(repo.RespondRepoInvitationRequest{}), repo.RespondRepoInvitation)
+			})
+			m.Group("/organization_invitations", func() {
+				m.Get("", org.ListMyOrgInvitations)
+				m.Patch("/:id", bind(org.RespondOrgInvitationRequest{}), org.RespondOrgInvitation)
+			})
 		}, reqToken())
 
 		// Repositories
@@ -241,16 +313,82 @@ func RegisterRoutes(m *macaron.Macaron) {
 			Post(bind(api.CreateRepoOption{}), repo.Create)
 		m.Post("/org/:org/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
 
+		m.Get("/topics/search", repo.SearchTopics)
+
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
 
 			m.Get("/:username/:reponame", repoAssignment(), repo.Get)
-			m.Get("/:username/:reponame/releases", repoAssignment(), repo.Releases)
+			// Drafts are only visible to writers of the repository.
+			m.Group("/:username/:reponame/releases", func() {
+				m.Combo("").
+					Get(repo.Releases).
+					Post(reqToken(), reqRepoWriter(), bind(repo.CreateReleaseRequest{}), repo.CreateRelease)
+				m.Get("/latest", repo.GetLatestRelease)
+				m.Get("/tags/*", repo.GetReleaseByTag)
+				m.Group("/:id", func() {
+					m.Combo("").
+						Get(repo.GetRelease).
+						Patch(reqToken(), reqRepoWriter(), bind(repo.EditReleaseRequest{}), repo.EditRelease).
+						Delete(reqToken(), reqRepoWriter(), repo.DeleteRelease)
+					m.Combo("/assets").
+						Get(repo.ListReleaseAssets).
+						Post(reqToken(), reqRepoWriter(), repo.UploadReleaseAsset)
+					m.Combo("/assets/:assetID").
+						Get(repo.GetReleaseAsset).
+						Delete(reqToken(), reqRepoWriter(), repo.DeleteReleaseAsset)
+					m.Get("/assets/:assetID/download", repo.DownloadReleaseAsset)
+				})
+			}, repoAssignment(), mustEnableReleases)
+			m.Get("/:username/:reponame/activities", repoAssignment(), repo.ListRepoActivities)
+			// Badges of private repositories require a token with access to the repository.
+			m.Get("/:username/:reponame/badge/:badge", repoAssignment(), repo.GetBadge)
+			// Secret values are only retrievable with a secrets token for CI contexts.
+			m.Get("/:username/:reponame/secrets/values", repo.ListSecretValues)
+
+			// Issues are readable without the access to the code when public access to
+			// issues is allowed.
+			m.Group("/:username/:reponame/issues", func() {
+				m.Combo("").
+					Get(repo.ListIssues).
+					Post(reqToken(), mustAllowCreateIssues, bind(api.CreateIssueOption{}), repo.CreateIssue)
+				m.Group("/comments", func() {
+					m.Get("", repo.ListRepoIssueComments)
+					m.Patch("/:id", reqToken(), bind(api.EditIssueCommentOption{}), repo.EditIssueComment)
+					m.Get("/:id/history", reqToken(), repo.ListIssueCommentHistory)
+					m.Delete("/:id/history/:historyID", reqToken(), reqRepoWriter(), repo.DeleteIssueCommentHistory)
+				})
+				m.Group("/:index", func() {
+					m.Combo("").
+						Get(repo.GetIssue).
+						Patch(reqToken(), bind(api.EditIssueOption{}), repo.EditIssue)
+
+					m.Group("/comments", func() {
+						m.Combo("").
+							Get(repo.ListIssueComments).
+							Post(reqToken(), bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
+						m.Combo("/:id").
+							Patch(reqToken(), bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
+							Delete(reqToken(), repo.DeleteIssueComment)
+					})
+
+					m.Get("/labels", repo.ListIssueLabels)
+					m.Group("/labels", func() {
+						m.Combo("").
+							Post(bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
+							Put(bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
+							Delete(repo.ClearIssueLabels)
+						m.Delete("/:id", repo.DeleteIssueLabel)
+					}, reqToken(), reqRepoWriter())
+					m.Put("/workflow_state", reqToken(), reqRepoWriter(), bind(repo.SetIssueWorkflowStateRequest{}), repo.SetIssueWorkflowState)
+				})
+			}, repoAssignment(true), mustEnableIssues)
 		})
 
 		m.Group("/repos", func() {
 			m.Post("/migrate", bind(form.MigrateRepo{}), repo.Migrate)
 			m.Delete("/:username/:reponame", repoAssignment(), repo.Delete)
+			m.Get("/:username/:reponame/deletion_token", repoAssignment(), reqRepoAdmin(), repo.GetDeletionToken)
 
 			m.Group("/:username/:reponame", func() {
 				m.Group("/hooks", func() {
@@ -269,13 +407,27 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Put(bind(api.AddCollaboratorOption{}), repo.AddCollaborator).
 						Delete(repo.DeleteCollaborator)
 				}, reqRepoAdmin())
+				m.Group("/invitations", func() {
+					m.Get("", repo.ListRepoInvitations)
+					m.Delete("/:id", repo.DeleteRepoInvitation)
+				}, reqRepoAdmin())
+				m.Group("/secrets", func() {
+					m.Get("", repo.ListSecrets)
+					m.Post("/token", repo.CreateSecretsToken)
+					m.Combo("/:name").
+						Put(bind(repo.SetSecretRequest{}), repo.SetSecret).
+						Delete(repo.DeleteSecret)
+				}, reqRepoAdmin())
 
 				m.Get("/raw/*", context.RepoRef(), repo.GetRawFile)
+				m.Get("/blame/*", context.RepoRef(), repo.GetBlame)
 				m.Group("/contents", func() {
 					m.Get("", repo.GetContents)
 					m.Combo("/*").
 						Get(repo.GetContents).
-						Put(bind(repo.PutContentsRequest{}), repo.PutContents)
+						Post(reqRepoWriter(), bind(repo.PutContentsRequest{}), repo.CreateContents).
+						Put(reqRepoWriter(), bind(repo.PutContentsRequest{}), repo.PutContents).
+						Delete(reqRepoWriter(), bind(repo.DeleteContentsRequest{}), repo.DeleteContents)
 				})
 				m.Get("/archive/*", repo.GetArchive)
 				m.Group("/git", func() {
@@ -292,11 +444,23 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", repo.GetBranch)
 				})
+				m.Group("/branch_protections", func() {
+					m.Combo("").
+						Get(repo.ListBranchProtections).
+						Post(bind(repo.CreateBranchProtectionRequest{}), repo.CreateBranchProtection)
+					m.Combo("/*").
+						Get(repo.GetBranchProtection).
+						Patch(bind(repo.EditBranchProtectionRequest{}), repo.EditBranchProtection).
+						Delete(repo.DeleteBranchProtection)
+				}, reqRepoAdmin())
 				m.Group("/commits", func() {
 					m.Get("/:sha", repo.GetSingleCommit)
 					m.Get("", repo.GetAllCommits)
 					m.Get("/*", repo.GetReferenceSHA)
 				})
+				m.Combo("/statuses/:sha").
+					Get(repo.ListCommitStatuses).
+					Post(reqRepoWriter(), bind(repo.CreateCommitStatusRequest{}), repo.CreateCommitStatus)
 
 				m.Group("/keys", func() {
 					m.Combo("").
@@ -307,39 +471,6 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Delete(repo.DeleteDeploykey)
 				}, reqRepoAdmin())
 
-				m.Group("/issues", func() {
-					m.Combo("").
-						Get(repo.ListIssues).
-						Post(bind(api.CreateIssueOption{}), repo.CreateIssue)
-					m.Group("/comments", func() {
-						m.Get("", repo.ListRepoIssueComments)
-						m.Patch("/:id", bind(api.EditIssueCommentOption{}), repo.EditIssueComment)
-					})
-					m.Group("/:index", func() {
-						m.Combo("").
-							Get(repo.GetIssue).
-							Patch(bind(api.EditIssueOption{}), repo.EditIssue)
-
-						m.Group("/comments", func() {
-							m.Combo("").
-								Get(repo.ListIssueComments).
-								Post(bind(api.CreateIssueCommentOption{}), repo.CreateIssueComment)
-							m.Combo("/:id").
-								Patch(bind(api.EditIssueCommentOption{}), repo.EditIssueComment).
-								Delete(repo.DeleteIssueComment)
-						})
-
-						m.Get("/labels", repo.ListIssueLabels)
-						m.Group("/labels", func() {
-							m.Combo("").
-								Post(bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
-								Put(bind(api.IssueLabelsOption{}), repo.ReplaceIssueLabels).
-								Delete(repo.ClearIssueLabels)
-							m.Delete("/:id", repo.DeleteIssueLabel)
-						}, reqRepoWriter())
-					})
-				}, mustEnableIssues)
-
 				m.Group("/labels", func() {
 					m.Get("", repo.ListLabels)
 					m.Get("/:id", repo.GetLabel)
@@ -351,6 +482,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 						Delete(repo.DeleteLabel)
 				}, reqRepoWriter())
 
+				m.Get("/pulls/:index/status", mustEnablePulls, repo.GetPullRequestStatus)
+				m.Get("/pulls/:index/requested_reviewers", mustEnablePulls, repo.ListRequestedReviewers)
+
 				m.Group("/milestones", func() {
 					m.Get("", repo.ListMilestones)
 					m.Get("/:id", repo.GetMilestone)
@@ -364,6 +498,35 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 				m.Patch("/issue-tracker", reqRepoWriter(), bind(api.EditIssueTrackerOption{}), repo.IssueTracker)
 				m.Patch("/wiki", reqRepoWriter(), bind(api.EditWikiOption{}), repo.Wiki)
+				m.Combo("/features").
+					Get(repo.GetFeatures).
+					Patch(reqRepoWriter(), bind(repo.EditFeaturesRequest{}), repo.EditFeatures)
+				m.Group("/deployments", func() {
+					m.Combo("").
+						Get(repo.ListDeployments).
+						Post(reqRepoWriter(), bind(repo.CreateDeploymentRequest{}), repo.CreateDeployment)
+					m.Group("/:id", func() {
+						m.Get("", repo.GetDeployment)
+						m.Combo("/statuses").
+							Get(repo.ListDeploymentStatuses).
+							Post(reqRepoWriter(), bind(repo.CreateDeploymentStatusRequest{}), repo.CreateDeploymentStatus)
+					})
+				})
+				m.Get("/environments", repo.ListEnvironments)
+				m.Get("/languages", repo.ListLanguages)
+				m.Combo("/workflow_states").
+					Get(repo.ListWorkflowStates).
+					Post(reqRepoWriter(), bind(repo.CreateWorkflowStateRequest{}), repo.CreateWorkflowState)
+				m.Delete("/workflow_states/:id", reqRepoWriter(), repo.DeleteWorkflowState)
+				m.Combo("/default_reviewers").
+					Get(repo.GetDefaultReviewers).
+					Put(reqRepoAdmin(), bind(repo.DefaultReviewers{}), repo.ReplaceDefaultReviewers)
+				m.Combo("/default_pull_assignee").
+					Get(repo.GetDefaultPullAssignee).
+					Put(reqRepoAdmin(), bind(repo.DefaultPullAssignee{}), repo.ReplaceDefaultPullAssignee)
+				m.Combo("/mirror").
+					Get(reqRepoWriter(), repo.GetMirror).
+					Patch(reqRepoAdmin(), bind(repo.EditMirrorRequest{}), repo.EditMirror)
 				m.Post("/mirror-sync", reqRepoWriter(), repo.MirrorSync)
 				m.Get("/editorconfig/:filename", context.RepoRef(), repo.GetEditorconfig)
 			}, repoAssignment())
@@ -381,10 +544,46 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Combo("").
 				Get(org.Get).
 				Patch(bind(api.EditOrgOption{}), org.Edit)
-			m.Get("/teams", org.ListTeams)
+			m.Combo("/teams").
+				Get(org.ListTeams).
+				Post(reqToken(), reqOrgOwner(), bind(api.CreateTeamOption{}), org.CreateTeam)
+			m.Get("/activities", repo.ListOrgActivities)
 		}, orgAssignment(true))
+		m.Group("/teams/:teamid", func() {
+			m.Combo("").
+				Get(org.GetTeam).
+				Patch(bind(org.EditTeamRequest{}), org.EditTeam).
+				Delete(org.DeleteTeam)
+			m.Get("/members", org.ListTeamMembers)
+			m.Combo("/members/:username").
+				Put(org.AddTeamMember).
+				Delete(org.RemoveTeamMember)
+			m.Combo("/repos/:owner/:reponame").
+				Put(org.AddTeamRepository).
+				Delete(org.RemoveTeamRepository)
+		}, reqToken(), orgAssignment(false, true), reqOrgOwner())
+		m.Group("/orgs/:orgname/hooks", func() {
+			m.Combo("").
+				Get(repo.ListOrgHooks).
+				Post(bind(api.CreateHookOption{}), repo.CreateOrgHook)
+			m.Combo("/:id").
+				Patch(bind(api.EditHookOption{}), repo.EditOrgHook).
+				Delete(repo.DeleteOrgHook)
+		}, reqToken(), orgAssignment(true), reqOrgOwner())
+		m.Group("/orgs/:orgname/secrets", func() {
+			m.Get("", repo.ListOrgSecrets)
+			m.Combo("/:name").
+				Put(bind(repo.SetSecretRequest{}), repo.SetOrgSecret).
+				Delete(repo.DeleteOrgSecret)
+		}, reqToken(), orgAssignment(true), reqOrgOwner())
+		m.Group("/orgs/:orgname/invitations", func() {
+			m.Get("", org.ListOrgInvitations)
+			m.Delete("/:id", org.DeleteOrgInvitation)
+		}, reqToken(), orgAssignment(true), reqOrgOwner())
 
 		m.Group("/admin", func() {
+			m.Get("/audit_logs", admin.ListAuditLogs)
+
 			m.Group("/users", func() {
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
 
@@ -392,16 +591,29 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Combo("").
 						Patch(bind(api.EditUserOption{}), admin.EditUser).
 						Delete(admin.DeleteUser)
+					m.Delete("/login_lockout", admin.ClearLoginLockout)
 					m.Post("/keys", bind(api.CreateKeyOption{}), admin.CreatePublicKey)
 					m.Post("/orgs", bind(api.CreateOrgOption{}), admin.CreateOrg)
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
 
+			m.Group("/repos/:username/:reponame", func() {
+				m.Post("/issues", bind(admin.ImportIssueRequest{}), admin.ImportIssue)
+				m.Put("/issues/next_index", bind(admin.SetNextIssueIndexRequest{}), admin.SetNextIssueIndex)
+			}, repoAssignment())
+
 			m.Group("/orgs/:orgname", func() {
 				m.Group("/teams", func() {
 					m.Post("", orgAssignment(true), bind(api.CreateTeamOption{}), admin.CreateTeam)
 				})
+				m.Group("/mirrors", func() {
+					m.Combo("").
+						Get(admin.ListOrgMirrors).
+						Post(bind(admin.CreateOrgMirrorRequest{}), admin.CreateOrgMirror)
+					m.Delete("/:id", admin.DeleteOrgMirror)
+					m.Post("/:id/sync", admin.SyncOrgMirror)
+				}, orgAssignment(true))
 			})
 
 			m.Group("/teams", func() {
@@ -0,0 +1,157 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/repo"
+)
+
+func Test_mustEnableFeatures(t *testing.T) {
+	tests := []struct {
+		name          string
+		repo          *db.Repository
+		handler       macaron.Handler
+		expStatusCode int
+	}{
+		{
+			name:          "issues enabled",
+			repo:          &db.Repository{EnableIssues: true},
+			handler:       mustEnableIssues,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "issues disabled",
+			repo:          &db.Repository{EnableIssues: false},
+			handler:       mustEnableIssues,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			name:          "issues use external tracker",
+			repo:          &db.Repository{EnableIssues: true, EnableExternalTracker: true},
+			handler:       mustEnableIssues,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			name:          "releases enabled",
+			repo:          &db.Repository{EnableReleases: true},
+			handler:       mustEnableReleases,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			name:          "releases disabled",
+			repo:          &db.Repository{EnableReleases: false},
+			handler:       mustEnableReleases,
+			expStatusCode: http.StatusNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := macaron.New()
+			m.Use(macaron.Renderer())
+			m.Use(func(ctx *macaron.Context) {
+				ctx.Map(&context.APIContext{
+					Context: &context.Context{
+						Context: ctx,
+						Repo:    &context.Repository{Repository: test.repo},
+					},
+				})
+			})
+			m.Get("/", test.handler, func(c *context.APIContext) {
+				c.Status(http.StatusOK)
+			})
+
+			r, err := http.NewRequest("GET", "/", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			m.ServeHTTP(rr, r)
+			assert.Equal(t, test.expStatusCode, rr.Code)
+		})
+	}
+}
+
+func Test_repoAssignment_badge(t *testing.T) {
+	owner := &db.User{ID: 1, Name: "alice", LowerName: "alice"}
+	usersStore := NewMockUsersStore()
+	usersStore.GetByUsernameFunc.SetDefaultReturn(owner, nil)
+	db.SetMockUsersStore(t, usersStore)
+
+	tests := []struct {
+		name          string
+		repo          *db.Repository
+		user          *db.User
+		accessMode    db.AccessMode
+		expStatusCode int
+		expBody       string
+	}{
+		{
+			name:          "public repository",
+			repo:          &db.Repository{ID: 1, OwnerID: 1, Owner: owner, NumIssues: 5, NumClosedIssues: 2},
+			accessMode:    db.AccessModeRead,
+			expStatusCode: http.StatusOK,
+			expBody:       `{"schemaVersion":1,"label":"open issues","message":"3","color":"dfb317"}`,
+		},
+		{
+			name:          "private repository without token",
+			repo:          &db.Repository{ID: 2, OwnerID: 1, Owner: owner, IsPrivate: true, NumIssues: 5},
+			accessMode:    db.AccessModeNone,
+			expStatusCode: http.StatusNotFound,
+		},
+		{
+			name:          "private repository with token",
+			repo:          &db.Repository{ID: 3, OwnerID: 1, Owner: owner, IsPrivate: true, NumIssues: 5, NumClosedIssues: 1},
+			user:          &db.User{ID: 2, Name: "bob", LowerName: "bob"},
+			accessMode:    db.AccessModeRead,
+			expStatusCode: http.StatusOK,
+			expBody:       `{"schemaVersion":1,"label":"open issues","message":"4","color":"dfb317"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reposStore := NewMockReposStore()
+			reposStore.GetByNameFunc.SetDefaultReturn(test.repo, nil)
+			db.SetMockReposStore(t, reposStore)
+
+			permsStore := NewMockPermsStore()
+			permsStore.AccessModeFunc.SetDefaultReturn(test.accessMode)
+			db.SetMockPermsStore(t, permsStore)
+
+			m := macaron.New()
+			m.Use(macaron.Renderer())
+			m.Use(func(ctx *macaron.Context) {
+				ctx.Map(&context.APIContext{
+					Context: &context.Context{
+						Context:     ctx,
+						User:        test.user,
+						IsLogged:    test.user != nil,
+						IsTokenAuth: test.user != nil,
+						Repo:        &context.Repository{},
+					},
+				})
+			})
+			m.Get("/repos/:username/:reponame/badge/:badge", repoAssignment(), repo.GetBadge)
+
+			r, err := http.NewRequest("GET", "/repos/alice/example/badge/open-issues.json", nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			m.ServeHTTP(rr, r)
+			assert.Equal(t, test.expStatusCode, rr.Code)
+			if test.expBody != "" {
+				assert.JSONEq(t, test.expBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/db"
+)
+
+// Activity is the API message of an action performed by a user on a repository.
+type Activity struct {
+	ID          int64     `json:"id"`
+	Type        string    `json:"type"`
+	ActUserID   int64     `json:"act_user_id"`
+	ActUserName string    `json:"act_user_name"`
+	RepoID      int64     `json:"repo_id"`
+	RepoName    string    `json:"repo_name"`
+	RefName     string    `json:"ref_name"`
+	IsPrivate   bool      `json:"is_private"`
+	Content     string    `json:"content"`
+	Created     time.Time `json:"created_at"`
+}
+
+var actionTypeNames = map[db.ActionType]string{
+	db.ActionCreateRepo:        "create_repo",
+	db.ActionRenameRepo:        "rename_repo",
+	db.ActionStarRepo:          "star_repo",
+	db.ActionWatchRepo:         "watch_repo",
+	db.ActionCommitRepo:        "commit_repo",
+	db.ActionCreateIssue:       "create_issue",
+	db.ActionCreatePullRequest: "create_pull_request",
+	db.ActionTransferRepo:      "transfer_repo",
+	db.ActionPushTag:           "push_tag",
+	db.ActionCommentIssue:      "comment_issue",
+	db.ActionMergePullRequest:  "merge_pull_request",
+	db.ActionCloseIssue:        "close_issue",
+	db.ActionReopenIssue:       "reopen_issue",
+	db.ActionClosePullRequest:  "close_pull_request",
+	db.ActionReopenPullRequest: "reopen_pull_request",
+	db.ActionCreateBranch:      "create_branch",
+	db.ActionDeleteBranch:      "delete_branch",
+	db.ActionDeleteTag:         "delete_tag",
+	db.ActionForkRepo:          "fork_repo",
+	db.ActionMirrorSyncPush:    "mirror_sync_push",
+	db.ActionMirrorSyncCreate:  "mirror_sync_create",
+	db.ActionMirrorSyncDelete:  "mirror_sync_delete",
+}
+
+// activityCategories maps the categories that activities can be filtered by to
+// the action types they consist of. Releases are backed by tags, thus the
+// category consists of tag actions.
+var activityCategories = map[string][]db.ActionType{
+	"push": {
+		db.ActionCommitRepo, db.ActionCreateBranch, db.ActionDeleteBranch,
+		db.ActionMirrorSyncPush, db.ActionMirrorSyncCreate, db.ActionMirrorSyncDelete,
+	},
+	"issue": {
+		db.ActionCreateIssue, db.ActionCloseIssue, db.ActionReopenIssue,
+	},
+	"pull_request": {
+		db.ActionCreatePullRequest, db.ActionMergePullRequest, db.ActionClosePullRequest, db.ActionReopenPullRequest,
+	},
+	"comment": {
+		db.ActionCommentIssue,
+	},
+	"release": {
+		db.ActionPushTag, db.ActionDeleteTag,
+	},
+}
+
+// ToActionTypes returns the action types of given activity categories. It
+// returns an error if any of the categories is unknown.
+func ToActionTypes(categories []string) ([]db.ActionType, error) {
+	var types []db.ActionType
+	for _, category := range categories {
+		ts, ok := activityCategories[category]
+		if !ok {
+			return nil, errors.Errorf("unknown activity type %q", category)
+		}
+		types = append(types, ts...)
+	}
+	return types, nil
+}
+
+func ToActivity(a *db.Action) *Activity {
+	return &Activity{
+		ID:          a.ID,
+		Type:        actionTypeNames[a.OpType],
+		ActUserID:   a.ActUserID,
+		ActUserName: a.ActUserName,
+		RepoID:      a.RepoID,
+		RepoName:    a.RepoUserName + "/" + a.RepoName,
+		RefName:     a.RefName,
+		IsPrivate:   a.IsPrivate,
+		Content:     a.Content,
+		Created:     a.Created,
+	}
+}
@@ -7,12 +7,14 @@ package convert
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/unknwon/com"
 
 	"github.com/gogs/git-module"
 	api "github.com/gogs/go-gogs-client"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/db"
 )
 
@@ -93,6 +95,8 @@ func ToHook(repoLink string, w *db.Webhook) *api.Hook {
 		config["username"] = s.Username
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
+	} else if w.HookTaskType == db.MSTEAMS {
+		config["theme_color"] = w.MSTeamsMeta().ThemeColor
 	}
 
 	return &api.Hook{
@@ -130,11 +134,96 @@ func ToOrganization(org *db.User) *api.Organization {
 	}
 }
 
-func ToTeam(team *db.Team) *api.Team {
-	return &api.Team{
-		ID:          team.ID,
-		Name:        team.Name,
-		Description: team.Description,
-		Permission:  team.Authorize.String(),
+// Issue extends api.Issue with the key of the issue for display, e.g. PROJ-123,
+// while the index stays canonical for URLs and references.
+type Issue struct {
+	*api.Issue
+	Key string `json:"key"`
+}
+
+// ToIssue converts the issue to its API format. This function assumes the
+// attributes of the issue are loaded.
+func ToIssue(issue *db.Issue) *Issue {
+	return &Issue{
+		Issue: issue.APIFormat(),
+		Key:   issue.Key(),
+	}
+}
+
+// Repository extends api.Repository with storage statistics.
+type Repository struct {
+	*api.Repository
+	ObjectCount int64 `json:"object_count"`
+	LFSSize     int64 `json:"lfs_size"`
+}
+
+func ToRepository(repo *db.Repository, permission *api.Permission) *Repository {
+	return &Repository{
+		Repository:  repo.APIFormatLegacy(permission),
+		ObjectCount: repo.ObjectCount,
+		LFSSize:     repo.LFSSize,
+	}
+}
+
+// Team extends api.Team with numbers of members and repositories.
+type Team struct {
+	*api.Team
+	MembersCount int `json:"members_count"`
+	ReposCount   int `json:"repos_count"`
+}
+
+func ToTeam(team *db.Team) *Team {
+	return &Team{
+		Team: &api.Team{
+			ID:          team.ID,
+			Name:        team.Name,
+			Description: team.Description,
+			Permission:  team.Authorize.String(),
+		},
+		MembersCount: team.NumMembers,
+		ReposCount:   team.NumRepos,
+	}
+}
+
+// ReleaseAsset is an uploaded file of a release.
+type ReleaseAsset struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	SHA256      string    `json:"sha256"`
+	DownloadURL string    `json:"browser_download_url"`
+	Created     time.Time `json:"created_at"`
+}
+
+// Release extends api.Release with uploaded assets and their checksums.
+type Release struct {
+	*api.Release
+	Assets       []*ReleaseAsset `json:"assets"`
+	ChecksumsURL string          `json:"checksums_url"`
+}
+
+// ToReleaseAsset converts the attachment to its API format of a release asset.
+// The checksum is empty when the file of the attachment was missing when
+// checksums were backfilled.
+func ToReleaseAsset(a *db.Attachment) *ReleaseAsset {
+	return &ReleaseAsset{
+		ID:          a.ID,
+		Name:        a.Name,
+		SHA256:      a.SHA256,
+		DownloadURL: conf.Server.ExternalURL + "attachments/" + a.UUID,
+		Created:     a.Created,
+	}
+}
+
+// ToRelease converts the release to its API format. This function assumes the
+// publisher and attachments of the release are loaded.
+func ToRelease(r *db.Release, repoHTMLURL string) *Release {
+	assets := make([]*ReleaseAsset, len(r.Attachments))
+	for i, a := range r.Attachments {
+		assets[i] = ToReleaseAsset(a)
+	}
+	return &Release{
+		Release:      r.APIFormat(),
+		Assets:       assets,
+		ChecksumsURL: repoHTMLURL + "/releases/checksums/" + r.TagName,
 	}
 }
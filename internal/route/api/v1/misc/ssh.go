@@ -0,0 +1,40 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/ssh"
+)
+
+type sshHostKey struct {
+	Type        string `json:"type"`
+	Fingerprint string `json:"fingerprint"`
+	PublicKey   string `json:"public_key"`
+}
+
+// GET /ssh/host_keys
+func ListSSHHostKeys(c *context.APIContext) {
+	if conf.SSH.Disabled || !conf.SSH.StartBuiltinServer {
+		c.NotFound()
+		return
+	}
+
+	hostKeys := ssh.HostKeys()
+	apiHostKeys := make([]*sshHostKey, 0, len(hostKeys))
+	for _, key := range hostKeys {
+		apiHostKeys = append(apiHostKeys, &sshHostKey{
+			Type:        key.Type(),
+			Fingerprint: key.Fingerprint(),
+			PublicKey:   strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key.Signer.PublicKey()))),
+		})
+	}
+	c.JSONSuccess(apiHostKeys)
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"time"
+
+	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// OrgInvitation is the API message of a pending invitation for a user to join
+// an organization.
+type OrgInvitation struct {
+	ID           int64             `json:"id"`
+	Organization *api.Organization `json:"organization"`
+	Inviter      *api.User         `json:"inviter"`
+	Invitee      *api.User         `json:"invitee"`
+	Created      time.Time         `json:"created_at"`
+	Expires      time.Time         `json:"expires_at"`
+}
+
+// RespondOrgInvitationRequest is the API message for accepting or declining an
+// organization invitation.
+type RespondOrgInvitationRequest struct {
+	Action string `json:"action" binding:"Required;In(accept,decline)"`
+}
+
+func toOrgInvitations(c *context.APIContext, invs []*db.OrgInvitation) ([]*OrgInvitation, error) {
+	apiInvs := make([]*OrgInvitation, len(invs))
+	for i, inv := range invs {
+		org, err := db.Users.GetByID(c.Req.Context(), inv.OrgID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get organization")
+		}
+		inviter, err := db.Users.GetByID(c.Req.Context(), inv.InviterID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get inviter")
+		}
+		invitee, err := db.Users.GetByID(c.Req.Context(), inv.InviteeID)
+		if err != nil {
+			return nil, errors.Wrap(err, "get invitee")
+		}
+
+		apiInvs[i] = &OrgInvitation{
+			ID:           inv.ID,
+			Organization: convert.ToOrganization(org),
+			Inviter:      inviter.APIFormat(),
+			Invitee:      invitee.APIFormat(),
+			Created:      inv.Created,
+			Expires:      inv.Expires,
+		}
+	}
+	return apiInvs, nil
+}
+
+func ListOrgInvitations(c *context.APIContext) {
+	invs, err := db.OrgInvitations.ListByOrgID(c.Req.Context(), c.Org.Organization.ID)
+	if err != nil {
+		c.Error(err, "list invitations")
+		return
+	}
+
+	apiInvs, err := toOrgInvitations(c, invs)
+	if err != nil {
+		c.Error(err, "convert invitations")
+		return
+	}
+	c.JSONSuccess(&apiInvs)
+}
+
+func DeleteOrgInvitation(c *context.APIContext) {
+	err := db.OrgInvitations.DeleteByID(c.Req.Context(), c.Org.Organization.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete invitation")
+		return
+	}
+	c.NoContent()
+}
+
+func ListMyOrgInvitations(c *context.APIContext) {
+	invs, err := db.OrgInvitations.ListByInviteeID(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Error(err, "list invitations")
+		return
+	}
+
+	apiInvs, err := toOrgInvitations(c, invs)
+	if err != nil {
+		c.Error(err, "convert invitations")
+		return
+	}
+	c.JSONSuccess(&apiInvs)
+}
+
+func RespondOrgInvitation(c *context.APIContext, form RespondOrgInvitationRequest) {
+	var err error
+	if form.Action == "accept" {
+		err = db.OrgInvitations.Accept(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	} else {
+		err = db.OrgInvitations.Decline(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	}
+	if err != nil {
+		c.NotFoundOrError(err, form.Action+" invitation")
+		return
+	}
+	c.NoContent()
+}
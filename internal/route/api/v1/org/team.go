@@ -5,10 +5,17 @@
 package org
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/go-macaron/binding"
 	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
 
 	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/route/api/v1/convert"
+	"gogs.io/gogs/internal/route/api/v1/user"
 )
 
 func ListTeams(c *context.APIContext) {
@@ -18,9 +25,232 @@ func ListTeams(c *context.APIContext) {
 		return
 	}
 
-	apiTeams := make([]*api.Team, len(org.Teams))
+	apiTeams := make([]*convert.Team, len(org.Teams))
 	for i := range org.Teams {
 		apiTeams[i] = convert.ToTeam(org.Teams[i])
 	}
 	c.JSONSuccess(apiTeams)
 }
+
+// parseTeamPermission returns the access mode of the team permission. It
+// returns false if the permission is not one of "read", "write" and "admin".
+func parseTeamPermission(permission string) (db.AccessMode, bool) {
+	switch permission {
+	case "read":
+		return db.AccessModeRead, true
+	case "write":
+		return db.AccessModeWrite, true
+	case "admin":
+		return db.AccessModeAdmin, true
+	}
+	return db.AccessModeNone, false
+}
+
+// handleTeamError writes the error of creating or updating a team.
+func handleTeamError(c *context.APIContext, err error, msg string) {
+	switch {
+	case db.IsErrTeamAlreadyExist(err),
+		db.IsErrNameNotAllowed(err),
+		db.IsErrReachLimitOfTeams(err):
+		c.ErrorStatus(http.StatusUnprocessableEntity, err)
+	default:
+		c.Error(err, msg)
+	}
+}
+
+// POST /orgs/:orgname/teams
+func CreateTeam(c *context.APIContext, form api.CreateTeamOption) {
+	authorize, ok := parseTeamPermission(form.Permission)
+	if !ok {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("invalid permission %q", form.Permission))
+		return
+	}
+
+	team := &db.Team{
+		OrgID:       c.Org.Organization.ID,
+		Name:        form.Name,
+		Description: form.Description,
+		Authorize:   authorize,
+	}
+	if err := db.NewTeam(team); err != nil {
+		handleTeamError(c, err, "new team")
+		return
+	}
+
+	c.JSON(http.StatusCreated, convert.ToTeam(team))
+}
+
+// GET /teams/:teamid
+func GetTeam(c *context.APIContext) {
+	c.JSONSuccess(convert.ToTeam(c.Org.Team))
+}
+
+// EditTeamRequest is the API message for updating a team. Fields that are not
+// set are left unchanged.
+type EditTeamRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Permission  *string `json:"permission"`
+}
+
+// PATCH /teams/:teamid
+func EditTeam(c *context.APIContext, r EditTeamRequest) {
+	team := c.Org.Team
+	authChanged := false
+	if r.Name != nil && *r.Name != team.Name {
+		// The name is how the owner team is recognized
+		if team.IsOwnerTeam() {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("cannot rename the owner team"))
+			return
+		} else if *r.Name == "" || len(*r.Name) > 30 || binding.AlphaDashDotPattern.MatchString(*r.Name) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("invalid team name %q", *r.Name))
+			return
+		}
+		team.Name = *r.Name
+	}
+	if r.Description != nil {
+		team.Description = *r.Description
+	}
+	if r.Permission != nil {
+		authorize, ok := parseTeamPermission(*r.Permission)
+		if !ok {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("invalid permission %q", *r.Permission))
+			return
+		}
+
+		if authorize != team.Authorize {
+			if team.IsOwnerTeam() {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("cannot change permission of the owner team"))
+				return
+			}
+			team.Authorize = authorize
+			authChanged = true
+		}
+	}
+
+	if err := db.UpdateTeam(team, authChanged); err != nil {
+		handleTeamError(c, err, "update team")
+		return
+	}
+
+	c.JSONSuccess(convert.ToTeam(team))
+}
+
+// DELETE /teams/:teamid
+func DeleteTeam(c *context.APIContext) {
+	if err := db.DeleteTeam(c.Org.Team); err != nil {
+		if db.IsErrCannotDeleteOwnerTeam(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "delete team")
+		}
+		return
+	}
+
+	c.NoContent()
+}
+
+// GET /teams/:teamid/members
+func ListTeamMembers(c *context.APIContext) {
+	team := c.Org.Team
+	if err := team.GetMembers(); err != nil {
+		c.Error(err, "get team members")
+		return
+	}
+
+	apiMembers := make([]*api.User, len(team.Members))
+	for i := range team.Members {
+		apiMembers[i] = team.Members[i].APIFormat()
+	}
+	c.JSONSuccess(apiMembers)
+}
+
+// PUT /teams/:teamid/members/:username
+func AddTeamMember(c *context.APIContext) {
+	u := user.GetUserByParams(c)
+	if c.Written() {
+		return
+	} else if u.IsOrganization() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("%q is an organization", u.Name))
+		return
+	} else if c.Org.Organization.RequireMemberInvitation && !c.Org.Organization.IsOrgMember(u.ID) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("%q needs to accept the invitation to the organization first", u.Name))
+		return
+	}
+
+	if err := c.Org.Team.AddMember(c.User, u.ID); err != nil {
+		if db.IsErrReachLimitOfTeamMembers(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "add member")
+		}
+		return
+	}
+
+	c.NoContent()
+}
+
+// DELETE /teams/:teamid/members/:username
+func RemoveTeamMember(c *context.APIContext) {
+	u := user.GetUserByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if err := c.Org.Team.RemoveMember(c.User, u.ID); err != nil {
+		if db.IsErrLastOrgOwner(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "remove member")
+		}
+		return
+	}
+
+	c.NoContent()
+}
+
+// teamRepositoryByParams returns the repository of the organization of the team
+// that is presented in URL parameters.
+func teamRepositoryByParams(c *context.APIContext) *db.Repository {
+	if !strings.EqualFold(c.Params(":owner"), c.Org.Organization.Name) {
+		c.NotFound()
+		return nil
+	}
+
+	repo, err := db.GetRepositoryByName(c.Org.Team.OrgID, c.Params(":reponame"))
+	if err != nil {
+		c.NotFoundOrError(err, "get repository by name")
+		return nil
+	}
+	return repo
+}
+
+// PUT /teams/:teamid/repos/:owner/:reponame
+func AddTeamRepository(c *context.APIContext) {
+	repo := teamRepositoryByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if err := c.Org.Team.AddRepository(repo); err != nil {
+		c.Error(err, "add repository")
+		return
+	}
+
+	c.NoContent()
+}
+
+// DELETE /teams/:teamid/repos/:owner/:reponame
+func RemoveTeamRepository(c *context.APIContext) {
+	repo := teamRepositoryByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if err := c.Org.Team.RemoveRepository(repo.ID); err != nil {
+		c.Error(err, "remove repository")
+		return
+	}
+
+	c.NoContent()
+}
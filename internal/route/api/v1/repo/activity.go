@@ -0,0 +1,77 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// listActivities responds with a page of activities matching given options
+// along with filters from the query, which are "type" (can be given multiple
+// times), "since" and "until" (in RFC 3339 format), "page" and "limit".
+func listActivities(c *context.APIContext, opts db.ListActivitiesOptions) {
+	var err error
+	opts.OpTypes, err = convert.ToActionTypes(c.QueryStrings("type"))
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		return
+	}
+	for name, t := range map[string]*time.Time{"since": &opts.Since, "until": &opts.Until} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		*t, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("invalid %q: %v", name, err))
+			return
+		}
+	}
+	opts.Page = c.QueryInt("page")
+	opts.PageSize = convert.ToCorrectPageSize(c.QueryInt("limit"))
+
+	actions, err := db.Actions.ListActivities(c.Req.Context(), opts)
+	if err != nil {
+		c.Error(err, "list activities")
+		return
+	}
+
+	activities := make([]*convert.Activity, len(actions))
+	for i := range actions {
+		activities[i] = convert.ToActivity(actions[i])
+	}
+	c.JSONSuccess(&activities)
+}
+
+func ListRepoActivities(c *context.APIContext) {
+	listActivities(c, db.ListActivitiesOptions{RepoID: c.Repo.Repository.ID})
+}
+
+func ListOrgActivities(c *context.APIContext) {
+	listActivities(c, db.ListActivitiesOptions{
+		OrgID:    c.Org.Organization.ID,
+		ViewerID: c.UserID(),
+	})
+}
+
+func ListUserActivities(c *context.APIContext) {
+	u, err := db.Users.GetByUsername(c.Req.Context(), c.Params(":username"))
+	if err != nil {
+		c.NotFoundOrError(err, "get user by name")
+		return
+	}
+
+	listActivities(c, db.ListActivitiesOptions{
+		ActUserID:  u.ID,
+		OnlyPublic: true,
+	})
+}
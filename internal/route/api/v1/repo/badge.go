@@ -0,0 +1,150 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/sync"
+)
+
+// badgeCacheTTL is the time to live of cached badge counts.
+const badgeCacheTTL = time.Minute
+
+var badgeCache = sync.NewTTLCache()
+
+type badgeKind struct {
+	label string
+	color string
+	count func(repo *db.Repository) int
+}
+
+var badgeKinds = map[string]badgeKind{
+	"issues": {
+		label: "issues",
+		color: "#007ec6",
+		count: func(repo *db.Repository) int { return repo.NumIssues },
+	},
+	"open-issues": {
+		label: "open issues",
+		color: "#dfb317",
+		count: func(repo *db.Repository) int { return repo.NumIssues - repo.NumClosedIssues },
+	},
+	"closed-issues": {
+		label: "closed issues",
+		color: "#4c1",
+		count: func(repo *db.Repository) int { return repo.NumClosedIssues },
+	},
+	"stars": {
+		label: "stars",
+		color: "#007ec6",
+		count: func(repo *db.Repository) int { return repo.NumStars },
+	},
+	"forks": {
+		label: "forks",
+		color: "#007ec6",
+		count: func(repo *db.Repository) int { return repo.NumForks },
+	},
+}
+
+// badgeCount returns the count of the badge kind for the repository, which is
+// cached for badgeCacheTTL.
+func badgeCount(repo *db.Repository, name string, kind badgeKind) int {
+	key := fmt.Sprintf("%d:%s", repo.ID, name)
+	if v, ok := badgeCache.Get(key); ok {
+		return v.(int)
+	}
+
+	count := kind.count(repo)
+	badgeCache.Set(key, count, badgeCacheTTL)
+	return count
+}
+
+// badgeTextWidth returns the approximate width in pixels of the text rendered
+// in 11px Verdana.
+func badgeTextWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("fijlrt ", r):
+			width += 4
+		case strings.ContainsRune("mw", r):
+			width += 10
+		default:
+			width += 7
+		}
+	}
+	return width
+}
+
+const badgeSVG = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">` +
+	`<title>%[4]s: %[5]s</title>` +
+	`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` +
+	`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>` +
+	`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>` +
+	`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` +
+	`<text x="%[7]d" y="14">%[4]s</text><text x="%[8]d" y="14">%[5]s</text></g></svg>`
+
+// renderBadgeSVG renders a flat badge that both parts are scaled to the width
+// of their text.
+func renderBadgeSVG(label, message, color string) string {
+	labelWidth := badgeTextWidth(label) + 10
+	messageWidth := badgeTextWidth(message) + 10
+	return fmt.Sprintf(badgeSVG,
+		labelWidth+messageWidth, labelWidth, messageWidth,
+		label, message, color,
+		labelWidth/2, labelWidth+messageWidth/2,
+	)
+}
+
+// ShieldsEndpoint is the response of a badge in the format of the shields.io
+// endpoint badge.
+type ShieldsEndpoint struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// GET /repos/:username/:reponame/badge/:badge
+func GetBadge(c *context.APIContext) {
+	badge := c.Params(":badge")
+	ext := path.Ext(badge)
+	name := strings.TrimSuffix(badge, ext)
+	kind, ok := badgeKinds[name]
+	if !ok || (ext != ".svg" && ext != ".json") {
+		c.NotFound()
+		return
+	}
+
+	message := strconv.Itoa(badgeCount(c.Repo.Repository, name, kind))
+
+	cacheControl := "public"
+	if c.Repo.Repository.IsPrivate {
+		cacheControl = "private"
+	}
+	c.Header().Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheControl, int(badgeCacheTTL.Seconds())))
+
+	if ext == ".json" {
+		c.JSONSuccess(&ShieldsEndpoint{
+			SchemaVersion: 1,
+			Label:         kind.label,
+			Message:       message,
+			Color:         strings.TrimPrefix(kind.color, "#"),
+		})
+		return
+	}
+
+	c.Header().Set("Content-Type", "image/svg+xml")
+	c.Status(http.StatusOK)
+	_, _ = c.Write([]byte(renderBadgeSVG(kind.label, message, kind.color)))
+}
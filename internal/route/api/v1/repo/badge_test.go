@@ -0,0 +1,48 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogs.io/gogs/internal/db"
+)
+
+func TestBadgeCount(t *testing.T) {
+	repo := &db.Repository{
+		ID:              1,
+		NumIssues:       10,
+		NumClosedIssues: 4,
+		NumStars:        7,
+		NumForks:        2,
+	}
+	badgeCache.Reset()
+	t.Cleanup(badgeCache.Reset)
+
+	want := map[string]int{
+		"issues":        10,
+		"open-issues":   6,
+		"closed-issues": 4,
+		"stars":         7,
+		"forks":         2,
+	}
+	for name, count := range want {
+		assert.Equal(t, count, badgeCount(repo, name, badgeKinds[name]), name)
+	}
+
+	// Counts are cached for a while.
+	repo.NumStars = 8
+	assert.Equal(t, 7, badgeCount(repo, "stars", badgeKinds["stars"]))
+}
+
+func TestRenderBadgeSVG(t *testing.T) {
+	narrow := renderBadgeSVG("issues", "1", "#007ec6")
+	wide := renderBadgeSVG("issues", "12345", "#007ec6")
+	assert.Contains(t, narrow, `width="66"`)
+	assert.Contains(t, wide, `width="94"`)
+	assert.Contains(t, wide, `<text x="71" y="14">12345</text>`)
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/gitutil"
+	"gogs.io/gogs/internal/tool"
+)
+
+// BlameCommit is the API message of a commit that lines are attributed to.
+type BlameCommit struct {
+	SHA         string    `json:"sha"`
+	AuthorName  string    `json:"author_name"`
+	AuthorEmail string    `json:"author_email"`
+	AuthorTime  time.Time `json:"author_time"`
+	Summary     string    `json:"summary"`
+}
+
+// BlameLine is the API message of a line of a blamed file.
+type BlameLine struct {
+	Line         int    `json:"line"`
+	OriginalLine int    `json:"original_line"`
+	SHA          string `json:"sha"`
+	Content      string `json:"content"`
+}
+
+// Blame is the API message of blame results of a range of lines of a file.
+type Blame struct {
+	SHA        string                  `json:"sha"`
+	Path       string                  `json:"path"`
+	TotalLines int                     `json:"total_lines"`
+	Lines      []*BlameLine            `json:"lines"`
+	Commits    map[string]*BlameCommit `json:"commits"`
+}
+
+// GET /repos/:username/:reponame/blame/:ref/*
+func GetBlame(c *context.APIContext) {
+	if c.Repo.Repository.IsBare || c.Repo.Commit == nil {
+		c.NotFound()
+		return
+	}
+
+	blob, err := c.Repo.Commit.Blob(c.Repo.TreePath)
+	if err != nil {
+		c.NotFoundOrError(gitutil.NewError(err), "get blob")
+		return
+	}
+	if blob.Size() >= conf.UI.MaxDisplayFileSize {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("File is too large to blame."))
+		return
+	}
+	p, err := blob.Bytes()
+	if err != nil {
+		c.Error(err, "read blob")
+		return
+	}
+	if !tool.IsTextFile(p) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Binary files have no blame."))
+		return
+	}
+
+	lines, err := gitutil.BlameFile(
+		c.Repo.GitRepo.Path(),
+		c.Repo.Commit.ID.String(),
+		c.Repo.TreePath,
+		time.Duration(conf.Git.Timeout.Blame)*time.Second,
+	)
+	if err != nil {
+		c.Error(err, "blame file")
+		return
+	}
+
+	blame := &Blame{
+		SHA:        c.Repo.Commit.ID.String(),
+		Path:       c.Repo.TreePath,
+		TotalLines: len(lines),
+		Lines:      []*BlameLine{},
+		Commits:    make(map[string]*BlameCommit),
+	}
+	for _, l := range gitutil.BlameRange(lines, c.QueryInt("start"), c.QueryInt("end")) {
+		blame.Lines = append(blame.Lines, &BlameLine{
+			Line:         l.Number,
+			OriginalLine: l.OriginalNumber,
+			SHA:          l.Commit.ID,
+			Content:      l.Content,
+		})
+		if _, ok := blame.Commits[l.Commit.ID]; !ok {
+			blame.Commits[l.Commit.ID] = &BlameCommit{
+				SHA:         l.Commit.ID,
+				AuthorName:  l.Commit.AuthorName,
+				AuthorEmail: l.Commit.AuthorEmail,
+				AuthorTime:  l.Commit.AuthorTime,
+				Summary:     l.Commit.Summary,
+			}
+		}
+	}
+	c.JSONSuccess(blame)
+}
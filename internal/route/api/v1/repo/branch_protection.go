@@ -0,0 +1,308 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/tool"
+)
+
+// BranchProtection is the API message of a branch protection rule.
+type BranchProtection struct {
+	Branch                  string   `json:"branch"`
+	RequirePullRequest      bool     `json:"require_pull_request"`
+	RequireLinearHistory    bool     `json:"require_linear_history"`
+	RequireUpToDate         bool     `json:"require_up_to_date"`
+	RequireCodeOwnerReviews bool     `json:"require_code_owner_reviews"`
+	RequiredApprovals       int      `json:"required_approvals"`
+	RequiredStatusChecks    []string `json:"required_status_checks"`
+	EnableWhitelist         bool     `json:"enable_whitelist"`
+	WhitelistUsers          []string `json:"whitelist_users"`
+	WhitelistTeams          []string `json:"whitelist_teams"`
+}
+
+// CreateBranchProtectionRequest is the API message for protecting a branch.
+type CreateBranchProtectionRequest struct {
+	Branch                  string   `json:"branch" binding:"Required"`
+	RequirePullRequest      bool     `json:"require_pull_request"`
+	RequireLinearHistory    bool     `json:"require_linear_history"`
+	RequireUpToDate         bool     `json:"require_up_to_date"`
+	RequireCodeOwnerReviews bool     `json:"require_code_owner_reviews"`
+	RequiredApprovals       int      `json:"required_approvals"`
+	RequiredStatusChecks    []string `json:"required_status_checks"`
+	EnableWhitelist         bool     `json:"enable_whitelist"`
+	WhitelistUsers          []string `json:"whitelist_users"`
+	WhitelistTeams          []string `json:"whitelist_teams"`
+}
+
+// EditBranchProtectionRequest is the API message for editing a branch
+// protection rule. Fields that are not set are left unchanged.
+type EditBranchProtectionRequest struct {
+	RequirePullRequest      *bool     `json:"require_pull_request"`
+	RequireLinearHistory    *bool     `json:"require_linear_history"`
+	RequireUpToDate         *bool     `json:"require_up_to_date"`
+	RequireCodeOwnerReviews *bool     `json:"require_code_owner_reviews"`
+	RequiredApprovals       *int      `json:"required_approvals"`
+	RequiredStatusChecks    *[]string `json:"required_status_checks"`
+	EnableWhitelist         *bool     `json:"enable_whitelist"`
+	WhitelistUsers          *[]string `json:"whitelist_users"`
+	WhitelistTeams          *[]string `json:"whitelist_teams"`
+}
+
+func toBranchProtection(c *context.APIContext, protectBranch *db.ProtectBranch) (*BranchProtection, error) {
+	p := &BranchProtection{
+		Branch:                  protectBranch.Name,
+		RequirePullRequest:      protectBranch.RequirePullRequest,
+		RequireLinearHistory:    protectBranch.RequireLinearHistory,
+		RequireUpToDate:         protectBranch.RequireUpToDate,
+		RequireCodeOwnerReviews: protectBranch.RequireCodeOwnerReviews,
+		RequiredApprovals:       protectBranch.RequiredApprovals,
+		RequiredStatusChecks:    protectBranch.StatusCheckContexts(),
+		EnableWhitelist:         protectBranch.EnableWhitelist,
+		WhitelistUsers:          []string{},
+		WhitelistTeams:          []string{},
+	}
+	for _, id := range tool.StringsToInt64s(strings.Split(protectBranch.WhitelistUserIDs, ",")) {
+		if id <= 0 {
+			continue
+		}
+		user, err := db.Users.GetByID(c.Req.Context(), id)
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "get user by ID")
+		}
+		p.WhitelistUsers = append(p.WhitelistUsers, user.Name)
+	}
+	for _, id := range tool.StringsToInt64s(strings.Split(protectBranch.WhitelistTeamIDs, ",")) {
+		if id <= 0 {
+			continue
+		}
+		team, err := db.GetTeamByID(id)
+		if err != nil {
+			if db.IsErrTeamNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "get team by ID")
+		}
+		p.WhitelistTeams = append(p.WhitelistTeams, team.Name)
+	}
+	return p, nil
+}
+
+// toWhitelistUserIDs resolves the usernames to a comma-separated list of user
+// IDs. It renders 422 and returns false when any of them does not exist.
+func toWhitelistUserIDs(c *context.APIContext, usernames []string) (string, bool) {
+	ids := make([]string, 0, len(usernames))
+	for _, name := range usernames {
+		user, err := db.Users.GetByUsername(c.Req.Context(), name)
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("User %q does not exist.", name))
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return "", false
+		}
+		ids = append(ids, strconv.FormatInt(user.ID, 10))
+	}
+	return strings.Join(ids, ","), true
+}
+
+// toWhitelistTeamIDs resolves the team names to a comma-separated list of team
+// IDs. It renders 422 and returns false when any of them does not exist.
+func toWhitelistTeamIDs(c *context.APIContext, teamNames []string) (string, bool) {
+	if len(teamNames) > 0 && !c.Repo.Owner.IsOrganization() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Teams can only be whitelisted in organization repositories."))
+		return "", false
+	}
+
+	ids := make([]string, 0, len(teamNames))
+	for _, name := range teamNames {
+		team, err := c.Repo.Owner.GetTeam(name)
+		if err != nil {
+			if db.IsErrTeamNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Team %q does not exist.", name))
+			} else {
+				c.Error(err, "get team by name")
+			}
+			return "", false
+		}
+		ids = append(ids, strconv.FormatInt(team.ID, 10))
+	}
+	return strings.Join(ids, ","), true
+}
+
+func saveBranchProtection(c *context.APIContext, protectBranch *db.ProtectBranch, status int) {
+	err := db.ProtectBranches.Save(c.Req.Context(), c.Repo.Repository, protectBranch)
+	if err != nil {
+		if db.IsErrInvalidProtectBranchWhitelist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Whitelisted users and teams must have write access to the repository."))
+		} else if db.IsErrInvalidProtectBranchRequiredApprovals(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Required approvals must not be negative."))
+		} else {
+			c.Error(err, "save protect branch")
+		}
+		return
+	}
+
+	p, err := toBranchProtection(c, protectBranch)
+	if err != nil {
+		c.Error(err, "convert branch protection")
+		return
+	}
+	c.JSON(status, p)
+}
+
+// GET /repos/:username/:reponame/branch_protections
+func ListBranchProtections(c *context.APIContext) {
+	protectBranches, err := db.ProtectBranches.List(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list protect branches")
+		return
+	}
+
+	apiProtections := make([]*BranchProtection, len(protectBranches))
+	for i := range protectBranches {
+		apiProtections[i], err = toBranchProtection(c, protectBranches[i])
+		if err != nil {
+			c.Error(err, "convert branch protection")
+			return
+		}
+	}
+	c.JSONSuccess(&apiProtections)
+}
+
+// GET /repos/:username/:reponame/branch_protections/*
+func GetBranchProtection(c *context.APIContext) {
+	protectBranch, err := db.ProtectBranches.GetByName(c.Req.Context(), c.Repo.Repository.ID, c.Params("*"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect branch by name")
+		return
+	} else if !protectBranch.Protected {
+		c.NotFound()
+		return
+	}
+
+	p, err := toBranchProtection(c, protectBranch)
+	if err != nil {
+		c.Error(err, "convert branch protection")
+		return
+	}
+	c.JSONSuccess(p)
+}
+
+// POST /repos/:username/:reponame/branch_protections
+func CreateBranchProtection(c *context.APIContext, r CreateBranchProtectionRequest) {
+	if _, err := c.Repo.Repository.GetBranch(r.Branch); err != nil {
+		if db.IsErrBranchNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Branch %q does not exist.", r.Branch))
+		} else {
+			c.Error(err, "get branch")
+		}
+		return
+	}
+
+	// The options of a branch are kept when it becomes unprotected, reuse them.
+	protectBranch, err := db.ProtectBranches.GetByName(c.Req.Context(), c.Repo.Repository.ID, r.Branch)
+	if err != nil {
+		if !db.IsErrProtectBranchNotExist(err) {
+			c.Error(err, "get protect branch by name")
+			return
+		}
+		protectBranch = &db.ProtectBranch{Name: r.Branch}
+	} else if protectBranch.Protected {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Branch %q is already protected.", r.Branch))
+		return
+	}
+
+	userIDs, ok := toWhitelistUserIDs(c, r.WhitelistUsers)
+	if !ok {
+		return
+	}
+	teamIDs, ok := toWhitelistTeamIDs(c, r.WhitelistTeams)
+	if !ok {
+		return
+	}
+	protectBranch.Protected = true
+	protectBranch.RequirePullRequest = r.RequirePullRequest
+	protectBranch.RequireLinearHistory = r.RequireLinearHistory
+	protectBranch.RequireUpToDate = r.RequireUpToDate
+	protectBranch.RequireCodeOwnerReviews = r.RequireCodeOwnerReviews
+	protectBranch.RequiredApprovals = r.RequiredApprovals
+	protectBranch.RequiredStatusChecks = strings.Join(r.RequiredStatusChecks, ",")
+	protectBranch.EnableWhitelist = r.EnableWhitelist
+	protectBranch.WhitelistUserIDs = userIDs
+	protectBranch.WhitelistTeamIDs = teamIDs
+	saveBranchProtection(c, protectBranch, http.StatusCreated)
+}
+
+// PATCH /repos/:username/:reponame/branch_protections/*
+func EditBranchProtection(c *context.APIContext, r EditBranchProtectionRequest) {
+	protectBranch, err := db.ProtectBranches.GetByName(c.Req.Context(), c.Repo.Repository.ID, c.Params("*"))
+	if err != nil {
+		c.NotFoundOrError(err, "get protect branch by name")
+		return
+	} else if !protectBranch.Protected {
+		c.NotFound()
+		return
+	}
+
+	if r.RequirePullRequest != nil {
+		protectBranch.RequirePullRequest = *r.RequirePullRequest
+	}
+	if r.RequireLinearHistory != nil {
+		protectBranch.RequireLinearHistory = *r.RequireLinearHistory
+	}
+	if r.RequireUpToDate != nil {
+		protectBranch.RequireUpToDate = *r.RequireUpToDate
+	}
+	if r.RequireCodeOwnerReviews != nil {
+		protectBranch.RequireCodeOwnerReviews = *r.RequireCodeOwnerReviews
+	}
+	if r.RequiredApprovals != nil {
+		protectBranch.RequiredApprovals = *r.RequiredApprovals
+	}
+	if r.RequiredStatusChecks != nil {
+		protectBranch.RequiredStatusChecks = strings.Join(*r.RequiredStatusChecks, ",")
+	}
+	if r.EnableWhitelist != nil {
+		protectBranch.EnableWhitelist = *r.EnableWhitelist
+	}
+	if r.WhitelistUsers != nil {
+		userIDs, ok := toWhitelistUserIDs(c, *r.WhitelistUsers)
+		if !ok {
+			return
+		}
+		protectBranch.WhitelistUserIDs = userIDs
+	}
+	if r.WhitelistTeams != nil {
+		teamIDs, ok := toWhitelistTeamIDs(c, *r.WhitelistTeams)
+		if !ok {
+			return
+		}
+		protectBranch.WhitelistTeamIDs = teamIDs
+	}
+	saveBranchProtection(c, protectBranch, http.StatusOK)
+}
+
+// DELETE /repos/:username/:reponame/branch_protections/*
+func DeleteBranchProtection(c *context.APIContext) {
+	err := db.ProtectBranches.Delete(c.Req.Context(), c.Repo.Repository.ID, c.Params("*"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete protect branch")
+		return
+	}
+
+	c.NoContent()
+}
@@ -5,10 +5,12 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 
 	api "github.com/gogs/go-gogs-client"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 )
@@ -38,16 +40,33 @@ func AddCollaborator(c *context.APIContext, form api.AddCollaboratorOption) {
 		return
 	}
 
+	if conf.Repository.RequireCollaboratorInvitation && !c.Repo.Repository.IsCollaborator(collaborator.ID) {
+		mode := db.AccessModeWrite
+		if form.Permission != nil {
+			mode = db.ParseAccessMode(*form.Permission)
+		}
+		inviteCollaborator(c, collaborator, mode)
+		return
+	}
+
 	if err := c.Repo.Repository.AddCollaborator(collaborator); err != nil {
-		c.Error(err, "add collaborator")
+		if db.IsErrReachLimitOfCollaborators(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "add collaborator")
+		}
 		return
 	}
+	db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorAdd, c.Repo.Repository.ID, c.Repo.Repository.FullName(), "collaborator: "+collaborator.Name)
 
 	if form.Permission != nil {
-		if err := c.Repo.Repository.ChangeCollaborationAccessMode(collaborator.ID, db.ParseAccessMode(*form.Permission)); err != nil {
+		mode := db.ParseAccessMode(*form.Permission)
+		if err := c.Repo.Repository.ChangeCollaborationAccessMode(collaborator.ID, mode); err != nil {
 			c.Error(err, "change collaboration access mode")
 			return
 		}
+		db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorEdit, c.Repo.Repository.ID, c.Repo.Repository.FullName(),
+			fmt.Sprintf("collaborator: %s, permission: %s", collaborator.Name, mode))
 	}
 
 	c.NoContent()
@@ -86,6 +105,7 @@ func DeleteCollaborator(c *context.APIContext) {
 		c.Error(err, "delete collaboration")
 		return
 	}
+	db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorRemove, c.Repo.Repository.ID, c.Repo.Repository.FullName(), "collaborator: "+collaborator.Name)
 
 	c.NoContent()
 }
@@ -0,0 +1,109 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// CreateCommitStatusRequest is the API message for creating a commit status.
+type CreateCommitStatusRequest struct {
+	State       string `json:"state" binding:"Required"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+	Context     string `json:"context" binding:"MaxSize(255)"`
+}
+
+// getStatusCommit returns the commit by the ":sha" parameter, which can be any
+// revision. It renders 404 and returns nil when not found.
+func getStatusCommit(c *context.APIContext) *git.Commit {
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
+	if err != nil {
+		c.Error(err, "open repository")
+		return nil
+	}
+	commit, err := gitRepo.CatFileCommit(c.Params(":sha"))
+	if err != nil {
+		c.NotFound()
+		return nil
+	}
+	return commit
+}
+
+// GET /repos/:username/:reponame/statuses/:sha
+func ListCommitStatuses(c *context.APIContext) {
+	commit := getStatusCommit(c)
+	if c.Written() {
+		return
+	}
+
+	statuses, err := db.CommitStatuses.ListByCommit(c.Req.Context(), c.Repo.Repository.ID, commit.ID.String())
+	if err != nil {
+		c.Error(err, "list commit statuses")
+		return
+	}
+
+	creators := make(map[int64]*db.User)
+	apiStatuses := make([]*db.APICommitStatus, len(statuses))
+	for i := range statuses {
+		creator, ok := creators[statuses[i].CreatorID]
+		if !ok {
+			creator, err = db.Users.GetByID(c.Req.Context(), statuses[i].CreatorID)
+			if err != nil {
+				if !db.IsErrUserNotExist(err) {
+					c.Error(err, "get creator")
+					return
+				}
+				creator = db.NewGhostUser()
+			}
+			creators[statuses[i].CreatorID] = creator
+		}
+		apiStatuses[i] = statuses[i].APIFormat(creator)
+	}
+	c.JSONSuccess(&apiStatuses)
+}
+
+// POST /repos/:username/:reponame/statuses/:sha
+func CreateCommitStatus(c *context.APIContext, r CreateCommitStatusRequest) {
+	state := db.CommitStatusState(r.State)
+	if !state.IsValid() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("State %q is not valid.", r.State))
+		return
+	}
+
+	commit := getStatusCommit(c)
+	if c.Written() {
+		return
+	}
+
+	status, err := db.CommitStatuses.Create(
+		c.Req.Context(),
+		c.Repo.Repository.ID,
+		c.User.ID,
+		commit.ID.String(),
+		db.CreateCommitStatusOptions{
+			Context:     r.Context,
+			State:       state,
+			TargetURL:   r.TargetURL,
+			Description: r.Description,
+		},
+	)
+	if err != nil {
+		c.Error(err, "create commit status")
+		return
+	}
+
+	// The status may be the last requirement for auto-merge.
+	if state == db.CommitStatusSuccess {
+		db.UpdateAutoMergeByCommit(c.Repo.Repository.ID, status.CommitID)
+	}
+	c.JSON(http.StatusCreated, status.APIFormat(c.User))
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"time"
 
 	"github.com/gogs/git-module"
 	"github.com/pkg/errors"
@@ -170,69 +171,147 @@ func GetContents(c *context.APIContext) {
 	c.JSONSuccess(contents)
 }
 
+// contentsIdentity is the API message for the author or committer of a file
+// change.
+type contentsIdentity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (id *contentsIdentity) toSignature() *git.Signature {
+	if id == nil || id.Name == "" || id.Email == "" {
+		return nil
+	}
+	return &git.Signature{
+		Name:  id.Name,
+		Email: id.Email,
+		When:  time.Now(),
+	}
+}
+
 // PutContentsRequest is the API message for creating or updating a file.
 type PutContentsRequest struct {
 	Message string `json:"message" binding:"Required"`
 	Content string `json:"content" binding:"Required"`
 	Branch  string `json:"branch"`
+	// The blob SHA of the file being replaced, the request is rejected if the
+	// file has been changed since.
+	Sha       string            `json:"sha"`
+	Author    *contentsIdentity `json:"author"`
+	Committer *contentsIdentity `json:"committer"`
+}
+
+// POST /repos/:username/:reponame/contents/*
+func CreateContents(c *context.APIContext, r PutContentsRequest) {
+	commitContents(c, r.Message, r.Branch, r.Sha, r.Author, r.Committer, &r.Content, true)
 }
 
 // PUT /repos/:username/:reponame/contents/*
 func PutContents(c *context.APIContext, r PutContentsRequest) {
-	content, err := base64.StdEncoding.DecodeString(r.Content)
-	if err != nil {
-		c.Error(err, "decoding base64")
-		return
+	commitContents(c, r.Message, r.Branch, r.Sha, r.Author, r.Committer, &r.Content, false)
+}
+
+// DeleteContentsRequest is the API message for deleting a file.
+type DeleteContentsRequest struct {
+	Message   string            `json:"message" binding:"Required"`
+	Branch    string            `json:"branch"`
+	Sha       string            `json:"sha" binding:"Required"`
+	Author    *contentsIdentity `json:"author"`
+	Committer *contentsIdentity `json:"committer"`
+}
+
+// DELETE /repos/:username/:reponame/contents/*
+func DeleteContents(c *context.APIContext, r DeleteContentsRequest) {
+	commitContents(c, r.Message, r.Branch, r.Sha, r.Author, r.Committer, nil, false)
+}
+
+// commitContents commits the change of the file to the branch and responds with
+// the new file (if not deleted) and the commit. A nil content means to delete the
+// file.
+func commitContents(
+	c *context.APIContext,
+	message, branch, sha string,
+	author, committer *contentsIdentity,
+	encodedContent *string,
+	isNewFile bool,
+) {
+	var content []byte
+	if encodedContent != nil {
+		var err error
+		content, err = base64.StdEncoding.DecodeString(*encodedContent)
+		if err != nil {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Wrap(err, "decoding base64"))
+			return
+		}
 	}
 
-	if r.Branch == "" {
-		r.Branch = c.Repo.Repository.DefaultBranch
+	if branch == "" {
+		branch = c.Repo.Repository.DefaultBranch
 	}
 	treePath := c.Params("*")
-	err = c.Repo.Repository.UpdateRepoFile(
+	commitID, err := c.Repo.Repository.CommitRepoFile(
 		c.User,
-		db.UpdateRepoFileOptions{
-			OldBranch:   c.Repo.Repository.DefaultBranch,
-			NewBranch:   r.Branch,
-			OldTreeName: treePath,
-			NewTreeName: treePath,
-			Message:     r.Message,
-			Content:     string(content),
+		db.CommitRepoFileOptions{
+			Branch:    branch,
+			TreePath:  treePath,
+			Content:   content,
+			IsNewFile: isNewFile,
+			IsDelete:  encodedContent == nil,
+			SHA:       sha,
+			Author:    author.toSignature(),
+			Committer: committer.toSignature(),
+			Message:   message,
 		},
 	)
 	if err != nil {
-		c.Error(err, "updating repository file")
+		switch {
+		case db.IsErrRepoFileSHAMismatch(err), errors.Cause(err) == gitutil.ErrRefChanged:
+			c.ErrorStatus(http.StatusConflict, err)
+		case db.IsErrRepoFileAlreadyExist(err), db.IsErrRepoFilePathInvalid(err):
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		case db.IsErrBranchWriteNotAllowed(err), errors.Cause(err) == gitutil.ErrPushRejected:
+			c.ErrorStatus(http.StatusForbidden, err)
+		default:
+			c.NotFoundOrError(err, "commit repository file")
+		}
 		return
 	}
 
-	repoPath := repoutil.RepositoryPath(c.Params(":username"), c.Params(":reponame"))
-	gitRepo, err := git.Open(repoPath)
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
 	if err != nil {
 		c.Error(err, "open repository")
 		return
 	}
 
-	commit, err := gitRepo.CatFileCommit(r.Branch)
+	commit, err := gitRepo.CatFileCommit(commitID)
 	if err != nil {
 		c.Error(err, "get file commit")
 		return
 	}
 
-	entry, err := commit.TreeEntry(treePath)
+	apiCommit, err := gitCommitToAPICommit(commit, c)
 	if err != nil {
-		c.Error(err, "get tree entry")
+		c.Error(err, "convert to *api.Commit")
+		return
+	}
+
+	if encodedContent == nil {
+		c.JSONSuccess(map[string]any{
+			"content": nil,
+			"commit":  apiCommit,
+		})
 		return
 	}
 
-	apiContent, err := toRepoContent(c, r.Branch, treePath, commit, entry)
+	entry, err := commit.TreeEntry(treePath)
 	if err != nil {
-		c.Error(err, "convert to *repoContent")
+		c.Error(err, "get tree entry")
 		return
 	}
 
-	apiCommit, err := gitCommitToAPICommit(commit, c)
+	apiContent, err := toRepoContent(c, branch, treePath, commit, entry)
 	if err != nil {
-		c.Error(err, "convert to *api.Commit")
+		c.Error(err, "convert to *repoContent")
 		return
 	}
 
@@ -0,0 +1,70 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// DefaultPullAssignee is the API message of the user who is assigned new pull
+// requests of a repository opened without an assignee.
+type DefaultPullAssignee struct {
+	// The username of the default assignee, empty means no default assignee.
+	Username string `json:"username"`
+}
+
+func toDefaultPullAssignee(c *context.APIContext, repo *db.Repository) (*DefaultPullAssignee, error) {
+	if repo.DefaultPullAssigneeID <= 0 {
+		return &DefaultPullAssignee{}, nil
+	}
+
+	u, err := db.Users.GetByID(c.Req.Context(), repo.DefaultPullAssigneeID)
+	if err != nil {
+		if db.IsErrUserNotExist(err) {
+			return &DefaultPullAssignee{}, nil
+		}
+		return nil, err
+	}
+	return &DefaultPullAssignee{Username: u.Name}, nil
+}
+
+// GET /repos/:username/:reponame/default_pull_assignee
+func GetDefaultPullAssignee(c *context.APIContext) {
+	r, err := toDefaultPullAssignee(c, c.Repo.Repository)
+	if err != nil {
+		c.Error(err, "get default pull request assignee")
+		return
+	}
+	c.JSONSuccess(r)
+}
+
+// PUT /repos/:username/:reponame/default_pull_assignee
+func ReplaceDefaultPullAssignee(c *context.APIContext, r DefaultPullAssignee) {
+	repo := c.Repo.Repository
+	err := repo.SetDefaultPullAssignee(c.Req.Context(), strings.TrimSpace(r.Username))
+	if err != nil {
+		if db.IsErrUserNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "set default pull request assignee")
+		}
+		return
+	}
+	if err = db.UpdateRepository(repo, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+
+	resp, err := toDefaultPullAssignee(c, repo)
+	if err != nil {
+		c.Error(err, "get default pull request assignee")
+		return
+	}
+	c.JSONSuccess(resp)
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// DefaultReviewers is the API message of users and teams who are requested to
+// review new pull requests of a repository.
+type DefaultReviewers struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+}
+
+func toDefaultReviewers(c *context.APIContext, repo *db.Repository) (*DefaultReviewers, error) {
+	users, teams, err := repo.DefaultReviewers(c.Req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &DefaultReviewers{
+		Users: make([]string, 0, len(users)),
+		Teams: make([]string, 0, len(teams)),
+	}
+	for _, u := range users {
+		r.Users = append(r.Users, u.Name)
+	}
+	for _, t := range teams {
+		r.Teams = append(r.Teams, t.Name)
+	}
+	return r, nil
+}
+
+// GET /repos/:username/:reponame/default_reviewers
+func GetDefaultReviewers(c *context.APIContext) {
+	r, err := toDefaultReviewers(c, c.Repo.Repository)
+	if err != nil {
+		c.Error(err, "get default reviewers")
+		return
+	}
+	c.JSONSuccess(r)
+}
+
+// PUT /repos/:username/:reponame/default_reviewers
+func ReplaceDefaultReviewers(c *context.APIContext, r DefaultReviewers) {
+	repo := c.Repo.Repository
+	if len(r.Teams) > 0 && !c.Repo.Owner.IsOrganization() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Teams can only be default reviewers of organization repositories."))
+		return
+	}
+
+	err := repo.SetDefaultReviewers(c.Req.Context(), r.Users, r.Teams)
+	if err != nil {
+		if db.IsErrUserNotExist(err) || db.IsErrTeamNotExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "set default reviewers")
+		}
+		return
+	}
+	if err = db.UpdateRepository(repo, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+
+	resp, err := toDefaultReviewers(c, repo)
+	if err != nil {
+		c.Error(err, "get default reviewers")
+		return
+	}
+	c.JSONSuccess(resp)
+}
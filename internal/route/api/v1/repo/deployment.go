@@ -0,0 +1,242 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// CreateDeploymentRequest is the API message for creating a deployment.
+type CreateDeploymentRequest struct {
+	Ref         string          `json:"ref" binding:"Required"`
+	Task        string          `json:"task" binding:"MaxSize(255)"`
+	Environment string          `json:"environment" binding:"MaxSize(255)"`
+	Payload     json.RawMessage `json:"payload"`
+	Description string          `json:"description"`
+}
+
+// CreateDeploymentStatusRequest is the API message for creating a deployment
+// status.
+type CreateDeploymentStatusRequest struct {
+	State       string `json:"state" binding:"Required"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+}
+
+// Environment is the API message of an environment that a repository has been
+// deployed to.
+type Environment struct {
+	Name string `json:"name"`
+}
+
+// deploymentCreators caches creators of deployments and statuses by their IDs,
+// users who no longer exist are replaced by the ghost user.
+type deploymentCreators map[int64]*db.User
+
+func (creators deploymentCreators) get(c *context.APIContext, id int64) (*db.User, error) {
+	if u, ok := creators[id]; ok {
+		return u, nil
+	}
+
+	u, err := db.Users.GetByID(c.Req.Context(), id)
+	if err != nil {
+		if !db.IsErrUserNotExist(err) {
+			return nil, err
+		}
+		u = db.NewGhostUser()
+	}
+	creators[id] = u
+	return u, nil
+}
+
+// getDeployment returns the deployment by the ":id" parameter. It renders 404
+// and returns nil when not found.
+func getDeployment(c *context.APIContext) *db.Deployment {
+	d, err := db.Deployments.GetByID(c.Req.Context(), c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get deployment")
+		return nil
+	}
+	return d
+}
+
+// GET /repos/:username/:reponame/deployments
+func ListDeployments(c *context.APIContext) {
+	ds, err := db.Deployments.List(
+		c.Req.Context(),
+		c.Repo.Repository.ID,
+		db.ListDeploymentsOptions{
+			Ref:         c.Query("ref"),
+			Task:        c.Query("task"),
+			Environment: c.Query("environment"),
+		},
+	)
+	if err != nil {
+		c.Error(err, "list deployments")
+		return
+	}
+
+	creators := make(deploymentCreators)
+	apiDeployments := make([]*db.APIDeployment, len(ds))
+	for i := range ds {
+		creator, err := creators.get(c, ds[i].CreatorID)
+		if err != nil {
+			c.Error(err, "get creator")
+			return
+		}
+		apiDeployments[i] = ds[i].APIFormat(creator)
+	}
+	c.JSONSuccess(&apiDeployments)
+}
+
+// GET /repos/:username/:reponame/deployments/:id
+func GetDeployment(c *context.APIContext) {
+	d := getDeployment(c)
+	if c.Written() {
+		return
+	}
+
+	creator, err := make(deploymentCreators).get(c, d.CreatorID)
+	if err != nil {
+		c.Error(err, "get creator")
+		return
+	}
+	c.JSONSuccess(d.APIFormat(creator))
+}
+
+// POST /repos/:username/:reponame/deployments
+func CreateDeployment(c *context.APIContext, r CreateDeploymentRequest) {
+	if len(r.Payload) > 0 && string(r.Payload) != "null" {
+		var object map[string]any
+		if err := json.Unmarshal(r.Payload, &object); err != nil {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Payload must be a JSON object."))
+			return
+		}
+	} else {
+		r.Payload = nil
+	}
+
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
+	if err != nil {
+		c.Error(err, "open repository")
+		return
+	}
+	commit, err := gitRepo.CatFileCommit(r.Ref)
+	if err != nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Ref %q does not exist.", r.Ref))
+		return
+	}
+
+	d, err := db.Deployments.Create(
+		c.Req.Context(),
+		c.Repo.Repository.ID,
+		c.User.ID,
+		db.CreateDeploymentOptions{
+			Ref:         r.Ref,
+			SHA:         commit.ID.String(),
+			Task:        r.Task,
+			Environment: r.Environment,
+			Payload:     string(r.Payload),
+			Description: r.Description,
+		},
+	)
+	if err != nil {
+		c.Error(err, "create deployment")
+		return
+	}
+	if err = db.PrepareDeploymentWebhooks(c.User, c.Repo.Repository, d); err != nil {
+		log.Error("Failed to prepare deployment webhooks: %v", err)
+	}
+	c.JSON(http.StatusCreated, d.APIFormat(c.User))
+}
+
+// GET /repos/:username/:reponame/deployments/:id/statuses
+func ListDeploymentStatuses(c *context.APIContext) {
+	d := getDeployment(c)
+	if c.Written() {
+		return
+	}
+
+	statuses, err := db.Deployments.ListStatuses(c.Req.Context(), c.Repo.Repository.ID, d.ID)
+	if err != nil {
+		c.Error(err, "list statuses")
+		return
+	}
+
+	creators := make(deploymentCreators)
+	apiStatuses := make([]*db.APIDeploymentStatus, len(statuses))
+	for i := range statuses {
+		creator, err := creators.get(c, statuses[i].CreatorID)
+		if err != nil {
+			c.Error(err, "get creator")
+			return
+		}
+		apiStatuses[i] = statuses[i].APIFormat(creator)
+	}
+	c.JSONSuccess(&apiStatuses)
+}
+
+// POST /repos/:username/:reponame/deployments/:id/statuses
+func CreateDeploymentStatus(c *context.APIContext, r CreateDeploymentStatusRequest) {
+	d := getDeployment(c)
+	if c.Written() {
+		return
+	}
+
+	state := db.DeploymentState(r.State)
+	if !state.IsValid() {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("State %q is not valid.", r.State))
+		return
+	}
+
+	status, err := db.Deployments.CreateStatus(
+		c.Req.Context(),
+		c.Repo.Repository.ID,
+		d.ID,
+		c.User.ID,
+		db.CreateDeploymentStatusOptions{
+			State:       state,
+			TargetURL:   r.TargetURL,
+			Description: r.Description,
+		},
+	)
+	if err != nil {
+		if db.IsErrDeploymentStateTransitionInvalid(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Deployment can't transition from %q to %q.", d.State, state))
+		} else {
+			c.NotFoundOrError(err, "create status")
+		}
+		return
+	}
+
+	d.State = status.State
+	if err = db.PrepareDeploymentStatusWebhooks(c.User, c.Repo.Repository, d, status); err != nil {
+		log.Error("Failed to prepare deployment status webhooks: %v", err)
+	}
+	c.JSON(http.StatusCreated, status.APIFormat(c.User))
+}
+
+// GET /repos/:username/:reponame/environments
+func ListEnvironments(c *context.APIContext) {
+	names, err := db.Deployments.ListEnvironments(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list environments")
+		return
+	}
+
+	environments := make([]*Environment, len(names))
+	for i := range names {
+		environments[i] = &Environment{Name: names[i]}
+	}
+	c.JSONSuccess(&environments)
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// Features is the API message of the toggles of repository features.
+type Features struct {
+	EnableIssues      bool `json:"enable_issues"`
+	AllowPublicIssues bool `json:"allow_public_issues"`
+	IssuesReadOnly    bool `json:"issues_read_only"`
+	EnablePulls       bool `json:"enable_pulls"`
+	EnableWiki        bool `json:"enable_wiki"`
+	EnableReleases    bool `json:"enable_releases"`
+	EnableLFS         bool `json:"enable_lfs"`
+}
+
+// EditFeaturesRequest is the API message for toggling repository features.
+// Fields that are not set are left unchanged.
+type EditFeaturesRequest struct {
+	EnableIssues      *bool `json:"enable_issues"`
+	AllowPublicIssues *bool `json:"allow_public_issues"`
+	IssuesReadOnly    *bool `json:"issues_read_only"`
+	EnablePulls       *bool `json:"enable_pulls"`
+	EnableWiki        *bool `json:"enable_wiki"`
+	EnableReleases    *bool `json:"enable_releases"`
+	EnableLFS         *bool `json:"enable_lfs"`
+}
+
+func toFeatures(repo *db.Repository) *Features {
+	return &Features{
+		EnableIssues:      repo.EnableIssues,
+		AllowPublicIssues: repo.AllowPublicIssues,
+		IssuesReadOnly:    repo.IssuesReadOnly,
+		EnablePulls:       repo.EnablePulls,
+		EnableWiki:        repo.EnableWiki,
+		EnableReleases:    repo.EnableReleases,
+		EnableLFS:         repo.EnableLFS,
+	}
+}
+
+// GET /repos/:username/:reponame/features
+func GetFeatures(c *context.APIContext) {
+	c.JSONSuccess(toFeatures(c.Repo.Repository))
+}
+
+// PATCH /repos/:username/:reponame/features
+func EditFeatures(c *context.APIContext, r EditFeaturesRequest) {
+	repo := c.Repo.Repository
+	if r.EnableIssues != nil {
+		repo.EnableIssues = *r.EnableIssues
+	}
+	if r.AllowPublicIssues != nil {
+		repo.AllowPublicIssues = *r.AllowPublicIssues
+	}
+	if r.IssuesReadOnly != nil {
+		repo.IssuesReadOnly = *r.IssuesReadOnly
+	}
+	if r.EnablePulls != nil {
+		repo.EnablePulls = *r.EnablePulls
+	}
+	if r.EnableWiki != nil {
+		repo.EnableWiki = *r.EnableWiki
+	}
+	if r.EnableReleases != nil {
+		repo.EnableReleases = *r.EnableReleases
+	}
+	if r.EnableLFS != nil {
+		repo.EnableLFS = *r.EnableLFS
+	}
+	if !repo.EnableIssues || repo.EnableExternalTracker {
+		repo.AllowPublicIssues = false
+		repo.IssuesReadOnly = false
+	}
+
+	if err := db.UpdateRepository(repo, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+	c.JSONSuccess(toFeatures(repo))
+}
@@ -13,11 +13,25 @@ import (
 
 	api "github.com/gogs/go-gogs-client"
 
+	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/route/api/v1/convert"
 )
 
+// orgLink returns the web link of the context organization.
+func orgLink(c *context.APIContext) string {
+	return conf.Server.Subpath + "/org/" + c.Org.Organization.Name
+}
+
+func listHooks(c *context.APIContext, link string, hooks []*db.Webhook) {
+	apiHooks := make([]*api.Hook, len(hooks))
+	for i := range hooks {
+		apiHooks[i] = convert.ToHook(link, hooks[i])
+	}
+	c.JSONSuccess(&apiHooks)
+}
+
 // https://github.com/gogs/go-gogs-client/wiki/Repositories#list-hooks
 func ListHooks(c *context.APIContext) {
 	hooks, err := db.GetWebhooksByRepoID(c.Repo.Repository.ID)
@@ -25,16 +39,41 @@ func ListHooks(c *context.APIContext) {
 		c.Errorf(err, "get webhooks by repository ID")
 		return
 	}
+	listHooks(c, c.Repo.RepoLink, hooks)
+}
 
-	apiHooks := make([]*api.Hook, len(hooks))
-	for i := range hooks {
-		apiHooks[i] = convert.ToHook(c.Repo.RepoLink, hooks[i])
+// ListOrgHooks lists webhooks of the organization that apply to all of its
+// repositories.
+func ListOrgHooks(c *context.APIContext) {
+	hooks, err := db.GetWebhooksByOrgID(c.Org.Organization.ID)
+	if err != nil {
+		c.Errorf(err, "get webhooks by organization ID")
+		return
 	}
-	c.JSONSuccess(&apiHooks)
+	listHooks(c, orgLink(c), hooks)
 }
 
-// https://github.com/gogs/go-gogs-client/wiki/Repositories#create-a-hook
-func CreateHook(c *context.APIContext, form api.CreateHookOption) {
+// toHookEvents returns the events set by the list of event names.
+func toHookEvents(events []string) db.HookEvents {
+	return db.HookEvents{
+		Create:           com.IsSliceContainsStr(events, string(db.HOOK_EVENT_CREATE)),
+		Delete:           com.IsSliceContainsStr(events, string(db.HOOK_EVENT_DELETE)),
+		Fork:             com.IsSliceContainsStr(events, string(db.HOOK_EVENT_FORK)),
+		Push:             com.IsSliceContainsStr(events, string(db.HOOK_EVENT_PUSH)),
+		Issues:           com.IsSliceContainsStr(events, string(db.HOOK_EVENT_ISSUES)),
+		IssueComment:     com.IsSliceContainsStr(events, string(db.HOOK_EVENT_ISSUE_COMMENT)),
+		PullRequest:      com.IsSliceContainsStr(events, string(db.HOOK_EVENT_PULL_REQUEST)),
+		Release:          com.IsSliceContainsStr(events, string(db.HOOK_EVENT_RELEASE)),
+		Label:            com.IsSliceContainsStr(events, string(db.HOOK_EVENT_LABEL)),
+		RepositoryEdited: com.IsSliceContainsStr(events, string(db.HOOK_EVENT_REPOSITORY_EDITED)),
+		Deployment:       com.IsSliceContainsStr(events, string(db.HOOK_EVENT_DEPLOYMENT)),
+		DeploymentStatus: com.IsSliceContainsStr(events, string(db.HOOK_EVENT_DEPLOYMENT_STATUS)),
+		Membership:       com.IsSliceContainsStr(events, string(db.HOOK_EVENT_MEMBERSHIP)),
+		Team:             com.IsSliceContainsStr(events, string(db.HOOK_EVENT_TEAM)),
+	}
+}
+
+func createHook(c *context.APIContext, repoID, orgID int64, link string, form api.CreateHookOption) {
 	if !db.IsValidHookTaskType(form.Type) {
 		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Invalid hook type."))
 		return
@@ -54,22 +93,14 @@ func CreateHook(c *context.APIContext, form api.CreateHookOption) {
 		form.Events = []string{"push"}
 	}
 	w := &db.Webhook{
-		RepoID:      c.Repo.Repository.ID,
+		RepoID:      repoID,
+		OrgID:       orgID,
 		URL:         form.Config["url"],
 		ContentType: db.ToHookContentType(form.Config["content_type"]),
 		Secret:      form.Config["secret"],
 		HookEvent: &db.HookEvent{
 			ChooseEvents: true,
-			HookEvents: db.HookEvents{
-				Create:       com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_CREATE)),
-				Delete:       com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_DELETE)),
-				Fork:         com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_FORK)),
-				Push:         com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_PUSH)),
-				Issues:       com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_ISSUES)),
-				IssueComment: com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_ISSUE_COMMENT)),
-				PullRequest:  com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_PULL_REQUEST)),
-				Release:      com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_RELEASE)),
-			},
+			HookEvents:   toHookEvents(form.Events),
 		},
 		IsActive:     form.Active,
 		HookTaskType: db.ToHookTaskType(form.Type),
@@ -91,6 +122,15 @@ func CreateHook(c *context.APIContext, form api.CreateHookOption) {
 			return
 		}
 		w.Meta = string(meta)
+	} else if w.HookTaskType == db.MSTEAMS {
+		meta, err := jsoniter.Marshal(&db.MSTeamsMeta{
+			ThemeColor: form.Config["theme_color"],
+		})
+		if err != nil {
+			c.Errorf(err, "marshal JSON")
+			return
+		}
+		w.Meta = string(meta)
 	}
 
 	if err := w.UpdateEvent(); err != nil {
@@ -101,17 +141,21 @@ func CreateHook(c *context.APIContext, form api.CreateHookOption) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, convert.ToHook(c.Repo.RepoLink, w))
+	c.JSON(http.StatusCreated, convert.ToHook(link, w))
 }
 
-// https://github.com/gogs/go-gogs-client/wiki/Repositories#edit-a-hook
-func EditHook(c *context.APIContext, form api.EditHookOption) {
-	w, err := db.GetWebhookOfRepoByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
-	if err != nil {
-		c.NotFoundOrError(err, "get webhook of repository by ID")
-		return
-	}
+// https://github.com/gogs/go-gogs-client/wiki/Repositories#create-a-hook
+func CreateHook(c *context.APIContext, form api.CreateHookOption) {
+	createHook(c, c.Repo.Repository.ID, 0, c.Repo.RepoLink, form)
+}
+
+// CreateOrgHook creates a webhook for the organization that is delivered for
+// events in any of its repositories.
+func CreateOrgHook(c *context.APIContext, form api.CreateHookOption) {
+	createHook(c, 0, c.Org.Organization.ID, orgLink(c), form)
+}
 
+func editHook(c *context.APIContext, w *db.Webhook, link string, form api.EditHookOption) {
 	if form.Config != nil {
 		if url, ok := form.Config["url"]; ok {
 			w.URL = url
@@ -138,6 +182,17 @@ func EditHook(c *context.APIContext, form api.EditHookOption) {
 				}
 				w.Meta = string(meta)
 			}
+		} else if w.HookTaskType == db.MSTEAMS {
+			if color, ok := form.Config["theme_color"]; ok {
+				meta, err := jsoniter.Marshal(&db.MSTeamsMeta{
+					ThemeColor: color,
+				})
+				if err != nil {
+					c.Errorf(err, "marshal JSON")
+					return
+				}
+				w.Meta = string(meta)
+			}
 		}
 	}
 
@@ -148,15 +203,8 @@ func EditHook(c *context.APIContext, form api.EditHookOption) {
 	w.PushOnly = false
 	w.SendEverything = false
 	w.ChooseEvents = true
-	w.Create = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_CREATE))
-	w.Delete = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_DELETE))
-	w.Fork = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_FORK))
-	w.Push = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_PUSH))
-	w.Issues = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_ISSUES))
-	w.IssueComment = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_ISSUE_COMMENT))
-	w.PullRequest = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_PULL_REQUEST))
-	w.Release = com.IsSliceContainsStr(form.Events, string(db.HOOK_EVENT_RELEASE))
-	if err = w.UpdateEvent(); err != nil {
+	w.HookEvents = toHookEvents(form.Events)
+	if err := w.UpdateEvent(); err != nil {
 		c.Errorf(err, "update event")
 		return
 	}
@@ -170,7 +218,27 @@ func EditHook(c *context.APIContext, form api.EditHookOption) {
 		return
 	}
 
-	c.JSONSuccess(convert.ToHook(c.Repo.RepoLink, w))
+	c.JSONSuccess(convert.ToHook(link, w))
+}
+
+// https://github.com/gogs/go-gogs-client/wiki/Repositories#edit-a-hook
+func EditHook(c *context.APIContext, form api.EditHookOption) {
+	w, err := db.GetWebhookOfRepoByID(c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get webhook of repository by ID")
+		return
+	}
+	editHook(c, w, c.Repo.RepoLink, form)
+}
+
+// EditOrgHook edits a webhook of the organization.
+func EditOrgHook(c *context.APIContext, form api.EditHookOption) {
+	w, err := db.GetWebhookByOrgID(c.Org.Organization.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get webhook of organization by ID")
+		return
+	}
+	editHook(c, w, orgLink(c), form)
 }
 
 func DeleteHook(c *context.APIContext) {
@@ -181,3 +249,13 @@ func DeleteHook(c *context.APIContext) {
 
 	c.NoContent()
 }
+
+// DeleteOrgHook deletes a webhook of the organization.
+func DeleteOrgHook(c *context.APIContext) {
+	if err := db.DeleteWebhookOfOrgByID(c.Org.Organization.ID, c.ParamsInt64(":id")); err != nil {
+		c.Errorf(err, "delete webhook of organization by ID")
+		return
+	}
+
+	c.NoContent()
+}
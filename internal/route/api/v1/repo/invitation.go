@@ -0,0 +1,162 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/email"
+)
+
+// RepoInvitation is the API message of a pending invitation for a user to
+// collaborate on a repository.
+type RepoInvitation struct {
+	ID         int64           `json:"id"`
+	Repository *api.Repository `json:"repository"`
+	Inviter    *api.User       `json:"inviter"`
+	Invitee    *api.User       `json:"invitee"`
+	Permission string          `json:"permission"`
+	Created    time.Time       `json:"created_at"`
+	Expires    time.Time       `json:"expires_at"`
+}
+
+// RespondRepoInvitationRequest is the API message for accepting or declining a
+// repository invitation.
+type RespondRepoInvitationRequest struct {
+	Action string `json:"action" binding:"Required;In(accept,decline)"`
+}
+
+func toRepoInvitation(c *context.APIContext, inv *db.RepoInvitation) (*RepoInvitation, error) {
+	repo, err := db.GetRepositoryByID(inv.RepoID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get repository by ID")
+	} else if err = repo.GetOwner(); err != nil {
+		return nil, errors.Wrap(err, "get owner")
+	}
+	inviter, err := db.Users.GetByID(c.Req.Context(), inv.InviterID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get inviter")
+	}
+	invitee, err := db.Users.GetByID(c.Req.Context(), inv.InviteeID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get invitee")
+	}
+
+	return &RepoInvitation{
+		ID:         inv.ID,
+		Repository: repo.APIFormatLegacy(nil),
+		Inviter:    inviter.APIFormat(),
+		Invitee:    invitee.APIFormat(),
+		Permission: inv.Mode.String(),
+		Created:    inv.Created,
+		Expires:    inv.Expires,
+	}, nil
+}
+
+func toRepoInvitations(c *context.APIContext, invs []*db.RepoInvitation) ([]*RepoInvitation, error) {
+	apiInvs := make([]*RepoInvitation, len(invs))
+	for i, inv := range invs {
+		apiInv, err := toRepoInvitation(c, inv)
+		if err != nil {
+			return nil, err
+		}
+		apiInvs[i] = apiInv
+	}
+	return apiInvs, nil
+}
+
+// inviteCollaborator creates an invitation for the user to collaborate on the
+// current repository with given access mode and notifies the user.
+func inviteCollaborator(c *context.APIContext, invitee *db.User, mode db.AccessMode) {
+	if invitee.IsOrganization() || invitee.ID == c.Repo.Repository.OwnerID {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("the user cannot be invited as a collaborator"))
+		return
+	}
+
+	inv, err := db.RepoInvitations.Create(c.Req.Context(), c.Repo.Repository.ID, c.User.ID, invitee.ID, mode)
+	if err != nil {
+		if db.IsErrRepoInvitationAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("the user has already been invited"))
+		} else {
+			c.Error(err, "create invitation")
+		}
+		return
+	}
+
+	if conf.User.EnableEmailNotification {
+		email.SendCollaboratorInvitationMail(db.NewMailerUser(invitee), db.NewMailerUser(c.User), db.NewMailerRepo(c.Repo.Repository))
+	}
+
+	apiInv, err := toRepoInvitation(c, inv)
+	if err != nil {
+		c.Error(err, "convert invitation")
+		return
+	}
+	c.JSON(http.StatusCreated, apiInv)
+}
+
+func ListRepoInvitations(c *context.APIContext) {
+	invs, err := db.RepoInvitations.ListByRepoID(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list invitations")
+		return
+	}
+
+	apiInvs, err := toRepoInvitations(c, invs)
+	if err != nil {
+		c.Error(err, "convert invitations")
+		return
+	}
+	c.JSONSuccess(&apiInvs)
+}
+
+func DeleteRepoInvitation(c *context.APIContext) {
+	err := db.RepoInvitations.DeleteByID(c.Req.Context(), c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete invitation")
+		return
+	}
+	c.NoContent()
+}
+
+func ListMyRepoInvitations(c *context.APIContext) {
+	invs, err := db.RepoInvitations.ListByInviteeID(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Error(err, "list invitations")
+		return
+	}
+
+	apiInvs, err := toRepoInvitations(c, invs)
+	if err != nil {
+		c.Error(err, "convert invitations")
+		return
+	}
+	c.JSONSuccess(&apiInvs)
+}
+
+func RespondRepoInvitation(c *context.APIContext, form RespondRepoInvitationRequest) {
+	var err error
+	if form.Action == "accept" {
+		err = db.RepoInvitations.Accept(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	} else {
+		err = db.RepoInvitations.Decline(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	}
+	if err != nil {
+		if db.IsErrReachLimitOfCollaborators(errors.Cause(err)) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Cause(err))
+		} else {
+			c.NotFoundOrError(err, form.Action+" invitation")
+		}
+		return
+	}
+	c.NoContent()
+}
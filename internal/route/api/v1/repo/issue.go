@@ -14,6 +14,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
 )
 
 func listIssues(c *context.APIContext, opts *db.IssuesOptions) {
@@ -30,13 +31,13 @@ func listIssues(c *context.APIContext, opts *db.IssuesOptions) {
 	}
 
 	// FIXME: use IssueList to improve performance.
-	apiIssues := make([]*api.Issue, len(issues))
+	apiIssues := make([]*convert.Issue, len(issues))
 	for i := range issues {
 		if err = issues[i].LoadAttributes(); err != nil {
 			c.Error(err, "load attributes")
 			return
 		}
-		apiIssues[i] = issues[i].APIFormat()
+		apiIssues[i] = convert.ToIssue(issues[i])
 	}
 
 	c.SetLinkHeader(int(count), conf.UI.IssuePagingNum)
@@ -55,21 +56,32 @@ func ListUserIssues(c *context.APIContext) {
 
 func ListIssues(c *context.APIContext) {
 	opts := db.IssuesOptions{
-		RepoID:   c.Repo.Repository.ID,
-		Page:     c.QueryInt("page"),
-		IsClosed: api.StateType(c.Query("state")) == api.STATE_CLOSED,
+		RepoID:          c.Repo.Repository.ID,
+		WorkflowStateID: c.QueryInt64("workflow_state"),
+		Page:            c.QueryInt("page"),
+		IsClosed:        api.StateType(c.Query("state")) == api.STATE_CLOSED,
 	}
 
 	listIssues(c, &opts)
 }
 
+// canReadIssue returns true if the current user can read the issue. Users
+// without access to the repository are only allowed to read issues but not pull
+// requests, which is the case when public access to issues is allowed.
+func canReadIssue(c *context.APIContext, issue *db.Issue) bool {
+	return !issue.IsPull || c.Repo.HasAccess()
+}
+
 func GetIssue(c *context.APIContext) {
 	issue, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
 	if err != nil {
 		c.NotFoundOrError(err, "get issue by index")
 		return
+	} else if !canReadIssue(c, issue) {
+		c.NotFound()
+		return
 	}
-	c.JSONSuccess(issue.APIFormat())
+	c.JSONSuccess(convert.ToIssue(issue))
 }
 
 func CreateIssue(c *context.APIContext, form api.CreateIssueOption) {
@@ -118,7 +130,7 @@ func CreateIssue(c *context.APIContext, form api.CreateIssueOption) {
 		c.Error(err, "get issue by ID")
 		return
 	}
-	c.JSON(http.StatusCreated, issue.APIFormat())
+	c.JSON(http.StatusCreated, convert.ToIssue(issue))
 }
 
 func EditIssue(c *context.APIContext, form api.EditIssueOption) {
@@ -126,6 +138,9 @@ func EditIssue(c *context.APIContext, form api.EditIssueOption) {
 	if err != nil {
 		c.NotFoundOrError(err, "get issue by index")
 		return
+	} else if !canReadIssue(c, issue) {
+		c.NotFound()
+		return
 	}
 
 	if !issue.IsPoster(c.User.ID) && !c.Repo.IsWriter() {
@@ -189,5 +204,5 @@ func EditIssue(c *context.APIContext, form api.EditIssueOption) {
 		c.Error(err, "get issue by ID")
 		return
 	}
-	c.JSON(http.StatusCreated, issue.APIFormat())
+	c.JSON(http.StatusCreated, convert.ToIssue(issue))
 }
@@ -27,7 +27,10 @@ func ListIssueComments(c *context.APIContext) {
 	// comments,err:=db.GetCommentsByIssueIDSince(, since)
 	issue, err := db.GetRawIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
 	if err != nil {
-		c.Error(err, "get raw issue by index")
+		c.NotFoundOrError(err, "get raw issue by index")
+		return
+	} else if !canReadIssue(c, issue) {
+		c.NotFound()
 		return
 	}
 
@@ -60,24 +63,43 @@ func ListRepoIssueComments(c *context.APIContext) {
 		c.Error(err, "get comments by repository ID")
 		return
 	}
+	c.JSONSuccess(readableComments(c, comments))
+}
 
-	apiComments := make([]*api.Comment, len(comments))
-	for i := range comments {
-		apiComments[i] = comments[i].APIFormat()
+// readableComments returns API formats of comments on issues that the current
+// user can read.
+func readableComments(c *context.APIContext, comments []*db.Comment) []*api.Comment {
+	apiComments := make([]*api.Comment, 0, len(comments))
+	for _, comment := range comments {
+		if canReadIssue(c, comment.Issue) {
+			apiComments = append(apiComments, comment.APIFormat())
+		}
 	}
-	c.JSONSuccess(&apiComments)
+	return apiComments
 }
 
 func CreateIssueComment(c *context.APIContext, form api.CreateIssueCommentOption) {
 	issue, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
 	if err != nil {
-		c.Error(err, "get issue by index")
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	} else if !canReadIssue(c, issue) {
+		c.NotFound()
+		return
+	}
+
+	if !issue.IsPull && !c.Repo.Repository.CanCreateIssues(c.Repo.AccessMode) {
+		c.Status(http.StatusForbidden)
 		return
 	}
 
 	comment, err := db.CreateIssueComment(c.User, c.Repo.Repository, issue, form.Body, nil)
 	if err != nil {
-		c.Error(err, "create issue comment")
+		if db.IsErrCommentRateLimitExceeded(err) {
+			c.ErrorStatus(http.StatusTooManyRequests, err)
+		} else {
+			c.Error(err, "create issue comment")
+		}
 		return
 	}
 
@@ -85,9 +107,8 @@ func CreateIssueComment(c *context.APIContext, form api.CreateIssueCommentOption
 }
 
 func EditIssueComment(c *context.APIContext, form api.EditIssueCommentOption) {
-	comment, err := db.GetCommentByID(c.ParamsInt64(":id"))
-	if err != nil {
-		c.NotFoundOrError(err, "get comment by ID")
+	comment := getRepoIssueComment(c)
+	if comment == nil {
 		return
 	}
 
@@ -109,9 +130,8 @@ func EditIssueComment(c *context.APIContext, form api.EditIssueCommentOption) {
 }
 
 func DeleteIssueComment(c *context.APIContext) {
-	comment, err := db.GetCommentByID(c.ParamsInt64(":id"))
-	if err != nil {
-		c.NotFoundOrError(err, "get comment by ID")
+	comment := getRepoIssueComment(c)
+	if comment == nil {
 		return
 	}
 
@@ -123,9 +143,95 @@ func DeleteIssueComment(c *context.APIContext) {
 		return
 	}
 
-	if err = db.DeleteCommentByID(c.User, comment.ID); err != nil {
+	if err := db.DeleteCommentByID(c.User, comment.ID); err != nil {
 		c.Error(err, "delete comment by ID")
 		return
 	}
 	c.NoContent()
 }
+
+// CommentHistory is the API message of a prior version of a comment.
+type CommentHistory struct {
+	ID      int64     `json:"id"`
+	Editor  string    `json:"editor"`
+	Body    string    `json:"body"`
+	Created time.Time `json:"created_at"`
+}
+
+// CommentHistories is the API message of the edit history of a comment.
+type CommentHistories struct {
+	NumEdits int               `json:"num_edits"`
+	Entries  []*CommentHistory `json:"entries"`
+}
+
+// getRepoIssueComment returns the comment with the ID in the URL, ensuring it
+// belongs to the current repository and the current user can read the issue.
+// It renders 404 and returns nil otherwise.
+func getRepoIssueComment(c *context.APIContext) *db.Comment {
+	comment, err := db.GetCommentByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get comment by ID")
+		return nil
+	} else if comment.Issue.RepoID != c.Repo.Repository.ID || !canReadIssue(c, comment.Issue) {
+		c.NotFound()
+		return nil
+	}
+	return comment
+}
+
+// GET /repos/:username/:reponame/issues/comments/:id/history
+func ListIssueCommentHistory(c *context.APIContext) {
+	comment := getRepoIssueComment(c)
+	if comment == nil {
+		return
+	}
+
+	if c.User.ID != comment.PosterID && !c.Repo.IsWriter() {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	histories, err := db.CommentHistories.List(c.Req.Context(), comment.ID)
+	if err != nil {
+		c.Error(err, "list comment histories")
+		return
+	}
+
+	apiHistories := &CommentHistories{
+		NumEdits: comment.NumEdits,
+		Entries:  make([]*CommentHistory, len(histories)),
+	}
+	for i, h := range histories {
+		apiHistories.Entries[i] = &CommentHistory{
+			ID:      h.ID,
+			Body:    h.Content,
+			Created: h.Created,
+		}
+		editor, err := db.Users.GetByID(c.Req.Context(), h.EditorID)
+		if err != nil {
+			if !db.IsErrUserNotExist(err) {
+				c.Error(err, "get user by ID")
+				return
+			}
+			apiHistories.Entries[i].Editor = db.NewGhostUser().Name
+		} else {
+			apiHistories.Entries[i].Editor = editor.Name
+		}
+	}
+	c.JSONSuccess(apiHistories)
+}
+
+// DELETE /repos/:username/:reponame/issues/comments/:id/history/:historyID
+func DeleteIssueCommentHistory(c *context.APIContext) {
+	comment := getRepoIssueComment(c)
+	if comment == nil {
+		return
+	}
+
+	err := db.CommentHistories.DeleteByID(c.Req.Context(), comment.ID, c.ParamsInt64(":historyID"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete comment history by ID")
+		return
+	}
+	c.NoContent()
+}
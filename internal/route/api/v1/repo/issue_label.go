@@ -18,6 +18,9 @@ func ListIssueLabels(c *context.APIContext) {
 	if err != nil {
 		c.NotFoundOrError(err, "get issue by index")
 		return
+	} else if !canReadIssue(c, issue) {
+		c.NotFound()
+		return
 	}
 
 	apiLabels := make([]*api.Label, len(issue.Labels))
@@ -96,7 +99,7 @@ func ReplaceIssueLabels(c *context.APIContext, form api.IssueLabelsOption) {
 		return
 	}
 
-	if err := issue.ReplaceLabels(labels); err != nil {
+	if err := issue.ReplaceLabels(c.User, labels); err != nil {
 		c.Error(err, "replace labels")
 		return
 	}
@@ -0,0 +1,41 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+func TestCanReadIssue(t *testing.T) {
+	issue := &db.Issue{}
+	pull := &db.Issue{IsPull: true}
+
+	tests := []struct {
+		name     string
+		mode     db.AccessMode
+		issue    *db.Issue
+		wantRead bool
+	}{
+		{name: "guest reads issue", mode: db.AccessModeNone, issue: issue, wantRead: true},
+		{name: "guest reads pull request", mode: db.AccessModeNone, issue: pull, wantRead: false},
+		{name: "reader reads issue", mode: db.AccessModeRead, issue: issue, wantRead: true},
+		{name: "reader reads pull request", mode: db.AccessModeRead, issue: pull, wantRead: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := &context.APIContext{
+				Context: &context.Context{
+					Repo: &context.Repository{AccessMode: test.mode},
+				},
+			}
+			assert.Equal(t, test.wantRead, canReadIssue(c, test.issue))
+		})
+	}
+}
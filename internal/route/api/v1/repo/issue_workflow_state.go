@@ -0,0 +1,121 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// WorkflowState is the API message of a custom workflow state of issues.
+type WorkflowState struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	IsClosed bool   `json:"is_closed"`
+}
+
+func toWorkflowState(state *db.IssueWorkflowState) *WorkflowState {
+	return &WorkflowState{
+		ID:       state.ID,
+		Name:     state.Name,
+		Color:    state.Color,
+		IsClosed: state.IsClosed,
+	}
+}
+
+// CreateWorkflowStateRequest is the API message for creating a custom workflow
+// state of issues.
+type CreateWorkflowStateRequest struct {
+	Name     string `json:"name" binding:"Required;MaxSize(50)"`
+	Color    string `json:"color" binding:"Required;Size(7)"`
+	IsClosed bool   `json:"is_closed"`
+}
+
+// SetIssueWorkflowStateRequest is the API message for setting the workflow
+// state of an issue, the state is unset when the ID is 0.
+type SetIssueWorkflowStateRequest struct {
+	StateID int64 `json:"state_id"`
+}
+
+// GET /repos/:username/:reponame/workflow_states
+func ListWorkflowStates(c *context.APIContext) {
+	states, err := db.IssueWorkflowStates.List(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list workflow states")
+		return
+	}
+
+	apiStates := make([]*WorkflowState, len(states))
+	for i := range states {
+		apiStates[i] = toWorkflowState(states[i])
+	}
+	c.JSONSuccess(&apiStates)
+}
+
+// POST /repos/:username/:reponame/workflow_states
+func CreateWorkflowState(c *context.APIContext, r CreateWorkflowStateRequest) {
+	state, err := db.IssueWorkflowStates.Create(
+		c.Req.Context(),
+		c.Repo.Repository.ID,
+		db.CreateIssueWorkflowStateOptions{
+			Name:     r.Name,
+			Color:    r.Color,
+			IsClosed: r.IsClosed,
+		},
+	)
+	if err != nil {
+		if db.IsErrIssueWorkflowStateAlreadyExist(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Workflow state %q already exists.", r.Name))
+		} else {
+			c.Error(err, "create workflow state")
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, toWorkflowState(state))
+}
+
+// DELETE /repos/:username/:reponame/workflow_states/:id
+func DeleteWorkflowState(c *context.APIContext) {
+	err := db.IssueWorkflowStates.DeleteByID(c.Req.Context(), c.Repo.Repository.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete workflow state")
+		return
+	}
+	c.NoContent()
+}
+
+// PUT /repos/:username/:reponame/issues/:index/workflow_state
+func SetIssueWorkflowState(c *context.APIContext, r SetIssueWorkflowStateRequest) {
+	issue, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	var state *db.IssueWorkflowState
+	if r.StateID > 0 {
+		state, err = db.IssueWorkflowStates.GetByID(c.Req.Context(), c.Repo.Repository.ID, r.StateID)
+		if err != nil {
+			if db.IsErrIssueWorkflowStateNotExist(err) {
+				c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Workflow state %d does not exist.", r.StateID))
+			} else {
+				c.Error(err, "get workflow state")
+			}
+			return
+		}
+	}
+
+	if err = issue.ChangeWorkflowState(c.User, c.Repo.Repository, state); err != nil {
+		c.Error(err, "change workflow state")
+		return
+	}
+	c.JSONSuccess(convert.ToIssue(issue))
+}
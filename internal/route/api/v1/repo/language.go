@@ -0,0 +1,25 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// GET /repos/:username/:reponame/languages
+func ListLanguages(c *context.APIContext) {
+	languages, err := db.RepoLanguages.List(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list languages")
+		return
+	}
+
+	stats := make(map[string]int64, len(languages))
+	for _, l := range languages {
+		stats[l.Language] = l.Bytes
+	}
+	c.JSONSuccess(stats)
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	dberrors "gogs.io/gogs/internal/db/errors"
+)
+
+// Mirror is the API message of mirror settings of a repository.
+type Mirror struct {
+	// The interval in hours between syncs.
+	Interval    int       `json:"interval"`
+	MinInterval int       `json:"min_interval"`
+	EnablePrune bool      `json:"enable_prune"`
+	LastSync    time.Time `json:"last_sync"`
+	NextSync    time.Time `json:"next_sync"`
+}
+
+// EditMirrorRequest is the API message for editing mirror settings of a
+// repository.
+type EditMirrorRequest struct {
+	Interval    *int  `json:"interval"`
+	EnablePrune *bool `json:"enable_prune"`
+}
+
+func toMirror(m *db.Mirror) *Mirror {
+	return &Mirror{
+		Interval:    m.Interval,
+		MinInterval: conf.Mirror.MinInterval,
+		EnablePrune: m.EnablePrune,
+		LastSync:    m.LastSync,
+		NextSync:    m.NextSync,
+	}
+}
+
+// mirrorByRepo returns the mirror of the current repository, it writes the
+// response when the repository is not a mirror.
+func mirrorByRepo(c *context.APIContext) *db.Mirror {
+	if !c.Repo.Repository.IsMirror {
+		c.NotFound()
+		return nil
+	}
+
+	m, err := db.GetMirrorByRepoID(c.Repo.Repository.ID)
+	if err != nil {
+		c.NotFoundOrError(err, "get mirror by repository ID")
+		return nil
+	}
+	return m
+}
+
+// GET /repos/:username/:reponame/mirror
+func GetMirror(c *context.APIContext) {
+	m := mirrorByRepo(c)
+	if c.Written() {
+		return
+	}
+	c.JSONSuccess(toMirror(m))
+}
+
+// PATCH /repos/:username/:reponame/mirror
+func EditMirror(c *context.APIContext, form EditMirrorRequest) {
+	m := mirrorByRepo(c)
+	if c.Written() {
+		return
+	}
+
+	if form.Interval != nil {
+		if err := m.SetInterval(*form.Interval); err != nil {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+			return
+		}
+	}
+	if form.EnablePrune != nil {
+		m.EnablePrune = *form.EnablePrune
+	}
+	if err := db.UpdateMirror(m); err != nil {
+		c.Error(err, "update mirror")
+		return
+	}
+	c.JSONSuccess(toMirror(m))
+}
+
+// POST /repos/:username/:reponame/mirror-sync
+func MirrorSync(c *context.APIContext) {
+	m := mirrorByRepo(c)
+	if c.Written() {
+		return
+	}
+
+	if err := m.SyncNow(); err != nil {
+		if dberrors.IsMirrorSyncCooldown(err) {
+			c.ErrorStatus(http.StatusTooManyRequests, err)
+		} else {
+			c.Error(err, "sync mirror")
+		}
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
@@ -0,0 +1,183 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	api "github.com/gogs/go-gogs-client"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+const (
+	PullRequestCheckSuccess = "success"
+	PullRequestCheckPending = "pending"
+	PullRequestCheckFailure = "failure"
+)
+
+// PullRequestCheck is the API message of a required check of a pull request.
+type PullRequestCheck struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// PullRequestStatus is the API message of the combined status of whether a pull
+// request is ready to merge. Mergeability is nil while it is being computed.
+type PullRequestStatus struct {
+	State                      db.MergeCheckState  `json:"state"`
+	Mergeable                  *bool               `json:"mergeable"`
+	HasConflicts               *bool               `json:"has_conflicts"`
+	MergeBase                  string              `json:"merge_base"`
+	BaseCommitID               string              `json:"base_commit_id"`
+	HeadCommitID               string              `json:"head_commit_id"`
+	RequiredApprovalsSatisfied bool                `json:"required_approvals_satisfied"`
+	RequiredChecksState        string              `json:"required_checks_state"`
+	RequiredChecks             []*PullRequestCheck `json:"required_checks"`
+}
+
+// GET /repos/:username/:reponame/pulls/:index/status
+func GetPullRequestStatus(c *context.APIContext) {
+	issue, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	} else if !issue.IsPull {
+		c.NotFound()
+		return
+	}
+
+	pr, err := db.GetPullRequestByIssueID(issue.ID)
+	if err != nil {
+		c.NotFoundOrError(err, "get pull request by issue ID")
+		return
+	} else if err = pr.LoadAttributes(); err != nil {
+		c.Error(err, "load attributes")
+		return
+	} else if pr.HeadRepo == nil {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("head repository does not exist"))
+		return
+	}
+
+	check, err := pr.CheckMergeability()
+	if err != nil {
+		c.Error(err, "check mergeability")
+		return
+	}
+
+	status := &PullRequestStatus{
+		State:                      check.State,
+		MergeBase:                  check.MergeBase,
+		BaseCommitID:               check.BaseCommitID,
+		HeadCommitID:               check.HeadCommitID,
+		RequiredApprovalsSatisfied: true,
+		RequiredChecksState:        PullRequestCheckSuccess,
+		RequiredChecks:             []*PullRequestCheck{},
+	}
+	if check.State == db.MergeCheckStateMergeable || check.State == db.MergeCheckStateConflict {
+		mergeable := check.Mergeable()
+		hasConflicts := !mergeable
+		status.Mergeable = &mergeable
+		status.HasConflicts = &hasConflicts
+	}
+
+	if c.Repo.Repository.RequireCLA {
+		required, err := c.Repo.Repository.IsCLASignatureRequired(c.Req.Context(), issue.PosterID)
+		if err != nil {
+			c.Error(err, "check CLA signature")
+			return
+		}
+
+		state := PullRequestCheckSuccess
+		if required {
+			state = PullRequestCheckFailure
+			status.RequiredChecksState = PullRequestCheckFailure
+		}
+		status.RequiredChecks = append(status.RequiredChecks, &PullRequestCheck{Name: "cla", State: state})
+	}
+
+	if c.Repo.Repository.RequireSignOff {
+		missing, err := pr.MissingSignOffs()
+		if err != nil {
+			c.Error(err, "get missing sign-offs")
+			return
+		}
+
+		state := PullRequestCheckSuccess
+		if len(missing) > 0 {
+			state = PullRequestCheckFailure
+			status.RequiredChecksState = PullRequestCheckFailure
+		}
+		status.RequiredChecks = append(status.RequiredChecks, &PullRequestCheck{Name: "dco", State: state})
+	}
+
+	checks, err := pr.RequiredStatusChecks(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get required status checks")
+		return
+	}
+	for _, check := range checks {
+		state := PullRequestCheckSuccess
+		if check.State == db.CommitStatusPending {
+			state = PullRequestCheckPending
+		} else if check.State != db.CommitStatusSuccess {
+			state = PullRequestCheckFailure
+		}
+		if state != PullRequestCheckSuccess && status.RequiredChecksState != PullRequestCheckFailure {
+			status.RequiredChecksState = state
+		}
+		status.RequiredChecks = append(status.RequiredChecks, &PullRequestCheck{Name: check.Context, State: state})
+	}
+
+	missingApprovals, err := pr.MissingApprovals(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get missing approvals")
+		return
+	}
+	status.RequiredApprovalsSatisfied = missingApprovals == 0
+
+	if db.IsBranchOfRepoRequireCodeOwnerReviews(pr.BaseRepoID, pr.BaseBranch) {
+		missing, err := pr.MissingCodeOwners(c.Req.Context())
+		if err != nil {
+			c.Error(err, "get missing code owners")
+			return
+		}
+		status.RequiredApprovalsSatisfied = status.RequiredApprovalsSatisfied && len(missing) == 0
+	}
+
+	c.JSONSuccess(status)
+}
+
+// GET /repos/:username/:reponame/pulls/:index/requested_reviewers
+func ListRequestedReviewers(c *context.APIContext) {
+	issue, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.ParamsInt64(":index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	} else if !issue.IsPull {
+		c.NotFound()
+		return
+	}
+
+	pr, err := db.GetPullRequestByIssueID(issue.ID)
+	if err != nil {
+		c.NotFoundOrError(err, "get pull request by issue ID")
+		return
+	}
+
+	reviewers, err := pr.RequestedReviewers(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get requested reviewers")
+		return
+	}
+
+	apiReviewers := make([]*api.User, len(reviewers))
+	for i := range reviewers {
+		apiReviewers[i] = reviewers[i].APIFormat()
+	}
+	c.JSONSuccess(&apiReviewers)
+}
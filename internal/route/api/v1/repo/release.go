@@ -0,0 +1,448 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gogs/git-module"
+	"github.com/pkg/errors"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// CreateReleaseRequest is the API message for creating a release.
+type CreateReleaseRequest struct {
+	TagName         string `json:"tag_name" binding:"Required"`
+	TargetCommitish string `json:"target_commitish"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+// EditReleaseRequest is the API message for editing a release. Fields that are
+// not set are left unchanged. The tag name and the target can only be changed
+// for drafts.
+type EditReleaseRequest struct {
+	TagName         *string `json:"tag_name"`
+	TargetCommitish *string `json:"target_commitish"`
+	Name            *string `json:"name"`
+	Body            *string `json:"body"`
+	Draft           *bool   `json:"draft"`
+	Prerelease      *bool   `json:"prerelease"`
+}
+
+// loadReleaseAttributes loads the publisher and attachments of the release.
+func loadReleaseAttributes(c *context.APIContext, r *db.Release) error {
+	publisher, err := db.Users.GetByID(c.Req.Context(), r.PublisherID)
+	if err != nil {
+		if !db.IsErrUserNotExist(err) {
+			return errors.Wrap(err, "get release publisher")
+		}
+		publisher = db.NewGhostUser()
+	}
+	r.Publisher = publisher
+
+	r.Attachments, err = db.GetAttachmentsByReleaseID(r.ID)
+	if err != nil {
+		return errors.Wrap(err, "get release attachments")
+	}
+	return nil
+}
+
+func renderRelease(c *context.APIContext, status int, r *db.Release) {
+	if err := loadReleaseAttributes(c, r); err != nil {
+		c.Error(err, "load release attributes")
+		return
+	}
+	c.JSON(status, convert.ToRelease(r, c.Repo.Repository.HTMLURL()))
+}
+
+// GET /repos/:username/:reponame/releases
+func Releases(c *context.APIContext) {
+	releases, err := db.GetReleasesByRepoID(c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get releases by repository ID")
+		return
+	}
+	apiReleases := make([]*convert.Release, 0, len(releases))
+	for _, r := range releases {
+		// Drafts are only visible to those who can publish them.
+		if r.IsDraft && !c.Repo.IsWriter() {
+			continue
+		}
+
+		if err = loadReleaseAttributes(c, r); err != nil {
+			c.Error(err, "load release attributes")
+			return
+		}
+		apiReleases = append(apiReleases, convert.ToRelease(r, c.Repo.Repository.HTMLURL()))
+	}
+
+	c.JSONSuccess(&apiReleases)
+}
+
+// getReleaseByParams returns the release by the ":id" parameter, drafts are
+// not found to those who cannot write to the repository.
+func getReleaseByParams(c *context.APIContext) *db.Release {
+	r, err := db.GetReleaseByID(c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get release by ID")
+		return nil
+	} else if r.RepoID != c.Repo.Repository.ID || (r.IsDraft && !c.Repo.IsWriter()) {
+		c.NotFound()
+		return nil
+	}
+	return r
+}
+
+// GET /repos/:username/:reponame/releases/:id
+func GetRelease(c *context.APIContext) {
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return
+	}
+	renderRelease(c, http.StatusOK, r)
+}
+
+// GET /repos/:username/:reponame/releases/latest
+func GetLatestRelease(c *context.APIContext) {
+	r, err := db.GetLatestReleaseByRepoID(c.Repo.Repository.ID)
+	if err != nil {
+		c.NotFoundOrError(err, "get latest release")
+		return
+	}
+	renderRelease(c, http.StatusOK, r)
+}
+
+// GET /repos/:username/:reponame/releases/tags/*
+func GetReleaseByTag(c *context.APIContext) {
+	r, err := db.GetRelease(c.Repo.Repository.ID, c.Params("*"))
+	if err != nil {
+		c.NotFoundOrError(err, "get release by tag")
+		return
+	} else if r.IsDraft && !c.Repo.IsWriter() {
+		c.NotFound()
+		return
+	}
+	renderRelease(c, http.StatusOK, r)
+}
+
+// POST /repos/:username/:reponame/releases
+func CreateRelease(c *context.APIContext, req CreateReleaseRequest) {
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
+	if err != nil {
+		c.Error(err, "open repository")
+		return
+	}
+
+	if req.TargetCommitish == "" {
+		req.TargetCommitish = c.Repo.Repository.DefaultBranch
+	}
+	if !gitRepo.HasBranch(req.TargetCommitish) {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("target branch %q does not exist", req.TargetCommitish))
+		return
+	}
+
+	// Use current time if tag not yet exist, otherwise get time from Git
+	var tagCreatedUnix int64
+	tag, err := gitRepo.Tag(git.RefsTags + req.TagName)
+	if err == nil {
+		commit, err := tag.Commit()
+		if err == nil {
+			tagCreatedUnix = commit.Author.When.Unix()
+		}
+	}
+
+	commit, err := gitRepo.BranchCommit(req.TargetCommitish)
+	if err != nil {
+		c.Error(err, "get branch commit")
+		return
+	}
+	commitsCount, err := commit.CommitsCount()
+	if err != nil {
+		c.Error(err, "count commits")
+		return
+	}
+
+	r := &db.Release{
+		RepoID:       c.Repo.Repository.ID,
+		PublisherID:  c.User.ID,
+		Title:        req.Name,
+		TagName:      req.TagName,
+		Target:       req.TargetCommitish,
+		Sha1:         commit.ID.String(),
+		NumCommits:   commitsCount,
+		Note:         req.Body,
+		IsDraft:      req.Draft,
+		IsPrerelease: req.Prerelease,
+		CreatedUnix:  tagCreatedUnix,
+	}
+	if err = db.NewRelease(gitRepo, r, nil); err != nil {
+		if db.IsErrReleaseAlreadyExist(err) || db.IsErrInvalidTagName(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "new release")
+		}
+		return
+	}
+
+	r, err = db.GetReleaseByID(r.ID)
+	if err != nil {
+		c.Error(err, "get release by ID")
+		return
+	}
+	renderRelease(c, http.StatusCreated, r)
+}
+
+// PATCH /repos/:username/:reponame/releases/:id
+func EditRelease(c *context.APIContext, req EditReleaseRequest) {
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return
+	}
+
+	gitRepo, err := git.Open(c.Repo.Repository.RepoPath())
+	if err != nil {
+		c.Error(err, "open repository")
+		return
+	}
+
+	if !r.IsDraft {
+		if req.Draft != nil && *req.Draft {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Published releases cannot be converted to drafts."))
+			return
+		} else if (req.TagName != nil && *req.TagName != r.TagName) ||
+			(req.TargetCommitish != nil && *req.TargetCommitish != r.Target) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.New("Tag name and target of published releases cannot be changed."))
+			return
+		}
+	}
+
+	if req.TagName != nil && *req.TagName != r.TagName {
+		exist, err := db.IsReleaseExist(r.RepoID, *req.TagName)
+		if err != nil {
+			c.Error(err, "check release existence")
+			return
+		} else if exist {
+			c.ErrorStatus(http.StatusUnprocessableEntity, db.ErrReleaseAlreadyExist{TagName: *req.TagName})
+			return
+		} else if *req.TagName == "" {
+			c.ErrorStatus(http.StatusUnprocessableEntity, db.ErrInvalidTagName{TagName: *req.TagName})
+			return
+		}
+		r.TagName = *req.TagName
+		r.LowerTagName = strings.ToLower(r.TagName)
+	}
+	if req.TargetCommitish != nil {
+		if !gitRepo.HasBranch(*req.TargetCommitish) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("target branch %q does not exist", *req.TargetCommitish))
+			return
+		}
+		r.Target = *req.TargetCommitish
+	}
+	if req.Name != nil {
+		r.Title = *req.Name
+	}
+	if req.Body != nil {
+		r.Note = *req.Body
+	}
+	if req.Prerelease != nil {
+		r.IsPrerelease = *req.Prerelease
+	}
+
+	var attachments []string
+	for _, a := range r.Attachments {
+		attachments = append(attachments, a.UUID)
+	}
+
+	isPublish := r.IsDraft && req.Draft != nil && !*req.Draft
+	if isPublish {
+		r.IsDraft = false
+	}
+	if err = db.UpdateRelease(c.User, gitRepo, r, isPublish, attachments); err != nil {
+		if db.IsErrInvalidTagName(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "update release")
+		}
+		return
+	}
+	renderRelease(c, http.StatusOK, r)
+}
+
+// DELETE /repos/:username/:reponame/releases/:id
+func DeleteRelease(c *context.APIContext) {
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if err := db.DeleteReleaseOfRepoByID(c.User, c.Repo.Repository.ID, r.ID); err != nil {
+		c.Error(err, "delete release")
+		return
+	}
+	c.NoContent()
+}
+
+// GET /repos/:username/:reponame/releases/:id/assets
+func ListReleaseAssets(c *context.APIContext) {
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return
+	}
+
+	attachments, err := db.GetAttachmentsByReleaseID(r.ID)
+	if err != nil {
+		c.Error(err, "get attachments by release ID")
+		return
+	}
+	assets := make([]*convert.ReleaseAsset, 0, len(attachments))
+	for _, a := range attachments {
+		assets = append(assets, convert.ToReleaseAsset(a))
+	}
+	c.JSONSuccess(assets)
+}
+
+// POST /repos/:username/:reponame/releases/:id/assets
+func UploadReleaseAsset(c *context.APIContext) {
+	if !conf.Release.Attachment.Enabled {
+		c.NotFound()
+		return
+	}
+
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if len(r.Attachments) >= conf.Release.Attachment.MaxFiles {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("Releases can have at most %d assets.", conf.Release.Attachment.MaxFiles))
+		return
+	}
+
+	file, header, err := c.Req.FormFile("attachment")
+	if err != nil {
+		c.ErrorStatus(http.StatusBadRequest, errors.Wrap(err, "get file"))
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	if header.Size > conf.Release.Attachment.MaxSize<<20 {
+		c.ErrorStatus(http.StatusRequestEntityTooLarge, errors.Errorf("Asset size exceeds the limit of %d MB.", conf.Release.Attachment.MaxSize))
+		return
+	}
+
+	buf := make([]byte, 1024)
+	n, _ := file.Read(buf)
+	buf = buf[:n]
+	fileType := http.DetectContentType(buf)
+
+	allowed := false
+	for _, t := range conf.Release.Attachment.AllowedTypes {
+		t := strings.TrimSpace(t)
+		if t == "*/*" || t == fileType {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		c.ErrorStatus(http.StatusUnprocessableEntity, errors.Errorf("File type %q is not allowed.", fileType))
+		return
+	}
+
+	name := c.Query("name")
+	if name == "" {
+		name = header.Filename
+	}
+	attach, err := db.NewAttachment(name, buf, file)
+	if err != nil {
+		c.Error(err, "new attachment")
+		return
+	}
+	if err = db.AddReleaseAttachment(r.ID, attach); err != nil {
+		c.Error(err, "add release attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, convert.ToReleaseAsset(attach))
+}
+
+// getReleaseAssetByParams returns the attachment by the ":assetID" parameter
+// of the release by the ":id" parameter.
+func getReleaseAssetByParams(c *context.APIContext) *db.Attachment {
+	r := getReleaseByParams(c)
+	if c.Written() {
+		return nil
+	}
+
+	attach, err := db.GetAttachmentByID(c.ParamsInt64(":assetID"))
+	if err != nil {
+		c.NotFoundOrError(err, "get attachment by ID")
+		return nil
+	} else if attach.ReleaseID != r.ID {
+		c.NotFound()
+		return nil
+	}
+	return attach
+}
+
+// GET /repos/:username/:reponame/releases/:id/assets/:assetID
+func GetReleaseAsset(c *context.APIContext) {
+	attach := getReleaseAssetByParams(c)
+	if c.Written() {
+		return
+	}
+
+	c.JSONSuccess(convert.ToReleaseAsset(attach))
+}
+
+// GET /repos/:username/:reponame/releases/:id/assets/:assetID/download
+func DownloadReleaseAsset(c *context.APIContext) {
+	attach := getReleaseAssetByParams(c)
+	if c.Written() {
+		return
+	}
+
+	f, err := os.Open(attach.LocalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.NotFound()
+		} else {
+			c.Error(err, "open attachment file")
+		}
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	c.Resp.Header().Set("Content-Type", "application/octet-stream")
+	c.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, attach.Name))
+	if _, err = io.Copy(c.Resp, f); err != nil {
+		c.Error(err, "copy from file to response")
+		return
+	}
+}
+
+// DELETE /repos/:username/:reponame/releases/:id/assets/:assetID
+func DeleteReleaseAsset(c *context.APIContext) {
+	attach := getReleaseAssetByParams(c)
+	if c.Written() {
+		return
+	}
+
+	if err := db.DeleteAttachment(attach, true); err != nil {
+		c.Error(err, "delete attachment")
+		return
+	}
+	c.NoContent()
+}
@@ -7,6 +7,7 @@ package repo
 import (
 	"net/http"
 	"path"
+	"time"
 
 	api "github.com/gogs/go-gogs-client"
 	"github.com/pkg/errors"
@@ -16,6 +17,7 @@ import (
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/repoutil"
 	"gogs.io/gogs/internal/route/api/v1/convert"
 )
 
@@ -23,6 +25,7 @@ func Search(c *context.APIContext) {
 	opts := &db.SearchRepoOptions{
 		Keyword:  path.Base(c.Query("q")),
 		OwnerID:  c.QueryInt64("uid"),
+		Topic:    c.Query("topic"),
 		PageSize: convert.ToCorrectPageSize(c.QueryInt("limit")),
 		Page:     c.QueryInt("page"),
 	}
@@ -165,7 +168,9 @@ func CreateUserRepo(c *context.APIContext, owner *db.User, opt api.CreateRepoOpt
 	})
 	if err != nil {
 		if db.IsErrRepoAlreadyExist(err) ||
-			db.IsErrNameNotAllowed(err) {
+			db.IsErrNameNotAllowed(err) ||
+			db.IsErrRepoInitTemplateNotExist(err) ||
+			db.IsErrRepoVisibilityNotAllowed(err) {
 			c.ErrorStatus(http.StatusUnprocessableEntity, err)
 		} else {
 			if repo != nil {
@@ -273,7 +278,7 @@ func Migrate(c *context.APIContext, f form.MigrateRepo) {
 			}
 		}
 
-		if db.IsErrReachLimitOfRepo(err) {
+		if db.IsErrReachLimitOfRepo(err) || db.IsErrRepoVisibilityNotAllowed(err) {
 			c.ErrorStatus(http.StatusUnprocessableEntity, err)
 		} else {
 			c.Error(errors.New(db.HandleMirrorCredentials(err.Error(), true)), "migrate repository")
@@ -312,7 +317,7 @@ func Get(c *context.APIContext) {
 		return
 	}
 
-	c.JSONSuccess(repo.APIFormatLegacy(&api.Permission{
+	c.JSONSuccess(convert.ToRepository(repo, &api.Permission{
 		Admin: c.Repo.IsAdmin(),
 		Push:  c.Repo.IsWriter(),
 		Pull:  true,
@@ -330,15 +335,53 @@ func Delete(c *context.APIContext) {
 		return
 	}
 
+	if conf.API.RequireRepoDeletionToken {
+		err := repoutil.VerifyDeletionToken(c.Query("deletion_token"), repo.ID, c.User.ID, time.Now())
+		if err != nil {
+			c.ErrorStatus(http.StatusForbidden, err)
+			return
+		}
+	}
+
 	if err := db.DeleteRepository(owner.ID, repo.ID); err != nil {
 		c.Error(err, "delete repository")
 		return
 	}
 
 	log.Trace("Repository deleted: %s/%s", owner.Name, repo.Name)
+	db.RecordAudit(c.Req.Context(), db.AuditActionRepoDelete, repo.ID, owner.Name+"/"+repo.Name, "")
 	c.NoContent()
 }
 
+// DeletionToken is the API message of a repository deletion token.
+type DeletionToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// GET /repos/:username/:reponame/deletion_token
+func GetDeletionToken(c *context.APIContext) {
+	owner, repo := parseOwnerAndRepo(c)
+	if c.Written() {
+		return
+	}
+
+	if owner.IsOrganization() && !owner.IsOwnedBy(c.User.ID) {
+		c.ErrorStatus(http.StatusForbidden, errors.New("Given user is not owner of organization."))
+		return
+	}
+
+	lifetime := conf.API.RepoDeletionTokenLifetime
+	if lifetime <= 0 {
+		lifetime = 5 * time.Minute
+	}
+	expiresAt := time.Now().Add(lifetime)
+	c.JSONSuccess(&DeletionToken{
+		Token:     repoutil.NewDeletionToken(repo.ID, c.User.ID, expiresAt),
+		ExpiresAt: expiresAt,
+	})
+}
+
 func ListForks(c *context.APIContext) {
 	forks, err := c.Repo.Repository.GetForks()
 	if err != nil {
@@ -430,39 +473,3 @@ func Wiki(c *context.APIContext, form api.EditWikiOption) {
 
 	c.NoContent()
 }
-
-func MirrorSync(c *context.APIContext) {
-	_, repo := parseOwnerAndRepo(c)
-	if c.Written() {
-		return
-	} else if !repo.IsMirror {
-		c.NotFound()
-		return
-	}
-
-	go db.MirrorQueue.Add(repo.ID)
-	c.Status(http.StatusAccepted)
-}
-
-func Releases(c *context.APIContext) {
-	_, repo := parseOwnerAndRepo(c)
-	releases, err := db.GetReleasesByRepoID(repo.ID)
-	if err != nil {
-		c.Error(err, "get releases by repository ID")
-		return
-	}
-	apiReleases := make([]*api.Release, 0, len(releases))
-	for _, r := range releases {
-		publisher, err := db.Users.GetByID(c.Req.Context(), r.PublisherID)
-		if err != nil {
-			c.Error(err, "get release publisher")
-			return
-		}
-		r.Publisher = publisher
-	}
-	for _, r := range releases {
-		apiReleases = append(apiReleases, r.APIFormat())
-	}
-
-	c.JSONSuccess(&apiReleases)
-}
@@ -0,0 +1,145 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/repoutil"
+)
+
+// Secret is the API message of a secret, values of secrets are never returned.
+type Secret struct {
+	Name    string    `json:"name"`
+	Created time.Time `json:"created_at"`
+	Updated time.Time `json:"updated_at"`
+}
+
+// SetSecretRequest is the API message for creating or updating a secret.
+type SetSecretRequest struct {
+	Value string `json:"value" binding:"Required"`
+}
+
+// SecretsToken is the API message of a token to retrieve values of secrets of a
+// repository.
+type SecretsToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func listSecrets(c *context.APIContext, orgID, repoID int64) {
+	secrets, err := db.RepoSecrets.List(c.Req.Context(), orgID, repoID)
+	if err != nil {
+		c.Error(err, "list secrets")
+		return
+	}
+
+	apiSecrets := make([]*Secret, len(secrets))
+	for i := range secrets {
+		apiSecrets[i] = &Secret{
+			Name:    secrets[i].Name,
+			Created: secrets[i].Created,
+			Updated: secrets[i].Updated,
+		}
+	}
+	c.JSONSuccess(&apiSecrets)
+}
+
+func setSecret(c *context.APIContext, orgID, repoID int64, form SetSecretRequest) {
+	err := db.RepoSecrets.Set(c.Req.Context(), orgID, repoID, conf.Security.SecretKey, c.Params(":name"), form.Value)
+	if err != nil {
+		if db.IsErrRepoSecretNameInvalid(err) {
+			c.ErrorStatus(http.StatusUnprocessableEntity, err)
+		} else {
+			c.Error(err, "set secret")
+		}
+		return
+	}
+	c.NoContent()
+}
+
+func deleteSecret(c *context.APIContext, orgID, repoID int64) {
+	err := db.RepoSecrets.Delete(c.Req.Context(), orgID, repoID, c.Params(":name"))
+	if err != nil {
+		c.NotFoundOrError(err, "delete secret")
+		return
+	}
+	c.NoContent()
+}
+
+// GET /repos/:username/:reponame/secrets
+func ListSecrets(c *context.APIContext) {
+	listSecrets(c, 0, c.Repo.Repository.ID)
+}
+
+// PUT /repos/:username/:reponame/secrets/:name
+func SetSecret(c *context.APIContext, form SetSecretRequest) {
+	setSecret(c, 0, c.Repo.Repository.ID, form)
+}
+
+// DELETE /repos/:username/:reponame/secrets/:name
+func DeleteSecret(c *context.APIContext) {
+	deleteSecret(c, 0, c.Repo.Repository.ID)
+}
+
+// GET /orgs/:orgname/secrets
+func ListOrgSecrets(c *context.APIContext) {
+	listSecrets(c, c.Org.Organization.ID, 0)
+}
+
+// PUT /orgs/:orgname/secrets/:name
+func SetOrgSecret(c *context.APIContext, form SetSecretRequest) {
+	setSecret(c, c.Org.Organization.ID, 0, form)
+}
+
+// DELETE /orgs/:orgname/secrets/:name
+func DeleteOrgSecret(c *context.APIContext) {
+	deleteSecret(c, c.Org.Organization.ID, 0)
+}
+
+// POST /repos/:username/:reponame/secrets/token
+func CreateSecretsToken(c *context.APIContext) {
+	lifetime := conf.API.SecretsTokenLifetime
+	if lifetime <= 0 {
+		lifetime = time.Hour
+	}
+	expiresAt := time.Now().Add(lifetime)
+	c.JSON(http.StatusCreated, &SecretsToken{
+		Token:     repoutil.NewSecretsToken(c.Repo.Repository.ID, expiresAt),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// GET /repos/:username/:reponame/secrets/values
+//
+// 🚨 SECURITY: This endpoint is authorized by the secrets token in the
+// "X-Gogs-Secrets-Token" header instead of the context user.
+func ListSecretValues(c *context.APIContext) {
+	owner, repo := parseOwnerAndRepo(c)
+	if c.Written() {
+		return
+	}
+
+	err := repoutil.VerifySecretsToken(c.Req.Header.Get("X-Gogs-Secrets-Token"), repo.ID, time.Now())
+	if err != nil {
+		c.ErrorStatus(http.StatusForbidden, err)
+		return
+	}
+
+	var orgID int64
+	if owner.IsOrganization() {
+		orgID = owner.ID
+	}
+	values, err := db.RepoSecrets.ListValues(c.Req.Context(), conf.Security.SecretKey, orgID, repo.ID)
+	if err != nil {
+		c.Error(err, "list secret values")
+		return
+	}
+	c.JSONSuccess(values)
+}
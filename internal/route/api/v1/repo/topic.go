@@ -0,0 +1,33 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/route/api/v1/convert"
+)
+
+// GET /topics/search
+func SearchTopics(c *context.APIContext) {
+	topics, err := db.Topics.Search(c.Req.Context(), c.Query("q"), convert.ToCorrectPageSize(c.QueryInt("limit")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, map[string]any{
+			"ok":    false,
+			"error": err.Error(),
+		})
+		return
+	}
+	if topics == nil {
+		topics = []string{}
+	}
+
+	c.JSONSuccess(map[string]any{
+		"ok":   true,
+		"data": topics,
+	})
+}
@@ -6,6 +6,7 @@ package user
 
 import (
 	"net/http"
+	"time"
 
 	api "github.com/gogs/go-gogs-client"
 
@@ -13,6 +14,13 @@ import (
 	"gogs.io/gogs/internal/db"
 )
 
+// AccessToken is the API message of an access token with its last use.
+type AccessToken struct {
+	api.AccessToken
+	LastUsed   *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
 func ListAccessTokens(c *context.APIContext) {
 	tokens, err := db.AccessTokens.List(c.Req.Context(), c.User.ID)
 	if err != nil {
@@ -20,9 +28,15 @@ func ListAccessTokens(c *context.APIContext) {
 		return
 	}
 
-	apiTokens := make([]*api.AccessToken, len(tokens))
-	for i := range tokens {
-		apiTokens[i] = &api.AccessToken{Name: tokens[i].Name, Sha1: tokens[i].Sha1}
+	apiTokens := make([]*AccessToken, len(tokens))
+	for i, t := range tokens {
+		apiTokens[i] = &AccessToken{
+			AccessToken: api.AccessToken{Name: t.Name, Sha1: t.Sha1},
+			LastUsedIP:  t.LastUsedIP,
+		}
+		if !t.LastUsed.IsZero() {
+			apiTokens[i].LastUsed = &t.LastUsed
+		}
 	}
 	c.JSONSuccess(&apiTokens)
 }
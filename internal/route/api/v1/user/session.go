@@ -0,0 +1,59 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"time"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+)
+
+// Session is the API message of a signed-in session of the user.
+type Session struct {
+	ID        int64     `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	Created   time.Time `json:"created_at"`
+	LastSeen  time.Time `json:"last_seen_at"`
+}
+
+func ListSessions(c *context.APIContext) {
+	sessions, err := db.UserSessions.List(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Error(err, "list sessions")
+		return
+	}
+
+	apiSessions := make([]*Session, len(sessions))
+	for i, s := range sessions {
+		apiSessions[i] = &Session{
+			ID:        s.ID,
+			UserAgent: s.UserAgent,
+			IP:        s.IP,
+			Created:   s.Created,
+			LastSeen:  s.LastSeen,
+		}
+	}
+	c.JSONSuccess(&apiSessions)
+}
+
+func DeleteSession(c *context.APIContext) {
+	err := db.UserSessions.Revoke(c.Req.Context(), c.User.ID, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "revoke session")
+		return
+	}
+	c.NoContent()
+}
+
+func DeleteSessions(c *context.APIContext) {
+	err := db.UserSessions.RevokeAll(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Error(err, "revoke sessions")
+		return
+	}
+	c.NoContent()
+}
@@ -37,7 +37,7 @@ func Search(c *context.APIContext) {
 			FullName:  markup.Sanitize(users[i].FullName),
 		}
 		if c.IsLogged {
-			results[i].Email = users[i].Email
+			results[i].Email = publicEmail(c, users[i])
 		}
 	}
 
@@ -57,10 +57,22 @@ func GetInfo(c *context.APIContext) {
 	// Hide user e-mail when API caller isn't signed in.
 	if !c.IsLogged {
 		u.Email = ""
+	} else {
+		u.Email = publicEmail(c, u)
 	}
 	c.JSONSuccess(u.APIFormat())
 }
 
+// publicEmail returns the email address of the user that is visible to the
+// API caller, which is the noreply email address if the user keeps their email
+// address private from others.
+func publicEmail(c *context.APIContext, u *db.User) string {
+	if u.KeepEmailPrivate && c.User.ID != u.ID {
+		return u.NoReplyEmail()
+	}
+	return u.Email
+}
+
 func GetAuthenticatedUser(c *context.APIContext) {
 	c.JSONSuccess(c.User.APIFormat())
 }
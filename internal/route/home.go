@@ -8,6 +8,7 @@ import (
 	gocontext "context"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/go-macaron/i18n"
 	"github.com/unknwon/paginater"
@@ -48,6 +49,10 @@ func Home(c *context.Context) {
 	c.Success(HOME)
 }
 
+// exploreTopicsNum is the maximum number of topics to be listed on the explore
+// page.
+const exploreTopicsNum = 20
+
 func ExploreRepos(c *context.Context) {
 	c.Data["Title"] = c.Tr("explore")
 	c.Data["PageIsExplore"] = true
@@ -59,8 +64,10 @@ func ExploreRepos(c *context.Context) {
 	}
 
 	keyword := c.Query("q")
+	topic := strings.ToLower(c.Query("topic"))
 	repos, count, err := db.SearchRepositoryByName(&db.SearchRepoOptions{
 		Keyword:  keyword,
+		Topic:    topic,
 		UserID:   c.UserID(),
 		OrderBy:  "updated_unix DESC",
 		Page:     page,
@@ -71,6 +78,7 @@ func ExploreRepos(c *context.Context) {
 		return
 	}
 	c.Data["Keyword"] = keyword
+	c.Data["Topic"] = topic
 	c.Data["Total"] = count
 	c.Data["Page"] = paginater.New(int(count), conf.UI.ExplorePagingNum, page, 5)
 
@@ -80,6 +88,18 @@ func ExploreRepos(c *context.Context) {
 	}
 	c.Data["Repos"] = repos
 
+	topicOrder := db.TopicOrder(c.Query("topic_sort"))
+	if topicOrder != db.TopicOrderName {
+		topicOrder = db.TopicOrderPopular
+	}
+	topics, err := db.Topics.Count(c.Req.Context(), topicOrder, exploreTopicsNum)
+	if err != nil {
+		c.Error(err, "count topics")
+		return
+	}
+	c.Data["TopicSort"] = string(topicOrder)
+	c.Data["Topics"] = topics
+
 	c.Success(EXPLORE_REPOS)
 }
 
@@ -67,10 +67,16 @@ func GlobalInit(customConf string) error {
 	}
 
 	email.NewContext()
+	if err := email.LoadTemplateOverrides(conf.Email.TemplatePath); err != nil {
+		log.Fatal("Failed to load mail template overrides: %v", err)
+	}
 
 	if conf.Security.InstallLock {
 		highlight.NewContext()
 		markup.NewSanitizer()
+		if err := markup.LoadCustomEmoji(conf.Markdown.CustomEmojiPath); err != nil {
+			log.Fatal("Failed to load custom emoji: %v", err)
+		}
 		if err := db.NewEngine(); err != nil {
 			log.Fatal("Failed to initialize ORM engine: %v", err)
 		}
@@ -84,6 +90,8 @@ func GlobalInit(customConf string) error {
 		db.InitSyncMirrors()
 		db.InitDeliverHooks()
 		db.InitTestPullRequests()
+		db.InitUpdateRepoLanguages()
+		db.InitSyncRepoSizes()
 	}
 	if conf.HasMinWinSvc {
 		log.Info("Builtin Windows Service is supported")
@@ -8,8 +8,10 @@ package lfs
 
 import (
 	"context"
+	"io"
 	"sync"
 
+	auth "gogs.io/gogs/internal/auth"
 	db "gogs.io/gogs/internal/db"
 	lfsutil "gogs.io/gogs/internal/lfsutil"
 )
@@ -61,7 +63,7 @@ func NewMockAccessTokensStore() *MockAccessTokensStore {
 			},
 		},
 		TouchFunc: &AccessTokensStoreTouchFunc{
-			defaultHook: func(context.Context, int64) (r0 error) {
+			defaultHook: func(context.Context, *db.AccessToken, string) (r0 error) {
 				return
 			},
 		},
@@ -94,7 +96,7 @@ func NewStrictMockAccessTokensStore() *MockAccessTokensStore {
 			},
 		},
 		TouchFunc: &AccessTokensStoreTouchFunc{
-			defaultHook: func(context.Context, int64) error {
+			defaultHook: func(context.Context, *db.AccessToken, string) error {
 				panic("unexpected invocation of MockAccessTokensStore.Touch")
 			},
 		},
@@ -563,24 +565,24 @@ func (c AccessTokensStoreListFuncCall) Results() []interface{} {
 // AccessTokensStoreTouchFunc describes the behavior when the Touch method
 // of the parent MockAccessTokensStore instance is invoked.
 type AccessTokensStoreTouchFunc struct {
-	defaultHook func(context.Context, int64) error
-	hooks       []func(context.Context, int64) error
+	defaultHook func(context.Context, *db.AccessToken, string) error
+	hooks       []func(context.Context, *db.AccessToken, string) error
 	history     []AccessTokensStoreTouchFuncCall
 	mutex       sync.Mutex
 }
 
 // Touch delegates to the next hook function in the queue and stores the
 // parameter and result values of this invocation.
-func (m *MockAccessTokensStore) Touch(v0 context.Context, v1 int64) error {
-	r0 := m.TouchFunc.nextHook()(v0, v1)
-	m.TouchFunc.appendCall(AccessTokensStoreTouchFuncCall{v0, v1, r0})
+func (m *MockAccessTokensStore) Touch(v0 context.Context, v1 *db.AccessToken, v2 string) error {
+	r0 := m.TouchFunc.nextHook()(v0, v1, v2)
+	m.TouchFunc.appendCall(AccessTokensStoreTouchFuncCall{v0, v1, v2, r0})
 	return r0
 }
 
 // SetDefaultHook sets function that is called when the Touch method of the
 // parent MockAccessTokensStore instance is invoked and the hook queue is
 // empty.
-func (f *AccessTokensStoreTouchFunc) SetDefaultHook(hook func(context.Context, int64) error) {
+func (f *AccessTokensStoreTouchFunc) SetDefaultHook(hook func(context.Context, *db.AccessToken, string) error) {
 	f.defaultHook = hook
 }
 
@@ -588,7 +590,7 @@ func (f *AccessTokensStoreTouchFunc) SetDefaultHook(hook func(context.Context, i
 // Touch method of the parent MockAccessTokensStore instance invokes the
 // hook at the front of the queue and discards it. After the queue is empty,
 // the default hook function is invoked for any future action.
-func (f *AccessTokensStoreTouchFunc) PushHook(hook func(context.Context, int64) error) {
+func (f *AccessTokensStoreTouchFunc) PushHook(hook func(context.Context, *db.AccessToken, string) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -597,19 +599,19 @@ func (f *AccessTokensStoreTouchFunc) PushHook(hook func(context.Context, int64)
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
 func (f *AccessTokensStoreTouchFunc) SetDefaultReturn(r0 error) {
-	f.SetDefaultHook(func(context.Context, int64) error {
+	f.SetDefaultHook(func(context.Context, *db.AccessToken, string) error {
 		return r0
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
 func (f *AccessTokensStoreTouchFunc) PushReturn(r0 error) {
-	f.PushHook(func(context.Context, int64) error {
+	f.PushHook(func(context.Context, *db.AccessToken, string) error {
 		return r0
 	})
 }
 
-func (f *AccessTokensStoreTouchFunc) nextHook() func(context.Context, int64) error {
+func (f *AccessTokensStoreTouchFunc) nextHook() func(context.Context, *db.AccessToken, string) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -647,7 +649,10 @@ type AccessTokensStoreTouchFuncCall struct {
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 int64
+	Arg1 *db.AccessToken
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 error
@@ -656,7 +661,7 @@ type AccessTokensStoreTouchFuncCall struct {
 // Args returns an interface slice containing the arguments of this
 // invocation.
 func (c AccessTokensStoreTouchFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1}
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
@@ -677,6 +682,15 @@ type MockLFSStore struct {
 	// GetObjectsByOIDsFunc is an instance of a mock function object
 	// controlling the behavior of the method GetObjectsByOIDs.
 	GetObjectsByOIDsFunc *LFSStoreGetObjectsByOIDsFunc
+	// ListObjectsByStorageFunc is an instance of a mock function object
+	// controlling the behavior of the method ListObjectsByStorage.
+	ListObjectsByStorageFunc *LFSStoreListObjectsByStorageFunc
+	// TotalSizeByRepoIDFunc is an instance of a mock function object
+	// controlling the behavior of the method TotalSizeByRepoID.
+	TotalSizeByRepoIDFunc *LFSStoreTotalSizeByRepoIDFunc
+	// UpdateObjectStorageFunc is an instance of a mock function object
+	// controlling the behavior of the method UpdateObjectStorage.
+	UpdateObjectStorageFunc *LFSStoreUpdateObjectStorageFunc
 }
 
 // NewMockLFSStore creates a new mock of the LFSStore interface. All methods
@@ -698,6 +712,21 @@ func NewMockLFSStore() *MockLFSStore {
 				return
 			},
 		},
+		ListObjectsByStorageFunc: &LFSStoreListObjectsByStorageFunc{
+			defaultHook: func(context.Context, lfsutil.Storage) (r0 []*db.LFSObject, r1 error) {
+				return
+			},
+		},
+		TotalSizeByRepoIDFunc: &LFSStoreTotalSizeByRepoIDFunc{
+			defaultHook: func(context.Context, int64) (r0 int64, r1 error) {
+				return
+			},
+		},
+		UpdateObjectStorageFunc: &LFSStoreUpdateObjectStorageFunc{
+			defaultHook: func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) (r0 error) {
+				return
+			},
+		},
 	}
 }
 
@@ -720,6 +749,21 @@ func NewStrictMockLFSStore() *MockLFSStore {
 				panic("unexpected invocation of MockLFSStore.GetObjectsByOIDs")
 			},
 		},
+		ListObjectsByStorageFunc: &LFSStoreListObjectsByStorageFunc{
+			defaultHook: func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error) {
+				panic("unexpected invocation of MockLFSStore.ListObjectsByStorage")
+			},
+		},
+		TotalSizeByRepoIDFunc: &LFSStoreTotalSizeByRepoIDFunc{
+			defaultHook: func(context.Context, int64) (int64, error) {
+				panic("unexpected invocation of MockLFSStore.TotalSizeByRepoID")
+			},
+		},
+		UpdateObjectStorageFunc: &LFSStoreUpdateObjectStorageFunc{
+			defaultHook: func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error {
+				panic("unexpected invocation of MockLFSStore.UpdateObjectStorage")
+			},
+		},
 	}
 }
 
@@ -736,6 +780,15 @@ func NewMockLFSStoreFrom(i db.LFSStore) *MockLFSStore {
 		GetObjectsByOIDsFunc: &LFSStoreGetObjectsByOIDsFunc{
 			defaultHook: i.GetObjectsByOIDs,
 		},
+		ListObjectsByStorageFunc: &LFSStoreListObjectsByStorageFunc{
+			defaultHook: i.ListObjectsByStorage,
+		},
+		TotalSizeByRepoIDFunc: &LFSStoreTotalSizeByRepoIDFunc{
+			defaultHook: i.TotalSizeByRepoID,
+		},
+		UpdateObjectStorageFunc: &LFSStoreUpdateObjectStorageFunc{
+			defaultHook: i.UpdateObjectStorage,
+		},
 	}
 }
 
@@ -1082,109 +1135,36 @@ func (c LFSStoreGetObjectsByOIDsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
-// MockPermsStore is a mock implementation of the PermsStore interface (from
-// the package gogs.io/gogs/internal/db) used for unit testing.
-type MockPermsStore struct {
-	// AccessModeFunc is an instance of a mock function object controlling
-	// the behavior of the method AccessMode.
-	AccessModeFunc *PermsStoreAccessModeFunc
-	// AuthorizeFunc is an instance of a mock function object controlling
-	// the behavior of the method Authorize.
-	AuthorizeFunc *PermsStoreAuthorizeFunc
-	// SetRepoPermsFunc is an instance of a mock function object controlling
-	// the behavior of the method SetRepoPerms.
-	SetRepoPermsFunc *PermsStoreSetRepoPermsFunc
-}
-
-// NewMockPermsStore creates a new mock of the PermsStore interface. All
-// methods return zero values for all results, unless overwritten.
-func NewMockPermsStore() *MockPermsStore {
-	return &MockPermsStore{
-		AccessModeFunc: &PermsStoreAccessModeFunc{
-			defaultHook: func(context.Context, int64, int64, db.AccessModeOptions) (r0 db.AccessMode) {
-				return
-			},
-		},
-		AuthorizeFunc: &PermsStoreAuthorizeFunc{
-			defaultHook: func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) (r0 bool) {
-				return
-			},
-		},
-		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
-			defaultHook: func(context.Context, int64, map[int64]db.AccessMode) (r0 error) {
-				return
-			},
-		},
-	}
-}
-
-// NewStrictMockPermsStore creates a new mock of the PermsStore interface.
-// All methods panic on invocation, unless overwritten.
-func NewStrictMockPermsStore() *MockPermsStore {
-	return &MockPermsStore{
-		AccessModeFunc: &PermsStoreAccessModeFunc{
-			defaultHook: func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
-				panic("unexpected invocation of MockPermsStore.AccessMode")
-			},
-		},
-		AuthorizeFunc: &PermsStoreAuthorizeFunc{
-			defaultHook: func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
-				panic("unexpected invocation of MockPermsStore.Authorize")
-			},
-		},
-		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
-			defaultHook: func(context.Context, int64, map[int64]db.AccessMode) error {
-				panic("unexpected invocation of MockPermsStore.SetRepoPerms")
-			},
-		},
-	}
-}
-
-// NewMockPermsStoreFrom creates a new mock of the MockPermsStore interface.
-// All methods delegate to the given implementation, unless overwritten.
-func NewMockPermsStoreFrom(i db.PermsStore) *MockPermsStore {
-	return &MockPermsStore{
-		AccessModeFunc: &PermsStoreAccessModeFunc{
-			defaultHook: i.AccessMode,
-		},
-		AuthorizeFunc: &PermsStoreAuthorizeFunc{
-			defaultHook: i.Authorize,
-		},
-		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
-			defaultHook: i.SetRepoPerms,
-		},
-	}
-}
-
-// PermsStoreAccessModeFunc describes the behavior when the AccessMode
-// method of the parent MockPermsStore instance is invoked.
-type PermsStoreAccessModeFunc struct {
-	defaultHook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode
-	hooks       []func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode
-	history     []PermsStoreAccessModeFuncCall
+// LFSStoreListObjectsByStorageFunc describes the behavior when the
+// ListObjectsByStorage method of the parent MockLFSStore instance is
+// invoked.
+type LFSStoreListObjectsByStorageFunc struct {
+	defaultHook func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error)
+	hooks       []func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error)
+	history     []LFSStoreListObjectsByStorageFuncCall
 	mutex       sync.Mutex
 }
 
-// AccessMode delegates to the next hook function in the queue and stores
-// the parameter and result values of this invocation.
-func (m *MockPermsStore) AccessMode(v0 context.Context, v1 int64, v2 int64, v3 db.AccessModeOptions) db.AccessMode {
-	r0 := m.AccessModeFunc.nextHook()(v0, v1, v2, v3)
-	m.AccessModeFunc.appendCall(PermsStoreAccessModeFuncCall{v0, v1, v2, v3, r0})
-	return r0
+// ListObjectsByStorage delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLFSStore) ListObjectsByStorage(v0 context.Context, v1 lfsutil.Storage) ([]*db.LFSObject, error) {
+	r0, r1 := m.ListObjectsByStorageFunc.nextHook()(v0, v1)
+	m.ListObjectsByStorageFunc.appendCall(LFSStoreListObjectsByStorageFuncCall{v0, v1, r0, r1})
+	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the AccessMode method of
-// the parent MockPermsStore instance is invoked and the hook queue is
-// empty.
-func (f *PermsStoreAccessModeFunc) SetDefaultHook(hook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode) {
+// SetDefaultHook sets function that is called when the ListObjectsByStorage
+// method of the parent MockLFSStore instance is invoked and the hook queue
+// is empty.
+func (f *LFSStoreListObjectsByStorageFunc) SetDefaultHook(hook func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// AccessMode method of the parent MockPermsStore instance invokes the hook
-// at the front of the queue and discards it. After the queue is empty, the
-// default hook function is invoked for any future action.
-func (f *PermsStoreAccessModeFunc) PushHook(hook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode) {
+// ListObjectsByStorage method of the parent MockLFSStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *LFSStoreListObjectsByStorageFunc) PushHook(hook func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1192,20 +1172,20 @@ func (f *PermsStoreAccessModeFunc) PushHook(hook func(context.Context, int64, in
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *PermsStoreAccessModeFunc) SetDefaultReturn(r0 db.AccessMode) {
-	f.SetDefaultHook(func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
-		return r0
+func (f *LFSStoreListObjectsByStorageFunc) SetDefaultReturn(r0 []*db.LFSObject, r1 error) {
+	f.SetDefaultHook(func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error) {
+		return r0, r1
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *PermsStoreAccessModeFunc) PushReturn(r0 db.AccessMode) {
-	f.PushHook(func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
-		return r0
+func (f *LFSStoreListObjectsByStorageFunc) PushReturn(r0 []*db.LFSObject, r1 error) {
+	f.PushHook(func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error) {
+		return r0, r1
 	})
 }
 
-func (f *PermsStoreAccessModeFunc) nextHook() func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
+func (f *LFSStoreListObjectsByStorageFunc) nextHook() func(context.Context, lfsutil.Storage) ([]*db.LFSObject, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1218,84 +1198,79 @@ func (f *PermsStoreAccessModeFunc) nextHook() func(context.Context, int64, int64
 	return hook
 }
 
-func (f *PermsStoreAccessModeFunc) appendCall(r0 PermsStoreAccessModeFuncCall) {
+func (f *LFSStoreListObjectsByStorageFunc) appendCall(r0 LFSStoreListObjectsByStorageFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of PermsStoreAccessModeFuncCall objects
+// History returns a sequence of LFSStoreListObjectsByStorageFuncCall objects
 // describing the invocations of this function.
-func (f *PermsStoreAccessModeFunc) History() []PermsStoreAccessModeFuncCall {
+func (f *LFSStoreListObjectsByStorageFunc) History() []LFSStoreListObjectsByStorageFuncCall {
 	f.mutex.Lock()
-	history := make([]PermsStoreAccessModeFuncCall, len(f.history))
+	history := make([]LFSStoreListObjectsByStorageFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// PermsStoreAccessModeFuncCall is an object that describes an invocation of
-// method AccessMode on an instance of MockPermsStore.
-type PermsStoreAccessModeFuncCall struct {
-	// Arg0 is the value of the 1st argument passed to this method
-	// invocation.
+// LFSStoreListObjectsByStorageFuncCall is an object that describes an
+// invocation of method ListObjectsByStorage on an instance of MockLFSStore.
+type LFSStoreListObjectsByStorageFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
 	Arg0 context.Context
-	// Arg1 is the value of the 2nd argument passed to this method
-	// invocation.
-	Arg1 int64
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 int64
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 db.AccessModeOptions
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
+	Arg1 lfsutil.Storage
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 db.AccessMode
+	Result0 []*db.LFSObject
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c PermsStoreAccessModeFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+func (c LFSStoreListObjectsByStorageFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c PermsStoreAccessModeFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0}
+func (c LFSStoreListObjectsByStorageFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
-// PermsStoreAuthorizeFunc describes the behavior when the Authorize method
-// of the parent MockPermsStore instance is invoked.
-type PermsStoreAuthorizeFunc struct {
-	defaultHook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool
-	hooks       []func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool
-	history     []PermsStoreAuthorizeFuncCall
+// LFSStoreTotalSizeByRepoIDFunc describes the behavior when the
+// TotalSizeByRepoID method of the parent MockLFSStore instance is invoked.
+type LFSStoreTotalSizeByRepoIDFunc struct {
+	defaultHook func(context.Context, int64) (int64, error)
+	hooks       []func(context.Context, int64) (int64, error)
+	history     []LFSStoreTotalSizeByRepoIDFuncCall
 	mutex       sync.Mutex
 }
 
-// Authorize delegates to the next hook function in the queue and stores the
-// parameter and result values of this invocation.
-func (m *MockPermsStore) Authorize(v0 context.Context, v1 int64, v2 int64, v3 db.AccessMode, v4 db.AccessModeOptions) bool {
-	r0 := m.AuthorizeFunc.nextHook()(v0, v1, v2, v3, v4)
-	m.AuthorizeFunc.appendCall(PermsStoreAuthorizeFuncCall{v0, v1, v2, v3, v4, r0})
-	return r0
+// TotalSizeByRepoID delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLFSStore) TotalSizeByRepoID(v0 context.Context, v1 int64) (int64, error) {
+	r0, r1 := m.TotalSizeByRepoIDFunc.nextHook()(v0, v1)
+	m.TotalSizeByRepoIDFunc.appendCall(LFSStoreTotalSizeByRepoIDFuncCall{v0, v1, r0, r1})
+	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the Authorize method of
-// the parent MockPermsStore instance is invoked and the hook queue is
-// empty.
-func (f *PermsStoreAuthorizeFunc) SetDefaultHook(hook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool) {
+// SetDefaultHook sets function that is called when the TotalSizeByRepoID
+// method of the parent MockLFSStore instance is invoked and the hook queue
+// is empty.
+func (f *LFSStoreTotalSizeByRepoIDFunc) SetDefaultHook(hook func(context.Context, int64) (int64, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// Authorize method of the parent MockPermsStore instance invokes the hook
-// at the front of the queue and discards it. After the queue is empty, the
-// default hook function is invoked for any future action.
-func (f *PermsStoreAuthorizeFunc) PushHook(hook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool) {
+// TotalSizeByRepoID method of the parent MockLFSStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *LFSStoreTotalSizeByRepoIDFunc) PushHook(hook func(context.Context, int64) (int64, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1303,20 +1278,20 @@ func (f *PermsStoreAuthorizeFunc) PushHook(hook func(context.Context, int64, int
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *PermsStoreAuthorizeFunc) SetDefaultReturn(r0 bool) {
-	f.SetDefaultHook(func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
-		return r0
+func (f *LFSStoreTotalSizeByRepoIDFunc) SetDefaultReturn(r0 int64, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64) (int64, error) {
+		return r0, r1
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *PermsStoreAuthorizeFunc) PushReturn(r0 bool) {
-	f.PushHook(func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
-		return r0
+func (f *LFSStoreTotalSizeByRepoIDFunc) PushReturn(r0 int64, r1 error) {
+	f.PushHook(func(context.Context, int64) (int64, error) {
+		return r0, r1
 	})
 }
 
-func (f *PermsStoreAuthorizeFunc) nextHook() func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
+func (f *LFSStoreTotalSizeByRepoIDFunc) nextHook() func(context.Context, int64) (int64, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1329,87 +1304,81 @@ func (f *PermsStoreAuthorizeFunc) nextHook() func(context.Context, int64, int64,
 	return hook
 }
 
-func (f *PermsStoreAuthorizeFunc) appendCall(r0 PermsStoreAuthorizeFuncCall) {
+func (f *LFSStoreTotalSizeByRepoIDFunc) appendCall(r0 LFSStoreTotalSizeByRepoIDFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of PermsStoreAuthorizeFuncCall objects
+// History returns a sequence of LFSStoreTotalSizeByRepoIDFuncCall objects
 // describing the invocations of this function.
-func (f *PermsStoreAuthorizeFunc) History() []PermsStoreAuthorizeFuncCall {
+func (f *LFSStoreTotalSizeByRepoIDFunc) History() []LFSStoreTotalSizeByRepoIDFuncCall {
 	f.mutex.Lock()
-	history := make([]PermsStoreAuthorizeFuncCall, len(f.history))
+	history := make([]LFSStoreTotalSizeByRepoIDFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// PermsStoreAuthorizeFuncCall is an object that describes an invocation of
-// method Authorize on an instance of MockPermsStore.
-type PermsStoreAuthorizeFuncCall struct {
+// LFSStoreTotalSizeByRepoIDFuncCall is an object that describes an
+// invocation of method TotalSizeByRepoID on an instance of MockLFSStore.
+type LFSStoreTotalSizeByRepoIDFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
 	Arg1 int64
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 int64
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 db.AccessMode
-	// Arg4 is the value of the 5th argument passed to this method
-	// invocation.
-	Arg4 db.AccessModeOptions
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 bool
+	Result0 int64
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c PermsStoreAuthorizeFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+func (c LFSStoreTotalSizeByRepoIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c PermsStoreAuthorizeFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0}
+func (c LFSStoreTotalSizeByRepoIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
-// PermsStoreSetRepoPermsFunc describes the behavior when the SetRepoPerms
-// method of the parent MockPermsStore instance is invoked.
-type PermsStoreSetRepoPermsFunc struct {
-	defaultHook func(context.Context, int64, map[int64]db.AccessMode) error
-	hooks       []func(context.Context, int64, map[int64]db.AccessMode) error
-	history     []PermsStoreSetRepoPermsFuncCall
+// LFSStoreUpdateObjectStorageFunc describes the behavior when the
+// UpdateObjectStorage method of the parent MockLFSStore instance is invoked.
+type LFSStoreUpdateObjectStorageFunc struct {
+	defaultHook func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error
+	hooks       []func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error
+	history     []LFSStoreUpdateObjectStorageFuncCall
 	mutex       sync.Mutex
 }
 
-// SetRepoPerms delegates to the next hook function in the queue and stores
-// the parameter and result values of this invocation.
-func (m *MockPermsStore) SetRepoPerms(v0 context.Context, v1 int64, v2 map[int64]db.AccessMode) error {
-	r0 := m.SetRepoPermsFunc.nextHook()(v0, v1, v2)
-	m.SetRepoPermsFunc.appendCall(PermsStoreSetRepoPermsFuncCall{v0, v1, v2, r0})
+// UpdateObjectStorage delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockLFSStore) UpdateObjectStorage(v0 context.Context, v1 lfsutil.OID, v2 lfsutil.Storage, v3 lfsutil.Storage) error {
+	r0 := m.UpdateObjectStorageFunc.nextHook()(v0, v1, v2, v3)
+	m.UpdateObjectStorageFunc.appendCall(LFSStoreUpdateObjectStorageFuncCall{v0, v1, v2, v3, r0})
 	return r0
 }
 
-// SetDefaultHook sets function that is called when the SetRepoPerms method
-// of the parent MockPermsStore instance is invoked and the hook queue is
-// empty.
-func (f *PermsStoreSetRepoPermsFunc) SetDefaultHook(hook func(context.Context, int64, map[int64]db.AccessMode) error) {
+// SetDefaultHook sets function that is called when the UpdateObjectStorage
+// method of the parent MockLFSStore instance is invoked and the hook queue
+// is empty.
+func (f *LFSStoreUpdateObjectStorageFunc) SetDefaultHook(hook func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// SetRepoPerms method of the parent MockPermsStore instance invokes the
+// UpdateObjectStorage method of the parent MockLFSStore instance invokes the
 // hook at the front of the queue and discards it. After the queue is empty,
 // the default hook function is invoked for any future action.
-func (f *PermsStoreSetRepoPermsFunc) PushHook(hook func(context.Context, int64, map[int64]db.AccessMode) error) {
+func (f *LFSStoreUpdateObjectStorageFunc) PushHook(hook func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1417,20 +1386,20 @@ func (f *PermsStoreSetRepoPermsFunc) PushHook(hook func(context.Context, int64,
 
 // SetDefaultReturn calls SetDefaultHook with a function that returns the
 // given values.
-func (f *PermsStoreSetRepoPermsFunc) SetDefaultReturn(r0 error) {
-	f.SetDefaultHook(func(context.Context, int64, map[int64]db.AccessMode) error {
+func (f *LFSStoreUpdateObjectStorageFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error {
 		return r0
 	})
 }
 
 // PushReturn calls PushHook with a function that returns the given values.
-func (f *PermsStoreSetRepoPermsFunc) PushReturn(r0 error) {
-	f.PushHook(func(context.Context, int64, map[int64]db.AccessMode) error {
+func (f *LFSStoreUpdateObjectStorageFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error {
 		return r0
 	})
 }
 
-func (f *PermsStoreSetRepoPermsFunc) nextHook() func(context.Context, int64, map[int64]db.AccessMode) error {
+func (f *LFSStoreUpdateObjectStorageFunc) nextHook() func(context.Context, lfsutil.OID, lfsutil.Storage, lfsutil.Storage) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1443,35 +1412,34 @@ func (f *PermsStoreSetRepoPermsFunc) nextHook() func(context.Context, int64, map
 	return hook
 }
 
-func (f *PermsStoreSetRepoPermsFunc) appendCall(r0 PermsStoreSetRepoPermsFuncCall) {
+func (f *LFSStoreUpdateObjectStorageFunc) appendCall(r0 LFSStoreUpdateObjectStorageFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of PermsStoreSetRepoPermsFuncCall objects
+// History returns a sequence of LFSStoreUpdateObjectStorageFuncCall objects
 // describing the invocations of this function.
-func (f *PermsStoreSetRepoPermsFunc) History() []PermsStoreSetRepoPermsFuncCall {
+func (f *LFSStoreUpdateObjectStorageFunc) History() []LFSStoreUpdateObjectStorageFuncCall {
 	f.mutex.Lock()
-	history := make([]PermsStoreSetRepoPermsFuncCall, len(f.history))
+	history := make([]LFSStoreUpdateObjectStorageFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// PermsStoreSetRepoPermsFuncCall is an object that describes an invocation
-// of method SetRepoPerms on an instance of MockPermsStore.
-type PermsStoreSetRepoPermsFuncCall struct {
-	// Arg0 is the value of the 1st argument passed to this method
-	// invocation.
+// LFSStoreUpdateObjectStorageFuncCall is an object that describes an
+// invocation of method UpdateObjectStorage on an instance of MockLFSStore.
+type LFSStoreUpdateObjectStorageFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
 	Arg0 context.Context
-	// Arg1 is the value of the 2nd argument passed to this method
-	// invocation.
-	Arg1 int64
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 map[int64]db.AccessMode
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
+	Arg1 lfsutil.OID
+	// Arg2 is the value of the 3rd argument passed to this method invocation.
+	Arg2 lfsutil.Storage
+	// Arg3 is the value of the 4th argument passed to this method invocation.
+	Arg3 lfsutil.Storage
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 error
@@ -1479,201 +1447,900 @@ type PermsStoreSetRepoPermsFuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c PermsStoreSetRepoPermsFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+func (c LFSStoreUpdateObjectStorageFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c PermsStoreSetRepoPermsFuncCall) Results() []interface{} {
+func (c LFSStoreUpdateObjectStorageFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
-// MockReposStore is a mock implementation of the ReposStore interface (from
+// MockPermsStore is a mock implementation of the PermsStore interface (from
 // the package gogs.io/gogs/internal/db) used for unit testing.
-type MockReposStore struct {
-	// CreateFunc is an instance of a mock function object controlling the
-	// behavior of the method Create.
-	CreateFunc *ReposStoreCreateFunc
-	// GetByCollaboratorIDFunc is an instance of a mock function object
-	// controlling the behavior of the method GetByCollaboratorID.
-	GetByCollaboratorIDFunc *ReposStoreGetByCollaboratorIDFunc
-	// GetByCollaboratorIDWithAccessModeFunc is an instance of a mock
-	// function object controlling the behavior of the method
-	// GetByCollaboratorIDWithAccessMode.
-	GetByCollaboratorIDWithAccessModeFunc *ReposStoreGetByCollaboratorIDWithAccessModeFunc
-	// GetByIDFunc is an instance of a mock function object controlling the
-	// behavior of the method GetByID.
-	GetByIDFunc *ReposStoreGetByIDFunc
-	// GetByNameFunc is an instance of a mock function object controlling
-	// the behavior of the method GetByName.
-	GetByNameFunc *ReposStoreGetByNameFunc
-	// HasForkedByFunc is an instance of a mock function object controlling
-	// the behavior of the method HasForkedBy.
-	HasForkedByFunc *ReposStoreHasForkedByFunc
-	// ListWatchesFunc is an instance of a mock function object controlling
-	// the behavior of the method ListWatches.
-	ListWatchesFunc *ReposStoreListWatchesFunc
-	// StarFunc is an instance of a mock function object controlling the
-	// behavior of the method Star.
-	StarFunc *ReposStoreStarFunc
-	// TouchFunc is an instance of a mock function object controlling the
-	// behavior of the method Touch.
-	TouchFunc *ReposStoreTouchFunc
-	// WatchFunc is an instance of a mock function object controlling the
-	// behavior of the method Watch.
-	WatchFunc *ReposStoreWatchFunc
+type MockPermsStore struct {
+	// AccessModeFunc is an instance of a mock function object controlling
+	// the behavior of the method AccessMode.
+	AccessModeFunc *PermsStoreAccessModeFunc
+	// AuthorizeFunc is an instance of a mock function object controlling
+	// the behavior of the method Authorize.
+	AuthorizeFunc *PermsStoreAuthorizeFunc
+	// SetRepoPermsFunc is an instance of a mock function object controlling
+	// the behavior of the method SetRepoPerms.
+	SetRepoPermsFunc *PermsStoreSetRepoPermsFunc
 }
 
-// NewMockReposStore creates a new mock of the ReposStore interface. All
+// NewMockPermsStore creates a new mock of the PermsStore interface. All
 // methods return zero values for all results, unless overwritten.
-func NewMockReposStore() *MockReposStore {
-	return &MockReposStore{
-		CreateFunc: &ReposStoreCreateFunc{
-			defaultHook: func(context.Context, int64, db.CreateRepoOptions) (r0 *db.Repository, r1 error) {
-				return
-			},
-		},
-		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
-			defaultHook: func(context.Context, int64, int, string) (r0 []*db.Repository, r1 error) {
+func NewMockPermsStore() *MockPermsStore {
+	return &MockPermsStore{
+		AccessModeFunc: &PermsStoreAccessModeFunc{
+			defaultHook: func(context.Context, int64, int64, db.AccessModeOptions) (r0 db.AccessMode) {
 				return
 			},
 		},
-		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
-			defaultHook: func(context.Context, int64) (r0 map[*db.Repository]db.AccessMode, r1 error) {
+		AuthorizeFunc: &PermsStoreAuthorizeFunc{
+			defaultHook: func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) (r0 bool) {
 				return
 			},
 		},
-		GetByIDFunc: &ReposStoreGetByIDFunc{
-			defaultHook: func(context.Context, int64) (r0 *db.Repository, r1 error) {
+		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
+			defaultHook: func(context.Context, int64, map[int64]db.AccessMode) (r0 error) {
 				return
 			},
 		},
-		GetByNameFunc: &ReposStoreGetByNameFunc{
-			defaultHook: func(context.Context, int64, string) (r0 *db.Repository, r1 error) {
-				return
+	}
+}
+
+// NewStrictMockPermsStore creates a new mock of the PermsStore interface.
+// All methods panic on invocation, unless overwritten.
+func NewStrictMockPermsStore() *MockPermsStore {
+	return &MockPermsStore{
+		AccessModeFunc: &PermsStoreAccessModeFunc{
+			defaultHook: func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
+				panic("unexpected invocation of MockPermsStore.AccessMode")
 			},
 		},
-		HasForkedByFunc: &ReposStoreHasForkedByFunc{
-			defaultHook: func(context.Context, int64, int64) (r0 bool) {
-				return
+		AuthorizeFunc: &PermsStoreAuthorizeFunc{
+			defaultHook: func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
+				panic("unexpected invocation of MockPermsStore.Authorize")
 			},
 		},
-		ListWatchesFunc: &ReposStoreListWatchesFunc{
-			defaultHook: func(context.Context, int64) (r0 []*db.Watch, r1 error) {
-				return
+		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
+			defaultHook: func(context.Context, int64, map[int64]db.AccessMode) error {
+				panic("unexpected invocation of MockPermsStore.SetRepoPerms")
 			},
 		},
-		StarFunc: &ReposStoreStarFunc{
-			defaultHook: func(context.Context, int64, int64) (r0 error) {
-				return
-			},
+	}
+}
+
+// NewMockPermsStoreFrom creates a new mock of the MockPermsStore interface.
+// All methods delegate to the given implementation, unless overwritten.
+func NewMockPermsStoreFrom(i db.PermsStore) *MockPermsStore {
+	return &MockPermsStore{
+		AccessModeFunc: &PermsStoreAccessModeFunc{
+			defaultHook: i.AccessMode,
 		},
-		TouchFunc: &ReposStoreTouchFunc{
-			defaultHook: func(context.Context, int64) (r0 error) {
-				return
-			},
+		AuthorizeFunc: &PermsStoreAuthorizeFunc{
+			defaultHook: i.Authorize,
 		},
-		WatchFunc: &ReposStoreWatchFunc{
-			defaultHook: func(context.Context, int64, int64) (r0 error) {
-				return
-			},
+		SetRepoPermsFunc: &PermsStoreSetRepoPermsFunc{
+			defaultHook: i.SetRepoPerms,
 		},
 	}
 }
 
-// NewStrictMockReposStore creates a new mock of the ReposStore interface.
-// All methods panic on invocation, unless overwritten.
-func NewStrictMockReposStore() *MockReposStore {
-	return &MockReposStore{
-		CreateFunc: &ReposStoreCreateFunc{
-			defaultHook: func(context.Context, int64, db.CreateRepoOptions) (*db.Repository, error) {
-				panic("unexpected invocation of MockReposStore.Create")
-			},
-		},
-		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
-			defaultHook: func(context.Context, int64, int, string) ([]*db.Repository, error) {
-				panic("unexpected invocation of MockReposStore.GetByCollaboratorID")
-			},
-		},
-		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
-			defaultHook: func(context.Context, int64) (map[*db.Repository]db.AccessMode, error) {
-				panic("unexpected invocation of MockReposStore.GetByCollaboratorIDWithAccessMode")
-			},
-		},
-		GetByIDFunc: &ReposStoreGetByIDFunc{
-			defaultHook: func(context.Context, int64) (*db.Repository, error) {
-				panic("unexpected invocation of MockReposStore.GetByID")
-			},
-		},
-		GetByNameFunc: &ReposStoreGetByNameFunc{
-			defaultHook: func(context.Context, int64, string) (*db.Repository, error) {
-				panic("unexpected invocation of MockReposStore.GetByName")
-			},
-		},
-		HasForkedByFunc: &ReposStoreHasForkedByFunc{
-			defaultHook: func(context.Context, int64, int64) bool {
-				panic("unexpected invocation of MockReposStore.HasForkedBy")
-			},
-		},
-		ListWatchesFunc: &ReposStoreListWatchesFunc{
-			defaultHook: func(context.Context, int64) ([]*db.Watch, error) {
-				panic("unexpected invocation of MockReposStore.ListWatches")
-			},
-		},
-		StarFunc: &ReposStoreStarFunc{
-			defaultHook: func(context.Context, int64, int64) error {
-				panic("unexpected invocation of MockReposStore.Star")
-			},
-		},
-		TouchFunc: &ReposStoreTouchFunc{
-			defaultHook: func(context.Context, int64) error {
-				panic("unexpected invocation of MockReposStore.Touch")
-			},
-		},
-		WatchFunc: &ReposStoreWatchFunc{
-			defaultHook: func(context.Context, int64, int64) error {
-				panic("unexpected invocation of MockReposStore.Watch")
-			},
-		},
-	}
+// PermsStoreAccessModeFunc describes the behavior when the AccessMode
+// method of the parent MockPermsStore instance is invoked.
+type PermsStoreAccessModeFunc struct {
+	defaultHook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode
+	hooks       []func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode
+	history     []PermsStoreAccessModeFuncCall
+	mutex       sync.Mutex
+}
+
+// AccessMode delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockPermsStore) AccessMode(v0 context.Context, v1 int64, v2 int64, v3 db.AccessModeOptions) db.AccessMode {
+	r0 := m.AccessModeFunc.nextHook()(v0, v1, v2, v3)
+	m.AccessModeFunc.appendCall(PermsStoreAccessModeFuncCall{v0, v1, v2, v3, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the AccessMode method of
+// the parent MockPermsStore instance is invoked and the hook queue is
+// empty.
+func (f *PermsStoreAccessModeFunc) SetDefaultHook(hook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// AccessMode method of the parent MockPermsStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *PermsStoreAccessModeFunc) PushHook(hook func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *PermsStoreAccessModeFunc) SetDefaultReturn(r0 db.AccessMode) {
+	f.SetDefaultHook(func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *PermsStoreAccessModeFunc) PushReturn(r0 db.AccessMode) {
+	f.PushHook(func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
+		return r0
+	})
+}
+
+func (f *PermsStoreAccessModeFunc) nextHook() func(context.Context, int64, int64, db.AccessModeOptions) db.AccessMode {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *PermsStoreAccessModeFunc) appendCall(r0 PermsStoreAccessModeFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of PermsStoreAccessModeFuncCall objects
+// describing the invocations of this function.
+func (f *PermsStoreAccessModeFunc) History() []PermsStoreAccessModeFuncCall {
+	f.mutex.Lock()
+	history := make([]PermsStoreAccessModeFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// PermsStoreAccessModeFuncCall is an object that describes an invocation of
+// method AccessMode on an instance of MockPermsStore.
+type PermsStoreAccessModeFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int64
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 db.AccessModeOptions
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 db.AccessMode
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c PermsStoreAccessModeFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c PermsStoreAccessModeFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// PermsStoreAuthorizeFunc describes the behavior when the Authorize method
+// of the parent MockPermsStore instance is invoked.
+type PermsStoreAuthorizeFunc struct {
+	defaultHook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool
+	hooks       []func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool
+	history     []PermsStoreAuthorizeFuncCall
+	mutex       sync.Mutex
+}
+
+// Authorize delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockPermsStore) Authorize(v0 context.Context, v1 int64, v2 int64, v3 db.AccessMode, v4 db.AccessModeOptions) bool {
+	r0 := m.AuthorizeFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.AuthorizeFunc.appendCall(PermsStoreAuthorizeFuncCall{v0, v1, v2, v3, v4, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the Authorize method of
+// the parent MockPermsStore instance is invoked and the hook queue is
+// empty.
+func (f *PermsStoreAuthorizeFunc) SetDefaultHook(hook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Authorize method of the parent MockPermsStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *PermsStoreAuthorizeFunc) PushHook(hook func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *PermsStoreAuthorizeFunc) SetDefaultReturn(r0 bool) {
+	f.SetDefaultHook(func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *PermsStoreAuthorizeFunc) PushReturn(r0 bool) {
+	f.PushHook(func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
+		return r0
+	})
+}
+
+func (f *PermsStoreAuthorizeFunc) nextHook() func(context.Context, int64, int64, db.AccessMode, db.AccessModeOptions) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *PermsStoreAuthorizeFunc) appendCall(r0 PermsStoreAuthorizeFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of PermsStoreAuthorizeFuncCall objects
+// describing the invocations of this function.
+func (f *PermsStoreAuthorizeFunc) History() []PermsStoreAuthorizeFuncCall {
+	f.mutex.Lock()
+	history := make([]PermsStoreAuthorizeFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// PermsStoreAuthorizeFuncCall is an object that describes an invocation of
+// method Authorize on an instance of MockPermsStore.
+type PermsStoreAuthorizeFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int64
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 db.AccessMode
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 db.AccessModeOptions
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 bool
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c PermsStoreAuthorizeFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c PermsStoreAuthorizeFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// PermsStoreSetRepoPermsFunc describes the behavior when the SetRepoPerms
+// method of the parent MockPermsStore instance is invoked.
+type PermsStoreSetRepoPermsFunc struct {
+	defaultHook func(context.Context, int64, map[int64]db.AccessMode) error
+	hooks       []func(context.Context, int64, map[int64]db.AccessMode) error
+	history     []PermsStoreSetRepoPermsFuncCall
+	mutex       sync.Mutex
+}
+
+// SetRepoPerms delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockPermsStore) SetRepoPerms(v0 context.Context, v1 int64, v2 map[int64]db.AccessMode) error {
+	r0 := m.SetRepoPermsFunc.nextHook()(v0, v1, v2)
+	m.SetRepoPermsFunc.appendCall(PermsStoreSetRepoPermsFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the SetRepoPerms method
+// of the parent MockPermsStore instance is invoked and the hook queue is
+// empty.
+func (f *PermsStoreSetRepoPermsFunc) SetDefaultHook(hook func(context.Context, int64, map[int64]db.AccessMode) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// SetRepoPerms method of the parent MockPermsStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *PermsStoreSetRepoPermsFunc) PushHook(hook func(context.Context, int64, map[int64]db.AccessMode) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *PermsStoreSetRepoPermsFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int64, map[int64]db.AccessMode) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *PermsStoreSetRepoPermsFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int64, map[int64]db.AccessMode) error {
+		return r0
+	})
+}
+
+func (f *PermsStoreSetRepoPermsFunc) nextHook() func(context.Context, int64, map[int64]db.AccessMode) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *PermsStoreSetRepoPermsFunc) appendCall(r0 PermsStoreSetRepoPermsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of PermsStoreSetRepoPermsFuncCall objects
+// describing the invocations of this function.
+func (f *PermsStoreSetRepoPermsFunc) History() []PermsStoreSetRepoPermsFuncCall {
+	f.mutex.Lock()
+	history := make([]PermsStoreSetRepoPermsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// PermsStoreSetRepoPermsFuncCall is an object that describes an invocation
+// of method SetRepoPerms on an instance of MockPermsStore.
+type PermsStoreSetRepoPermsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 map[int64]db.AccessMode
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c PermsStoreSetRepoPermsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c PermsStoreSetRepoPermsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// MockReposStore is a mock implementation of the ReposStore interface (from
+// the package gogs.io/gogs/internal/db) used for unit testing.
+type MockReposStore struct {
+	// AutoWatchFunc is an instance of a mock function object controlling
+	// the behavior of the method AutoWatch.
+	AutoWatchFunc *ReposStoreAutoWatchFunc
+	// CountIssuesByRepoFunc is an instance of a mock function object controlling
+	// the behavior of the method CountIssuesByRepo.
+	CountIssuesByRepoFunc *ReposStoreCountIssuesByRepoFunc
+	// CreateFunc is an instance of a mock function object controlling the
+	// behavior of the method Create.
+	CreateFunc *ReposStoreCreateFunc
+	// GetByCollaboratorIDFunc is an instance of a mock function object
+	// controlling the behavior of the method GetByCollaboratorID.
+	GetByCollaboratorIDFunc *ReposStoreGetByCollaboratorIDFunc
+	// GetByCollaboratorIDWithAccessModeFunc is an instance of a mock
+	// function object controlling the behavior of the method
+	// GetByCollaboratorIDWithAccessMode.
+	GetByCollaboratorIDWithAccessModeFunc *ReposStoreGetByCollaboratorIDWithAccessModeFunc
+	// GetByIDFunc is an instance of a mock function object controlling the
+	// behavior of the method GetByID.
+	GetByIDFunc *ReposStoreGetByIDFunc
+	// GetByNameFunc is an instance of a mock function object controlling
+	// the behavior of the method GetByName.
+	GetByNameFunc *ReposStoreGetByNameFunc
+	// HasForkedByFunc is an instance of a mock function object controlling
+	// the behavior of the method HasForkedBy.
+	HasForkedByFunc *ReposStoreHasForkedByFunc
+	// IsIgnoringFunc is an instance of a mock function object controlling
+	// the behavior of the method IsIgnoring.
+	IsIgnoringFunc *ReposStoreIsIgnoringFunc
+	// ListWatchesFunc is an instance of a mock function object controlling
+	// the behavior of the method ListWatches.
+	ListWatchesFunc *ReposStoreListWatchesFunc
+	// ReconcileIssueCountsFunc is an instance of a mock function object
+	// controlling the behavior of the method ReconcileIssueCounts.
+	ReconcileIssueCountsFunc *ReposStoreReconcileIssueCountsFunc
+	// StarFunc is an instance of a mock function object controlling the
+	// behavior of the method Star.
+	StarFunc *ReposStoreStarFunc
+	// TouchFunc is an instance of a mock function object controlling the
+	// behavior of the method Touch.
+	TouchFunc *ReposStoreTouchFunc
+	// UnwatchFunc is an instance of a mock function object controlling the
+	// behavior of the method Unwatch.
+	UnwatchFunc *ReposStoreUnwatchFunc
+	// WatchFunc is an instance of a mock function object controlling the
+	// behavior of the method Watch.
+	WatchFunc *ReposStoreWatchFunc
+}
+
+// NewMockReposStore creates a new mock of the ReposStore interface. All
+// methods return zero values for all results, unless overwritten.
+func NewMockReposStore() *MockReposStore {
+	return &MockReposStore{
+		AutoWatchFunc: &ReposStoreAutoWatchFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 error) {
+				return
+			},
+		},
+		CountIssuesByRepoFunc: &ReposStoreCountIssuesByRepoFunc{
+			defaultHook: func(context.Context, int64, db.CountIssuesByRepoOptions) (r0 *db.RepoIssueCounts, r1 error) {
+				return
+			},
+		},
+		CreateFunc: &ReposStoreCreateFunc{
+			defaultHook: func(context.Context, int64, db.CreateRepoOptions) (r0 *db.Repository, r1 error) {
+				return
+			},
+		},
+		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
+			defaultHook: func(context.Context, int64, int, string) (r0 []*db.Repository, r1 error) {
+				return
+			},
+		},
+		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
+			defaultHook: func(context.Context, int64) (r0 map[*db.Repository]db.AccessMode, r1 error) {
+				return
+			},
+		},
+		GetByIDFunc: &ReposStoreGetByIDFunc{
+			defaultHook: func(context.Context, int64) (r0 *db.Repository, r1 error) {
+				return
+			},
+		},
+		GetByNameFunc: &ReposStoreGetByNameFunc{
+			defaultHook: func(context.Context, int64, string) (r0 *db.Repository, r1 error) {
+				return
+			},
+		},
+		HasForkedByFunc: &ReposStoreHasForkedByFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 bool) {
+				return
+			},
+		},
+		IsIgnoringFunc: &ReposStoreIsIgnoringFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 bool) {
+				return
+			},
+		},
+		ListWatchesFunc: &ReposStoreListWatchesFunc{
+			defaultHook: func(context.Context, int64) (r0 []*db.Watch, r1 error) {
+				return
+			},
+		},
+		ReconcileIssueCountsFunc: &ReposStoreReconcileIssueCountsFunc{
+			defaultHook: func(context.Context, int64) (r0 bool, r1 error) {
+				return
+			},
+		},
+		StarFunc: &ReposStoreStarFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 error) {
+				return
+			},
+		},
+		TouchFunc: &ReposStoreTouchFunc{
+			defaultHook: func(context.Context, int64) (r0 error) {
+				return
+			},
+		},
+		UnwatchFunc: &ReposStoreUnwatchFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 error) {
+				return
+			},
+		},
+		WatchFunc: &ReposStoreWatchFunc{
+			defaultHook: func(context.Context, int64, int64) (r0 error) {
+				return
+			},
+		},
+	}
+}
+
+// NewStrictMockReposStore creates a new mock of the ReposStore interface.
+// All methods panic on invocation, unless overwritten.
+func NewStrictMockReposStore() *MockReposStore {
+	return &MockReposStore{
+		AutoWatchFunc: &ReposStoreAutoWatchFunc{
+			defaultHook: func(context.Context, int64, int64) error {
+				panic("unexpected invocation of MockReposStore.AutoWatch")
+			},
+		},
+		CountIssuesByRepoFunc: &ReposStoreCountIssuesByRepoFunc{
+			defaultHook: func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error) {
+				panic("unexpected invocation of MockReposStore.CountIssuesByRepo")
+			},
+		},
+		CreateFunc: &ReposStoreCreateFunc{
+			defaultHook: func(context.Context, int64, db.CreateRepoOptions) (*db.Repository, error) {
+				panic("unexpected invocation of MockReposStore.Create")
+			},
+		},
+		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
+			defaultHook: func(context.Context, int64, int, string) ([]*db.Repository, error) {
+				panic("unexpected invocation of MockReposStore.GetByCollaboratorID")
+			},
+		},
+		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
+			defaultHook: func(context.Context, int64) (map[*db.Repository]db.AccessMode, error) {
+				panic("unexpected invocation of MockReposStore.GetByCollaboratorIDWithAccessMode")
+			},
+		},
+		GetByIDFunc: &ReposStoreGetByIDFunc{
+			defaultHook: func(context.Context, int64) (*db.Repository, error) {
+				panic("unexpected invocation of MockReposStore.GetByID")
+			},
+		},
+		GetByNameFunc: &ReposStoreGetByNameFunc{
+			defaultHook: func(context.Context, int64, string) (*db.Repository, error) {
+				panic("unexpected invocation of MockReposStore.GetByName")
+			},
+		},
+		HasForkedByFunc: &ReposStoreHasForkedByFunc{
+			defaultHook: func(context.Context, int64, int64) bool {
+				panic("unexpected invocation of MockReposStore.HasForkedBy")
+			},
+		},
+		IsIgnoringFunc: &ReposStoreIsIgnoringFunc{
+			defaultHook: func(context.Context, int64, int64) bool {
+				panic("unexpected invocation of MockReposStore.IsIgnoring")
+			},
+		},
+		ListWatchesFunc: &ReposStoreListWatchesFunc{
+			defaultHook: func(context.Context, int64) ([]*db.Watch, error) {
+				panic("unexpected invocation of MockReposStore.ListWatches")
+			},
+		},
+		ReconcileIssueCountsFunc: &ReposStoreReconcileIssueCountsFunc{
+			defaultHook: func(context.Context, int64) (bool, error) {
+				panic("unexpected invocation of MockReposStore.ReconcileIssueCounts")
+			},
+		},
+		StarFunc: &ReposStoreStarFunc{
+			defaultHook: func(context.Context, int64, int64) error {
+				panic("unexpected invocation of MockReposStore.Star")
+			},
+		},
+		TouchFunc: &ReposStoreTouchFunc{
+			defaultHook: func(context.Context, int64) error {
+				panic("unexpected invocation of MockReposStore.Touch")
+			},
+		},
+		UnwatchFunc: &ReposStoreUnwatchFunc{
+			defaultHook: func(context.Context, int64, int64) error {
+				panic("unexpected invocation of MockReposStore.Unwatch")
+			},
+		},
+		WatchFunc: &ReposStoreWatchFunc{
+			defaultHook: func(context.Context, int64, int64) error {
+				panic("unexpected invocation of MockReposStore.Watch")
+			},
+		},
+	}
+}
+
+// NewMockReposStoreFrom creates a new mock of the MockReposStore interface.
+// All methods delegate to the given implementation, unless overwritten.
+func NewMockReposStoreFrom(i db.ReposStore) *MockReposStore {
+	return &MockReposStore{
+		AutoWatchFunc: &ReposStoreAutoWatchFunc{
+			defaultHook: i.AutoWatch,
+		},
+		CountIssuesByRepoFunc: &ReposStoreCountIssuesByRepoFunc{
+			defaultHook: i.CountIssuesByRepo,
+		},
+		CreateFunc: &ReposStoreCreateFunc{
+			defaultHook: i.Create,
+		},
+		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
+			defaultHook: i.GetByCollaboratorID,
+		},
+		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
+			defaultHook: i.GetByCollaboratorIDWithAccessMode,
+		},
+		GetByIDFunc: &ReposStoreGetByIDFunc{
+			defaultHook: i.GetByID,
+		},
+		GetByNameFunc: &ReposStoreGetByNameFunc{
+			defaultHook: i.GetByName,
+		},
+		HasForkedByFunc: &ReposStoreHasForkedByFunc{
+			defaultHook: i.HasForkedBy,
+		},
+		IsIgnoringFunc: &ReposStoreIsIgnoringFunc{
+			defaultHook: i.IsIgnoring,
+		},
+		ListWatchesFunc: &ReposStoreListWatchesFunc{
+			defaultHook: i.ListWatches,
+		},
+		ReconcileIssueCountsFunc: &ReposStoreReconcileIssueCountsFunc{
+			defaultHook: i.ReconcileIssueCounts,
+		},
+		StarFunc: &ReposStoreStarFunc{
+			defaultHook: i.Star,
+		},
+		TouchFunc: &ReposStoreTouchFunc{
+			defaultHook: i.Touch,
+		},
+		UnwatchFunc: &ReposStoreUnwatchFunc{
+			defaultHook: i.Unwatch,
+		},
+		WatchFunc: &ReposStoreWatchFunc{
+			defaultHook: i.Watch,
+		},
+	}
+}
+
+// ReposStoreAutoWatchFunc describes the behavior when the AutoWatch method of
+// the parent MockReposStore instance is invoked.
+type ReposStoreAutoWatchFunc struct {
+	defaultHook func(context.Context, int64, int64) error
+	hooks       []func(context.Context, int64, int64) error
+	history     []ReposStoreAutoWatchFuncCall
+	mutex       sync.Mutex
+}
+
+// AutoWatch delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockReposStore) AutoWatch(v0 context.Context, v1 int64, v2 int64) error {
+	r0 := m.AutoWatchFunc.nextHook()(v0, v1, v2)
+	m.AutoWatchFunc.appendCall(ReposStoreAutoWatchFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the AutoWatch method of the
+// parent MockReposStore instance is invoked and the hook queue is empty.
+func (f *ReposStoreAutoWatchFunc) SetDefaultHook(hook func(context.Context, int64, int64) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Watch method of the parent MockReposStore instance invokes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *ReposStoreAutoWatchFunc) PushHook(hook func(context.Context, int64, int64) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *ReposStoreAutoWatchFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int64, int64) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ReposStoreAutoWatchFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int64, int64) error {
+		return r0
+	})
+}
+
+func (f *ReposStoreAutoWatchFunc) nextHook() func(context.Context, int64, int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ReposStoreAutoWatchFunc) appendCall(r0 ReposStoreAutoWatchFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ReposStoreAutoWatchFuncCall objects describing
+// the invocations of this function.
+func (f *ReposStoreAutoWatchFunc) History() []ReposStoreAutoWatchFuncCall {
+	f.mutex.Lock()
+	history := make([]ReposStoreAutoWatchFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ReposStoreAutoWatchFuncCall is an object that describes an invocation of
+// method AutoWatch on an instance of MockReposStore.
+type ReposStoreAutoWatchFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this invocation.
+func (c ReposStoreAutoWatchFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this invocation.
+func (c ReposStoreAutoWatchFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// ReposStoreCountIssuesByRepoFunc describes the behavior when the
+// CountIssuesByRepo method of the parent MockReposStore instance is invoked.
+type ReposStoreCountIssuesByRepoFunc struct {
+	defaultHook func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error)
+	hooks       []func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error)
+	history     []ReposStoreCountIssuesByRepoFuncCall
+	mutex       sync.Mutex
+}
+
+// CountIssuesByRepo delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockReposStore) CountIssuesByRepo(v0 context.Context, v1 int64, v2 db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error) {
+	r0, r1 := m.CountIssuesByRepoFunc.nextHook()(v0, v1, v2)
+	m.CountIssuesByRepoFunc.appendCall(ReposStoreCountIssuesByRepoFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the CountIssuesByRepo
+// method of the parent MockReposStore instance is invoked and the hook queue
+// is empty.
+func (f *ReposStoreCountIssuesByRepoFunc) SetDefaultHook(hook func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// CountIssuesByRepo method of the parent MockReposStore instance invokes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *ReposStoreCountIssuesByRepoFunc) PushHook(hook func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *ReposStoreCountIssuesByRepoFunc) SetDefaultReturn(r0 *db.RepoIssueCounts, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ReposStoreCountIssuesByRepoFunc) PushReturn(r0 *db.RepoIssueCounts, r1 error) {
+	f.PushHook(func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error) {
+		return r0, r1
+	})
+}
+
+func (f *ReposStoreCountIssuesByRepoFunc) nextHook() func(context.Context, int64, db.CountIssuesByRepoOptions) (*db.RepoIssueCounts, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ReposStoreCountIssuesByRepoFunc) appendCall(r0 ReposStoreCountIssuesByRepoFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ReposStoreCountIssuesByRepoFuncCall objects
+// describing the invocations of this function.
+func (f *ReposStoreCountIssuesByRepoFunc) History() []ReposStoreCountIssuesByRepoFuncCall {
+	f.mutex.Lock()
+	history := make([]ReposStoreCountIssuesByRepoFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ReposStoreCountIssuesByRepoFuncCall is an object that describes an
+// invocation of method CountIssuesByRepo on an instance of MockReposStore.
+type ReposStoreCountIssuesByRepoFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method invocation.
+	Arg2 db.CountIssuesByRepoOptions
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *db.RepoIssueCounts
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
 }
 
-// NewMockReposStoreFrom creates a new mock of the MockReposStore interface.
-// All methods delegate to the given implementation, unless overwritten.
-func NewMockReposStoreFrom(i db.ReposStore) *MockReposStore {
-	return &MockReposStore{
-		CreateFunc: &ReposStoreCreateFunc{
-			defaultHook: i.Create,
-		},
-		GetByCollaboratorIDFunc: &ReposStoreGetByCollaboratorIDFunc{
-			defaultHook: i.GetByCollaboratorID,
-		},
-		GetByCollaboratorIDWithAccessModeFunc: &ReposStoreGetByCollaboratorIDWithAccessModeFunc{
-			defaultHook: i.GetByCollaboratorIDWithAccessMode,
-		},
-		GetByIDFunc: &ReposStoreGetByIDFunc{
-			defaultHook: i.GetByID,
-		},
-		GetByNameFunc: &ReposStoreGetByNameFunc{
-			defaultHook: i.GetByName,
-		},
-		HasForkedByFunc: &ReposStoreHasForkedByFunc{
-			defaultHook: i.HasForkedBy,
-		},
-		ListWatchesFunc: &ReposStoreListWatchesFunc{
-			defaultHook: i.ListWatches,
-		},
-		StarFunc: &ReposStoreStarFunc{
-			defaultHook: i.Star,
-		},
-		TouchFunc: &ReposStoreTouchFunc{
-			defaultHook: i.Touch,
-		},
-		WatchFunc: &ReposStoreWatchFunc{
-			defaultHook: i.Watch,
-		},
-	}
+// Args returns an interface slice containing the arguments of this invocation.
+func (c ReposStoreCountIssuesByRepoFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ReposStoreCountIssuesByRepoFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
 // ReposStoreCreateFunc describes the behavior when the Create method of the
@@ -2341,6 +3008,111 @@ func (c ReposStoreHasForkedByFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// ReposStoreIsIgnoringFunc describes the behavior when the IsIgnoring method of
+// the parent MockReposStore instance is invoked.
+type ReposStoreIsIgnoringFunc struct {
+	defaultHook func(context.Context, int64, int64) bool
+	hooks       []func(context.Context, int64, int64) bool
+	history     []ReposStoreIsIgnoringFuncCall
+	mutex       sync.Mutex
+}
+
+// IsIgnoring delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockReposStore) IsIgnoring(v0 context.Context, v1 int64, v2 int64) bool {
+	r0 := m.IsIgnoringFunc.nextHook()(v0, v1, v2)
+	m.IsIgnoringFunc.appendCall(ReposStoreIsIgnoringFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the IsIgnoring method of the
+// parent MockReposStore instance is invoked and the hook queue is empty.
+func (f *ReposStoreIsIgnoringFunc) SetDefaultHook(hook func(context.Context, int64, int64) bool) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// IsIgnoring method of the parent MockReposStore instance invokes the hook at
+// the front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *ReposStoreIsIgnoringFunc) PushHook(hook func(context.Context, int64, int64) bool) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *ReposStoreIsIgnoringFunc) SetDefaultReturn(r0 bool) {
+	f.SetDefaultHook(func(context.Context, int64, int64) bool {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ReposStoreIsIgnoringFunc) PushReturn(r0 bool) {
+	f.PushHook(func(context.Context, int64, int64) bool {
+		return r0
+	})
+}
+
+func (f *ReposStoreIsIgnoringFunc) nextHook() func(context.Context, int64, int64) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ReposStoreIsIgnoringFunc) appendCall(r0 ReposStoreIsIgnoringFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ReposStoreIsIgnoringFuncCall objects describing
+// the invocations of this function.
+func (f *ReposStoreIsIgnoringFunc) History() []ReposStoreIsIgnoringFuncCall {
+	f.mutex.Lock()
+	history := make([]ReposStoreIsIgnoringFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ReposStoreIsIgnoringFuncCall is an object that describes an invocation of
+// method IsIgnoring on an instance of MockReposStore.
+type ReposStoreIsIgnoringFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 bool
+}
+
+// Args returns an interface slice containing the arguments of this invocation.
+func (c ReposStoreIsIgnoringFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this invocation.
+func (c ReposStoreIsIgnoringFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // ReposStoreListWatchesFunc describes the behavior when the ListWatches
 // method of the parent MockReposStore instance is invoked.
 type ReposStoreListWatchesFunc struct {
@@ -2426,26 +3198,132 @@ type ReposStoreListWatchesFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
-	// Arg1 is the value of the 2nd argument passed to this method
-	// invocation.
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []*db.Watch
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c ReposStoreListWatchesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c ReposStoreListWatchesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// ReposStoreReconcileIssueCountsFunc describes the behavior when the
+// ReconcileIssueCounts method of the parent MockReposStore instance is
+// invoked.
+type ReposStoreReconcileIssueCountsFunc struct {
+	defaultHook func(context.Context, int64) (bool, error)
+	hooks       []func(context.Context, int64) (bool, error)
+	history     []ReposStoreReconcileIssueCountsFuncCall
+	mutex       sync.Mutex
+}
+
+// ReconcileIssueCounts delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockReposStore) ReconcileIssueCounts(v0 context.Context, v1 int64) (bool, error) {
+	r0, r1 := m.ReconcileIssueCountsFunc.nextHook()(v0, v1)
+	m.ReconcileIssueCountsFunc.appendCall(ReposStoreReconcileIssueCountsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the ReconcileIssueCounts
+// method of the parent MockReposStore instance is invoked and the hook queue
+// is empty.
+func (f *ReposStoreReconcileIssueCountsFunc) SetDefaultHook(hook func(context.Context, int64) (bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ReconcileIssueCounts method of the parent MockReposStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is
+// empty, the default hook function is invoked for any future action.
+func (f *ReposStoreReconcileIssueCountsFunc) PushHook(hook func(context.Context, int64) (bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *ReposStoreReconcileIssueCountsFunc) SetDefaultReturn(r0 bool, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64) (bool, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ReposStoreReconcileIssueCountsFunc) PushReturn(r0 bool, r1 error) {
+	f.PushHook(func(context.Context, int64) (bool, error) {
+		return r0, r1
+	})
+}
+
+func (f *ReposStoreReconcileIssueCountsFunc) nextHook() func(context.Context, int64) (bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ReposStoreReconcileIssueCountsFunc) appendCall(r0 ReposStoreReconcileIssueCountsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ReposStoreReconcileIssueCountsFuncCall objects
+// describing the invocations of this function.
+func (f *ReposStoreReconcileIssueCountsFunc) History() []ReposStoreReconcileIssueCountsFuncCall {
+	f.mutex.Lock()
+	history := make([]ReposStoreReconcileIssueCountsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ReposStoreReconcileIssueCountsFuncCall is an object that describes an
+// invocation of method ReconcileIssueCounts on an instance of MockReposStore.
+type ReposStoreReconcileIssueCountsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method invocation.
 	Arg1 int64
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 []*db.Watch
+	Result0 bool
 	// Result1 is the value of the 2nd result returned from this method
 	// invocation.
 	Result1 error
 }
 
-// Args returns an interface slice containing the arguments of this
-// invocation.
-func (c ReposStoreListWatchesFuncCall) Args() []interface{} {
+// Args returns an interface slice containing the arguments of this invocation.
+func (c ReposStoreReconcileIssueCountsFuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c ReposStoreListWatchesFuncCall) Results() []interface{} {
+func (c ReposStoreReconcileIssueCountsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
@@ -2660,6 +3538,111 @@ func (c ReposStoreTouchFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// ReposStoreUnwatchFunc describes the behavior when the Unwatch method of the
+// parent MockReposStore instance is invoked.
+type ReposStoreUnwatchFunc struct {
+	defaultHook func(context.Context, int64, int64) error
+	hooks       []func(context.Context, int64, int64) error
+	history     []ReposStoreUnwatchFuncCall
+	mutex       sync.Mutex
+}
+
+// Unwatch delegates to the next hook function in the queue and stores the
+// parameter and result values of this invocation.
+func (m *MockReposStore) Unwatch(v0 context.Context, v1 int64, v2 int64) error {
+	r0 := m.UnwatchFunc.nextHook()(v0, v1, v2)
+	m.UnwatchFunc.appendCall(ReposStoreUnwatchFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the Unwatch method of the
+// parent MockReposStore instance is invoked and the hook queue is empty.
+func (f *ReposStoreUnwatchFunc) SetDefaultHook(hook func(context.Context, int64, int64) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Watch method of the parent MockReposStore instance invokes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *ReposStoreUnwatchFunc) PushHook(hook func(context.Context, int64, int64) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *ReposStoreUnwatchFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int64, int64) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *ReposStoreUnwatchFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int64, int64) error {
+		return r0
+	})
+}
+
+func (f *ReposStoreUnwatchFunc) nextHook() func(context.Context, int64, int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *ReposStoreUnwatchFunc) appendCall(r0 ReposStoreUnwatchFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of ReposStoreUnwatchFuncCall objects describing
+// the invocations of this function.
+func (f *ReposStoreUnwatchFunc) History() []ReposStoreUnwatchFuncCall {
+	f.mutex.Lock()
+	history := make([]ReposStoreUnwatchFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// ReposStoreUnwatchFuncCall is an object that describes an invocation of method
+// Unwatch on an instance of MockReposStore.
+type ReposStoreUnwatchFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int64
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this invocation.
+func (c ReposStoreUnwatchFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this invocation.
+func (c ReposStoreUnwatchFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // ReposStoreWatchFunc describes the behavior when the Watch method of the
 // parent MockReposStore instance is invoked.
 type ReposStoreWatchFunc struct {
@@ -3177,6 +4160,9 @@ type MockUsersStore struct {
 	// AuthenticateFunc is an instance of a mock function object controlling
 	// the behavior of the method Authenticate.
 	AuthenticateFunc *UsersStoreAuthenticateFunc
+	// AuthenticateExternalFunc is an instance of a mock function object
+	// controlling the behavior of the method AuthenticateExternal.
+	AuthenticateExternalFunc *UsersStoreAuthenticateExternalFunc
 	// ChangeUsernameFunc is an instance of a mock function object
 	// controlling the behavior of the method ChangeUsername.
 	ChangeUsernameFunc *UsersStoreChangeUsernameFunc
@@ -3198,6 +4184,9 @@ type MockUsersStore struct {
 	// DeleteInactivatedFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteInactivated.
 	DeleteInactivatedFunc *UsersStoreDeleteInactivatedFunc
+	// ExportFunc is an instance of a mock function object controlling the
+	// behavior of the method Export.
+	ExportFunc *UsersStoreExportFunc
 	// FollowFunc is an instance of a mock function object controlling the
 	// behavior of the method Follow.
 	FollowFunc *UsersStoreFollowFunc
@@ -3272,6 +4261,11 @@ func NewMockUsersStore() *MockUsersStore {
 				return
 			},
 		},
+		AuthenticateExternalFunc: &UsersStoreAuthenticateExternalFunc{
+			defaultHook: func(context.Context, int64, *auth.ExternalAccount) (r0 *db.User, r1 error) {
+				return
+			},
+		},
 		ChangeUsernameFunc: &UsersStoreChangeUsernameFunc{
 			defaultHook: func(context.Context, int64, string) (r0 error) {
 				return
@@ -3307,6 +4301,11 @@ func NewMockUsersStore() *MockUsersStore {
 				return
 			},
 		},
+		ExportFunc: &UsersStoreExportFunc{
+			defaultHook: func(context.Context, int64, io.Writer) (r0 error) {
+				return
+			},
+		},
 		FollowFunc: &UsersStoreFollowFunc{
 			defaultHook: func(context.Context, int64, int64) (r0 error) {
 				return
@@ -3419,6 +4418,11 @@ func NewStrictMockUsersStore() *MockUsersStore {
 				panic("unexpected invocation of MockUsersStore.Authenticate")
 			},
 		},
+		AuthenticateExternalFunc: &UsersStoreAuthenticateExternalFunc{
+			defaultHook: func(context.Context, int64, *auth.ExternalAccount) (*db.User, error) {
+				panic("unexpected invocation of MockUsersStore.AuthenticateExternal")
+			},
+		},
 		ChangeUsernameFunc: &UsersStoreChangeUsernameFunc{
 			defaultHook: func(context.Context, int64, string) error {
 				panic("unexpected invocation of MockUsersStore.ChangeUsername")
@@ -3454,6 +4458,11 @@ func NewStrictMockUsersStore() *MockUsersStore {
 				panic("unexpected invocation of MockUsersStore.DeleteInactivated")
 			},
 		},
+		ExportFunc: &UsersStoreExportFunc{
+			defaultHook: func(context.Context, int64, io.Writer) error {
+				panic("unexpected invocation of MockUsersStore.Export")
+			},
+		},
 		FollowFunc: &UsersStoreFollowFunc{
 			defaultHook: func(context.Context, int64, int64) error {
 				panic("unexpected invocation of MockUsersStore.Follow")
@@ -3562,6 +4571,9 @@ func NewMockUsersStoreFrom(i db.UsersStore) *MockUsersStore {
 		AuthenticateFunc: &UsersStoreAuthenticateFunc{
 			defaultHook: i.Authenticate,
 		},
+		AuthenticateExternalFunc: &UsersStoreAuthenticateExternalFunc{
+			defaultHook: i.AuthenticateExternal,
+		},
 		ChangeUsernameFunc: &UsersStoreChangeUsernameFunc{
 			defaultHook: i.ChangeUsername,
 		},
@@ -3583,6 +4595,9 @@ func NewMockUsersStoreFrom(i db.UsersStore) *MockUsersStore {
 		DeleteInactivatedFunc: &UsersStoreDeleteInactivatedFunc{
 			defaultHook: i.DeleteInactivated,
 		},
+		ExportFunc: &UsersStoreExportFunc{
+			defaultHook: i.Export,
+		},
 		FollowFunc: &UsersStoreFollowFunc{
 			defaultHook: i.Follow,
 		},
@@ -3868,6 +4883,116 @@ func (c UsersStoreAuthenticateFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// UsersStoreAuthenticateExternalFunc describes the behavior when the
+// AuthenticateExternal method of the parent MockUsersStore instance is
+// invoked.
+type UsersStoreAuthenticateExternalFunc struct {
+	defaultHook func(context.Context, int64, *auth.ExternalAccount) (*db.User, error)
+	hooks       []func(context.Context, int64, *auth.ExternalAccount) (*db.User, error)
+	history     []UsersStoreAuthenticateExternalFuncCall
+	mutex       sync.Mutex
+}
+
+// AuthenticateExternal delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockUsersStore) AuthenticateExternal(v0 context.Context, v1 int64, v2 *auth.ExternalAccount) (*db.User, error) {
+	r0, r1 := m.AuthenticateExternalFunc.nextHook()(v0, v1, v2)
+	m.AuthenticateExternalFunc.appendCall(UsersStoreAuthenticateExternalFuncCall{v0, v1, v2, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the AuthenticateExternal
+// method of the parent MockUsersStore instance is invoked and the hook queue is
+// empty.
+func (f *UsersStoreAuthenticateExternalFunc) SetDefaultHook(hook func(context.Context, int64, *auth.ExternalAccount) (*db.User, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// AuthenticateExternal method of the parent MockUsersStore instance invokes
+// the hook at the front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *UsersStoreAuthenticateExternalFunc) PushHook(hook func(context.Context, int64, *auth.ExternalAccount) (*db.User, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the given
+// values.
+func (f *UsersStoreAuthenticateExternalFunc) SetDefaultReturn(r0 *db.User, r1 error) {
+	f.SetDefaultHook(func(context.Context, int64, *auth.ExternalAccount) (*db.User, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UsersStoreAuthenticateExternalFunc) PushReturn(r0 *db.User, r1 error) {
+	f.PushHook(func(context.Context, int64, *auth.ExternalAccount) (*db.User, error) {
+		return r0, r1
+	})
+}
+
+func (f *UsersStoreAuthenticateExternalFunc) nextHook() func(context.Context, int64, *auth.ExternalAccount) (*db.User, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UsersStoreAuthenticateExternalFunc) appendCall(r0 UsersStoreAuthenticateExternalFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UsersStoreAuthenticateExternalFuncCall objects
+// describing the invocations of this function.
+func (f *UsersStoreAuthenticateExternalFunc) History() []UsersStoreAuthenticateExternalFuncCall {
+	f.mutex.Lock()
+	history := make([]UsersStoreAuthenticateExternalFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UsersStoreAuthenticateExternalFuncCall is an object that describes an
+// invocation of method AuthenticateExternal on an instance of MockUsersStore.
+type UsersStoreAuthenticateExternalFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 *auth.ExternalAccount
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 *db.User
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this invocation.
+func (c UsersStoreAuthenticateExternalFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this invocation.
+func (c UsersStoreAuthenticateExternalFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // UsersStoreChangeUsernameFunc describes the behavior when the
 // ChangeUsername method of the parent MockUsersStore instance is invoked.
 type UsersStoreChangeUsernameFunc struct {
@@ -4612,6 +5737,114 @@ func (c UsersStoreDeleteInactivatedFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0}
 }
 
+// UsersStoreExportFunc describes the behavior when the Export
+// method of the parent MockUsersStore instance is invoked.
+type UsersStoreExportFunc struct {
+	defaultHook func(context.Context, int64, io.Writer) error
+	hooks       []func(context.Context, int64, io.Writer) error
+	history     []UsersStoreExportFuncCall
+	mutex       sync.Mutex
+}
+
+// Export delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockUsersStore) Export(v0 context.Context, v1 int64, v2 io.Writer) error {
+	r0 := m.ExportFunc.nextHook()(v0, v1, v2)
+	m.ExportFunc.appendCall(UsersStoreExportFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the Export method of
+// the parent MockUsersStore instance is invoked and the hook queue is
+// empty.
+func (f *UsersStoreExportFunc) SetDefaultHook(hook func(context.Context, int64, io.Writer) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// Export method of the parent MockUsersStore instance invokes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *UsersStoreExportFunc) PushHook(hook func(context.Context, int64, io.Writer) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultHook with a function that returns the
+// given values.
+func (f *UsersStoreExportFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int64, io.Writer) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushHook with a function that returns the given values.
+func (f *UsersStoreExportFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int64, io.Writer) error {
+		return r0
+	})
+}
+
+func (f *UsersStoreExportFunc) nextHook() func(context.Context, int64, io.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *UsersStoreExportFunc) appendCall(r0 UsersStoreExportFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of UsersStoreExportFuncCall objects
+// describing the invocations of this function.
+func (f *UsersStoreExportFunc) History() []UsersStoreExportFuncCall {
+	f.mutex.Lock()
+	history := make([]UsersStoreExportFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// UsersStoreExportFuncCall is an object that describes an invocation of
+// method Export on an instance of MockUsersStore.
+type UsersStoreExportFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int64
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 io.Writer
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c UsersStoreExportFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c UsersStoreExportFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
 // UsersStoreFollowFunc describes the behavior when the Follow method of the
 // parent MockUsersStore instance is invoked.
 type UsersStoreFollowFunc struct {
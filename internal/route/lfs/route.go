@@ -30,9 +30,7 @@ func RegisterRoutes(r *macaron.Router) {
 		r.Group("/objects/basic", func() {
 			basic := &basicHandler{
 				defaultStorage: lfsutil.Storage(conf.LFS.Storage),
-				storagers: map[lfsutil.Storage]lfsutil.Storager{
-					lfsutil.StorageLocal: &lfsutil.LocalStorage{Root: conf.LFS.ObjectsPath},
-				},
+				storagers:      Storagers(),
 			}
 			r.Combo("/:oid", verifyOID()).
 				Get(authorize(db.AccessModeRead), basic.serveDownload).
@@ -42,6 +40,20 @@ func RegisterRoutes(r *macaron.Router) {
 	}, authenticate())
 }
 
+// Storagers returns all available storage backends by the configuration.
+func Storagers() map[lfsutil.Storage]lfsutil.Storager {
+	return map[lfsutil.Storage]lfsutil.Storager{
+		lfsutil.StorageLocal: &lfsutil.LocalStorage{Root: conf.LFS.ObjectsPath},
+		lfsutil.StorageS3: &lfsutil.S3Storage{
+			Endpoint:        conf.LFS.S3.Endpoint,
+			Region:          conf.LFS.S3.Region,
+			Bucket:          conf.LFS.S3.Bucket,
+			AccessKeyID:     conf.LFS.S3.AccessKeyID,
+			SecretAccessKey: conf.LFS.S3.SecretAccessKey,
+		},
+	}
+}
+
 // authenticate tries to authenticate user via HTTP Basic Auth. It first tries to authenticate
 // as plain username and password, then use username as access token if previous step failed.
 func authenticate() macaron.Handler {
@@ -59,39 +71,27 @@ func authenticate() macaron.Handler {
 			return
 		}
 
-		user, err := db.Users.Authenticate(c.Req.Context(), username, password, -1)
-		if err != nil && !auth.IsErrBadCredentials(err) {
-			internalServerError(c.Resp)
-			log.Error("Failed to authenticate user [name: %s]: %v", username, err)
-			return
+		// Access tokens are tried first, either as the username or the password, so
+		// that using them is never counted as a failed sign in attempt.
+		user, err := context.AuthenticateByToken(c.Req.Context(), username, c.RemoteAddr())
+		if db.IsErrAccessTokenNotExist(err) {
+			user, err = context.AuthenticateByToken(c.Req.Context(), password, c.RemoteAddr())
 		}
-
-		if err == nil && db.TwoFactors.IsEnabled(c.Req.Context(), user.ID) {
-			c.Error(http.StatusBadRequest, "Users with 2FA enabled are not allowed to authenticate via username and password.")
-			return
+		if db.IsErrAccessTokenNotExist(err) {
+			user, err = db.AuthenticateWithLockout(c.Req.Context(), username, password, -1, context.LoginIP(c))
+			if err == nil && db.TwoFactors.IsEnabled(c.Req.Context(), user.ID) {
+				c.Error(http.StatusBadRequest, "Users with 2FA enabled are not allowed to authenticate via username and password.")
+				return
+			}
 		}
-
-		// If username and password combination failed, try again using either username
-		// or password as the token.
-		if auth.IsErrBadCredentials(err) {
-			user, err = context.AuthenticateByToken(c.Req.Context(), username)
-			if err != nil && !db.IsErrAccessTokenNotExist(err) {
+		if err != nil {
+			if auth.IsErrBadCredentials(err) || db.IsErrLoginLocked(err) {
+				askCredentials(c.Resp)
+			} else {
 				internalServerError(c.Resp)
-				log.Error("Failed to authenticate by access token via username: %v", err)
-				return
-			} else if db.IsErrAccessTokenNotExist(err) {
-				// Try again using the password field as the token.
-				user, err = context.AuthenticateByToken(c.Req.Context(), password)
-				if err != nil {
-					if db.IsErrAccessTokenNotExist(err) {
-						askCredentials(c.Resp)
-					} else {
-						c.Status(http.StatusInternalServerError)
-						log.Error("Failed to authenticate by access token via password: %v", err)
-					}
-					return
-				}
+				log.Error("Failed to authenticate user [name: %s]: %v", username, err)
 			}
+			return
 		}
 
 		log.Trace("[LFS] Authenticated user: %s", user.Name)
@@ -133,11 +133,18 @@ func authorize(mode db.AccessMode) macaron.Handler {
 				OwnerID: repo.OwnerID,
 				Private: repo.IsPrivate,
 			},
-		) {
+		) || !repo.HasBaseAccess(c.Req.Context(), actor.ID) {
 			c.Status(http.StatusNotFound)
 			return
 		}
 
+		if !repo.EnableLFS {
+			responseJSON(c.Resp, http.StatusNotFound, responseError{
+				Message: "LFS is disabled for the repository",
+			})
+			return
+		}
+
 		log.Trace("[LFS] Authorized user %q to %q", actor.Name, username+"/"+reponame)
 
 		c.Map(owner) // NOTE: Override actor
@@ -56,6 +56,11 @@ func Test_authenticate(t *testing.T) {
 				mock.AuthenticateFunc.SetDefaultReturn(&db.User{}, nil)
 				return mock
 			},
+			mockAccessTokensStore: func() db.AccessTokensStore {
+				mock := NewMockAccessTokensStore()
+				mock.GetBySHA1Func.SetDefaultReturn(nil, db.ErrAccessTokenNotExist{})
+				return mock
+			},
 			mockTwoFactorsStore: func() db.TwoFactorsStore {
 				mock := NewMockTwoFactorsStore()
 				mock.IsEnabledFunc.SetDefaultReturn(true)
@@ -98,6 +103,11 @@ func Test_authenticate(t *testing.T) {
 				mock.AuthenticateFunc.SetDefaultReturn(&db.User{ID: 1, Name: "unknwon"}, nil)
 				return mock
 			},
+			mockAccessTokensStore: func() db.AccessTokensStore {
+				mock := NewMockAccessTokensStore()
+				mock.GetBySHA1Func.SetDefaultReturn(nil, db.ErrAccessTokenNotExist{})
+				return mock
+			},
 			mockTwoFactorsStore: func() db.TwoFactorsStore {
 				mock := NewMockTwoFactorsStore()
 				mock.IsEnabledFunc.SetDefaultReturn(false)
@@ -251,6 +261,33 @@ func Test_authorize(t *testing.T) {
 			expStatusCode: http.StatusNotFound,
 		},
 
+		{
+			name:      "LFS is disabled for the repository",
+			authroize: authorize(db.AccessModeRead),
+			mockUsersStore: func() db.UsersStore {
+				mock := NewMockUsersStore()
+				mock.GetByUsernameFunc.SetDefaultHook(func(ctx context.Context, username string) (*db.User, error) {
+					return &db.User{Name: username}, nil
+				})
+				return mock
+			},
+			mockReposStore: func() db.ReposStore {
+				mock := NewMockReposStore()
+				mock.GetByNameFunc.SetDefaultHook(func(ctx context.Context, ownerID int64, name string) (*db.Repository, error) {
+					return &db.Repository{Name: name, EnableLFS: false}, nil
+				})
+				return mock
+			},
+			mockPermsStore: func() db.PermsStore {
+				mock := NewMockPermsStore()
+				mock.AuthorizeFunc.SetDefaultHook(func(ctx context.Context, userID int64, repoID int64, desired db.AccessMode, opts db.AccessModeOptions) bool {
+					return desired <= db.AccessModeRead
+				})
+				return mock
+			},
+			expStatusCode: http.StatusNotFound,
+			expBody:       `{"message":"LFS is disabled for the repository"}` + "\n",
+		},
 		{
 			name:      "actor is authorized",
 			authroize: authorize(db.AccessModeRead),
@@ -264,7 +301,7 @@ func Test_authorize(t *testing.T) {
 			mockReposStore: func() db.ReposStore {
 				mock := NewMockReposStore()
 				mock.GetByNameFunc.SetDefaultHook(func(ctx context.Context, ownerID int64, name string) (*db.Repository, error) {
-					return &db.Repository{Name: name}, nil
+					return &db.Repository{Name: name, EnableLFS: true}, nil
 				})
 				return mock
 			},
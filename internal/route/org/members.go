@@ -11,6 +11,7 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/email"
 )
 
 const (
@@ -29,6 +30,25 @@ func Members(c *context.Context) {
 	}
 	c.Data["Members"] = org.Members
 
+	if c.Org.IsOwner {
+		invitations, err := db.OrgInvitations.ListByOrgID(c.Req.Context(), org.ID)
+		if err != nil {
+			c.Error(err, "list invitations")
+			return
+		}
+		invitees := make([]*db.User, 0, len(invitations))
+		for _, inv := range invitations {
+			invitee, err := db.Users.GetByID(c.Req.Context(), inv.InviteeID)
+			if err != nil {
+				c.Error(err, "get invitee")
+				return
+			}
+			invitees = append(invitees, invitee)
+		}
+		c.Data["Invitations"] = invitations
+		c.Data["Invitees"] = invitees
+	}
+
 	c.Success(MEMBERS)
 }
 
@@ -59,14 +79,14 @@ func MembersAction(c *context.Context) {
 			c.NotFound()
 			return
 		}
-		err = org.RemoveMember(uid)
+		err = org.RemoveMember(c.User, uid)
 		if db.IsErrLastOrgOwner(err) {
 			c.Flash.Error(c.Tr("form.last_org_owner"))
 			c.Redirect(c.Org.OrgLink + "/members")
 			return
 		}
 	case "leave":
-		err = org.RemoveMember(c.User.ID)
+		err = org.RemoveMember(c.User, c.User.ID)
 		if db.IsErrLastOrgOwner(err) {
 			c.Flash.Error(c.Tr("form.last_org_owner"))
 			c.Redirect(c.Org.OrgLink + "/members")
@@ -108,7 +128,12 @@ func Invitation(c *context.Context) {
 			return
 		}
 
-		if err = org.AddMember(u.ID); err != nil {
+		if org.RequireMemberInvitation {
+			inviteMember(c, u)
+			return
+		}
+
+		if err = org.AddMember(c.User, u.ID); err != nil {
 			c.Error(err, "add member")
 			return
 		}
@@ -120,3 +145,42 @@ func Invitation(c *context.Context) {
 
 	c.Success(MEMBER_INVITE)
 }
+
+// inviteMember creates an invitation for the user to join the current
+// organization and notifies the user.
+func inviteMember(c *context.Context, u *db.User) {
+	org := c.Org.Organization
+	if u.IsOrganization() || org.IsOrgMember(u.ID) {
+		c.Flash.Error(c.Tr("org.members.already_member"))
+		c.Redirect(c.Org.OrgLink + "/invitations/new")
+		return
+	}
+
+	_, err := db.OrgInvitations.Create(c.Req.Context(), org.ID, c.User.ID, u.ID)
+	if err != nil {
+		if db.IsErrOrgInvitationAlreadyExist(err) {
+			c.Flash.Error(c.Tr("org.members.already_invited"))
+			c.Redirect(c.Org.OrgLink + "/invitations/new")
+		} else {
+			c.Error(err, "create invitation")
+		}
+		return
+	}
+
+	if conf.User.EnableEmailNotification {
+		email.SendOrgMemberInvitationMail(db.NewMailerUser(u), db.NewMailerUser(c.User), db.NewMailerUser(org))
+	}
+
+	log.Trace("New member invited(%s): %s", org.Name, u.Name)
+	c.Flash.Success(c.Tr("org.members.invite_success"))
+	c.Redirect(c.Org.OrgLink + "/members")
+}
+
+func DeleteInvitation(c *context.Context) {
+	if err := db.OrgInvitations.DeleteByID(c.Req.Context(), c.Org.Organization.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("DeleteInvitation: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("org.members.cancel_invitation_success"))
+	}
+	c.Redirect(c.Org.OrgLink + "/members")
+}
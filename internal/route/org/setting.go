@@ -5,6 +5,8 @@
 package org
 
 import (
+	"strings"
+
 	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/auth"
@@ -23,18 +25,58 @@ const (
 func Settings(c *context.Context) {
 	c.Title("org.settings")
 	c.Data["PageIsSettingsOptions"] = true
+	c.Data["Gitignores"] = db.Gitignores
+	c.Data["Licenses"] = db.Licenses
+	c.Data["Readmes"] = db.Readmes
+	c.Data["ConventionalCommitsPattern"] = db.ConventionalCommitsPattern
+	c.Data["DefaultBranchProtectionEnabled"] = conf.Repository.DefaultBranchProtection.Enabled
 	c.Success(SETTINGS_OPTIONS)
 }
 
 func SettingsPost(c *context.Context, f form.UpdateOrgSetting) {
 	c.Title("org.settings")
 	c.Data["PageIsSettingsOptions"] = true
+	c.Data["Gitignores"] = db.Gitignores
+	c.Data["Licenses"] = db.Licenses
+	c.Data["Readmes"] = db.Readmes
+	c.Data["ConventionalCommitsPattern"] = db.ConventionalCommitsPattern
+	c.Data["DefaultBranchProtectionEnabled"] = conf.Repository.DefaultBranchProtection.Enabled
 
 	if c.HasError() {
 		c.Success(SETTINGS_OPTIONS)
 		return
 	}
 
+	err := db.ValidateRepoInitTemplates(f.DefaultRepoGitignores, f.DefaultRepoLicense, f.DefaultRepoReadme)
+	if err != nil {
+		if db.IsErrRepoInitTemplateNotExist(err) {
+			c.RenderWithErr(c.Tr("repo.form.init_template_not_exist", err.(db.ErrRepoInitTemplateNotExist).Value()), SETTINGS_OPTIONS, &f)
+		} else {
+			c.Error(err, "validate repository initialization templates")
+		}
+		return
+	}
+
+	f.BranchNamePattern = strings.TrimSpace(f.BranchNamePattern)
+	if err = db.ValidateBranchNamePattern(f.BranchNamePattern); err != nil {
+		c.Data["Err_BranchNamePattern"] = true
+		c.RenderWithErr(c.Tr("org.settings.branch_name_pattern_invalid"), SETTINGS_OPTIONS, &f)
+		return
+	}
+
+	f.CommitMessagePattern = strings.TrimSpace(f.CommitMessagePattern)
+	if err = db.ValidateCommitMessagePattern(f.CommitMessagePattern); err != nil {
+		c.Data["Err_CommitMessagePattern"] = true
+		c.RenderWithErr(c.Tr("org.settings.commit_message_pattern_invalid"), SETTINGS_OPTIONS, &f)
+		return
+	}
+
+	if err = db.ValidateFilePatterns(f.PushBlockedFilePatterns); err != nil {
+		c.Data["Err_PushBlockedFilePatterns"] = true
+		c.RenderWithErr(c.Tr("org.settings.push_blocked_file_patterns_invalid"), SETTINGS_OPTIONS, &f)
+		return
+	}
+
 	org := c.Org.Organization
 
 	// Check if the organization username (including cases) had been changed
@@ -62,16 +104,43 @@ func SettingsPost(c *context.Context, f form.UpdateOrgSetting) {
 		log.Trace("Organization name changed: %s -> %s", org.Name, f.Name)
 	}
 
+	defaultBranchProtection := db.DefaultBranchProtection(f.DefaultBranchProtection)
+	switch defaultBranchProtection {
+	case db.DefaultBranchProtectionEnabled, db.DefaultBranchProtectionDisabled:
+	default:
+		defaultBranchProtection = db.DefaultBranchProtectionDefault
+	}
+
 	opts := db.UpdateUserOptions{
 		FullName:    &f.FullName,
 		Website:     &f.Website,
 		Location:    &f.Location,
 		Description: &f.Description,
+
+		DefaultRepoGitignores: &f.DefaultRepoGitignores,
+		DefaultRepoLicense:    &f.DefaultRepoLicense,
+		DefaultRepoReadme:     &f.DefaultRepoReadme,
+		ForcePrivateRepos:     &f.ForcePrivateRepos,
+		RestrictPrivateForks:  &f.RestrictPrivateForks,
+		BranchNamePattern:     &f.BranchNamePattern,
+
+		CommitMessagePattern:      &f.CommitMessagePattern,
+		CommitMessageExemptMerges: &f.CommitMessageExemptMerges,
+
+		PushMaxSize:             &f.PushMaxSize,
+		PushMaxBlobSize:         &f.PushMaxBlobSize,
+		PushBlockedFilePatterns: &f.PushBlockedFilePatterns,
+
+		RequireMemberInvitation: &f.RequireMemberInvitation,
+		DefaultBranchProtection: &defaultBranchProtection,
 	}
 	if c.User.IsAdmin {
 		opts.MaxRepoCreation = &f.MaxRepoCreation
+		opts.MaxCollaborators = &f.MaxCollaborators
+		opts.MaxTeams = &f.MaxTeams
+		opts.MaxTeamMembers = &f.MaxTeamMembers
 	}
-	err := db.Users.Update(c.Req.Context(), c.Org.Organization.ID, opts)
+	err = db.Users.Update(c.Req.Context(), c.Org.Organization.ID, opts)
 	if err != nil {
 		c.Error(err, "update organization")
 		return
@@ -106,9 +175,11 @@ func SettingsDelete(c *context.Context) {
 
 	org := c.Org.Organization
 	if c.Req.Method == "POST" {
-		if _, err := db.Users.Authenticate(c.Req.Context(), c.User.Name, c.Query("password"), c.User.LoginSource); err != nil {
+		if _, err := db.AuthenticateWithLockout(c.Req.Context(), c.User.Name, c.Query("password"), c.User.LoginSource, context.LoginIP(c.Context)); err != nil {
 			if auth.IsErrBadCredentials(err) {
 				c.RenderWithErr(c.Tr("form.enterred_invalid_password"), SETTINGS_DELETE, nil)
+			} else if db.IsErrLoginLocked(err) {
+				c.RenderWithErr(c.Tr("form.login_attempts_exceeded"), SETTINGS_DELETE, nil)
 			} else {
 				c.Error(err, "authenticate user")
 			}
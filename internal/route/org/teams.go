@@ -54,15 +54,15 @@ func TeamsAction(c *context.Context) {
 			c.NotFound()
 			return
 		}
-		err = c.Org.Team.AddMember(c.User.ID)
+		err = c.Org.Team.AddMember(c.User, c.User.ID)
 	case "leave":
-		err = c.Org.Team.RemoveMember(c.User.ID)
+		err = c.Org.Team.RemoveMember(c.User, c.User.ID)
 	case "remove":
 		if !c.Org.IsOwner {
 			c.NotFound()
 			return
 		}
-		err = c.Org.Team.RemoveMember(uid)
+		err = c.Org.Team.RemoveMember(c.User, uid)
 		page = "team"
 	case "add":
 		if !c.Org.IsOwner {
@@ -82,13 +82,22 @@ func TeamsAction(c *context.Context) {
 			return
 		}
 
-		err = c.Org.Team.AddMember(u.ID)
+		// Users need to accept the invitation to become a member first
+		if c.Org.Organization.RequireMemberInvitation && !c.Org.Organization.IsOrgMember(u.ID) {
+			c.Flash.Error(c.Tr("org.teams.member_invitation_required"))
+			c.Redirect(c.Org.OrgLink + "/teams/" + c.Org.Team.LowerName)
+			return
+		}
+
+		err = c.Org.Team.AddMember(c.User, u.ID)
 		page = "team"
 	}
 
 	if err != nil {
 		if db.IsErrLastOrgOwner(err) {
 			c.Flash.Error(c.Tr("form.last_org_owner"))
+		} else if db.IsErrReachLimitOfTeamMembers(err) {
+			c.Flash.Error(c.Tr("org.teams.reach_limit_of_members", err.(db.ErrReachLimitOfTeamMembers).Limit))
 		} else {
 			log.Error("Action(%s): %v", c.Params(":action"), err)
 			c.JSONSuccess(map[string]any{
@@ -174,6 +183,8 @@ func NewTeamPost(c *context.Context, f form.CreateTeam) {
 			c.RenderWithErr(c.Tr("form.team_name_been_taken"), TEAM_NEW, &f)
 		case db.IsErrNameNotAllowed(err):
 			c.RenderWithErr(c.Tr("org.form.team_name_not_allowed", err.(db.ErrNameNotAllowed).Value()), TEAM_NEW, &f)
+		case db.IsErrReachLimitOfTeams(err):
+			c.RenderWithErr(c.Tr("org.form.reach_limit_of_teams", err.(db.ErrReachLimitOfTeams).Limit), TEAM_NEW, &f)
 		default:
 			c.Error(err, "new team")
 		}
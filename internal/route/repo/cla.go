@@ -0,0 +1,56 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/tool"
+)
+
+const (
+	PULL_CLA = "repo/pulls/cla"
+)
+
+// MustRequireCLA renders 404 if the repository does not require a contributor
+// license agreement.
+func MustRequireCLA(c *context.Context) {
+	if !c.Repo.Repository.RequireCLA {
+		c.NotFound()
+		return
+	}
+}
+
+func CLA(c *context.Context) {
+	c.Title("repo.pulls.cla")
+	c.PageIs("PullList")
+
+	signature, err := db.CLASignatures.Get(c.Req.Context(), c.Repo.Repository.ID, c.User.ID)
+	if err != nil && !db.IsErrCLASignatureNotExist(err) {
+		c.Error(err, "get CLA signature")
+		return
+	}
+	c.Data["Signature"] = signature
+	c.Data["RedirectTo"] = c.Query("redirect_to")
+	c.Success(PULL_CLA)
+}
+
+func CLAPost(c *context.Context) {
+	_, err := db.CLASignatures.Sign(c.Req.Context(), c.Repo.Repository.ID, c.User.ID)
+	if err != nil {
+		c.Error(err, "sign CLA")
+		return
+	}
+	log.Trace("CLA signed by user [%d] for repository [%d]", c.User.ID, c.Repo.Repository.ID)
+
+	c.Flash.Success(c.Tr("repo.pulls.cla_signed_success"))
+	redirectTo := c.Query("redirect_to")
+	if !tool.IsSameSiteURLPath(redirectTo) {
+		redirectTo = c.Repo.RepoLink + "/pulls"
+	}
+	c.Redirect(redirectTo)
+}
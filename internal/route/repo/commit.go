@@ -118,6 +118,19 @@ func tryGetUserByEmail(ctx gocontext.Context, email string) *db.User {
 	return user
 }
 
+// applyDiffRenderOptions collapses files that are too large to be rendered by
+// default and skips rendering files beyond the total limit, unless requested to
+// expand by the "expand" or "expand_file" query parameters.
+func applyDiffRenderOptions(c *context.Context, diff *gitutil.Diff) {
+	diff.ApplyRenderOptions(gitutil.DiffRenderOptions{
+		CollapseLines: conf.Git.CollapseDiffLines,
+		CollapseBytes: conf.Git.CollapseDiffBytes,
+		MaxTotalLines: conf.Git.MaxDiffRenderedLines,
+		ExpandAll:     c.QueryBool("expand"),
+		ExpandFiles:   c.QueryStrings("expand_file"),
+	})
+}
+
 func Diff(c *context.Context) {
 	c.PageIs("Diff")
 	c.RequireHighlightJS()
@@ -140,6 +153,7 @@ func Diff(c *context.Context) {
 		c.NotFoundOrError(gitutil.NewError(err), "get diff")
 		return
 	}
+	applyDiffRenderOptions(c, diff)
 
 	parents := make([]string, commit.ParentsCount())
 	for i := 0; i < commit.ParentsCount(); i++ {
@@ -235,6 +249,7 @@ func CompareDiff(c *context.Context) {
 		c.NotFoundOrError(gitutil.NewError(err), "get diff")
 		return
 	}
+	applyDiffRenderOptions(c, diff)
 
 	commits, err := commit.CommitsAfter(beforeCommitID)
 	if err != nil {
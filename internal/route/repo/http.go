@@ -24,7 +24,9 @@ import (
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/gitutil"
 	"gogs.io/gogs/internal/lazyregexp"
+	"gogs.io/gogs/internal/metrics"
 	"gogs.io/gogs/internal/pathutil"
 	"gogs.io/gogs/internal/tool"
 )
@@ -124,37 +126,18 @@ func HTTPContexter() macaron.Handler {
 			return
 		}
 
-		authUser, err := db.Users.Authenticate(c.Req.Context(), authUsername, authPassword, -1)
-		if err != nil && !auth.IsErrBadCredentials(err) {
-			c.Status(http.StatusInternalServerError)
-			log.Error("Failed to authenticate user [name: %s]: %v", authUsername, err)
-			return
-		}
-
-		// If username and password combination failed, try again using either username
-		// or password as the token.
-		if authUser == nil {
-			authUser, err = context.AuthenticateByToken(c.Req.Context(), authUsername)
-			if err != nil && !db.IsErrAccessTokenNotExist(err) {
+		authUser, err := context.AuthenticateByBasic(c, authUsername, authPassword)
+		if err != nil {
+			switch {
+			case context.IsErrTwoFactorRequired(err):
+				askCredentials(c, http.StatusUnauthorized, `User with two-factor authentication enabled cannot perform HTTP/HTTPS operations via plain username and password
+Please create and use personal access token on user settings page`)
+			case auth.IsErrBadCredentials(err) || db.IsErrLoginLocked(err):
+				askCredentials(c, http.StatusUnauthorized, "")
+			default:
 				c.Status(http.StatusInternalServerError)
-				log.Error("Failed to authenticate by access token via username: %v", err)
-				return
-			} else if db.IsErrAccessTokenNotExist(err) {
-				// Try again using the password field as the token.
-				authUser, err = context.AuthenticateByToken(c.Req.Context(), authPassword)
-				if err != nil {
-					if db.IsErrAccessTokenNotExist(err) {
-						askCredentials(c, http.StatusUnauthorized, "")
-					} else {
-						c.Status(http.StatusInternalServerError)
-						log.Error("Failed to authenticate by access token via password: %v", err)
-					}
-					return
-				}
+				log.Error("Failed to authenticate user [name: %s]: %v", authUsername, err)
 			}
-		} else if db.TwoFactors.IsEnabled(c.Req.Context(), authUser.ID) {
-			askCredentials(c, http.StatusUnauthorized, `User with two-factor authentication enabled cannot perform HTTP/HTTPS operations via plain username and password
-Please create and use personal access token on user settings page`)
 			return
 		}
 
@@ -169,7 +152,7 @@ Please create and use personal access token on user settings page`)
 				OwnerID: repo.OwnerID,
 				Private: repo.IsPrivate,
 			},
-		) {
+		) || !repo.HasBaseAccess(c.Req.Context(), authUser.ID) {
 			askCredentials(c, http.StatusForbidden, "User permission denied")
 			return
 		}
@@ -195,6 +178,7 @@ type serviceHandler struct {
 	r    *http.Request
 	dir  string
 	file string
+	ip   string
 
 	authUser  *db.User
 	ownerName string
@@ -238,12 +222,19 @@ func serviceRPC(h serviceHandler, service string) {
 		h.w.WriteHeader(http.StatusUnauthorized)
 		return
 	}
+
+	release, err := gitutil.DefaultOperationLimiter().Acquire(h.dir, h.ip)
+	if err != nil {
+		log.Trace("HTTP.serviceRPC: rejected '%s' on %q from %s: %v", service, h.dir, h.ip, err)
+		h.w.Header().Set("Retry-After", "60")
+		http.Error(h.w, "Server is busy, please try again later.", http.StatusServiceUnavailable)
+		return
+	}
+	defer release()
+
 	h.w.Header().Set("Content-Type", fmt.Sprintf("application/x-git-%s-result", service))
 
-	var (
-		reqBody = h.r.Body
-		err     error
-	)
+	reqBody := h.r.Body
 
 	// Handle GZIP
 	if h.r.Header.Get("Content-Encoding") == "gzip" {
@@ -255,6 +246,11 @@ func serviceRPC(h serviceHandler, service string) {
 		}
 	}
 
+	start := time.Now()
+	defer func() {
+		metrics.GitOperationDuration.WithLabelValues(service).Observe(time.Since(start).Seconds())
+	}()
+
 	var stderr bytes.Buffer
 	cmd := exec.Command("git", service, "--stateless-rpc", h.dir)
 	if service == "receive-pack" {
@@ -268,7 +264,7 @@ func serviceRPC(h serviceHandler, service string) {
 		})...)
 	}
 	cmd.Dir = h.dir
-	cmd.Stdout = h.w
+	cmd.Stdout = gitutil.NewThrottledWriter(h.w, conf.Git.MaxOperationBandwidth)
 	cmd.Stderr = &stderr
 	cmd.Stdin = reqBody
 	if err = cmd.Run(); err != nil {
@@ -430,6 +426,7 @@ func HTTP(c *HTTPContext) {
 			r:    c.Req.Request,
 			dir:  dir,
 			file: file,
+			ip:   c.RemoteAddr(),
 
 			authUser:  c.AuthUser,
 			ownerName: c.OwnerName,
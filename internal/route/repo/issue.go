@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 	"time"
 
@@ -20,14 +21,16 @@ import (
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/db/errors"
 	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/issueform"
 	"gogs.io/gogs/internal/markup"
 	"gogs.io/gogs/internal/tool"
 )
 
 const (
-	ISSUES     = "repo/issue/list"
-	ISSUE_NEW  = "repo/issue/new"
-	ISSUE_VIEW = "repo/issue/view"
+	ISSUES       = "repo/issue/list"
+	ISSUE_NEW    = "repo/issue/new"
+	ISSUE_CHOOSE = "repo/issue/choose"
+	ISSUE_VIEW   = "repo/issue/view"
 
 	LABELS = "repo/issue/labels"
 
@@ -47,6 +50,10 @@ var (
 		".gogs/ISSUE_TEMPLATE.md",
 		".github/ISSUE_TEMPLATE.md",
 	}
+	IssueFormDirCandidates = []string{
+		".gogs/ISSUE_TEMPLATE",
+		".github/ISSUE_TEMPLATE",
+	}
 )
 
 func MustEnableIssues(c *context.Context) {
@@ -55,8 +62,23 @@ func MustEnableIssues(c *context.Context) {
 		return
 	}
 
+	// Pull requests are always kept internal, the redirect only applies to
+	// routes of issues.
 	if c.Repo.Repository.EnableExternalTracker {
-		c.Redirect(c.Repo.Repository.ExternalTrackerURL)
+		if index := c.Params(":index"); index != "" {
+			c.Redirect(c.Repo.Repository.ExternalIssueURL(index))
+		} else {
+			c.Redirect(c.Repo.Repository.ExternalTrackerURL)
+		}
+		return
+	}
+}
+
+// MustAllowCreateIssues responds 403 if the current user is not allowed to
+// create issues, e.g. issues of the repository are read-only.
+func MustAllowCreateIssues(c *context.Context) {
+	if !c.Repo.Repository.CanCreateIssues(c.Repo.AccessMode) {
+		c.Status(http.StatusForbidden)
 		return
 	}
 }
@@ -143,15 +165,17 @@ func issues(c *context.Context, isPullList bool) {
 	repo := c.Repo.Repository
 	selectLabels := c.Query("labels")
 	milestoneID := c.QueryInt64("milestone")
+	workflowStateID := c.QueryInt64("workflow_state")
 	isShowClosed := c.Query("state") == "closed"
 	issueStats := db.GetIssueStats(&db.IssueStatsOptions{
-		RepoID:      repo.ID,
-		UserID:      uid,
-		Labels:      selectLabels,
-		MilestoneID: milestoneID,
-		AssigneeID:  assigneeID,
-		FilterMode:  filterMode,
-		IsPull:      isPullList,
+		RepoID:          repo.ID,
+		UserID:          uid,
+		Labels:          selectLabels,
+		MilestoneID:     milestoneID,
+		AssigneeID:      assigneeID,
+		WorkflowStateID: workflowStateID,
+		FilterMode:      filterMode,
+		IsPull:          isPullList,
 	})
 
 	page := c.QueryInt("page")
@@ -169,23 +193,39 @@ func issues(c *context.Context, isPullList bool) {
 	c.Data["Page"] = pager
 
 	issues, err := db.Issues(&db.IssuesOptions{
-		UserID:      uid,
-		AssigneeID:  assigneeID,
-		RepoID:      repo.ID,
-		PosterID:    posterID,
-		MilestoneID: milestoneID,
-		Page:        pager.Current(),
-		IsClosed:    isShowClosed,
-		IsMention:   filterMode == db.FILTER_MODE_MENTION,
-		IsPull:      isPullList,
-		Labels:      selectLabels,
-		SortType:    sortType,
+		UserID:          uid,
+		AssigneeID:      assigneeID,
+		RepoID:          repo.ID,
+		PosterID:        posterID,
+		MilestoneID:     milestoneID,
+		WorkflowStateID: workflowStateID,
+		Page:            pager.Current(),
+		IsClosed:        isShowClosed,
+		IsMention:       filterMode == db.FILTER_MODE_MENTION,
+		IsPull:          isPullList,
+		Labels:          selectLabels,
+		SortType:        sortType,
 	})
 	if err != nil {
 		c.Error(err, "list issues")
 		return
 	}
 
+	// Get workflow states.
+	workflowStates, err := db.IssueWorkflowStates.List(c.Req.Context(), repo.ID)
+	if err != nil {
+		c.Error(err, "list workflow states")
+		return
+	}
+	workflowStatesByID := make(map[int64]*db.IssueWorkflowState, len(workflowStates))
+	for _, state := range workflowStates {
+		workflowStatesByID[state.ID] = state
+	}
+	for i := range issues {
+		issues[i].WorkflowState = workflowStatesByID[issues[i].WorkflowStateID]
+	}
+	c.Data["WorkflowStates"] = workflowStates
+
 	// Get issue-user relations.
 	pairs, err := db.GetIssueUsers(repo.ID, posterID, isShowClosed)
 	if err != nil {
@@ -234,6 +274,7 @@ func issues(c *context.Context, isPullList bool) {
 	c.Data["SortType"] = sortType
 	c.Data["MilestoneID"] = milestoneID
 	c.Data["AssigneeID"] = assigneeID
+	c.Data["WorkflowStateID"] = workflowStateID
 	c.Data["IsShowClosed"] = isShowClosed
 	if isShowClosed {
 		c.Data["State"] = "closed"
@@ -330,6 +371,95 @@ func setTemplateIfExists(c *context.Context, ctxDataKey string, possibleFiles []
 	}
 }
 
+// loadIssueForms returns issue forms found in the first candidate directory of
+// the default branch that has any. Forms that fail to parse are left out and
+// only shown to users with write access so that maintainers can fix them.
+func loadIssueForms(c *context.Context) []*issueform.Form {
+	if c.Repo.Commit == nil {
+		var err error
+		c.Repo.Commit, err = c.Repo.GitRepo.BranchCommit(c.Repo.Repository.DefaultBranch)
+		if err != nil {
+			return nil
+		}
+	}
+
+	for _, dir := range IssueFormDirCandidates {
+		tree, err := c.Repo.Commit.Subtree(dir)
+		if err != nil {
+			continue
+		}
+		entries, err := tree.Entries()
+		if err != nil {
+			continue
+		}
+
+		var forms []*issueform.Form
+		var formErrors []string
+		for _, entry := range entries {
+			ext := path.Ext(entry.Name())
+			if !entry.IsBlob() || (ext != ".yml" && ext != ".yaml") {
+				continue
+			}
+
+			p, err := entry.Blob().Bytes()
+			if err != nil {
+				log.Error("Failed to read issue form %q: %v", entry.Name(), err)
+				continue
+			}
+			form, err := issueform.Parse(entry.Name(), p)
+			if err != nil {
+				formErrors = append(formErrors, fmt.Sprintf("%s/%s: %v", dir, entry.Name(), err))
+				continue
+			}
+			forms = append(forms, form)
+		}
+		if len(forms) == 0 && len(formErrors) == 0 {
+			continue
+		}
+
+		if len(formErrors) > 0 && c.Repo.IsWriter() {
+			c.Data["IssueFormErrors"] = formErrors
+		}
+		return forms
+	}
+	return nil
+}
+
+// setIssueForm sets the issue form with given file name and the submitted
+// values to be rendered, returns nil if no such form is found.
+func setIssueForm(c *context.Context, forms []*issueform.Form, fileName string, values url.Values) *issueform.Form {
+	for _, form := range forms {
+		if form.FileName != fileName {
+			continue
+		}
+
+		// Values of checkboxes and multiple dropdowns are looked up by "<name>=<value>"
+		inputs := make(map[string]string, len(values))
+		selected := make(map[string]bool, len(values))
+		for name, vals := range values {
+			if len(vals) > 0 {
+				inputs[name] = vals[0]
+			}
+			for _, v := range vals {
+				selected[name+"="+v] = true
+			}
+		}
+		markdowns := make(map[string]string)
+		for _, field := range form.Body {
+			if field.Type == issueform.FieldTypeMarkdown {
+				markdowns[field.Name] = string(markup.Markdown(field.Attributes.Value, c.Repo.RepoLink, c.Repo.Repository.ComposeMetas()))
+			}
+		}
+
+		c.Data["IssueForm"] = form
+		c.Data["IssueFormMarkdowns"] = markdowns
+		c.Data["IssueFormInputs"] = inputs
+		c.Data["IssueFormSelected"] = selected
+		return form
+	}
+	return nil
+}
+
 func NewIssue(c *context.Context) {
 	c.Data["Title"] = c.Tr("repo.issues.new")
 	c.Data["PageIsIssueList"] = true
@@ -337,7 +467,21 @@ func NewIssue(c *context.Context) {
 	c.Data["RequireSimpleMDE"] = true
 	c.Data["title"] = c.Query("title")
 	c.Data["content"] = c.Query("content")
-	setTemplateIfExists(c, ISSUE_TEMPLATE_KEY, IssueTemplateCandidates)
+
+	forms := loadIssueForms(c)
+	formName := c.Query("template")
+	if formName == "" && len(forms) > 0 {
+		c.Data["IssueForms"] = forms
+		c.Success(ISSUE_CHOOSE)
+		return
+	}
+	if form := setIssueForm(c, forms, formName, nil); form != nil {
+		if c.Data["title"] == "" {
+			c.Data["title"] = form.Title
+		}
+	} else {
+		setTemplateIfExists(c, ISSUE_TEMPLATE_KEY, IssueTemplateCandidates)
+	}
 	renderAttachmentSettings(c)
 
 	RetrieveRepoMetas(c, c.Repo.Repository)
@@ -414,6 +558,21 @@ func NewIssuePost(c *context.Context, f form.NewIssue) {
 		return
 	}
 
+	content := f.Content
+	if f.Template != "" {
+		if form := setIssueForm(c, loadIssueForms(c), f.Template, c.Req.Form); form != nil {
+			err := form.Validate(c.Req.Form)
+			if fieldErr, ok := err.(issueform.RequiredFieldError); ok {
+				c.RenderWithErr(c.Tr("repo.issues.form.field_required", fieldErr.Label), ISSUE_NEW, &f)
+				return
+			} else if err != nil {
+				c.Error(err, "validate issue form")
+				return
+			}
+			content = form.Markdown(c.Req.Form)
+		}
+	}
+
 	if c.HasError() {
 		c.Success(ISSUE_NEW)
 		return
@@ -431,7 +590,7 @@ func NewIssuePost(c *context.Context, f form.NewIssue) {
 		Poster:      c.User,
 		MilestoneID: milestoneID,
 		AssigneeID:  assigneeID,
-		Content:     f.Content,
+		Content:     content,
 	}
 	if err := db.NewIssue(c.Repo.Repository, issue, labelIDs, attachments); err != nil {
 		c.Error(err, "new issue")
@@ -545,6 +704,17 @@ func viewIssue(c *context.Context, isPullList bool) {
 			PrepareMergedViewPullInfo(c, issue)
 		} else {
 			PrepareViewPullInfo(c, issue)
+			if c.Written() {
+				return
+			}
+
+			required, err := repo.IsCLASignatureRequired(c.Req.Context(), issue.PosterID)
+			if err != nil {
+				c.Error(err, "check CLA signature")
+				return
+			}
+			c.Data["IsCLARequired"] = required
+			c.Data["CLASignLink"] = c.Repo.RepoLink + "/cla?redirect_to=" + url.QueryEscape(c.Data["Link"].(string))
 		}
 		if c.Written() {
 			return
@@ -642,6 +812,48 @@ func viewIssue(c *context.Context, isPullList bool) {
 		}
 	}
 
+	if issue.IsPull && !issue.PullRequest.HasMerged {
+		c.Data["RequireLinearHistory"] = db.IsBranchOfRepoRequireLinearHistory(issue.PullRequest.BaseRepoID, issue.PullRequest.BaseBranch)
+
+		if issue.PullRequest.IsAutoMergeEnabled() {
+			autoMergeUser, err := db.Users.GetByID(c.Req.Context(), issue.PullRequest.AutoMergeUserID)
+			if err != nil && !db.IsErrUserNotExist(err) {
+				c.Error(err, "get auto-merge user")
+				return
+			}
+			c.Data["AutoMergeUser"] = autoMergeUser
+		}
+	}
+
+	if issue.IsPull {
+		c.Data["LinkedIssues"], err = issue.PullRequest.LinkedIssues(c.Req.Context())
+		if err != nil {
+			c.Error(err, "get linked issues")
+			return
+		}
+
+		c.Data["RequestedReviewers"], err = issue.PullRequest.RequestedReviewers(c.Req.Context())
+		if err != nil {
+			c.Error(err, "get requested reviewers")
+			return
+		}
+
+		approvers, err := issue.PullRequest.Approvers(c.Req.Context())
+		if err != nil {
+			c.Error(err, "get approvers")
+			return
+		}
+		c.Data["Approvers"] = approvers
+		if c.IsLogged {
+			for _, approver := range approvers {
+				if approver.ID == c.User.ID {
+					c.Data["HasApproved"] = true
+					break
+				}
+			}
+		}
+	}
+
 	if issue.IsPull && issue.PullRequest.HasMerged {
 		pull := issue.PullRequest
 		branchProtected := false
@@ -840,6 +1052,11 @@ func NewComment(c *context.Context, f form.CreateComment) {
 		return
 	}
 
+	if !issue.IsPull && !c.Repo.Repository.CanCreateIssues(c.Repo.AccessMode) {
+		c.Status(http.StatusForbidden)
+		return
+	}
+
 	var attachments []string
 	if conf.Attachment.Enabled {
 		attachments = f.Files
@@ -878,7 +1095,6 @@ func NewComment(c *context.Context, f form.CreateComment) {
 						c.Error(err, "update patch")
 						return
 					}
-
 					issue.PullRequest.AddToTaskQueue()
 				}
 			}
@@ -918,7 +1134,11 @@ func NewComment(c *context.Context, f form.CreateComment) {
 
 	comment, err = db.CreateIssueComment(c.User, c.Repo.Repository, issue, f.Content, attachments)
 	if err != nil {
-		c.Error(err, "create issue comment")
+		if db.IsErrCommentRateLimitExceeded(err) {
+			c.Flash.Error(c.Tr("repo.issues.comment_rate_limit_exceeded"))
+		} else {
+			c.Error(err, "create issue comment")
+		}
 		return
 	}
 
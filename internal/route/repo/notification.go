@@ -0,0 +1,146 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/repoutil"
+)
+
+const (
+	SETTINGS_NOTIFICATIONS   = "repo/settings/notifications"
+	NOTIFICATION_UNSUBSCRIBE = "repo/notification_unsubscribe"
+)
+
+func loadNotificationRecipients(c *context.Context) bool {
+	recipients, err := db.RepoNotificationRecipients.List(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list notification recipients")
+		return false
+	}
+	c.Data["NotificationRecipients"] = recipients
+	return true
+}
+
+func SettingsNotifications(c *context.Context) {
+	c.Title("repo.settings.notifications")
+	c.PageIs("SettingsNotifications")
+
+	if !loadNotificationRecipients(c) {
+		return
+	}
+	c.Success(SETTINGS_NOTIFICATIONS)
+}
+
+func SettingsNotificationsPost(c *context.Context, f form.NotificationRecipient) {
+	c.Title("repo.settings.notifications")
+	c.PageIs("SettingsNotifications")
+
+	if !loadNotificationRecipients(c) {
+		return
+	}
+	if c.HasError() {
+		c.Data["HasError"] = true
+		c.Success(SETTINGS_NOTIFICATIONS)
+		return
+	}
+
+	var events []db.NotificationEvent
+	for i, ok := range []bool{f.Issue, f.IssueComment, f.PullRequest, f.PullRequestComment} {
+		if ok {
+			events = append(events, db.NotificationEvents[i])
+		}
+	}
+	if len(events) == 0 {
+		c.Data["HasError"] = true
+		c.RenderWithErr(c.Tr("repo.settings.notification_recipient_no_events"), SETTINGS_NOTIFICATIONS, &f)
+		return
+	}
+
+	recipient, err := db.RepoNotificationRecipients.Set(c.Req.Context(), c.Repo.Repository.ID, f.Email, events)
+	if err != nil {
+		if db.IsErrNotificationRecipientInvalid(err) {
+			c.Data["HasError"] = true
+			c.Data["Err_Email"] = true
+			c.RenderWithErr(c.Tr("repo.settings.notification_recipient_email_invalid"), SETTINGS_NOTIFICATIONS, &f)
+			return
+		}
+		c.Error(err, "set notification recipient")
+		return
+	}
+
+	log.Trace("Notification recipient added to repository [%d]: %s", c.Repo.Repository.ID, recipient.Email)
+	c.Flash.Success(c.Tr("repo.settings.notification_recipient_add_success", recipient.Email))
+	c.Redirect(c.Repo.RepoLink + "/settings/notifications")
+}
+
+func DeleteNotificationRecipient(c *context.Context) {
+	err := db.RepoNotificationRecipients.Delete(c.Req.Context(), c.Repo.Repository.ID, c.Query("email"))
+	if err != nil && !db.IsErrNotificationRecipientNotExist(err) {
+		c.Flash.Error("DeleteNotificationRecipient: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("repo.settings.notification_recipient_deletion_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Repo.RepoLink + "/settings/notifications",
+	})
+}
+
+// parseUnsubscribeRequest returns the repository and the email address of the
+// unsubscribe request, it renders 404 when the token is not valid.
+func parseUnsubscribeRequest(c *context.Context) (*db.Repository, string) {
+	repoID := c.QueryInt64("repo_id")
+	email := c.Query("email")
+	if !repoutil.VerifyUnsubscribeToken(c.Query("token"), repoID, email) {
+		c.NotFound()
+		return nil, ""
+	}
+
+	repo, err := db.Repos.GetByID(c.Req.Context(), repoID)
+	if err != nil {
+		c.NotFoundOrError(err, "get repository by ID")
+		return nil, ""
+	}
+	c.Data["RepoFullName"] = repo.FullName()
+	c.Data["Email"] = email
+	c.Data["Link"] = conf.Server.Subpath + "/notifications/unsubscribe?" + c.Req.URL.RawQuery
+	return repo, email
+}
+
+// NotificationUnsubscribe renders the page for a notification recipient to
+// confirm unsubscribing from notifications of a repository, which requires no
+// sign in but a valid token from the notification email.
+func NotificationUnsubscribe(c *context.Context) {
+	c.Title("repo.notification_unsubscribe")
+	_, _ = parseUnsubscribeRequest(c)
+	if c.Written() {
+		return
+	}
+	c.Success(NOTIFICATION_UNSUBSCRIBE)
+}
+
+func NotificationUnsubscribePost(c *context.Context) {
+	c.Title("repo.notification_unsubscribe")
+	repo, email := parseUnsubscribeRequest(c)
+	if c.Written() {
+		return
+	}
+
+	err := db.RepoNotificationRecipients.Delete(c.Req.Context(), repo.ID, email)
+	if err != nil && !db.IsErrNotificationRecipientNotExist(err) {
+		c.Error(err, "delete notification recipient")
+		return
+	}
+
+	log.Trace("Notification recipient unsubscribed from repository [%d]: %s", repo.ID, email)
+	c.Data["IsUnsubscribed"] = true
+	c.Success(NOTIFICATION_UNSUBSCRIBE)
+}
@@ -147,6 +147,8 @@ func ForkPost(c *context.Context, f form.CreateRepo) {
 			c.RenderWithErr(c.Tr("repo.settings.new_owner_has_same_repo"), FORK, &f)
 		case db.IsErrNameNotAllowed(err):
 			c.RenderWithErr(c.Tr("repo.form.name_not_allowed", err.(db.ErrNameNotAllowed).Value()), FORK, &f)
+		case db.IsErrPrivateForkNotAllowed(err):
+			c.RenderWithErr(c.Tr("repo.form.private_fork_not_allowed"), FORK, &f)
 		default:
 			c.Error(err, "fork repository")
 		}
@@ -247,9 +249,71 @@ func PrepareViewPullInfo(c *context.Context, issue *db.Issue) *gitutil.PullReque
 	}
 	c.Data["NumCommits"] = len(prMeta.Commits)
 	c.Data["NumFiles"] = prMeta.NumFiles
+
+	if db.IsBranchOfRepoRequireUpToDate(repo.ID, pull.BaseBranch) {
+		baseCommitID, err := c.Repo.GitRepo.BranchCommitID(pull.BaseBranch)
+		if err != nil {
+			c.Error(err, "get base branch commit ID")
+			return nil
+		}
+		c.Data["IsPullHeadOutOfDate"] = prMeta.MergeBase != baseCommitID
+		c.Data["CanUpdatePullBranch"] = canUpdatePullBranch(c, pull)
+	}
+
+	// Merge requirements only apply to open pull requests, and the head ref might
+	// have been cleaned up when the pull request was closed.
+	if issue.IsClosed {
+		return prMeta
+	}
+
+	if db.IsBranchOfRepoRequireCodeOwnerReviews(repo.ID, pull.BaseBranch) {
+		c.Data["MissingCodeOwners"], err = pull.MissingCodeOwners(c.Req.Context())
+		if err != nil {
+			c.Error(err, "get missing code owners")
+			return nil
+		}
+	}
+
+	c.Data["MissingApprovals"], err = pull.MissingApprovals(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get missing approvals")
+		return nil
+	}
+	c.Data["MissingStatusChecks"], err = pull.MissingStatusChecks(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get missing status checks")
+		return nil
+	}
+
+	if repo.RequireSignOff {
+		c.Data["MissingSignOffs"], err = pull.MissingSignOffs()
+		if err != nil {
+			c.Error(err, "get missing sign-offs")
+			return nil
+		}
+		c.Data["SignOffBase"] = prMeta.MergeBase
+	}
 	return prMeta
 }
 
+// canUpdatePullBranch returns true if the current user is allowed to update the
+// head branch of the pull request with changes of the base branch.
+func canUpdatePullBranch(c *context.Context, pull *db.PullRequest) bool {
+	if !c.IsLogged || !c.Repo.IsWriter() || pull.HeadRepo == nil || pull.IsHeadBranchProtected() {
+		return false
+	}
+	return c.User.IsAdmin || db.Perms.Authorize(
+		c.Req.Context(),
+		c.User.ID,
+		pull.HeadRepo.ID,
+		db.AccessModeWrite,
+		db.AccessModeOptions{
+			OwnerID: pull.HeadRepo.OwnerID,
+			Private: pull.HeadRepo.IsPrivate,
+		},
+	)
+}
+
 func ViewPullCommits(c *context.Context) {
 	c.Data["PageIsPullList"] = true
 	c.Data["PageIsPullCommits"] = true
@@ -368,6 +432,7 @@ func ViewPullFiles(c *context.Context) {
 		c.Error(err, "get diff")
 		return
 	}
+	applyDiffRenderOptions(c, diff)
 	c.Data["Diff"] = diff
 	c.Data["DiffNotAvailable"] = diff.NumFiles() == 0
 
@@ -423,9 +488,44 @@ func MergePullRequest(c *context.Context) {
 		return
 	}
 
+	required, err := c.Repo.Repository.IsCLASignatureRequired(c.Req.Context(), issue.PosterID)
+	if err != nil {
+		c.Error(err, "check CLA signature")
+		return
+	} else if required {
+		c.Flash.Error(c.Tr("repo.pulls.cla_not_signed"))
+		c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	pr.Issue = issue
 	pr.Issue.Repo = c.Repo.Repository
 	if err = pr.Merge(c.User, c.Repo.GitRepo, db.MergeStyle(c.Query("merge_style")), c.Query("commit_description")); err != nil {
+		if db.IsErrMergeCommitNotAllowed(err) {
+			c.Flash.Error(c.Tr("repo.pulls.merge_commit_not_allowed"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrPullRequestOutOfDate(err) {
+			c.Flash.Error(c.Tr("repo.pulls.head_out_of_date"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrCodeOwnerReviewRequired(err) {
+			c.Flash.Error(c.Tr("repo.pulls.code_owner_review_required"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrApprovalsRequired(err) {
+			c.Flash.Error(c.Tr("repo.pulls.approvals_required"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrStatusChecksRequired(err) {
+			c.Flash.Error(c.Tr("repo.pulls.status_checks_required"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrSignOffRequired(err) {
+			c.Flash.Error(c.Tr("repo.pulls.sign_off_required"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
 		c.Error(err, "merge")
 		return
 	}
@@ -434,6 +534,194 @@ func MergePullRequest(c *context.Context) {
 	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
 }
 
+func UpdatePullBranch(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	if issue.IsClosed {
+		c.NotFound()
+		return
+	}
+
+	pr, err := db.GetPullRequestByIssueID(issue.ID)
+	if err != nil {
+		c.NotFoundOrError(err, "get pull request by issue ID")
+		return
+	}
+	if pr.HasMerged || !canUpdatePullBranch(c, pr) {
+		c.NotFound()
+		return
+	}
+
+	if err = pr.UpdateBranch(c.User); err != nil {
+		if db.IsErrPullRequestUpdateConflict(err) {
+			c.Flash.Error(c.Tr("repo.pulls.update_branch_conflict"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		} else if db.IsErrPullRequestHeadProtected(err) {
+			c.Flash.Error(c.Tr("repo.pulls.update_branch_protected"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+			return
+		}
+		c.Error(err, "update branch")
+		return
+	}
+	go db.AddTestPullRequestTask(c.User, pr.HeadRepoID, pr.HeadBranch, true)
+
+	log.Trace("Pull request branch updated: %d", pr.ID)
+	c.Flash.Success(c.Tr("repo.pulls.update_branch_success"))
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+}
+
+// EnableAutoMergePull enables auto-merge of the pull request by the current
+// user with the chosen merge style.
+func EnableAutoMergePull(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	if issue.IsClosed {
+		c.NotFound()
+		return
+	}
+
+	pr := issue.PullRequest
+	if pr.HasMerged || pr.Status == db.PULL_REQUEST_STATUS_CONFLICT {
+		c.NotFound()
+		return
+	}
+
+	pr.Issue = issue
+	if err := pr.EnableAutoMerge(c.User, db.MergeStyle(c.Query("merge_style"))); err != nil {
+		if db.IsErrMergeCommitNotAllowed(err) {
+			c.Flash.Error(c.Tr("repo.pulls.merge_commit_not_allowed"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+			return
+		}
+		c.Error(err, "enable auto-merge")
+		return
+	}
+
+	log.Trace("Pull request auto-merge enabled [%d]: %d", pr.ID, c.User.ID)
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// CancelAutoMergePull cancels auto-merge of the pull request.
+func CancelAutoMergePull(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.PullRequest.CancelAutoMerge(); err != nil {
+		c.Error(err, "cancel auto-merge")
+		return
+	}
+
+	log.Trace("Pull request auto-merge canceled [%d]: %d", issue.PullRequest.ID, c.User.ID)
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// ApprovePull approves the pull request by the current user.
+func ApprovePull(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	if issue.IsClosed {
+		c.NotFound()
+		return
+	}
+
+	pr := issue.PullRequest
+	pr.Issue = issue
+	if err := pr.Approve(c.Req.Context(), c.User); err != nil {
+		if db.IsErrApprovalNotAllowed(err) {
+			c.Flash.Error(c.Tr("repo.pulls.approve_not_allowed"))
+			c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+			return
+		}
+		c.Error(err, "approve")
+		return
+	}
+
+	log.Trace("Pull request approved [%d]: %d", pr.ID, c.User.ID)
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// UnapprovePull withdraws the approval of the pull request by the current user.
+func UnapprovePull(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+
+	if err := issue.PullRequest.Unapprove(c.Req.Context(), c.User); err != nil {
+		c.Error(err, "unapprove")
+		return
+	}
+
+	log.Trace("Pull request approval withdrawn [%d]: %d", issue.PullRequest.ID, c.User.ID)
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
+// LinkPullIssue links the issue with given index to the pull request to be
+// closed when the pull request is merged.
+func LinkPullIssue(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+	redirectTo := c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index)
+
+	linked, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.QueryInt64("index"))
+	if err != nil {
+		if db.IsErrIssueNotExist(err) {
+			c.Flash.Error(c.Tr("repo.pulls.linked_issues_not_exist"))
+			c.Redirect(redirectTo)
+			return
+		}
+		c.Error(err, "get issue by index")
+		return
+	}
+
+	if err = issue.PullRequest.LinkIssue(c.Req.Context(), linked); err != nil {
+		if db.IsErrLinkedIssueNotAllowed(err) {
+			c.Flash.Error(c.Tr("repo.pulls.linked_issues_not_allowed"))
+			c.Redirect(redirectTo)
+			return
+		}
+		c.Error(err, "link issue")
+		return
+	}
+
+	log.Trace("Issue linked to pull request [%d]: %d", issue.PullRequest.ID, linked.ID)
+	c.Redirect(redirectTo)
+}
+
+// UnlinkPullIssue unlinks the issue with given index from the pull request.
+func UnlinkPullIssue(c *context.Context) {
+	issue := checkPullInfo(c)
+	if c.Written() {
+		return
+	}
+
+	linked, err := db.GetIssueByIndex(c.Repo.Repository.ID, c.QueryInt64("index"))
+	if err != nil {
+		c.NotFoundOrError(err, "get issue by index")
+		return
+	}
+
+	if err = issue.PullRequest.UnlinkIssue(c.Req.Context(), linked); err != nil {
+		c.Error(err, "unlink issue")
+		return
+	}
+
+	log.Trace("Issue unlinked from pull request [%d]: %d", issue.PullRequest.ID, linked.ID)
+	c.Redirect(c.Repo.RepoLink + "/pulls/" + com.ToStr(issue.Index))
+}
+
 func ParseCompareInfo(c *context.Context) (*db.User, *db.Repository, *git.Repository, *gitutil.PullRequestMeta, string, string) {
 	baseRepo := c.Repo.Repository
 
@@ -597,6 +885,7 @@ func PrepareCompareDiff(
 		c.Error(err, "get repository diff")
 		return false
 	}
+	applyDiffRenderOptions(c, diff)
 	c.Data["Diff"] = diff
 	c.Data["DiffNotAvailable"] = diff.NumFiles() == 0
 
@@ -753,6 +1042,8 @@ func CompareAndPullRequestPost(c *context.Context, f form.NewIssue) {
 	} else if err := pullRequest.PushToBaseRepo(); err != nil {
 		c.Error(err, "push to base repository")
 		return
+	} else if err := pullRequest.ApplyPathLabels(c.User); err != nil {
+		log.Error("Failed to apply path labels to pull request [id: %d]: %v", pullRequest.ID, err)
 	}
 
 	log.Trace("Pull request created: %d/%d", repo.ID, pullIssue.ID)
@@ -24,6 +24,13 @@ const (
 	RELEASE_NEW = "repo/release/new"
 )
 
+func MustEnableReleases(c *context.Context) {
+	if !c.Repo.Repository.EnableReleases {
+		c.NotFound()
+		return
+	}
+}
+
 // calReleaseNumCommitsBehind calculates given release has how many commits behind release target.
 func calReleaseNumCommitsBehind(repoCtx *context.Repository, release *db.Release, countCache map[string]int64) error {
 	// Get count if not exists
@@ -151,6 +158,28 @@ func Releases(c *context.Context) {
 	c.Success(RELEASES)
 }
 
+// ReleaseChecksums serves the "SHA256SUMS" file of attachments of the release.
+func ReleaseChecksums(c *context.Context) {
+	rel, err := db.GetRelease(c.Repo.Repository.ID, c.Params("*"))
+	if err != nil {
+		c.NotFoundOrError(err, "get release")
+		return
+	} else if rel.IsDraft && !c.Repo.IsWriter() {
+		c.NotFound()
+		return
+	}
+
+	rel.Attachments, err = db.GetAttachmentsByReleaseID(rel.ID)
+	if err != nil {
+		c.Error(err, "get attachments by release ID")
+		return
+	}
+
+	c.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Resp.Header().Set("Content-Disposition", `attachment; filename="SHA256SUMS"`)
+	_, _ = c.Resp.Write(rel.SHA256Sums())
+}
+
 func renderReleaseAttachmentSettings(c *context.Context) {
 	c.Data["RequireDropzone"] = true
 	c.Data["IsAttachmentEnabled"] = conf.Release.Attachment.Enabled
@@ -314,7 +343,7 @@ func UploadReleaseAttachment(c *context.Context) {
 }
 
 func DeleteRelease(c *context.Context) {
-	if err := db.DeleteReleaseOfRepoByID(c.Repo.Repository.ID, c.QueryInt64("id")); err != nil {
+	if err := db.DeleteReleaseOfRepoByID(c.User, c.Repo.Repository.ID, c.QueryInt64("id")); err != nil {
 		c.Flash.Error("DeleteReleaseByID: " + err.Error())
 	} else {
 		c.Flash.Success(c.Tr("repo.release.deletion_success"))
@@ -7,11 +7,8 @@ package repo
 import (
 	"net/http"
 	"os"
-	"path"
-	"path/filepath"
 	"strings"
 
-	"github.com/unknwon/com"
 	log "unknwon.dev/clog/v2"
 
 	"github.com/gogs/git-module"
@@ -20,6 +17,7 @@ import (
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/pathutil"
 	"gogs.io/gogs/internal/tool"
 )
 
@@ -73,15 +71,15 @@ func Create(c *context.Context) {
 	c.Data["Gitignores"] = db.Gitignores
 	c.Data["Licenses"] = db.Licenses
 	c.Data["Readmes"] = db.Readmes
-	c.Data["readme"] = "Default"
 	c.Data["private"] = c.User.LastRepoVisibility
-	c.Data["IsForcedPrivate"] = conf.Repository.ForcePrivate
 
 	ctxUser := checkContextUser(c, c.QueryInt64("org"))
 	if c.Written() {
 		return
 	}
 	c.Data["ContextUser"] = ctxUser
+	c.Data["IsForcedPrivate"] = !ctxUser.IsPublicRepoAllowed()
+	c.Data["gitignores"], c.Data["license"], c.Data["readme"] = ctxUser.RepoInitDefaults()
 
 	c.Success(CREATE)
 }
@@ -96,6 +94,10 @@ func handleCreateError(c *context.Context, err error, name, tpl string, form any
 	case db.IsErrNameNotAllowed(err):
 		c.Data["Err_RepoName"] = true
 		c.RenderWithErr(c.Tr("repo.form.name_not_allowed", err.(db.ErrNameNotAllowed).Value()), tpl, form)
+	case db.IsErrRepoInitTemplateNotExist(err):
+		c.RenderWithErr(c.Tr("repo.form.init_template_not_exist", err.(db.ErrRepoInitTemplateNotExist).Value()), tpl, form)
+	case db.IsErrRepoVisibilityNotAllowed(err):
+		c.RenderWithErr(c.Tr("repo.form.public_repo_not_allowed"), tpl, form)
 	default:
 		c.Error(err, name)
 	}
@@ -113,6 +115,7 @@ func CreatePost(c *context.Context, f form.CreateRepo) {
 		return
 	}
 	c.Data["ContextUser"] = ctxUser
+	c.Data["IsForcedPrivate"] = !ctxUser.IsPublicRepoAllowed()
 
 	if c.HasError() {
 		c.Success(CREATE)
@@ -147,7 +150,6 @@ func CreatePost(c *context.Context, f form.CreateRepo) {
 func Migrate(c *context.Context) {
 	c.Data["Title"] = c.Tr("new_migrate")
 	c.Data["private"] = c.User.LastRepoVisibility
-	c.Data["IsForcedPrivate"] = conf.Repository.ForcePrivate
 	c.Data["mirror"] = c.Query("mirror") == "1"
 
 	ctxUser := checkContextUser(c, c.QueryInt64("org"))
@@ -155,6 +157,7 @@ func Migrate(c *context.Context) {
 		return
 	}
 	c.Data["ContextUser"] = ctxUser
+	c.Data["IsForcedPrivate"] = !ctxUser.IsPublicRepoAllowed()
 
 	c.Success(MIGRATE)
 }
@@ -167,6 +170,7 @@ func MigratePost(c *context.Context, f form.MigrateRepo) {
 		return
 	}
 	c.Data["ContextUser"] = ctxUser
+	c.Data["IsForcedPrivate"] = !ctxUser.IsPublicRepoAllowed()
 
 	if c.HasError() {
 		c.Success(MIGRATE)
@@ -234,14 +238,16 @@ func Action(c *context.Context) {
 	var err error
 	switch c.Params(":action") {
 	case "watch":
-		err = db.WatchRepo(c.User.ID, c.Repo.Repository.ID, true)
+		err = db.Repos.Watch(c.Req.Context(), c.User.ID, c.Repo.Repository.ID)
 	case "unwatch":
 		if userID := c.QueryInt64("user_id"); userID != 0 {
 			if c.User.IsAdmin {
 				err = db.WatchRepo(userID, c.Repo.Repository.ID, false)
 			}
 		} else {
-			err = db.WatchRepo(c.User.ID, c.Repo.Repository.ID, false)
+			// Explicitly unwatching makes the repository ignored, so that it is not
+			// watched automatically again.
+			err = db.Repos.Unwatch(c.Req.Context(), c.User.ID, c.Repo.Repository.ID)
 		}
 	case "star":
 		err = db.StarRepo(c.User.ID, c.Repo.Repository.ID, true)
@@ -275,18 +281,18 @@ func Download(c *context.Context) {
 		uri           = c.Params("*")
 		refName       string
 		ext           string
-		archivePath   string
+		contentType   string
 		archiveFormat git.ArchiveFormat
 	)
 
 	switch {
 	case strings.HasSuffix(uri, ".zip"):
 		ext = ".zip"
-		archivePath = filepath.Join(c.Repo.GitRepo.Path(), "archives", "zip")
+		contentType = "application/zip"
 		archiveFormat = git.ArchiveZip
 	case strings.HasSuffix(uri, ".tar.gz"):
 		ext = ".tar.gz"
-		archivePath = filepath.Join(c.Repo.GitRepo.Path(), "archives", "targz")
+		contentType = "application/gzip"
 		archiveFormat = git.ArchiveTarGz
 	default:
 		log.Trace("Unknown format: %s", uri)
@@ -295,13 +301,6 @@ func Download(c *context.Context) {
 	}
 	refName = strings.TrimSuffix(uri, ext)
 
-	if !com.IsDir(archivePath) {
-		if err := os.MkdirAll(archivePath, os.ModePerm); err != nil {
-			c.Error(err, "create archive directory")
-			return
-		}
-	}
-
 	// Get corresponding commit.
 	var (
 		commit *git.Commit
@@ -331,13 +330,43 @@ func Download(c *context.Context) {
 		return
 	}
 
-	archivePath = path.Join(archivePath, tool.ShortSHA1(commit.ID.String())+ext)
-	if !com.IsFile(archivePath) {
-		if err := commit.CreateArchive(archiveFormat, archivePath); err != nil {
-			c.Error(err, "creates archive")
+	// Only archive the subdirectory when requested.
+	treePath := pathutil.Clean(c.Query("path"))
+	if treePath != "" {
+		if _, err = commit.Subtree(treePath); err != nil {
+			c.NotFound()
+			return
+		}
+	}
+
+	archivePath, err := c.Repo.Repository.ArchiveCommit(commit, archiveFormat, treePath)
+	if err != nil {
+		if db.IsErrArchiveTooLarge(err) {
+			c.PlainText(http.StatusRequestEntityTooLarge, err.Error())
 			return
 		}
+		c.Error(err, "create archive")
+		return
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		c.Error(err, "open archive")
+		return
 	}
+	defer func() { _ = f.Close() }()
 
-	c.ServeFile(archivePath, c.Repo.Repository.Name+"-"+refName+ext)
+	fi, err := f.Stat()
+	if err != nil {
+		c.Error(err, "stat archive")
+		return
+	}
+
+	name := c.Repo.Repository.Name + "-" + strings.ReplaceAll(refName, "/", "-")
+	if treePath != "" {
+		name += "-" + strings.ReplaceAll(treePath, "/", "-")
+	}
+	c.Resp.Header().Set("Content-Type", contentType)
+	c.Resp.Header().Set("Content-Disposition", `attachment; filename="`+name+ext+`"`)
+	http.ServeContent(c.Resp, c.Req.Request, name+ext, fi.ModTime(), f)
 }
@@ -7,8 +7,8 @@ package repo
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
-	"time"
 
 	"github.com/gogs/git-module"
 	"github.com/unknwon/com"
@@ -20,6 +20,7 @@ import (
 	"gogs.io/gogs/internal/db/errors"
 	"gogs.io/gogs/internal/email"
 	"gogs.io/gogs/internal/form"
+	"gogs.io/gogs/internal/markup"
 	"gogs.io/gogs/internal/osutil"
 	"gogs.io/gogs/internal/tool"
 	"gogs.io/gogs/internal/userutil"
@@ -40,15 +41,140 @@ func Settings(c *context.Context) {
 	c.Title("repo.settings")
 	c.PageIs("SettingsOptions")
 	c.RequireAutosize()
+	c.Data["IsForcedPrivate"] = !c.Repo.Owner.IsPublicRepoAllowed()
+	c.Data["CLAAllowlistUsers"] = joinUsernames(c, c.Repo.Repository.CLAAllowlistUserIDList())
+	if !loadRepoTopics(c, c.Repo.Repository) ||
+		!loadAutoAssignSettings(c, c.Repo.Repository) ||
+		!loadDefaultReviewers(c, c.Repo.Repository) {
+		return
+	}
 	c.Success(SETTINGS_OPTIONS)
 }
 
+// loadRepoTopics loads topics of the repository for the settings page. It
+// returns false if an error has been rendered.
+func loadRepoTopics(c *context.Context, repo *db.Repository) bool {
+	c.Data["TopicsStrict"] = conf.Repository.Topics.Strict
+
+	var err error
+	repo.Topics, err = db.Topics.ListByRepo(c.Req.Context(), repo.ID)
+	if err != nil {
+		c.Error(err, "list topics")
+		return false
+	}
+	return true
+}
+
+// loadAutoAssignSettings loads teams of the organization and users marked
+// unavailable for automatic assignment of new issues for the settings page. It
+// returns false if an error has been rendered.
+func loadAutoAssignSettings(c *context.Context, repo *db.Repository) bool {
+	c.Data["AutoAssignUnavailableUsers"] = joinUsernames(c, repo.AutoAssignUnavailableUserIDList())
+	if !c.Repo.Owner.IsOrganization() {
+		return true
+	}
+
+	if err := c.Repo.Owner.GetTeams(); err != nil {
+		c.Error(err, "get teams")
+		return false
+	}
+	c.Data["AutoAssignTeams"] = c.Repo.Owner.Teams
+	return true
+}
+
+// loadDefaultReviewers loads names of users and teams who are requested to
+// review new pull requests, and the default assignee of new pull requests for
+// the settings page. It returns false if an error
+// has been rendered.
+func loadDefaultReviewers(c *context.Context, repo *db.Repository) bool {
+	users, teams, err := repo.DefaultReviewers(c.Req.Context())
+	if err != nil {
+		c.Error(err, "get default reviewers")
+		return false
+	}
+
+	usernames := make([]string, len(users))
+	for i := range users {
+		usernames[i] = users[i].Name
+	}
+	teamNames := make([]string, len(teams))
+	for i := range teams {
+		teamNames[i] = teams[i].Name
+	}
+	c.Data["DefaultReviewers"] = strings.Join(usernames, ", ")
+	c.Data["DefaultReviewerTeams"] = strings.Join(teamNames, ", ")
+	if repo.DefaultPullAssigneeID > 0 {
+		c.Data["DefaultPullAssignee"] = joinUsernames(c, []int64{repo.DefaultPullAssigneeID})
+	}
+	return true
+}
+
+// splitNames returns non-empty names in the comma-separated list.
+func splitNames(names string) []string {
+	fields := strings.Split(names, ",")
+	list := make([]string, 0, len(fields))
+	for _, name := range fields {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// joinUsernames returns the comma-separated names of users with given IDs.
+// Users that no longer exist are skipped.
+func joinUsernames(c *context.Context, userIDs []int64) string {
+	names := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		u, err := db.Users.GetByID(c.Req.Context(), id)
+		if err != nil {
+			if !db.IsErrUserNotExist(err) {
+				log.Error("Failed to get user by ID [%d]: %v", id, err)
+			}
+			continue
+		}
+		names = append(names, u.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// parseUsernames returns the comma-separated IDs of users with given
+// comma-separated names. It returns false if an error has been rendered, e.g.
+// any of the users does not exist.
+func parseUsernames(c *context.Context, names string) (string, bool) {
+	userIDs := make([]string, 0, 5)
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		u, err := db.Users.GetByUsername(c.Req.Context(), name)
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				c.Flash.Error(c.Tr("repo.settings.cla_allowlist_user_not_exist", name))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+			} else {
+				c.Error(err, "get user by name")
+			}
+			return "", false
+		}
+		userIDs = append(userIDs, com.ToStr(u.ID))
+	}
+	return strings.Join(userIDs, ","), true
+}
+
 func SettingsPost(c *context.Context, f form.RepoSetting) {
 	c.Title("repo.settings")
 	c.PageIs("SettingsOptions")
 	c.RequireAutosize()
+	c.Data["IsForcedPrivate"] = !c.Repo.Owner.IsPublicRepoAllowed()
 
 	repo := c.Repo.Repository
+	c.Data["CLAAllowlistUsers"] = joinUsernames(c, repo.CLAAllowlistUserIDList())
+	if !loadRepoTopics(c, repo) || !loadAutoAssignSettings(c, repo) || !loadDefaultReviewers(c, repo) {
+		return
+	}
 
 	switch c.Query("action") {
 	case "update":
@@ -57,6 +183,36 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 			return
 		}
 
+		topics, err := db.ValidateTopics(strings.Split(f.Topics, ","))
+		if err != nil {
+			c.FormErr("Topics")
+			switch {
+			case db.IsErrTopicInvalid(err):
+				c.RenderWithErr(c.Tr("repo.settings.topic_invalid", err.(db.ErrTopicInvalid).Topic()), SETTINGS_OPTIONS, &f)
+			case db.IsErrTopicNotAllowed(err):
+				c.RenderWithErr(c.Tr("repo.settings.topic_not_allowed", err.(db.ErrTopicNotAllowed).Topic()), SETTINGS_OPTIONS, &f)
+			case db.IsErrTooManyTopics(err):
+				c.RenderWithErr(c.Tr("repo.settings.too_many_topics", db.MaxRepoTopics), SETTINGS_OPTIONS, &f)
+			default:
+				c.Error(err, "validate topics")
+			}
+			return
+		}
+
+		// Visibility of forked repository is forced sync with base repository,
+		// unless the owner is not allowed to have public repositories.
+		if repo.IsFork {
+			f.Private = repo.BaseRepo.IsPrivate || !c.Repo.Owner.IsPublicRepoAllowed()
+			f.Unlisted = repo.BaseRepo.IsUnlisted
+		}
+
+		visibilityChanged := repo.IsPrivate != f.Private || repo.IsUnlisted != f.Unlisted
+		if repo.IsPrivate && !f.Private && !c.Repo.Owner.IsPublicRepoAllowed() {
+			c.RenderWithErr(c.Tr("repo.form.public_repo_not_allowed"), SETTINGS_OPTIONS, &f)
+			return
+		}
+
+		old := *repo
 		isNameChanged := false
 		oldRepoName := repo.Name
 		newRepoName := f.RepoName
@@ -85,19 +241,16 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		repo.Description = f.Description
 		repo.Website = f.Website
 
-		// Visibility of forked repository is forced sync with base repository.
-		if repo.IsFork {
-			f.Private = repo.BaseRepo.IsPrivate
-			f.Unlisted = repo.BaseRepo.IsUnlisted
-		}
-
-		visibilityChanged := repo.IsPrivate != f.Private || repo.IsUnlisted != f.Unlisted
 		repo.IsPrivate = f.Private
 		repo.IsUnlisted = f.Unlisted
 		if err := db.UpdateRepository(repo, visibilityChanged); err != nil {
 			c.Error(err, "update repository")
 			return
 		}
+		if err := db.Topics.Set(c.Req.Context(), repo.ID, topics); err != nil {
+			c.Error(err, "set topics")
+			return
+		}
 		log.Trace("Repository basic settings updated: %s/%s", c.Repo.Owner.Name, repo.Name)
 
 		if isNameChanged {
@@ -105,6 +258,9 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 				log.Error("create rename repository action: %v", err)
 			}
 		}
+		if err := db.PrepareRepositoryEditedWebhooks(c.User, &old, repo); err != nil {
+			log.Error("prepare repository edited webhooks: %v", err)
+		}
 
 		c.Flash.Success(c.Tr("repo.settings.update_settings_success"))
 		c.Redirect(repo.Link() + "/settings")
@@ -116,9 +272,12 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		}
 
 		if f.Interval > 0 {
+			if err := c.Repo.Mirror.SetInterval(f.Interval); err != nil {
+				c.FormErr("Interval")
+				c.RenderWithErr(c.Tr("repo.settings.mirror_interval_too_short", conf.Mirror.MinInterval), SETTINGS_OPTIONS, &f)
+				return
+			}
 			c.Repo.Mirror.EnablePrune = f.EnablePrune
-			c.Repo.Mirror.Interval = f.Interval
-			c.Repo.Mirror.NextSync = time.Now().Add(time.Duration(f.Interval) * time.Hour)
 			if err := db.UpdateMirror(c.Repo.Mirror); err != nil {
 				c.Error(err, "update mirror")
 				return
@@ -138,7 +297,11 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 			return
 		}
 
-		go db.MirrorQueue.Add(repo.ID)
+		if err := c.Repo.Mirror.SyncNow(); err != nil {
+			c.Flash.Error(c.Tr("repo.settings.mirror_sync_cooldown", err.(errors.MirrorSyncCooldown).Remaining))
+			c.Redirect(repo.Link() + "/settings")
+			return
+		}
 		c.Flash.Info(c.Tr("repo.settings.mirror_sync_in_progress"))
 		c.Redirect(repo.Link() + "/settings")
 
@@ -149,6 +312,13 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		repo.ExternalWikiURL = f.ExternalWikiURL
 		repo.EnableIssues = f.EnableIssues
 		repo.AllowPublicIssues = f.AllowPublicIssues
+		repo.IssuesReadOnly = f.IssuesReadOnly
+		repo.IssueKeyPrefix = strings.TrimSpace(f.IssueKeyPrefix)
+		if repo.IssueKeyPrefix != "" && !markup.IssueKeyPrefixPattern.MatchString(repo.IssueKeyPrefix) {
+			c.Flash.Error(c.Tr("repo.settings.issue_key_prefix_invalid"))
+			c.Redirect(c.Repo.RepoLink + "/settings")
+			return
+		}
 		repo.EnableExternalTracker = f.EnableExternalTracker
 		repo.ExternalTrackerURL = f.ExternalTrackerURL
 		repo.ExternalTrackerFormat = f.TrackerURLFormat
@@ -156,12 +326,122 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		repo.EnablePulls = f.EnablePulls
 		repo.PullsIgnoreWhitespace = f.PullsIgnoreWhitespace
 		repo.PullsAllowRebase = f.PullsAllowRebase
+		repo.PullsAutosquash = f.PullsAutosquash
+		repo.PullsDeleteBranchAfterMerge = f.PullsDeleteBranchAfterMerge
+		repo.PullsDeleteBranchExemptions = strings.Join(db.ParseFilePatterns(f.PullsDeleteBranchExemptions), ",")
+		if err := db.ValidateBranchPatterns(repo.PullsDeleteBranchExemptions); err != nil {
+			c.Flash.Error(c.Tr("repo.settings.pulls.delete_branch_exemptions_invalid"))
+			c.Redirect(c.Repo.RepoLink + "/settings")
+			return
+		}
+		repo.EnableReleases = f.EnableReleases
+		repo.EnableLFS = f.EnableLFS
+
+		repo.RequireCLA = f.RequireCLA
+		repo.CLAExemptOrgMembers = f.CLAExemptOrgMembers
+		repo.CLADocumentURL = strings.TrimSpace(f.CLADocumentURL)
+		if repo.RequireCLA {
+			u, err := url.Parse(repo.CLADocumentURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				c.Flash.Error(c.Tr("repo.settings.cla_document_url_invalid"))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+				return
+			}
+		}
+
+		var ok bool
+		repo.CLAAllowlistUserIDs, ok = parseUsernames(c, f.CLAAllowlistUsers)
+		if !ok {
+			return
+		}
+
+		repo.RequireSignOff = f.RequireSignOff
+		emails := make([]string, 0, 5)
+		for _, email := range strings.Split(f.SignOffAllowlistEmails, ",") {
+			email = strings.TrimSpace(email)
+			if email == "" {
+				continue
+			} else if !strings.Contains(email, "@") {
+				c.Flash.Error(c.Tr("repo.settings.sign_off_allowlist_email_invalid", email))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+				return
+			}
+			emails = append(emails, email)
+		}
+		repo.SignOffAllowlistEmails = strings.Join(emails, ",")
+
+		repo.AutoAssignStrategy = db.AutoAssignStrategy(f.AutoAssignStrategy)
+		if !repo.AutoAssignStrategy.IsValid() {
+			repo.AutoAssignStrategy = db.AutoAssignDisabled
+		}
+		repo.AutoAssignTeamID = 0
+		if repo.AutoAssignStrategy != db.AutoAssignDisabled {
+			t, err := db.GetTeamByID(f.AutoAssignTeamID)
+			if err != nil && !db.IsErrTeamNotExist(err) {
+				c.Error(err, "get team by ID")
+				return
+			} else if err != nil || t.OrgID != repo.OwnerID {
+				c.Flash.Error(c.Tr("repo.settings.auto_assign_team_invalid"))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+				return
+			}
+			repo.AutoAssignTeamID = t.ID
+		}
+		repo.AutoAssignUnavailableUserIDs, ok = parseUsernames(c, f.AutoAssignUnavailableUsers)
+		if !ok {
+			return
+		}
+
+		repo.ReviewRotationStrategy = db.AutoAssignStrategy(f.ReviewRotationStrategy)
+		if !repo.ReviewRotationStrategy.IsValid() {
+			repo.ReviewRotationStrategy = db.AutoAssignDisabled
+		}
+		repo.ReviewRotationTeamID = 0
+		if repo.ReviewRotationStrategy != db.AutoAssignDisabled {
+			t, err := db.GetTeamByID(f.ReviewRotationTeamID)
+			if err != nil && !db.IsErrTeamNotExist(err) {
+				c.Error(err, "get team by ID")
+				return
+			} else if err != nil || t.OrgID != repo.OwnerID {
+				c.Flash.Error(c.Tr("repo.settings.review_rotation_team_invalid"))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+				return
+			}
+			repo.ReviewRotationTeamID = t.ID
+		}
+		repo.ReviewRotationCount = f.ReviewRotationCount
+		if repo.ReviewRotationCount < 1 {
+			repo.ReviewRotationCount = 1
+		}
+
+		err := repo.SetDefaultReviewers(c.Req.Context(), splitNames(f.DefaultReviewers), splitNames(f.DefaultReviewerTeams))
+		if err != nil {
+			if db.IsErrUserNotExist(err) || db.IsErrTeamNotExist(err) {
+				c.Flash.Error(c.Tr("repo.settings.default_reviewers_not_exist"))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+			} else {
+				c.Error(err, "set default reviewers")
+			}
+			return
+		}
+
+		err = repo.SetDefaultPullAssignee(c.Req.Context(), strings.TrimSpace(f.DefaultPullAssignee))
+		if err != nil {
+			if db.IsErrUserNotExist(err) {
+				c.Flash.Error(c.Tr("repo.settings.default_pull_assignee_invalid"))
+				c.Redirect(c.Repo.RepoLink + "/settings")
+			} else {
+				c.Error(err, "set default pull request assignee")
+			}
+			return
+		}
 
 		if !repo.EnableWiki || repo.EnableExternalWiki {
 			repo.AllowPublicWiki = false
 		}
 		if !repo.EnableIssues || repo.EnableExternalTracker {
 			repo.AllowPublicIssues = false
+			repo.IssuesReadOnly = false
 		}
 
 		if err := db.UpdateRepository(repo, false); err != nil {
@@ -233,12 +513,15 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 		if err := db.TransferOwnership(c.User, newOwner, repo); err != nil {
 			if db.IsErrRepoAlreadyExist(err) {
 				c.RenderWithErr(c.Tr("repo.settings.new_owner_has_same_repo"), SETTINGS_OPTIONS, nil)
+			} else if db.IsErrRepoVisibilityNotAllowed(err) {
+				c.RenderWithErr(c.Tr("repo.settings.new_owner_public_repo_not_allowed"), SETTINGS_OPTIONS, nil)
 			} else {
 				c.Error(err, "transfer ownership")
 			}
 			return
 		}
 		log.Trace("Repository transferred: %s/%s -> %s", c.Repo.Owner.Name, repo.Name, newOwner)
+		db.RecordAudit(c.Req.Context(), db.AuditActionRepoTransfer, repo.ID, c.Repo.Owner.Name+"/"+repo.Name, "new owner: "+newOwner)
 		c.Flash.Success(c.Tr("repo.settings.transfer_succeed"))
 		c.Redirect(conf.Server.Subpath + "/" + newOwner + "/" + repo.Name)
 
@@ -264,6 +547,7 @@ func SettingsPost(c *context.Context, f form.RepoSetting) {
 			return
 		}
 		log.Trace("Repository deleted: %s/%s", c.Repo.Owner.Name, repo.Name)
+		db.RecordAudit(c.Req.Context(), db.AuditActionRepoDelete, repo.ID, c.Repo.Owner.Name+"/"+repo.Name, "")
 
 		c.Flash.Success(c.Tr("repo.settings.deletion_success"))
 		c.Redirect(userutil.DashboardURLPath(c.Repo.Owner.Name, c.Repo.Owner.IsOrganization()))
@@ -370,6 +654,23 @@ func SettingsCollaboration(c *context.Context) {
 	}
 	c.Data["Collaborators"] = users
 
+	invitations, err := db.RepoInvitations.ListByRepoID(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list invitations")
+		return
+	}
+	invitees := make([]*db.User, 0, len(invitations))
+	for _, inv := range invitations {
+		invitee, err := db.Users.GetByID(c.Req.Context(), inv.InviteeID)
+		if err != nil {
+			c.Error(err, "get invitee")
+			return
+		}
+		invitees = append(invitees, invitee)
+	}
+	c.Data["Invitations"] = invitations
+	c.Data["Invitees"] = invitees
+
 	c.Success(SETTINGS_COLLABORATION)
 }
 
@@ -398,10 +699,37 @@ func SettingsCollaborationPost(c *context.Context) {
 		return
 	}
 
+	if conf.Repository.RequireCollaboratorInvitation && !c.Repo.Repository.IsCollaborator(u.ID) {
+		_, err = db.RepoInvitations.Create(c.Req.Context(), c.Repo.Repository.ID, c.User.ID, u.ID, db.AccessModeWrite)
+		if err != nil {
+			if db.IsErrRepoInvitationAlreadyExist(err) {
+				c.Flash.Error(c.Tr("repo.settings.collaborator_already_invited"))
+				c.Redirect(conf.Server.Subpath + c.Req.URL.Path)
+			} else {
+				c.Error(err, "create invitation")
+			}
+			return
+		}
+
+		if conf.User.EnableEmailNotification {
+			email.SendCollaboratorInvitationMail(db.NewMailerUser(u), db.NewMailerUser(c.User), db.NewMailerRepo(c.Repo.Repository))
+		}
+
+		c.Flash.Success(c.Tr("repo.settings.invite_collaborator_success"))
+		c.Redirect(conf.Server.Subpath + c.Req.URL.Path)
+		return
+	}
+
 	if err = c.Repo.Repository.AddCollaborator(u); err != nil {
-		c.Error(err, "add collaborator")
+		if db.IsErrReachLimitOfCollaborators(err) {
+			c.Flash.Error(c.Tr("repo.settings.reach_limit_of_collaborators", err.(db.ErrReachLimitOfCollaborators).Limit))
+			c.Redirect(conf.Server.Subpath + c.Req.URL.Path)
+		} else {
+			c.Error(err, "add collaborator")
+		}
 		return
 	}
+	db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorAdd, c.Repo.Repository.ID, c.Repo.Repository.FullName(), "collaborator: "+u.Name)
 
 	if conf.User.EnableEmailNotification {
 		email.SendCollaboratorMail(db.NewMailerUser(u), db.NewMailerUser(c.User), db.NewMailerRepo(c.Repo.Repository))
@@ -412,20 +740,25 @@ func SettingsCollaborationPost(c *context.Context) {
 }
 
 func ChangeCollaborationAccessMode(c *context.Context) {
-	if err := c.Repo.Repository.ChangeCollaborationAccessMode(
-		c.QueryInt64("uid"),
-		db.AccessMode(c.QueryInt("mode"))); err != nil {
+	userID := c.QueryInt64("uid")
+	mode := db.AccessMode(c.QueryInt("mode"))
+	if err := c.Repo.Repository.ChangeCollaborationAccessMode(userID, mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
 		return
 	}
+	db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorEdit, c.Repo.Repository.ID, c.Repo.Repository.FullName(),
+		fmt.Sprintf("collaborator ID: %d, permission: %s", userID, mode))
 
 	c.Status(204)
 }
 
 func DeleteCollaboration(c *context.Context) {
-	if err := c.Repo.Repository.DeleteCollaboration(c.QueryInt64("id")); err != nil {
+	userID := c.QueryInt64("id")
+	if err := c.Repo.Repository.DeleteCollaboration(userID); err != nil {
 		c.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		db.RecordAudit(c.Req.Context(), db.AuditActionCollaboratorRemove, c.Repo.Repository.ID, c.Repo.Repository.FullName(),
+			fmt.Sprintf("collaborator ID: %d", userID))
 		c.Flash.Success(c.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -434,6 +767,18 @@ func DeleteCollaboration(c *context.Context) {
 	})
 }
 
+func DeleteCollaboratorInvitation(c *context.Context) {
+	if err := db.RepoInvitations.DeleteByID(c.Req.Context(), c.Repo.Repository.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("DeleteCollaboratorInvitation: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("repo.settings.cancel_invitation_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": c.Repo.RepoLink + "/settings/collaboration",
+	})
+}
+
 func SettingsBranches(c *context.Context) {
 	c.Data["Title"] = c.Tr("repo.settings.branches")
 	c.Data["PageIsSettingsBranches"] = true
@@ -458,12 +803,26 @@ func SettingsBranches(c *context.Context) {
 		}
 	}
 	c.Data["ProtectBranches"] = branches
+	c.Data["ConventionalCommitsPattern"] = db.ConventionalCommitsPattern
+
+	if c.Repo.Owner.IsOrganization() {
+		c.Data["OrgBranchNamePattern"] = c.Repo.Owner.BranchNamePattern
+		c.Data["OrgCommitMessagePattern"] = c.Repo.Owner.CommitMessagePattern
+	}
+
+	limits, err := c.Repo.Repository.EffectivePushLimits()
+	if err != nil {
+		c.Error(err, "get effective push limits")
+		return
+	}
+	c.Data["PushLimits"] = limits
 
 	c.Success(SETTINGS_BRANCHES)
 }
 
 func UpdateDefaultBranch(c *context.Context) {
 	branch := c.Query("branch")
+	old := *c.Repo.Repository
 	if c.Repo.GitRepo.HasBranch(branch) &&
 		c.Repo.Repository.DefaultBranch != branch {
 		c.Repo.Repository.DefaultBranch = branch
@@ -480,11 +839,77 @@ func UpdateDefaultBranch(c *context.Context) {
 		c.Error(err, "update repository")
 		return
 	}
+	if err := db.PrepareRepositoryEditedWebhooks(c.User, &old, c.Repo.Repository); err != nil {
+		log.Error("prepare repository edited webhooks: %v", err)
+	}
 
 	c.Flash.Success(c.Tr("repo.settings.update_default_branch_success"))
 	c.Redirect(c.Repo.RepoLink + "/settings/branches")
 }
 
+func UpdateBranchNamePattern(c *context.Context) {
+	pattern := strings.TrimSpace(c.Query("pattern"))
+	if err := db.ValidateBranchNamePattern(pattern); err != nil {
+		c.Flash.Error(c.Tr("repo.settings.branch_name_pattern_invalid", pattern))
+		c.Redirect(c.Repo.RepoLink + "/settings/branches")
+		return
+	}
+
+	c.Repo.Repository.BranchNamePattern = pattern
+	if err := db.UpdateRepository(c.Repo.Repository, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+
+	c.Flash.Success(c.Tr("repo.settings.update_branch_name_pattern_success"))
+	c.Redirect(c.Repo.RepoLink + "/settings/branches")
+}
+
+func UpdateCommitMessagePattern(c *context.Context) {
+	pattern := strings.TrimSpace(c.Query("pattern"))
+	if err := db.ValidateCommitMessagePattern(pattern); err != nil {
+		c.Flash.Error(c.Tr("repo.settings.commit_message_pattern_invalid", pattern))
+		c.Redirect(c.Repo.RepoLink + "/settings/branches")
+		return
+	}
+
+	c.Repo.Repository.CommitMessagePattern = pattern
+	c.Repo.Repository.CommitMessageExemptMerges = c.QueryBool("exempt_merges")
+	if err := db.UpdateRepository(c.Repo.Repository, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+
+	c.Flash.Success(c.Tr("repo.settings.update_commit_message_pattern_success"))
+	c.Redirect(c.Repo.RepoLink + "/settings/branches")
+}
+
+func UpdatePushLimits(c *context.Context) {
+	patterns := strings.TrimSpace(c.Query("blocked_file_patterns"))
+	if err := db.ValidateFilePatterns(patterns); err != nil {
+		c.Flash.Error(c.Tr("repo.settings.push_blocked_file_patterns_invalid", patterns))
+		c.Redirect(c.Repo.RepoLink + "/settings/branches")
+		return
+	}
+
+	c.Repo.Repository.PushMaxSize = c.QueryInt64("max_size")
+	c.Repo.Repository.PushMaxBlobSize = c.QueryInt64("max_blob_size")
+	if c.Repo.Repository.PushMaxSize < 0 {
+		c.Repo.Repository.PushMaxSize = 0
+	}
+	if c.Repo.Repository.PushMaxBlobSize < 0 {
+		c.Repo.Repository.PushMaxBlobSize = 0
+	}
+	c.Repo.Repository.PushBlockedFilePatterns = patterns
+	if err := db.UpdateRepository(c.Repo.Repository, false); err != nil {
+		c.Error(err, "update repository")
+		return
+	}
+
+	c.Flash.Success(c.Tr("repo.settings.update_push_limits_success"))
+	c.Redirect(c.Repo.RepoLink + "/settings/branches")
+}
+
 func SettingsProtectedBranch(c *context.Context) {
 	branch := c.Params("*")
 	if !c.Repo.GitRepo.HasBranch(branch) {
@@ -495,9 +920,9 @@ func SettingsProtectedBranch(c *context.Context) {
 	c.Data["Title"] = c.Tr("repo.settings.protected_branches") + " - " + branch
 	c.Data["PageIsSettingsBranches"] = true
 
-	protectBranch, err := db.GetProtectBranchOfRepoByName(c.Repo.Repository.ID, branch)
+	protectBranch, err := db.ProtectBranches.GetByName(c.Req.Context(), c.Repo.Repository.ID, branch)
 	if err != nil {
-		if !db.IsErrBranchNotExist(err) {
+		if !db.IsErrProtectBranchNotExist(err) {
 			c.Error(err, "get protect branch of repository by name")
 			return
 		}
@@ -537,9 +962,9 @@ func SettingsProtectedBranchPost(c *context.Context, f form.ProtectBranch) {
 		return
 	}
 
-	protectBranch, err := db.GetProtectBranchOfRepoByName(c.Repo.Repository.ID, branch)
+	protectBranch, err := db.ProtectBranches.GetByName(c.Req.Context(), c.Repo.Repository.ID, branch)
 	if err != nil {
-		if !db.IsErrBranchNotExist(err) {
+		if !db.IsErrProtectBranchNotExist(err) {
 			c.Error(err, "get protect branch of repository by name")
 			return
 		}
@@ -553,14 +978,28 @@ func SettingsProtectedBranchPost(c *context.Context, f form.ProtectBranch) {
 
 	protectBranch.Protected = f.Protected
 	protectBranch.RequirePullRequest = f.RequirePullRequest
+	protectBranch.RequireLinearHistory = f.RequireLinearHistory
+	protectBranch.RequireUpToDate = f.RequireUpToDate
+	protectBranch.RequireCodeOwnerReviews = f.RequireCodeOwnerReviews
+	protectBranch.RequiredApprovals = f.RequiredApprovals
+	protectBranch.RequiredStatusChecks = f.RequiredStatusChecks
 	protectBranch.EnableWhitelist = f.EnableWhitelist
 	if c.Repo.Owner.IsOrganization() {
-		err = db.UpdateOrgProtectBranch(c.Repo.Repository, protectBranch, f.WhitelistUsers, f.WhitelistTeams)
-	} else {
-		err = db.UpdateProtectBranch(protectBranch)
+		protectBranch.WhitelistUserIDs = f.WhitelistUsers
+		protectBranch.WhitelistTeamIDs = f.WhitelistTeams
 	}
+	err = db.ProtectBranches.Save(c.Req.Context(), c.Repo.Repository, protectBranch)
 	if err != nil {
-		c.Error(err, "update protect branch")
+		if db.IsErrInvalidProtectBranchWhitelist(err) {
+			c.Flash.Error(c.Tr("repo.settings.protect_invalid_whitelist"))
+			c.Redirect(fmt.Sprintf("%s/settings/branches/%s", c.Repo.RepoLink, branch))
+			return
+		} else if db.IsErrInvalidProtectBranchRequiredApprovals(err) {
+			c.Flash.Error(c.Tr("repo.settings.protect_invalid_required_approvals"))
+			c.Redirect(fmt.Sprintf("%s/settings/branches/%s", c.Repo.RepoLink, branch))
+			return
+		}
+		c.Error(err, "save protect branch")
 		return
 	}
 
@@ -7,11 +7,13 @@ package repo
 import (
 	"net/http"
 
+	"github.com/gogs/git-module"
 	"gopkg.in/macaron.v1"
 	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/cryptoutil"
 	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/gitutil"
 )
 
 func TriggerTask(c *macaron.Context) {
@@ -70,5 +72,16 @@ func TriggerTask(c *macaron.Context) {
 
 	go db.HookQueue.Add(repo.ID)
 	go db.AddTestPullRequestTask(pusher, repo.ID, branch, true)
+	// Queues are only consumed by the web server, tasks added by the hook
+	// subprocess would be lost.
+	db.AddRepoSizeTask(repo.ID)
+	if branch == repo.DefaultBranch {
+		db.AddRepoLanguagesTask(repo.ID)
+	}
+	// Blame results of the previous head are unlikely to be requested again once
+	// the branch has moved on.
+	if oldCommitID := c.Query("old"); oldCommitID != "" && oldCommitID != git.EmptyID {
+		gitutil.InvalidateBlame(oldCommitID)
+	}
 	c.Status(http.StatusAccepted)
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	gotemplate "html/template"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"gogs.io/gogs/internal/context"
 	"gogs.io/gogs/internal/db"
 	"gogs.io/gogs/internal/gitutil"
+	"gogs.io/gogs/internal/langutil"
 	"gogs.io/gogs/internal/markup"
 	"gogs.io/gogs/internal/template"
 	"gogs.io/gogs/internal/template/highlight"
@@ -82,7 +84,7 @@ func renderDirectory(c *context.Context, treeLink string) {
 		isTextFile := tool.IsTextFile(p)
 		c.Data["IsTextFile"] = isTextFile
 		c.Data["FileName"] = readmeFile.Name()
-		if isTextFile {
+		if isTextFile && !renderRichFile(c, readmeFile.Name(), readmeFile.Size(), p, treeLink) {
 			switch markup.Detect(readmeFile.Name()) {
 			case markup.TypeMarkdown:
 				c.Data["IsMarkdown"] = true
@@ -90,9 +92,6 @@ func renderDirectory(c *context.Context, treeLink string) {
 			case markup.TypeOrgMode:
 				c.Data["IsMarkdown"] = true
 				p = markup.OrgMode(p, treeLink, c.Repo.Repository.ComposeMetas())
-			case markup.TypeIPythonNotebook:
-				c.Data["IsIPythonNotebook"] = true
-				c.Data["RawFileLink"] = c.Repo.RepoLink + "/raw/" + path.Join(c.Repo.BranchName, c.Repo.TreePath, readmeFile.Name())
 			default:
 				p = bytes.ReplaceAll(p, []byte("\n"), []byte(`<br>`))
 			}
@@ -119,6 +118,26 @@ func renderDirectory(c *context.Context, treeLink string) {
 	}
 }
 
+// renderRichFile renders the file with the file renderer registered for its
+// extension. It returns false when there is no such renderer, or the file is
+// too large or fails to render, in which case the file should be displayed as
+// raw text.
+func renderRichFile(c *context.Context, name string, size int64, p []byte, urlPrefix string) bool {
+	renderer := markup.FileRendererFor(name)
+	if renderer == nil || size > conf.UI.MaxRenderFileSize {
+		return false
+	}
+
+	content, err := renderer.Render(p, urlPrefix)
+	if err != nil {
+		log.Warn("Failed to render file %q: %v", name, err)
+		return false
+	}
+	c.Data["IsRenderedFile"] = true
+	c.Data["FileContent"] = gotemplate.HTML(content)
+	return true
+}
+
 func renderFile(c *context.Context, entry *git.TreeEntry, treeLink, rawLink string) {
 	c.Data["IsViewFile"] = true
 
@@ -152,15 +171,15 @@ func renderFile(c *context.Context, entry *git.TreeEntry, treeLink, rawLink stri
 
 		c.Data["ReadmeExist"] = markup.IsReadmeFile(blob.Name())
 
-		switch markup.Detect(blob.Name()) {
-		case markup.TypeMarkdown:
+		switch {
+		case renderRichFile(c, blob.Name(), blob.Size(), p, path.Dir(treeLink)):
+			// Rendered by the file renderer
+		case markup.Detect(blob.Name()) == markup.TypeMarkdown:
 			c.Data["IsMarkdown"] = true
 			c.Data["FileContent"] = string(markup.Markdown(p, path.Dir(treeLink), c.Repo.Repository.ComposeMetas()))
-		case markup.TypeOrgMode:
+		case markup.Detect(blob.Name()) == markup.TypeOrgMode:
 			c.Data["IsMarkdown"] = true
 			c.Data["FileContent"] = string(markup.OrgMode(p, path.Dir(treeLink), c.Repo.Repository.ComposeMetas()))
-		case markup.TypeIPythonNotebook:
-			c.Data["IsIPythonNotebook"] = true
 		default:
 			// Building code view blocks with line number on server side.
 			var fileContent string
@@ -227,6 +246,55 @@ func setEditorconfigIfExists(c *context.Context) {
 	c.Data["Editorconfig"] = ec
 }
 
+// languageStat is the share of a language in the repository for displaying.
+type languageStat struct {
+	Name    string
+	Color   string
+	Percent string
+}
+
+// setLanguageStats sets the language statistics of the repository for the
+// language bar. Statistics of repositories that have not been scanned are
+// queued to be computed.
+func setLanguageStats(c *context.Context) {
+	if !conf.Repository.Languages.Enabled {
+		return
+	}
+
+	commitSHA, err := db.RepoLanguages.GetCommitSHA(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "get languages commit SHA")
+		return
+	} else if commitSHA == "" {
+		db.AddRepoLanguagesTask(c.Repo.Repository.ID)
+		return
+	}
+
+	languages, err := db.RepoLanguages.List(c.Req.Context(), c.Repo.Repository.ID)
+	if err != nil {
+		c.Error(err, "list languages")
+		return
+	}
+
+	var total int64
+	for _, l := range languages {
+		total += l.Bytes
+	}
+	if total == 0 {
+		return
+	}
+
+	stats := make([]*languageStat, 0, len(languages))
+	for _, l := range languages {
+		stats = append(stats, &languageStat{
+			Name:    l.Language,
+			Color:   langutil.Color(l.Language),
+			Percent: strconv.FormatFloat(float64(l.Bytes)*100/float64(total), 'f', 1, 64),
+		})
+	}
+	c.Data["LanguageStats"] = stats
+}
+
 func Home(c *context.Context) {
 	c.Data["PageIsViewFiles"] = true
 
@@ -263,6 +331,17 @@ func Home(c *context.Context) {
 			return
 		}
 		c.Data["CommitsCount"] = c.Repo.CommitsCount
+
+		c.Repo.Repository.Topics, err = db.Topics.ListByRepo(c.Req.Context(), c.Repo.Repository.ID)
+		if err != nil {
+			c.Error(err, "list topics")
+			return
+		}
+
+		setLanguageStats(c)
+		if c.Written() {
+			return
+		}
 	}
 	c.Data["PageIsRepoHome"] = isRootDir
 
@@ -131,6 +131,13 @@ func validateWebhook(l macaron.Locale, w *db.Webhook) (field, msg string, ok boo
 	if netutil.IsBlockedLocalHostname(payloadURL.Hostname(), conf.Security.LocalNetworkAllowlist) {
 		return "PayloadURL", l.Tr("repo.settings.webhook.url_resolved_to_blocked_local_address"), false
 	}
+
+	if err = db.ValidateWebhookFilter(w.Filter); err != nil {
+		if db.IsErrWebhookFilterInvalid(err) {
+			return "Filter", l.Tr("repo.settings.webhook.filter_invalid", err.(db.ErrWebhookFilterInvalid).Reason()), false
+		}
+		return "Filter", err.Error(), false
+	}
 	return "", "", true
 }
 
@@ -167,14 +174,20 @@ func toHookEvent(f form.Webhook) *db.HookEvent {
 		SendEverything: f.SendEverything(),
 		ChooseEvents:   f.ChooseEvents(),
 		HookEvents: db.HookEvents{
-			Create:       f.Create,
-			Delete:       f.Delete,
-			Fork:         f.Fork,
-			Push:         f.Push,
-			Issues:       f.Issues,
-			IssueComment: f.IssueComment,
-			PullRequest:  f.PullRequest,
-			Release:      f.Release,
+			Create:           f.Create,
+			Delete:           f.Delete,
+			Fork:             f.Fork,
+			Push:             f.Push,
+			Issues:           f.Issues,
+			IssueComment:     f.IssueComment,
+			PullRequest:      f.PullRequest,
+			Release:          f.Release,
+			Label:            f.Label,
+			RepositoryEdited: f.RepositoryEdited,
+			Deployment:       f.Deployment,
+			DeploymentStatus: f.DeploymentStatus,
+			Membership:       f.Membership,
+			Team:             f.Team,
 		},
 	}
 }
@@ -197,6 +210,7 @@ func WebhooksNewPost(c *context.Context, orCtx *orgRepoContext, f form.NewWebhoo
 		ContentType:  contentType,
 		Secret:       f.Secret,
 		HookEvent:    toHookEvent(f.Webhook),
+		Filter:       f.Filter,
 		IsActive:     f.Active,
 		HookTaskType: db.GOGS,
 	}
@@ -228,6 +242,7 @@ func WebhooksSlackNewPost(c *context.Context, orCtx *orgRepoContext, f form.NewS
 		URL:          f.PayloadURL,
 		ContentType:  db.JSON,
 		HookEvent:    toHookEvent(f.Webhook),
+		Filter:       f.Filter,
 		IsActive:     f.Active,
 		HookTaskType: db.SLACK,
 		Meta:         string(p),
@@ -260,6 +275,7 @@ func WebhooksDiscordNewPost(c *context.Context, orCtx *orgRepoContext, f form.Ne
 		URL:          f.PayloadURL,
 		ContentType:  db.JSON,
 		HookEvent:    toHookEvent(f.Webhook),
+		Filter:       f.Filter,
 		IsActive:     f.Active,
 		HookTaskType: db.DISCORD,
 		Meta:         string(p),
@@ -279,6 +295,7 @@ func WebhooksDingtalkNewPost(c *context.Context, orCtx *orgRepoContext, f form.N
 		URL:          f.PayloadURL,
 		ContentType:  db.JSON,
 		HookEvent:    toHookEvent(f.Webhook),
+		Filter:       f.Filter,
 		IsActive:     f.Active,
 		HookTaskType: db.DINGTALK,
 		OrgID:        orCtx.OrgID,
@@ -286,6 +303,37 @@ func WebhooksDingtalkNewPost(c *context.Context, orCtx *orgRepoContext, f form.N
 	validateAndCreateWebhook(c, orCtx, w)
 }
 
+func WebhooksMSTeamsNewPost(c *context.Context, orCtx *orgRepoContext, f form.NewMSTeamsHook) {
+	c.Title("repo.settings.add_webhook")
+	c.PageIs("SettingsHooks")
+	c.PageIs("SettingsHooksNew")
+	c.Data["HookType"] = "msteams"
+
+	meta := &db.MSTeamsMeta{
+		ThemeColor: f.ThemeColor,
+	}
+	c.Data["MSTeamsMeta"] = meta
+
+	p, err := jsoniter.Marshal(meta)
+	if err != nil {
+		c.Error(err, "marshal JSON")
+		return
+	}
+
+	w := &db.Webhook{
+		RepoID:       orCtx.RepoID,
+		URL:          f.PayloadURL,
+		ContentType:  db.JSON,
+		HookEvent:    toHookEvent(f.Webhook),
+		Filter:       f.Filter,
+		IsActive:     f.Active,
+		HookTaskType: db.MSTEAMS,
+		Meta:         string(p),
+		OrgID:        orCtx.OrgID,
+	}
+	validateAndCreateWebhook(c, orCtx, w)
+}
+
 func loadWebhook(c *context.Context, orCtx *orgRepoContext) *db.Webhook {
 	c.RequireHighlightJS()
 
@@ -311,6 +359,9 @@ func loadWebhook(c *context.Context, orCtx *orgRepoContext) *db.Webhook {
 		c.Data["HookType"] = "discord"
 	case db.DINGTALK:
 		c.Data["HookType"] = "dingtalk"
+	case db.MSTEAMS:
+		c.Data["MSTeamsMeta"] = w.MSTeamsMeta()
+		c.Data["HookType"] = "msteams"
 	default:
 		c.Data["HookType"] = "gogs"
 	}
@@ -384,6 +435,7 @@ func WebhooksEditPost(c *context.Context, orCtx *orgRepoContext, f form.NewWebho
 	w.ContentType = contentType
 	w.Secret = f.Secret
 	w.HookEvent = toHookEvent(f.Webhook)
+	w.Filter = f.Filter
 	w.IsActive = f.Active
 	validateAndUpdateWebhook(c, orCtx, w)
 }
@@ -412,6 +464,7 @@ func WebhooksSlackEditPost(c *context.Context, orCtx *orgRepoContext, f form.New
 	w.URL = f.PayloadURL
 	w.Meta = string(meta)
 	w.HookEvent = toHookEvent(f.Webhook)
+	w.Filter = f.Filter
 	w.IsActive = f.Active
 	validateAndUpdateWebhook(c, orCtx, w)
 }
@@ -439,6 +492,7 @@ func WebhooksDiscordEditPost(c *context.Context, orCtx *orgRepoContext, f form.N
 	w.URL = f.PayloadURL
 	w.Meta = string(meta)
 	w.HookEvent = toHookEvent(f.Webhook)
+	w.Filter = f.Filter
 	w.IsActive = f.Active
 	validateAndUpdateWebhook(c, orCtx, w)
 }
@@ -455,6 +509,33 @@ func WebhooksDingtalkEditPost(c *context.Context, orCtx *orgRepoContext, f form.
 
 	w.URL = f.PayloadURL
 	w.HookEvent = toHookEvent(f.Webhook)
+	w.Filter = f.Filter
+	w.IsActive = f.Active
+	validateAndUpdateWebhook(c, orCtx, w)
+}
+
+func WebhooksMSTeamsEditPost(c *context.Context, orCtx *orgRepoContext, f form.NewMSTeamsHook) {
+	c.Title("repo.settings.update_webhook")
+	c.PageIs("SettingsHooks")
+	c.PageIs("SettingsHooksEdit")
+
+	w := loadWebhook(c, orCtx)
+	if c.Written() {
+		return
+	}
+
+	meta, err := jsoniter.Marshal(&db.MSTeamsMeta{
+		ThemeColor: f.ThemeColor,
+	})
+	if err != nil {
+		c.Error(err, "marshal JSON")
+		return
+	}
+
+	w.URL = f.PayloadURL
+	w.Meta = string(meta)
+	w.HookEvent = toHookEvent(f.Webhook)
+	w.Filter = f.Filter
 	w.IsActive = f.Active
 	validateAndUpdateWebhook(c, orCtx, w)
 }
@@ -76,7 +76,7 @@ func renderWikiPage(c *context.Context, isViewPage bool) (*git.Repository, strin
 
 	pageURL := c.Params(":page")
 	if pageURL == "" {
-		pageURL = "Home"
+		pageURL = db.WikiHomePage
 	}
 	c.Data["PageURL"] = pageURL
 
@@ -106,6 +106,22 @@ func renderWikiPage(c *context.Context, isViewPage bool) (*git.Repository, strin
 		c.Data["content"] = string(p)
 	}
 
+	if isViewPage && pageName != db.WikiSidebarPage {
+		blob, err = commit.Blob(db.WikiSidebarPage + ".md")
+		if err == nil {
+			p, err = blob.Bytes()
+			if err != nil {
+				c.Error(err, "read sidebar blob")
+				return nil, ""
+			}
+			c.Data["sidebar"] = string(markup.Markdown(p, c.Repo.RepoLink, c.Repo.Repository.ComposeMetas()))
+			c.Data["SidebarURL"] = db.ToWikiPageURL(db.WikiSidebarPage)
+		} else if !gitutil.IsErrRevisionNotExist(err) {
+			c.Error(err, "get sidebar blob")
+			return nil, ""
+		}
+	}
+
 	return wikiRepo, pageName
 }
 
@@ -186,7 +202,7 @@ func NewWiki(c *context.Context) {
 	c.Data["RequireSimpleMDE"] = true
 
 	if !c.Repo.Repository.HasWiki() {
-		c.Data["title"] = "Home"
+		c.Data["title"] = db.WikiHomePage
 	}
 
 	c.Success(WIKI_NEW)
@@ -244,7 +260,13 @@ func EditWikiPost(c *context.Context, f form.NewWiki) {
 	}
 
 	if err := c.Repo.Repository.EditWikiPage(c.User, f.OldTitle, f.Title, f.Content, f.Message); err != nil {
-		c.Error(err, "edit wiki page")
+		if db.IsErrWikiAlreadyExist(err) {
+			c.Data["PageIsWikiEdit"] = true
+			c.Data["Err_Title"] = true
+			c.RenderWithErr(c.Tr("repo.wiki.page_already_exists"), WIKI_NEW, &f)
+		} else {
+			c.Error(err, "edit wiki page")
+		}
 		return
 	}
 
@@ -254,7 +276,7 @@ func EditWikiPost(c *context.Context, f form.NewWiki) {
 func DeleteWikiPagePost(c *context.Context) {
 	pageURL := c.Params(":page")
 	if pageURL == "" {
-		pageURL = "Home"
+		pageURL = db.WikiHomePage
 	}
 
 	pageName := db.ToWikiPageName(pageURL)
@@ -12,7 +12,6 @@ import (
 	"net/url"
 
 	"github.com/go-macaron/captcha"
-	"github.com/unknwon/com"
 	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/auth"
@@ -111,7 +110,7 @@ func Login(c *context.Context) {
 		c.Error(err, "list activated login sources")
 		return
 	}
-	c.Data["LoginSources"] = loginSources
+	loginSources = setLoginSources(c, loginSources)
 	for i := range loginSources {
 		if loginSources[i].IsDefault {
 			c.Data["DefaultLoginSource"] = loginSources[i]
@@ -122,6 +121,23 @@ func Login(c *context.Context) {
 	c.Success(LOGIN)
 }
 
+// setLoginSources sets login sources for the login page and returns the ones
+// to sign in with password. Users of OpenID Connect login sources sign in via
+// redirection to the provider instead.
+func setLoginSources(c *context.Context, loginSources []*db.LoginSource) []*db.LoginSource {
+	var passwordSources, oidcSources []*db.LoginSource
+	for _, source := range loginSources {
+		if source.IsOIDC() {
+			oidcSources = append(oidcSources, source)
+		} else {
+			passwordSources = append(passwordSources, source)
+		}
+	}
+	c.Data["LoginSources"] = passwordSources
+	c.Data["OIDCLoginSources"] = oidcSources
+	return passwordSources
+}
+
 func afterLogin(c *context.Context, u *db.User, remember bool) {
 	if remember {
 		days := 86400 * conf.Security.LoginRememberDays
@@ -158,16 +174,18 @@ func LoginPost(c *context.Context, f form.SignIn) {
 		c.Error(err, "list activated login sources")
 		return
 	}
-	c.Data["LoginSources"] = loginSources
+	loginSources = setLoginSources(c, loginSources)
 
 	if c.HasError() {
 		c.Success(LOGIN)
 		return
 	}
 
-	u, err := db.Users.Authenticate(c.Req.Context(), f.UserName, f.Password, f.LoginSource)
+	u, err := db.AuthenticateWithLockout(c.Req.Context(), f.UserName, f.Password, f.LoginSource, context.LoginIP(c.Context))
 	if err != nil {
 		switch {
+		case db.IsErrLoginLocked(err):
+			c.RenderWithErr(c.Tr("form.login_attempts_exceeded"), LOGIN, &f)
 		case auth.IsErrBadCredentials(err):
 			c.FormErr("UserName", "Password")
 			c.RenderWithErr(c.Tr("form.username_password_incorrect"), LOGIN, &f)
@@ -285,12 +303,21 @@ func LoginTwoFactorRecoveryCodePost(c *context.Context) {
 	afterLogin(c, u, c.Session.Get("twoFactorRemember").(bool))
 }
 
-func SignOut(c *context.Context) {
+// clearSignIn destroys the current session and clears cookies that would sign
+// the user in again.
+func clearSignIn(c *context.Context) {
 	_ = c.Session.Flush()
 	_ = c.Session.Destory(c.Context)
 	c.SetCookie(conf.Security.CookieUsername, "", -1, conf.Server.Subpath)
 	c.SetCookie(conf.Security.CookieRememberName, "", -1, conf.Server.Subpath)
 	c.SetCookie(conf.Session.CSRFCookieName, "", -1, conf.Server.Subpath)
+}
+
+func SignOut(c *context.Context) {
+	if s, err := db.UserSessions.GetBySID(c.Req.Context(), c.Session.ID()); err == nil {
+		_ = db.UserSessions.DeleteByID(c.Req.Context(), s.UserID, s.ID)
+	}
+	clearSignIn(c)
 	c.RedirectSubpath("/")
 }
 
@@ -421,14 +448,8 @@ func parseUserFromCode(code string) (user *db.User) {
 
 // verify active code when active account
 func verifyUserActiveCode(code string) (user *db.User) {
-	minutes := conf.Auth.ActivateCodeLives
-
 	if user = parseUserFromCode(code); user != nil {
-		// time limit code
-		prefix := code[:tool.TIME_LIMIT_CODE_LENGTH]
-		data := com.ToStr(user.ID) + user.Email + user.LowerName + user.Password + user.Rands
-
-		if tool.VerifyTimeLimitCode(data, minutes, prefix) {
+		if userutil.VerifyActivateCode(code, user.ID, user.Email, user.Name, user.Password, user.Rands) {
 			return user
 		}
 	}
@@ -437,14 +458,8 @@ func verifyUserActiveCode(code string) (user *db.User) {
 
 // verify active code when active account
 func verifyActiveEmailCode(code, email string) *db.EmailAddress {
-	minutes := conf.Auth.ActivateCodeLives
-
 	if user := parseUserFromCode(code); user != nil {
-		// time limit code
-		prefix := code[:tool.TIME_LIMIT_CODE_LENGTH]
-		data := com.ToStr(user.ID) + email + user.LowerName + user.Password + user.Rands
-
-		if tool.VerifyTimeLimitCode(data, minutes, prefix) {
+		if userutil.VerifyActivateCode(code, user.ID, email, user.Name, user.Password, user.Rands) {
 			emailAddress, err := db.Users.GetEmail(gocontext.TODO(), user.ID, email, false)
 			if err == nil {
 				return emailAddress
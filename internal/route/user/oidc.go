@@ -0,0 +1,137 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/auth/oidc"
+	"gogs.io/gogs/internal/conf"
+	"gogs.io/gogs/internal/context"
+	"gogs.io/gogs/internal/db"
+	"gogs.io/gogs/internal/strutil"
+)
+
+// oidcRedirectURL returns the URL that the OpenID Connect provider redirects
+// the user back to, which must be registered with the provider.
+func oidcRedirectURL(loginSourceID int64) string {
+	return fmt.Sprintf("%suser/login/oidc/%d/callback", conf.Server.ExternalURL, loginSourceID)
+}
+
+// oidcLoginSource returns the activated OpenID Connect login source with the ID
+// in the URL and its provider.
+func oidcLoginSource(c *context.Context) (*db.LoginSource, *oidc.Provider, bool) {
+	source, err := db.LoginSources.GetByID(c.Req.Context(), c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrError(err, "get login source")
+		return nil, nil, false
+	}
+
+	provider, ok := source.Provider.(*oidc.Provider)
+	if !ok || !source.IsActived {
+		c.NotFound()
+		return nil, nil, false
+	}
+	return source, provider, true
+}
+
+// OIDCLogin redirects the user to the OpenID Connect provider for
+// authorization.
+func OIDCLogin(c *context.Context) {
+	source, provider, ok := oidcLoginSource(c)
+	if !ok {
+		return
+	}
+
+	state, err := strutil.RandomChars(32)
+	if err != nil {
+		c.Error(err, "generate state")
+		return
+	}
+	nonce, err := strutil.RandomChars(32)
+	if err != nil {
+		c.Error(err, "generate nonce")
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Req.Context(), oidcRedirectURL(source.ID), state, nonce)
+	if err != nil {
+		log.Error("Failed to get authorization URL of login source %d: %v", source.ID, err)
+		c.Flash.Error(c.Tr("auth.oidc_failed", source.Name))
+		c.RedirectSubpath("/user/login")
+		return
+	}
+
+	_ = c.Session.Set("oidcLoginSourceID", source.ID)
+	_ = c.Session.Set("oidcState", state)
+	_ = c.Session.Set("oidcNonce", nonce)
+	c.Redirect(authURL)
+}
+
+// OIDCCallback completes the authorization of the OpenID Connect provider and
+// signs in the user, who is created or linked on the first sign in.
+func OIDCCallback(c *context.Context) {
+	source, provider, ok := oidcLoginSource(c)
+	if !ok {
+		return
+	}
+
+	// The state and nonce are for one-time use regardless of the result.
+	sourceID, _ := c.Session.Get("oidcLoginSourceID").(int64)
+	state, _ := c.Session.Get("oidcState").(string)
+	nonce, _ := c.Session.Get("oidcNonce").(string)
+	_ = c.Session.Delete("oidcLoginSourceID")
+	_ = c.Session.Delete("oidcState")
+	_ = c.Session.Delete("oidcNonce")
+
+	fail := func() {
+		c.Flash.Error(c.Tr("auth.oidc_failed", source.Name))
+		c.RedirectSubpath("/user/login")
+	}
+
+	if errCode := c.Query("error"); errCode != "" {
+		log.Trace("OpenID Connect login source %d returned error %q: %s", source.ID, errCode, c.Query("error_description"))
+		fail()
+		return
+	} else if sourceID != source.ID || state == "" || subtle.ConstantTimeCompare([]byte(c.Query("state")), []byte(state)) != 1 {
+		log.Trace("OpenID Connect callback of login source %d has invalid state", source.ID)
+		fail()
+		return
+	}
+
+	extAccount, err := provider.Exchange(c.Req.Context(), oidcRedirectURL(source.ID), c.Query("code"), nonce)
+	if err != nil {
+		log.Error("Failed to authenticate via login source %d: %v", source.ID, err)
+		fail()
+		return
+	}
+
+	u, err := db.Users.AuthenticateExternal(c.Req.Context(), source.ID, extAccount)
+	if err != nil {
+		switch {
+		case db.IsErrEmailAlreadyUsed(err):
+			c.Flash.Error(c.Tr("form.email_been_used"))
+			c.RedirectSubpath("/user/login")
+		case db.IsErrUserAlreadyExist(err):
+			c.Flash.Error(c.Tr("form.username_been_taken"))
+			c.RedirectSubpath("/user/login")
+		default:
+			c.Error(err, "authenticate external account")
+		}
+		return
+	}
+
+	if !db.TwoFactors.IsEnabled(c.Req.Context(), u.ID) {
+		afterLogin(c, u, false)
+		return
+	}
+
+	_ = c.Session.Set("twoFactorRemember", false)
+	_ = c.Session.Set("twoFactorUserID", u.ID)
+	c.RedirectSubpath("/user/login/two_factor")
+}
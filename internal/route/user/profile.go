@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/unknwon/paginater"
+	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/context"
@@ -78,6 +79,15 @@ func Profile(c *context.Context, puser *context.ParamsUser) {
 
 		count := db.CountUserRepositories(puser.ID, showPrivate)
 		c.Data["Page"] = paginater.New(int(count), conf.UI.User.RepoPagingNum, page, 5)
+
+		if conf.UI.User.EnableProfileReadme && page == 1 {
+			// The profile page should still be available when the README cannot be
+			// rendered for whatever reason.
+			c.Data["ProfileReadme"], err = db.GetProfileReadme(c.Req.Context(), puser.User, c.UserID())
+			if err != nil {
+				log.Error("Failed to get profile README of user %q: %v", puser.Name, err)
+			}
+		}
 	}
 
 	c.Success(PROFILE)
@@ -40,6 +40,7 @@ const (
 	SETTINGS_REPOSITORIES              = "user/settings/repositories"
 	SETTINGS_ORGANIZATIONS             = "user/settings/organizations"
 	SETTINGS_APPLICATIONS              = "user/settings/applications"
+	SETTINGS_SESSIONS                  = "user/settings/sessions"
 	SETTINGS_DELETE                    = "user/settings/delete"
 	NOTIFICATION                       = "user/notification"
 )
@@ -233,6 +234,19 @@ func SettingsEmails(c *context.Context) {
 	c.Success(SETTINGS_EMAILS)
 }
 
+func SettingsEmailPrivacyPost(c *context.Context, f form.EmailPrivacy) {
+	err := db.Users.Update(c.Req.Context(), c.User.ID, db.UpdateUserOptions{
+		KeepEmailPrivate: &f.KeepEmailPrivate,
+	})
+	if err != nil {
+		c.Errorf(err, "update user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.email_privacy_success"))
+	c.RedirectSubpath("/user/settings/email")
+}
+
 func SettingsEmailPost(c *context.Context, f form.AddEmail) {
 	c.Title("settings.emails")
 	c.PageIs("SettingsEmails")
@@ -530,9 +544,109 @@ func SettingsRepos(c *context.Context) {
 	}
 	c.Data["Repos"] = repos
 
+	invitations, err := db.RepoInvitations.ListByInviteeID(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list invitations")
+		return
+	}
+	invitedRepos := make([]*db.Repository, 0, len(invitations))
+	for _, inv := range invitations {
+		repo, err := db.GetRepositoryByID(inv.RepoID)
+		if err != nil {
+			c.Errorf(err, "get repository by ID")
+			return
+		} else if err = repo.GetOwner(); err != nil {
+			c.Errorf(err, "get owner")
+			return
+		}
+		invitedRepos = append(invitedRepos, repo)
+	}
+	c.Data["Invitations"] = invitations
+	c.Data["InvitedRepos"] = invitedRepos
+
+	c.Data["AutoWatchPreferences"] = []struct {
+		Name       string
+		Preference db.AutoWatchPreference
+		Default    bool
+	}{
+		{"auto_watch_on_create", c.User.AutoWatchOnCreate, conf.User.AutoWatchOnCreate},
+		{"auto_watch_on_push", c.User.AutoWatchOnPush, conf.User.AutoWatchOnPush},
+		{"auto_watch_on_comment", c.User.AutoWatchOnComment, conf.User.AutoWatchOnComment},
+	}
+
 	c.Success(SETTINGS_REPOSITORIES)
 }
 
+func SettingsAutoWatchPost(c *context.Context) {
+	parse := func(name string) *db.AutoWatchPreference {
+		p := db.AutoWatchPreference(c.QueryInt(name))
+		switch p {
+		case db.AutoWatchEnabled, db.AutoWatchDisabled:
+		default:
+			p = db.AutoWatchDefault
+		}
+		return &p
+	}
+
+	err := db.Users.Update(c.Req.Context(), c.User.ID, db.UpdateUserOptions{
+		AutoWatchOnCreate:  parse("auto_watch_on_create"),
+		AutoWatchOnPush:    parse("auto_watch_on_push"),
+		AutoWatchOnComment: parse("auto_watch_on_comment"),
+	})
+	if err != nil {
+		c.Errorf(err, "update user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.repos.auto_watch_success"))
+	c.RedirectSubpath("/user/settings/repositories")
+}
+
+func SettingsAutoAssignPost(c *context.Context, f form.AutoAssign) {
+	err := db.Users.Update(c.Req.Context(), c.User.ID, db.UpdateUserOptions{
+		AutoAssignOptOut: &f.AutoAssignOptOut,
+	})
+	if err != nil {
+		c.Errorf(err, "update user")
+		return
+	}
+
+	c.Flash.Success(c.Tr("settings.repos.auto_assign_success"))
+	c.RedirectSubpath("/user/settings/repositories")
+}
+
+func SettingsAcceptRepoInvitation(c *context.Context) {
+	err := db.RepoInvitations.Accept(c.Req.Context(), c.User.ID, c.QueryInt64("id"))
+	if err != nil {
+		if db.IsErrRepoInvitationNotExist(err) {
+			c.Flash.Error(c.Tr("settings.repos.invitation_not_exist"))
+		} else if db.IsErrReachLimitOfCollaborators(errors.Cause(err)) {
+			c.Flash.Error(c.Tr("repo.settings.reach_limit_of_collaborators", errors.Cause(err).(db.ErrReachLimitOfCollaborators).Limit))
+		} else {
+			c.Errorf(err, "accept invitation")
+			return
+		}
+	} else {
+		c.Flash.Success(c.Tr("settings.repos.accept_invitation_success"))
+	}
+	c.RedirectSubpath("/user/settings/repositories")
+}
+
+func SettingsDeclineRepoInvitation(c *context.Context) {
+	err := db.RepoInvitations.Decline(c.Req.Context(), c.User.ID, c.QueryInt64("id"))
+	if err != nil {
+		if db.IsErrRepoInvitationNotExist(err) {
+			c.Flash.Error(c.Tr("settings.repos.invitation_not_exist"))
+		} else {
+			c.Errorf(err, "decline invitation")
+			return
+		}
+	} else {
+		c.Flash.Success(c.Tr("settings.repos.decline_invitation_success"))
+	}
+	c.RedirectSubpath("/user/settings/repositories")
+}
+
 func SettingsLeaveRepo(c *context.Context) {
 	repo, err := db.GetRepositoryByID(c.QueryInt64("id"))
 	if err != nil {
@@ -562,11 +676,58 @@ func SettingsOrganizations(c *context.Context) {
 	}
 	c.Data["Orgs"] = orgs
 
+	invitations, err := db.OrgInvitations.ListByInviteeID(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list invitations")
+		return
+	}
+	invitedOrgs := make([]*db.User, 0, len(invitations))
+	for _, inv := range invitations {
+		org, err := db.Users.GetByID(c.Req.Context(), inv.OrgID)
+		if err != nil {
+			c.Errorf(err, "get organization by ID")
+			return
+		}
+		invitedOrgs = append(invitedOrgs, org)
+	}
+	c.Data["Invitations"] = invitations
+	c.Data["InvitedOrgs"] = invitedOrgs
+
 	c.Success(SETTINGS_ORGANIZATIONS)
 }
 
+func SettingsAcceptOrgInvitation(c *context.Context) {
+	err := db.OrgInvitations.Accept(c.Req.Context(), c.User.ID, c.QueryInt64("id"))
+	if err != nil {
+		if db.IsErrOrgInvitationNotExist(err) {
+			c.Flash.Error(c.Tr("settings.repos.invitation_not_exist"))
+		} else {
+			c.Errorf(err, "accept invitation")
+			return
+		}
+	} else {
+		c.Flash.Success(c.Tr("settings.orgs.accept_invitation_success"))
+	}
+	c.RedirectSubpath("/user/settings/organizations")
+}
+
+func SettingsDeclineOrgInvitation(c *context.Context) {
+	err := db.OrgInvitations.Decline(c.Req.Context(), c.User.ID, c.QueryInt64("id"))
+	if err != nil {
+		if db.IsErrOrgInvitationNotExist(err) {
+			c.Flash.Error(c.Tr("settings.repos.invitation_not_exist"))
+		} else {
+			c.Errorf(err, "decline invitation")
+			return
+		}
+	} else {
+		c.Flash.Success(c.Tr("settings.repos.decline_invitation_success"))
+	}
+	c.RedirectSubpath("/user/settings/organizations")
+}
+
 func SettingsLeaveOrganization(c *context.Context) {
-	if err := db.RemoveOrgUser(c.QueryInt64("id"), c.User.ID); err != nil {
+	if err := db.RemoveOrgUser(c.User, c.QueryInt64("id"), c.User.ID); err != nil {
 		if db.IsErrLastOrgOwner(err) {
 			c.Flash.Error(c.Tr("form.last_org_owner"))
 		} else {
@@ -638,14 +799,83 @@ func SettingsDeleteApplication(c *context.Context) {
 	})
 }
 
+func SettingsSessions(c *context.Context) {
+	c.Title("settings.sessions")
+	c.PageIs("SettingsSessions")
+
+	sessions, err := db.UserSessions.List(c.Req.Context(), c.User.ID)
+	if err != nil {
+		c.Errorf(err, "list sessions")
+		return
+	}
+	c.Data["Sessions"] = sessions
+	c.Data["CurrentSessionID"] = int64(0)
+
+	current, err := db.UserSessions.GetBySID(c.Req.Context(), c.Session.ID())
+	if err != nil && !db.IsErrUserSessionNotExist(err) {
+		c.Errorf(err, "get current session")
+		return
+	} else if err == nil {
+		c.Data["CurrentSessionID"] = current.ID
+	}
+
+	c.Success(SETTINGS_SESSIONS)
+}
+
+func SettingsDeleteSession(c *context.Context) {
+	if err := db.UserSessions.Revoke(c.Req.Context(), c.User.ID, c.QueryInt64("id")); err != nil {
+		c.Flash.Error("RevokeUserSession: " + err.Error())
+	} else {
+		c.Flash.Success(c.Tr("settings.revoke_session_success"))
+	}
+
+	c.JSONSuccess(map[string]any{
+		"redirect": conf.Server.Subpath + "/user/settings/sessions",
+	})
+}
+
+func SettingsDeleteSessions(c *context.Context) {
+	if err := db.UserSessions.RevokeAll(c.Req.Context(), c.User.ID); err != nil {
+		c.Errorf(err, "revoke sessions")
+		return
+	}
+
+	log.Trace("User signed out everywhere: %s", c.User.Name)
+	SignOut(c)
+}
+
+func SettingsExport(c *context.Context) {
+	cacheKey := userutil.ExportCacheKey(c.User.ID)
+	if c.Cache.IsExist(cacheKey) {
+		c.Flash.Error(c.Tr("settings.export_data_too_frequent"))
+		c.RedirectSubpath("/user/settings/delete")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := db.Users.Export(c.Req.Context(), c.User.ID, &buf); err != nil {
+		c.Error(err, "export user data")
+		return
+	}
+
+	if err := c.Cache.Put(cacheKey, 1, int64(conf.User.ExportInterval.Seconds())); err != nil {
+		log.Error("Failed to put cache 'export': %v", err)
+	}
+	log.Trace("Account data exported: %s", c.User.Name)
+	c.ServeContent(c.User.Name+"-export.zip", bytes.NewReader(buf.Bytes()))
+}
+
 func SettingsDelete(c *context.Context) {
 	c.Title("settings.delete")
 	c.PageIs("SettingsDelete")
+	c.Data["DeletionPolicy"] = conf.User.DeletionPolicy
 
 	if c.Req.Method == "POST" {
-		if _, err := db.Users.Authenticate(c.Req.Context(), c.User.Name, c.Query("password"), c.User.LoginSource); err != nil {
+		if _, err := db.AuthenticateWithLockout(c.Req.Context(), c.User.Name, c.Query("password"), c.User.LoginSource, context.LoginIP(c.Context)); err != nil {
 			if auth.IsErrBadCredentials(err) {
 				c.RenderWithErr(c.Tr("form.enterred_invalid_password"), SETTINGS_DELETE, nil)
+			} else if db.IsErrLoginLocked(err) {
+				c.RenderWithErr(c.Tr("form.login_attempts_exceeded"), SETTINGS_DELETE, nil)
 			} else {
 				c.Errorf(err, "authenticate user")
 			}
@@ -657,14 +887,18 @@ func SettingsDelete(c *context.Context) {
 			case db.IsErrUserOwnRepos(err):
 				c.Flash.Error(c.Tr("form.still_own_repo"))
 				c.Redirect(conf.Server.Subpath + "/user/settings/delete")
-			case db.IsErrUserHasOrgs(err):
-				c.Flash.Error(c.Tr("form.still_has_org"))
+			case db.IsErrLastOrgOwner(err):
+				c.Flash.Error(c.Tr("form.still_last_org_owner"))
 				c.Redirect(conf.Server.Subpath + "/user/settings/delete")
 			default:
 				c.Errorf(err, "delete user")
 			}
 		} else {
 			log.Trace("Account deleted: %s", c.User.Name)
+
+			// Records of all sessions of the user are deleted along with the account,
+			// which revokes them on other devices.
+			clearSignIn(c)
 			c.Redirect(conf.Server.Subpath + "/")
 		}
 		return
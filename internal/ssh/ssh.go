@@ -5,7 +5,9 @@
 package ssh
 
 import (
-	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"fmt"
 	"io"
 	"net"
@@ -13,17 +15,17 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/pkg/errors"
-	"github.com/sourcegraph/run"
 	"github.com/unknwon/com"
 	"golang.org/x/crypto/ssh"
 	log "unknwon.dev/clog/v2"
 
 	"gogs.io/gogs/internal/conf"
 	"gogs.io/gogs/internal/db"
-	"gogs.io/gogs/internal/osutil"
+	"gogs.io/gogs/internal/gitutil"
 )
 
 func cleanCommand(cmd string) string {
@@ -34,7 +36,22 @@ func cleanCommand(cmd string) string {
 	return cmd[i:]
 }
 
-func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
+// gitRepoPath returns the path of the repository that the Git pack command
+// operates on, it returns false if the command is not a Git pack command.
+func gitRepoPath(cmd string) (string, bool) {
+	verb, args, ok := strings.Cut(cmd, " ")
+	if !ok || (verb != "git-upload-pack" && verb != "git-receive-pack") {
+		return "", false
+	}
+
+	dir := strings.ToLower(strings.Trim(strings.TrimSpace(args), "'/"))
+	if !strings.HasSuffix(dir, ".git") {
+		dir += ".git"
+	}
+	return filepath.Join(conf.Repository.Root, dir), true
+}
+
+func handleServerConn(keyID, ip string, chans <-chan ssh.NewChannel) {
 	for newChan := range chans {
 		if newChan.ChannelType() != "session" {
 			_ = newChan.Reject(ssh.UnknownChannelType, "unknown channel type")
@@ -80,6 +97,18 @@ func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
 					cmdName := strings.TrimLeft(payload, "'()")
 					log.Trace("SSH: Payload: %v", cmdName)
 
+					if repoPath, ok := gitRepoPath(cmdName); ok {
+						release, err := gitutil.DefaultOperationLimiter().Acquire(repoPath, ip)
+						if err != nil {
+							log.Trace("SSH: Rejected %q from %s: %v", cmdName, ip, err)
+							_ = req.Reply(true, nil)
+							_, _ = ch.Stderr().Write([]byte("Gogs: Server is busy, please try again later.\n"))
+							_, _ = ch.SendRequest("exit-status", false, []byte{0, 0, 0, 1})
+							return
+						}
+						defer release()
+					}
+
 					args := []string{"serv", "key-" + keyID, "--config=" + conf.CustomConf}
 					log.Trace("SSH: Arguments: %v", args)
 					cmd := exec.Command(conf.AppPath(), args...)
@@ -111,7 +140,7 @@ func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
 					go func() {
 						_, _ = io.Copy(input, ch)
 					}()
-					_, _ = io.Copy(ch, stdout)
+					_, _ = io.Copy(gitutil.NewThrottledWriter(ch, conf.Git.MaxOperationBandwidth), stdout)
 					_, _ = io.Copy(ch.Stderr(), stderr)
 
 					if err = cmd.Wait(); err != nil {
@@ -128,15 +157,22 @@ func handleServerConn(keyID string, chans <-chan ssh.NewChannel) {
 	}
 }
 
-func listen(config *ssh.ServerConfig, host string, port int) {
+func listen(host string, port int) {
 	listener, err := net.Listen("tcp", host+":"+com.ToStr(port))
 	if err != nil {
 		log.Fatal("Failed to start SSH server: %v", err)
 	}
+	serve(listener)
+}
+
+func serve(listener net.Listener) {
 	for {
 		// Once a ServerConfig has been configured, connections can be accepted.
 		conn, err := listener.Accept()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
 			log.Error("SSH: Error accepting incoming connection: %v", err)
 			continue
 		}
@@ -147,7 +183,7 @@ func listen(config *ssh.ServerConfig, host string, port int) {
 		// For example, user could be asked to trust server key fingerprint and hangs.
 		go func() {
 			log.Trace("SSH: Handshaking for %s", conn.RemoteAddr())
-			sConn, chans, reqs, err := ssh.NewServerConn(conn, config)
+			sConn, chans, reqs, err := ssh.NewServerConn(conn, currentServerConfig())
 			if err != nil {
 				if err == io.EOF || errors.Is(err, syscall.ECONNRESET) {
 					log.Trace("SSH: Handshaking was terminated: %v", err)
@@ -160,13 +196,54 @@ func listen(config *ssh.ServerConfig, host string, port int) {
 			log.Trace("SSH: Connection from %s (%s)", sConn.RemoteAddr(), sConn.ClientVersion())
 			// The incoming Request channel must be serviced.
 			go ssh.DiscardRequests(reqs)
-			go handleServerConn(sConn.Permissions.Extensions["key-id"], chans)
+			ip, _, _ := net.SplitHostPort(sConn.RemoteAddr().String())
+			go handleServerConn(sConn.Permissions.Extensions["key-id"], ip, chans)
 		}()
 	}
 }
 
-// Listen starts a SSH server listens on given port.
-func Listen(opts conf.SSHOpts, appDataPath string) {
+// HostKey is a host key of the builtin SSH server.
+type HostKey struct {
+	// FileName is the name of the private key file in the host key directory.
+	FileName string
+	Signer   ssh.Signer
+}
+
+// Type returns the type of the host key, e.g. "ssh-ed25519".
+func (k *HostKey) Type() string {
+	return k.Signer.PublicKey().Type()
+}
+
+// Fingerprint returns the SHA256 fingerprint of the host key.
+func (k *HostKey) Fingerprint() string {
+	return ssh.FingerprintSHA256(k.Signer.PublicKey())
+}
+
+// server is the state of the builtin SSH server, host keys can be reloaded
+// while the server is running and new connections use the reloaded keys.
+var server = struct {
+	sync.RWMutex
+	opts       conf.SSHOpts
+	hostKeyDir string
+	config     *ssh.ServerConfig
+	hostKeys   []*HostKey
+}{}
+
+func currentServerConfig() *ssh.ServerConfig {
+	server.RLock()
+	defer server.RUnlock()
+	return server.config
+}
+
+// HostKeys returns host keys that are currently offered by the builtin SSH
+// server, or nil if the server is not started.
+func HostKeys() []*HostKey {
+	server.RLock()
+	defer server.RUnlock()
+	return server.hostKeys
+}
+
+func newServerConfig(opts conf.SSHOpts, hostKeys []*HostKey) *ssh.ServerConfig {
 	config := &ssh.ServerConfig{
 		Config: ssh.Config{
 			Ciphers: opts.ServerCiphers,
@@ -181,53 +258,153 @@ func Listen(opts conf.SSHOpts, appDataPath string) {
 			return &ssh.Permissions{Extensions: map[string]string{"key-id": com.ToStr(pkey.ID)}}, nil
 		},
 	}
+	for _, key := range hostKeys {
+		config.AddHostKey(key.Signer)
+	}
+	return config
+}
 
-	keys, err := setupHostKeys(appDataPath, opts.ServerAlgorithms)
+// Listen starts a SSH server listens on given port.
+func Listen(opts conf.SSHOpts, appDataPath string) {
+	server.Lock()
+	server.opts = opts
+	server.hostKeyDir = filepath.Join(appDataPath, "ssh")
+	server.Unlock()
+
+	err := ReloadHostKeys()
 	if err != nil {
 		log.Fatal("SSH: Failed to setup host keys: %v", err)
 	}
-	for _, key := range keys {
-		config.AddHostKey(key)
+
+	go listen(opts.ListenHost, opts.ListenPort)
+}
+
+// ReloadHostKeys reloads host keys of the builtin SSH server from the host key
+// directory, so that a new key can be added and an old key can be retired
+// without restarting. The currently offered keys are kept when fails.
+func ReloadHostKeys() error {
+	server.RLock()
+	opts := server.opts
+	dir := server.hostKeyDir
+	server.RUnlock()
+	if dir == "" {
+		return errors.New("SSH server is not started")
+	}
+
+	hostKeys, err := setupHostKeys(dir, opts.ServerAlgorithms)
+	if err != nil {
+		return err
+	}
+	for _, key := range hostKeys {
+		log.Trace("SSH: Host key %q loaded: %s %s", key.FileName, key.Type(), key.Fingerprint())
 	}
 
-	go listen(config, opts.ListenHost, opts.ListenPort)
+	config := newServerConfig(opts, hostKeys)
+	server.Lock()
+	server.config = config
+	server.hostKeys = hostKeys
+	server.Unlock()
+	return nil
 }
 
-func setupHostKeys(appDataPath string, algorithms []string) ([]ssh.Signer, error) {
-	dir := filepath.Join(appDataPath, "ssh")
+// hostKeyAlgorithm returns the name of the algorithm of given key type as used
+// by the "SSH_SERVER_ALGORITHMS" setting.
+func hostKeyAlgorithm(keyType string) string {
+	switch {
+	case keyType == ssh.KeyAlgoRSA:
+		return "rsa"
+	case strings.HasPrefix(keyType, "ecdsa-"):
+		return "ecdsa"
+	case keyType == ssh.KeyAlgoED25519:
+		return "ed25519"
+	}
+	return keyType
+}
+
+// setupHostKeys loads all private keys in the directory as host keys, ordered by
+// file names, and only keeps keys of given algorithms. An Ed25519 key is
+// generated when the directory has no key at all.
+func setupHostKeys(dir string, algorithms []string) ([]*HostKey, error) {
 	err := os.MkdirAll(dir, os.ModePerm)
 	if err != nil {
 		return nil, errors.Wrapf(err, "create host key directory")
 	}
 
-	var hostKeys []ssh.Signer
-	for _, algo := range algorithms {
-		keyPath := filepath.Join(dir, "gogs."+algo)
-		if !osutil.IsExist(keyPath) {
-			args := []string{
-				conf.SSH.KeygenPath,
-				"-t", algo,
-				"-f", keyPath,
-				"-m", "PEM",
-				"-N", run.Arg(""),
-			}
-			err = run.Cmd(context.Background(), args...).Run().Wait()
-			if err != nil {
-				return nil, errors.Wrapf(err, "generate host key with args %v", args)
-			}
-			log.Trace("SSH: New private key is generated: %s", keyPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read host key directory")
+	}
+	var keyNames []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasSuffix(entry.Name(), ".pub") {
+			keyNames = append(keyNames, entry.Name())
+		}
+	}
+
+	if len(keyNames) == 0 {
+		const name = "gogs.ed25519"
+		err = generateEd25519HostKey(filepath.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrap(err, "generate host key")
 		}
+		log.Trace("SSH: New private key is generated: %s", filepath.Join(dir, name))
+		keyNames = append(keyNames, name)
+	}
+
+	allowed := make(map[string]bool, len(algorithms))
+	for _, algo := range algorithms {
+		allowed[algo] = true
+	}
 
+	var hostKeys []*HostKey
+	for _, name := range keyNames {
+		keyPath := filepath.Join(dir, name)
 		keyData, err := os.ReadFile(keyPath)
 		if err != nil {
 			return nil, errors.Wrapf(err, "read host key %q", keyPath)
 		}
 		signer, err := ssh.ParsePrivateKey(keyData)
 		if err != nil {
-			return nil, errors.Wrapf(err, "parse host key %q", keyPath)
+			log.Error("SSH: Failed to parse host key %q: %v", keyPath, err)
+			continue
 		}
 
-		hostKeys = append(hostKeys, signer)
+		key := &HostKey{
+			FileName: name,
+			Signer:   signer,
+		}
+		if !allowed[hostKeyAlgorithm(key.Type())] {
+			log.Trace("SSH: Host key %q is skipped for not being one of the algorithms %v", keyPath, algorithms)
+			continue
+		}
+		hostKeys = append(hostKeys, key)
+	}
+	if len(hostKeys) == 0 {
+		return nil, errors.Errorf("no host key is found in %q for algorithms %v", dir, algorithms)
 	}
 	return hostKeys, nil
 }
+
+// generateEd25519HostKey generates a new Ed25519 private key to the path, along
+// with the public key with ".pub" suffix.
+func generateEd25519HostKey(keyPath string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return errors.Wrap(err, "generate key")
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return errors.Wrap(err, "marshal private key")
+	}
+	err = os.WriteFile(keyPath, pem.EncodeToMemory(block), 0o600)
+	if err != nil {
+		return errors.Wrap(err, "write private key")
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return errors.Wrap(err, "new public key")
+	}
+	return os.WriteFile(keyPath+".pub", ssh.MarshalAuthorizedKey(sshPub), 0o644)
+}
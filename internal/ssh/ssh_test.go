@@ -0,0 +1,139 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ssh
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+func writeHostKey(t *testing.T, path string, key crypto.PrivateKey) {
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	err = os.WriteFile(path, pem.EncodeToMemory(block), 0o600)
+	require.NoError(t, err)
+}
+
+func TestSetupHostKeys(t *testing.T) {
+	t.Run("generate Ed25519 key when none exist", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "ssh")
+		hostKeys, err := setupHostKeys(dir, []string{"rsa", "ecdsa", "ed25519"})
+		require.NoError(t, err)
+		require.Len(t, hostKeys, 1)
+		assert.Equal(t, "gogs.ed25519", hostKeys[0].FileName)
+		assert.Equal(t, ssh.KeyAlgoED25519, hostKeys[0].Type())
+		assert.FileExists(t, filepath.Join(dir, "gogs.ed25519.pub"))
+
+		// The existing key is loaded rather than generating a new one
+		again, err := setupHostKeys(dir, []string{"ed25519"})
+		require.NoError(t, err)
+		require.Len(t, again, 1)
+		assert.Equal(t, hostKeys[0].Fingerprint(), again[0].Fingerprint())
+	})
+
+	t.Run("only keep configured algorithms", func(t *testing.T) {
+		dir := t.TempDir()
+		rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+		writeHostKey(t, filepath.Join(dir, "gogs.rsa"), rsaKey)
+		err = generateEd25519HostKey(filepath.Join(dir, "gogs.ed25519"))
+		require.NoError(t, err)
+
+		hostKeys, err := setupHostKeys(dir, []string{"ed25519"})
+		require.NoError(t, err)
+		require.Len(t, hostKeys, 1)
+		assert.Equal(t, ssh.KeyAlgoED25519, hostKeys[0].Type())
+
+		_, err = setupHostKeys(dir, []string{"ecdsa"})
+		assert.Error(t, err)
+	})
+}
+
+func TestServer_HostKeys(t *testing.T) {
+	dir := t.TempDir()
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	writeHostKey(t, filepath.Join(dir, "gogs.ecdsa"), ecdsaKey)
+	err = generateEd25519HostKey(filepath.Join(dir, "gogs.ed25519"))
+	require.NoError(t, err)
+
+	server.Lock()
+	server.opts = conf.SSHOpts{ServerAlgorithms: []string{"ecdsa", "ed25519"}}
+	server.hostKeyDir = dir
+	server.Unlock()
+	t.Cleanup(func() {
+		server.Lock()
+		server.opts = conf.SSHOpts{}
+		server.hostKeyDir = ""
+		server.config = nil
+		server.hostKeys = nil
+		server.Unlock()
+	})
+
+	err = ReloadHostKeys()
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+	go serve(listener)
+
+	// offeredKey returns the host key that the server offers for the algorithm,
+	// or nil if the handshake fails before receiving a host key.
+	offeredKey := func(t *testing.T, algorithm string) ssh.PublicKey {
+		var got ssh.PublicKey
+		client, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+			User:              "git",
+			HostKeyAlgorithms: []string{algorithm},
+			HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+				got = key
+				return nil
+			},
+		})
+		if err == nil {
+			_ = client.Close()
+		}
+		return got
+	}
+
+	hostKeys := HostKeys()
+	require.Len(t, hostKeys, 2)
+	for _, key := range hostKeys {
+		got := offeredKey(t, key.Type())
+		require.NotNil(t, got, key.Type())
+		assert.Equal(t, key.Fingerprint(), ssh.FingerprintSHA256(got))
+	}
+	assert.Equal(t, ssh.KeyAlgoECDSA256, hostKeys[0].Type())
+	ecdsaPub, err := ssh.NewPublicKey(&ecdsaKey.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, ssh.FingerprintSHA256(ecdsaPub), hostKeys[0].Fingerprint())
+
+	t.Run("rotate", func(t *testing.T) {
+		err := os.Remove(filepath.Join(dir, "gogs.ecdsa"))
+		require.NoError(t, err)
+		err = ReloadHostKeys()
+		require.NoError(t, err)
+
+		hostKeys := HostKeys()
+		require.Len(t, hostKeys, 1)
+		assert.Equal(t, ssh.KeyAlgoED25519, hostKeys[0].Type())
+		assert.Nil(t, offeredKey(t, ssh.KeyAlgoECDSA256), "retired key should no longer be offered")
+		assert.NotNil(t, offeredKey(t, ssh.KeyAlgoED25519))
+	})
+}
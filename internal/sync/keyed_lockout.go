@@ -0,0 +1,105 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyedLockout tracks consecutive failures of each key and locks the key once
+// failures reach a threshold. Every further failure after the threshold doubles
+// the lockout window. Failures of different keys do not affect each other.
+type KeyedLockout struct {
+	lock    sync.Mutex
+	entries map[string]*lockoutEntry
+	// lastSweep is the time that keys without recent failures were last removed
+	// to recycle memory.
+	lastSweep time.Time
+}
+
+type lockoutEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewKeyedLockout initializes and returns a new KeyedLockout.
+func NewKeyedLockout() *KeyedLockout {
+	return &KeyedLockout{
+		entries: make(map[string]*lockoutEntry),
+	}
+}
+
+// LockedFor returns the remaining time that the key is locked, or 0 if the key
+// is not locked.
+func (l *KeyedLockout) LockedFor(key string) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry, ok := l.entries[key]
+	if !ok {
+		return 0
+	}
+	if remaining := time.Until(entry.lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Fail records a failure for the key and returns the time that the key is
+// locked for, or 0 if the failures have not reached the threshold. The lockout
+// window starts from the given window and doubles for every further failure,
+// up to the max window. Failures are forgotten when there has been no failure
+// of the key within the max window.
+func (l *KeyedLockout) Fail(key string, threshold int, window, maxWindow time.Duration) time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > maxWindow {
+		for k, entry := range l.entries {
+			if now.Sub(entry.lastFailure) > maxWindow && !now.Before(entry.lockedUntil) {
+				delete(l.entries, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	entry, ok := l.entries[key]
+	if !ok || now.Sub(entry.lastFailure) > maxWindow {
+		entry = &lockoutEntry{}
+		l.entries[key] = entry
+	}
+	entry.failures++
+	entry.lastFailure = now
+
+	if entry.failures < threshold {
+		return 0
+	}
+
+	d := maxWindow
+	if shift := entry.failures - threshold; shift < 32 {
+		if doubled := window << shift; doubled > 0 && doubled < maxWindow {
+			d = doubled
+		}
+	}
+	entry.lockedUntil = now.Add(d)
+	return d
+}
+
+// Reset removes all recorded failures for the key, which also unlocks the key.
+func (l *KeyedLockout) Reset(key string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.entries, key)
+}
+
+// ResetAll removes all recorded failures for all keys.
+func (l *KeyedLockout) ResetAll() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = make(map[string]*lockoutEntry)
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyedRateLimiter limits the number of events for each key within a sliding
+// time window. Events of different keys do not affect each other.
+type KeyedRateLimiter struct {
+	lock sync.Mutex
+	// events maintains the times of allowed events within the last window of
+	// each key, sorted from the oldest.
+	events map[string][]time.Time
+	// lastSweep is the time that keys without recent events were last removed
+	// to recycle memory.
+	lastSweep time.Time
+}
+
+// NewKeyedRateLimiter initializes and returns a new KeyedRateLimiter.
+func NewKeyedRateLimiter() *KeyedRateLimiter {
+	return &KeyedRateLimiter{
+		events: make(map[string][]time.Time),
+	}
+}
+
+// Allow records an event for the key and returns true if there are fewer than
+// the limit of events for the key within the last window. Otherwise, it returns
+// false without recording the event.
+func (l *KeyedRateLimiter) Allow(key string, limit int, window time.Duration) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	since := now.Add(-window)
+	if now.Sub(l.lastSweep) > window {
+		for k, events := range l.events {
+			if len(events) == 0 || !events[len(events)-1].After(since) {
+				delete(l.events, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	events := l.events[key]
+	i := 0
+	for i < len(events) && !events[i].After(since) {
+		i++
+	}
+	events = events[i:]
+
+	if len(events) >= limit {
+		l.events[key] = events
+		return false
+	}
+	l.events[key] = append(events, now)
+	return true
+}
+
+// Reset removes all recorded events for the key.
+func (l *KeyedRateLimiter) Reset(key string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.events, key)
+}
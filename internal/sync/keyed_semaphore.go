@@ -0,0 +1,89 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// KeyedSemaphore limits the number of holders for each key at the same time.
+// Holders of different keys do not affect each other.
+type KeyedSemaphore struct {
+	limit int
+
+	lock sync.Mutex
+	// slots maintains the buffered channel for each key, each value in the
+	// channel represents a holder.
+	slots map[string]chan struct{}
+	// count maintains the number of holders and waiters of each key, the channel
+	// is removed from the map when it counts down to 0 to recycle memory.
+	count map[string]int
+}
+
+// NewKeyedSemaphore initializes and returns a new KeyedSemaphore that allows at
+// most given number of holders for each key.
+func NewKeyedSemaphore(limit int) *KeyedSemaphore {
+	return &KeyedSemaphore{
+		limit: limit,
+		slots: make(map[string]chan struct{}),
+		count: make(map[string]int),
+	}
+}
+
+// Acquire acquires a slot for the key, it waits for at most the timeout when
+// all slots are taken. It returns false if no slot is acquired, and the caller
+// must call Release with the same key once done when it returns true.
+func (s *KeyedSemaphore) Acquire(key string, timeout time.Duration) bool {
+	s.lock.Lock()
+	slots, ok := s.slots[key]
+	if !ok {
+		slots = make(chan struct{}, s.limit)
+		s.slots[key] = slots
+	}
+	s.count[key]++
+	s.lock.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case slots <- struct{}{}:
+			return true
+		case <-timer.C:
+		}
+	}
+
+	s.done(key)
+	return false
+}
+
+// Release releases a slot acquired for the key.
+func (s *KeyedSemaphore) Release(key string) {
+	s.lock.Lock()
+	slots := s.slots[key]
+	s.lock.Unlock()
+
+	<-slots
+	s.done(key)
+}
+
+func (s *KeyedSemaphore) done(key string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.count[key] == 1 {
+		delete(s.slots, key)
+		delete(s.count, key)
+	} else {
+		s.count[key]--
+	}
+}
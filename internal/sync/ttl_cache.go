@@ -0,0 +1,82 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"sync"
+	"time"
+)
+
+// TTLCache is an in-process cache that each value expires after its own time to
+// live.
+type TTLCache struct {
+	lock    sync.Mutex
+	entries map[string]ttlCacheEntry
+	// lastSweep is the time that expired entries were last removed to recycle
+	// memory.
+	lastSweep time.Time
+}
+
+type ttlCacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+// NewTTLCache initializes and returns a new TTLCache.
+func NewTTLCache() *TTLCache {
+	return &TTLCache{
+		entries: make(map[string]ttlCacheEntry),
+	}
+}
+
+// Get returns the value of the key and true if the key exists and has not
+// expired.
+func (c *TTLCache) Get(key string) (any, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	} else if !time.Now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set sets the value of the key that expires after given time to live.
+func (c *TTLCache) Set(key string, value any, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > ttl {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = ttlCacheEntry{
+		value:     value,
+		expiresAt: now.Add(ttl),
+	}
+}
+
+// Delete removes the value of the key.
+func (c *TTLCache) Delete(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+// Reset removes all values.
+func (c *TTLCache) Reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = make(map[string]ttlCacheEntry)
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "unknwon.dev/clog/v2"
+
+	"gogs.io/gogs/internal/conf"
+)
+
+// Names of the blocks that custom snippets can be injected into.
+const (
+	SnippetBlockHead   = "head"
+	SnippetBlockFooter = "footer"
+)
+
+var snippetBlocks = []string{SnippetBlockHead, SnippetBlockFooter}
+
+// SnippetData is the data that custom snippets are rendered with.
+type SnippetData struct {
+	// Nonce is the random value of the current request to be used as the "nonce"
+	// attribute of inline scripts and styles for the Content Security Policy.
+	Nonce     string
+	AppSubURL string
+}
+
+var snippets = struct {
+	sync.RWMutex
+	blocks map[string][]*template.Template
+}{}
+
+// LoadSnippets (re)loads custom snippets from the given directory, where each
+// block has its own subdirectory containing files with ".tmpl" extension that
+// are rendered in the order of their names. Snippets that fail to parse are
+// skipped with errors logged.
+func LoadSnippets(dir string) error {
+	blocks := make(map[string][]*template.Template, len(snippetBlocks))
+	for _, block := range snippetBlocks {
+		paths, err := filepath.Glob(filepath.Join(dir, block, "*.tmpl"))
+		if err != nil {
+			return errors.Wrapf(err, "glob %q", block)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return errors.Wrapf(err, "read %q", path)
+			}
+
+			tmpl, err := template.New(filepath.Base(path)).Parse(string(data))
+			if err != nil {
+				log.Error("Failed to parse custom snippet %q: %v", path, err)
+				continue
+			}
+			blocks[block] = append(blocks[block], tmpl)
+		}
+	}
+
+	snippets.Lock()
+	snippets.blocks = blocks
+	snippets.Unlock()
+	return nil
+}
+
+// RenderSnippets renders all custom snippets of the named block. Snippets that
+// fail to render are left out with errors logged so that they cannot break the
+// page.
+func RenderSnippets(block, nonce string) template.HTML {
+	snippets.RLock()
+	tmpls := snippets.blocks[block]
+	snippets.RUnlock()
+	if len(tmpls) == 0 {
+		return ""
+	}
+
+	data := SnippetData{
+		Nonce:     nonce,
+		AppSubURL: conf.Server.Subpath,
+	}
+	var out strings.Builder
+	for _, tmpl := range tmpls {
+		out.Write(renderSnippet(tmpl, data))
+	}
+	return template.HTML(out.String())
+}
+
+func renderSnippet(tmpl *template.Template, data SnippetData) (out []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Panic while rendering custom snippet %q: %v", tmpl.Name(), r)
+			out = nil
+		}
+	}()
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		log.Error("Failed to render custom snippet %q: %v", tmpl.Name(), err)
+		return nil
+	}
+	return buf.Bytes()
+}
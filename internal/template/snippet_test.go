@@ -0,0 +1,67 @@
+// Copyright 2024 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package template
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderSnippets(t *testing.T) {
+	dir := t.TempDir()
+	writeSnippet := func(t *testing.T, name, content string) {
+		path := filepath.Join(dir, name)
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		require.NoError(t, err)
+		err = os.WriteFile(path, []byte(content), 0o644)
+		require.NoError(t, err)
+	}
+	writeSnippet(t, "head/01-analytics.tmpl", `<script nonce="{{.Nonce}}">track()</script>`)
+	writeSnippet(t, "head/02-malformed.tmpl", `<p>{{if .Nonce}}</p>`)
+	writeSnippet(t, "head/03-broken.tmpl", `<p>{{.Missing}}</p>`)
+	writeSnippet(t, "head/04-style.tmpl", `<link rel="stylesheet" href="{{.AppSubURL}}/custom.css">`)
+	writeSnippet(t, "head/README.md", `Not a snippet`)
+	writeSnippet(t, "footer/01-banner.tmpl", `<div class="banner">Hello</div>`)
+
+	err := LoadSnippets(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = LoadSnippets(t.TempDir())
+	})
+
+	tmpl, err := template.New("page").Funcs(FuncMap()[0]).Parse(
+		`<head>{{RenderSnippets "head" .CSPNonce}}</head><body>{{RenderSnippets "footer" .CSPNonce}}</body>`,
+	)
+	require.NoError(t, err)
+
+	render := func(t *testing.T, data map[string]any) string {
+		var buf strings.Builder
+		err := tmpl.Execute(&buf, data)
+		require.NoError(t, err)
+		return buf.String()
+	}
+
+	got := render(t, map[string]any{"CSPNonce": `abc"def`})
+	want := `<head><script nonce="abc&#34;def">track()</script><link rel="stylesheet" href="/custom.css"></head>` +
+		`<body><div class="banner">Hello</div></body>`
+	assert.Equal(t, want, got)
+
+	t.Run("reload", func(t *testing.T) {
+		err := os.Remove(filepath.Join(dir, "footer", "01-banner.tmpl"))
+		require.NoError(t, err)
+		err = LoadSnippets(dir)
+		require.NoError(t, err)
+
+		got := render(t, map[string]any{"CSPNonce": "abc"})
+		assert.NotContains(t, got, "banner")
+		assert.Contains(t, got, `<script nonce="abc">track()</script>`)
+	})
+}
@@ -132,6 +132,7 @@ func FuncMap() []template.FuncMap {
 				return "tab-size-8"
 			},
 			"InferSubmoduleURL": gitutil.InferSubmoduleURL,
+			"RenderSnippets":    RenderSnippets,
 		}}
 	})
 	return funcMap
@@ -49,6 +49,22 @@ func GenerateActivateCode(userID int64, email, name, password, rands string) str
 	return code
 }
 
+// VerifyActivateCode returns true if the activate code is generated by
+// GenerateActivateCode with the same user information and the given email, and
+// has not yet expired.
+func VerifyActivateCode(code string, userID int64, email, name, password, rands string) bool {
+	if len(code) <= tool.TIME_LIMIT_CODE_LENGTH ||
+		code[tool.TIME_LIMIT_CODE_LENGTH:] != hex.EncodeToString([]byte(strings.ToLower(name))) {
+		return false
+	}
+
+	return tool.VerifyTimeLimitCode(
+		fmt.Sprintf("%d%s%s%s%s", userID, email, strings.ToLower(name), password, rands),
+		conf.Auth.ActivateCodeLives,
+		code[:tool.TIME_LIMIT_CODE_LENGTH],
+	)
+}
+
 // CustomAvatarPath returns the absolute path of the user custom avatar file.
 func CustomAvatarPath(userID int64) string {
 	return filepath.Join(conf.Picture.AvatarUploadPath, strconv.FormatInt(userID, 10))
@@ -129,11 +145,41 @@ func MailResendCacheKey(userID int64) string {
 	return fmt.Sprintf("mailResend::%d", userID)
 }
 
+// ExportCacheKey returns the key used for caching data export.
+func ExportCacheKey(userID int64) string {
+	return fmt.Sprintf("export::%d", userID)
+}
+
 // TwoFactorCacheKey returns the key used for caching two factor passcode.
 func TwoFactorCacheKey(userID int64, passcode string) string {
 	return fmt.Sprintf("twoFactor::%d::%s", userID, passcode)
 }
 
+// NoReplyEmail returns the noreply email address of the user, which is used in
+// place of the real email address of the user who keeps it private.
+func NoReplyEmail(userID int64, name string) string {
+	return fmt.Sprintf("%d+%s@%s", userID, strings.ToLower(name), conf.User.NoReplyDomain)
+}
+
+// ParseNoReplyEmail returns the user ID and name of the noreply email address.
+// It returns false if the email is not a noreply email address.
+func ParseNoReplyEmail(email string) (userID int64, name string, ok bool) {
+	local, ok := strings.CutSuffix(strings.ToLower(email), "@"+strings.ToLower(conf.User.NoReplyDomain))
+	if !ok {
+		return 0, "", false
+	}
+
+	idStr, name, ok := strings.Cut(local, "+")
+	if !ok || name == "" {
+		return 0, "", false
+	}
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || userID <= 0 {
+		return 0, "", false
+	}
+	return userID, name, true
+}
+
 // RandomSalt returns randomly generated 10-character string that can be used as
 // the user salt.
 func RandomSalt() (string, error) {
@@ -5,9 +5,11 @@
 package userutil
 
 import (
+	"encoding/hex"
 	"os"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,6 +46,41 @@ func TestGenerateActivateCode(t *testing.T) {
 	assert.True(t, got)
 }
 
+func TestVerifyActivateCode(t *testing.T) {
+	conf.SetMockAuth(t,
+		conf.AuthOpts{
+			ActivateCodeLives: 10,
+		},
+	)
+
+	t.Run("round trip", func(t *testing.T) {
+		code := GenerateActivateCode(1, "alice@example.com", "Alice", "123456", "rands")
+		assert.True(t, VerifyActivateCode(code, 1, "alice@example.com", "Alice", "123456", "rands"))
+	})
+
+	t.Run("mismatched user information", func(t *testing.T) {
+		code := GenerateActivateCode(1, "alice@example.com", "Alice", "123456", "rands")
+		assert.False(t, VerifyActivateCode(code, 1, "bob@example.com", "Alice", "123456", "rands"))
+		assert.False(t, VerifyActivateCode(code, 1, "alice@example.com", "Bob", "123456", "rands"))
+		// The code is invalidated once the rands are regenerated
+		assert.False(t, VerifyActivateCode(code, 1, "alice@example.com", "Alice", "123456", "new-rands"))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		start := time.Now().Add(-11 * time.Minute).Format("200601021504")
+		code := tool.CreateTimeLimitCode("1alice@example.comalice123456rands", conf.Auth.ActivateCodeLives, start) +
+			hex.EncodeToString([]byte("alice"))
+		assert.False(t, VerifyActivateCode(code, 1, "alice@example.com", "Alice", "123456", "rands"))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		assert.False(t, VerifyActivateCode("", 1, "alice@example.com", "Alice", "123456", "rands"))
+
+		code := GenerateActivateCode(1, "alice@example.com", "Alice", "123456", "rands")
+		assert.False(t, VerifyActivateCode(code[:tool.TIME_LIMIT_CODE_LENGTH], 1, "alice@example.com", "Alice", "123456", "rands"))
+	})
+}
+
 func TestCustomAvatarPath(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("Skipping testing on Windows")
@@ -187,11 +224,74 @@ func TestMailResendCacheKey(t *testing.T) {
 	assert.Equal(t, "mailResend::1", got)
 }
 
+func TestExportCacheKey(t *testing.T) {
+	got := ExportCacheKey(1)
+	assert.Equal(t, "export::1", got)
+}
+
 func TestTwoFactorCacheKey(t *testing.T) {
 	got := TwoFactorCacheKey(1, "113654")
 	assert.Equal(t, "twoFactor::1::113654", got)
 }
 
+func TestNoReplyEmail(t *testing.T) {
+	conf.SetMockUser(t, conf.UserOpts{NoReplyDomain: "noreply.example.com"})
+
+	got := NoReplyEmail(1, "Alice")
+	assert.Equal(t, "1+alice@noreply.example.com", got)
+}
+
+func TestParseNoReplyEmail(t *testing.T) {
+	conf.SetMockUser(t, conf.UserOpts{NoReplyDomain: "noreply.example.com"})
+
+	tests := []struct {
+		name     string
+		email    string
+		wantID   int64
+		wantName string
+		wantOK   bool
+	}{
+		{
+			name:     "noreply",
+			email:    "1+alice@noreply.example.com",
+			wantID:   1,
+			wantName: "alice",
+			wantOK:   true,
+		},
+		{
+			name:     "case insensitive",
+			email:    "1+Alice@NoReply.Example.com",
+			wantID:   1,
+			wantName: "alice",
+			wantOK:   true,
+		},
+		{
+			name:  "other domain",
+			email: "1+alice@example.com",
+		},
+		{
+			name:  "no user ID",
+			email: "alice@noreply.example.com",
+		},
+		{
+			name:  "bad user ID",
+			email: "x+alice@noreply.example.com",
+		},
+		{
+			name:  "no username",
+			email: "1+@noreply.example.com",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotID, gotName, gotOK := ParseNoReplyEmail(test.email)
+			assert.Equal(t, test.wantID, gotID)
+			assert.Equal(t, test.wantName, gotName)
+			assert.Equal(t, test.wantOK, gotOK)
+		})
+	}
+}
+
 func TestRandomSalt(t *testing.T) {
 	salt1, err := RandomSalt()
 	require.NoError(t, err)
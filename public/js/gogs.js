@@ -1070,6 +1070,7 @@ function initAdmin() {
       $(".smtp").hide();
       $(".pam").hide();
       $(".github").hide();
+      $(".oidc").hide();
       $(".has-tls").hide();
 
       var authType = $(this).val();
@@ -1091,6 +1092,10 @@ function initAdmin() {
           $(".github").show();
           $(".has-tls").show();
           break;
+        case "7": // OpenID Connect
+          $(".oidc").show();
+          $(".has-tls").show();
+          break;
       }
 
       if (authType == "2" || authType == "5") {
@@ -1174,6 +1179,34 @@ function hideWhenLostFocus(body, parent) {
   });
 }
 
+function searchTopics() {
+  var $topics = $("#topics");
+  if (!$topics.length) {
+    return;
+  }
+
+  var $list = $("#topics-list");
+  $topics.keyup(function() {
+    var value = $topics.val();
+    var index = value.lastIndexOf(",");
+    var prefix = index === -1 ? "" : value.substring(0, index + 1) + " ";
+    var keyword = $.trim(value.substring(index + 1));
+
+    $.ajax({
+      url: $topics.data("url") + "?q=" + encodeURIComponent(keyword),
+      dataType: "json",
+      success: function(response) {
+        $list.html("");
+        if (response.ok) {
+          $.each(response.data, function(i, topic) {
+            $list.append($("<option>").attr("value", prefix + topic));
+          });
+        }
+      }
+    });
+  });
+}
+
 function searchUsers() {
   if (!$("#search-user-box .results").length) {
     return;
@@ -1666,6 +1699,7 @@ $(document).ready(function() {
   buttonsClickOnEnter();
   searchUsers();
   searchRepositories();
+  searchTopics();
 
   initCommentForm();
   initRepository();